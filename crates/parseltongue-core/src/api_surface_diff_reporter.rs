@@ -0,0 +1,405 @@
+//! Public API surface diff between two revisions (v1.7.3)
+//!
+//! # 4-Word Naming: api_surface_diff_reporter
+//!
+//! `parseltongue api-diff <rev1> <rev2>` checks both revisions out into one
+//! cached git worktree (under the repository's git directory, so it is never
+//! indexed itself) and indexes them into one cached database. The second
+//! revision, and every later run, is an incremental re-index: only files
+//! whose content hash changed are re-parsed.
+//!
+//! Exported symbols (`is_entity_symbol_exported`) of both snapshots are
+//! matched by ISGL1 key, which is stable for the same file and name, and
+//! compared by their one-line `signature`:
+//!
+//! ```text
+//! # API Diff: v1.4.0..HEAD
+//! Semver impact: **major** (1 removed, 1 changed, 2 added)
+//! ## Removed               breaking: gone or no longer exported
+//! ## Changed Signatures    breaking: before / after
+//! ## Added                 minor
+//! ```
+//!
+//! Removed or changed symbols make the change `major`, additions alone
+//! `minor`, anything else `patch`. Moving a symbol to another file reports
+//! it as removed plus added.
+
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+use serde::Serialize;
+
+use crate::dead_code_detection_analyzer::is_entity_symbol_exported;
+use crate::entities::CodeEntity;
+use crate::error::{ParseltongError, Result};
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::storage::revision_history_sqlite_store::RevisionCommitDetailsEntry;
+use crate::symbol_doc_signature_extractor::{extract_symbol_signature_line, SIGNATURE_METADATA_KEY};
+
+/// Fixed section headings of the markdown report, in order
+pub const API_DIFF_SECTION_HEADINGS: [&str; 3] = ["Removed", "Changed Signatures", "Added"];
+
+/// One exported symbol of a revision
+///
+/// # 4-Word Name: ApiSymbolSurfaceEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ApiSymbolSurfaceEntry {
+    pub entity_key: String,
+    pub name: String,
+    /// Key segment 2: fn, method, struct, trait, ...
+    pub kind: String,
+    /// Relative to the indexed root
+    pub file_path: String,
+    pub signature: String,
+}
+
+/// An exported symbol whose signature differs between the revisions
+///
+/// # 4-Word Name: ApiSignatureChangeEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ApiSignatureChangeEntry {
+    pub entity_key: String,
+    pub name: String,
+    pub kind: String,
+    pub file_path: String,
+    pub before_signature: String,
+    pub after_signature: String,
+}
+
+/// Semver bump implied by an API diff
+///
+/// # 4-Word Name: SemverImpactLevel
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum SemverImpactLevel {
+    Patch,
+    Minor,
+    Major,
+}
+
+impl SemverImpactLevel {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            SemverImpactLevel::Patch => "patch",
+            SemverImpactLevel::Minor => "minor",
+            SemverImpactLevel::Major => "major",
+        }
+    }
+}
+
+/// Added / removed / changed exported symbols between two revisions
+///
+/// # 4-Word Name: ApiSurfaceDiffReport
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ApiSurfaceDiffReport {
+    pub from_revision: String,
+    pub to_revision: String,
+    pub semver_impact: SemverImpactLevel,
+    pub removed: Vec<ApiSymbolSurfaceEntry>,
+    pub changed: Vec<ApiSignatureChangeEntry>,
+    pub added: Vec<ApiSymbolSurfaceEntry>,
+}
+
+/// Exported symbols of one indexed revision, by entity key
+///
+/// # 4-Word Name: collect_exported_api_symbols
+///
+/// # Contract
+/// - Precondition: `root_prefix` is the directory the revision was indexed
+///   from; it is stripped from file paths
+/// - Postcondition: placeholders and symbols without a signature are skipped
+pub fn collect_exported_api_symbols(
+    entities: &[CodeEntity],
+    root_prefix: &Path,
+) -> BTreeMap<String, ApiSymbolSurfaceEntry> {
+    let mut symbols = BTreeMap::new();
+    for entity in entities {
+        if is_external_graph_node_key(&entity.isgl1_key) || !is_entity_symbol_exported(entity) {
+            continue;
+        }
+        let signature = match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+            Some(signature) => signature.clone(),
+            None => extract_symbol_signature_line(entity.current_code.as_deref().unwrap_or("")),
+        };
+        if signature.is_empty() {
+            continue;
+        }
+        let file_path = &entity.interface_signature.file_path;
+        let relative = file_path.strip_prefix(root_prefix).unwrap_or(file_path);
+        symbols.insert(
+            entity.isgl1_key.clone(),
+            ApiSymbolSurfaceEntry {
+                entity_key: entity.isgl1_key.clone(),
+                name: entity.interface_signature.name.clone(),
+                kind: entity.isgl1_key.split(':').nth(1).unwrap_or_default().to_string(),
+                file_path: relative.to_string_lossy().to_string(),
+                signature,
+            },
+        );
+    }
+    symbols
+}
+
+/// Compare the exported symbols of two revisions
+///
+/// # 4-Word Name: diff_api_surface_snapshots
+///
+/// # Contract
+/// - Postcondition: every list sorted by (file, name, key); a symbol is in
+///   at most one list; `semver_impact` as described in the module docs
+pub fn diff_api_surface_snapshots(
+    from_revision: &str,
+    before: &BTreeMap<String, ApiSymbolSurfaceEntry>,
+    to_revision: &str,
+    after: &BTreeMap<String, ApiSymbolSurfaceEntry>,
+) -> ApiSurfaceDiffReport {
+    let mut removed: Vec<ApiSymbolSurfaceEntry> =
+        before.iter().filter(|(key, _)| !after.contains_key(*key)).map(|(_, s)| s.clone()).collect();
+    let mut added: Vec<ApiSymbolSurfaceEntry> =
+        after.iter().filter(|(key, _)| !before.contains_key(*key)).map(|(_, s)| s.clone()).collect();
+    let mut changed: Vec<ApiSignatureChangeEntry> = before
+        .iter()
+        .filter_map(|(key, old)| {
+            let new = after.get(key)?;
+            (new.signature != old.signature).then(|| ApiSignatureChangeEntry {
+                entity_key: key.clone(),
+                name: new.name.clone(),
+                kind: new.kind.clone(),
+                file_path: new.file_path.clone(),
+                before_signature: old.signature.clone(),
+                after_signature: new.signature.clone(),
+            })
+        })
+        .collect();
+
+    let order = |a: &ApiSymbolSurfaceEntry, b: &ApiSymbolSurfaceEntry| {
+        (&a.file_path, &a.name, &a.entity_key).cmp(&(&b.file_path, &b.name, &b.entity_key))
+    };
+    removed.sort_by(order);
+    added.sort_by(order);
+    changed.sort_by(|a, b| (&a.file_path, &a.name, &a.entity_key).cmp(&(&b.file_path, &b.name, &b.entity_key)));
+
+    let semver_impact = if !removed.is_empty() || !changed.is_empty() {
+        SemverImpactLevel::Major
+    } else if !added.is_empty() {
+        SemverImpactLevel::Minor
+    } else {
+        SemverImpactLevel::Patch
+    };
+
+    ApiSurfaceDiffReport {
+        from_revision: from_revision.to_string(),
+        to_revision: to_revision.to_string(),
+        semver_impact,
+        removed,
+        changed,
+        added,
+    }
+}
+
+/// Render the report as markdown with the fixed section headings
+///
+/// # 4-Word Name: render_api_surface_diff_markdown
+pub fn render_api_surface_diff_markdown(report: &ApiSurfaceDiffReport) -> String {
+    let mut out = format!(
+        "# API Diff: {}..{}\n\nSemver impact: **{}** ({} removed, {} changed, {} added)\n",
+        report.from_revision,
+        report.to_revision,
+        report.semver_impact.as_str(),
+        report.removed.len(),
+        report.changed.len(),
+        report.added.len()
+    );
+    for heading in API_DIFF_SECTION_HEADINGS {
+        out.push_str(&format!("\n## {}\n\n", heading));
+        let lines: Vec<String> = match heading {
+            "Changed Signatures" => report
+                .changed
+                .iter()
+                .map(|c| {
+                    format!(
+                        "- `{}` ({}) {}\n  - before: `{}`\n  - after: `{}`\n",
+                        c.name, c.kind, c.file_path, c.before_signature, c.after_signature
+                    )
+                })
+                .collect(),
+            _ => {
+                let entries = if heading == "Removed" { &report.removed } else { &report.added };
+                entries
+                    .iter()
+                    .map(|s| format!("- `{}` ({}) {}\n  `{}`\n", s.name, s.kind, s.file_path, s.signature))
+                    .collect()
+            }
+        };
+        if lines.is_empty() {
+            out.push_str("_None._\n");
+        }
+        for line in lines {
+            out.push_str(&line);
+        }
+    }
+    out
+}
+
+/// Directory git keeps shared state in (the main `.git` for linked worktrees)
+///
+/// # 4-Word Name: resolve_git_common_directory
+///
+/// # Contract
+/// - Error: git missing or `repo_root` not inside a repository
+pub fn resolve_git_common_directory(repo_root: &Path) -> Result<PathBuf> {
+    let stdout = run_git_command_checked(repo_root, &["rev-parse", "--git-common-dir"])?;
+    let common_dir = PathBuf::from(stdout.trim());
+    Ok(if common_dir.is_absolute() { common_dir } else { repo_root.join(common_dir) })
+}
+
+/// Check `revision` out, detached, into the cached worktree at `worktree_dir`
+///
+/// # 4-Word Name: checkout_revision_into_worktree
+///
+/// # Contract
+/// - Postcondition: `worktree_dir` holds exactly the tree of `revision`;
+///   the worktree is created on first use and reused afterwards, so files
+///   unchanged between revisions keep their content (and incremental hash)
+/// - `revision` is resolved in `repo_root`, so `HEAD` means the caller's
+///   HEAD, not the worktree's
+/// - Error: git missing, or `revision` unknown
+pub fn checkout_revision_into_worktree(repo_root: &Path, revision: &str, worktree_dir: &Path) -> Result<()> {
+    let commit_spec = format!("{}^{{commit}}", revision);
+    let commit = run_git_command_checked(repo_root, &["rev-parse", "--verify", &commit_spec])?;
+    let commit = commit.trim();
+    if worktree_dir.join(".git").exists() {
+        run_git_command_checked(worktree_dir, &["checkout", "--detach", "--force", commit])?;
+        run_git_command_checked(worktree_dir, &["clean", "-fdx", "--quiet"])?;
+        return Ok(());
+    }
+    // A stale directory (worktree pruned by `git worktree prune`) blocks `add`
+    if worktree_dir.exists() {
+        std::fs::remove_dir_all(worktree_dir).map_err(|source| ParseltongError::FileSystemError {
+            path: worktree_dir.display().to_string(),
+            source,
+        })?;
+    }
+    run_git_command_checked(repo_root, &["worktree", "prune"])?;
+    let target = worktree_dir.to_string_lossy().to_string();
+    run_git_command_checked(repo_root, &["worktree", "add", "--detach", "--force", &target, commit])?;
+    Ok(())
+}
+
+/// Commit, date, subject and tags that `revision` resolves to
+///
+/// # 4-Word Name: resolve_revision_commit_details
+///
+/// # Contract
+/// - Postcondition: `commit_sha` is the full hash; `tags` are every tag
+///   pointing at it, sorted by git
+/// - Error: git missing, or `revision` unknown
+pub fn resolve_revision_commit_details(repo_root: &Path, revision: &str) -> Result<RevisionCommitDetailsEntry> {
+    let commit_spec = format!("{}^{{commit}}", revision);
+    let commit = run_git_command_checked(repo_root, &["rev-parse", "--verify", &commit_spec])?;
+    let commit = commit.trim();
+    let log = run_git_command_checked(repo_root, &["log", "-1", "--format=%cI%n%s", commit])?;
+    let mut lines = log.lines();
+    let committed_at = lines.next().unwrap_or_default().to_string();
+    let subject = lines.next().unwrap_or_default().to_string();
+    let tags = run_git_command_checked(repo_root, &["tag", "--points-at", commit])?;
+    Ok(RevisionCommitDetailsEntry {
+        commit_sha: commit.to_string(),
+        tags: tags.lines().filter(|tag| !tag.is_empty()).map(str::to_string).collect(),
+        committed_at,
+        subject,
+    })
+}
+
+/// Every tag of the repository, oldest first
+///
+/// # 4-Word Name: list_repository_tags_chronologically
+pub fn list_repository_tags_chronologically(repo_root: &Path) -> Result<Vec<String>> {
+    let tags = run_git_command_checked(repo_root, &["tag", "--list", "--sort=creatordate"])?;
+    Ok(tags.lines().filter(|tag| !tag.is_empty()).map(str::to_string).collect())
+}
+
+/// stdout of a git command that must succeed
+fn run_git_command_checked(directory: &Path, args: &[&str]) -> Result<String> {
+    let output = Command::new("git")
+        .args(args)
+        .current_dir(directory)
+        .output()
+        .map_err(|source| ParseltongError::FileSystemError {
+            path: directory.display().to_string(),
+            source,
+        })?;
+    if !output.status.success() {
+        return Err(ParseltongError::ConfigurationError {
+            details: format!("git {} failed: {}", args.join(" "), String::from_utf8_lossy(&output.stderr).trim()),
+        });
+    }
+    Ok(String::from_utf8_lossy(&output.stdout).to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn symbol(key: &str, name: &str, signature: &str) -> CodeEntity {
+        create_go_test_entity(key, name, EntityType::Function, "/wt/svc/store.go", &[("signature", signature)])
+    }
+
+    #[test]
+    fn test_api_diff_classifies_symbols_and_semver() {
+        let before = vec![
+            symbol("go:fn:Save:__wt_svc_store:T1", "Save", "func Save(u User) error"),
+            symbol("go:fn:Load:__wt_svc_store:T2", "Load", "func Load(id int) User"),
+            symbol("go:fn:Keep:__wt_svc_store:T3", "Keep", "func Keep()"),
+            symbol("go:fn:helper:__wt_svc_store:T4", "helper", "func helper()"),
+        ];
+        let after = vec![
+            symbol("go:fn:Save:__wt_svc_store:T1", "Save", "func Save(ctx context.Context, u User) error"),
+            symbol("go:fn:Keep:__wt_svc_store:T3", "Keep", "func Keep()"),
+            symbol("go:fn:Delete:__wt_svc_store:T5", "Delete", "func Delete(id int) error"),
+            symbol("go:fn:helper:__wt_svc_store:T4", "helper", "func helper(x int)"),
+        ];
+        let root = Path::new("/wt");
+        let report = diff_api_surface_snapshots(
+            "v1",
+            &collect_exported_api_symbols(&before, root),
+            "v2",
+            &collect_exported_api_symbols(&after, root),
+        );
+
+        assert_eq!(report.semver_impact, SemverImpactLevel::Major);
+        assert_eq!(report.removed.iter().map(|s| s.name.as_str()).collect::<Vec<_>>(), vec!["Load"]);
+        assert_eq!(report.added.iter().map(|s| s.name.as_str()).collect::<Vec<_>>(), vec!["Delete"]);
+        assert_eq!(report.changed.len(), 1);
+        assert_eq!(report.changed[0].before_signature, "func Save(u User) error");
+        assert_eq!(report.changed[0].file_path, "svc/store.go");
+
+        let additive = diff_api_surface_snapshots(
+            "v2",
+            &collect_exported_api_symbols(&after[1..2], root),
+            "v3",
+            &collect_exported_api_symbols(&after, root),
+        );
+        assert_eq!(additive.semver_impact, SemverImpactLevel::Minor);
+    }
+
+    #[test]
+    fn test_api_diff_markdown_keeps_empty_sections() {
+        let after = vec![symbol("go:fn:Delete:__wt_svc_store:T5", "Delete", "func Delete(id int) error")];
+        let report = diff_api_surface_snapshots(
+            "v1",
+            &BTreeMap::new(),
+            "v2",
+            &collect_exported_api_symbols(&after, Path::new("/wt")),
+        );
+        let markdown = render_api_surface_diff_markdown(&report);
+
+        assert!(markdown.starts_with("# API Diff: v1..v2\n\nSemver impact: **minor** (0 removed, 0 changed, 1 added)\n"));
+        assert!(markdown.contains("## Removed\n\n_None._\n"));
+        assert!(markdown.contains("## Changed Signatures\n\n_None._\n"));
+        assert!(markdown.contains("## Added\n\n- `Delete` (fn) svc/store.go\n  `func Delete(id int) error`\n"));
+    }
+}
@@ -0,0 +1,356 @@
+//! BM25 keyword search blended with graph proximity (v1.7.3)
+//!
+//! # 4-Word Naming: bm25_hybrid_keyword_retriever
+//!
+//! A full-text index over every symbol, so "everything about rate limiting
+//! near this handler" works without embeddings. Terms come from the same
+//! word splitting as the embeddings (camelCase/snake_case split, stop words
+//! dropped, light stemming: `RateLimiter`, `rate_limit` and "rate limiting"
+//! all meet at `rate` + `limit`), weighted by where they occur:
+//!
+//! | Field | Weight |
+//! |-------|--------|
+//! | symbol name | 3 |
+//! | doc comment | 2 |
+//! | string literals in the body | 2 (on top of the body) |
+//! | body identifiers and comments | 1 |
+//!
+//! Scores are Okapi BM25 (`k1 = 1.2`, `b = 0.75`). With a seed, each hit's
+//! keyword score (scaled to the best hit) is blended with its graph
+//! proximity to the seed, `1 / (1 + hops)` over edges in either direction
+//! up to `max_depth` hops (0 beyond):
+//!
+//! ```text
+//! score = (1 - proximity_weight) × keyword / best_keyword + proximity_weight × proximity
+//! ```
+//!
+//! Only symbols matching at least one query term are returned; proximity
+//! reorders matches but never adds non-matching symbols.
+
+use std::collections::{HashMap, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::semantic_symbol_embedding_index::{
+    split_identifier_into_words, stem_word_light_suffix, EMBEDDING_STOP_WORD_LIST,
+};
+
+/// BM25 term-frequency saturation
+pub const BM25_TERM_SATURATION_K1: f64 = 1.2;
+
+/// BM25 document-length normalization
+pub const BM25_LENGTH_NORMALIZATION_B: f64 = 0.75;
+
+/// Share of the blended score that comes from graph proximity
+pub const DEFAULT_PROXIMITY_BLEND_WEIGHT: f64 = 0.4;
+
+/// Hops from the seed beyond which proximity is 0
+pub const DEFAULT_PROXIMITY_MAX_DEPTH: usize = 3;
+
+const NAME_FIELD_TERM_WEIGHT: f64 = 3.0;
+const DOC_FIELD_TERM_WEIGHT: f64 = 2.0;
+const LITERAL_FIELD_TERM_WEIGHT: f64 = 1.0;
+const BODY_FIELD_TERM_WEIGHT: f64 = 1.0;
+
+/// Weighted term frequencies of one symbol
+#[derive(Debug, Clone)]
+struct KeywordDocumentEntry {
+    entity_key: String,
+    term_weights: HashMap<String, f64>,
+    length: f64,
+}
+
+/// In-memory BM25 index over symbols
+///
+/// # 4-Word Name: KeywordSearchIndexStore
+#[derive(Debug, Clone, Default)]
+pub struct KeywordSearchIndexStore {
+    documents: Vec<KeywordDocumentEntry>,
+    document_frequency: HashMap<String, usize>,
+    average_length: f64,
+}
+
+/// Options of a keyword search
+///
+/// # 4-Word Name: HybridRetrievalOptionsSpec
+#[derive(Debug, Clone, PartialEq)]
+pub struct HybridRetrievalOptionsSpec {
+    /// Seed whose neighbourhood is preferred; keyword-only when `None`
+    pub seed_key: Option<String>,
+    pub proximity_weight: f64,
+    pub max_depth: usize,
+    pub limit: usize,
+}
+
+impl Default for HybridRetrievalOptionsSpec {
+    fn default() -> Self {
+        Self {
+            seed_key: None,
+            proximity_weight: DEFAULT_PROXIMITY_BLEND_WEIGHT,
+            max_depth: DEFAULT_PROXIMITY_MAX_DEPTH,
+            limit: 10,
+        }
+    }
+}
+
+/// One keyword search result
+///
+/// # 4-Word Name: HybridRetrievalHitEntry
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct HybridRetrievalHitEntry {
+    pub entity_key: String,
+    /// Raw BM25 score
+    pub keyword_score: f64,
+    /// Hops from the seed (either direction), when within `max_depth`
+    pub graph_distance: Option<usize>,
+    pub score: f64,
+}
+
+/// Search terms of a text: split, stop words dropped, stemmed
+///
+/// # 4-Word Name: tokenize_keyword_search_terms
+pub fn tokenize_keyword_search_terms(text: &str) -> Vec<String> {
+    split_identifier_into_words(text)
+        .into_iter()
+        .filter(|word| word.len() >= 2 && !EMBEDDING_STOP_WORD_LIST.contains(&word.as_str()))
+        .map(|word| stem_word_light_suffix(&word).to_string())
+        .collect()
+}
+
+/// Contents of `"..."` and `` `...` `` literals in source text
+fn extract_string_literal_contents(code: &str) -> Vec<String> {
+    let mut literals = Vec::new();
+    let mut chars = code.chars();
+    while let Some(c) = chars.next() {
+        if c != '"' && c != '`' {
+            continue;
+        }
+        let mut literal = String::new();
+        while let Some(next) = chars.next() {
+            match next {
+                '\\' if c == '"' => literal.extend(chars.next()),
+                '\n' if c == '"' => break,
+                _ if next == c => break,
+                _ => literal.push(next),
+            }
+        }
+        literals.push(literal);
+    }
+    literals
+}
+
+impl KeywordSearchIndexStore {
+    /// Index every symbol's name, doc comment, literals and body
+    ///
+    /// # 4-Word Name: build_from_code_entities
+    pub fn build_from_code_entities(entities: &[CodeEntity]) -> Self {
+        let mut documents = Vec::with_capacity(entities.len());
+        let mut document_frequency: HashMap<String, usize> = HashMap::new();
+        for entity in entities {
+            let mut term_weights: HashMap<String, f64> = HashMap::new();
+            let mut add_terms = |text: &str, weight: f64| {
+                for term in tokenize_keyword_search_terms(text) {
+                    *term_weights.entry(term).or_default() += weight;
+                }
+            };
+            add_terms(&entity.interface_signature.name, NAME_FIELD_TERM_WEIGHT);
+            if let Some(doc) = entity.interface_signature.documentation.as_deref() {
+                add_terms(doc, DOC_FIELD_TERM_WEIGHT);
+            }
+            if let Some(code) = entity.current_code.as_deref() {
+                for literal in extract_string_literal_contents(code) {
+                    add_terms(&literal, LITERAL_FIELD_TERM_WEIGHT);
+                }
+                add_terms(code, BODY_FIELD_TERM_WEIGHT);
+            }
+            for term in term_weights.keys() {
+                *document_frequency.entry(term.clone()).or_default() += 1;
+            }
+            let length = term_weights.values().sum();
+            documents.push(KeywordDocumentEntry { entity_key: entity.isgl1_key.clone(), term_weights, length });
+        }
+        let average_length = if documents.is_empty() {
+            0.0
+        } else {
+            documents.iter().map(|d| d.length).sum::<f64>() / documents.len() as f64
+        };
+        Self { documents, document_frequency, average_length }
+    }
+
+    /// Number of indexed symbols
+    ///
+    /// # 4-Word Name: indexed_document_count_value
+    pub fn indexed_document_count_value(&self) -> usize {
+        self.documents.len()
+    }
+
+    /// BM25 score of every symbol matching a query term
+    ///
+    /// # 4-Word Name: score_query_terms_bm25
+    ///
+    /// # Contract
+    /// - Postcondition: only positive scores; sorted by score descending,
+    ///   ties by key
+    pub fn score_query_terms_bm25(&self, query: &str) -> Vec<(String, f64)> {
+        let mut terms = tokenize_keyword_search_terms(query);
+        terms.sort();
+        terms.dedup();
+        let total = self.documents.len() as f64;
+        let mut scored: Vec<(String, f64)> = self
+            .documents
+            .iter()
+            .filter_map(|document| {
+                let score: f64 = terms
+                    .iter()
+                    .filter_map(|term| {
+                        let tf = *document.term_weights.get(term)?;
+                        let df = *self.document_frequency.get(term)? as f64;
+                        // Lucene's idf: the +1 keeps terms in most symbols non-negative
+                        let idf = ((total - df + 0.5) / (df + 0.5) + 1.0).ln();
+                        let norm = 1.0 - BM25_LENGTH_NORMALIZATION_B
+                            + BM25_LENGTH_NORMALIZATION_B * document.length / self.average_length.max(f64::EPSILON);
+                        Some(idf * tf * (BM25_TERM_SATURATION_K1 + 1.0) / (tf + BM25_TERM_SATURATION_K1 * norm))
+                    })
+                    .sum();
+                (score > 0.0).then(|| (document.entity_key.clone(), score))
+            })
+            .collect();
+        scored.sort_by(|a, b| b.1.partial_cmp(&a.1).unwrap_or(std::cmp::Ordering::Equal).then_with(|| a.0.cmp(&b.0)));
+        scored
+    }
+}
+
+/// Hops from `seed` to every key within `max_depth`, edges in either direction
+fn undirected_hop_distances_from(seed: &str, edges: &[DependencyEdge], max_depth: usize) -> HashMap<String, usize> {
+    let mut adjacency: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges {
+        adjacency.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+        adjacency.entry(edge.to_key.as_str()).or_default().push(edge.from_key.as_str());
+    }
+    let mut distances: HashMap<String, usize> = HashMap::from([(seed.to_string(), 0)]);
+    let mut queue = VecDeque::from([(seed, 0usize)]);
+    while let Some((key, depth)) = queue.pop_front() {
+        if depth == max_depth {
+            continue;
+        }
+        for &next in adjacency.get(key).into_iter().flatten() {
+            if !distances.contains_key(next) {
+                distances.insert(next.to_string(), depth + 1);
+                queue.push_back((next, depth + 1));
+            }
+        }
+    }
+    distances
+}
+
+/// Keyword search, optionally blended with proximity to a seed
+///
+/// # 4-Word Name: search_keywords_near_seed
+///
+/// # Contract
+/// - Postcondition: at most `limit` hits, each matching a query term;
+///   sorted by `score` descending, ties by key
+/// - Without a seed, `score = keyword / best_keyword` and `graph_distance`
+///   is `None`
+pub fn search_keywords_near_seed(
+    index: &KeywordSearchIndexStore,
+    query: &str,
+    edges: &[DependencyEdge],
+    options: &HybridRetrievalOptionsSpec,
+) -> Vec<HybridRetrievalHitEntry> {
+    let scored = index.score_query_terms_bm25(query);
+    let best = scored.first().map_or(1.0, |(_, score)| *score);
+    let distances = options
+        .seed_key
+        .as_deref()
+        .map(|seed| undirected_hop_distances_from(seed, edges, options.max_depth))
+        .unwrap_or_default();
+    let weight = if options.seed_key.is_some() { options.proximity_weight.clamp(0.0, 1.0) } else { 0.0 };
+
+    let mut hits: Vec<HybridRetrievalHitEntry> = scored
+        .into_iter()
+        .map(|(entity_key, keyword_score)| {
+            let graph_distance = distances.get(&entity_key).copied();
+            let proximity = graph_distance.map_or(0.0, |hops| 1.0 / (1.0 + hops as f64));
+            HybridRetrievalHitEntry {
+                score: (1.0 - weight) * keyword_score / best + weight * proximity,
+                entity_key,
+                keyword_score,
+                graph_distance,
+            }
+        })
+        .collect();
+    hits.sort_by(|a, b| {
+        b.score
+            .partial_cmp(&a.score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+    hits.truncate(options.limit);
+    hits
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn entity_with(key: &str, name: &str, doc: Option<&str>, code: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, EntityType::Function, "svc/a.go", &[]);
+        entity.interface_signature.documentation = doc.map(str::to_string);
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    fn sample_entities() -> Vec<CodeEntity> {
+        vec![
+            entity_with("go:fn:NewRateLimiter:__mw:T1", "NewRateLimiter", Some("NewRateLimiter caps requests per client."), "func NewRateLimiter() {}"),
+            entity_with("go:fn:HandleLogin:__api:T2", "HandleLogin", None, "func HandleLogin() { allow(\"login rate limited\") }"),
+            entity_with("go:fn:ParseConfig:__cfg:T3", "ParseConfig", None, "func ParseConfig() { load() }"),
+            entity_with("go:fn:throttle:__jobs:T4", "throttle", Some("throttle limits job rate"), "func throttle() {}"),
+        ]
+    }
+
+    #[test]
+    fn test_bm25_matches_identifiers_docs_and_literals() {
+        assert_eq!(tokenize_keyword_search_terms("rate limiting"), vec!["rate", "limit"]);
+        assert_eq!(extract_string_literal_contents("a(\"x \\\"y\\\"\", `raw`)"), vec!["x \"y\"", "raw"]);
+
+        let index = KeywordSearchIndexStore::build_from_code_entities(&sample_entities());
+        assert_eq!(index.indexed_document_count_value(), 4);
+        let scored = index.score_query_terms_bm25("rate limiting");
+        let keys: Vec<&str> = scored.iter().map(|(k, _)| k.as_str()).collect();
+        // Name match outranks a doc match, which outranks a literal; no match for ParseConfig
+        assert_eq!(keys.first(), Some(&"go:fn:NewRateLimiter:__mw:T1"));
+        assert_eq!(keys.len(), 3);
+        assert!(!keys.contains(&"go:fn:ParseConfig:__cfg:T3"));
+    }
+
+    #[test]
+    fn test_proximity_reorders_keyword_matches() {
+        let index = KeywordSearchIndexStore::build_from_code_entities(&sample_entities());
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:HandleLogin:__api:T2")
+            .to_key("go:fn:throttle:__jobs:T4")
+            .edge_type(EdgeType::Calls)
+            .build()
+            .unwrap()];
+
+        let plain = search_keywords_near_seed(&index, "rate limit", &edges, &HybridRetrievalOptionsSpec::default());
+        assert_eq!(plain[0].entity_key, "go:fn:NewRateLimiter:__mw:T1");
+        assert!((plain[0].score - 1.0).abs() < 1e-9 && plain[0].graph_distance.is_none());
+
+        let near = HybridRetrievalOptionsSpec {
+            seed_key: Some("go:fn:HandleLogin:__api:T2".to_string()),
+            proximity_weight: 0.6,
+            ..HybridRetrievalOptionsSpec::default()
+        };
+        let hits = search_keywords_near_seed(&index, "rate limit", &edges, &near);
+        assert_eq!(hits.len(), 3);
+        assert_eq!(hits[0].entity_key, "go:fn:HandleLogin:__api:T2");
+        assert_eq!(hits[1].entity_key, "go:fn:throttle:__jobs:T4");
+        assert_eq!(hits[1].graph_distance, Some(1));
+    }
+}
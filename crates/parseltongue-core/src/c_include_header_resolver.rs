@@ -0,0 +1,424 @@
+//! C/C++ Include & Call Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: c_include_header_resolver
+//!
+//! The per-file extractor gives C and C++ files function/struct/class nodes,
+//! `Calls` edges to unresolved targets (`c:fn:parse:unresolved-reference:0-0`)
+//! and one `Includes` edge per `#include`, from the file node to a
+//! placeholder of the header as written (`c:file:util_h:0-0`, with
+//! `include_path: util.h` and `include_kind: local | system`). This
+//! cross-file pass joins native code into the graph of the Go and Rust code
+//! wrapping it:
+//!
+//! 1. Includes → the header's file node when the repo has it
+//!    (`c:file:src_util_h:1-1`): `"x.h"` next to the including file first,
+//!    then, for both forms, the repo header whose path ends with the include
+//!    path, closest to the including file. Headers outside the repo
+//!    (`<stdio.h>`) keep their placeholder
+//! 2. cgo: the `#include`s of the preamble above `import "C"` become
+//!    `Includes` edges from the Go file node (`go:file:...:1-1`), bound the
+//!    same way
+//! 3. C/C++ calls → the function of that name in the caller's file, else the
+//!    unique one in a file it includes (transitively) or in the
+//!    implementation file next to such a header (`util.h` → `util.c`)
+//!
+//! ## Limitations (syntactic)
+//!
+//! - No preprocessor: `#ifdef`-guarded includes all count, macros are not
+//!   expanded and `-I` search paths are approximated by path suffix
+//! - Overloads and namespaces are not told apart; a name defined in several
+//!   visible files stays unresolved
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::path::{Path, PathBuf};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{parse_unresolved_reference_name, UNRESOLVED_TARGET_METADATA_KEY};
+use crate::go_typed_selector_resolver::relative_slash_path;
+use crate::protobuf_service_definition_linker::find_contract_files_by_extension;
+use crate::query_extractor::sanitize_path_for_key_format;
+
+/// Edge metadata key: `local` (`#include "x.h"`) or `system` (`#include <x.h>`)
+pub const INCLUDE_KIND_METADATA_KEY: &str = "include_kind";
+
+/// Edge metadata key: header path as written in the directive (`net/socket.h`)
+pub const INCLUDE_PATH_METADATA_KEY: &str = "include_path";
+
+/// Files an `#include` can be bound to
+const HEADER_FILE_EXTENSIONS: [&str; 4] = [".h", ".hh", ".hpp", ".hxx"];
+
+/// Files defining what a header declares, tried next to it in this order
+const IMPLEMENTATION_FILE_EXTENSIONS: [&str; 4] = ["c", "cc", "cpp", "cxx"];
+
+/// True for C and C++ sources and headers
+///
+/// # 4-Word Name: is_c_family_file_path
+pub fn is_c_family_file_path(path: &Path) -> bool {
+    matches!(
+        path.extension().and_then(|e| e.to_str()),
+        Some("c" | "h" | "cc" | "cpp" | "cxx" | "hh" | "hpp" | "hxx")
+    )
+}
+
+/// Placeholder target of an include no repo file is bound to yet
+pub(crate) fn format_unresolved_include_target_key(language: &str, include_path: &str) -> String {
+    format!("{}:file:{}:0-0", language, sanitize_path_for_key_format(include_path))
+}
+
+/// `"util.h"` / `<stdio.h>` / `'x.php'` → `util.h` / `stdio.h` / `x.php`
+pub(crate) fn unquote_include_path_text(text: &str) -> String {
+    text.trim().trim_matches(|c| matches!(c, '"' | '\'' | '<' | '>')).to_string()
+}
+
+/// One `#include` directive
+///
+/// # 4-Word Name: IncludeDirectiveLineEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct IncludeDirectiveLineEntry {
+    /// 1-based line of the directive
+    pub line: usize,
+    /// Header path without quotes or angle brackets
+    pub path: String,
+    /// `local` or `system`
+    pub kind: &'static str,
+}
+
+/// `#include`s of the cgo preamble: the comment right above `import "C"`
+///
+/// # 4-Word Name: parse_cgo_preamble_includes
+///
+/// # Contract
+/// - Postcondition: empty without a standalone `import "C"`; `//` lines and
+///   `/* */` blocks both count, a blank line ends the preamble (as in cgo)
+pub fn parse_cgo_preamble_includes(source: &str) -> Vec<IncludeDirectiveLineEntry> {
+    let lines: Vec<&str> = source.lines().collect();
+    let mut includes = Vec::new();
+    for (index, line) in lines.iter().enumerate() {
+        if line.trim() != "import \"C\"" {
+            continue;
+        }
+        let mut start = index;
+        while start > 0 {
+            let above = lines[start - 1].trim();
+            if above.starts_with("//") {
+                start -= 1;
+            } else if above.ends_with("*/") {
+                // Block comment: up to its opening line
+                let Some(open) = (0..start).rev().find(|&i| lines[i].contains("/*")) else { break };
+                start = open;
+            } else {
+                break;
+            }
+        }
+        for (offset, text) in lines[start..index].iter().enumerate() {
+            let text = text.trim().trim_start_matches("//").trim_start_matches("/*").trim();
+            if let Some(include) = parse_include_directive_text(text, start + offset + 1) {
+                includes.push(include);
+            }
+        }
+    }
+    includes
+}
+
+/// `#include "x.h"` / `# include <x.h>` on one line
+fn parse_include_directive_text(text: &str, line: usize) -> Option<IncludeDirectiveLineEntry> {
+    let rest = text.strip_prefix('#')?.trim_start().strip_prefix("include")?.trim_start();
+    let (kind, close) = match rest.chars().next()? {
+        '"' => ("local", '"'),
+        '<' => ("system", '>'),
+        _ => return None,
+    };
+    let end = rest[1..].find(close)?;
+    let path = rest[1..1 + end].trim();
+    if path.is_empty() {
+        return None;
+    }
+    Some(IncludeDirectiveLineEntry { line, path: path.to_string(), kind })
+}
+
+/// Bind includes and C/C++ calls to repo files and functions; add cgo includes
+///
+/// # 4-Word Name: resolve_c_include_header_edges
+///
+/// # Contract
+/// - Precondition: entity file paths and edge source locations (`path:line`)
+///   are the paths walked under `root`
+/// - Postcondition: bound includes point at `{c|cpp}:file:...:1-1` nodes, bound
+///   calls at entity keys; both carry `resolution` (`relative` /
+///   `search_path`, `same_file` / `included_file`) and `unresolved_target`
+/// - Postcondition: cgo preamble `Includes` edges are appended to `edges`
+/// - Returns: number of edges rewritten or added
+pub fn resolve_c_include_header_edges(entities: &[CodeEntity], edges: &mut Vec<DependencyEdge>, root: &Path) -> usize {
+    let mut headers: BTreeMap<String, PathBuf> = BTreeMap::new();
+    for extension in HEADER_FILE_EXTENSIONS {
+        for path in find_contract_files_by_extension(root, extension) {
+            headers.insert(relative_slash_path(root, &path), path);
+        }
+    }
+
+    let mut changed = 0;
+    let go_files: BTreeSet<&Path> = entities
+        .iter()
+        .map(|e| e.interface_signature.file_path.as_path())
+        .filter(|path| path.extension().is_some_and(|e| e == "go"))
+        .collect();
+    for path in go_files {
+        let Ok(source) = std::fs::read_to_string(path) else { continue };
+        for include in parse_cgo_preamble_includes(&source) {
+            let edge = DependencyEdge::builder()
+                .from_key(format!("go:file:{}:1-1", sanitize_path_for_key_format(&path.display().to_string())))
+                .to_key(format_unresolved_include_target_key("c", &include.path))
+                .edge_type(EdgeType::Includes)
+                .source_location(format!("{}:{}", path.display(), include.line))
+                .metadata_entry(INCLUDE_KIND_METADATA_KEY, include.kind)
+                .metadata_entry(INCLUDE_PATH_METADATA_KEY, include.path)
+                .build();
+            if let Ok(edge) = edge {
+                edges.push(edge);
+                changed += 1;
+            }
+        }
+    }
+
+    // Includes → header file nodes; remember what each file includes
+    let mut included_by_file: HashMap<String, Vec<String>> = HashMap::new();
+    for edge in edges.iter_mut().filter(|edge| edge.edge_type == EdgeType::Includes) {
+        let (Some(include), Some(location)) =
+            (edge.metadata.get(INCLUDE_PATH_METADATA_KEY).cloned(), edge.source_location.clone())
+        else {
+            continue;
+        };
+        let file = location.rsplit_once(':').map_or(location.as_str(), |(path, _)| path);
+        let file = relative_slash_path(root, Path::new(file));
+        let local = edge.metadata_value(INCLUDE_KIND_METADATA_KEY) != Some("system");
+        let Some((relative, path, resolution)) = resolve_include_header_path(&headers, &file, &include, local) else {
+            continue;
+        };
+        included_by_file.entry(file).or_default().push(relative.to_string());
+        let language = match path.extension().and_then(|e| e.to_str()) {
+            Some("h") => "c",
+            _ => "cpp",
+        };
+        let key = format!("{}:file:{}:1-1", language, sanitize_path_for_key_format(&path.display().to_string()));
+        if edge.to_key.as_str() == key {
+            continue;
+        }
+        if let Ok(key) = Isgl1Key::new(key) {
+            edge.metadata
+                .entry(UNRESOLVED_TARGET_METADATA_KEY.to_string())
+                .or_insert_with(|| edge.to_key.as_str().to_string());
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), resolution.to_string());
+            changed += 1;
+        }
+    }
+
+    // File → function name → definitions
+    let mut functions: HashMap<String, HashMap<&str, Vec<&CodeEntity>>> = HashMap::new();
+    for entity in entities {
+        let signature = &entity.interface_signature;
+        if is_c_family_file_path(&signature.file_path)
+            && matches!(signature.entity_type, EntityType::Function | EntityType::Method)
+        {
+            functions
+                .entry(relative_slash_path(root, &signature.file_path))
+                .or_default()
+                .entry(signature.name.as_str())
+                .or_default()
+                .push(entity);
+        }
+    }
+    let entities_by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut visible_by_file: HashMap<String, BTreeSet<String>> = HashMap::new();
+    for edge in edges.iter_mut().filter(|edge| edge.edge_type == EdgeType::Calls) {
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else { continue };
+        if !is_c_family_file_path(&from.interface_signature.file_path) {
+            continue;
+        }
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+        let file = relative_slash_path(root, &from.interface_signature.file_path);
+
+        let resolved = match definitions_named_in_file(&functions, &file, name).as_slice() {
+            [only] => Some((*only, "same_file")),
+            [] => {
+                let visible = visible_by_file
+                    .entry(file.clone())
+                    .or_insert_with(|| collect_visible_include_files(&file, &included_by_file));
+                let candidates: Vec<&CodeEntity> =
+                    visible.iter().flat_map(|f| definitions_named_in_file(&functions, f, name)).collect();
+                match candidates.as_slice() {
+                    [only] => Some((*only, "included_file")),
+                    _ => None,
+                }
+            }
+            _ => None,
+        };
+        let Some((target, resolution)) = resolved else { continue };
+        if target.isgl1_key == from.isgl1_key {
+            continue;
+        }
+        if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+            edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), resolution.to_string());
+            changed += 1;
+        }
+    }
+    changed
+}
+
+/// Functions and methods called `name` defined in `file`
+fn definitions_named_in_file<'a>(
+    functions: &HashMap<String, HashMap<&str, Vec<&'a CodeEntity>>>,
+    file: &str,
+    name: &str,
+) -> Vec<&'a CodeEntity> {
+    functions.get(file).and_then(|names| names.get(name)).cloned().unwrap_or_default()
+}
+
+/// Repo header for an include from `file` (root-relative): `(relative, walked path, resolution)`
+fn resolve_include_header_path<'a>(
+    headers: &'a BTreeMap<String, PathBuf>,
+    file: &str,
+    include: &str,
+    local: bool,
+) -> Option<(&'a str, &'a PathBuf, &'static str)> {
+    if local {
+        let directory = file.rsplit_once('/').map_or("", |(directory, _)| directory);
+        let beside = normalize_lexical_slash_path(&format!("{}/{}", directory, include));
+        if let Some((relative, path)) = headers.get_key_value(&beside) {
+            return Some((relative.as_str(), path, "relative"));
+        }
+    }
+    let include = normalize_lexical_slash_path(include);
+    let suffix = format!("/{}", include);
+    headers
+        .iter()
+        .filter(|(relative, _)| **relative == include || relative.ends_with(&suffix))
+        .min_by_key(|(relative, _)| (std::cmp::Reverse(shared_directory_depth(relative, file)), relative.len()))
+        .map(|(relative, path)| (relative.as_str(), path, "search_path"))
+}
+
+/// `a/./b/../c.h` → `a/c.h`
+fn normalize_lexical_slash_path(path: &str) -> String {
+    let mut parts: Vec<&str> = Vec::new();
+    for part in path.split('/') {
+        match part {
+            "" | "." => {}
+            ".." => {
+                parts.pop();
+            }
+            part => parts.push(part),
+        }
+    }
+    parts.join("/")
+}
+
+/// Leading directories two root-relative paths share
+fn shared_directory_depth(a: &str, b: &str) -> usize {
+    let directories = |path: &str| {
+        let mut parts: Vec<String> = path.split('/').map(str::to_string).collect();
+        parts.pop();
+        parts
+    };
+    directories(a).iter().zip(directories(b).iter()).take_while(|(x, y)| x == y).count()
+}
+
+/// Files whose definitions a file sees: includes (transitively) and their implementation files
+fn collect_visible_include_files(file: &str, included_by_file: &HashMap<String, Vec<String>>) -> BTreeSet<String> {
+    let mut visible = BTreeSet::new();
+    let mut pending = vec![file.to_string()];
+    while let Some(current) = pending.pop() {
+        for header in included_by_file.get(&current).into_iter().flatten() {
+            if visible.insert(header.clone()) {
+                pending.push(header.clone());
+            }
+        }
+    }
+    let implementations: Vec<String> = visible
+        .iter()
+        .filter_map(|header| header.rsplit_once('.').map(|(stem, _)| stem))
+        .flat_map(|stem| IMPLEMENTATION_FILE_EXTENSIONS.iter().map(move |extension| format!("{}.{}", stem, extension)))
+        .collect();
+    visible.extend(implementations);
+    visible.remove(file);
+    visible
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::query_extractor::QueryBasedExtractor;
+    use crate::entities::Language;
+
+    #[test]
+    fn test_cgo_preamble_includes_parsed() {
+        let source = concat!(
+            "package native\n\n",
+            "// #cgo LDFLAGS: -lz\n",
+            "// #include <stdlib.h>\n",
+            "// #include \"native/codec.h\"\n",
+            "import \"C\"\n\n",
+            "/*\n#include \"block.h\"\n*/\n",
+            "import \"C\"\n",
+        );
+        let includes = parse_cgo_preamble_includes(source);
+        let found: Vec<(usize, &str, &str)> = includes.iter().map(|i| (i.line, i.path.as_str(), i.kind)).collect();
+        assert_eq!(
+            found,
+            vec![(4, "stdlib.h", "system"), (5, "native/codec.h", "local"), (9, "block.h", "local")]
+        );
+        assert!(parse_cgo_preamble_includes("package x\n\n// #include <stdio.h>\n\nimport \"C\"\n").is_empty());
+    }
+
+    #[test]
+    fn test_includes_and_calls_bind_to_repo_headers() {
+        let dir = tempfile::tempdir().unwrap();
+        let root = dir.path();
+        std::fs::create_dir_all(root.join("native/codec")).unwrap();
+        std::fs::write(root.join("native/codec/codec.h"), "int encode(int x);\n").unwrap();
+        let codec_path = root.join("native/codec/codec.c");
+        std::fs::write(&codec_path, "int encode(int x) { return x; }\n").unwrap();
+        let main_source = "#include <stdio.h>\n#include <codec/codec.h>\n\nint run(void) { return encode(1); }\n";
+        let main_path = root.join("native/main.c");
+        std::fs::write(&main_path, main_source).unwrap();
+        let go_path = root.join("wrap.go");
+        let go_source = "package wrap\n\n// #include \"native/codec/codec.h\"\nimport \"C\"\n\nfunc Run() {}\n";
+        std::fs::write(&go_path, go_source).unwrap();
+
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, mut edges) = extractor.parse_source(main_source, &main_path, Language::C).unwrap();
+        let (run_key, encode_key) = ("c:fn:run:__native_main:T1", "c:fn:encode:__native_codec:T2");
+        for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::Calls) {
+            edge.from_key = Isgl1Key::new(run_key.to_string()).unwrap();
+        }
+        let file = |path: &PathBuf| path.to_string_lossy().into_owned();
+        let entities = vec![
+            create_go_test_entity(run_key, "run", EntityType::Function, &file(&main_path), &[]),
+            create_go_test_entity(encode_key, "encode", EntityType::Function, &file(&codec_path), &[]),
+            create_go_test_entity("go:fn:Run:__wrap:T3", "Run", EntityType::Function, &file(&go_path), &[]),
+        ];
+
+        // cgo include added, two includes bound, one call bound
+        assert_eq!(resolve_c_include_header_edges(&entities, &mut edges, root), 4);
+        let includes: Vec<&DependencyEdge> = edges.iter().filter(|e| e.edge_type == EdgeType::Includes).collect();
+        assert_eq!(includes.len(), 3);
+        let header = |path: &str| {
+            includes.iter().find(|e| e.metadata_value(INCLUDE_PATH_METADATA_KEY) == Some(path)).copied().unwrap()
+        };
+        let stdio = header("stdio.h");
+        assert_eq!((stdio.to_key.as_str(), stdio.metadata_value("resolution")), ("c:file:stdio_h:0-0", None));
+        let codec = header("codec/codec.h");
+        assert!(codec.to_key.as_str().ends_with("native_codec_codec_h:1-1"), "{}", codec.to_key);
+        assert_eq!(codec.metadata_value("resolution"), Some("search_path"));
+        let cgo = includes.iter().find(|e| e.from_key.as_str().starts_with("go:file:")).unwrap();
+        assert_eq!(cgo.to_key, codec.to_key);
+        assert_eq!(cgo.metadata_value("resolution"), Some("relative"));
+
+        let call = edges.iter().find(|e| e.edge_type == EdgeType::Calls).unwrap();
+        assert_eq!(call.to_key.as_str(), encode_key);
+        assert_eq!(call.metadata_value("resolution"), Some("included_file"));
+        assert_eq!(call.metadata_value(UNRESOLVED_TARGET_METADATA_KEY), Some("c:fn:encode:unresolved-reference:0-0"));
+    }
+}
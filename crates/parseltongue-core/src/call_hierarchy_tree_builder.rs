@@ -0,0 +1,269 @@
+//! IDE-style call hierarchy trees (v1.7.3)
+//!
+//! # 4-Word Naming: call_hierarchy_tree_builder
+//!
+//! Incoming (callers) or outgoing (callees) calls of a symbol as a tree,
+//! the way an IDE's call hierarchy view shows them:
+//!
+//! ```text
+//! CreateUser  internal/svc/user.go:12
+//!   HandleSignup  api/signup.go:30
+//!     main  cmd/server/main.go:8
+//!   Retry  internal/svc/retry.go:40  (recursive)
+//! ```
+//!
+//! `Calls` and `Spawns` edges (`go f()`, marked `(go)`) are followed. Each
+//! related symbol appears once per parent, with every call site collected
+//! in `call_sites`. A symbol already on the path from the root is shown
+//! with `(recursive)` and not expanded again; symbols at the depth limit
+//! show how many further entries were not expanded (`… 3 more`). External
+//! callees (unresolved call targets) have no location.
+
+use std::collections::{BTreeMap, HashMap};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+
+/// Node budget per tree; wide graphs stop expanding beyond it
+pub const MAX_CALL_HIERARCHY_NODES: usize = 2000;
+
+/// Which way a call hierarchy walks
+///
+/// # 4-Word Name: CallHierarchyDirectionKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum CallHierarchyDirectionKind {
+    /// Incoming calls: who calls this
+    Callers,
+    /// Outgoing calls: what this calls
+    Callees,
+}
+
+/// One entry of a call hierarchy
+///
+/// # 4-Word Name: CallHierarchyTreeNode
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct CallHierarchyTreeNode {
+    pub entity_key: String,
+    pub name: String,
+    /// `file:line` of the definition; `None` for external symbols
+    pub location: Option<String>,
+    /// Call sites linking this entry to its parent (`file:line`)
+    pub call_sites: Vec<String>,
+    /// Reached through `go f()` rather than a plain call
+    pub spawned: bool,
+    /// Already on the path from the root; not expanded again
+    pub recursive: bool,
+    /// Related symbols not expanded (depth limit or node budget)
+    pub unexpanded_count: usize,
+    pub children: Vec<CallHierarchyTreeNode>,
+}
+
+struct HierarchyWalkContext<'a> {
+    adjacency: HashMap<&'a str, Vec<&'a DependencyEdge>>,
+    entities: HashMap<&'a str, &'a CodeEntity>,
+    direction: CallHierarchyDirectionKind,
+    max_depth: usize,
+    max_nodes: usize,
+    node_count: usize,
+}
+
+impl<'a> HierarchyWalkContext<'a> {
+    fn neighbor_of(&self, edge: &'a DependencyEdge) -> &'a str {
+        match self.direction {
+            CallHierarchyDirectionKind::Callers => edge.from_key.as_str(),
+            CallHierarchyDirectionKind::Callees => edge.to_key.as_str(),
+        }
+    }
+
+    fn leaf_node(&self, key: &str) -> CallHierarchyTreeNode {
+        let entity = self.entities.get(key);
+        CallHierarchyTreeNode {
+            entity_key: key.to_string(),
+            name: entity.map_or_else(
+                || key.split(':').nth(2).unwrap_or(key).to_string(),
+                |e| e.interface_signature.name.clone(),
+            ),
+            location: entity.map(|e| {
+                format!("{}:{}", e.interface_signature.file_path.display(), e.interface_signature.line_range.start)
+            }),
+            call_sites: Vec::new(),
+            spawned: false,
+            recursive: false,
+            unexpanded_count: 0,
+            children: Vec::new(),
+        }
+    }
+
+    /// Related symbols of `key`, grouped, in (name, key) order
+    fn grouped_neighbors(&self, key: &str) -> Vec<(&'a str, Vec<&'a DependencyEdge>)> {
+        let mut grouped: BTreeMap<(String, &'a str), Vec<&'a DependencyEdge>> = BTreeMap::new();
+        for &edge in self.adjacency.get(key).into_iter().flatten() {
+            let neighbor = self.neighbor_of(edge);
+            let name = self.leaf_node(neighbor).name;
+            grouped.entry((name, neighbor)).or_default().push(edge);
+        }
+        grouped.into_iter().map(|((_, neighbor), edges)| (neighbor, edges)).collect()
+    }
+
+    fn expand(&mut self, node: &mut CallHierarchyTreeNode, depth: usize, path: &mut Vec<String>) {
+        let neighbors = self.grouped_neighbors(&node.entity_key);
+        if depth >= self.max_depth || self.node_count >= self.max_nodes {
+            node.unexpanded_count = neighbors.len();
+            return;
+        }
+        path.push(node.entity_key.clone());
+        for (neighbor, edges) in neighbors {
+            if self.node_count >= self.max_nodes {
+                node.unexpanded_count += 1;
+                continue;
+            }
+            self.node_count += 1;
+            let mut child = self.leaf_node(neighbor);
+            child.call_sites = edges.iter().filter_map(|e| e.source_location.clone()).collect();
+            child.call_sites.sort();
+            child.call_sites.dedup();
+            child.spawned = edges.iter().all(|e| e.edge_type == EdgeType::Spawns);
+            child.recursive = path.iter().any(|k| k == neighbor);
+            if !child.recursive {
+                self.expand(&mut child, depth + 1, path);
+            }
+            node.children.push(child);
+        }
+        path.pop();
+    }
+}
+
+/// Build the call hierarchy of `root_key`
+///
+/// # 4-Word Name: build_call_hierarchy_tree
+///
+/// # Contract
+/// - Postcondition: the root is `root_key` (a leaf-only node if unknown);
+///   nodes at depth `max_depth` have no children; at most
+///   `MAX_CALL_HIERARCHY_NODES` nodes below the root
+/// - Deterministic: children ordered by name, then key
+pub fn build_call_hierarchy_tree(
+    root_key: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    direction: CallHierarchyDirectionKind,
+    max_depth: usize,
+) -> CallHierarchyTreeNode {
+    build_bounded_call_hierarchy_tree(root_key, entities, edges, direction, max_depth, MAX_CALL_HIERARCHY_NODES)
+}
+
+/// Build the call hierarchy of `root_key` with a node budget (`--max-nodes`)
+///
+/// # 4-Word Name: build_bounded_call_hierarchy_tree
+///
+/// # Contract
+/// - Postcondition: as `build_call_hierarchy_tree`, with at most
+///   `max_nodes` nodes below the root; entries beyond the budget are
+///   counted in their parent's `unexpanded_count`
+pub fn build_bounded_call_hierarchy_tree(
+    root_key: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    direction: CallHierarchyDirectionKind,
+    max_depth: usize,
+    max_nodes: usize,
+) -> CallHierarchyTreeNode {
+    let mut adjacency: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    for edge in edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)) {
+        let from = match direction {
+            CallHierarchyDirectionKind::Callers => edge.to_key.as_str(),
+            CallHierarchyDirectionKind::Callees => edge.from_key.as_str(),
+        };
+        adjacency.entry(from).or_default().push(edge);
+    }
+    let mut context = HierarchyWalkContext {
+        adjacency,
+        entities: entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect(),
+        direction,
+        max_depth,
+        max_nodes,
+        node_count: 0,
+    };
+    let mut root = context.leaf_node(root_key);
+    context.expand(&mut root, 0, &mut Vec::new());
+    root
+}
+
+/// Render a hierarchy as an indented tree, two spaces per level
+///
+/// # 4-Word Name: render_call_hierarchy_text
+pub fn render_call_hierarchy_text(root: &CallHierarchyTreeNode) -> String {
+    fn render_node(node: &CallHierarchyTreeNode, indent: usize, out: &mut String) {
+        out.push_str(&"  ".repeat(indent));
+        out.push_str(&node.name);
+        out.push_str("  ");
+        out.push_str(node.location.as_deref().unwrap_or("(external)"));
+        if node.spawned {
+            out.push_str("  (go)");
+        }
+        if node.recursive {
+            out.push_str("  (recursive)");
+        }
+        if node.unexpanded_count > 0 {
+            out.push_str(&format!("  … {} more", node.unexpanded_count));
+        }
+        out.push('\n');
+        for child in &node.children {
+            render_node(child, indent + 1, out);
+        }
+    }
+    let mut out = String::new();
+    render_node(root, 0, &mut out);
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call(from: &str, to: &str, edge_type: EdgeType, site: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .source_location(site)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_callers_tree_marks_recursion_and_depth_limit() {
+        let entities = vec![
+            create_go_test_entity("go:fn:Save:__svc:T1", "Save", EntityType::Function, "svc/save.go", &[]),
+            create_go_test_entity("go:fn:Handle:__api:T2", "Handle", EntityType::Function, "api/handle.go", &[]),
+            create_go_test_entity("go:fn:Retry:__svc:T3", "Retry", EntityType::Function, "svc/retry.go", &[]),
+            create_go_test_entity("go:fn:main:__cmd:T4", "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![
+            call("go:fn:Handle:__api:T2", "go:fn:Save:__svc:T1", EdgeType::Calls, "api/handle.go:9"),
+            call("go:fn:Handle:__api:T2", "go:fn:Save:__svc:T1", EdgeType::Calls, "api/handle.go:14"),
+            call("go:fn:Retry:__svc:T3", "go:fn:Save:__svc:T1", EdgeType::Spawns, "svc/retry.go:3"),
+            call("go:fn:Save:__svc:T1", "go:fn:Retry:__svc:T3", EdgeType::Calls, "svc/save.go:4"),
+            call("go:fn:main:__cmd:T4", "go:fn:Handle:__api:T2", EdgeType::Calls, "cmd/main.go:2"),
+            call("go:fn:Handle:__api:T2", "go:fn:Log:__fmt:0-0", EdgeType::Uses, "api/handle.go:3"),
+        ];
+
+        let tree = build_call_hierarchy_tree("go:fn:Save:__svc:T1", &entities, &edges, CallHierarchyDirectionKind::Callers, 5);
+        assert_eq!(tree.children[0].call_sites, vec!["api/handle.go:14", "api/handle.go:9"]);
+        assert_eq!(
+            render_call_hierarchy_text(&tree),
+            "Save  svc/save.go:1\n  Handle  api/handle.go:1\n    main  cmd/main.go:1\n  Retry  svc/retry.go:1  (go)\n    Save  svc/save.go:1  (recursive)\n"
+        );
+
+        let shallow = build_call_hierarchy_tree("go:fn:Save:__svc:T1", &entities, &edges, CallHierarchyDirectionKind::Callers, 1);
+        assert_eq!(shallow.children[0].unexpanded_count, 1);
+        assert!(shallow.children.iter().all(|c| c.children.is_empty()));
+
+        let callees = build_call_hierarchy_tree("go:fn:Handle:__api:T2", &entities, &edges, CallHierarchyDirectionKind::Callees, 1);
+        assert_eq!(callees.children.len(), 1, "Uses edges are not calls");
+    }
+}
@@ -0,0 +1,466 @@
+//! Canonical Symbol ID Normalizer (namespace separator normalization)
+//!
+//! # 4-Word Naming: canonical_symbol_id_normalizer
+//!
+//! Qualified names leak language-specific separators into node IDs:
+//! `crate::auth::Service::login` (Rust), `github.com/org/app/auth.Service.Login`
+//! (Go), `app.auth.Service.login` (Python). Cross-language queries cannot
+//! address these uniformly. This module defines one canonical form:
+//!
+//! ```text
+//! {language}://{module/path}#{Type::member}
+//! ```
+//!
+//! - Module segments are always joined with `/`
+//! - Symbol segments are always joined with `::`
+//! - Per-language adapters decide where the module ends and the symbol begins
+//!
+//! ## Where canonical IDs appear
+//!
+//! Nodes and edges stay keyed by ISGL1 keys; a canonical ID is an address
+//! that resolves to those keys. The daemon returns `canonical_id` on
+//! `/code-entity-detail-view` and on `/canonical-symbol-id-resolve`
+//! matches, and SCIP export derives symbol names from it. Edge endpoints
+//! are keys; resolve an ID first to walk the graph from it.
+//!
+//! ## Limitations (syntactic)
+//!
+//! - The module/symbol boundary is a naming-convention heuristic for
+//!   languages whose module and type separators coincide (`.` or `::`)
+//! - Go receiver forms `(*T).M` and `(T).M` are normalized to `T::M`
+
+use std::fmt;
+use std::path::Path;
+
+use crate::entities::{CodeEntity, EntityType};
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Entity metadata key: declared JVM package (`com.acme.auth`)
+pub const PACKAGE_NAME_METADATA_KEY: &str = "package_name";
+
+/// Separator between language scheme and module path
+pub const CANONICAL_SCHEME_SEPARATOR: &str = "://";
+
+/// Separator between module path and symbol path
+pub const CANONICAL_SYMBOL_MARKER: char = '#';
+
+/// Separator between symbol segments (`Type::method`)
+pub const CANONICAL_SYMBOL_SEPARATOR: &str = "::";
+
+/// Canonical, language-neutral symbol address
+///
+/// # 4-Word Name: CanonicalSymbolIdentifier
+#[derive(Debug, Clone, PartialEq, Eq, Hash, PartialOrd, Ord)]
+pub struct CanonicalSymbolIdentifier {
+    /// Lowercase language tag (same as the ISGL1 key prefix)
+    pub language: String,
+    /// Module path segments (`["internal", "auth"]`)
+    pub module_segments: Vec<String>,
+    /// Symbol path segments (`["Service", "Login"]`)
+    pub symbol_segments: Vec<String>,
+}
+
+impl CanonicalSymbolIdentifier {
+    /// Module path joined with `/`
+    ///
+    /// # 4-Word Name: module_path_joined_string
+    pub fn module_path_joined_string(&self) -> String {
+        self.module_segments.join("/")
+    }
+
+    /// Innermost symbol name (`Login` for `Service::Login`)
+    ///
+    /// # 4-Word Name: leaf_symbol_name_str
+    pub fn leaf_symbol_name_str(&self) -> Option<&str> {
+        self.symbol_segments.last().map(String::as_str)
+    }
+
+    /// Check whether this ID's module path ends with `other`'s module path
+    ///
+    /// # 4-Word Name: module_path_ends_with
+    ///
+    /// # Contract
+    /// - Segment-wise suffix match: `github.com/org/app/auth` ends with `app/auth`
+    ///   but not with `pp/auth`
+    /// - An empty `other` module matches everything
+    pub fn module_path_ends_with(&self, other: &CanonicalSymbolIdentifier) -> bool {
+        self.module_segments.ends_with(&other.module_segments)
+    }
+}
+
+impl fmt::Display for CanonicalSymbolIdentifier {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "{}{}{}{}{}",
+            self.language,
+            CANONICAL_SCHEME_SEPARATOR,
+            self.module_path_joined_string(),
+            CANONICAL_SYMBOL_MARKER,
+            self.symbol_segments.join(CANONICAL_SYMBOL_SEPARATOR)
+        )
+    }
+}
+
+/// Parse a canonical symbol ID string
+///
+/// # 4-Word Name: parse_canonical_symbol_identifier
+///
+/// # Contract
+/// - Precondition: `lang://module/path#Type::member` (module may be empty)
+/// - Postcondition: Some(id) with non-empty language and symbol path, else None
+pub fn parse_canonical_symbol_identifier(text: &str) -> Option<CanonicalSymbolIdentifier> {
+    let (language, rest) = text.trim().split_once(CANONICAL_SCHEME_SEPARATOR)?;
+    let (module, symbol) = rest.rsplit_once(CANONICAL_SYMBOL_MARKER)?;
+    if language.is_empty() {
+        return None;
+    }
+
+    let symbol_segments = split_non_empty_segments(symbol, CANONICAL_SYMBOL_SEPARATOR);
+    if symbol_segments.is_empty() {
+        return None;
+    }
+
+    Some(CanonicalSymbolIdentifier {
+        language: language.to_lowercase(),
+        module_segments: split_non_empty_segments(module, "/"),
+        symbol_segments,
+    })
+}
+
+/// Per-language rule for splitting qualified names
+///
+/// # 4-Word Name: NamespaceSeparatorAdapterKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum NamespaceSeparatorAdapterKind {
+    /// Rust, C, C++: `::` everywhere, lowercase leading segments are modules
+    DoubleColonPath,
+    /// Go: `/` import path, `.` between package and symbol
+    GoImportPath,
+    /// Python, Java, Kotlin, Scala, JS/TS, Swift: `.` everywhere,
+    /// lowercase leading segments are modules
+    DottedPath,
+    /// C#, Ruby: namespaces are capitalized, so only the last segment is the symbol
+    DottedNamespacePath,
+    /// PHP: `\` namespaces, `::` for static members
+    BackslashNamespacePath,
+}
+
+/// Pick the separator adapter for a language tag
+///
+/// # 4-Word Name: adapter_for_language_tag
+///
+/// # Contract
+/// - Accepts ISGL1 key prefixes (`rust`, `go`, `python`, `csharp`, ...)
+/// - Unknown languages fall back to `DottedPath`
+pub fn adapter_for_language_tag(language: &str) -> NamespaceSeparatorAdapterKind {
+    match language.to_lowercase().as_str() {
+        "rust" | "c" | "cpp" => NamespaceSeparatorAdapterKind::DoubleColonPath,
+        "go" => NamespaceSeparatorAdapterKind::GoImportPath,
+        "csharp" | "ruby" => NamespaceSeparatorAdapterKind::DottedNamespacePath,
+        "php" => NamespaceSeparatorAdapterKind::BackslashNamespacePath,
+        _ => NamespaceSeparatorAdapterKind::DottedPath,
+    }
+}
+
+impl NamespaceSeparatorAdapterKind {
+    /// Split a qualified name into (module segments, symbol segments)
+    ///
+    /// # 4-Word Name: split_qualified_name_parts
+    ///
+    /// # Contract
+    /// - Postcondition: symbol segments non-empty for any non-empty input
+    /// - Rust `crate`/`self`/`super` and C# `global::` prefixes are dropped
+    pub fn split_qualified_name_parts(&self, qualified: &str) -> (Vec<String>, Vec<String>) {
+        let qualified = qualified.trim();
+        match self {
+            NamespaceSeparatorAdapterKind::DoubleColonPath => {
+                let segments: Vec<String> = split_non_empty_segments(qualified, "::")
+                    .into_iter()
+                    .filter(|s| !matches!(s.as_str(), "crate" | "self" | "super" | "$crate"))
+                    .collect();
+                split_at_first_capitalized_segment(segments)
+            }
+            NamespaceSeparatorAdapterKind::GoImportPath => split_go_qualified_name(qualified),
+            NamespaceSeparatorAdapterKind::DottedPath => {
+                split_at_first_capitalized_segment(split_non_empty_segments(qualified, "."))
+            }
+            NamespaceSeparatorAdapterKind::DottedNamespacePath => {
+                let without_global = qualified.strip_prefix("global::").unwrap_or(qualified);
+                let mut segments: Vec<String> = without_global
+                    .split(|c: char| c == '.' || c == ':')
+                    .filter(|s| !s.is_empty())
+                    .map(str::to_string)
+                    .collect();
+                let symbol = segments.pop().into_iter().collect();
+                (segments, symbol)
+            }
+            NamespaceSeparatorAdapterKind::BackslashNamespacePath => {
+                let (namespace, member) = match qualified.split_once("::") {
+                    Some((ns, member)) => (ns, Some(member)),
+                    None => (qualified, None),
+                };
+                let mut segments = split_non_empty_segments(namespace, "\\");
+                let mut symbol: Vec<String> = segments.pop().into_iter().collect();
+                symbol.extend(member.map(|m| split_non_empty_segments(m, "::")).unwrap_or_default());
+                (segments, symbol)
+            }
+        }
+    }
+}
+
+/// Normalize a language-specific qualified name to a canonical ID
+///
+/// # 4-Word Name: canonicalize_qualified_symbol_name
+///
+/// # Contract
+/// - Precondition: `qualified` is a reference as written in source
+///   (`std::collections::HashMap::new`, `fmt.Println`, `os.path.join`)
+/// - Postcondition: Some(id) with the adapter's module/symbol split; None if empty
+pub fn canonicalize_qualified_symbol_name(
+    language: &str,
+    qualified: &str,
+) -> Option<CanonicalSymbolIdentifier> {
+    let (module_segments, symbol_segments) =
+        adapter_for_language_tag(language).split_qualified_name_parts(qualified);
+    if symbol_segments.is_empty() {
+        return None;
+    }
+
+    Some(CanonicalSymbolIdentifier {
+        language: language.to_lowercase(),
+        module_segments,
+        symbol_segments,
+    })
+}
+
+/// Derive the canonical ID of an ingested entity
+///
+/// # 4-Word Name: canonical_id_for_code_entity
+///
+/// # Contract
+/// - Language: ISGL1 key prefix
+/// - Module: Java/Kotlin → declared `package_name` (`com.acme.auth` →
+///   `com/acme/auth`), so a symbol keeps its ID when files move; Go → package
+///   directory; others → file path without extension (`mod.rs`, `lib.rs`,
+///   `__init__.py`, `index.{js,ts}` collapse to their directory)
+/// - Symbol: `[receiver_type, name]` for methods whose extractor recorded the
+///   enclosing type (Go, Java, Kotlin, Python, JS/TS), `[name]` otherwise
+///   (the parent type is never guessed when the extractor did not record it)
+pub fn canonical_id_for_code_entity(entity: &CodeEntity) -> CanonicalSymbolIdentifier {
+    let language = entity
+        .isgl1_key
+        .split(':')
+        .next()
+        .unwrap_or_default()
+        .to_lowercase();
+    let signature = &entity.interface_signature;
+
+    let mut symbol_segments = Vec::new();
+    if signature.entity_type == EntityType::Method {
+        if let Some(receiver) = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) {
+            symbol_segments.push(receiver.clone());
+        }
+    }
+    symbol_segments.push(signature.name.clone());
+
+    let declared_package = entity
+        .metadata
+        .additional
+        .get(PACKAGE_NAME_METADATA_KEY)
+        .filter(|_| matches!(language.as_str(), "java" | "kotlin"));
+    let module_segments = match declared_package {
+        Some(package) => split_non_empty_segments(package, "."),
+        None => module_segments_from_file_path(&language, &signature.file_path),
+    };
+
+    CanonicalSymbolIdentifier {
+        module_segments,
+        language,
+        symbol_segments,
+    }
+}
+
+/// Derive module path segments from a source file path
+///
+/// # 4-Word Name: module_segments_from_file_path
+///
+/// # Contract
+/// - Go: package directory (`internal/auth/service.go` → `internal/auth`)
+/// - Others: path without extension; directory-index files collapse to the directory
+/// - Leading `./` and Windows separators are normalized away
+pub fn module_segments_from_file_path(language: &str, file_path: &Path) -> Vec<String> {
+    let normalized = file_path.to_string_lossy().replace('\\', "/");
+    let mut segments = split_non_empty_segments(&normalized, "/");
+    segments.retain(|s| s != ".");
+
+    let Some(file_name) = segments.pop() else {
+        return segments;
+    };
+    if language == "go" {
+        return segments;
+    }
+
+    let stem = match file_name.rfind('.') {
+        Some(pos) if pos > 0 => file_name[..pos].to_string(),
+        _ => file_name,
+    };
+    if !matches!(stem.as_str(), "mod" | "lib" | "__init__" | "index") {
+        segments.push(stem);
+    }
+    segments
+}
+
+/// Split Go references: `(*T).M`, `pkg.Func`, `github.com/x/pkg.T.M`
+///
+/// # 4-Word Name: split_go_qualified_name
+fn split_go_qualified_name(qualified: &str) -> (Vec<String>, Vec<String>) {
+    // (*T).M / (T).M / (*pkg.T).M → drop the receiver parens and pointer star
+    let unwrapped: String = qualified.chars().filter(|c| !matches!(c, '(' | ')' | '*')).collect();
+
+    let (import_dir, tail) = match unwrapped.rfind('/') {
+        Some(pos) => (&unwrapped[..pos], &unwrapped[pos + 1..]),
+        None => ("", unwrapped.as_str()),
+    };
+    let mut module_segments = split_non_empty_segments(import_dir, "/");
+    let mut tail_segments = split_non_empty_segments(tail, ".");
+
+    // Package names are lowercase by convention; a capitalized head is a type
+    let head_is_package = tail_segments.len() > 1
+        && tail_segments[0].chars().next().is_some_and(|c| !c.is_uppercase());
+    if (head_is_package || !module_segments.is_empty()) && tail_segments.len() > 1 {
+        module_segments.push(tail_segments.remove(0));
+    }
+    (module_segments, tail_segments)
+}
+
+/// Leading lowercase segments are modules, the rest is the symbol
+///
+/// # 4-Word Name: split_at_first_capitalized_segment
+///
+/// Falls back to "last segment is the symbol" when nothing is capitalized
+/// (`os::path::join`, `os.path.join`).
+fn split_at_first_capitalized_segment(mut segments: Vec<String>) -> (Vec<String>, Vec<String>) {
+    let boundary = segments
+        .iter()
+        .position(|s| s.chars().next().is_some_and(char::is_uppercase))
+        .unwrap_or_else(|| segments.len().saturating_sub(1));
+    let symbol = segments.split_off(boundary);
+    (segments, symbol)
+}
+
+/// Split on a separator, dropping empty segments
+///
+/// # 4-Word Name: split_non_empty_segments
+fn split_non_empty_segments(text: &str, separator: &str) -> Vec<String> {
+    text.split(separator)
+        .map(str::trim)
+        .filter(|s| !s.is_empty())
+        .map(str::to_string)
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn canonical(language: &str, qualified: &str) -> String {
+        canonicalize_qualified_symbol_name(language, qualified)
+            .map(|id| id.to_string())
+            .unwrap_or_default()
+    }
+
+    #[test]
+    fn test_rust_paths_normalize_to_canonical_form() {
+        assert_eq!(canonical("rust", "crate::auth::Service::login"), "rust://auth#Service::login");
+        assert_eq!(
+            canonical("rust", "std::collections::HashMap::new"),
+            "rust://std/collections#HashMap::new"
+        );
+        assert_eq!(canonical("rust", "super::helpers::parse"), "rust://helpers#parse");
+    }
+
+    #[test]
+    fn test_go_paths_normalize_to_canonical_form() {
+        assert_eq!(canonical("go", "fmt.Println"), "go://fmt#Println");
+        assert_eq!(
+            canonical("go", "github.com/org/app/auth.Service.Login"),
+            "go://github.com/org/app/auth#Service::Login"
+        );
+        assert_eq!(canonical("go", "(*Service).Login"), "go://#Service::Login");
+        assert_eq!(canonical("go", "(*auth.Service).Login"), "go://auth#Service::Login");
+    }
+
+    #[test]
+    fn test_dotted_and_namespace_languages_normalize() {
+        assert_eq!(canonical("python", "app.auth.Service.login"), "python://app/auth#Service::login");
+        assert_eq!(canonical("python", "os.path.join"), "python://os/path#join");
+        assert_eq!(
+            canonical("csharp", "global::System.Console.WriteLine"),
+            "csharp://System/Console#WriteLine"
+        );
+        assert_eq!(canonical("php", "\\App\\Models\\User::find"), "php://App/Models#User::find");
+    }
+
+    #[test]
+    fn test_same_symbol_across_languages_shares_symbol_path() {
+        let rust = canonicalize_qualified_symbol_name("rust", "auth::Service::login").unwrap();
+        let go = canonicalize_qualified_symbol_name("go", "auth.Service.login").unwrap();
+        let python = canonicalize_qualified_symbol_name("python", "auth.Service.login").unwrap();
+
+        assert_eq!(rust.module_segments, go.module_segments);
+        assert_eq!(go.module_segments, python.module_segments);
+        assert_eq!(rust.symbol_segments, python.symbol_segments);
+    }
+
+    #[test]
+    fn test_parse_roundtrip_and_rejects_malformed() {
+        let id = parse_canonical_symbol_identifier("go://internal/auth#Service::Login").unwrap();
+        assert_eq!(id.module_segments, vec!["internal", "auth"]);
+        assert_eq!(id.symbol_segments, vec!["Service", "Login"]);
+        assert_eq!(id.to_string(), "go://internal/auth#Service::Login");
+
+        assert!(parse_canonical_symbol_identifier("go://#Main").is_some());
+        assert!(parse_canonical_symbol_identifier("internal/auth#Login").is_none());
+        assert!(parse_canonical_symbol_identifier("go://internal/auth#").is_none());
+    }
+
+    #[test]
+    fn test_module_suffix_match_is_segment_wise() {
+        let full = canonicalize_qualified_symbol_name("go", "github.com/org/app/auth.Login").unwrap();
+        let short = parse_canonical_symbol_identifier("go://app/auth#Login").unwrap();
+        let partial = parse_canonical_symbol_identifier("go://pp/auth#Login").unwrap();
+
+        assert!(full.module_path_ends_with(&short));
+        assert!(!full.module_path_ends_with(&partial));
+    }
+
+    #[test]
+    fn test_entity_canonical_id_uses_package_and_receiver() {
+        let method = create_go_test_entity("go:method:Login:__internal_auth_service:T1", "Login",
+            EntityType::Method, "internal/auth/service.go", &[(RECEIVER_TYPE_METADATA_KEY, "Service")]);
+        assert_eq!(canonical_id_for_code_entity(&method).to_string(), "go://internal/auth#Service::Login");
+
+        assert_eq!(
+            module_segments_from_file_path("rust", Path::new("./src/auth/mod.rs")),
+            vec!["src", "auth"]
+        );
+        assert_eq!(
+            module_segments_from_file_path("python", Path::new("app/auth/views.py")),
+            vec!["app", "auth", "views"]
+        );
+    }
+
+    #[test]
+    fn test_jvm_entity_canonical_id_uses_declared_package() {
+        let method = create_go_test_entity("java:method:login:__src_main_java_com_acme_auth_UserService:T1", "login",
+            EntityType::Method, "src/main/java/com/acme/auth/UserService.java",
+            &[(RECEIVER_TYPE_METADATA_KEY, "UserService"), (PACKAGE_NAME_METADATA_KEY, "com.acme.auth")]);
+        assert_eq!(canonical_id_for_code_entity(&method).to_string(), "java://com/acme/auth#UserService::login");
+
+        let class = create_go_test_entity("kotlin:class:UserService:__app_src_UserService:T2", "UserService",
+            EntityType::Class, "app/src/UserService.kt", &[(PACKAGE_NAME_METADATA_KEY, "com.acme.auth")]);
+        assert_eq!(canonical_id_for_code_entity(&class).to_string(), "kotlin://com/acme/auth#UserService");
+    }
+}
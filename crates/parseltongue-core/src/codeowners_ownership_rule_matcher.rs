@@ -0,0 +1,307 @@
+//! CODEOWNERS ownership rules (v1.7.3)
+//!
+//! # 4-Word Naming: codeowners_ownership_rule_matcher
+//!
+//! Attaches owner teams from a `CODEOWNERS` file to entities, as
+//! `code_owners` metadata (comma-separated handles, `@acme/payments,@ada`),
+//! so context and review packs can point out the symbols a change touches
+//! that belong to someone else's team.
+//!
+//! ## Discovery
+//!
+//! The ingest root and its ancestors are searched, nearest first, for
+//! `.github/CODEOWNERS`, `CODEOWNERS` and `docs/CODEOWNERS` (GitHub's
+//! order). The search stops at the first directory holding `.git`; the
+//! directory the file belongs to is the root its patterns are relative to.
+//!
+//! ## Matching
+//!
+//! GitHub's rules, on top of `matches_path_glob_pattern`:
+//!
+//! - the last matching line wins; a line without owners leaves the path
+//!   unowned
+//! - a pattern with a `/` other than a trailing one is anchored to the
+//!   root, otherwise it matches a name at any depth
+//! - a pattern matching a directory owns everything below it; `dir/` only
+//!   matches directories and `dir/*` only the files directly inside
+//!
+//! ## Teams
+//!
+//! A team is one or more handles (`--team @acme/payments`). Without one,
+//! the team is inferred as the owner of most changed symbols. A symbol is
+//! outside the team's ownership when it has code owners and shares none
+//! with the team; handles compare case-insensitively, like GitHub's.
+
+use std::collections::BTreeMap;
+use std::path::{Component, Path, PathBuf};
+
+use crate::entities::CodeEntity;
+use crate::serializers::graph_export_node_table::matches_path_glob_pattern;
+
+/// Owner handles of the entity's file, comma-separated
+pub const CODE_OWNERS_METADATA_KEY: &str = "code_owners";
+
+/// Where a CODEOWNERS file may live, relative to a repository root
+pub const CODEOWNERS_FILE_LOCATION_LIST: [&str; 3] = [".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"];
+
+/// One rule line of a CODEOWNERS file
+///
+/// # 4-Word Name: CodeownersRuleLineEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct CodeownersRuleLineEntry {
+    /// Glob with trailing and leading `/` removed
+    pub pattern: String,
+    /// Matched against the path from the root
+    pub anchored: bool,
+    pub directory_only: bool,
+    /// Empty: the path is explicitly unowned
+    pub owners: Vec<String>,
+}
+
+/// Parse CODEOWNERS text into rule lines
+///
+/// # 4-Word Name: parse_codeowners_rule_lines
+///
+/// # Contract
+/// - Postcondition: rules in file order; comments (whole-line and trailing
+///   `# ...`) and blank lines dropped
+pub fn parse_codeowners_rule_lines(text: &str) -> Vec<CodeownersRuleLineEntry> {
+    text.lines()
+        .filter_map(|line| {
+            let mut words = line.split_whitespace().take_while(|word| !word.starts_with('#'));
+            let first = words.next()?;
+            let raw = first.strip_prefix('\\').unwrap_or(first);
+            let owners: Vec<String> = words.map(str::to_string).collect();
+            let directory_only = raw.ends_with('/');
+            let trimmed = raw.trim_end_matches('/');
+            let anchored = trimmed.contains('/');
+            let pattern = trimmed.trim_start_matches('/');
+            if pattern.is_empty() {
+                return None;
+            }
+            Some(CodeownersRuleLineEntry {
+                pattern: pattern.to_string(),
+                anchored,
+                directory_only,
+                owners,
+            })
+        })
+        .collect()
+}
+
+/// Rules of one CODEOWNERS file and the root they apply to
+///
+/// # 4-Word Name: CodeownersOwnershipRuleSet
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct CodeownersOwnershipRuleSet {
+    /// Directory the patterns are relative to
+    pub root_dir: PathBuf,
+    /// CODEOWNERS file the rules came from
+    pub source_file: PathBuf,
+    pub rules: Vec<CodeownersRuleLineEntry>,
+}
+
+impl CodeownersOwnershipRuleSet {
+    /// Find the CODEOWNERS file governing `ingest_root`
+    ///
+    /// # 4-Word Name: discover_for_ingest_root
+    ///
+    /// # Contract
+    /// - Postcondition: `None` when no CODEOWNERS file exists up to the
+    ///   repository root (the first ancestor holding `.git`)
+    pub fn discover_for_ingest_root(ingest_root: &Path) -> Option<Self> {
+        let start = std::fs::canonicalize(ingest_root).unwrap_or_else(|_| ingest_root.to_path_buf());
+        for directory in start.ancestors() {
+            for location in CODEOWNERS_FILE_LOCATION_LIST {
+                let candidate = directory.join(location);
+                if let Ok(text) = std::fs::read_to_string(&candidate) {
+                    return Some(Self {
+                        root_dir: directory.to_path_buf(),
+                        source_file: candidate,
+                        rules: parse_codeowners_rule_lines(&text),
+                    });
+                }
+            }
+            if directory.join(".git").exists() {
+                break;
+            }
+        }
+        None
+    }
+
+    /// Owners of a path relative to the root (`internal/billing/pay.go`)
+    ///
+    /// # 4-Word Name: owners_for_relative_path
+    ///
+    /// # Contract
+    /// - Postcondition: the owners of the last matching rule; `None` when no
+    ///   rule matches or the last match has no owners
+    pub fn owners_for_relative_path(&self, relative: &str) -> Option<&[String]> {
+        let components: Vec<&str> = relative
+            .trim_start_matches("./")
+            .split('/')
+            .filter(|part| !part.is_empty() && *part != ".")
+            .collect();
+        if components.is_empty() {
+            return None;
+        }
+        let rule = self.rules.iter().rev().find(|rule| {
+            let whole_file_only = rule.pattern.ends_with("/*");
+            (1..=components.len()).any(|depth| {
+                let is_file = depth == components.len();
+                if (rule.directory_only && is_file) || (whole_file_only && !is_file) {
+                    return false;
+                }
+                let target = if rule.anchored {
+                    components[..depth].join("/")
+                } else {
+                    components[depth - 1].to_string()
+                };
+                matches_path_glob_pattern(&target, &format!("/{}", rule.pattern))
+            })
+        })?;
+        (!rule.owners.is_empty()).then_some(rule.owners.as_slice())
+    }
+
+    /// Owners of a file path as stored on an entity
+    ///
+    /// # 4-Word Name: owners_for_file_path
+    ///
+    /// # Contract
+    /// - Postcondition: relative paths are resolved against the current
+    ///   directory; `None` for files outside the root
+    pub fn owners_for_file_path(&self, file_path: &Path) -> Option<&[String]> {
+        let absolute = std::fs::canonicalize(file_path).unwrap_or_else(|_| file_path.to_path_buf());
+        let relative = absolute.strip_prefix(&self.root_dir).ok()?;
+        let joined: Vec<String> = relative
+            .components()
+            .filter_map(|component| match component {
+                Component::Normal(name) => Some(name.to_string_lossy().into_owned()),
+                _ => None,
+            })
+            .collect();
+        self.owners_for_relative_path(&joined.join("/"))
+    }
+}
+
+/// Add `code_owners` metadata from the CODEOWNERS file above `ingest_root`
+///
+/// # 4-Word Name: annotate_entities_with_codeowners
+///
+/// # Contract
+/// - Postcondition: owned entities carry `code_owners`; others lose any
+///   stale entry; returns how many were annotated (0 without a CODEOWNERS
+///   file, in which case entities are untouched)
+pub fn annotate_entities_with_codeowners(entities: &mut [CodeEntity], ingest_root: &Path) -> usize {
+    let Some(rule_set) = CodeownersOwnershipRuleSet::discover_for_ingest_root(ingest_root) else {
+        return 0;
+    };
+    let mut owners_by_file: BTreeMap<PathBuf, Option<String>> = BTreeMap::new();
+    let mut annotated = 0;
+    for entity in entities.iter_mut() {
+        let file = &entity.interface_signature.file_path;
+        let owners = owners_by_file
+            .entry(file.clone())
+            .or_insert_with(|| rule_set.owners_for_file_path(file).map(|owners| owners.join(",")))
+            .clone();
+        match owners {
+            Some(owners) => {
+                entity.metadata.additional.insert(CODE_OWNERS_METADATA_KEY.to_string(), owners);
+                annotated += 1;
+            }
+            None => {
+                entity.metadata.additional.remove(CODE_OWNERS_METADATA_KEY);
+            }
+        }
+    }
+    annotated
+}
+
+/// Split a `code_owners` entry or `--team` value into handles
+///
+/// # 4-Word Name: split_code_owner_handles
+pub fn split_code_owner_handles(value: &str) -> Vec<&str> {
+    value
+        .split(|c: char| c == ',' || c.is_whitespace())
+        .filter(|handle| !handle.is_empty())
+        .collect()
+}
+
+/// Infer a team as the owner of most changed symbols
+///
+/// # 4-Word Name: infer_team_from_owner_lists
+///
+/// # Contract
+/// - Precondition: one `code_owners` value per changed symbol
+/// - Postcondition: the handle listed by most values (ties: name order);
+///   `None` when no value names an owner
+pub fn infer_team_from_owner_lists<'a>(owner_lists: impl IntoIterator<Item = &'a str>) -> Option<String> {
+    let mut counts: BTreeMap<String, usize> = BTreeMap::new();
+    for list in owner_lists {
+        for handle in split_code_owner_handles(list) {
+            *counts.entry(handle.to_ascii_lowercase()).or_default() += 1;
+        }
+    }
+    let best = counts.values().copied().max()?;
+    counts.into_iter().find(|(_, count)| *count == best).map(|(handle, _)| handle)
+}
+
+/// Whether a symbol's code owners exclude every handle of `team`
+///
+/// # 4-Word Name: is_outside_team_ownership
+///
+/// # Contract
+/// - Postcondition: false for unowned symbols (no owners is not "someone
+///   else's") and for an empty team
+pub fn is_outside_team_ownership(code_owners: &str, team: &str) -> bool {
+    let team = split_code_owner_handles(team);
+    let owners = split_code_owner_handles(code_owners);
+    !team.is_empty()
+        && !owners.is_empty()
+        && !owners.iter().any(|owner| team.iter().any(|member| member.eq_ignore_ascii_case(owner)))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn rule_set(text: &str) -> CodeownersOwnershipRuleSet {
+        CodeownersOwnershipRuleSet {
+            root_dir: PathBuf::from("/repo"),
+            source_file: PathBuf::from("/repo/.github/CODEOWNERS"),
+            rules: parse_codeowners_rule_lines(text),
+        }
+    }
+
+    #[test]
+    fn test_last_matching_rule_owns_path() {
+        let rules = rule_set(
+            "# default\n* @acme/core\n*.md @acme/docs # prose\n/internal/billing/ @acme/payments @ada\napps/ @acme/apps\ndocs/* @acme/docs\n/internal/billing/generated\n",
+        );
+        let owners = |path: &str| rules.owners_for_relative_path(path).map(|o| o.join(","));
+        assert_eq!(owners("main.go").as_deref(), Some("@acme/core"));
+        assert_eq!(owners("svc/README.md").as_deref(), Some("@acme/docs"));
+        assert_eq!(owners("internal/billing/pay/charge.go").as_deref(), Some("@acme/payments,@ada"));
+        assert_eq!(owners("web/apps/ui/app.ts").as_deref(), Some("@acme/apps"));
+        // `docs/*` owns direct children only; deeper files fall back to `*`
+        assert_eq!(owners("docs/setup.txt").as_deref(), Some("@acme/docs"));
+        assert_eq!(owners("docs/api/v1.txt").as_deref(), Some("@acme/core"));
+        // A rule without owners unowns the path
+        assert_eq!(owners("internal/billing/generated/pb.go"), None);
+        assert_eq!(rules.owners_for_file_path(Path::new("/repo/internal/billing/x.go")).map(|o| o.len()), Some(2));
+        assert_eq!(rules.owners_for_file_path(Path::new("/elsewhere/x.go")), None);
+    }
+
+    #[test]
+    fn test_team_inference_and_outside_check() {
+        let changed = ["@acme/payments,@ada", "@Acme/Payments", "@acme/core"];
+        assert_eq!(infer_team_from_owner_lists(changed).as_deref(), Some("@acme/payments"));
+        assert_eq!(infer_team_from_owner_lists(Vec::<&str>::new()), None);
+
+        assert!(is_outside_team_ownership("@acme/core", "@acme/payments"));
+        assert!(!is_outside_team_ownership("@acme/core,@ACME/PAYMENTS", "@acme/payments"));
+        assert!(!is_outside_team_ownership("@acme/core", "@ada, @acme/core"));
+        assert!(!is_outside_team_ownership("", "@acme/payments"));
+        assert!(!is_outside_team_ownership("@acme/core", ""));
+    }
+}
@@ -0,0 +1,163 @@
+//! Colliding Symbol Key Disambiguator (v1.7.3)
+//!
+//! # 4-Word Naming: colliding_symbol_key_disambiguator
+//!
+//! ISGL1 v2 keys hash `file path + name`, so two symbols of the same kind
+//! and name in one file — Java/C#/C++ overloads, Go methods with the same
+//! name on different receivers, repeated `func init()` — produce one key and
+//! the last one written wins. Which one that is depends on insertion
+//! order, so the surviving node (and every edge pointing at it) could change
+//! between runs.
+//!
+//! Every member of a colliding group is re-keyed from the shared key plus
+//! its declaration signature hash:
+//!
+//! ```text
+//! java:method:save:__src_Store:T1702001234              (both overloads)
+//! java:method:save:__src_Store:T1650443210              save(User u)
+//! java:method:save:__src_Store:T1871190007              save(User u, boolean flush)
+//! ```
+//!
+//! Symbols with identical signatures (two `func init()`) additionally hash
+//! their ordinal among those twins, in source order. The result depends only
+//! on the file's contents, never on traversal order or parallelism. Edges
+//! leaving a re-keyed symbol are re-pointed by their source line.
+
+use std::collections::BTreeMap;
+
+use crate::entities::{DependencyEdge, Isgl1Key};
+use crate::isgl1_v2::stable_birth_timestamp_from_parts;
+use crate::symbol_doc_signature_extractor::extract_symbol_signature_line;
+
+/// Re-key symbols of one file that share an ISGL1 key
+///
+/// # 4-Word Name: disambiguate_colliding_symbol_keys
+///
+/// # Contract
+/// - Precondition: `keys[i]` is the generated key of the symbol spanning
+///   `line_ranges[i]` (1-based, inclusive) in `source`; `edges` are the
+///   file's extracted edges
+/// - Postcondition: keys are unique unless a group shares signature *and*
+///   position; unique keys are untouched
+/// - Postcondition: an edge whose `from_key` was a colliding key and whose
+///   source line lies in a member's range now starts at that member
+///   (innermost range wins), otherwise at the first member in source order
+pub fn disambiguate_colliding_symbol_keys(
+    keys: &mut [String],
+    line_ranges: &[(usize, usize)],
+    source: &str,
+    edges: &mut [DependencyEdge],
+) {
+    debug_assert_eq!(keys.len(), line_ranges.len());
+    let mut groups: BTreeMap<String, Vec<usize>> = BTreeMap::new();
+    for (index, key) in keys.iter().enumerate() {
+        groups.entry(key.clone()).or_default().push(index);
+    }
+    groups.retain(|_, members| members.len() > 1);
+    if groups.is_empty() {
+        return;
+    }
+
+    let lines: Vec<&str> = source.lines().collect();
+    let mut rekeyed: BTreeMap<String, Vec<((usize, usize), String)>> = BTreeMap::new();
+    for (base_key, mut members) in groups {
+        members.sort_by_key(|&index| (line_ranges[index].0, line_ranges[index].1));
+        let mut twins_seen: BTreeMap<String, usize> = BTreeMap::new();
+        for index in members {
+            let signature = signature_of_line_range(&lines, line_ranges[index]);
+            let ordinal = twins_seen.entry(signature.clone()).or_insert(0);
+            let birth = if *ordinal == 0 {
+                stable_birth_timestamp_from_parts(&[base_key.as_str(), signature.as_str()])
+            } else {
+                stable_birth_timestamp_from_parts(&[base_key.as_str(), signature.as_str(), ordinal.to_string().as_str()])
+            };
+            *ordinal += 1;
+
+            let new_key = replace_key_birth_component(&base_key, birth);
+            keys[index] = new_key.clone();
+            rekeyed.entry(base_key.clone()).or_default().push((line_ranges[index], new_key));
+        }
+    }
+
+    for edge in edges.iter_mut() {
+        let Some(members) = rekeyed.get(edge.from_key.as_str()) else { continue };
+        let line = edge
+            .source_location
+            .as_deref()
+            .and_then(|location| location.rsplit(':').next())
+            .and_then(|line| line.parse::<usize>().ok());
+        let owner = line
+            .and_then(|line| {
+                members
+                    .iter()
+                    .filter(|((start, end), _)| *start <= line && line <= *end)
+                    .min_by_key(|((start, end), _)| end - start)
+            })
+            .unwrap_or(&members[0]);
+        edge.from_key = Isgl1Key::new_unchecked(owner.1.clone());
+    }
+}
+
+/// Signature of the declaration spanning `(start, end)`
+fn signature_of_line_range(lines: &[&str], (start, end): (usize, usize)) -> String {
+    let start = start.max(1) - 1;
+    let end = end.min(lines.len());
+    if start >= end {
+        return String::new();
+    }
+    extract_symbol_signature_line(&lines[start..end].join("\n"))
+}
+
+/// `...:T{old}` with the birth component replaced (appended if absent)
+fn replace_key_birth_component(key: &str, birth: i64) -> String {
+    match key.rsplit_once(":T") {
+        Some((prefix, old)) if old.chars().all(|c| c.is_ascii_digit()) => format!("{}:T{}", prefix, birth),
+        _ => format!("{}:T{}", key, birth),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+
+    #[test]
+    fn test_overloads_get_signature_keys_and_own_edges() {
+        let source = "class Store {\n  void save(User u) {\n    write(u);\n  }\n  void save(User u, boolean flush) {\n    write(u);\n    sync();\n  }\n  void load() {}\n}\n";
+        let base = "java:method:save:__src_Store:T1702001234".to_string();
+        let mut keys = vec![base.clone(), base.clone(), "java:method:load:__src_Store:T1600000000".to_string()];
+        let ranges = [(2, 4), (5, 8), (9, 9)];
+        let call = |line: u32| {
+            DependencyEdge::builder()
+                .from_key(base.clone())
+                .to_key("java:fn:write:unresolved-reference:0-0")
+                .edge_type(EdgeType::Calls)
+                .source_location(format!("src/Store.java:{}", line))
+                .build()
+                .unwrap()
+        };
+        let mut edges = vec![call(3), call(7)];
+
+        disambiguate_colliding_symbol_keys(&mut keys, &ranges, source, &mut edges);
+
+        assert_ne!(keys[0], keys[1]);
+        assert!(keys[0].starts_with("java:method:save:__src_Store:T") && keys[0] != base);
+        assert_eq!(keys[2], "java:method:load:__src_Store:T1600000000");
+        assert_eq!(edges[0].from_key.as_str(), keys[0]);
+        assert_eq!(edges[1].from_key.as_str(), keys[1]);
+
+        // Independent of declaration order: reversed input, same keys
+        let mut reversed = vec![base.clone(), base.clone()];
+        disambiguate_colliding_symbol_keys(&mut reversed, &[(5, 8), (2, 4)], source, &mut []);
+        assert_eq!(reversed, vec![keys[1].clone(), keys[0].clone()]);
+    }
+
+    #[test]
+    fn test_identical_signatures_are_split_by_ordinal() {
+        let source = "package main\nfunc init() {}\nfunc init() {}\n";
+        let base = "go:fn:init:__main:T1700000000".to_string();
+        let mut keys = vec![base.clone(), base];
+        disambiguate_colliding_symbol_keys(&mut keys, &[(2, 2), (3, 3)], source, &mut []);
+        assert_ne!(keys[0], keys[1]);
+    }
+}
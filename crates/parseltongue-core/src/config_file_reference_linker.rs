@@ -0,0 +1,684 @@
+//! Config File Reference Linker (v1.7.3)
+//!
+//! # 4-Word Naming: config_file_reference_linker
+//!
+//! A feature flag check reads `os.Getenv("FEATURE_NEW_CHECKOUT")` or
+//! `cfg.Bool("features.new_checkout")`, but what sets the value lives in a
+//! Kubernetes manifest, Helm values, a CI workflow or an application config
+//! file the code graph never sees. This cross-file pass reads the YAML and
+//! JSON files under the ingest root and adds a `ConfiguredBy` edge from the
+//! code to each setting it depends on, so "what config controls this check"
+//! is a forward walk and "what does this setting affect" a reverse one.
+//!
+//! ## References (`config_kind`)
+//!
+//! - `env`: a variable set under an `env:` / `environment:` section (k8s
+//!   `- name: X`, compose `X: v` or `- X=v`, workflow `env:` maps) and read
+//!   in code through `os.Getenv`, `env::var`, `os.environ`, `process.env`,
+//!   `System.getenv`, `ENV[...]`, `getenv` or an `env:"X"` struct tag
+//! - `key`: a scalar setting whose dotted path (`features.new_checkout`) is a
+//!   string literal in code; under a `features` / `flags` / `toggles`
+//!   section the leaf name (`new_checkout`) is enough
+//! - `handler`: a `handler:` value (`src/users.create`, `main.HandleRequest`)
+//!   naming a function; several of that name are narrowed by the module part
+//! - `binary`: a `command` / `args` / `entrypoint` / `run` word naming a
+//!   binary of the repo, the directory of a Go `main` (`./cmd/api` → `api`)
+//!   or a Rust crate / `src/bin` target; the edge starts at its `main`
+//!
+//! ## Nodes
+//!
+//! Each referenced setting becomes one `yaml:var:` / `json:var:` node per
+//! file, at its first line, with `config_kind`, `config_path`
+//! (`spec.template.spec.containers[].env[].name`) and, when set inline,
+//! `config_value`. Settings no code refers to get no node. Lock files and
+//! files over 512 KiB are skipped. The YAML scanner is line-based: anchors,
+//! flow mappings and Helm template logic are not evaluated. Test code is
+//! not linked.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::path::Path;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_test_coverage_linker::{TEST_FACET_METADATA_KEY, TEST_FACET_METADATA_VALUE};
+use crate::go_typed_selector_resolver::relative_slash_path;
+use crate::protobuf_service_definition_linker::{build_contract_file_entity, find_contract_files_by_extension};
+
+/// Entity/edge metadata key: `env`, `key`, `handler` or `binary`
+pub const CONFIG_KIND_METADATA_KEY: &str = "config_kind";
+
+/// Entity metadata key: where the setting sits in its file (`env[].name`)
+pub const CONFIG_PATH_METADATA_KEY: &str = "config_path";
+
+/// Entity metadata key: the value a config file gives the setting
+pub const CONFIG_VALUE_METADATA_KEY: &str = "config_value";
+
+/// `resolution` value of `ConfiguredBy` edges
+pub const CONFIG_REFERENCE_RESOLUTION_VALUE: &str = "config_reference";
+
+const CONFIG_FILE_EXTENSIONS: [&str; 3] = [".yaml", ".yml", ".json"];
+
+const MAX_CONFIG_FILE_BYTES: u64 = 512 * 1024;
+
+/// Sections whose entries are environment variables
+const ENV_SECTION_KEYS: [&str; 2] = ["env", "environment"];
+
+/// Settings holding a command line (lowercased)
+const COMMAND_SETTING_KEYS: [&str; 7] = ["command", "args", "entrypoint", "cmd", "run", "script", "execstart"];
+
+/// Sections whose leaf names are flag names (lowercased, `_`/`-` removed)
+const FLAG_SECTION_KEYS: [&str; 4] = ["features", "featureflags", "flags", "toggles"];
+
+/// Calls and indexers whose first string argument names an env var
+const ENV_READ_CALL_MARKERS: [&str; 13] = [
+    "Getenv(",
+    "getenv(",
+    "LookupEnv(",
+    "env::var(",
+    "env::var_os(",
+    "env!(",
+    "environ.get(",
+    "environ[",
+    "GetEnvironmentVariable(",
+    "ENV.fetch(",
+    "ENV[",
+    "process.env[",
+    "Deno.env.get(",
+];
+
+/// Struct tags naming an env var (`env:"PORT"`, `envconfig:"PORT"`)
+const ENV_STRUCT_TAG_MARKERS: [&str; 2] = ["env:\"", "envconfig:\""];
+
+/// One scalar of a config file
+///
+/// # 4-Word Name: ConfigScalarSettingEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ConfigScalarSettingEntry {
+    /// Mapping keys from the document root; `-` for a sequence item
+    pub path: Vec<String>,
+    pub value: String,
+    /// 1-based
+    pub line: u32,
+}
+
+/// Scalars of one YAML file, with their key paths
+///
+/// # 4-Word Name: parse_yaml_config_settings
+///
+/// # Contract
+/// - Postcondition: one entry per scalar, in file order; flow sequences
+///   (`[a, b]`) yield one entry per item, block scalars (`|`, `>`) one
+///   entry with the joined lines; documents split at `---`
+/// - Postcondition: comments, Helm `{{ ... }}` lines, flow mappings and
+///   aliases yield nothing
+pub fn parse_yaml_config_settings(source: &str) -> Vec<ConfigScalarSettingEntry> {
+    let lines: Vec<&str> = source.lines().collect();
+    let mut settings = Vec::new();
+    // (indent, key or `-`) of the mappings and sequence items enclosing a line
+    let mut stack: Vec<(usize, String)> = Vec::new();
+    let mut index = 0;
+    while index < lines.len() {
+        let raw = lines[index];
+        let line = index as u32 + 1;
+        index += 1;
+        let mut text = strip_yaml_line_comment(raw.trim_start());
+        if text.starts_with("---") || text == "..." {
+            stack.clear();
+            continue;
+        }
+        if text.is_empty() || text.starts_with("{{") || text.starts_with('%') {
+            continue;
+        }
+        let mut indent = raw.len() - raw.trim_start().len();
+        // A sequence may sit at its key's indent, so items only close siblings
+        let is_item = text == "-" || text.starts_with("- ");
+        while stack.last().is_some_and(|(i, s)| *i > indent || (*i == indent && (!is_item || s == "-"))) {
+            stack.pop();
+        }
+        while text == "-" || text.starts_with("- ") {
+            stack.push((indent, "-".to_string()));
+            let rest = text[1..].trim_start();
+            indent += text.len() - rest.len();
+            text = rest;
+        }
+        if text.is_empty() {
+            continue;
+        }
+
+        let mut path: Vec<String> = stack.iter().map(|(_, segment)| segment.clone()).collect();
+        let Some((key, value)) = split_yaml_mapping_entry(text) else {
+            if !text.starts_with(['{', '*']) {
+                settings.push(ConfigScalarSettingEntry { path, value: unquote_yaml_scalar(text), line });
+            }
+            continue;
+        };
+        let anchor_only = value.starts_with('&') && !value.contains(' ');
+        if value.is_empty() || anchor_only {
+            stack.push((indent, key));
+            continue;
+        }
+        path.push(key);
+        if value.starts_with(['|', '>']) {
+            let mut block = Vec::new();
+            while let Some(next) = lines.get(index) {
+                if !next.trim().is_empty() && next.len() - next.trim_start().len() <= indent {
+                    break;
+                }
+                block.push(next.trim());
+                index += 1;
+            }
+            settings.push(ConfigScalarSettingEntry { path, value: block.join("\n").trim().to_string(), line });
+        } else if let Some(items) = value.strip_prefix('[').and_then(|v| v.strip_suffix(']')) {
+            path.push("-".to_string());
+            for item in items.split(',').map(|item| unquote_yaml_scalar(item.trim())).filter(|item| !item.is_empty()) {
+                settings.push(ConfigScalarSettingEntry { path: path.clone(), value: item, line });
+            }
+        } else if !value.starts_with(['{', '*']) {
+            settings.push(ConfigScalarSettingEntry { path, value: unquote_yaml_scalar(value), line });
+        }
+    }
+    settings
+}
+
+/// Scalars of one JSON file, with their key paths
+///
+/// # 4-Word Name: parse_json_config_settings
+///
+/// # Contract
+/// - Postcondition: one entry per string, number and bool; the line is that
+///   of the first occurrence of the key (or array item) in the text
+/// - Postcondition: invalid JSON yields nothing
+pub fn parse_json_config_settings(source: &str) -> Vec<ConfigScalarSettingEntry> {
+    let Ok(document) = serde_json::from_str::<serde_json::Value>(source) else {
+        return Vec::new();
+    };
+    let mut settings = Vec::new();
+    collect_json_scalar_settings(&document, &mut Vec::new(), source, &mut settings);
+    settings
+}
+
+fn collect_json_scalar_settings(
+    value: &serde_json::Value,
+    path: &mut Vec<String>,
+    source: &str,
+    settings: &mut Vec<ConfigScalarSettingEntry>,
+) {
+    match value {
+        serde_json::Value::Object(map) => {
+            for (key, child) in map {
+                path.push(key.clone());
+                collect_json_scalar_settings(child, path, source, settings);
+                path.pop();
+            }
+        }
+        serde_json::Value::Array(items) => {
+            path.push("-".to_string());
+            for item in items {
+                collect_json_scalar_settings(item, path, source, settings);
+            }
+            path.pop();
+        }
+        serde_json::Value::Null => {}
+        scalar => {
+            let text = match scalar {
+                serde_json::Value::String(text) => text.clone(),
+                other => other.to_string(),
+            };
+            let needle = match path.last() {
+                Some(key) if key != "-" => format!("\"{}\"", key),
+                _ => format!("\"{}\"", text),
+            };
+            let line = source.find(&needle).map_or(1, |offset| source[..offset].matches('\n').count() as u32 + 1);
+            settings.push(ConfigScalarSettingEntry { path: path.clone(), value: text, line });
+        }
+    }
+}
+
+/// `ConfiguredBy` edges from code to the YAML/JSON settings it refers to
+///
+/// # 4-Word Name: link_config_reference_edges
+///
+/// # Contract
+/// - Precondition: `entities` carry `current_code`; test entities carry
+///   `facet: test`
+/// - Postcondition: `(nodes, edges)`; every edge starts at an entity of
+///   `entities` and ends at one of `nodes`, one edge per (entity, node)
+/// - Postcondition: config files sorted by path; unreadable, invalid and
+///   oversized files are skipped
+pub fn link_config_reference_edges(entities: &[CodeEntity], root: &Path) -> (Vec<CodeEntity>, Vec<DependencyEdge>) {
+    let mut env_readers: HashMap<String, BTreeSet<&str>> = HashMap::new();
+    let mut literal_readers: HashMap<String, BTreeSet<&str>> = HashMap::new();
+    for entity in entities.iter().filter(|e| is_config_reading_entity(e)) {
+        let Some(code) = entity.current_code.as_deref() else { continue };
+        let (env_names, literals) = scan_code_config_references(code);
+        for name in env_names {
+            env_readers.entry(name).or_default().insert(entity.isgl1_key.as_str());
+        }
+        for literal in literals {
+            literal_readers.entry(literal).or_default().insert(entity.isgl1_key.as_str());
+        }
+    }
+    let mut binaries: HashMap<String, Vec<&str>> = HashMap::new();
+    let mut functions: HashMap<&str, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities {
+        if let Some(binary) = binary_name_of_main(entity, root) {
+            binaries.entry(binary).or_default().push(entity.isgl1_key.as_str());
+        }
+        if matches!(entity.interface_signature.entity_type, EntityType::Function | EntityType::Method) {
+            functions.entry(entity.interface_signature.name.as_str()).or_default().push(entity);
+        }
+    }
+
+    let mut nodes = Vec::new();
+    let mut edges = Vec::new();
+    for path in CONFIG_FILE_EXTENSIONS.iter().flat_map(|extension| find_contract_files_by_extension(root, extension)) {
+        let file_name = path.file_name().map(|name| name.to_string_lossy().into_owned()).unwrap_or_default();
+        if file_name.split('.').next().is_some_and(|stem| stem.ends_with("-lock")) {
+            continue;
+        }
+        if std::fs::metadata(&path).map_or(true, |meta| meta.len() > MAX_CONFIG_FILE_BYTES) {
+            continue;
+        }
+        let Ok(source) = std::fs::read_to_string(&path) else { continue };
+        let (language, settings) = if file_name.ends_with(".json") {
+            ("json", parse_json_config_settings(&source))
+        } else {
+            ("yaml", parse_yaml_config_settings(&source))
+        };
+
+        // Node name → (kind, setting, inline value, readers), first occurrence wins
+        let mut referenced: BTreeMap<String, (&str, &ConfigScalarSettingEntry, String, BTreeSet<&str>)> =
+            BTreeMap::new();
+        for setting in &settings {
+            let mut found: Vec<(&str, String, String, BTreeSet<&str>)> = Vec::new();
+            if let Some((name, value)) = env_variable_setting_of(setting) {
+                if let Some(readers) = env_readers.get(&name) {
+                    found.push(("env", name, value, readers.clone()));
+                }
+            }
+            let flag_key = flag_key_names_of(setting)
+                .into_iter()
+                .find_map(|name| literal_readers.get(&name).map(|readers| (name, readers)));
+            if let Some((name, readers)) = flag_key {
+                found.push(("key", name, setting.value.clone(), readers.clone()));
+            }
+            if let Some(handler) = handler_function_key_of(setting, &functions) {
+                found.push(("handler", setting.value.replace(':', "."), String::new(), BTreeSet::from([handler])));
+            }
+            for word in command_words_of(setting) {
+                if let Some([main]) = binaries.get(&word).map(Vec::as_slice) {
+                    found.push(("binary", word, String::new(), BTreeSet::from([*main])));
+                }
+            }
+            for (kind, name, value, readers) in found {
+                referenced.entry(name).or_insert_with(|| (kind, setting, value, BTreeSet::new())).3.extend(readers);
+            }
+        }
+
+        let lines: Vec<&str> = source.lines().collect();
+        let file = path.to_string_lossy().into_owned();
+        for (name, (kind, setting, value, readers)) in referenced {
+            let body = lines.get(setting.line as usize - 1).map(|line| line.trim().to_string());
+            let metadata = [
+                (CONFIG_KIND_METADATA_KEY, kind.to_string()),
+                (CONFIG_PATH_METADATA_KEY, config_setting_path_label(&setting.path)),
+                (CONFIG_VALUE_METADATA_KEY, value),
+            ];
+            let line_span = (setting.line, setting.line);
+            let Some(node) =
+                build_contract_file_entity(language, &file, &name, EntityType::Variable, line_span, body, &metadata)
+            else {
+                continue;
+            };
+            for reader in readers {
+                let edge = DependencyEdge::builder()
+                    .from_key(reader)
+                    .to_key(node.isgl1_key.clone())
+                    .edge_type(EdgeType::ConfiguredBy)
+                    .source_location(format!("{}:{}", file, setting.line))
+                    .metadata_entry(CONFIG_KIND_METADATA_KEY, kind)
+                    .metadata_entry("resolution", CONFIG_REFERENCE_RESOLUTION_VALUE)
+                    .build();
+                edges.extend(edge.ok());
+            }
+            nodes.push(node);
+        }
+    }
+    (nodes, edges)
+}
+
+/// Production code that can read configuration
+fn is_config_reading_entity(entity: &CodeEntity) -> bool {
+    let is_test = entity.metadata.additional.get(TEST_FACET_METADATA_KEY).map(String::as_str)
+        == Some(TEST_FACET_METADATA_VALUE);
+    !is_test
+        && matches!(
+            entity.interface_signature.entity_type,
+            EntityType::Function | EntityType::Method | EntityType::Struct | EntityType::Variable | EntityType::Constant
+        )
+}
+
+/// Env var names read by a code body, and its short whitespace-free string literals
+fn scan_code_config_references(code: &str) -> (BTreeSet<String>, BTreeSet<String>) {
+    let mut env_names = BTreeSet::new();
+    for marker in ENV_READ_CALL_MARKERS {
+        for (offset, _) in code.match_indices(marker) {
+            let rest = code[offset + marker.len()..].trim_start();
+            let Some(quote) = rest.chars().next().filter(|c| matches!(*c, '"' | '\'' | '`')) else { continue };
+            if let Some((name, _)) = rest[1..].split_once(quote) {
+                env_names.insert(name.to_string());
+            }
+        }
+    }
+    for marker in ENV_STRUCT_TAG_MARKERS {
+        for (offset, _) in code.match_indices(marker) {
+            let preceded_by_word = code[..offset].chars().next_back().is_some_and(|c| c.is_alphanumeric() || c == '_');
+            if let Some((name, _)) = code[offset + marker.len()..].split_once('"').filter(|_| !preceded_by_word) {
+                env_names.insert(name.split(',').next().unwrap_or_default().to_string());
+            }
+        }
+    }
+    for (offset, _) in code.match_indices("process.env.") {
+        let rest = &code[offset + "process.env.".len()..];
+        let end = rest.find(|c: char| !(c.is_alphanumeric() || c == '_')).unwrap_or(rest.len());
+        env_names.insert(rest[..end].to_string());
+    }
+    env_names.retain(|name| is_env_variable_name(name));
+
+    let mut literals = BTreeSet::new();
+    for line in code.lines() {
+        let mut rest = line;
+        while let Some(start) = rest.find(['"', '\'', '`']) {
+            let quote = rest.as_bytes()[start] as char;
+            let after = &rest[start + 1..];
+            let Some(end) = after.find(quote) else { break };
+            let literal = &after[..end];
+            if !literal.is_empty() && literal.len() <= 128 && !literal.contains(char::is_whitespace) {
+                literals.insert(literal.to_string());
+            }
+            rest = &after[end + 1..];
+        }
+    }
+    (env_names, literals)
+}
+
+/// `(name, inline value)` of a setting under an env section
+fn env_variable_setting_of(setting: &ConfigScalarSettingEntry) -> Option<(String, String)> {
+    let section = setting.path.iter().rposition(|segment| ENV_SECTION_KEYS.contains(&segment.as_str()))?;
+    let (name, value) = match &setting.path[section + 1..] {
+        [item, field] if item == "-" && field == "name" => (setting.value.clone(), String::new()),
+        [item] if item == "-" => {
+            let (name, value) = setting.value.split_once('=').unwrap_or((setting.value.as_str(), ""));
+            (name.to_string(), value.to_string())
+        }
+        [name] => (name.clone(), setting.value.clone()),
+        _ => return None,
+    };
+    is_env_variable_name(&name).then_some((name, value))
+}
+
+/// Literals a setting answers to, in order: its dotted path, then its leaf under a flag section
+fn flag_key_names_of(setting: &ConfigScalarSettingEntry) -> Vec<String> {
+    if setting.path.iter().any(|segment| segment == "-") {
+        return Vec::new();
+    }
+    let mut names = Vec::new();
+    if setting.path.len() >= 2 {
+        names.push(setting.path.join("."));
+    }
+    let parent = setting.path.len().checked_sub(2).map(|i| setting.path[i].to_ascii_lowercase());
+    let parent = parent.map(|parent| parent.replace(['_', '-'], ""));
+    if parent.is_some_and(|parent| FLAG_SECTION_KEYS.contains(&parent.as_str())) {
+        names.extend(setting.path.last().cloned());
+    }
+    names
+}
+
+/// The function a `handler:` setting names, when exactly one matches
+fn handler_function_key_of<'a>(
+    setting: &ConfigScalarSettingEntry,
+    functions: &HashMap<&str, Vec<&'a CodeEntity>>,
+) -> Option<&'a str> {
+    if !setting.path.last().is_some_and(|key| key.eq_ignore_ascii_case("handler")) {
+        return None;
+    }
+    let value = setting.value.trim();
+    let (module, function) = value.rsplit_once("::").or_else(|| value.rsplit_once('.')).unwrap_or(("", value));
+    let module = module.rsplit(['/', '.']).next().unwrap_or_default();
+    let candidates = functions.get(function)?;
+    let narrowed: Vec<&CodeEntity> = candidates
+        .iter()
+        .copied()
+        .filter(|entity| {
+            let file = &entity.interface_signature.file_path;
+            let stem = file.file_stem().map(|s| s.to_string_lossy().into_owned()).unwrap_or_default();
+            let directory = file.parent().and_then(Path::file_name).map(|d| d.to_string_lossy().into_owned());
+            module.is_empty() || stem == module || directory.as_deref() == Some(module)
+        })
+        .collect();
+    let chosen: &'a CodeEntity = match (candidates.as_slice(), narrowed.as_slice()) {
+        ([only], _) | (_, [only]) => *only,
+        _ => return None,
+    };
+    Some(chosen.isgl1_key.as_str())
+}
+
+/// Words of a command setting, reduced to the last path component
+fn command_words_of(setting: &ConfigScalarSettingEntry) -> Vec<String> {
+    let Some(key) = setting.path.iter().rev().find(|segment| *segment != "-") else {
+        return Vec::new();
+    };
+    if !COMMAND_SETTING_KEYS.contains(&key.to_ascii_lowercase().as_str()) {
+        return Vec::new();
+    }
+    setting
+        .value
+        .split_whitespace()
+        .map(|word| word.trim_matches(|c: char| matches!(c, '"' | '\'' | ';' | '&' | '(' | ')' | ',')))
+        .filter(|word| !word.starts_with('-'))
+        .filter_map(|word| word.trim_end_matches('/').rsplit('/').next())
+        .filter(|word| word.len() >= 2)
+        .map(str::to_string)
+        .collect()
+}
+
+/// Binary a `main` function builds: its Go package directory, or its Rust crate / `src/bin` target
+fn binary_name_of_main(entity: &CodeEntity, root: &Path) -> Option<String> {
+    let signature = &entity.interface_signature;
+    if signature.name != "main" || signature.entity_type != EntityType::Function {
+        return None;
+    }
+    let relative = relative_slash_path(root, &signature.file_path);
+    let parts: Vec<&str> = relative.split('/').collect();
+    let root_name = || root.canonicalize().ok()?.file_name().map(|name| name.to_string_lossy().into_owned());
+    let at = |back: usize| parts.len().checked_sub(back + 1).map(|i| parts[i]);
+    if relative.ends_with(".go") {
+        return at(1).map(str::to_string).or_else(root_name);
+    }
+    if !relative.ends_with(".rs") {
+        return None;
+    }
+    match (at(0), at(1), at(2)) {
+        (Some(file), Some("bin"), _) => file.strip_suffix(".rs").map(str::to_string),
+        (Some("main.rs"), Some(target), Some("bin")) => Some(target.to_string()),
+        (Some("main.rs"), Some("src"), Some(krate)) => Some(krate.to_string()),
+        (Some("main.rs"), Some("src"), None) => root_name(),
+        _ => None,
+    }
+}
+
+/// `spec.containers[].env[].name`
+fn config_setting_path_label(path: &[String]) -> String {
+    let mut label = String::new();
+    for segment in path {
+        if segment == "-" {
+            label.push_str("[]");
+        } else {
+            if !label.is_empty() {
+                label.push('.');
+            }
+            label.push_str(segment);
+        }
+    }
+    label
+}
+
+fn is_env_variable_name(name: &str) -> bool {
+    name.chars().next().is_some_and(|c| c.is_ascii_alphabetic() || c == '_')
+        && name.chars().all(|c| c.is_ascii_alphanumeric() || c == '_')
+}
+
+/// `key: value` split at the first `:` outside quotes that ends the line or precedes a space
+fn split_yaml_mapping_entry(text: &str) -> Option<(String, &str)> {
+    let mut quote = None;
+    for (offset, c) in text.char_indices() {
+        match (quote, c) {
+            (None, '"' | '\'') if offset == 0 => quote = Some(c),
+            (Some(open), _) if c == open => quote = None,
+            (None, ':') => {
+                let value = &text[offset + 1..];
+                if value.is_empty() || value.starts_with(' ') {
+                    let key = unquote_yaml_scalar(&text[..offset]);
+                    return (!key.is_empty() && !key.starts_with(['{', '['])).then_some((key, value.trim()));
+                }
+            }
+            _ => {}
+        }
+    }
+    None
+}
+
+/// Text before a ` #` comment that is not inside quotes; empty for comment lines
+fn strip_yaml_line_comment(text: &str) -> &str {
+    let mut quote = None;
+    let mut previous = ' ';
+    for (offset, c) in text.char_indices() {
+        match (quote, c) {
+            (None, '#') if previous == ' ' => return text[..offset].trim_end(),
+            (None, '"' | '\'') => quote = Some(c),
+            (Some(open), _) if c == open => quote = None,
+            _ => {}
+        }
+        previous = c;
+    }
+    text.trim_end()
+}
+
+fn unquote_yaml_scalar(text: &str) -> String {
+    let text = text.trim();
+    ['"', '\'']
+        .iter()
+        .find_map(|quote| text.strip_prefix(*quote).and_then(|t| t.strip_suffix(*quote)))
+        .unwrap_or(text)
+        .to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_yaml_settings_keep_key_paths() {
+        let source = concat!(
+            "# deploy\n",
+            "spec:\n",
+            "  containers:\n",
+            "  - name: api\n",
+            "    command: [\"/bin/api\", \"--port=8080\"]\n",
+            "    env:\n",
+            "    - name: FEATURE_NEW_CHECKOUT # rollout\n",
+            "      value: \"true\"\n",
+            "---\n",
+            "jobs:\n",
+            "  test:\n",
+            "    steps:\n",
+            "      - run: |\n",
+            "          go build ./cmd/worker\n",
+            "          go test ./...\n",
+            "    env:\n",
+            "      LOG_LEVEL: debug\n",
+        );
+        let found: Vec<(String, String, u32)> = parse_yaml_config_settings(source)
+            .into_iter()
+            .map(|s| (config_setting_path_label(&s.path), s.value, s.line))
+            .collect();
+        let expected = [
+            ("spec.containers[].name", "api", 4),
+            ("spec.containers[].command[]", "/bin/api", 5),
+            ("spec.containers[].command[]", "--port=8080", 5),
+            ("spec.containers[].env[].name", "FEATURE_NEW_CHECKOUT", 7),
+            ("spec.containers[].env[].value", "true", 8),
+            ("jobs.test.steps[].run", "go build ./cmd/worker\ngo test ./...", 13),
+            ("jobs.test.env.LOG_LEVEL", "debug", 17),
+        ];
+        let expected: Vec<(String, String, u32)> =
+            expected.iter().map(|(path, value, line)| (path.to_string(), value.to_string(), *line)).collect();
+        assert_eq!(found, expected);
+    }
+
+    #[test]
+    fn test_code_linked_to_env_flags_handlers_and_binaries() {
+        let dir = tempfile::tempdir().unwrap();
+        let root = dir.path();
+        std::fs::create_dir_all(root.join("deploy")).unwrap();
+        let deploy = concat!(
+            "spec:\n",
+            "  containers:\n",
+            "  - command: [\"/app/api\"]\n",
+            "    env:\n",
+            "    - name: FEATURE_NEW_CHECKOUT\n",
+            "    - name: UNUSED_VAR\n",
+        );
+        std::fs::write(root.join("deploy/api.yaml"), deploy).unwrap();
+        let serverless = "functions:\n  hook:\n    handler: handlers/hook.Handle\n";
+        std::fs::write(root.join("serverless.yml"), serverless).unwrap();
+        std::fs::write(root.join("config.json"), "{\n  \"features\": {\n    \"fast_search\": true\n  }\n}\n").unwrap();
+        std::fs::write(root.join("package-lock.json"), "{\"features\": {\"fast_search\": 1}}").unwrap();
+
+        let file = |relative: &str| root.join(relative).to_string_lossy().into_owned();
+        let code = |key: &str, name: &str, relative: &str, body: &str, facet: &[(&str, &str)]| {
+            let mut entity = create_go_test_entity(key, name, EntityType::Function, &file(relative), facet);
+            entity.current_code = Some(body.to_string());
+            entity
+        };
+        let entities = vec![
+            code("go:fn:main:__cmd_api:T1", "main", "cmd/api/main.go", "func main() {}", &[]),
+            code("go:fn:Checkout:__svc:T2", "Checkout", "svc/checkout.go", "os.Getenv(\"FEATURE_NEW_CHECKOUT\")", &[]),
+            code("go:fn:Search:__svc:T3", "Search", "svc/search.go", "if flags.On(\"fast_search\") {}", &[]),
+            code("go:fn:Handle:__handlers:T4", "Handle", "handlers/hook.go", "func Handle() {}", &[]),
+            code("go:fn:Handle:__admin:T5", "Handle", "admin/hook_admin.go", "func Handle() {}", &[]),
+            code("go:fn:TestCheckout:__svc:T6", "TestCheckout", "svc/checkout_test.go", "os.Getenv(\"UNUSED_VAR\")", &[
+                ("facet", "test"),
+            ]),
+        ];
+
+        let (nodes, edges) = link_config_reference_edges(&entities, root);
+        let mut found: Vec<(&str, &str, &str)> = edges
+            .iter()
+            .map(|edge| {
+                let node = nodes.iter().find(|n| n.isgl1_key == edge.to_key.as_str()).unwrap();
+                let kind = edge.metadata_value(CONFIG_KIND_METADATA_KEY).unwrap();
+                (edge.from_key.as_str(), node.interface_signature.name.as_str(), kind)
+            })
+            .collect();
+        found.sort();
+        assert_eq!(
+            found,
+            vec![
+                ("go:fn:Checkout:__svc:T2", "FEATURE_NEW_CHECKOUT", "env"),
+                ("go:fn:Handle:__handlers:T4", "handlers/hook.Handle", "handler"),
+                ("go:fn:Search:__svc:T3", "fast_search", "key"),
+                ("go:fn:main:__cmd_api:T1", "api", "binary"),
+            ]
+        );
+        assert!(edges.iter().all(|edge| edge.edge_type == EdgeType::ConfiguredBy));
+        let flag = nodes.iter().find(|n| n.interface_signature.name == "fast_search").unwrap();
+        assert!(flag.isgl1_key.starts_with("json:var:fast_search:"), "{}", flag.isgl1_key);
+        let flag_path = flag.metadata.additional.get(CONFIG_PATH_METADATA_KEY).map(String::as_str);
+        assert_eq!(flag_path, Some("features.fast_search"));
+        assert_eq!(flag.interface_signature.line_range.start, 3);
+        let env = nodes.iter().find(|n| n.interface_signature.name == "FEATURE_NEW_CHECKOUT").unwrap();
+        assert!(env.isgl1_key.starts_with("yaml:var:"), "{}", env.isgl1_key);
+        assert_eq!(nodes.len(), 4, "UNUSED_VAR is only read by a test");
+    }
+}
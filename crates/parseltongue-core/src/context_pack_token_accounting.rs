@@ -0,0 +1,262 @@
+//! Token accounting for context packs (v1.7.3)
+//!
+//! # 4-Word Naming: context_pack_token_accounting
+//!
+//! A pack budget is only useful if it is counted the way the target model
+//! counts. This module holds the tokenizers a pack can be measured with,
+//! the `--model` presets mapping model names to their context window and
+//! tokenizer, and the per-symbol token report written next to a pack
+//! (`pack-context --token-report pack.tokens.json`).
+//!
+//! ## Presets
+//!
+//! A preset sets the budget to the model's context window (unless
+//! `--budget` asks for less) and the tokenizer the packer counts with.
+//! OpenAI models are counted with their own BPE (`cl100k_base`,
+//! `o200k_base`). Claude's tokenizer is not published, so Claude presets
+//! count with `cl100k_base` and are marked `tokenizer_exact: false`; leave
+//! headroom with `--budget` when the limit is tight.
+//!
+//! ## Report
+//!
+//! Every packed item records the tokens it added to the rendered output.
+//! `overhead_tokens` is what the items do not account for (template
+//! wrappers, the outside-ownership section), so items plus overhead always
+//! equal `tokens_used`.
+
+use std::sync::OnceLock;
+
+use serde::Serialize;
+
+use crate::error::{ParseltongError, Result};
+use crate::token_budget_context_packer::PackedContextBundleResult;
+
+/// BPE a pack is counted with
+///
+/// # 4-Word Name: ContextTokenizerEncodingKind
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize)]
+pub enum ContextTokenizerEncodingKind {
+    /// GPT-3.5 / GPT-4 (and the stand-in for unpublished tokenizers)
+    #[default]
+    #[serde(rename = "cl100k_base")]
+    Cl100kBase,
+    /// GPT-4o family
+    #[serde(rename = "o200k_base")]
+    O200kBase,
+}
+
+impl ContextTokenizerEncodingKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Cl100kBase => "cl100k_base",
+            Self::O200kBase => "o200k_base",
+        }
+    }
+}
+
+static CL100K_BPE_INSTANCE: OnceLock<Option<tiktoken_rs::CoreBPE>> = OnceLock::new();
+static O200K_BPE_INSTANCE: OnceLock<Option<tiktoken_rs::CoreBPE>> = OnceLock::new();
+
+/// Count tokens with one tokenizer
+///
+/// # 4-Word Name: count_tokens_with_encoding
+///
+/// # Contract
+/// - Postcondition: exact BPE token count; conservative upper bound
+///   (`ceil(bytes / 2)`) if the tokenizer tables fail to load
+pub fn count_tokens_with_encoding(text: &str, encoding: ContextTokenizerEncodingKind) -> usize {
+    let bpe = match encoding {
+        ContextTokenizerEncodingKind::Cl100kBase => CL100K_BPE_INSTANCE.get_or_init(|| tiktoken_rs::cl100k_base().ok()),
+        ContextTokenizerEncodingKind::O200kBase => O200K_BPE_INSTANCE.get_or_init(|| tiktoken_rs::o200k_base().ok()),
+    };
+    match bpe {
+        Some(bpe) => bpe.encode_ordinary(text).len(),
+        None => (text.len() + 1) / 2,
+    }
+}
+
+/// Context window and tokenizer of one model
+///
+/// # 4-Word Name: ModelContextWindowPreset
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ModelContextWindowPreset {
+    pub name: &'static str,
+    /// Input tokens the model accepts
+    pub context_window: usize,
+    pub tokenizer: ContextTokenizerEncodingKind,
+    /// False when `tokenizer` only approximates the model's own
+    pub tokenizer_exact: bool,
+}
+
+const fn preset(
+    name: &'static str,
+    context_window: usize,
+    tokenizer: ContextTokenizerEncodingKind,
+    tokenizer_exact: bool,
+) -> ModelContextWindowPreset {
+    ModelContextWindowPreset { name, context_window, tokenizer, tokenizer_exact }
+}
+
+/// `--model` presets, smallest window first
+pub const MODEL_CONTEXT_WINDOW_PRESETS: &[ModelContextWindowPreset] = &[
+    preset("gpt-4", 8_192, ContextTokenizerEncodingKind::Cl100kBase, true),
+    preset("gpt-3.5-turbo", 16_385, ContextTokenizerEncodingKind::Cl100kBase, true),
+    preset("gpt-4-32k", 32_768, ContextTokenizerEncodingKind::Cl100kBase, true),
+    preset("gpt-4-turbo", 128_000, ContextTokenizerEncodingKind::Cl100kBase, true),
+    preset("gpt-4o", 128_000, ContextTokenizerEncodingKind::O200kBase, true),
+    preset("gpt-4o-mini", 128_000, ContextTokenizerEncodingKind::O200kBase, true),
+    preset("claude-3-haiku", 200_000, ContextTokenizerEncodingKind::Cl100kBase, false),
+    preset("claude-3-opus", 200_000, ContextTokenizerEncodingKind::Cl100kBase, false),
+    preset("claude-3-5-sonnet", 200_000, ContextTokenizerEncodingKind::Cl100kBase, false),
+];
+
+/// Look up a `--model` preset by name
+///
+/// # 4-Word Name: resolve_model_context_preset
+///
+/// # Contract
+/// - Postcondition: names match case-insensitively
+/// - Error: `ParseError` listing the presets for unknown names
+pub fn resolve_model_context_preset(name: &str) -> Result<&'static ModelContextWindowPreset> {
+    MODEL_CONTEXT_WINDOW_PRESETS
+        .iter()
+        .find(|preset| preset.name.eq_ignore_ascii_case(name.trim()))
+        .ok_or_else(|| ParseltongError::ParseError {
+            reason: format!(
+                "unknown model '{}' (presets: {})",
+                name,
+                MODEL_CONTEXT_WINDOW_PRESETS.iter().map(|p| p.name).collect::<Vec<_>>().join(", ")
+            ),
+            location: "--model".to_string(),
+        })
+}
+
+/// Tokens one packed symbol consumed
+///
+/// # 4-Word Name: PackedItemTokenCostEntry
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct PackedItemTokenCostEntry {
+    pub entity_key: String,
+    pub relation: String,
+    /// `body`, `signature`, `summary` or `chunks`
+    pub content: &'static str,
+    pub file_path: String,
+    pub tokens: usize,
+    /// Fraction of `tokens_used`
+    pub share: f64,
+}
+
+/// Token breakdown of a pack (the JSON sidecar)
+///
+/// # 4-Word Name: ContextPackTokenReport
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct ContextPackTokenReport {
+    pub seed_key: String,
+    /// Preset the budget came from, if any
+    pub model: Option<ModelContextWindowPreset>,
+    pub tokenizer: ContextTokenizerEncodingKind,
+    pub token_budget: usize,
+    pub tokens_used: usize,
+    /// Rendered tokens not attributed to an item
+    pub overhead_tokens: usize,
+    /// Items in pack order
+    pub items: Vec<PackedItemTokenCostEntry>,
+}
+
+/// Per-symbol token breakdown of a packed bundle
+///
+/// # 4-Word Name: build_context_pack_token_report
+///
+/// # Contract
+/// - Postcondition: `overhead_tokens + Σ items.tokens == tokens_used`
+///   (items are capped so the sum never exceeds it)
+pub fn build_context_pack_token_report(
+    bundle: &PackedContextBundleResult,
+    model: Option<&ModelContextWindowPreset>,
+) -> ContextPackTokenReport {
+    let mut remaining = bundle.tokens_used;
+    let items: Vec<PackedItemTokenCostEntry> = bundle
+        .items
+        .iter()
+        .map(|item| {
+            let tokens = item.tokens.min(remaining);
+            remaining -= tokens;
+            PackedItemTokenCostEntry {
+                entity_key: item.entity_key.clone(),
+                relation: item.relation.clone(),
+                content: item.packed_content_kind_label(),
+                file_path: item.file_path.clone(),
+                tokens,
+                share: if bundle.tokens_used == 0 { 0.0 } else { tokens as f64 / bundle.tokens_used as f64 },
+            }
+        })
+        .collect();
+    ContextPackTokenReport {
+        seed_key: bundle.seed_key.clone(),
+        model: model.cloned(),
+        tokenizer: bundle.tokenizer,
+        token_budget: bundle.token_budget,
+        tokens_used: bundle.tokens_used,
+        overhead_tokens: remaining,
+        items,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_model_presets_resolve_case_insensitively() {
+        let gpt4 = resolve_model_context_preset("GPT-4").unwrap();
+        assert_eq!((gpt4.context_window, gpt4.tokenizer), (8_192, ContextTokenizerEncodingKind::Cl100kBase));
+        assert_eq!(resolve_model_context_preset("claude-3-5-sonnet").unwrap().context_window, 200_000);
+        assert!(!resolve_model_context_preset("claude-3-5-sonnet").unwrap().tokenizer_exact);
+        let error = resolve_model_context_preset("gpt-9").unwrap_err().to_string();
+        assert!(error.contains("gpt-4o"), "{}", error);
+    }
+
+    #[test]
+    fn test_report_items_and_overhead_sum_to_total() {
+        use crate::context_prompt_template_renderer::ContextPackOutputTemplate;
+        use crate::entities::EntityType;
+        use crate::go_embedding_promotion_resolver::create_go_test_entity;
+        use crate::token_budget_context_packer::{pack_ranked_entities_with_tokenizer, RankedNeighborEntityEntry};
+        use std::collections::HashMap;
+
+        let mut entities = HashMap::new();
+        let mut ranked = Vec::new();
+        for (index, key) in ["seed", "caller"].iter().enumerate() {
+            let mut entity = create_go_test_entity(key, key, EntityType::Function, "svc/a.go", &[]);
+            entity.current_code = Some(format!("func {}() error {{\n    return nil\n}}", key));
+            entities.insert(key.to_string(), entity);
+            ranked.push(RankedNeighborEntityEntry {
+                entity_key: key.to_string(),
+                relation: key.to_string(),
+                depth: index,
+                relevance_score: 2.0 - index as f64,
+            });
+        }
+        let template = ContextPackOutputTemplate::resolve_context_pack_template("claude-xml").unwrap();
+        let bundle = pack_ranked_entities_with_tokenizer(
+            "seed",
+            &ranked,
+            &entities,
+            2_000,
+            &template,
+            ContextTokenizerEncodingKind::O200kBase,
+        );
+        let report = build_context_pack_token_report(&bundle, resolve_model_context_preset("gpt-4o").ok());
+
+        assert_eq!(report.tokenizer, ContextTokenizerEncodingKind::O200kBase);
+        assert_eq!(report.items.len(), 2);
+        assert_eq!(report.items[0].content, "body");
+        assert!(report.overhead_tokens > 0, "claude-xml wrappers are overhead");
+        let item_total: usize = report.items.iter().map(|i| i.tokens).sum();
+        assert_eq!(item_total + report.overhead_tokens, bundle.tokens_used);
+        assert_eq!(
+            count_tokens_with_encoding(&template.render_context_pack_bundle(&bundle), bundle.tokenizer),
+            bundle.tokens_used
+        );
+    }
+}
@@ -0,0 +1,744 @@
+//! Context Prompt Template Renderer (v1.7.3)
+//!
+//! # 4-Word Naming: context_prompt_template_renderer
+//!
+//! Controls how a context pack is rendered for a prompt: section order,
+//! per-symbol formatting, markdown vs XML tags. Templates use a subset of
+//! Go's `text/template`:
+//!
+//! ```text
+//! {{.Field}}  {{$.Field}}  {{.}}                     fields of dot / of the bundle / dot
+//! {{if .Owner}}...{{else if .Documentation}}...{{else}}...{{end}}
+//! {{range .Items}}...{{else}}...{{end}}  {{with .Owner}}...{{end}}
+//! {{escapeXml .FilePath}}  {{.Text | trimSpace}}      functions and pipelines
+//! {{- trim -}}  {{/* comment */}}
+//! ```
+//!
+//! Functions: `escapeXml s`, `prefixLines prefix s`, `upper s`, `lower s`,
+//! `trimSpace s`, `title s`, `default fallback v`, `len v`. Unknown fields
+//! render empty; unknown functions and wrong arity fail when the template is
+//! loaded.
+//!
+//! ## Data
+//!
+//! - Bundle: `SeedKey`, `TokenBudget`, `ItemCount`, `Items`, `Sections`
+//!   (`Relation`, `Title`, `Items`; seed, changed, callers, callees, tests,
+//!   transitive callers/callees in that order), `OwnershipTeam`,
+//!   `OutsideOwnership` (items owned by other teams; empty without a team)
+//! - Item: `EntityKey`, `Name`, `Language`, `Relation`, `RelevanceScore`,
+//!   `FilePath`, `Owner`, `CodeOwners`, `Documentation`, `Summary`, `BodyIncluded`,
+//!   `Content` (`body` / `summary` / `signature` / `chunks`), `ChunksEmitted`,
+//!   `ChunkCount` (0 unless the body was chunked), `Text`
+//!
+//! ## Built-ins
+//!
+//! - `plain`: `// key (relation, content) file` comment headers (default)
+//! - `claude-xml`: one `<symbol>` element per item inside `<context>`,
+//!   attributes escaped, code left verbatim
+//! - `gpt-markdown`: `##` section per relation, `###` per symbol, fenced code
+//! - `markdown`: fenced code blocks in rank order, no sections
+//!
+//! The packer measures items with the selected template, so the token
+//! budget guarantee holds for the rendered template output.
+
+use serde_json::{json, Value};
+
+use crate::error::{ParseltongError, Result};
+use crate::token_budget_context_packer::{render_packed_context_text, PackedContextBundleResult, PackedContextItemEntry};
+
+/// Names accepted by `--template` besides a template file path
+pub const BUILT_IN_TEMPLATE_NAMES: [&str; 4] = ["plain", "claude-xml", "gpt-markdown", "markdown"];
+
+/// Relations in section order; later relations follow in first-seen order
+const SECTION_RELATION_ORDER: [(&str, &str); 8] = [
+    ("seed", "Seed"),
+    ("changed", "Changed"),
+    ("caller", "Callers"),
+    ("callee", "Callees"),
+    ("implementation", "Implementations"),
+    ("test", "Tests"),
+    ("transitive_caller", "Transitive callers"),
+    ("transitive_callee", "Transitive callees"),
+];
+
+/// `(name, arity)` of every template function
+const TEMPLATE_FUNCTION_TABLE: [(&str, usize); 8] = [
+    ("escapeXml", 1),
+    ("prefixLines", 2),
+    ("upper", 1),
+    ("lower", 1),
+    ("trimSpace", 1),
+    ("title", 1),
+    ("default", 2),
+    ("len", 1),
+];
+
+const CLAUDE_XML_TEMPLATE_TEXT: &str = r#"<context seed="{{escapeXml .SeedKey}}">
+{{- range .Items}}
+<symbol key="{{escapeXml .EntityKey}}" relation="{{.Relation}}" file="{{escapeXml .FilePath}}" content="{{.Content}}"{{if .ChunkCount}} chunks="{{.ChunksEmitted}}/{{.ChunkCount}}"{{end}}{{with .Owner}} owner="{{escapeXml .}}"{{end}}{{with .CodeOwners}} code_owners="{{escapeXml .}}"{{end}}>
+{{- with .Documentation}}
+<doc>
+{{trimSpace .}}
+</doc>
+{{- end}}
+{{- with .Summary}}
+<summary>{{escapeXml .}}</summary>
+{{- end}}
+<code>
+{{.Text}}
+</code>
+</symbol>
+{{- end}}
+{{- with .OutsideOwnership}}
+<outside_ownership team="{{escapeXml $.OwnershipTeam}}">
+{{- range .}}
+<symbol key="{{escapeXml .EntityKey}}" file="{{escapeXml .FilePath}}" code_owners="{{escapeXml .CodeOwners}}"/>
+{{- end}}
+</outside_ownership>
+{{- end}}
+</context>
+"#;
+
+const GPT_MARKDOWN_TEMPLATE_TEXT: &str = r#"# Context for `{{.SeedKey}}`
+{{range .Sections}}
+## {{.Title}}
+{{range .Items}}
+### `{{.EntityKey}}`
+
+{{.FilePath}} ({{.Content}}{{with .Owner}}, owner: {{.}}{{end}}{{with .CodeOwners}}, code owners: {{.}}{{end}})
+{{with .Documentation}}
+{{prefixLines "> " .}}{{end}}{{with .Summary}}
+_Summary: {{.}}_
+{{end}}
+```{{.Language}}
+{{.Text}}
+```
+{{end}}{{end}}{{with .OutsideOwnership}}
+## Outside {{$.OwnershipTeam}} ownership
+{{range .}}
+- `{{.EntityKey}}` ({{.FilePath}}) owned by {{.CodeOwners}}{{end}}
+{{end}}"#;
+
+const MARKDOWN_TEMPLATE_TEXT: &str = r#"{{range .Items}}**{{.EntityKey}}** ({{.Relation}}, {{.Content}}) `{{.FilePath}}`{{with .Owner}} owner: {{.}}{{end}}{{with .CodeOwners}} code owners: {{.}}{{end}}
+{{with .Documentation}}
+{{prefixLines "> " .}}{{end}}{{with .Summary}}
+_Summary: {{.}}_
+{{end}}
+```{{.Language}}
+{{.Text}}
+```
+
+{{end}}{{with .OutsideOwnership}}**Outside {{$.OwnershipTeam}} ownership**
+{{range .}}
+- `{{.EntityKey}}` ({{.FilePath}}) owned by {{.CodeOwners}}{{end}}
+{{end}}"#;
+
+/// How a context pack is rendered
+///
+/// # 4-Word Name: ContextPackOutputTemplate
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub enum ContextPackOutputTemplate {
+    /// `render_packed_context_text` comment headers
+    #[default]
+    Plain,
+    /// Parsed template text
+    Parsed(ParsedPromptTemplateDocument),
+}
+
+/// Parsed template ready for rendering
+///
+/// # 4-Word Name: ParsedPromptTemplateDocument
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ParsedPromptTemplateDocument {
+    nodes: Vec<TemplateNode>,
+}
+
+impl ContextPackOutputTemplate {
+    /// Template selected by `--template`: a built-in name or a file path
+    ///
+    /// # 4-Word Name: resolve_context_pack_template
+    ///
+    /// # Contract
+    /// - Precondition: `spec` names a built-in or an existing file
+    /// - Error: `ParseError` naming the built-ins for unknown names, or the
+    ///   line of a malformed template
+    pub fn resolve_context_pack_template(spec: &str) -> Result<Self> {
+        let text = match spec {
+            "plain" | "" => return Ok(Self::Plain),
+            "claude-xml" => CLAUDE_XML_TEMPLATE_TEXT.to_string(),
+            "gpt-markdown" => GPT_MARKDOWN_TEMPLATE_TEXT.to_string(),
+            "markdown" => MARKDOWN_TEMPLATE_TEXT.to_string(),
+            path => std::fs::read_to_string(path).map_err(|e| ParseltongError::ParseError {
+                reason: format!(
+                    "unknown template '{}' (built-in: {}; or a template file path): {}",
+                    path,
+                    BUILT_IN_TEMPLATE_NAMES.join(", "),
+                    e
+                ),
+                location: "--template".to_string(),
+            })?,
+        };
+        Ok(Self::Parsed(parse_prompt_template_text(&text)?))
+    }
+
+    /// Render a bundle with this template
+    ///
+    /// # 4-Word Name: render_context_pack_bundle
+    pub fn render_context_pack_bundle(&self, bundle: &PackedContextBundleResult) -> String {
+        match self {
+            Self::Plain => render_packed_context_text(bundle),
+            Self::Parsed(document) => {
+                let data = build_template_bundle_value(bundle);
+                let mut out = String::new();
+                render_template_nodes(&document.nodes, &data, &data, &mut out);
+                out
+            }
+        }
+    }
+}
+
+/// Parse template text
+///
+/// # 4-Word Name: parse_prompt_template_text
+///
+/// # Contract
+/// - Error: `ParseError` with the line of an unclosed action, unbalanced
+///   `{{end}}`, unknown function or wrong function arity
+pub fn parse_prompt_template_text(text: &str) -> Result<ParsedPromptTemplateDocument> {
+    let pieces = split_template_text_pieces(text)?;
+    let mut position = 0;
+    let (nodes, terminator) = parse_template_node_list(&pieces, &mut position)?;
+    if let Some((keyword, line)) = terminator {
+        return Err(template_parse_error(&format!("unexpected {{{{{}}}}}", keyword), line));
+    }
+    Ok(ParsedPromptTemplateDocument { nodes })
+}
+
+// ── Data model ───────────────────────────────────────────────────────────
+
+fn build_template_item_value(item: &PackedContextItemEntry) -> Value {
+    let mut segments = item.entity_key.split(':');
+    let language = segments.next().unwrap_or("").to_string();
+    let name = segments.nth(1).unwrap_or("").to_string();
+    json!({
+        "EntityKey": item.entity_key,
+        "Name": name,
+        "Language": language,
+        "Relation": item.relation,
+        "RelevanceScore": item.relevance_score,
+        "FilePath": item.file_path,
+        "Owner": item.owner,
+        "CodeOwners": item.code_owners,
+        "Documentation": item.documentation,
+        "GeneratedFrom": item.generated_from,
+        "Summary": item.summary,
+        "BodyIncluded": item.body_included,
+        "Content": match (&item.chunks, item.body_included) {
+            (Some(_), _) => "chunks",
+            (None, true) => "body",
+            (None, false) if item.summary.is_some() => "summary",
+            (None, false) => "signature",
+        },
+        "ChunksEmitted": item.chunks.as_ref().map_or(0, |chunks| chunks.emitted.len()),
+        "ChunkCount": item.chunks.as_ref().map_or(0, |chunks| chunks.spans.len()),
+        "Text": item.text,
+    })
+}
+
+fn build_template_bundle_value(bundle: &PackedContextBundleResult) -> Value {
+    let items: Vec<Value> = bundle.items.iter().map(build_template_item_value).collect();
+
+    let mut relations: Vec<&str> = SECTION_RELATION_ORDER.iter().map(|(relation, _)| *relation).collect();
+    for item in &bundle.items {
+        if !relations.contains(&item.relation.as_str()) {
+            relations.push(item.relation.as_str());
+        }
+    }
+    let sections: Vec<Value> = relations
+        .iter()
+        .filter_map(|relation| {
+            let members: Vec<Value> = bundle
+                .items
+                .iter()
+                .zip(&items)
+                .filter(|(item, _)| item.relation == *relation)
+                .map(|(_, value)| value.clone())
+                .collect();
+            if members.is_empty() {
+                return None;
+            }
+            let title = SECTION_RELATION_ORDER
+                .iter()
+                .find(|(known, _)| known == relation)
+                .map_or_else(|| relation.to_string(), |(_, title)| title.to_string());
+            Some(json!({ "Relation": relation, "Title": title, "Items": members }))
+        })
+        .collect();
+
+    json!({
+        "SeedKey": bundle.seed_key,
+        "TokenBudget": bundle.token_budget,
+        "ItemCount": bundle.items.len(),
+        "OutsideOwnership": bundle
+            .outside_team_ownership_items()
+            .into_iter()
+            .map(build_template_item_value)
+            .collect::<Vec<Value>>(),
+        "OwnershipTeam": bundle.ownership_team,
+        "Items": items,
+        "Sections": sections,
+    })
+}
+
+// ── Parser ───────────────────────────────────────────────────────────────
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+enum TemplateArgument {
+    /// `.A.B` (relative to dot) or `$.A.B` (relative to the bundle)
+    Field { from_root: bool, path: Vec<String> },
+    Dot,
+    Text(String),
+    Integer(i64),
+    Function(String),
+}
+
+/// `arg arg ... | fn arg ...`
+type TemplatePipeline = Vec<Vec<TemplateArgument>>;
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+enum TemplateNode {
+    Text(String),
+    Output(TemplatePipeline),
+    If { branches: Vec<(TemplatePipeline, Vec<TemplateNode>)>, otherwise: Vec<TemplateNode> },
+    Range { pipeline: TemplatePipeline, body: Vec<TemplateNode>, otherwise: Vec<TemplateNode> },
+    With { pipeline: TemplatePipeline, body: Vec<TemplateNode>, otherwise: Vec<TemplateNode> },
+}
+
+#[derive(Debug, Clone)]
+enum TemplatePiece {
+    Text(String),
+    /// Trimmed action text and its 1-based line
+    Action(String, usize),
+}
+
+/// Split into literal text and `{{ }}` actions, applying `{{-` / `-}}` trims
+fn split_template_text_pieces(text: &str) -> Result<Vec<TemplatePiece>> {
+    let mut pieces = Vec::new();
+    let mut rest = text;
+    let mut line = 1;
+    let mut trim_next_text = false;
+    while let Some(open) = rest.find("{{") {
+        let mut literal = &rest[..open];
+        if trim_next_text {
+            literal = literal.trim_start();
+        }
+        let after_open = &rest[open + 2..];
+        let close = after_open
+            .find("}}")
+            .ok_or_else(|| template_parse_error("unclosed {{", line + rest[..open].matches('\n').count()))?;
+        let mut action = &after_open[..close];
+        if action.starts_with('-') && action[1..].starts_with(char::is_whitespace) {
+            literal = literal.trim_end();
+            action = &action[1..];
+        }
+        trim_next_text = action.ends_with('-') && action[..action.len() - 1].ends_with(char::is_whitespace);
+        if trim_next_text {
+            action = &action[..action.len() - 1];
+        }
+        if !literal.is_empty() {
+            pieces.push(TemplatePiece::Text(literal.to_string()));
+        }
+        line += rest[..open].matches('\n').count();
+        let action = action.trim();
+        if !(action.starts_with("/*") && action.ends_with("*/")) {
+            pieces.push(TemplatePiece::Action(action.to_string(), line));
+        }
+        line += after_open[..close].matches('\n').count();
+        rest = &after_open[close + 2..];
+    }
+    let tail = if trim_next_text { rest.trim_start() } else { rest };
+    if !tail.is_empty() {
+        pieces.push(TemplatePiece::Text(tail.to_string()));
+    }
+    Ok(pieces)
+}
+
+/// Parse nodes up to `{{else ...}}` / `{{end}}`, returned as the terminator
+fn parse_template_node_list(
+    pieces: &[TemplatePiece],
+    position: &mut usize,
+) -> Result<(Vec<TemplateNode>, Option<(String, usize)>)> {
+    let mut nodes = Vec::new();
+    while let Some(piece) = pieces.get(*position) {
+        *position += 1;
+        let (action, line) = match piece {
+            TemplatePiece::Text(text) => {
+                nodes.push(TemplateNode::Text(text.clone()));
+                continue;
+            }
+            TemplatePiece::Action(action, line) => (action.as_str(), *line),
+        };
+        let (keyword, rest) = action.split_once(char::is_whitespace).unwrap_or((action, ""));
+        match keyword {
+            "end" | "else" => return Ok((nodes, Some((action.to_string(), line)))),
+            "if" => {
+                let mut branches = vec![(parse_template_pipeline(rest, line)?, Vec::new())];
+                let mut otherwise = Vec::new();
+                loop {
+                    let (body, terminator) = parse_template_node_list(pieces, position)?;
+                    let Some((terminator, end_line)) = terminator else {
+                        return Err(template_parse_error("{{if}} without {{end}}", line));
+                    };
+                    if let Some(condition) = terminator.strip_prefix("else if ") {
+                        branches.last_mut().expect("at least one branch").1 = body;
+                        branches.push((parse_template_pipeline(condition, end_line)?, Vec::new()));
+                    } else if terminator == "else" {
+                        branches.last_mut().expect("at least one branch").1 = body;
+                        otherwise = expect_end_after_else(pieces, position, "if", line)?;
+                        break;
+                    } else if terminator == "end" {
+                        branches.last_mut().expect("at least one branch").1 = body;
+                        break;
+                    } else {
+                        return Err(template_parse_error(&format!("unexpected {{{{{}}}}}", terminator), end_line));
+                    }
+                }
+                nodes.push(TemplateNode::If { branches, otherwise });
+            }
+            "range" | "with" => {
+                let pipeline = parse_template_pipeline(rest, line)?;
+                let (body, terminator) = parse_template_node_list(pieces, position)?;
+                let otherwise = match terminator.as_ref().map(|(t, _)| t.as_str()) {
+                    Some("end") => Vec::new(),
+                    Some("else") => expect_end_after_else(pieces, position, keyword, line)?,
+                    _ => return Err(template_parse_error(&format!("{{{{{}}}}} without {{{{end}}}}", keyword), line)),
+                };
+                nodes.push(if keyword == "range" {
+                    TemplateNode::Range { pipeline, body, otherwise }
+                } else {
+                    TemplateNode::With { pipeline, body, otherwise }
+                });
+            }
+            _ => nodes.push(TemplateNode::Output(parse_template_pipeline(action, line)?)),
+        }
+    }
+    Ok((nodes, None))
+}
+
+fn expect_end_after_else(pieces: &[TemplatePiece], position: &mut usize, keyword: &str, line: usize) -> Result<Vec<TemplateNode>> {
+    let (otherwise, terminator) = parse_template_node_list(pieces, position)?;
+    match terminator {
+        Some((end, _)) if end == "end" => Ok(otherwise),
+        _ => Err(template_parse_error(&format!("{{{{{}}}}} ... {{{{else}}}} without {{{{end}}}}", keyword), line)),
+    }
+}
+
+fn parse_template_pipeline(text: &str, line: usize) -> Result<TemplatePipeline> {
+    let words = split_template_action_words(text, line)?;
+    let mut pipeline: TemplatePipeline = vec![Vec::new()];
+    for word in words {
+        if word == "|" {
+            pipeline.push(Vec::new());
+            continue;
+        }
+        let argument = if let Some(quoted) = word.strip_prefix('"') {
+            TemplateArgument::Text(quoted.to_string())
+        } else if word == "." {
+            TemplateArgument::Dot
+        } else if let Some(path) = word.strip_prefix("$.") {
+            TemplateArgument::Field { from_root: true, path: path.split('.').map(str::to_string).collect() }
+        } else if word == "$" {
+            TemplateArgument::Field { from_root: true, path: Vec::new() }
+        } else if let Some(path) = word.strip_prefix('.') {
+            TemplateArgument::Field { from_root: false, path: path.split('.').map(str::to_string).collect() }
+        } else if let Ok(number) = word.parse::<i64>() {
+            TemplateArgument::Integer(number)
+        } else if TEMPLATE_FUNCTION_TABLE.iter().any(|(name, _)| *name == word) {
+            TemplateArgument::Function(word)
+        } else {
+            let known: Vec<&str> = TEMPLATE_FUNCTION_TABLE.iter().map(|(name, _)| *name).collect();
+            return Err(template_parse_error(&format!("unknown function '{}' (known: {})", word, known.join(", ")), line));
+        };
+        pipeline.last_mut().expect("pipeline has a command").push(argument);
+    }
+
+    for (index, command) in pipeline.iter().enumerate() {
+        let piped = usize::from(index > 0);
+        match command.first() {
+            None => return Err(template_parse_error("empty command in pipeline", line)),
+            Some(TemplateArgument::Function(name)) => {
+                let arity = TEMPLATE_FUNCTION_TABLE.iter().find(|(n, _)| *n == name.as_str()).map_or(0, |(_, a)| *a);
+                if command.len() - 1 + piped != arity {
+                    return Err(template_parse_error(&format!("{} takes {} argument(s)", name, arity), line));
+                }
+            }
+            Some(_) if command.len() > 1 || piped == 1 => {
+                return Err(template_parse_error("only functions take arguments or piped values", line))
+            }
+            Some(_) => {}
+        }
+    }
+    Ok(pipeline)
+}
+
+/// Words of an action; quoted strings come back as `"` + unescaped text
+fn split_template_action_words(text: &str, line: usize) -> Result<Vec<String>> {
+    let mut words = Vec::new();
+    let mut chars = text.chars().peekable();
+    while let Some(&c) = chars.peek() {
+        if c.is_whitespace() {
+            chars.next();
+        } else if c == '|' {
+            chars.next();
+            words.push("|".to_string());
+        } else if c == '"' {
+            chars.next();
+            let mut value = String::from("\"");
+            loop {
+                match chars.next() {
+                    None => return Err(template_parse_error("unterminated string", line)),
+                    Some('"') => break,
+                    Some('\\') => match chars.next() {
+                        Some('n') => value.push('\n'),
+                        Some('t') => value.push('\t'),
+                        Some(other) => value.push(other),
+                        None => return Err(template_parse_error("unterminated string", line)),
+                    },
+                    Some(other) => value.push(other),
+                }
+            }
+            words.push(value);
+        } else {
+            let mut word = String::new();
+            while let Some(&c) = chars.peek() {
+                if c.is_whitespace() || c == '|' {
+                    break;
+                }
+                word.push(c);
+                chars.next();
+            }
+            words.push(word);
+        }
+    }
+    Ok(words)
+}
+
+fn template_parse_error(reason: &str, line: usize) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: reason.to_string(),
+        location: format!("template line {}", line),
+    }
+}
+
+// ── Rendering ────────────────────────────────────────────────────────────
+
+fn render_template_nodes(nodes: &[TemplateNode], dot: &Value, root: &Value, out: &mut String) {
+    for node in nodes {
+        match node {
+            TemplateNode::Text(text) => out.push_str(text),
+            TemplateNode::Output(pipeline) => out.push_str(&template_value_text(&evaluate_template_pipeline(pipeline, dot, root))),
+            TemplateNode::If { branches, otherwise } => {
+                let chosen = branches
+                    .iter()
+                    .find(|(condition, _)| is_template_value_truthy(&evaluate_template_pipeline(condition, dot, root)))
+                    .map_or(otherwise, |(_, body)| body);
+                render_template_nodes(chosen, dot, root, out);
+            }
+            TemplateNode::Range { pipeline, body, otherwise } => match evaluate_template_pipeline(pipeline, dot, root) {
+                Value::Array(elements) if !elements.is_empty() => {
+                    for element in &elements {
+                        render_template_nodes(body, element, root, out);
+                    }
+                }
+                Value::Object(fields) if !fields.is_empty() => {
+                    for element in fields.values() {
+                        render_template_nodes(body, element, root, out);
+                    }
+                }
+                _ => render_template_nodes(otherwise, dot, root, out),
+            },
+            TemplateNode::With { pipeline, body, otherwise } => {
+                let value = evaluate_template_pipeline(pipeline, dot, root);
+                if is_template_value_truthy(&value) {
+                    render_template_nodes(body, &value, root, out);
+                } else {
+                    render_template_nodes(otherwise, dot, root, out);
+                }
+            }
+        }
+    }
+}
+
+fn evaluate_template_pipeline(pipeline: &TemplatePipeline, dot: &Value, root: &Value) -> Value {
+    let mut piped: Option<Value> = None;
+    for command in pipeline {
+        let mut arguments = command.iter();
+        let value = match arguments.next() {
+            Some(TemplateArgument::Function(name)) => {
+                let mut values: Vec<Value> = arguments.map(|a| evaluate_template_argument(a, dot, root)).collect();
+                values.extend(piped.take());
+                call_template_function(name, &values)
+            }
+            Some(argument) => evaluate_template_argument(argument, dot, root),
+            None => Value::Null,
+        };
+        piped = Some(value);
+    }
+    piped.unwrap_or(Value::Null)
+}
+
+fn evaluate_template_argument(argument: &TemplateArgument, dot: &Value, root: &Value) -> Value {
+    match argument {
+        TemplateArgument::Dot => dot.clone(),
+        TemplateArgument::Text(text) => Value::String(text.clone()),
+        TemplateArgument::Integer(number) => Value::from(*number),
+        TemplateArgument::Field { from_root, path } => {
+            let mut value = if *from_root { root } else { dot };
+            for field in path {
+                match value.get(field) {
+                    Some(next) => value = next,
+                    None => return Value::Null,
+                }
+            }
+            value.clone()
+        }
+        TemplateArgument::Function(name) => call_template_function(name, &[]),
+    }
+}
+
+fn call_template_function(name: &str, values: &[Value]) -> Value {
+    let text = |index: usize| values.get(index).map(template_value_text).unwrap_or_default();
+    match name {
+        "escapeXml" => Value::String(escape_xml_template_text(&text(0))),
+        "prefixLines" => {
+            let prefix = text(0);
+            Value::String(
+                text(1)
+                    .lines()
+                    .map(|line| format!("{}\n", format!("{}{}", prefix, line).trim_end()))
+                    .collect(),
+            )
+        }
+        "upper" => Value::String(text(0).to_uppercase()),
+        "lower" => Value::String(text(0).to_lowercase()),
+        "trimSpace" => Value::String(text(0).trim().to_string()),
+        "title" => {
+            let value = text(0);
+            let mut chars = value.chars();
+            Value::String(chars.next().map_or_else(String::new, |first| first.to_uppercase().chain(chars).collect()))
+        }
+        "default" => match values.get(1) {
+            Some(value) if is_template_value_truthy(value) => value.clone(),
+            _ => values.first().cloned().unwrap_or(Value::Null),
+        },
+        "len" => match values.first() {
+            Some(Value::Array(items)) => Value::from(items.len()),
+            Some(Value::Object(fields)) => Value::from(fields.len()),
+            Some(Value::String(s)) => Value::from(s.chars().count()),
+            _ => Value::from(0),
+        },
+        _ => Value::Null,
+    }
+}
+
+/// Go truthiness: false, 0, null and empty strings/lists/maps are false
+fn is_template_value_truthy(value: &Value) -> bool {
+    match value {
+        Value::Null => false,
+        Value::Bool(b) => *b,
+        Value::Number(n) => n.as_f64().is_some_and(|f| f != 0.0),
+        Value::String(s) => !s.is_empty(),
+        Value::Array(items) => !items.is_empty(),
+        Value::Object(fields) => !fields.is_empty(),
+    }
+}
+
+fn template_value_text(value: &Value) -> String {
+    match value {
+        Value::Null => String::new(),
+        Value::String(s) => s.clone(),
+        other => other.to_string(),
+    }
+}
+
+fn escape_xml_template_text(text: &str) -> String {
+    let mut escaped = String::with_capacity(text.len());
+    for c in text.chars() {
+        match c {
+            '&' => escaped.push_str("&amp;"),
+            '<' => escaped.push_str("&lt;"),
+            '>' => escaped.push_str("&gt;"),
+            '"' => escaped.push_str("&quot;"),
+            '\'' => escaped.push_str("&apos;"),
+            _ => escaped.push(c),
+        }
+    }
+    escaped
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn sample_context_bundle() -> PackedContextBundleResult {
+        let item = |key: &str, relation: &str, owner: Option<&str>, doc: Option<&str>| PackedContextItemEntry {
+            entity_key: key.to_string(),
+            relation: relation.to_string(),
+            relevance_score: 1.0,
+            file_path: "svc/user.go".to_string(),
+            owner: owner.map(str::to_string),
+            code_owners: None,
+            documentation: doc.map(str::to_string),
+            generated_from: None,
+            summary: None,
+            body_included: relation == "seed",
+            chunks: None,
+            text: "func CreateUser(u User) error".to_string(),
+            tokens: 0,
+        };
+        PackedContextBundleResult {
+            seed_key: "go:fn:CreateUser:__svc:T1".to_string(),
+            token_budget: 1000,
+            tokens_used: 0,
+            candidates_considered: 3,
+            items: vec![
+                item("go:fn:CreateUser:__svc:T1", "seed", Some("Ada"), Some("CreateUser stores a user.")),
+                item("go:fn:main:__cmd:T2", "caller", None, None),
+                item("go:fn:HandleSignup:__api:T3", "caller", None, None),
+            ],
+            ownership_team: None,
+            tokenizer: Default::default(),
+            truncation: None,
+        }
+    }
+
+    #[test]
+    fn test_template_syntax_sections_and_trimming() {
+        let template = parse_prompt_template_text(
+            "{{range .Sections -}}\n[{{.Title}}:{{len .Items}}]{{range .Items}} {{.Name | upper}}{{if .Owner}}@{{.Owner}}{{else if .BodyIncluded}}!{{else}}-{{end}}{{end}}\n{{- /* comment */}}\n{{end}}{{with $.Missing}}x{{else}}{{default \"none\" $.Missing}}{{end}}",
+        )
+        .unwrap();
+        let rendered = ContextPackOutputTemplate::Parsed(template).render_context_pack_bundle(&sample_context_bundle());
+        assert_eq!(rendered, "[Seed:1] CREATEUSER@Ada\n[Callers:2] MAIN- HANDLESIGNUP-\nnone");
+
+        for broken in ["{{if .Owner}}open", "{{end}}", "{{shout .Name}}", "{{prefixLines .Text}}", "{{.Name"] {
+            assert!(parse_prompt_template_text(broken).is_err(), "{}", broken);
+        }
+    }
+
+    #[test]
+    fn test_builtin_templates_resolve_and_render() {
+        let bundle = sample_context_bundle();
+        for name in BUILT_IN_TEMPLATE_NAMES {
+            let template = ContextPackOutputTemplate::resolve_context_pack_template(name).unwrap();
+            let rendered = template.render_context_pack_bundle(&bundle);
+            assert!(rendered.contains("func CreateUser(u User) error"), "{}", name);
+        }
+        let xml = ContextPackOutputTemplate::resolve_context_pack_template("claude-xml").unwrap().render_context_pack_bundle(&bundle);
+        assert!(xml.starts_with("<context seed=\"go:fn:CreateUser:__svc:T1\">\n<symbol key="));
+        assert!(xml.contains("owner=\"Ada\">\n<doc>\nCreateUser stores a user.\n</doc>\n<code>"));
+        let markdown = ContextPackOutputTemplate::resolve_context_pack_template("gpt-markdown").unwrap().render_context_pack_bundle(&bundle);
+        assert!(markdown.contains("## Callers\n") && markdown.contains("```go\n") && markdown.contains("> CreateUser stores a user.\n"));
+        assert!(ContextPackOutputTemplate::resolve_context_pack_template("no-such-template").is_err());
+    }
+}
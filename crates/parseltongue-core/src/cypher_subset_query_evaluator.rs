@@ -0,0 +1,1548 @@
+//! Cypher Subset Query Evaluator (v1.7.3)
+//!
+//! # 4-Word Naming: cypher_subset_query_evaluator
+//!
+//! Read-only `MATCH ... WHERE ... RETURN` over the dependency graph, so
+//! Neo4j query snippets mostly run unchanged against the daemon's
+//! `/cypher-subset-query-execute` endpoint:
+//!
+//! ```text
+//! MATCH (caller:Function)-[:CALLS]->(target:Method {name: 'CreateUser'})
+//! WHERE caller.file STARTS WITH 'api/' AND NOT caller.name = 'main'
+//! RETURN DISTINCT caller.name, caller.file ORDER BY caller.name LIMIT 20
+//!
+//! MATCH (s:Struct)<-[:IMPLEMENTS|EMBEDS*1..3]-(t) RETURN s.name, count(t) AS n ORDER BY n DESC
+//! ```
+//!
+//! ## Supported
+//!
+//! - One `MATCH` with comma-separated pattern chains; `-[]->`, `<-[]-`,
+//!   `-[]-`, `-->`, `<--`, `--`; `[r:A|B]`, `[:A*]`, `*N`, `*N..M`, `*..M`
+//!   (unbounded lengths stop at 10 hops)
+//! - Node labels: `Function`, `Method`, `Struct`, ... (the key's kind in
+//!   UpperCamel, or the raw kind: `fn`), `External` for unresolved call
+//!   targets, `Entity`/`Node`/`Symbol` for any node. Relationship types are
+//!   the edge types in SCREAMING_SNAKE: `CALLS`, `SENDS_TO`, ...
+//! - Node properties: `key`, `name`, `kind`, `language`, `file`, `line`,
+//!   `line_end`, `visibility`, `external`, plus any extractor metadata key
+//!   (`receiver_type`, ...); relationship properties `type`, `location`
+//! - `WHERE`: `AND OR XOR NOT`, `= <> < <= > >=`, `CONTAINS`,
+//!   `STARTS WITH`, `ENDS WITH`, `IN [...]`, `IS [NOT] NULL`
+//! - `RETURN [DISTINCT] expr [AS alias], ...`, aggregates `count(*)`,
+//!   `count([DISTINCT] x)`, `collect(x)`; functions `type labels id
+//!   toLower toUpper size`; `ORDER BY ... [ASC|DESC]`, `SKIP`, `LIMIT`
+//!
+//! Writing clauses (`CREATE`, `MERGE`, `SET`, `DELETE`, ...) are rejected.
+//! A variable-length relationship matches each reachable end node once,
+//! rather than once per path as Neo4j does.
+
+use std::cmp::Ordering;
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+
+use serde::Serialize;
+use serde_json::Value;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::error::{ParseltongError, Result};
+use crate::filtered_graph_traversal_queries::{all_edge_type_variants_list, is_placeholder_target_key};
+
+/// Hop cap of `*`, `*N..` and `*..M` over M
+pub const MAX_VARIABLE_LENGTH_HOPS: usize = 10;
+
+/// Pattern bindings enumerated before a query is aborted
+pub const MAX_CYPHER_MATCH_BINDINGS: usize = 1_000_000;
+
+/// Clauses that would modify a graph database
+const WRITING_CLAUSE_KEYWORDS: [&str; 9] =
+    ["CREATE", "MERGE", "SET", "DELETE", "DETACH", "REMOVE", "FOREACH", "LOAD", "DROP"];
+
+/// Tabular result of a Cypher query
+///
+/// # 4-Word Name: CypherQueryResultPayload
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct CypherQueryResultPayload {
+    pub query: String,
+    /// Alias or expression text of each RETURN item
+    pub columns: Vec<String>,
+    /// Rows before SKIP / LIMIT
+    pub total_count: usize,
+    pub truncated: bool,
+    pub rows: Vec<Vec<Value>>,
+}
+
+/// Neo4j label of a node kind (`fn` → `Function`, `type_alias` → `TypeAlias`)
+///
+/// # 4-Word Name: cypher_node_label_for_kind
+pub fn cypher_node_label_for_kind(kind: &str) -> String {
+    match kind {
+        "fn" => "Function".to_string(),
+        "" => "Entity".to_string(),
+        other => other
+            .split(|c: char| c == '_' || c == '-')
+            .filter(|part| !part.is_empty())
+            .map(|part| {
+                let mut chars = part.chars();
+                chars.next().map_or_else(String::new, |first| first.to_uppercase().chain(chars).collect())
+            })
+            .collect(),
+    }
+}
+
+/// Neo4j relationship type of an edge type (`SendsTo` → `SENDS_TO`)
+///
+/// # 4-Word Name: cypher_relationship_type_name
+pub fn cypher_relationship_type_name(edge_type: EdgeType) -> String {
+    let mut name = String::new();
+    for (index, c) in edge_type.as_str().chars().enumerate() {
+        if c.is_ascii_uppercase() && index > 0 {
+            name.push('_');
+        }
+        name.push(c.to_ascii_uppercase());
+    }
+    name
+}
+
+/// Parse and run a read-only Cypher query against an in-memory graph
+///
+/// # 4-Word Name: execute_cypher_subset_query
+///
+/// # Contract
+/// - Postcondition: rows in match order unless `ORDER BY` is given
+/// - Error: `ParseError` for syntax outside the subset, writing clauses,
+///   unknown labels/relationship types or more than
+///   `MAX_CYPHER_MATCH_BINDINGS` pattern matches
+pub fn execute_cypher_subset_query(
+    text: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> Result<CypherQueryResultPayload> {
+    let query = parse_cypher_subset_query(text)?;
+    let graph = CypherGraphIndex::from_graph(entities, edges);
+    let (total_count, rows) = project_query_result_rows(&query, &graph)?;
+    let shown = rows.len();
+    Ok(CypherQueryResultPayload {
+        query: text.trim().to_string(),
+        columns: query.items.iter().map(|item| item.column.clone()).collect(),
+        total_count,
+        truncated: query.skip + shown < total_count,
+        rows,
+    })
+}
+
+// ── AST ──────────────────────────────────────────────────────────────────
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum RelationshipDirection {
+    Outgoing,
+    Incoming,
+    Either,
+}
+
+#[derive(Debug, Clone, Default)]
+struct CypherNodePattern {
+    variable: Option<String>,
+    labels: Vec<String>,
+    properties: Vec<(String, Value)>,
+}
+
+#[derive(Debug, Clone)]
+struct CypherRelationshipPattern {
+    variable: Option<String>,
+    types: Vec<EdgeType>,
+    direction: RelationshipDirection,
+    /// `Some((min, max))` for `*` patterns
+    hops: Option<(usize, usize)>,
+}
+
+#[derive(Debug, Clone)]
+struct CypherPatternChain {
+    start: CypherNodePattern,
+    steps: Vec<(CypherRelationshipPattern, CypherNodePattern)>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum CypherBinaryOperator {
+    Or,
+    Xor,
+    And,
+    Eq,
+    Ne,
+    Lt,
+    Le,
+    Gt,
+    Ge,
+    Contains,
+    StartsWith,
+    EndsWith,
+    In,
+}
+
+#[derive(Debug, Clone)]
+enum CypherExpression {
+    Literal(Value),
+    List(Vec<CypherExpression>),
+    Variable(String),
+    Property(Box<CypherExpression>, String),
+    Function { name: String, distinct: bool, args: Vec<CypherExpression> },
+    CountStar,
+    Binary(CypherBinaryOperator, Box<CypherExpression>, Box<CypherExpression>),
+    Not(Box<CypherExpression>),
+    Negate(Box<CypherExpression>),
+    IsNull { operand: Box<CypherExpression>, negated: bool },
+}
+
+#[derive(Debug, Clone)]
+struct CypherReturnItem {
+    expression: CypherExpression,
+    column: String,
+}
+
+#[derive(Debug, Clone)]
+struct CypherOrderItem {
+    expression: CypherExpression,
+    text: String,
+    descending: bool,
+}
+
+#[derive(Debug, Clone)]
+struct ParsedCypherQuery {
+    patterns: Vec<CypherPatternChain>,
+    filter: Option<CypherExpression>,
+    distinct: bool,
+    items: Vec<CypherReturnItem>,
+    order_by: Vec<CypherOrderItem>,
+    skip: usize,
+    limit: Option<usize>,
+}
+
+// ── Tokenizer ────────────────────────────────────────────────────────────
+
+#[derive(Debug, Clone, PartialEq)]
+enum CypherTokenKind {
+    /// Identifier or keyword (backtick-quoted identifiers included)
+    Word(String),
+    Text(String),
+    Integer(i64),
+    Symbol(&'static str),
+}
+
+#[derive(Debug, Clone)]
+struct CypherToken {
+    kind: CypherTokenKind,
+    /// Character offsets into the query text
+    start: usize,
+    end: usize,
+}
+
+const MULTI_CHAR_SYMBOLS: [&str; 5] = ["<>", "<=", ">=", "=~", ".."];
+const SINGLE_CHAR_SYMBOLS: [&str; 16] = ["(", ")", "[", "]", "{", "}", ":", ",", ".", "|", "*", "-", "<", ">", "=", "+"];
+
+fn tokenize_cypher_query_text(chars: &[char]) -> Result<Vec<CypherToken>> {
+    let mut tokens = Vec::new();
+    let mut index = 0;
+    while index < chars.len() {
+        let c = chars[index];
+        let start = index;
+        if c.is_whitespace() {
+            index += 1;
+            continue;
+        }
+        if c == '/' && chars.get(index + 1) == Some(&'/') {
+            while index < chars.len() && chars[index] != '\n' {
+                index += 1;
+            }
+            continue;
+        }
+        let kind = if c == '\'' || c == '"' {
+            index += 1;
+            let mut value = String::new();
+            loop {
+                match chars.get(index) {
+                    None => return Err(cypher_syntax_error("unterminated string literal", start)),
+                    Some(&'\\') => {
+                        match chars.get(index + 1) {
+                            Some('n') => value.push('\n'),
+                            Some('t') => value.push('\t'),
+                            Some(&other) => value.push(other),
+                            None => return Err(cypher_syntax_error("unterminated string literal", start)),
+                        }
+                        index += 2;
+                    }
+                    Some(&quote) if quote == c => {
+                        index += 1;
+                        break;
+                    }
+                    Some(&other) => {
+                        value.push(other);
+                        index += 1;
+                    }
+                }
+            }
+            CypherTokenKind::Text(value)
+        } else if c == '`' {
+            let close = chars[index + 1..]
+                .iter()
+                .position(|&d| d == '`')
+                .ok_or_else(|| cypher_syntax_error("unterminated `identifier`", start))?;
+            let word: String = chars[index + 1..index + 1 + close].iter().collect();
+            index += close + 2;
+            CypherTokenKind::Word(word)
+        } else if c.is_ascii_digit() {
+            while index < chars.len() && chars[index].is_ascii_digit() {
+                index += 1;
+            }
+            let digits: String = chars[start..index].iter().collect();
+            let value = digits.parse().map_err(|_| cypher_syntax_error("integer out of range", start))?;
+            CypherTokenKind::Integer(value)
+        } else if c.is_alphabetic() || c == '_' {
+            while index < chars.len() && (chars[index].is_alphanumeric() || chars[index] == '_') {
+                index += 1;
+            }
+            CypherTokenKind::Word(chars[start..index].iter().collect())
+        } else if c == '$' {
+            return Err(cypher_syntax_error("query parameters ($name) are not supported; inline the value", start));
+        } else {
+            let pair: String = chars[index..(index + 2).min(chars.len())].iter().collect();
+            if let Some(&symbol) = MULTI_CHAR_SYMBOLS.iter().find(|s| **s == pair) {
+                index += 2;
+                CypherTokenKind::Symbol(symbol)
+            } else if let Some(&symbol) = SINGLE_CHAR_SYMBOLS.iter().find(|s| s.starts_with(c)) {
+                index += 1;
+                CypherTokenKind::Symbol(symbol)
+            } else {
+                return Err(cypher_syntax_error(&format!("unexpected character `{}`", c), start));
+            }
+        };
+        tokens.push(CypherToken { kind, start, end: index });
+    }
+    Ok(tokens)
+}
+
+fn cypher_syntax_error(reason: &str, offset: usize) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: reason.to_string(),
+        location: format!("cypher column {}", offset + 1),
+    }
+}
+
+// ── Parser ───────────────────────────────────────────────────────────────
+
+struct CypherTokenParser<'t> {
+    chars: &'t [char],
+    tokens: &'t [CypherToken],
+    position: usize,
+}
+
+/// Parse query text into the supported subset
+fn parse_cypher_subset_query(text: &str) -> Result<ParsedCypherQuery> {
+    let chars: Vec<char> = text.chars().collect();
+    let tokens = tokenize_cypher_query_text(&chars)?;
+    if let Some(token) = tokens.iter().find(|t| {
+        matches!(&t.kind, CypherTokenKind::Word(w) if WRITING_CLAUSE_KEYWORDS.iter().any(|k| w.eq_ignore_ascii_case(k)))
+    }) {
+        return Err(cypher_syntax_error("the graph is read-only: writing clauses are not supported", token.start));
+    }
+    let mut parser = CypherTokenParser { chars: &chars, tokens: &tokens, position: 0 };
+    parser.parse_query()
+}
+
+impl<'t> CypherTokenParser<'t> {
+    fn peek(&self) -> Option<&'t CypherToken> {
+        self.tokens.get(self.position)
+    }
+
+    fn peek_is_symbol(&self, symbol: &str) -> bool {
+        matches!(self.peek(), Some(CypherToken { kind: CypherTokenKind::Symbol(s), .. }) if *s == symbol)
+    }
+
+    fn peek_is_keyword(&self, keyword: &str) -> bool {
+        self.peek_keyword_at(0, keyword)
+    }
+
+    fn peek_keyword_at(&self, ahead: usize, keyword: &str) -> bool {
+        matches!(self.tokens.get(self.position + ahead), Some(CypherToken { kind: CypherTokenKind::Word(w), .. }) if w.eq_ignore_ascii_case(keyword))
+    }
+
+    fn eat_symbol(&mut self, symbol: &str) -> bool {
+        let found = self.peek_is_symbol(symbol);
+        if found {
+            self.position += 1;
+        }
+        found
+    }
+
+    fn eat_keyword(&mut self, keyword: &str) -> bool {
+        let found = self.peek_is_keyword(keyword);
+        if found {
+            self.position += 1;
+        }
+        found
+    }
+
+    fn expect_symbol(&mut self, symbol: &str) -> Result<()> {
+        if self.eat_symbol(symbol) {
+            Ok(())
+        } else {
+            Err(self.unexpected(&format!("`{}`", symbol)))
+        }
+    }
+
+    fn expect_keyword(&mut self, keyword: &str) -> Result<()> {
+        if self.eat_keyword(keyword) {
+            Ok(())
+        } else {
+            Err(self.unexpected(keyword))
+        }
+    }
+
+    fn expect_identifier(&mut self, what: &str) -> Result<String> {
+        match self.peek() {
+            Some(CypherToken { kind: CypherTokenKind::Word(word), .. }) => {
+                self.position += 1;
+                Ok(word.clone())
+            }
+            _ => Err(self.unexpected(what)),
+        }
+    }
+
+    fn expect_count(&mut self, what: &str) -> Result<usize> {
+        match self.peek() {
+            Some(CypherToken { kind: CypherTokenKind::Integer(value), .. }) if *value >= 0 => {
+                self.position += 1;
+                Ok(*value as usize)
+            }
+            _ => Err(self.unexpected(what)),
+        }
+    }
+
+    fn unexpected(&self, expected: &str) -> ParseltongError {
+        match self.peek() {
+            Some(token) => {
+                let found: String = self.chars[token.start..token.end].iter().collect();
+                cypher_syntax_error(&format!("expected {}, found `{}`", expected, found), token.start)
+            }
+            None => cypher_syntax_error(&format!("expected {}, found end of query", expected), self.chars.len()),
+        }
+    }
+
+    /// Source text from token `from` up to the current position
+    fn source_text_since(&self, from: usize) -> String {
+        let start = self.tokens[from].start;
+        let end = self.tokens[self.position - 1].end;
+        self.chars[start..end].iter().collect()
+    }
+
+    fn parse_query(&mut self) -> Result<ParsedCypherQuery> {
+        if self.peek_is_keyword("OPTIONAL") || self.peek_is_keyword("UNWIND") || self.peek_is_keyword("CALL") {
+            return Err(self.unexpected("MATCH (OPTIONAL MATCH, UNWIND and CALL are not supported)"));
+        }
+        self.expect_keyword("MATCH")?;
+        let mut patterns = vec![self.parse_pattern_chain()?];
+        while self.eat_symbol(",") {
+            patterns.push(self.parse_pattern_chain()?);
+        }
+        let filter = if self.eat_keyword("WHERE") { Some(self.parse_expression()?) } else { None };
+        if self.peek_is_keyword("MATCH") || self.peek_is_keyword("WITH") {
+            return Err(self.unexpected("RETURN (only a single MATCH ... RETURN is supported)"));
+        }
+        self.expect_keyword("RETURN")?;
+        let distinct = self.eat_keyword("DISTINCT");
+        let mut items = vec![self.parse_return_item()?];
+        while self.eat_symbol(",") {
+            items.push(self.parse_return_item()?);
+        }
+
+        let mut order_by = Vec::new();
+        if self.eat_keyword("ORDER") {
+            self.expect_keyword("BY")?;
+            loop {
+                let from = self.position;
+                let expression = self.parse_expression()?;
+                let text = self.source_text_since(from);
+                let descending = if self.eat_keyword("DESC") || self.eat_keyword("DESCENDING") {
+                    true
+                } else {
+                    let _ = self.eat_keyword("ASC") || self.eat_keyword("ASCENDING");
+                    false
+                };
+                order_by.push(CypherOrderItem { expression, text, descending });
+                if !self.eat_symbol(",") {
+                    break;
+                }
+            }
+        }
+        let skip = if self.eat_keyword("SKIP") { self.expect_count("a SKIP count")? } else { 0 };
+        let limit = if self.eat_keyword("LIMIT") { Some(self.expect_count("a LIMIT count")?) } else { None };
+        let _ = self.eat_symbol(";");
+        if self.peek().is_some() {
+            return Err(self.unexpected("end of query"));
+        }
+        Ok(ParsedCypherQuery { patterns, filter, distinct, items, order_by, skip, limit })
+    }
+
+    fn parse_return_item(&mut self) -> Result<CypherReturnItem> {
+        let from = self.position;
+        let expression = self.parse_expression()?;
+        let mut column = self.source_text_since(from);
+        if self.eat_keyword("AS") {
+            column = self.expect_identifier("an alias after AS")?;
+        }
+        Ok(CypherReturnItem { expression, column })
+    }
+
+    fn parse_pattern_chain(&mut self) -> Result<CypherPatternChain> {
+        let start = self.parse_node_pattern()?;
+        let mut steps = Vec::new();
+        while self.peek_is_symbol("-") || self.peek_is_symbol("<") {
+            let relationship = self.parse_relationship_pattern()?;
+            steps.push((relationship, self.parse_node_pattern()?));
+        }
+        Ok(CypherPatternChain { start, steps })
+    }
+
+    fn parse_node_pattern(&mut self) -> Result<CypherNodePattern> {
+        self.expect_symbol("(")?;
+        let mut node = CypherNodePattern::default();
+        if let Some(CypherToken { kind: CypherTokenKind::Word(word), .. }) = self.peek() {
+            node.variable = Some(word.clone());
+            self.position += 1;
+        }
+        while self.eat_symbol(":") {
+            node.labels.push(self.expect_identifier("a node label")?);
+        }
+        if self.peek_is_symbol("{") {
+            node.properties = self.parse_property_map()?;
+        }
+        self.expect_symbol(")")?;
+        Ok(node)
+    }
+
+    fn parse_property_map(&mut self) -> Result<Vec<(String, Value)>> {
+        self.expect_symbol("{")?;
+        let mut properties = Vec::new();
+        if !self.peek_is_symbol("}") {
+            loop {
+                let name = self.expect_identifier("a property name")?;
+                self.expect_symbol(":")?;
+                let value = match self.parse_unary_operand()? {
+                    CypherExpression::Literal(value) => value,
+                    _ => return Err(self.unexpected("a literal property value")),
+                };
+                properties.push((name, value));
+                if !self.eat_symbol(",") {
+                    break;
+                }
+            }
+        }
+        self.expect_symbol("}")?;
+        Ok(properties)
+    }
+
+    fn parse_relationship_pattern(&mut self) -> Result<CypherRelationshipPattern> {
+        let incoming = self.eat_symbol("<");
+        self.expect_symbol("-")?;
+        let mut relationship = CypherRelationshipPattern {
+            variable: None,
+            types: Vec::new(),
+            direction: RelationshipDirection::Either,
+            hops: None,
+        };
+        if self.eat_symbol("[") {
+            if let Some(CypherToken { kind: CypherTokenKind::Word(word), .. }) = self.peek() {
+                relationship.variable = Some(word.clone());
+                self.position += 1;
+            }
+            if self.eat_symbol(":") {
+                loop {
+                    let _ = self.eat_symbol(":");
+                    let from = self.position;
+                    let name = self.expect_identifier("a relationship type")?;
+                    let edge_type = edge_type_for_relationship_name(&name)
+                        .ok_or_else(|| cypher_syntax_error(&unknown_relationship_message(&name), self.tokens[from].start))?;
+                    relationship.types.push(edge_type);
+                    if !self.eat_symbol("|") {
+                        break;
+                    }
+                }
+            }
+            if self.eat_symbol("*") {
+                relationship.hops = Some(self.parse_hop_range()?);
+            }
+            if self.peek_is_symbol("{") {
+                return Err(self.unexpected("`]` (relationship property maps are not supported; use WHERE)"));
+            }
+            self.expect_symbol("]")?;
+        }
+        self.expect_symbol("-")?;
+        let outgoing = self.eat_symbol(">");
+        relationship.direction = match (incoming, outgoing) {
+            (true, true) => return Err(self.unexpected("a node pattern (`<-...->` has no direction)")),
+            (true, false) => RelationshipDirection::Incoming,
+            (false, true) => RelationshipDirection::Outgoing,
+            (false, false) => RelationshipDirection::Either,
+        };
+        if relationship.hops.is_some() && relationship.variable.is_some() {
+            return Err(self.unexpected("a node pattern (variable-length relationships cannot be bound to a variable)"));
+        }
+        Ok(relationship)
+    }
+
+    /// After `*`: ``, `N`, `N..`, `..M`, `N..M`
+    fn parse_hop_range(&mut self) -> Result<(usize, usize)> {
+        let low = if matches!(self.peek(), Some(CypherToken { kind: CypherTokenKind::Integer(_), .. })) {
+            Some(self.expect_count("a hop count")?)
+        } else {
+            None
+        };
+        if !self.eat_symbol("..") {
+            return Ok(match low {
+                Some(exact) => (exact, exact.min(MAX_VARIABLE_LENGTH_HOPS)),
+                None => (1, MAX_VARIABLE_LENGTH_HOPS),
+            });
+        }
+        let high = if matches!(self.peek(), Some(CypherToken { kind: CypherTokenKind::Integer(_), .. })) {
+            self.expect_count("a hop count")?
+        } else {
+            MAX_VARIABLE_LENGTH_HOPS
+        };
+        Ok((low.unwrap_or(1), high.min(MAX_VARIABLE_LENGTH_HOPS)))
+    }
+
+    fn parse_expression(&mut self) -> Result<CypherExpression> {
+        let mut left = self.parse_xor_expression()?;
+        while self.eat_keyword("OR") {
+            let right = self.parse_xor_expression()?;
+            left = CypherExpression::Binary(CypherBinaryOperator::Or, Box::new(left), Box::new(right));
+        }
+        Ok(left)
+    }
+
+    fn parse_xor_expression(&mut self) -> Result<CypherExpression> {
+        let mut left = self.parse_and_expression()?;
+        while self.eat_keyword("XOR") {
+            let right = self.parse_and_expression()?;
+            left = CypherExpression::Binary(CypherBinaryOperator::Xor, Box::new(left), Box::new(right));
+        }
+        Ok(left)
+    }
+
+    fn parse_and_expression(&mut self) -> Result<CypherExpression> {
+        let mut left = self.parse_not_expression()?;
+        while self.eat_keyword("AND") {
+            let right = self.parse_not_expression()?;
+            left = CypherExpression::Binary(CypherBinaryOperator::And, Box::new(left), Box::new(right));
+        }
+        Ok(left)
+    }
+
+    fn parse_not_expression(&mut self) -> Result<CypherExpression> {
+        if self.eat_keyword("NOT") {
+            return Ok(CypherExpression::Not(Box::new(self.parse_not_expression()?)));
+        }
+        self.parse_comparison_expression()
+    }
+
+    fn parse_comparison_expression(&mut self) -> Result<CypherExpression> {
+        let left = self.parse_unary_operand()?;
+        if self.eat_keyword("IS") {
+            let negated = self.eat_keyword("NOT");
+            self.expect_keyword("NULL")?;
+            return Ok(CypherExpression::IsNull { operand: Box::new(left), negated });
+        }
+        if self.peek_is_symbol("=~") {
+            return Err(self.unexpected("an operator (`=~` regular expressions are not supported; use CONTAINS / STARTS WITH / ENDS WITH)"));
+        }
+        let operator = if self.eat_symbol("=") {
+            CypherBinaryOperator::Eq
+        } else if self.eat_symbol("<>") {
+            CypherBinaryOperator::Ne
+        } else if self.eat_symbol("<=") {
+            CypherBinaryOperator::Le
+        } else if self.eat_symbol(">=") {
+            CypherBinaryOperator::Ge
+        } else if self.eat_symbol("<") {
+            CypherBinaryOperator::Lt
+        } else if self.eat_symbol(">") {
+            CypherBinaryOperator::Gt
+        } else if self.eat_keyword("CONTAINS") {
+            CypherBinaryOperator::Contains
+        } else if self.eat_keyword("IN") {
+            CypherBinaryOperator::In
+        } else if self.peek_is_keyword("STARTS") && self.peek_keyword_at(1, "WITH") {
+            self.position += 2;
+            CypherBinaryOperator::StartsWith
+        } else if self.peek_is_keyword("ENDS") && self.peek_keyword_at(1, "WITH") {
+            self.position += 2;
+            CypherBinaryOperator::EndsWith
+        } else {
+            return Ok(left);
+        };
+        let right = self.parse_unary_operand()?;
+        Ok(CypherExpression::Binary(operator, Box::new(left), Box::new(right)))
+    }
+
+    fn parse_unary_operand(&mut self) -> Result<CypherExpression> {
+        if self.eat_symbol("-") {
+            return Ok(CypherExpression::Negate(Box::new(self.parse_unary_operand()?)));
+        }
+        let mut operand = self.parse_primary_operand()?;
+        while self.eat_symbol(".") {
+            let property = self.expect_identifier("a property name")?;
+            operand = CypherExpression::Property(Box::new(operand), property);
+        }
+        Ok(operand)
+    }
+
+    fn parse_primary_operand(&mut self) -> Result<CypherExpression> {
+        let Some(token) = self.peek() else { return Err(self.unexpected("an expression")) };
+        match &token.kind {
+            CypherTokenKind::Text(value) => {
+                self.position += 1;
+                Ok(CypherExpression::Literal(Value::String(value.clone())))
+            }
+            CypherTokenKind::Integer(value) => {
+                self.position += 1;
+                Ok(CypherExpression::Literal(Value::from(*value)))
+            }
+            CypherTokenKind::Symbol("(") => {
+                self.position += 1;
+                let inner = self.parse_expression()?;
+                self.expect_symbol(")")?;
+                Ok(inner)
+            }
+            CypherTokenKind::Symbol("[") => {
+                self.position += 1;
+                let mut elements = Vec::new();
+                if !self.peek_is_symbol("]") {
+                    loop {
+                        elements.push(self.parse_expression()?);
+                        if !self.eat_symbol(",") {
+                            break;
+                        }
+                    }
+                }
+                self.expect_symbol("]")?;
+                Ok(CypherExpression::List(elements))
+            }
+            CypherTokenKind::Word(word) => {
+                self.position += 1;
+                if word.eq_ignore_ascii_case("true") || word.eq_ignore_ascii_case("false") {
+                    return Ok(CypherExpression::Literal(Value::Bool(word.eq_ignore_ascii_case("true"))));
+                }
+                if word.eq_ignore_ascii_case("null") {
+                    return Ok(CypherExpression::Literal(Value::Null));
+                }
+                if !self.eat_symbol("(") {
+                    return Ok(CypherExpression::Variable(word.clone()));
+                }
+                let name = word.to_ascii_lowercase();
+                if name == "count" && self.eat_symbol("*") {
+                    self.expect_symbol(")")?;
+                    return Ok(CypherExpression::CountStar);
+                }
+                let distinct = self.eat_keyword("DISTINCT");
+                let mut args = Vec::new();
+                if !self.peek_is_symbol(")") {
+                    loop {
+                        args.push(self.parse_expression()?);
+                        if !self.eat_symbol(",") {
+                            break;
+                        }
+                    }
+                }
+                self.expect_symbol(")")?;
+                Ok(CypherExpression::Function { name, distinct, args })
+            }
+            _ => Err(self.unexpected("an expression")),
+        }
+    }
+}
+
+/// `CALLS`, `calls`, `SENDS_TO`, `SendsTo` → the edge type
+fn edge_type_for_relationship_name(name: &str) -> Option<EdgeType> {
+    let wanted: String = name.chars().filter(|c| *c != '_').collect();
+    all_edge_type_variants_list().into_iter().find(|t| t.as_str().eq_ignore_ascii_case(&wanted))
+}
+
+fn unknown_relationship_message(name: &str) -> String {
+    let known: Vec<String> = all_edge_type_variants_list().into_iter().map(cypher_relationship_type_name).collect();
+    format!("unknown relationship type `{}` (known: {})", name, known.join(", "))
+}
+
+// ── Graph index and matching ─────────────────────────────────────────────
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum BoundGraphElement {
+    Node(usize),
+    Relationship(usize),
+}
+
+type CypherBindingRow = Vec<(String, BoundGraphElement)>;
+
+struct CypherGraphIndex<'g> {
+    node_keys: Vec<&'g str>,
+    entities: Vec<Option<&'g CodeEntity>>,
+    edges: &'g [DependencyEdge],
+    /// `(from node, to node)` of each edge
+    endpoints: Vec<(usize, usize)>,
+    outgoing: Vec<Vec<usize>>,
+    incoming: Vec<Vec<usize>>,
+}
+
+impl<'g> CypherGraphIndex<'g> {
+    fn from_graph(entities: &'g [CodeEntity], edges: &'g [DependencyEdge]) -> Self {
+        let mut index: HashMap<&'g str, usize> = HashMap::new();
+        let mut node_keys: Vec<&'g str> = Vec::new();
+        let mut node_entities: Vec<Option<&'g CodeEntity>> = Vec::new();
+        for entity in entities {
+            if !index.contains_key(entity.isgl1_key.as_str()) {
+                index.insert(entity.isgl1_key.as_str(), node_keys.len());
+                node_keys.push(entity.isgl1_key.as_str());
+                node_entities.push(Some(entity));
+            }
+        }
+        let mut endpoints = Vec::with_capacity(edges.len());
+        for edge in edges {
+            let mut node_of = |key: &'g str| {
+                *index.entry(key).or_insert_with(|| {
+                    node_keys.push(key);
+                    node_entities.push(None);
+                    node_keys.len() - 1
+                })
+            };
+            let from = node_of(edge.from_key.as_str());
+            let to = node_of(edge.to_key.as_str());
+            endpoints.push((from, to));
+        }
+        let mut outgoing = vec![Vec::new(); node_keys.len()];
+        let mut incoming = vec![Vec::new(); node_keys.len()];
+        for (edge_index, &(from, to)) in endpoints.iter().enumerate() {
+            outgoing[from].push(edge_index);
+            incoming[to].push(edge_index);
+        }
+        Self { node_keys, entities: node_entities, edges, endpoints, outgoing, incoming }
+    }
+
+    fn node_kind(&self, node: usize) -> &'g str {
+        self.node_keys[node].split(':').nth(1).unwrap_or("")
+    }
+
+    fn node_labels(&self, node: usize) -> Vec<String> {
+        let mut labels = vec![cypher_node_label_for_kind(self.node_kind(node))];
+        if self.entities[node].is_none() || is_placeholder_target_key(self.node_keys[node]) {
+            labels.push("External".to_string());
+        }
+        labels
+    }
+
+    fn node_has_label(&self, node: usize, label: &str) -> bool {
+        ["Entity", "Node", "Symbol"].iter().any(|any| any.eq_ignore_ascii_case(label))
+            || self.node_kind(node).eq_ignore_ascii_case(label)
+            || self.node_labels(node).iter().any(|l| l.eq_ignore_ascii_case(label))
+    }
+
+    fn node_property(&self, node: usize, property: &str) -> Value {
+        let key = self.node_keys[node];
+        let entity = self.entities[node];
+        match property {
+            "key" | "id" | "isgl1_key" => Value::String(key.to_string()),
+            "kind" => Value::String(self.node_kind(node).to_string()),
+            "language" | "lang" => Value::String(key.split(':').next().unwrap_or("").to_string()),
+            "name" => Value::String(
+                entity.map_or_else(|| key.split(':').nth(2).unwrap_or("").to_string(), |e| e.interface_signature.name.clone()),
+            ),
+            "external" => Value::Bool(entity.is_none() || is_placeholder_target_key(key)),
+            _ => {
+                let Some(entity) = entity else { return Value::Null };
+                let signature = &entity.interface_signature;
+                match property {
+                    "file" | "file_path" => Value::String(signature.file_path.to_string_lossy().to_string()),
+                    "line" | "line_start" => Value::from(signature.line_range.start),
+                    "line_end" => Value::from(signature.line_range.end),
+                    "visibility" => Value::String(format!("{:?}", signature.visibility).to_lowercase()),
+                    other => entity.metadata.additional.get(other).map_or(Value::Null, |v| Value::String(v.clone())),
+                }
+            }
+        }
+    }
+
+    fn node_value(&self, node: usize) -> Value {
+        let mut object = serde_json::Map::new();
+        for property in ["key", "name", "kind", "file_path", "line_start"] {
+            object.insert(property.to_string(), self.node_property(node, property));
+        }
+        Value::Object(object)
+    }
+
+    fn relationship_property(&self, edge: usize, property: &str) -> Value {
+        let record = &self.edges[edge];
+        match property {
+            "type" => Value::String(cypher_relationship_type_name(record.edge_type)),
+            "location" | "source_location" => record.source_location.clone().map_or(Value::Null, Value::String),
+            "from" | "from_key" => Value::String(record.from_key.as_str().to_string()),
+            "to" | "to_key" => Value::String(record.to_key.as_str().to_string()),
+            _ => Value::Null,
+        }
+    }
+
+    fn relationship_value(&self, edge: usize) -> Value {
+        let mut object = serde_json::Map::new();
+        for property in ["type", "from", "to", "location"] {
+            object.insert(property.to_string(), self.relationship_property(edge, property));
+        }
+        Value::Object(object)
+    }
+
+    fn node_matches_pattern(&self, node: usize, pattern: &CypherNodePattern) -> bool {
+        pattern.labels.iter().all(|label| self.node_has_label(node, label))
+            && pattern.properties.iter().all(|(name, value)| {
+                compare_cypher_values(&self.node_property(node, name), value) == Some(Ordering::Equal)
+            })
+    }
+}
+
+/// Depth-first pattern matcher feeding complete bindings to a sink
+struct CypherPatternMatcher<'q, 'g> {
+    graph: &'q CypherGraphIndex<'g>,
+    patterns: &'q [CypherPatternChain],
+    binding: CypherBindingRow,
+    used_edges: Vec<usize>,
+    enumerated: usize,
+}
+
+impl<'q, 'g> CypherPatternMatcher<'q, 'g> {
+    fn bound(&self, variable: &Option<String>) -> Option<BoundGraphElement> {
+        let variable = variable.as_ref()?;
+        self.binding.iter().find(|(name, _)| name == variable).map(|(_, element)| *element)
+    }
+
+    fn match_chain(&mut self, chain: usize, sink: &mut dyn FnMut(&CypherBindingRow) -> Result<()>) -> Result<()> {
+        if chain == self.patterns.len() {
+            self.enumerated += 1;
+            if self.enumerated > MAX_CYPHER_MATCH_BINDINGS {
+                return Err(ParseltongError::ParseError {
+                    reason: format!("pattern matched more than {} times; constrain it with labels or properties", MAX_CYPHER_MATCH_BINDINGS),
+                    location: "cypher MATCH".to_string(),
+                });
+            }
+            return sink(&self.binding);
+        }
+        let patterns = self.patterns;
+        let start = &patterns[chain].start;
+        let candidates: Vec<usize> = match self.bound(&start.variable) {
+            Some(BoundGraphElement::Node(node)) => vec![node],
+            Some(BoundGraphElement::Relationship(_)) => return Err(variable_kind_error(start.variable.as_deref())),
+            None => (0..self.graph.node_keys.len()).collect(),
+        };
+        for node in candidates {
+            self.try_bind_node(chain, 0, node, start, sink)?;
+        }
+        Ok(())
+    }
+
+    /// Bind `pattern` to `node`, then continue with step `step` of `chain`
+    fn try_bind_node(
+        &mut self,
+        chain: usize,
+        step: usize,
+        node: usize,
+        pattern: &CypherNodePattern,
+        sink: &mut dyn FnMut(&CypherBindingRow) -> Result<()>,
+    ) -> Result<()> {
+        if !self.graph.node_matches_pattern(node, pattern) {
+            return Ok(());
+        }
+        let pushed = match self.bound(&pattern.variable) {
+            Some(BoundGraphElement::Node(existing)) if existing == node => false,
+            Some(BoundGraphElement::Node(_)) => return Ok(()),
+            Some(BoundGraphElement::Relationship(_)) => return Err(variable_kind_error(pattern.variable.as_deref())),
+            None => match &pattern.variable {
+                Some(variable) => {
+                    self.binding.push((variable.clone(), BoundGraphElement::Node(node)));
+                    true
+                }
+                None => false,
+            },
+        };
+        let result = self.match_step(chain, step, node, sink);
+        if pushed {
+            self.binding.pop();
+        }
+        result
+    }
+
+    fn match_step(
+        &mut self,
+        chain: usize,
+        step: usize,
+        node: usize,
+        sink: &mut dyn FnMut(&CypherBindingRow) -> Result<()>,
+    ) -> Result<()> {
+        let patterns = self.patterns;
+        let Some((relationship, next)) = patterns[chain].steps.get(step) else {
+            return self.match_chain(chain + 1, sink);
+        };
+        if let Some((min, max)) = relationship.hops {
+            for end in self.variable_length_end_nodes(node, relationship, min, max) {
+                self.try_bind_node(chain, step + 1, end, next, sink)?;
+            }
+            return Ok(());
+        }
+        for (edge, other) in self.adjacent_edges(node, relationship) {
+            if self.used_edges.contains(&edge) {
+                continue;
+            }
+            let pushed = match self.bound(&relationship.variable) {
+                Some(BoundGraphElement::Relationship(existing)) if existing == edge => false,
+                Some(BoundGraphElement::Relationship(_)) => continue,
+                Some(BoundGraphElement::Node(_)) => return Err(variable_kind_error(relationship.variable.as_deref())),
+                None => match &relationship.variable {
+                    Some(variable) => {
+                        self.binding.push((variable.clone(), BoundGraphElement::Relationship(edge)));
+                        true
+                    }
+                    None => false,
+                },
+            };
+            self.used_edges.push(edge);
+            let result = self.try_bind_node(chain, step + 1, other, next, sink);
+            self.used_edges.pop();
+            if pushed {
+                self.binding.pop();
+            }
+            result?;
+        }
+        Ok(())
+    }
+
+    /// `(edge, node at its far end)` leaving `node` in the pattern's direction
+    fn adjacent_edges(&self, node: usize, relationship: &CypherRelationshipPattern) -> Vec<(usize, usize)> {
+        let graph = self.graph;
+        let allowed = |edge: usize| relationship.types.is_empty() || relationship.types.contains(&graph.edges[edge].edge_type);
+        let mut adjacent = Vec::new();
+        if relationship.direction != RelationshipDirection::Incoming {
+            adjacent.extend(graph.outgoing[node].iter().filter(|&&e| allowed(e)).map(|&e| (e, graph.endpoints[e].1)));
+        }
+        if relationship.direction != RelationshipDirection::Outgoing {
+            adjacent.extend(graph.incoming[node].iter().filter(|&&e| allowed(e)).map(|&e| (e, graph.endpoints[e].0)));
+        }
+        adjacent
+    }
+
+    /// Nodes reachable from `node` in `min..=max` hops, each once
+    fn variable_length_end_nodes(&self, node: usize, relationship: &CypherRelationshipPattern, min: usize, max: usize) -> Vec<usize> {
+        let mut ends: BTreeSet<usize> = BTreeSet::new();
+        let mut frontier: BTreeSet<usize> = BTreeSet::from([node]);
+        if min == 0 {
+            ends.insert(node);
+        }
+        for depth in 1..=max {
+            // Nodes exactly `depth` hops away along some walk
+            let next: BTreeSet<usize> = frontier
+                .iter()
+                .flat_map(|&current| self.adjacent_edges(current, relationship))
+                .map(|(_, other)| other)
+                .collect();
+            if depth >= min {
+                ends.extend(next.iter().copied());
+            }
+            if next.is_empty() || next == frontier {
+                break;
+            }
+            frontier = next;
+        }
+        ends.into_iter().collect()
+    }
+}
+
+fn variable_kind_error(variable: Option<&str>) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: format!("variable `{}` is used both as a node and as a relationship", variable.unwrap_or("")),
+        location: "cypher MATCH".to_string(),
+    }
+}
+
+// ── Expressions and projection ───────────────────────────────────────────
+
+fn is_aggregate_expression(expression: &CypherExpression) -> bool {
+    matches!(expression, CypherExpression::CountStar)
+        || matches!(expression, CypherExpression::Function { name, .. } if name == "count" || name == "collect")
+}
+
+fn evaluate_cypher_expression(
+    expression: &CypherExpression,
+    row: &CypherBindingRow,
+    graph: &CypherGraphIndex<'_>,
+) -> Result<Value> {
+    let evaluate = |inner: &CypherExpression| evaluate_cypher_expression(inner, row, graph);
+    Ok(match expression {
+        CypherExpression::Literal(value) => value.clone(),
+        CypherExpression::List(elements) => Value::Array(elements.iter().map(evaluate).collect::<Result<_>>()?),
+        CypherExpression::Variable(name) => match row.iter().find(|(bound, _)| bound == name) {
+            Some((_, BoundGraphElement::Node(node))) => graph.node_value(*node),
+            Some((_, BoundGraphElement::Relationship(edge))) => graph.relationship_value(*edge),
+            None => return Err(unknown_variable_error(name)),
+        },
+        CypherExpression::Property(target, property) => match target.as_ref() {
+            CypherExpression::Variable(name) => match row.iter().find(|(bound, _)| bound == name) {
+                Some((_, BoundGraphElement::Node(node))) => graph.node_property(*node, property),
+                Some((_, BoundGraphElement::Relationship(edge))) => graph.relationship_property(*edge, property),
+                None => return Err(unknown_variable_error(name)),
+            },
+            other => match evaluate(other)? {
+                Value::Object(object) => object.get(property).cloned().unwrap_or(Value::Null),
+                _ => Value::Null,
+            },
+        },
+        CypherExpression::CountStar => return Err(aggregate_position_error()),
+        CypherExpression::Function { name, args, .. } => {
+            if name == "count" || name == "collect" {
+                return Err(aggregate_position_error());
+            }
+            let element = |index: usize| match args.get(index) {
+                Some(CypherExpression::Variable(variable)) => row.iter().find(|(b, _)| b == variable).map(|(_, e)| *e),
+                _ => None,
+            };
+            match (name.as_str(), args.len()) {
+                ("type", 1) => match element(0) {
+                    Some(BoundGraphElement::Relationship(edge)) => graph.relationship_property(edge, "type"),
+                    _ => Value::Null,
+                },
+                ("labels", 1) => match element(0) {
+                    Some(BoundGraphElement::Node(node)) => Value::from(graph.node_labels(node)),
+                    _ => Value::Null,
+                },
+                ("id", 1) | ("elementid", 1) => match element(0) {
+                    Some(BoundGraphElement::Node(node)) => graph.node_property(node, "key"),
+                    _ => Value::Null,
+                },
+                ("tolower", 1) => map_string_value(evaluate(&args[0])?, |s| s.to_lowercase()),
+                ("toupper", 1) => map_string_value(evaluate(&args[0])?, |s| s.to_uppercase()),
+                ("size", 1) => match evaluate(&args[0])? {
+                    Value::String(s) => Value::from(s.chars().count()),
+                    Value::Array(items) => Value::from(items.len()),
+                    _ => Value::Null,
+                },
+                (other, arity) => {
+                    return Err(ParseltongError::ParseError {
+                        reason: format!(
+                            "unsupported function {}() with {} argument(s) (known: type, labels, id, toLower, toUpper, size, count, collect)",
+                            other, arity
+                        ),
+                        location: "cypher expression".to_string(),
+                    })
+                }
+            }
+        }
+        CypherExpression::Not(inner) => truth_value(cypher_truth(&evaluate(inner)?).map(|b| !b)),
+        CypherExpression::Negate(inner) => match evaluate(inner)? {
+            Value::Number(number) => number.as_i64().map_or(Value::Null, |n| Value::from(-n)),
+            _ => Value::Null,
+        },
+        CypherExpression::IsNull { operand, negated } => Value::Bool(evaluate(operand)?.is_null() != *negated),
+        CypherExpression::Binary(operator, left, right) => {
+            let (left, right) = (evaluate(left)?, evaluate(right)?);
+            evaluate_binary_operator(*operator, &left, &right)
+        }
+    })
+}
+
+fn evaluate_binary_operator(operator: CypherBinaryOperator, left: &Value, right: &Value) -> Value {
+    use CypherBinaryOperator::*;
+    match operator {
+        And | Or | Xor => {
+            let (a, b) = (cypher_truth(left), cypher_truth(right));
+            truth_value(match operator {
+                And => match (a, b) {
+                    (Some(false), _) | (_, Some(false)) => Some(false),
+                    (Some(true), Some(true)) => Some(true),
+                    _ => None,
+                },
+                Or => match (a, b) {
+                    (Some(true), _) | (_, Some(true)) => Some(true),
+                    (Some(false), Some(false)) => Some(false),
+                    _ => None,
+                },
+                _ => a.zip(b).map(|(a, b)| a != b),
+            })
+        }
+        _ if left.is_null() || right.is_null() => Value::Null,
+        Eq | Ne | Lt | Le | Gt | Ge => {
+            let Some(ordering) = compare_cypher_values(left, right) else { return Value::Null };
+            Value::Bool(match operator {
+                Eq => ordering == Ordering::Equal,
+                Ne => ordering != Ordering::Equal,
+                Lt => ordering == Ordering::Less,
+                Le => ordering != Ordering::Greater,
+                Gt => ordering == Ordering::Greater,
+                _ => ordering != Ordering::Less,
+            })
+        }
+        Contains | StartsWith | EndsWith => match (left, right) {
+            (Value::String(haystack), Value::String(needle)) => Value::Bool(match operator {
+                Contains => haystack.contains(needle.as_str()),
+                StartsWith => haystack.starts_with(needle.as_str()),
+                _ => haystack.ends_with(needle.as_str()),
+            }),
+            _ => Value::Null,
+        },
+        In => match right {
+            Value::Array(items) => {
+                Value::Bool(items.iter().any(|item| compare_cypher_values(left, item) == Some(Ordering::Equal)))
+            }
+            _ => Value::Null,
+        },
+    }
+}
+
+/// Comparable values order; None when incomparable (string vs number)
+fn compare_cypher_values(left: &Value, right: &Value) -> Option<Ordering> {
+    match (left, right) {
+        (Value::Number(a), Value::Number(b)) => a.as_f64()?.partial_cmp(&b.as_f64()?),
+        (Value::String(a), Value::String(b)) => Some(a.cmp(b)),
+        (Value::Bool(a), Value::Bool(b)) => Some(a.cmp(b)),
+        (Value::Null, Value::Null) => Some(Ordering::Equal),
+        (Value::Array(_), Value::Array(_)) | (Value::Object(_), Value::Object(_)) => {
+            (left == right).then_some(Ordering::Equal).or(Some(left.to_string().cmp(&right.to_string())))
+        }
+        _ => None,
+    }
+}
+
+/// Total order for ORDER BY: nulls last, then by type, then by value
+fn order_cypher_values(left: &Value, right: &Value) -> Ordering {
+    let rank = |value: &Value| match value {
+        Value::Object(_) => 0,
+        Value::Array(_) => 1,
+        Value::String(_) => 2,
+        Value::Bool(_) => 3,
+        Value::Number(_) => 4,
+        Value::Null => 5,
+    };
+    rank(left).cmp(&rank(right)).then_with(|| compare_cypher_values(left, right).unwrap_or(Ordering::Equal))
+}
+
+fn cypher_truth(value: &Value) -> Option<bool> {
+    match value {
+        Value::Bool(b) => Some(*b),
+        _ => None,
+    }
+}
+
+fn truth_value(truth: Option<bool>) -> Value {
+    truth.map_or(Value::Null, Value::Bool)
+}
+
+fn map_string_value(value: Value, map: impl Fn(&str) -> String) -> Value {
+    match value {
+        Value::String(s) => Value::String(map(&s)),
+        _ => Value::Null,
+    }
+}
+
+fn unknown_variable_error(name: &str) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: format!("variable `{}` is not defined in MATCH", name),
+        location: "cypher expression".to_string(),
+    }
+}
+
+fn aggregate_position_error() -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: "count() and collect() are only supported as whole RETURN items".to_string(),
+        location: "cypher expression".to_string(),
+    }
+}
+
+/// Per-group state of one aggregate RETURN item
+#[derive(Default)]
+struct AggregateColumnState {
+    count: usize,
+    distinct_seen: BTreeSet<String>,
+    collected: Vec<Value>,
+}
+
+/// Match, filter, project, sort and page the query's rows
+///
+/// Returns `(rows before SKIP/LIMIT, rows after)`.
+fn project_query_result_rows(query: &ParsedCypherQuery, graph: &CypherGraphIndex<'_>) -> Result<(usize, Vec<Vec<Value>>)> {
+    let aggregating = query.items.iter().any(|item| is_aggregate_expression(&item.expression));
+    // ORDER BY items naming a RETURN column sort by it; others are evaluated per row
+    let order_columns: Vec<Option<usize>> = query
+        .order_by
+        .iter()
+        .map(|order| query.items.iter().position(|item| item.column == order.text))
+        .collect();
+    if aggregating && order_columns.iter().any(|column| column.is_none()) {
+        return Err(ParseltongError::ParseError {
+            reason: "with count()/collect(), ORDER BY must name a RETURN column or alias".to_string(),
+            location: "cypher ORDER BY".to_string(),
+        });
+    }
+    // Without sorting, de-duplication or aggregation only SKIP+LIMIT rows are kept
+    let keep_at_most = match (aggregating || query.distinct || !query.order_by.is_empty(), query.limit) {
+        (false, Some(limit)) => query.skip.saturating_add(limit),
+        _ => usize::MAX,
+    };
+
+    let mut plain_rows: Vec<(Vec<Value>, Vec<Value>)> = Vec::new();
+    let mut plain_total = 0usize;
+    let mut seen_rows: HashSet<String> = HashSet::new();
+    let mut groups: BTreeMap<String, (Vec<Value>, Vec<AggregateColumnState>)> = BTreeMap::new();
+    let mut group_order: Vec<String> = Vec::new();
+
+    let mut sink = |row: &CypherBindingRow| -> Result<()> {
+        if let Some(filter) = &query.filter {
+            if cypher_truth(&evaluate_cypher_expression(filter, row, graph)?) != Some(true) {
+                return Ok(());
+            }
+        }
+        if !aggregating {
+            let values: Vec<Value> = query
+                .items
+                .iter()
+                .map(|item| evaluate_cypher_expression(&item.expression, row, graph))
+                .collect::<Result<_>>()?;
+            if query.distinct && !seen_rows.insert(Value::Array(values.clone()).to_string()) {
+                return Ok(());
+            }
+            plain_total += 1;
+            if plain_rows.len() < keep_at_most {
+                let mut sort_keys = Vec::new();
+                for (order, column) in query.order_by.iter().zip(&order_columns) {
+                    sort_keys.push(match column {
+                        Some(index) => values[*index].clone(),
+                        None => evaluate_cypher_expression(&order.expression, row, graph)?,
+                    });
+                }
+                plain_rows.push((values, sort_keys));
+            }
+            return Ok(());
+        }
+
+        let mut group_values = Vec::new();
+        for item in query.items.iter().filter(|item| !is_aggregate_expression(&item.expression)) {
+            group_values.push(evaluate_cypher_expression(&item.expression, row, graph)?);
+        }
+        let group_key = Value::Array(group_values.clone()).to_string();
+        if !groups.contains_key(&group_key) {
+            group_order.push(group_key.clone());
+            let states = query.items.iter().map(|_| AggregateColumnState::default()).collect();
+            groups.insert(group_key.clone(), (group_values, states));
+        }
+        let (_, states) = groups.get_mut(&group_key).expect("group inserted above");
+        for (item, state) in query.items.iter().zip(states.iter_mut()) {
+            match &item.expression {
+                CypherExpression::CountStar => state.count += 1,
+                CypherExpression::Function { name, distinct, args } if is_aggregate_expression(&item.expression) => {
+                    let [argument] = args.as_slice() else {
+                        return Err(ParseltongError::ParseError {
+                            reason: format!("{}() takes exactly one argument", name),
+                            location: "cypher RETURN".to_string(),
+                        });
+                    };
+                    let value = evaluate_cypher_expression(argument, row, graph)?;
+                    if value.is_null() || (*distinct && !state.distinct_seen.insert(value.to_string())) {
+                        continue;
+                    }
+                    state.count += 1;
+                    if name == "collect" {
+                        state.collected.push(value);
+                    }
+                }
+                _ => {}
+            }
+        }
+        Ok(())
+    };
+
+    let mut matcher = CypherPatternMatcher {
+        graph,
+        patterns: &query.patterns,
+        binding: Vec::new(),
+        used_edges: Vec::new(),
+        enumerated: 0,
+    };
+    matcher.match_chain(0, &mut sink)?;
+
+    let mut rows: Vec<(Vec<Value>, Vec<Value>)> = if aggregating {
+        // No match and no grouping columns still yields one row (count = 0)
+        if groups.is_empty() && query.items.iter().all(|item| is_aggregate_expression(&item.expression)) {
+            group_order.push(String::new());
+            let states = query.items.iter().map(|_| AggregateColumnState::default()).collect();
+            groups.insert(String::new(), (Vec::new(), states));
+        }
+        group_order
+            .iter()
+            .map(|key| {
+                let (group_values, states) = groups.remove(key).expect("group recorded in order");
+                let mut group_values = group_values.into_iter();
+                let values: Vec<Value> = query
+                    .items
+                    .iter()
+                    .zip(states)
+                    .map(|(item, state)| match &item.expression {
+                        CypherExpression::Function { name, .. } if name == "collect" => Value::Array(state.collected),
+                        expression if is_aggregate_expression(expression) => Value::from(state.count),
+                        _ => group_values.next().unwrap_or(Value::Null),
+                    })
+                    .collect();
+                let sort_keys: Vec<Value> = order_columns.iter().map(|column| values[column.unwrap_or(0)].clone()).collect();
+                (values, sort_keys)
+            })
+            .collect()
+    } else {
+        plain_rows
+    };
+    let total_count = if aggregating { rows.len() } else { plain_total };
+
+    if !query.order_by.is_empty() {
+        rows.sort_by(|(_, a), (_, b)| {
+            for ((left, right), order) in a.iter().zip(b).zip(&query.order_by) {
+                let ordering = order_cypher_values(left, right);
+                let ordering = if order.descending { ordering.reverse() } else { ordering };
+                if ordering != Ordering::Equal {
+                    return ordering;
+                }
+            }
+            Ordering::Equal
+        });
+    }
+    let limit = query.limit.unwrap_or(usize::MAX);
+    let rows = rows.into_iter().skip(query.skip).take(limit).map(|(values, _)| values).collect();
+    Ok((total_count, rows))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call_edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type).build().unwrap()
+    }
+
+    fn sample_service_graph() -> (Vec<CodeEntity>, Vec<DependencyEdge>) {
+        let entities = vec![
+            create_go_test_entity("go:method:CreateUser:__svc:T1", "CreateUser", EntityType::Method, "svc/user.go", &[("receiver_type", "*UserService")]),
+            create_go_test_entity("go:fn:HandleSignup:__api:T2", "HandleSignup", EntityType::Function, "api/signup.go", &[]),
+            create_go_test_entity("go:fn:HandleInvite:__api:T3", "HandleInvite", EntityType::Function, "api/invite.go", &[]),
+            create_go_test_entity("go:fn:main:__cmd:T4", "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![
+            call_edge("go:fn:HandleSignup:__api:T2", "go:method:CreateUser:__svc:T1", EdgeType::Calls),
+            call_edge("go:fn:HandleInvite:__api:T3", "go:method:CreateUser:__svc:T1", EdgeType::Calls),
+            call_edge("go:fn:main:__cmd:T4", "go:fn:HandleSignup:__api:T2", EdgeType::Calls),
+            call_edge("go:method:CreateUser:__svc:T1", "go:fn:Exec:unresolved-reference:0-0", EdgeType::Calls),
+        ];
+        (entities, edges)
+    }
+
+    #[test]
+    fn test_match_where_return_order_limit() {
+        let (entities, edges) = sample_service_graph();
+        let result = execute_cypher_subset_query(
+            "MATCH (caller:Function)-[:CALLS]->(t:Method {name: 'CreateUser'}) \
+             WHERE caller.file STARTS WITH 'api/' AND NOT caller.name = 'HandleInvite' \
+             RETURN caller.name AS name, t.receiver_type",
+            &entities,
+            &edges,
+        )
+        .unwrap();
+        assert_eq!(result.columns, vec!["name", "t.receiver_type"]);
+        assert_eq!(result.rows, vec![vec![Value::from("HandleSignup"), Value::from("*UserService")]]);
+
+        // Reverse direction, variable length, aggregation and ordering
+        let grouped = execute_cypher_subset_query(
+            "MATCH (t {name: 'CreateUser'})<-[:CALLS*1..2]-(c) RETURN t.name, count(c) AS n",
+            &entities,
+            &edges,
+        )
+        .unwrap();
+        assert_eq!(grouped.rows, vec![vec![Value::from("CreateUser"), Value::from(3)]]);
+
+        let ordered = execute_cypher_subset_query(
+            "MATCH (a)-[r]->(b:External) RETURN a.name, type(r) ORDER BY a.name DESC LIMIT 1",
+            &entities,
+            &edges,
+        )
+        .unwrap();
+        assert_eq!(ordered.rows, vec![vec![Value::from("CreateUser"), Value::from("CALLS")]]);
+        assert!(!ordered.truncated);
+
+        let paged = execute_cypher_subset_query("MATCH (n:Function) WHERE NOT n.external RETURN n.name ORDER BY n.name LIMIT 2", &entities, &edges).unwrap();
+        assert_eq!((paged.total_count, paged.truncated), (3, true));
+        assert_eq!(paged.rows[0], vec![Value::from("HandleInvite")]);
+    }
+
+    #[test]
+    fn test_rejects_writes_and_unknown_syntax() {
+        let (entities, edges) = sample_service_graph();
+        for query in [
+            "MATCH (n) DETACH DELETE n",
+            "CREATE (n:Function {name: 'x'})",
+            "MATCH (n)-[:FLOWS_TO]->(m) RETURN n",
+            "MATCH (n) WHERE n.name =~ 'Create.*' RETURN n",
+            "MATCH (n) RETURN m",
+        ] {
+            assert!(execute_cypher_subset_query(query, &entities, &edges).is_err(), "{}", query);
+        }
+        assert_eq!(cypher_relationship_type_name(EdgeType::SendsTo), "SENDS_TO");
+        assert_eq!(cypher_node_label_for_kind("fn"), "Function");
+        assert_eq!(cypher_node_label_for_kind("type_alias"), "TypeAlias");
+    }
+
+    #[test]
+    fn test_parser_pattern_shapes_and_clauses() {
+        let query = parse_cypher_subset_query(
+            "MATCH (a:Function)-->(b), (b)<-[:CALLS|EMBEDS*2..]-(c {name: 'it\\'s'}) \
+             WHERE a.name IN ['x', 'y'] OR c.line IS NOT NULL \
+             RETURN DISTINCT a.name AS n, count(c) ORDER BY n DESC, count(c) SKIP 1 LIMIT 3;",
+        )
+        .unwrap();
+        assert_eq!(query.patterns.len(), 2);
+        assert_eq!(query.patterns[0].start.labels, vec!["Function"]);
+        let (outgoing, _) = &query.patterns[0].steps[0];
+        assert_eq!(outgoing.direction, RelationshipDirection::Outgoing);
+        assert_eq!((outgoing.types.len(), outgoing.hops), (0, None));
+        let (incoming, end) = &query.patterns[1].steps[0];
+        assert_eq!(incoming.direction, RelationshipDirection::Incoming);
+        assert_eq!(incoming.types, vec![EdgeType::Calls, EdgeType::Embeds]);
+        assert_eq!(incoming.hops, Some((2, MAX_VARIABLE_LENGTH_HOPS)));
+        assert_eq!(end.properties, vec![("name".to_string(), Value::from("it's"))]);
+        assert!(matches!(query.filter, Some(CypherExpression::Binary(CypherBinaryOperator::Or, _, _))));
+        assert!(query.distinct);
+        let columns: Vec<&str> = query.items.iter().map(|item| item.column.as_str()).collect();
+        assert_eq!(columns, vec!["n", "count(c)"]);
+        let order: Vec<(&str, bool)> = query.order_by.iter().map(|o| (o.text.as_str(), o.descending)).collect();
+        assert_eq!(order, vec![("n", true), ("count(c)", false)]);
+        assert_eq!((query.skip, query.limit), (1, Some(3)));
+
+        let hops = |text: &str| parse_cypher_subset_query(text).unwrap().patterns[0].steps[0].0.hops;
+        assert_eq!(hops("MATCH (a)-[*3]-(b) RETURN a"), Some((3, 3)));
+        assert_eq!(hops("MATCH (a)-[:CALLS*]->(b) RETURN a"), Some((1, MAX_VARIABLE_LENGTH_HOPS)));
+        assert_eq!(hops("MATCH (a)-[*..50]->(b) RETURN a"), Some((1, MAX_VARIABLE_LENGTH_HOPS)));
+        assert_eq!(hops("MATCH (a)--(b) RETURN a"), None);
+    }
+
+    #[test]
+    fn test_parser_errors_name_the_column() {
+        for (query, expected) in [
+            ("MATCH (a)<-[]->(b) RETURN a", "has no direction"),
+            ("MATCH (a)-[r*2]->(b) RETURN a", "cannot be bound to a variable"),
+            ("MATCH (a)-[r {x: 1}]->(b) RETURN a", "relationship property maps"),
+            ("MATCH (a) WHERE a.name = $name RETURN a", "query parameters"),
+            ("MATCH (a) WHERE a.name = 'open RETURN a", "unterminated string literal"),
+            ("OPTIONAL MATCH (a) RETURN a", "OPTIONAL MATCH"),
+            ("MATCH (a) WITH a RETURN a", "single MATCH"),
+            ("MATCH (a) RETURN a LIMIT -1", "a LIMIT count"),
+            ("MATCH (a) RETURN a a", "end of query"),
+        ] {
+            let error = parse_cypher_subset_query(query).unwrap_err().to_string();
+            assert!(error.contains(expected), "{} → {}", query, error);
+        }
+        let error = parse_cypher_subset_query("MATCH (a) RETURN a a").unwrap_err().to_string();
+        assert!(error.contains("cypher column 20"), "{}", error);
+    }
+}
@@ -0,0 +1,203 @@
+//! Dead Code Detection Analyzer (v1.7.3)
+//!
+//! # 4-Word Naming: dead_code_detection_analyzer
+//!
+//! Reports symbols nothing in the graph points at: zero inbound edges of any
+//! type (Calls, Uses, Implements, Embeds, Reads, Tests, ...), self-recursion
+//! not counted. Exported symbols are reported too but flagged, since they may
+//! be used by code outside the indexed tree.
+//!
+//! ## Never reported
+//!
+//! - Entry points: `main`, `init` (and Python `__init__` / `__main__`)
+//! - Tests: `TestImplementation` entities and the `test` facet
+//! - Symbols flagged `dynamic_usage` (reflection, plugin lookups, registries:
+//!   static edges are known to be incomplete there)
+//! - Go methods that satisfy an interface the receiver implements (reached
+//!   through dynamic dispatch, which has no Calls edge)
+//! - Symbols marked `//companion:ignore` or `//companion:entrypoint`
+//!   (`inline_suppression_directive_parser`)
+//! - Placeholders (`unresolved-reference`, `external-dependency-`, `0-0` nodes),
+//!   modules, impl blocks and SQL objects
+
+use std::collections::{HashMap, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType, Visibility};
+use crate::go_test_coverage_linker::has_test_facet_metadata;
+use crate::inline_suppression_directive_parser::{has_inline_companion_directive, InlineCompanionDirectiveKind};
+use crate::structural_interface_matcher::{INTERFACE_METHODS_METADATA_KEY, RECEIVER_TYPE_METADATA_KEY};
+
+/// Entity metadata key set (to `true`) on symbols reached through reflection or registries
+pub const DYNAMIC_USAGE_METADATA_KEY: &str = "dynamic_usage";
+
+const ENTRY_POINT_SYMBOL_NAMES: &[&str] = &["main", "init", "__init__", "__main__"];
+
+/// One symbol without inbound edges
+///
+/// # 4-Word Name: DeadCodeCandidateEntry
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct DeadCodeCandidateEntry {
+    pub entity_key: String,
+    pub name: String,
+    pub entity_type: String,
+    pub file_path: String,
+    pub line_start: u32,
+    /// Visible outside its package/module (may have external callers)
+    pub exported: bool,
+}
+
+/// Find symbols with zero inbound edges
+///
+/// # 4-Word Name: find_unreferenced_dead_code_entities
+///
+/// # Contract
+/// - Precondition: `entities` carry extractor metadata (`receiver_type`,
+///   `interface_methods`, `facet`, `dynamic_usage`) when available
+/// - Postcondition: sorted by (file_path, line_start, key); exclusions as in
+///   the module docs
+pub fn find_unreferenced_dead_code_entities(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> Vec<DeadCodeCandidateEntry> {
+    let referenced: HashSet<&str> = edges
+        .iter()
+        .filter(|e| e.from_key.as_str() != e.to_key.as_str())
+        .map(|e| e.to_key.as_str())
+        .collect();
+    let dispatched = collect_interface_dispatched_methods(entities, edges);
+
+    let mut candidates: Vec<DeadCodeCandidateEntry> = entities
+        .iter()
+        .filter(|e| !referenced.contains(e.isgl1_key.as_str()))
+        .filter(|e| is_dead_code_candidate_entity(e))
+        .filter(|e| {
+            let receiver = e.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY);
+            !receiver.is_some_and(|r| dispatched.contains(&(r.as_str(), e.interface_signature.name.as_str())))
+        })
+        .map(|e| DeadCodeCandidateEntry {
+            entity_key: e.isgl1_key.clone(),
+            name: e.interface_signature.name.clone(),
+            entity_type: format!("{:?}", e.interface_signature.entity_type),
+            file_path: e.interface_signature.file_path.display().to_string(),
+            line_start: e.interface_signature.line_range.start,
+            exported: is_entity_symbol_exported(e),
+        })
+        .collect();
+    candidates.sort_by(|a, b| {
+        (&a.file_path, a.line_start, &a.entity_key).cmp(&(&b.file_path, b.line_start, &b.entity_key))
+    });
+    candidates
+}
+
+/// True when the symbol is visible outside its package/module
+///
+/// # 4-Word Name: is_entity_symbol_exported
+///
+/// Go: capitalized name; Python: no leading underscore; otherwise the
+/// extracted visibility.
+pub fn is_entity_symbol_exported(entity: &CodeEntity) -> bool {
+    let name = entity.interface_signature.name.as_str();
+    match entity.isgl1_key.split(':').next() {
+        Some("go") => name.starts_with(|c: char| c.is_uppercase()),
+        Some("python") => !name.starts_with('_'),
+        _ => entity.interface_signature.visibility == Visibility::Public,
+    }
+}
+
+/// Kinds, keys and flags that can be reported at all
+fn is_dead_code_candidate_entity(entity: &CodeEntity) -> bool {
+    let key = entity.isgl1_key.as_str();
+    let is_placeholder =
+        key.contains(":unresolved-reference:") || key.contains(":external-dependency-") || key.ends_with(":0-0");
+    let reportable_kind = matches!(
+        entity.interface_signature.entity_type,
+        EntityType::Function
+            | EntityType::Method
+            | EntityType::Struct
+            | EntityType::Enum
+            | EntityType::Trait
+            | EntityType::Interface
+            | EntityType::Macro
+            | EntityType::Class
+            | EntityType::Variable
+            | EntityType::Constant
+    );
+    !is_placeholder
+        && reportable_kind
+        && !ENTRY_POINT_SYMBOL_NAMES.contains(&entity.interface_signature.name.as_str())
+        && entity.entity_class != EntityClass::TestImplementation
+        && !has_test_facet_metadata(entity)
+        && entity.metadata.additional.get(DYNAMIC_USAGE_METADATA_KEY).map(String::as_str) != Some("true")
+        && !has_inline_companion_directive(entity, InlineCompanionDirectiveKind::Ignore)
+        && !has_inline_companion_directive(entity, InlineCompanionDirectiveKind::Entrypoint)
+}
+
+/// `(receiver type, method)` pairs required by an interface the receiver implements
+fn collect_interface_dispatched_methods<'a>(
+    entities: &'a [CodeEntity],
+    edges: &[DependencyEdge],
+) -> HashSet<(&'a str, &'a str)> {
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut dispatched = HashSet::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Implements) {
+        let (Some(implementer), Some(interface)) =
+            (by_key.get(edge.from_key.as_str()), by_key.get(edge.to_key.as_str()))
+        else {
+            continue;
+        };
+        let Some(methods) = interface.metadata.additional.get(INTERFACE_METHODS_METADATA_KEY) else {
+            continue;
+        };
+        for method in methods.split(',').map(str::trim).filter(|m| !m.is_empty()) {
+            dispatched.insert((implementer.interface_signature.name.as_str(), method));
+        }
+    }
+    dispatched
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_reports_unreferenced_symbols_with_exclusions() {
+        let file = "internal/users/service.go";
+        let entities = vec![
+            create_go_test_entity("go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[]),
+            create_go_test_entity("go:fn:Create:__s:T2", "Create", EntityType::Function, file, &[]),
+            create_go_test_entity("go:fn:validate:__s:T3", "validate", EntityType::Function, file, &[]),
+            create_go_test_entity("go:fn:unusedHelper:__s:T4", "unusedHelper", EntityType::Function, file, &[]),
+            create_go_test_entity("go:fn:Legacy:__s:T5", "Legacy", EntityType::Function, file, &[]),
+            create_go_test_entity("go:fn:TestCreate:__t:T6", "TestCreate", EntityType::Function, "internal/users/service_test.go", &[("facet", "test")]),
+            create_go_test_entity("go:fn:handler:__s:T7", "handler", EntityType::Function, file, &[(DYNAMIC_USAGE_METADATA_KEY, "true")]),
+            create_go_test_entity("go:struct:Store:__s:T8", "Store", EntityType::Struct, file, &[]),
+            create_go_test_entity("go:interface:Saver:__s:T9", "Saver", EntityType::Interface, file, &[(INTERFACE_METHODS_METADATA_KEY, "Save")]),
+            create_go_test_entity("go:method:Save:__s:T10", "Save", EntityType::Method, file, &[(RECEIVER_TYPE_METADATA_KEY, "Store")]),
+            create_go_test_entity("go:fn:Printf:unresolved-reference:0-0", "Printf", EntityType::Function, "fmt", &[]),
+        ];
+        let edges = vec![
+            edge("go:fn:main:__cmd:T1", "go:fn:Create:__s:T2", EdgeType::Calls),
+            edge("go:fn:Create:__s:T2", "go:fn:validate:__s:T3", EdgeType::Calls),
+            edge("go:fn:Create:__s:T2", "go:struct:Store:__s:T8", EdgeType::Uses),
+            edge("go:fn:unusedHelper:__s:T4", "go:fn:unusedHelper:__s:T4", EdgeType::Calls),
+            edge("go:struct:Store:__s:T8", "go:interface:Saver:__s:T9", EdgeType::Implements),
+            edge("go:fn:Create:__s:T2", "go:fn:Printf:unresolved-reference:0-0", EdgeType::Calls),
+        ];
+
+        let dead = find_unreferenced_dead_code_entities(&entities, &edges);
+        let reported: Vec<(&str, bool)> = dead.iter().map(|d| (d.name.as_str(), d.exported)).collect();
+        assert_eq!(reported, vec![("Legacy", true), ("unusedHelper", false)]);
+    }
+}
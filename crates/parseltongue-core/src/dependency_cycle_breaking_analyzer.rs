@@ -0,0 +1,310 @@
+//! Dependency Cycle Breaking Analyzer (v1.7.3)
+//!
+//! # 4-Word Naming: dependency_cycle_breaking_analyzer
+//!
+//! Finds dependency cycles at two levels and proposes where to cut them:
+//!
+//! - **Package**: one node per directory (Go package, Python package, Rust
+//!   module folder); an edge A → B when any symbol in A depends on one in B,
+//!   weighted by the number of symbol edges behind it
+//! - **Symbol**: one node per entity; self-recursion is not a cycle here
+//!
+//! Cycles are the strongly connected components with 2+ nodes (Tarjan).
+//! For each one, a *minimal* set of edges whose removal makes it acyclic is
+//! reported: an Eades–Lin–Smyth ordering picks a feedback arc set (cheap,
+//! favours cutting light edges), then every cut edge that can be put back
+//! without closing a cycle is restored, heaviest first. Minimum feedback arc
+//! set is NP-hard, so the result is minimal (no cut is redundant) rather than
+//! guaranteed smallest.
+//!
+//! Only structural dependencies count: `Calls`, `Uses`, `Implements`,
+//! `Embeds`, `Spawns`. Placeholders (external, unresolved, `go:field:`,
+//! `go:chan:`) are not nodes.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::graph_analysis::{tarjan_strongly_connected_components, AdjacencyListGraphRepresentation};
+
+/// Edge proposed for removal, with how many symbol edges it stands for
+///
+/// # 4-Word Name: CycleBreakEdgeEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct CycleBreakEdgeEntry {
+    pub from: String,
+    pub to: String,
+    pub weight: usize,
+}
+
+/// One strongly connected component and the cuts that break it
+///
+/// # 4-Word Name: DependencyCycleReportEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct DependencyCycleReportEntry {
+    /// "package" or "symbol"
+    pub level: String,
+    /// Sorted member nodes
+    pub members: Vec<String>,
+    /// Edges between members
+    pub internal_edge_count: usize,
+    /// Minimal edge set whose removal leaves the members acyclic
+    pub break_edges: Vec<CycleBreakEdgeEntry>,
+}
+
+/// Cycles at both levels, largest first
+///
+/// # 4-Word Name: DependencyCycleAnalysisResult
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct DependencyCycleAnalysisResult {
+    pub package_cycles: Vec<DependencyCycleReportEntry>,
+    pub symbol_cycles: Vec<DependencyCycleReportEntry>,
+}
+
+/// Find package- and symbol-level cycles with break suggestions
+///
+/// # 4-Word Name: analyze_dependency_cycles_both_levels
+///
+/// # Contract
+/// - Postcondition: every reported cycle has ≥ 2 members; removing its
+///   `break_edges` leaves the members acyclic, and putting any single one
+///   back closes a cycle again
+/// - Postcondition: sorted by member count desc, then first member
+pub fn analyze_dependency_cycles_both_levels(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> DependencyCycleAnalysisResult {
+    let package_of: HashMap<&str, String> = entities
+        .iter()
+        .filter(|e| !is_cycle_placeholder_key(&e.isgl1_key))
+        .map(|e| (e.isgl1_key.as_str(), package_directory_of_entity(e)))
+        .collect();
+
+    let mut symbol_weights: BTreeMap<(String, String), usize> = BTreeMap::new();
+    let mut package_weights: BTreeMap<(String, String), usize> = BTreeMap::new();
+    for edge in edges.iter().filter(|e| {
+        matches!(
+            e.edge_type,
+            EdgeType::Calls | EdgeType::Uses | EdgeType::Implements | EdgeType::Embeds | EdgeType::Spawns
+        )
+    }) {
+        let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+        let (Some(from_package), Some(to_package)) = (package_of.get(from), package_of.get(to)) else {
+            continue;
+        };
+        if from != to {
+            *symbol_weights.entry((from.to_string(), to.to_string())).or_default() += 1;
+        }
+        if from_package != to_package {
+            *package_weights.entry((from_package.clone(), to_package.clone())).or_default() += 1;
+        }
+    }
+
+    DependencyCycleAnalysisResult {
+        package_cycles: find_weighted_graph_cycles("package", &package_weights),
+        symbol_cycles: find_weighted_graph_cycles("symbol", &symbol_weights),
+    }
+}
+
+fn is_cycle_placeholder_key(key: &str) -> bool {
+    key.contains(":unresolved-reference:") || key.contains(":external-dependency-") || key.ends_with(":0-0")
+}
+
+/// SCCs of a weighted graph, each with a minimal feedback arc set
+fn find_weighted_graph_cycles(
+    level: &str,
+    weights: &BTreeMap<(String, String), usize>,
+) -> Vec<DependencyCycleReportEntry> {
+    let triples: Vec<(String, String, String)> = weights
+        .keys()
+        .map(|(from, to)| (from.clone(), to.clone(), level.to_string()))
+        .collect();
+    let graph = AdjacencyListGraphRepresentation::build_from_dependency_edges(&triples);
+
+    let mut cycles: Vec<DependencyCycleReportEntry> = tarjan_strongly_connected_components(&graph)
+        .into_iter()
+        .filter(|scc| scc.len() > 1)
+        .map(|scc| {
+            let members: BTreeSet<String> = scc.into_iter().collect();
+            let internal: Vec<(&str, &str, usize)> = weights
+                .iter()
+                .filter(|((from, to), _)| members.contains(from) && members.contains(to))
+                .map(|((from, to), weight)| (from.as_str(), to.as_str(), *weight))
+                .collect();
+            let break_edges = compute_minimal_feedback_arcs(&members, &internal)
+                .into_iter()
+                .map(|(from, to, weight)| CycleBreakEdgeEntry {
+                    from: from.to_string(),
+                    to: to.to_string(),
+                    weight,
+                })
+                .collect();
+            DependencyCycleReportEntry {
+                level: level.to_string(),
+                internal_edge_count: internal.len(),
+                members: members.into_iter().collect(),
+                break_edges,
+            }
+        })
+        .collect();
+    cycles.sort_by(|a, b| b.members.len().cmp(&a.members.len()).then_with(|| a.members.cmp(&b.members)));
+    cycles
+}
+
+/// Eades–Lin–Smyth feedback arcs, then restore every arc that stays acyclic
+fn compute_minimal_feedback_arcs<'a>(
+    members: &BTreeSet<String>,
+    internal: &[(&'a str, &'a str, usize)],
+) -> Vec<(&'a str, &'a str, usize)> {
+    // Greedy ordering: sinks to the back, sources to the front, otherwise the
+    // node with the largest (weighted out - weighted in) to the front
+    let mut remaining: BTreeSet<&str> = members.iter().map(String::as_str).collect();
+    let mut front: Vec<&str> = Vec::new();
+    let mut back: Vec<&str> = Vec::new();
+    let degree = |node: &str, remaining: &BTreeSet<&str>, outgoing: bool| -> usize {
+        internal
+            .iter()
+            .filter(|(from, to, _)| {
+                let (this, other) = if outgoing { (from, to) } else { (to, from) };
+                *this == node && remaining.contains(other)
+            })
+            .map(|(_, _, weight)| weight)
+            .sum()
+    };
+    while !remaining.is_empty() {
+        if let Some(sink) = remaining.iter().copied().find(|n| degree(n, &remaining, true) == 0) {
+            remaining.remove(sink);
+            back.push(sink);
+            continue;
+        }
+        if let Some(source) = remaining.iter().copied().find(|n| degree(n, &remaining, false) == 0) {
+            remaining.remove(source);
+            front.push(source);
+            continue;
+        }
+        let best = remaining
+            .iter()
+            .copied()
+            .max_by_key(|n| {
+                let delta = degree(n, &remaining, true) as i64 - degree(n, &remaining, false) as i64;
+                (delta, std::cmp::Reverse(*n))
+            })
+            .expect("remaining is not empty");
+        remaining.remove(best);
+        front.push(best);
+    }
+    let position: HashMap<&str, usize> = front
+        .into_iter()
+        .chain(back.into_iter().rev())
+        .enumerate()
+        .map(|(i, n)| (n, i))
+        .collect();
+
+    let (mut cut, mut kept): (Vec<_>, Vec<_>) =
+        internal.iter().copied().partition(|(from, to, _)| position[from] >= position[to]);
+
+    // Restore cuts that are not needed, heaviest first
+    cut.sort_by(|a, b| b.2.cmp(&a.2).then_with(|| (a.0, a.1).cmp(&(b.0, b.1))));
+    let mut minimal = Vec::new();
+    for arc in cut {
+        kept.push(arc);
+        if is_edge_set_acyclic(members, &kept) {
+            continue;
+        }
+        kept.pop();
+        minimal.push(arc);
+    }
+    minimal.sort_by(|a, b| (a.0, a.1).cmp(&(b.0, b.1)));
+    minimal
+}
+
+/// Kahn's algorithm over the member subgraph
+fn is_edge_set_acyclic(members: &BTreeSet<String>, arcs: &[(&str, &str, usize)]) -> bool {
+    let mut in_degree: HashMap<&str, usize> = members.iter().map(|m| (m.as_str(), 0)).collect();
+    let mut outgoing: HashMap<&str, Vec<&str>> = HashMap::new();
+    for (from, to, _) in arcs {
+        *in_degree.entry(*to).or_default() += 1;
+        outgoing.entry(*from).or_default().push(*to);
+    }
+    let mut ready: Vec<&str> = in_degree.iter().filter(|(_, d)| **d == 0).map(|(n, _)| *n).collect();
+    let mut visited = 0;
+    while let Some(node) = ready.pop() {
+        visited += 1;
+        for next in outgoing.get(node).into_iter().flatten() {
+            let d = in_degree.get_mut(next).expect("arc target is a member");
+            *d -= 1;
+            if *d == 0 {
+                ready.push(*next);
+            }
+        }
+    }
+    visited == in_degree.len()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn calls(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_package_cycle_cut_at_lightest_edge() {
+        let entities = vec![
+            create_go_test_entity("go:fn:A1:__a:T1", "A1", EntityType::Function, "a/a.go", &[]),
+            create_go_test_entity("go:fn:A2:__a:T2", "A2", EntityType::Function, "a/a.go", &[]),
+            create_go_test_entity("go:fn:B1:__b:T3", "B1", EntityType::Function, "b/b.go", &[]),
+            create_go_test_entity("go:fn:C1:__c:T4", "C1", EntityType::Function, "c/c.go", &[]),
+        ];
+        // a → b twice, b → c twice, c → a once: cut c → a
+        let edges = vec![
+            calls("go:fn:A1:__a:T1", "go:fn:B1:__b:T3"),
+            calls("go:fn:A2:__a:T2", "go:fn:B1:__b:T3"),
+            calls("go:fn:B1:__b:T3", "go:fn:C1:__c:T4"),
+            calls("go:fn:B1:__b:T3", "go:fn:C1:__c:T4"),
+            calls("go:fn:C1:__c:T4", "go:fn:A1:__a:T1"),
+            // recursion is not a symbol cycle
+            calls("go:fn:A2:__a:T2", "go:fn:A2:__a:T2"),
+        ];
+
+        let result = analyze_dependency_cycles_both_levels(&entities, &edges);
+        assert_eq!(result.package_cycles.len(), 1);
+        let cycle = &result.package_cycles[0];
+        assert_eq!(cycle.members, vec!["a", "b", "c"]);
+        assert_eq!(
+            cycle.break_edges,
+            vec![CycleBreakEdgeEntry { from: "c".to_string(), to: "a".to_string(), weight: 1 }]
+        );
+
+        assert_eq!(result.symbol_cycles.len(), 1);
+        assert_eq!(result.symbol_cycles[0].members.len(), 3);
+        assert_eq!(result.symbol_cycles[0].break_edges.len(), 1);
+    }
+
+    #[test]
+    fn test_minimal_cut_restores_redundant_arcs() {
+        // Two overlapping cycles x ⇄ y and x → y → z → x
+        let members: BTreeSet<String> = ["x", "y", "z"].iter().map(|s| s.to_string()).collect();
+        let internal = vec![("x", "y", 3), ("y", "x", 1), ("y", "z", 3), ("z", "x", 1)];
+        let cut = compute_minimal_feedback_arcs(&members, &internal);
+
+        let kept: Vec<_> = internal.iter().copied().filter(|a| !cut.contains(a)).collect();
+        assert!(is_edge_set_acyclic(&members, &kept));
+        for arc in &cut {
+            let mut restored = kept.clone();
+            restored.push(*arc);
+            assert!(!is_edge_set_acyclic(&members, &restored), "{:?} was not needed", arc);
+        }
+        assert_eq!(cut, vec![("y", "x", 1), ("z", "x", 1)]);
+    }
+}
@@ -0,0 +1,334 @@
+//! Derived symbol artifact invalidation (v1.7.3)
+//!
+//! # 4-Word Naming: derived_symbol_artifact_invalidator
+//!
+//! Summaries (`summary`, `summary_hash`, `summary_model` metadata) and
+//! embeddings (SymbolEmbedding rows) are derived from one symbol's code.
+//! An incremental re-index keeps them for every symbol whose content hash is
+//! unchanged, even when another symbol of the same file was edited, and drops
+//! them for symbols that changed or disappeared. The next `summarize` or
+//! `embed` run then only pays for what actually changed.
+//!
+//! ## Rules
+//!
+//! - Same key, same content hash: kept
+//! - Same key, different content hash: invalidated (the daemon keeps the key
+//!   of an edited symbol matched by position)
+//! - Key gone: invalidated
+//!
+//! `verify_derived_symbol_artifacts` backs `parseltongue cache verify`: it
+//! recomputes the summary and embedding hashes of every symbol and reports
+//! entries that no longer match the stored ones.
+
+use std::collections::{HashMap, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::CodeEntity;
+use crate::isgl1_v2::compute_content_hash;
+use crate::llm_symbol_summary_cache::{
+    build_symbol_summary_prompt, compute_summary_cache_key, SUMMARY_HASH_METADATA_KEY, SUMMARY_METADATA_KEY,
+    SUMMARY_MODEL_METADATA_KEY,
+};
+use crate::semantic_symbol_embedding_index::{compute_embedding_input_hash, SymbolEmbeddingRecord};
+
+/// Metadata keys derived from a symbol's code rather than extracted from it
+pub const DERIVED_METADATA_KEY_LIST: &[&str] =
+    &[SUMMARY_METADATA_KEY, SUMMARY_HASH_METADATA_KEY, SUMMARY_MODEL_METADATA_KEY];
+
+/// Derived artifacts of the entities about to be re-indexed
+///
+/// # 4-Word Name: DerivedArtifactCarryoverSet
+#[derive(Debug, Clone, Default)]
+pub struct DerivedArtifactCarryoverSet {
+    /// Content fingerprint of every previous entity
+    fingerprints: HashMap<String, String>,
+    /// Derived metadata pairs of previous entities that had any
+    metadata: HashMap<String, Vec<(String, String)>>,
+}
+
+/// What an incremental re-index kept and dropped
+///
+/// # 4-Word Name: DerivedArtifactInvalidationOutcome
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct DerivedArtifactInvalidationOutcome {
+    /// Summaries carried over to unchanged symbols
+    pub kept_summary_count: usize,
+    /// Previous keys that changed or disappeared, sorted
+    pub invalidated_keys: Vec<String>,
+}
+
+/// Stale entries found by `cache verify`
+///
+/// # 4-Word Name: DerivedArtifactVerifyReport
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct DerivedArtifactVerifyReport {
+    pub symbols_checked: usize,
+    pub summaries_current: usize,
+    /// Summary made from other code, or by an unrecorded model
+    pub stale_summary_keys: Vec<String>,
+    pub embeddings_current: usize,
+    /// Embedding of text that has since changed
+    pub stale_embedding_keys: Vec<String>,
+    /// Embedding of a symbol no longer in the graph
+    pub orphan_embedding_keys: Vec<String>,
+}
+
+impl DerivedArtifactVerifyReport {
+    /// Whether any stale or orphan entry was found
+    ///
+    /// # 4-Word Name: has_stale_artifact_entries
+    pub fn has_stale_artifact_entries(&self) -> bool {
+        !self.stale_summary_keys.is_empty()
+            || !self.stale_embedding_keys.is_empty()
+            || !self.orphan_embedding_keys.is_empty()
+    }
+}
+
+/// Content hash of an entity, falling back to a hash of its code
+fn entity_content_fingerprint(entity: &CodeEntity) -> String {
+    entity
+        .content_hash
+        .clone()
+        .unwrap_or_else(|| compute_content_hash(entity.current_code.as_deref().unwrap_or_default()))
+}
+
+/// Record the derived artifacts of entities before they are re-indexed
+///
+/// # 4-Word Name: collect_derived_artifact_carryover
+///
+/// # Contract
+/// - Precondition: `metadata_by_key` is the stored extractor metadata (the
+///   entities themselves may come without it)
+/// - Postcondition: only `DERIVED_METADATA_KEY_LIST` pairs are kept
+pub fn collect_derived_artifact_carryover(
+    previous: &[CodeEntity],
+    metadata_by_key: &HashMap<String, HashMap<String, String>>,
+) -> DerivedArtifactCarryoverSet {
+    let mut carryover = DerivedArtifactCarryoverSet::default();
+    for entity in previous {
+        carryover
+            .fingerprints
+            .insert(entity.isgl1_key.clone(), entity_content_fingerprint(entity));
+        let Some(stored) = metadata_by_key.get(&entity.isgl1_key) else {
+            continue;
+        };
+        let derived: Vec<(String, String)> = DERIVED_METADATA_KEY_LIST
+            .iter()
+            .filter_map(|key| stored.get(*key).map(|value| (key.to_string(), value.clone())))
+            .collect();
+        if !derived.is_empty() {
+            carryover.metadata.insert(entity.isgl1_key.clone(), derived);
+        }
+    }
+    carryover
+}
+
+/// Keys of the carryover whose derived artifacts no longer apply
+///
+/// # 4-Word Name: plan_derived_artifact_invalidation
+///
+/// # Contract
+/// - Postcondition: a key is listed when it is absent from `current` or its
+///   content hash differs; sorted
+pub fn plan_derived_artifact_invalidation(
+    carryover: &DerivedArtifactCarryoverSet,
+    current: &[CodeEntity],
+) -> Vec<String> {
+    let current_fingerprints: HashMap<&str, String> = current
+        .iter()
+        .map(|e| (e.isgl1_key.as_str(), entity_content_fingerprint(e)))
+        .collect();
+    let mut keys: Vec<String> = carryover
+        .fingerprints
+        .iter()
+        .filter(|(key, fingerprint)| current_fingerprints.get(key.as_str()) != Some(*fingerprint))
+        .map(|(key, _)| key.clone())
+        .collect();
+    keys.sort();
+    keys
+}
+
+/// Put derived metadata back on unchanged re-parsed entities
+///
+/// # 4-Word Name: restore_unchanged_derived_artifacts
+///
+/// # Contract
+/// - Postcondition: unchanged entities regain their summary metadata; the
+///   outcome lists every changed or removed key, whose embeddings the caller
+///   deletes
+pub fn restore_unchanged_derived_artifacts(
+    current: &mut [CodeEntity],
+    carryover: &DerivedArtifactCarryoverSet,
+) -> DerivedArtifactInvalidationOutcome {
+    let invalidated_keys = plan_derived_artifact_invalidation(carryover, current);
+    let invalidated: HashSet<&str> = invalidated_keys.iter().map(String::as_str).collect();
+
+    let mut kept_summary_count = 0;
+    for entity in current.iter_mut() {
+        if invalidated.contains(entity.isgl1_key.as_str()) {
+            continue;
+        }
+        let Some(derived) = carryover.metadata.get(&entity.isgl1_key) else {
+            continue;
+        };
+        for (key, value) in derived {
+            entity.metadata.additional.insert(key.clone(), value.clone());
+        }
+        kept_summary_count += 1;
+    }
+
+    DerivedArtifactInvalidationOutcome { kept_summary_count, invalidated_keys }
+}
+
+/// Remove derived keys from one metadata map
+///
+/// # 4-Word Name: strip_derived_symbol_metadata
+///
+/// # Returns
+/// Whether anything was removed
+pub fn strip_derived_symbol_metadata(metadata: &mut HashMap<String, String>) -> bool {
+    let before = metadata.len();
+    for key in DERIVED_METADATA_KEY_LIST {
+        metadata.remove(*key);
+    }
+    metadata.len() != before
+}
+
+/// Compare stored summaries and embeddings against the current graph
+///
+/// # 4-Word Name: verify_derived_symbol_artifacts
+///
+/// # Contract
+/// - Precondition: `entities` carry their stored metadata
+/// - Postcondition: embeddings of other models are neither current nor stale;
+///   key lists are sorted
+pub fn verify_derived_symbol_artifacts(
+    entities: &[CodeEntity],
+    embeddings: &[SymbolEmbeddingRecord],
+) -> DerivedArtifactVerifyReport {
+    let mut report = DerivedArtifactVerifyReport {
+        symbols_checked: entities.len(),
+        ..Default::default()
+    };
+
+    for entity in entities {
+        let additional = &entity.metadata.additional;
+        if !additional.contains_key(SUMMARY_METADATA_KEY) {
+            continue;
+        }
+        let expected = additional.get(SUMMARY_MODEL_METADATA_KEY).and_then(|model| {
+            build_symbol_summary_prompt(entity).map(|prompt| compute_summary_cache_key(model, &prompt))
+        });
+        if expected.is_some() && expected.as_ref() == additional.get(SUMMARY_HASH_METADATA_KEY) {
+            report.summaries_current += 1;
+        } else {
+            report.stale_summary_keys.push(entity.isgl1_key.clone());
+        }
+    }
+
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    for record in embeddings {
+        let Some(entity) = by_key.get(record.isgl1_key.as_str()) else {
+            report.orphan_embedding_keys.push(record.isgl1_key.clone());
+            continue;
+        };
+        // The embedded text does not depend on the model, so every record is checked
+        if compute_embedding_input_hash(entity) == record.input_hash {
+            report.embeddings_current += 1;
+        } else {
+            report.stale_embedding_keys.push(record.isgl1_key.clone());
+        }
+    }
+
+    report.stale_summary_keys.sort();
+    report.stale_embedding_keys.sort();
+    report.orphan_embedding_keys.sort();
+    report
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::semantic_symbol_embedding_index::compute_symbol_embedding_record;
+
+    fn entity_with_code(key: &str, name: &str, code: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, EntityType::Function, "svc/pay.go", &[]);
+        entity.current_code = Some(code.to_string());
+        entity.content_hash = Some(compute_content_hash(code));
+        entity
+    }
+
+    #[test]
+    fn test_only_changed_symbols_lose_summaries() {
+        let previous = vec![
+            entity_with_code("go:fn:Charge:__svc:T1", "Charge", "func Charge() {}"),
+            entity_with_code("go:fn:Refund:__svc:T2", "Refund", "func Refund() {}"),
+            entity_with_code("go:fn:Void:__svc:T3", "Void", "func Void() {}"),
+        ];
+        let mut stored = HashMap::new();
+        for entity in &previous {
+            stored.insert(
+                entity.isgl1_key.clone(),
+                HashMap::from([
+                    (SUMMARY_METADATA_KEY.to_string(), format!("About {}", entity.interface_signature.name)),
+                    (SUMMARY_HASH_METADATA_KEY.to_string(), "h".to_string()),
+                    ("receiver".to_string(), "Service".to_string()),
+                ]),
+            );
+        }
+        let carryover = collect_derived_artifact_carryover(&previous, &stored);
+
+        // Refund edited in place, Void deleted
+        let mut current = vec![
+            entity_with_code("go:fn:Charge:__svc:T1", "Charge", "func Charge() {}"),
+            entity_with_code("go:fn:Refund:__svc:T2", "Refund", "func Refund() { audit() }"),
+        ];
+        let outcome = restore_unchanged_derived_artifacts(&mut current, &carryover);
+
+        assert_eq!(outcome.kept_summary_count, 1);
+        assert_eq!(outcome.invalidated_keys, vec!["go:fn:Refund:__svc:T2", "go:fn:Void:__svc:T3"]);
+        assert_eq!(current[0].metadata.additional.get(SUMMARY_METADATA_KEY).unwrap(), "About Charge");
+        assert!(!current[0].metadata.additional.contains_key("receiver"));
+        assert!(current[1].metadata.additional.is_empty());
+
+        let mut metadata = stored["go:fn:Refund:__svc:T2"].clone();
+        assert!(strip_derived_symbol_metadata(&mut metadata));
+        assert_eq!(metadata.len(), 1);
+    }
+
+    #[test]
+    fn test_verify_reports_stale_and_orphan_entries() {
+        let mut current = entity_with_code("go:fn:Charge:__svc:T1", "Charge", "func Charge() {}");
+        let prompt = build_symbol_summary_prompt(&current).unwrap();
+        current.metadata.additional = HashMap::from([
+            (SUMMARY_METADATA_KEY.to_string(), "Charges.".to_string()),
+            (SUMMARY_HASH_METADATA_KEY.to_string(), compute_summary_cache_key("llama3.1", &prompt)),
+            (SUMMARY_MODEL_METADATA_KEY.to_string(), "llama3.1".to_string()),
+        ]);
+        let mut edited = entity_with_code("go:fn:Refund:__svc:T2", "Refund", "func Refund() {}");
+        edited.metadata.additional = HashMap::from([
+            (SUMMARY_METADATA_KEY.to_string(), "Refunds.".to_string()),
+            (SUMMARY_HASH_METADATA_KEY.to_string(), "old".to_string()),
+            (SUMMARY_MODEL_METADATA_KEY.to_string(), "llama3.1".to_string()),
+        ]);
+        let mut stale_embedding = compute_symbol_embedding_record(&edited);
+        stale_embedding.input_hash = "old".to_string();
+        let mut orphan = compute_symbol_embedding_record(&current);
+        orphan.isgl1_key = "go:fn:Gone:__svc:T9".to_string();
+        // Vectors from an embeddings endpoint are verified like hashed ones
+        let mut served = compute_symbol_embedding_record(&current);
+        served.model = "nomic-embed-text".to_string();
+        let embeddings = vec![served, stale_embedding, orphan];
+
+        let report = verify_derived_symbol_artifacts(&[current, edited], &embeddings);
+        assert_eq!(report.summaries_current, 1);
+        assert_eq!(report.stale_summary_keys, vec!["go:fn:Refund:__svc:T2"]);
+        assert_eq!(report.embeddings_current, 1);
+        assert_eq!(report.stale_embedding_keys, vec!["go:fn:Refund:__svc:T2"]);
+        assert_eq!(report.orphan_embedding_keys, vec!["go:fn:Gone:__svc:T9"]);
+        assert!(report.has_stale_artifact_entries());
+    }
+}
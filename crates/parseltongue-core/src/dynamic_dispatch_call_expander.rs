@@ -0,0 +1,505 @@
+//! Dynamic Dispatch Call Expander (v1.7.3)
+//!
+//! # 4-Word Naming: dynamic_dispatch_call_expander
+//!
+//! A call through a Go interface value (`store.Save(u)` with
+//! `store UserStore`) or a Rust trait object (`repo.get(id)` with
+//! `repo: &dyn Repo`) dead-ends at an unresolved `Save`/`get`: the callee is
+//! only known at run time. This module fans such calls out to every known
+//! implementer as low-confidence edges.
+//!
+//! ## Two passes
+//!
+//! 1. Per file (query_extractor): call edges whose operand has a statically
+//!    known type get `receiver_static_type` metadata
+//!    - Go: typed parameters, `var x T`, and fields of structs declared in the
+//!      same file (`s.store.Save()`); the method's own receiver is skipped
+//!      (concrete, resolved by go_embedding_promotion_resolver)
+//!    - Rust: parameters, typed `let` and `self.field` whose type contains
+//!      `dyn Trait` (`&dyn Repo`, `Box<dyn Repo + Send>`, `Arc<dyn Repo>`)
+//! 2. Cross file (pt01, after Implements edges exist): every annotated call
+//!    whose type is a Go interface / Rust trait gets one extra edge per
+//!    implementer method, same edge type as the original call, with
+//!    `dispatch: dynamic` and `confidence: low`
+//!
+//! The original edge is kept, so consumers that want static edges only drop
+//! `dispatch == dynamic`.
+//!
+//! ## Limitations (syntactic)
+//!
+//! - Go implementers come from structural `Implements` edges (method names
+//!   only); promoted methods count
+//! - Rust trait default methods not overridden by an impl get no edge
+
+use std::collections::{HashMap, HashSet};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_channel_operation_extractor::{build_go_function_local_scope, find_go_declaration_entity_key};
+use crate::go_embedding_promotion_resolver::{
+    package_directory_of_entity, parse_unresolved_reference_name, GoTypeMethodSetIndex,
+};
+use crate::go_field_access_extractor::{collect_go_file_type_shapes, resolve_go_operand_type};
+use crate::query_extractor::{EntityType as ParsedEntityType, ParsedEntity};
+use crate::rust_trait_impl_bound_extractor::{find_rust_item_entity_key, trait_reference_parts};
+
+/// Edge metadata key: declared type of the call operand (`UserStore`, `models.Store`, `Repo`)
+pub const RECEIVER_STATIC_TYPE_METADATA_KEY: &str = "receiver_static_type";
+
+/// Edge metadata key marking fanned-out calls
+pub const DISPATCH_METADATA_KEY: &str = "dispatch";
+
+/// `dispatch` value of edges added by this pass
+pub const DISPATCH_DYNAMIC_METADATA_VALUE: &str = "dynamic";
+
+/// Call site identity: (caller key, method name, line)
+type CallSiteLookupKey = (String, String, usize);
+
+/// Annotate Go method calls with the operand's declared type
+///
+/// # 4-Word Name: annotate_go_call_receiver_types
+///
+/// # Contract
+/// - Precondition: `dependencies` and `entities` come from the same parse
+/// - Postcondition: Calls/Spawns edges on typed locals, params and same-file
+///   struct fields carry `receiver_static_type`; other edges untouched
+pub fn annotate_go_call_receiver_types(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    entities: &[ParsedEntity],
+    dependencies: &mut [DependencyEdge],
+) {
+    let shapes = collect_go_file_type_shapes(root, source);
+    let mut sites: HashMap<CallSiteLookupKey, String> = HashMap::new();
+
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, from_key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let scope = build_go_function_local_scope(declaration, source);
+
+        let mut stack = vec![body];
+        while let Some(node) = stack.pop() {
+            let mut children = node.walk();
+            stack.extend(node.named_children(&mut children));
+            if node.kind() != "call_expression" {
+                continue;
+            }
+            let Some(selector) = node.child_by_field_name("function").filter(|f| f.kind() == "selector_expression") else {
+                continue;
+            };
+            let (Some(operand), Some(field)) = (selector.child_by_field_name("operand"), selector.child_by_field_name("field")) else {
+                continue;
+            };
+            let is_own_receiver = operand.kind() == "identifier"
+                && scope.receiver.as_ref().is_some_and(|(receiver, _)| receiver == &source[operand.byte_range()]);
+            if is_own_receiver {
+                continue;
+            }
+            if let Some(type_name) = resolve_go_operand_type(operand, source, &scope, &shapes) {
+                let site = (from_key.clone(), source[field.byte_range()].to_string(), node.start_position().row + 1);
+                sites.insert(site, type_name);
+            }
+        }
+    }
+    apply_receiver_static_types(&sites, dependencies);
+}
+
+/// Annotate Rust method calls on `dyn Trait` values with the trait name
+///
+/// # 4-Word Name: annotate_rust_dyn_receiver_types
+///
+/// # Contract
+/// - Precondition: `dependencies` and `entities` come from the same parse
+/// - Postcondition: Calls edges whose operand is a `dyn Trait` parameter,
+///   typed `let` or `self` field carry `receiver_static_type` = trait name
+pub fn annotate_rust_dyn_receiver_types(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    entities: &[ParsedEntity],
+    dependencies: &mut [DependencyEdge],
+) {
+    let dyn_fields = collect_rust_struct_dyn_fields(root, source);
+    let mut sites: HashMap<CallSiteLookupKey, String> = HashMap::new();
+
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut children = node.walk();
+        stack.extend(node.named_children(&mut children));
+        if node.kind() != "function_item" {
+            continue;
+        }
+        let Some(from_key) = find_rust_item_entity_key(node, &ParsedEntityType::Function, source, entities) else {
+            continue;
+        };
+        let self_type = enclosing_rust_impl_type_name(node, source);
+
+        // Typed bindings are collected before calls are resolved: shadowing is ignored
+        let mut locals: HashMap<String, String> = HashMap::new();
+        let mut calls = Vec::new();
+        let mut inner = vec![node];
+        while let Some(current) = inner.pop() {
+            match current.kind() {
+                "function_item" if current.id() != node.id() => continue,
+                "parameter" | "let_declaration" => {
+                    let pattern = current.child_by_field_name("pattern").filter(|p| p.kind() == "identifier");
+                    let trait_name = current.child_by_field_name("type").and_then(|t| rust_dyn_trait_name(t, source));
+                    if let (Some(pattern), Some(trait_name)) = (pattern, trait_name) {
+                        locals.insert(source[pattern.byte_range()].to_string(), trait_name);
+                    }
+                }
+                "call_expression" => calls.push(current),
+                _ => {}
+            }
+            let mut children = current.walk();
+            inner.extend(current.named_children(&mut children));
+        }
+
+        for call in calls {
+            let Some(callee) = call.child_by_field_name("function").filter(|f| f.kind() == "field_expression") else {
+                continue;
+            };
+            let (Some(value), Some(method)) = (callee.child_by_field_name("value"), callee.child_by_field_name("field")) else {
+                continue;
+            };
+            let trait_name = match value.kind() {
+                "identifier" => locals.get(&source[value.byte_range()]).cloned(),
+                // self.repo.get(id)
+                "field_expression" => value
+                    .child_by_field_name("value")
+                    .filter(|owner| owner.kind() == "self")
+                    .zip(value.child_by_field_name("field"))
+                    .zip(self_type.as_ref())
+                    .and_then(|((_, field), owner)| {
+                        dyn_fields.get(&(owner.clone(), source[field.byte_range()].to_string())).cloned()
+                    }),
+                _ => None,
+            };
+            if let Some(trait_name) = trait_name {
+                let site = (from_key.clone(), source[method.byte_range()].to_string(), call.start_position().row + 1);
+                sites.insert(site, trait_name);
+            }
+        }
+    }
+    apply_receiver_static_types(&sites, dependencies);
+}
+
+/// Copy call-site types onto the matching Calls/Spawns edges
+fn apply_receiver_static_types(sites: &HashMap<CallSiteLookupKey, String>, dependencies: &mut [DependencyEdge]) {
+    if sites.is_empty() {
+        return;
+    }
+    for edge in dependencies.iter_mut() {
+        if !matches!(edge.edge_type, EdgeType::Calls | EdgeType::Spawns) {
+            continue;
+        }
+        let Some(method) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+        let Some(line) = edge
+            .source_location
+            .as_deref()
+            .and_then(|location| location.rsplit(':').next())
+            .and_then(|line| line.parse::<usize>().ok())
+        else {
+            continue;
+        };
+        let site = (edge.from_key.as_str().to_string(), method.to_string(), line);
+        if let Some(type_name) = sites.get(&site) {
+            edge.metadata.insert(RECEIVER_STATIC_TYPE_METADATA_KEY.to_string(), type_name.clone());
+        }
+    }
+}
+
+/// `(Struct, field)` → trait name for struct fields typed `dyn Trait`
+fn collect_rust_struct_dyn_fields(root: tree_sitter::Node<'_>, source: &str) -> HashMap<(String, String), String> {
+    let mut fields = HashMap::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut children = node.walk();
+        stack.extend(node.named_children(&mut children));
+        if node.kind() != "struct_item" {
+            continue;
+        }
+        let (Some(name), Some(body)) = (node.child_by_field_name("name"), node.child_by_field_name("body")) else {
+            continue;
+        };
+        let mut cursor = body.walk();
+        for field in body.named_children(&mut cursor).filter(|f| f.kind() == "field_declaration") {
+            let field_name = field.child_by_field_name("name");
+            let trait_name = field.child_by_field_name("type").and_then(|t| rust_dyn_trait_name(t, source));
+            if let (Some(field_name), Some(trait_name)) = (field_name, trait_name) {
+                fields.insert(
+                    (source[name.byte_range()].to_string(), source[field_name.byte_range()].to_string()),
+                    trait_name,
+                );
+            }
+        }
+    }
+    fields
+}
+
+/// Trait of the first `dyn Trait` inside a type (`Arc<Mutex<dyn Repo>>` → `Repo`)
+fn rust_dyn_trait_name(type_node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    let mut stack = vec![type_node];
+    while let Some(node) = stack.pop() {
+        if node.kind() == "dynamic_type" {
+            return node
+                .child_by_field_name("trait")
+                .and_then(|trait_node| trait_reference_parts(trait_node, source))
+                .map(|reference| reference.name);
+        }
+        let mut cursor = node.walk();
+        let children: Vec<_> = node.named_children(&mut cursor).collect();
+        stack.extend(children.into_iter().rev());
+    }
+    None
+}
+
+/// Implementing type name of the impl block a method sits in
+fn enclosing_rust_impl_type_name(function: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    let impl_item = function.parent()?.parent().filter(|p| p.kind() == "impl_item")?;
+    let type_node = impl_item.child_by_field_name("type")?;
+    let name_node = if type_node.kind() == "generic_type" {
+        type_node.child_by_field_name("type")?
+    } else {
+        type_node
+    };
+    Some(source[name_node.byte_range()].to_string())
+}
+
+/// Fan interface/trait-object calls out to implementer methods
+///
+/// # 4-Word Name: expand_dynamic_dispatch_call_edges
+///
+/// # Contract
+/// - Precondition: `edges` include Go structural and Rust `Implements` edges;
+///   call edges carry `receiver_static_type` from the per-file pass
+/// - Postcondition: one new edge per (caller, implementer method) not already
+///   present, marked `dispatch: dynamic`, `confidence: low`; input unchanged
+pub fn expand_dynamic_dispatch_call_edges(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<DependencyEdge> {
+    let go_index = GoTypeMethodSetIndex::build_from_code_entities(entities);
+    let entities_by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut rust_entities_by_file: HashMap<String, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities.iter().filter(|e| e.isgl1_key.starts_with("rust:")) {
+        rust_entities_by_file
+            .entry(entity.interface_signature.file_path.to_string_lossy().to_string())
+            .or_default()
+            .push(entity);
+    }
+
+    // Trait/interface key → Implements edges pointing at it
+    let mut implementers: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Implements) {
+        implementers.entry(edge.to_key.as_str()).or_default().push(edge);
+    }
+    let mut seen: HashSet<(String, String)> = edges
+        .iter()
+        .filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns))
+        .map(|e| (e.from_key.as_str().to_string(), e.to_key.as_str().to_string()))
+        .collect();
+
+    let mut expanded = Vec::new();
+    for edge in edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)) {
+        let Some(static_type) = edge.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY) else { continue };
+        let Some(method) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+
+        let targets = if edge.from_key.as_str().starts_with("go:") {
+            let Some(caller) = entities_by_key.get(edge.from_key.as_str()) else { continue };
+            let location = match static_type.split_once('.') {
+                Some((qualifier, name)) => go_index.resolve_qualified_type_location(qualifier, name),
+                None => go_index.resolve_type_name_location(&package_directory_of_entity(caller), static_type),
+            };
+            let interface = location
+                .and_then(|l| go_index.type_entity(&l))
+                .filter(|e| matches!(e.interface_signature.entity_type, EntityType::Trait | EntityType::Interface));
+            let Some(interface) = interface else { continue };
+            implementers
+                .get(interface.isgl1_key.as_str())
+                .into_iter()
+                .flatten()
+                .filter_map(|implements| entities_by_key.get(implements.from_key.as_str()))
+                .filter_map(|implementer| {
+                    let location = (package_directory_of_entity(implementer), implementer.interface_signature.name.clone());
+                    go_index.find_method_resolution_target(&location, method)
+                })
+                .map(|target| target.target_key)
+                .filter(|target| target != &interface.isgl1_key)
+                .collect::<Vec<_>>()
+        } else if edge.from_key.as_str().starts_with("rust:") {
+            let trait_key = format!("rust:fn:{}:unresolved-reference:0-0", static_type);
+            implementers
+                .get(trait_key.as_str())
+                .into_iter()
+                .flatten()
+                .filter_map(|implements| {
+                    let (file, line) = implements.source_location.as_deref()?.rsplit_once(':')?;
+                    Some((rust_entities_by_file.get(file)?, line.parse::<u32>().ok()?))
+                })
+                .flat_map(|(file_entities, impl_line)| {
+                    let impl_end = file_entities
+                        .iter()
+                        .find(|e| {
+                            matches!(e.interface_signature.entity_type, EntityType::ImplBlock { .. })
+                                && e.interface_signature.line_range.start == impl_line
+                        })
+                        .map(|e| e.interface_signature.line_range.end)
+                        .unwrap_or(impl_line);
+                    file_entities
+                        .iter()
+                        .filter(move |e| {
+                            let range = &e.interface_signature.line_range;
+                            e.interface_signature.entity_type == EntityType::Method
+                                && e.interface_signature.name == method
+                                && range.start > impl_line
+                                && range.end <= impl_end
+                        })
+                        .map(|e| e.isgl1_key.clone())
+                })
+                .collect::<Vec<_>>()
+        } else {
+            continue;
+        };
+
+        for target in targets {
+            if !seen.insert((edge.from_key.as_str().to_string(), target.clone())) {
+                continue;
+            }
+            let mut builder = DependencyEdge::builder()
+                .from_key(edge.from_key.as_str())
+                .to_key(target)
+                .edge_type(edge.edge_type)
+                .metadata_entry(DISPATCH_METADATA_KEY, DISPATCH_DYNAMIC_METADATA_VALUE)
+                .metadata_entry("confidence", "low")
+                .metadata_entry(RECEIVER_STATIC_TYPE_METADATA_KEY, static_type);
+            if let Some(location) = &edge.source_location {
+                builder = builder.source_location(location.clone());
+            }
+            if let Ok(expanded_edge) = builder.build() {
+                expanded.push(expanded_edge);
+            }
+        }
+    }
+    expanded
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{Language, LineRange};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::query_extractor::QueryBasedExtractor;
+    use crate::structural_interface_matcher::compute_go_structural_implements_edges;
+    use std::path::Path;
+
+    /// CodeEntity fixture with a real line range (fixture default is 1-5)
+    fn entity_at(key: &str, name: &str, entity_type: EntityType, file: &str, lines: (u32, u32), metadata: &[(&str, &str)]) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, entity_type, file, metadata);
+        entity.interface_signature.line_range = LineRange::new(lines.0, lines.1).unwrap();
+        entity
+    }
+
+    #[test]
+    fn test_go_interface_field_call_fans_out() {
+        let code = r#"package svc
+
+type Service struct {
+	store UserStore
+}
+
+func (s *Service) Register(name string) {
+	s.store.Save(name)
+	s.audit()
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new("svc/service.go"), Language::Go).unwrap();
+        let save = edges
+            .iter()
+            .find(|e| e.to_key.as_str().contains(":Save:"))
+            .expect("Save call edge");
+        assert_eq!(save.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY), Some("UserStore"));
+        let audit = edges.iter().find(|e| e.to_key.as_str().contains(":audit:"));
+        assert!(audit.map_or(true, |e| e.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY).is_none()));
+
+        let register_key = save.from_key.as_str().to_string();
+        let entities = vec![
+            create_go_test_entity(&register_key, "Register", EntityType::Method, "svc/service.go", &[("receiver_type", "Service")]),
+            create_go_test_entity("go:trait:UserStore:__svc:T1", "UserStore", EntityType::Trait, "svc/store.go", &[("interface_methods", "Save")]),
+            create_go_test_entity("go:struct:PgStore:__svc_pg:T2", "PgStore", EntityType::Struct, "svc/pg/pg.go", &[]),
+            create_go_test_entity("go:method:Save:__svc_pg:T3", "Save", EntityType::Method, "svc/pg/pg.go", &[("receiver_type", "PgStore")]),
+            create_go_test_entity("go:struct:MemStore:__svc:T4", "MemStore", EntityType::Struct, "svc/mem.go", &[]),
+            create_go_test_entity("go:method:Save:__svc_mem:T5", "Save", EntityType::Method, "svc/mem.go", &[("receiver_type", "MemStore")]),
+        ];
+        let mut all_edges = vec![save.clone()];
+        all_edges.extend(compute_go_structural_implements_edges(&entities));
+
+        let mut targets: Vec<_> = expand_dynamic_dispatch_call_edges(&entities, &all_edges)
+            .into_iter()
+            .inspect(|e| {
+                assert_eq!(e.metadata_value(DISPATCH_METADATA_KEY), Some(DISPATCH_DYNAMIC_METADATA_VALUE));
+                assert_eq!(e.metadata_value("confidence"), Some("low"));
+                assert_eq!(e.from_key.as_str(), register_key);
+            })
+            .map(|e| e.to_key.as_str().to_string())
+            .collect();
+        targets.sort();
+        assert_eq!(targets, vec!["go:method:Save:__svc_mem:T5", "go:method:Save:__svc_pg:T3"]);
+    }
+
+    #[test]
+    fn test_rust_dyn_trait_call_fans_out_to_impl_methods() {
+        let code = r#"pub trait Repo {
+    fn get(&self, id: u32) -> String;
+}
+
+pub struct Service {
+    repo: Box<dyn Repo + Send>,
+}
+
+impl Service {
+    pub fn load(&self, id: u32) -> String {
+        self.repo.get(id)
+    }
+}
+
+pub fn lookup(repo: &dyn Repo) -> String {
+    repo.get(1)
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new("src/service.rs"), Language::Rust).unwrap();
+        let annotated: Vec<&DependencyEdge> = edges
+            .iter()
+            .filter(|e| e.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY) == Some("Repo"))
+            .collect();
+        assert!(annotated.iter().any(|e| e.from_key.as_str().contains(":load:")), "{:?}", edges);
+        assert!(annotated.iter().any(|e| e.from_key.as_str().contains(":lookup:")), "{:?}", edges);
+
+        let entities = vec![
+            entity_at(
+                "rust:impl:PgRepo:__src_pg:T1",
+                "PgRepo",
+                EntityType::ImplBlock { trait_name: Some("Repo".to_string()), struct_name: "PgRepo".to_string() },
+                "src/pg.rs", (3, 7), &[]),
+            entity_at("rust:method:get:__src_pg:T2", "get", EntityType::Method, "src/pg.rs", (4, 6), &[]),
+            // Same name outside the impl block: not a target
+            entity_at("rust:fn:get:__src_pg:T3", "get", EntityType::Method, "src/pg.rs", (9, 11), &[]),
+        ];
+        let implements = DependencyEdge::builder()
+            .from_key("rust:impl:PgRepo:__src_pg:T1")
+            .to_key("rust:fn:Repo:unresolved-reference:0-0")
+            .edge_type(EdgeType::Implements)
+            .source_location("src/pg.rs:3")
+            .build()
+            .unwrap();
+        let mut all_edges: Vec<DependencyEdge> = annotated.into_iter().cloned().collect();
+        all_edges.push(implements);
+
+        let expanded = expand_dynamic_dispatch_call_edges(&entities, &all_edges);
+        assert_eq!(expanded.len(), 2, "one per caller: {:?}", expanded);
+        assert!(expanded.iter().all(|e| e.to_key.as_str() == "rust:method:get:__src_pg:T2"));
+    }
+}
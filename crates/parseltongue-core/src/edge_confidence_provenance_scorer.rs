@@ -0,0 +1,233 @@
+//! Edge Confidence Provenance Scorer (v1.7.3)
+//!
+//! # 4-Word Naming: edge_confidence_provenance_scorer
+//!
+//! Every edge gets a `provenance` (how its target was found) and a
+//! `confidence_score` in [0, 1], so consumers can tell certain
+//! relationships from guesses and queries can drop the guesses with
+//! `--min-confidence`.
+//!
+//! ## Provenance
+//!
+//! Rules in the order they are tried, first match wins:
+//!
+//! | provenance      | score     | evidence on the edge                                   |
+//! |-----------------|-----------|--------------------------------------------------------|
+//! | `dynamic`       | 0.3       | interface / trait object fan-out (`dispatch: dynamic`) |
+//! | `heuristic`     | 0.6       | function-value call (`confidence: indirect`), even once a resolver bound it |
+//! | `type-resolved` | 0.95      | imported from a SCIP/LSIF index (`imported_from`)      |
+//! | `type-resolved` | 0.95      | bound by the Go type checker (`resolution: go_types`, `--typed`) |
+//! | `type-resolved` | 0.9       | a resolver pass bound it (`resolution`: imports, receivers, packages) |
+//! | `heuristic`     | 0.7       | Go structural interface match (method names)           |
+//! | `heuristic`     | 0.6       | test reached through a helper                          |
+//! | `syntactic`     | 0.8       | external target qualified by its import path (`external`) |
+//! | `heuristic`     | 0.5       | unresolved placeholder endpoint, matched by name only  |
+//! | `syntactic`     | 0.85      | otherwise: the parse named an entity directly          |
+//!
+//! Full and incremental ingests stamp both keys into the edge metadata
+//! after the cross-file passes. Edges from databases written before that
+//! are assessed on read by the same rules.
+
+use crate::dynamic_dispatch_call_expander::DISPATCH_METADATA_KEY;
+use crate::entities::DependencyEdge;
+use crate::error::{ParseltongError, Result};
+use crate::external_code_index_importer::IMPORTED_FROM_METADATA_KEY;
+use crate::filtered_graph_traversal_queries::is_placeholder_target_key;
+use crate::go_module_version_resolver::EXTERNAL_METADATA_KEY;
+use crate::go_test_coverage_linker::TEST_COVERAGE_METADATA_KEY;
+use crate::go_typed_selector_resolver::GO_TYPES_RESOLUTION_VALUE;
+
+/// Edge metadata key: `syntactic`, `type-resolved`, `heuristic` or `dynamic`
+pub const EDGE_PROVENANCE_METADATA_KEY: &str = "provenance";
+
+/// Edge metadata key: confidence in [0, 1], two decimals
+pub const EDGE_CONFIDENCE_SCORE_METADATA_KEY: &str = "confidence_score";
+
+/// How an edge's target was determined
+///
+/// # 4-Word Name: EdgeResolutionProvenanceKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum EdgeResolutionProvenanceKind {
+    Syntactic,
+    TypeResolved,
+    Heuristic,
+    Dynamic,
+}
+
+impl EdgeResolutionProvenanceKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Syntactic => "syntactic",
+            Self::TypeResolved => "type-resolved",
+            Self::Heuristic => "heuristic",
+            Self::Dynamic => "dynamic",
+        }
+    }
+
+    fn from_metadata_value(value: &str) -> Option<Self> {
+        [Self::Syntactic, Self::TypeResolved, Self::Heuristic, Self::Dynamic]
+            .into_iter()
+            .find(|kind| kind.as_str() == value)
+    }
+}
+
+/// Provenance and score of one edge
+///
+/// # 4-Word Name: EdgeConfidenceAssessmentEntry
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct EdgeConfidenceAssessmentEntry {
+    pub provenance: EdgeResolutionProvenanceKind,
+    pub score: f64,
+}
+
+/// Assess one edge: stamped values when present, else the rules above
+///
+/// # 4-Word Name: assess_edge_resolution_confidence
+///
+/// # Contract
+/// - Postcondition: `score` in [0, 1]; rules are tried top to bottom of the
+///   module table, first match wins
+pub fn assess_edge_resolution_confidence(edge: &DependencyEdge) -> EdgeConfidenceAssessmentEntry {
+    let stamped = edge
+        .metadata_value(EDGE_PROVENANCE_METADATA_KEY)
+        .and_then(EdgeResolutionProvenanceKind::from_metadata_value)
+        .zip(edge.metadata_value(EDGE_CONFIDENCE_SCORE_METADATA_KEY).and_then(|v| v.parse::<f64>().ok()));
+    if let Some((provenance, score)) = stamped {
+        return EdgeConfidenceAssessmentEntry { provenance, score: score.clamp(0.0, 1.0) };
+    }
+
+    use EdgeResolutionProvenanceKind::*;
+    let (provenance, score) = if edge.metadata_value(DISPATCH_METADATA_KEY) == Some("dynamic")
+        || edge.metadata_value("confidence") == Some("low")
+    {
+        (Dynamic, 0.3)
+    } else if edge.metadata_value("confidence") == Some("indirect") {
+        // The call goes through a value; binding that value does not make it certain
+        (Heuristic, 0.6)
+    } else if edge.metadata_value(IMPORTED_FROM_METADATA_KEY).is_some()
+        || edge.metadata_value("resolution") == Some(GO_TYPES_RESOLUTION_VALUE)
+    {
+        (TypeResolved, 0.95)
+    } else if edge.metadata_value("resolution").is_some() {
+        (TypeResolved, 0.9)
+    } else if edge.metadata_value("match_kind") == Some("structural") {
+        (Heuristic, 0.7)
+    } else if edge.metadata_value(TEST_COVERAGE_METADATA_KEY) == Some("helper") {
+        (Heuristic, 0.6)
+    } else if edge.metadata_value(EXTERNAL_METADATA_KEY) == Some("true") {
+        (Syntactic, 0.8)
+    } else if is_placeholder_target_key(edge.to_key.as_str()) || is_placeholder_target_key(edge.from_key.as_str()) {
+        (Heuristic, 0.5)
+    } else {
+        (Syntactic, 0.85)
+    };
+    EdgeConfidenceAssessmentEntry { provenance, score }
+}
+
+/// Write provenance and score into every edge's metadata
+///
+/// # 4-Word Name: stamp_edge_confidence_provenance
+///
+/// # Contract
+/// - Postcondition: every edge carries both keys, computed from its current
+///   metadata; earlier stamps are replaced, so an edge a resolver re-bound
+///   or reverted is not left with a stale score
+pub fn stamp_edge_confidence_provenance(edges: &mut [DependencyEdge]) {
+    for edge in edges.iter_mut() {
+        edge.metadata.remove(EDGE_PROVENANCE_METADATA_KEY);
+        edge.metadata.remove(EDGE_CONFIDENCE_SCORE_METADATA_KEY);
+        let assessment = assess_edge_resolution_confidence(edge);
+        edge.metadata.insert(EDGE_PROVENANCE_METADATA_KEY.to_string(), assessment.provenance.as_str().to_string());
+        edge.metadata.insert(EDGE_CONFIDENCE_SCORE_METADATA_KEY.to_string(), format!("{:.2}", assessment.score));
+    }
+}
+
+/// Parse a `--min-confidence` value
+///
+/// # Contract
+/// - Postcondition: a number in [0, 1], else a validation error
+pub fn parse_min_confidence_value(value: &str) -> Result<f64> {
+    match value.trim().parse::<f64>() {
+        Ok(min) if (0.0..=1.0).contains(&min) => Ok(min),
+        _ => Err(ParseltongError::ValidationError {
+            field: "min-confidence".to_string(),
+            expected: "a number between 0 and 1".to_string(),
+            actual: value.to_string(),
+        }),
+    }
+}
+
+/// Drop edges whose confidence is below `min_confidence`
+///
+/// # 4-Word Name: retain_edges_meeting_confidence
+pub fn retain_edges_meeting_confidence(edges: &mut Vec<DependencyEdge>, min_confidence: f64) {
+    if min_confidence > 0.0 {
+        edges.retain(|edge| assess_edge_resolution_confidence(edge).score >= min_confidence);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+
+    fn edge(from: &str, to: &str, metadata: &[(&str, &str)]) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls);
+        for (key, value) in metadata {
+            builder = builder.metadata_entry(*key, *value);
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_provenance_rules_stamping_and_filter() {
+        let mut edges = vec![
+            edge("go:fn:Handle:__api:T1", "go:fn:validate:__api:T2", &[]),
+            edge("go:fn:Handle:__api:T1", "go:method:Save:__store:T3", &[("resolution", "receiver_method")]),
+            edge("go:fn:Handle:__api:T1", "go:fn:Sprintf:unresolved-reference:0-0", &[]),
+            edge("go:fn:Handle:__api:T1", "go:method:Save:__pg:T4", &[("dispatch", "dynamic"), ("confidence", "low")]),
+            edge("go:fn:Handle:__api:T1", "go:fn:log:__api:T5", &[("provenance", "heuristic"), ("confidence_score", "0.40")]),
+        ];
+        let provenance: Vec<&str> = edges.iter().map(|e| assess_edge_resolution_confidence(e).provenance.as_str()).collect();
+        assert_eq!(provenance, vec!["syntactic", "type-resolved", "heuristic", "dynamic", "heuristic"], "stamps are read back");
+
+        let mut read_filtered = edges.clone();
+        retain_edges_meeting_confidence(&mut read_filtered, parse_min_confidence_value("0.8").unwrap());
+        assert_eq!(read_filtered.len(), 2);
+
+        stamp_edge_confidence_provenance(&mut edges);
+        assert_eq!(edges[2].metadata_value(EDGE_CONFIDENCE_SCORE_METADATA_KEY), Some("0.50"));
+        assert_eq!(edges[4].metadata_value(EDGE_PROVENANCE_METADATA_KEY), Some("syntactic"), "restamped from its metadata");
+        assert!(parse_min_confidence_value("1.5").is_err());
+        assert!(parse_min_confidence_value("high").is_err());
+    }
+
+    #[test]
+    fn test_retargeted_function_value_calls_stay_heuristic() {
+        // `handler := s.m; handler()` after the resolver passes bound the value
+        let mut edges = vec![
+            edge(
+                "go:fn:Run:__api:T1",
+                "go:method:Serve:__api:T2",
+                &[("confidence", "indirect"), ("receiver_expr", "s"), ("resolution", "receiver_method")],
+            ),
+            edge(
+                "go:fn:Run:__api:T1",
+                "go:fn:Save:__store:T3",
+                &[("confidence", "indirect"), ("resolution", "imported_package")],
+            ),
+            edge("go:fn:Run:__api:T1", "go:fn:Load:__store:T4", &[("confidence", "indirect"), ("dispatch", "dynamic")]),
+        ];
+        let assessed: Vec<(&str, f64)> = edges
+            .iter()
+            .map(|e| assess_edge_resolution_confidence(e))
+            .map(|a| (a.provenance.as_str(), a.score))
+            .collect();
+        assert_eq!(assessed, vec![("heuristic", 0.6), ("heuristic", 0.6), ("dynamic", 0.3)]);
+
+        stamp_edge_confidence_provenance(&mut edges);
+        assert_eq!(edges[0].metadata_value(EDGE_CONFIDENCE_SCORE_METADATA_KEY), Some("0.60"));
+        retain_edges_meeting_confidence(&mut edges, parse_min_confidence_value("0.9").unwrap());
+        assert!(edges.is_empty(), "{:?}", edges);
+    }
+}
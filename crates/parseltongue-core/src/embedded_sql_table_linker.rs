@@ -0,0 +1,832 @@
+//! Embedded SQL Table Linker (v1.7.3)
+//!
+//! # 4-Word Naming: embedded_sql_table_linker
+//!
+//! "Who reads the `users` table?" The answer hides in string literals:
+//! `db.QueryContext(ctx, "SELECT id, email FROM users WHERE ...")`. This
+//! module reads those strings and the migrations declaring the tables, and
+//! joins them with `QueriesTable` edges.
+//!
+//! ## Steps
+//!
+//! 1. Per Go file, `extract_go_embedded_sql_edges` finds SQL passed to
+//!    `database/sql` (`Query`, `QueryRow`, `Exec`, `Prepare` and their
+//!    `Context` forms, also pgx), sqlx (`Get`, `Select`, `Queryx`,
+//!    `NamedExec`, `MustExec`, ...) and gorm (`Raw`, `Exec`, `Table("users")`).
+//!    The SQL is a string literal, a `+` concatenation of literals, or a
+//!    const / local variable bound to one in the same file. Each referenced
+//!    table becomes an edge to `sql:table:{name}:unresolved-reference:0-0`
+//! 2. `load_sql_schema_entities` turns `CREATE TABLE` / `CREATE VIEW` of
+//!    every `.sql` file under the ingest root (`*.down.sql` excluded) into
+//!    `sql:table:` / `sql:view:` nodes with a `columns` list; `ALTER TABLE
+//!    ... ADD COLUMN` extends it
+//! 3. `resolve_embedded_sql_table_edges` binds the placeholders to those
+//!    nodes by table name (`resolution: sql_schema`)
+//!
+//! Edge metadata: `sql_operation` (`select`, `insert`, `update`, `delete`,
+//! or `orm` for gorm's `Table`) and `sql_columns` (columns the statement
+//! names for that table: select list, insert list, `SET` targets). Names
+//! are compared in lower case, without quotes or schema (`public.users` is
+//! `users`). Strings that do not start like a statement (`SELECT`, `WITH`,
+//! `INSERT`, ...) are not SQL. Queries built at run time (`fmt.Sprintf`,
+//! query builders) are not seen.
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_embedding_promotion_resolver::{parse_unresolved_reference_name, UNRESOLVED_TARGET_METADATA_KEY};
+use crate::protobuf_service_definition_linker::{build_contract_file_entity, find_contract_files_by_extension};
+use crate::query_extractor::ParsedEntity;
+
+/// Edge metadata key: `select`, `insert`, `update`, `delete` or `orm`
+pub const SQL_OPERATION_METADATA_KEY: &str = "sql_operation";
+
+/// Edge metadata key: columns of the table named by the statement, comma-separated
+pub const SQL_COLUMNS_METADATA_KEY: &str = "sql_columns";
+
+/// Entity metadata key: declared columns of a schema table, comma-separated
+pub const SCHEMA_COLUMNS_METADATA_KEY: &str = "columns";
+
+/// Methods whose string arguments are SQL statements
+const SQL_STATEMENT_METHOD_NAMES: &[&str] = &[
+    "Query", "QueryContext", "QueryRow", "QueryRowContext", "Exec", "ExecContext", "Prepare", "PrepareContext",
+    "Queryx", "QueryxContext", "QueryRowx", "QueryRowxContext", "Get", "GetContext", "Select", "SelectContext",
+    "NamedExec", "NamedExecContext", "NamedQuery", "NamedQueryContext", "MustExec", "MustExecContext",
+    "Preparex", "PreparexContext", "Raw",
+];
+
+const SQL_STATEMENT_LEADING_KEYWORDS: &[&str] = &["SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "UPSERT"];
+
+/// Keywords ending a table reference or a clause
+const SQL_CLAUSE_KEYWORDS: &[&str] = &[
+    "WHERE", "JOIN", "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "OUTER", "ON", "USING", "GROUP", "ORDER", "LIMIT",
+    "OFFSET", "HAVING", "UNION", "EXCEPT", "INTERSECT", "RETURNING", "SET", "VALUES", "SELECT", "FROM", "AS",
+    "NATURAL", "LATERAL", "WINDOW", "FOR", "OF", "ONLY", "SKIP", "NOWAIT", "CONFLICT", "DO", "NOTHING", "DEFAULT",
+    "OUTPUT",
+];
+
+/// One table named by one statement
+///
+/// # 4-Word Name: SqlTableReferenceEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SqlTableReferenceEntry {
+    /// Lower case, no schema or quotes
+    pub table: String,
+    pub operation: &'static str,
+    pub columns: Vec<String>,
+}
+
+/// One `CREATE TABLE` / `CREATE VIEW` with the columns added later
+///
+/// # 4-Word Name: SqlSchemaTableDefinition
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SqlSchemaTableDefinition {
+    pub name: String,
+    pub is_view: bool,
+    pub columns: Vec<String>,
+    pub line_start: u32,
+    pub line_end: u32,
+}
+
+/// Tables referenced by one SQL statement
+///
+/// # 4-Word Name: parse_sql_table_references
+///
+/// # Contract
+/// - Postcondition: empty unless `sql` starts like a statement; one entry
+///   per (table, operation) in first-reference order; CTE names are not tables
+pub fn parse_sql_table_references(sql: &str) -> Vec<SqlTableReferenceEntry> {
+    let tokens: Vec<String> = tokenize_sql_text(sql).into_iter().map(|t| t.0).collect();
+    if !tokens.first().is_some_and(|t| SQL_STATEMENT_LEADING_KEYWORDS.contains(&t.to_uppercase().as_str())) {
+        return Vec::new();
+    }
+    let upper: Vec<String> = tokens.iter().map(|t| t.to_uppercase()).collect();
+    let is_word = |i: usize, word: &str| upper.get(i).is_some_and(|t| t.as_str() == word);
+
+    let mut references: Vec<SqlTableReferenceEntry> = Vec::new();
+    let mut aliases: HashMap<String, String> = HashMap::new();
+    let mut cte_names: HashSet<String> = HashSet::new();
+    let mut pending_columns: Vec<(Option<String>, String)> = Vec::new();
+
+    let mut i = 0;
+    while i < tokens.len() {
+        match upper[i].as_str() {
+            // WITH recent AS (...): `recent` is not a table
+            "WITH" | "," if is_word(i + 2, "AS") && tokens.get(i + 3).is_some_and(|t| t.as_str() == "(") => {
+                cte_names.insert(normalize_sql_object_name(&tokens[i + 1]));
+            }
+            "SELECT" => {
+                let mut depth = 0i32;
+                let mut item: Vec<&str> = Vec::new();
+                let mut j = i + 1;
+                while j < tokens.len() {
+                    let token = tokens[j].as_str();
+                    match token {
+                        "(" => depth += 1,
+                        ")" if depth == 0 => {
+                            pending_columns.extend(select_item_column(&item));
+                            break;
+                        }
+                        ")" => depth -= 1,
+                        _ => {}
+                    }
+                    if depth == 0 && (upper[j].as_str() == "FROM" || token == ",") {
+                        pending_columns.extend(select_item_column(&item));
+                        item.clear();
+                        if token != "," {
+                            break;
+                        }
+                    } else {
+                        item.push(token);
+                    }
+                    j += 1;
+                }
+                if j == tokens.len() {
+                    pending_columns.extend(select_item_column(&item));
+                }
+            }
+            "FROM" | "JOIN" | "INTO" | "UPDATE" => {
+                let operation = match upper[i].as_str() {
+                    "INTO" => "insert",
+                    "UPDATE" => "update",
+                    "FROM" if i > 0 && is_word(i - 1, "DELETE") => "delete",
+                    _ => "select",
+                };
+                let mut j = i + 1;
+                loop {
+                    // `ON CONFLICT DO UPDATE SET`, `FOR UPDATE`, `FROM (SELECT ...)` name no table
+                    let Some(name) = tokens
+                        .get(j)
+                        .filter(|t| is_sql_identifier_token(t) && !SQL_CLAUSE_KEYWORDS.contains(&upper[j].as_str()))
+                    else {
+                        break;
+                    };
+                    let table = normalize_sql_object_name(name);
+                    if !cte_names.contains(&table)
+                        && !references.iter().any(|r| r.table == table && r.operation == operation)
+                    {
+                        references.push(SqlTableReferenceEntry { table: table.clone(), operation, columns: Vec::new() });
+                    }
+                    j += 1;
+                    if is_word(j, "AS") {
+                        j += 1;
+                    }
+                    if let Some(alias) = tokens
+                        .get(j)
+                        .filter(|t| is_sql_identifier_token(t) && !SQL_CLAUSE_KEYWORDS.contains(&upper[j].as_str()))
+                    {
+                        aliases.insert(alias.to_lowercase(), table.clone());
+                        j += 1;
+                    }
+                    aliases.insert(table.clone(), table.clone());
+                    if operation == "insert" && tokens.get(j).is_some_and(|t| t.as_str() == "(") {
+                        j += 1;
+                        while let Some(column) = tokens.get(j).filter(|t| t.as_str() != ")") {
+                            if is_sql_identifier_token(column) {
+                                pending_columns.push((Some(table.clone()), normalize_sql_object_name(column)));
+                            }
+                            j += 1;
+                        }
+                    }
+                    if operation == "update" && is_word(j, "SET") {
+                        let mut depth = 0i32;
+                        let mut k = j + 1;
+                        while k < tokens.len() {
+                            match tokens[k].as_str() {
+                                "(" => depth += 1,
+                                ")" => depth -= 1,
+                                "=" if depth == 0 && is_sql_identifier_token(&tokens[k - 1]) => {
+                                    let column = tokens[k - 1].rsplit('.').next().unwrap_or(&tokens[k - 1]);
+                                    pending_columns.push((Some(table.clone()), normalize_sql_object_name(column)));
+                                }
+                                _ if depth == 0 && matches!(upper[k].as_str(), "WHERE" | "FROM" | "RETURNING") => break,
+                                _ => {}
+                            }
+                            k += 1;
+                        }
+                    }
+                    // FROM a, b
+                    if operation == "select" && upper[i].as_str() == "FROM" && tokens.get(j).is_some_and(|t| t.as_str() == ",") {
+                        j += 1;
+                        continue;
+                    }
+                    break;
+                }
+            }
+            _ => {}
+        }
+        i += 1;
+    }
+
+    for (qualifier, column) in pending_columns {
+        let table = match qualifier {
+            Some(qualifier) => aliases.get(&qualifier).cloned().or(Some(qualifier)),
+            None => {
+                let mut tables: Vec<&str> = references.iter().map(|r| r.table.as_str()).collect();
+                tables.dedup();
+                (tables.len() == 1).then(|| tables[0].to_string())
+            }
+        };
+        let Some(table) = table else { continue };
+        for reference in references.iter_mut().filter(|r| r.table == table) {
+            if !reference.columns.contains(&column) {
+                reference.columns.push(column.clone());
+            }
+        }
+    }
+    references
+}
+
+/// `QueriesTable` edges for the SQL strings of one Go file
+///
+/// # 4-Word Name: extract_go_embedded_sql_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: per function/method, one edge per (table, operation),
+///   at the first statement naming it; columns merged over statements
+pub fn extract_go_embedded_sql_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let mut file_strings: HashMap<String, String> = HashMap::new();
+    collect_go_string_bindings(root, source, &mut file_strings, false);
+
+    let mut edges = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, from_key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let mut strings = file_strings.clone();
+        collect_go_string_bindings(body, source, &mut strings, true);
+
+        // (table, operation) -> (line, columns)
+        let mut sites: BTreeMap<(String, &'static str), (usize, Vec<String>)> = BTreeMap::new();
+        let mut order: Vec<(String, &'static str)> = Vec::new();
+        let mut stack = vec![body];
+        while let Some(node) = stack.pop() {
+            let mut child_cursor = node.walk();
+            stack.extend(node.named_children(&mut child_cursor));
+            if node.kind() != "call_expression" {
+                continue;
+            }
+            let Some(method) = node
+                .child_by_field_name("function")
+                .filter(|f| f.kind() == "selector_expression")
+                .and_then(|f| f.child_by_field_name("field"))
+                .map(|f| &source[f.byte_range()])
+            else {
+                continue;
+            };
+            let Some(arguments) = node.child_by_field_name("arguments") else { continue };
+            let mut argument_cursor = arguments.walk();
+            let values: Vec<String> = arguments
+                .named_children(&mut argument_cursor)
+                .filter_map(|a| resolve_go_string_value(a, source, &strings))
+                .collect();
+            let references = if method == "Table" {
+                values
+                    .first()
+                    .filter(|name| !name.trim().is_empty() && !name.contains(char::is_whitespace))
+                    .map(|name| {
+                        vec![SqlTableReferenceEntry { table: normalize_sql_object_name(name), operation: "orm", columns: vec![] }]
+                    })
+                    .unwrap_or_default()
+            } else if SQL_STATEMENT_METHOD_NAMES.contains(&method) {
+                values.iter().map(|v| parse_sql_table_references(v)).find(|r| !r.is_empty()).unwrap_or_default()
+            } else {
+                continue;
+            };
+            let line = node.start_position().row + 1;
+            for reference in references {
+                let id = (reference.table, reference.operation);
+                let site = sites.entry(id.clone()).or_insert_with(|| {
+                    order.push(id);
+                    (line, Vec::new())
+                });
+                site.0 = site.0.min(line);
+                for column in reference.columns {
+                    if !site.1.contains(&column) {
+                        site.1.push(column);
+                    }
+                }
+            }
+        }
+
+        order.sort_by_key(|id| sites[id].0);
+        for id in order {
+            let (line, columns) = &sites[&id];
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(format!("sql:table:{}:unresolved-reference:0-0", id.0))
+                .edge_type(EdgeType::QueriesTable)
+                .source_location(format!("{}:{}", path_text, line))
+                .metadata_entry(SQL_OPERATION_METADATA_KEY, id.1);
+            if !columns.is_empty() {
+                builder = builder.metadata_entry(SQL_COLUMNS_METADATA_KEY, columns.join(","));
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+/// Tables and views declared by one `.sql` file (migration or schema dump)
+///
+/// # 4-Word Name: parse_sql_schema_definitions
+///
+/// # Contract
+/// - Postcondition: declaration order; `ALTER TABLE t ADD [COLUMN] c`
+///   extends a table declared earlier in the same file, or yields a
+///   definition without lines (0, 0) for one declared elsewhere
+pub fn parse_sql_schema_definitions(source: &str) -> Vec<SqlSchemaTableDefinition> {
+    let tokens = tokenize_sql_text(source);
+    let upper: Vec<String> = tokens.iter().map(|t| t.0.to_uppercase()).collect();
+    let mut definitions: Vec<SqlSchemaTableDefinition> = Vec::new();
+    let mut i = 0;
+    while i < tokens.len() {
+        let statement_end = (i..tokens.len()).find(|&j| tokens[j].0.as_str() == ";").unwrap_or(tokens.len());
+        let words: Vec<&str> = upper[i..statement_end].iter().map(|w| w.as_str()).collect();
+        let skip_modifiers = |at: usize, modifiers: &[&str]| skip_sql_modifier_words(&words, at, modifiers);
+        match words.first().copied() {
+            Some("CREATE") => {
+                let at = skip_modifiers(1, &["OR", "REPLACE", "TEMP", "TEMPORARY", "UNLOGGED", "MATERIALIZED", "VIRTUAL", "GLOBAL", "LOCAL"]);
+                let is_view = match words.get(at).copied() {
+                    Some("TABLE") => false,
+                    Some("VIEW") => true,
+                    _ => {
+                        i = statement_end + 1;
+                        continue;
+                    }
+                };
+                let at = skip_modifiers(at + 1, &["IF", "NOT", "EXISTS"]);
+                if let Some((name, _)) = tokens.get(i + at).filter(|t| is_sql_identifier_token(&t.0)) {
+                    let mut definition = SqlSchemaTableDefinition {
+                        name: normalize_sql_object_name(name),
+                        is_view,
+                        columns: Vec::new(),
+                        line_start: tokens[i].1,
+                        line_end: tokens.get(statement_end).or(tokens.last()).map(|t| t.1).unwrap_or(tokens[i].1),
+                    };
+                    if !is_view && tokens.get(i + at + 1).is_some_and(|t| t.0.as_str() == "(") {
+                        let mut depth = 0i32;
+                        let mut item_start = true;
+                        for (j, (token, _)) in tokens.iter().enumerate().take(statement_end).skip(i + at + 1) {
+                            match token.as_str() {
+                                "(" => {
+                                    depth += 1;
+                                    continue;
+                                }
+                                ")" => depth -= 1,
+                                "," if depth == 1 => {
+                                    item_start = true;
+                                    continue;
+                                }
+                                _ => {}
+                            }
+                            if depth == 1 && item_start {
+                                item_start = false;
+                                let constraint = matches!(
+                                    upper[j].as_str(),
+                                    "CONSTRAINT" | "PRIMARY" | "FOREIGN" | "UNIQUE" | "CHECK" | "KEY" | "INDEX" | "EXCLUDE" | "LIKE"
+                                );
+                                if !constraint && is_sql_identifier_token(token) {
+                                    definition.columns.push(normalize_sql_object_name(token));
+                                }
+                            }
+                        }
+                    }
+                    definitions.push(definition);
+                }
+            }
+            Some("ALTER") if words.get(1).copied() == Some("TABLE") => {
+                let at = skip_modifiers(2, &["IF", "EXISTS", "ONLY"]);
+                if let Some((name, _)) = tokens.get(i + at) {
+                    let name = normalize_sql_object_name(name);
+                    let mut added = Vec::new();
+                    for j in at + 1..words.len() {
+                        if words[j] == "ADD" {
+                            let column_at = skip_modifiers(j + 1, &["COLUMN", "IF", "NOT", "EXISTS"]);
+                            let is_constraint = matches!(
+                                words.get(column_at).copied(),
+                                Some("CONSTRAINT" | "PRIMARY" | "FOREIGN" | "UNIQUE" | "CHECK" | "INDEX")
+                            );
+                            if let Some((column, _)) = tokens.get(i + column_at).filter(|_| !is_constraint) {
+                                added.push(normalize_sql_object_name(column));
+                            }
+                        }
+                    }
+                    let index = match definitions.iter().position(|d| d.name == name) {
+                        Some(index) => index,
+                        None => {
+                            definitions.push(SqlSchemaTableDefinition {
+                                name,
+                                is_view: false,
+                                columns: Vec::new(),
+                                line_start: 0,
+                                line_end: 0,
+                            });
+                            definitions.len() - 1
+                        }
+                    };
+                    for column in added {
+                        if !definitions[index].columns.contains(&column) {
+                            definitions[index].columns.push(column);
+                        }
+                    }
+                }
+            }
+            _ => {}
+        }
+        i = statement_end + 1;
+    }
+    definitions
+}
+
+/// Schema nodes of every `.sql` file under `root`
+///
+/// # 4-Word Name: load_sql_schema_entities
+///
+/// # Contract
+/// - Postcondition: one node per table name, at its first `CREATE` in path
+///   order (numbered migrations in sequence); `columns` gathers every
+///   file's additions; tables only ever altered get no node
+pub fn load_sql_schema_entities(root: &Path) -> Vec<CodeEntity> {
+    let mut tables: Vec<(String, String, SqlSchemaTableDefinition)> = Vec::new();
+    for path in find_contract_files_by_extension(root, ".sql") {
+        if path.to_string_lossy().ends_with(".down.sql") {
+            continue;
+        }
+        let Ok(source) = std::fs::read_to_string(&path) else { continue };
+        for definition in parse_sql_schema_definitions(&source) {
+            match tables.iter_mut().find(|(_, _, t)| t.name == definition.name) {
+                Some((file, text, existing)) => {
+                    if existing.line_start == 0 && definition.line_start > 0 {
+                        let mut columns = std::mem::take(&mut existing.columns);
+                        *file = path.to_string_lossy().to_string();
+                        *text = source.clone();
+                        *existing = definition.clone();
+                        columns.retain(|c| !existing.columns.contains(c));
+                        existing.columns.extend(columns);
+                    } else {
+                        for column in definition.columns {
+                            if !existing.columns.contains(&column) {
+                                existing.columns.push(column);
+                            }
+                        }
+                    }
+                }
+                None => tables.push((path.to_string_lossy().to_string(), source.clone(), definition)),
+            }
+        }
+    }
+
+    tables
+        .into_iter()
+        .filter(|(_, _, definition)| definition.line_start > 0)
+        .filter_map(|(file, source, definition)| {
+            let lines: Vec<&str> = source.lines().collect();
+            let body = lines
+                .get(definition.line_start as usize - 1..(definition.line_end as usize).min(lines.len()))
+                .map(|block| block.join("\n"));
+            let kind = if definition.is_view { EntityType::View } else { EntityType::Table };
+            build_contract_file_entity(
+                "sql",
+                &file,
+                &definition.name,
+                kind,
+                (definition.line_start, definition.line_end),
+                body,
+                &[(SCHEMA_COLUMNS_METADATA_KEY, definition.columns.join(","))],
+            )
+        })
+        .collect()
+}
+
+/// Bind `sql:table:` placeholders to schema nodes by table name
+///
+/// # 4-Word Name: resolve_embedded_sql_table_edges
+///
+/// # Contract
+/// - Postcondition: returns the number of edges rebound; placeholders of
+///   tables no `.sql` file declares stay as they are
+pub fn resolve_embedded_sql_table_edges(entities: &[CodeEntity], edges: &mut [DependencyEdge]) -> usize {
+    let schema: HashMap<&str, &str> = entities
+        .iter()
+        .filter(|e| matches!(e.interface_signature.entity_type, EntityType::Table | EntityType::View))
+        .filter(|e| e.isgl1_key.starts_with("sql:"))
+        .map(|e| (e.interface_signature.name.as_str(), e.isgl1_key.as_str()))
+        .collect();
+    let mut rewritten = 0;
+    for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::QueriesTable) {
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+        let Some(target) = schema.get(name) else { continue };
+        if let Ok(key) = Isgl1Key::new(target.to_string()) {
+            edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), "sql_schema".to_string());
+            rewritten += 1;
+        }
+    }
+    rewritten
+}
+
+/// First index at or after `at` whose word is not one of `modifiers`
+fn skip_sql_modifier_words(words: &[&str], mut at: usize, modifiers: &[&str]) -> usize {
+    while words.get(at).is_some_and(|w| modifiers.iter().any(|m| m == w)) {
+        at += 1;
+    }
+    at
+}
+
+/// `id`, `u.email`, `email AS e` -> column; `*`, `count(*)`, literals -> none
+fn select_item_column(item: &[&str]) -> Option<(Option<String>, String)> {
+    let expression = match item {
+        [expression] | [expression, _] => *expression,
+        [expression, as_word, _] if as_word.eq_ignore_ascii_case("AS") => *expression,
+        _ => return None,
+    };
+    if !is_sql_identifier_token(expression) || expression.ends_with(['*', '.']) {
+        return None;
+    }
+    if SQL_CLAUSE_KEYWORDS.contains(&expression.to_uppercase().as_str()) || expression.eq_ignore_ascii_case("DISTINCT") {
+        return None;
+    }
+    Some(match expression.rsplit_once('.') {
+        Some((qualifier, column)) => (Some(normalize_sql_object_name(qualifier)), normalize_sql_object_name(column)),
+        None => (None, normalize_sql_object_name(expression)),
+    })
+}
+
+/// `"Public"."Users"` / `` `users` `` / `public.users` -> `users`
+fn normalize_sql_object_name(name: &str) -> String {
+    let last = name.rsplit('.').next().unwrap_or(name);
+    last.trim_matches(|c| matches!(c, '"' | '`' | '[' | ']')).to_lowercase()
+}
+
+fn is_sql_identifier_token(token: &str) -> bool {
+    token.chars().next().is_some_and(|c| c.is_alphabetic() || c == '_' || c == '"' || c == '`' || c == '[')
+}
+
+/// Words (with dotted / quoted parts), punctuation and their 1-based line;
+/// comments, string literals and bind parameters (`$1`, `?`, `:name`) dropped
+fn tokenize_sql_text(text: &str) -> Vec<(String, u32)> {
+    let chars: Vec<char> = text.chars().collect();
+    let mut tokens = Vec::new();
+    let (mut i, mut line) = (0, 1u32);
+    let is_word_char = |c: char| c.is_alphanumeric() || c == '_' || c == '.' || c == '$';
+    while i < chars.len() {
+        let c = chars[i];
+        match c {
+            '\n' => {
+                line += 1;
+                i += 1;
+            }
+            '-' if chars.get(i + 1) == Some(&'-') => {
+                while i < chars.len() && chars[i] != '\n' {
+                    i += 1;
+                }
+            }
+            '/' if chars.get(i + 1) == Some(&'*') => {
+                i += 2;
+                while i < chars.len() && !(chars[i] == '*' && chars.get(i + 1) == Some(&'/')) {
+                    line += (chars[i] == '\n') as u32;
+                    i += 1;
+                }
+                i += 2;
+            }
+            '\'' => {
+                i += 1;
+                while i < chars.len() && chars[i] != '\'' {
+                    line += (chars[i] == '\n') as u32;
+                    i += 1;
+                }
+                i += 1;
+            }
+            '?' => i += 1,
+            ':' | '$' | '@' if chars.get(i + 1).is_some_and(|n| n.is_alphanumeric()) => {
+                i += 1;
+                while i < chars.len() && (chars[i].is_alphanumeric() || chars[i] == '_') {
+                    i += 1;
+                }
+            }
+            '"' | '`' | '[' => {
+                let close = if c == '[' { ']' } else { c };
+                let start = i;
+                i += 1;
+                while i < chars.len() && chars[i] != close {
+                    i += 1;
+                }
+                i += 1;
+                // "schema"."table"
+                while i < chars.len() && (is_word_char(chars[i]) || chars[i] == close || chars[i] == c) {
+                    i += 1;
+                }
+                tokens.push((chars[start..i.min(chars.len())].iter().collect(), line));
+            }
+            c if is_word_char(c) => {
+                let start = i;
+                while i < chars.len() && (is_word_char(chars[i]) || (chars[i] == '"' && chars[i - 1] == '.')) {
+                    if chars[i] == '"' {
+                        i += 1;
+                        while i < chars.len() && chars[i] != '"' {
+                            i += 1;
+                        }
+                    }
+                    i += 1;
+                }
+                tokens.push((chars[start..i.min(chars.len())].iter().collect(), line));
+            }
+            c if c.is_whitespace() => i += 1,
+            c => {
+                tokens.push((c.to_string(), line));
+                i += 1;
+            }
+        }
+    }
+    tokens
+}
+
+/// String literal, `+` concatenation, or identifier bound to one
+fn resolve_go_string_value(node: tree_sitter::Node<'_>, source: &str, strings: &HashMap<String, String>) -> Option<String> {
+    let text = &source[node.byte_range()];
+    match node.kind() {
+        "raw_string_literal" => Some(text.trim_matches('`').to_string()),
+        "interpreted_string_literal" => Some(
+            text.strip_prefix('"')
+                .and_then(|t| t.strip_suffix('"'))
+                .unwrap_or(text)
+                .replace("\\n", "\n")
+                .replace("\\t", " ")
+                .replace("\\\"", "\""),
+        ),
+        "binary_expression" => {
+            let left = node.child_by_field_name("left")?;
+            let right = node.child_by_field_name("right")?;
+            Some(resolve_go_string_value(left, source, strings)? + &resolve_go_string_value(right, source, strings)?)
+        }
+        "parenthesized_expression" => resolve_go_string_value(node.named_child(0)?, source, strings),
+        "identifier" => strings.get(text).cloned(),
+        _ => None,
+    }
+}
+
+/// `const q = "..."`, `var q = "..."`, and inside bodies `q := "..."` / `q = "..."`
+fn collect_go_string_bindings(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    strings: &mut HashMap<String, String>,
+    include_assignments: bool,
+) {
+    let mut stack = vec![node];
+    while let Some(current) = stack.pop() {
+        let (names, values) = match current.kind() {
+            "const_spec" | "var_spec" => (
+                current.children_by_field_name("name", &mut current.walk()).collect::<Vec<_>>(),
+                current.child_by_field_name("value"),
+            ),
+            "short_var_declaration" | "assignment_statement" if include_assignments => (
+                current
+                    .child_by_field_name("left")
+                    .map(|l| l.named_children(&mut l.walk()).collect::<Vec<_>>())
+                    .unwrap_or_default(),
+                current.child_by_field_name("right"),
+            ),
+            "func_literal" | "function_declaration" | "method_declaration" if !include_assignments => continue,
+            _ => {
+                let mut cursor = current.walk();
+                stack.extend(current.named_children(&mut cursor));
+                continue;
+            }
+        };
+        let Some(values) = values else { continue };
+        let values: Vec<_> = values.named_children(&mut values.walk()).collect();
+        for (name, value) in names.iter().zip(values) {
+            if name.kind() != "identifier" {
+                continue;
+            }
+            if let Some(text) = resolve_go_string_value(value, source, strings) {
+                strings.insert(source[name.byte_range()].to_string(), text);
+            }
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_statement_tables_columns_and_schema() {
+        let references = parse_sql_table_references(
+            "SELECT u.id, u.email, o.total FROM public.users AS u JOIN \"orders\" o ON o.user_id = u.id WHERE u.id = $1",
+        );
+        assert_eq!(
+            references,
+            vec![
+                SqlTableReferenceEntry { table: "users".into(), operation: "select", columns: vec!["id".into(), "email".into()] },
+                SqlTableReferenceEntry { table: "orders".into(), operation: "select", columns: vec!["total".into()] },
+            ]
+        );
+        let insert = parse_sql_table_references("INSERT INTO users (email, name) SELECT email, name FROM invites");
+        assert_eq!((insert[0].operation, insert[0].columns.len()), ("insert", 2));
+        assert_eq!(insert[1].table, "invites");
+        let update = parse_sql_table_references("UPDATE users SET name = ?, updated_at = now() WHERE id = ?");
+        assert_eq!(update[0].columns, vec!["name".to_string(), "updated_at".to_string()]);
+        let delete = parse_sql_table_references("WITH old AS (SELECT id FROM sessions) DELETE FROM sessions WHERE id IN (SELECT id FROM old)");
+        let pairs: Vec<(&str, &str)> = delete.iter().map(|r| (r.table.as_str(), r.operation)).collect();
+        assert_eq!(pairs, vec![("sessions", "select"), ("sessions", "delete")]);
+        assert!(parse_sql_table_references("users").is_empty());
+
+        let schema = parse_sql_schema_definitions(
+            "-- 0001_init.up.sql\nCREATE TABLE IF NOT EXISTS users (\n  id BIGSERIAL PRIMARY KEY,\n  email TEXT NOT NULL,\n  CONSTRAINT users_email_key UNIQUE (email)\n);\nCREATE VIEW active_users AS SELECT * FROM users;\nALTER TABLE users ADD COLUMN name TEXT;\n",
+        );
+        assert_eq!(schema.len(), 2);
+        assert_eq!(schema[0].columns, vec!["id".to_string(), "email".to_string(), "name".to_string()]);
+        assert_eq!((schema[0].line_start, schema[0].line_end), (2, 6));
+        assert!(schema[1].is_view);
+
+        let table_key = "sql:table:users:__db_migrations_0001_init:T1";
+        let mut edges = vec![DependencyEdge::builder()
+            .from_key("go:method:FindUser:__internal_store:T2")
+            .to_key("sql:table:users:unresolved-reference:0-0")
+            .edge_type(EdgeType::QueriesTable)
+            .build()
+            .unwrap()];
+        let entities = vec![crate::go_embedding_promotion_resolver::create_go_test_entity(
+            table_key,
+            "users",
+            EntityType::Table,
+            "db/migrations/0001_init.up.sql",
+            &[],
+        )];
+        assert_eq!(resolve_embedded_sql_table_edges(&entities, &mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), table_key);
+    }
+    #[test]
+    fn test_aliases_attribute_qualified_columns() {
+        let references = parse_sql_table_references(
+            "SELECT o.id, c.name, status FROM orders o LEFT JOIN customers AS c ON c.id = o.customer_id",
+        );
+        assert_eq!(
+            references,
+            vec![
+                SqlTableReferenceEntry { table: "orders".into(), operation: "select", columns: vec!["id".into()] },
+                SqlTableReferenceEntry { table: "customers".into(), operation: "select", columns: vec!["name".into()] },
+            ]
+        );
+        let update = parse_sql_table_references("UPDATE accounts AS a SET a.balance = a.balance - $1 WHERE a.id = $2");
+        assert_eq!(update[0].table, "accounts");
+        assert_eq!(update[0].columns, vec!["balance".to_string()]);
+    }
+
+    #[test]
+    fn test_unparseable_statements_name_nothing() {
+        for sql in ["", "not sql at all", "FROM users", "SELECT", "SELECT * FROM (", "UPDATE", "INSERT INTO ("] {
+            assert!(parse_sql_table_references(sql).is_empty(), "{:?}", sql);
+        }
+        let dangling = parse_sql_table_references("SELECT id FROM users WHERE note = 'unterminated FROM secrets");
+        assert_eq!(
+            dangling,
+            vec![SqlTableReferenceEntry { table: "users".into(), operation: "select", columns: vec!["id".into()] }]
+        );
+        assert!(parse_sql_schema_definitions("CREATE TABLE (\n").is_empty());
+    }
+
+    #[test]
+    fn test_undeclared_table_keeps_placeholder() {
+        let placeholder = |table: &str| {
+            DependencyEdge::builder()
+                .from_key("go:method:Record:__internal_audit:T2")
+                .to_key(format!("sql:table:{}:unresolved-reference:0-0", table))
+                .edge_type(EdgeType::QueriesTable)
+                .build()
+                .unwrap()
+        };
+        let mut edges = vec![placeholder("audit_log"), placeholder("users")];
+        let entities = vec![crate::go_embedding_promotion_resolver::create_go_test_entity(
+            "sql:table:users:__db_schema:T1",
+            "users",
+            EntityType::Table,
+            "db/schema.sql",
+            &[],
+        )];
+        assert_eq!(resolve_embedded_sql_table_edges(&entities, &mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "sql:table:audit_log:unresolved-reference:0-0");
+        assert_eq!(edges[0].metadata_value(UNRESOLVED_TARGET_METADATA_KEY), None);
+        assert_eq!(edges[0].metadata_value("resolution"), None);
+        assert_eq!(edges[1].metadata_value("resolution"), Some("sql_schema"));
+    }
+}
@@ -5,7 +5,7 @@
 
 use crate::error::{ParseltongError, Result};
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 use std::path::PathBuf;
 use std::fmt;
 
@@ -120,6 +120,7 @@ pub enum EntityType {
     // v1.5.6: SQL-specific entity types
     Table,      // SQL CREATE TABLE
     View,       // SQL CREATE VIEW
+    Route,      // v1.7.3: HTTP route registration (`POST /users`)
 }
 
 /// Temporal action for state transitions
@@ -919,6 +920,7 @@ impl CodeEntity {
             EntityType::Constant => "const",
             EntityType::Table => "table",    // v1.5.6: SQL table
             EntityType::View => "view",      // v1.5.6: SQL view
+            EntityType::Route => "route",    // v1.7.3: HTTP route
         };
 
         // Create hash input: filepath + name + type + timestamp
@@ -1059,6 +1061,34 @@ pub enum EdgeType {
     Uses,
     /// Trait implementation (A implements trait B)
     Implements,
+    /// Type embedding (Go struct/interface A embeds type B)
+    Embeds,
+    /// Goroutine launch (Go `go B()` inside A runs B concurrently)
+    Spawns,
+    /// Channel send (A executes `B <- v`; B is a `go:chan:` node)
+    SendsTo,
+    /// Channel receive (A executes `<-B` or ranges over channel B)
+    ReceivesFrom,
+    /// Field read (A reads struct field B; B is a `go:field:` node)
+    Reads,
+    /// Field write (A assigns, updates or initializes struct field B)
+    Writes,
+    /// Test coverage (test function A exercises production symbol B)
+    Tests,
+    /// Trait bound (a type parameter of Rust item A is bounded by trait B)
+    Bound,
+    /// Error propagation (an error produced by A is returned, possibly wrapped, by B)
+    PropagatesErrorTo,
+    /// Embedded SQL (a statement in A reads or writes table B; B is a `sql:table:` node)
+    QueriesTable,
+    /// Serialized name (struct A encodes a field as wire/column name B; B is a `wire:field:` node)
+    Serializes,
+    /// Generated file lineage (generated file A came from B: the `//go:generate` file or the named source)
+    GeneratedFrom,
+    /// File inclusion (C/C++ or cgo preamble file A `#include`s header B; PHP `include`)
+    Includes,
+    /// Configuration (code A reads env var, flag key, handler or binary name B set in a YAML/JSON config file)
+    ConfiguredBy,
 }
 
 // S77 Pattern A.1: Expression-oriented code
@@ -1069,6 +1099,20 @@ impl EdgeType {
             Self::Calls => "Calls",
             Self::Uses => "Uses",
             Self::Implements => "Implements",
+            Self::Embeds => "Embeds",
+            Self::Spawns => "Spawns",
+            Self::SendsTo => "SendsTo",
+            Self::ReceivesFrom => "ReceivesFrom",
+            Self::Reads => "Reads",
+            Self::Writes => "Writes",
+            Self::Tests => "Tests",
+            Self::Bound => "Bound",
+            Self::PropagatesErrorTo => "PropagatesErrorTo",
+            Self::QueriesTable => "QueriesTable",
+            Self::Serializes => "Serializes",
+            Self::GeneratedFrom => "GeneratedFrom",
+            Self::Includes => "Includes",
+            Self::ConfiguredBy => "ConfiguredBy",
         }
     }
 }
@@ -1088,9 +1132,23 @@ impl std::str::FromStr for EdgeType {
             "Calls" => Ok(Self::Calls),
             "Uses" => Ok(Self::Uses),
             "Implements" => Ok(Self::Implements),
+            "Embeds" => Ok(Self::Embeds),
+            "Spawns" => Ok(Self::Spawns),
+            "SendsTo" => Ok(Self::SendsTo),
+            "ReceivesFrom" => Ok(Self::ReceivesFrom),
+            "Reads" => Ok(Self::Reads),
+            "Writes" => Ok(Self::Writes),
+            "Tests" => Ok(Self::Tests),
+            "Bound" => Ok(Self::Bound),
+            "PropagatesErrorTo" => Ok(Self::PropagatesErrorTo),
+            "QueriesTable" => Ok(Self::QueriesTable),
+            "Serializes" => Ok(Self::Serializes),
+            "GeneratedFrom" => Ok(Self::GeneratedFrom),
+            "Includes" => Ok(Self::Includes),
+            "ConfiguredBy" => Ok(Self::ConfiguredBy),
             _ => Err(ParseltongError::ValidationError {
                 field: "edge_type".to_string(),
-                expected: "Calls, Uses, or Implements".to_string(),
+                expected: "Calls, Uses, Implements, Embeds, Spawns, SendsTo, ReceivesFrom, Reads, Writes, Tests, Bound, PropagatesErrorTo, QueriesTable, Serializes, GeneratedFrom, Includes, or ConfiguredBy".to_string(),
                 actual: s.to_owned(),
             }),
         }
@@ -1133,6 +1191,10 @@ pub struct DependencyEdge {
     pub edge_type: EdgeType,
     /// Source code location where relationship occurs (optional)
     pub source_location: Option<String>,
+    /// Extra facts about the relationship (e.g. `type_arguments` for generic
+    /// instantiations). Sorted map so serialized output is deterministic.
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub metadata: BTreeMap<String, String>,
 }
 
 impl DependencyEdge {
@@ -1148,9 +1210,15 @@ impl DependencyEdge {
             to_key: Isgl1Key::new(to_key)?,
             edge_type,
             source_location,
+            metadata: BTreeMap::new(),
         })
     }
 
+    /// Returns metadata value for key, if recorded on this edge
+    pub fn metadata_value(&self, key: &str) -> Option<&str> {
+        self.metadata.get(key).map(String::as_str)
+    }
+
     /// Returns a builder for constructing dependency edges
     pub fn builder() -> DependencyEdgeBuilder {
         DependencyEdgeBuilder::default()
@@ -1164,6 +1232,7 @@ pub struct DependencyEdgeBuilder {
     to_key: Option<String>,
     edge_type: Option<EdgeType>,
     source_location: Option<String>,
+    metadata: BTreeMap<String, String>,
 }
 
 impl DependencyEdgeBuilder {
@@ -1191,13 +1260,20 @@ impl DependencyEdgeBuilder {
         self
     }
 
+    /// Adds a metadata entry (optional, repeatable)
+    pub fn metadata_entry(mut self, key: impl Into<String>, value: impl Into<String>) -> Self {
+        self.metadata.insert(key.into(), value.into());
+        self
+    }
+
     /// Builds the DependencyEdge
     ///
     /// # Errors
     ///
     /// Returns error if required fields are missing or invalid.
     pub fn build(self) -> Result<DependencyEdge> {
-        DependencyEdge::new(
+        let metadata = self.metadata;
+        let mut edge = DependencyEdge::new(
             self.from_key.ok_or_else(|| ParseltongError::ValidationError {
                 field: "from_key".to_string(),
                 expected: "non-empty string".to_string(),
@@ -1214,7 +1290,9 @@ impl DependencyEdgeBuilder {
                 actual: "None".to_string(),
             })?,
             self.source_location,
-        )
+        )?;
+        edge.metadata = metadata;
+        Ok(edge)
     }
 }
 
@@ -1566,7 +1644,25 @@ mod tests {
         use std::str::FromStr;
 
         // Test all variants
-        for edge_type in [EdgeType::Calls, EdgeType::Uses, EdgeType::Implements] {
+        for edge_type in [
+            EdgeType::Calls,
+            EdgeType::Uses,
+            EdgeType::Implements,
+            EdgeType::Embeds,
+            EdgeType::Spawns,
+            EdgeType::SendsTo,
+            EdgeType::ReceivesFrom,
+            EdgeType::Reads,
+            EdgeType::Writes,
+            EdgeType::Tests,
+            EdgeType::Bound,
+            EdgeType::PropagatesErrorTo,
+            EdgeType::QueriesTable,
+            EdgeType::Serializes,
+            EdgeType::GeneratedFrom,
+            EdgeType::Includes,
+            EdgeType::ConfiguredBy,
+        ] {
             let s = edge_type.as_str();
             let parsed = EdgeType::from_str(s).unwrap();
             assert_eq!(parsed, edge_type);
@@ -1585,6 +1681,7 @@ mod tests {
         assert_eq!(format!("{}", EdgeType::Calls), "Calls");
         assert_eq!(format!("{}", EdgeType::Uses), "Uses");
         assert_eq!(format!("{}", EdgeType::Implements), "Implements");
+        assert_eq!(format!("{}", EdgeType::Embeds), "Embeds");
     }
 
     #[test]
@@ -1615,6 +1712,25 @@ mod tests {
         assert_eq!(edge.source_location, Some("src/main.rs:5".to_string()));
     }
 
+    #[test]
+    fn test_dependency_edge_builder_with_metadata() {
+        let edge = DependencyEdge::builder()
+            .from_key("go:fn:main:__main:T1")
+            .to_key("go:fn:Map:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .metadata_entry("type_arguments", "int, string")
+            .build()
+            .unwrap();
+
+        assert_eq!(edge.metadata_value("type_arguments"), Some("int, string"));
+        assert_eq!(edge.metadata_value("missing"), None);
+
+        // Metadata survives serialization roundtrip
+        let json = serde_json::to_string(&edge).unwrap();
+        let deserialized: DependencyEdge = serde_json::from_str(&json).unwrap();
+        assert_eq!(deserialized, edge);
+    }
+
     #[test]
     fn test_dependency_edge_builder_missing_field() {
         // Missing to_key
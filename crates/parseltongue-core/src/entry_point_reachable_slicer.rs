@@ -0,0 +1,801 @@
+//! Entry Point Reachable Slicer (v1.7.3)
+//!
+//! # 4-Word Naming: entry_point_reachable_slicer
+//!
+//! Where does a feature start, and what does it touch? `parseltongue analyze
+//! entrypoints` lists the places the outside world enters the code, and
+//! `parseltongue slice --from <entrypoint>` extracts everything reachable
+//! from one of them: the natural seed set for a whole-feature context pack.
+//!
+//! ## Entry points
+//!
+//! - `main`: production functions named `main`
+//! - `http`: `Route` nodes (`POST /users`, see `http_route_handler_mapper`)
+//!   and the handlers they call
+//! - `grpc`: rpc nodes (`proto:method:...`, labelled by `rpc_path`) and the
+//!   server methods they call
+//! - `cli`: command registrations. `&cobra.Command{Use: ..., RunE: ...}`,
+//!   urfave `cli.Command{Name: ..., Action: ...}`, commander
+//!   `.command("x")...action(fn)`, click `cli.add_command(fn)`, and
+//!   decorated or derived commands (`@click.command`, `@app.command()`,
+//!   picocli `@Command`, `#[derive(Parser)]` / `Subcommand`)
+//! - `job`: cron and job registrations. `c.AddFunc("@hourly", fn)`,
+//!   `.AddJob`, `cron.schedule(...)`, `new CronJob(...)`,
+//!   `scheduler.add_job(fn, ...)`, and decorators (`@Scheduled`, `@app.task`,
+//!   `@shared_task`, `@sched.scheduled_job`)
+//! - `directive`: symbols marked `companion:entrypoint`
+//!
+//! A registration found in code belongs to the symbol that makes it; the
+//! handler it names is resolved by name (same file first) and becomes part
+//! of the entry point. Inline closures need no resolution: they are inside
+//! the registering symbol already. Decorators are read from the `attributes`
+//! metadata pt01 stores (see `symbol_doc_signature_extractor`).
+//!
+//! ## Slices
+//!
+//! From the entry symbol and its handlers, every outgoing edge except
+//! `Tests` is followed (calls, type uses, spawns, reads, ...), optionally up
+//! to `max_depth` hops. External targets are kept as edge endpoints only.
+
+use std::collections::{BTreeMap, HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType};
+use crate::inline_suppression_directive_parser::{
+    has_inline_companion_directive, InlineCompanionDirectiveKind, DIRECTIVES_METADATA_KEY,
+    DIRECTIVE_REASON_METADATA_KEY,
+};
+use crate::protobuf_service_definition_linker::RPC_PATH_METADATA_KEY;
+use crate::symbol_doc_signature_extractor::ATTRIBUTES_METADATA_KEY;
+
+/// How the outside world reaches an entry point
+///
+/// # 4-Word Name: FeatureEntryPointKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum FeatureEntryPointKind {
+    Main,
+    Http,
+    Grpc,
+    Cli,
+    Job,
+    Directive,
+}
+
+impl FeatureEntryPointKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Main => "main",
+            Self::Http => "http",
+            Self::Grpc => "grpc",
+            Self::Cli => "cli",
+            Self::Job => "job",
+            Self::Directive => "directive",
+        }
+    }
+
+    /// Kind from its `--kind` name
+    pub fn parse_entry_kind_name(value: &str) -> Option<Self> {
+        match value {
+            "main" => Some(Self::Main),
+            "http" => Some(Self::Http),
+            "grpc" => Some(Self::Grpc),
+            "cli" => Some(Self::Cli),
+            "job" => Some(Self::Job),
+            "directive" => Some(Self::Directive),
+            _ => None,
+        }
+    }
+}
+
+/// One place where execution enters the code
+///
+/// # 4-Word Name: FeatureEntryPointEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct FeatureEntryPointEntry {
+    pub kind: FeatureEntryPointKind,
+    /// Route, rpc path, command name, cron spec, or the symbol name
+    pub label: String,
+    /// Symbol that is (or registers) the entry point
+    pub entry_key: String,
+    /// Handlers it hands control to (resolved callees of routes, rpc
+    /// methods, and registrations)
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub handler_keys: Vec<String>,
+    /// `file:line` of the entry symbol
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub location: Option<String>,
+}
+
+impl FeatureEntryPointEntry {
+    /// Entry symbol followed by its handlers (the slice seeds)
+    pub fn seed_keys(&self) -> Vec<String> {
+        let mut keys = vec![self.entry_key.clone()];
+        keys.extend(self.handler_keys.iter().filter(|k| **k != self.entry_key).cloned());
+        keys
+    }
+}
+
+/// One symbol of a slice and its hop distance from the seeds
+///
+/// # 4-Word Name: ReachableSliceMemberEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ReachableSliceMemberEntry {
+    pub key: String,
+    pub depth: usize,
+}
+
+/// Reachable subgraph of a set of seeds
+///
+/// # 4-Word Name: ReachableEntrySliceResult
+#[derive(Debug, Clone)]
+pub struct ReachableEntrySliceResult {
+    /// Every reached key (seeds at depth 0), by depth then key
+    pub members: Vec<ReachableSliceMemberEntry>,
+    /// Entities of the reached keys (externals have none)
+    pub entities: Vec<CodeEntity>,
+    /// Followed edges, in graph order
+    pub edges: Vec<DependencyEdge>,
+}
+
+impl ReachableEntrySliceResult {
+    /// Keep the first `limit` members (nearest first) and what they span (`--max-nodes`)
+    ///
+    /// # 4-Word Name: retain_nearest_slice_members
+    ///
+    /// # Contract
+    /// - Postcondition: entities and edges of dropped members are removed;
+    ///   returns how many members were dropped
+    pub fn retain_nearest_slice_members(&mut self, limit: usize) -> usize {
+        if self.members.len() <= limit {
+            return 0;
+        }
+        let dropped = self.members.len() - limit;
+        self.members.truncate(limit);
+        let kept: HashSet<&str> = self.members.iter().map(|m| m.key.as_str()).collect();
+        self.entities.retain(|e| kept.contains(e.isgl1_key.as_str()));
+        self.edges.retain(|e| kept.contains(e.from_key.as_str()) && kept.contains(e.to_key.as_str()));
+        dropped
+    }
+}
+
+/// How a registration call carries its label and handler
+enum RegistrationSyntaxForm {
+    /// `needle(arg0, arg1, ...)`: label and handler by argument index
+    CallArguments { label: Option<usize>, handler: Option<usize> },
+    /// `needle field: value, ... }`: struct literal fields
+    StructLiteral { label_field: &'static str, handler_fields: &'static [&'static str] },
+    /// commander: `.command("name")` ... `.action(handler)`
+    CommanderChain,
+}
+
+struct RegistrationCallPattern {
+    kind: FeatureEntryPointKind,
+    languages: &'static [&'static str],
+    needle: &'static str,
+    form: RegistrationSyntaxForm,
+}
+
+const REGISTRATION_CALL_PATTERN_LIST: &[RegistrationCallPattern] = {
+    use FeatureEntryPointKind::{Cli, Job};
+    use RegistrationSyntaxForm::{CallArguments, CommanderChain, StructLiteral};
+    &[
+        RegistrationCallPattern {
+            kind: Cli,
+            languages: &["go"],
+            needle: "cobra.Command{",
+            form: StructLiteral { label_field: "Use", handler_fields: &["RunE", "Run"] },
+        },
+        RegistrationCallPattern {
+            kind: Cli,
+            languages: &["go"],
+            needle: "cli.Command{",
+            form: StructLiteral { label_field: "Name", handler_fields: &["Action"] },
+        },
+        RegistrationCallPattern {
+            kind: Cli,
+            languages: &["javascript", "typescript"],
+            needle: ".command(",
+            form: CommanderChain,
+        },
+        RegistrationCallPattern {
+            kind: Cli,
+            languages: &["python"],
+            needle: ".add_command(",
+            form: CallArguments { label: None, handler: Some(0) },
+        },
+        RegistrationCallPattern {
+            kind: Job,
+            languages: &["go"],
+            needle: ".AddFunc(",
+            form: CallArguments { label: Some(0), handler: Some(1) },
+        },
+        RegistrationCallPattern {
+            kind: Job,
+            languages: &["go"],
+            needle: ".AddJob(",
+            form: CallArguments { label: Some(0), handler: Some(1) },
+        },
+        RegistrationCallPattern {
+            kind: Job,
+            languages: &["javascript", "typescript"],
+            needle: "cron.schedule(",
+            form: CallArguments { label: Some(0), handler: Some(1) },
+        },
+        RegistrationCallPattern {
+            kind: Job,
+            languages: &["javascript", "typescript"],
+            needle: "new CronJob(",
+            form: CallArguments { label: Some(0), handler: Some(1) },
+        },
+        RegistrationCallPattern {
+            kind: Job,
+            languages: &["python"],
+            needle: ".add_job(",
+            form: CallArguments { label: None, handler: Some(0) },
+        },
+    ]
+};
+
+/// Find every entry point of the graph
+///
+/// # 4-Word Name: find_feature_entry_points
+///
+/// # Contract
+/// - Postcondition: test code is skipped; one entry per (kind, entry,
+///   label); sorted by kind, then location, then label
+pub fn find_feature_entry_points(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<FeatureEntryPointEntry> {
+    let mut callees: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Calls) {
+        callees.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+    }
+    let mut by_name: HashMap<&str, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities.iter().filter(|e| is_handler_candidate_entity(e)) {
+        by_name.entry(entity.interface_signature.name.as_str()).or_default().push(entity);
+    }
+
+    let mut found: Vec<FeatureEntryPointEntry> = Vec::new();
+    for entity in entities.iter().filter(|e| e.entity_class != EntityClass::TestImplementation) {
+        let signature = &entity.interface_signature;
+        let called = || -> Vec<String> {
+            let keys = callees.get(entity.isgl1_key.as_str()).map(Vec::as_slice).unwrap_or_default();
+            keys.iter().map(|k| k.to_string()).collect()
+        };
+
+        if signature.entity_type == EntityType::Route {
+            found.push(new_entry_point(FeatureEntryPointKind::Http, signature.name.clone(), entity, called()));
+        } else if entity.isgl1_key.starts_with("proto:method:") {
+            let label = entity.metadata.additional.get(RPC_PATH_METADATA_KEY).cloned();
+            let label = label.unwrap_or_else(|| signature.name.clone());
+            found.push(new_entry_point(FeatureEntryPointKind::Grpc, label, entity, called()));
+        } else if signature.name == "main"
+            && matches!(signature.entity_type, EntityType::Function | EntityType::Method)
+        {
+            found.push(new_entry_point(FeatureEntryPointKind::Main, signature.name.clone(), entity, Vec::new()));
+        }
+
+        if has_inline_companion_directive(entity, InlineCompanionDirectiveKind::Entrypoint) {
+            let label = entity.metadata.additional.get(DIRECTIVE_REASON_METADATA_KEY).cloned();
+            let label = label.unwrap_or_else(|| signature.name.clone());
+            found.push(new_entry_point(FeatureEntryPointKind::Directive, label, entity, Vec::new()));
+        }
+        if let Some(attributes) = entity.metadata.additional.get(ATTRIBUTES_METADATA_KEY) {
+            for (kind, label) in classify_entry_point_attributes(attributes) {
+                let label = label.unwrap_or_else(|| signature.name.clone());
+                found.push(new_entry_point(kind, label, entity, Vec::new()));
+            }
+        }
+
+        let language = entity.isgl1_key.split(':').next().unwrap_or_default();
+        if let Some(code) = entity.current_code.as_deref() {
+            for (kind, label, handler) in scan_code_for_registrations(code, language) {
+                let handler_key = handler.as_deref().and_then(|name| resolve_handler_by_name(&by_name, name, entity));
+                let label = label.or(handler).unwrap_or_else(|| signature.name.clone());
+                found.push(new_entry_point(kind, label, entity, handler_key.into_iter().collect()));
+            }
+        }
+    }
+
+    let mut seen: HashSet<(FeatureEntryPointKind, String, String)> = HashSet::new();
+    found.retain(|e| seen.insert((e.kind, e.entry_key.clone(), e.label.clone())));
+    found.sort_by(|a, b| {
+        a.kind
+            .cmp(&b.kind)
+            .then_with(|| a.location.cmp(&b.location))
+            .then_with(|| a.label.cmp(&b.label))
+            .then_with(|| a.entry_key.cmp(&b.entry_key))
+    });
+    found
+}
+
+/// Entry points a `--from` reference names
+///
+/// # 4-Word Name: match_entry_point_reference
+///
+/// # Contract
+/// - Postcondition: an exact entry key wins; otherwise every entry whose
+///   label equals the reference (`POST /users`, `/billing.Billing/Charge`,
+///   `serve`); empty when nothing matches
+pub fn match_entry_point_reference<'a>(
+    entry_points: &'a [FeatureEntryPointEntry],
+    reference: &str,
+) -> Vec<&'a FeatureEntryPointEntry> {
+    let by_key: Vec<&FeatureEntryPointEntry> = entry_points.iter().filter(|e| e.entry_key == reference).collect();
+    if !by_key.is_empty() {
+        return by_key;
+    }
+    let reference = reference.trim();
+    entry_points.iter().filter(|e| e.label == reference).collect()
+}
+
+/// Everything reachable from the seeds over outgoing edges
+///
+/// # 4-Word Name: extract_reachable_entry_slice
+///
+/// # Contract
+/// - Precondition: seeds are entity keys; unknown seeds are kept as members
+/// - Postcondition: breadth-first, every edge type but `Tests`; with
+///   `max_depth`, nothing beyond that many hops; each member at its
+///   shortest distance
+pub fn extract_reachable_entry_slice(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    seed_keys: &[String],
+    max_depth: Option<usize>,
+) -> ReachableEntrySliceResult {
+    let mut outgoing: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type != EdgeType::Tests) {
+        outgoing.entry(edge.from_key.as_str()).or_default().push(edge);
+    }
+
+    let mut depth_of: BTreeMap<&str, usize> = BTreeMap::new();
+    let mut queue: VecDeque<(&str, usize)> = VecDeque::new();
+    for seed in seed_keys {
+        if !depth_of.contains_key(seed.as_str()) {
+            depth_of.insert(seed.as_str(), 0);
+            queue.push_back((seed.as_str(), 0));
+        }
+    }
+    while let Some((node, depth)) = queue.pop_front() {
+        if max_depth.is_some_and(|limit| depth >= limit) {
+            continue;
+        }
+        for &edge in outgoing.get(node).map(Vec::as_slice).unwrap_or_default() {
+            let target = edge.to_key.as_str();
+            if !depth_of.contains_key(target) {
+                depth_of.insert(target, depth + 1);
+                queue.push_back((target, depth + 1));
+            }
+        }
+    }
+
+    let mut members: Vec<ReachableSliceMemberEntry> = depth_of
+        .iter()
+        .map(|(key, depth)| ReachableSliceMemberEntry { key: key.to_string(), depth: *depth })
+        .collect();
+    members.sort_by(|a, b| a.depth.cmp(&b.depth).then_with(|| a.key.cmp(&b.key)));
+
+    let within = |key: &str| depth_of.get(key).is_some_and(|d| max_depth.map_or(true, |limit| *d < limit));
+    ReachableEntrySliceResult {
+        members,
+        entities: entities.iter().filter(|e| depth_of.contains_key(e.isgl1_key.as_str())).cloned().collect(),
+        edges: edges
+            .iter()
+            .filter(|e| {
+                e.edge_type != EdgeType::Tests && within(&e.from_key) && depth_of.contains_key(e.to_key.as_str())
+            })
+            .cloned()
+            .collect(),
+    }
+}
+
+fn new_entry_point(
+    kind: FeatureEntryPointKind,
+    label: String,
+    entity: &CodeEntity,
+    handler_keys: Vec<String>,
+) -> FeatureEntryPointEntry {
+    let signature = &entity.interface_signature;
+    let file = signature.file_path.to_string_lossy();
+    FeatureEntryPointEntry {
+        kind,
+        label,
+        entry_key: entity.isgl1_key.clone(),
+        handler_keys,
+        location: (!file.is_empty()).then(|| format!("{}:{}", file, signature.line_range.start)),
+    }
+}
+
+fn is_handler_candidate_entity(entity: &CodeEntity) -> bool {
+    matches!(
+        entity.interface_signature.entity_type,
+        EntityType::Function | EntityType::Method | EntityType::Struct | EntityType::Class
+    ) && entity.entity_class != EntityClass::TestImplementation
+}
+
+/// Handler named in a registration: same file first, else the only match
+fn resolve_handler_by_name(
+    by_name: &HashMap<&str, Vec<&CodeEntity>>,
+    name: &str,
+    registrar: &CodeEntity,
+) -> Option<String> {
+    let candidates = by_name.get(name)?;
+    let file = &registrar.interface_signature.file_path;
+    let mut same_file: Vec<&&CodeEntity> =
+        candidates.iter().filter(|e| &e.interface_signature.file_path == file).collect();
+    same_file.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    match (same_file.first(), candidates.as_slice()) {
+        (Some(entity), _) => Some(entity.isgl1_key.clone()),
+        (None, [only]) => Some(only.isgl1_key.clone()),
+        _ => None,
+    }
+}
+
+/// Entry kinds declared by decorators / attributes, with an explicit label
+fn classify_entry_point_attributes(attributes: &str) -> Vec<(FeatureEntryPointKind, Option<String>)> {
+    let mut found = Vec::new();
+    // pest grammars derive `Parser` too
+    let is_grammar = attributes.contains("#[grammar");
+    for line in attributes.lines().map(str::trim) {
+        if let Some(inner) = line.strip_prefix("#[derive(") {
+            let derives_command = inner
+                .split(|c: char| !c.is_alphanumeric() && c != '_')
+                .any(|word| matches!(word, "Parser" | "Subcommand"));
+            if derives_command && !is_grammar {
+                found.push((FeatureEntryPointKind::Cli, None));
+            }
+            continue;
+        }
+        let Some(body) = line.strip_prefix('@') else {
+            continue;
+        };
+        let (name, arguments) = match body.find('(') {
+            Some(open) => (&body[..open], &body[open + 1..]),
+            None => (body, ""),
+        };
+        let last = name.rsplit('.').next().unwrap_or(name);
+        let is_cli = matches!(name, "Command" | "ShellMethod")
+            || (name.contains('.') && matches!(last, "command" | "group"));
+        let is_job = matches!(last, "Scheduled" | "shared_task" | "periodic_task" | "scheduled_job")
+            || (name.contains('.') && last == "task");
+        let kind = match (is_cli, is_job) {
+            (true, _) => FeatureEntryPointKind::Cli,
+            (_, true) => FeatureEntryPointKind::Job,
+            _ => continue,
+        };
+        found.push((kind, attribute_label_argument(arguments)));
+    }
+    found
+}
+
+/// `name = "x"` / `cron = "x"` argument, else the first string argument
+fn attribute_label_argument(arguments: &str) -> Option<String> {
+    for field in ["name", "cron", "value"] {
+        let mut search_from = 0;
+        while let Some(relative) = arguments[search_from..].find(field) {
+            let at = search_from + relative;
+            search_from = at + field.len();
+            let boundary = arguments[..at].chars().next_back().map_or(true, |c| !c.is_alphanumeric() && c != '_');
+            let rest = arguments[search_from..].trim_start();
+            if boundary && rest.starts_with('=') {
+                if let Some(text) = leading_string_literal(rest[1..].trim_start()) {
+                    return Some(text);
+                }
+            }
+        }
+    }
+    leading_string_literal(arguments.trim_start())
+}
+
+/// Registrations in one body: (kind, label, handler name)
+fn scan_code_for_registrations(
+    code: &str,
+    language: &str,
+) -> Vec<(FeatureEntryPointKind, Option<String>, Option<String>)> {
+    let mut found = Vec::new();
+    for pattern in REGISTRATION_CALL_PATTERN_LIST.iter().filter(|p| p.languages.contains(&language)) {
+        for offset in find_registration_offsets(code, pattern.needle) {
+            let open = offset + pattern.needle.len() - 1;
+            let body = bracketed_text_after(code, open);
+            let (label, handler) = match &pattern.form {
+                RegistrationSyntaxForm::CallArguments { label, handler } => {
+                    let arguments = split_top_level_arguments(body);
+                    let label = label.and_then(|i| arguments.get(i)).and_then(|a| leading_string_literal(a));
+                    let handler = handler.and_then(|i| arguments.get(i)).and_then(|a| handler_identifier_of(a));
+                    (label, handler)
+                }
+                RegistrationSyntaxForm::StructLiteral { label_field, handler_fields } => {
+                    let label = struct_field_value(body, label_field).and_then(leading_string_literal);
+                    // `Use: "serve [addr]"`: the command is the first word
+                    let label = label.map(|usage| usage.split_whitespace().next().unwrap_or_default().to_string());
+                    let handler = handler_fields
+                        .iter()
+                        .find_map(|field| struct_field_value(body, field))
+                        .and_then(handler_identifier_of);
+                    (label.filter(|l| !l.is_empty()), handler)
+                }
+                RegistrationSyntaxForm::CommanderChain => {
+                    let label = leading_string_literal(body.trim_start())
+                        .map(|spec| spec.split_whitespace().next().unwrap_or_default().to_string());
+                    let rest = &code[open..];
+                    let chain_end = rest[1..].find(pattern.needle).map(|i| i + 1).unwrap_or(rest.len());
+                    let handler = rest[..chain_end]
+                        .find(".action(")
+                        .map(|at| bracketed_text_after(rest, at + ".action(".len() - 1))
+                        .and_then(|arguments| {
+                            split_top_level_arguments(arguments).first().and_then(|a| handler_identifier_of(a))
+                        });
+                    (label.filter(|l| !l.is_empty()), handler)
+                }
+            };
+            found.push((pattern.kind, label, handler));
+        }
+    }
+    found
+}
+
+/// Offsets of `needle` outside comment lines
+fn find_registration_offsets(code: &str, needle: &str) -> Vec<usize> {
+    let mut offsets = Vec::new();
+    let mut search_from = 0;
+    while let Some(relative) = code[search_from..].find(needle) {
+        let offset = search_from + relative;
+        search_from = offset + needle.len();
+
+        let before = &code[..offset];
+        if !needle.starts_with('.') && before.chars().next_back().is_some_and(|c| c.is_alphanumeric() || c == '_') {
+            continue;
+        }
+        let line_start = before.rfind('\n').map(|i| i + 1).unwrap_or(0);
+        let line_prefix = code[line_start..offset].trim_start();
+        if !["//", "#", "*", "/*"].iter().any(|marker| line_prefix.starts_with(marker)) {
+            offsets.push(offset);
+        }
+    }
+    offsets
+}
+
+/// Text inside the bracket opened at `open_index`, strings respected
+fn bracketed_text_after(code: &str, open_index: usize) -> &str {
+    let body = &code[open_index + 1..];
+    let mut depth = 0usize;
+    let mut quote: Option<char> = None;
+    let mut escaped = false;
+    for (index, c) in body.char_indices() {
+        if let Some(q) = quote {
+            if escaped {
+                escaped = false;
+            } else if c == '\\' {
+                escaped = true;
+            } else if c == q {
+                quote = None;
+            }
+            continue;
+        }
+        match c {
+            '"' | '\'' | '`' => quote = Some(c),
+            '(' | '[' | '{' => depth += 1,
+            ')' | ']' | '}' if depth == 0 => return &body[..index],
+            ')' | ']' | '}' => depth -= 1,
+            _ => {}
+        }
+    }
+    body
+}
+
+/// Comma-separated arguments at nesting depth zero
+fn split_top_level_arguments(text: &str) -> Vec<&str> {
+    let mut arguments = Vec::new();
+    let mut depth = 0usize;
+    let mut quote: Option<char> = None;
+    let mut escaped = false;
+    let mut start = 0;
+    for (index, c) in text.char_indices() {
+        if let Some(q) = quote {
+            if escaped {
+                escaped = false;
+            } else if c == '\\' {
+                escaped = true;
+            } else if c == q {
+                quote = None;
+            }
+            continue;
+        }
+        match c {
+            '"' | '\'' | '`' => quote = Some(c),
+            '(' | '[' | '{' => depth += 1,
+            ')' | ']' | '}' => depth = depth.saturating_sub(1),
+            ',' if depth == 0 => {
+                arguments.push(text[start..index].trim());
+                start = index + 1;
+            }
+            _ => {}
+        }
+    }
+    let last = text[start..].trim();
+    if !last.is_empty() {
+        arguments.push(last);
+    }
+    arguments
+}
+
+/// Value text after `field:` at the top level of a struct literal body
+fn struct_field_value<'a>(body: &'a str, field: &str) -> Option<&'a str> {
+    split_top_level_arguments(body).into_iter().find_map(|entry| {
+        let (name, value) = entry.split_once(':')?;
+        (name.trim() == field).then(|| value.trim())
+    })
+}
+
+/// Contents of the string literal `text` starts with
+fn leading_string_literal(text: &str) -> Option<String> {
+    let quote = text.chars().next().filter(|c| matches!(c, '"' | '\'' | '`'))?;
+    let mut value = String::new();
+    let mut escaped = false;
+    for c in text[1..].chars() {
+        if escaped {
+            value.push(c);
+            escaped = false;
+        } else if c == '\\' {
+            escaped = true;
+        } else if c == quote {
+            return Some(value);
+        } else {
+            value.push(c);
+        }
+    }
+    None
+}
+
+/// Last path segment of a handler expression, None for inline closures
+fn handler_identifier_of(expression: &str) -> Option<String> {
+    let expression = expression.trim().trim_start_matches('&');
+    let path: String = expression
+        .chars()
+        .take_while(|c| c.is_alphanumeric() || matches!(*c, '_' | '.' | ':' | '$'))
+        .collect();
+    let inline = matches!(path.as_str(), "func" | "function" | "lambda" | "async" | "")
+        || expression.contains("=>");
+    if inline {
+        return None;
+    }
+    let name = path.rsplit(|c| c == '.' || c == ':').next().unwrap_or_default();
+    name.chars().next().filter(|c| c.is_alphabetic() || *c == '_').map(|_| name.to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type).build().unwrap()
+    }
+
+    fn with_code(mut entity: CodeEntity, code: &str) -> CodeEntity {
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    #[test]
+    fn test_entry_points_of_each_kind() {
+        let main = create_go_test_entity("go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[]);
+        let route = create_go_test_entity("go:route:POST_/users:__api:T2", "POST /users", EntityType::Route, "api/routes.go", &[]);
+        let create_user = create_go_test_entity("go:fn:createUser:__api:T3", "createUser", EntityType::Function, "api/users.go", &[]);
+        let rpc = create_go_test_entity(
+            "proto:method:Billing.Charge:__billing:T4",
+            "Charge",
+            EntityType::Method,
+            "billing.proto",
+            &[(RPC_PATH_METADATA_KEY, "/billing.Billing/Charge")],
+        );
+        let commands = with_code(
+            create_go_test_entity("go:fn:newServeCmd:__cmd:T5", "newServeCmd", EntityType::Function, "cmd/serve.go", &[]),
+            concat!(
+                "func newServeCmd() *cobra.Command {\n",
+                "    return &cobra.Command{\n",
+                "        Use:   \"serve [addr]\",\n",
+                "        Short: \"Run the server, {fast}\",\n",
+                "        RunE:  runServe,\n",
+                "    }\n",
+                "}",
+            ),
+        );
+        let run_serve = create_go_test_entity("go:fn:runServe:__cmd:T6", "runServe", EntityType::Function, "cmd/serve.go", &[]);
+        let scheduler = with_code(
+            create_go_test_entity("go:fn:startJobs:__jobs:T7", "startJobs", EntityType::Function, "jobs/jobs.go", &[]),
+            concat!(
+                "func startJobs(c *cron.Cron) {\n",
+                "    // c.AddFunc(\"@daily\", disabled)\n",
+                "    c.AddFunc(\"@hourly\", jobs.PurgeSessions)\n",
+                "    c.AddFunc(\"0 3 * * *\", func() { vacuum() })\n",
+                "}",
+            ),
+        );
+        let purge = create_go_test_entity("go:fn:PurgeSessions:__jobs:T8", "PurgeSessions", EntityType::Function, "jobs/purge.go", &[]);
+        let worker = create_go_test_entity(
+            "python:fn:send_digest:__tasks:T9",
+            "send_digest",
+            EntityType::Function,
+            "tasks.py",
+            &[(ATTRIBUTES_METADATA_KEY, "@app.task(name=\"digest\")")],
+        );
+        let webhook = create_go_test_entity(
+            "go:fn:HandleWebhook:__api:T10",
+            "HandleWebhook",
+            EntityType::Function,
+            "api/hook.go",
+            &[(DIRECTIVES_METADATA_KEY, "entrypoint"), (DIRECTIVE_REASON_METADATA_KEY, "registered by the router")],
+        );
+        let entities = vec![main, route, create_user, rpc, commands, run_serve, scheduler, purge, worker, webhook];
+        let edges = vec![
+            edge("go:route:POST_/users:__api:T2", "go:fn:createUser:__api:T3", EdgeType::Calls),
+            edge("proto:method:Billing.Charge:__billing:T4", "go:fn:Charge:__billing:T11", EdgeType::Calls),
+        ];
+
+        let found = find_feature_entry_points(&entities, &edges);
+        let summary: Vec<(&str, &str, Vec<&str>)> = found
+            .iter()
+            .map(|e| (e.kind.as_str(), e.label.as_str(), e.handler_keys.iter().map(String::as_str).collect()))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("main", "main", vec![]),
+                ("http", "POST /users", vec!["go:fn:createUser:__api:T3"]),
+                ("grpc", "/billing.Billing/Charge", vec!["go:fn:Charge:__billing:T11"]),
+                ("cli", "serve", vec!["go:fn:runServe:__cmd:T6"]),
+                ("job", "0 3 * * *", vec![]),
+                ("job", "@hourly", vec!["go:fn:PurgeSessions:__jobs:T8"]),
+                ("job", "digest", vec![]),
+                ("directive", "registered by the router", vec![]),
+            ]
+        );
+
+        let matched = match_entry_point_reference(&found, "POST /users");
+        assert_eq!(matched.len(), 1);
+        assert_eq!(matched[0].seed_keys(), vec!["go:route:POST_/users:__api:T2", "go:fn:createUser:__api:T3"]);
+        assert!(match_entry_point_reference(&found, "GET /nothing").is_empty());
+    }
+
+    #[test]
+    fn test_slice_follows_outgoing_edges_with_depth() {
+        let keys = ["go:route:GET_/a:__api:T1", "go:fn:handle:__api:T2", "go:fn:load:__db:T3", "go:struct:Row:__db:T4"];
+        let entities: Vec<CodeEntity> = keys
+            .iter()
+            .map(|k| create_go_test_entity(k, k.split(':').nth(2).unwrap(), EntityType::Function, "x.go", &[]))
+            .collect();
+        let edges = vec![
+            edge(keys[0], keys[1], EdgeType::Calls),
+            edge(keys[1], keys[2], EdgeType::Calls),
+            edge(keys[2], keys[3], EdgeType::Uses),
+            edge(keys[2], "go:fn:database/sql.Query:unresolved-reference:0-0", EdgeType::Calls),
+            edge("go:fn:TestHandle:__api:T5", keys[1], EdgeType::Tests),
+        ];
+
+        let full = extract_reachable_entry_slice(&entities, &edges, &[keys[0].to_string()], None);
+        let depths: Vec<(&str, usize)> = full.members.iter().map(|m| (m.key.as_str(), m.depth)).collect();
+        assert_eq!(
+            depths,
+            vec![
+                (keys[0], 0),
+                (keys[1], 1),
+                (keys[2], 2),
+                ("go:fn:database/sql.Query:unresolved-reference:0-0", 3),
+                (keys[3], 3),
+            ]
+        );
+        assert_eq!(full.entities.len(), 4);
+        assert_eq!(full.edges.len(), 4);
+
+        let shallow = extract_reachable_entry_slice(&entities, &edges, &[keys[0].to_string()], Some(1));
+        assert_eq!(shallow.members.len(), 2);
+        assert_eq!(shallow.edges.len(), 1);
+
+        let mut bounded = full.clone();
+        assert_eq!(bounded.retain_nearest_slice_members(3), 2);
+        assert_eq!(bounded.members.last().map(|m| m.key.as_str()), Some(keys[2]));
+        assert_eq!((bounded.entities.len(), bounded.edges.len()), (3, 2));
+    }
+}
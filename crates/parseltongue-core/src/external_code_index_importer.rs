@@ -0,0 +1,804 @@
+//! External code index importer: SCIP and LSIF (v1.7.3)
+//!
+//! # 4-Word Naming: external_code_index_importer
+//!
+//! Teams that already run `scip-go`, `rust-analyzer scip`, `scip-typescript`
+//! or an LSIF indexer in CI can load that index instead of re-parsing.
+//!
+//! ## Pipeline
+//!
+//! 1. Decode the index into [`CodeIndexImportModel`]: documents, symbol
+//!    occurrences (definition or reference) and per-symbol information.
+//!    SCIP is decoded here (protobuf, see `protobuf_wire_format_codec`);
+//!    LSIF in `lsif_dump_graph_decoder`.
+//! 2. [`build_graph_from_index_model`] turns definitions into entities
+//!    (ISGL1 v2 keys, same as a tree-sitter ingest) and references into
+//!    `Calls` (target is a function/method) or `Uses` edges from the
+//!    enclosing definition. SCIP `is_implementation` relationships become
+//!    `Implements` edges.
+//!
+//! ## Limitations
+//!
+//! - The enclosing entity of a reference comes from `enclosing_range` when
+//!   the indexer emits it; otherwise the nearest preceding function/method
+//!   definition in the same document is used
+//! - Local symbols (`local N`), parameters and fields are not entities
+
+use std::collections::{HashMap, HashSet};
+use std::path::{Path, PathBuf};
+
+use crate::entities::{
+    CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType, InterfaceSignature, Language,
+    LanguageSpecificSignature, LineRange, RustSignature, Visibility,
+};
+use crate::error::{ParseltongError, Result};
+use crate::isgl1_v2::{
+    compute_birth_timestamp, compute_content_hash, extract_semantic_path, format_key_v2,
+    sanitize_entity_name_for_isgl1,
+};
+use crate::protobuf_wire_format_codec::{decode_packed_int32_list, ProtobufFieldReader};
+
+/// Metadata key recording which index produced an entity or edge
+pub const IMPORTED_FROM_METADATA_KEY: &str = "imported_from";
+
+/// One source file of the index
+///
+/// # 4-Word Name: IndexedDocumentRecord
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct IndexedDocumentRecord {
+    /// Path relative to the project root
+    pub relative_path: String,
+    /// Lowercase language name (`go`, `rust`); empty = from extension
+    pub language: String,
+    /// Embedded source text (SCIP `Document.text`), if any
+    pub text: Option<String>,
+}
+
+/// One occurrence of a symbol in a document
+///
+/// # 4-Word Name: IndexedOccurrenceRecord
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct IndexedOccurrenceRecord {
+    pub document_index: usize,
+    pub symbol: String,
+    /// 0-based line of the symbol name
+    pub line: u32,
+    pub start_character: u32,
+    pub end_character: u32,
+    pub is_definition: bool,
+    /// 0-based last line of the enclosing definition (definitions only)
+    pub enclosing_end_line: Option<u32>,
+}
+
+/// Per-symbol information
+///
+/// # 4-Word Name: IndexedSymbolRecord
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct IndexedSymbolRecord {
+    pub display_name: Option<String>,
+    pub kind: Option<EntityType>,
+    /// Symbols this one implements
+    pub implements: Vec<String>,
+    /// Package the symbol belongs to (external dependency name)
+    pub package_name: Option<String>,
+    pub documentation: Option<String>,
+    pub is_local: bool,
+}
+
+/// Format-neutral decoded index
+///
+/// # 4-Word Name: CodeIndexImportModel
+#[derive(Debug, Clone, Default)]
+pub struct CodeIndexImportModel {
+    /// `scip` or `lsif`
+    pub format_name: String,
+    pub project_root: Option<PathBuf>,
+    pub documents: Vec<IndexedDocumentRecord>,
+    pub occurrences: Vec<IndexedOccurrenceRecord>,
+    pub symbols: HashMap<String, IndexedSymbolRecord>,
+}
+
+/// Entities and edges produced from an index
+///
+/// # 4-Word Name: ImportedCodeGraphResult
+#[derive(Debug, Clone, Default)]
+pub struct ImportedCodeGraphResult {
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    /// References with no enclosing definition (top-level code, imports)
+    pub unattributed_references: usize,
+}
+
+/// Read and convert a SCIP or LSIF index file
+///
+/// # 4-Word Name: import_code_index_file
+///
+/// # Contract
+/// - Format is detected from content: LSIF is JSON (`{` or `[` first),
+///   anything else is decoded as SCIP protobuf
+/// - `source_root` overrides the index's project root for reading source
+///   text (entity bodies); missing files only leave `current_code` empty
+pub fn import_code_index_file(index_path: &Path, source_root: Option<&Path>) -> Result<ImportedCodeGraphResult> {
+    let bytes = std::fs::read(index_path).map_err(|e| ParseltongError::FileSystemError {
+        path: index_path.to_path_buf(),
+        source: e,
+    })?;
+    let first_byte = bytes.iter().find(|b| !b.is_ascii_whitespace()).copied();
+    let mut model = if matches!(first_byte, Some(b'{') | Some(b'[')) {
+        crate::lsif_dump_graph_decoder::decode_lsif_dump_model(&bytes)?
+    } else {
+        decode_scip_index_model(&bytes)?
+    };
+    if let Some(root) = source_root {
+        model.project_root = Some(root.to_path_buf());
+    }
+    Ok(build_graph_from_index_model(&model))
+}
+
+/// Decode a SCIP `Index` message
+///
+/// # 4-Word Name: decode_scip_index_model
+///
+/// Field numbers follow scip.proto: Index{metadata=1, documents=2,
+/// external_symbols=3}, Document{relative_path=1, occurrences=2, symbols=3,
+/// language=4, text=5}, Occurrence{range=1, symbol=2, symbol_roles=3,
+/// enclosing_range=7}, SymbolInformation{symbol=1, documentation=3,
+/// relationships=4, kind=5, display_name=6}.
+pub fn decode_scip_index_model(bytes: &[u8]) -> Result<CodeIndexImportModel> {
+    let mut model = CodeIndexImportModel { format_name: "scip".to_string(), ..Default::default() };
+
+    for field in ProtobufFieldReader::new(bytes) {
+        let (number, value) = field?;
+        let Some(message) = value.as_bytes() else { continue };
+        match number {
+            1 => {
+                // Metadata.project_root = 3 ("file:///abs/path")
+                for meta_field in ProtobufFieldReader::new(message) {
+                    let (meta_number, meta_value) = meta_field?;
+                    if meta_number == 3 {
+                        model.project_root = meta_value.as_string_lossy().map(|uri| path_from_file_uri(&uri));
+                    }
+                }
+            }
+            2 => decode_scip_document_into(message, &mut model)?,
+            3 => {
+                let (symbol, info) = decode_scip_symbol_information(message)?;
+                model.symbols.entry(symbol).or_insert(info);
+            }
+            _ => {}
+        }
+    }
+    Ok(model)
+}
+
+fn decode_scip_document_into(message: &[u8], model: &mut CodeIndexImportModel) -> Result<()> {
+    let document_index = model.documents.len();
+    let mut document = IndexedDocumentRecord::default();
+    let mut occurrences = Vec::new();
+
+    for field in ProtobufFieldReader::new(message) {
+        let (number, value) = field?;
+        match number {
+            1 => document.relative_path = value.as_string_lossy().unwrap_or_default(),
+            2 => {
+                if let Some(bytes) = value.as_bytes() {
+                    if let Some(occurrence) = decode_scip_occurrence_record(bytes, document_index)? {
+                        occurrences.push(occurrence);
+                    }
+                }
+            }
+            3 => {
+                if let Some(bytes) = value.as_bytes() {
+                    let (symbol, info) = decode_scip_symbol_information(bytes)?;
+                    model.symbols.insert(symbol, info);
+                }
+            }
+            4 => document.language = value.as_string_lossy().unwrap_or_default().to_lowercase(),
+            5 => document.text = value.as_string_lossy().filter(|t| !t.is_empty()),
+            _ => {}
+        }
+    }
+
+    model.documents.push(document);
+    model.occurrences.extend(occurrences);
+    Ok(())
+}
+
+fn decode_scip_occurrence_record(message: &[u8], document_index: usize) -> Result<Option<IndexedOccurrenceRecord>> {
+    let mut occurrence = IndexedOccurrenceRecord { document_index, ..Default::default() };
+    let mut range = Vec::new();
+    let mut enclosing = Vec::new();
+
+    for field in ProtobufFieldReader::new(message) {
+        let (number, value) = field?;
+        match number {
+            1 => range = value.as_bytes().map(decode_packed_int32_list).transpose()?.unwrap_or_default(),
+            2 => occurrence.symbol = value.as_string_lossy().unwrap_or_default(),
+            // SymbolRole.Definition = 0x1
+            3 => occurrence.is_definition = value.as_varint().unwrap_or(0) & 0x1 != 0,
+            7 => enclosing = value.as_bytes().map(decode_packed_int32_list).transpose()?.unwrap_or_default(),
+            _ => {}
+        }
+    }
+
+    // [startLine, startChar, endChar] or [startLine, startChar, endLine, endChar]
+    if range.len() < 3 || occurrence.symbol.is_empty() {
+        return Ok(None);
+    }
+    occurrence.line = range[0].max(0) as u32;
+    occurrence.start_character = range[1].max(0) as u32;
+    occurrence.end_character = range[range.len() - 1].max(0) as u32;
+    if enclosing.len() == 4 {
+        occurrence.enclosing_end_line = Some(enclosing[2].max(0) as u32);
+    } else if enclosing.len() == 3 {
+        occurrence.enclosing_end_line = Some(enclosing[0].max(0) as u32);
+    }
+    Ok(Some(occurrence))
+}
+
+fn decode_scip_symbol_information(message: &[u8]) -> Result<(String, IndexedSymbolRecord)> {
+    let mut symbol = String::new();
+    let mut info = IndexedSymbolRecord::default();
+
+    for field in ProtobufFieldReader::new(message) {
+        let (number, value) = field?;
+        match number {
+            1 => symbol = value.as_string_lossy().unwrap_or_default(),
+            3 => {
+                let doc = value.as_string_lossy().unwrap_or_default();
+                info.documentation = Some(match info.documentation.take() {
+                    Some(existing) => format!("{}\n{}", existing, doc),
+                    None => doc,
+                });
+            }
+            4 => {
+                // Relationship{symbol=1, is_implementation=3}
+                let mut related = String::new();
+                let mut is_implementation = false;
+                for rel_field in ProtobufFieldReader::new(value.as_bytes().unwrap_or_default()) {
+                    let (rel_number, rel_value) = rel_field?;
+                    match rel_number {
+                        1 => related = rel_value.as_string_lossy().unwrap_or_default(),
+                        3 => is_implementation = rel_value.as_varint().unwrap_or(0) != 0,
+                        _ => {}
+                    }
+                }
+                if is_implementation && !related.is_empty() {
+                    info.implements.push(related);
+                }
+            }
+            5 => info.kind = value.as_varint().and_then(entity_type_from_scip_kind),
+            6 => info.display_name = value.as_string_lossy().filter(|n| !n.is_empty()),
+            _ => {}
+        }
+    }
+
+    let parsed = parse_scip_symbol_string(&symbol);
+    info.is_local = parsed.is_none();
+    if let Some(parsed) = parsed {
+        info.package_name = Some(parsed.package_name).filter(|p| !p.is_empty() && p != ".");
+    }
+    Ok((symbol, info))
+}
+
+/// SymbolInformation.Kind values that map onto entity types
+fn entity_type_from_scip_kind(kind: u64) -> Option<EntityType> {
+    match kind {
+        7 => Some(EntityType::Class),
+        8 => Some(EntityType::Constant),
+        11 => Some(EntityType::Enum),
+        17 => Some(EntityType::Function),
+        21 => Some(EntityType::Interface),
+        25 => Some(EntityType::Macro),
+        26 => Some(EntityType::Method),
+        29 => Some(EntityType::Module),
+        49 => Some(EntityType::Struct),
+        53 => Some(EntityType::Trait),
+        61 => Some(EntityType::Variable),
+        _ => None,
+    }
+}
+
+/// Parsed global SCIP symbol
+///
+/// # 4-Word Name: ParsedScipSymbolParts
+#[derive(Debug, Clone, PartialEq)]
+pub struct ParsedScipSymbolParts {
+    pub package_name: String,
+    /// Last descriptor name (`Query` in `.../DB#Query().`)
+    pub name: String,
+    /// None for descriptors that are never entities (parameters, fields, ...)
+    pub kind: Option<EntityType>,
+}
+
+/// Parse `<scheme> <manager> <package> <version> <descriptors>`
+///
+/// # 4-Word Name: parse_scip_symbol_string
+///
+/// # Contract
+/// - `local N` symbols → None
+/// - Descriptor suffixes: `/` namespace, `#` type, `.` term, `().` method,
+///   `!` macro, `:` meta, `[..]` type parameter, `(..)` parameter
+pub fn parse_scip_symbol_string(symbol: &str) -> Option<ParsedScipSymbolParts> {
+    if symbol.starts_with("local ") {
+        return None;
+    }
+    let mut parts = symbol.splitn(5, ' ');
+    let _scheme = parts.next()?;
+    let _manager = parts.next()?;
+    let package_name = parts.next()?.to_string();
+    let _version = parts.next()?;
+    let descriptors = parts.next()?;
+
+    // (name, suffix) pairs
+    let mut parsed: Vec<(String, char)> = Vec::new();
+    let chars: Vec<char> = descriptors.chars().collect();
+    let mut i = 0;
+    while i < chars.len() {
+        let mut name = String::new();
+        if chars[i] == '`' {
+            i += 1;
+            while i < chars.len() && chars[i] != '`' {
+                name.push(chars[i]);
+                i += 1;
+            }
+            i += 1;
+        } else if chars[i] == '(' || chars[i] == '[' {
+            // Parameter / type parameter: name inside the brackets
+            let close = if chars[i] == '(' { ')' } else { ']' };
+            let suffix = chars[i];
+            i += 1;
+            while i < chars.len() && chars[i] != close {
+                name.push(chars[i]);
+                i += 1;
+            }
+            i += 1;
+            parsed.push((name, suffix));
+            continue;
+        } else {
+            while i < chars.len() && !"/#.:!([".contains(chars[i]) {
+                name.push(chars[i]);
+                i += 1;
+            }
+        }
+        let Some(&suffix) = chars.get(i) else { break };
+        if suffix == '(' {
+            // Method disambiguator `(+1).`
+            while i < chars.len() && chars[i] != ')' {
+                i += 1;
+            }
+            i += 2;
+            parsed.push((name, 'm'));
+        } else if suffix == '[' {
+            parsed.push((name, '#'));
+        } else {
+            i += 1;
+            parsed.push((name, suffix));
+        }
+    }
+
+    let (name, suffix) = parsed.last()?.clone();
+    let parent_suffix = parsed.len().checked_sub(2).map(|idx| parsed[idx].1);
+    let kind = match suffix {
+        'm' if parent_suffix == Some('#') => Some(EntityType::Method),
+        'm' => Some(EntityType::Function),
+        '#' => Some(EntityType::Struct),
+        // Terms inside types are fields
+        '.' if parent_suffix == Some('#') => None,
+        '.' => Some(EntityType::Variable),
+        '!' => Some(EntityType::Macro),
+        _ => None,
+    };
+    Some(ParsedScipSymbolParts { package_name, name, kind })
+}
+
+/// Resolved definition of one symbol
+struct DefinitionSiteEntry {
+    key: String,
+    kind: EntityType,
+    document_index: usize,
+    start_line: u32,
+    end_line: Option<u32>,
+}
+
+/// Turn a decoded index into entities and edges
+///
+/// # 4-Word Name: build_graph_from_index_model
+///
+/// # Contract
+/// - One entity per non-local symbol definition (first definition wins)
+/// - Edges deduplicated, self-edges dropped; targets without a definition
+///   become `external-dependency-{package}` or `unresolved-reference` keys,
+///   matching pt01's placeholder conventions
+pub fn build_graph_from_index_model(model: &CodeIndexImportModel) -> ImportedCodeGraphResult {
+    let document_lines: Vec<Option<Vec<String>>> = model
+        .documents
+        .iter()
+        .map(|doc| {
+            doc.text
+                .clone()
+                .or_else(|| {
+                    let root = model.project_root.as_deref()?;
+                    std::fs::read_to_string(root.join(&doc.relative_path)).ok()
+                })
+                .map(|text| text.lines().map(str::to_string).collect())
+        })
+        .collect();
+    let occurrence_text = |occurrence: &IndexedOccurrenceRecord| -> Option<String> {
+        let line = document_lines.get(occurrence.document_index)?.as_ref()?.get(occurrence.line as usize)?;
+        let chars: Vec<char> = line.chars().collect();
+        let (start, end) = (occurrence.start_character as usize, occurrence.end_character as usize);
+        (start < end && end <= chars.len()).then(|| chars[start..end].iter().collect())
+    };
+
+    let mut result = ImportedCodeGraphResult::default();
+    let mut definitions: HashMap<&str, DefinitionSiteEntry> = HashMap::new();
+
+    for occurrence in model.occurrences.iter().filter(|o| o.is_definition) {
+        if definitions.contains_key(occurrence.symbol.as_str()) {
+            continue;
+        }
+        let info = model.symbols.get(&occurrence.symbol).cloned().unwrap_or_default();
+        let parsed = parse_scip_symbol_string(&occurrence.symbol);
+        if info.is_local || (model.format_name == "scip" && parsed.is_none()) {
+            continue;
+        }
+        let source_line = document_lines
+            .get(occurrence.document_index)
+            .and_then(|lines| lines.as_ref()?.get(occurrence.line as usize).cloned())
+            .unwrap_or_default();
+        let kind = info
+            .kind
+            .clone()
+            .or_else(|| parsed.as_ref().and_then(|p| p.kind.clone()))
+            .or_else(|| (model.format_name != "scip").then(|| infer_entity_type_from_line(&source_line)).flatten());
+        let Some(kind) = kind else { continue };
+        let name = info
+            .display_name
+            .clone()
+            .or_else(|| parsed.as_ref().map(|p| p.name.clone()).filter(|n| !n.is_empty()))
+            .or_else(|| occurrence_text(occurrence));
+        let Some(name) = name else { continue };
+
+        let document = &model.documents[occurrence.document_index];
+        let language = document_language_name(document);
+        let safe_name = sanitize_entity_name_for_isgl1(&name);
+        let key = format_key_v2(
+            kind.clone(),
+            &safe_name,
+            &language,
+            &extract_semantic_path(&document.relative_path),
+            compute_birth_timestamp(&document.relative_path, &name),
+        );
+
+        let start_line = occurrence.line + 1;
+        let end_line = occurrence.enclosing_end_line.map(|l| l + 1).unwrap_or(start_line).max(start_line);
+        let body = document_lines[occurrence.document_index].as_ref().map(|lines| {
+            lines
+                .iter()
+                .skip(occurrence.line as usize)
+                .take((end_line - start_line + 1) as usize)
+                .cloned()
+                .collect::<Vec<_>>()
+                .join("\n")
+        });
+        if let Some(entity) = create_imported_code_entity(
+            &key, &name, kind.clone(), document, start_line, end_line, body, &info, &model.format_name,
+        ) {
+            result.entities.push(entity);
+        }
+        definitions.insert(
+            occurrence.symbol.as_str(),
+            DefinitionSiteEntry {
+                key,
+                kind,
+                document_index: occurrence.document_index,
+                start_line: occurrence.line,
+                end_line: occurrence.enclosing_end_line,
+            },
+        );
+    }
+
+    // Per document: definitions sorted by line for enclosing lookup
+    let mut definitions_by_document: HashMap<usize, Vec<&DefinitionSiteEntry>> = HashMap::new();
+    for site in definitions.values() {
+        definitions_by_document.entry(site.document_index).or_default().push(site);
+    }
+    for sites in definitions_by_document.values_mut() {
+        sites.sort_by_key(|s| (s.start_line, s.key.clone()));
+    }
+
+    let mut seen_edges: HashSet<(String, String, EdgeType)> = HashSet::new();
+    let mut push_edge = |from: &str, to: &str, edge_type: EdgeType, location: String, result: &mut ImportedCodeGraphResult| {
+        if from == to || !seen_edges.insert((from.to_string(), to.to_string(), edge_type)) {
+            return;
+        }
+        if let Ok(edge) = DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .source_location(location)
+            .metadata_entry(IMPORTED_FROM_METADATA_KEY, &model.format_name)
+            .build()
+        {
+            result.edges.push(edge);
+        }
+    };
+
+    for occurrence in model.occurrences.iter().filter(|o| !o.is_definition) {
+        let info = model.symbols.get(&occurrence.symbol);
+        if info.is_some_and(|i| i.is_local) || occurrence.symbol.starts_with("local ") {
+            continue;
+        }
+        let Some(enclosing) = definitions_by_document
+            .get(&occurrence.document_index)
+            .and_then(|sites| find_enclosing_definition_site(sites, occurrence.line))
+        else {
+            result.unattributed_references += 1;
+            continue;
+        };
+
+        let document = &model.documents[occurrence.document_index];
+        let (to_key, target_kind) = match definitions.get(occurrence.symbol.as_str()) {
+            Some(site) => (site.key.clone(), Some(site.kind.clone())),
+            None => {
+                let Some(parsed) = parse_scip_symbol_string(&occurrence.symbol) else { continue };
+                let Some(kind) = info.and_then(|i| i.kind.clone()).or(parsed.kind.clone()) else { continue };
+                let package = info.and_then(|i| i.package_name.clone()).unwrap_or(parsed.package_name);
+                (external_placeholder_key_for(document, &kind, &parsed.name, &package), Some(kind))
+            }
+        };
+
+        let is_call = match target_kind {
+            Some(EntityType::Function | EntityType::Method) => true,
+            Some(_) => false,
+            // Unknown kind (LSIF without tags): a `(` right after the name
+            None => document_lines[occurrence.document_index]
+                .as_ref()
+                .and_then(|lines| lines.get(occurrence.line as usize))
+                .and_then(|line| line.chars().nth(occurrence.end_character as usize))
+                == Some('('),
+        };
+        let location = format!("{}:{}", document.relative_path, occurrence.line + 1);
+        let edge_type = if is_call { EdgeType::Calls } else { EdgeType::Uses };
+        push_edge(&enclosing.key, &to_key, edge_type, location, &mut result);
+    }
+
+    for (symbol, info) in &model.symbols {
+        let Some(site) = definitions.get(symbol.as_str()) else { continue };
+        for interface in &info.implements {
+            if let Some(target) = definitions.get(interface.as_str()) {
+                let document = &model.documents[site.document_index];
+                let location = format!("{}:{}", document.relative_path, site.start_line + 1);
+                push_edge(&site.key, &target.key, EdgeType::Implements, location, &mut result);
+            }
+        }
+    }
+
+    result.entities.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    result.edges.sort_by(|a, b| {
+        (a.from_key.as_str(), a.to_key.as_str(), a.edge_type.as_str())
+            .cmp(&(b.from_key.as_str(), b.to_key.as_str(), b.edge_type.as_str()))
+    });
+    result
+}
+
+/// Innermost definition containing `line`, else the nearest preceding
+/// function/method definition
+fn find_enclosing_definition_site<'a>(
+    sites: &[&'a DefinitionSiteEntry],
+    line: u32,
+) -> Option<&'a DefinitionSiteEntry> {
+    let containing = sites
+        .iter()
+        .filter(|s| s.start_line <= line && s.end_line.is_some_and(|end| end >= line))
+        .max_by_key(|s| s.start_line);
+    if let Some(site) = containing {
+        return Some(site);
+    }
+    sites
+        .iter()
+        .filter(|s| s.start_line <= line && matches!(s.kind, EntityType::Function | EntityType::Method))
+        .filter(|s| s.end_line.is_none())
+        .max_by_key(|s| s.start_line)
+        .copied()
+}
+
+/// Entity type from the keywords on a definition line (LSIF without kinds)
+fn infer_entity_type_from_line(line: &str) -> Option<EntityType> {
+    let padded = format!(" {} ", line.trim());
+    let keyword_table: &[(&str, EntityType)] = &[
+        (" fn ", EntityType::Function),
+        (" func ", EntityType::Function),
+        (" def ", EntityType::Function),
+        (" function ", EntityType::Function),
+        (" struct ", EntityType::Struct),
+        (" trait ", EntityType::Trait),
+        (" interface ", EntityType::Interface),
+        (" class ", EntityType::Class),
+        (" enum ", EntityType::Enum),
+        (" const ", EntityType::Constant),
+        (" type ", EntityType::Struct),
+    ];
+    keyword_table
+        .iter()
+        .find(|(keyword, _)| padded.contains(keyword))
+        .map(|(_, kind)| kind.clone())
+}
+
+/// Language of a document: declared name, else from extension
+fn document_language_name(document: &IndexedDocumentRecord) -> String {
+    if !document.language.is_empty() {
+        return document.language.clone();
+    }
+    Language::from_file_path(Path::new(&document.relative_path))
+        .map(|l| l.to_string())
+        .unwrap_or_else(|| "unknown".to_string())
+}
+
+/// Placeholder key for a target outside the index
+fn external_placeholder_key_for(
+    document: &IndexedDocumentRecord,
+    kind: &EntityType,
+    name: &str,
+    package: &str,
+) -> String {
+    let type_segment = match kind {
+        EntityType::Method => "method",
+        EntityType::Struct => "struct",
+        EntityType::Trait => "trait",
+        EntityType::Interface => "interface",
+        EntityType::Class => "class",
+        EntityType::Enum => "enum",
+        EntityType::Module => "module",
+        EntityType::Macro => "macro",
+        EntityType::Variable => "var",
+        EntityType::Constant => "const",
+        _ => "fn",
+    };
+    let location = if package.is_empty() || package == "." {
+        "unresolved-reference".to_string()
+    } else {
+        format!("external-dependency-{}", package.replace(':', "_"))
+    };
+    format!(
+        "{}:{}:{}:{}:0-0",
+        document_language_name(document),
+        type_segment,
+        sanitize_entity_name_for_isgl1(name),
+        location
+    )
+}
+
+#[allow(clippy::too_many_arguments)]
+fn create_imported_code_entity(
+    key: &str,
+    name: &str,
+    kind: EntityType,
+    document: &IndexedDocumentRecord,
+    start_line: u32,
+    end_line: u32,
+    body: Option<String>,
+    info: &IndexedSymbolRecord,
+    format_name: &str,
+) -> Option<CodeEntity> {
+    let signature = InterfaceSignature {
+        entity_type: kind,
+        name: name.to_string(),
+        visibility: Visibility::Public,
+        file_path: PathBuf::from(&document.relative_path),
+        line_range: LineRange::new(start_line, end_line).ok()?,
+        module_path: vec![],
+        documentation: info.documentation.clone(),
+        language_specific: LanguageSpecificSignature::Rust(RustSignature {
+            generics: vec![],
+            lifetimes: vec![],
+            where_clauses: vec![],
+            attributes: vec![],
+            trait_impl: None,
+        }),
+    };
+    let is_test = document.relative_path.contains("_test.") || name.starts_with("Test") || name.starts_with("test_");
+    let entity_class = if is_test { EntityClass::TestImplementation } else { EntityClass::CodeImplementation };
+    let semantic_path = extract_semantic_path(&document.relative_path);
+    let content_hash = compute_content_hash(body.as_deref().unwrap_or(key));
+    let mut entity = CodeEntity::new_with_v2_fields(
+        key.to_string(),
+        signature,
+        entity_class,
+        compute_birth_timestamp(&document.relative_path, name),
+        content_hash,
+        semantic_path,
+    )
+    .ok()?;
+    entity.current_code = body;
+    entity
+        .metadata
+        .additional
+        .insert(IMPORTED_FROM_METADATA_KEY.to_string(), format_name.to_string());
+    Some(entity)
+}
+
+/// `file:///abs/path` → `/abs/path`
+pub(crate) fn path_from_file_uri(uri: &str) -> PathBuf {
+    PathBuf::from(uri.strip_prefix("file://").unwrap_or(uri))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::protobuf_wire_format_codec::ProtobufMessageWriter;
+
+    const SAVE_SYMBOL: &str = "scip-go gomod example.com/app v1 `example.com/app/store`/Store#Save().";
+    const MAIN_SYMBOL: &str = "scip-go gomod example.com/app v1 `example.com/app/cmd`/main().";
+    const QUERY_SYMBOL: &str = "scip-go gomod database/sql go1.22 `database/sql`/DB#Query().";
+
+    fn occurrence(range: &[i32], symbol: &str, roles: u64, enclosing: &[i32]) -> ProtobufMessageWriter {
+        let mut message = ProtobufMessageWriter::new();
+        message
+            .write_packed_int32_field(1, range)
+            .write_string_field(2, symbol)
+            .write_varint_field(3, roles)
+            .write_packed_int32_field(7, enclosing);
+        message
+    }
+
+    fn scip_fixture_bytes() -> Vec<u8> {
+        let mut document = ProtobufMessageWriter::new();
+        document
+            .write_string_field(1, "cmd/main.go")
+            .write_message_field(2, &occurrence(&[2, 5, 9], MAIN_SYMBOL, 1, &[2, 0, 6, 1]))
+            .write_message_field(2, &occurrence(&[3, 3, 7], SAVE_SYMBOL, 0, &[]))
+            .write_message_field(2, &occurrence(&[4, 5, 10], QUERY_SYMBOL, 0, &[]))
+            .write_message_field(2, &occurrence(&[5, 1, 2], "local 0", 0, &[]))
+            .write_string_field(4, "Go");
+        let mut store_document = ProtobufMessageWriter::new();
+        store_document
+            .write_string_field(1, "store/store.go")
+            .write_message_field(2, &occurrence(&[10, 16, 20], SAVE_SYMBOL, 1, &[10, 0, 12, 1]))
+            .write_string_field(4, "go");
+
+        let mut index = ProtobufMessageWriter::new();
+        index.write_message_field(2, &document).write_message_field(2, &store_document);
+        index.into_bytes()
+    }
+
+    #[test]
+    fn test_parse_scip_symbol_descriptors() {
+        let save = parse_scip_symbol_string(SAVE_SYMBOL).unwrap();
+        assert_eq!(save.name, "Save");
+        assert_eq!(save.kind, Some(EntityType::Method));
+        assert_eq!(save.package_name, "example.com/app");
+
+        let field = parse_scip_symbol_string("rust-analyzer cargo core 1.0 config/Config#timeout.").unwrap();
+        assert_eq!(field.kind, None);
+        assert!(parse_scip_symbol_string("local 12").is_none());
+    }
+
+    #[test]
+    fn test_scip_index_becomes_entities_and_edges() {
+        let model = decode_scip_index_model(&scip_fixture_bytes()).unwrap();
+        assert_eq!(model.documents.len(), 2);
+        assert_eq!(model.documents[0].language, "go");
+
+        let graph = build_graph_from_index_model(&model);
+        let names: Vec<&str> = graph.entities.iter().map(|e| e.interface_signature.name.as_str()).collect();
+        assert_eq!(graph.entities.len(), 2, "{:?}", names);
+
+        let main_key = graph.entities.iter().find(|e| e.interface_signature.name == "main").unwrap().isgl1_key.clone();
+        assert!(main_key.starts_with("go:fn:main:__cmd_main:T"));
+        let main_entity = graph.entities.iter().find(|e| e.isgl1_key == main_key).unwrap();
+        assert_eq!(main_entity.interface_signature.line_range.end, 7);
+
+        let targets: Vec<(&str, &EdgeType)> = graph
+            .edges
+            .iter()
+            .map(|e| (e.to_key.as_str(), &e.edge_type))
+            .collect();
+        assert_eq!(graph.edges.len(), 2, "{:?}", targets);
+        assert!(graph.edges.iter().all(|e| e.from_key.as_str() == main_key && e.edge_type == EdgeType::Calls));
+        assert!(targets.iter().any(|(to, _)| to.starts_with("go:method:Save:__store_store:T")));
+        assert!(targets.iter().any(|(to, _)| *to == "go:method:Query:external-dependency-database/sql:0-0"));
+        assert_eq!(graph.edges[0].metadata_value(IMPORTED_FROM_METADATA_KEY), Some("scip"));
+    }
+}
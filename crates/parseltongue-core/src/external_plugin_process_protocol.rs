@@ -0,0 +1,681 @@
+//! External extractor and enricher plugins (v1.7.3)
+//!
+//! # 4-Word Naming: external_plugin_process_protocol
+//!
+//! Teams add extractors for in-house DSLs, config formats or frameworks
+//! without forking: a plugin is any executable (a Go binary, a Python
+//! script, ...) speaking newline-delimited JSON on stdin/stdout, declared in
+//! `.parseltongue.toml`:
+//!
+//! ```toml
+//! [[plugins]]
+//! name = "terraform"
+//! command = ["./tools/pt-terraform", "--strict"]  # relative to the config file
+//! extensions = [".tf"]     # extractor: these files are sent to the plugin
+//! enrich = false           # enricher: sees the whole graph after resolution
+//! timeout_seconds = 30     # per reply (default 30)
+//! ```
+//!
+//! ## Protocol (version 1)
+//!
+//! One JSON object per line each way; the plugin answers every request
+//! with exactly one line, in order. stderr is passed through for logs.
+//!
+//! ```text
+//! → {"type":"hello","protocol":1,"root":"/abs/repo"}
+//! ← {"type":"hello","protocol":1}
+//! → {"type":"extract","path":"infra/main.tf","content":"..."}
+//! ← {"entities":[{"name":"aws_s3_bucket.logs","kind":"struct","line_start":3,"line_end":9,"metadata":{"provider":"aws"}}],
+//!    "edges":[{"from":"aws_s3_bucket.logs","to":"var.region","type":"Uses","line":4}]}
+//! → {"type":"enrich","entities":[{"key":...,"name":...,"kind":...,"file_path":...,"line_start":1,"line_end":5,"metadata":{}}],
+//!    "edges":[{"from":...,"to":...,"type":"Calls"}]}
+//! ← {"edges":[{"from":KEY,"to":KEY,"type":"Calls"}],"metadata":[{"key":KEY,"entries":{"owner_team":"payments"}}]}
+//! ```
+//!
+//! Closing stdin ends the session. Any reply may be `{"error":"..."}`,
+//! which skips that file (extract) or that plugin's enrichment.
+//!
+//! ## Mapping
+//!
+//! Extracted entities get regular ISGL1 keys with the plugin `language`
+//! (default: the plugin name) and one of the kinds `fn`/`function`,
+//! `method`, `class`, `struct`, `interface`, `enum`, `trait`, `module`,
+//! `var`/`variable`, `const`/`constant`, `table`, `view`, `route`.
+//! Extract edge endpoints name entities of the same reply, or are full
+//! keys; anything else becomes an unresolved placeholder. Enrich edges
+//! must join existing keys. Edge types are the graph's (`Calls`, `Uses`,
+//! ...; case-insensitive), and every plugin edge carries `plugin: <name>`.
+//!
+//! Go `plugin` packages cannot be loaded into this binary; ship them as a
+//! small `main` speaking this protocol instead.
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::io::{BufRead, BufReader, Write};
+use std::path::{Path, PathBuf};
+use std::process::{Child, ChildStdin, Command, Stdio};
+use std::sync::mpsc::{self, Receiver, RecvTimeoutError};
+use std::time::Duration;
+
+use serde::{Deserialize, Serialize};
+use serde_json::{json, Value};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::filtered_graph_traversal_queries::all_edge_type_variants_list;
+use crate::go_typed_selector_resolver::relative_slash_path;
+use crate::protobuf_service_definition_linker::{build_contract_file_entity, find_contract_files_by_extension};
+
+/// Protocol version sent in `hello`
+pub const PLUGIN_PROTOCOL_VERSION_NUMBER: u64 = 1;
+
+/// Edge metadata naming the plugin that produced the edge
+pub const PLUGIN_SOURCE_METADATA_KEY: &str = "plugin";
+
+/// Reply timeout when `timeout_seconds` is unset
+const DEFAULT_PLUGIN_REPLY_TIMEOUT_SECONDS: u64 = 30;
+
+/// One `[[plugins]]` entry
+///
+/// # 4-Word Name: ExternalPluginProcessSpec
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct ExternalPluginProcessSpec {
+    pub name: String,
+    /// Program and arguments
+    pub command: Vec<String>,
+    /// File extensions routed to the plugin (`.tf` or `tf`); empty = no extraction
+    pub extensions: Vec<String>,
+    /// Send the resolved graph for extra edges and metadata
+    pub enrich: bool,
+    /// Key language segment of extracted entities (default: `name`)
+    pub language: Option<String>,
+    pub timeout_seconds: Option<u64>,
+}
+
+impl ExternalPluginProcessSpec {
+    /// Language segment of the keys of extracted entities
+    pub fn language_segment(&self) -> &str {
+        self.language.as_deref().unwrap_or(&self.name)
+    }
+}
+
+/// Entity of an `extract` reply
+///
+/// # 4-Word Name: PluginEntityDeclarationEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default)]
+pub struct PluginEntityDeclarationEntry {
+    pub name: String,
+    pub kind: String,
+    pub line_start: u32,
+    pub line_end: u32,
+    pub metadata: BTreeMap<String, String>,
+}
+
+/// Edge of an `extract` or `enrich` reply
+///
+/// # 4-Word Name: PluginEdgeDeclarationEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default)]
+pub struct PluginEdgeDeclarationEntry {
+    pub from: String,
+    pub to: String,
+    #[serde(rename = "type")]
+    pub edge_type: String,
+    pub line: Option<u32>,
+    pub metadata: BTreeMap<String, String>,
+}
+
+/// Metadata entries for one existing entity (`enrich` reply)
+///
+/// # 4-Word Name: PluginMetadataPatchEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default)]
+pub struct PluginMetadataPatchEntry {
+    pub key: String,
+    pub entries: BTreeMap<String, String>,
+}
+
+/// Reply to `extract` or `enrich`
+///
+/// # 4-Word Name: PluginReplyPayloadEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default)]
+pub struct PluginReplyPayloadEntry {
+    pub entities: Vec<PluginEntityDeclarationEntry>,
+    pub edges: Vec<PluginEdgeDeclarationEntry>,
+    pub metadata: Vec<PluginMetadataPatchEntry>,
+    pub error: Option<String>,
+}
+
+/// A running plugin process
+///
+/// # 4-Word Name: PluginProcessSessionHandle
+pub struct PluginProcessSessionHandle {
+    name: String,
+    child: Child,
+    stdin: Option<ChildStdin>,
+    replies: Receiver<String>,
+    timeout: Duration,
+}
+
+impl PluginProcessSessionHandle {
+    /// Spawn the plugin in `root` and exchange `hello`
+    ///
+    /// # 4-Word Name: start_plugin_process_session
+    ///
+    /// # Contract
+    /// - Postcondition: Err when the program cannot start, does not answer
+    ///   `hello` within the timeout, or speaks another protocol version
+    pub fn start_plugin_process_session(spec: &ExternalPluginProcessSpec, root: &Path) -> Result<Self, String> {
+        let (program, args) = spec.command.split_first().ok_or("plugin has an empty command")?;
+        let mut child = Command::new(program)
+            .args(args)
+            .current_dir(root)
+            .stdin(Stdio::piped())
+            .stdout(Stdio::piped())
+            .stderr(Stdio::inherit())
+            .spawn()
+            .map_err(|e| format!("cannot start '{}': {}", program, e))?;
+        let stdout = child.stdout.take().ok_or("plugin stdout unavailable")?;
+        let (sender, replies) = mpsc::channel();
+        // Reads block; a thread lets requests time out
+        std::thread::spawn(move || {
+            for line in BufReader::new(stdout).lines().map_while(Result::ok) {
+                if !line.trim().is_empty() && sender.send(line).is_err() {
+                    break;
+                }
+            }
+        });
+        let mut session = Self {
+            name: spec.name.clone(),
+            stdin: child.stdin.take(),
+            child,
+            replies,
+            timeout: Duration::from_secs(spec.timeout_seconds.unwrap_or(DEFAULT_PLUGIN_REPLY_TIMEOUT_SECONDS)),
+        };
+        let root = std::fs::canonicalize(root).unwrap_or_else(|_| root.to_path_buf());
+        let hello = session.request_plugin_reply_value(&json!({
+            "type": "hello",
+            "protocol": PLUGIN_PROTOCOL_VERSION_NUMBER,
+            "root": root.display().to_string(),
+        }))?;
+        match hello.get("protocol").and_then(Value::as_u64) {
+            Some(PLUGIN_PROTOCOL_VERSION_NUMBER) => Ok(session),
+            other => Err(format!("plugin answered hello with protocol {:?}, expected {}", other, PLUGIN_PROTOCOL_VERSION_NUMBER)),
+        }
+    }
+
+    /// Send one request line, wait for its reply line
+    ///
+    /// # 4-Word Name: request_plugin_reply_value
+    pub fn request_plugin_reply_value(&mut self, request: &Value) -> Result<Value, String> {
+        let stdin = self.stdin.as_mut().ok_or("plugin session is closed")?;
+        writeln!(stdin, "{}", request)
+            .and_then(|_| stdin.flush())
+            .map_err(|e| format!("plugin '{}' stopped reading: {}", self.name, e))?;
+        let line = match self.replies.recv_timeout(self.timeout) {
+            Ok(line) => line,
+            Err(RecvTimeoutError::Timeout) => {
+                return Err(format!("plugin '{}' did not reply within {:?}", self.name, self.timeout))
+            }
+            Err(RecvTimeoutError::Disconnected) => return Err(format!("plugin '{}' exited", self.name)),
+        };
+        serde_json::from_str(&line).map_err(|e| format!("plugin '{}' sent invalid JSON: {}", self.name, e))
+    }
+
+    /// `request_plugin_reply_value` decoded as a reply payload
+    fn request_plugin_reply_payload(&mut self, request: &Value) -> Result<PluginReplyPayloadEntry, String> {
+        let value = self.request_plugin_reply_value(request)?;
+        serde_json::from_value(value).map_err(|e| format!("plugin '{}' sent an unexpected reply: {}", self.name, e))
+    }
+}
+
+impl Drop for PluginProcessSessionHandle {
+    fn drop(&mut self) {
+        // EOF on stdin ends the session; a plugin still running after its
+        // timeout is killed
+        drop(self.stdin.take());
+        let deadline = std::time::Instant::now() + self.timeout;
+        while std::time::Instant::now() < deadline {
+            if !matches!(self.child.try_wait(), Ok(None)) {
+                return;
+            }
+            std::thread::sleep(Duration::from_millis(20));
+        }
+        let _ = self.child.kill();
+        let _ = self.child.wait();
+    }
+}
+
+/// Entities and edges of every file matching the plugin's extensions
+///
+/// # 4-Word Name: extract_files_with_plugin
+///
+/// # Contract
+/// - Postcondition: files sorted by path (vendor/build/hidden directories
+///   skipped, as for `.proto`); a file whose reply is an `error` is skipped
+///   and named in the returned warnings
+/// - Err only when the session cannot be started or breaks mid-run
+pub fn extract_files_with_plugin(
+    spec: &ExternalPluginProcessSpec,
+    root: &Path,
+) -> Result<(Vec<CodeEntity>, Vec<DependencyEdge>, Vec<String>), String> {
+    let files: Vec<PathBuf> = plugin_file_extensions(spec)
+        .iter()
+        .flat_map(|extension| find_contract_files_by_extension(root, extension))
+        .collect::<std::collections::BTreeSet<_>>()
+        .into_iter()
+        .collect();
+    let (mut entities, mut edges, mut warnings) = (Vec::new(), Vec::new(), Vec::new());
+    if files.is_empty() {
+        return Ok((entities, edges, warnings));
+    }
+
+    let mut session = PluginProcessSessionHandle::start_plugin_process_session(spec, root)?;
+    for path in files {
+        let Ok(content) = std::fs::read_to_string(&path) else { continue };
+        let relative = relative_slash_path(root, &path);
+        let reply = session.request_plugin_reply_payload(&json!({
+            "type": "extract",
+            "path": relative,
+            "content": content,
+        }))?;
+        if let Some(error) = reply.error {
+            warnings.push(format!("{}: {}", relative, error));
+            continue;
+        }
+        let (file_entities, file_edges) = build_plugin_file_graph(spec, &path.to_string_lossy(), &content, &reply);
+        entities.extend(file_entities);
+        edges.extend(file_edges);
+    }
+    Ok((entities, edges, warnings))
+}
+
+/// Entities and edges of one `extract` reply
+///
+/// # 4-Word Name: build_plugin_file_graph
+///
+/// # Contract
+/// - Postcondition: entities with an unknown `kind` or no `name` are
+///   dropped; an edge endpoint naming an entity of this reply resolves to
+///   its key, a full key is kept, anything else becomes
+///   `{language}:unknown:{name}:unresolved-reference:0-0`
+pub fn build_plugin_file_graph(
+    spec: &ExternalPluginProcessSpec,
+    file_path: &str,
+    source: &str,
+    reply: &PluginReplyPayloadEntry,
+) -> (Vec<CodeEntity>, Vec<DependencyEdge>) {
+    let language = spec.language_segment();
+    let lines: Vec<&str> = source.lines().collect();
+    let mut entities = Vec::new();
+    let mut key_by_name: HashMap<&str, String> = HashMap::new();
+    for declared in &reply.entities {
+        let Some(kind) = entity_type_from_plugin_kind(&declared.kind) else { continue };
+        if declared.name.is_empty() {
+            continue;
+        }
+        let start = declared.line_start.max(1);
+        let end = declared.line_end.max(start);
+        let body = lines.get(start as usize - 1..(end as usize).min(lines.len())).map(|block| block.join("\n"));
+        let metadata: Vec<(&str, String)> = declared.metadata.iter().map(|(k, v)| (k.as_str(), v.clone())).collect();
+        if let Some(entity) = build_contract_file_entity(language, file_path, &declared.name, kind, (start, end), body, &metadata) {
+            key_by_name.entry(declared.name.as_str()).or_insert_with(|| entity.isgl1_key.clone());
+            entities.push(entity);
+        }
+    }
+
+    let endpoint_key = |endpoint: &str| match key_by_name.get(endpoint) {
+        Some(key) => key.clone(),
+        None if looks_like_isgl1_key(endpoint) => endpoint.to_string(),
+        None => format!("{}:unknown:{}:unresolved-reference:0-0", language, endpoint),
+    };
+    let edges = reply
+        .edges
+        .iter()
+        .filter(|declared| !declared.from.is_empty() && !declared.to.is_empty())
+        .filter_map(|declared| {
+            let location = declared.line.map(|line| format!("{}:{}", file_path, line));
+            build_plugin_edge(spec, &endpoint_key(&declared.from), &endpoint_key(&declared.to), declared, location)
+        })
+        .collect();
+    (entities, edges)
+}
+
+/// Send the graph to an enricher; add its edges and metadata
+///
+/// # 4-Word Name: enrich_graph_with_plugin
+///
+/// # Contract
+/// - Postcondition: returns (edges added, entities patched); reply edges
+///   whose endpoints are not both in the graph, or already present, are
+///   dropped; metadata patches for unknown keys are ignored
+pub fn enrich_graph_with_plugin(
+    spec: &ExternalPluginProcessSpec,
+    root: &Path,
+    entities: &mut [CodeEntity],
+    edges: &mut Vec<DependencyEdge>,
+) -> Result<(usize, usize), String> {
+    let request = json!({
+        "type": "enrich",
+        "entities": entities.iter().map(|entity| json!({
+            "key": entity.isgl1_key,
+            "name": entity.interface_signature.name,
+            "kind": entity.isgl1_key.split(':').nth(1).unwrap_or(""),
+            "file_path": relative_slash_path(root, &entity.interface_signature.file_path),
+            "line_start": entity.interface_signature.line_range.start,
+            "line_end": entity.interface_signature.line_range.end,
+            "metadata": entity.metadata.additional.iter().collect::<BTreeMap<_, _>>(),
+        })).collect::<Vec<_>>(),
+        "edges": edges.iter().map(|edge| json!({
+            "from": edge.from_key.as_str(),
+            "to": edge.to_key.as_str(),
+            "type": edge.edge_type.as_str(),
+        })).collect::<Vec<_>>(),
+    });
+    let mut session = PluginProcessSessionHandle::start_plugin_process_session(spec, root)?;
+    let reply = session.request_plugin_reply_payload(&request)?;
+    if let Some(error) = reply.error {
+        return Err(error);
+    }
+    Ok(apply_plugin_enrich_reply(spec, entities, edges, &reply))
+}
+
+/// Merge an `enrich` reply into the graph; returns (edges added, entities patched)
+///
+/// # 4-Word Name: apply_plugin_enrich_reply
+pub fn apply_plugin_enrich_reply(
+    spec: &ExternalPluginProcessSpec,
+    entities: &mut [CodeEntity],
+    edges: &mut Vec<DependencyEdge>,
+    reply: &PluginReplyPayloadEntry,
+) -> (usize, usize) {
+    let known: HashSet<String> = entities.iter().map(|entity| entity.isgl1_key.clone()).collect();
+    let mut seen: HashSet<(String, String, &'static str)> = edges
+        .iter()
+        .map(|edge| (edge.from_key.as_str().to_string(), edge.to_key.as_str().to_string(), edge.edge_type.as_str()))
+        .collect();
+    let mut added = 0;
+    for declared in &reply.edges {
+        if !known.contains(&declared.from) || !known.contains(&declared.to) {
+            continue;
+        }
+        let Some(edge) = build_plugin_edge(spec, &declared.from, &declared.to, declared, None) else { continue };
+        if seen.insert((declared.from.clone(), declared.to.clone(), edge.edge_type.as_str())) {
+            edges.push(edge);
+            added += 1;
+        }
+    }
+
+    let mut patched = 0;
+    let patches: HashMap<&str, &BTreeMap<String, String>> =
+        reply.metadata.iter().map(|patch| (patch.key.as_str(), &patch.entries)).collect();
+    for entity in entities.iter_mut() {
+        if let Some(entries) = patches.get(entity.isgl1_key.as_str()).filter(|entries| !entries.is_empty()) {
+            entity.metadata.additional.extend(entries.iter().map(|(k, v)| (k.clone(), v.clone())));
+            patched += 1;
+        }
+    }
+    (added, patched)
+}
+
+/// Plugin `kind` → entity type (None for kinds the graph has no type for)
+///
+/// # 4-Word Name: entity_type_from_plugin_kind
+pub fn entity_type_from_plugin_kind(kind: &str) -> Option<EntityType> {
+    Some(match kind.to_ascii_lowercase().as_str() {
+        "fn" | "function" => EntityType::Function,
+        "method" => EntityType::Method,
+        "class" => EntityType::Class,
+        "struct" => EntityType::Struct,
+        "interface" => EntityType::Interface,
+        "enum" => EntityType::Enum,
+        "trait" => EntityType::Trait,
+        "module" => EntityType::Module,
+        "var" | "variable" => EntityType::Variable,
+        "const" | "constant" => EntityType::Constant,
+        "table" => EntityType::Table,
+        "view" => EntityType::View,
+        "route" => EntityType::Route,
+        _ => return None,
+    })
+}
+
+fn build_plugin_edge(
+    spec: &ExternalPluginProcessSpec,
+    from: &str,
+    to: &str,
+    declared: &PluginEdgeDeclarationEntry,
+    location: Option<String>,
+) -> Option<DependencyEdge> {
+    let edge_type = all_edge_type_variants_list()
+        .into_iter()
+        .find(|variant| variant.as_str().eq_ignore_ascii_case(&declared.edge_type))?;
+    let mut builder = DependencyEdge::builder()
+        .from_key(from)
+        .to_key(to)
+        .edge_type(edge_type)
+        .metadata_entry(PLUGIN_SOURCE_METADATA_KEY, spec.name.clone());
+    for (key, value) in &declared.metadata {
+        builder = builder.metadata_entry(key.clone(), value.clone());
+    }
+    if let Some(location) = location {
+        builder = builder.source_location(location);
+    }
+    builder.build().ok()
+}
+
+/// Extensions a plugin extracts, with the leading dot (`tf` and `.tf` alike)
+///
+/// # 4-Word Name: plugin_file_extensions
+pub fn plugin_file_extensions(spec: &ExternalPluginProcessSpec) -> Vec<String> {
+    spec.extensions
+        .iter()
+        .filter(|extension| !extension.trim_start_matches('.').is_empty())
+        .map(|extension| format!(".{}", extension.trim_start_matches('.')))
+        .collect()
+}
+
+/// `lang:kind:name:path:T...` or a placeholder (at least four `:` segments)
+fn looks_like_isgl1_key(endpoint: &str) -> bool {
+    endpoint.split(':').count() >= 4
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn terraform_plugin(command: Vec<String>) -> ExternalPluginProcessSpec {
+        ExternalPluginProcessSpec {
+            name: "terraform".to_string(),
+            command,
+            extensions: vec!["tf".to_string()],
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_extract_reply_builds_keyed_graph() {
+        let reply: PluginReplyPayloadEntry = serde_json::from_str(
+            r#"{"entities":[{"name":"aws_s3_bucket.logs","kind":"struct","line_start":1,"line_end":3,"metadata":{"provider":"aws"}},
+                            {"name":"odd","kind":"widget","line_start":4,"line_end":4}],
+                "edges":[{"from":"aws_s3_bucket.logs","to":"var.region","type":"uses","line":2},
+                         {"from":"aws_s3_bucket.logs","to":"x","type":"Teleports"}]}"#,
+        )
+        .unwrap();
+        let source = "resource \"aws_s3_bucket\" \"logs\" {\n  region = var.region\n}\nodd {}\n";
+        let (entities, edges) = build_plugin_file_graph(&terraform_plugin(vec![]), "infra/main.tf", source, &reply);
+
+        assert_eq!(entities.len(), 1, "unknown kinds are dropped");
+        assert!(entities[0].isgl1_key.starts_with("terraform:struct:aws_s3_bucket.logs:"));
+        assert_eq!(entities[0].metadata.additional.get("provider").map(String::as_str), Some("aws"));
+        assert_eq!(entities[0].current_code.as_deref().map(|code| code.lines().count()), Some(3));
+        assert_eq!(edges.len(), 1, "unknown edge types are dropped");
+        assert_eq!(edges[0].from_key.as_str(), entities[0].isgl1_key);
+        assert_eq!(edges[0].to_key.as_str(), "terraform:unknown:var.region:unresolved-reference:0-0");
+        assert_eq!(edges[0].edge_type, EdgeType::Uses);
+        assert_eq!(edges[0].source_location.as_deref(), Some("infra/main.tf:2"));
+        assert_eq!(edges[0].metadata.get(PLUGIN_SOURCE_METADATA_KEY).map(String::as_str), Some("terraform"));
+
+        let mut all_entities = entities.clone();
+        let mut all_edges = edges.clone();
+        let key = entities[0].isgl1_key.clone();
+        let enrich: PluginReplyPayloadEntry = serde_json::from_value(json!({
+            "edges": [{"from": key, "to": key, "type": "Uses"}, {"from": key, "to": "go:fn:gone:x:T1", "type": "Calls"}],
+            "metadata": [{"key": key, "entries": {"owner_team": "infra"}}],
+        }))
+        .unwrap();
+        let applied = apply_plugin_enrich_reply(&terraform_plugin(vec![]), &mut all_entities, &mut all_edges, &enrich);
+        assert_eq!(applied, (1, 1));
+        assert_eq!(all_entities[0].metadata.additional.get("owner_team").map(String::as_str), Some("infra"));
+
+        #[cfg(unix)]
+        {
+            let dir = tempfile::tempdir().unwrap();
+            std::fs::write(dir.path().join("main.tf"), source).unwrap();
+            let script = r#"read hello; echo '{"type":"hello","protocol":1}'
+while read request; do echo '{"entities":[{"name":"logs","kind":"struct","line_start":1,"line_end":3}]}'; done"#;
+            let plugin = terraform_plugin(vec!["sh".to_string(), "-c".to_string(), script.to_string()]);
+            let (entities, _, warnings) = extract_files_with_plugin(&plugin, dir.path()).unwrap();
+            assert_eq!(entities.len(), 1);
+            assert!(warnings.is_empty());
+        }
+    }
+
+    /// Plugin running `script` under `sh -c`, one-second replies
+    #[cfg(unix)]
+    fn shell_plugin(script: &str) -> ExternalPluginProcessSpec {
+        ExternalPluginProcessSpec {
+            timeout_seconds: Some(1),
+            ..terraform_plugin(vec!["sh".to_string(), "-c".to_string(), script.to_string()])
+        }
+    }
+
+    #[cfg(unix)]
+    const HELLO_REPLY_LINE: &str = r#"read hello; echo '{"type":"hello","protocol":1}'"#;
+
+    #[cfg(unix)]
+    #[test]
+    fn test_session_rejects_version_mismatch_crash_and_timeout() {
+        let dir = tempfile::tempdir().unwrap();
+        let start =
+            |script: &str| PluginProcessSessionHandle::start_plugin_process_session(&shell_plugin(script), dir.path());
+
+        let newer = start(r#"read hello; echo '{"type":"hello","protocol":2}'"#).err().unwrap();
+        assert_eq!(newer, "plugin answered hello with protocol Some(2), expected 1");
+        let missing = start(r#"read hello; echo '{"type":"hello"}'"#).err().unwrap();
+        assert!(missing.contains("protocol None"), "{}", missing);
+        assert_eq!(start("read hello; exit 3").err().unwrap(), "plugin 'terraform' exited");
+        let started = std::time::Instant::now();
+        let silent = start("read hello; sleep 5").err().unwrap();
+        assert_eq!(silent, "plugin 'terraform' did not reply within 1s");
+        assert!(started.elapsed() < Duration::from_secs(5), "a hung plugin is killed after its timeout");
+
+        let empty = terraform_plugin(Vec::new());
+        let error = PluginProcessSessionHandle::start_plugin_process_session(&empty, dir.path()).err().unwrap();
+        assert_eq!(error, "plugin has an empty command");
+        let absent = terraform_plugin(vec!["./no-such-plugin-binary".to_string()]);
+        let error = PluginProcessSessionHandle::start_plugin_process_session(&absent, dir.path()).err().unwrap();
+        assert!(error.starts_with("cannot start './no-such-plugin-binary'"), "{}", error);
+        // Nothing to extract: the plugin is never started
+        let (entities, edges, warnings) = extract_files_with_plugin(&empty, dir.path()).unwrap();
+        assert!(entities.is_empty() && edges.is_empty() && warnings.is_empty());
+
+        // A crash mid-run fails the whole extraction
+        std::fs::write(dir.path().join("main.tf"), "x\n").unwrap();
+        let crashing = shell_plugin(&format!("{}; read request; exit 1", HELLO_REPLY_LINE));
+        assert_eq!(extract_files_with_plugin(&crashing, dir.path()).unwrap_err(), "plugin 'terraform' exited");
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn test_malformed_and_error_replies() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("a.tf"), "a\n").unwrap();
+        std::fs::write(dir.path().join("b.tf"), "b\n").unwrap();
+
+        let invalid = shell_plugin(&format!("{}; read request; echo 'not json'", HELLO_REPLY_LINE));
+        let error = extract_files_with_plugin(&invalid, dir.path()).unwrap_err();
+        assert!(error.starts_with("plugin 'terraform' sent invalid JSON"), "{}", error);
+        let mistyped = shell_plugin(&format!(r#"{}; read request; echo '{{"entities":"none"}}'"#, HELLO_REPLY_LINE));
+        let error = extract_files_with_plugin(&mistyped, dir.path()).unwrap_err();
+        assert!(error.starts_with("plugin 'terraform' sent an unexpected reply"), "{}", error);
+
+        // An error reply skips that file only; blank lines are not replies
+        let partial = shell_plugin(&format!(
+            r#"{}
+read first; echo; echo '{{"error":"cannot parse"}}'
+read second; echo '{{"entities":[{{"name":"b","kind":"MODULE","line_start":1,"line_end":1}}]}}'"#,
+            HELLO_REPLY_LINE
+        ));
+        let (entities, edges, warnings) = extract_files_with_plugin(&partial, dir.path()).unwrap();
+        assert_eq!(warnings, vec!["a.tf: cannot parse".to_string()]);
+        assert_eq!(entities.len(), 1);
+        assert!(entities[0].isgl1_key.starts_with("terraform:module:b:"), "{}", entities[0].isgl1_key);
+        assert!(edges.is_empty());
+
+        let refusing = ExternalPluginProcessSpec {
+            enrich: true,
+            ..shell_plugin(&format!(r#"{}; read request; echo '{{"error":"graph too large"}}'"#, HELLO_REPLY_LINE))
+        };
+        let (mut entities, mut edges) = (entities, Vec::new());
+        let error = enrich_graph_with_plugin(&refusing, dir.path(), &mut entities, &mut edges).unwrap_err();
+        assert_eq!(error, "graph too large");
+        assert!(edges.is_empty());
+    }
+
+    #[test]
+    fn test_reply_mapping_and_spec_edge_cases() {
+        let reply: PluginReplyPayloadEntry = serde_json::from_value(json!({
+            "entities": [
+                {"name": "first", "kind": "Function", "line_start": 0, "line_end": 0},
+                {"name": "", "kind": "fn", "line_start": 1, "line_end": 1},
+                {"name": "late", "kind": "const", "line_start": 10, "line_end": 12},
+            ],
+            "edges": [
+                {"from": "first", "to": "go:fn:Run:__cmd:T1", "type": "CALLS", "metadata": {"via": "hook"}},
+                {"from": "", "to": "first", "type": "Uses"},
+                {"from": "late", "to": "first", "type": "Uses"},
+            ],
+        }))
+        .unwrap();
+        let spec = ExternalPluginProcessSpec { language: Some("hcl".to_string()), ..terraform_plugin(vec![]) };
+        let (entities, edges) = build_plugin_file_graph(&spec, "infra/main.tf", "one\ntwo\n", &reply);
+        assert_eq!(entities.len(), 2, "nameless entities are dropped");
+        assert!(entities[0].isgl1_key.starts_with("hcl:fn:first:"), "{}", entities[0].isgl1_key);
+        assert_eq!(entities[0].interface_signature.line_range.start, 1, "line 0 clamps to 1");
+        assert_eq!(entities[0].current_code.as_deref(), Some("one"));
+        assert_eq!(entities[1].current_code, None, "lines past the end of the file have no body");
+
+        assert_eq!(edges.len(), 2, "edges without an endpoint are dropped");
+        assert_eq!(edges[0].to_key.as_str(), "go:fn:Run:__cmd:T1", "full keys are kept");
+        assert_eq!(edges[0].edge_type, EdgeType::Calls);
+        assert_eq!(edges[0].source_location, None);
+        assert_eq!(edges[0].metadata.get("via").map(String::as_str), Some("hook"));
+        assert_eq!(edges[1].from_key.as_str(), entities[1].isgl1_key);
+
+        // Enrich edges already in the graph are not added twice
+        let (mut graph_entities, mut graph_edges) = (entities.clone(), edges.clone());
+        let repeat: PluginReplyPayloadEntry = serde_json::from_value(json!({
+            "edges": [{"from": entities[1].isgl1_key, "to": entities[0].isgl1_key, "type": "uses"}],
+            "metadata": [
+                {"key": entities[0].isgl1_key, "entries": {}},
+                {"key": "hcl:fn:gone:x:T1", "entries": {"a": "b"}},
+            ],
+        }))
+        .unwrap();
+        assert_eq!(apply_plugin_enrich_reply(&spec, &mut graph_entities, &mut graph_edges, &repeat), (0, 0));
+        assert_eq!(graph_edges.len(), 2);
+
+        let extensions = ExternalPluginProcessSpec {
+            extensions: vec!["tf".to_string(), ".hcl".to_string(), ".".to_string(), String::new()],
+            ..Default::default()
+        };
+        assert_eq!(plugin_file_extensions(&extensions), vec![".tf".to_string(), ".hcl".to_string()]);
+        assert_eq!(terraform_plugin(vec![]).language_segment(), "terraform");
+        assert_eq!(entity_type_from_plugin_kind("VARIABLE"), Some(EntityType::Variable));
+        assert_eq!(entity_type_from_plugin_kind("widget"), None);
+        let unknown_field = serde_json::from_value::<ExternalPluginProcessSpec>(json!({"name": "x", "cmd": ["x"]}));
+        assert!(unknown_field.is_err(), "misspelled keys are rejected");
+    }
+}
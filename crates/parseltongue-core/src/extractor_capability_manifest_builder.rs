@@ -0,0 +1,554 @@
+//! Extractor Capability Manifest Builder (v1.7.3)
+//!
+//! # 4-Word Naming: extractor_capability_manifest_builder
+//!
+//! A graph built by extractors without generics or `macro_rules!` support
+//! looks exactly like one of a codebase that has none. This module describes,
+//! per language, what the installed extractors can produce, so consumers can
+//! tell the two apart programmatically (`parseltongue capabilities --json`,
+//! the `capabilities` object of every graph snapshot):
+//!
+//! - `node_kinds`: key kind segments (`fn`, `struct`, `route`, ...)
+//! - `edge_types`: edge types (`Calls`, `Implements`, ...)
+//! - `features`: named capabilities (`generics`, `imports`, `macros`, ...)
+//! - `passes`: enrichment passes that add to the per-file extraction
+//! - `extractor_version`: `{parseltongue version}+{fingerprint}`, pinned to
+//!   the embedded query files, the grammar ABI and the pass table
+//!
+//! ## Sources
+//!
+//! Query-based languages are described from the compiled-in `.scm` files:
+//! `@definition.*` captures of the entity query give node kinds,
+//! `@dependency.*` captures of the dependency query give edge types (with the
+//! extractor's own classification), and capture names give query features.
+//! Kotlin (syntax walk) and cross-file passes are described by
+//! [`ENRICHMENT_PASS_CAPABILITY_LIST`], which must be updated with the passes.
+//!
+//! ## Drift
+//!
+//! Ingest stores the manifest with the graph. `diff_capability_manifest_pair`
+//! lists, per language whose version changed, the capabilities only one side
+//! has; an incremental re-index warns on drift, since unchanged files keep
+//! what the older extractors produced.
+
+use std::collections::{BTreeMap, BTreeSet};
+
+use serde::{Deserialize, Serialize};
+
+use crate::entities::{EdgeType, Language};
+use crate::error::ParseltongError;
+use crate::isgl1_v2::compute_content_hash;
+use crate::query_extractor::{
+    classify_dependency_capture_edge_type, entity_type_of_definition_capture, key_component_for_entity_type,
+    QueryBasedExtractor,
+};
+
+/// Version of the manifest layout (bumped on incompatible changes)
+pub const CAPABILITY_MANIFEST_FORMAT_VERSION: u32 = 1;
+
+/// Languages described by the manifest, in output order
+const DESCRIBED_LANGUAGE_LIST: &[Language] = &[
+    Language::C,
+    Language::Cpp,
+    Language::CSharp,
+    Language::Go,
+    Language::Java,
+    Language::JavaScript,
+    Language::Kotlin,
+    Language::Php,
+    Language::Python,
+    Language::Ruby,
+    Language::Rust,
+    Language::Sql,
+    Language::Swift,
+    Language::TypeScript,
+];
+
+/// Query features: feature name and the capture name fragments implying it
+const CAPTURE_FEATURE_RULE_LIST: &[(&str, &[&str])] = &[
+    ("async", &["await", "async", "promise"]),
+    ("decorators", &["decorator", "annotation"]),
+    ("generics", &["type_parameters", "type_arguments", "generic", "template"]),
+    ("imports", &["import", "include", "require", "dependency.use"]),
+];
+
+/// What one extractor or enrichment pass adds for its languages
+///
+/// # 4-Word Name: EnrichmentPassCapabilityEntry
+#[derive(Debug)]
+pub struct EnrichmentPassCapabilityEntry {
+    /// Module name of the pass
+    pub pass: &'static str,
+    /// Language display names (`go`, `rust`, ...)
+    pub languages: &'static [&'static str],
+    /// Key kind segments of the nodes it creates
+    pub node_kinds: &'static [&'static str],
+    /// Edge types it emits or rewrites
+    pub edge_types: &'static [EdgeType],
+    /// Feature names
+    pub features: &'static [&'static str],
+}
+
+/// Syntax walkers and passes beyond the embedded queries
+pub const ENRICHMENT_PASS_CAPABILITY_LIST: &[EnrichmentPassCapabilityEntry] = &[
+    EnrichmentPassCapabilityEntry {
+        pass: "kotlin_syntax_tree_walker",
+        languages: &["kotlin"],
+        node_kinds: &["class", "enum", "fn", "interface", "method"],
+        edge_types: &[EdgeType::Calls, EdgeType::Implements, EdgeType::Uses],
+        features: &["imports", "inheritance"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "c_include_header_resolver",
+        languages: &["c", "cpp"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Calls, EdgeType::Includes],
+        features: &["include-resolution"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "c_include_header_resolver",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Includes],
+        features: &["cgo-includes"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "config_file_reference_linker",
+        languages: &["c", "cpp", "csharp", "go", "java", "javascript", "php", "python", "ruby", "rust", "typescript"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::ConfiguredBy],
+        features: &["config-references"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "config_file_reference_linker",
+        languages: &["json", "yaml"],
+        node_kinds: &["var"],
+        edge_types: &[],
+        features: &["config-settings"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "embedded_sql_table_linker",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::QueriesTable],
+        features: &["embedded-sql"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "embedded_sql_table_linker",
+        languages: &["sql"],
+        node_kinds: &["table", "view"],
+        edge_types: &[],
+        features: &["sql-schema"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "ffi_symbol_bridge_linker",
+        languages: &["c", "cpp", "go", "rust"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Calls],
+        features: &["ffi-bridges"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_channel_operation_extractor",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::SendsTo, EdgeType::ReceivesFrom],
+        features: &["channels"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_closure_literal_extractor",
+        languages: &["go"],
+        node_kinds: &["fn"],
+        edge_types: &[EdgeType::Calls],
+        features: &["closures"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_error_propagation_extractor",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::PropagatesErrorTo],
+        features: &["error-flow"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_field_access_extractor",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Reads, EdgeType::Writes],
+        features: &["field-access"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_generate_lineage_tracker",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::GeneratedFrom],
+        features: &["go-generate"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_package_value_extractor",
+        languages: &["go"],
+        node_kinds: &["const", "var"],
+        edge_types: &[EdgeType::Uses],
+        features: &["package-values"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_test_coverage_linker",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Tests],
+        features: &["test-links"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "go_type_reference_extractor",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Uses],
+        features: &["type-references"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "http_route_handler_mapper",
+        languages: &["go", "rust"],
+        node_kinds: &["route"],
+        edge_types: &[EdgeType::Calls],
+        features: &["http-routes"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "javascript_module_import_resolver",
+        languages: &["javascript", "typescript"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Calls],
+        features: &["import-resolution"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "jvm_type_hierarchy_resolver",
+        languages: &["java", "kotlin"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Implements],
+        features: &["type-hierarchy"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "python_import_call_resolver",
+        languages: &["python"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Calls, EdgeType::Implements],
+        features: &["import-resolution"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "rust_macro_symbol_attributor",
+        languages: &["rust"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Implements],
+        features: &["macros"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "rust_trait_impl_bound_extractor",
+        languages: &["rust"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Implements, EdgeType::Bound],
+        features: &["inheritance", "trait-bounds"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "struct_tag_serialization_mapper",
+        languages: &["go", "rust"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Serializes],
+        features: &["serialization-tags"],
+    },
+    EnrichmentPassCapabilityEntry {
+        pass: "structural_interface_matcher",
+        languages: &["go"],
+        node_kinds: &[],
+        edge_types: &[EdgeType::Implements],
+        features: &["implicit-interfaces"],
+    },
+];
+
+/// Capabilities of one language's extractors
+///
+/// # 4-Word Name: LanguageExtractorCapabilityEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct LanguageExtractorCapabilityEntry {
+    /// Language display name (`go`, `rust`, ...)
+    pub language: String,
+    /// `tree-sitter-query`, `syntax-tree-walker` or `enrichment-only`
+    pub extractor: String,
+    /// `{parseltongue version}+{fingerprint}`
+    pub extractor_version: String,
+    /// ABI version of the tree-sitter grammar, None without a grammar
+    #[serde(default)]
+    pub grammar_abi_version: Option<usize>,
+    #[serde(default)]
+    pub node_kinds: Vec<String>,
+    #[serde(default)]
+    pub edge_types: Vec<String>,
+    #[serde(default)]
+    pub features: Vec<String>,
+    #[serde(default)]
+    pub passes: Vec<String>,
+}
+
+/// Capabilities of every language, as stored with a graph
+///
+/// # 4-Word Name: ExtractorCapabilityManifestRecord
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ExtractorCapabilityManifestRecord {
+    pub format_version: u32,
+    pub parseltongue_version: String,
+    pub languages: Vec<LanguageExtractorCapabilityEntry>,
+}
+
+impl ExtractorCapabilityManifestRecord {
+    /// Entry of one language by display name
+    ///
+    /// # 4-Word Name: language_entry
+    pub fn language_entry(&self, language: &str) -> Option<&LanguageExtractorCapabilityEntry> {
+        self.languages.iter().find(|entry| entry.language == language)
+    }
+}
+
+/// Capabilities one side of a manifest pair lacks for a language
+///
+/// # 4-Word Name: LanguageCapabilityDriftEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct LanguageCapabilityDriftEntry {
+    pub language: String,
+    /// None when the graph's extractors did not know the language
+    pub graph_version: Option<String>,
+    /// None when the installed extractors no longer know the language
+    pub installed_version: Option<String>,
+    /// `node:kind`, `edge:Type`, `feature:name` installed but not in the graph
+    pub missing_in_graph: Vec<String>,
+    /// Same, present when the graph was built but no longer installed
+    pub missing_in_installed: Vec<String>,
+}
+
+/// Describe what `extractor` and the enrichment passes support
+///
+/// # 4-Word Name: describe_extractor_capabilities
+///
+/// # Contract
+/// - Languages in [`DESCRIBED_LANGUAGE_LIST`] order; a language without a
+///   query, walker or pass is left out
+/// - Lists sorted and deduplicated; equal inputs give equal versions
+pub fn describe_extractor_capabilities(extractor: &QueryBasedExtractor) -> ExtractorCapabilityManifestRecord {
+    let mut languages = Vec::new();
+    for &language in DESCRIBED_LANGUAGE_LIST {
+        let name = language.to_string();
+        let (entity_query, dependency_query) = extractor.embedded_query_texts_for(language);
+        let grammar_abi_version = extractor.grammar_abi_version_for(language);
+        let passes: Vec<&EnrichmentPassCapabilityEntry> = ENRICHMENT_PASS_CAPABILITY_LIST
+            .iter()
+            .filter(|pass| pass.languages.contains(&name.as_str()))
+            .collect();
+        let extractor_kind = match (entity_query, language) {
+            (Some(_), _) => "tree-sitter-query",
+            (None, Language::Kotlin) => "syntax-tree-walker",
+            (None, _) if !passes.is_empty() => "enrichment-only",
+            _ => continue,
+        };
+
+        let entity_captures = collect_query_capture_names(entity_query.unwrap_or(""));
+        let dependency_captures = collect_query_capture_names(dependency_query.unwrap_or(""));
+
+        let mut node_kinds: BTreeSet<String> = entity_captures
+            .iter()
+            .filter_map(|capture| entity_type_of_definition_capture(capture))
+            .map(|entity_type| key_component_for_entity_type(&entity_type).to_string())
+            .collect();
+        let mut edge_types: BTreeSet<&'static str> = dependency_captures
+            .iter()
+            .filter(|capture| capture.starts_with("dependency."))
+            .filter_map(|capture| classify_dependency_capture_edge_type(capture))
+            .map(EdgeType::as_str)
+            .collect();
+        let mut features: BTreeSet<String> = CAPTURE_FEATURE_RULE_LIST
+            .iter()
+            .filter(|(_, fragments)| {
+                entity_captures
+                    .iter()
+                    .chain(dependency_captures.iter())
+                    .any(|capture| fragments.iter().any(|fragment| capture.contains(fragment)))
+            })
+            .map(|(feature, _)| feature.to_string())
+            .collect();
+        for pass in &passes {
+            node_kinds.extend(pass.node_kinds.iter().map(|kind| kind.to_string()));
+            edge_types.extend(pass.edge_types.iter().map(|edge_type| edge_type.as_str()));
+            features.extend(pass.features.iter().map(|feature| feature.to_string()));
+        }
+        for (edge_type, feature) in [
+            (EdgeType::Implements, "inheritance"),
+            (EdgeType::Embeds, "embedding"),
+            (EdgeType::Spawns, "goroutines"),
+        ] {
+            if edge_types.contains(edge_type.as_str()) {
+                features.insert(feature.to_string());
+            }
+        }
+
+        let fingerprint_input = format!(
+            "{}\n{}\n{:?}\n{:?}",
+            entity_query.unwrap_or(""),
+            dependency_query.unwrap_or(""),
+            grammar_abi_version,
+            passes
+        );
+        let fingerprint = compute_content_hash(&fingerprint_input);
+        let mut pass_names: Vec<String> = passes.iter().map(|pass| pass.pass.to_string()).collect();
+        pass_names.sort();
+        pass_names.dedup();
+
+        languages.push(LanguageExtractorCapabilityEntry {
+            language: name,
+            extractor: extractor_kind.to_string(),
+            extractor_version: format!("{}+{}", env!("CARGO_PKG_VERSION"), &fingerprint[..8]),
+            grammar_abi_version,
+            node_kinds: node_kinds.into_iter().collect(),
+            edge_types: edge_types.into_iter().map(str::to_string).collect(),
+            features: features.into_iter().collect(),
+            passes: pass_names,
+        });
+    }
+
+    ExtractorCapabilityManifestRecord {
+        format_version: CAPABILITY_MANIFEST_FORMAT_VERSION,
+        parseltongue_version: env!("CARGO_PKG_VERSION").to_string(),
+        languages,
+    }
+}
+
+/// Manifest of the extractors compiled into this binary
+///
+/// # 4-Word Name: build_installed_capability_manifest
+pub fn build_installed_capability_manifest() -> crate::error::Result<ExtractorCapabilityManifestRecord> {
+    let extractor = QueryBasedExtractor::new().map_err(|e| ParseltongError::ParseError {
+        reason: e.to_string(),
+        location: "extractor initialisation".to_string(),
+    })?;
+    Ok(describe_extractor_capabilities(&extractor))
+}
+
+/// Per-language capability differences between a graph and this binary
+///
+/// # 4-Word Name: diff_capability_manifest_pair
+///
+/// # Contract
+/// - Only languages whose `extractor_version` differs (or that one side
+///   lacks) are listed, sorted by language
+/// - A differing version with identical capability lists still yields an
+///   entry with empty lists: the queries changed, the results may too
+pub fn diff_capability_manifest_pair(
+    graph: &ExtractorCapabilityManifestRecord,
+    installed: &ExtractorCapabilityManifestRecord,
+) -> Vec<LanguageCapabilityDriftEntry> {
+    type EntryPair<'a> = (Option<&'a LanguageExtractorCapabilityEntry>, Option<&'a LanguageExtractorCapabilityEntry>);
+    let mut by_language: BTreeMap<&str, EntryPair> = BTreeMap::new();
+    for entry in &graph.languages {
+        by_language.entry(entry.language.as_str()).or_default().0 = Some(entry);
+    }
+    for entry in &installed.languages {
+        by_language.entry(entry.language.as_str()).or_default().1 = Some(entry);
+    }
+
+    by_language
+        .into_iter()
+        .filter(|(_, (graph_entry, installed_entry))| {
+            graph_entry.map(|e| &e.extractor_version) != installed_entry.map(|e| &e.extractor_version)
+        })
+        .map(|(language, (graph_entry, installed_entry))| {
+            let graph_items = capability_item_set_of(graph_entry);
+            let installed_items = capability_item_set_of(installed_entry);
+            LanguageCapabilityDriftEntry {
+                language: language.to_string(),
+                graph_version: graph_entry.map(|e| e.extractor_version.clone()),
+                installed_version: installed_entry.map(|e| e.extractor_version.clone()),
+                missing_in_graph: installed_items.difference(&graph_items).cloned().collect(),
+                missing_in_installed: graph_items.difference(&installed_items).cloned().collect(),
+            }
+        })
+        .collect()
+}
+
+fn capability_item_set_of(entry: Option<&LanguageExtractorCapabilityEntry>) -> BTreeSet<String> {
+    let Some(entry) = entry else {
+        return BTreeSet::new();
+    };
+    entry
+        .node_kinds
+        .iter()
+        .map(|kind| format!("node:{}", kind))
+        .chain(entry.edge_types.iter().map(|edge_type| format!("edge:{}", edge_type)))
+        .chain(entry.features.iter().map(|feature| format!("feature:{}", feature)))
+        .collect()
+}
+
+/// `@capture` names of a query, comment lines skipped
+fn collect_query_capture_names(query: &str) -> BTreeSet<String> {
+    let mut captures = BTreeSet::new();
+    for line in query.lines().filter(|line| !line.trim_start().starts_with(';')) {
+        let mut rest = line;
+        while let Some(at) = rest.find('@') {
+            let tail = &rest[at + 1..];
+            let end = tail
+                .find(|c: char| !(c.is_ascii_alphanumeric() || c == '_' || c == '.'))
+                .unwrap_or(tail.len());
+            if end > 0 {
+                captures.insert(tail[..end].to_string());
+            }
+            rest = &tail[end..];
+        }
+    }
+    captures
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_installed_manifest_reports_query_and_pass_capabilities() {
+        let manifest = build_installed_capability_manifest().unwrap();
+        assert_eq!(manifest.format_version, CAPABILITY_MANIFEST_FORMAT_VERSION);
+
+        let go = manifest.language_entry("go").unwrap();
+        assert_eq!(go.extractor, "tree-sitter-query");
+        assert!(go.grammar_abi_version.is_some());
+        assert!(go.features.contains(&"generics".to_string()));
+        assert!(go.edge_types.contains(&"Embeds".to_string()));
+        assert!(go.node_kinds.contains(&"route".to_string()));
+        assert!(go.extractor_version.starts_with(&format!("{}+", env!("CARGO_PKG_VERSION"))));
+
+        let c = manifest.language_entry("c").unwrap();
+        assert!(!c.features.contains(&"generics".to_string()));
+        assert!(c.features.contains(&"imports".to_string()));
+        assert!(c.edge_types.contains(&"Includes".to_string()));
+
+        let rust = manifest.language_entry("rust").unwrap();
+        assert!(rust.features.contains(&"macros".to_string()));
+        assert!(rust.node_kinds.contains(&"trait".to_string()));
+
+        let kotlin = manifest.language_entry("kotlin").unwrap();
+        assert_eq!(kotlin.extractor, "syntax-tree-walker");
+        assert_eq!(manifest.language_entry("sql").unwrap().extractor, "enrichment-only");
+        assert!(manifest.language_entry("scala").is_none());
+    }
+
+    #[test]
+    fn test_manifest_diff_lists_capabilities_of_changed_languages() {
+        let installed = build_installed_capability_manifest().unwrap();
+        assert!(diff_capability_manifest_pair(&installed, &installed).is_empty());
+
+        let mut graph = installed.clone();
+        let go = graph.languages.iter_mut().find(|entry| entry.language == "go").unwrap();
+        go.extractor_version = "1.7.1+00000000".to_string();
+        go.features.retain(|feature| feature != "generics");
+        graph.languages.retain(|entry| entry.language != "swift");
+
+        let drift = diff_capability_manifest_pair(&graph, &installed);
+        assert_eq!(drift.len(), 2);
+        assert_eq!(drift[0].language, "go");
+        assert_eq!(drift[0].missing_in_graph, vec!["feature:generics".to_string()]);
+        assert!(drift[0].missing_in_installed.is_empty());
+        assert_eq!(drift[1].language, "swift");
+        assert!(drift[1].graph_version.is_none());
+        assert!(!drift[1].missing_in_graph.is_empty());
+    }
+}
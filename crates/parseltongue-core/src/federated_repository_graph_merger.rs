@@ -0,0 +1,306 @@
+//! Federated Repository Graph Merger (v1.7.3)
+//!
+//! # 4-Word Naming: federated_repository_graph_merger
+//!
+//! Joins the indexes of several repositories (a service and the shared
+//! client library it imports, or a whole org) into one graph for
+//! `parseltongue federate`, so blast-radius, path and context questions
+//! cross repository boundaries.
+//!
+//! In each repository's own index, calls into another one end in
+//! placeholders: an external Go target qualified by its import path
+//! (`go:fn:github.com/acme/client/auth.NewToken:unresolved-reference:0-0`)
+//! or a Rust `external-dependency-{crate}` key. The merge binds them to the
+//! public symbols of the repository that publishes that module, using the
+//! `workspace_module` tags every ingest writes (go.mod `module` path, Cargo
+//! package name):
+//!
+//! - Go: the longest published module path prefixing the import path; the
+//!   rest is the package directory under the module root. Only exported
+//!   symbols bind
+//! - Rust: the crate name (`-` and `_` alike); only `pub` items bind
+//!
+//! A placeholder binds only when exactly one symbol qualifies (the shallowest
+//! matching package directory wins for Go). Bound edges get
+//! `resolution: federated`, `federated_repo` and the placeholder key in
+//! `unresolved_target`; `external` is dropped since the target is now in
+//! the graph. Placeholder entities nothing points at any more are removed.
+//!
+//! ## Identity
+//!
+//! Entities are tagged `repo` with their repository name. Keys embed file
+//! paths as walked, so two repositories ingested the same way can produce
+//! the same key; the first repository's entity wins and the key is reported
+//! as a collision.
+
+use std::collections::{HashMap, HashSet};
+use std::path::Component;
+
+use crate::entities::{CodeEntity, DependencyEdge, Isgl1Key, Visibility};
+use crate::filtered_graph_traversal_queries::is_placeholder_target_key;
+use crate::go_embedding_promotion_resolver::UNRESOLVED_TARGET_METADATA_KEY;
+use crate::go_module_version_resolver::EXTERNAL_METADATA_KEY;
+use crate::workspace_module_root_detector::{WORKSPACE_MODULE_KIND_METADATA_KEY, WORKSPACE_MODULE_METADATA_KEY};
+
+/// Entity metadata key: name of the federated repository the entity comes from
+pub const REPOSITORY_METADATA_KEY: &str = "repo";
+
+/// Edge metadata key: repository a federated edge was bound into
+pub const FEDERATED_REPO_METADATA_KEY: &str = "federated_repo";
+
+/// `resolution` value of edges bound by the merge
+pub const FEDERATED_RESOLUTION_METADATA_VALUE: &str = "federated";
+
+/// One repository's stored graph
+///
+/// # 4-Word Name: FederatedRepositoryGraphPart
+#[derive(Debug, Clone, Default)]
+pub struct FederatedRepositoryGraphPart {
+    /// Registered name (`billing-service`, `client-lib`)
+    pub name: String,
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+}
+
+/// Merged graph and what the merge did
+///
+/// # 4-Word Name: FederatedRepositoryMergeResult
+#[derive(Debug, Clone, Default)]
+pub struct FederatedRepositoryMergeResult {
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    /// Placeholder edges bound to another repository's symbol
+    pub cross_repo_edge_count: usize,
+    /// Keys defined by more than one repository (first one kept), sorted
+    pub key_collisions: Vec<String>,
+}
+
+/// Union repository graphs and bind cross-repository placeholders
+///
+/// # 4-Word Name: federate_repository_graph_parts
+///
+/// # Contract
+/// - Precondition: each part was ingested on its own (entities carry
+///   `workspace_module` tags when the repository has a manifest)
+/// - Postcondition: entities by key and edges by (from, to, type) are
+///   unique; every non-placeholder entity carries `repo`; bound edges
+///   follow the module docs, unbound placeholders are left as they were
+pub fn federate_repository_graph_parts(parts: Vec<FederatedRepositoryGraphPart>) -> FederatedRepositoryMergeResult {
+    let mut result = FederatedRepositoryMergeResult::default();
+    let mut entity_keys: HashSet<String> = HashSet::new();
+    let mut collisions: HashSet<String> = HashSet::new();
+    let mut raw_edges = Vec::new();
+
+    for part in parts {
+        for mut entity in part.entities {
+            let placeholder = is_federation_placeholder_key(&entity.isgl1_key);
+            if !entity_keys.insert(entity.isgl1_key.clone()) {
+                if !placeholder {
+                    collisions.insert(entity.isgl1_key.clone());
+                }
+                continue;
+            }
+            if !placeholder {
+                entity.metadata.additional.insert(REPOSITORY_METADATA_KEY.to_string(), part.name.clone());
+            }
+            result.entities.push(entity);
+        }
+        raw_edges.extend(part.edges);
+    }
+
+    let index = PublishedModuleSymbolIndex::build_from_entities(&result.entities);
+    let mut edge_keys: HashSet<(String, String, String)> = HashSet::new();
+    for mut edge in raw_edges {
+        let bound = index
+            .resolve_placeholder_target(edge.to_key.as_str())
+            .and_then(|(target_key, repo)| Some((Isgl1Key::new(target_key).ok()?, repo)));
+        if let Some((target_key, repo)) = bound {
+            edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+            edge.to_key = target_key;
+            edge.metadata.remove(EXTERNAL_METADATA_KEY);
+            edge.metadata.insert("resolution".to_string(), FEDERATED_RESOLUTION_METADATA_VALUE.to_string());
+            edge.metadata.insert(FEDERATED_REPO_METADATA_KEY.to_string(), repo);
+            result.cross_repo_edge_count += 1;
+        }
+        let key = (
+            edge.from_key.as_str().to_string(),
+            edge.to_key.as_str().to_string(),
+            edge.edge_type.as_str().to_string(),
+        );
+        if edge_keys.insert(key) {
+            result.edges.push(edge);
+        }
+    }
+
+    let referenced: HashSet<&str> =
+        result.edges.iter().flat_map(|e| [e.from_key.as_str(), e.to_key.as_str()]).collect();
+    let orphaned: HashSet<String> = result
+        .entities
+        .iter()
+        .filter(|e| is_federation_placeholder_key(&e.isgl1_key) && !referenced.contains(e.isgl1_key.as_str()))
+        .map(|e| e.isgl1_key.clone())
+        .collect();
+    result.entities.retain(|e| !orphaned.contains(&e.isgl1_key));
+
+    result.key_collisions = collisions.into_iter().collect();
+    result.key_collisions.sort();
+    result
+}
+
+/// Public symbols by (manifest kind, module), for placeholder binding
+struct PublishedModuleSymbolIndex<'a> {
+    /// (kind, module) -> entities of that module
+    modules: HashMap<(&'a str, String), Vec<&'a CodeEntity>>,
+}
+
+impl<'a> PublishedModuleSymbolIndex<'a> {
+    fn build_from_entities(entities: &'a [CodeEntity]) -> Self {
+        let mut modules: HashMap<(&str, String), Vec<&CodeEntity>> = HashMap::new();
+        for entity in entities.iter().filter(|e| !is_federation_placeholder_key(&e.isgl1_key)) {
+            let additional = &entity.metadata.additional;
+            let (Some(kind), Some(module)) =
+                (additional.get(WORKSPACE_MODULE_KIND_METADATA_KEY), additional.get(WORKSPACE_MODULE_METADATA_KEY))
+            else {
+                continue;
+            };
+            let module = if kind.as_str() == "cargo" { module.replace('-', "_") } else { module.clone() };
+            modules.entry((kind.as_str(), module)).or_default().push(entity);
+        }
+        Self { modules }
+    }
+
+    /// `(entity key, repo)` a placeholder binds to, when exactly one qualifies
+    fn resolve_placeholder_target(&self, key: &str) -> Option<(String, String)> {
+        let parts: Vec<&str> = key.split(':').collect();
+        let target = match parts.as_slice() {
+            ["go", _, name, "unresolved-reference", _] => self.resolve_go_qualified_symbol(name),
+            ["rust", _, name, location, _] => {
+                let crate_name = location.strip_prefix("external-dependency-")?.replace('-', "_");
+                let mut found = self
+                    .modules
+                    .get(&("cargo", crate_name))?
+                    .iter()
+                    .filter(|e| e.interface_signature.name.as_str() == *name)
+                    .filter(|e| e.interface_signature.visibility == Visibility::Public);
+                let only = found.next()?;
+                found.next().is_none().then_some(*only)
+            }
+            _ => None,
+        }?;
+        let repo = target.metadata.additional.get(REPOSITORY_METADATA_KEY).cloned().unwrap_or_default();
+        Some((target.isgl1_key.clone(), repo))
+    }
+
+    /// `github.com/acme/client/auth.NewToken` -> exported `NewToken` in `auth/`
+    fn resolve_go_qualified_symbol(&self, qualified: &str) -> Option<&'a CodeEntity> {
+        let package_start = qualified.rfind('/').map_or(0, |slash| slash + 1);
+        let dot = package_start + qualified[package_start..].find('.')?;
+        let (import_path, name) = (&qualified[..dot], &qualified[dot + 1..]);
+        let name = name.rsplit('.').next().unwrap_or(name);
+        if !name.starts_with(|c: char| c.is_uppercase()) {
+            return None;
+        }
+
+        let (module, members) = self
+            .modules
+            .iter()
+            .filter(|((kind, module), _)| {
+                *kind == "go"
+                    && (import_path == module.as_str()
+                        || import_path.strip_prefix(module.as_str()).is_some_and(|rest| rest.starts_with('/')))
+            })
+            .max_by_key(|((_, module), _)| module.len())?;
+        let package: Vec<&str> =
+            import_path[module.1.len()..].split('/').filter(|part| !part.is_empty()).collect();
+
+        let mut candidates: Vec<(usize, &CodeEntity)> = members
+            .iter()
+            .filter(|e| e.interface_signature.name.as_str() == name)
+            .filter_map(|e| {
+                let directory: Vec<String> = e
+                    .interface_signature
+                    .file_path
+                    .parent()
+                    .map(|parent| {
+                        parent
+                            .components()
+                            .filter_map(|c| match c {
+                                Component::Normal(part) => Some(part.to_string_lossy().to_string()),
+                                _ => None,
+                            })
+                            .collect()
+                    })
+                    .unwrap_or_default();
+                let in_package = directory.len() >= package.len()
+                    && directory[directory.len() - package.len()..].iter().zip(&package).all(|(a, b)| a.as_str() == *b);
+                in_package.then_some((directory.len(), *e))
+            })
+            .collect();
+        let shallowest = candidates.iter().map(|(depth, _)| *depth).min()?;
+        candidates.retain(|(depth, _)| *depth == shallowest);
+        match candidates.as_slice() {
+            [(_, only)] => Some(*only),
+            _ => None,
+        }
+    }
+}
+
+fn is_federation_placeholder_key(key: &str) -> bool {
+    is_placeholder_target_key(key) || key.contains(":external-dependency-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_service_calls_bind_into_client_library() {
+        let module = [("workspace_module", "github.com/acme/client"), ("workspace_module_kind", "go")];
+        let library = FederatedRepositoryGraphPart {
+            name: "client-lib".to_string(),
+            entities: vec![
+                create_go_test_entity("go:fn:NewToken:__auth:T1", "NewToken", EntityType::Function, "auth/token.go", &module),
+                create_go_test_entity("go:fn:NewToken:__internal_auth:T2", "NewToken", EntityType::Function, "internal/auth/token.go", &module),
+                create_go_test_entity("go:fn:Dial:__root:T3", "Dial", EntityType::Function, "client.go", &module),
+            ],
+            edges: Vec::new(),
+        };
+        let placeholder = "go:fn:github.com/acme/client/auth.NewToken:unresolved-reference:0-0";
+        let call = |to: &str| {
+            DependencyEdge::builder()
+                .from_key("go:fn:Charge:__billing:T9")
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+                .metadata_entry("external", "true")
+                .build()
+                .unwrap()
+        };
+        let service = FederatedRepositoryGraphPart {
+            name: "billing".to_string(),
+            entities: vec![
+                create_go_test_entity("go:fn:Charge:__billing:T9", "Charge", EntityType::Function, "billing/charge.go", &[]),
+                create_go_test_entity(placeholder, "NewToken", EntityType::Function, "", &[]),
+                create_go_test_entity("go:fn:Dial:__root:T3", "Dial", EntityType::Function, "client.go", &[]),
+            ],
+            edges: vec![
+                call(placeholder),
+                call("go:fn:github.com/acme/client.Dial:unresolved-reference:0-0"),
+                call("go:fn:github.com/other/lib.Open:unresolved-reference:0-0"),
+            ],
+        };
+
+        let merged = federate_repository_graph_parts(vec![library, service]);
+        assert_eq!(merged.cross_repo_edge_count, 2);
+        assert_eq!(merged.edges[0].to_key.as_str(), "go:fn:NewToken:__auth:T1", "shallowest auth/ package wins");
+        assert_eq!(merged.edges[0].metadata_value("federated_repo"), Some("client-lib"));
+        assert_eq!(merged.edges[0].metadata_value("external"), None);
+        assert_eq!(merged.edges[1].to_key.as_str(), "go:fn:Dial:__root:T3");
+        assert_eq!(merged.edges[2].metadata_value("external"), Some("true"), "unpublished module stays external");
+        assert_eq!(merged.key_collisions, vec!["go:fn:Dial:__root:T3".to_string()]);
+        assert!(!merged.entities.iter().any(|e| e.isgl1_key.as_str() == placeholder), "orphaned placeholder removed");
+        let charge = merged.entities.iter().find(|e| e.interface_signature.name == "Charge").unwrap();
+        assert_eq!(charge.metadata.additional.get(REPOSITORY_METADATA_KEY).map(String::as_str), Some("billing"));
+    }
+}
@@ -0,0 +1,277 @@
+//! Cross-Language FFI Bridge Linker (v1.7.3)
+//!
+//! # 4-Word Naming: ffi_symbol_bridge_linker
+//!
+//! A Go function calling `C.deflate(...)` and a Rust function calling a
+//! function declared in `extern "C" { ... }` both reach code the graph has,
+//! but under another language's key, so their call chains stop at the
+//! boundary. This cross-file pass joins them through the linker's view: one
+//! flat namespace of exported symbols.
+//!
+//! ## Exported symbols
+//!
+//! - C functions, by name (`static` ones are file-local and skipped); C++
+//!   free functions by their source name, as if declared `extern "C"`
+//! - Rust functions with `#[no_mangle]` (symbol: the name) or
+//!   `#[export_name = "..."]` (symbol: the given name)
+//! - Go functions marked `//export Name`
+//!
+//! ## Bridges
+//!
+//! - cgo: Go `Calls` edges to `go:fn:C.{symbol}:unresolved-reference:0-0`
+//!   point at the symbol's definition
+//! - Rust: every `extern` block declaration (`boundary: ffi_import`) gets a
+//!   `Calls` edge to the definition of its symbol (`#[link_name = "..."]`,
+//!   else its name), so callers of the declaration continue into it
+//! - Callbacks: C/C++ calls left unresolved by `c_include_header_resolver`
+//!   point at the Rust or Go export of that symbol when no C/C++ function
+//!   defines it
+//!
+//! Bridged edges carry `ffi_bridge` (`go_to_c`, `rust_to_c`, `c_to_rust`,
+//! `c_to_go`, ...), `ffi_symbol` and `resolution: ffi_symbol`; rewritten
+//! ones keep `unresolved_target`. A symbol defined more than once in the
+//! candidate languages stays unbridged. C++ mangling and overloads are not
+//! modelled.
+
+use std::collections::HashMap;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{parse_unresolved_reference_name, UNRESOLVED_TARGET_METADATA_KEY};
+use crate::go_import_alias_resolver::IMPORT_PATH_METADATA_KEY;
+use crate::safety_boundary_facet_tagger::{BOUNDARY_FACET_METADATA_KEY, FFI_IMPORT_BOUNDARY_KIND};
+use crate::symbol_doc_signature_extractor::{ATTRIBUTES_METADATA_KEY, SIGNATURE_METADATA_KEY};
+
+/// Edge metadata key: direction of a bridged call (`go_to_c`, `c_to_rust`, ...)
+pub const FFI_BRIDGE_METADATA_KEY: &str = "ffi_bridge";
+
+/// Edge metadata key: linker symbol a bridged call goes through
+pub const FFI_SYMBOL_METADATA_KEY: &str = "ffi_symbol";
+
+/// Import path cgo gives the pseudo-package `C`
+const CGO_PSEUDO_PACKAGE_PATH: &str = "C";
+
+/// Linker symbol a definition exports, None for non-exported entities
+///
+/// # 4-Word Name: ffi_exported_symbol_name
+///
+/// # Contract
+/// - Postcondition: see module docs; Rust `extern` declarations are imports
+///   and export nothing
+pub fn ffi_exported_symbol_name(entity: &CodeEntity) -> Option<String> {
+    let signature = &entity.interface_signature;
+    if signature.entity_type != EntityType::Function {
+        return None;
+    }
+    let metadata = &entity.metadata.additional;
+    let boundary = metadata.get(BOUNDARY_FACET_METADATA_KEY).map(String::as_str).unwrap_or_default();
+    match entity_key_language(entity) {
+        "c" | "cpp" => {
+            let file_local = metadata
+                .get(SIGNATURE_METADATA_KEY)
+                .is_some_and(|s| s.split_whitespace().any(|word| word == "static"));
+            (!file_local).then(|| signature.name.clone())
+        }
+        "rust" if boundary != FFI_IMPORT_BOUNDARY_KIND => {
+            let attributes = metadata.get(ATTRIBUTES_METADATA_KEY)?;
+            quoted_attribute_value(attributes, "export_name")
+                .or_else(|| attributes.contains("no_mangle").then(|| signature.name.clone()))
+        }
+        "go" if boundary.split(',').any(|kind| kind == "cgo_export") => Some(signature.name.clone()),
+        _ => None,
+    }
+}
+
+/// Bridge cgo calls, Rust `extern` declarations and C callbacks to definitions
+///
+/// # 4-Word Name: link_ffi_bridge_edges
+///
+/// # Contract
+/// - Precondition: entities carry `boundary`, `attributes` and `signature`
+///   metadata; C/C++ includes already resolved (`c_include_header_resolver`)
+/// - Postcondition: see module docs; one declaration edge is appended per
+///   bridged Rust `extern` declaration, other bridges rewrite edges in place
+/// - Returns: number of edges rewritten or added
+pub fn link_ffi_bridge_edges(entities: &[CodeEntity], edges: &mut Vec<DependencyEdge>) -> usize {
+    let mut exports: HashMap<String, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities {
+        if let Some(symbol) = ffi_exported_symbol_name(entity) {
+            exports.entry(symbol).or_default().push(entity);
+        }
+    }
+    if exports.is_empty() {
+        return 0;
+    }
+    let entities_by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut bridged = 0;
+
+    for edge in edges.iter_mut().filter(|edge| edge.edge_type == EdgeType::Calls) {
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else { continue };
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+        let (symbol, target) = match entity_key_language(from) {
+            "go" if edge.metadata_value(IMPORT_PATH_METADATA_KEY) == Some(CGO_PSEUDO_PACKAGE_PATH) => {
+                let Some(symbol) = name.strip_prefix("C.") else { continue };
+                (symbol.to_string(), unique_export_outside(&exports, symbol, &["go"]))
+            }
+            "c" | "cpp" => {
+                let defined_natively = exports
+                    .get(name)
+                    .is_some_and(|found| found.iter().any(|e| matches!(entity_key_language(e), "c" | "cpp")));
+                if defined_natively {
+                    continue;
+                }
+                (name.to_string(), unique_export_outside(&exports, name, &["c", "cpp"]))
+            }
+            _ => continue,
+        };
+        let Some(target) = target else { continue };
+        let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) else { continue };
+        edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+        edge.to_key = key;
+        edge.metadata.insert("resolution".to_string(), "ffi_symbol".to_string());
+        edge.metadata.insert(FFI_BRIDGE_METADATA_KEY.to_string(), bridge_direction_label(from, target));
+        edge.metadata.insert(FFI_SYMBOL_METADATA_KEY.to_string(), symbol);
+        bridged += 1;
+    }
+
+    let is_extern_declaration = |e: &&CodeEntity| {
+        let boundary = e.metadata.additional.get(BOUNDARY_FACET_METADATA_KEY);
+        entity_key_language(e) == "rust" && boundary.map(String::as_str) == Some(FFI_IMPORT_BOUNDARY_KIND)
+    };
+    for declaration in entities.iter().filter(is_extern_declaration) {
+        let symbol = declaration
+            .metadata
+            .additional
+            .get(ATTRIBUTES_METADATA_KEY)
+            .and_then(|attributes| quoted_attribute_value(attributes, "link_name"))
+            .unwrap_or_else(|| declaration.interface_signature.name.clone());
+        let Some(target) = unique_export_outside(&exports, &symbol, &[]) else { continue };
+        let edge = DependencyEdge::builder()
+            .from_key(declaration.isgl1_key.clone())
+            .to_key(target.isgl1_key.clone())
+            .edge_type(EdgeType::Calls)
+            .source_location(format!(
+                "{}:{}",
+                declaration.interface_signature.file_path.display(),
+                declaration.interface_signature.line_range.start
+            ))
+            .metadata_entry("resolution", "ffi_symbol")
+            .metadata_entry(FFI_BRIDGE_METADATA_KEY, bridge_direction_label(declaration, target))
+            .metadata_entry(FFI_SYMBOL_METADATA_KEY, symbol)
+            .build();
+        if let Ok(edge) = edge {
+            edges.push(edge);
+            bridged += 1;
+        }
+    }
+    bridged
+}
+
+/// The one export of `symbol` outside `excluded` languages
+fn unique_export_outside<'a>(
+    exports: &HashMap<String, Vec<&'a CodeEntity>>,
+    symbol: &str,
+    excluded: &[&str],
+) -> Option<&'a CodeEntity> {
+    let candidates: Vec<&CodeEntity> = exports
+        .get(symbol)
+        .into_iter()
+        .flatten()
+        .copied()
+        .filter(|e| !excluded.contains(&entity_key_language(e)))
+        .collect();
+    match candidates.as_slice() {
+        [only] => Some(*only),
+        _ => None,
+    }
+}
+
+/// `rust_to_c`, `go_to_c`, `c_to_rust`, ... (C++ counts as C)
+fn bridge_direction_label(from: &CodeEntity, to: &CodeEntity) -> String {
+    let side = |entity: &CodeEntity| match entity_key_language(entity) {
+        "cpp" => "c",
+        other => other,
+    }
+    .to_string();
+    format!("{}_to_{}", side(from), side(to))
+}
+
+fn entity_key_language(entity: &CodeEntity) -> &str {
+    entity.isgl1_key.split(':').next().unwrap_or_default()
+}
+
+/// `"x"` of `#[export_name = "x"]` / `#[unsafe(export_name = "x")]` / `#[link_name = "x"]`
+fn quoted_attribute_value(attributes: &str, name: &str) -> Option<String> {
+    let after = &attributes[attributes.find(name)? + name.len()..];
+    let after = after.trim_start().strip_prefix('=')?.trim_start().strip_prefix('"')?;
+    after.find('"').map(|end| after[..end].to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call(from: &str, to: &str, import_path: Option<&str>) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls);
+        if let Some(path) = import_path {
+            builder = builder.metadata_entry(IMPORT_PATH_METADATA_KEY, path);
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_cgo_rust_extern_and_callbacks_bridged() {
+        let function = |key: &str, name: &str, file: &str, metadata: &[(&str, &str)]| {
+            create_go_test_entity(key, name, EntityType::Function, file, metadata)
+        };
+        let entities = vec![
+            function("c:fn:deflate:__native_zlib:T1", "deflate", "native/zlib.c", &[("signature", "int deflate(int)")]),
+            function("c:fn:helper:__native_zlib:T2", "helper", "native/zlib.c", &[
+                ("signature", "static int helper(void)"),
+            ]),
+            function("c:fn:run_hooks:__native_hooks:T3", "run_hooks", "native/hooks.c", &[]),
+            function("go:fn:Compress:__zip:T4", "Compress", "zip/zip.go", &[("boundary", "cgo")]),
+            function("go:fn:OnFlush:__zip:T5", "OnFlush", "zip/hooks.go", &[("boundary", "cgo_export")]),
+            function("rust:fn:zlib_deflate:__src_ffi:T6", "zlib_deflate", "src/ffi.rs", &[
+                ("boundary", FFI_IMPORT_BOUNDARY_KIND),
+                ("attributes", "#[link_name = \"deflate\"]"),
+            ]),
+            function("rust:fn:on_progress:__src_hooks:T7", "on_progress", "src/hooks.rs", &[
+                ("boundary", "extern_fn"),
+                ("attributes", "#[unsafe(no_mangle)]"),
+            ]),
+            function("rust:fn:callback:__src_hooks:T8", "callback", "src/hooks.rs", &[("boundary", "extern_fn")]),
+        ];
+        assert_eq!(ffi_exported_symbol_name(&entities[1]), None, "static is file-local");
+        assert_eq!(ffi_exported_symbol_name(&entities[5]), None, "extern declarations import");
+        assert_eq!(ffi_exported_symbol_name(&entities[6]).as_deref(), Some("on_progress"));
+        assert_eq!(ffi_exported_symbol_name(&entities[7]), None, "mangled without #[no_mangle]");
+
+        let mut edges = vec![
+            call("go:fn:Compress:__zip:T4", "go:fn:C.deflate:unresolved-reference:0-0", Some("C")),
+            call("go:fn:Compress:__zip:T4", "go:fn:C.free:unresolved-reference:0-0", Some("C")),
+            call("go:fn:Compress:__zip:T4", "go:fn:deflate:unresolved-reference:0-0", None),
+            call("c:fn:run_hooks:__native_hooks:T3", "c:fn:OnFlush:unresolved-reference:0-0", None),
+            call("c:fn:run_hooks:__native_hooks:T3", "c:fn:on_progress:unresolved-reference:0-0", None),
+        ];
+        assert_eq!(link_ffi_bridge_edges(&entities, &mut edges), 4);
+
+        let bridge = |edge: &DependencyEdge| {
+            let direction = edge.metadata_value(FFI_BRIDGE_METADATA_KEY).unwrap_or_default();
+            (edge.to_key.as_str().to_string(), direction.to_string())
+        };
+        assert_eq!(bridge(&edges[0]), ("c:fn:deflate:__native_zlib:T1".to_string(), "go_to_c".to_string()));
+        assert_eq!(
+            edges[0].metadata_value(UNRESOLVED_TARGET_METADATA_KEY),
+            Some("go:fn:C.deflate:unresolved-reference:0-0")
+        );
+        assert_eq!(bridge(&edges[1]).1, "", "C.free is not in the repo");
+        assert_eq!(bridge(&edges[2]).1, "", "plain Go calls are not cgo");
+        assert_eq!(bridge(&edges[3]), ("go:fn:OnFlush:__zip:T5".to_string(), "c_to_go".to_string()));
+        assert_eq!(bridge(&edges[4]), ("rust:fn:on_progress:__src_hooks:T7".to_string(), "c_to_rust".to_string()));
+        let declaration = &edges[5];
+        assert_eq!(declaration.from_key.as_str(), "rust:fn:zlib_deflate:__src_ffi:T6");
+        assert_eq!(bridge(declaration), ("c:fn:deflate:__native_zlib:T1".to_string(), "rust_to_c".to_string()));
+        assert_eq!(declaration.metadata_value(FFI_SYMBOL_METADATA_KEY), Some("deflate"));
+    }
+}
@@ -0,0 +1,674 @@
+//! Edge-type filtered graph traversal queries (v1.7.3)
+//!
+//! # 4-Word Naming: filtered_graph_traversal_queries
+//!
+//! In-memory traversals over `DependencyEdge` lists that respect an
+//! edge-type allow-list (`--edges calls,implements,uses`): blast radius
+//! (reverse closure), shortest dependency path (forward BFS) and bounded
+//! neighborhoods for diagrams (undirected BFS).
+//!
+//! ## Direction
+//!
+//! Every edge `A -> B` reads "A depends on B" (A calls B, A implements B,
+//! A embeds B, A uses B, A spawns B, A sends to / receives from channel B,
+//! A reads / writes field B, test A tests B).
+//! The blast radius of B is therefore found by walking edges BACKWARDS from B.
+//!
+//! ## Unresolved targets
+//!
+//! Calls to symbols the extractor could not resolve point at placeholder keys
+//! (`rust:fn:new:unresolved-reference:0-0`, `...:unknown:0-0`). A changed
+//! entity is treated as the target of placeholders with the same language and
+//! name, mirroring the fuzzy matching of `/blast-radius-impact-analysis`.
+
+use std::collections::{HashMap, HashSet, VecDeque};
+use std::str::FromStr;
+
+use serde::Serialize;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::error::{ParseltongError, Result};
+
+/// Parse a comma-separated edge type list (case-insensitive)
+///
+/// # 4-Word Name: parse_edge_type_filter_list
+///
+/// # Contract
+/// - `"calls,implements"` → `[Calls, Implements]`; `"all"` or `""` → every type
+/// - Unknown names are an error naming the offending entry
+pub fn parse_edge_type_filter_list(spec: &str) -> Result<Vec<EdgeType>> {
+    let spec = spec.trim();
+    if spec.is_empty() || spec.eq_ignore_ascii_case("all") {
+        return Ok(all_edge_type_variants_list());
+    }
+    let mut types = Vec::new();
+    for part in spec.split(',').map(str::trim).filter(|p| !p.is_empty()) {
+        let canonical = all_edge_type_variants_list()
+            .into_iter()
+            .find(|t| t.as_str().eq_ignore_ascii_case(part))
+            .map(|t| t.as_str())
+            .unwrap_or(part);
+        let edge_type = EdgeType::from_str(canonical).map_err(|_| ParseltongError::ValidationError {
+            field: "edges".to_string(),
+            expected: all_edge_type_variants_list()
+                .iter()
+                .map(|t| t.as_str().to_lowercase())
+                .collect::<Vec<_>>()
+                .join(", "),
+            actual: part.to_string(),
+        })?;
+        if !types.contains(&edge_type) {
+            types.push(edge_type);
+        }
+    }
+    Ok(types)
+}
+
+/// Every EdgeType variant
+///
+/// # 4-Word Name: all_edge_type_variants_list
+pub fn all_edge_type_variants_list() -> Vec<EdgeType> {
+    vec![
+        EdgeType::Calls,
+        EdgeType::Uses,
+        EdgeType::Implements,
+        EdgeType::Embeds,
+        EdgeType::Spawns,
+        EdgeType::SendsTo,
+        EdgeType::ReceivesFrom,
+        EdgeType::Reads,
+        EdgeType::Writes,
+        EdgeType::Tests,
+        EdgeType::Bound,
+        EdgeType::PropagatesErrorTo,
+        EdgeType::QueriesTable,
+        EdgeType::Serializes,
+        EdgeType::GeneratedFrom,
+        EdgeType::Includes,
+        EdgeType::ConfiguredBy,
+    ]
+}
+
+/// True for extractor placeholder keys of unresolved targets
+///
+/// # 4-Word Name: is_placeholder_target_key
+pub fn is_placeholder_target_key(key: &str) -> bool {
+    key.contains(":unresolved-reference:") || key.contains(":unknown:")
+}
+
+/// `(language, name)` of an ISGL1 key
+fn language_and_name_of(key: &str) -> Option<(&str, &str)> {
+    let mut parts = key.split(':');
+    let language = parts.next()?;
+    let _entity_type = parts.next()?;
+    Some((language, parts.next()?))
+}
+
+/// Entity affected by a change, with the edge that reached it
+///
+/// # 4-Word Name: AffectedEntityHopEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct AffectedEntityHopEntry {
+    pub entity_key: String,
+    pub depth: usize,
+    /// Edge type of the dependency that pulls this entity in
+    pub via_edge_type: String,
+    /// Entity one hop closer to the changed symbol
+    pub via_entity_key: String,
+}
+
+/// Compute blast radius with an edge-type allow-list
+///
+/// # 4-Word Name: compute_filtered_blast_radius
+///
+/// # Contract
+/// - Precondition: `allowed` non-empty (use `all_edge_type_variants_list`)
+/// - Postcondition: every entity that transitively depends on `changed_key`
+///   via allowed edges within `max_depth` hops, each once at its minimum
+///   depth; sorted by (depth, key). The changed entity itself is excluded.
+/// - Performance: O(V + E)
+pub fn compute_filtered_blast_radius(
+    edges: &[DependencyEdge],
+    changed_key: &str,
+    max_depth: usize,
+    allowed: &[EdgeType],
+) -> Vec<AffectedEntityHopEntry> {
+    // to_key -> incoming edges; placeholder targets indexed by (language, name)
+    let mut incoming: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    let mut placeholder_incoming: HashMap<(&str, &str), Vec<&DependencyEdge>> = HashMap::new();
+    for edge in edges.iter().filter(|e| allowed.contains(&e.edge_type)) {
+        let to_key = edge.to_key.as_str();
+        incoming.entry(to_key).or_default().push(edge);
+        if is_placeholder_target_key(to_key) {
+            if let Some(language_name) = language_and_name_of(to_key) {
+                placeholder_incoming.entry(language_name).or_default().push(edge);
+            }
+        }
+    }
+
+    let mut affected = Vec::new();
+    let mut visited: HashSet<String> = HashSet::new();
+    visited.insert(changed_key.to_string());
+    let mut queue: VecDeque<(String, usize)> = VecDeque::new();
+    queue.push_back((changed_key.to_string(), 0));
+
+    while let Some((key, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        let exact = incoming.get(key.as_str()).into_iter().flatten();
+        let fuzzy = language_and_name_of(&key)
+            .filter(|_| !is_placeholder_target_key(&key))
+            .and_then(|language_name| placeholder_incoming.get(&language_name))
+            .into_iter()
+            .flatten();
+
+        let mut dependents: Vec<&DependencyEdge> = exact.chain(fuzzy).copied().collect();
+        dependents.sort_by(|a, b| a.from_key.as_str().cmp(b.from_key.as_str()));
+        for edge in dependents {
+            let dependent = edge.from_key.as_str();
+            if !visited.insert(dependent.to_string()) {
+                continue;
+            }
+            affected.push(AffectedEntityHopEntry {
+                entity_key: dependent.to_string(),
+                depth: depth + 1,
+                via_edge_type: edge.edge_type.as_str().to_string(),
+                via_entity_key: key.clone(),
+            });
+            queue.push_back((dependent.to_string(), depth + 1));
+        }
+    }
+
+    affected.sort_by(|a, b| (a.depth, &a.entity_key).cmp(&(b.depth, &b.entity_key)));
+    affected
+}
+
+/// One edge on a dependency path
+///
+/// # 4-Word Name: DependencyPathHopEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct DependencyPathHopEntry {
+    pub from_key: String,
+    pub to_key: String,
+    pub edge_type: String,
+    pub source_location: Option<String>,
+    /// Placeholder target that was matched to `to_key` by name (if any)
+    pub via_placeholder_key: Option<String>,
+}
+
+/// Find the shortest dependency path between two entities
+///
+/// # 4-Word Name: find_shortest_dependency_path
+///
+/// # Contract
+/// - Precondition: `target_keys` non-empty; any member ends the search
+///   (callers pass every match of an ambiguous or external target name)
+/// - Postcondition: fewest-hop chain of allowed edges `from_key -> ... -> t`
+///   following edge direction, or None within `max_depth` hops. Ties are
+///   broken by key order for deterministic output. Edges into placeholder
+///   targets continue through same-language entities with that name.
+/// - Performance: O(V + E)
+pub fn find_shortest_dependency_path(
+    edges: &[DependencyEdge],
+    from_key: &str,
+    target_keys: &HashSet<String>,
+    max_depth: usize,
+    allowed: &[EdgeType],
+) -> Option<Vec<DependencyPathHopEntry>> {
+    find_bounded_shortest_dependency_path(edges, from_key, target_keys, max_depth, usize::MAX, allowed).0
+}
+
+/// Find the shortest dependency path, visiting at most `max_nodes` nodes (`--max-nodes`)
+///
+/// # 4-Word Name: find_bounded_shortest_dependency_path
+///
+/// # Contract
+/// - Postcondition: as `find_shortest_dependency_path`, but the search
+///   stops queueing new nodes once `max_nodes` were reached (a target is
+///   still recognised); the second value counts the nodes it turned away
+pub fn find_bounded_shortest_dependency_path(
+    edges: &[DependencyEdge],
+    from_key: &str,
+    target_keys: &HashSet<String>,
+    max_depth: usize,
+    max_nodes: usize,
+    allowed: &[EdgeType],
+) -> (Option<Vec<DependencyPathHopEntry>>, usize) {
+    let mut outgoing: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    let mut entities_by_name: HashMap<(&str, &str), Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| allowed.contains(&e.edge_type)) {
+        outgoing.entry(edge.from_key.as_str()).or_default().push(edge);
+        if let Some(language_name) = language_and_name_of(edge.from_key.as_str()) {
+            entities_by_name.entry(language_name).or_default().push(edge.from_key.as_str());
+        }
+    }
+    for list in outgoing.values_mut() {
+        list.sort_by(|a, b| a.to_key.as_str().cmp(b.to_key.as_str()));
+    }
+    for list in entities_by_name.values_mut() {
+        list.sort_unstable();
+        list.dedup();
+    }
+
+    if target_keys.contains(from_key) {
+        return (Some(Vec::new()), 0);
+    }
+
+    // Parent pointers: node -> hop that first reached it
+    let mut reached_by: HashMap<String, DependencyPathHopEntry> = HashMap::new();
+    let mut turned_away: HashSet<String> = HashSet::new();
+    let mut visited: HashSet<String> = HashSet::new();
+    visited.insert(from_key.to_string());
+    let mut queue: VecDeque<(String, usize)> = VecDeque::new();
+    queue.push_back((from_key.to_string(), 0));
+
+    while let Some((key, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        for edge in outgoing.get(key.as_str()).into_iter().flatten() {
+            let to_key = edge.to_key.as_str();
+            // Placeholder targets step through to the real entities of that name
+            let mut next_nodes: Vec<(&str, Option<&str>)> = vec![(to_key, None)];
+            if is_placeholder_target_key(to_key) {
+                if let Some(real) = language_and_name_of(to_key).and_then(|ln| entities_by_name.get(&ln)) {
+                    next_nodes.extend(real.iter().map(|r| (*r, Some(to_key))));
+                }
+            }
+
+            for (next, placeholder) in next_nodes {
+                if visited.contains(next) {
+                    continue;
+                }
+                // `visited` holds the start, which the budget does not count
+                if visited.len() > max_nodes && !target_keys.contains(next) {
+                    turned_away.insert(next.to_string());
+                    continue;
+                }
+                visited.insert(next.to_string());
+                reached_by.insert(next.to_string(), DependencyPathHopEntry {
+                    from_key: key.clone(),
+                    to_key: next.to_string(),
+                    edge_type: edge.edge_type.as_str().to_string(),
+                    source_location: edge.source_location.clone(),
+                    via_placeholder_key: placeholder.map(str::to_string),
+                });
+                if target_keys.contains(next) {
+                    return (Some(reconstruct_path_from_parents(&reached_by, next)), turned_away.len());
+                }
+                queue.push_back((next.to_string(), depth + 1));
+            }
+        }
+    }
+    (None, turned_away.len())
+}
+
+/// Collect the edges of a bounded neighborhood around a root
+///
+/// # 4-Word Name: collect_bounded_neighborhood_edges
+///
+/// # Contract
+/// - Postcondition: every allowed edge with at least one endpoint within
+///   `max_depth` undirected hops of `root_key` whose other endpoint is also
+///   within that radius; walk stops admitting new nodes at `max_nodes`
+/// - Placeholder targets are matched to the root's (language, name) like
+///   `compute_filtered_blast_radius`; output sorted by (from, to, type)
+pub fn collect_bounded_neighborhood_edges(
+    edges: &[DependencyEdge],
+    root_key: &str,
+    max_depth: usize,
+    max_nodes: usize,
+    allowed: &[EdgeType],
+) -> Vec<DependencyEdge> {
+    let mut adjacent: HashMap<&str, Vec<&str>> = HashMap::new();
+    let mut placeholder_by_name: HashMap<(&str, &str), Vec<&str>> = HashMap::new();
+    let allowed_edges: Vec<&DependencyEdge> = edges.iter().filter(|e| allowed.contains(&e.edge_type)).collect();
+    for edge in &allowed_edges {
+        let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+        adjacent.entry(from).or_default().push(to);
+        adjacent.entry(to).or_default().push(from);
+        if is_placeholder_target_key(to) {
+            if let Some(language_name) = language_and_name_of(to) {
+                placeholder_by_name.entry(language_name).or_default().push(to);
+            }
+        }
+    }
+    for list in adjacent.values_mut() {
+        list.sort_unstable();
+        list.dedup();
+    }
+
+    let mut inside: HashSet<String> = HashSet::new();
+    inside.insert(root_key.to_string());
+    // The root's own placeholders are the same symbol seen from call sites
+    if let Some(aliases) = language_and_name_of(root_key)
+        .filter(|_| !is_placeholder_target_key(root_key))
+        .and_then(|ln| placeholder_by_name.get(&ln))
+    {
+        inside.extend(aliases.iter().map(|k| k.to_string()));
+    }
+    let mut queue: VecDeque<(String, usize)> = inside.iter().map(|k| (k.clone(), 0)).collect();
+
+    while let Some((key, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        for next in adjacent.get(key.as_str()).into_iter().flatten() {
+            if inside.len() >= max_nodes {
+                break;
+            }
+            if inside.insert(next.to_string()) {
+                queue.push_back((next.to_string(), depth + 1));
+            }
+        }
+    }
+
+    let mut selected: Vec<DependencyEdge> = allowed_edges
+        .into_iter()
+        .filter(|e| inside.contains(e.from_key.as_str()) && inside.contains(e.to_key.as_str()))
+        .cloned()
+        .collect();
+    selected.sort_by(|a, b| {
+        (a.from_key.as_str(), a.to_key.as_str(), a.edge_type.as_str())
+            .cmp(&(b.from_key.as_str(), b.to_key.as_str(), b.edge_type.as_str()))
+    });
+    selected.dedup();
+    selected
+}
+
+/// Entity that runs on a goroutine started downstream of a root
+///
+/// # 4-Word Name: ConcurrentReachEntityEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ConcurrentReachEntityEntry {
+    pub entity_key: String,
+    pub depth: usize,
+    /// Entity whose `go` statement started the goroutine
+    pub spawned_by_key: String,
+    /// Where that `go` statement is (`path:line`)
+    pub spawn_location: Option<String>,
+}
+
+/// Collect everything that runs concurrently downstream of a root
+///
+/// # 4-Word Name: collect_concurrent_downstream_entities
+///
+/// # Contract
+/// - Postcondition: entities reachable from `root_key` over Calls/Spawns
+///   within `max_depth` hops through at least one `Spawns` edge, each once at
+///   its minimum depth with the nearest spawn site; sorted by (depth, key)
+/// - Entities only reached synchronously are excluded; placeholder targets
+///   step through to same-language entities with that name, like
+///   `find_shortest_dependency_path`
+/// - Performance: O(V + E)
+pub fn collect_concurrent_downstream_entities(
+    edges: &[DependencyEdge],
+    root_key: &str,
+    max_depth: usize,
+) -> Vec<ConcurrentReachEntityEntry> {
+    let mut outgoing: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    let mut entities_by_name: HashMap<(&str, &str), Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)) {
+        outgoing.entry(edge.from_key.as_str()).or_default().push(edge);
+        if let Some(language_name) = language_and_name_of(edge.from_key.as_str()) {
+            entities_by_name.entry(language_name).or_default().push(edge.from_key.as_str());
+        }
+    }
+    for list in outgoing.values_mut() {
+        list.sort_by(|a, b| a.to_key.as_str().cmp(b.to_key.as_str()));
+    }
+    for list in entities_by_name.values_mut() {
+        list.sort_unstable();
+        list.dedup();
+    }
+
+    // A node is visited at most twice: once synchronously, once on a goroutine
+    let mut visited: HashSet<(String, bool)> = HashSet::new();
+    visited.insert((root_key.to_string(), false));
+    let mut reached = Vec::new();
+    // (key, depth, spawn site that put us on a goroutine)
+    let mut queue: VecDeque<(String, usize, Option<(String, Option<String>)>)> = VecDeque::new();
+    queue.push_back((root_key.to_string(), 0, None));
+
+    while let Some((key, depth, spawn)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        for edge in outgoing.get(key.as_str()).into_iter().flatten() {
+            let spawn_here = if edge.edge_type == EdgeType::Spawns {
+                Some((key.clone(), edge.source_location.clone()))
+            } else {
+                spawn.clone()
+            };
+            let to_key = edge.to_key.as_str();
+            let mut next_nodes = vec![to_key];
+            // Placeholders are reported only when no real entity stands behind them
+            let mut report_placeholder = true;
+            if is_placeholder_target_key(to_key) {
+                if let Some(real) = language_and_name_of(to_key).and_then(|ln| entities_by_name.get(&ln)) {
+                    next_nodes.extend(real.iter().copied());
+                    report_placeholder = false;
+                }
+            }
+            for next in next_nodes {
+                if !visited.insert((next.to_string(), spawn_here.is_some())) {
+                    continue;
+                }
+                if let Some((spawned_by, location)) = &spawn_here {
+                    if report_placeholder || next != to_key {
+                        reached.push(ConcurrentReachEntityEntry {
+                            entity_key: next.to_string(),
+                            depth: depth + 1,
+                            spawned_by_key: spawned_by.clone(),
+                            spawn_location: location.clone(),
+                        });
+                    }
+                }
+                queue.push_back((next.to_string(), depth + 1, spawn_here.clone()));
+            }
+        }
+    }
+
+    reached.sort_by(|a, b| (a.depth, &a.entity_key).cmp(&(b.depth, &b.entity_key)));
+    reached
+}
+
+/// Walk parent pointers back to the start
+fn reconstruct_path_from_parents(
+    reached_by: &HashMap<String, DependencyPathHopEntry>,
+    end_key: &str,
+) -> Vec<DependencyPathHopEntry> {
+    let mut path = Vec::new();
+    let mut current = end_key;
+    while let Some(hop) = reached_by.get(current) {
+        path.push(hop.clone());
+        current = hop.from_key.as_str();
+    }
+    path.reverse();
+    path
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_parse_edge_filter_case_insensitive() {
+        assert_eq!(
+            parse_edge_type_filter_list("calls, IMPLEMENTS,uses").unwrap(),
+            vec![EdgeType::Calls, EdgeType::Implements, EdgeType::Uses]
+        );
+        assert_eq!(parse_edge_type_filter_list("all").unwrap().len(), 15);
+        assert!(parse_edge_type_filter_list("calls,bogus").is_err());
+    }
+
+    #[test]
+    fn test_blast_radius_respects_edge_filter() {
+        let edges = vec![
+            edge("go:fn:Handler:__api:T1", "go:method:Get:__svc:T2", EdgeType::Calls),
+            edge("go:struct:Svc:__svc:T3", "go:trait:Store:__store:T4", EdgeType::Implements),
+            edge("go:method:Get:__svc:T2", "go:trait:Store:__store:T4", EdgeType::Uses),
+        ];
+
+        let calls_only = compute_filtered_blast_radius(&edges, "go:trait:Store:__store:T4", 3, &[EdgeType::Calls]);
+        assert!(calls_only.is_empty());
+
+        let all = compute_filtered_blast_radius(&edges, "go:trait:Store:__store:T4", 3, &all_edge_type_variants_list());
+        let keys: Vec<(&str, usize)> = all.iter().map(|a| (a.entity_key.as_str(), a.depth)).collect();
+        assert_eq!(
+            keys,
+            vec![("go:method:Get:__svc:T2", 1), ("go:struct:Svc:__svc:T3", 1), ("go:fn:Handler:__api:T1", 2)]
+        );
+        assert_eq!(all[2].via_edge_type, "Calls");
+
+        let shallow = compute_filtered_blast_radius(&edges, "go:trait:Store:__store:T4", 1, &all_edge_type_variants_list());
+        assert_eq!(shallow.len(), 2);
+    }
+
+    #[test]
+    fn test_shortest_path_prefers_fewest_hops() {
+        let edges = vec![
+            edge("go:fn:main:__cmd:T1", "go:fn:run:__cmd:T2", EdgeType::Calls),
+            edge("go:fn:run:__cmd:T2", "go:method:Save:__store:T3", EdgeType::Calls),
+            edge("go:method:Save:__store:T3", "go:fn:Query:unresolved-reference:0-0", EdgeType::Calls),
+            edge("go:fn:main:__cmd:T1", "go:fn:Query:unresolved-reference:0-0", EdgeType::Uses),
+        ];
+        let target: HashSet<String> = ["go:fn:Query:unresolved-reference:0-0".to_string()].into_iter().collect();
+
+        let direct = find_shortest_dependency_path(&edges, "go:fn:main:__cmd:T1", &target, 5, &all_edge_type_variants_list()).unwrap();
+        assert_eq!(direct.len(), 1);
+        assert_eq!(direct[0].edge_type, "Uses");
+
+        let calls = find_shortest_dependency_path(&edges, "go:fn:main:__cmd:T1", &target, 5, &[EdgeType::Calls]).unwrap();
+        let chain: Vec<&str> = calls.iter().map(|h| h.to_key.as_str()).collect();
+        assert_eq!(chain, vec!["go:fn:run:__cmd:T2", "go:method:Save:__store:T3", "go:fn:Query:unresolved-reference:0-0"]);
+
+        assert!(find_shortest_dependency_path(&edges, "go:fn:main:__cmd:T1", &target, 2, &[EdgeType::Calls]).is_none());
+
+        let (cut, turned_away) =
+            find_bounded_shortest_dependency_path(&edges, "go:fn:main:__cmd:T1", &target, 5, 1, &[EdgeType::Calls]);
+        assert_eq!((cut, turned_away), (None, 1));
+        let (found, _) =
+            find_bounded_shortest_dependency_path(&edges, "go:fn:main:__cmd:T1", &target, 5, 2, &[EdgeType::Calls]);
+        assert_eq!(found.map(|p| p.len()), Some(3));
+    }
+
+    #[test]
+    fn test_path_steps_through_placeholder_by_name() {
+        let edges = vec![
+            edge("rust:fn:main:src_main_rs:T1", "rust:fn:helper:unresolved-reference:0-0", EdgeType::Calls),
+            edge("rust:fn:helper:src_lib_rs:T2", "rust:fn:leaf:src_lib_rs:T3", EdgeType::Calls),
+        ];
+        let target: HashSet<String> = ["rust:fn:leaf:src_lib_rs:T3".to_string()].into_iter().collect();
+
+        let path = find_shortest_dependency_path(&edges, "rust:fn:main:src_main_rs:T1", &target, 5, &[EdgeType::Calls]).unwrap();
+        assert_eq!(path.len(), 2);
+        assert_eq!(path[0].to_key, "rust:fn:helper:src_lib_rs:T2");
+        assert_eq!(path[0].via_placeholder_key.as_deref(), Some("rust:fn:helper:unresolved-reference:0-0"));
+    }
+
+    #[test]
+    fn test_neighborhood_bounded_by_depth_both_directions() {
+        let edges = vec![
+            edge("go:fn:caller:__a:T1", "go:fn:root:__a:T2", EdgeType::Calls),
+            edge("go:fn:root:__a:T2", "go:fn:callee:__a:T3", EdgeType::Calls),
+            edge("go:fn:callee:__a:T3", "go:fn:far:__a:T4", EdgeType::Calls),
+            edge("go:fn:root:__a:T2", "go:struct:Cfg:__a:T5", EdgeType::Uses),
+        ];
+
+        let one_hop = collect_bounded_neighborhood_edges(&edges, "go:fn:root:__a:T2", 1, 100, &[EdgeType::Calls]);
+        assert_eq!(one_hop.len(), 2);
+        assert!(one_hop.iter().all(|e| e.from_key.as_str() != "go:fn:callee:__a:T3"));
+
+        let two_hops = collect_bounded_neighborhood_edges(&edges, "go:fn:root:__a:T2", 2, 100, &all_edge_type_variants_list());
+        assert_eq!(two_hops.len(), 4);
+
+        let capped = collect_bounded_neighborhood_edges(&edges, "go:fn:root:__a:T2", 2, 2, &all_edge_type_variants_list());
+        assert_eq!(capped.len(), 1);
+    }
+
+    #[test]
+    fn test_placeholder_targets_match_by_name() {
+        let edges = vec![edge("rust:fn:main:src_main_rs:T1", "rust:fn:helper:unresolved-reference:0-0", EdgeType::Calls)];
+
+        let affected = compute_filtered_blast_radius(&edges, "rust:fn:helper:src_lib_rs:T2", 2, &[EdgeType::Calls]);
+        assert_eq!(affected.len(), 1);
+        assert_eq!(affected[0].entity_key, "rust:fn:main:src_main_rs:T1");
+    }
+
+    #[test]
+    fn test_concurrent_downstream_follows_spawns() {
+        let edges = vec![
+            edge("go:fn:Handle:__api:T1", "go:fn:validate:__api:T2", EdgeType::Calls),
+            edge("go:fn:Handle:__api:T1", "go:fn:worker:unresolved-reference:0-0", EdgeType::Spawns),
+            edge("go:fn:Handle:__api:T1", "go:fn:worker:unresolved-reference:0-0", EdgeType::Calls),
+            edge("go:fn:worker:__jobs:T3", "go:fn:flush:__jobs:T4", EdgeType::Calls),
+            edge("go:fn:flush:__jobs:T4", "go:fn:Exec:unresolved-reference:0-0", EdgeType::Calls),
+            edge("go:fn:validate:__api:T2", "go:fn:check:__api:T5", EdgeType::Calls),
+        ];
+
+        let reached = collect_concurrent_downstream_entities(&edges, "go:fn:Handle:__api:T1", 5);
+        let keys: Vec<(&str, usize)> = reached.iter().map(|r| (r.entity_key.as_str(), r.depth)).collect();
+        assert_eq!(
+            keys,
+            vec![("go:fn:worker:__jobs:T3", 1), ("go:fn:flush:__jobs:T4", 2), ("go:fn:Exec:unresolved-reference:0-0", 3)]
+        );
+        assert!(reached.iter().all(|r| r.spawned_by_key == "go:fn:Handle:__api:T1"));
+
+        assert_eq!(collect_concurrent_downstream_entities(&edges, "go:fn:Handle:__api:T1", 1).len(), 1);
+        assert!(collect_concurrent_downstream_entities(&edges, "go:fn:validate:__api:T2", 5).is_empty());
+    }
+
+    #[test]
+    fn test_concurrent_downstream_nested_spawns_and_cycles() {
+        let mut spawn_a = edge("go:fn:a:__app:T2", "go:fn:b:__app:T3", EdgeType::Spawns);
+        spawn_a.source_location = Some("app/a.go:4".to_string());
+        let mut spawn_b = edge("go:fn:b:__app:T3", "go:fn:c:__app:T4", EdgeType::Spawns);
+        spawn_b.source_location = Some("app/b.go:9".to_string());
+        let edges = vec![
+            edge("go:fn:root:__app:T1", "go:fn:a:__app:T2", EdgeType::Calls),
+            edge("go:fn:root:__app:T1", "go:fn:shared:__app:T5", EdgeType::Calls),
+            spawn_a,
+            spawn_b,
+            edge("go:fn:b:__app:T3", "go:fn:shared:__app:T5", EdgeType::Calls),
+            // Cycle back to the root from inside the goroutine
+            edge("go:fn:c:__app:T4", "go:fn:root:__app:T1", EdgeType::Calls),
+            edge("go:fn:a:__app:T2", "go:fn:b:__app:T3", EdgeType::Uses),
+        ];
+
+        let reached = collect_concurrent_downstream_entities(&edges, "go:fn:root:__app:T1", 10);
+        let keys: Vec<(&str, usize)> = reached.iter().map(|r| (r.entity_key.as_str(), r.depth)).collect();
+        assert_eq!(
+            keys,
+            vec![
+                ("go:fn:b:__app:T3", 2),
+                ("go:fn:c:__app:T4", 3),
+                ("go:fn:shared:__app:T5", 3),
+                ("go:fn:root:__app:T1", 4),
+                // Re-entered on the goroutine once, then the cycle stops
+                ("go:fn:a:__app:T2", 5),
+            ]
+        );
+
+        // Each entry names the nearest spawn site, not the first one
+        let c = &reached[1];
+        assert_eq!(c.spawned_by_key, "go:fn:b:__app:T3");
+        assert_eq!(c.spawn_location.as_deref(), Some("app/b.go:9"));
+        let shared = &reached[2];
+        assert_eq!(shared.spawned_by_key, "go:fn:a:__app:T2");
+        assert_eq!(shared.spawn_location.as_deref(), Some("app/a.go:4"));
+
+        assert!(collect_concurrent_downstream_entities(&edges, "go:fn:root:__app:T1", 0).is_empty());
+        assert!(collect_concurrent_downstream_entities(&edges, "go:fn:missing:__app:T9", 10).is_empty());
+    }
+}
@@ -0,0 +1,327 @@
+//! Fuzzy Symbol Name Matcher (v1.7.3)
+//!
+//! # 4-Word Naming: fuzzy_symbol_name_matcher
+//!
+//! Ranks symbols against an abbreviated query such as `usrSvc.creat`, the
+//! way an editor's "go to symbol" does. Each dotted query segment must
+//! match a segment of the symbol's qualified name in order (package
+//! directories, receiver type, name), and the last query segment must match
+//! the name itself. Within a segment the query characters are a
+//! case-insensitive subsequence.
+//!
+//! ## Scoring
+//!
+//! - Every matched character scores; matches at the start of a segment or
+//!   at a camel hump (`User|Service`, `http_|server`, `HTTP|Server`) and
+//!   runs of consecutive matches score extra, skipped characters cost a
+//!   little
+//! - `match_quality` is the score over that of a contiguous match from the
+//!   start of each segment (1.0), capped at 1.0
+//! - `package_proximity` is the share of leading directory components the
+//!   symbol's package has in common with `--near`; it adds up to
+//!   `PACKAGE_PROXIMITY_WEIGHT` to the final score
+
+use std::cmp::Ordering;
+
+use serde::Serialize;
+
+use crate::entities::CodeEntity;
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Score added for a symbol in the `--near` package itself
+pub const PACKAGE_PROXIMITY_WEIGHT: f64 = 0.15;
+
+const MATCH_SCORE: i64 = 16;
+const SEGMENT_START_BONUS: i64 = 8;
+const CAMEL_HUMP_BONUS: i64 = 8;
+const CONSECUTIVE_MATCH_BONUS: i64 = 6;
+const SKIPPED_CHAR_PENALTY: i64 = 1;
+const MAX_GAP_PENALTY: i64 = 8;
+/// Multiplier when the query only matches the joined qualified name
+const JOINED_NAME_MATCH_FACTOR: f64 = 0.8;
+
+/// One ranked fuzzy match
+///
+/// # 4-Word Name: FuzzySymbolMatchHit
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct FuzzySymbolMatchHit {
+    pub entity_key: String,
+    pub name: String,
+    /// `Receiver.Name` for methods, otherwise the name
+    pub qualified_name: String,
+    pub file_path: String,
+    pub line: u32,
+    pub score: f64,
+    pub match_quality: f64,
+    pub package_proximity: f64,
+}
+
+/// Rank entities against a fuzzy query
+///
+/// # 4-Word Name: rank_fuzzy_symbol_matches
+///
+/// # Contract
+/// - Precondition: `near_package` is a directory as stored in entity file
+///   paths (e.g. `internal/api`), or None
+/// - Postcondition: at most `top` hits, best score first; ties go to the
+///   shorter qualified name, then the key. Entities the query does not
+///   match are left out
+pub fn rank_fuzzy_symbol_matches(
+    entities: &[CodeEntity],
+    query: &str,
+    near_package: Option<&str>,
+    top: usize,
+) -> Vec<FuzzySymbolMatchHit> {
+    let mut hits: Vec<FuzzySymbolMatchHit> = entities
+        .iter()
+        .filter_map(|entity| {
+            let package = package_directory_of_entity(entity);
+            let receiver = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY);
+            let name = entity.interface_signature.name.as_str();
+
+            let mut segments: Vec<&str> = split_path_components(&package);
+            if let Some(receiver) = receiver {
+                segments.push(receiver.as_str());
+            }
+            segments.extend(split_query_segments(name));
+
+            let match_quality = score_fuzzy_qualified_match(query, &segments)?;
+            let package_proximity = near_package.map(|near| shared_package_prefix_ratio(&package, near)).unwrap_or(0.0);
+            let qualified_name = match receiver {
+                Some(receiver) => format!("{}.{}", receiver, name),
+                None => name.to_string(),
+            };
+            Some(FuzzySymbolMatchHit {
+                entity_key: entity.isgl1_key.clone(),
+                name: name.to_string(),
+                qualified_name,
+                file_path: entity.interface_signature.file_path.to_string_lossy().to_string(),
+                line: entity.interface_signature.line_range.start,
+                score: round_to_thousandths(match_quality + PACKAGE_PROXIMITY_WEIGHT * package_proximity),
+                match_quality: round_to_thousandths(match_quality),
+                package_proximity: round_to_thousandths(package_proximity),
+            })
+        })
+        .collect();
+
+    hits.sort_by(|a, b| {
+        b.score
+            .partial_cmp(&a.score)
+            .unwrap_or(Ordering::Equal)
+            .then(a.qualified_name.len().cmp(&b.qualified_name.len()))
+            .then(a.entity_key.cmp(&b.entity_key))
+    });
+    hits.truncate(top);
+    hits
+}
+
+/// Match quality (0..=1) of a query against qualified name segments
+///
+/// # 4-Word Name: score_fuzzy_qualified_match
+///
+/// # Contract
+/// - Precondition: `segments` run from outermost (package) to the name
+/// - Postcondition: None when the last query segment does not match the
+///   last name segment and the whole query does not match the last two
+///   segments joined (`UserService.CreateUser`, `store.Save`) either
+pub fn score_fuzzy_qualified_match(query: &str, segments: &[&str]) -> Option<f64> {
+    let query_segments = split_query_segments(query);
+    let (last_query, outer_queries) = query_segments.split_last()?;
+    let (last_segment, outer_segments) = segments.split_last()?;
+
+    let segment_wise = (|| {
+        let mut score = score_segment_subsequence(last_query, last_segment)?;
+        let mut best = best_possible_segment_score(last_query);
+        let mut remaining = outer_segments.len();
+        for query_segment in outer_queries.iter().rev() {
+            let (position, segment_score) = (0..remaining)
+                .rev()
+                .find_map(|index| Some((index, score_segment_subsequence(query_segment, outer_segments[index])?)))?;
+            score += segment_score;
+            best += best_possible_segment_score(query_segment);
+            remaining = position;
+        }
+        Some((score as f64 / best as f64).min(1.0))
+    })();
+    if segment_wise.is_some() {
+        return segment_wise;
+    }
+
+    let joined_query: String = query_segments.concat();
+    let joined_name = segments[outer_segments.len().saturating_sub(1)..].join(".");
+    let score = score_segment_subsequence(&joined_query, &joined_name)?;
+    let quality = (score as f64 / best_possible_segment_score(&joined_query) as f64).min(1.0);
+    Some(JOINED_NAME_MATCH_FACTOR * quality)
+}
+
+/// Best subsequence score of `query` inside one name segment
+fn score_segment_subsequence(query: &str, candidate: &str) -> Option<i64> {
+    let query: Vec<char> = query.chars().flat_map(char::to_lowercase).collect();
+    let chars: Vec<char> = candidate.chars().collect();
+    let lower: Vec<char> = chars.iter().map(|c| c.to_lowercase().next().unwrap_or(*c)).collect();
+    if query.is_empty() || query.len() > chars.len() {
+        return None;
+    }
+    if lower == query {
+        return Some(best_possible_score_for_length(query.len()));
+    }
+
+    const UNMATCHED: i64 = i64::MIN / 2;
+    let bonus = |j: usize| -> i64 {
+        let mut bonus = 0;
+        if j == 0 {
+            bonus += SEGMENT_START_BONUS;
+        }
+        if is_camel_hump_position(&chars, j) {
+            bonus += CAMEL_HUMP_BONUS;
+        }
+        bonus
+    };
+
+    let mut row: Vec<i64> = (0..chars.len())
+        .map(|j| {
+            if lower[j] == query[0] {
+                MATCH_SCORE + bonus(j) - (j as i64 * SKIPPED_CHAR_PENALTY).min(MAX_GAP_PENALTY)
+            } else {
+                UNMATCHED
+            }
+        })
+        .collect();
+    for &wanted in &query[1..] {
+        let mut next = vec![UNMATCHED; chars.len()];
+        for j in 1..chars.len() {
+            if lower[j] != wanted {
+                continue;
+            }
+            let best_previous = (0..j)
+                .filter(|&k| row[k] > UNMATCHED)
+                .map(|k| {
+                    if k + 1 == j {
+                        row[k] + CONSECUTIVE_MATCH_BONUS
+                    } else {
+                        row[k] - ((j - k - 1) as i64 * SKIPPED_CHAR_PENALTY).min(MAX_GAP_PENALTY)
+                    }
+                })
+                .max();
+            if let Some(previous) = best_previous {
+                next[j] = previous + MATCH_SCORE + bonus(j);
+            }
+        }
+        row = next;
+    }
+    row.into_iter().filter(|&score| score > UNMATCHED).max()
+}
+
+fn best_possible_segment_score(query: &str) -> i64 {
+    best_possible_score_for_length(query.chars().count())
+}
+
+fn best_possible_score_for_length(length: usize) -> i64 {
+    if length == 0 {
+        return 1;
+    }
+    let first = MATCH_SCORE + SEGMENT_START_BONUS + CAMEL_HUMP_BONUS;
+    first + (length as i64 - 1) * (MATCH_SCORE + CONSECUTIVE_MATCH_BONUS)
+}
+
+/// Start of a word inside an identifier: after `_`/`-`/`.`, lower→Upper,
+/// the last capital of an acronym (`HTTP|Server`), or letter→digit
+fn is_camel_hump_position(chars: &[char], j: usize) -> bool {
+    if j == 0 {
+        return true;
+    }
+    let (previous, current) = (chars[j - 1], chars[j]);
+    if !previous.is_alphanumeric() {
+        return current.is_alphanumeric();
+    }
+    if current.is_uppercase() && !previous.is_uppercase() {
+        return true;
+    }
+    if current.is_uppercase() && chars.get(j + 1).is_some_and(|next| next.is_lowercase()) {
+        return true;
+    }
+    current.is_ascii_digit() && !previous.is_ascii_digit()
+}
+
+/// Share of leading directory components two packages have in common
+fn shared_package_prefix_ratio(package: &str, near: &str) -> f64 {
+    let package = split_path_components(package);
+    let near = split_path_components(near);
+    let longest = package.len().max(near.len());
+    if longest == 0 {
+        return 1.0;
+    }
+    let shared = package.iter().zip(near.iter()).take_while(|(a, b)| a == b).count();
+    shared as f64 / longest as f64
+}
+
+fn split_path_components(path: &str) -> Vec<&str> {
+    path.split(['/', '\\']).filter(|part| !part.is_empty() && *part != ".").collect()
+}
+
+/// Query and name segments: `.`, `::`, `/` and `#` separate them
+fn split_query_segments(text: &str) -> Vec<&str> {
+    text.split(['.', ':', '/', '#']).filter(|part| !part.is_empty()).collect()
+}
+
+fn round_to_thousandths(value: f64) -> f64 {
+    (value * 1000.0).round() / 1000.0
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_camel_humps_and_package_proximity_rank() {
+        let entities = vec![
+            create_go_test_entity(
+                "go:method:CreateUser:__internal_user:T1",
+                "CreateUser",
+                EntityType::Method,
+                "internal/user/service.go",
+                &[(RECEIVER_TYPE_METADATA_KEY, "UserService")],
+            ),
+            create_go_test_entity(
+                "go:method:CreateUser:__internal_admin:T2",
+                "CreateUser",
+                EntityType::Method,
+                "internal/admin/service.go",
+                &[(RECEIVER_TYPE_METADATA_KEY, "UserService")],
+            ),
+            create_go_test_entity(
+                "go:fn:cancelRequest:__internal_user:T3",
+                "cancelRequest",
+                EntityType::Function,
+                "internal/user/http.go",
+                &[],
+            ),
+            create_go_test_entity("go:fn:Save:__store:T4", "Save", EntityType::Function, "store/disk.go", &[]),
+        ];
+
+        let hits = rank_fuzzy_symbol_matches(&entities, "usrSvc.creat", Some("internal/admin"), 10);
+        assert_eq!(hits.len(), 2, "receiver must match usrSvc: {:?}", hits);
+        assert_eq!(hits[0].entity_key, "go:method:CreateUser:__internal_admin:T2");
+        assert_eq!(hits[0].qualified_name, "UserService.CreateUser");
+        assert!(hits[0].score > hits[1].score);
+        assert_eq!(hits[0].match_quality, hits[1].match_quality);
+
+        // Contiguous from the start beats a jump to a hump: Cre|ateUser over c|ancel|Re|quest
+        let hits = rank_fuzzy_symbol_matches(&entities, "cre", None, 10);
+        assert_eq!(hits.len(), 3);
+        assert_eq!(hits[0].name, "CreateUser");
+        assert_eq!(hits[2].name, "cancelRequest");
+
+        let hits = rank_fuzzy_symbol_matches(&entities, "canReq", None, 1);
+        assert_eq!(hits[0].name, "cancelRequest");
+        assert!(hits[0].match_quality > 0.9, "{:?}", hits[0]);
+
+        // Segments must appear in order; the joined name is the fallback
+        assert!(rank_fuzzy_symbol_matches(&entities, "creat.usrSvc", None, 10).is_empty());
+        assert_eq!(rank_fuzzy_symbol_matches(&entities, "svcCreate", None, 10).len(), 2);
+        assert_eq!(rank_fuzzy_symbol_matches(&entities, "store.save", None, 10)[0].name, "Save");
+    }
+}
@@ -0,0 +1,296 @@
+//! Git blame ownership annotations (v1.7.3)
+//!
+//! # 4-Word Naming: git_blame_ownership_annotator
+//!
+//! Adds `git blame` facts to entity metadata, over the entity's line range:
+//!
+//! | Key | Value |
+//! |-----|-------|
+//! | `last_commit` | newest commit touching the symbol (full SHA) |
+//! | `last_author` | author of that commit |
+//! | `last_modified` | its author time, Unix seconds |
+//! | `owner` | author of the most lines of the symbol (ties: name order) |
+//! | `commit_tickets` | ticket IDs (`PROJ-123`, `#45`) in the subjects of the commits touching the symbol, comma-separated; only when there are any |
+//!
+//! One `git blame --line-porcelain` per file, run from the file's directory
+//! so any ingest root inside a repository works; files are blamed in
+//! parallel. Uncommitted lines are ignored; an entity whose lines are all
+//! uncommitted, or a file outside git, gets no annotation.
+
+use std::collections::{BTreeMap, HashMap};
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+use rayon::prelude::*;
+
+use crate::entities::CodeEntity;
+
+/// Newest commit touching the symbol
+pub const LAST_COMMIT_METADATA_KEY: &str = "last_commit";
+
+/// Author of `last_commit`
+pub const LAST_AUTHOR_METADATA_KEY: &str = "last_author";
+
+/// Author time of `last_commit`, Unix seconds
+pub const LAST_MODIFIED_METADATA_KEY: &str = "last_modified";
+
+/// Author of most of the symbol's lines
+pub const OWNER_METADATA_KEY: &str = "owner";
+
+/// Ticket IDs named by the commits touching the symbol, comma-separated
+pub const COMMIT_TICKETS_METADATA_KEY: &str = "commit_tickets";
+
+const UNCOMMITTED_COMMIT_SHA: &str = "0000000000000000000000000000000000000000";
+
+/// Blame of one line of a file
+///
+/// # 4-Word Name: BlameLineRecordEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct BlameLineRecordEntry {
+    pub commit: String,
+    pub author: String,
+    pub author_time: i64,
+    /// Commit subject
+    pub summary: String,
+}
+
+/// Blame facts of one symbol
+///
+/// # 4-Word Name: SymbolBlameSummaryEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SymbolBlameSummaryEntry {
+    pub last_commit: String,
+    pub last_author: String,
+    pub last_modified: i64,
+    pub owner: String,
+    /// Sorted, deduplicated ticket IDs from the commit subjects
+    pub tickets: Vec<String>,
+}
+
+/// Parse `git blame --line-porcelain` output
+///
+/// # 4-Word Name: parse_git_blame_porcelain
+///
+/// # Contract
+/// - Postcondition: element `i` is the blame of line `i + 1`
+pub fn parse_git_blame_porcelain(text: &str) -> Vec<BlameLineRecordEntry> {
+    let mut records = Vec::new();
+    let mut current = BlameLineRecordEntry {
+        commit: String::new(),
+        author: String::new(),
+        author_time: 0,
+        summary: String::new(),
+    };
+    for line in text.lines() {
+        if line.starts_with('\t') {
+            records.push(current.clone());
+        } else if let Some(author) = line.strip_prefix("author ") {
+            current.author = author.to_string();
+        } else if let Some(time) = line.strip_prefix("author-time ") {
+            current.author_time = time.trim().parse().unwrap_or(0);
+        } else if let Some(summary) = line.strip_prefix("summary ") {
+            current.summary = summary.to_string();
+        } else if let Some(sha) = line.split(' ').next().filter(|s| s.len() == 40 && s.bytes().all(|b| b.is_ascii_hexdigit())) {
+            current.commit = sha.to_string();
+        }
+    }
+    records
+}
+
+/// Summarize blame over lines `start..=end` (1-based)
+///
+/// # 4-Word Name: summarize_blame_line_range
+///
+/// # Contract
+/// - Postcondition: `None` when no committed line falls in the range
+pub fn summarize_blame_line_range(
+    lines: &[BlameLineRecordEntry],
+    start: u32,
+    end: u32,
+) -> Option<SymbolBlameSummaryEntry> {
+    let first = (start.max(1) - 1) as usize;
+    let last = (end as usize).min(lines.len());
+    let committed: Vec<&BlameLineRecordEntry> = lines
+        .get(first..last)?
+        .iter()
+        .filter(|l| l.commit != UNCOMMITTED_COMMIT_SHA)
+        .collect();
+    let newest = committed
+        .iter()
+        .max_by(|a, b| a.author_time.cmp(&b.author_time).then_with(|| b.commit.cmp(&a.commit)))?;
+
+    let mut line_counts: BTreeMap<&str, usize> = BTreeMap::new();
+    for line in &committed {
+        *line_counts.entry(line.author.as_str()).or_default() += 1;
+    }
+    // BTreeMap order + strict comparison: first name wins ties
+    let owner = line_counts
+        .iter()
+        .fold(None, |best: Option<(&str, usize)>, (&author, &count)| match best {
+            Some((_, best_count)) if best_count >= count => best,
+            _ => Some((author, count)),
+        })
+        .map(|(author, _)| author.to_string())?;
+
+    let mut tickets: Vec<String> = committed.iter().flat_map(|line| extract_ticket_ids_from_text(&line.summary)).collect();
+    tickets.sort();
+    tickets.dedup();
+
+    Some(SymbolBlameSummaryEntry {
+        last_commit: newest.commit.clone(),
+        last_author: newest.author.clone(),
+        last_modified: newest.author_time,
+        owner,
+        tickets,
+    })
+}
+
+/// Ticket IDs in a commit subject: `PROJ-123` (Jira style) and `#45`
+///
+/// # 4-Word Name: extract_ticket_ids_from_text
+///
+/// # Contract
+/// - Postcondition: IDs in order of appearance, `#` kept, project keys
+///   upper-case as written (`proj-1` is not a ticket)
+pub fn extract_ticket_ids_from_text(text: &str) -> Vec<String> {
+    text.split(|c: char| !(c.is_ascii_alphanumeric() || c == '-' || c == '#' || c == '_'))
+        .map(|word| word.trim_matches('-'))
+        .filter(|word| {
+            if let Some(number) = word.strip_prefix('#') {
+                return !number.is_empty() && number.bytes().all(|b| b.is_ascii_digit());
+            }
+            let Some((project, number)) = word.rsplit_once('-') else {
+                return false;
+            };
+            project.starts_with(|c: char| c.is_ascii_uppercase())
+                && project.len() >= 2
+                && project.bytes().all(|b| b.is_ascii_uppercase() || b.is_ascii_digit() || b == b'_')
+                && !number.is_empty()
+                && number.bytes().all(|b| b.is_ascii_digit())
+        })
+        .map(str::to_string)
+        .collect()
+}
+
+/// Blame one file; `None` outside git or when blame fails
+///
+/// # 4-Word Name: run_git_blame_for_file
+pub fn run_git_blame_for_file(path: &Path) -> Option<Vec<BlameLineRecordEntry>> {
+    let directory = path.parent().filter(|p| !p.as_os_str().is_empty()).unwrap_or(Path::new("."));
+    let output = Command::new("git")
+        .args(["blame", "--line-porcelain", "--"])
+        .arg(path.file_name()?)
+        .current_dir(directory)
+        .output()
+        .ok()
+        .filter(|output| output.status.success())?;
+    Some(parse_git_blame_porcelain(&String::from_utf8_lossy(&output.stdout)))
+}
+
+/// Add blame metadata to every entity whose file git knows
+///
+/// # 4-Word Name: annotate_entities_with_git_blame
+///
+/// # Contract
+/// - Postcondition: annotated entities carry all four keys (and
+///   `commit_tickets` when a subject names a ticket); returns how many were
+///   annotated
+pub fn annotate_entities_with_git_blame(entities: &mut [CodeEntity]) -> usize {
+    let mut files: Vec<PathBuf> = entities.iter().map(|e| e.interface_signature.file_path.clone()).collect();
+    files.sort();
+    files.dedup();
+    let blames: HashMap<PathBuf, Vec<BlameLineRecordEntry>> = files
+        .into_par_iter()
+        .filter_map(|file| run_git_blame_for_file(&file).map(|lines| (file, lines)))
+        .collect();
+
+    let mut annotated = 0;
+    for entity in entities.iter_mut() {
+        let Some(lines) = blames.get(&entity.interface_signature.file_path) else {
+            continue;
+        };
+        let range = &entity.interface_signature.line_range;
+        let Some(summary) = summarize_blame_line_range(lines, range.start, range.end) else {
+            continue;
+        };
+        let additional = &mut entity.metadata.additional;
+        additional.insert(LAST_COMMIT_METADATA_KEY.to_string(), summary.last_commit);
+        additional.insert(LAST_AUTHOR_METADATA_KEY.to_string(), summary.last_author);
+        additional.insert(LAST_MODIFIED_METADATA_KEY.to_string(), summary.last_modified.to_string());
+        additional.insert(OWNER_METADATA_KEY.to_string(), summary.owner);
+        if !summary.tickets.is_empty() {
+            additional.insert(COMMIT_TICKETS_METADATA_KEY.to_string(), summary.tickets.join(","));
+        }
+        annotated += 1;
+    }
+    annotated
+}
+
+/// Days since the entity was last modified, from its `last_modified` entry
+///
+/// # 4-Word Name: entity_age_in_days
+pub fn entity_age_in_days(entity: &CodeEntity, now_unix: i64) -> Option<i64> {
+    let modified: i64 = entity.metadata.additional.get(LAST_MODIFIED_METADATA_KEY)?.parse().ok()?;
+    Some((now_unix - modified).max(0) / 86_400)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn porcelain_line(sha: &str, author: &str, time: i64, content: &str) -> String {
+        format!(
+            "{sha} 1 1\nauthor {author}\nauthor-mail <{author}@example.com>\nauthor-time {time}\nauthor-tz +0000\n\
+             committer {author}\ncommitter-time {time}\nsummary PAY-{time} change\nfilename main.go\n\t{content}\n"
+        )
+    }
+
+    #[test]
+    fn test_porcelain_parses_one_record_per_line() {
+        let a = "a".repeat(40);
+        let b = "b".repeat(40);
+        let text = [
+            porcelain_line(&a, "Ada", 1_600_000_000, "func main() {"),
+            porcelain_line(&b, "Linus", 1_700_000_000, "\trun()"),
+            porcelain_line(&a, "Ada", 1_600_000_000, "}"),
+        ]
+        .concat();
+
+        let lines = parse_git_blame_porcelain(&text);
+        assert_eq!(lines.len(), 3);
+        assert_eq!(lines[1].author, "Linus");
+        assert_eq!(lines[1].commit, b);
+
+        let summary = summarize_blame_line_range(&lines, 1, 3).unwrap();
+        assert_eq!(summary.last_commit, b);
+        assert_eq!(summary.last_author, "Linus");
+        assert_eq!(summary.last_modified, 1_700_000_000);
+        assert_eq!(summary.owner, "Ada");
+        assert_eq!(summary.tickets, vec!["PAY-1600000000".to_string(), "PAY-1700000000".to_string()]);
+        assert_eq!(
+            extract_ticket_ids_from_text("Fix retry (#45), see AUTH-12: not proj-3 or X-1 or PAY-"),
+            vec!["#45", "AUTH-12"]
+        );
+    }
+
+    #[test]
+    fn test_uncommitted_lines_are_ignored() {
+        let lines = vec![
+            BlameLineRecordEntry {
+                commit: UNCOMMITTED_COMMIT_SHA.to_string(),
+                author: "Not Committed Yet".to_string(),
+                author_time: 1_800_000_000,
+                summary: String::new(),
+            },
+            BlameLineRecordEntry {
+                commit: "c".repeat(40),
+                author: "Grace".to_string(),
+                author_time: 1_500_000_000,
+                summary: String::new(),
+            },
+        ];
+        assert_eq!(summarize_blame_line_range(&lines, 1, 2).unwrap().last_author, "Grace");
+        assert!(summarize_blame_line_range(&lines, 1, 1).is_none());
+        assert!(summarize_blame_line_range(&lines, 5, 9).is_none());
+    }
+}
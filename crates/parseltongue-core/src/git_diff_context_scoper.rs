@@ -0,0 +1,257 @@
+//! Git-diff-scoped context (v1.7.3)
+//!
+//! # 4-Word Naming: git_diff_context_scoper
+//!
+//! Turns a git change into a context bundle for review prompts:
+//!
+//! 1. `git diff --unified=0 <rev>` (or `<rev>..<rev>`) gives the changed line
+//!    ranges per file, on the new side of the diff. A pure deletion counts as
+//!    the line it was removed after, so the enclosing symbol is still found
+//! 2. Every entity whose line range overlaps a changed range is a *changed*
+//!    symbol (modules and impl blocks excepted: they would pull in whole files)
+//! 3. All changed symbols seed one pack (`rank_neighbors_of_seed_set`):
+//!    callers and callees of the change fill the rest of the budget
+//!
+//! Line numbers are matched against the indexed graph, so the graph should be
+//! indexed at the diff's new side (the working tree for `--since`).
+//! Diff paths are repository-relative; entity paths match when either one is
+//! a component-wise suffix of the other (index root inside the repository,
+//! or absolute entity paths).
+
+use std::collections::BTreeSet;
+use std::path::{Component, Path, PathBuf};
+use std::process::Command;
+
+use serde::Serialize;
+
+use crate::context_pack_token_accounting::ContextTokenizerEncodingKind;
+use crate::context_prompt_template_renderer::ContextPackOutputTemplate;
+use crate::entities::{CodeEntity, EntityType};
+use crate::error::{ParseltongError, Result};
+use crate::recency_relevance_score_booster::RecencyScoringConfigSpec;
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::storage::CozoDbStorage;
+use crate::token_budget_context_packer::{
+    load_packing_edges_from_storage, pack_ranked_entities_from_storage, rank_neighbors_of_seed_set,
+    rank_within_depth_limit, PackedContextBundleResult,
+};
+use crate::traversal_expansion_limit_guards::{
+    cap_edge_list_fanout, TraversalExpansionLimitSpec, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+};
+
+/// Lines changed in one file, on the new side of the diff (1-based, inclusive)
+///
+/// # 4-Word Name: ChangedLineRangeEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ChangedLineRangeEntry {
+    pub file_path: String,
+    pub start_line: u32,
+    pub end_line: u32,
+}
+
+/// Run `git diff --unified=0` and return its changed line ranges
+///
+/// # 4-Word Name: collect_git_diff_changed_lines
+///
+/// # Contract
+/// - Precondition: `revision` is anything `git diff` accepts as one
+///   argument (`HEAD~1`, `main..feature`, `main...HEAD`)
+/// - Error: git missing, or git exits non-zero (bad revision, not a repo)
+pub fn collect_git_diff_changed_lines(repo_root: &Path, revision: &str) -> Result<Vec<ChangedLineRangeEntry>> {
+    let output = Command::new("git")
+        .args(["diff", "--unified=0", "--no-color", "--no-ext-diff", revision, "--"])
+        .current_dir(repo_root)
+        .output()
+        .map_err(|source| ParseltongError::FileSystemError {
+            path: repo_root.display().to_string(),
+            source,
+        })?;
+    if !output.status.success() {
+        return Err(ParseltongError::ConfigurationError {
+            details: format!(
+                "git diff {} failed: {}",
+                revision,
+                String::from_utf8_lossy(&output.stderr).trim()
+            ),
+        });
+    }
+    Ok(parse_unified_diff_hunks(&String::from_utf8_lossy(&output.stdout)))
+}
+
+/// Parse `@@ -a,b +c,d @@` hunk headers of a unified diff
+///
+/// # 4-Word Name: parse_unified_diff_hunks
+///
+/// # Contract
+/// - Postcondition: one entry per hunk of a file that exists on the new side
+///   (deleted files are skipped); `d == 0` hunks become the single line `c`
+///   (at least 1)
+pub fn parse_unified_diff_hunks(diff_text: &str) -> Vec<ChangedLineRangeEntry> {
+    let mut ranges = Vec::new();
+    let mut current_file: Option<String> = None;
+    for line in diff_text.lines() {
+        if let Some(path) = line.strip_prefix("+++ ") {
+            current_file = match path.trim_end() {
+                "/dev/null" => None,
+                path => Some(path.strip_prefix("b/").unwrap_or(path).to_string()),
+            };
+            continue;
+        }
+        let (Some(header), Some(file)) = (line.strip_prefix("@@ "), current_file.as_ref()) else {
+            continue;
+        };
+        let Some(new_side) = header.split_whitespace().find_map(|part| part.strip_prefix('+')) else {
+            continue;
+        };
+        let mut numbers = new_side.splitn(2, ',');
+        let Some(Ok(start)) = numbers.next().map(str::parse::<u32>) else {
+            continue;
+        };
+        let count = numbers.next().and_then(|n| n.parse::<u32>().ok()).unwrap_or(1);
+        let start_line = start.max(1);
+        ranges.push(ChangedLineRangeEntry {
+            file_path: file.clone(),
+            start_line,
+            end_line: start_line + count.saturating_sub(1),
+        });
+    }
+    ranges
+}
+
+/// Keys of entities overlapping any changed range
+///
+/// # 4-Word Name: map_changed_lines_to_entities
+///
+/// # Contract
+/// - Postcondition: sorted, deduplicated keys; placeholders, modules and
+///   impl blocks excluded
+pub fn map_changed_lines_to_entities(ranges: &[ChangedLineRangeEntry], entities: &[CodeEntity]) -> Vec<String> {
+    let mut changed = BTreeSet::new();
+    for entity in entities {
+        if is_external_graph_node_key(&entity.isgl1_key)
+            || matches!(entity.interface_signature.entity_type, EntityType::Module | EntityType::ImplBlock { .. })
+        {
+            continue;
+        }
+        let entity_path = normalize_relative_path_components(&entity.interface_signature.file_path);
+        let lines = &entity.interface_signature.line_range;
+        let touched = ranges.iter().any(|range| {
+            let diff_path = normalize_relative_path_components(Path::new(&range.file_path));
+            (entity_path.ends_with(&diff_path) || diff_path.ends_with(&entity_path))
+                && range.start_line <= lines.end
+                && lines.start <= range.end_line
+        });
+        if touched {
+            changed.insert(entity.isgl1_key.clone());
+        }
+    }
+    changed.into_iter().collect()
+}
+
+/// Path without `.` components, for suffix comparison
+fn normalize_relative_path_components(path: &Path) -> PathBuf {
+    path.components().filter(|c| !matches!(c, Component::CurDir)).collect()
+}
+
+/// Context bundle seeded by every symbol a diff touches
+///
+/// # 4-Word Name: build_diff_context_pack_from_storage
+///
+/// # Contract
+/// - Postcondition: changed symbols carry relation `changed` and rank first;
+///   same budget guarantee as `build_context_pack_from_storage`
+/// - Postcondition: `seed_key` of the bundle is `diff:<revision>`; an empty
+///   bundle when no indexed symbol overlaps the diff
+/// - Postcondition: `limits` bound the walk as in `build_context_pack_from_storage`
+#[allow(clippy::too_many_arguments)]
+pub async fn build_diff_context_pack_from_storage(
+    storage: &CozoDbStorage,
+    revision: &str,
+    ranges: &[ChangedLineRangeEntry],
+    budget: usize,
+    limits: TraversalExpansionLimitSpec,
+    include_tests: bool,
+    template: &ContextPackOutputTemplate,
+    tokenizer: ContextTokenizerEncodingKind,
+    recency: Option<&RecencyScoringConfigSpec>,
+) -> Result<PackedContextBundleResult> {
+    let entities = storage.get_all_entities().await?;
+    let changed_keys = map_changed_lines_to_entities(ranges, &entities);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let edges = load_packing_edges_from_storage(storage, include_tests).await?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Undirected, &mut truncation);
+
+    let rank = |depth| rank_neighbors_of_seed_set(&changed_keys, &edges, depth);
+    let mut ranked = rank_within_depth_limit(rank, &mut truncation);
+    truncation.truncate_entries_to_node_limit(&mut ranked);
+    for entry in ranked.iter_mut().filter(|e| e.depth == 0) {
+        entry.relation = "changed".to_string();
+    }
+    let label = format!("diff:{}", revision);
+    let mut bundle =
+        pack_ranked_entities_from_storage(storage, &label, ranked, budget, include_tests, template, tokenizer, recency)
+            .await?;
+    bundle.truncation = Some(truncation);
+    Ok(bundle)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    const SAMPLE_DIFF: &str = "\
+diff --git a/internal/users/service.go b/internal/users/service.go
+index 1111111..2222222 100644
+--- a/internal/users/service.go
++++ b/internal/users/service.go
+@@ -12,0 +13,2 @@ func Create(name string) error {
++\tif name == \"\" {
++\t\treturn errEmpty
+@@ -40 +42 @@ func Delete(id int) error {
+-\treturn nil
++\treturn db.Delete(id)
+@@ -55,3 +56,0 @@ func Rename() {
+-\tlegacy()
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package main
+";
+
+    #[test]
+    fn test_hunks_parse_new_side_ranges() {
+        let ranges = parse_unified_diff_hunks(SAMPLE_DIFF);
+        let summary: Vec<(&str, u32, u32)> = ranges
+            .iter()
+            .map(|r| (r.file_path.as_str(), r.start_line, r.end_line))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("internal/users/service.go", 13, 14),
+                ("internal/users/service.go", 42, 42),
+                ("internal/users/service.go", 56, 56),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_changed_lines_map_to_enclosing_symbols() {
+        let mut create = create_go_test_entity("go:fn:Create:__s:T10", "Create", EntityType::Function, "./internal/users/service.go", &[]);
+        create.interface_signature.line_range.start = 10;
+        create.interface_signature.line_range.end = 20;
+        let mut list = create_go_test_entity("go:fn:List:__s:T22", "List", EntityType::Function, "internal/users/service.go", &[]);
+        list.interface_signature.line_range.start = 22;
+        list.interface_signature.line_range.end = 30;
+        let mut other = create_go_test_entity("go:fn:Create:__o:T10", "Create", EntityType::Function, "internal/orders/service.go", &[]);
+        other.interface_signature.line_range.start = 10;
+        other.interface_signature.line_range.end = 20;
+
+        let ranges = parse_unified_diff_hunks(SAMPLE_DIFF);
+        let changed = map_changed_lines_to_entities(&ranges, &[create, list, other]);
+        assert_eq!(changed, vec!["go:fn:Create:__s:T10"]);
+    }
+}
@@ -0,0 +1,361 @@
+//! Go Build Constraint Evaluator (v1.7.3)
+//!
+//! # 4-Word Naming: go_build_constraint_evaluator
+//!
+//! Go selects the files of a package per build configuration: `//go:build`
+//! lines (and legacy `// +build` lines) and `_GOOS` / `_GOARCH` file name
+//! suffixes. Indexing every file puts `open_linux.go` and `open_windows.go`
+//! side by side, so one package "declares" `openFile` twice; skipping them
+//! loses real code. This module evaluates the constraints against one
+//! [`GoBuildContextConfig`] (GOOS, GOARCH, `-tags`), like `go build` does.
+//!
+//! ## Overlays
+//!
+//! With `index_all_configurations` every file is kept and the combined
+//! constraint of each constrained file (`linux && amd64`, `integration`) is
+//! returned instead, so callers can tag the file's entities with it and
+//! filter one configuration out of the graph later.
+//!
+//! ## Tag semantics (matching `go/build`)
+//!
+//! - Satisfied: GOOS, GOARCH, `-tags`, `unix` on Unix-like GOOS, `gc`, every
+//!   `go1.N` release tag
+//! - `android` implies `linux`, `illumos` implies `solaris`, `ios` implies `darwin`
+//! - `cgo` only when passed in `-tags`
+//! - Unparseable expressions keep the file (never lose code on a typo)
+
+use std::path::Path;
+
+/// Entity metadata key carrying the build constraint of the declaring file
+pub const BUILD_CONSTRAINT_METADATA_KEY: &str = "build_constraint";
+
+const KNOWN_GOOS_VALUES: &[&str] = &[
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux", "nacl",
+    "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+];
+
+const KNOWN_GOARCH_VALUES: &[&str] = &[
+    "386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle", "mips64",
+    "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64", "s390", "s390x",
+    "sparc", "sparc64", "wasm",
+];
+
+const UNIX_GOOS_VALUES: &[&str] = &[
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd", "openbsd",
+    "solaris",
+];
+
+/// One Go build configuration (`GOOS=linux GOARCH=amd64 go build -tags integration`)
+///
+/// # 4-Word Name: GoBuildContextConfig
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GoBuildContextConfig {
+    /// Target operating system (`linux`, `darwin`, `windows`, ...)
+    pub goos: String,
+    /// Target architecture (`amd64`, `arm64`, ...)
+    pub goarch: String,
+    /// Extra build tags (`-tags integration,sqlite`)
+    pub build_tags: Vec<String>,
+    /// Keep every file and tag entities with their constraint instead of filtering
+    pub index_all_configurations: bool,
+}
+
+impl Default for GoBuildContextConfig {
+    /// `$GOOS` / `$GOARCH` when set, otherwise the host platform (what `go build` would use)
+    fn default() -> Self {
+        let host_goos = match std::env::consts::OS {
+            "macos" => "darwin",
+            other => other,
+        };
+        let host_goarch = match std::env::consts::ARCH {
+            "x86_64" => "amd64",
+            "x86" => "386",
+            "aarch64" => "arm64",
+            "powerpc64" => "ppc64le",
+            "loongarch64" => "loong64",
+            "wasm32" => "wasm",
+            other => other,
+        };
+        let from_env = |name: &str, fallback: &str| {
+            std::env::var(name)
+                .ok()
+                .filter(|value| !value.is_empty())
+                .unwrap_or_else(|| fallback.to_string())
+        };
+        Self {
+            goos: from_env("GOOS", host_goos),
+            goarch: from_env("GOARCH", host_goarch),
+            build_tags: Vec::new(),
+            index_all_configurations: false,
+        }
+    }
+}
+
+impl GoBuildContextConfig {
+    /// True when `tag` holds in this configuration
+    ///
+    /// # 4-Word Name: is_build_tag_satisfied
+    pub fn is_build_tag_satisfied(&self, tag: &str) -> bool {
+        let implied_goos = match self.goos.as_str() {
+            "android" => Some("linux"),
+            "illumos" => Some("solaris"),
+            "ios" => Some("darwin"),
+            _ => None,
+        };
+        tag == self.goos
+            || tag == self.goarch
+            || implied_goos == Some(tag)
+            || (tag == "unix" && UNIX_GOOS_VALUES.contains(&self.goos.as_str()))
+            || tag == "gc"
+            || is_go_release_tag(tag)
+            || self.build_tags.iter().any(|t| t == tag)
+    }
+}
+
+/// Combined build constraint of a Go file, `None` when it builds everywhere
+///
+/// # 4-Word Name: extract_go_file_constraint
+///
+/// # Contract
+/// - `//go:build` wins over `// +build` lines (as in Go 1.17+)
+/// - File name suffixes are ANDed with the header expression
+/// - Returns: a `//go:build`-syntax expression (`(linux || darwin) && amd64`)
+pub fn extract_go_file_constraint(file_path: &Path, source: &str) -> Option<String> {
+    let file_name = file_path.file_name().and_then(|n| n.to_str()).unwrap_or_default();
+    let parts: Vec<String> = [extract_go_header_constraint(source), go_file_name_constraint(file_name)]
+        .into_iter()
+        .flatten()
+        .collect();
+    match parts.len() {
+        0 => None,
+        1 => parts.into_iter().next(),
+        _ => Some(parts.iter().map(|p| wrap_go_constraint_operand(p)).collect::<Vec<_>>().join(" && ")),
+    }
+}
+
+/// True when the Go file is part of the build described by `context`
+///
+/// # 4-Word Name: is_go_file_in_build
+///
+/// # Contract
+/// - Always true with `index_all_configurations`
+pub fn is_go_file_in_build(file_path: &Path, source: &str, context: &GoBuildContextConfig) -> bool {
+    if context.index_all_configurations {
+        return true;
+    }
+    extract_go_file_constraint(file_path, source)
+        .map_or(true, |expression| evaluate_go_constraint_expression(&expression, context))
+}
+
+/// Evaluate a `//go:build` expression (`||`, `&&`, `!`, parentheses)
+///
+/// # 4-Word Name: evaluate_go_constraint_expression
+///
+/// # Contract
+/// - Malformed expressions evaluate to true
+pub fn evaluate_go_constraint_expression(expression: &str, context: &GoBuildContextConfig) -> bool {
+    let tokens = tokenize_go_constraint_expression(expression);
+    let mut position = 0;
+    match parse_constraint_or_expression(&tokens, &mut position, context) {
+        Some(value) if position == tokens.len() => value,
+        _ => true,
+    }
+}
+
+/// Header constraint: the `//go:build` line, or the ANDed `// +build` lines
+///
+/// Only comments before the `package` clause count, as in Go.
+fn extract_go_header_constraint(source: &str) -> Option<String> {
+    let mut plus_build_lines: Vec<String> = Vec::new();
+    let mut in_block_comment = false;
+    for raw in source.lines() {
+        let line = raw.trim();
+        if in_block_comment {
+            in_block_comment = !line.contains("*/");
+            continue;
+        }
+        if line.starts_with("/*") {
+            in_block_comment = !line.contains("*/");
+            continue;
+        }
+        if let Some(expression) = line.strip_prefix("//go:build ") {
+            return Some(expression.trim().to_string());
+        }
+        if let Some(options) = line.strip_prefix("// +build ") {
+            plus_build_lines.push(convert_plus_build_line(options));
+            continue;
+        }
+        if !line.is_empty() && !line.starts_with("//") {
+            break;
+        }
+    }
+    match plus_build_lines.len() {
+        0 => None,
+        1 => plus_build_lines.pop(),
+        _ => Some(plus_build_lines.iter().map(|l| wrap_go_constraint_operand(l)).collect::<Vec<_>>().join(" && ")),
+    }
+}
+
+/// `// +build linux,386 darwin,!cgo` → `(linux && 386) || (darwin && !cgo)`
+fn convert_plus_build_line(options: &str) -> String {
+    let alternatives: Vec<String> = options
+        .split_whitespace()
+        .map(|option| option.split(',').collect::<Vec<_>>().join(" && "))
+        .collect();
+    if alternatives.len() == 1 {
+        return alternatives.into_iter().next().unwrap_or_default();
+    }
+    alternatives.iter().map(|a| wrap_go_constraint_operand(a)).collect::<Vec<_>>().join(" || ")
+}
+
+/// `_GOOS`, `_GOARCH`, `_GOOS_GOARCH` suffix (before `_test`) as an expression
+fn go_file_name_constraint(file_name: &str) -> Option<String> {
+    let stem = file_name.strip_suffix(".go")?;
+    let stem = stem.strip_suffix("_test").unwrap_or(stem);
+    let elements: Vec<&str> = stem.split('_').collect();
+    let n = elements.len();
+    if n >= 3 && KNOWN_GOOS_VALUES.contains(&elements[n - 2]) && KNOWN_GOARCH_VALUES.contains(&elements[n - 1]) {
+        return Some(format!("{} && {}", elements[n - 2], elements[n - 1]));
+    }
+    if n >= 2 && (KNOWN_GOOS_VALUES.contains(&elements[n - 1]) || KNOWN_GOARCH_VALUES.contains(&elements[n - 1])) {
+        return Some(elements[n - 1].to_string());
+    }
+    None
+}
+
+fn is_go_release_tag(tag: &str) -> bool {
+    tag.strip_prefix("go1.")
+        .is_some_and(|minor| !minor.is_empty() && minor.chars().all(|c| c.is_ascii_digit()))
+}
+
+fn wrap_go_constraint_operand(expression: &str) -> String {
+    if expression.contains("||") || expression.contains("&&") {
+        format!("({})", expression)
+    } else {
+        expression.to_string()
+    }
+}
+
+fn tokenize_go_constraint_expression(expression: &str) -> Vec<String> {
+    let mut tokens: Vec<String> = Vec::new();
+    let mut chars = expression.chars().peekable();
+    while let Some(c) = chars.next() {
+        match c {
+            ' ' | '\t' => {}
+            '(' | ')' | '!' => tokens.push(c.to_string()),
+            '&' | '|' => {
+                if chars.peek() == Some(&c) {
+                    chars.next();
+                }
+                tokens.push(format!("{}{}", c, c));
+            }
+            _ => {
+                let mut word = c.to_string();
+                while let Some(&next) = chars.peek() {
+                    if next.is_alphanumeric() || next == '_' || next == '.' {
+                        word.push(next);
+                        chars.next();
+                    } else {
+                        break;
+                    }
+                }
+                tokens.push(word);
+            }
+        }
+    }
+    tokens
+}
+
+fn parse_constraint_or_expression(tokens: &[String], position: &mut usize, context: &GoBuildContextConfig) -> Option<bool> {
+    let mut value = parse_constraint_and_expression(tokens, position, context)?;
+    while tokens.get(*position).map(String::as_str) == Some("||") {
+        *position += 1;
+        let rhs = parse_constraint_and_expression(tokens, position, context)?;
+        value = value || rhs;
+    }
+    Some(value)
+}
+
+fn parse_constraint_and_expression(tokens: &[String], position: &mut usize, context: &GoBuildContextConfig) -> Option<bool> {
+    let mut value = parse_constraint_unary_expression(tokens, position, context)?;
+    while tokens.get(*position).map(String::as_str) == Some("&&") {
+        *position += 1;
+        let rhs = parse_constraint_unary_expression(tokens, position, context)?;
+        value = value && rhs;
+    }
+    Some(value)
+}
+
+fn parse_constraint_unary_expression(tokens: &[String], position: &mut usize, context: &GoBuildContextConfig) -> Option<bool> {
+    let token = tokens.get(*position)?.as_str();
+    *position += 1;
+    match token {
+        "!" => parse_constraint_unary_expression(tokens, position, context).map(|v| !v),
+        "(" => {
+            let value = parse_constraint_or_expression(tokens, position, context)?;
+            if tokens.get(*position).map(String::as_str) != Some(")") {
+                return None;
+            }
+            *position += 1;
+            Some(value)
+        }
+        ")" | "&&" | "||" => None,
+        tag => Some(context.is_build_tag_satisfied(tag)),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn linux_amd64(tags: &[&str]) -> GoBuildContextConfig {
+        GoBuildContextConfig {
+            goos: "linux".to_string(),
+            goarch: "amd64".to_string(),
+            build_tags: tags.iter().map(|t| t.to_string()).collect(),
+            index_all_configurations: false,
+        }
+    }
+
+    #[test]
+    fn test_file_name_and_header_constraints_combine() {
+        let header = "// Copyright 2024\n\n//go:build !windows && (amd64 || arm64)\n\npackage fs\n";
+        assert_eq!(
+            extract_go_file_constraint(Path::new("pkg/fs/open_linux.go"), header).as_deref(),
+            Some("(!windows && (amd64 || arm64)) && linux")
+        );
+        assert_eq!(
+            extract_go_file_constraint(Path::new("pkg/fs/stat_darwin_arm64_test.go"), "package fs\n").as_deref(),
+            Some("darwin && arm64")
+        );
+        // A bare GOOS name is not a suffix
+        assert_eq!(extract_go_file_constraint(Path::new("linux.go"), "package fs\n"), None);
+        // Constraints after the package clause are ignored
+        assert_eq!(extract_go_file_constraint(Path::new("a.go"), "package fs\n//go:build ignore\n"), None);
+        assert_eq!(
+            extract_go_header_constraint("// +build linux,386 darwin,!cgo\n// +build go1.18\n\npackage fs\n").as_deref(),
+            Some("((linux && 386) || (darwin && !cgo)) && go1.18")
+        );
+    }
+
+    #[test]
+    fn test_build_context_selects_one_configuration() {
+        let context = linux_amd64(&["integration"]);
+        let source = "package fs\n";
+        assert!(is_go_file_in_build(Path::new("open_linux.go"), source, &context));
+        assert!(!is_go_file_in_build(Path::new("open_windows.go"), source, &context));
+        assert!(!is_go_file_in_build(Path::new("open_linux_arm64.go"), source, &context));
+        assert!(is_go_file_in_build(Path::new("db.go"), "//go:build integration\n\npackage fs\n", &context));
+        assert!(!is_go_file_in_build(Path::new("db.go"), "//go:build ignore\n\npackage fs\n", &context));
+        assert!(is_go_file_in_build(Path::new("u.go"), "//go:build unix && go1.21\n\npackage fs\n", &context));
+        assert!(!is_go_file_in_build(Path::new("c.go"), "//go:build cgo\n\npackage fs\n", &context));
+        // Malformed expressions keep the file
+        assert!(is_go_file_in_build(Path::new("m.go"), "//go:build linux &&\n\npackage fs\n", &context));
+
+        let overlays = GoBuildContextConfig {
+            index_all_configurations: true,
+            ..linux_amd64(&[])
+        };
+        assert!(is_go_file_in_build(Path::new("open_windows.go"), source, &overlays));
+    }
+}
@@ -0,0 +1,570 @@
+//! Go Channel Operation Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_channel_operation_extractor
+//!
+//! Goroutines hand data to each other over channels, which the call graph
+//! cannot see: `s.jobs <- j` in one method and `for j := range s.jobs` in
+//! another are connected only through the channel. This per-file pass models
+//! each channel as a node and records:
+//!
+//! - `SendsTo`: `ch <- v` (including `select` send cases)
+//! - `ReceivesFrom`: `<-ch`, `v, ok := <-ch` and `for v := range ch`
+//!
+//! ## Channel identity
+//!
+//! Channels are keyed `go:chan:{identity}:__{package_dir}:0-0` so every file
+//! of a package agrees on the node:
+//!
+//! - Receiver or typed parameter field (`s.jobs`, `s *Server`) → `Server.jobs`
+//! - Local or parameter (`ch`) → `{Type.}Func.ch` (scoped to its function)
+//! - Package-level identifier (`events`) → `events`
+//! - Other selectors (`bus.Events`) → `bus.Events`
+//!
+//! Edge metadata: `channel_op` (`send` / `receive` / `range`) and
+//! `channel_expr` (source text of the channel operand).
+//!
+//! ## Limitations (syntactic, no go/types)
+//!
+//! - `range x` counts as a receive only when `x` is known to be a channel:
+//!   a `chan` field of a struct in the same file, a `chan` parameter, or a
+//!   local from `make(chan T)` / `var x chan T`
+//! - Channels returned by calls (`<-ctx.Done()`, `<-time.After(d)`) are skipped
+//! - A channel passed as an argument gets a new identity in the callee
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path};
+use crate::query_extractor::{
+    key_component_for_entity_type, sanitize_path_for_key_format, EntityType, ParsedEntity,
+};
+
+/// Edge metadata key: `send`, `receive` or `range`
+pub const CHANNEL_OP_METADATA_KEY: &str = "channel_op";
+
+/// Edge metadata key: channel operand as written (`s.jobs`)
+pub const CHANNEL_EXPR_METADATA_KEY: &str = "channel_expr";
+
+/// Per-function facts used to name channels and fields
+pub(crate) struct GoFunctionLocalScope {
+    /// Prefix for function-local names (`Server.Run`, `main`)
+    pub(crate) owner: String,
+    /// Receiver variable and its type name (`s`, `Server`)
+    pub(crate) receiver: Option<(String, String)>,
+    /// Parameters and locals → declared type name when known (`Server`, `models.User`)
+    pub(crate) local_types: HashMap<String, Option<String>>,
+    /// Locals/params known to hold a channel
+    pub(crate) channel_locals: HashSet<String>,
+}
+
+impl GoFunctionLocalScope {
+    /// Declared type of a variable: receiver or typed local/param
+    pub(crate) fn type_of_variable(&self, name: &str) -> Option<String> {
+        match &self.receiver {
+            Some((receiver, type_name)) if receiver == name => Some(type_name.clone()),
+            _ => self.local_types.get(name).cloned().flatten(),
+        }
+    }
+}
+
+/// Package segment of Go virtual node keys: `internal/jobs` → `__internal_jobs`
+///
+/// # 4-Word Name: format_package_scope_segment
+pub fn format_package_scope_segment(package_dir: &str) -> String {
+    format!("__{}", sanitize_path_for_key_format(package_dir))
+}
+
+/// Entity and ISGL1 key of a top-level Go function/method declaration
+pub(crate) fn find_go_declaration_entity_key<'e>(
+    declaration: tree_sitter::Node<'_>,
+    source: &str,
+    entities: &'e [ParsedEntity],
+) -> Option<(&'e ParsedEntity, String)> {
+    let name = &source[declaration.child_by_field_name("name")?.byte_range()];
+    let line = declaration.start_position().row + 1;
+    let entity = entities.iter().find(|e| {
+        // v1.7.3: numbered `init.0`, `init.1` keep their declared name in metadata
+        let declared = e.metadata.get(crate::go_package_init_extractor::DECLARED_NAME_METADATA_KEY);
+        (e.name == name || declared.is_some_and(|d| d == name))
+            && e.line_range.0 == line
+            && matches!(e.entity_type, EntityType::Function | EntityType::Method)
+    })?;
+    let key = format!(
+        "go:{}:{}:{}:T{}",
+        key_component_for_entity_type(&entity.entity_type),
+        entity.name,
+        extract_semantic_path(&entity.file_path),
+        compute_birth_timestamp(&entity.file_path, &entity.name)
+    );
+    Some((entity, key))
+}
+
+/// Receiver, parameters and body locals of one declaration
+pub(crate) fn build_go_function_local_scope(declaration: tree_sitter::Node<'_>, source: &str) -> GoFunctionLocalScope {
+    let name = declaration
+        .child_by_field_name("name")
+        .map(|n| &source[n.byte_range()])
+        .unwrap_or_default();
+    let mut scope = build_go_declaration_signature_scope(declaration, source, name);
+    if let Some(body) = declaration.child_by_field_name("body") {
+        collect_go_channel_locals(body, source, &mut scope);
+    }
+    scope
+}
+
+/// Extract SendsTo/ReceivesFrom edges for one Go file
+///
+/// # 4-Word Name: extract_go_channel_operation_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: one edge per channel operation inside a function or
+///   method, from the enclosing entity to the channel node; operations in
+///   closures are attributed to the enclosing declaration
+pub fn extract_go_channel_operation_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let package_scope = format_package_scope_segment(
+        &file_path.parent().map(|p| p.to_string_lossy().to_string()).unwrap_or_default(),
+    );
+    let channel_fields = collect_go_channel_struct_fields(root, source);
+
+    let mut edges = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, from_key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let scope = build_go_function_local_scope(declaration, source);
+
+        let mut operations = Vec::new();
+        collect_go_channel_operations(body, source, &scope, &channel_fields, &mut operations);
+        for (node, edge_type, op) in operations {
+            let Some(identity) = go_channel_identity_name(node, source, &scope) else {
+                continue;
+            };
+            let edge = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(format!("go:chan:{}:{}:0-0", identity, package_scope))
+                .edge_type(edge_type)
+                .source_location(format!("{}:{}", path_text, node.start_position().row + 1))
+                .metadata_entry(CHANNEL_OP_METADATA_KEY, op)
+                .metadata_entry(CHANNEL_EXPR_METADATA_KEY, &source[node.byte_range()])
+                .build();
+            if let Ok(edge) = edge {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+/// `(StructName, field)` pairs declared with a channel type in this file
+fn collect_go_channel_struct_fields(root: tree_sitter::Node<'_>, source: &str) -> HashSet<(String, String)> {
+    let mut fields = HashSet::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        if node.kind() == "type_spec" {
+            if let (Some(name), Some(struct_type)) = (
+                node.child_by_field_name("name"),
+                node.child_by_field_name("type").filter(|t| t.kind() == "struct_type"),
+            ) {
+                let struct_name = source[name.byte_range()].to_string();
+                let mut field_stack = vec![struct_type];
+                while let Some(field) = field_stack.pop() {
+                    if field.kind() == "field_declaration" {
+                        if field.child_by_field_name("type").is_some_and(|t| t.kind() == "channel_type") {
+                            let mut name_cursor = field.walk();
+                            for field_name in field.children_by_field_name("name", &mut name_cursor) {
+                                fields.insert((struct_name.clone(), source[field_name.byte_range()].to_string()));
+                            }
+                        }
+                        continue;
+                    }
+                    let mut cursor = field.walk();
+                    field_stack.extend(field.named_children(&mut cursor));
+                }
+            }
+            continue;
+        }
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+    }
+    fields
+}
+
+/// Type name of a declaration type node: `*Server[T]` → `Server`, `*pkg.User` → `pkg.User`
+pub(crate) fn go_declared_type_name(type_node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    match type_node.kind() {
+        "type_identifier" => Some(source[type_node.byte_range()].to_string()),
+        "qualified_type" => Some(source[type_node.byte_range()].split_whitespace().collect()),
+        "pointer_type" | "generic_type" => {
+            let mut cursor = type_node.walk();
+            let inner: Vec<_> = type_node.named_children(&mut cursor).collect();
+            inner
+                .into_iter()
+                .rev()
+                .find(|child| matches!(child.kind(), "type_identifier" | "qualified_type" | "pointer_type" | "generic_type"))
+                .and_then(|child| go_declared_type_name(child, source))
+        }
+        _ => None,
+    }
+}
+
+fn build_go_declaration_signature_scope(
+    declaration: tree_sitter::Node<'_>,
+    source: &str,
+    name: &str,
+) -> GoFunctionLocalScope {
+    let mut scope = GoFunctionLocalScope {
+        owner: name.to_string(),
+        receiver: None,
+        local_types: HashMap::new(),
+        channel_locals: HashSet::new(),
+    };
+    if let Some(receiver) = declaration.child_by_field_name("receiver") {
+        let mut cursor = receiver.walk();
+        for param in receiver.named_children(&mut cursor) {
+            let type_name = param.child_by_field_name("type").and_then(|t| go_declared_type_name(t, source));
+            if let Some(type_name) = &type_name {
+                scope.owner = format!("{}.{}", type_name, name);
+            }
+            if let (Some(param_name), Some(type_name)) = (param.child_by_field_name("name"), type_name) {
+                scope.receiver = Some((source[param_name.byte_range()].to_string(), type_name));
+            }
+        }
+    }
+    if let Some(parameters) = declaration.child_by_field_name("parameters") {
+        let mut cursor = parameters.walk();
+        for param in parameters.named_children(&mut cursor) {
+            let type_node = param.child_by_field_name("type");
+            let is_channel = type_node.is_some_and(|t| t.kind() == "channel_type");
+            let type_name = type_node.and_then(|t| go_declared_type_name(t, source));
+            let mut name_cursor = param.walk();
+            for param_name in param.children_by_field_name("name", &mut name_cursor) {
+                let local = source[param_name.byte_range()].to_string();
+                if is_channel {
+                    scope.channel_locals.insert(local.clone());
+                }
+                scope.local_types.insert(local, type_name.clone());
+            }
+        }
+    }
+    scope
+}
+
+/// True for `make(chan T)` / `make(chan T, n)`
+fn is_go_make_channel_call(value: tree_sitter::Node<'_>, source: &str) -> bool {
+    value.kind() == "call_expression"
+        && value.child_by_field_name("function").is_some_and(|f| &source[f.byte_range()] == "make")
+        && value
+            .child_by_field_name("arguments")
+            .and_then(|args| args.named_child(0))
+            .is_some_and(|first| first.kind() == "channel_type")
+}
+
+/// Record locals declared in the body and which of them are channels
+fn collect_go_channel_locals(node: tree_sitter::Node<'_>, source: &str, scope: &mut GoFunctionLocalScope) {
+    match node.kind() {
+        "short_var_declaration" => {
+            if let (Some(left), Some(right)) = (node.child_by_field_name("left"), node.child_by_field_name("right")) {
+                let mut left_cursor = left.walk();
+                let names: Vec<_> = left.named_children(&mut left_cursor).collect();
+                let mut right_cursor = right.walk();
+                let values: Vec<_> = right.named_children(&mut right_cursor).collect();
+                for (index, name) in names.iter().enumerate() {
+                    let local = source[name.byte_range()].to_string();
+                    if values.get(index).is_some_and(|v| names.len() == values.len() && is_go_make_channel_call(*v, source)) {
+                        scope.channel_locals.insert(local.clone());
+                    }
+                    scope.local_types.entry(local).or_insert(None);
+                }
+            }
+        }
+        "var_spec" => {
+            let type_node = node.child_by_field_name("type");
+            let is_channel = type_node.is_some_and(|t| t.kind() == "channel_type");
+            let type_name = type_node.and_then(|t| go_declared_type_name(t, source));
+            let values: Vec<_> = node
+                .child_by_field_name("value")
+                .map(|list| {
+                    let mut cursor = list.walk();
+                    let collected: Vec<_> = list.named_children(&mut cursor).collect();
+                    collected
+                })
+                .unwrap_or_default();
+            let mut name_cursor = node.walk();
+            for (index, name) in node.children_by_field_name("name", &mut name_cursor).enumerate() {
+                let local = source[name.byte_range()].to_string();
+                if is_channel || values.get(index).is_some_and(|v| is_go_make_channel_call(*v, source)) {
+                    scope.channel_locals.insert(local.clone());
+                }
+                scope.local_types.insert(local, type_name.clone());
+            }
+        }
+        "range_clause" => {
+            if let Some(left) = node.child_by_field_name("left") {
+                let mut cursor = left.walk();
+                for name in left.named_children(&mut cursor).filter(|n| n.kind() == "identifier") {
+                    scope.local_types.entry(source[name.byte_range()].to_string()).or_insert(None);
+                }
+            }
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_channel_locals(child, source, scope);
+    }
+}
+
+/// Channel operand nodes with their edge type and `channel_op`
+fn collect_go_channel_operations<'t>(
+    node: tree_sitter::Node<'t>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+    channel_fields: &HashSet<(String, String)>,
+    operations: &mut Vec<(tree_sitter::Node<'t>, EdgeType, &'static str)>,
+) {
+    match node.kind() {
+        "send_statement" => {
+            if let Some(channel) = node.child_by_field_name("channel") {
+                operations.push((channel, EdgeType::SendsTo, "send"));
+            }
+        }
+        "unary_expression" => {
+            let is_receive = node
+                .child_by_field_name("operator")
+                .is_some_and(|op| &source[op.byte_range()] == "<-");
+            if let Some(operand) = node.child_by_field_name("operand").filter(|_| is_receive) {
+                operations.push((operand, EdgeType::ReceivesFrom, "receive"));
+            }
+        }
+        "range_clause" => {
+            if let Some(ranged) = node
+                .child_by_field_name("right")
+                .filter(|r| is_known_go_channel_operand(*r, source, scope, channel_fields))
+            {
+                operations.push((ranged, EdgeType::ReceivesFrom, "range"));
+            }
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_channel_operations(child, source, scope, channel_fields, operations);
+    }
+}
+
+/// `range` operand declared as a channel (slices and maps range too)
+fn is_known_go_channel_operand(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+    channel_fields: &HashSet<(String, String)>,
+) -> bool {
+    match node.kind() {
+        "identifier" => scope.channel_locals.contains(&source[node.byte_range()]),
+        "selector_expression" => go_selector_owner_type(node, source, scope)
+            .zip(node.child_by_field_name("field"))
+            .is_some_and(|(owner, field)| {
+                channel_fields.contains(&(owner, source[field.byte_range()].to_string()))
+            }),
+        "parenthesized_expression" => node
+            .named_child(0)
+            .is_some_and(|inner| is_known_go_channel_operand(inner, source, scope, channel_fields)),
+        _ => false,
+    }
+}
+
+/// Declared type of a selector's operand: receiver or typed local/param
+fn go_selector_owner_type(
+    selector: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+) -> Option<String> {
+    let operand = selector.child_by_field_name("operand").filter(|o| o.kind() == "identifier")?;
+    scope.type_of_variable(&source[operand.byte_range()])
+}
+
+/// Stable channel node name (see module docs)
+fn go_channel_identity_name(node: tree_sitter::Node<'_>, source: &str, scope: &GoFunctionLocalScope) -> Option<String> {
+    match node.kind() {
+        "identifier" => {
+            let name = &source[node.byte_range()];
+            let is_local = scope.local_types.contains_key(name)
+                || scope.receiver.as_ref().is_some_and(|(receiver, _)| receiver == name);
+            Some(if is_local { format!("{}.{}", scope.owner, name) } else { name.to_string() })
+        }
+        "selector_expression" => {
+            let field = &source[node.child_by_field_name("field")?.byte_range()];
+            if let Some(owner) = go_selector_owner_type(node, source, scope) {
+                return Some(format!("{}.{}", owner, field));
+            }
+            let operand = node.child_by_field_name("operand").filter(|o| o.kind() == "identifier")?;
+            Some(format!("{}.{}", &source[operand.byte_range()], field))
+        }
+        "parenthesized_expression" => node
+            .named_child(0)
+            .and_then(|inner| go_channel_identity_name(inner, source, scope)),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_channel_fields_link_sender_and_receiver() {
+        let code = r#"
+package jobs
+
+type Pool struct {
+    work chan Job
+    done chan struct{}
+    names []string
+}
+
+func (p *Pool) Submit(j Job) {
+    p.work <- j
+}
+
+func (p *Pool) worker() {
+    for j := range p.work {
+        j.Run()
+    }
+    for _, n := range p.names {
+        log(n)
+    }
+    select {
+    case <-p.done:
+        return
+    case <-time.After(d):
+    }
+}
+
+func Drain(p *Pool) {
+    results := make(chan int, 1)
+    go func() { results <- 1 }()
+    v, ok := <-results
+    <-p.done
+    _ = v
+    _ = ok
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor
+            .parse_source(code, Path::new("internal/jobs/pool.go"), Language::Go)
+            .unwrap();
+        let channel_edges: Vec<(EdgeType, &str, &str, &str)> = edges
+            .iter()
+            .filter(|e| matches!(e.edge_type, EdgeType::SendsTo | EdgeType::ReceivesFrom))
+            .map(|e| {
+                (
+                    e.edge_type,
+                    e.from_key.as_str().split(':').nth(2).unwrap_or(""),
+                    e.to_key.as_str(),
+                    e.metadata_value(CHANNEL_OP_METADATA_KEY).unwrap_or(""),
+                )
+            })
+            .collect();
+
+        let work = "go:chan:Pool.work:__internal_jobs:0-0";
+        let done = "go:chan:Pool.done:__internal_jobs:0-0";
+        let results = "go:chan:Drain.results:__internal_jobs:0-0";
+        assert!(channel_edges.contains(&(EdgeType::SendsTo, "Submit", work, "send")));
+        assert!(channel_edges.contains(&(EdgeType::ReceivesFrom, "worker", work, "range")));
+        assert!(channel_edges.contains(&(EdgeType::ReceivesFrom, "worker", done, "receive")));
+        assert!(channel_edges.contains(&(EdgeType::ReceivesFrom, "Drain", done, "receive")));
+        assert!(channel_edges.contains(&(EdgeType::SendsTo, "Drain", results, "send")));
+        assert!(channel_edges.contains(&(EdgeType::ReceivesFrom, "Drain", results, "receive")));
+        // Slices range too; call results have no identity
+        assert_eq!(channel_edges.len(), 6, "{:?}", channel_edges);
+    }
+
+    #[test]
+    fn test_channel_identity_across_files_and_scopes() {
+        let publisher = r#"
+package bus
+
+var events = make(chan Event)
+
+func Publish(e Event) {
+    events <- e
+}
+
+func Fan(in <-chan int, out chan<- int, counts []int) {
+    for v := range in {
+        select {
+        case out <- v:
+        default:
+        }
+    }
+    for _, c := range counts {
+        _ = c
+    }
+}
+
+func Local() {
+    var events chan Event
+    (events) <- Event{}
+}
+"#;
+        let consumer = r#"
+package bus
+
+func Consume() {
+    e := <-events
+    handle(e)
+    <-remote.Events
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let mut channel_edges: Vec<(EdgeType, String, String, String)> = Vec::new();
+        for (path, code) in [("internal/bus/publish.go", publisher), ("internal/bus/consume.go", consumer)] {
+            let (_entities, edges) = extractor.parse_source(code, Path::new(path), Language::Go).unwrap();
+            channel_edges.extend(
+                edges
+                    .iter()
+                    .filter(|e| matches!(e.edge_type, EdgeType::SendsTo | EdgeType::ReceivesFrom))
+                    .map(|e| {
+                        (
+                            e.edge_type,
+                            e.from_key.as_str().split(':').nth(2).unwrap_or("").to_string(),
+                            e.to_key.as_str().to_string(),
+                            e.metadata_value(CHANNEL_OP_METADATA_KEY).unwrap_or("").to_string(),
+                        )
+                    }),
+            );
+        }
+        let has = |edge_type: EdgeType, from: &str, to: &str, op: &str| {
+            channel_edges.contains(&(edge_type, from.to_string(), to.to_string(), op.to_string()))
+        };
+
+        // A package-level channel is one node for every file of the package
+        let events = "go:chan:events:__internal_bus:0-0";
+        assert!(has(EdgeType::SendsTo, "Publish", events, "send"));
+        assert!(has(EdgeType::ReceivesFrom, "Consume", events, "receive"));
+        // Directional channel parameters are scoped to their function; select sends count
+        assert!(has(EdgeType::ReceivesFrom, "Fan", "go:chan:Fan.in:__internal_bus:0-0", "range"));
+        assert!(has(EdgeType::SendsTo, "Fan", "go:chan:Fan.out:__internal_bus:0-0", "send"));
+        // A local shadowing the package channel is its own node, parentheses and all
+        assert!(has(EdgeType::SendsTo, "Local", "go:chan:Local.events:__internal_bus:0-0", "send"));
+        // Selectors on unknown operands keep their written name
+        assert!(has(EdgeType::ReceivesFrom, "Consume", "go:chan:remote.Events:__internal_bus:0-0", "receive"));
+        // Ranging over a slice parameter is not a receive
+        assert_eq!(channel_edges.len(), 6, "{:?}", channel_edges);
+    }
+}
@@ -0,0 +1,374 @@
+//! Go Closure Literal Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_closure_literal_extractor
+//!
+//! Anonymous functions (`http.HandlerFunc(func(w, r) {...})`, `go func()
+//! {...}()`, `sort.Slice(xs, func(i, j int) bool {...})`) were folded into
+//! their enclosing function, so call chains through them lost a hop and
+//! their bodies' calls were credited to the wrong symbol. This per-file
+//! pass materializes every `func_literal` as a `Function` entity named the
+//! way Go's runtime names it in stack traces:
+//!
+//! - `Serve.func1`, `Serve.func2`: literals of `Serve`, in source order
+//! - `Server.Start.func1`: literals of method `Start` on `Server`
+//! - `Serve.func1.1`: literals nested inside `Serve.func1`
+//! - `handler.func1`: literals in a package-level var initializer
+//!
+//! Entity metadata: `enclosing_symbol` (the name above), `closure_captures`
+//! (sorted, comma-separated locals and parameters of enclosing scopes the
+//! body refers to) and `byte_span`, which lets call attribution tell a
+//! one-line closure from the call it is passed to. Closures inside methods
+//! also carry the method's `receiver_type` / `receiver_name`, so `s.Save()`
+//! in the body resolves like it does in the method.
+//!
+//! Each literal gets one edge from its enclosing symbol, with `closure:
+//! literal` and `invocation` metadata: `Spawns` for `go func() {...}()`
+//! (`goroutine`), `Calls` otherwise (`immediate`, `defer` or `value` when
+//! the literal is passed or stored).
+//!
+//! Other per-file Go passes (field access, channel operations, package
+//! values, type references) still credit a closure body to the enclosing
+//! declaration.
+
+use std::collections::{BTreeSet, HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType, Language};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_package_value_extractor::{collect_go_declared_local_names, format_go_parsed_entity_key};
+use crate::query_extractor::{EntityType, ParsedEntity};
+
+/// Entity metadata key: name of the symbol a closure is defined in
+pub const ENCLOSING_SYMBOL_METADATA_KEY: &str = "enclosing_symbol";
+
+/// Entity metadata key: captured variable names, comma-separated
+pub const CLOSURE_CAPTURES_METADATA_KEY: &str = "closure_captures";
+
+/// Entity metadata key: `start-end` byte offsets of a closure literal
+pub const BYTE_SPAN_METADATA_KEY: &str = "byte_span";
+
+/// True when `node` lies inside the `byte_span` of `entity` (or the entity has none)
+///
+/// # 4-Word Name: entity_byte_span_contains_node
+pub(crate) fn entity_byte_span_contains_node(entity: &ParsedEntity, node: tree_sitter::Node<'_>) -> bool {
+    let Some((start, end)) = entity
+        .metadata
+        .get(BYTE_SPAN_METADATA_KEY)
+        .and_then(|span| span.split_once('-'))
+        .and_then(|(start, end)| Some((start.parse::<usize>().ok()?, end.parse::<usize>().ok()?)))
+    else {
+        return true;
+    };
+    start <= node.start_byte() && node.end_byte() <= end
+}
+
+/// How a literal is used by the expression around it
+fn closure_invocation_kind(literal: tree_sitter::Node<'_>) -> &'static str {
+    let Some(call) = literal.parent().filter(|p| p.kind() == "call_expression") else {
+        return "value";
+    };
+    if call.child_by_field_name("function").map(|f| f.id()) != Some(literal.id()) {
+        return "value";
+    }
+    match call.parent().map(|p| p.kind()) {
+        Some("go_statement") => "goroutine",
+        Some("defer_statement") => "defer",
+        _ => "immediate",
+    }
+}
+
+/// Identifiers referenced under `node`
+fn collect_go_identifier_names(node: tree_sitter::Node<'_>, source: &str, names: &mut HashSet<String>) {
+    if node.kind() == "identifier" {
+        names.insert(source[node.byte_range()].to_string());
+        return;
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_identifier_names(child, source, names);
+    }
+}
+
+struct ClosureWalkState<'s> {
+    source: &'s str,
+    file_path: String,
+    /// Receiver metadata copied onto closures of a method
+    receiver: Vec<(String, String)>,
+    /// Locals and parameters of the enclosing declaration
+    declaration_locals: HashSet<String>,
+    entities: Vec<ParsedEntity>,
+    edges: Vec<DependencyEdge>,
+}
+
+impl ClosureWalkState<'_> {
+    /// Materialize the literals under `node` as children of `parent`
+    fn walk(&mut self, node: tree_sitter::Node<'_>, parent_name: &str, parent_key: &str, nested: bool) {
+        let mut ordinal = 0;
+        let mut stack = vec![node];
+        let mut literals = Vec::new();
+        while let Some(current) = stack.pop() {
+            let mut cursor = current.walk();
+            for child in current.named_children(&mut cursor) {
+                if child.kind() == "func_literal" {
+                    literals.push(child);
+                } else {
+                    stack.push(child);
+                }
+            }
+        }
+        literals.sort_by_key(|literal| literal.start_byte());
+
+        for literal in literals {
+            ordinal += 1;
+            let name = if nested {
+                format!("{}.{}", parent_name, ordinal)
+            } else {
+                format!("{}.func{}", parent_name, ordinal)
+            };
+
+            let mut own_locals = HashSet::new();
+            collect_go_declared_local_names(literal, self.source, &mut own_locals);
+            let mut referenced = HashSet::new();
+            if let Some(body) = literal.child_by_field_name("body") {
+                collect_go_identifier_names(body, self.source, &mut referenced);
+            }
+            let captures: BTreeSet<&String> = referenced
+                .iter()
+                .filter(|name| self.declaration_locals.contains(*name) && !own_locals.contains(*name))
+                .collect();
+
+            let mut metadata: HashMap<String, String> = self.receiver.iter().cloned().collect();
+            metadata.insert(ENCLOSING_SYMBOL_METADATA_KEY.to_string(), parent_name.to_string());
+            metadata.insert(BYTE_SPAN_METADATA_KEY.to_string(), format!("{}-{}", literal.start_byte(), literal.end_byte()));
+            if !captures.is_empty() {
+                let joined: Vec<&str> = captures.iter().map(|c| c.as_str()).collect();
+                metadata.insert(CLOSURE_CAPTURES_METADATA_KEY.to_string(), joined.join(","));
+            }
+            let entity = ParsedEntity {
+                entity_type: EntityType::Function,
+                name: name.clone(),
+                language: Language::Go,
+                line_range: (literal.start_position().row + 1, literal.end_position().row + 1),
+                file_path: self.file_path.clone(),
+                metadata,
+            };
+            let key = format_go_parsed_entity_key(&entity);
+
+            let invocation = closure_invocation_kind(literal);
+            let edge_type = if invocation == "goroutine" { EdgeType::Spawns } else { EdgeType::Calls };
+            if let Ok(edge) = DependencyEdge::builder()
+                .from_key(parent_key.to_string())
+                .to_key(key.clone())
+                .edge_type(edge_type)
+                .source_location(format!("{}:{}", self.file_path, literal.start_position().row + 1))
+                .metadata_entry("closure", "literal")
+                .metadata_entry("invocation", invocation)
+                .build()
+            {
+                self.edges.push(edge);
+            }
+            self.entities.push(entity);
+
+            if let Some(body) = literal.child_by_field_name("body") {
+                self.walk(body, &name, &key, true);
+            }
+        }
+    }
+}
+
+/// Closure entities and their enclosing-symbol edges for one Go file
+///
+/// # 4-Word Name: extract_go_closure_literal_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path` and
+///   include `collect_go_package_value_entities`
+/// - Postcondition: one entity and one edge per `func_literal` inside a
+///   function, method or package-level var; literals elsewhere are skipped
+/// - Names are unique per file: ordinals count per enclosing symbol
+pub fn extract_go_closure_literal_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+    let mut state = ClosureWalkState {
+        source,
+        file_path: file_path.to_string_lossy().to_string(),
+        receiver: Vec::new(),
+        declaration_locals: HashSet::new(),
+        entities: Vec::new(),
+        edges: Vec::new(),
+    };
+
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        match declaration.kind() {
+            "function_declaration" | "method_declaration" => {
+                let Some((entity, key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+                    continue;
+                };
+                let name = match entity.metadata.get("receiver_type") {
+                    Some(receiver_type) => format!("{}.{}", receiver_type, entity.name),
+                    None => entity.name.clone(),
+                };
+                state.receiver = ["receiver_type", "receiver_name"]
+                    .iter()
+                    .filter_map(|k| entity.metadata.get(*k).map(|v| (k.to_string(), v.clone())))
+                    .collect();
+                state.declaration_locals.clear();
+                collect_go_declared_local_names(declaration, source, &mut state.declaration_locals);
+                state.walk(declaration, &name, &key, false);
+            }
+            "var_declaration" => {
+                let mut stack = vec![declaration];
+                while let Some(node) = stack.pop() {
+                    if node.kind() != "var_spec" {
+                        let mut child_cursor = node.walk();
+                        stack.extend(node.named_children(&mut child_cursor));
+                        continue;
+                    }
+                    let Some(name_node) = node.child_by_field_name("name") else { continue };
+                    let name = &source[name_node.byte_range()];
+                    let line = node.start_position().row + 1;
+                    let Some(owner) = entities.iter().find(|e| {
+                        e.name == name && e.entity_type == EntityType::Variable && e.line_range.0 <= line && line <= e.line_range.1
+                    }) else {
+                        continue;
+                    };
+                    state.receiver.clear();
+                    state.declaration_locals.clear();
+                    state.walk(node, &owner.name, &format_go_parsed_entity_key(owner), false);
+                }
+            }
+            _ => {}
+        }
+    }
+    (state.entities, state.edges)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_closures_become_nodes_with_call_chains() {
+        let code = r#"
+package srv
+
+import "net/http"
+
+func Routes(mux *http.ServeMux, db *DB) {
+    prefix := "/api"
+    mux.Handle(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { serveUsers(w, db) }))
+    go func() {
+        defer func() { recoverPanic() }()
+        warmCache(db)
+    }()
+}
+
+func (s *Server) Start() {
+    func() { s.listen() }()
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("srv/routes.go"), Language::Go).unwrap();
+
+        let closures: Vec<(&str, Option<&str>)> = entities
+            .iter()
+            .filter(|e| e.metadata.contains_key(ENCLOSING_SYMBOL_METADATA_KEY))
+            .map(|e| (e.name.as_str(), e.metadata.get(CLOSURE_CAPTURES_METADATA_KEY).map(String::as_str)))
+            .collect();
+        assert_eq!(
+            closures,
+            vec![
+                ("Routes.func1", Some("db")),
+                ("Routes.func2", Some("db")),
+                ("Routes.func2.1", None),
+                ("Server.Start.func1", Some("s")),
+            ]
+        );
+        let start_closure = entities.iter().find(|e| e.name == "Server.Start.func1").unwrap();
+        assert_eq!(start_closure.metadata.get("receiver_name").map(String::as_str), Some("s"));
+
+        // Callee names may be import-qualified (`net/http.HandlerFunc`)
+        let edge_from = |from: &str, to: &str| {
+            edges.iter().find(|e| {
+                e.from_key.as_str().split(':').nth(2) == Some(from)
+                    && e.to_key.as_str().split(':').nth(2).is_some_and(|name| name == to || name.ends_with(&format!(".{}", to)))
+            })
+        };
+        // Routes → closure → serveUsers; the one-line closure does not take HandlerFunc
+        assert_eq!(edge_from("Routes", "Routes.func1").unwrap().metadata_value("invocation"), Some("value"));
+        assert!(edge_from("Routes.func1", "serveUsers").is_some());
+        assert!(edge_from("Routes", "HandlerFunc").is_some(), "{:?}", edges);
+        assert!(edge_from("Routes.func1", "HandlerFunc").is_none());
+
+        let spawn = edge_from("Routes", "Routes.func2").unwrap();
+        assert_eq!(spawn.edge_type, EdgeType::Spawns);
+        assert_eq!(edge_from("Routes.func2", "Routes.func2.1").unwrap().metadata_value("invocation"), Some("defer"));
+        assert!(edge_from("Routes.func2", "warmCache").is_some());
+        assert!(edge_from("Routes.func2.1", "recoverPanic").is_some());
+    }
+
+    #[test]
+    fn test_closure_naming_captures_and_package_vars() {
+        let code = r#"
+package srv
+
+var onBoot = func() { boot() }
+
+func Pipeline(db *DB, items []int) {
+    sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+    run := func(db *DB) {
+        func() {
+            func() {
+                flush(db)
+            }()
+        }()
+    }
+    run(db)
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("srv/pipeline.go"), Language::Go).unwrap();
+
+        // Own parameters are not captures; nested levels count from 1 under their parent
+        let closures: Vec<(&str, Option<&str>)> = entities
+            .iter()
+            .filter(|e| e.metadata.contains_key(ENCLOSING_SYMBOL_METADATA_KEY))
+            .map(|e| (e.name.as_str(), e.metadata.get(CLOSURE_CAPTURES_METADATA_KEY).map(String::as_str)))
+            .collect();
+        assert_eq!(
+            closures,
+            vec![
+                ("onBoot.func1", None),
+                ("Pipeline.func1", Some("items")),
+                ("Pipeline.func2", None),
+                ("Pipeline.func2.1", Some("db")),
+                ("Pipeline.func2.1.1", Some("db")),
+            ]
+        );
+
+        let edge_from = |from: &str, to: &str| {
+            edges.iter().find(|e| {
+                e.from_key.as_str().split(':').nth(2) == Some(from) && e.to_key.as_str().split(':').nth(2) == Some(to)
+            })
+        };
+        let invocation = |from: &str, to: &str| edge_from(from, to).and_then(|e| e.metadata_value("invocation"));
+        // Package-level initializers own their literals
+        let boot = edge_from("onBoot", "onBoot.func1").expect("var initializer closure");
+        assert!(boot.from_key.as_str().starts_with("go:var:"));
+        assert_eq!(invocation("onBoot", "onBoot.func1"), Some("value"));
+        // Arguments and stored literals are values; `func() {...}()` is immediate
+        assert_eq!(invocation("Pipeline", "Pipeline.func1"), Some("value"));
+        assert_eq!(invocation("Pipeline", "Pipeline.func2"), Some("value"));
+        assert_eq!(invocation("Pipeline.func2", "Pipeline.func2.1"), Some("immediate"));
+        assert_eq!(invocation("Pipeline.func2.1", "Pipeline.func2.1.1"), Some("immediate"));
+        // Each literal hangs off its direct parent only, and its calls move with it
+        assert!(edge_from("Pipeline", "Pipeline.func2.1").is_none());
+        assert!(edge_from("Pipeline.func2.1.1", "flush").is_some(), "{:?}", edges);
+        assert!(edge_from("Pipeline", "flush").is_none());
+    }
+}
@@ -0,0 +1,705 @@
+//! Go Context Propagation Auditor (v1.7.3)
+//!
+//! # 4-Word Naming: go_context_propagation_auditor
+//!
+//! A `context.Context` carries cancellation, deadlines and request values
+//! down a call chain. One function minting `context.Background()` in the
+//! middle cuts every callee off from the request's deadline. This module
+//! records how each Go call passes its context, then reports the breaks for
+//! `parseltongue analyze context-propagation`.
+//!
+//! ## Recorded at parse time
+//!
+//! - `context_param` on functions and methods with a `context.Context`
+//!   parameter (its name); `context_unused: true` when the body never
+//!   reads it; `context_shadowed` (line) when the body rebinds that name to
+//!   a value not derived from it (`ctx := context.Background()`)
+//! - `context_arg` on Calls edges, classifying the first argument:
+//!   `propagated` (mentions a context derived from the caller's, or a
+//!   `.Context()` call such as `r.Context()`), `background`, `todo`, `nil`,
+//!   or `other` (a variable or call not derived from it). Calls passing a
+//!   fresh Background/TODO are always classified; `nil`/`other` only while
+//!   the caller holds a context
+//! - `caller_context` on those edges: what the caller holds (`ctx`, or
+//!   `r.Context()` for `*http.Request` handlers)
+//!
+//! Derived contexts (`tctx, cancel := context.WithTimeout(ctx, d)`) count
+//! as the caller's. Calls inside a `go func() { ... }()` are not
+//! classified: detaching a goroutine from the request is deliberate.
+//!
+//! ## Issues reported
+//!
+//! - `unused`: takes a context and never reads it
+//! - `shadowed`: rebinds its context to an unrelated one
+//! - `replaced`: holds a context, passes Background/TODO (or `nil` to a
+//!   context-taking callee) instead
+//! - `unrelated`: holds a context, passes a different one to a callee that
+//!   takes a context
+//! - `chain-broken`: takes no context although context-holding functions
+//!   call it, and mints Background/TODO for its callees
+
+use std::collections::{BTreeSet, HashMap, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType as GraphEntityType};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::go_import_alias_resolver::collect_go_file_import_table;
+use crate::go_package_value_extractor::format_go_parsed_entity_key;
+use crate::query_extractor::ParsedEntity;
+
+/// Entity metadata key: name of the `context.Context` parameter
+pub const CONTEXT_PARAM_METADATA_KEY: &str = "context_param";
+
+/// Entity metadata key: the context parameter is never read
+pub const CONTEXT_UNUSED_METADATA_KEY: &str = "context_unused";
+
+/// Entity metadata key: first line rebinding the context to an unrelated value
+pub const CONTEXT_SHADOWED_METADATA_KEY: &str = "context_shadowed";
+
+/// Edge metadata key: `propagated`, `background`, `todo`, `nil` or `other`
+pub const CONTEXT_ARG_METADATA_KEY: &str = "context_arg";
+
+/// Edge metadata key: the context the caller holds (`ctx`, `r.Context()`)
+pub const CALLER_CONTEXT_METADATA_KEY: &str = "caller_context";
+
+/// One way a function breaks the context chain
+///
+/// # 4-Word Name: ContextPropagationIssueKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum ContextPropagationIssueKind {
+    Replaced,
+    Unrelated,
+    ChainBroken,
+    Shadowed,
+    Unused,
+}
+
+impl ContextPropagationIssueKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Replaced => "replaced",
+            Self::Unrelated => "unrelated",
+            Self::ChainBroken => "chain-broken",
+            Self::Shadowed => "shadowed",
+            Self::Unused => "unused",
+        }
+    }
+
+    /// Parse a `--kind` value
+    pub fn parse_issue_kind_name(value: &str) -> Option<Self> {
+        [Self::Replaced, Self::Unrelated, Self::ChainBroken, Self::Shadowed, Self::Unused]
+            .into_iter()
+            .find(|kind| kind.as_str() == value.trim())
+    }
+}
+
+/// One reported break of the context chain
+///
+/// # 4-Word Name: ContextPropagationIssueEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ContextPropagationIssueEntry {
+    pub kind: ContextPropagationIssueKind,
+    /// Function where the chain breaks
+    pub entity_key: String,
+    /// Callee handed the wrong context, for call-site issues
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub target_key: Option<String>,
+    /// `file:line`
+    pub location: Option<String>,
+    pub detail: String,
+}
+
+/// Contexts one Go declaration holds
+#[derive(Default)]
+struct GoContextScope {
+    context_alias: Option<String>,
+    /// Context parameter and every local derived from it
+    carrying: HashSet<String>,
+    /// `ctx`, or `r.Context()` for an `*http.Request` handler
+    holder: Option<String>,
+}
+
+/// Record context parameters and how each call of one Go file passes its context
+///
+/// # 4-Word Name: annotate_go_context_propagation
+///
+/// # Contract
+/// - Precondition: `entities` and `edges` come from the same parse of the
+///   file at `root`
+/// - Postcondition: entity and edge metadata as listed in the module docs;
+///   edges are matched to calls by (line, callee name); closure edges keep
+///   no `context_arg`
+/// - Returns: number of edges classified
+pub fn annotate_go_context_propagation(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    entities: &mut [ParsedEntity],
+    edges: &mut [DependencyEdge],
+) -> usize {
+    let imports = collect_go_file_import_table(root, source);
+    let alias_of = |path: &str| imports.aliases.iter().find(|(_, p)| p.as_str() == path).map(|(a, _)| a.clone());
+    let context_alias = alias_of("context");
+    let request_type = alias_of("net/http").map(|http| format!("*{}.Request", http));
+
+    let mut entity_metadata: HashMap<String, Vec<(&'static str, String)>> = HashMap::new();
+    // (line, callee name) → (context_arg, caller_context)
+    let mut call_sites: HashMap<(usize, String), (&'static str, Option<String>)> = HashMap::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+
+        let mut scope = GoContextScope { context_alias: context_alias.clone(), ..Default::default() };
+        let mut context_param = None;
+        if let Some(parameters) = declaration.child_by_field_name("parameters") {
+            let mut parameter_cursor = parameters.walk();
+            for parameter in parameters.named_children(&mut parameter_cursor) {
+                let type_text = parameter.child_by_field_name("type").map(|t| &source[t.byte_range()]);
+                let mut name_cursor = parameter.walk();
+                let names: Vec<&str> = parameter
+                    .children_by_field_name("name", &mut name_cursor)
+                    .map(|n| &source[n.byte_range()])
+                    .collect();
+                if type_text.is_some() && type_text == context_alias.as_ref().map(|a| format!("{}.Context", a)).as_deref() {
+                    if context_param.is_none() {
+                        context_param = names.first().map(|n| n.to_string());
+                    }
+                    scope.carrying.extend(names.iter().map(|n| n.to_string()));
+                } else if type_text.is_some() && type_text == request_type.as_deref() && scope.holder.is_none() {
+                    scope.holder = names.first().map(|n| format!("{}.Context()", n));
+                }
+            }
+        }
+        if let Some(name) = &context_param {
+            scope.holder = Some(name.clone());
+        }
+
+        let shadowed = collect_go_derived_contexts(body, source, &mut scope, context_param.as_deref());
+        if let Some(name) = context_param.as_ref().filter(|n| n.as_str() != "_") {
+            let metadata = entity_metadata.entry(key.clone()).or_default();
+            metadata.push((CONTEXT_PARAM_METADATA_KEY, name.clone()));
+            if !node_mentions_identifier(body, source, name) {
+                metadata.push((CONTEXT_UNUSED_METADATA_KEY, "true".to_string()));
+            }
+            if let Some(line) = shadowed {
+                metadata.push((CONTEXT_SHADOWED_METADATA_KEY, line.to_string()));
+            }
+        }
+        collect_go_context_call_sites(body, source, &scope, &mut call_sites);
+    }
+
+    for entity in entities.iter_mut() {
+        if let Some(metadata) = entity_metadata.remove(&format_go_parsed_entity_key(entity)) {
+            for (name, value) in metadata {
+                entity.metadata.insert(name.to_string(), value);
+            }
+        }
+    }
+
+    let mut classified = 0;
+    for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::Calls) {
+        let line = edge
+            .source_location
+            .as_deref()
+            .and_then(|l| l.rsplit_once(':'))
+            .and_then(|(_, line)| line.parse::<usize>().ok());
+        let name = edge.to_key.as_str().split(':').nth(2).and_then(|n| n.rsplit('.').next());
+        let (Some(line), Some(name)) = (line, name) else { continue };
+        if let Some((argument, holder)) = call_sites.get(&(line, name.to_string())) {
+            edge.metadata.insert(CONTEXT_ARG_METADATA_KEY.to_string(), argument.to_string());
+            if let Some(holder) = holder {
+                edge.metadata.insert(CALLER_CONTEXT_METADATA_KEY.to_string(), holder.clone());
+            }
+            classified += 1;
+        }
+    }
+    classified
+}
+
+/// Every break of the context chain in the stored Go graph
+///
+/// # 4-Word Name: audit_go_context_propagation
+///
+/// # Contract
+/// - Precondition: entities and edges carry the parse-time metadata of
+///   `annotate_go_context_propagation`
+/// - Postcondition: sorted by (kind, entity key, location); a callee takes
+///   a context when its entity has `context_param`, when every Go entity
+///   of a placeholder's name has one, or when its name ends in `Context`
+///   (`QueryContext`, `NewRequestWithContext`)
+pub fn audit_go_context_propagation(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<ContextPropagationIssueEntry> {
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut by_name: HashMap<&str, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities.iter().filter(|e| e.isgl1_key.starts_with("go:")) {
+        if matches!(entity.interface_signature.entity_type, GraphEntityType::Function | GraphEntityType::Method) {
+            by_name.entry(entity.interface_signature.name.as_str()).or_default().push(entity);
+        }
+    }
+    let context_param_of = |entity: &CodeEntity| entity.metadata.additional.get(CONTEXT_PARAM_METADATA_KEY).cloned();
+    let takes_context = |to_key: &str| -> bool {
+        if let Some(target) = by_key.get(to_key) {
+            return context_param_of(target).is_some();
+        }
+        let name = parse_unresolved_reference_name(to_key).unwrap_or_default();
+        let short = name.rsplit('.').next().unwrap_or(name);
+        short.ends_with("Context")
+            || by_name.get(short).is_some_and(|candidates| candidates.iter().all(|c| context_param_of(c).is_some()))
+    };
+    // Placeholders of a name only one Go function has count as calls to it
+    let resolved_target = |to_key: &str| -> Option<String> {
+        if by_key.contains_key(to_key) {
+            return Some(to_key.to_string());
+        }
+        let name = parse_unresolved_reference_name(to_key)?;
+        match by_name.get(name).map(Vec::as_slice) {
+            Some([only]) => Some(only.isgl1_key.clone()),
+            _ => None,
+        }
+    };
+    let location_of = |key: &str| {
+        by_key.get(key).map(|e| {
+            format!("{}:{}", e.interface_signature.file_path.display(), e.interface_signature.line_range.start)
+        })
+    };
+
+    // Functions holding a context, and the context-holding callers of each function
+    let mut holders: HashSet<&str> = entities
+        .iter()
+        .filter(|e| context_param_of(e).is_some())
+        .map(|e| e.isgl1_key.as_str())
+        .collect();
+    holders.extend(
+        edges
+            .iter()
+            .filter(|e| e.metadata_value(CALLER_CONTEXT_METADATA_KEY).is_some())
+            .map(|e| e.from_key.as_str()),
+    );
+    let mut holding_callers: HashMap<String, BTreeSet<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Calls && holders.contains(e.from_key.as_str())) {
+        if let Some(target) = resolved_target(edge.to_key.as_str()) {
+            holding_callers.entry(target).or_default().insert(edge.from_key.as_str());
+        }
+    }
+
+    let mut issues = Vec::new();
+    for entity in entities {
+        let Some(name) = context_param_of(entity) else { continue };
+        let additional = &entity.metadata.additional;
+        if additional.get(CONTEXT_UNUSED_METADATA_KEY).is_some_and(|v| v == "true") {
+            issues.push(ContextPropagationIssueEntry {
+                kind: ContextPropagationIssueKind::Unused,
+                entity_key: entity.isgl1_key.clone(),
+                target_key: None,
+                location: location_of(&entity.isgl1_key),
+                detail: format!("takes `{}` but never uses it", name),
+            });
+        }
+        if let Some(line) = additional.get(CONTEXT_SHADOWED_METADATA_KEY) {
+            issues.push(ContextPropagationIssueEntry {
+                kind: ContextPropagationIssueKind::Shadowed,
+                entity_key: entity.isgl1_key.clone(),
+                target_key: None,
+                location: Some(format!("{}:{}", entity.interface_signature.file_path.display(), line)),
+                detail: format!("rebinds `{}` to a context not derived from it", name),
+            });
+        }
+    }
+
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Calls) {
+        let Some(argument) = edge.metadata_value(CONTEXT_ARG_METADATA_KEY) else { continue };
+        let holder = edge.metadata_value(CALLER_CONTEXT_METADATA_KEY);
+        let callee = edge.to_key.as_str().split(':').nth(2).unwrap_or_default();
+        let fresh = match argument {
+            "background" => Some("context.Background()"),
+            "todo" => Some("context.TODO()"),
+            _ => None,
+        };
+        let (kind, detail) = match (holder, fresh, argument) {
+            (Some(holder), Some(fresh), _) => (
+                ContextPropagationIssueKind::Replaced,
+                format!("passes {} to {} although it holds `{}`", fresh, callee, holder),
+            ),
+            (Some(holder), None, "nil") if takes_context(edge.to_key.as_str()) => (
+                ContextPropagationIssueKind::Replaced,
+                format!("passes nil to {} although it holds `{}`", callee, holder),
+            ),
+            (Some(holder), None, "other") if takes_context(edge.to_key.as_str()) => (
+                ContextPropagationIssueKind::Unrelated,
+                format!("passes {} a context not derived from `{}`", callee, holder),
+            ),
+            (None, Some(fresh), _) => {
+                let Some(callers) = holding_callers.get(edge.from_key.as_str()) else { continue };
+                (
+                    ContextPropagationIssueKind::ChainBroken,
+                    format!(
+                        "takes no context but {} context-holding caller{} reach it; passes {} to {}",
+                        callers.len(),
+                        if callers.len() == 1 { "" } else { "s" },
+                        fresh,
+                        callee
+                    ),
+                )
+            }
+            _ => continue,
+        };
+        issues.push(ContextPropagationIssueEntry {
+            kind,
+            entity_key: edge.from_key.as_str().to_string(),
+            target_key: Some(edge.to_key.as_str().to_string()),
+            location: edge.source_location.clone(),
+            detail,
+        });
+    }
+
+    issues.sort_by(|a, b| (a.kind, &a.entity_key, &a.location).cmp(&(b.kind, &b.entity_key, &b.location)));
+    issues
+}
+
+/// Add locals derived from a held context to `scope.carrying`; returns the
+/// first line rebinding `context_param` to an unrelated value
+fn collect_go_derived_contexts(
+    body: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &mut GoContextScope,
+    context_param: Option<&str>,
+) -> Option<usize> {
+    let mut bindings: Vec<(tree_sitter::Node<'_>, Vec<String>, Option<tree_sitter::Node<'_>>)> = Vec::new();
+    let mut stack = vec![body];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        match node.kind() {
+            "short_var_declaration" | "assignment_statement" => {
+                let names = node
+                    .child_by_field_name("left")
+                    .map(|left| {
+                        let mut left_cursor = left.walk();
+                        let names: Vec<String> = left
+                            .named_children(&mut left_cursor)
+                            .filter(|n| n.kind() == "identifier")
+                            .map(|n| source[n.byte_range()].to_string())
+                            .collect();
+                        names
+                    })
+                    .unwrap_or_default();
+                bindings.push((node, names, node.child_by_field_name("right")));
+            }
+            "var_spec" => {
+                let mut name_cursor = node.walk();
+                let names: Vec<String> = node
+                    .children_by_field_name("name", &mut name_cursor)
+                    .map(|n| source[n.byte_range()].to_string())
+                    .collect();
+                bindings.push((node, names, node.child_by_field_name("value")));
+            }
+            // func(ctx context.Context) { ... } literals bring their own context
+            "parameter_declaration" => {
+                let type_text = node.child_by_field_name("type").map(|t| &source[t.byte_range()]);
+                if type_text.is_some() && type_text == scope.context_alias.as_ref().map(|a| format!("{}.Context", a)).as_deref() {
+                    let mut name_cursor = node.walk();
+                    let names: Vec<String> = node
+                        .children_by_field_name("name", &mut name_cursor)
+                        .map(|n| source[n.byte_range()].to_string())
+                        .collect();
+                    scope.carrying.extend(names);
+                }
+            }
+            _ => {}
+        }
+    }
+    bindings.sort_by_key(|(node, _, _)| node.start_byte());
+
+    let mut shadowed = None;
+    for (node, names, value) in bindings {
+        let derived = value.is_some_and(|v| classify_go_context_argument(v, source, scope) == "propagated");
+        if derived {
+            scope.carrying.extend(names);
+        } else if let Some(param) = context_param.filter(|p| names.iter().any(|n| n == p)) {
+            // `var ctx context.Context` without a value is a zero context too
+            scope.carrying.remove(param);
+            shadowed.get_or_insert(node.start_position().row + 1);
+        }
+    }
+    shadowed
+}
+
+/// (line, callee) → classification of the first argument of every call
+fn collect_go_context_call_sites(
+    body: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoContextScope,
+    call_sites: &mut HashMap<(usize, String), (&'static str, Option<String>)>,
+) {
+    let mut stack = vec![body];
+    while let Some(node) = stack.pop() {
+        // go func() { ... }(): detached on purpose
+        if node.kind() == "go_statement"
+            && node
+                .named_child(0)
+                .and_then(|call| call.child_by_field_name("function"))
+                .is_some_and(|f| f.kind() == "func_literal")
+        {
+            continue;
+        }
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        if node.kind() != "call_expression" {
+            continue;
+        }
+        let callee = node.child_by_field_name("function").and_then(|f| match f.kind() {
+            "identifier" => Some(f),
+            "selector_expression" => f.child_by_field_name("field"),
+            _ => None,
+        });
+        let first = node.child_by_field_name("arguments").and_then(|a| a.named_child(0));
+        let (Some(callee), Some(first)) = (callee, first) else { continue };
+        if !matches!(
+            first.kind(),
+            "identifier" | "selector_expression" | "call_expression" | "parenthesized_expression" | "nil"
+        ) {
+            continue;
+        }
+        let argument = classify_go_context_argument(first, source, scope);
+        let fresh = matches!(argument, "background" | "todo");
+        if scope.holder.is_none() && !fresh {
+            continue;
+        }
+        call_sites
+            .entry((node.start_position().row + 1, source[callee.byte_range()].to_string()))
+            .or_insert((argument, scope.holder.clone()));
+    }
+}
+
+/// `background`, `todo`, `nil`, `propagated` or `other`
+fn classify_go_context_argument(node: tree_sitter::Node<'_>, source: &str, scope: &GoContextScope) -> &'static str {
+    let text = source[node.byte_range()].trim();
+    if text == "nil" {
+        return "nil";
+    }
+    let mut fresh = None;
+    let mut propagated = false;
+    let mut stack = vec![node];
+    while let Some(current) = stack.pop() {
+        let mut cursor = current.walk();
+        stack.extend(current.named_children(&mut cursor));
+        match current.kind() {
+            "identifier" if scope.carrying.contains(&source[current.byte_range()]) => propagated = true,
+            "call_expression" => {
+                let Some(function) = current.child_by_field_name("function").filter(|f| f.kind() == "selector_expression")
+                else {
+                    continue;
+                };
+                let operand = function.child_by_field_name("operand").map(|o| &source[o.byte_range()]);
+                let field = function.child_by_field_name("field").map(|f| &source[f.byte_range()]);
+                let from_context_package = operand.is_some() && operand == scope.context_alias.as_deref();
+                match field {
+                    Some("Background") if from_context_package => fresh = fresh.or(Some("background")),
+                    Some("TODO") if from_context_package => fresh = fresh.or(Some("todo")),
+                    // r.Context(), c.Request.Context()
+                    Some("Context") => propagated = true,
+                    _ => {}
+                }
+            }
+            _ => {}
+        }
+    }
+    match (fresh, propagated) {
+        (Some(fresh), false) => fresh,
+        (_, true) => "propagated",
+        (None, false) => "other",
+    }
+}
+
+fn node_mentions_identifier(node: tree_sitter::Node<'_>, source: &str, name: &str) -> bool {
+    let mut stack = vec![node];
+    while let Some(current) = stack.pop() {
+        if current.kind() == "identifier" && &source[current.byte_range()] == name {
+            return true;
+        }
+        let mut cursor = current.walk();
+        stack.extend(current.named_children(&mut cursor));
+    }
+    false
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::query_extractor::{EntityType, QueryBasedExtractor};
+    use std::path::Path;
+
+    #[test]
+    fn test_context_breaks_are_reported_by_kind() {
+        let go = r#"package svc
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+func Save(ctx context.Context, id int) error { return ping(ctx) }
+
+func ping(ctx context.Context) error { return nil }
+
+func Handle(w http.ResponseWriter, r *http.Request) {
+	Save(r.Context(), 1)
+	sync(2)
+}
+
+func sync(id int) {
+	Save(context.TODO(), id)
+}
+
+func Process(ctx context.Context) error {
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := Save(tctx, 1); err != nil {
+		return err
+	}
+	go func() { Save(context.Background(), 2) }()
+	return Save(context.Background(), 3)
+}
+
+func Reset(ctx context.Context) {
+	ctx = context.Background()
+	Save(ctx, 4)
+}
+
+func Idle(ctx context.Context) {}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (parsed, edges) = extractor.parse_source(go, Path::new("svc/save.go"), Language::Go).unwrap();
+        let context_arg = |line: usize| -> Option<&str> {
+            let location = format!("svc/save.go:{}", line);
+            edges
+                .iter()
+                .find(|e| e.source_location.as_deref() == Some(location.as_str()) && e.to_key.as_str().contains(":Save:"))
+                .and_then(|e| e.metadata_value(CONTEXT_ARG_METADATA_KEY))
+        };
+        assert_eq!(context_arg(14), Some("propagated"));
+        assert_eq!(context_arg(19), Some("todo"));
+        assert_eq!(context_arg(25), Some("propagated"));
+        assert_eq!(context_arg(28), None, "detached goroutine");
+        assert_eq!(context_arg(29), Some("background"));
+        assert_eq!(context_arg(34), Some("other"));
+
+        let graph: Vec<CodeEntity> = parsed
+            .iter()
+            .filter(|e| e.entity_type == EntityType::Function)
+            .map(|e| {
+                let metadata: Vec<(&str, &str)> = e.metadata.iter().map(|(k, v)| (k.as_str(), v.as_str())).collect();
+                create_go_test_entity(&format_go_parsed_entity_key(e), &e.name, GraphEntityType::Function, &e.file_path, &metadata)
+            })
+            .collect();
+        let issues = audit_go_context_propagation(&graph, &edges);
+        let summary: Vec<(&str, &str)> = issues
+            .iter()
+            .map(|i| (i.kind.as_str(), i.entity_key.split(':').nth(2).unwrap()))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("replaced", "Process"),
+                ("unrelated", "Reset"),
+                ("chain-broken", "sync"),
+                ("shadowed", "Reset"),
+                ("unused", "Idle"),
+            ]
+        );
+        assert!(issues[2].detail.contains("context.TODO() to Save"));
+        assert_eq!(ContextPropagationIssueKind::parse_issue_kind_name("chain-broken"), Some(ContextPropagationIssueKind::ChainBroken));
+    }
+
+    #[test]
+    fn test_context_audit_guards_against_false_positives() {
+        let go = r#"package jobs
+
+import (
+	stdctx "context"
+	"database/sql"
+)
+
+func Save(ctx stdctx.Context, id int) error { return nil }
+
+func decode(raw []byte) error { return nil }
+
+func Run(ctx stdctx.Context, db *sql.DB, raw []byte) error {
+	scoped := stdctx.WithValue(ctx, "k", 1)
+	decode(nil)
+	label := "job"
+	logLine(label)
+	retry(func(inner stdctx.Context) error { return Save(inner, 1) })
+	other := newContext()
+	db.QueryContext(other, "select 1")
+	Save(nil, 2)
+	return Save(scoped, 3)
+}
+
+func Ignore(_ stdctx.Context) {}
+
+func main() {
+	Save(stdctx.Background(), 4)
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (parsed, edges) = extractor.parse_source(go, Path::new("jobs/run.go"), Language::Go).unwrap();
+        let call_at = |line: usize, callee: &str| -> &DependencyEdge {
+            let location = format!("jobs/run.go:{}", line);
+            edges
+                .iter()
+                .filter(|e| e.edge_type == EdgeType::Calls)
+                .find(|e| e.source_location.as_deref() == Some(location.as_str()) && e.to_key.as_str().contains(callee))
+                .unwrap_or_else(|| panic!("no call to {} at line {}", callee, line))
+        };
+        // Aliased import: derived, literal-owned and fresh contexts all classified
+        assert_eq!(call_at(14, "decode").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("nil"));
+        assert_eq!(call_at(16, "logLine").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("other"));
+        assert_eq!(call_at(17, "Save").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("propagated"));
+        assert_eq!(call_at(19, "QueryContext").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("other"));
+        assert_eq!(call_at(20, "Save").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("nil"));
+        assert_eq!(call_at(21, "Save").metadata_value(CONTEXT_ARG_METADATA_KEY), Some("propagated"));
+        assert_eq!(call_at(21, "Save").metadata_value(CALLER_CONTEXT_METADATA_KEY), Some("ctx"));
+        let fresh = call_at(27, "Save");
+        assert_eq!(fresh.metadata_value(CONTEXT_ARG_METADATA_KEY), Some("background"));
+        assert_eq!(fresh.metadata_value(CALLER_CONTEXT_METADATA_KEY), None);
+
+        let ignore = parsed.iter().find(|e| e.name == "Ignore").unwrap();
+        assert!(!ignore.metadata.contains_key(CONTEXT_PARAM_METADATA_KEY), "`_` is not reported unused");
+
+        let graph: Vec<CodeEntity> = parsed
+            .iter()
+            .filter(|e| e.entity_type == EntityType::Function)
+            .map(|e| {
+                let metadata: Vec<(&str, &str)> = e.metadata.iter().map(|(k, v)| (k.as_str(), v.as_str())).collect();
+                let key = format_go_parsed_entity_key(e);
+                create_go_test_entity(&key, &e.name, GraphEntityType::Function, &e.file_path, &metadata)
+            })
+            .collect();
+        // nil to decode, `other` to logLine and main's Background stay quiet:
+        // neither callee takes a context and no context-holding caller reaches main
+        let issues = audit_go_context_propagation(&graph, &edges);
+        let summary: Vec<(&str, &str, Option<&str>)> = issues
+            .iter()
+            .map(|i| (i.kind.as_str(), i.entity_key.split(':').nth(2).unwrap(), i.location.as_deref()))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![("replaced", "Run", Some("jobs/run.go:20")), ("unrelated", "Run", Some("jobs/run.go:19"))]
+        );
+        assert!(issues[0].detail.contains("passes nil to Save"));
+        let parse = ContextPropagationIssueKind::parse_issue_kind_name;
+        assert_eq!(parse(" unused "), Some(ContextPropagationIssueKind::Unused));
+        assert_eq!(parse("dropped"), None);
+    }
+}
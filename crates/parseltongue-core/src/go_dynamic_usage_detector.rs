@@ -0,0 +1,311 @@
+//! Go Dynamic Usage Detector (v1.7.3)
+//!
+//! # 4-Word Naming: go_dynamic_usage_detector
+//!
+//! Some Go code is reached or reaches out without a call the parser can see:
+//! `reflect.ValueOf(svc).MethodByName(name).Call(nil)`, `plugin.Open(path)`
+//! then `p.Lookup("Handler")`, or a registry of handlers looked up by name.
+//! This per-file pass flags the symbols involved with `dynamic_usage: true`
+//! and `dynamic_usage_kinds` (sorted, comma-separated), so dead-code and
+//! blast-radius reports can say static edges are incomplete there:
+//!
+//! - `reflect`: a function or var initializer that uses package `reflect`
+//! - `plugin`: one that uses package `plugin`
+//! - `registry`: a function calling through an index (`handlers[name](w, r)`),
+//!   and every same-file function stored in a `map[string]func...` literal
+//!   or assigned into a map/slice (`handlers["x"] = serveX`)
+//!
+//! Syntactic only: functions registered from another file or package, and
+//! maps of named func types (`map[string]http.HandlerFunc`) in literals,
+//! are not flagged.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+
+use crate::dead_code_detection_analyzer::DYNAMIC_USAGE_METADATA_KEY;
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_closure_literal_extractor::ENCLOSING_SYMBOL_METADATA_KEY;
+use crate::go_import_alias_resolver::collect_go_file_import_table;
+use crate::go_package_value_extractor::format_go_parsed_entity_key;
+use crate::query_extractor::{EntityType, ParsedEntity};
+
+/// Entity metadata key: `plugin`, `reflect` and/or `registry`
+pub const DYNAMIC_USAGE_KINDS_METADATA_KEY: &str = "dynamic_usage_kinds";
+
+/// Import paths whose use makes static edges incomplete
+const DYNAMIC_USAGE_IMPORT_PATHS: &[(&str, &str)] = &[("reflect", "reflect"), ("plugin", "plugin")];
+
+/// Flag the Go entities of one file that reflection or registries touch
+///
+/// # 4-Word Name: mark_go_dynamic_usage_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of the file at `root`
+/// - Postcondition: flagged entities carry `dynamic_usage: true` and
+///   `dynamic_usage_kinds`; closures are credited to their declaration
+/// - Returns: number of entities flagged
+pub fn mark_go_dynamic_usage_entities(root: tree_sitter::Node<'_>, source: &str, entities: &mut [ParsedEntity]) -> usize {
+    let table = collect_go_file_import_table(root, source);
+    let package_aliases: HashMap<&str, &'static str> = table
+        .aliases
+        .iter()
+        .filter_map(|(alias, path)| {
+            DYNAMIC_USAGE_IMPORT_PATHS
+                .iter()
+                .find(|(import_path, _)| *import_path == path.as_str())
+                .map(|(_, kind)| (alias.as_str(), *kind))
+        })
+        .collect();
+    let functions: HashMap<&str, String> = entities
+        .iter()
+        .filter(|e| e.entity_type == EntityType::Function && !e.metadata.contains_key(ENCLOSING_SYMBOL_METADATA_KEY))
+        .map(|e| (e.name.as_str(), format_go_parsed_entity_key(e)))
+        .collect();
+
+    let mut flags: BTreeMap<String, BTreeSet<&'static str>> = BTreeMap::new();
+    for (scope, owner) in collect_go_owned_scopes(root, source, entities) {
+        let mut stack = vec![scope];
+        while let Some(node) = stack.pop() {
+            let mut owner_kind = None;
+            match node.kind() {
+                "selector_expression" => {
+                    owner_kind = node
+                        .child_by_field_name("operand")
+                        .filter(|o| o.kind() == "identifier")
+                        .and_then(|o| package_aliases.get(&source[o.byte_range()]).copied());
+                }
+                "call_expression" => {
+                    if node.child_by_field_name("function").is_some_and(|f| f.kind() == "index_expression") {
+                        owner_kind = Some("registry");
+                    }
+                }
+                "composite_literal" if is_go_string_func_map_type(node.child_by_field_name("type")) => {
+                    for value in collect_go_keyed_element_values(node) {
+                        if let Some(key) = functions.get(&source[value.byte_range()]) {
+                            flags.entry(key.clone()).or_default().insert("registry");
+                        }
+                    }
+                }
+                "assignment_statement" => {
+                    let sides = (node.child_by_field_name("left"), node.child_by_field_name("right"));
+                    if let (Some(left), Some(right)) = sides {
+                        let mut left_cursor = left.walk();
+                        let mut right_cursor = right.walk();
+                        for (target, value) in left.named_children(&mut left_cursor).zip(right.named_children(&mut right_cursor)) {
+                            if target.kind() != "index_expression" || value.kind() != "identifier" {
+                                continue;
+                            }
+                            if let Some(key) = functions.get(&source[value.byte_range()]) {
+                                flags.entry(key.clone()).or_default().insert("registry");
+                            }
+                        }
+                    }
+                }
+                _ => {}
+            }
+            if let (Some(kind), Some(owner)) = (owner_kind, &owner) {
+                flags.entry(owner.clone()).or_default().insert(kind);
+            }
+            let mut child_cursor = node.walk();
+            stack.extend(node.named_children(&mut child_cursor));
+        }
+    }
+
+    let mut flagged = 0;
+    for entity in entities.iter_mut() {
+        let Some(kinds) = flags.get(&format_go_parsed_entity_key(entity)) else {
+            continue;
+        };
+        entity.metadata.insert(DYNAMIC_USAGE_METADATA_KEY.to_string(), "true".to_string());
+        entity.metadata.insert(
+            DYNAMIC_USAGE_KINDS_METADATA_KEY.to_string(),
+            kinds.iter().copied().collect::<Vec<_>>().join(","),
+        );
+        flagged += 1;
+    }
+    flagged
+}
+
+/// Function/method declarations and var specs, with the entity that owns each
+fn collect_go_owned_scopes<'t>(
+    root: tree_sitter::Node<'t>,
+    source: &str,
+    entities: &[ParsedEntity],
+) -> Vec<(tree_sitter::Node<'t>, Option<String>)> {
+    let mut scopes = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        match declaration.kind() {
+            "function_declaration" | "method_declaration" => {
+                let owner = find_go_declaration_entity_key(declaration, source, entities).map(|(_, key)| key);
+                scopes.push((declaration, owner));
+            }
+            "var_declaration" => {
+                let mut stack = vec![declaration];
+                while let Some(node) = stack.pop() {
+                    if node.kind() != "var_spec" {
+                        let mut child_cursor = node.walk();
+                        stack.extend(node.named_children(&mut child_cursor));
+                        continue;
+                    }
+                    let line = node.start_position().row + 1;
+                    let name = node.child_by_field_name("name").map(|n| &source[n.byte_range()]);
+                    let owner = entities
+                        .iter()
+                        .find(|e| {
+                            Some(e.name.as_str()) == name
+                                && e.entity_type == EntityType::Variable
+                                && e.line_range.0 <= line
+                                && line <= e.line_range.1
+                        })
+                        .map(format_go_parsed_entity_key);
+                    scopes.push((node, owner));
+                }
+            }
+            _ => {}
+        }
+    }
+    scopes
+}
+
+/// `map[string]func(...)` (one level of parentheses allowed around `func`)
+fn is_go_string_func_map_type(node: Option<tree_sitter::Node<'_>>) -> bool {
+    let Some(map_type) = node.filter(|n| n.kind() == "map_type") else {
+        return false;
+    };
+    let mut value = map_type.child_by_field_name("value");
+    while let Some(inner) = value.filter(|v| v.kind() == "parenthesized_type") {
+        value = inner.named_child(0);
+    }
+    value.is_some_and(|v| v.kind() == "function_type")
+}
+
+/// Value expressions of a composite literal's `key: value` elements
+fn collect_go_keyed_element_values(literal: tree_sitter::Node<'_>) -> Vec<tree_sitter::Node<'_>> {
+    let Some(body) = literal.child_by_field_name("body") else {
+        return Vec::new();
+    };
+    let mut values = Vec::new();
+    let mut cursor = body.walk();
+    for element in body.named_children(&mut cursor).filter(|e| e.kind() == "keyed_element") {
+        let Some(mut value) = element.named_child(element.named_child_count().saturating_sub(1)) else {
+            continue;
+        };
+        // Newer grammars wrap each side in `literal_element`
+        if value.kind() == "literal_element" {
+            match value.named_child(0) {
+                Some(inner) => value = inner,
+                None => continue,
+            }
+        }
+        values.push(value);
+    }
+    values
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+    use std::path::Path;
+
+    #[test]
+    fn test_reflection_plugins_and_registries_flagged() {
+        let code = r#"
+package cmds
+
+import (
+    "plugin"
+    r "reflect"
+)
+
+var handlers = map[string]func(args []string) error{
+    "build": runBuild,
+}
+
+func init() { handlers["clean"] = runClean }
+
+func runBuild(args []string) error { return nil }
+func runClean(args []string) error { return nil }
+func runUnused(args []string) error { return nil }
+
+func Dispatch(name string, args []string) error { return handlers[name](args) }
+
+func CallByName(v any, method string) { r.ValueOf(v).MethodByName(method).Call(nil) }
+
+func LoadPlugin(path string) { p, _ := plugin.Open(path); p.Lookup("Main") }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, _) = extractor.parse_source(code, Path::new("cmds/registry.go"), Language::Go).unwrap();
+
+        let kinds = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(DYNAMIC_USAGE_KINDS_METADATA_KEY))
+                .map(String::as_str)
+        };
+        assert_eq!(kinds("runBuild"), Some("registry"));
+        assert_eq!(kinds("runClean"), Some("registry"));
+        assert_eq!(kinds("Dispatch"), Some("registry"));
+        assert_eq!(kinds("CallByName"), Some("reflect"));
+        assert_eq!(kinds("LoadPlugin"), Some("plugin"));
+        assert_eq!(kinds("runUnused"), None);
+        assert_eq!(kinds("init"), None);
+    }
+
+    #[test]
+    fn test_dynamic_usage_kinds_combine_and_stay_on_declarations() {
+        let code = r#"
+package api
+
+import (
+    "net/http"
+    "reflect"
+)
+
+var userType = reflect.TypeOf(User{})
+
+var routes = map[string](func()){
+    "ping": ping,
+}
+
+var typed = map[string]http.HandlerFunc{
+    "health": health,
+}
+
+func ping() {}
+
+func health(w http.ResponseWriter, r *http.Request) {}
+
+func Invoke(name string, v any) {
+    go func() { reflect.ValueOf(v).Call(nil) }()
+    routes[name]()
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, _) = extractor.parse_source(code, Path::new("api/invoke.go"), Language::Go).unwrap();
+
+        let kinds = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(DYNAMIC_USAGE_KINDS_METADATA_KEY))
+                .map(String::as_str)
+        };
+        // Package-level initializers are owners too
+        assert_eq!(kinds("userType"), Some("reflect"));
+        // Parenthesized func types count; named func types do not
+        assert_eq!(kinds("ping"), Some("registry"));
+        assert_eq!(kinds("health"), None);
+        // Kinds are merged and sorted; the closure's reflection goes to Invoke
+        assert_eq!(kinds("Invoke"), Some("reflect,registry"));
+        assert_eq!(kinds("Invoke.func1"), None);
+        let flagged: Vec<&str> = entities
+            .iter()
+            .filter(|e| e.metadata.get(DYNAMIC_USAGE_METADATA_KEY).map(String::as_str) == Some("true"))
+            .map(|e| e.name.as_str())
+            .collect();
+        assert_eq!(flagged.len(), 3, "{:?}", flagged);
+    }
+}
@@ -0,0 +1,1019 @@
+//! Go Embedding & Promoted Method Resolver
+//!
+//! # 4-Word Naming: go_embedding_promotion_resolver
+//!
+//! Embedding a type in a Go struct promotes its methods: with
+//! `type Server struct { *Conn }`, `s.Close()` calls `Conn.Close`. The
+//! per-file extractor only sees `s.Close` and emits an unresolved target.
+//! This cross-file pass:
+//!
+//! 1. Points `Embeds` edges at the embedded type's entity key
+//! 2. Rewrites method calls on a method's own receiver (`s.Close()` inside
+//!    `func (s *Server) ...`), or on a param/local of a named type
+//!    (`srv.Close()` with `srv *Server`), to the method node that actually
+//!    runs, following Go's shallowest-depth promotion rule; `go s.serve()`
+//!    (`Spawns`) resolves the same way
+//! 3. Moves `Reads`/`Writes` of imported struct fields (`u.Name` with
+//!    `u *models.User`) to the field node of the declaring package
+//! 4. Points `Uses` of package values (`go:var:` placeholders) at the
+//!    const/var/func entity: bare names in the same package, qualified
+//!    names (`config.DefaultPort`) in the package matching `import_path`
+//! 5. Points package-qualified calls and literals (`import_path` metadata)
+//!    at the function/type of the imported package, and bare names of files
+//!    with dot imports (`dot_imports` metadata) at the dot-imported package
+//!
+//! ## Inputs (entity metadata from query_extractor)
+//!
+//! - Methods: `receiver_type`, `receiver_name`
+//! - Functions/methods: `local_types` (`srv=Server,u=models.User`)
+//! - Structs/interfaces: `embedded_types`; interfaces: `interface_methods`
+//! - Call edges: `receiver_expr` (plain identifier operand)
+//! - Field edges: `type_qualifier` (package alias of the field's owner type)
+//! - Import-qualified edges: `import_path` (of the qualifier in `pkg.Name`);
+//!   bare names: `dot_imports`
+//!
+//! ## Limitations (syntactic, no go/types)
+//!
+//! - Locals only get a type from a declaration or composite literal
+//!   (`var srv Server`, `srv := &Server{}`); `srv := NewServer()` stays
+//!   unresolved, as do names declared twice with different types
+//! - Types are matched by name: same package first, else a unique global match
+
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_channel_operation_extractor::format_package_scope_segment;
+use crate::go_field_access_extractor::TYPE_QUALIFIER_METADATA_KEY;
+use crate::go_import_alias_resolver::{DOT_IMPORTS_METADATA_KEY, IMPORT_PATH_METADATA_KEY};
+use crate::go_package_init_extractor::{
+    format_go_package_init_key, BLANK_IMPORT_INIT_KIND, GO_PACKAGE_INIT_KEY_PREFIX, PACKAGE_INIT_METADATA_KEY,
+};
+use crate::structural_interface_matcher::{
+    INTERFACE_METHODS_METADATA_KEY, RECEIVER_TYPE_METADATA_KEY,
+};
+
+/// Metadata key holding a Go method's receiver variable name
+pub const RECEIVER_NAME_METADATA_KEY: &str = "receiver_name";
+
+/// Metadata key holding embedded type names of a Go struct/interface
+pub const EMBEDDED_TYPES_METADATA_KEY: &str = "embedded_types";
+
+/// Metadata key holding `name=Type` pairs of a Go function's params and locals
+pub const LOCAL_TYPES_METADATA_KEY: &str = "local_types";
+
+/// Edge metadata key holding the call operand (`s` in `s.Close()`)
+pub const RECEIVER_EXPR_METADATA_KEY: &str = "receiver_expr";
+
+/// Edge metadata key holding the pre-resolution target key (v1.7.3 incremental)
+pub const UNRESOLVED_TARGET_METADATA_KEY: &str = "unresolved_target";
+
+/// Package-scoped Go type identity: (package directory, type name)
+pub type GoTypeLocationPair = (String, String);
+
+/// Where a method call on a Go type lands
+///
+/// # 4-Word Name: MethodResolutionTargetInfo
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct MethodResolutionTargetInfo {
+    /// Entity key of the method node (or interface, for interface methods)
+    pub target_key: String,
+    /// Embedded type that supplied the method (None = declared directly)
+    pub promoted_via: Option<String>,
+}
+
+/// Index of Go types, their embeddings, and their declared methods
+///
+/// # 4-Word Name: GoTypeMethodSetIndex
+pub struct GoTypeMethodSetIndex<'a> {
+    types: HashMap<GoTypeLocationPair, &'a CodeEntity>,
+    packages_by_type_name: HashMap<String, Vec<String>>,
+    methods: HashMap<GoTypeLocationPair, BTreeMap<String, String>>,
+    values: HashMap<GoTypeLocationPair, &'a CodeEntity>,
+    packages_by_value_name: HashMap<String, Vec<String>>,
+    packages: Vec<String>,
+}
+
+impl<'a> GoTypeMethodSetIndex<'a> {
+    /// Build index from ingested entities (non-Go entities ignored)
+    ///
+    /// # 4-Word Name: build_from_code_entities
+    pub fn build_from_code_entities(entities: &'a [CodeEntity]) -> Self {
+        let mut types = HashMap::new();
+        let mut packages_by_type_name: HashMap<String, Vec<String>> = HashMap::new();
+        let mut methods: HashMap<GoTypeLocationPair, BTreeMap<String, String>> = HashMap::new();
+        let mut values = HashMap::new();
+        let mut packages_by_value_name: HashMap<String, Vec<String>> = HashMap::new();
+        let mut packages = BTreeSet::new();
+
+        for entity in entities.iter().filter(|e| is_go_entity_file_path(&e.interface_signature.file_path)) {
+            let package = package_directory_of_entity(entity);
+            packages.insert(package.clone());
+            let name = entity.interface_signature.name.clone();
+            match entity.interface_signature.entity_type {
+                EntityType::Struct | EntityType::Trait | EntityType::Interface => {
+                    packages_by_type_name.entry(name.clone()).or_default().push(package.clone());
+                    types.insert((package, name), entity);
+                }
+                EntityType::Method => {
+                    if let Some(receiver) = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) {
+                        methods
+                            .entry((package, receiver.clone()))
+                            .or_default()
+                            .insert(name, entity.isgl1_key.clone());
+                    }
+                }
+                EntityType::Constant | EntityType::Variable | EntityType::Function => {
+                    packages_by_value_name.entry(name.clone()).or_default().push(package.clone());
+                    values.insert((package, name), entity);
+                }
+                _ => {}
+            }
+        }
+
+        let packages = packages.into_iter().collect();
+        Self { types, packages_by_type_name, methods, values, packages_by_value_name, packages }
+    }
+
+    /// Look up a type entity by location
+    pub fn type_entity(&self, location: &GoTypeLocationPair) -> Option<&'a CodeEntity> {
+        self.types.get(location).copied()
+    }
+
+    /// Resolve a bare type name seen from a package
+    ///
+    /// # 4-Word Name: resolve_type_name_location
+    ///
+    /// Same package wins; otherwise the name must be unique across packages.
+    pub fn resolve_type_name_location(&self, from_package: &str, type_name: &str) -> Option<GoTypeLocationPair> {
+        let packages = self.packages_by_type_name.get(type_name)?;
+        if packages.iter().any(|p| p == from_package) {
+            return Some((from_package.to_string(), type_name.to_string()));
+        }
+        match packages.as_slice() {
+            [only] => Some((only.clone(), type_name.to_string())),
+            _ => None,
+        }
+    }
+
+    /// Resolve `qualifier.Type` to the package directory named `qualifier`
+    ///
+    /// # 4-Word Name: resolve_qualified_type_location
+    ///
+    /// Import aliases are assumed to match the package directory name; two
+    /// such directories declaring the type are ambiguous → None.
+    pub fn resolve_qualified_type_location(&self, qualifier: &str, type_name: &str) -> Option<GoTypeLocationPair> {
+        let packages = self.packages_by_type_name.get(type_name)?;
+        let matching: Vec<&String> = packages
+            .iter()
+            .filter(|p| Path::new(p.as_str()).file_name().and_then(|n| n.to_str()) == Some(qualifier))
+            .collect();
+        match matching.as_slice() {
+            [only] => Some(((*only).clone(), type_name.to_string())),
+            _ => None,
+        }
+    }
+
+    /// Look up a package-level const/var/func by location
+    pub fn value_entity(&self, location: &GoTypeLocationPair) -> Option<&'a CodeEntity> {
+        self.values.get(location).copied()
+    }
+
+    /// Resolve `pkg.Name` to the package directory that best matches `import_path`
+    ///
+    /// # 4-Word Name: resolve_imported_value_location
+    ///
+    /// Directories are matched by `select_import_path_package`.
+    pub fn resolve_imported_value_location(&self, import_path: &str, value_name: &str) -> Option<GoTypeLocationPair> {
+        let packages = self.packages_by_value_name.get(value_name)?;
+        select_import_path_package(packages, import_path).map(|p| (p, value_name.to_string()))
+    }
+
+    /// Resolve `pkg.Type` to the package directory that best matches `import_path`
+    ///
+    /// # 4-Word Name: resolve_imported_type_location
+    pub fn resolve_imported_type_location(&self, import_path: &str, type_name: &str) -> Option<GoTypeLocationPair> {
+        let packages = self.packages_by_type_name.get(type_name)?;
+        select_import_path_package(packages, import_path).map(|p| (p, type_name.to_string()))
+    }
+
+    /// Package directory in the graph that best matches `import_path`
+    ///
+    /// # 4-Word Name: resolve_import_package_directory
+    pub fn resolve_import_package_directory(&self, import_path: &str) -> Option<String> {
+        select_import_path_package(&self.packages, import_path)
+    }
+
+    /// Function, const, var or type `name` of the package at `import_path`
+    ///
+    /// # 4-Word Name: resolve_import_qualified_entity
+    pub fn resolve_import_qualified_entity(&self, import_path: &str, name: &str) -> Option<&'a CodeEntity> {
+        self.resolve_imported_value_location(import_path, name)
+            .and_then(|location| self.value_entity(&location))
+            .or_else(|| {
+                self.resolve_imported_type_location(import_path, name)
+                    .and_then(|location| self.type_entity(&location))
+            })
+    }
+
+    /// True when `package` declares a value or type called `name`
+    fn package_declares_name(&self, package: &str, name: &str) -> bool {
+        let location = (package.to_string(), name.to_string());
+        self.values.contains_key(&location) || self.types.contains_key(&location)
+    }
+
+    /// Method names a type supports, including promoted ones
+    ///
+    /// # 4-Word Name: effective_method_names_set
+    pub fn effective_method_names_set(&self, location: &GoTypeLocationPair) -> BTreeSet<String> {
+        let mut names = BTreeSet::new();
+        let mut visited = HashSet::new();
+        let mut frontier = vec![location.clone()];
+        while let Some(current) = frontier.pop() {
+            if !visited.insert(current.clone()) {
+                continue;
+            }
+            names.extend(self.own_method_names_map(&current).into_keys());
+            frontier.extend(self.embedded_type_locations(&current));
+        }
+        names
+    }
+
+    /// Find the method a call `T.method` dispatches to
+    ///
+    /// # 4-Word Name: find_method_resolution_target
+    ///
+    /// Go promotion rule: the shallowest embedding depth wins; two candidates
+    /// at the same depth are ambiguous (compile error) → None.
+    pub fn find_method_resolution_target(
+        &self,
+        location: &GoTypeLocationPair,
+        method_name: &str,
+    ) -> Option<MethodResolutionTargetInfo> {
+        if let Some(key) = self.own_method_names_map(location).remove(method_name) {
+            return Some(MethodResolutionTargetInfo { target_key: key, promoted_via: None });
+        }
+
+        let mut visited: HashSet<GoTypeLocationPair> = HashSet::new();
+        visited.insert(location.clone());
+        let mut level = self.embedded_type_locations(location);
+
+        while !level.is_empty() {
+            let candidates: Vec<MethodResolutionTargetInfo> = level
+                .iter()
+                .filter_map(|embedded| {
+                    self.own_method_names_map(embedded).remove(method_name).map(|key| {
+                        MethodResolutionTargetInfo { target_key: key, promoted_via: Some(embedded.1.clone()) }
+                    })
+                })
+                .collect();
+            match candidates.len() {
+                0 => {}
+                1 => return candidates.into_iter().next(),
+                _ => return None,
+            }
+
+            let mut next_level = Vec::new();
+            for embedded in level {
+                if visited.insert(embedded.clone()) {
+                    next_level.extend(self.embedded_type_locations(&embedded));
+                }
+            }
+            next_level.retain(|l| !visited.contains(l));
+            level = next_level;
+        }
+        None
+    }
+
+    /// Directly declared methods: name → target key
+    ///
+    /// Interface methods point at the interface entity itself (no method node).
+    fn own_method_names_map(&self, location: &GoTypeLocationPair) -> BTreeMap<String, String> {
+        let mut own = self.methods.get(location).cloned().unwrap_or_default();
+        if let Some(entity) = self.types.get(location) {
+            if let Some(raw) = entity.metadata.additional.get(INTERFACE_METHODS_METADATA_KEY) {
+                for name in raw.split(',').map(str::trim).filter(|n| !n.is_empty()) {
+                    own.entry(name.to_string()).or_insert_with(|| entity.isgl1_key.clone());
+                }
+            }
+        }
+        own
+    }
+
+    /// Resolved locations of the types embedded in `location`
+    fn embedded_type_locations(&self, location: &GoTypeLocationPair) -> Vec<GoTypeLocationPair> {
+        let Some(entity) = self.types.get(location) else {
+            return Vec::new();
+        };
+        let Some(raw) = entity.metadata.additional.get(EMBEDDED_TYPES_METADATA_KEY) else {
+            return Vec::new();
+        };
+        raw.split(',')
+            .map(str::trim)
+            .filter(|n| !n.is_empty())
+            .filter_map(|n| self.resolve_type_name_location(&location.0, n))
+            .collect()
+    }
+}
+
+/// Package directory that best matches an import path
+///
+/// # 4-Word Name: select_import_path_package
+///
+/// Directories are scored by how many trailing segments match the import
+/// path (`internal/config` for `example.com/app/internal/config`); the unique
+/// best score wins, a tie is ambiguous → None.
+fn select_import_path_package(packages: &[String], import_path: &str) -> Option<String> {
+    let import_segments: Vec<&str> = import_path.split('/').rev().collect();
+    let mut scored: Vec<(usize, &String)> = packages
+        .iter()
+        .map(|p| {
+            let matching = p
+                .split('/')
+                .rev()
+                .zip(import_segments.iter())
+                .take_while(|(dir, import)| dir == *import)
+                .count();
+            (matching, p)
+        })
+        .filter(|(matching, _)| *matching > 0)
+        .collect();
+    scored.sort_by(|a, b| b.0.cmp(&a.0));
+    match scored.as_slice() {
+        [(_, only)] => Some((*only).clone()),
+        [(best, first), (second, _), ..] if best > second => Some((*first).clone()),
+        _ => None,
+    }
+}
+
+/// Target of a bare name in a file with dot imports
+///
+/// # 4-Word Name: resolve_go_dot_import_target
+///
+/// A same-package declaration wins (clashing with a dot import does not
+/// compile) → None; then a dot-imported package in the graph; then, with a
+/// single dot import, a qualified placeholder (`go:fn:strings.ToUpper:...`).
+fn resolve_go_dot_import_target(
+    index: &GoTypeMethodSetIndex<'_>,
+    package: &str,
+    name: &str,
+    dot_imports: &str,
+    key_prefix: &str,
+) -> Option<String> {
+    if index.package_declares_name(package, name) {
+        return None;
+    }
+    let paths: Vec<&str> = dot_imports.split(',').filter(|p| !p.is_empty()).collect();
+    if let Some(target) = paths.iter().find_map(|p| index.resolve_import_qualified_entity(p, name)) {
+        return Some(target.isgl1_key.clone());
+    }
+    match paths.as_slice() {
+        [only] => Some(format!("{}:{}.{}:unresolved-reference:0-0", key_prefix, only, name)),
+        _ => None,
+    }
+}
+
+/// Resolve Embeds targets and receiver method calls in place
+///
+/// # 4-Word Name: resolve_go_embedding_promoted_calls
+///
+/// # Contract
+/// - Precondition: entities carry query_extractor metadata in `metadata.additional`
+/// - Postcondition: resolved edges point at real entity keys (field nodes
+///   for Reads/Writes) and carry a `resolution` metadata entry
+///   (`embedded_type` / `receiver_method` / `promoted_method`, plus
+///   `promoted_via` / `qualified_type` / `package_value` /
+///   `imported_value` / `imported_package` / `dot_import` /
+///   `type_reference` / `imported_type`); edges of package init nodes
+///   resolve through their `import_path`; other edges are untouched
+/// - Returns: number of edges rewritten
+pub fn resolve_go_embedding_promoted_calls(
+    entities: &[CodeEntity],
+    edges: &mut [DependencyEdge],
+) -> usize {
+    let index = GoTypeMethodSetIndex::build_from_code_entities(entities);
+    let entities_by_key: HashMap<&str, &CodeEntity> = entities
+        .iter()
+        .map(|e| (e.isgl1_key.as_str(), e))
+        .collect();
+
+    let mut rewritten = 0;
+    for edge in edges.iter_mut() {
+        // v1.7.3: package init nodes are virtual; only their import edges resolve
+        if edge.from_key.as_str().starts_with(GO_PACKAGE_INIT_KEY_PREFIX) {
+            if resolve_go_package_init_target(&index, edge) {
+                rewritten += 1;
+            }
+            continue;
+        }
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else {
+            continue;
+        };
+        if !is_go_entity_file_path(&from.interface_signature.file_path) {
+            continue;
+        }
+        let Some(target_name) = parse_unresolved_reference_name(edge.to_key.as_str()) else {
+            continue;
+        };
+        let target_name = target_name.to_string();
+        let package = package_directory_of_entity(from);
+
+        match edge.edge_type {
+            EdgeType::Embeds => {
+                let resolved = index
+                    .resolve_type_name_location(&package, &target_name)
+                    .and_then(|location| index.type_entity(&location));
+                if let Some(target) = resolved {
+                    if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+                        record_unresolved_target_key(edge);
+                        edge.to_key = key;
+                        edge.metadata.insert("resolution".to_string(), "embedded_type".to_string());
+                        rewritten += 1;
+                    }
+                }
+            }
+            EdgeType::Calls | EdgeType::Spawns => {
+                if let Some(import_path) = edge.metadata.get(IMPORT_PATH_METADATA_KEY).cloned() {
+                    let name = target_name.rsplit_once('.').map_or(target_name.as_str(), |(_, n)| n);
+                    if let Some(target) = index.resolve_import_qualified_entity(&import_path, name) {
+                        retarget_go_edge_key(edge, target.isgl1_key.clone(), "imported_package");
+                        rewritten += 1;
+                    }
+                    continue;
+                }
+                if let Some(dot_imports) = edge.metadata.get(DOT_IMPORTS_METADATA_KEY).cloned() {
+                    if let Some(key) = resolve_go_dot_import_target(&index, &package, &target_name, &dot_imports, "go:fn") {
+                        retarget_go_edge_key(edge, key, "dot_import");
+                        rewritten += 1;
+                    }
+                    continue;
+                }
+                let Some(expr) = edge.metadata.get(RECEIVER_EXPR_METADATA_KEY).cloned() else {
+                    continue;
+                };
+                let own_receiver = from.metadata.additional.get(RECEIVER_NAME_METADATA_KEY) == Some(&expr);
+                let location = if own_receiver {
+                    from.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY).map(|t| (package.clone(), t.clone()))
+                } else {
+                    // v1.7.3: params and locals declared with a named type
+                    lookup_go_local_type_name(from, &expr).and_then(|type_name| match type_name.split_once('.') {
+                        Some((qualifier, name)) => index.resolve_qualified_type_location(qualifier, name),
+                        None => index.resolve_type_name_location(&package, type_name),
+                    })
+                };
+                let Some(location) = location else {
+                    continue;
+                };
+                if let Some(target) = index.find_method_resolution_target(&location, &target_name) {
+                    if let Ok(key) = Isgl1Key::new(target.target_key) {
+                        record_unresolved_target_key(edge);
+                        edge.to_key = key;
+                        match target.promoted_via {
+                            Some(via) => {
+                                edge.metadata.insert("resolution".to_string(), "promoted_method".to_string());
+                                edge.metadata.insert("promoted_via".to_string(), via);
+                            }
+                            None if own_receiver => {
+                                edge.metadata.insert("resolution".to_string(), "receiver_method".to_string());
+                            }
+                            None => {
+                                edge.metadata.insert("resolution".to_string(), "local_type_method".to_string());
+                            }
+                        }
+                        rewritten += 1;
+                    }
+                }
+            }
+            EdgeType::Reads | EdgeType::Writes => {
+                // `User.Name`, or `example.com/app/models.User.Name` once import-qualified
+                let mut segments = target_name.rsplitn(3, '.');
+                let (Some(field), Some(type_name)) = (segments.next(), segments.next()) else {
+                    continue;
+                };
+                let location = match (
+                    edge.metadata.get(IMPORT_PATH_METADATA_KEY),
+                    edge.metadata.get(TYPE_QUALIFIER_METADATA_KEY),
+                ) {
+                    (Some(import_path), _) => index.resolve_imported_type_location(import_path, type_name),
+                    (None, Some(qualifier)) => index.resolve_qualified_type_location(qualifier, type_name),
+                    (None, None) => continue,
+                };
+                if let Some(location) = location {
+                    record_unresolved_target_key(edge);
+                    edge.to_key = Isgl1Key::new_unchecked(format!(
+                        "go:field:{}.{}:{}:0-0",
+                        type_name,
+                        field,
+                        format_package_scope_segment(&location.0)
+                    ));
+                    edge.metadata.insert("resolution".to_string(), "qualified_type".to_string());
+                    rewritten += 1;
+                }
+            }
+            EdgeType::Uses if edge.to_key.as_str().starts_with("go:var:") => {
+                let resolved = match edge.metadata.get(IMPORT_PATH_METADATA_KEY) {
+                    Some(import_path) => {
+                        let name = target_name.rsplit_once('.').map_or(target_name.as_str(), |(_, n)| n);
+                        index
+                            .resolve_imported_value_location(import_path, name)
+                            .and_then(|location| index.value_entity(&location))
+                            .map(|target| (target.isgl1_key.clone(), "imported_value"))
+                    }
+                    None => index
+                        .value_entity(&(package.clone(), target_name.clone()))
+                        .map(|target| (target.isgl1_key.clone(), "package_value"))
+                        .or_else(|| {
+                            let dot_imports = edge.metadata.get(DOT_IMPORTS_METADATA_KEY)?;
+                            resolve_go_dot_import_target(&index, &package, &target_name, dot_imports, "go:var")
+                                .map(|key| (key, "dot_import"))
+                        }),
+                };
+                let Some((key, resolution)) = resolved else { continue };
+                if key == from.isgl1_key {
+                    continue;
+                }
+                retarget_go_edge_key(edge, key, resolution);
+                rewritten += 1;
+            }
+            EdgeType::Uses if edge.to_key.as_str().starts_with("go:type:") => {
+                let name = target_name.rsplit_once('.').map_or(target_name.as_str(), |(_, n)| n);
+                let resolved = match (
+                    edge.metadata.get(IMPORT_PATH_METADATA_KEY),
+                    edge.metadata.get(TYPE_QUALIFIER_METADATA_KEY),
+                ) {
+                    (Some(import_path), _) => index
+                        .resolve_imported_type_location(import_path, name)
+                        .and_then(|location| index.type_entity(&location))
+                        .map(|target| (target.isgl1_key.clone(), "imported_type")),
+                    (None, Some(qualifier)) => index
+                        .resolve_qualified_type_location(qualifier, name)
+                        .and_then(|location| index.type_entity(&location))
+                        .map(|target| (target.isgl1_key.clone(), "qualified_type")),
+                    (None, None) => index
+                        .resolve_type_name_location(&package, &target_name)
+                        .and_then(|location| index.type_entity(&location))
+                        .map(|target| (target.isgl1_key.clone(), "type_reference"))
+                        .or_else(|| {
+                            let dot_imports = edge.metadata.get(DOT_IMPORTS_METADATA_KEY)?;
+                            resolve_go_dot_import_target(&index, &package, &target_name, dot_imports, "go:type")
+                                .map(|key| (key, "dot_import"))
+                        }),
+                };
+                let Some((key, resolution)) = resolved else { continue };
+                if key == from.isgl1_key {
+                    continue;
+                }
+                retarget_go_edge_key(edge, key, resolution);
+                rewritten += 1;
+            }
+            _ => {}
+        }
+    }
+    rewritten
+}
+
+/// Declared type of a param/local of `entity` (`local_types` metadata)
+fn lookup_go_local_type_name<'e>(entity: &'e CodeEntity, local: &str) -> Option<&'e str> {
+    entity
+        .metadata
+        .additional
+        .get(LOCAL_TYPES_METADATA_KEY)?
+        .split(',')
+        .filter_map(|pair| pair.split_once('='))
+        .find(|(name, _)| *name == local)
+        .map(|(_, type_name)| type_name)
+}
+
+/// Point an edge at `target_key`, remembering the unresolved key
+fn retarget_go_edge_key(edge: &mut DependencyEdge, target_key: String, resolution: &str) {
+    record_unresolved_target_key(edge);
+    edge.to_key = Isgl1Key::new_unchecked(target_key);
+    edge.metadata.insert("resolution".to_string(), resolution.to_string());
+}
+
+/// Blank import → that package's init node; `var _ = pkg.F()` → `F`
+fn resolve_go_package_init_target(index: &GoTypeMethodSetIndex<'_>, edge: &mut DependencyEdge) -> bool {
+    let Some(import_path) = edge.metadata.get(IMPORT_PATH_METADATA_KEY).cloned() else {
+        return false;
+    };
+    let Some(target_name) = parse_unresolved_reference_name(edge.to_key.as_str()).map(str::to_string) else {
+        return false;
+    };
+    let target_key = if edge.metadata_value(PACKAGE_INIT_METADATA_KEY) == Some(BLANK_IMPORT_INIT_KIND) {
+        index.resolve_import_package_directory(&import_path).map(|p| format_go_package_init_key(&p))
+    } else {
+        let name = target_name.rsplit_once('.').map_or(target_name.as_str(), |(_, n)| n);
+        index.resolve_import_qualified_entity(&import_path, name).map(|t| t.isgl1_key.clone())
+    };
+    match target_key {
+        Some(key) => {
+            retarget_go_edge_key(edge, key, "imported_package");
+            true
+        }
+        None => false,
+    }
+}
+
+/// Remember the unresolved key so incremental runs can re-resolve the edge
+fn record_unresolved_target_key(edge: &mut DependencyEdge) {
+    edge.metadata.insert(
+        UNRESOLVED_TARGET_METADATA_KEY.to_string(),
+        edge.to_key.as_str().to_string(),
+    );
+}
+
+/// Undo resolution so edges can be resolved again against a changed graph
+///
+/// # 4-Word Name: revert_go_resolved_edge_targets
+///
+/// # Contract
+/// - Postcondition: edges carrying `unresolved_target` point back at it and
+///   lose their `resolution` / `promoted_via` / `unresolved_target` entries
+/// - Returns: number of edges reverted
+pub fn revert_go_resolved_edge_targets(edges: &mut [DependencyEdge]) -> usize {
+    let mut reverted = 0;
+    for edge in edges.iter_mut() {
+        let Some(original) = edge.metadata.remove(UNRESOLVED_TARGET_METADATA_KEY) else {
+            continue;
+        };
+        edge.metadata.remove("resolution");
+        edge.metadata.remove("promoted_via");
+        edge.to_key = Isgl1Key::new_unchecked(original);
+        reverted += 1;
+    }
+    reverted
+}
+
+/// Keys of Go entities whose cross-file edges may change
+///
+/// # 4-Word Name: collect_go_dirty_subgraph_keys
+///
+/// # Contract
+/// - Precondition: `changed_packages` = package directories of changed/deleted files
+/// - Postcondition: every Go entity in a changed package, plus every type that
+///   (transitively) embeds a type from one, plus the methods of those types
+/// - Use: scope for re-running Embeds/promotion and structural Implements passes
+pub fn collect_go_dirty_subgraph_keys(
+    entities: &[CodeEntity],
+    changed_packages: &HashSet<String>,
+) -> HashSet<String> {
+    let index = GoTypeMethodSetIndex::build_from_code_entities(entities);
+
+    let mut dirty: HashSet<GoTypeLocationPair> = index
+        .types
+        .keys()
+        .filter(|location| changed_packages.contains(&location.0))
+        .cloned()
+        .collect();
+
+    // Fixpoint: an embedder of a dirty type has a dirty method set
+    loop {
+        let newly_dirty: Vec<GoTypeLocationPair> = index
+            .types
+            .keys()
+            .filter(|location| !dirty.contains(*location))
+            .filter(|location| index.embedded_type_locations(location).iter().any(|e| dirty.contains(e)))
+            .cloned()
+            .collect();
+        if newly_dirty.is_empty() {
+            break;
+        }
+        dirty.extend(newly_dirty);
+    }
+
+    let mut keys: HashSet<String> = entities
+        .iter()
+        .filter(|e| is_go_entity_file_path(&e.interface_signature.file_path))
+        .filter(|e| changed_packages.contains(&package_directory_of_entity(e)))
+        .map(|e| e.isgl1_key.clone())
+        .collect();
+    for location in &dirty {
+        if let Some(entity) = index.type_entity(location) {
+            keys.insert(entity.isgl1_key.clone());
+        }
+        if let Some(methods) = index.methods.get(location) {
+            keys.extend(methods.values().cloned());
+        }
+    }
+    keys
+}
+
+/// Extract NAME from `lang:kind:NAME:unresolved-reference:0-0`
+///
+/// # 4-Word Name: parse_unresolved_reference_name
+pub fn parse_unresolved_reference_name(key: &str) -> Option<&str> {
+    let parts: Vec<&str> = key.split(':').collect();
+    match parts.as_slice() {
+        [_, _, name, "unresolved-reference", _] => Some(name),
+        _ => None,
+    }
+}
+
+/// Package directory used to scope Go types and methods
+///
+/// # 4-Word Name: package_directory_of_entity
+pub fn package_directory_of_entity(entity: &CodeEntity) -> String {
+    entity
+        .interface_signature
+        .file_path
+        .parent()
+        .map(|p| p.to_string_lossy().to_string())
+        .unwrap_or_default()
+}
+
+/// Check for `.go` extension
+///
+/// # 4-Word Name: is_go_entity_file_path
+pub fn is_go_entity_file_path(path: &Path) -> bool {
+    path.extension().and_then(|e| e.to_str()) == Some("go")
+}
+
+/// Build a Go CodeEntity with extractor metadata (test fixture)
+#[cfg(test)]
+pub(crate) fn create_go_test_entity(
+    key: &str,
+    name: &str,
+    entity_type: EntityType,
+    file: &str,
+    metadata: &[(&str, &str)],
+) -> CodeEntity {
+    use crate::entities::{
+        EntityClass, InterfaceSignature, LanguageSpecificSignature, LineRange, RustSignature,
+        Visibility,
+    };
+
+    let signature = InterfaceSignature {
+        entity_type,
+        name: name.to_string(),
+        visibility: Visibility::Public,
+        file_path: std::path::PathBuf::from(file),
+        line_range: LineRange::new(1, 5).unwrap(),
+        module_path: vec![],
+        documentation: None,
+        language_specific: LanguageSpecificSignature::Rust(RustSignature {
+            generics: vec![],
+            lifetimes: vec![],
+            where_clauses: vec![],
+            attributes: vec![],
+            trait_impl: None,
+        }),
+    };
+    let mut entity =
+        CodeEntity::new(key.to_string(), signature, EntityClass::CodeImplementation).unwrap();
+    for (k, v) in metadata {
+        entity.metadata.additional.insert(k.to_string(), v.to_string());
+    }
+    entity
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn unresolved_call(from: &str, name: &str, receiver: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(format!("go:fn:{}:unresolved-reference:0-0", name))
+            .edge_type(EdgeType::Calls)
+            .metadata_entry(RECEIVER_EXPR_METADATA_KEY, receiver)
+            .build()
+            .unwrap()
+    }
+
+    /// Server embeds *Conn (same package); Conn embeds Logger (other package)
+    fn server_fixture() -> Vec<CodeEntity> {
+        vec![
+            create_go_test_entity("go:struct:Server:__srv:T1", "Server", EntityType::Struct,
+                "srv/server.go", &[(EMBEDDED_TYPES_METADATA_KEY, "Conn")]),
+            create_go_test_entity("go:struct:Conn:__srv:T2", "Conn", EntityType::Struct,
+                "srv/conn.go", &[(EMBEDDED_TYPES_METADATA_KEY, "Logger")]),
+            create_go_test_entity("go:struct:Logger:__log:T3", "Logger", EntityType::Struct,
+                "log/logger.go", &[]),
+            create_go_test_entity("go:method:Close:__srv_conn:T4", "Close", EntityType::Method,
+                "srv/conn.go", &[(RECEIVER_TYPE_METADATA_KEY, "Conn"), (RECEIVER_NAME_METADATA_KEY, "c")]),
+            create_go_test_entity("go:method:Logf:__log:T5", "Logf", EntityType::Method,
+                "log/logger.go", &[(RECEIVER_TYPE_METADATA_KEY, "Logger"), (RECEIVER_NAME_METADATA_KEY, "l")]),
+            create_go_test_entity("go:method:Shutdown:__srv:T6", "Shutdown", EntityType::Method,
+                "srv/server.go", &[(RECEIVER_TYPE_METADATA_KEY, "Server"), (RECEIVER_NAME_METADATA_KEY, "s")]),
+            create_go_test_entity("go:method:Stop:__srv:T7", "Stop", EntityType::Method,
+                "srv/server.go", &[(RECEIVER_TYPE_METADATA_KEY, "Server"), (RECEIVER_NAME_METADATA_KEY, "s")]),
+        ]
+    }
+
+    #[test]
+    fn test_promoted_method_call_resolves_to_embedded_method() {
+        let entities = server_fixture();
+        let mut edges = vec![unresolved_call("go:method:Shutdown:__srv:T6", "Close", "s")];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "go:method:Close:__srv_conn:T4");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("promoted_method"));
+        assert_eq!(edges[0].metadata_value("promoted_via"), Some("Conn"));
+    }
+
+    #[test]
+    fn test_two_level_promotion_crosses_packages() {
+        let entities = server_fixture();
+        let mut edges = vec![unresolved_call("go:method:Shutdown:__srv:T6", "Logf", "s")];
+
+        resolve_go_embedding_promoted_calls(&entities, &mut edges);
+        assert_eq!(edges[0].to_key.as_str(), "go:method:Logf:__log:T5");
+        assert_eq!(edges[0].metadata_value("promoted_via"), Some("Logger"));
+    }
+
+    #[test]
+    fn test_own_method_wins_and_other_receivers_untouched() {
+        let entities = server_fixture();
+        let mut edges = vec![
+            unresolved_call("go:method:Shutdown:__srv:T6", "Stop", "s"),
+            // Operand is not the receiver: no type info, stay unresolved
+            unresolved_call("go:method:Shutdown:__srv:T6", "Close", "other"),
+        ];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "go:method:Stop:__srv:T7");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("receiver_method"));
+        assert_eq!(edges[1].to_key.as_str(), "go:fn:Close:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_promoted_call_on_param_and_local_of_embedding_type() {
+        // `func Drain(s *Server) { s.Close() }`; `srv := &srv.Server{}; srv.Logf()` in cmd
+        let mut entities = server_fixture();
+        entities.push(create_go_test_entity("go:fn:Drain:__srv:T8", "Drain", EntityType::Function,
+            "srv/drain.go", &[(LOCAL_TYPES_METADATA_KEY, "s=Server")]));
+        entities.push(create_go_test_entity("go:fn:main:__cmd:T9", "main", EntityType::Function,
+            "cmd/main.go", &[(LOCAL_TYPES_METADATA_KEY, "conn=srv.Conn,srv=srv.Server")]));
+        let mut edges = vec![
+            unresolved_call("go:fn:Drain:__srv:T8", "Close", "s"),
+            unresolved_call("go:fn:main:__cmd:T9", "Logf", "srv"),
+            unresolved_call("go:fn:main:__cmd:T9", "Close", "conn"),
+            unresolved_call("go:fn:main:__cmd:T9", "Close", "untyped"),
+        ];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 3);
+        assert_eq!(edges[0].to_key.as_str(), "go:method:Close:__srv_conn:T4");
+        assert_eq!(edges[0].metadata_value("promoted_via"), Some("Conn"));
+        assert_eq!(edges[1].to_key.as_str(), "go:method:Logf:__log:T5");
+        assert_eq!(edges[1].metadata_value("promoted_via"), Some("Logger"));
+        assert_eq!(edges[2].to_key.as_str(), "go:method:Close:__srv_conn:T4");
+        assert_eq!(edges[2].metadata_value("resolution"), Some("local_type_method"));
+        assert_eq!(edges[3].to_key.as_str(), "go:fn:Close:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_embeds_edge_points_at_type_entity() {
+        let entities = server_fixture();
+        let mut edges = vec![DependencyEdge::builder()
+            .from_key("go:struct:Server:__srv:T1")
+            .to_key("go:fn:Conn:unresolved-reference:0-0")
+            .edge_type(EdgeType::Embeds)
+            .build()
+            .unwrap()];
+
+        resolve_go_embedding_promoted_calls(&entities, &mut edges);
+        assert_eq!(edges[0].to_key.as_str(), "go:struct:Conn:__srv:T2");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("embedded_type"));
+    }
+
+    #[test]
+    fn test_ambiguous_promotion_at_same_depth_unresolved() {
+        let entities = vec![
+            create_go_test_entity("go:struct:Both:__p:T1", "Both", EntityType::Struct,
+                "p/both.go", &[(EMBEDDED_TYPES_METADATA_KEY, "A,B")]),
+            create_go_test_entity("go:struct:A:__p:T2", "A", EntityType::Struct, "p/a.go", &[]),
+            create_go_test_entity("go:struct:B:__p:T3", "B", EntityType::Struct, "p/b.go", &[]),
+            create_go_test_entity("go:method:Close:__p_a:T4", "Close", EntityType::Method,
+                "p/a.go", &[(RECEIVER_TYPE_METADATA_KEY, "A")]),
+            create_go_test_entity("go:method:Close:__p_b:T5", "Close", EntityType::Method,
+                "p/b.go", &[(RECEIVER_TYPE_METADATA_KEY, "B")]),
+        ];
+        let index = GoTypeMethodSetIndex::build_from_code_entities(&entities);
+        let both = ("p".to_string(), "Both".to_string());
+
+        assert_eq!(index.find_method_resolution_target(&both, "Close"), None);
+        assert!(index.effective_method_names_set(&both).contains("Close"));
+    }
+
+    #[test]
+    fn test_imported_field_moves_to_declaring_package() {
+        let entities = server_fixture();
+        let mut edges = vec![DependencyEdge::builder()
+            .from_key("go:method:Shutdown:__srv:T6")
+            .to_key("go:field:Logger.Prefix:unresolved-reference:0-0")
+            .edge_type(EdgeType::Writes)
+            .metadata_entry(TYPE_QUALIFIER_METADATA_KEY, "log")
+            .build()
+            .unwrap()];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "go:field:Logger.Prefix:__log:0-0");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("qualified_type"));
+    }
+
+    #[test]
+    fn test_package_values_resolve_by_package_and_import() {
+        let mut entities = server_fixture();
+        entities.push(create_go_test_entity("go:const:DefaultPort:__app_config:T8", "DefaultPort",
+            EntityType::Constant, "app/config/defaults.go", &[]));
+        entities.push(create_go_test_entity("go:const:DefaultPort:__vendor_config:T9", "DefaultPort",
+            EntityType::Constant, "vendor/config/defaults.go", &[]));
+        entities.push(create_go_test_entity("go:var:registry:__srv:T10", "registry",
+            EntityType::Variable, "srv/registry.go", &[]));
+        let value_edge = |to: &str, import_path: Option<&str>| {
+            let builder = DependencyEdge::builder()
+                .from_key("go:method:Shutdown:__srv:T6")
+                .to_key(to)
+                .edge_type(EdgeType::Uses);
+            let builder = match import_path {
+                Some(path) => builder.metadata_entry(IMPORT_PATH_METADATA_KEY, path),
+                None => builder,
+            };
+            builder.build().unwrap()
+        };
+        let mut edges = vec![
+            value_edge("go:var:registry:unresolved-reference:0-0", None),
+            value_edge("go:var:example.com/app/config.DefaultPort:unresolved-reference:0-0", Some("example.com/app/config")),
+            value_edge("go:var:net/http.StatusOK:unresolved-reference:0-0", Some("net/http")),
+        ];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 2);
+        assert_eq!(edges[0].to_key.as_str(), "go:var:registry:__srv:T10");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("package_value"));
+        assert_eq!(edges[1].to_key.as_str(), "go:const:DefaultPort:__app_config:T8");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("imported_value"));
+        assert_eq!(edges[2].to_key.as_str(), "go:var:net/http.StatusOK:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_import_qualified_and_dot_imported_calls_resolve() {
+        let mut entities = server_fixture();
+        entities.push(create_go_test_entity("go:fn:Load:__app_config:T8", "Load",
+            EntityType::Function, "app/config/load.go", &[]));
+        entities.push(create_go_test_entity("go:fn:Helper:__srv:T9", "Helper",
+            EntityType::Function, "srv/helper.go", &[]));
+        let call = |to: &str, key: &str, value: &str| {
+            DependencyEdge::builder()
+                .from_key("go:method:Shutdown:__srv:T6")
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+                .metadata_entry(key, value)
+                .build()
+                .unwrap()
+        };
+        let mut edges = vec![
+            call("go:fn:example.com/app/config.Load:unresolved-reference:0-0", IMPORT_PATH_METADATA_KEY, "example.com/app/config"),
+            call("go:fn:Load:unresolved-reference:0-0", DOT_IMPORTS_METADATA_KEY, "example.com/app/config"),
+            // Same package wins over the dot import
+            call("go:fn:Helper:unresolved-reference:0-0", DOT_IMPORTS_METADATA_KEY, "strings"),
+            call("go:fn:ToUpper:unresolved-reference:0-0", DOT_IMPORTS_METADATA_KEY, "strings"),
+        ];
+
+        assert_eq!(resolve_go_embedding_promoted_calls(&entities, &mut edges), 3);
+        assert_eq!(edges[0].to_key.as_str(), "go:fn:Load:__app_config:T8");
+        assert_eq!(edges[0].metadata_value("resolution"), Some("imported_package"));
+        assert_eq!(edges[1].to_key.as_str(), "go:fn:Load:__app_config:T8");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("dot_import"));
+        assert_eq!(edges[2].to_key.as_str(), "go:fn:Helper:unresolved-reference:0-0");
+        assert_eq!(edges[3].to_key.as_str(), "go:fn:strings.ToUpper:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_revert_restores_unresolved_target() {
+        let entities = server_fixture();
+        let mut edges = vec![unresolved_call("go:method:Shutdown:__srv:T6", "Close", "s")];
+        resolve_go_embedding_promoted_calls(&entities, &mut edges);
+        assert_eq!(
+            edges[0].metadata_value(UNRESOLVED_TARGET_METADATA_KEY),
+            Some("go:fn:Close:unresolved-reference:0-0")
+        );
+
+        assert_eq!(revert_go_resolved_edge_targets(&mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "go:fn:Close:unresolved-reference:0-0");
+        assert_eq!(edges[0].metadata_value("resolution"), None);
+        assert_eq!(edges[0].metadata_value(RECEIVER_EXPR_METADATA_KEY), Some("s"));
+    }
+
+    #[test]
+    fn test_dirty_subgraph_follows_embedders_across_packages() {
+        let entities = server_fixture();
+        // Logger's package changed: Conn embeds Logger, Server embeds Conn
+        let changed: HashSet<String> = ["log".to_string()].into_iter().collect();
+        let dirty = collect_go_dirty_subgraph_keys(&entities, &changed);
+
+        for key in ["go:struct:Logger:__log:T3", "go:method:Logf:__log:T5",
+                    "go:struct:Conn:__srv:T2", "go:struct:Server:__srv:T1",
+                    "go:method:Shutdown:__srv:T6"] {
+            assert!(dirty.contains(key), "{} should be dirty: {:?}", key, dirty);
+        }
+
+        let unrelated: HashSet<String> = ["other".to_string()].into_iter().collect();
+        assert!(collect_go_dirty_subgraph_keys(&entities, &unrelated).is_empty());
+    }
+
+    #[test]
+    fn test_parse_unresolved_reference_name() {
+        assert_eq!(parse_unresolved_reference_name("go:fn:Close:unresolved-reference:0-0"), Some("Close"));
+        assert_eq!(parse_unresolved_reference_name("go:method:Close:__srv:T4"), None);
+    }
+}
@@ -0,0 +1,541 @@
+//! Go Error Propagation Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_error_propagation_extractor
+//!
+//! Go errors travel by hand: `if err != nil { return ..., err }` hands a
+//! callee's error to the caller, `fmt.Errorf("load: %w", err)` wraps it on
+//! the way, and `return ErrNotFound` starts a sentinel's journey. This pass
+//! makes those hops explicit as `PropagatesErrorTo` edges, from the origin
+//! (the called function or the sentinel var) to the function returning it,
+//! so "where can `ErrNotFound` surface?" is a forward walk.
+//!
+//! Two steps, because origins are often in other files:
+//!
+//! 1. Per file, `annotate_go_error_propagation_edges` tags the `Calls` edge
+//!    of every call whose error is returned, and the `Uses` edge of every
+//!    returned sentinel, with `error_flow`: `returned` or `wrapped` (`%w`)
+//! 2. After cross-file resolution, `derive_go_error_propagation_edges` turns
+//!    each tagged edge around into a `PropagatesErrorTo` edge with the same
+//!    `error_flow` and site
+//!
+//! ## Heuristics (syntactic, no go/types)
+//!
+//! - Error variables are named `err` or end in `Err`/`err` (`readErr`); the
+//!   last call assigned to one before a `return` of it is its origin
+//! - `return f(x)` / `return v, f(x)`: a call in last position is returned
+//! - Sentinels are identifiers named `ErrXxx` / `errXxx` (also `pkg.ErrXxx`)
+//! - Only `fmt.Errorf` with `%w` counts as wrapping; `%v` starts a new error
+//!
+//! `trace_error_surface_sites` answers the query: every function an origin's
+//! error reaches, by hop count, with the functions where it stops (exported
+//! API, `main`, handlers) marked `terminal`.
+
+use std::collections::{BTreeSet, HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::go_import_alias_resolver::collect_go_file_import_table;
+
+/// Edge metadata key: `returned` or `wrapped`
+pub const ERROR_FLOW_METADATA_KEY: &str = "error_flow";
+
+/// Function an error can surface in, reached from the traced origin
+///
+/// # 4-Word Name: ErrorSurfaceSiteEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct ErrorSurfaceSiteEntry {
+    pub entity_key: String,
+    /// Propagation hops from the origin (1 = returns the origin's error)
+    pub depth: usize,
+    /// Origin or function this site got the error from
+    pub via_key: String,
+    /// `returned` or `wrapped`
+    pub error_flow: String,
+    pub source_location: Option<String>,
+    /// Nothing propagates the error further: it is handled or leaves here
+    pub terminal: bool,
+}
+
+/// Facts gathered for one file's return statements
+#[derive(Default)]
+struct ErrorFlowSiteTable {
+    /// (call line, callee name) → flow
+    calls: HashMap<(usize, String), &'static str>,
+    /// (declaration start, declaration end, sentinel name) → flow
+    sentinels: Vec<(usize, usize, String, &'static str)>,
+}
+
+struct ErrorFlowWalkContext<'s> {
+    source: &'s str,
+    fmt_alias: Option<String>,
+    declaration_lines: (usize, usize),
+    /// Error variable → (call line, callee name) of its latest assignment
+    origins: HashMap<String, (usize, String)>,
+}
+
+impl<'s> ErrorFlowWalkContext<'s> {
+    fn text(&self, node: tree_sitter::Node<'_>) -> &'s str {
+        &self.source[node.byte_range()]
+    }
+
+    /// Walk one function body in source order; nested literals get their own walk
+    fn walk(&mut self, node: tree_sitter::Node<'_>, table: &mut ErrorFlowSiteTable) {
+        match node.kind() {
+            "func_literal" => {
+                let saved = std::mem::take(&mut self.origins);
+                if let Some(body) = node.child_by_field_name("body") {
+                    self.walk(body, table);
+                }
+                self.origins = saved;
+                return;
+            }
+            "short_var_declaration" | "assignment_statement" => self.record_assignment(node),
+            "return_statement" => self.record_return(node, table),
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        let children: Vec<_> = node.named_children(&mut cursor).collect();
+        for child in children {
+            self.walk(child, table);
+        }
+    }
+
+    /// `v, err := f(x)` / `err = s.repo.Save(u)`
+    fn record_assignment(&mut self, node: tree_sitter::Node<'_>) {
+        let (Some(left), Some(right)) = (node.child_by_field_name("left"), node.child_by_field_name("right")) else {
+            return;
+        };
+        let mut right_cursor = right.walk();
+        let values: Vec<_> = right.named_children(&mut right_cursor).collect();
+        let [call] = values.as_slice() else { return };
+        let Some(callee) = self.callee_name(*call) else { return };
+        let line = call.start_position().row + 1;
+        let mut left_cursor = left.walk();
+        for target in left.named_children(&mut left_cursor).filter(|t| t.kind() == "identifier") {
+            let name = self.text(target);
+            if is_go_error_variable_name(name) {
+                self.origins.insert(name.to_string(), (line, callee.to_string()));
+            }
+        }
+    }
+
+    fn record_return(&self, node: tree_sitter::Node<'_>, table: &mut ErrorFlowSiteTable) {
+        let mut cursor = node.walk();
+        let mut results: Vec<_> = node.named_children(&mut cursor).collect();
+        if let [list] = results.as_slice() {
+            if list.kind() == "expression_list" {
+                let mut list_cursor = list.walk();
+                results = list.named_children(&mut list_cursor).collect();
+            }
+        }
+        let last = results.len().saturating_sub(1);
+        for (index, result) in results.iter().enumerate() {
+            if result.kind() == "call_expression" {
+                if let Some(arguments) = self.wrapped_error_arguments(*result) {
+                    for argument in arguments {
+                        self.record_returned_value(argument, "wrapped", table);
+                    }
+                } else if index == last {
+                    if let Some(callee) = self.callee_name(*result) {
+                        let site = (result.start_position().row + 1, callee.to_string());
+                        table.calls.entry(site).or_insert("returned");
+                    }
+                }
+                continue;
+            }
+            self.record_returned_value(*result, "returned", table);
+        }
+    }
+
+    /// Error variable or sentinel handed on as is (or as a `%w` argument)
+    fn record_returned_value(&self, value: tree_sitter::Node<'_>, flow: &'static str, table: &mut ErrorFlowSiteTable) {
+        let name = match value.kind() {
+            "identifier" => self.text(value),
+            "selector_expression" => match value.child_by_field_name("field") {
+                Some(field) => self.text(field),
+                None => return,
+            },
+            _ => return,
+        };
+        if let Some(origin) = self.origins.get(name).filter(|_| value.kind() == "identifier") {
+            table.calls.entry(origin.clone()).or_insert(flow);
+        } else if is_go_sentinel_error_name(name) {
+            let (start, end) = self.declaration_lines;
+            table.sentinels.push((start, end, name.to_string(), flow));
+        }
+    }
+
+    /// Arguments after the format string of `fmt.Errorf("...%w...", ...)`
+    fn wrapped_error_arguments<'t>(&self, call: tree_sitter::Node<'t>) -> Option<Vec<tree_sitter::Node<'t>>> {
+        let function = call.child_by_field_name("function").filter(|f| f.kind() == "selector_expression")?;
+        let operand = function.child_by_field_name("operand")?;
+        if Some(self.text(operand)) != self.fmt_alias.as_deref()
+            || function.child_by_field_name("field").map(|f| self.text(f)) != Some("Errorf")
+        {
+            return None;
+        }
+        let arguments = call.child_by_field_name("arguments")?;
+        let mut cursor = arguments.walk();
+        let values: Vec<_> = arguments.named_children(&mut cursor).collect();
+        let (format, rest) = values.split_first()?;
+        self.text(*format).contains("%w").then(|| rest.to_vec())
+    }
+
+    /// `f` for `f(x)`, `Save` for `s.repo.Save(u)`
+    fn callee_name(&self, call: tree_sitter::Node<'_>) -> Option<&'s str> {
+        if call.kind() != "call_expression" {
+            return None;
+        }
+        let function = call.child_by_field_name("function")?;
+        match function.kind() {
+            "identifier" => Some(self.text(function)),
+            "selector_expression" => function.child_by_field_name("field").map(|f| self.text(f)),
+            _ => None,
+        }
+    }
+}
+
+/// `err`, `readErr`, `cerr`
+fn is_go_error_variable_name(name: &str) -> bool {
+    name == "err" || name.ends_with("Err") || name.ends_with("err")
+}
+
+/// `ErrNotFound`, `errClosed`
+fn is_go_sentinel_error_name(name: &str) -> bool {
+    ["Err", "err"].iter().any(|prefix| {
+        name.strip_prefix(prefix)
+            .and_then(|rest| rest.chars().next())
+            .is_some_and(|c| c.is_ascii_uppercase())
+    })
+}
+
+/// Last segment of a target key's name (`example.com/app/store.Load` → `Load`)
+fn target_short_name(key: &str) -> Option<&str> {
+    key.split(':').nth(2).and_then(|name| name.rsplit('.').next())
+}
+
+/// Tag the Calls/Uses edges of one Go file whose errors are returned
+///
+/// # 4-Word Name: annotate_go_error_propagation_edges
+///
+/// # Contract
+/// - Precondition: `edges` come from the same parse of the file at `root`
+/// - Postcondition: Calls edges of returned/wrapped error calls and Uses
+///   edges of returned sentinels carry `error_flow`; others untouched
+/// - Returns: number of edges tagged
+pub fn annotate_go_error_propagation_edges(root: tree_sitter::Node<'_>, source: &str, edges: &mut [DependencyEdge]) -> usize {
+    let imports = collect_go_file_import_table(root, source);
+    let fmt_alias = imports.aliases.iter().find(|(_, path)| path.as_str() == "fmt").map(|(alias, _)| alias.clone());
+    let mut table = ErrorFlowSiteTable::default();
+
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let mut context = ErrorFlowWalkContext {
+            source,
+            fmt_alias: fmt_alias.clone(),
+            declaration_lines: (declaration.start_position().row + 1, declaration.end_position().row + 1),
+            origins: HashMap::new(),
+        };
+        context.walk(body, &mut table);
+    }
+
+    let mut tagged = 0;
+    let mut seen_sentinels: HashSet<(String, String)> = HashSet::new();
+    for edge in edges.iter_mut() {
+        let Some(line) = edge
+            .source_location
+            .as_deref()
+            .and_then(|l| l.rsplit_once(':'))
+            .and_then(|(_, line)| line.parse::<usize>().ok())
+        else {
+            continue;
+        };
+        let Some(name) = target_short_name(edge.to_key.as_str()).map(str::to_string) else {
+            continue;
+        };
+        let flow = match edge.edge_type {
+            EdgeType::Calls => table.calls.get(&(line, name)).copied(),
+            EdgeType::Uses => table
+                .sentinels
+                .iter()
+                .find(|(start, end, sentinel, _)| *start <= line && line <= *end && *sentinel == name)
+                .map(|(_, _, _, flow)| *flow)
+                .filter(|_| seen_sentinels.insert((edge.from_key.as_str().to_string(), name.clone()))),
+            _ => None,
+        };
+        if let Some(flow) = flow {
+            edge.metadata.insert(ERROR_FLOW_METADATA_KEY.to_string(), flow.to_string());
+            tagged += 1;
+        }
+    }
+    tagged
+}
+
+/// `PropagatesErrorTo` edges from resolved, `error_flow`-tagged edges
+///
+/// # 4-Word Name: derive_go_error_propagation_edges
+///
+/// # Contract
+/// - Precondition: cross-file resolution already ran on `edges`
+/// - Postcondition: one edge `to_key → from_key` per tagged Calls/Uses
+///   edge, with its `error_flow` and source location
+pub fn derive_go_error_propagation_edges(edges: &[DependencyEdge]) -> Vec<DependencyEdge> {
+    edges
+        .iter()
+        .filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Uses))
+        .filter_map(|edge| {
+            let flow = edge.metadata_value(ERROR_FLOW_METADATA_KEY)?;
+            let mut builder = DependencyEdge::builder()
+                .from_key(edge.to_key.as_str())
+                .to_key(edge.from_key.as_str())
+                .edge_type(EdgeType::PropagatesErrorTo)
+                .metadata_entry(ERROR_FLOW_METADATA_KEY, flow);
+            if let Some(location) = &edge.source_location {
+                builder = builder.source_location(location.clone());
+            }
+            builder.build().ok()
+        })
+        .collect()
+}
+
+/// Every site the errors of `origin_key` propagate to, breadth first
+///
+/// # 4-Word Name: trace_error_surface_sites
+///
+/// # Contract
+/// - Precondition: `edges` include derived `PropagatesErrorTo` edges
+/// - Postcondition: one entry per reachable function within `max_depth`
+///   hops, at its shortest depth; placeholder origins (`go:fn:Load:
+///   unresolved-reference:0-0`, `store.ErrNotFound`) match by language and
+///   name; sorted by depth, then key
+/// - Performance: O(V + E)
+pub fn trace_error_surface_sites(origin_key: &str, edges: &[DependencyEdge], max_depth: usize) -> Vec<ErrorSurfaceSiteEntry> {
+    let mut exact: HashMap<&str, Vec<&DependencyEdge>> = HashMap::new();
+    let mut by_language_name: HashMap<(&str, &str), Vec<&DependencyEdge>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::PropagatesErrorTo) {
+        let from = edge.from_key.as_str();
+        match (parse_unresolved_reference_name(from), from.split(':').next()) {
+            (Some(name), Some(language)) => by_language_name
+                .entry((language, name.rsplit('.').next().unwrap_or(name)))
+                .or_default()
+                .push(edge),
+            _ => exact.entry(from).or_default().push(edge),
+        }
+    }
+    let propagations_from = |key: &str| collect_propagations_from_key(key, &exact, &by_language_name);
+
+    let mut visited: BTreeSet<String> = BTreeSet::from([origin_key.to_string()]);
+    let mut queue = VecDeque::from([(origin_key.to_string(), 0usize)]);
+    let mut sites = Vec::new();
+    while let Some((key, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        for edge in propagations_from(&key) {
+            let site = edge.to_key.as_str();
+            if !visited.insert(site.to_string()) {
+                continue;
+            }
+            sites.push(ErrorSurfaceSiteEntry {
+                entity_key: site.to_string(),
+                depth: depth + 1,
+                via_key: key.clone(),
+                error_flow: edge.metadata_value(ERROR_FLOW_METADATA_KEY).unwrap_or("returned").to_string(),
+                source_location: edge.source_location.clone(),
+                terminal: propagations_from(site).is_empty(),
+            });
+            queue.push_back((site.to_string(), depth + 1));
+        }
+    }
+    sites.sort_by(|a, b| a.depth.cmp(&b.depth).then_with(|| a.entity_key.cmp(&b.entity_key)));
+    sites
+}
+
+/// Propagation edges leaving `key`, including those of matching placeholders
+fn collect_propagations_from_key<'e>(
+    key: &str,
+    exact: &HashMap<&str, Vec<&'e DependencyEdge>>,
+    by_language_name: &HashMap<(&str, &str), Vec<&'e DependencyEdge>>,
+) -> Vec<&'e DependencyEdge> {
+    let mut found: Vec<&DependencyEdge> = exact.get(key).into_iter().flatten().copied().collect();
+    let mut parts = key.split(':');
+    if let (Some(language), Some(name)) = (parts.next(), parts.nth(1)) {
+        found.extend(by_language_name.get(&(language, name)).into_iter().flatten().copied());
+    }
+    found
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+    use std::path::Path;
+
+    #[test]
+    fn test_returned_and_wrapped_errors_become_propagation_edges() {
+        let code = r#"
+package svc
+
+import (
+    "errors"
+    "fmt"
+
+    "example.com/app/store"
+)
+
+var ErrDenied = errors.New("denied")
+
+func (s *Service) Get(id string) (*User, error) {
+    u, err := s.repo.Find(id)
+    if err != nil {
+        return nil, fmt.Errorf("find %s: %w", id, err)
+    }
+    if !u.Active {
+        return nil, ErrDenied
+    }
+    if err := validate(u); err != nil {
+        return nil, err
+    }
+    if cerr := audit(u); cerr != nil {
+        log(cerr)
+    }
+    return u, nil
+}
+
+func Load(id string) error { return store.Load(id) }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, edges) = extractor.parse_source(code, Path::new("svc/get.go"), Language::Go).unwrap();
+
+        let flow_of = |from: &str, to: &str| {
+            edges
+                .iter()
+                .filter(|e| e.from_key.as_str().split(':').nth(2) == Some(from))
+                .filter(|e| target_short_name(e.to_key.as_str()) == Some(to))
+                .find_map(|e| e.metadata_value(ERROR_FLOW_METADATA_KEY))
+        };
+        assert_eq!(flow_of("Get", "Find"), Some("wrapped"));
+        assert_eq!(flow_of("Get", "validate"), Some("returned"));
+        assert_eq!(flow_of("Get", "ErrDenied"), Some("returned"));
+        assert_eq!(flow_of("Get", "audit"), None, "handled, not returned");
+        assert_eq!(flow_of("Load", "Load"), Some("returned"));
+
+        let derived = derive_go_error_propagation_edges(&edges);
+        assert_eq!(derived.len(), 4);
+        let denied = derived.iter().find(|e| target_short_name(e.from_key.as_str()) == Some("ErrDenied")).unwrap();
+        assert_eq!(denied.edge_type, EdgeType::PropagatesErrorTo);
+        assert_eq!(denied.to_key.as_str().split(':').nth(2), Some("Get"));
+
+        let sites = trace_error_surface_sites(denied.from_key.as_str(), &derived, 5);
+        assert_eq!(sites.len(), 1);
+        assert_eq!(sites[0].entity_key, denied.to_key.as_str());
+        assert!(sites[0].terminal);
+    }
+
+    #[test]
+    fn test_error_origin_heuristic_edge_cases() {
+        let code = r#"
+package svc
+
+import (
+    "fmt"
+
+    "example.com/app/store"
+)
+
+func Retry() error {
+    err := first()
+    err = second()
+    return err
+}
+
+func Describe() error {
+    err := fetch()
+    return fmt.Errorf("fetch: %v", err)
+}
+
+func Deferred() error {
+    err := prepare()
+    run(func() error { return err })
+    return nil
+}
+
+func Pair() (int, error) {
+    return count(), nil
+}
+
+func Remote() error {
+    return store.ErrMissing
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, edges) = extractor.parse_source(code, Path::new("svc/edge.go"), Language::Go).unwrap();
+        let flow_of = |from: &str, to: &str| {
+            edges
+                .iter()
+                .filter(|e| e.from_key.as_str().split(':').nth(2) == Some(from))
+                .filter(|e| target_short_name(e.to_key.as_str()) == Some(to))
+                .find_map(|e| e.metadata_value(ERROR_FLOW_METADATA_KEY))
+        };
+
+        // The latest assignment before the return is the origin
+        assert_eq!(flow_of("Retry", "second"), Some("returned"));
+        assert_eq!(flow_of("Retry", "first"), None);
+        // `%v` starts a new error at Errorf instead of wrapping the old one
+        assert_eq!(flow_of("Describe", "fetch"), None);
+        assert_eq!(flow_of("Describe", "Errorf"), Some("returned"));
+        // A closure returning err does not make the outer call an origin
+        assert_eq!(flow_of("Deferred", "prepare"), None);
+        // Only a call in last result position is returned
+        assert_eq!(flow_of("Pair", "count"), None);
+        // Package-qualified sentinels count
+        assert_eq!(flow_of("Remote", "ErrMissing"), Some("returned"));
+    }
+
+    #[test]
+    fn test_error_trace_matches_placeholders_and_marks_terminals() {
+        let propagation = |from: &str, to: &str, flow: &str| {
+            DependencyEdge::builder()
+                .from_key(from)
+                .to_key(to)
+                .edge_type(EdgeType::PropagatesErrorTo)
+                .source_location("svc/edge.go:3")
+                .metadata_entry(ERROR_FLOW_METADATA_KEY, flow)
+                .build()
+                .unwrap()
+        };
+        let remote = "go:fn:Remote:__svc:T1";
+        let handler = "go:fn:Handler:__api:T2";
+        let serve = "go:fn:Serve:__api:T3";
+        let edges = vec![
+            propagation("go:var:example.com/app/store.ErrMissing:unresolved-reference:0-0", remote, "returned"),
+            propagation(remote, handler, "wrapped"),
+            propagation(remote, serve, "returned"),
+            propagation(handler, serve, "returned"),
+        ];
+
+        // The resolved sentinel key picks up edges recorded against its placeholder
+        let sites = trace_error_surface_sites("go:var:ErrMissing:__store:T9", &edges, 5);
+        let summary: Vec<(&str, usize, &str, &str, bool)> = sites
+            .iter()
+            .map(|s| (s.entity_key.as_str(), s.depth, s.via_key.as_str(), s.error_flow.as_str(), s.terminal))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                (remote, 1, "go:var:ErrMissing:__store:T9", "returned", false),
+                (handler, 2, remote, "wrapped", false),
+                (serve, 2, remote, "returned", true),
+            ]
+        );
+        assert_eq!(sites[0].source_location.as_deref(), Some("svc/edge.go:3"));
+        assert_eq!(trace_error_surface_sites("go:var:ErrMissing:__store:T9", &edges, 1).len(), 1);
+    }
+}
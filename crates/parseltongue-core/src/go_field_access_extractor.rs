@@ -0,0 +1,511 @@
+//! Go Struct Field Access Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_field_access_extractor
+//!
+//! "Who mutates `User.Name`?" needs edges to fields, not just to types. This
+//! per-file pass resolves selector chains to `Type.field` and records:
+//!
+//! - `Writes`: assignment targets (`u.Name = n`, `s.hits++`, `s.n += 1`,
+//!   `s.items[i] = v`, `*s.ptr = v`) and keyed composite literals
+//!   (`User{Name: n}`)
+//! - `Reads`: every other resolved field selector, including the inner
+//!   links of a chain (`s.cfg` in `s.cfg.Name = n`)
+//!
+//! Fields are virtual nodes keyed `go:field:{Type}.{field}:__{package_dir}:0-0`
+//! (the package of the file declaring the access). Fields of types imported
+//! from another package (`u *models.User`) are emitted as
+//! `go:field:{import_path}.User.Name:unresolved-reference:0-0` with
+//! `type_qualifier` and `import_path` metadata (or `go:field:User.Name:...`
+//! when the alias is not in the file's imports); the Go cross-file pass
+//! moves them to the declaring package.
+//!
+//! Each function gets one edge per (field, Reads/Writes), at the first access.
+//! Edge metadata: `field_access` (`assign` / `update` / `init` / `read`).
+//!
+//! ## Field resolution (syntactic, no go/types)
+//!
+//! - Operand types come from the receiver, typed parameters and `var x T`
+//! - Chains (`s.cfg.Name`) follow field types of structs in the same file
+//! - Pointer dereferences (`(*p).Name`) and parentheses are unwrapped
+//! - Selectors naming a method of the type declared in this file
+//!   (`s.process` as a method value) are not fields
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::go_import_alias_resolver::{collect_go_file_import_table, IMPORT_PATH_METADATA_KEY};
+use crate::go_channel_operation_extractor::{
+    build_go_function_local_scope, find_go_declaration_entity_key, format_package_scope_segment,
+    go_declared_type_name, GoFunctionLocalScope,
+};
+use crate::query_extractor::ParsedEntity;
+
+/// Edge metadata key: `assign`, `update`, `init` or `read`
+pub const FIELD_ACCESS_METADATA_KEY: &str = "field_access";
+
+/// Edge metadata key: package alias of an imported field owner (`models`)
+pub const TYPE_QUALIFIER_METADATA_KEY: &str = "type_qualifier";
+
+/// Struct shapes declared in one file
+#[derive(Default)]
+pub(crate) struct GoFileTypeShapes {
+    /// (Struct, field) → declared field type name (`Config`, `models.User`)
+    field_types: HashMap<(String, String), Option<String>>,
+    /// Structs declared in this file
+    structs: HashSet<String>,
+    /// (Type, method) declared in this file
+    methods: HashSet<(String, String)>,
+}
+
+/// One resolved field access
+struct GoFieldAccessSite {
+    owner_type: String,
+    field: String,
+    edge_type: EdgeType,
+    access: &'static str,
+    line: usize,
+}
+
+/// Extract Reads/Writes edges to struct fields for one Go file
+///
+/// # 4-Word Name: extract_go_field_access_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: per function/method, at most one Reads and one Writes
+///   edge per resolved field; closures count towards the enclosing declaration
+pub fn extract_go_field_access_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let package_scope = format_package_scope_segment(
+        &file_path.parent().map(|p| p.to_string_lossy().to_string()).unwrap_or_default(),
+    );
+    let shapes = collect_go_file_type_shapes(root, source);
+    let imports = collect_go_file_import_table(root, source);
+
+    let mut edges = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, from_key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let scope = build_go_function_local_scope(declaration, source);
+
+        let mut write_targets: HashMap<usize, &'static str> = HashMap::new();
+        collect_go_write_targets(body, source, &mut write_targets);
+        let mut sites = Vec::new();
+        collect_go_field_access_sites(body, source, &scope, &shapes, &write_targets, &mut sites);
+
+        let mut seen: HashSet<(String, String, EdgeType)> = HashSet::new();
+        for site in sites {
+            if !seen.insert((site.owner_type.clone(), site.field.clone(), site.edge_type)) {
+                continue;
+            }
+            let (to_key, qualifier) = match site.owner_type.rsplit_once('.') {
+                Some((qualifier, type_name)) => {
+                    let owner = match imports.import_path_of(qualifier) {
+                        Some(import_path) => format!("{}.{}", import_path, type_name),
+                        None => type_name.to_string(),
+                    };
+                    (
+                        format!("go:field:{}.{}:unresolved-reference:0-0", owner, site.field),
+                        Some(qualifier.to_string()),
+                    )
+                }
+                None => (format!("go:field:{}.{}:{}:0-0", site.owner_type, site.field, package_scope), None),
+            };
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(to_key)
+                .edge_type(site.edge_type)
+                .source_location(format!("{}:{}", path_text, site.line))
+                .metadata_entry(FIELD_ACCESS_METADATA_KEY, site.access);
+            if let Some(qualifier) = qualifier {
+                if let Some(import_path) = imports.import_path_of(&qualifier) {
+                    builder = builder.metadata_entry(IMPORT_PATH_METADATA_KEY, import_path);
+                }
+                builder = builder.metadata_entry(TYPE_QUALIFIER_METADATA_KEY, qualifier);
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+pub(crate) fn collect_go_file_type_shapes(root: tree_sitter::Node<'_>, source: &str) -> GoFileTypeShapes {
+    let mut shapes = GoFileTypeShapes::default();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        match node.kind() {
+            "type_spec" => {
+                if let (Some(name), Some(struct_type)) = (
+                    node.child_by_field_name("name"),
+                    node.child_by_field_name("type").filter(|t| t.kind() == "struct_type"),
+                ) {
+                    let struct_name = source[name.byte_range()].to_string();
+                    let mut field_stack = vec![struct_type];
+                    while let Some(field) = field_stack.pop() {
+                        if field.kind() == "field_declaration" {
+                            let field_type = field.child_by_field_name("type").and_then(|t| go_declared_type_name(t, source));
+                            let mut name_cursor = field.walk();
+                            for field_name in field.children_by_field_name("name", &mut name_cursor) {
+                                shapes
+                                    .field_types
+                                    .insert((struct_name.clone(), source[field_name.byte_range()].to_string()), field_type.clone());
+                            }
+                            continue;
+                        }
+                        let mut cursor = field.walk();
+                        field_stack.extend(field.named_children(&mut cursor));
+                    }
+                    shapes.structs.insert(struct_name);
+                }
+                continue;
+            }
+            "method_declaration" => {
+                let receiver_type = node
+                    .child_by_field_name("receiver")
+                    .and_then(|r| r.named_child(0))
+                    .and_then(|p| p.child_by_field_name("type"))
+                    .and_then(|t| go_declared_type_name(t, source));
+                if let (Some(receiver_type), Some(name)) = (receiver_type, node.child_by_field_name("name")) {
+                    shapes.methods.insert((receiver_type, source[name.byte_range()].to_string()));
+                }
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+    }
+    shapes
+}
+
+/// Strip `(x)`, `*x`, `x[i]` down to the selector being mutated
+fn unwrap_go_write_target(mut node: tree_sitter::Node<'_>, source: &str) -> tree_sitter::Node<'_> {
+    loop {
+        node = match node.kind() {
+            "parenthesized_expression" => match node.named_child(0) {
+                Some(inner) => inner,
+                None => return node,
+            },
+            "index_expression" => match node.child_by_field_name("operand") {
+                Some(operand) => operand,
+                None => return node,
+            },
+            "unary_expression"
+                if node.child_by_field_name("operator").is_some_and(|op| &source[op.byte_range()] == "*") =>
+            {
+                match node.child_by_field_name("operand") {
+                    Some(operand) => operand,
+                    None => return node,
+                }
+            }
+            _ => return node,
+        };
+    }
+}
+
+/// Node ids of selector expressions in write position → `field_access`
+fn collect_go_write_targets(node: tree_sitter::Node<'_>, source: &str, targets: &mut HashMap<usize, &'static str>) {
+    match node.kind() {
+        "assignment_statement" => {
+            let access = match node.child_by_field_name("operator").map(|op| &source[op.byte_range()]) {
+                Some("=") | None => "assign",
+                Some(_) => "update",
+            };
+            if let Some(left) = node.child_by_field_name("left") {
+                let mut cursor = left.walk();
+                for target in left.named_children(&mut cursor) {
+                    let target = unwrap_go_write_target(target, source);
+                    if target.kind() == "selector_expression" {
+                        targets.insert(target.id(), access);
+                    }
+                }
+            }
+        }
+        "inc_statement" | "dec_statement" => {
+            if let Some(target) = node.named_child(0).map(|t| unwrap_go_write_target(t, source)) {
+                if target.kind() == "selector_expression" {
+                    targets.insert(target.id(), "update");
+                }
+            }
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_write_targets(child, source, targets);
+    }
+}
+
+/// Type name of an expression used as a selector operand
+pub(crate) fn resolve_go_operand_type(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+    shapes: &GoFileTypeShapes,
+) -> Option<String> {
+    match node.kind() {
+        "identifier" => scope.type_of_variable(&source[node.byte_range()]),
+        "parenthesized_expression" => node
+            .named_child(0)
+            .and_then(|inner| resolve_go_operand_type(inner, source, scope, shapes)),
+        "unary_expression" => node
+            .child_by_field_name("operand")
+            .and_then(|inner| resolve_go_operand_type(inner, source, scope, shapes)),
+        "selector_expression" => {
+            let owner = resolve_go_selector_owner(node, source, scope, shapes)?;
+            let field = &source[node.child_by_field_name("field")?.byte_range()];
+            shapes.field_types.get(&(owner, field.to_string())).cloned().flatten()
+        }
+        _ => None,
+    }
+}
+
+fn resolve_go_selector_owner(
+    selector: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+    shapes: &GoFileTypeShapes,
+) -> Option<String> {
+    resolve_go_operand_type(selector.child_by_field_name("operand")?, source, scope, shapes)
+}
+
+/// True when `node` is the callee of a call (`s.save()`), not a value
+pub(crate) fn is_go_call_callee_node(node: tree_sitter::Node<'_>) -> bool {
+    node.parent().is_some_and(|parent| {
+        parent.kind() == "call_expression"
+            && parent.child_by_field_name("function").is_some_and(|f| f.id() == node.id())
+    })
+}
+
+fn collect_go_field_access_sites(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    scope: &GoFunctionLocalScope,
+    shapes: &GoFileTypeShapes,
+    write_targets: &HashMap<usize, &'static str>,
+    sites: &mut Vec<GoFieldAccessSite>,
+) {
+    match node.kind() {
+        "selector_expression" if !is_go_call_callee_node(node) => {
+            let owner = resolve_go_selector_owner(node, source, scope, shapes);
+            let field = node.child_by_field_name("field").map(|f| source[f.byte_range()].to_string());
+            if let (Some(owner_type), Some(field)) = (owner, field) {
+                let is_field = if shapes.structs.contains(&owner_type) {
+                    shapes.field_types.contains_key(&(owner_type.clone(), field.clone()))
+                } else {
+                    !shapes.methods.contains(&(owner_type.clone(), field.clone()))
+                };
+                if is_field {
+                    let (edge_type, access) = match write_targets.get(&node.id()) {
+                        Some(access) => (EdgeType::Writes, *access),
+                        None => (EdgeType::Reads, "read"),
+                    };
+                    sites.push(GoFieldAccessSite {
+                        owner_type,
+                        field,
+                        edge_type,
+                        access,
+                        line: node.start_position().row + 1,
+                    });
+                }
+            }
+        }
+        "composite_literal" => {
+            let literal_type = node.child_by_field_name("type").and_then(|t| go_declared_type_name(t, source));
+            if let (Some(owner_type), Some(body)) = (literal_type, node.child_by_field_name("body")) {
+                let mut cursor = body.walk();
+                for element in body.named_children(&mut cursor).filter(|e| e.kind() == "keyed_element") {
+                    let key = element
+                        .child_by_field_name("key")
+                        .or_else(|| element.named_child(0))
+                        .map(|k| if k.kind() == "literal_element" { k.named_child(0).unwrap_or(k) } else { k });
+                    if let Some(key) = key.filter(|k| matches!(k.kind(), "identifier" | "field_identifier")) {
+                        sites.push(GoFieldAccessSite {
+                            owner_type: owner_type.clone(),
+                            field: source[key.byte_range()].to_string(),
+                            edge_type: EdgeType::Writes,
+                            access: "init",
+                            line: key.start_position().row + 1,
+                        });
+                    }
+                }
+            }
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_field_access_sites(child, source, scope, shapes, write_targets, sites);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    fn field_edges(code: &str, path: &str) -> Vec<(EdgeType, String, String, String)> {
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new(path), Language::Go).unwrap();
+        edges
+            .iter()
+            .filter(|e| matches!(e.edge_type, EdgeType::Reads | EdgeType::Writes))
+            .map(|e| {
+                (
+                    e.edge_type,
+                    e.from_key.as_str().split(':').nth(2).unwrap_or("").to_string(),
+                    e.to_key.as_str().to_string(),
+                    e.metadata_value(FIELD_ACCESS_METADATA_KEY).unwrap_or("").to_string(),
+                )
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_field_writes_through_selectors_and_pointers() {
+        let code = r#"
+package users
+
+type Config struct {
+    Name string
+}
+
+type Service struct {
+    cfg   *Config
+    hits  int
+    items []string
+}
+
+func (s *Service) Rename(n string) {
+    s.cfg.Name = n
+    s.hits++
+    s.items[0] = n
+    s.save()
+}
+
+func (s *Service) save() {}
+
+func Describe(svc *Service) string {
+    return (*svc).cfg.Name
+}
+
+func NewConfig(n string) *Config {
+    return &Config{Name: n}
+}
+"#;
+        let edges = field_edges(code, "internal/users/service.go");
+        let key = |f: &str| format!("go:field:{}:__internal_users:0-0", f);
+        let has = |t: EdgeType, from: &str, field: &str, access: &str| {
+            edges.contains(&(t, from.to_string(), key(field), access.to_string()))
+        };
+
+        assert!(has(EdgeType::Writes, "Rename", "Config.Name", "assign"), "{:?}", edges);
+        assert!(has(EdgeType::Reads, "Rename", "Service.cfg", "read"));
+        assert!(has(EdgeType::Writes, "Rename", "Service.hits", "update"));
+        assert!(has(EdgeType::Writes, "Rename", "Service.items", "assign"));
+        assert!(has(EdgeType::Reads, "Describe", "Config.Name", "read"));
+        assert!(has(EdgeType::Reads, "Describe", "Service.cfg", "read"));
+        assert!(has(EdgeType::Writes, "NewConfig", "Config.Name", "init"));
+        // Method calls are not field accesses
+        assert!(!edges.iter().any(|e| e.2.contains(".save:")));
+    }
+
+    #[test]
+    fn test_imported_type_fields_carry_qualifier() {
+        let code = r#"
+package api
+
+func Update(u *models.User, n string) {
+    u.Name = n
+    u.Touch()
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new("api/update.go"), Language::Go).unwrap();
+        let write = edges.iter().find(|e| e.edge_type == EdgeType::Writes).expect("Writes edge");
+        assert_eq!(write.to_key.as_str(), "go:field:User.Name:unresolved-reference:0-0");
+        assert_eq!(write.metadata_value(TYPE_QUALIFIER_METADATA_KEY), Some("models"));
+        assert_eq!(edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Reads | EdgeType::Writes)).count(), 1);
+    }
+
+    #[test]
+    fn test_field_access_dedup_and_untyped_operands() {
+        let code = r#"
+package users
+
+import m "example.com/app/models"
+
+type Config struct {
+    Name string
+    Tags []string
+}
+
+type Service struct {
+    cfg   Config
+    ptr   *int
+    total int
+}
+
+func (s *Service) Tally(n int) {
+    s.total += n
+    _ = s.total
+    _ = s.total
+    *s.ptr = n
+    f := s.process
+    f()
+    alias := s.cfg
+    alias.Name = "x"
+    var c Config
+    c.Tags = nil
+    _ = Config{"positional", nil}
+}
+
+func (s *Service) process() {}
+
+func Sync(u m.User) {
+    u.Email = ""
+}
+"#;
+        let edges = field_edges(code, "internal/users/tally.go");
+        let key = |f: &str| format!("go:field:{}:__internal_users:0-0", f);
+        let count = |t: EdgeType, field: &str| edges.iter().filter(|e| e.0 == t && e.2 == key(field)).count();
+
+        // One edge per (field, Reads/Writes) however often it is touched
+        assert_eq!(count(EdgeType::Writes, "Service.total"), 1, "{:?}", edges);
+        assert_eq!(count(EdgeType::Reads, "Service.total"), 1);
+        assert!(edges.contains(&(EdgeType::Writes, "Tally".into(), key("Service.total"), "update".into())));
+        assert!(edges.contains(&(EdgeType::Writes, "Tally".into(), key("Service.ptr"), "assign".into())));
+        assert!(edges.contains(&(EdgeType::Reads, "Tally".into(), key("Service.cfg"), "read".into())));
+        // `var c Config` is typed; `:=` locals and positional literals are not
+        assert!(edges.contains(&(EdgeType::Writes, "Tally".into(), key("Config.Tags"), "assign".into())));
+        assert_eq!(count(EdgeType::Writes, "Config.Name"), 0);
+        // Uncalled method values are not fields
+        assert!(!edges.iter().any(|e| e.2.contains(".process:")));
+
+        // Aliased imports resolve to the import path
+        let imported = "go:field:example.com/app/models.User.Email:unresolved-reference:0-0";
+        assert!(edges.contains(&(EdgeType::Writes, "Sync".into(), imported.into(), "assign".into())));
+        assert_eq!(edges.len(), 6, "{:?}", edges);
+
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let path = Path::new("internal/users/tally.go");
+        let (_entities, raw) = extractor.parse_source(code, path, Language::Go).unwrap();
+        let sync = raw.iter().find(|e| e.to_key.as_str() == imported).unwrap();
+        assert_eq!(sync.metadata_value(IMPORT_PATH_METADATA_KEY), Some("example.com/app/models"));
+        assert_eq!(sync.metadata_value(TYPE_QUALIFIER_METADATA_KEY), Some("m"));
+    }
+}
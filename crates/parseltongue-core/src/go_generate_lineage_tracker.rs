@@ -0,0 +1,372 @@
+//! Go Generate Lineage Tracker (v1.7.3)
+//!
+//! # 4-Word Naming: go_generate_lineage_tracker
+//!
+//! Generated Go files announce themselves with the standard header
+//! (`// Code generated by stringer -type=Color; DO NOT EDIT.`, before the
+//! package clause) and are produced by `//go:generate` directives in
+//! hand-written files. Editing the output is pointless, and packing it into
+//! a prompt wastes tokens on code the model should not touch. This pass
+//! links each generated file back to where it comes from:
+//!
+//! - Entities of a generated file get `generated: true`, `generated_by`
+//!   (the directive command, else the tool named in the header) and
+//!   `generated_from` (the directive's file, else the header's
+//!   `// source:` file) when known
+//! - A `GeneratedFrom` edge joins the file nodes
+//!   (`go:file:internal_color_string_go:1-1` → `go:file:internal_color_go:1-1`),
+//!   with `generator` and, for directives, `directive_line`
+//!
+//! ## Matching
+//!
+//! A generated file belongs to a directive when the directive names it as
+//! output (`-o`, `-output`, `-destination`, `--out`, relative to the
+//! directive's package), else to a directive of the same package running
+//! the tool its header names (`stringer`, `mockgen`, `go run
+//! github.com/99designs/gqlgen` → `gqlgen`; case-insensitive). Among
+//! several such directives, one in a file whose stem starts the generated
+//! file's stem wins (`color.go` for `color_string.go`), else the first in
+//! path order.
+//!
+//! Context packing ranks generated entities below hand-written ones and
+//! packs their signatures only (see `token_budget_context_packer`).
+
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::go_typed_selector_resolver::relative_slash_path;
+use crate::protobuf_service_definition_linker::find_contract_files_by_extension;
+use crate::query_extractor::sanitize_path_for_key_format;
+
+/// Entity metadata key: `true` on entities of generated files
+pub const GENERATED_METADATA_KEY: &str = "generated";
+
+/// Entity metadata key: generator command or tool (`stringer -type=Color`)
+pub const GENERATED_BY_METADATA_KEY: &str = "generated_by";
+
+/// Entity metadata key: file the output comes from (`internal/color.go`, `api/user.proto`)
+pub const GENERATED_FROM_METADATA_KEY: &str = "generated_from";
+
+/// Edge metadata key: generator command or tool of a `GeneratedFrom` edge
+pub const GENERATOR_METADATA_KEY: &str = "generator";
+
+/// Edge metadata key: 1-based line of the `//go:generate` directive
+pub const DIRECTIVE_LINE_METADATA_KEY: &str = "directive_line";
+
+const OUTPUT_FLAG_NAMES: [&str; 5] = ["-o", "-output", "-destination", "--out", "-out"];
+
+/// One `//go:generate` directive
+///
+/// # 4-Word Name: GoGenerateDirectiveEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GoGenerateDirectiveEntry {
+    /// 1-based line of the directive
+    pub line: usize,
+    /// Command after `//go:generate` (`stringer -type=Color`)
+    pub command: String,
+    /// Tool base name (`stringer`, `gqlgen` for `go run .../gqlgen@v0.17`)
+    pub tool: String,
+    /// Output paths named by flags, as written
+    pub outputs: Vec<String>,
+}
+
+/// What a `Code generated ... DO NOT EDIT.` header says
+///
+/// # 4-Word Name: GeneratedFileHeaderEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct GeneratedFileHeaderEntry {
+    /// Generator as written (`protoc-gen-go`, `stringer -type=Color`)
+    pub generator: Option<String>,
+    /// `// source:` / `// Source:` file, as written
+    pub source: Option<String>,
+}
+
+/// Parse every `//go:generate` directive of a Go file
+///
+/// # 4-Word Name: parse_go_generate_directives
+///
+/// # Contract
+/// - Postcondition: directives in line order; only lines starting with
+///   `//go:generate` (no space, as the go tool requires)
+pub fn parse_go_generate_directives(source: &str) -> Vec<GoGenerateDirectiveEntry> {
+    source
+        .lines()
+        .enumerate()
+        .filter_map(|(index, line)| {
+            let command = line.strip_prefix("//go:generate")?;
+            if !command.starts_with([' ', '\t']) {
+                return None;
+            }
+            let command = command.trim().to_string();
+            let words: Vec<&str> = command.split_whitespace().collect();
+            let tool = match words.as_slice() {
+                ["go", "run", rest @ ..] => rest.iter().find(|w| !w.starts_with('-')).copied(),
+                [first, ..] => Some(*first),
+                [] => None,
+            }?;
+            let mut outputs = Vec::new();
+            for (position, word) in words.iter().enumerate() {
+                let (flag, value) = match word.split_once('=') {
+                    Some((flag, value)) => (flag, Some(value)),
+                    None => (*word, words.get(position + 1).copied()),
+                };
+                if let Some(value) = value.filter(|_| OUTPUT_FLAG_NAMES.contains(&flag)) {
+                    outputs.push(value.trim_matches('"').to_string());
+                }
+            }
+            Some(GoGenerateDirectiveEntry {
+                line: index + 1,
+                tool: tool_base_name(tool),
+                command,
+                outputs,
+            })
+        })
+        .collect()
+}
+
+/// Read the generated-code header of a Go file, if it has one
+///
+/// # 4-Word Name: parse_generated_file_header
+///
+/// # Contract
+/// - Postcondition: `Some` only when a `// Code generated ... DO NOT EDIT.`
+///   line precedes the package clause
+pub fn parse_generated_file_header(source: &str) -> Option<GeneratedFileHeaderEntry> {
+    let mut header: Option<GeneratedFileHeaderEntry> = None;
+    let mut source_file = None;
+    for line in source.lines() {
+        let trimmed = line.trim();
+        if trimmed.starts_with("package ") {
+            break;
+        }
+        let Some(comment) = trimmed.strip_prefix("//") else { continue };
+        let comment = comment.trim();
+        if let Some(text) = comment.strip_prefix("Code generated").filter(|_| comment.ends_with("DO NOT EDIT.")) {
+            let text = text.trim_end_matches("DO NOT EDIT.").trim().trim_end_matches([';', '.', ',']).trim();
+            let text = text.strip_prefix("by ").unwrap_or(text).trim();
+            let generator = text.trim_matches('"').trim();
+            header = Some(GeneratedFileHeaderEntry {
+                generator: (!generator.is_empty()).then(|| generator.to_string()),
+                source: None,
+            });
+        } else if let Some(path) = comment.strip_prefix("source:").or_else(|| comment.strip_prefix("Source:")) {
+            source_file = Some(path.trim().to_string());
+        }
+    }
+    header.map(|mut header| {
+        header.source = source_file;
+        header
+    })
+}
+
+/// Tag generated Go entities and link generated files to their sources
+///
+/// # 4-Word Name: link_go_generated_file_lineage
+///
+/// # Contract
+/// - Precondition: entity file paths are the paths walked under `root`
+/// - Postcondition: see module docs; nothing changes without generated files
+/// - Returns: the `GeneratedFrom` edges, one per generated file with a source
+pub fn link_go_generated_file_lineage(entities: &mut [CodeEntity], root: &Path) -> Vec<DependencyEdge> {
+    let mut directives: Vec<(PathBuf, GoGenerateDirectiveEntry)> = Vec::new();
+    let mut generated: Vec<(PathBuf, GeneratedFileHeaderEntry)> = Vec::new();
+    for path in find_contract_files_by_extension(root, ".go") {
+        let Ok(source) = std::fs::read_to_string(&path) else { continue };
+        if let Some(header) = parse_generated_file_header(&source) {
+            generated.push((path.clone(), header));
+        }
+        for directive in parse_go_generate_directives(&source) {
+            directives.push((path.clone(), directive));
+        }
+    }
+
+    let mut edges = Vec::new();
+    let mut lineage_by_file: HashMap<String, (Option<String>, Option<String>)> = HashMap::new();
+    for (path, header) in &generated {
+        let directive = find_generating_directive(path, header, &directives);
+        let (generator, from_path) = match directive {
+            Some((directive_path, directive)) => (Some(directive.command.clone()), Some(directive_path.clone())),
+            None => (header.generator.clone(), header.source.as_deref().map(|s| resolve_header_source_path(root, path, s))),
+        };
+        if let Some(from_path) = &from_path {
+            let mut builder = DependencyEdge::builder()
+                .from_key(format_file_node_key(path))
+                .to_key(format_file_node_key(from_path))
+                .edge_type(EdgeType::GeneratedFrom)
+                .source_location(format!("{}:1", path.display()));
+            if let Some(generator) = &generator {
+                builder = builder.metadata_entry(GENERATOR_METADATA_KEY, generator.clone());
+            }
+            if let Some((_, directive)) = directive {
+                builder = builder.metadata_entry(DIRECTIVE_LINE_METADATA_KEY, directive.line.to_string());
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+        lineage_by_file.insert(
+            relative_slash_path(root, path),
+            (generator, from_path.map(|p| relative_slash_path(root, &p))),
+        );
+    }
+
+    for entity in entities.iter_mut() {
+        let file = relative_slash_path(root, &entity.interface_signature.file_path);
+        let Some((generator, from)) = lineage_by_file.get(&file) else { continue };
+        entity.metadata.additional.insert(GENERATED_METADATA_KEY.to_string(), "true".to_string());
+        if let Some(generator) = generator {
+            entity.metadata.additional.insert(GENERATED_BY_METADATA_KEY.to_string(), generator.clone());
+        }
+        if let Some(from) = from {
+            entity.metadata.additional.insert(GENERATED_FROM_METADATA_KEY.to_string(), from.clone());
+        }
+    }
+    edges
+}
+
+/// The directive producing `path`: named output first, then same-package tool match
+fn find_generating_directive<'a>(
+    path: &Path,
+    header: &GeneratedFileHeaderEntry,
+    directives: &'a [(PathBuf, GoGenerateDirectiveEntry)],
+) -> Option<&'a (PathBuf, GoGenerateDirectiveEntry)> {
+    let directory = path.parent().unwrap_or(Path::new(""));
+    let names_output = directives.iter().find(|(directive_path, directive)| {
+        let base = directive_path.parent().unwrap_or(Path::new(""));
+        directive
+            .outputs
+            .iter()
+            .any(|output| relative_slash_path(Path::new(""), &base.join(output)) == relative_slash_path(Path::new(""), path))
+    });
+    if names_output.is_some() {
+        return names_output;
+    }
+
+    let tool = header
+        .generator
+        .as_deref()
+        .and_then(|g| g.split_whitespace().next())
+        .map(tool_base_name)?;
+    let same_tool: Vec<&(PathBuf, GoGenerateDirectiveEntry)> = directives
+        .iter()
+        .filter(|(directive_path, directive)| {
+            directive_path.parent() == Some(directory) && directive.tool.eq_ignore_ascii_case(&tool)
+        })
+        .collect();
+    let stem = path.file_stem().map(|s| s.to_string_lossy().into_owned()).unwrap_or_default();
+    same_tool
+        .iter()
+        .find(|(directive_path, _)| {
+            directive_path
+                .file_stem()
+                .is_some_and(|s| stem.starts_with(s.to_string_lossy().as_ref()))
+        })
+        .or_else(|| same_tool.first())
+        .copied()
+}
+
+/// `// source:` path next to the generated file, else under the root, else as written
+fn resolve_header_source_path(root: &Path, generated: &Path, source: &str) -> PathBuf {
+    let beside = generated.parent().unwrap_or(Path::new("")).join(source);
+    if beside.exists() {
+        return beside;
+    }
+    let under_root = root.join(source);
+    if under_root.exists() {
+        under_root
+    } else {
+        PathBuf::from(source)
+    }
+}
+
+/// File node key in the format of the `Uses` edges from files
+fn format_file_node_key(path: &Path) -> String {
+    let language = match path.extension().and_then(|e| e.to_str()) {
+        Some("go") | None => "go",
+        Some("yml") => "yaml",
+        Some("gql") => "graphql",
+        Some(other) => other,
+    };
+    format!("{}:file:{}:1-1", language, sanitize_path_for_key_format(&path.display().to_string()))
+}
+
+fn tool_base_name(tool: &str) -> String {
+    let base = tool.rsplit('/').next().unwrap_or(tool);
+    base.split('@').next().unwrap_or(base).to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_generated_files_link_back_to_directives() {
+        let directives = parse_go_generate_directives(concat!(
+            "package color\n\n",
+            "//go:generate stringer -type=Color\n",
+            "//go:generate go run github.com/golang/mock/mockgen@v1.6.0 -source=color.go -destination=mocks/color_mock.go\n",
+            "// go:generate not-a-directive\n",
+        ));
+        assert_eq!(directives.len(), 2);
+        assert_eq!((directives[0].line, directives[0].tool.as_str()), (3, "stringer"));
+        assert_eq!(directives[1].tool, "mockgen");
+        assert_eq!(directives[1].outputs, vec!["mocks/color_mock.go".to_string()]);
+        assert_eq!(
+            parse_generated_file_header("// Code generated by \"stringer -type=Color\"; DO NOT EDIT.\n\npackage color\n"),
+            Some(GeneratedFileHeaderEntry { generator: Some("stringer -type=Color".to_string()), source: None })
+        );
+        assert_eq!(
+            parse_generated_file_header("// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api/user.proto\n\npackage api\n")
+                .and_then(|h| h.source),
+            Some("api/user.proto".to_string())
+        );
+        assert_eq!(parse_generated_file_header("package color\n\n// Code generated by x. DO NOT EDIT.\n"), None);
+
+        let dir = tempfile::tempdir().unwrap();
+        let root = dir.path();
+        std::fs::create_dir_all(root.join("color/mocks")).unwrap();
+        std::fs::write(
+            root.join("color/color.go"),
+            "package color\n\n//go:generate stringer -type=Color\n//go:generate mockgen -destination=mocks/color_mock.go . Painter\n\ntype Color int\n",
+        )
+        .unwrap();
+        std::fs::write(
+            root.join("color/color_string.go"),
+            "// Code generated by \"stringer -type=Color\"; DO NOT EDIT.\n\npackage color\n\nfunc (i Color) String() string { return \"\" }\n",
+        )
+        .unwrap();
+        std::fs::write(
+            root.join("color/mocks/color_mock.go"),
+            "// Code generated by MockGen. DO NOT EDIT.\n\npackage mocks\n",
+        )
+        .unwrap();
+        let string_file = root.join("color/color_string.go");
+        let mut entities = vec![
+            create_go_test_entity("go:method:String:__color:T1", "String", EntityType::Method, &string_file.to_string_lossy(), &[]),
+            create_go_test_entity("go:type:Color:__color:T2", "Color", EntityType::Struct, &root.join("color/color.go").to_string_lossy(), &[]),
+        ];
+
+        let edges = link_go_generated_file_lineage(&mut entities, root);
+        assert_eq!(edges.len(), 2);
+        let stringer = edges.iter().find(|e| e.from_key.as_str().ends_with("color_string_go:1-1")).unwrap();
+        assert!(stringer.to_key.as_str().ends_with("color_color_go:1-1"));
+        assert_eq!(stringer.edge_type, EdgeType::GeneratedFrom);
+        assert_eq!(stringer.metadata_value(DIRECTIVE_LINE_METADATA_KEY), Some("3"));
+        let mock = edges.iter().find(|e| e.from_key.as_str().ends_with("color_mock_go:1-1")).unwrap();
+        assert_eq!(mock.metadata_value(DIRECTIVE_LINE_METADATA_KEY), Some("4"));
+
+        assert_eq!(entities[0].metadata.additional.get(GENERATED_METADATA_KEY).map(String::as_str), Some("true"));
+        assert_eq!(
+            entities[0].metadata.additional.get(GENERATED_BY_METADATA_KEY).map(String::as_str),
+            Some("stringer -type=Color")
+        );
+        assert_eq!(
+            entities[0].metadata.additional.get(GENERATED_FROM_METADATA_KEY).map(String::as_str),
+            Some("color/color.go")
+        );
+        assert!(!entities[1].metadata.additional.contains_key(GENERATED_METADATA_KEY));
+    }
+}
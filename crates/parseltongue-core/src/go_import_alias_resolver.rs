@@ -0,0 +1,341 @@
+//! Go Import Alias Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: go_import_alias_resolver
+//!
+//! A Go call site names a package by its local alias, not its path:
+//! `json.Marshal(v)`, `j.Marshal(v)` with `j "encoding/json"`, or plain
+//! `Marshal(v)` after `import . "encoding/json"`. This per-file pass reads
+//! the file's import table and qualifies targets with the import path, so
+//! every spelling lands on the same node:
+//!
+//! - `alias.Name(...)`, `alias.Type{...}`, `go alias.Name()` →
+//!   `go:fn:encoding/json.Marshal:unresolved-reference:0-0`, with
+//!   `import_path` metadata
+//! - Bare names not declared in the file, when the file has dot imports →
+//!   `dot_imports` metadata (paths, comma-separated); the Go cross-file pass
+//!   picks the same package first, then a dot-imported package
+//!
+//! Aliases shadowed by a local (`json := load()`) are left alone.
+
+use std::collections::{HashMap, HashSet};
+
+use crate::entities::{DependencyEdge, EdgeType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{parse_unresolved_reference_name, RECEIVER_EXPR_METADATA_KEY};
+use crate::go_package_value_extractor::{collect_go_declared_local_names, GO_PREDECLARED_IDENTIFIER_NAMES};
+
+/// Edge metadata key: import path of a package-qualified target (`net/http`)
+pub const IMPORT_PATH_METADATA_KEY: &str = "import_path";
+
+/// Edge metadata key: dot-imported paths a bare name may come from
+pub const DOT_IMPORTS_METADATA_KEY: &str = "dot_imports";
+
+/// Import declarations of one Go file
+///
+/// # 4-Word Name: GoFileImportTable
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct GoFileImportTable {
+    /// Alias → import path (`http` → `net/http`, `j` → `encoding/json`)
+    pub aliases: HashMap<String, String>,
+    /// Paths imported with `import . "path"`
+    pub dot_imports: Vec<String>,
+}
+
+impl GoFileImportTable {
+    /// Import path bound to `alias`
+    pub fn import_path_of(&self, alias: &str) -> Option<&str> {
+        self.aliases.get(alias).map(String::as_str)
+    }
+
+    /// Dot-imported paths, comma-separated (None without dot imports)
+    pub fn dot_imports_joined(&self) -> Option<String> {
+        (!self.dot_imports.is_empty()).then(|| self.dot_imports.join(","))
+    }
+}
+
+/// Read the import table of a Go file
+///
+/// # 4-Word Name: collect_go_file_import_table
+///
+/// # Contract
+/// - Unaliased imports bind the last path element, skipping a major-version
+///   suffix (`chi/v5` → `chi`) and `gopkg.in` versions (`yaml.v3` → `yaml`)
+/// - Blank imports (`_`) bind nothing; dot imports go to `dot_imports`
+pub fn collect_go_file_import_table(root: tree_sitter::Node<'_>, source: &str) -> GoFileImportTable {
+    let mut table = GoFileImportTable::default();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        if node.kind() == "import_spec" {
+            let Some(path) = node.child_by_field_name("path") else { continue };
+            let path = source[path.byte_range()].trim_matches(|c| c == '"' || c == '`').to_string();
+            match node.child_by_field_name("name").map(|n| &source[n.byte_range()]) {
+                Some("_") => {}
+                Some(".") => table.dot_imports.push(path),
+                Some(alias) => {
+                    table.aliases.insert(alias.to_string(), path);
+                }
+                None => {
+                    table.aliases.insert(default_go_import_alias(&path), path);
+                }
+            }
+            continue;
+        }
+        if matches!(node.kind(), "source_file" | "import_declaration" | "import_spec_list") {
+            let mut cursor = node.walk();
+            stack.extend(node.named_children(&mut cursor));
+        }
+    }
+    table.dot_imports.sort();
+    table
+}
+
+/// Package name Go binds for an unaliased import path
+pub(crate) fn default_go_import_alias(path: &str) -> String {
+    let mut segments: Vec<&str> = path.split('/').collect();
+    let is_major_version = |s: &str| s.len() > 1 && s.starts_with('v') && s[1..].chars().all(|c| c.is_ascii_digit());
+    if segments.len() > 1 && segments.last().is_some_and(|s| is_major_version(s)) {
+        segments.pop();
+    }
+    let last = segments.last().copied().unwrap_or(path);
+    match last.rsplit_once(".v") {
+        Some((name, version)) if !version.is_empty() && version.chars().all(|c| c.is_ascii_digit()) => name.to_string(),
+        _ => last.to_string(),
+    }
+}
+
+/// Qualify call/spawn/constructor targets through the file's import table
+///
+/// # 4-Word Name: qualify_go_import_call_targets
+///
+/// # Contract
+/// - Precondition: `edges` come from the same parse of the file at `root`
+/// - Postcondition: Calls/Spawns edges whose site is `alias.Name` point at
+///   `go:fn:{import_path}.{Name}:unresolved-reference:0-0` and carry
+///   `import_path`; bare unresolved targets not declared in the file carry
+///   `dot_imports` when the file has dot imports
+/// - Returns: number of edges qualified with an import path
+pub fn qualify_go_import_call_targets(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    edges: &mut [DependencyEdge],
+) -> usize {
+    let table = collect_go_file_import_table(root, source);
+    if table.aliases.is_empty() && table.dot_imports.is_empty() {
+        return 0;
+    }
+
+    // (line, name) → aliases qualifying a call or literal on that line
+    let mut sites: HashMap<(usize, String), Vec<String>> = HashMap::new();
+    let mut declared: HashSet<String> = HashSet::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        collect_go_top_level_names(declaration, source, &mut declared);
+        let mut locals = HashSet::new();
+        collect_go_declared_local_names(declaration, source, &mut locals);
+        collect_go_qualified_call_sites(declaration, source, &table, &locals, &mut sites);
+    }
+    let dot_imports = table.dot_imports_joined();
+
+    let mut qualified = 0;
+    for edge in edges.iter_mut() {
+        if !matches!(edge.edge_type, EdgeType::Calls | EdgeType::Spawns) {
+            continue;
+        }
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()).map(str::to_string) else {
+            continue;
+        };
+        let Some(line) = edge
+            .source_location
+            .as_deref()
+            .and_then(|l| l.rsplit_once(':'))
+            .and_then(|(_, line)| line.parse::<usize>().ok())
+        else {
+            continue;
+        };
+        let receiver = edge.metadata.get(RECEIVER_EXPR_METADATA_KEY).cloned();
+        let alias = sites.get(&(line, name.clone())).and_then(|aliases| match &receiver {
+            Some(receiver) => aliases.iter().find(|a| a.as_str() == receiver.as_str()).cloned(),
+            None if aliases.iter().all(|a| *a == aliases[0]) => aliases.first().cloned(),
+            None => None,
+        });
+        match alias.and_then(|a| table.import_path_of(&a).map(str::to_string)) {
+            Some(import_path) => {
+                edge.to_key = Isgl1Key::new_unchecked(format!(
+                    "go:fn:{}.{}:unresolved-reference:0-0",
+                    import_path, name
+                ));
+                edge.metadata.insert(IMPORT_PATH_METADATA_KEY.to_string(), import_path);
+                qualified += 1;
+            }
+            None => {
+                let is_bare = receiver.is_none() && !name.contains('.');
+                let is_external = !declared.contains(&name) && !GO_PREDECLARED_IDENTIFIER_NAMES.contains(&name.as_str());
+                if let (true, true, Some(paths)) = (is_bare, is_external, &dot_imports) {
+                    edge.metadata.insert(DOT_IMPORTS_METADATA_KEY.to_string(), paths.clone());
+                }
+            }
+        }
+    }
+    qualified
+}
+
+/// Functions, types, consts and vars declared at the top level of the file
+fn collect_go_top_level_names(declaration: tree_sitter::Node<'_>, source: &str, names: &mut HashSet<String>) {
+    match declaration.kind() {
+        "function_declaration" => {
+            if let Some(name) = declaration.child_by_field_name("name") {
+                names.insert(source[name.byte_range()].to_string());
+            }
+        }
+        "type_declaration" | "const_declaration" | "var_declaration" => {
+            let mut stack = vec![declaration];
+            while let Some(node) = stack.pop() {
+                if matches!(node.kind(), "type_spec" | "type_alias" | "const_spec" | "var_spec") {
+                    let mut cursor = node.walk();
+                    for name in node.children_by_field_name("name", &mut cursor) {
+                        names.insert(source[name.byte_range()].to_string());
+                    }
+                    continue;
+                }
+                let mut cursor = node.walk();
+                stack.extend(node.named_children(&mut cursor));
+            }
+        }
+        _ => {}
+    }
+}
+
+/// Alias of an `alias.Name` selector or `alias.Type` qualified type
+fn go_import_alias_qualifier<'s>(
+    node: tree_sitter::Node<'_>,
+    source: &'s str,
+    table: &GoFileImportTable,
+    locals: &HashSet<String>,
+) -> Option<(&'s str, &'s str)> {
+    let (package, name) = match node.kind() {
+        "selector_expression" => (node.child_by_field_name("operand")?, node.child_by_field_name("field")?),
+        "qualified_type" => (node.child_by_field_name("package")?, node.child_by_field_name("name")?),
+        "generic_type" => return go_import_alias_qualifier(node.child_by_field_name("type")?, source, table, locals),
+        "index_expression" => return go_import_alias_qualifier(node.child_by_field_name("operand")?, source, table, locals),
+        _ => return None,
+    };
+    if !matches!(package.kind(), "identifier" | "package_identifier") {
+        return None;
+    }
+    let alias = &source[package.byte_range()];
+    (table.aliases.contains_key(alias) && !locals.contains(alias)).then(|| (alias, &source[name.byte_range()]))
+}
+
+fn collect_go_qualified_call_sites(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    table: &GoFileImportTable,
+    locals: &HashSet<String>,
+    sites: &mut HashMap<(usize, String), Vec<String>>,
+) {
+    let qualifier = match node.kind() {
+        "call_expression" => node.child_by_field_name("function"),
+        "composite_literal" => node.child_by_field_name("type"),
+        _ => None,
+    };
+    if let Some((alias, name)) = qualifier.and_then(|q| go_import_alias_qualifier(q, source, table, locals)) {
+        sites
+            .entry((node.start_position().row + 1, name.to_string()))
+            .or_default()
+            .push(alias.to_string());
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_qualified_call_sites(child, source, table, locals, sites);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+    use std::path::Path;
+
+    fn parse_go(code: &str) -> Vec<DependencyEdge> {
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        extractor.parse_source(code, Path::new("api/handler.go"), Language::Go).unwrap().1
+    }
+
+    #[test]
+    fn test_aliased_calls_point_at_import_path() {
+        let code = r#"
+package api
+
+import (
+    "encoding/json"
+    j "encoding/json"
+    yaml "gopkg.in/yaml.v3"
+    "example.com/app/internal/models"
+)
+
+func Encode(v any) {
+    json.Marshal(v)
+    j.Marshal(v)
+    yaml.Marshal(v)
+    _ = models.User{}
+    go models.Sync()
+}
+
+func Shadowed(json Encoder) {
+    json.Marshal(nil)
+}
+"#;
+        let edges = parse_go(code);
+        let targets = |from: &str| -> Vec<String> {
+            edges
+                .iter()
+                .filter(|e| matches!(e.edge_type, EdgeType::Calls) && e.from_key.as_str().contains(from))
+                .map(|e| e.to_key.as_str().to_string())
+                .collect()
+        };
+        let encode = targets(":fn:Encode:");
+        let json_calls = encode.iter().filter(|k| *k == "go:fn:encoding/json.Marshal:unresolved-reference:0-0").count();
+        assert_eq!(json_calls, 2, "both spellings of encoding/json: {:?}", encode);
+        assert!(encode.contains(&"go:fn:gopkg.in/yaml.v3.Marshal:unresolved-reference:0-0".to_string()));
+        assert!(encode.contains(&"go:fn:example.com/app/internal/models.User:unresolved-reference:0-0".to_string()));
+        let spawn = edges.iter().find(|e| e.edge_type == EdgeType::Spawns).unwrap();
+        assert_eq!(spawn.to_key.as_str(), "go:fn:example.com/app/internal/models.Sync:unresolved-reference:0-0");
+        assert_eq!(spawn.metadata_value(IMPORT_PATH_METADATA_KEY), Some("example.com/app/internal/models"));
+
+        // A parameter named like the import is a value, not the package
+        assert_eq!(targets(":fn:Shadowed:"), vec!["go:fn:Marshal:unresolved-reference:0-0".to_string()]);
+    }
+
+    #[test]
+    fn test_dot_imports_tag_undeclared_bare_calls() {
+        let code = r#"
+package api
+
+import . "strings"
+
+func helper(s string) string { return s }
+
+func Shout(s string) string {
+    return helper(ToUpper(s)) + string(len(s))
+}
+"#;
+        let edges = parse_go(code);
+        let dot = |name: &str| {
+            edges
+                .iter()
+                .find(|e| e.to_key.as_str() == format!("go:fn:{}:unresolved-reference:0-0", name))
+                .and_then(|e| e.metadata_value(DOT_IMPORTS_METADATA_KEY))
+        };
+        assert_eq!(dot("ToUpper"), Some("strings"));
+        assert_eq!(dot("helper"), None);
+        assert_eq!(dot("len"), None);
+    }
+
+    #[test]
+    fn test_default_import_alias_follows_go_rules() {
+        assert_eq!(default_go_import_alias("net/http"), "http");
+        assert_eq!(default_go_import_alias("github.com/go-chi/chi/v5"), "chi");
+        assert_eq!(default_go_import_alias("gopkg.in/yaml.v3"), "yaml");
+        assert_eq!(default_go_import_alias("fmt"), "fmt");
+    }
+}
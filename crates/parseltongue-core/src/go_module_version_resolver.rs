@@ -0,0 +1,356 @@
+//! Go Module Version Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: go_module_version_resolver
+//!
+//! Import-qualified Go targets that stay unresolved after the cross-file pass
+//! (`go:fn:github.com/go-chi/chi/v5.NewRouter:unresolved-reference:0-0`)
+//! point outside the repository. This pass asks the Go toolchain which module
+//! provides each import path (`go list -m -json all`) and records it:
+//!
+//! - Edge metadata: `external=true`, `module_path`, `module_version` (and
+//!   `module_replace` for `replace` directives)
+//! - Placeholder entity metadata: the same entries, copied from the edges
+//!
+//! Standard library imports (`net/http`) are `external=true` with
+//! `module_path=std` and no version. Imports of the main module are left
+//! alone: they are in-repo targets the resolver could not match.
+//!
+//! ## Fallback
+//!
+//! Without a Go toolchain (or when `go list` fails, e.g. offline with an
+//! incomplete module cache) the `require` / `replace` directives of `go.mod`
+//! are read directly. `go list` runs with `GOPROXY=off` and `GOFLAGS=-mod=mod`
+//! so ingestion never downloads modules.
+
+use std::collections::HashMap;
+use std::path::Path;
+use std::process::Command;
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::go_import_alias_resolver::IMPORT_PATH_METADATA_KEY;
+
+/// Edge/entity metadata key: `true` for targets outside the repository
+pub const EXTERNAL_METADATA_KEY: &str = "external";
+
+/// Edge/entity metadata key: module providing the import (`golang.org/x/sync`)
+pub const MODULE_PATH_METADATA_KEY: &str = "module_path";
+
+/// Edge/entity metadata key: module version (`v0.7.0`)
+pub const MODULE_VERSION_METADATA_KEY: &str = "module_version";
+
+/// Edge/entity metadata key: replacement of a replaced module (`../sync`, `example.com/fork@v1.2.0`)
+pub const MODULE_REPLACE_METADATA_KEY: &str = "module_replace";
+
+const GO_MODULE_METADATA_KEYS: [&str; 4] = [
+    EXTERNAL_METADATA_KEY,
+    MODULE_PATH_METADATA_KEY,
+    MODULE_VERSION_METADATA_KEY,
+    MODULE_REPLACE_METADATA_KEY,
+];
+
+/// `module_path` of standard library imports
+pub const GO_STANDARD_LIBRARY_MODULE: &str = "std";
+
+/// One module of the build list
+///
+/// # 4-Word Name: GoModuleRecordEntry
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct GoModuleRecordEntry {
+    /// Module path (`github.com/go-chi/chi/v5`)
+    pub path: String,
+    /// Selected version (empty for the main module)
+    pub version: String,
+    /// True for the module being ingested
+    pub main: bool,
+    /// `replace` target: a directory, or `path@version`
+    pub replace: Option<String>,
+}
+
+/// Build list of the module rooted at (or above) `root`
+///
+/// # 4-Word Name: load_go_module_records
+///
+/// # Contract
+/// - Never fails: no `go.mod` → empty; `go list` failure → `go.mod` fallback
+pub fn load_go_module_records(root: &Path) -> Vec<GoModuleRecordEntry> {
+    if !root.join("go.mod").is_file() {
+        return Vec::new();
+    }
+    let listed = Command::new("go")
+        .args(["list", "-m", "-json", "all"])
+        .current_dir(root)
+        .env("GOPROXY", "off")
+        .env("GOFLAGS", "-mod=mod")
+        .output()
+        .ok()
+        .filter(|output| output.status.success())
+        .map(|output| parse_go_list_module_json(&String::from_utf8_lossy(&output.stdout)))
+        .filter(|modules| !modules.is_empty());
+    listed.unwrap_or_else(|| {
+        std::fs::read_to_string(root.join("go.mod"))
+            .map(|text| parse_go_mod_directives(&text))
+            .unwrap_or_default()
+    })
+}
+
+/// Parse the JSON object stream printed by `go list -m -json`
+///
+/// # 4-Word Name: parse_go_list_module_json
+pub fn parse_go_list_module_json(stdout: &str) -> Vec<GoModuleRecordEntry> {
+    let field = |value: &serde_json::Value, name: &str| value.get(name).and_then(|v| v.as_str()).map(str::to_string);
+    serde_json::Deserializer::from_str(stdout)
+        .into_iter::<serde_json::Value>()
+        .filter_map(Result::ok)
+        .filter_map(|module| {
+            let replace = module.get("Replace").map(|r| match (field(r, "Path"), field(r, "Version")) {
+                (Some(path), Some(version)) => format!("{}@{}", path, version),
+                (Some(path), None) => path,
+                _ => String::new(),
+            });
+            Some(GoModuleRecordEntry {
+                path: field(&module, "Path")?,
+                version: field(&module, "Version").unwrap_or_default(),
+                main: module.get("Main").and_then(|m| m.as_bool()).unwrap_or(false),
+                replace: replace.filter(|r| !r.is_empty()),
+            })
+        })
+        .collect()
+}
+
+/// Read `module`, `require` and `replace` directives of a `go.mod` file
+///
+/// # 4-Word Name: parse_go_mod_directives
+///
+/// # Contract
+/// - Handles single-line and block (`require ( ... )`) forms and `//` comments
+/// - Replacements apply to every version unless the left side names one
+pub fn parse_go_mod_directives(text: &str) -> Vec<GoModuleRecordEntry> {
+    let mut modules: Vec<GoModuleRecordEntry> = Vec::new();
+    let mut replaces: Vec<(String, Option<String>, String)> = Vec::new();
+    let mut block: Option<&str> = None;
+    for raw in text.lines() {
+        let line = raw.split("//").next().unwrap_or_default().trim();
+        if line.is_empty() {
+            continue;
+        }
+        if line == ")" {
+            block = None;
+            continue;
+        }
+        let (directive, rest) = match block {
+            Some(directive) => (directive, line),
+            None => match line.split_once(char::is_whitespace) {
+                Some((directive, rest)) => (directive, rest.trim()),
+                None => continue,
+            },
+        };
+        if rest == "(" {
+            block = Some(directive);
+            continue;
+        }
+        let words: Vec<&str> = rest.split_whitespace().map(|w| w.trim_matches('"')).collect();
+        match (directive, words.as_slice()) {
+            ("module", [path, ..]) => modules.push(GoModuleRecordEntry {
+                path: path.to_string(),
+                main: true,
+                ..Default::default()
+            }),
+            ("require", [path, version, ..]) => modules.push(GoModuleRecordEntry {
+                path: path.to_string(),
+                version: version.to_string(),
+                ..Default::default()
+            }),
+            ("replace", _) => {
+                let Some(arrow) = words.iter().position(|w| *w == "=>") else { continue };
+                let (Some(old), Some(new)) = (words.first(), words.get(arrow + 1)) else { continue };
+                let old_version = (arrow == 2).then(|| words[1].to_string());
+                let new = match words.get(arrow + 2) {
+                    Some(version) => format!("{}@{}", new, version),
+                    None => new.to_string(),
+                };
+                replaces.push((old.to_string(), old_version, new));
+            }
+            _ => {}
+        }
+    }
+    for module in modules.iter_mut().filter(|m| !m.main) {
+        module.replace = replaces
+            .iter()
+            .find(|(path, version, _)| *path == module.path && version.as_ref().map_or(true, |v| *v == module.version))
+            .map(|(_, _, new)| new.clone());
+    }
+    modules
+}
+
+/// Module providing `import_path`: the longest module path that prefixes it
+///
+/// # 4-Word Name: find_go_module_for_import
+pub fn find_go_module_for_import<'m>(
+    modules: &'m [GoModuleRecordEntry],
+    import_path: &str,
+) -> Option<&'m GoModuleRecordEntry> {
+    modules
+        .iter()
+        .filter(|m| {
+            import_path == m.path
+                || import_path.strip_prefix(m.path.as_str()).is_some_and(|rest| rest.starts_with('/'))
+        })
+        .max_by_key(|m| m.path.len())
+}
+
+/// True for standard library import paths (no dot in the first element)
+///
+/// # 4-Word Name: is_go_standard_library_path
+pub fn is_go_standard_library_path(import_path: &str) -> bool {
+    let first = import_path.split('/').next().unwrap_or_default();
+    !first.is_empty() && !first.contains('.')
+}
+
+/// Mark unresolved import-qualified Go edges with their module
+///
+/// # 4-Word Name: annotate_go_external_module_edges
+///
+/// # Contract
+/// - Precondition: the Go cross-file pass already ran (resolved edges no
+///   longer point at `unresolved-reference` keys and are skipped)
+/// - Postcondition: edges into other modules or the standard library carry
+///   `external=true` and `module_path` (+ `module_version` / `module_replace`)
+/// - Postcondition: edges re-resolved into the repository drop stale entries
+/// - Returns: number of edges annotated
+pub fn annotate_go_external_module_edges(edges: &mut [DependencyEdge], modules: &[GoModuleRecordEntry]) -> usize {
+    edges
+        .iter_mut()
+        .map(|edge| annotate_go_external_module_edge(edge, modules))
+        .filter(|&annotated| annotated)
+        .count()
+}
+
+/// `annotate_go_external_module_edges` for one edge; true when annotated
+///
+/// # 4-Word Name: annotate_go_external_module_edge
+pub fn annotate_go_external_module_edge(edge: &mut DependencyEdge, modules: &[GoModuleRecordEntry]) -> bool {
+    if !edge.to_key.as_str().starts_with("go:") {
+        return false;
+    }
+    if !edge.to_key.as_str().contains(":unresolved-reference:") {
+        for key in GO_MODULE_METADATA_KEYS {
+            edge.metadata.remove(key);
+        }
+        return false;
+    }
+    let Some(import_path) = edge.metadata.get(IMPORT_PATH_METADATA_KEY).cloned() else {
+        return false;
+    };
+    let mut entries: Vec<(&str, String)> = Vec::new();
+    match find_go_module_for_import(modules, &import_path) {
+        Some(module) if module.main => return false,
+        Some(module) => {
+            entries.push((MODULE_PATH_METADATA_KEY, module.path.clone()));
+            if !module.version.is_empty() {
+                entries.push((MODULE_VERSION_METADATA_KEY, module.version.clone()));
+            }
+            if let Some(replace) = &module.replace {
+                entries.push((MODULE_REPLACE_METADATA_KEY, replace.clone()));
+            }
+        }
+        None if is_go_standard_library_path(&import_path) => {
+            entries.push((MODULE_PATH_METADATA_KEY, GO_STANDARD_LIBRARY_MODULE.to_string()));
+        }
+        None => return false,
+    }
+    edge.metadata.insert(EXTERNAL_METADATA_KEY.to_string(), "true".to_string());
+    for (key, value) in entries {
+        edge.metadata.insert(key.to_string(), value);
+    }
+    true
+}
+
+/// Copy module metadata from annotated edges onto their placeholder targets
+///
+/// # 4-Word Name: annotate_go_external_placeholder_entities
+///
+/// # Contract
+/// - Postcondition: every entity whose key is the target of an `external`
+///   edge carries the edge's `external` / `module_*` entries
+/// - Returns: number of entities annotated
+pub fn annotate_go_external_placeholder_entities(entities: &mut [CodeEntity], edges: &[DependencyEdge]) -> usize {
+    let mut by_target: HashMap<&str, &DependencyEdge> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.metadata_value(EXTERNAL_METADATA_KEY) == Some("true")) {
+        by_target.entry(edge.to_key.as_str()).or_insert(edge);
+    }
+    let mut annotated = 0;
+    for entity in entities.iter_mut() {
+        let Some(edge) = by_target.get(entity.isgl1_key.as_str()) else { continue };
+        for key in GO_MODULE_METADATA_KEYS {
+            if let Some(value) = edge.metadata_value(key) {
+                entity.metadata.additional.insert(key.to_string(), value.to_string());
+            }
+        }
+        annotated += 1;
+    }
+    annotated
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_go_list_stream_and_go_mod_agree() {
+        let listed = parse_go_list_module_json(
+            r#"{"Path": "example.com/app", "Main": true, "Dir": "/src/app"}
+{"Path": "github.com/go-chi/chi/v5", "Version": "v5.0.12"}
+{"Path": "golang.org/x/sync", "Version": "v0.7.0", "Indirect": true,
+ "Replace": {"Path": "../sync"}}"#,
+        );
+        let go_mod = parse_go_mod_directives(
+            r#"module example.com/app
+
+go 1.22
+
+require github.com/go-chi/chi/v5 v5.0.12
+
+require (
+    golang.org/x/sync v0.7.0 // indirect
+)
+
+replace golang.org/x/sync => ../sync
+"#,
+        );
+        assert_eq!(listed, go_mod);
+        assert!(listed[0].main);
+        assert_eq!(listed[2].replace.as_deref(), Some("../sync"));
+    }
+
+    #[test]
+    fn test_external_edges_carry_module_version() {
+        let modules = parse_go_mod_directives(
+            "module example.com/app\nrequire github.com/go-chi/chi/v5 v5.0.12\n",
+        );
+        let edge = |to: &str, import_path: &str| {
+            DependencyEdge::builder()
+                .from_key("go:fn:main:__cmd:T1")
+                .to_key(to)
+                .edge_type(crate::entities::EdgeType::Calls)
+                .metadata_entry(IMPORT_PATH_METADATA_KEY, import_path)
+                .build()
+                .unwrap()
+        };
+        let mut edges = vec![
+            edge("go:fn:github.com/go-chi/chi/v5/middleware.Logger:unresolved-reference:0-0", "github.com/go-chi/chi/v5/middleware"),
+            edge("go:fn:net/http.ListenAndServe:unresolved-reference:0-0", "net/http"),
+            // In-repo package the resolver could not match: not external
+            edge("go:fn:example.com/app/internal/db.Open:unresolved-reference:0-0", "example.com/app/internal/db"),
+            // Already resolved to an in-repo entity
+            edge("go:fn:Open:__internal_db:T2", "example.com/app/internal/db"),
+        ];
+
+        assert_eq!(annotate_go_external_module_edges(&mut edges, &modules), 2);
+        assert_eq!(edges[0].metadata_value(MODULE_PATH_METADATA_KEY), Some("github.com/go-chi/chi/v5"));
+        assert_eq!(edges[0].metadata_value(MODULE_VERSION_METADATA_KEY), Some("v5.0.12"));
+        assert_eq!(edges[1].metadata_value(MODULE_PATH_METADATA_KEY), Some(GO_STANDARD_LIBRARY_MODULE));
+        assert_eq!(edges[1].metadata_value(MODULE_VERSION_METADATA_KEY), None);
+        assert_eq!(edges[2].metadata_value(EXTERNAL_METADATA_KEY), None);
+        assert_eq!(edges[3].metadata_value(EXTERNAL_METADATA_KEY), None);
+    }
+}
@@ -0,0 +1,335 @@
+//! Go Package Initialization Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_package_init_extractor
+//!
+//! Nothing in a Go program calls `init` or evaluates `var x = f()`: the
+//! runtime does, once per package, before `main`. Side-effect imports
+//! (`_ "github.com/lib/pq"`) and registration (`func init() {
+//! sql.Register(...) }`, `var _ = plugins.Add(...)`) therefore left no trace
+//! in the graph. This per-file pass models each package's initialization as
+//! a node keyed `go:package:{dir}.init:__{package_dir}:0-0` (Go's own name
+//! for the package init function) with edges, tagged `package_init`:
+//!
+//! - `Calls` → every `func init()` (`init_function`)
+//! - `Uses` → every package-level var with an initializer (`var_initializer`);
+//!   the calls in the initializer stay on the var
+//! - `Calls` → calls in `var _ = ...` initializers, which have no var
+//!   entity to own them (`blank_var`)
+//! - `Uses` → `go:package:{import_path}:unresolved-reference:0-0` for every
+//!   blank import (`blank_import`), resolved to that package's init node
+//!   when it is in the graph
+//!
+//! A file may declare several `init` functions; they share a name, so each
+//! such file's inits are renamed `init.0`, `init.1`, … in source order (as
+//! in Go stack traces), keeping `declared_name: init`.
+
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, DependencyEdgeBuilder, EdgeType};
+use crate::go_channel_operation_extractor::{find_go_declaration_entity_key, format_package_scope_segment};
+use crate::go_import_alias_resolver::IMPORT_PATH_METADATA_KEY;
+use crate::go_package_value_extractor::format_go_parsed_entity_key;
+use crate::query_extractor::{EntityType, ParsedEntity};
+
+/// Edge metadata key: `init_function`, `var_initializer`, `blank_var` or `blank_import`
+pub const PACKAGE_INIT_METADATA_KEY: &str = "package_init";
+
+/// Entity metadata key: source name of a renamed declaration (`init`)
+pub const DECLARED_NAME_METADATA_KEY: &str = "declared_name";
+
+/// `package_init` value of edges to blank-imported packages
+pub const BLANK_IMPORT_INIT_KIND: &str = "blank_import";
+
+/// Key prefix of package initialization nodes
+pub const GO_PACKAGE_INIT_KEY_PREFIX: &str = "go:package:";
+
+/// Key of the initialization node of the package in `package_dir`
+///
+/// # 4-Word Name: format_go_package_init_key
+///
+/// `internal/jobs` → `go:package:jobs.init:__internal_jobs:0-0`; the
+/// repository root package is `init`.
+pub fn format_go_package_init_key(package_dir: &str) -> String {
+    let name = match Path::new(package_dir).file_name().and_then(|n| n.to_str()) {
+        Some(dir) => format!("{}.init", dir),
+        None => "init".to_string(),
+    };
+    format!("{}{}:{}:0-0", GO_PACKAGE_INIT_KEY_PREFIX, name, format_package_scope_segment(package_dir))
+}
+
+/// Rename the `init` functions of one file to `init.0`, `init.1`, …
+///
+/// # 4-Word Name: number_go_init_function_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from one Go file, before edges are built
+/// - Postcondition: with two or more `func init()`, each is renamed in line
+///   order and carries `declared_name: init`; a single init is untouched
+pub fn number_go_init_function_entities(entities: &mut [ParsedEntity]) {
+    let mut inits: Vec<&mut ParsedEntity> = entities
+        .iter_mut()
+        .filter(|e| e.name == "init" && e.entity_type == EntityType::Function)
+        .collect();
+    if inits.len() < 2 {
+        return;
+    }
+    inits.sort_by_key(|e| e.line_range.0);
+    for (ordinal, entity) in inits.into_iter().enumerate() {
+        entity.name = format!("init.{}", ordinal);
+        entity.metadata.insert(DECLARED_NAME_METADATA_KEY.to_string(), "init".to_string());
+    }
+}
+
+/// Extract package initialization edges for one Go file
+///
+/// # 4-Word Name: extract_go_package_init_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`, after
+///   `number_go_init_function_entities`
+/// - Postcondition: edges from this file's package init node, as listed in
+///   the module docs; none for a file without inits, initializers or blank
+///   imports
+pub fn extract_go_package_init_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let package_key = format_go_package_init_key(
+        &file_path.parent().map(|p| p.to_string_lossy().to_string()).unwrap_or_default(),
+    );
+    let edge = |edge_type: EdgeType, to_key: String, line: usize, kind: &str| {
+        DependencyEdge::builder()
+            .from_key(package_key.clone())
+            .to_key(to_key)
+            .edge_type(edge_type)
+            .source_location(format!("{}:{}", path_text, line))
+            .metadata_entry(PACKAGE_INIT_METADATA_KEY, kind)
+    };
+
+    let mut edges = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        let line = declaration.start_position().row + 1;
+        match declaration.kind() {
+            "function_declaration" => {
+                let Some((entity, key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+                    continue;
+                };
+                if entity.metadata.get(DECLARED_NAME_METADATA_KEY).map_or(entity.name.as_str(), String::as_str) == "init" {
+                    edges.extend(edge(EdgeType::Calls, key, line, "init_function").build().ok());
+                }
+            }
+            "var_declaration" => {
+                for spec in collect_descendants_of_kind(declaration, "var_spec") {
+                    let Some(value) = spec.child_by_field_name("value") else { continue };
+                    let spec_line = spec.start_position().row + 1;
+                    let mut name_cursor = spec.walk();
+                    let names: Vec<&str> = spec
+                        .children_by_field_name("name", &mut name_cursor)
+                        .map(|n| &source[n.byte_range()])
+                        .collect();
+                    for name in names.iter().filter(|n| **n != "_") {
+                        let owner = entities.iter().find(|e| {
+                            e.name == *name
+                                && e.entity_type == EntityType::Variable
+                                && e.line_range.0 <= spec_line
+                                && spec_line <= e.line_range.1
+                        });
+                        if let Some(owner) = owner {
+                            let key = format_go_parsed_entity_key(owner);
+                            edges.extend(edge(EdgeType::Uses, key, spec_line, "var_initializer").build().ok());
+                        }
+                    }
+                    if names.iter().all(|n| *n == "_") {
+                        for call in collect_descendants_of_kind(value, "call_expression") {
+                            edges.extend(build_blank_var_call_edge(call, source, &edge));
+                        }
+                    }
+                }
+            }
+            "import_declaration" => {
+                for spec in collect_descendants_of_kind(declaration, "import_spec") {
+                    if !spec.child_by_field_name("name").is_some_and(|n| n.kind() == "blank_identifier") {
+                        continue;
+                    }
+                    let Some(path) = spec.child_by_field_name("path") else { continue };
+                    let import_path = source[path.byte_range()].trim_matches(|c| c == '"' || c == '`');
+                    edges.extend(
+                        edge(
+                            EdgeType::Uses,
+                            format!("go:package:{}:unresolved-reference:0-0", import_path),
+                            spec.start_position().row + 1,
+                            BLANK_IMPORT_INIT_KIND,
+                        )
+                        .metadata_entry(IMPORT_PATH_METADATA_KEY, import_path)
+                        .build()
+                        .ok(),
+                    );
+                }
+            }
+            _ => {}
+        }
+    }
+    edges
+}
+
+/// `register(x)` / `plugins.Add(x)` in a `var _ = ...` initializer
+fn build_blank_var_call_edge(
+    call: tree_sitter::Node<'_>,
+    source: &str,
+    edge: &dyn Fn(EdgeType, String, usize, &str) -> DependencyEdgeBuilder,
+) -> Option<DependencyEdge> {
+    let function = call.child_by_field_name("function")?;
+    let (name, receiver) = match function.kind() {
+        "identifier" => (&source[function.byte_range()], None),
+        "selector_expression" => (
+            &source[function.child_by_field_name("field")?.byte_range()],
+            function
+                .child_by_field_name("operand")
+                .filter(|o| o.kind() == "identifier")
+                .map(|o| &source[o.byte_range()]),
+        ),
+        _ => return None,
+    };
+    let mut builder = edge(
+        EdgeType::Calls,
+        format!("go:fn:{}:unresolved-reference:0-0", name),
+        call.start_position().row + 1,
+        "blank_var",
+    );
+    if let Some(receiver) = receiver {
+        builder = builder.metadata_entry("receiver_expr", receiver);
+    }
+    builder.build().ok()
+}
+
+/// Descendants of `node` (itself included) of one kind, in source order
+fn collect_descendants_of_kind<'t>(node: tree_sitter::Node<'t>, kind: &str) -> Vec<tree_sitter::Node<'t>> {
+    let mut found = Vec::new();
+    let mut stack = vec![node];
+    while let Some(current) = stack.pop() {
+        if current.kind() == kind {
+            found.push(current);
+        }
+        let mut cursor = current.walk();
+        let children: Vec<_> = current.named_children(&mut cursor).collect();
+        stack.extend(children.into_iter().rev());
+    }
+    found
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_package_init_node_links_inits_and_imports() {
+        let code = r#"
+package store
+
+import (
+    "database/sql"
+    _ "github.com/lib/pq"
+    "example.com/app/plugins"
+)
+
+var defaultDB = sql.OpenDB(nil)
+
+var _ = plugins.Add("store", newStore)
+
+func init() { sql.Register("mem", memDriver{}) }
+
+func init() { warm() }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("internal/store/db.go"), Language::Go).unwrap();
+
+        let inits: Vec<&str> = entities
+            .iter()
+            .filter(|e| e.metadata.get(DECLARED_NAME_METADATA_KEY).map(String::as_str) == Some("init"))
+            .map(|e| e.name.as_str())
+            .collect();
+        assert_eq!(inits, vec!["init.0", "init.1"]);
+
+        let package_key = "go:package:store.init:__internal_store:0-0";
+        let from_package: Vec<(&str, &str)> = edges
+            .iter()
+            .filter(|e| e.from_key.as_str() == package_key)
+            .map(|e| (e.metadata_value(PACKAGE_INIT_METADATA_KEY).unwrap(), e.to_key.as_str().split(':').nth(2).unwrap()))
+            .collect();
+        assert!(from_package.contains(&("init_function", "init.0")), "{:?}", from_package);
+        assert!(from_package.contains(&("init_function", "init.1")));
+        assert!(from_package.contains(&("var_initializer", "defaultDB")));
+        assert!(from_package.contains(&("blank_import", "github.com/lib/pq")));
+        assert!(from_package.iter().any(|(kind, to)| *kind == "blank_var" && to.ends_with("Add")));
+
+        // Registration inside init stays on the renamed init
+        assert!(edges.iter().any(|e| e.from_key.as_str().split(':').nth(2) == Some("init.0")
+            && e.to_key.as_str().split(':').nth(2).is_some_and(|n| n.ends_with("Register"))));
+    }
+
+    #[test]
+    fn test_package_init_single_init_and_mixed_vars() {
+        let code = r#"
+package main
+
+import "fmt"
+
+var cache map[string]int
+
+var a, b = load(), load()
+
+var _, err = setup()
+
+var _ = register(build())
+
+func (s *Server) init() {}
+
+func init() { fmt.Println() }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("main.go"), Language::Go).unwrap();
+
+        // A lone init keeps its name; methods called init are not package inits
+        assert!(!entities.iter().any(|e| e.metadata.contains_key(DECLARED_NAME_METADATA_KEY)));
+
+        // The repository root package is plain `init`
+        let package_key = format_go_package_init_key("");
+        assert_eq!(package_key, "go:package:init:__:0-0");
+        assert_eq!(format_go_package_init_key("internal/jobs"), "go:package:jobs.init:__internal_jobs:0-0");
+
+        let mut from_package: Vec<(&str, &str)> = edges
+            .iter()
+            .filter(|e| e.from_key.as_str() == package_key)
+            .map(|e| {
+                let kind = e.metadata_value(PACKAGE_INIT_METADATA_KEY).unwrap();
+                (kind, e.to_key.as_str().split(':').nth(2).unwrap())
+            })
+            .collect();
+        from_package.sort();
+        // Vars without initializers are skipped; `_, err` belongs to err, and
+        // every call of a `var _ = ...` initializer is the package's own
+        assert_eq!(
+            from_package,
+            vec![
+                ("blank_var", "build"),
+                ("blank_var", "register"),
+                ("init_function", "init"),
+                ("var_initializer", "a"),
+                ("var_initializer", "b"),
+                ("var_initializer", "err"),
+            ]
+        );
+        let init = edges.iter().find(|e| e.metadata_value(PACKAGE_INIT_METADATA_KEY) == Some("init_function")).unwrap();
+        assert!(init.to_key.as_str().starts_with("go:fn:init:"));
+
+        let quiet = Path::new("quiet/q.go");
+        let (_entities, edges) = extractor.parse_source("package quiet\n", quiet, Language::Go).unwrap();
+        assert!(!edges.iter().any(|e| e.from_key.as_str().starts_with(GO_PACKAGE_INIT_KEY_PREFIX)));
+    }
+}
@@ -0,0 +1,441 @@
+//! Go Package Value Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_package_value_extractor
+//!
+//! References to package-level values (`http.StatusOK`, `defaultTimeout`)
+//! are not calls, so the dependency query drops them. This per-file pass:
+//!
+//! 1. Emits top-level `const` / `var` names as `Constant` / `Variable` entities
+//! 2. Records a `Uses` edge for every value reference in a function, method
+//!    or package-level initializer:
+//!    - Same-file const/var/func → the entity key directly
+//!    - Other bare names → `go:var:{name}:unresolved-reference:0-0`
+//!      (plus `dot_imports` metadata when the file has dot imports)
+//!    - Imported names (`http.StatusOK`, or `h.StatusOK` with
+//!      `h "net/http"`) → `go:var:net/http.StatusOK:unresolved-reference:0-0`
+//!      with `import_path` metadata
+//!
+//! The Go cross-file pass resolves placeholders to the declaring package;
+//! values of packages outside the graph (stdlib) stay placeholder nodes.
+//! Each declaration gets one edge per target, at the first reference.
+//!
+//! ## Limitations (syntactic, no go/types)
+//!
+//! - Locals are collected per declaration, not per block: a local that
+//!   shadows a package name hides every reference to it in that function
+//! - Keys of struct literals (`User{Name: n}`) are fields, not values; keys
+//!   of map literals are values
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType, Language};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_field_access_extractor::is_go_call_callee_node;
+use crate::go_import_alias_resolver::{
+    collect_go_file_import_table, GoFileImportTable, DOT_IMPORTS_METADATA_KEY, IMPORT_PATH_METADATA_KEY,
+};
+use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path};
+use crate::query_extractor::{key_component_for_entity_type, EntityType, ParsedEntity};
+
+/// Predeclared identifiers that are never package values
+pub(crate) const GO_PREDECLARED_IDENTIFIER_NAMES: &[&str] = &[
+    "_", "true", "false", "nil", "iota", "append", "cap", "clear", "close", "complex", "copy", "delete",
+    "imag", "len", "make", "max", "min", "new", "panic", "print", "println", "real", "recover", "any",
+    "bool", "byte", "comparable", "complex64", "complex128", "error", "float32", "float64", "int",
+    "int8", "int16", "int32", "int64", "rune", "string", "uint", "uint8", "uint16", "uint32", "uint64",
+    "uintptr",
+];
+
+/// Top-level `const` / `var` names of one Go file as entities
+///
+/// # 4-Word Name: collect_go_package_value_entities
+///
+/// # Contract
+/// - Postcondition: one entity per declared name (`_` skipped), spanning its
+///   spec; grouped declarations (`const ( A = 1; B = 2 )`) yield one each
+pub fn collect_go_package_value_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+) -> Vec<ParsedEntity> {
+    let mut entities = Vec::new();
+    for (spec, entity_type) in collect_go_package_value_specs(root) {
+        let mut cursor = spec.walk();
+        for name in spec.children_by_field_name("name", &mut cursor) {
+            let name = &source[name.byte_range()];
+            if name == "_" {
+                continue;
+            }
+            entities.push(ParsedEntity {
+                entity_type: entity_type.clone(),
+                name: name.to_string(),
+                language: Language::Go,
+                line_range: (spec.start_position().row + 1, spec.end_position().row + 1),
+                file_path: file_path.to_string_lossy().to_string(),
+                metadata: HashMap::new(),
+            });
+        }
+    }
+    entities
+}
+
+/// Extract Uses edges to package-level values for one Go file
+///
+/// # 4-Word Name: extract_go_package_value_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path` and
+///   include `collect_go_package_value_entities`
+/// - Postcondition: per declaration, at most one Uses edge per target;
+///   callees (`f()`, `http.Get()`) are left to the call edges
+pub fn extract_go_package_value_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let imports = collect_go_file_import_table(root, source);
+    let dot_imports = imports.dot_imports_joined();
+    let file_values: HashMap<&str, String> = entities
+        .iter()
+        .filter(|e| matches!(e.entity_type, EntityType::Constant | EntityType::Variable | EntityType::Function))
+        .map(|e| (e.name.as_str(), format_go_parsed_entity_key(e)))
+        .collect();
+
+    // (from key, declaration-or-initializer node, declared locals)
+    let mut owners: Vec<(String, tree_sitter::Node<'_>, HashSet<String>)> = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, from_key)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let mut locals = HashSet::new();
+        collect_go_declared_local_names(declaration, source, &mut locals);
+        owners.push((from_key, body, locals));
+    }
+    for (spec, _) in collect_go_package_value_specs(root) {
+        let Some(value) = spec.child_by_field_name("value") else { continue };
+        let mut locals = HashSet::new();
+        collect_go_declared_local_names(value, source, &mut locals);
+        let mut name_cursor = spec.walk();
+        for name in spec.children_by_field_name("name", &mut name_cursor) {
+            if let Some(from_key) = file_values.get(&source[name.byte_range()]) {
+                owners.push((from_key.clone(), value, locals.clone()));
+            }
+        }
+    }
+
+    let mut edges = Vec::new();
+    for (from_key, node, locals) in owners {
+        let mut references = Vec::new();
+        collect_go_value_references(node, source, &locals, &imports, &mut references);
+        let mut seen: HashSet<String> = HashSet::new();
+        for (name, qualifier, line) in references {
+            let (to_key, metadata) = match qualifier.as_deref().and_then(|a| imports.import_path_of(a)) {
+                Some(import_path) => (
+                    format!("go:var:{}.{}:unresolved-reference:0-0", import_path, name),
+                    Some((IMPORT_PATH_METADATA_KEY, import_path.to_string())),
+                ),
+                None => match file_values.get(name.as_str()) {
+                    Some(key) => (key.clone(), None),
+                    None => (
+                        format!("go:var:{}:unresolved-reference:0-0", name),
+                        dot_imports.clone().map(|paths| (DOT_IMPORTS_METADATA_KEY, paths)),
+                    ),
+                },
+            };
+            if to_key == from_key || !seen.insert(to_key.clone()) {
+                continue;
+            }
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(to_key)
+                .edge_type(EdgeType::Uses)
+                .source_location(format!("{}:{}", path_text, line));
+            if let Some((key, value)) = metadata {
+                builder = builder.metadata_entry(key, value);
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+/// Top-level `const_spec` / `var_spec` nodes with their entity type
+fn collect_go_package_value_specs(root: tree_sitter::Node<'_>) -> Vec<(tree_sitter::Node<'_>, EntityType)> {
+    let mut specs = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        let (spec_kind, entity_type) = match declaration.kind() {
+            "const_declaration" => ("const_spec", EntityType::Constant),
+            "var_declaration" => ("var_spec", EntityType::Variable),
+            _ => continue,
+        };
+        let mut stack = vec![declaration];
+        while let Some(node) = stack.pop() {
+            if node.kind() == spec_kind {
+                specs.push((node, entity_type.clone()));
+                continue;
+            }
+            let mut child_cursor = node.walk();
+            stack.extend(node.named_children(&mut child_cursor));
+        }
+    }
+    specs.sort_by_key(|(spec, _)| spec.start_byte());
+    specs
+}
+
+pub(crate) fn format_go_parsed_entity_key(entity: &ParsedEntity) -> String {
+    format!(
+        "go:{}:{}:{}:T{}",
+        key_component_for_entity_type(&entity.entity_type),
+        entity.name,
+        extract_semantic_path(&entity.file_path),
+        compute_birth_timestamp(&entity.file_path, &entity.name)
+    )
+}
+
+/// Names declared anywhere inside `node`: params, results, receivers, type
+/// params, `:=`, `var`/`const`, range/receive/type-switch bindings
+pub(crate) fn collect_go_declared_local_names(node: tree_sitter::Node<'_>, source: &str, locals: &mut HashSet<String>) {
+    let field = match node.kind() {
+        "parameter_declaration" | "variadic_parameter_declaration" | "type_parameter_declaration" | "var_spec"
+        | "const_spec" => Some("name"),
+        "short_var_declaration" | "range_clause" | "receive_statement" => Some("left"),
+        "type_switch_statement" => Some("alias"),
+        _ => None,
+    };
+    if let Some(field) = field {
+        let mut cursor = node.walk();
+        for declared in node.children_by_field_name(field, &mut cursor) {
+            let mut stack = vec![declared];
+            while let Some(current) = stack.pop() {
+                match current.kind() {
+                    "identifier" => {
+                        locals.insert(source[current.byte_range()].to_string());
+                    }
+                    "expression_list" => {
+                        let mut list_cursor = current.walk();
+                        stack.extend(current.named_children(&mut list_cursor));
+                    }
+                    _ => {}
+                }
+            }
+        }
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_declared_local_names(child, source, locals);
+    }
+}
+
+/// True for the key of a struct literal element (`Name` in `User{Name: n}`)
+fn is_go_struct_literal_key(identifier: tree_sitter::Node<'_>) -> bool {
+    let key = match identifier.parent() {
+        Some(parent) if parent.kind() == "literal_element" => parent,
+        _ => identifier,
+    };
+    let Some(element) = key.parent().filter(|p| p.kind() == "keyed_element") else {
+        return false;
+    };
+    if element.named_child(0).map(|k| k.id()) != Some(key.id()) {
+        return false;
+    }
+    let literal_type = element
+        .parent()
+        .and_then(|body| body.parent())
+        .filter(|literal| literal.kind() == "composite_literal")
+        .and_then(|literal| literal.child_by_field_name("type"));
+    !literal_type.is_some_and(|t| t.kind() == "map_type")
+}
+
+/// (name, import alias, line) of every package value referenced under `node`
+fn collect_go_value_references(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    locals: &HashSet<String>,
+    imports: &GoFileImportTable,
+    references: &mut Vec<(String, Option<String>, usize)>,
+) {
+    match node.kind() {
+        "selector_expression" => {
+            let operand = node.child_by_field_name("operand").filter(|o| o.kind() == "identifier");
+            let alias = operand.map(|o| &source[o.byte_range()]);
+            if let Some(alias) = alias.filter(|a| imports.aliases.contains_key(*a) && !locals.contains(*a)) {
+                if !is_go_call_callee_node(node) {
+                    if let Some(field) = node.child_by_field_name("field") {
+                        references.push((
+                            source[field.byte_range()].to_string(),
+                            Some(alias.to_string()),
+                            node.start_position().row + 1,
+                        ));
+                    }
+                }
+                return;
+            }
+        }
+        "identifier" => {
+            let name = &source[node.byte_range()];
+            let skipped = locals.contains(name)
+                || imports.aliases.contains_key(name)
+                || GO_PREDECLARED_IDENTIFIER_NAMES.contains(&name)
+                || is_go_call_callee_node(node)
+                || is_go_struct_literal_key(node);
+            if !skipped {
+                references.push((name.to_string(), None, node.start_position().row + 1));
+            }
+            return;
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_value_references(child, source, locals, imports, references);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_package_values_become_entities_and_uses() {
+        let code = r#"
+package server
+
+import (
+    "net/http"
+    "time"
+)
+
+const (
+    DefaultPort = 8080
+    _           = 0
+)
+
+var defaultTimeout = 5 * time.Second
+
+func Serve(w http.ResponseWriter, port int) {
+    if port == 0 {
+        port = DefaultPort
+    }
+    w.WriteHeader(http.StatusOK)
+    cfg := Config{Timeout: defaultTimeout}
+    apply(cfg, fallbackHandler)
+    http.Error(w, "", http.StatusOK)
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("srv/serve.go"), Language::Go).unwrap();
+
+        let values: Vec<(&EntityType, &str)> = entities
+            .iter()
+            .filter(|e| matches!(e.entity_type, EntityType::Constant | EntityType::Variable))
+            .map(|e| (&e.entity_type, e.name.as_str()))
+            .collect();
+        assert_eq!(values, vec![(&EntityType::Constant, "DefaultPort"), (&EntityType::Variable, "defaultTimeout")]);
+
+        let uses: Vec<(&str, &str)> = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Uses && !e.to_key.as_str().starts_with("go:module:"))
+            .map(|e| (e.from_key.as_str().split(':').nth(2).unwrap_or(""), e.to_key.as_str()))
+            .collect();
+        let serve_targets: Vec<&str> = uses.iter().filter(|(from, _)| *from == "Serve").map(|(_, to)| *to).collect();
+
+        assert!(serve_targets.iter().any(|t| t.starts_with("go:const:DefaultPort:")), "{:?}", uses);
+        assert!(serve_targets.iter().any(|t| t.starts_with("go:var:defaultTimeout:") && !t.contains("unresolved")));
+        assert!(serve_targets.contains(&"go:var:fallbackHandler:unresolved-reference:0-0"));
+        // One edge per target; calls, locals, params and struct keys are not values
+        assert_eq!(serve_targets.iter().filter(|t| t.contains("http.StatusOK")).count(), 1);
+        assert!(!serve_targets.iter().any(|t| t.contains(":port:") || t.contains(":cfg:") || t.contains("Timeout")));
+        assert!(!serve_targets.iter().any(|t| t.contains("http.Error") || t.contains(":apply:")));
+
+        let status = edges.iter().find(|e| e.to_key.as_str() == "go:var:net/http.StatusOK:unresolved-reference:0-0").unwrap();
+        assert_eq!(status.metadata_value(IMPORT_PATH_METADATA_KEY), Some("net/http"));
+
+        // Package-level initializers reference values too
+        assert!(uses.contains(&("defaultTimeout", "go:var:time.Second:unresolved-reference:0-0")));
+    }
+
+    #[test]
+    fn test_package_values_aliases_dot_imports_and_shadowing() {
+        let code = r#"
+package server
+
+import (
+    h "net/http"
+    . "example.com/app/codes"
+    "time"
+)
+
+var (
+    low, high = 1, 2
+)
+
+func handle() {}
+
+func Route(v any) int {
+    register(handle)
+    weights := map[string]int{KeyA: len(Routes)}
+    switch time := v.(type) {
+    case Clock:
+        _ = time.Zone
+    }
+    if v == nil {
+        return 0
+    }
+    _ = weights
+    return low
+}
+
+func Status() int {
+    return h.StatusOK
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("srv/route.go"), Language::Go).unwrap();
+
+        // Grouped multi-name specs give one entity per name, sharing the spec's lines
+        let values: Vec<(&str, (usize, usize))> = entities
+            .iter()
+            .filter(|e| e.entity_type == EntityType::Variable)
+            .map(|e| (e.name.as_str(), e.line_range))
+            .collect();
+        assert_eq!(values, vec![("low", (11, 11)), ("high", (11, 11))]);
+
+        let route: Vec<&DependencyEdge> = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Uses && e.from_key.as_str().starts_with("go:fn:Route:"))
+            // Type mentions (`case Clock`) are the type reference pass's edges
+            .filter(|e| !e.to_key.as_str().starts_with("go:module:") && !e.to_key.as_str().starts_with("go:type:"))
+            .collect();
+        let targets: Vec<&str> = route.iter().map(|e| e.to_key.as_str()).collect();
+
+        // Same-file functions used as values point at the function entity
+        assert!(targets.iter().any(|t| t.starts_with("go:fn:handle:") && !t.contains("unresolved")), "{:?}", targets);
+        assert!(targets.iter().any(|t| t.starts_with("go:var:low:") && !t.contains("unresolved")));
+        // Map literal keys are values; bare unknown names carry the dot imports
+        let key_a = route
+            .iter()
+            .find(|e| e.to_key.as_str() == "go:var:KeyA:unresolved-reference:0-0")
+            .expect("map key value");
+        assert_eq!(key_a.metadata_value(DOT_IMPORTS_METADATA_KEY), Some("example.com/app/codes"));
+        assert!(targets.contains(&"go:var:Routes:unresolved-reference:0-0"));
+        // A type-switch binding shadows the `time` import; predeclared names are skipped
+        assert!(!targets.iter().any(|t| t.contains("time") || t.contains(":nil:") || t.contains(":len:")));
+        assert_eq!(targets.len(), 4, "{:?}", targets);
+
+        // Aliases resolve to the import path
+        assert!(edges.iter().any(|e| e.from_key.as_str().starts_with("go:fn:Status:")
+            && e.to_key.as_str() == "go:var:net/http.StatusOK:unresolved-reference:0-0"));
+    }
+}
@@ -0,0 +1,486 @@
+//! Go Panic/Recover Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_panic_recover_extractor
+//!
+//! A panic unwinds the call stack until a deferred function calls
+//! `recover()`, or the program dies. This pass records the two ends of that
+//! flow so the graph can answer "is this panic caught before main?":
+//!
+//! - `panic_kinds` on functions (and closures) that may panic directly:
+//!   `panic` for the builtin, `stdlib` for known panicking calls such as
+//!   `regexp.MustCompile`, `template.Must` and `log.Panicf`
+//! - `recovers: true` on functions that call `recover()`; they rescue a
+//!   panic only when run deferred
+//! - `invocation: defer` on the Calls edges of `defer f()` / `defer
+//!   s.cleanup()`, as deferred closures already carry
+//!
+//! `trace_panic_propagation_paths` then walks callers from a panicking
+//! symbol. A caller catches the panic when it defers a function that
+//! recovers. A goroutine entry stops the walk: its spawner cannot catch
+//! what it panics.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_closure_literal_extractor::BYTE_SPAN_METADATA_KEY;
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::go_import_alias_resolver::collect_go_file_import_table;
+use crate::go_package_value_extractor::format_go_parsed_entity_key;
+use crate::query_extractor::ParsedEntity;
+
+/// Entity metadata key: `panic` and/or `stdlib`
+pub const PANIC_KINDS_METADATA_KEY: &str = "panic_kinds";
+
+/// Entity metadata key: the function calls `recover()`
+pub const RECOVERS_METADATA_KEY: &str = "recovers";
+
+/// Standard library functions documented to panic on bad input
+const PANICKING_STDLIB_FUNCTIONS: &[(&str, &[&str])] = &[
+    ("regexp", &["MustCompile", "MustCompilePOSIX"]),
+    ("text/template", &["Must"]),
+    ("html/template", &["Must"]),
+    ("log", &["Panic", "Panicf", "Panicln"]),
+    ("net/netip", &["MustParseAddr", "MustParseAddrPort", "MustParsePrefix"]),
+];
+
+/// Tag the Go functions of one file that may panic or that recover
+///
+/// # 4-Word Name: mark_go_panic_recover_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of the file at `root`,
+///   closures included
+/// - Postcondition: `panic_kinds` / `recovers` on the innermost function or
+///   closure owning each call
+/// - Returns: number of entities tagged
+pub fn mark_go_panic_recover_entities(root: tree_sitter::Node<'_>, source: &str, entities: &mut [ParsedEntity]) -> usize {
+    let imports = collect_go_file_import_table(root, source);
+    let panicking: HashMap<&str, &[&str]> = imports
+        .aliases
+        .iter()
+        .filter_map(|(alias, path)| {
+            PANICKING_STDLIB_FUNCTIONS
+                .iter()
+                .find(|(import_path, _)| *import_path == path.as_str())
+                .map(|(_, names)| (alias.as_str(), *names))
+        })
+        .collect();
+    let closures: HashMap<&str, String> = entities
+        .iter()
+        .filter_map(|e| Some((e.metadata.get(BYTE_SPAN_METADATA_KEY)?.as_str(), format_go_parsed_entity_key(e))))
+        .collect();
+
+    let mut panics: BTreeMap<String, BTreeSet<&'static str>> = BTreeMap::new();
+    let mut recovers: HashSet<String> = HashSet::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, owner)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let mut stack = vec![(declaration, owner)];
+        while let Some((node, owner)) = stack.pop() {
+            let owner = match node.kind() {
+                "func_literal" => closures
+                    .get(format!("{}-{}", node.start_byte(), node.end_byte()).as_str())
+                    .cloned()
+                    .unwrap_or(owner),
+                _ => owner,
+            };
+            if node.kind() == "call_expression" {
+                match node.child_by_field_name("function") {
+                    Some(f) if f.kind() == "identifier" && &source[f.byte_range()] == "panic" => {
+                        panics.entry(owner.clone()).or_default().insert("panic");
+                    }
+                    Some(f) if f.kind() == "identifier" && &source[f.byte_range()] == "recover" => {
+                        recovers.insert(owner.clone());
+                    }
+                    Some(f) if f.kind() == "selector_expression" => {
+                        let operand = f.child_by_field_name("operand").map(|o| &source[o.byte_range()]);
+                        let field = f.child_by_field_name("field").map(|o| &source[o.byte_range()]);
+                        let known = operand.and_then(|o| panicking.get(o));
+                        if let (Some(names), Some(field)) = (known, field) {
+                            if names.contains(&field) {
+                                panics.entry(owner.clone()).or_default().insert("stdlib");
+                            }
+                        }
+                    }
+                    _ => {}
+                }
+            }
+            let mut child_cursor = node.walk();
+            for child in node.named_children(&mut child_cursor) {
+                stack.push((child, owner.clone()));
+            }
+        }
+    }
+
+    let mut tagged = 0;
+    for entity in entities.iter_mut() {
+        let key = format_go_parsed_entity_key(entity);
+        let kinds = panics.get(&key);
+        if let Some(kinds) = kinds {
+            let joined: Vec<&str> = kinds.iter().copied().collect();
+            entity.metadata.insert(PANIC_KINDS_METADATA_KEY.to_string(), joined.join(","));
+        }
+        if recovers.contains(&key) {
+            entity.metadata.insert(RECOVERS_METADATA_KEY.to_string(), "true".to_string());
+        }
+        if kinds.is_some() || recovers.contains(&key) {
+            tagged += 1;
+        }
+    }
+    tagged
+}
+
+/// Mark the Calls edges of one file's `defer f()` statements
+///
+/// # 4-Word Name: annotate_go_deferred_call_edges
+///
+/// # Contract
+/// - Precondition: `edges` come from the same parse of the file at `root`
+/// - Postcondition: Calls edges at a deferred call's line, to its callee
+///   name, carry `invocation: defer`; closure edges keep their own value
+/// - Returns: number of edges marked
+pub fn annotate_go_deferred_call_edges(root: tree_sitter::Node<'_>, source: &str, edges: &mut [DependencyEdge]) -> usize {
+    let mut deferred: HashSet<(usize, &str)> = HashSet::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        if node.kind() == "defer_statement" {
+            let callee = node
+                .named_child(0)
+                .filter(|c| c.kind() == "call_expression")
+                .and_then(|c| c.child_by_field_name("function"))
+                .and_then(|f| match f.kind() {
+                    "identifier" => Some(f),
+                    "selector_expression" => f.child_by_field_name("field"),
+                    _ => None,
+                });
+            if let Some(callee) = callee {
+                deferred.insert((node.start_position().row + 1, &source[callee.byte_range()]));
+            }
+        }
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+    }
+
+    let mut marked = 0;
+    for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::Calls && e.metadata_value("invocation").is_none()) {
+        let line = edge
+            .source_location
+            .as_deref()
+            .and_then(|l| l.rsplit_once(':'))
+            .and_then(|(_, line)| line.parse::<usize>().ok());
+        let name = edge.to_key.as_str().split(':').nth(2).and_then(|n| n.rsplit('.').next());
+        if let (Some(line), Some(name)) = (line, name) {
+            if deferred.contains(&(line, name)) {
+                edge.metadata.insert("invocation".to_string(), "defer".to_string());
+                marked += 1;
+            }
+        }
+    }
+    marked
+}
+
+/// Where a panic stops: caught by a deferred recover, or escaping
+///
+/// # 4-Word Name: PanicPropagationPathEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PanicPropagationPathEntry {
+    /// Panicking symbol first, the function where the walk stopped last
+    pub path: Vec<String>,
+    /// `caught`, `main`, `goroutine` (entry of a spawned goroutine) or
+    /// `entry` (no known caller)
+    pub outcome: String,
+    /// Deferred function that recovers, for `caught`
+    pub recovered_by: Option<String>,
+}
+
+/// Every way a panic in `origin_key` can unwind, shortest path first
+///
+/// # 4-Word Name: trace_panic_propagation_paths
+///
+/// # Contract
+/// - Precondition: `entities` carry extractor metadata (`recovers`) and
+///   `edges` the `invocation: defer` marks
+/// - Postcondition: one entry per stopping point within `max_depth` caller
+///   hops: functions deferring a recovering function (`caught`), `main`,
+///   goroutine entries and callerless symbols; placeholder edges match by
+///   language and name; sorted by path length, then last key
+/// - Performance: O(V + E)
+pub fn trace_panic_propagation_paths(
+    origin_key: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    max_depth: usize,
+) -> Vec<PanicPropagationPathEntry> {
+    let recovering: HashSet<&str> = entities
+        .iter()
+        .filter(|e| e.metadata.additional.get(RECOVERS_METADATA_KEY).is_some_and(|v| v == "true"))
+        .map(|e| e.isgl1_key.as_str())
+        .collect();
+    let mut by_language_name: HashMap<(&str, &str), Vec<&str>> = HashMap::new();
+    for entity in entities {
+        if let Some(language) = entity.isgl1_key.split(':').next() {
+            by_language_name
+                .entry((language, entity.interface_signature.name.as_str()))
+                .or_default()
+                .push(entity.isgl1_key.as_str());
+        }
+    }
+    let resolve = |to_key: &str| -> Vec<String> {
+        match (parse_unresolved_reference_name(to_key), to_key.split(':').next()) {
+            (Some(name), Some(language)) => {
+                let short = name.rsplit('.').next().unwrap_or(name);
+                by_language_name
+                    .get(&(language, short))
+                    .into_iter()
+                    .flatten()
+                    .map(|k| k.to_string())
+                    .collect()
+            }
+            _ => vec![to_key.to_string()],
+        }
+    };
+
+    let mut callers: HashMap<String, BTreeSet<String>> = HashMap::new();
+    let mut spawned: HashSet<String> = HashSet::new();
+    let mut handlers: HashMap<String, String> = HashMap::new();
+    for edge in edges {
+        let from = edge.from_key.as_str();
+        match edge.edge_type {
+            EdgeType::Calls => {
+                for target in resolve(edge.to_key.as_str()) {
+                    if edge.metadata_value("invocation") == Some("defer") && recovering.contains(target.as_str()) {
+                        handlers.entry(from.to_string()).or_insert_with(|| target.clone());
+                    }
+                    callers.entry(target).or_default().insert(from.to_string());
+                }
+            }
+            EdgeType::Spawns => spawned.extend(resolve(edge.to_key.as_str())),
+            _ => {}
+        }
+    }
+
+    let mut parents: HashMap<String, Option<String>> = HashMap::from([(origin_key.to_string(), None)]);
+    let mut queue = VecDeque::from([(origin_key.to_string(), 0usize)]);
+    let mut paths = Vec::new();
+    while let Some((key, depth)) = queue.pop_front() {
+        let name = key.split(':').nth(2).unwrap_or_default();
+        let outcome = if handlers.contains_key(&key) {
+            Some("caught")
+        } else if name == "main" {
+            Some("main")
+        } else if spawned.contains(&key) {
+            Some("goroutine")
+        } else if !callers.contains_key(&key) {
+            Some("entry")
+        } else {
+            None
+        };
+        if let Some(outcome) = outcome {
+            let mut path = vec![key.clone()];
+            let mut current = key.clone();
+            while let Some(Some(parent)) = parents.get(&current) {
+                path.push(parent.clone());
+                current = parent.clone();
+            }
+            path.reverse();
+            paths.push(PanicPropagationPathEntry {
+                path,
+                outcome: outcome.to_string(),
+                recovered_by: handlers.get(&key).cloned(),
+            });
+            // A spawned function may also be called directly; keep unwinding then
+            if outcome != "goroutine" {
+                continue;
+            }
+        }
+        if depth >= max_depth {
+            continue;
+        }
+        for caller in callers.get(&key).into_iter().flatten() {
+            if !parents.contains_key(caller) {
+                parents.insert(caller.clone(), Some(key.clone()));
+                queue.push_back((caller.clone(), depth + 1));
+            }
+        }
+    }
+    paths.sort_by(|a, b| a.path.len().cmp(&b.path.len()).then_with(|| a.path.last().cmp(&b.path.last())));
+    paths
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EntityType as CodeEntityType, Language};
+    use crate::query_extractor::QueryBasedExtractor;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use std::path::Path;
+
+    #[test]
+    fn test_panics_recovers_and_unwinding_paths() {
+        let code = r#"
+package app
+
+import "regexp"
+
+var slug = regexp.MustCompile(`^[a-z]+$`)
+
+func parse(s string) int {
+    if s == "" {
+        panic("empty")
+    }
+    return len(s)
+}
+
+func rescue() {
+    if r := recover(); r != nil {
+        log(r)
+    }
+}
+
+func Safe(s string) int {
+    defer rescue()
+    return parse(s)
+}
+
+func Guarded(s string) (n int) {
+    defer func() { recover() }()
+    return parse(s)
+}
+
+func compile(p string) { regexp.MustCompile(p) }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("app/parse.go"), Language::Go).unwrap();
+
+        let meta = |name: &str, key: &str| {
+            entities.iter().find(|e| e.name == name).and_then(|e| e.metadata.get(key)).map(String::as_str)
+        };
+        assert_eq!(meta("parse", PANIC_KINDS_METADATA_KEY), Some("panic"));
+        assert_eq!(meta("compile", PANIC_KINDS_METADATA_KEY), Some("stdlib"));
+        assert_eq!(meta("rescue", RECOVERS_METADATA_KEY), Some("true"));
+        assert_eq!(meta("Guarded.func1", RECOVERS_METADATA_KEY), Some("true"));
+        assert_eq!(meta("Guarded", RECOVERS_METADATA_KEY), None, "recover runs in the closure");
+        assert!(edges.iter().any(|e| e.to_key.as_str().split(':').nth(2) == Some("rescue")
+            && e.metadata_value("invocation") == Some("defer")));
+
+        // Graph-level walk: main → Run → parse, and Safe → parse with a deferred rescue
+        let key = |name: &str| format!("go:fn:{}:__app:T1", name);
+        let mut code_entities: Vec<CodeEntity> = ["parse", "Safe", "Run", "main"]
+            .iter()
+            .map(|name| create_go_test_entity(&key(name), name, CodeEntityType::Function, "app/parse.go", &[]))
+            .collect();
+        code_entities.push(create_go_test_entity(
+            &key("rescue"),
+            "rescue",
+            CodeEntityType::Function,
+            "app/parse.go",
+            &[(RECOVERS_METADATA_KEY, "true")],
+        ));
+        let call = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(key(from)).to_key(to.to_string()).edge_type(EdgeType::Calls)
+        };
+        let graph = vec![
+            call("Safe", &key("parse")).build().unwrap(),
+            call("Safe", "go:fn:rescue:unresolved-reference:0-0").metadata_entry("invocation", "defer").build().unwrap(),
+            call("Run", &key("parse")).build().unwrap(),
+            call("main", &key("Run")).build().unwrap(),
+        ];
+        let paths = trace_panic_propagation_paths(&key("parse"), &code_entities, &graph, 10);
+        let outcomes: Vec<(&str, usize)> = paths.iter().map(|p| (p.outcome.as_str(), p.path.len())).collect();
+        assert_eq!(outcomes, vec![("caught", 2), ("main", 3)]);
+        assert_eq!(paths[0].recovered_by.as_deref(), Some(key("rescue").as_str()));
+    }
+
+    #[test]
+    fn test_panic_owners_aliases_and_deferred_selectors() {
+        let code = r#"
+package app
+
+import (
+    l "log"
+    "text/template"
+)
+
+var page = template.Must(template.New("x").Parse(""))
+
+func strict(v int) {
+    if v < 0 {
+        l.Panicf("negative %d", v)
+    }
+    panic("always")
+}
+
+func (s *Server) Close() {
+    defer s.flush()
+    defer cleanup()
+    go func() {
+        panic("worker")
+    }()
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(code, Path::new("app/close.go"), Language::Go).unwrap();
+        let panic_kinds = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(PANIC_KINDS_METADATA_KEY))
+                .map(String::as_str)
+        };
+        // Both kinds merge; aliased `log` still counts
+        assert_eq!(panic_kinds("strict"), Some("panic,stdlib"));
+        // The closure owns its panic, not the method that spawns it
+        assert_eq!(panic_kinds("Server.Close.func1"), Some("panic"));
+        assert_eq!(panic_kinds("Close"), None);
+        // Package-level initializers are not functions
+        assert_eq!(panic_kinds("page"), None);
+
+        let invocation = |name: &str| {
+            edges
+                .iter()
+                .filter(|e| e.edge_type == EdgeType::Calls)
+                .find(|e| e.to_key.as_str().split(':').nth(2).and_then(|n| n.rsplit('.').next()) == Some(name))
+                .and_then(|e| e.metadata_value("invocation"))
+        };
+        assert_eq!(invocation("flush"), Some("defer"));
+        assert_eq!(invocation("cleanup"), Some("defer"));
+    }
+
+    #[test]
+    fn test_panic_trace_goroutines_entries_and_plain_defers() {
+        let key = |name: &str| format!("go:fn:{}:__app:T1", name);
+        let code_entities: Vec<CodeEntity> = ["worker", "Run", "Start", "cleanup"]
+            .iter()
+            .map(|name| create_go_test_entity(&key(name), name, CodeEntityType::Function, "app/run.go", &[]))
+            .collect();
+        let edge = |from: &str, to: &str, edge_type: EdgeType| {
+            DependencyEdge::builder().from_key(key(from)).to_key(to.to_string()).edge_type(edge_type)
+        };
+        let graph = vec![
+            edge("Start", "go:fn:worker:unresolved-reference:0-0", EdgeType::Spawns).build().unwrap(),
+            edge("Run", &key("worker"), EdgeType::Calls).build().unwrap(),
+            edge("worker", &key("worker"), EdgeType::Calls).build().unwrap(),
+            // Deferring a function that does not recover catches nothing
+            edge("Run", &key("cleanup"), EdgeType::Calls).metadata_entry("invocation", "defer").build().unwrap(),
+        ];
+
+        // A spawned function called directly too keeps unwinding into its callers
+        let paths = trace_panic_propagation_paths(&key("worker"), &code_entities, &graph, 10);
+        let outcomes: Vec<(&str, Vec<&str>)> = paths
+            .iter()
+            .map(|p| (p.outcome.as_str(), p.path.iter().map(|k| k.split(':').nth(2).unwrap()).collect()))
+            .collect();
+        assert_eq!(outcomes, vec![("goroutine", vec!["worker"]), ("entry", vec!["worker", "Run"])]);
+        assert!(paths.iter().all(|p| p.recovered_by.is_none()));
+
+        assert_eq!(trace_panic_propagation_paths(&key("worker"), &code_entities, &graph, 0).len(), 1);
+    }
+}
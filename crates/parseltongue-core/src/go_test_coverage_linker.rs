@@ -0,0 +1,193 @@
+//! Go Test Coverage Linker (v1.7.3)
+//!
+//! # 4-Word Naming: go_test_coverage_linker
+//!
+//! Entities declared in `_test.go` files are kept in the graph with a
+//! `facet=test` metadata entry, and each Go test function
+//! (`TestXxx`, `BenchmarkXxx`, `FuzzXxx`, `ExampleXxx`) gets a `Tests`
+//! edge to every production symbol it exercises:
+//!
+//! ```text
+//! TestCreateUser --Calls--> newFixture --Calls--> UserService.Create
+//! TestCreateUser --Tests--> UserService.Create   (coverage=helper)
+//! ```
+//!
+//! The walk follows Calls / Spawns / Uses / Reads / Writes edges through
+//! other `_test.go` entities (helpers, fixtures, fakes) and stops at the
+//! first production entity on each path. Direct references carry
+//! `coverage=direct`, ones reached through a helper `coverage=helper`.
+//! Placeholders (external targets, `go:field:` / `go:chan:` nodes) are
+//! never test targets.
+//!
+//! Run after the cross-file resolver so edges point at real entity keys.
+
+use std::collections::{BTreeMap, HashMap, HashSet, VecDeque};
+use std::path::Path;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_embedding_promotion_resolver::is_go_entity_file_path;
+
+/// Entity metadata key of the test/production facet
+pub const TEST_FACET_METADATA_KEY: &str = "facet";
+
+/// `facet` value of entities declared in `_test.go` files
+pub const TEST_FACET_METADATA_VALUE: &str = "test";
+
+/// Edge metadata key: `direct` or `helper`
+pub const TEST_COVERAGE_METADATA_KEY: &str = "coverage";
+
+/// True for Go test files (`*_test.go`)
+///
+/// # 4-Word Name: is_go_test_file_path
+pub fn is_go_test_file_path(path: &Path) -> bool {
+    path.file_name()
+        .and_then(|name| name.to_str())
+        .is_some_and(|name| name.ends_with("_test.go"))
+}
+
+/// True for names `go test` runs: `Test`, `Benchmark`, `Fuzz`, `Example` + non-lowercase rest
+///
+/// # 4-Word Name: is_go_test_function_name
+pub fn is_go_test_function_name(name: &str) -> bool {
+    ["Test", "Benchmark", "Fuzz", "Example"].iter().any(|prefix| {
+        name.strip_prefix(prefix)
+            .is_some_and(|rest| !rest.starts_with(|c: char| c.is_lowercase()))
+    })
+}
+
+/// True for entities tagged with the `test` facet
+///
+/// # 4-Word Name: has_test_facet_metadata
+pub fn has_test_facet_metadata(entity: &CodeEntity) -> bool {
+    entity.metadata.additional.get(TEST_FACET_METADATA_KEY).map(String::as_str) == Some(TEST_FACET_METADATA_VALUE)
+}
+
+/// Compute `Tests` edges from Go test functions to production symbols
+///
+/// # 4-Word Name: compute_go_test_coverage_edges
+///
+/// # Contract
+/// - Precondition: `edges` already resolved by the Go cross-file pass
+/// - Postcondition: one `Tests` edge per (test function, production entity)
+///   pair, `coverage=direct` when some path has no helper in between;
+///   sorted by (from_key, to_key)
+pub fn compute_go_test_coverage_edges(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<DependencyEdge> {
+    let go_entities: Vec<&CodeEntity> = entities
+        .iter()
+        .filter(|e| is_go_entity_file_path(&e.interface_signature.file_path))
+        .collect();
+    let test_file_keys: HashSet<&str> = go_entities
+        .iter()
+        .filter(|e| is_go_test_file_path(&e.interface_signature.file_path))
+        .map(|e| e.isgl1_key.as_str())
+        .collect();
+    let production_keys: HashSet<&str> = go_entities
+        .iter()
+        .filter(|e| !is_go_test_file_path(&e.interface_signature.file_path))
+        .filter(|e| !e.isgl1_key.ends_with(":0-0"))
+        .map(|e| e.isgl1_key.as_str())
+        .collect();
+
+    let mut forward: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| {
+        matches!(
+            e.edge_type,
+            EdgeType::Calls | EdgeType::Spawns | EdgeType::Uses | EdgeType::Reads | EdgeType::Writes
+        )
+    }) {
+        forward.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+    }
+
+    let mut coverage: BTreeMap<(&str, &str), bool> = BTreeMap::new();
+    for test in go_entities.iter().filter(|e| {
+        test_file_keys.contains(e.isgl1_key.as_str())
+            && e.interface_signature.entity_type == EntityType::Function
+            && is_go_test_function_name(&e.interface_signature.name)
+    }) {
+        let test_key = test.isgl1_key.as_str();
+        let mut visited: HashSet<&str> = HashSet::from([test_key]);
+        let mut queue: VecDeque<(&str, bool)> = VecDeque::from([(test_key, true)]);
+        while let Some((key, direct)) = queue.pop_front() {
+            for &target in forward.get(key).into_iter().flatten() {
+                if production_keys.contains(target) {
+                    *coverage.entry((test_key, target)).or_insert(direct) |= direct;
+                } else if test_file_keys.contains(target) && visited.insert(target) {
+                    queue.push_back((target, false));
+                }
+            }
+        }
+    }
+
+    coverage
+        .into_iter()
+        .filter_map(|((test_key, target), direct)| {
+            DependencyEdge::builder()
+                .from_key(test_key)
+                .to_key(target)
+                .edge_type(EdgeType::Tests)
+                .metadata_entry(TEST_COVERAGE_METADATA_KEY, if direct { "direct" } else { "helper" })
+                .build()
+                .ok()
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn calls(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_names_follow_go_test_rules() {
+        assert!(is_go_test_function_name("TestCreateUser"));
+        assert!(is_go_test_function_name("Test"));
+        assert!(is_go_test_function_name("Test_parse"));
+        assert!(is_go_test_function_name("BenchmarkEncode"));
+        assert!(is_go_test_function_name("ExampleClient_Do"));
+        assert!(!is_go_test_function_name("Testify"));
+        assert!(!is_go_test_function_name("newFixture"));
+        assert!(is_go_test_file_path(Path::new("internal/users/service_test.go")));
+        assert!(!is_go_test_file_path(Path::new("internal/users/service.go")));
+    }
+
+    #[test]
+    fn test_tests_edges_reach_through_helpers() {
+        let test_file = "internal/users/service_test.go";
+        let entities = vec![
+            create_go_test_entity("go:fn:TestCreate:__t:T1", "TestCreate", EntityType::Function, test_file, &[]),
+            create_go_test_entity("go:fn:newFixture:__t:T2", "newFixture", EntityType::Function, test_file, &[]),
+            create_go_test_entity("go:method:Create:__s:T3", "Create", EntityType::Method, "internal/users/service.go", &[]),
+            create_go_test_entity("go:fn:Validate:__s:T4", "Validate", EntityType::Function, "internal/users/service.go", &[]),
+        ];
+        let edges = vec![
+            calls("go:fn:TestCreate:__t:T1", "go:fn:newFixture:__t:T2"),
+            calls("go:fn:TestCreate:__t:T1", "go:method:Create:__s:T3"),
+            calls("go:fn:TestCreate:__t:T1", "go:fn:Errorf:unresolved-reference:0-0"),
+            calls("go:fn:newFixture:__t:T2", "go:fn:Validate:__s:T4"),
+            calls("go:fn:newFixture:__t:T2", "go:method:Create:__s:T3"),
+        ];
+
+        let tests = compute_go_test_coverage_edges(&entities, &edges);
+        let summary: Vec<(&str, Option<&str>)> = tests
+            .iter()
+            .map(|e| (e.to_key.as_str(), e.metadata_value(TEST_COVERAGE_METADATA_KEY)))
+            .collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("go:fn:Validate:__s:T4", Some("helper")),
+                ("go:method:Create:__s:T3", Some("direct")),
+            ]
+        );
+        assert!(tests.iter().all(|e| e.from_key.as_str() == "go:fn:TestCreate:__t:T1"));
+    }
+}
@@ -0,0 +1,386 @@
+//! Go Type Reference Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: go_type_reference_extractor
+//!
+//! "Where is `User` used?" is more than its constructors: a type shows up in
+//! struct fields, signatures, variable declarations and type assertions,
+//! none of which are calls. This per-file pass records a `Uses` edge from
+//! the declaration mentioning a type to the type, with `usage_kinds`
+//! metadata naming how it is mentioned:
+//!
+//! - `field`: struct field types, from the struct (`Buyer *User`)
+//! - `parameter` / `return`: function, method, function-literal and
+//!   interface-method signatures
+//! - `variable`: `var u User` (locals and package-level vars)
+//! - `type_assertion`: `x.(User)` and type-switch cases
+//!
+//! Targets follow the package-value scheme: same-file structs/interfaces by
+//! key, other bare names as `go:type:{name}:unresolved-reference:0-0`
+//! (`dot_imports` metadata when the file has them), imported names as
+//! `go:type:{import_path}.{name}:unresolved-reference:0-0` with
+//! `import_path`. The Go cross-file pass resolves them to the declaring
+//! package; stdlib types (`time.Time`) stay placeholder nodes.
+//!
+//! One edge per (declaration, type): every kind is listed, the location is
+//! the first mention. Predeclared types and type parameters are skipped;
+//! embedded fields are `Embeds` edges already.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+use std::path::Path;
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::go_field_access_extractor::TYPE_QUALIFIER_METADATA_KEY;
+use crate::go_import_alias_resolver::{collect_go_file_import_table, DOT_IMPORTS_METADATA_KEY, IMPORT_PATH_METADATA_KEY};
+use crate::go_package_value_extractor::{format_go_parsed_entity_key, GO_PREDECLARED_IDENTIFIER_NAMES};
+use crate::query_extractor::{EntityType, ParsedEntity};
+
+/// Edge metadata key: comma-separated usage kinds (`field,parameter`)
+pub const USAGE_KINDS_METADATA_KEY: &str = "usage_kinds";
+
+/// Usage kind reported for `Embeds` edges by `group_type_usage_sites`
+pub const EMBEDDING_USAGE_KIND_NAME: &str = "embedding";
+
+/// Usage kind of a type mentioned under `field` of a `parent` node.
+/// `Some(None)` stops classification below (receivers, constraints,
+/// embedded fields); `None` keeps the enclosing kind.
+fn classify_go_type_position(parent: tree_sitter::Node<'_>, field: &str) -> Option<Option<&'static str>> {
+    match (parent.kind(), field) {
+        ("function_declaration" | "method_declaration" | "func_literal" | "function_type" | "method_elem"
+        | "method_spec", "parameters") => Some(Some("parameter")),
+        ("function_declaration" | "method_declaration" | "func_literal" | "function_type" | "method_elem"
+        | "method_spec", "result") => Some(Some("return")),
+        ("method_declaration", "receiver") | (_, "type_parameters") => Some(None),
+        ("field_declaration", "type") if parent.child_by_field_name("name").is_some() => Some(Some("field")),
+        ("field_declaration", "type") => Some(None),
+        ("var_spec", "type") => Some(Some("variable")),
+        ("type_assertion_expression" | "type_case", "type") => Some(Some("type_assertion")),
+        _ => None,
+    }
+}
+
+/// (type name, package alias, usage kind, line) under `node`
+fn collect_go_type_mentions(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    kind: Option<&'static str>,
+    type_parameters: &HashSet<String>,
+    mentions: &mut Vec<(String, Option<String>, &'static str, usize)>,
+) {
+    if let Some(kind) = kind {
+        let line = node.start_position().row + 1;
+        match node.kind() {
+            "type_identifier" => {
+                let name = &source[node.byte_range()];
+                if !GO_PREDECLARED_IDENTIFIER_NAMES.contains(&name) && !type_parameters.contains(name) {
+                    mentions.push((name.to_string(), None, kind, line));
+                }
+                return;
+            }
+            "qualified_type" => {
+                let package = node.child_by_field_name("package").map(|p| source[p.byte_range()].to_string());
+                if let (Some(package), Some(name)) = (package, node.child_by_field_name("name")) {
+                    mentions.push((source[name.byte_range()].to_string(), Some(package), kind, line));
+                }
+                return;
+            }
+            _ => {}
+        }
+    }
+    let mut cursor = node.walk();
+    if !cursor.goto_first_child() {
+        return;
+    }
+    loop {
+        let child = cursor.node();
+        if child.is_named() {
+            let child_kind = match cursor.field_name().and_then(|field| classify_go_type_position(node, field)) {
+                Some(overridden) => overridden,
+                None => kind,
+            };
+            collect_go_type_mentions(child, source, child_kind, type_parameters, mentions);
+        }
+        if !cursor.goto_next_sibling() {
+            break;
+        }
+    }
+}
+
+/// Type parameter names declared under `node` (`T`, `K`, `V`)
+fn collect_go_type_parameter_names(node: tree_sitter::Node<'_>, source: &str, names: &mut HashSet<String>) {
+    if node.kind() == "type_parameter_declaration" {
+        let mut cursor = node.walk();
+        for name in node.children_by_field_name("name", &mut cursor) {
+            names.insert(source[name.byte_range()].to_string());
+        }
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_go_type_parameter_names(child, source, names);
+    }
+}
+
+/// Entity key of a top-level type or value spec
+pub(crate) fn find_go_spec_entity_key(spec: tree_sitter::Node<'_>, source: &str, entities: &[ParsedEntity]) -> Option<String> {
+    let name = &source[spec.child_by_field_name("name")?.byte_range()];
+    let line = spec.start_position().row + 1;
+    entities
+        .iter()
+        .find(|e| {
+            e.name == name
+                && e.line_range.0 <= line
+                && e.line_range.1 >= line
+                && matches!(
+                    e.entity_type,
+                    EntityType::Struct | EntityType::Interface | EntityType::Variable | EntityType::Constant
+                )
+        })
+        .map(format_go_parsed_entity_key)
+}
+
+/// Extract type-usage Uses edges for one Go file
+///
+/// # 4-Word Name: extract_go_type_reference_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path` and
+///   include `collect_go_package_value_entities`
+/// - Postcondition: at most one Uses edge per (declaration, type), with
+///   sorted `usage_kinds`; no self edges
+pub fn extract_go_type_reference_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let imports = collect_go_file_import_table(root, source);
+    let dot_imports = imports.dot_imports_joined();
+    let file_types: HashMap<&str, String> = entities
+        .iter()
+        .filter(|e| matches!(e.entity_type, EntityType::Struct | EntityType::Interface))
+        .map(|e| (e.name.as_str(), format_go_parsed_entity_key(e)))
+        .collect();
+
+    // (owner key, node to scan, classification of the node itself)
+    let mut owners: Vec<(String, tree_sitter::Node<'_>, Option<&'static str>)> = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        match declaration.kind() {
+            "function_declaration" | "method_declaration" => {
+                if let Some((_, key)) = find_go_declaration_entity_key(declaration, source, entities) {
+                    owners.push((key, declaration, None));
+                }
+            }
+            "type_declaration" | "var_declaration" => {
+                let mut stack = vec![declaration];
+                while let Some(node) = stack.pop() {
+                    if matches!(node.kind(), "type_spec" | "var_spec") {
+                        if let Some(key) = find_go_spec_entity_key(node, source, entities) {
+                            owners.push((key, node, None));
+                        }
+                        continue;
+                    }
+                    let mut child_cursor = node.walk();
+                    stack.extend(node.named_children(&mut child_cursor));
+                }
+            }
+            _ => {}
+        }
+    }
+
+    let mut edges = Vec::new();
+    for (from_key, node, kind) in owners {
+        let mut type_parameters = HashSet::new();
+        collect_go_type_parameter_names(node, source, &mut type_parameters);
+        let mut mentions = Vec::new();
+        collect_go_type_mentions(node, source, kind, &type_parameters, &mut mentions);
+
+        // Target key → (first line, metadata, kinds), in first-mention order
+        let mut targets: Vec<(String, usize, Option<(&str, String)>, BTreeSet<&str>)> = Vec::new();
+        for (name, qualifier, usage_kind, line) in mentions {
+            let (to_key, metadata) = match qualifier {
+                Some(alias) => match imports.import_path_of(&alias) {
+                    Some(import_path) => (
+                        format!("go:type:{}.{}:unresolved-reference:0-0", import_path, name),
+                        Some((IMPORT_PATH_METADATA_KEY, import_path.to_string())),
+                    ),
+                    None => (
+                        format!("go:type:{}.{}:unresolved-reference:0-0", alias, name),
+                        Some((TYPE_QUALIFIER_METADATA_KEY, alias)),
+                    ),
+                },
+                None => match file_types.get(name.as_str()) {
+                    Some(key) => (key.clone(), None),
+                    None => (
+                        format!("go:type:{}:unresolved-reference:0-0", name),
+                        dot_imports.clone().map(|paths| (DOT_IMPORTS_METADATA_KEY, paths)),
+                    ),
+                },
+            };
+            if to_key == from_key {
+                continue;
+            }
+            match targets.iter_mut().find(|(key, ..)| *key == to_key) {
+                Some((_, _, _, kinds)) => {
+                    kinds.insert(usage_kind);
+                }
+                None => targets.push((to_key, line, metadata, BTreeSet::from([usage_kind]))),
+            }
+        }
+
+        for (to_key, line, metadata, kinds) in targets {
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(to_key)
+                .edge_type(EdgeType::Uses)
+                .source_location(format!("{}:{}", path_text, line))
+                .metadata_entry(USAGE_KINDS_METADATA_KEY, kinds.into_iter().collect::<Vec<_>>().join(","));
+            if let Some((key, value)) = metadata {
+                builder = builder.metadata_entry(key, value);
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+/// One declaration that mentions a type
+///
+/// # 4-Word Name: TypeUsageSiteEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeUsageSiteEntry {
+    pub entity_key: String,
+    pub name: String,
+    /// First mention (`file:line`)
+    pub location: Option<String>,
+}
+
+/// Declarations mentioning a type, grouped by usage kind
+///
+/// # 4-Word Name: group_type_usage_sites
+///
+/// # Contract
+/// - Precondition: `type_keys` = the type's entity key and/or its
+///   unresolved placeholder keys
+/// - Postcondition: kinds in name order, sites sorted by (name, key);
+///   `Embeds` edges reported as `embedding`; an edge with several kinds is
+///   listed under each
+pub fn group_type_usage_sites(
+    type_keys: &HashSet<String>,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> BTreeMap<String, Vec<TypeUsageSiteEntry>> {
+    let names: HashMap<&str, &str> = entities
+        .iter()
+        .map(|e| (e.isgl1_key.as_str(), e.interface_signature.name.as_str()))
+        .collect();
+    let mut grouped: BTreeMap<String, Vec<TypeUsageSiteEntry>> = BTreeMap::new();
+    for edge in edges.iter().filter(|e| type_keys.contains(e.to_key.as_str())) {
+        let kinds: Vec<&str> = match edge.edge_type {
+            EdgeType::Embeds => vec![EMBEDDING_USAGE_KIND_NAME],
+            EdgeType::Uses => match edge.metadata_value(USAGE_KINDS_METADATA_KEY) {
+                Some(kinds) => kinds.split(',').collect(),
+                None => continue,
+            },
+            _ => continue,
+        };
+        let from_key = edge.from_key.as_str();
+        let name = names
+            .get(from_key)
+            .copied()
+            .or_else(|| parse_unresolved_reference_name(from_key))
+            .unwrap_or(from_key);
+        for kind in kinds {
+            grouped.entry(kind.to_string()).or_default().push(TypeUsageSiteEntry {
+                entity_key: from_key.to_string(),
+                name: name.to_string(),
+                location: edge.source_location.clone(),
+            });
+        }
+    }
+    for sites in grouped.values_mut() {
+        sites.sort_by(|a, b| (&a.name, &a.entity_key).cmp(&(&b.name, &b.entity_key)));
+        sites.dedup();
+    }
+    grouped
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_type_mentions_become_uses_by_kind() {
+        let code = r#"
+package shop
+
+import (
+    "example.com/app/models"
+    "time"
+)
+
+type Order struct {
+    Buyer   *models.User
+    Placed  time.Time
+    Items   []Item
+    Next    *Order
+    Item
+}
+
+var fallback models.User
+
+type Repo interface {
+    Find(id string) (*models.User, error)
+}
+
+func Lookup[T any](seen T, r Repo) models.User {
+    var u models.User
+    if v, ok := any(r).(*models.User); ok {
+        u = *v
+    }
+    return u
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, edges) = extractor.parse_source(code, Path::new("shop/order.go"), Language::Go).unwrap();
+
+        let user = "go:type:example.com/app/models.User:unresolved-reference:0-0";
+        let kinds_of = |from: &str, to: &str| {
+            edges
+                .iter()
+                .find(|e| {
+                    e.edge_type == EdgeType::Uses
+                        && e.from_key.as_str().split(':').nth(2) == Some(from)
+                        && e.to_key.as_str() == to
+                })
+                .and_then(|e| e.metadata_value(USAGE_KINDS_METADATA_KEY))
+                .map(str::to_string)
+        };
+        assert_eq!(kinds_of("Order", user).as_deref(), Some("field"));
+        assert_eq!(kinds_of("fallback", user).as_deref(), Some("variable"));
+        assert_eq!(kinds_of("Find", user), None, "interface methods belong to the interface");
+        assert_eq!(kinds_of("Repo", user).as_deref(), Some("return"));
+        assert_eq!(kinds_of("Lookup", user).as_deref(), Some("return,type_assertion,variable"));
+        assert_eq!(kinds_of("Order", "go:type:time.Time:unresolved-reference:0-0").as_deref(), Some("field"));
+        assert_eq!(kinds_of("Order", "go:type:Item:unresolved-reference:0-0").as_deref(), Some("field"));
+
+        let lookup_targets: Vec<&str> = edges
+            .iter()
+            .filter(|e| e.metadata_value(USAGE_KINDS_METADATA_KEY).is_some())
+            .filter(|e| e.from_key.as_str().split(':').nth(2) == Some("Lookup"))
+            .map(|e| e.to_key.as_str())
+            .collect();
+        // Type parameters, predeclared types and self references are skipped
+        assert!(!lookup_targets.iter().any(|t| t.contains(":T:") || t.contains(":any:") || t.contains(":error:")));
+        assert!(lookup_targets.iter().any(|t| t.starts_with("go:interface:Repo:")), "{:?}", lookup_targets);
+        assert!(!edges.iter().any(|e| e.from_key.as_str().contains(":Order:") && e.to_key.as_str().contains(":Order:")));
+    }
+}
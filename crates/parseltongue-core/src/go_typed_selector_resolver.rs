@@ -0,0 +1,614 @@
+//! Go Typed Selector Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: go_typed_selector_resolver
+//!
+//! The syntactic Go passes bind `x.Save()` by name, imports and receiver
+//! declarations; chained selectors, method values on returned types and
+//! shadowed identifiers stay guesses. `--typed` ingests additionally load
+//! the packages with `golang.org/x/tools/go/packages` and ask `go/types`
+//! which function or method object every call resolves to
+//! (`types.Info.Uses`, which already follows method sets through
+//! embedding and pointer receivers). Each answer rebinds the matching
+//! `Calls` edge:
+//!
+//! - In-repo target: `to_key` becomes the entity declared at that
+//!   file and line
+//! - External function: `to_key` becomes the import-qualified placeholder
+//!   (`go:fn:encoding/json.Marshal:unresolved-reference:0-0`) with
+//!   `import_path`, so the module version pass annotates it
+//! - Either way the edge gets `resolution: go_types` (confidence 0.95)
+//!   and keeps its previous key in `unresolved_target`
+//!
+//! Calls through interface methods are left to the dynamic dispatch
+//! fan-out, and `dispatch: dynamic` edges are never touched. When two
+//! calls with the same name on one line resolve to different objects the
+//! edges are left as they are.
+//!
+//! ## Helper
+//!
+//! The type checker runs in a small Go program embedded below. It is
+//! built once per helper source into the user cache directory (the same
+//! one as the parse cache):
+//!
+//! ```text
+//! ~/.cache/parseltongue-companion/go-typed-helper/gotyped-{source hash}
+//! ```
+//!
+//! - The directory is created `0700`; before anything in it is executed,
+//!   the directory and the binary must belong to the current user and be
+//!   writable by nobody else, otherwise `--typed` fails instead of running
+//!   someone else's program
+//! - `golang.org/x/tools` is pinned to `GO_TYPED_HELPER_TOOLS_VERSION`
+//!   (`go get` at that exact version, checksums verified against the Go
+//!   checksum database as usual), then built with `-mod=readonly`; the
+//!   first build needs it in the module cache or network access
+//! - Each build runs in its own directory and the binary is renamed into
+//!   place, so concurrent ingests never run a half-written helper
+//!
+//! The helper is run on the ingest root with `GOPROXY=off GOFLAGS=-mod=mod`,
+//! like `go list` in the module version pass. The ingested module's
+//! dependencies must therefore already be in the module cache; packages
+//! that fail to type-check still report the calls that did.
+
+use std::collections::HashMap;
+use std::path::{Component, Path, PathBuf};
+use std::process::Command;
+
+use serde::Deserialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, Isgl1Key};
+use crate::dynamic_dispatch_call_expander::DISPATCH_METADATA_KEY;
+use crate::go_embedding_promotion_resolver::{is_go_entity_file_path, UNRESOLVED_TARGET_METADATA_KEY};
+use crate::go_import_alias_resolver::IMPORT_PATH_METADATA_KEY;
+use crate::isgl1_v2::compute_content_hash;
+
+/// `resolution` of edges bound by the Go type checker
+pub const GO_TYPES_RESOLUTION_VALUE: &str = "go_types";
+
+/// Helper directory under the user cache directory
+const GO_TYPED_HELPER_DIR_NAME: &str = "go-typed-helper";
+
+/// `golang.org/x/tools` release the helper is built against
+pub const GO_TYPED_HELPER_TOOLS_VERSION: &str = "v0.24.0";
+
+const GO_TYPED_HELPER_GO_MOD: &str = "module parseltongue/gotyped\n\ngo 1.21\n";
+
+const GO_TYPED_HELPER_MAIN_GO: &str = r##"// Generated by parseltongue: prints the go/types target of every call as JSON lines.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type callTarget struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Name     string `json:"name"`
+	Pkg      string `json:"pkg,omitempty"`
+	Recv     string `json:"recv,omitempty"`
+	Iface    bool   `json:"iface,omitempty"`
+	External bool   `json:"external,omitempty"`
+	DeclFile string `json:"decl_file,omitempty"`
+	DeclLine int    `json:"decl_line,omitempty"`
+}
+
+func relativeTo(root, file string) (string, bool) {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	for {
+		switch f := fun.(type) {
+		case *ast.ParenExpr:
+			fun = f.X
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		case *ast.Ident:
+			return f
+		case *ast.SelectorExpr:
+			return f.Sel
+		default:
+			return nil
+		}
+	}
+}
+
+func main() {
+	root, err := filepath.Abs(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:   root,
+		Tests: true,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out := json.NewEncoder(os.Stdout)
+	seen := map[callTarget]bool{}
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				ident := calleeIdent(call.Fun)
+				if ident == nil {
+					return true
+				}
+				fn, ok := pkg.TypesInfo.Uses[ident].(*types.Func)
+				if !ok {
+					return true
+				}
+				fn = fn.Origin()
+				at := pkg.Fset.Position(call.Pos())
+				rel, inRoot := relativeTo(root, at.Filename)
+				if !inRoot {
+					return true
+				}
+				target := callTarget{File: rel, Line: at.Line, Name: fn.Name()}
+				if fn.Pkg() != nil {
+					target.Pkg = fn.Pkg().Path()
+				}
+				if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+					recv := sig.Recv().Type()
+					if ptr, ok := recv.(*types.Pointer); ok {
+						recv = ptr.Elem()
+					}
+					if named, ok := recv.(*types.Named); ok {
+						target.Recv = named.Obj().Name()
+					}
+					_, target.Iface = recv.Underlying().(*types.Interface)
+				}
+				decl := pkg.Fset.Position(fn.Pos())
+				if declRel, ok := relativeTo(root, decl.Filename); ok && fn.Pos().IsValid() {
+					target.DeclFile, target.DeclLine = declRel, decl.Line
+				} else {
+					target.External = true
+				}
+				if !seen[target] {
+					seen[target] = true
+					out.Encode(target)
+				}
+				return true
+			})
+		}
+	}
+}
+"##;
+
+/// Object the Go type checker resolved one call to
+///
+/// # 4-Word Name: GoTypedCallTarget
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+pub struct GoTypedCallTarget {
+    /// Calling file, relative to the ingest root (`/`-separated)
+    pub file: String,
+    /// 1-based line the call expression starts on
+    pub line: usize,
+    /// Function or method name (`Save`)
+    pub name: String,
+    /// Import path of the declaring package (`encoding/json`)
+    #[serde(default)]
+    pub pkg: String,
+    /// Receiver type name of methods (`Store`)
+    #[serde(default)]
+    pub recv: String,
+    /// Method of an interface type
+    #[serde(default)]
+    pub iface: bool,
+    /// Declared outside the ingest root (standard library, module cache)
+    #[serde(default)]
+    pub external: bool,
+    /// Declaring file, relative to the ingest root
+    #[serde(default)]
+    pub decl_file: String,
+    /// 1-based line of the declared name
+    #[serde(default)]
+    pub decl_line: usize,
+}
+
+/// Parse the helper's JSON lines into call targets
+///
+/// # 4-Word Name: parse_go_typed_call_lines
+///
+/// # Contract
+/// - Postcondition: one target per well-formed line; blank and malformed
+///   lines are skipped
+pub fn parse_go_typed_call_lines(output: &str) -> Vec<GoTypedCallTarget> {
+    output
+        .lines()
+        .filter(|line| !line.trim().is_empty())
+        .filter_map(|line| serde_json::from_str(line).ok())
+        .collect()
+}
+
+/// Type-check the Go packages under `root` and list every call's target
+///
+/// # 4-Word Name: load_go_typed_call_targets
+///
+/// # Contract
+/// - Precondition: a Go toolchain on `PATH`; `root` holds a Go module
+/// - Postcondition: targets of every package that loaded
+/// - Error: reason the helper could not be built or run
+pub fn load_go_typed_call_targets(root: &Path) -> Result<Vec<GoTypedCallTarget>, String> {
+    let helper = build_go_typed_helper_binary()?;
+    let root = std::fs::canonicalize(root).unwrap_or_else(|_| root.to_path_buf());
+    let output = Command::new(&helper)
+        .arg(&root)
+        .current_dir(&root)
+        .env("GOPROXY", "off")
+        .env("GOFLAGS", "-mod=mod")
+        .output()
+        .map_err(|e| format!("cannot run {}: {}", helper.display(), e))?;
+    if !output.status.success() {
+        return Err(format!("go/packages load failed: {}", String::from_utf8_lossy(&output.stderr).trim()));
+    }
+    Ok(parse_go_typed_call_lines(&String::from_utf8_lossy(&output.stdout)))
+}
+
+/// Helper binary for the embedded sources, building it unless cached
+///
+/// # 4-Word Name: build_go_typed_helper_binary
+///
+/// # Contract
+/// - Postcondition: Ok only for a binary that passed
+///   `verify_private_helper_path` (see module docs)
+/// - Error: no cache directory, an unsafe directory or binary, or the
+///   failing `go` step with its stderr
+fn build_go_typed_helper_binary() -> Result<PathBuf, String> {
+    let dir = go_typed_helper_cache_directory()
+        .ok_or("no cache directory for the Go helper (set $PARSELTONGUE_CACHE_DIR or $HOME)")?;
+    create_private_helper_directory(&dir)?;
+    let binary = dir.join(format!("gotyped-{}", go_typed_helper_source_hash()));
+    if binary.exists() {
+        verify_private_helper_path(&dir, &binary)?;
+        return Ok(binary);
+    }
+
+    let build_dir = dir.join(format!("build-{}", std::process::id()));
+    let built = build_helper_in_directory(&build_dir, &binary);
+    let _ = std::fs::remove_dir_all(&build_dir);
+    built?;
+    verify_private_helper_path(&dir, &binary)?;
+    Ok(binary)
+}
+
+/// Write the sources into `build_dir`, build, rename the result to `binary`
+fn build_helper_in_directory(build_dir: &Path, binary: &Path) -> Result<(), String> {
+    let _ = std::fs::remove_dir_all(build_dir);
+    std::fs::create_dir(build_dir).map_err(|e| format!("cannot create {}: {}", build_dir.display(), e))?;
+    std::fs::write(build_dir.join("go.mod"), GO_TYPED_HELPER_GO_MOD).map_err(|e| e.to_string())?;
+    std::fs::write(build_dir.join("main.go"), GO_TYPED_HELPER_MAIN_GO).map_err(|e| e.to_string())?;
+    let pinned = format!("golang.org/x/tools/go/packages@{}", GO_TYPED_HELPER_TOOLS_VERSION);
+    let temporary = build_dir.join("gotyped");
+    let temporary_arg = temporary.to_string_lossy().to_string();
+    let steps: [&[&str]; 2] = [
+        &["get", pinned.as_str()],
+        &["build", "-mod=readonly", "-trimpath", "-o", temporary_arg.as_str(), "."],
+    ];
+    for args in steps {
+        let output = Command::new("go")
+            .args(args)
+            .current_dir(build_dir)
+            // Ignore user-wide -mod=vendor etc. and never fetch another toolchain
+            .env("GOFLAGS", "")
+            .env("GOTOOLCHAIN", "local")
+            .output()
+            .map_err(|e| format!("cannot run go: {}", e))?;
+        if !output.status.success() {
+            return Err(format!("go {} failed: {}", args.join(" "), String::from_utf8_lossy(&output.stderr).trim()));
+        }
+    }
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::PermissionsExt;
+        std::fs::set_permissions(&temporary, std::fs::Permissions::from_mode(0o700)).map_err(|e| e.to_string())?;
+    }
+    std::fs::rename(&temporary, binary).map_err(|e| format!("cannot install {}: {}", binary.display(), e))
+}
+
+/// `$PARSELTONGUE_CACHE_DIR`, `$XDG_CACHE_HOME/...` or `~/.cache/...`, then `go-typed-helper`
+///
+/// Same lookup as the parse cache (`default_parse_cache_directory` in pt01).
+fn go_typed_helper_cache_directory() -> Option<PathBuf> {
+    let root = if let Some(dir) = std::env::var_os("PARSELTONGUE_CACHE_DIR").filter(|d| !d.is_empty()) {
+        PathBuf::from(dir)
+    } else if let Some(dir) = std::env::var_os("XDG_CACHE_HOME").filter(|d| !d.is_empty()) {
+        PathBuf::from(dir).join("parseltongue-companion")
+    } else {
+        let home = std::env::var_os("HOME").or_else(|| std::env::var_os("USERPROFILE"))?;
+        PathBuf::from(home).join(".cache").join("parseltongue-companion")
+    };
+    Some(root.join(GO_TYPED_HELPER_DIR_NAME))
+}
+
+/// First 16 hex digits of the SHA-256 of everything the build reads
+fn go_typed_helper_source_hash() -> String {
+    let hash = compute_content_hash(&format!(
+        "{}\n{}\n{}",
+        GO_TYPED_HELPER_TOOLS_VERSION, GO_TYPED_HELPER_GO_MOD, GO_TYPED_HELPER_MAIN_GO
+    ));
+    hash[..16].to_string()
+}
+
+/// Create `dir` (parents as needed) with mode `0700` on Unix
+fn create_private_helper_directory(dir: &Path) -> Result<(), String> {
+    let mut builder = std::fs::DirBuilder::new();
+    builder.recursive(true);
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::DirBuilderExt;
+        builder.mode(0o700);
+    }
+    builder.create(dir).map_err(|e| format!("cannot create {}: {}", dir.display(), e))
+}
+
+/// Refuse a helper directory or binary another user could have planted
+///
+/// # 4-Word Name: verify_private_helper_path
+///
+/// # Contract
+/// - Postcondition (Unix): Ok iff `dir` is a real directory and `binary`
+///   a regular file (no symlinks), both owned by the current user, `dir`
+///   not accessible to group/others and `binary` not writable by them
+/// - Other platforms: only the file-type checks
+fn verify_private_helper_path(dir: &Path, binary: &Path) -> Result<(), String> {
+    let dir_meta = std::fs::symlink_metadata(dir).map_err(|e| format!("cannot inspect {}: {}", dir.display(), e))?;
+    let binary_meta =
+        std::fs::symlink_metadata(binary).map_err(|e| format!("cannot inspect {}: {}", binary.display(), e))?;
+    if !dir_meta.is_dir() || !binary_meta.is_file() {
+        return Err(format!("refusing to run {}: not a regular file in a real directory", binary.display()));
+    }
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::MetadataExt;
+        let uid = current_user_id_in(dir)?;
+        if dir_meta.uid() != uid || dir_meta.mode() & 0o077 != 0 {
+            return Err(format!(
+                "refusing to run the Go helper: {} must be owned by you with mode 0700",
+                dir.display()
+            ));
+        }
+        if binary_meta.uid() != uid || binary_meta.mode() & 0o022 != 0 {
+            return Err(format!(
+                "refusing to run {}: it must be owned by you and writable only by you",
+                binary.display()
+            ));
+        }
+    }
+    Ok(())
+}
+
+/// Owner of a file this process creates in `dir`, i.e. the effective user
+#[cfg(unix)]
+fn current_user_id_in(dir: &Path) -> Result<u32, String> {
+    use std::os::unix::fs::MetadataExt;
+    let probe = dir.join(format!(".owner-probe-{}", std::process::id()));
+    std::fs::write(&probe, b"").map_err(|e| format!("cannot write in {}: {}", dir.display(), e))?;
+    let uid = std::fs::metadata(&probe).map(|m| m.uid());
+    let _ = std::fs::remove_file(&probe);
+    uid.map_err(|e| e.to_string())
+}
+
+/// Rebind Go `Calls` edges to the targets the type checker reported
+///
+/// # 4-Word Name: apply_go_typed_call_targets
+///
+/// # Contract
+/// - Precondition: `targets` from `load_go_typed_call_targets(root)`;
+///   entity file paths and edge locations are under `root`
+/// - Postcondition: see module docs; edges without an unambiguous,
+///   non-interface target are unchanged
+/// - Returns: number of edges bound by the type checker
+pub fn apply_go_typed_call_targets(
+    entities: &[CodeEntity],
+    edges: &mut [DependencyEdge],
+    root: &Path,
+    targets: &[GoTypedCallTarget],
+) -> usize {
+    // (file, line, name) → target; None when calls on that line disagree
+    let mut by_call_site: HashMap<(&str, usize, &str), Option<&GoTypedCallTarget>> = HashMap::new();
+    for target in targets.iter().filter(|t| !t.iface) {
+        by_call_site
+            .entry((target.file.as_str(), target.line, target.name.as_str()))
+            .and_modify(|known| {
+                if known.is_some_and(|k| k.decl_file != target.decl_file || k.decl_line != target.decl_line || k.pkg != target.pkg) {
+                    *known = None;
+                }
+            })
+            .or_insert(Some(target));
+    }
+
+    let go_entities: Vec<(String, &CodeEntity)> = entities
+        .iter()
+        .filter(|e| is_go_entity_file_path(&e.interface_signature.file_path))
+        .map(|e| (relative_slash_path(root, &e.interface_signature.file_path), e))
+        .collect();
+
+    let mut bound = 0;
+    for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::Calls) {
+        if edge.metadata_value(DISPATCH_METADATA_KEY) == Some("dynamic") {
+            continue;
+        }
+        let Some((file, line)) = edge
+            .source_location
+            .as_deref()
+            .and_then(|l| l.rsplit_once(':'))
+            .and_then(|(file, line)| Some((relative_slash_path(root, Path::new(file)), line.parse::<usize>().ok()?)))
+        else {
+            continue;
+        };
+        let Some(name) = edge.to_key.as_str().split(':').nth(2).and_then(|n| n.rsplit('.').next()) else {
+            continue;
+        };
+        let Some(Some(target)) = by_call_site.get(&(file.as_str(), line, name)) else {
+            continue;
+        };
+        let target_key = if target.external {
+            if !target.recv.is_empty() || target.pkg.is_empty() {
+                continue;
+            }
+            edge.metadata.insert(IMPORT_PATH_METADATA_KEY.to_string(), target.pkg.clone());
+            format!("go:fn:{}.{}:unresolved-reference:0-0", target.pkg, target.name)
+        } else {
+            match find_declared_entity_key(&go_entities, &target.decl_file, target.decl_line) {
+                Some(key) => key,
+                None => continue,
+            }
+        };
+        if edge.to_key.as_str() != target_key {
+            if !edge.metadata.contains_key(UNRESOLVED_TARGET_METADATA_KEY) {
+                edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+            }
+            edge.to_key = Isgl1Key::new_unchecked(target_key);
+        }
+        edge.metadata.insert("resolution".to_string(), GO_TYPES_RESOLUTION_VALUE.to_string());
+        bound += 1;
+    }
+    bound
+}
+
+/// Innermost entity of `file` whose line range holds `line`
+fn find_declared_entity_key(go_entities: &[(String, &CodeEntity)], file: &str, line: usize) -> Option<String> {
+    go_entities
+        .iter()
+        .filter(|(path, entity)| {
+            let range = &entity.interface_signature.line_range;
+            path == file && (range.start as usize..=range.end as usize).contains(&line)
+        })
+        .min_by_key(|(_, entity)| entity.interface_signature.line_range.end - entity.interface_signature.line_range.start)
+        .map(|(_, entity)| entity.isgl1_key.clone())
+}
+
+/// `path` relative to `root`, `/`-separated, without `.` components
+pub(crate) fn relative_slash_path(root: &Path, path: &Path) -> String {
+    let relative = path.strip_prefix(root).unwrap_or(path);
+    relative
+        .components()
+        .filter_map(|component| match component {
+            Component::Normal(part) => Some(part.to_string_lossy().into_owned()),
+            _ => None,
+        })
+        .collect::<Vec<_>>()
+        .join("/")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EntityType, LineRange};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call_at(from: &str, to: &str, location: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .source_location(location)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_typed_targets_rebind_call_edges() {
+        let output = concat!(
+            r#"{"file":"api/h.go","line":5,"name":"Save","pkg":"example.com/app/store","recv":"Store","decl_file":"store/db.go","decl_line":12}"#, "\n",
+            r#"{"file":"api/h.go","line":6,"name":"Marshal","pkg":"encoding/json","external":true}"#, "\n",
+            r#"{"file":"api/h.go","line":7,"name":"Close","pkg":"os","recv":"File","external":true}"#, "\n",
+            r#"{"file":"api/h.go","line":8,"name":"Save","pkg":"example.com/app/store","recv":"Store","decl_file":"store/db.go","decl_line":12}"#, "\n",
+            r#"{"file":"api/h.go","line":8,"name":"Save","pkg":"example.com/app/cache","recv":"Cache","decl_file":"cache/c.go","decl_line":3}"#, "\n",
+            r#"{"file":"api/h.go","line":9,"name":"Get","pkg":"example.com/app/store","recv":"Getter","iface":true,"decl_file":"store/db.go","decl_line":3}"#, "\n",
+            "not json\n",
+        );
+        let targets = parse_go_typed_call_lines(output);
+        assert_eq!(targets.len(), 6);
+
+        let mut save = create_go_test_entity("go:method:Save:__store:T2", "Save", EntityType::Method, "repo/store/db.go", &[]);
+        save.interface_signature.line_range = LineRange::new(12, 20).unwrap();
+        let entities = vec![
+            create_go_test_entity("go:fn:Handle:__api:T1", "Handle", EntityType::Function, "repo/api/h.go", &[]),
+            save,
+        ];
+        let from = "go:fn:Handle:__api:T1";
+        let mut edges = vec![
+            call_at(from, "go:fn:Save:unresolved-reference:0-0", "repo/api/h.go:5"),
+            call_at(from, "go:fn:json.Marshal:unresolved-reference:0-0", "repo/api/h.go:6"),
+            call_at(from, "go:fn:Close:unresolved-reference:0-0", "repo/api/h.go:7"),
+            call_at(from, "go:fn:Save:unresolved-reference:0-0", "repo/api/h.go:8"),
+            call_at(from, "go:fn:Get:unresolved-reference:0-0", "repo/api/h.go:9"),
+        ];
+
+        let bound = apply_go_typed_call_targets(&entities, &mut edges, Path::new("repo"), &targets);
+        assert_eq!(bound, 2);
+        assert_eq!(edges[0].to_key.as_str(), "go:method:Save:__store:T2");
+        assert_eq!(edges[0].metadata_value("resolution"), Some(GO_TYPES_RESOLUTION_VALUE));
+        assert_eq!(
+            edges[0].metadata_value(UNRESOLVED_TARGET_METADATA_KEY),
+            Some("go:fn:Save:unresolved-reference:0-0")
+        );
+        assert_eq!(edges[1].to_key.as_str(), "go:fn:encoding/json.Marshal:unresolved-reference:0-0");
+        assert_eq!(edges[1].metadata_value(IMPORT_PATH_METADATA_KEY), Some("encoding/json"));
+        // External method, ambiguous line, interface method: unchanged
+        for edge in &edges[2..] {
+            assert!(edge.to_key.as_str().ends_with(":unresolved-reference:0-0"));
+            assert_eq!(edge.metadata_value("resolution"), None);
+        }
+    }
+
+    #[test]
+    fn test_helper_key_covers_sources_and_pin() {
+        let hash = go_typed_helper_source_hash();
+        assert_eq!(hash.len(), 16);
+        assert_eq!(hash, go_typed_helper_source_hash());
+        assert!(!GO_TYPED_HELPER_GO_MOD.contains("require"), "the version is pinned by `go get`, not go.mod");
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn test_private_helper_path_rejects_shared_files() {
+        use std::os::unix::fs::PermissionsExt;
+        let tmp = tempfile::tempdir().unwrap();
+        let dir = tmp.path().join("go-typed-helper");
+        create_private_helper_directory(&dir).unwrap();
+        assert_eq!(std::fs::metadata(&dir).unwrap().permissions().mode() & 0o777, 0o700);
+        let binary = dir.join("gotyped-0123456789abcdef");
+        std::fs::write(&binary, b"#!/bin/sh\n").unwrap();
+        std::fs::set_permissions(&binary, std::fs::Permissions::from_mode(0o755)).unwrap();
+        assert!(verify_private_helper_path(&dir, &binary).is_ok());
+
+        std::fs::set_permissions(&binary, std::fs::Permissions::from_mode(0o777)).unwrap();
+        assert!(verify_private_helper_path(&dir, &binary).is_err());
+        std::fs::set_permissions(&binary, std::fs::Permissions::from_mode(0o755)).unwrap();
+        std::fs::set_permissions(&dir, std::fs::Permissions::from_mode(0o755)).unwrap();
+        assert!(verify_private_helper_path(&dir, &binary).is_err());
+        std::fs::set_permissions(&dir, std::fs::Permissions::from_mode(0o700)).unwrap();
+
+        let link = dir.join("gotyped-link");
+        std::os::unix::fs::symlink(&binary, &link).unwrap();
+        assert!(verify_private_helper_path(&dir, &link).is_err());
+    }
+}
@@ -0,0 +1,426 @@
+//! Natural-language graph delta (v1.7.3)
+//!
+//! # 4-Word Naming: graph_delta_narrative_reporter
+//!
+//! `parseltongue report delta --since <rev>` turns the structural diff of
+//! two revisions (`graph_snapshot_diff_reporter`) into short, changelog-style
+//! bullets for commit messages and standups:
+//!
+//! ```text
+//! - Added function Audit (api/audit.go)
+//! - Charge now has signature func Charge(ctx context.Context) error (was func Charge() error)
+//! - User gained field Email
+//! - CreateUser now calls AuditLogger.Record and Mailer.Send
+//! - handle no longer calls Refund
+//! - Changed the bodies of Migrate and Seed
+//! ```
+//!
+//! The text is built from node and edge diffs only, no LLM, so the same
+//! pair of revisions always yields the same bullets, in a fixed order:
+//! added, removed, signature, visibility, fields, new edges, dropped edges,
+//! body-only changes.
+//!
+//! ## Names
+//!
+//! Go methods read `Receiver.Method` (`receiver_type`); external targets
+//! keep their package (`exec.Command`), fields their type (`User.Email`).
+//!
+//! ## Scope
+//!
+//! - Edges of added or removed symbols are not repeated: the symbol's own
+//!   bullet covers them
+//! - Field changes are read from struct bodies for Go and Rust; other
+//!   languages' field edits show up as body changes
+//! - Body-only changes are reported for symbols no other bullet mentions
+
+use std::collections::{BTreeMap, BTreeSet, HashMap, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EntityType};
+use crate::graph_snapshot_diff_reporter::diff_graph_snapshot_pair;
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+use crate::symbol_doc_signature_extractor::{extract_symbol_signature_line, SIGNATURE_METADATA_KEY};
+
+/// Body-changed symbols named in full before "and N more"
+pub const BODY_CHANGE_NAME_LIMIT: usize = 8;
+
+/// One bullet of the delta
+///
+/// # 4-Word Name: GraphDeltaBulletEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GraphDeltaBulletEntry {
+    /// `added`, `removed`, `signature`, `visibility`, `fields`,
+    /// `edges_added`, `edges_removed` or `body`
+    pub kind: &'static str,
+    /// Key of the symbol the bullet is about (empty for the body summary)
+    pub subject: String,
+    pub text: String,
+}
+
+/// Describe the delta between two graph snapshots in bullets
+///
+/// # 4-Word Name: narrate_graph_snapshot_delta
+///
+/// # Contract
+/// - Postcondition: deterministic; bullets grouped by kind in the order of
+///   the module doc, sorted by subject within a kind; one `edges_added` /
+///   `edges_removed` bullet per (symbol, edge type)
+/// - Postcondition: empty when the snapshots differ in nothing but
+///   placeholders
+pub fn narrate_graph_snapshot_delta(
+    old_entities: &[CodeEntity],
+    old_edges: &[DependencyEdge],
+    new_entities: &[CodeEntity],
+    new_edges: &[DependencyEdge],
+) -> Vec<GraphDeltaBulletEntry> {
+    let report = diff_graph_snapshot_pair(old_entities, old_edges, new_entities, new_edges);
+    let old_by_key: HashMap<&str, &CodeEntity> = old_entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let new_by_key: HashMap<&str, &CodeEntity> = new_entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let display = |key: &str| delta_display_name(key, new_by_key.get(key).or_else(|| old_by_key.get(key)).copied());
+    let bullet = |kind: &'static str, subject: &str, text: String| GraphDeltaBulletEntry {
+        kind,
+        subject: subject.to_string(),
+        text,
+    };
+
+    let mut bullets = Vec::new();
+    for node in &report.nodes.added {
+        let text = format!("Added {} {} ({})", kind_word(&node.kind), display(&node.key), node.file_path);
+        bullets.push(bullet("added", &node.key, text));
+    }
+    for node in &report.nodes.removed {
+        let text = format!("Removed {} {} ({})", kind_word(&node.kind), display(&node.key), node.file_path);
+        bullets.push(bullet("removed", &node.key, text));
+    }
+
+    let mut mentioned: HashSet<&str> = HashSet::new();
+    let mut visibility = Vec::new();
+    let mut fields = Vec::new();
+    for node in &report.nodes.changed {
+        let (Some(old), Some(new)) = (old_by_key.get(node.key.as_str()), new_by_key.get(node.key.as_str())) else {
+            continue;
+        };
+        let name = display(&node.key);
+        if node.changes.contains(&"signature") {
+            mentioned.insert(&node.key);
+            bullets.push(bullet(
+                "signature",
+                &node.key,
+                format!("{} now has signature {} (was {})", name, delta_signature_line(new), delta_signature_line(old)),
+            ));
+        }
+        if node.changes.contains(&"visibility") {
+            mentioned.insert(&node.key);
+            let text = if node.exported {
+                format!("{} is now exported", name)
+            } else {
+                format!("{} is no longer exported", name)
+            };
+            visibility.push(bullet("visibility", &node.key, text));
+        }
+        let is_record_type = matches!(new.interface_signature.entity_type, EntityType::Struct | EntityType::Class);
+        if is_record_type && node.changes.contains(&"body") {
+            let language = node.key.split(':').next().unwrap_or_default();
+            let before = extract_declared_field_names(language, old.current_code.as_deref().unwrap_or_default());
+            let after = extract_declared_field_names(language, new.current_code.as_deref().unwrap_or_default());
+            let gained: Vec<&str> = after.difference(&before).map(String::as_str).collect();
+            let lost: Vec<&str> = before.difference(&after).map(String::as_str).collect();
+            let mut parts = Vec::new();
+            if !gained.is_empty() {
+                parts.push(format!("gained {} {}", plural_word("field", gained.len()), join_names_naturally(&gained)));
+            }
+            if !lost.is_empty() {
+                parts.push(format!("lost {} {}", plural_word("field", lost.len()), join_names_naturally(&lost)));
+            }
+            if !parts.is_empty() {
+                mentioned.insert(&node.key);
+                fields.push(bullet("fields", &node.key, format!("{} {}", name, parts.join("; "))));
+            }
+        }
+    }
+    bullets.extend(visibility);
+    bullets.extend(fields);
+
+    // Edge changes of symbols present in both snapshots, per (symbol, type)
+    let endpoints: HashSet<&str> = report
+        .nodes
+        .added
+        .iter()
+        .chain(&report.nodes.removed)
+        .map(|node| node.key.as_str())
+        .collect();
+    for (kind, prefix, edges) in [
+        ("edges_added", "now", &report.edges.added),
+        ("edges_removed", "no longer", &report.edges.removed),
+    ] {
+        let mut grouped: BTreeMap<(&str, &str), BTreeSet<String>> = BTreeMap::new();
+        for edge in edges.iter().filter(|edge| !endpoints.contains(edge.from_key.as_str())) {
+            grouped
+                .entry((edge.from_key.as_str(), edge.edge_type.as_str()))
+                .or_default()
+                .insert(display(&edge.to_key));
+        }
+        for ((from, edge_type), targets) in grouped {
+            mentioned.insert(from);
+            let targets: Vec<&str> = targets.iter().map(String::as_str).collect();
+            let text = format!("{} {} {} {}", display(from), prefix, edge_type_verb(edge_type), join_names_naturally(&targets));
+            bullets.push(bullet(kind, from, text));
+        }
+    }
+
+    let body_only: Vec<String> = report
+        .nodes
+        .changed
+        .iter()
+        .filter(|node| !mentioned.contains(node.key.as_str()))
+        .map(|node| display(&node.key))
+        .collect();
+    if !body_only.is_empty() {
+        let mut names: Vec<String> = body_only.iter().take(BODY_CHANGE_NAME_LIMIT).cloned().collect();
+        if body_only.len() > BODY_CHANGE_NAME_LIMIT {
+            names.push(format!("{} more", body_only.len() - BODY_CHANGE_NAME_LIMIT));
+        }
+        let names: Vec<&str> = names.iter().map(String::as_str).collect();
+        let text = format!("Changed the {} of {}", plural_word("body", body_only.len()), join_names_naturally(&names));
+        bullets.push(bullet("body", "", text));
+    }
+    bullets
+}
+
+/// Render bullets as a markdown list, one `- ` line each
+///
+/// # 4-Word Name: render_graph_delta_bullets
+///
+/// # Contract
+/// - Postcondition: `No structural changes.` for an empty delta
+pub fn render_graph_delta_bullets(bullets: &[GraphDeltaBulletEntry]) -> String {
+    if bullets.is_empty() {
+        return "No structural changes.\n".to_string();
+    }
+    bullets.iter().map(|b| format!("- {}\n", b.text)).collect()
+}
+
+/// Field names declared in a struct body
+///
+/// # 4-Word Name: extract_declared_field_names
+///
+/// # Contract
+/// - Precondition: `language` is the key's first segment
+/// - Postcondition: Go named fields (`A, B int` gives both; embedded types
+///   are skipped) and Rust named fields; empty for other languages and for
+///   tuple structs
+pub fn extract_declared_field_names(language: &str, code: &str) -> BTreeSet<String> {
+    // Text directly inside the outermost braces, nested blocks removed
+    let mut depth = 0usize;
+    let mut body = String::new();
+    for line in code.lines() {
+        let line = line.split("//").next().unwrap_or_default();
+        for c in line.chars() {
+            match c {
+                '{' => depth += 1,
+                '}' => depth = depth.saturating_sub(1),
+                _ if depth == 1 => body.push(c),
+                _ => {}
+            }
+        }
+        if depth == 1 {
+            body.push('\n');
+        }
+    }
+
+    let is_identifier = |word: &str| {
+        word.chars().next().is_some_and(|c| c.is_alphabetic() || c == '_')
+            && word.chars().all(|c| c.is_alphanumeric() || c == '_')
+    };
+    let mut names = BTreeSet::new();
+    match language {
+        "go" => {
+            for declaration in body.split(|c| c == '\n' || c == ';') {
+                let words: Vec<&str> = declaration.split_whitespace().collect();
+                // `Name Type`, `A, B Type`; a lone word is an embedded type
+                if words.len() < 2 {
+                    continue;
+                }
+                for word in &words {
+                    let name = word.trim_end_matches(',');
+                    if !is_identifier(name) {
+                        break;
+                    }
+                    names.insert(name.to_string());
+                    if !word.ends_with(',') {
+                        break;
+                    }
+                }
+            }
+        }
+        "rust" => {
+            let mut nesting = 0usize;
+            let mut part = String::new();
+            let mut parts = Vec::new();
+            for c in body.chars() {
+                match c {
+                    '<' | '(' | '[' => nesting += 1,
+                    '>' | ')' | ']' => nesting = nesting.saturating_sub(1),
+                    ',' if nesting == 0 => {
+                        parts.push(std::mem::take(&mut part));
+                        continue;
+                    }
+                    _ => {}
+                }
+                part.push(c);
+            }
+            parts.push(part);
+            for part in parts {
+                let Some((before, _)) = part.split_once(':') else {
+                    continue;
+                };
+                // Attributes and `pub(crate)` come before the name
+                let name = before.split(|c: char| c.is_whitespace() || c == ')' || c == ']').last().unwrap_or_default();
+                if is_identifier(name) && name != "pub" {
+                    names.insert(name.to_string());
+                }
+            }
+        }
+        _ => {}
+    }
+    names
+}
+
+/// Display name of a key: `Receiver.Method`, `exec.Command`, `User.Email`
+fn delta_display_name(key: &str, entity: Option<&CodeEntity>) -> String {
+    if let Some(entity) = entity {
+        let name = &entity.interface_signature.name;
+        return match entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) {
+            Some(receiver) => format!("{}.{}", receiver.trim_start_matches('*'), name),
+            None => name.clone(),
+        };
+    }
+    let name = key.split(':').nth(2).unwrap_or(key);
+    name.rsplit('/').next().unwrap_or(name).to_string()
+}
+
+fn delta_signature_line(entity: &CodeEntity) -> String {
+    match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+        Some(signature) => signature.clone(),
+        None => extract_symbol_signature_line(entity.current_code.as_deref().unwrap_or_default()),
+    }
+}
+
+fn kind_word(kind: &str) -> &str {
+    match kind {
+        "fn" => "function",
+        "const" => "constant",
+        "var" => "variable",
+        other => other,
+    }
+}
+
+fn edge_type_verb(edge_type: &str) -> &str {
+    match edge_type {
+        "Calls" => "calls",
+        "Uses" => "uses",
+        "Implements" => "implements",
+        "Embeds" => "embeds",
+        "Spawns" => "spawns",
+        "SendsTo" => "sends to",
+        "ReceivesFrom" => "receives from",
+        "Reads" => "reads",
+        "Writes" => "writes",
+        "Tests" => "tests",
+        "Bound" => "is bounded by",
+        "PropagatesErrorTo" => "propagates errors to",
+        "QueriesTable" => "queries",
+        "Serializes" => "serializes",
+        "GeneratedFrom" => "is generated from",
+        "Includes" => "includes",
+        "ConfiguredBy" => "is configured by",
+        other => other,
+    }
+}
+
+fn plural_word(word: &str, count: usize) -> String {
+    match (word, count) {
+        (_, 1) => word.to_string(),
+        ("body", _) => "bodies".to_string(),
+        _ => format!("{}s", word),
+    }
+}
+
+/// `a`, `a and b`, `a, b and c`
+fn join_names_naturally(names: &[&str]) -> String {
+    match names {
+        [] => String::new(),
+        [only] => only.to_string(),
+        [rest @ .., last] => format!("{} and {}", rest.join(", "), last),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn node(key: &str, name: &str, entity_type: EntityType, code: &str, metadata: &[(&str, &str)]) -> CodeEntity {
+        let file = format!("svc/{}.go", name.to_lowercase());
+        let mut entity = create_go_test_entity(key, name, entity_type, &file, metadata);
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type).build().unwrap()
+    }
+
+    #[test]
+    fn test_delta_bullets_read_like_changelog() {
+        let create = "go:fn:CreateUser:__svc:T1";
+        let record = "go:method:Record:__svc:T2";
+        let user = "go:struct:User:__svc:T3";
+        let migrate = "go:fn:Migrate:__svc:T4";
+        let audit = "go:fn:Audit:__svc:T5";
+        let old_entities = vec![
+            node(create, "CreateUser", EntityType::Function, "func CreateUser(u User) error {\n\treturn save(u)\n}", &[]),
+            node(record, "Record", EntityType::Method, "func (a *AuditLogger) Record() {}", &[("receiver_type", "AuditLogger")]),
+            node(user, "User", EntityType::Struct, "type User struct {\n\tName string\n\tAge int\n}", &[]),
+            node(migrate, "Migrate", EntityType::Function, "func Migrate() {\n}", &[]),
+        ];
+        let mut new_entities = old_entities.clone();
+        new_entities[0].current_code = Some("func CreateUser(u User) error {\n\taudit.Record()\n\treturn save(u)\n}".to_string());
+        new_entities[2].current_code = Some("type User struct {\n\tName, Email string `json:\"email\"`\n\tsync.Mutex\n}".to_string());
+        new_entities[3].current_code = Some("func Migrate() {\n\tstep()\n}".to_string());
+        new_entities.push(node(audit, "Audit", EntityType::Function, "func Audit() {}", &[]));
+
+        let old_edges = vec![edge(create, "go:fn:save:unresolved-reference:0-0", EdgeType::Calls)];
+        let new_edges = vec![
+            edge(create, "go:fn:save:unresolved-reference:0-0", EdgeType::Calls),
+            edge(create, record, EdgeType::Calls),
+            edge(create, "go:fn:os/exec.Command:unresolved-reference:0-0", EdgeType::Calls),
+            edge(audit, create, EdgeType::Calls),
+        ];
+
+        let bullets = narrate_graph_snapshot_delta(&old_entities, &old_edges, &new_entities, &new_edges);
+        let text = render_graph_delta_bullets(&bullets);
+        assert_eq!(
+            text,
+            "- Added function Audit (svc/audit.go)\n\
+             - User gained field Email; lost field Age\n\
+             - CreateUser now calls AuditLogger.Record and exec.Command\n\
+             - Changed the body of Migrate\n"
+        );
+        assert_eq!(render_graph_delta_bullets(&narrate_graph_snapshot_delta(&old_entities, &old_edges, &old_entities, &old_edges)), "No structural changes.\n");
+    }
+
+    #[test]
+    fn test_field_names_for_go_and_rust() {
+        let names = |language: &str, code: &str| extract_declared_field_names(language, code).into_iter().collect::<Vec<_>>();
+        assert_eq!(names("go", "type T struct {\n\tA, B int // pair\n\tInner struct {\n\t\tX int\n\t}\n\t*Base\n}"), vec!["A", "B", "Inner"]);
+        assert_eq!(
+            names("rust", "pub struct T {\n    #[serde(rename = \"id\")]\n    pub(crate) id: u64,\n    map: HashMap<String, u32>,\n}"),
+            vec!["id", "map"]
+        );
+        assert!(names("rust", "struct P(i32, i32);").is_empty());
+        assert!(names("java", "class T { int x; }").is_empty());
+    }
+}
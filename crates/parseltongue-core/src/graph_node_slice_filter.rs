@@ -0,0 +1,226 @@
+//! Graph Node Slice Filter (v1.7.3)
+//!
+//! # 4-Word Naming: graph_node_slice_filter
+//!
+//! The `--match <regex>`, `--kind` and `--visibility` flags shared by the
+//! export and query commands. A filter selects nodes; a graph sliced with
+//! it keeps the selected nodes and the edges between them, so huge graphs
+//! can be cut down before they are serialized instead of after.
+//!
+//! ## Node tests (all given ones must hold)
+//!
+//! - `--match`: the regex is searched in the symbol name and in its ISGL1
+//!   key (`^Handle` on names, `internal_api` on keys)
+//! - `--kind`: `function` (fn, method), `type` (struct, enum, trait,
+//!   interface, class, typedef), `const`, or any key kind segment (`var`,
+//!   `module`, `table`, ...); repeatable
+//! - `--visibility`: `public` or `private`, as `analyze dead-code` decides
+//!   it (Go capitalization, Python `_`, otherwise the extracted visibility).
+//!   Nodes known only from an edge (external and unresolved targets) are
+//!   another package's API and count as public
+//!
+//! `--min-confidence` rides along as an edge test: sliced graphs also drop
+//! edges scored below it by `edge_confidence_provenance_scorer`.
+
+use std::collections::{HashMap, HashSet};
+
+use regex::Regex;
+
+use crate::dead_code_detection_analyzer::is_entity_symbol_exported;
+use crate::edge_confidence_provenance_scorer::assess_edge_resolution_confidence;
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::error::{ParseltongError, Result};
+
+/// Kind classes accepted by `--kind`, with the key kind segments they cover
+pub const NODE_KIND_CLASS_SEGMENTS: &[(&str, &[&str])] = &[
+    ("function", &["fn", "method"]),
+    ("type", &["struct", "enum", "trait", "interface", "class", "typedef"]),
+    ("const", &["const"]),
+];
+
+/// Which side of the export boundary `--visibility` keeps
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum NodeVisibilityFilterKind {
+    Public,
+    Private,
+}
+
+/// Node predicate built from `--match` / `--kind` / `--visibility`
+///
+/// # 4-Word Name: GraphNodeSliceFilter
+#[derive(Debug, Clone, Default)]
+pub struct GraphNodeSliceFilter {
+    name_pattern: Option<Regex>,
+    /// Key kind segments; empty = every kind
+    kind_segments: Vec<String>,
+    visibility: Option<NodeVisibilityFilterKind>,
+    /// Edges scored below this are dropped; None = every edge
+    min_edge_confidence: Option<f64>,
+}
+
+impl GraphNodeSliceFilter {
+    /// Build a filter from flag values
+    ///
+    /// # Contract
+    /// - Precondition: `kinds` may hold comma-separated lists
+    /// - Postcondition: invalid regexes and visibilities are validation
+    ///   errors naming the flag; all-None input is the unrestricted filter
+    pub fn parse_node_slice_filter(
+        pattern: Option<&str>,
+        kinds: &[&str],
+        visibility: Option<&str>,
+    ) -> Result<Self> {
+        let name_pattern = pattern
+            .map(|pattern| {
+                Regex::new(pattern).map_err(|e| ParseltongError::ValidationError {
+                    field: "match".to_string(),
+                    expected: "a regular expression".to_string(),
+                    actual: e.to_string(),
+                })
+            })
+            .transpose()?;
+
+        let mut kind_segments = Vec::new();
+        for kind in kinds.iter().flat_map(|spec| spec.split(',')).map(|k| k.trim().to_lowercase()) {
+            if kind.is_empty() {
+                continue;
+            }
+            match NODE_KIND_CLASS_SEGMENTS.iter().find(|(class, _)| *class == kind.as_str()) {
+                Some((_, segments)) => kind_segments.extend(segments.iter().map(|s| s.to_string())),
+                None => kind_segments.push(kind),
+            }
+        }
+
+        let visibility = match visibility.map(|v| v.trim().to_lowercase()) {
+            None => None,
+            Some(v) if v.as_str() == "public" => Some(NodeVisibilityFilterKind::Public),
+            Some(v) if v.as_str() == "private" => Some(NodeVisibilityFilterKind::Private),
+            Some(other) => {
+                return Err(ParseltongError::ValidationError {
+                    field: "visibility".to_string(),
+                    expected: "public, private".to_string(),
+                    actual: other,
+                })
+            }
+        };
+
+        Ok(Self { name_pattern, kind_segments, visibility, min_edge_confidence: None })
+    }
+
+    /// Also drop edges scored below `min_confidence` (`--min-confidence`)
+    pub fn with_min_edge_confidence(mut self, min_confidence: Option<f64>) -> Self {
+        self.min_edge_confidence = min_confidence.filter(|&min| min > 0.0);
+        self
+    }
+
+    /// True when no flag was given (every node and edge passes)
+    pub fn is_unrestricted(&self) -> bool {
+        self.name_pattern.is_none()
+            && self.kind_segments.is_empty()
+            && self.visibility.is_none()
+            && self.min_edge_confidence.is_none()
+    }
+
+    /// Test one node; `entity` is None for nodes known only from an edge
+    ///
+    /// # 4-Word Name: matches_graph_node_entry
+    pub fn matches_graph_node_entry(&self, key: &str, entity: Option<&CodeEntity>) -> bool {
+        let mut segments = key.splitn(4, ':');
+        let _language = segments.next();
+        let kind = segments.next().unwrap_or("");
+        let key_name = segments.next().unwrap_or(key);
+
+        if let Some(pattern) = &self.name_pattern {
+            let name = entity.map(|e| e.interface_signature.name.as_str()).unwrap_or(key_name);
+            if !pattern.is_match(name) && !pattern.is_match(key) {
+                return false;
+            }
+        }
+        if !self.kind_segments.is_empty() && !self.kind_segments.iter().any(|k| k.as_str() == kind) {
+            return false;
+        }
+        match (self.visibility, entity) {
+            (Some(NodeVisibilityFilterKind::Public), Some(entity)) => is_entity_symbol_exported(entity),
+            (Some(NodeVisibilityFilterKind::Private), Some(entity)) => !is_entity_symbol_exported(entity),
+            (Some(NodeVisibilityFilterKind::Private), None) => false,
+            _ => true,
+        }
+    }
+
+    /// Keep matching entities and the confident edges whose endpoints both match
+    ///
+    /// # 4-Word Name: retain_matching_graph_slice
+    ///
+    /// # Contract
+    /// - Postcondition: keys in `always_keep` (e.g. a diagram root) pass
+    ///   regardless of the filter; an unrestricted filter changes nothing
+    pub fn retain_matching_graph_slice(
+        &self,
+        entities: &mut Vec<CodeEntity>,
+        edges: &mut Vec<DependencyEdge>,
+        always_keep: &[&str],
+    ) {
+        if self.is_unrestricted() {
+            return;
+        }
+        let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+        let mut verdicts: HashMap<String, bool> = HashMap::new();
+        let mut passes = |key: &str| -> bool {
+            if let Some(&verdict) = verdicts.get(key) {
+                return verdict;
+            }
+            let verdict = always_keep.contains(&key) || self.matches_graph_node_entry(key, by_key.get(key).copied());
+            verdicts.insert(key.to_string(), verdict);
+            verdict
+        };
+        let kept_entities: HashSet<String> =
+            entities.iter().filter(|e| passes(&e.isgl1_key)).map(|e| e.isgl1_key.clone()).collect();
+        let confident = |edge: &DependencyEdge| {
+            self.min_edge_confidence.map_or(true, |min| assess_edge_resolution_confidence(edge).score >= min)
+        };
+        edges.retain(|edge| confident(edge) && passes(edge.from_key.as_str()) && passes(edge.to_key.as_str()));
+        entities.retain(|e| kept_entities.contains(&e.isgl1_key));
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_slice_keeps_matching_nodes_and_edges() {
+        let mut entities = vec![
+            create_go_test_entity("go:fn:HandleLogin:__api:T1", "HandleLogin", EntityType::Function, "api/login.go", &[]),
+            create_go_test_entity("go:fn:checkToken:__api:T2", "checkToken", EntityType::Function, "api/login.go", &[]),
+            create_go_test_entity("go:struct:Session:__api:T3", "Session", EntityType::Struct, "api/session.go", &[]),
+        ];
+        let mut edges = vec![
+            edge("go:fn:HandleLogin:__api:T1", "go:fn:checkToken:__api:T2"),
+            edge("go:fn:HandleLogin:__api:T1", "go:struct:Session:__api:T3"),
+            edge("go:fn:HandleLogin:__api:T1", "go:fn:Println:unresolved-reference:0-0"),
+        ];
+
+        let filter = GraphNodeSliceFilter::parse_node_slice_filter(None, &["function"], Some("public")).unwrap();
+        assert!(filter.matches_graph_node_entry("go:fn:Println:unresolved-reference:0-0", None));
+        let (mut e, mut d) = (entities.clone(), edges.clone());
+        filter.retain_matching_graph_slice(&mut e, &mut d, &[]);
+        assert_eq!(e.len(), 1);
+        assert_eq!(d.len(), 1, "only the edge to the external Println survives: {:?}", d);
+
+        let filter = GraphNodeSliceFilter::parse_node_slice_filter(Some("^(Handle|Sess)"), &["fn,type"], None).unwrap();
+        filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+        assert_eq!(entities.len(), 2);
+        assert_eq!(edges[0].to_key.as_str(), "go:struct:Session:__api:T3");
+        assert_eq!(edges.len(), 1);
+
+        assert!(GraphNodeSliceFilter::parse_node_slice_filter(Some("("), &[], None).is_err());
+        assert!(GraphNodeSliceFilter::parse_node_slice_filter(None, &[], Some("internal")).is_err());
+        assert!(GraphNodeSliceFilter::parse_node_slice_filter(None, &[], None).unwrap().is_unrestricted());
+    }
+}
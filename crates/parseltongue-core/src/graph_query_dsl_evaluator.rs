@@ -0,0 +1,614 @@
+//! Graph query DSL (v1.7.3)
+//!
+//! # 4-Word Naming: graph_query_dsl_evaluator
+//!
+//! A small set-algebra language over the dependency graph, shared by
+//! `parseltongue q` and the daemon's `/graph-query-dsl-evaluate` endpoint:
+//!
+//! ```text
+//! callers(UserService.CreateUser) & in_package("api") depth 2
+//! dependents(store.Save) - in_file("*_test.go") limit 50
+//! (callees(main) | callees(init)) & kind(fn, method) & !lang(rust)
+//! ```
+//!
+//! ## Grammar
+//!
+//! ```text
+//! query   := union [depth N] [limit N]
+//! union   := inter ("|" inter)*
+//! inter   := unary (("&" | "-") unary)*
+//! unary   := "!" unary | "(" union ")" | call
+//! call    := name "(" [arg ("," arg)*] ")"
+//! arg     := "quoted string" | bare-word       (UserService.CreateUser, go:fn:Save:__svc:T1)
+//! ```
+//!
+//! `&` intersects, `|` unites, `-` (surrounded by spaces) subtracts, `!`
+//! takes the complement within every known node.
+//!
+//! ## Functions
+//!
+//! Every function returns a set of node keys; several arguments unite.
+//!
+//! - Traversals, `depth` hops (default 1): `callers`, `callees` (Calls
+//!   edges), `dependents`, `dependencies` (every edge type), `implementers`
+//!   (Implements edges, reversed)
+//! - `symbol(s)`: the symbol itself
+//! - Filters: `in_package(dir)` (package directory or its last component),
+//!   `in_file(glob)`, `kind(fn)`, `lang(go)`, `name(glob)`, `all()`
+//!
+//! A symbol is an ISGL1 key, a name, or `Qualifier.name` where the qualifier
+//! is the receiver type, package directory, file or key path segment. A
+//! symbol matching several entities stands for all of them.
+
+use std::collections::{BTreeSet, HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::error::{ParseltongError, Result};
+use crate::filtered_graph_traversal_queries::{all_edge_type_variants_list, compute_filtered_blast_radius};
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::serializers::graph_export_node_table::matches_path_glob_pattern;
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Hops of `callers`/`callees`/... when the query has no `depth` clause
+pub const DEFAULT_QUERY_TRAVERSAL_DEPTH: usize = 1;
+
+/// Parsed query expression
+///
+/// # 4-Word Name: GraphQueryExpressionNode
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum GraphQueryExpressionNode {
+    Call { function: String, args: Vec<String> },
+    Intersect(Box<GraphQueryExpressionNode>, Box<GraphQueryExpressionNode>),
+    Union(Box<GraphQueryExpressionNode>, Box<GraphQueryExpressionNode>),
+    Difference(Box<GraphQueryExpressionNode>, Box<GraphQueryExpressionNode>),
+    Complement(Box<GraphQueryExpressionNode>),
+}
+
+/// Expression plus trailing `depth` / `limit` clauses
+///
+/// # 4-Word Name: ParsedGraphQueryProgram
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ParsedGraphQueryProgram {
+    pub expression: GraphQueryExpressionNode,
+    pub depth: usize,
+    pub limit: Option<usize>,
+}
+
+/// One node in a query result
+///
+/// # 4-Word Name: GraphQueryMatchEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GraphQueryMatchEntry {
+    pub key: String,
+    pub name: String,
+    pub kind: String,
+    /// None for external / unresolved nodes
+    pub file_path: Option<String>,
+    pub line_start: Option<u32>,
+}
+
+/// Result of evaluating a query
+///
+/// # 4-Word Name: GraphQueryResultPayload
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GraphQueryResultPayload {
+    pub query: String,
+    pub depth: usize,
+    /// Matches before `limit`
+    pub total_count: usize,
+    pub truncated: bool,
+    /// Sorted by key
+    pub matches: Vec<GraphQueryMatchEntry>,
+}
+
+/// Parse query text
+///
+/// # 4-Word Name: parse_graph_query_text
+///
+/// # Contract
+/// - Error: `ParseError` naming the offending token and its column
+pub fn parse_graph_query_text(text: &str) -> Result<ParsedGraphQueryProgram> {
+    let tokens = tokenize_graph_query_text(text)?;
+    let mut parser = QueryTokenParser { tokens: &tokens, position: 0 };
+    let expression = parser.parse_union()?;
+    let mut depth = DEFAULT_QUERY_TRAVERSAL_DEPTH;
+    let mut limit = None;
+    while let Some(token) = parser.peek() {
+        match &token.kind {
+            QueryTokenKind::Word(word) if word == "depth" || word == "limit" => {
+                let clause = word.clone();
+                parser.position += 1;
+                let value = parser.expect_number(&clause)?;
+                if clause == "depth" {
+                    depth = value;
+                } else {
+                    limit = Some(value);
+                }
+            }
+            _ => return Err(query_parse_error("`&`, `|`, `-`, `depth` or `limit`", token)),
+        }
+    }
+    Ok(ParsedGraphQueryProgram { expression, depth, limit })
+}
+
+/// Parse and evaluate a query against an in-memory graph
+///
+/// # 4-Word Name: evaluate_graph_query_text
+///
+/// # Contract
+/// - Postcondition: matches sorted by key, at most `limit`
+/// - Error: parse errors, unknown functions, wrong arity, symbols that
+///   match nothing
+pub fn evaluate_graph_query_text(
+    text: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> Result<GraphQueryResultPayload> {
+    let program = parse_graph_query_text(text)?;
+    let context = GraphQueryEvaluationContext::from_graph(entities, edges, program.depth);
+    let keys = context.evaluate_expression(&program.expression)?;
+
+    let total_count = keys.len();
+    let limit = program.limit.unwrap_or(usize::MAX);
+    let matches = keys.iter().take(limit).map(|key| context.describe_node_key(key)).collect();
+    Ok(GraphQueryResultPayload {
+        query: text.trim().to_string(),
+        depth: program.depth,
+        total_count,
+        truncated: total_count > limit,
+        matches,
+    })
+}
+
+// ── Tokenizer ────────────────────────────────────────────────────────────
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+enum QueryTokenKind {
+    Word(String),
+    Quoted(String),
+    OpenParen,
+    CloseParen,
+    Comma,
+    And,
+    Or,
+    Minus,
+    Not,
+}
+
+#[derive(Debug, Clone)]
+struct QueryToken {
+    kind: QueryTokenKind,
+    /// 1-based character column
+    column: usize,
+}
+
+fn tokenize_graph_query_text(text: &str) -> Result<Vec<QueryToken>> {
+    let chars: Vec<char> = text.chars().collect();
+    let mut tokens = Vec::new();
+    let mut index = 0;
+    while index < chars.len() {
+        let c = chars[index];
+        let column = index + 1;
+        let single = match c {
+            '(' => Some(QueryTokenKind::OpenParen),
+            ')' => Some(QueryTokenKind::CloseParen),
+            ',' => Some(QueryTokenKind::Comma),
+            '&' => Some(QueryTokenKind::And),
+            '|' => Some(QueryTokenKind::Or),
+            '!' => Some(QueryTokenKind::Not),
+            _ => None,
+        };
+        if let Some(kind) = single {
+            tokens.push(QueryToken { kind, column });
+            index += 1;
+        } else if c.is_whitespace() {
+            index += 1;
+        } else if c == '"' || c == '\'' {
+            let end = chars[index + 1..].iter().position(|&d| d == c).ok_or_else(|| ParseltongError::ParseError {
+                reason: "unterminated string".to_string(),
+                location: format!("query column {}", column),
+            })?;
+            let value: String = chars[index + 1..index + 1 + end].iter().collect();
+            tokens.push(QueryToken { kind: QueryTokenKind::Quoted(value), column });
+            index += end + 2;
+        } else {
+            let start = index;
+            while index < chars.len() && !chars[index].is_whitespace() && !"(),&|!\"'".contains(chars[index]) {
+                index += 1;
+            }
+            let word: String = chars[start..index].iter().collect();
+            // `-` only subtracts as its own word: keys and packages contain dashes
+            let kind = if word == "-" { QueryTokenKind::Minus } else { QueryTokenKind::Word(word) };
+            tokens.push(QueryToken { kind, column });
+        }
+    }
+    Ok(tokens)
+}
+
+// ── Parser ───────────────────────────────────────────────────────────────
+
+struct QueryTokenParser<'t> {
+    tokens: &'t [QueryToken],
+    position: usize,
+}
+
+impl<'t> QueryTokenParser<'t> {
+    fn peek(&self) -> Option<&'t QueryToken> {
+        self.tokens.get(self.position)
+    }
+
+    fn next_token(&mut self, expected: &str) -> Result<&'t QueryToken> {
+        let token = self.tokens.get(self.position).ok_or_else(|| ParseltongError::ParseError {
+            reason: format!("expected {}, found end of query", expected),
+            location: "query end".to_string(),
+        })?;
+        self.position += 1;
+        Ok(token)
+    }
+
+    fn parse_union(&mut self) -> Result<GraphQueryExpressionNode> {
+        let mut left = self.parse_intersection()?;
+        while matches!(self.peek().map(|t| &t.kind), Some(QueryTokenKind::Or)) {
+            self.position += 1;
+            let right = self.parse_intersection()?;
+            left = GraphQueryExpressionNode::Union(Box::new(left), Box::new(right));
+        }
+        Ok(left)
+    }
+
+    fn parse_intersection(&mut self) -> Result<GraphQueryExpressionNode> {
+        let mut left = self.parse_unary()?;
+        loop {
+            let combine: fn(Box<GraphQueryExpressionNode>, Box<GraphQueryExpressionNode>) -> GraphQueryExpressionNode =
+                match self.peek().map(|t| &t.kind) {
+                    Some(QueryTokenKind::And) => GraphQueryExpressionNode::Intersect,
+                    Some(QueryTokenKind::Minus) => GraphQueryExpressionNode::Difference,
+                    _ => return Ok(left),
+                };
+            self.position += 1;
+            let right = self.parse_unary()?;
+            left = combine(Box::new(left), Box::new(right));
+        }
+    }
+
+    fn parse_unary(&mut self) -> Result<GraphQueryExpressionNode> {
+        let token = self.next_token("a function call")?;
+        match &token.kind {
+            QueryTokenKind::Not => Ok(GraphQueryExpressionNode::Complement(Box::new(self.parse_unary()?))),
+            QueryTokenKind::OpenParen => {
+                let inner = self.parse_union()?;
+                let close = self.next_token("`)`")?;
+                if close.kind != QueryTokenKind::CloseParen {
+                    return Err(query_parse_error("`)`", close));
+                }
+                Ok(inner)
+            }
+            QueryTokenKind::Word(function) => {
+                let open = self.next_token("`(`")?;
+                if open.kind != QueryTokenKind::OpenParen {
+                    return Err(query_parse_error("`(` after a function name", open));
+                }
+                let mut args = Vec::new();
+                if matches!(self.peek().map(|t| &t.kind), Some(QueryTokenKind::CloseParen)) {
+                    self.position += 1;
+                } else {
+                    loop {
+                        let arg = self.next_token("an argument")?;
+                        match &arg.kind {
+                            QueryTokenKind::Word(value) | QueryTokenKind::Quoted(value) => args.push(value.clone()),
+                            _ => return Err(query_parse_error("an argument", arg)),
+                        }
+                        let separator = self.next_token("`,` or `)`")?;
+                        match separator.kind {
+                            QueryTokenKind::Comma => continue,
+                            QueryTokenKind::CloseParen => break,
+                            _ => return Err(query_parse_error("`,` or `)`", separator)),
+                        }
+                    }
+                }
+                Ok(GraphQueryExpressionNode::Call { function: function.clone(), args })
+            }
+            _ => Err(query_parse_error("a function call", token)),
+        }
+    }
+
+    fn expect_number(&mut self, clause: &str) -> Result<usize> {
+        let token = self.next_token(&format!("a number after `{}`", clause))?;
+        match &token.kind {
+            QueryTokenKind::Word(word) => word
+                .parse()
+                .map_err(|_| query_parse_error(&format!("a number after `{}`", clause), token)),
+            _ => Err(query_parse_error(&format!("a number after `{}`", clause), token)),
+        }
+    }
+}
+
+fn query_parse_error(expected: &str, token: &QueryToken) -> ParseltongError {
+    let found = match &token.kind {
+        QueryTokenKind::Word(word) => format!("`{}`", word),
+        QueryTokenKind::Quoted(value) => format!("\"{}\"", value),
+        QueryTokenKind::OpenParen => "`(`".to_string(),
+        QueryTokenKind::CloseParen => "`)`".to_string(),
+        QueryTokenKind::Comma => "`,`".to_string(),
+        QueryTokenKind::And => "`&`".to_string(),
+        QueryTokenKind::Or => "`|`".to_string(),
+        QueryTokenKind::Minus => "`-`".to_string(),
+        QueryTokenKind::Not => "`!`".to_string(),
+    };
+    ParseltongError::ParseError {
+        reason: format!("expected {}, found {}", expected, found),
+        location: format!("query column {}", token.column),
+    }
+}
+
+// ── Evaluation ───────────────────────────────────────────────────────────
+
+struct GraphQueryEvaluationContext<'g> {
+    entities: HashMap<&'g str, &'g CodeEntity>,
+    edges: &'g [DependencyEdge],
+    /// Every entity key and edge endpoint
+    universe: BTreeSet<String>,
+    depth: usize,
+}
+
+impl<'g> GraphQueryEvaluationContext<'g> {
+    fn from_graph(entities: &'g [CodeEntity], edges: &'g [DependencyEdge], depth: usize) -> Self {
+        let mut universe: BTreeSet<String> = entities.iter().map(|e| e.isgl1_key.clone()).collect();
+        for edge in edges {
+            universe.insert(edge.from_key.as_str().to_string());
+            universe.insert(edge.to_key.as_str().to_string());
+        }
+        Self {
+            entities: entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect(),
+            edges,
+            universe,
+            depth,
+        }
+    }
+
+    fn evaluate_expression(&self, node: &GraphQueryExpressionNode) -> Result<BTreeSet<String>> {
+        Ok(match node {
+            GraphQueryExpressionNode::Call { function, args } => self.evaluate_function_call(function, args)?,
+            GraphQueryExpressionNode::Intersect(a, b) => {
+                let right = self.evaluate_expression(b)?;
+                self.evaluate_expression(a)?.into_iter().filter(|k| right.contains(k)).collect()
+            }
+            GraphQueryExpressionNode::Union(a, b) => {
+                let mut left = self.evaluate_expression(a)?;
+                left.extend(self.evaluate_expression(b)?);
+                left
+            }
+            GraphQueryExpressionNode::Difference(a, b) => {
+                let right = self.evaluate_expression(b)?;
+                self.evaluate_expression(a)?.into_iter().filter(|k| !right.contains(k)).collect()
+            }
+            GraphQueryExpressionNode::Complement(inner) => {
+                let excluded = self.evaluate_expression(inner)?;
+                self.universe.iter().filter(|k| !excluded.contains(*k)).cloned().collect()
+            }
+        })
+    }
+
+    fn evaluate_function_call(&self, function: &str, args: &[String]) -> Result<BTreeSet<String>> {
+        if function == "all" {
+            return Ok(self.universe.clone());
+        }
+        if args.is_empty() {
+            return Err(ParseltongError::ParseError {
+                reason: format!("{}() needs at least one argument", function),
+                location: format!("query function {}", function),
+            });
+        }
+        let mut result = BTreeSet::new();
+        for arg in args {
+            match function {
+                "callers" => self.collect_reverse_reach(arg, &[EdgeType::Calls], &mut result)?,
+                "dependents" => self.collect_reverse_reach(arg, &all_edge_type_variants_list(), &mut result)?,
+                "implementers" => self.collect_reverse_reach(arg, &[EdgeType::Implements], &mut result)?,
+                "callees" => self.collect_forward_reach(arg, &[EdgeType::Calls], &mut result)?,
+                "dependencies" => self.collect_forward_reach(arg, &all_edge_type_variants_list(), &mut result)?,
+                "symbol" => result.extend(self.resolve_symbol_keys(arg)?),
+                "in_package" => result.extend(self.filter_entity_keys(|e| package_matches(e, arg))),
+                "in_file" => result.extend(self.filter_entity_keys(|e| {
+                    matches_path_glob_pattern(&e.interface_signature.file_path.to_string_lossy(), arg)
+                })),
+                "kind" => result.extend(self.filter_universe_keys(|k| key_segment(k, 1) == arg.as_str())),
+                "lang" => result.extend(self.filter_universe_keys(|k| key_segment(k, 0) == arg.as_str())),
+                "name" => result.extend(self.filter_universe_keys(|k| matches_path_glob_pattern(key_segment(k, 2), arg))),
+                other => {
+                    return Err(ParseltongError::ParseError {
+                        reason: format!(
+                            "unknown function {}() (known: callers, callees, dependents, dependencies, implementers, symbol, in_package, in_file, kind, lang, name, all)",
+                            other
+                        ),
+                        location: format!("query function {}", other),
+                    })
+                }
+            }
+        }
+        Ok(result)
+    }
+
+    fn collect_reverse_reach(&self, symbol: &str, allowed: &[EdgeType], out: &mut BTreeSet<String>) -> Result<()> {
+        for key in self.resolve_symbol_keys(symbol)? {
+            let reached = compute_filtered_blast_radius(self.edges, &key, self.depth, allowed);
+            out.extend(reached.into_iter().map(|hop| hop.entity_key));
+        }
+        Ok(())
+    }
+
+    fn collect_forward_reach(&self, symbol: &str, allowed: &[EdgeType], out: &mut BTreeSet<String>) -> Result<()> {
+        let mut outgoing: HashMap<&str, Vec<&str>> = HashMap::new();
+        for edge in self.edges.iter().filter(|e| allowed.contains(&e.edge_type)) {
+            outgoing.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+        }
+        for start in self.resolve_symbol_keys(symbol)? {
+            let mut visited: HashSet<&str> = HashSet::new();
+            let mut queue: VecDeque<(&str, usize)> = VecDeque::new();
+            queue.push_back((start.as_str(), 0));
+            visited.insert(start.as_str());
+            while let Some((key, depth)) = queue.pop_front() {
+                if depth >= self.depth {
+                    continue;
+                }
+                for &target in outgoing.get(key).into_iter().flatten() {
+                    if visited.insert(target) {
+                        out.insert(target.to_string());
+                        queue.push_back((target, depth + 1));
+                    }
+                }
+            }
+        }
+        Ok(())
+    }
+
+    /// Keys a symbol argument stands for
+    fn resolve_symbol_keys(&self, symbol: &str) -> Result<Vec<String>> {
+        if self.universe.contains(symbol) {
+            return Ok(vec![symbol.to_string()]);
+        }
+        let (qualifier, leaf) = split_symbol_qualifier(symbol);
+        let matches: Vec<String> = self
+            .universe
+            .iter()
+            .filter(|key| key_segment(key, 2) == leaf)
+            .filter(|key| qualifier.map_or(true, |q| self.key_matches_qualifier(key, q)))
+            .cloned()
+            .collect();
+        if matches.is_empty() {
+            return Err(ParseltongError::EntityNotFound { isgl1_key: symbol.to_string() });
+        }
+        Ok(matches)
+    }
+
+    fn key_matches_qualifier(&self, key: &str, qualifier: &str) -> bool {
+        if key_segment(key, 3).contains(qualifier) {
+            return true;
+        }
+        let Some(entity) = self.entities.get(key) else { return false };
+        let receiver = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY).map(|r| r.trim_start_matches('*'));
+        let file_stem = entity.interface_signature.file_path.file_stem().map(|s| s.to_string_lossy().to_string());
+        receiver == Some(qualifier)
+            || package_matches(entity, qualifier)
+            || file_stem.is_some_and(|stem| stem.eq_ignore_ascii_case(qualifier))
+    }
+
+    fn filter_entity_keys(&self, keep: impl Fn(&CodeEntity) -> bool) -> Vec<String> {
+        self.entities.values().filter(|e| keep(e)).map(|e| e.isgl1_key.clone()).collect()
+    }
+
+    fn filter_universe_keys(&self, keep: impl Fn(&str) -> bool) -> Vec<String> {
+        self.universe.iter().filter(|k| keep(k)).cloned().collect()
+    }
+
+    fn describe_node_key(&self, key: &str) -> GraphQueryMatchEntry {
+        let entity = self.entities.get(key);
+        GraphQueryMatchEntry {
+            key: key.to_string(),
+            name: entity.map_or_else(|| key_segment(key, 2).to_string(), |e| e.interface_signature.name.clone()),
+            kind: key_segment(key, 1).to_string(),
+            file_path: entity.map(|e| e.interface_signature.file_path.to_string_lossy().to_string()),
+            line_start: entity.map(|e| e.interface_signature.line_range.start),
+        }
+    }
+}
+
+/// `UserService.CreateUser` → (Some("UserService"), "CreateUser")
+fn split_symbol_qualifier(symbol: &str) -> (Option<&str>, &str) {
+    for separator in ["::", "#", "."] {
+        if let Some((qualifier, leaf)) = symbol.rsplit_once(separator) {
+            if !qualifier.is_empty() && !leaf.is_empty() {
+                return (Some(qualifier.rsplit(['.', ':', '/']).next().unwrap_or(qualifier)), leaf);
+            }
+        }
+    }
+    (None, symbol)
+}
+
+/// Package directory equal to `package`, ending in `/package`, or matching it as a glob
+fn package_matches(entity: &CodeEntity, package: &str) -> bool {
+    let directory = package_directory_of_entity(entity);
+    let directory = directory.trim_start_matches("./");
+    directory == package
+        || directory.ends_with(&format!("/{}", package))
+        || (package.contains('*') && matches_path_glob_pattern(directory, package))
+}
+
+/// `index`-th `:` segment of an ISGL1 key, empty when missing
+fn key_segment(key: &str, index: usize) -> &str {
+    key.split(':').nth(index).unwrap_or("")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_parser_precedence_clauses_and_errors() {
+        let program = parse_graph_query_text("callers(a) | callers(b) & kind(fn) depth 3 limit 5").unwrap();
+        assert_eq!(program.depth, 3);
+        assert_eq!(program.limit, Some(5));
+        // `&` binds tighter than `|`
+        assert!(matches!(program.expression, GraphQueryExpressionNode::Union(_, ref right)
+            if matches!(**right, GraphQueryExpressionNode::Intersect(_, _))));
+
+        let dashed = parse_graph_query_text("symbol(go:fn:new:unresolved-reference:0-0) - in_file(\"*_test.go\")").unwrap();
+        match dashed.expression {
+            GraphQueryExpressionNode::Difference(left, _) => assert_eq!(
+                *left,
+                GraphQueryExpressionNode::Call {
+                    function: "symbol".to_string(),
+                    args: vec!["go:fn:new:unresolved-reference:0-0".to_string()]
+                }
+            ),
+            other => panic!("expected difference, got {:?}", other),
+        }
+
+        let error = parse_graph_query_text("callers(a) depth").unwrap_err().to_string();
+        assert!(error.contains("a number after `depth`"), "{}", error);
+        let error = parse_graph_query_text("callers(a) callers(b)").unwrap_err().to_string();
+        assert!(error.contains("column 12"), "{}", error);
+    }
+
+    #[test]
+    fn test_callers_in_package_with_depth() {
+        let entity = |key: &str, name: &str, file: &str, receiver: Option<&str>| {
+            let metadata: Vec<(&str, &str)> = receiver.map(|r| vec![(RECEIVER_TYPE_METADATA_KEY, r)]).unwrap_or_default();
+            create_go_test_entity(key, name, EntityType::Method, file, &metadata)
+        };
+        let entities = vec![
+            entity("go:method:CreateUser:__svc:T1", "CreateUser", "svc/user_service.go", Some("*UserService")),
+            entity("go:method:CreateUser:__admin:T2", "CreateUser", "admin/admin.go", Some("*AdminService")),
+            entity("go:fn:handleSignup:__api:T3", "handleSignup", "api/signup.go", None),
+            entity("go:fn:routes:__api:T4", "routes", "api/routes.go", None),
+            entity("go:fn:seed:__tools:T5", "seed", "tools/seed.go", None),
+        ];
+        let call = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+        };
+        let edges = vec![
+            call("go:fn:handleSignup:__api:T3", "go:method:CreateUser:__svc:T1"),
+            call("go:fn:routes:__api:T4", "go:fn:handleSignup:__api:T3"),
+            call("go:fn:seed:__tools:T5", "go:method:CreateUser:__svc:T1"),
+            call("go:fn:seed:__tools:T5", "go:method:CreateUser:__admin:T2"),
+        ];
+
+        let keys = |result: GraphQueryResultPayload| result.matches.into_iter().map(|m| m.key).collect::<Vec<_>>();
+        let one_hop = evaluate_graph_query_text("callers(UserService.CreateUser) & in_package(\"api\")", &entities, &edges).unwrap();
+        assert_eq!(keys(one_hop), vec!["go:fn:handleSignup:__api:T3"]);
+
+        let two_hops = evaluate_graph_query_text("callers(UserService.CreateUser) & in_package(api) depth 2", &entities, &edges).unwrap();
+        assert_eq!(keys(two_hops), vec!["go:fn:handleSignup:__api:T3", "go:fn:routes:__api:T4"]);
+
+        // Unqualified name: both CreateUser methods; limit truncates
+        let all_callers = evaluate_graph_query_text("callers(CreateUser) limit 1", &entities, &edges).unwrap();
+        assert_eq!((all_callers.total_count, all_callers.truncated, all_callers.matches.len()), (2, true, 1));
+
+        assert!(evaluate_graph_query_text("callers(Missing)", &entities, &edges).is_err());
+        assert!(evaluate_graph_query_text("frobnicate(x)", &entities, &edges).is_err());
+    }
+}
@@ -0,0 +1,311 @@
+//! Graph diff between two snapshots (v1.7.3)
+//!
+//! # 4-Word Naming: graph_snapshot_diff_reporter
+//!
+//! `parseltongue graph-diff old.json.zst new.json.zst` compares two
+//! `export --format json` snapshots, typically of a PR's base and head:
+//!
+//! - nodes are matched by ISGL1 key; a node present in both is `changed`
+//!   when its body (content hash), signature or visibility differs
+//! - edges are matched by (from, to, edge type); a moved call site is not a
+//!   change
+//! - external placeholders (`...:unresolved-reference:...`) are not nodes of
+//!   the diff, but edges into them are
+//!
+//! The summary groups added and removed edges by the package (directory) of
+//! their target per edge type, which is what CI comments need: "adds 3
+//! public symbols and removes 2 call edges into `internal/payments`".
+
+use std::collections::BTreeMap;
+
+use serde::Serialize;
+
+use crate::dead_code_detection_analyzer::is_entity_symbol_exported;
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::symbol_doc_signature_extractor::{extract_symbol_signature_line, SIGNATURE_METADATA_KEY};
+
+/// Target package of edges into placeholders
+pub const EXTERNAL_TARGET_PACKAGE_NAME: &str = "external";
+
+/// A node only in one snapshot, or in both with `changes`
+///
+/// # 4-Word Name: GraphNodeDiffEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GraphNodeDiffEntry {
+    pub key: String,
+    pub name: String,
+    /// Key segment 2: fn, method, struct, ...
+    pub kind: String,
+    pub file_path: String,
+    pub exported: bool,
+    /// `body`, `signature`, `visibility`; empty for added/removed nodes
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub changes: Vec<&'static str>,
+}
+
+/// An edge only in one snapshot
+///
+/// # 4-Word Name: GraphEdgeDiffEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct GraphEdgeDiffEntry {
+    pub from_key: String,
+    pub to_key: String,
+    pub edge_type: String,
+    /// Package directory of the target, or `external`
+    pub to_package: String,
+}
+
+/// Added/removed edge counts per edge type into one package
+///
+/// # 4-Word Name: TargetPackageEdgeDelta
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct TargetPackageEdgeDelta {
+    pub added: BTreeMap<String, usize>,
+    pub removed: BTreeMap<String, usize>,
+}
+
+/// Counts of the diff
+///
+/// # 4-Word Name: GraphSnapshotDiffSummary
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct GraphSnapshotDiffSummary {
+    pub nodes_added: usize,
+    pub nodes_removed: usize,
+    pub nodes_changed: usize,
+    pub public_symbols_added: usize,
+    pub public_symbols_removed: usize,
+    pub public_symbols_changed: usize,
+    pub edges_added: usize,
+    pub edges_removed: usize,
+    /// Only packages whose inbound edges changed
+    pub by_target_package: BTreeMap<String, TargetPackageEdgeDelta>,
+}
+
+/// Node lists of the diff
+///
+/// # 4-Word Name: GraphNodeDiffSections
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct GraphNodeDiffSections {
+    pub added: Vec<GraphNodeDiffEntry>,
+    pub removed: Vec<GraphNodeDiffEntry>,
+    pub changed: Vec<GraphNodeDiffEntry>,
+}
+
+/// Edge lists of the diff
+///
+/// # 4-Word Name: GraphEdgeDiffSections
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct GraphEdgeDiffSections {
+    pub added: Vec<GraphEdgeDiffEntry>,
+    pub removed: Vec<GraphEdgeDiffEntry>,
+}
+
+/// Everything `graph-diff` prints
+///
+/// # 4-Word Name: GraphSnapshotDiffReport
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct GraphSnapshotDiffReport {
+    pub summary: GraphSnapshotDiffSummary,
+    pub nodes: GraphNodeDiffSections,
+    pub edges: GraphEdgeDiffSections,
+}
+
+/// Compare two graph snapshots
+///
+/// # 4-Word Name: diff_graph_snapshot_pair
+///
+/// # Contract
+/// - Postcondition: node lists sorted by key, edge lists by (from, to,
+///   type); a node or edge is in at most one list
+pub fn diff_graph_snapshot_pair(
+    old_entities: &[CodeEntity],
+    old_edges: &[DependencyEdge],
+    new_entities: &[CodeEntity],
+    new_edges: &[DependencyEdge],
+) -> GraphSnapshotDiffReport {
+    let old_nodes = index_diffable_graph_nodes(old_entities);
+    let new_nodes = index_diffable_graph_nodes(new_entities);
+    let mut report = GraphSnapshotDiffReport::default();
+
+    for (key, old) in &old_nodes {
+        match new_nodes.get(key) {
+            None => report.nodes.removed.push(describe_graph_node_entry(old, Vec::new())),
+            Some(new) => {
+                let changes = compare_graph_node_versions(old, new);
+                if !changes.is_empty() {
+                    report.nodes.changed.push(describe_graph_node_entry(new, changes));
+                }
+            }
+        }
+    }
+    for (key, new) in &new_nodes {
+        if !old_nodes.contains_key(key) {
+            report.nodes.added.push(describe_graph_node_entry(new, Vec::new()));
+        }
+    }
+
+    let old_edge_keys = index_graph_edge_keys(old_edges);
+    let new_edge_keys = index_graph_edge_keys(new_edges);
+    for (key, edge) in &old_edge_keys {
+        if !new_edge_keys.contains_key(key) {
+            report.edges.removed.push(describe_graph_edge_entry(edge, &old_nodes));
+        }
+    }
+    for (key, edge) in &new_edge_keys {
+        if !old_edge_keys.contains_key(key) {
+            report.edges.added.push(describe_graph_edge_entry(edge, &new_nodes));
+        }
+    }
+
+    let summary = &mut report.summary;
+    summary.nodes_added = report.nodes.added.len();
+    summary.nodes_removed = report.nodes.removed.len();
+    summary.nodes_changed = report.nodes.changed.len();
+    summary.public_symbols_added = report.nodes.added.iter().filter(|n| n.exported).count();
+    summary.public_symbols_removed = report.nodes.removed.iter().filter(|n| n.exported).count();
+    summary.public_symbols_changed = report.nodes.changed.iter().filter(|n| n.exported).count();
+    summary.edges_added = report.edges.added.len();
+    summary.edges_removed = report.edges.removed.len();
+    for edge in &report.edges.added {
+        let delta = summary.by_target_package.entry(edge.to_package.clone()).or_default();
+        *delta.added.entry(edge.edge_type.clone()).or_default() += 1;
+    }
+    for edge in &report.edges.removed {
+        let delta = summary.by_target_package.entry(edge.to_package.clone()).or_default();
+        *delta.removed.entry(edge.edge_type.clone()).or_default() += 1;
+    }
+    report
+}
+
+/// One-line sentences of the summary, for CI comments
+///
+/// # 4-Word Name: render_graph_diff_headlines
+///
+/// `adds 3 public symbols, removes 2`, then one line per target package:
+/// `internal/payments: -2 Calls, +1 Uses`.
+pub fn render_graph_diff_headlines(summary: &GraphSnapshotDiffSummary) -> Vec<String> {
+    let mut lines = vec![format!(
+        "adds {} public symbols, removes {}, changes {} ({} / {} / {} nodes in total); adds {} edges, removes {}",
+        summary.public_symbols_added,
+        summary.public_symbols_removed,
+        summary.public_symbols_changed,
+        summary.nodes_added,
+        summary.nodes_removed,
+        summary.nodes_changed,
+        summary.edges_added,
+        summary.edges_removed
+    )];
+    for (package, delta) in &summary.by_target_package {
+        let mut parts: Vec<String> = delta.removed.iter().map(|(edge_type, n)| format!("-{} {}", n, edge_type)).collect();
+        parts.extend(delta.added.iter().map(|(edge_type, n)| format!("+{} {}", n, edge_type)));
+        let package = if package.is_empty() { "." } else { package.as_str() };
+        lines.push(format!("{}: {}", package, parts.join(", ")));
+    }
+    lines
+}
+
+fn index_diffable_graph_nodes(entities: &[CodeEntity]) -> BTreeMap<&str, &CodeEntity> {
+    entities
+        .iter()
+        .filter(|e| !is_external_graph_node_key(&e.isgl1_key))
+        .map(|e| (e.isgl1_key.as_str(), e))
+        .collect()
+}
+
+fn index_graph_edge_keys(edges: &[DependencyEdge]) -> BTreeMap<(&str, &str, &'static str), &DependencyEdge> {
+    edges.iter().map(|e| ((e.from_key.as_str(), e.to_key.as_str(), e.edge_type.as_str()), e)).collect()
+}
+
+fn graph_node_signature(entity: &CodeEntity) -> String {
+    match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+        Some(signature) => signature.clone(),
+        None => extract_symbol_signature_line(entity.current_code.as_deref().unwrap_or("")),
+    }
+}
+
+fn compare_graph_node_versions(old: &CodeEntity, new: &CodeEntity) -> Vec<&'static str> {
+    let mut changes = Vec::new();
+    if old.content_hash != new.content_hash || old.current_code != new.current_code {
+        changes.push("body");
+    }
+    if graph_node_signature(old) != graph_node_signature(new) {
+        changes.push("signature");
+    }
+    if is_entity_symbol_exported(old) != is_entity_symbol_exported(new) {
+        changes.push("visibility");
+    }
+    changes
+}
+
+fn describe_graph_node_entry(entity: &CodeEntity, changes: Vec<&'static str>) -> GraphNodeDiffEntry {
+    GraphNodeDiffEntry {
+        key: entity.isgl1_key.clone(),
+        name: entity.interface_signature.name.clone(),
+        kind: entity.isgl1_key.split(':').nth(1).unwrap_or_default().to_string(),
+        file_path: entity.interface_signature.file_path.to_string_lossy().to_string(),
+        exported: is_entity_symbol_exported(entity),
+        changes,
+    }
+}
+
+fn describe_graph_edge_entry(edge: &DependencyEdge, nodes: &BTreeMap<&str, &CodeEntity>) -> GraphEdgeDiffEntry {
+    let to_package = match nodes.get(edge.to_key.as_str()) {
+        Some(target) => package_directory_of_entity(target),
+        None => EXTERNAL_TARGET_PACKAGE_NAME.to_string(),
+    };
+    GraphEdgeDiffEntry {
+        from_key: edge.from_key.as_str().to_string(),
+        to_key: edge.to_key.as_str().to_string(),
+        edge_type: edge.edge_type.as_str().to_string(),
+        to_package,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn go_function_node(key: &str, name: &str, file: &str, code: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, EntityType::Function, file, &[]);
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    #[test]
+    fn test_diff_reports_nodes_edges_and_package_deltas() {
+        let charge = go_function_node("go:fn:Charge:__internal_payments:T1", "Charge", "internal/payments/charge.go", "func Charge() error { return nil }");
+        let refund = go_function_node("go:fn:Refund:__internal_payments:T2", "Refund", "internal/payments/refund.go", "func Refund() {}");
+        let handler = go_function_node("go:fn:handle:__api:T3", "handle", "api/handler.go", "func handle() { Charge(); Refund() }");
+        let mut handler_v2 = handler.clone();
+        handler_v2.current_code = Some("func handle() { log.Print() }".to_string());
+        let audit = go_function_node("go:fn:Audit:__api:T4", "Audit", "api/audit.go", "func Audit() {}");
+
+        let calls = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+        };
+        let old_edges = vec![calls(&handler.isgl1_key, &charge.isgl1_key), calls(&handler.isgl1_key, &refund.isgl1_key)];
+        let new_edges = vec![calls(&handler.isgl1_key, "go:fn:Print:unresolved-reference:0-0")];
+
+        let report = diff_graph_snapshot_pair(
+            &[charge.clone(), refund.clone(), handler],
+            &old_edges,
+            &[charge, refund, handler_v2, audit],
+            &new_edges,
+        );
+        assert_eq!(report.nodes.added.iter().map(|n| n.name.as_str()).collect::<Vec<_>>(), vec!["Audit"]);
+        assert_eq!(report.summary.public_symbols_added, 1);
+        assert_eq!(report.nodes.changed.len(), 1);
+        assert!(report.nodes.changed[0].changes.contains(&"body"));
+        assert_eq!(report.summary.edges_removed, 2);
+        assert_eq!(report.summary.by_target_package["internal/payments"].removed["Calls"], 2);
+        assert_eq!(report.summary.by_target_package[EXTERNAL_TARGET_PACKAGE_NAME].added["Calls"], 1);
+
+        let lines = render_graph_diff_headlines(&report.summary);
+        assert!(lines[0].starts_with("adds 1 public symbols, removes 0"));
+        assert!(lines.contains(&"internal/payments: -2 Calls".to_string()));
+    }
+}
@@ -0,0 +1,1412 @@
+//! HTTP Route Handler Mapper (v1.7.3)
+//!
+//! # 4-Word Naming: http_route_handler_mapper
+//!
+//! Router registrations become `Route` nodes named `METHOD /path` with a
+//! `Calls` edge to each handler, so "the code behind `POST /users`" is one
+//! lookup (`parseltongue query route /users/42`) instead of a grep through
+//! router setup.
+//!
+//! ## Go (net/http, gorilla/mux, gin, echo, chi)
+//!
+//! Files importing one of the routers are scanned per function body:
+//!
+//! - `r.GET("/users", h)`, `e.POST(...)`, `r.Any(...)` (gin, echo)
+//! - `r.Get("/users", h)`, `r.Method("GET", ...)`, `r.Mount("/admin", h)` (chi)
+//! - `r.Handle("GET", "/users", h)`, `e.Add("GET", ...)` (gin, echo)
+//! - `mux.HandleFunc("POST /users", h)` (Go 1.22 patterns) and gorilla's
+//!   `r.HandleFunc("/users", h).Methods("POST")`
+//!
+//! Prefixes of `api := r.Group("/api")`, `r.PathPrefix("/api").Subrouter()`
+//! and `r.Route("/api", func(r chi.Router) {...})` are applied. Handlers are
+//! functions (`handleUsers`), imported functions (`users.List`), methods
+//! (`s.handleUsers`, resolved like calls of the enclosing method: routes
+//! carry its `receiver_type` / `receiver_name`), closures, and whatever
+//! `http.HandlerFunc(...)` / `http.StripPrefix(p, h)` wrap. A middleware
+//! call `auth(h)` links both: `route_binding: wrapper` for `auth`,
+//! `route_binding: handler` for `h`.
+//!
+//! ## Rust (axum, actix-web, rocket)
+//!
+//! - `#[get("/users/<id>")]`, `#[route("/", method = "GET")]` on functions
+//! - `.route("/users", get(list).post(create))` (axum method routers)
+//! - `.route("/users", web::get().to(list))` and
+//!   `web::resource("/users").route(web::post().to(create))` (actix)
+//!
+//! Prefixes of enclosing `.nest("/api", ...)` and `web::scope("/api")` apply.
+//!
+//! ## Paths
+//!
+//! Parameters are normalized to `{name}` (`:id`, `<id>`, `{id:[0-9]+}`) and
+//! catch-alls to `*`. Registrations whose path is not a string literal are
+//! skipped; handler placeholders left after the language resolvers bind to
+//! the function of that name nearest the route (same file, then package).
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::path::Path;
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, Isgl1Key, Language};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_closure_literal_extractor::BYTE_SPAN_METADATA_KEY;
+use crate::go_embedding_promotion_resolver::{
+    package_directory_of_entity, parse_unresolved_reference_name, RECEIVER_EXPR_METADATA_KEY,
+    RECEIVER_NAME_METADATA_KEY, UNRESOLVED_TARGET_METADATA_KEY,
+};
+use crate::go_import_alias_resolver::{collect_go_file_import_table, GoFileImportTable, IMPORT_PATH_METADATA_KEY};
+use crate::go_package_value_extractor::{collect_go_declared_local_names, format_go_parsed_entity_key};
+use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path, sanitize_entity_name_for_isgl1};
+use crate::query_extractor::{key_component_for_entity_type, EntityType, ParsedEntity};
+use crate::rust_trait_impl_bound_extractor::find_rust_item_entity_key;
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Entity metadata key: `GET`, `POST`, ... or `ANY`
+pub const HTTP_METHOD_METADATA_KEY: &str = "http_method";
+
+/// Entity metadata key: normalized path template (`/users/{id}`)
+pub const HTTP_PATH_METADATA_KEY: &str = "http_path";
+
+/// Entity metadata key: router library (`gin`, `chi`, `net/http`, `axum`, ...)
+pub const HTTP_FRAMEWORK_METADATA_KEY: &str = "http_framework";
+
+/// Edge metadata key: `handler`, or `wrapper` for a middleware/factory the handler passes through
+pub const ROUTE_BINDING_METADATA_KEY: &str = "route_binding";
+
+/// Method of routes registered for every method
+pub const ANY_HTTP_METHOD: &str = "ANY";
+
+const HTTP_METHOD_NAMES: &[&str] = &["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "CONNECT", "TRACE"];
+
+/// Import path (or path prefix) → framework, first match wins
+const GO_ROUTER_IMPORT_FRAMEWORKS: &[(&str, &str)] = &[
+    ("github.com/gin-gonic/gin", "gin"),
+    ("github.com/labstack/echo", "echo"),
+    ("github.com/go-chi/chi", "chi"),
+    ("github.com/gorilla/mux", "gorilla"),
+    ("net/http", "net/http"),
+];
+
+/// One handler of a matched route
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct HttpRouteHandlerEntry {
+    pub key: String,
+    /// `handler` or `wrapper`
+    pub binding: String,
+    /// `file:start-end` when the handler is an indexed entity
+    pub location: Option<String>,
+    pub code: Option<String>,
+}
+
+/// A route and the code behind it
+///
+/// # 4-Word Name: HttpRouteMatchEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct HttpRouteMatchEntry {
+    pub route_key: String,
+    pub method: String,
+    pub path: String,
+    pub framework: String,
+    /// `file:line` of the registration
+    pub location: String,
+    /// Path parameters bound by the requested URL (`id` → `42`)
+    pub params: BTreeMap<String, String>,
+    pub handlers: Vec<HttpRouteHandlerEntry>,
+}
+
+/// Normalize a router path template: `{name}` parameters, `*` catch-alls
+///
+/// # 4-Word Name: normalize_http_route_path
+///
+/// # Contract
+/// - `:id`, `<id>`, `{id}`, `{id:[0-9]+}` → `{id}`; `*`, `*rest`, `{rest...}`,
+///   `<rest..>` → `*`; Go's `{$}` end anchor is dropped
+/// - Postcondition: starts with `/`
+pub fn normalize_http_route_path(path: &str) -> String {
+    let segments: Vec<String> = path
+        .split('/')
+        .map(|segment| {
+            if let Some(name) = segment.strip_prefix(':') {
+                format!("{{{}}}", name)
+            } else if let Some(inner) = segment.strip_prefix('<').and_then(|s| s.strip_suffix('>')) {
+                if inner.ends_with("..") {
+                    "*".to_string()
+                } else {
+                    format!("{{{}}}", inner)
+                }
+            } else if let Some(inner) = segment.strip_prefix('{').and_then(|s| s.strip_suffix('}')) {
+                if inner == "$" {
+                    String::new()
+                } else if inner.ends_with("...") || inner.starts_with('*') {
+                    "*".to_string()
+                } else {
+                    format!("{{{}}}", inner.split(':').next().unwrap_or(inner).trim())
+                }
+            } else if segment.starts_with('*') {
+                "*".to_string()
+            } else {
+                segment.to_string()
+            }
+        })
+        .collect();
+    let joined = segments.join("/");
+    if joined.starts_with('/') {
+        joined
+    } else {
+        format!("/{}", joined)
+    }
+}
+
+/// Route nodes and handler edges of one Go file
+///
+/// # 4-Word Name: extract_go_http_route_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path` and
+///   include its closures
+/// - Postcondition: empty unless the file imports a supported router; one
+///   entity per (method, path), one edge per handler reference
+pub fn extract_go_http_route_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+    let imports = collect_go_file_import_table(root, source);
+    let Some(framework) = GO_ROUTER_IMPORT_FRAMEWORKS
+        .iter()
+        .find(|(prefix, _)| {
+            imports.aliases.values().any(|path| path.as_str() == *prefix || path.starts_with(&format!("{}/", prefix)))
+        })
+        .map(|(_, framework)| *framework)
+    else {
+        return (Vec::new(), Vec::new());
+    };
+    let mut collector = HttpRouteCollector::new(Language::Go, file_path, framework);
+
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((owner, _)) = find_go_declaration_entity_key(declaration, source, entities) else {
+            continue;
+        };
+        let Some(body) = declaration.child_by_field_name("body") else { continue };
+        let receiver: Vec<(String, String)> = [RECEIVER_TYPE_METADATA_KEY, RECEIVER_NAME_METADATA_KEY]
+            .iter()
+            .filter_map(|k| owner.metadata.get(*k).map(|v| (k.to_string(), v.clone())))
+            .collect();
+        let mut locals = HashSet::new();
+        collect_go_declared_local_names(declaration, source, &mut locals);
+        let mut scope = GoRouteScope {
+            source,
+            imports: &imports,
+            locals: &locals,
+            entities,
+            group_prefixes: HashMap::new(),
+        };
+
+        let mut nodes = Vec::new();
+        let mut stack = vec![body];
+        while let Some(node) = stack.pop() {
+            let mut child_cursor = node.walk();
+            stack.extend(node.named_children(&mut child_cursor));
+            if matches!(node.kind(), "call_expression" | "short_var_declaration" | "assignment_statement") {
+                nodes.push(node);
+            }
+        }
+        nodes.sort_by_key(|node| node.start_byte());
+
+        for node in nodes {
+            if node.kind() != "call_expression" {
+                scope.record_group_prefix(node);
+                continue;
+            }
+            let line = node.start_position().row + 1;
+            for (method, path, handlers) in scope.route_registrations(node) {
+                collector.push_route(&method, &path, line, &receiver, handlers);
+            }
+        }
+    }
+    (collector.entities, collector.edges)
+}
+
+/// Route nodes and handler edges of one Rust file
+///
+/// # 4-Word Name: extract_rust_http_route_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: empty unless the file uses axum, actix-web or rocket;
+///   attribute routes link straight to the annotated function's entity
+pub fn extract_rust_http_route_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+    let framework = if source.contains("actix_web") {
+        "actix-web"
+    } else if source.contains("axum::") {
+        "axum"
+    } else if source.contains("rocket::") || source.contains("extern crate rocket") {
+        "rocket"
+    } else {
+        return (Vec::new(), Vec::new());
+    };
+    let mut collector = HttpRouteCollector::new(Language::Rust, file_path, framework);
+
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        match node.kind() {
+            "function_item" => {
+                let Some(name) = node.child_by_field_name("name").map(|n| &source[n.byte_range()]) else {
+                    continue;
+                };
+                let handler = find_rust_item_entity_key(node, &EntityType::Function, source, entities)
+                    .unwrap_or_else(|| rust_handler_target_key(name, entities));
+                let mut attribute = node.prev_named_sibling();
+                while let Some(item) = attribute.filter(|a| a.kind() == "attribute_item" || a.kind().ends_with("comment")) {
+                    if let Some((methods, path)) = rust_attribute_route(item, source) {
+                        for method in methods {
+                            let target = RouteHandlerTarget::new(handler.clone(), "handler");
+                            collector.push_route(&method, &path, item.start_position().row + 1, &[], vec![target]);
+                        }
+                    }
+                    attribute = item.prev_named_sibling();
+                }
+            }
+            "call_expression" => {
+                let Some(function) = node.child_by_field_name("function").filter(|f| f.kind() == "field_expression") else {
+                    continue;
+                };
+                let Some(field) = function.child_by_field_name("field").map(|f| &source[f.byte_range()]) else {
+                    continue;
+                };
+                let receiver = function.child_by_field_name("value");
+                let arguments = call_argument_nodes(node);
+                let routes = match (field, arguments.as_slice()) {
+                    ("route", [path, router]) => rust_string_literal_value(*path, source)
+                        .map(|path| (path, rust_method_router_handlers(*router, source))),
+                    ("route", [router]) => receiver
+                        .and_then(|r| rust_receiver_chain_path(r, source, "resource"))
+                        .map(|path| (path, rust_method_router_handlers(*router, source))),
+                    ("to", [handler]) => receiver
+                        .and_then(|r| rust_receiver_chain_path(r, source, "resource"))
+                        .map(|path| (path, vec![(ANY_HTTP_METHOD.to_string(), *handler)])),
+                    _ => None,
+                };
+                let Some((path, handlers)) = routes.filter(|(path, _)| path.starts_with('/') || path.is_empty()) else {
+                    continue;
+                };
+                let path = join_http_route_path(&rust_route_prefix(node, source), &path);
+                for (method, handler) in handlers {
+                    let targets: Vec<RouteHandlerTarget> = rust_handler_name(handler, source)
+                        .map(|name| {
+                            let key = rust_handler_target_key(name, entities);
+                            RouteHandlerTarget::new(key, "handler")
+                        })
+                        .into_iter()
+                        .collect();
+                    collector.push_route(&method, &path, node.start_position().row + 1, &[], targets);
+                }
+            }
+            _ => {}
+        }
+    }
+    (collector.entities, collector.edges)
+}
+
+/// Bind route handler placeholders to the functions they name
+///
+/// # 4-Word Name: resolve_http_route_handler_edges
+///
+/// # Contract
+/// - Precondition: the language resolvers ran, so import-qualified and
+///   receiver handlers are bound where they can be
+/// - Postcondition: a placeholder handler of a `Route` node is rebound when
+///   the nearest scope holding functions of that name (route's file, its
+///   directory, the whole graph) holds exactly one; `resolution:
+///   route_handler`. Go method values (`h.List`) only bind methods, bare
+///   names only functions
+pub fn resolve_http_route_handler_edges(entities: &[CodeEntity], edges: &mut [DependencyEdge]) -> usize {
+    use crate::entities::EntityType as GraphEntityType;
+
+    let routes: HashMap<&str, &CodeEntity> = entities
+        .iter()
+        .filter(|e| e.interface_signature.entity_type == GraphEntityType::Route)
+        .map(|e| (e.isgl1_key.as_str(), e))
+        .collect();
+    if routes.is_empty() {
+        return 0;
+    }
+    let mut functions: HashMap<(&str, &str), Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities
+        .iter()
+        .filter(|e| matches!(e.interface_signature.entity_type, GraphEntityType::Function | GraphEntityType::Method))
+    {
+        let language = entity.isgl1_key.split(':').next().unwrap_or("");
+        functions.entry((language, entity.interface_signature.name.as_str())).or_default().push(entity);
+    }
+
+    let mut rewritten = 0;
+    for edge in edges.iter_mut().filter(|e| e.edge_type == EdgeType::Calls) {
+        let Some(route) = routes.get(edge.from_key.as_str()) else { continue };
+        if edge.metadata.contains_key(IMPORT_PATH_METADATA_KEY) {
+            continue;
+        }
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()) else { continue };
+        let language = edge.to_key.as_str().split(':').next().unwrap_or("");
+        let wants_method = edge.metadata.contains_key(RECEIVER_EXPR_METADATA_KEY);
+        let candidates: Vec<&CodeEntity> = functions
+            .get(&(language, name))
+            .into_iter()
+            .flatten()
+            .copied()
+            .filter(|e| language != "go" || (e.interface_signature.entity_type == GraphEntityType::Method) == wants_method)
+            .collect();
+        let route_directory = package_directory_of_entity(route);
+        let same_file: Vec<&CodeEntity> = candidates
+            .iter()
+            .copied()
+            .filter(|e| e.interface_signature.file_path == route.interface_signature.file_path)
+            .collect();
+        let same_directory: Vec<&CodeEntity> = candidates
+            .iter()
+            .copied()
+            .filter(|e| package_directory_of_entity(e) == route_directory)
+            .collect();
+        let Some(target) = [same_file, same_directory, candidates]
+            .into_iter()
+            .find(|scope| !scope.is_empty())
+            .filter(|scope| scope.len() == 1)
+            .map(|scope| scope[0])
+        else {
+            continue;
+        };
+        if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+            edge.metadata.insert(UNRESOLVED_TARGET_METADATA_KEY.to_string(), edge.to_key.as_str().to_string());
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), "route_handler".to_string());
+            rewritten += 1;
+        }
+    }
+    rewritten
+}
+
+/// Routes serving a request, or every route when `url` is None
+///
+/// # 4-Word Name: match_http_route_entries
+///
+/// # Contract
+/// - Precondition: `url` is a path or full URL; scheme, host, query and
+///   fragment are ignored
+/// - Postcondition: routes whose template matches the path and whose method
+///   is `method` or `ANY` (any method when None); most literal segments
+///   first. Without a URL: every route, by path then method
+/// - `net/http` patterns ending in `/` match their subtree, as `ServeMux` does
+pub fn match_http_route_entries(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    url: Option<&str>,
+    method: Option<&str>,
+) -> Vec<HttpRouteMatchEntry> {
+    use crate::entities::EntityType as GraphEntityType;
+
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let request_path = url.map(http_request_path_of);
+    let mut matches: Vec<(usize, HttpRouteMatchEntry)> = Vec::new();
+    for route in entities.iter().filter(|e| e.interface_signature.entity_type == GraphEntityType::Route) {
+        let metadata = &route.metadata.additional;
+        let route_method = metadata.get(HTTP_METHOD_METADATA_KEY).map(String::as_str).unwrap_or(ANY_HTTP_METHOD);
+        let path = metadata.get(HTTP_PATH_METADATA_KEY).cloned().unwrap_or_default();
+        let framework = metadata.get(HTTP_FRAMEWORK_METADATA_KEY).cloned().unwrap_or_default();
+        if method.is_some_and(|m| route_method != ANY_HTTP_METHOD && !route_method.eq_ignore_ascii_case(m)) {
+            continue;
+        }
+        let (specificity, params) = match request_path {
+            Some(request) => {
+                let subtree = framework == "net/http" && path.ends_with('/');
+                let Some(found) = match_http_route_template(&path, request, subtree) else { continue };
+                found
+            }
+            None => (0, BTreeMap::new()),
+        };
+
+        let handlers = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Calls && e.from_key.as_str() == route.isgl1_key)
+            .map(|edge| {
+                let target = by_key.get(edge.to_key.as_str());
+                HttpRouteHandlerEntry {
+                    key: edge.to_key.as_str().to_string(),
+                    binding: edge.metadata_value(ROUTE_BINDING_METADATA_KEY).unwrap_or("handler").to_string(),
+                    location: target.map(|t| {
+                        let lines = &t.interface_signature.line_range;
+                        format!("{}:{}-{}", t.interface_signature.file_path.display(), lines.start, lines.end)
+                    }),
+                    code: target.and_then(|t| t.current_code.clone()),
+                }
+            })
+            .collect();
+        matches.push((
+            specificity,
+            HttpRouteMatchEntry {
+                route_key: route.isgl1_key.clone(),
+                method: route_method.to_string(),
+                path,
+                framework,
+                location: format!(
+                    "{}:{}",
+                    route.interface_signature.file_path.display(),
+                    route.interface_signature.line_range.start
+                ),
+                params,
+                handlers,
+            },
+        ));
+    }
+
+    if request_path.is_some() {
+        matches.sort_by(|a, b| {
+            b.0.cmp(&a.0)
+                .then_with(|| a.1.params.len().cmp(&b.1.params.len()))
+                .then_with(|| a.1.route_key.cmp(&b.1.route_key))
+        });
+    } else {
+        matches.sort_by(|a, b| (&a.1.path, &a.1.method, &a.1.route_key).cmp(&(&b.1.path, &b.1.method, &b.1.route_key)));
+    }
+    matches.into_iter().map(|(_, entry)| entry).collect()
+}
+
+/// Path component of a URL: `https://api.example.com/v1/users?id=1` → `/v1/users`
+fn http_request_path_of(url: &str) -> &str {
+    let path = match url.split_once("://") {
+        Some((_, rest)) => rest.find('/').map_or("/", |at| &rest[at..]),
+        None => url,
+    };
+    path.split(['?', '#']).next().unwrap_or(path)
+}
+
+/// Literal segment count and bound parameters when `template` matches `path`
+fn match_http_route_template(template: &str, path: &str, subtree: bool) -> Option<(usize, BTreeMap<String, String>)> {
+    let template_segments: Vec<&str> = template.trim_start_matches('/').split('/').collect();
+    let path_segments: Vec<&str> = path.trim_start_matches('/').split('/').collect();
+    let mut params = BTreeMap::new();
+    let mut literal = 0;
+    for (at, segment) in template_segments.iter().enumerate() {
+        if *segment == "*" || (subtree && segment.is_empty() && at + 1 == template_segments.len()) {
+            return Some((literal, params));
+        }
+        let actual = path_segments.get(at)?;
+        match segment.strip_prefix('{').and_then(|s| s.strip_suffix('}')) {
+            Some(name) if !actual.is_empty() => {
+                params.insert(name.to_string(), actual.to_string());
+            }
+            Some(_) => return None,
+            None if segment == actual => literal += 1,
+            None => return None,
+        }
+    }
+    (template_segments.len() == path_segments.len()).then_some((literal, params))
+}
+
+/// `prefix` + `path`, with exactly one `/` between them
+fn join_http_route_path(prefix: &str, path: &str) -> String {
+    let prefix = prefix.trim_end_matches('/');
+    let joined = if path.is_empty() {
+        prefix.to_string()
+    } else if path.starts_with('/') {
+        format!("{}{}", prefix, path)
+    } else {
+        format!("{}/{}", prefix, path)
+    };
+    if joined.is_empty() {
+        "/".to_string()
+    } else {
+        joined
+    }
+}
+
+/// Named arguments of a call, comments skipped
+fn call_argument_nodes(call: tree_sitter::Node<'_>) -> Vec<tree_sitter::Node<'_>> {
+    let Some(arguments) = call.child_by_field_name("arguments") else {
+        return Vec::new();
+    };
+    let mut cursor = arguments.walk();
+    arguments
+        .named_children(&mut cursor)
+        .filter(|a| !a.kind().ends_with("comment"))
+        .collect()
+}
+
+/// One edge target of a registration
+struct RouteHandlerTarget {
+    to_key: String,
+    binding: &'static str,
+    metadata: Vec<(&'static str, String)>,
+}
+
+impl RouteHandlerTarget {
+    fn new(to_key: String, binding: &'static str) -> Self {
+        Self { to_key, binding, metadata: Vec::new() }
+    }
+}
+
+struct HttpRouteCollector {
+    language: Language,
+    file_path: String,
+    framework: &'static str,
+    entities: Vec<ParsedEntity>,
+    edges: Vec<DependencyEdge>,
+}
+
+impl HttpRouteCollector {
+    fn new(language: Language, file_path: &Path, framework: &'static str) -> Self {
+        Self {
+            language,
+            file_path: file_path.to_string_lossy().to_string(),
+            framework,
+            entities: Vec::new(),
+            edges: Vec::new(),
+        }
+    }
+
+    /// Add the route node (once per name) and one edge per handler
+    fn push_route(
+        &mut self,
+        method: &str,
+        path: &str,
+        line: usize,
+        receiver: &[(String, String)],
+        handlers: Vec<RouteHandlerTarget>,
+    ) {
+        let path = normalize_http_route_path(path);
+        let name = format!("{} {}", method, path);
+        // pt01 keys Route nodes with a sanitized name (`GET_/users/__lc__id__rc__`)
+        let key = format!(
+            "{}:{}:{}:{}:T{}",
+            self.language,
+            key_component_for_entity_type(&EntityType::Route),
+            sanitize_entity_name_for_isgl1(&name),
+            extract_semantic_path(&self.file_path),
+            compute_birth_timestamp(&self.file_path, &name)
+        );
+        if !self.entities.iter().any(|e| e.name == name) {
+            let mut metadata: HashMap<String, String> = receiver.iter().cloned().collect();
+            metadata.insert(HTTP_METHOD_METADATA_KEY.to_string(), method.to_string());
+            metadata.insert(HTTP_PATH_METADATA_KEY.to_string(), path);
+            metadata.insert(HTTP_FRAMEWORK_METADATA_KEY.to_string(), self.framework.to_string());
+            self.entities.push(ParsedEntity {
+                entity_type: EntityType::Route,
+                name,
+                language: self.language,
+                line_range: (line, line),
+                file_path: self.file_path.clone(),
+                metadata,
+            });
+        }
+        for handler in handlers {
+            let mut builder = DependencyEdge::builder()
+                .from_key(key.clone())
+                .to_key(handler.to_key)
+                .edge_type(EdgeType::Calls)
+                .source_location(format!("{}:{}", self.file_path, line))
+                .metadata_entry(ROUTE_BINDING_METADATA_KEY, handler.binding);
+            for (k, v) in handler.metadata {
+                builder = builder.metadata_entry(k, v);
+            }
+            if let Ok(edge) = builder.build() {
+                self.edges.push(edge);
+            }
+        }
+    }
+}
+
+/// Names and group prefixes visible in one Go function body
+struct GoRouteScope<'a> {
+    source: &'a str,
+    imports: &'a GoFileImportTable,
+    locals: &'a HashSet<String>,
+    entities: &'a [ParsedEntity],
+    /// `api := r.Group("/api")` → `api` → `/api`
+    group_prefixes: HashMap<String, String>,
+}
+
+impl GoRouteScope<'_> {
+    fn text(&self, node: tree_sitter::Node<'_>) -> &str {
+        &self.source[node.byte_range()]
+    }
+
+    /// `v1 := r.Group("/v1")`, `api := r.PathPrefix("/api").Subrouter()`
+    fn record_group_prefix(&mut self, assignment: tree_sitter::Node<'_>) {
+        let (Some(left), Some(right)) = (
+            assignment.child_by_field_name("left").and_then(|l| l.named_child(0)),
+            assignment.child_by_field_name("right").and_then(|r| r.named_child(0)),
+        ) else {
+            return;
+        };
+        if left.kind() != "identifier" || right.kind() != "call_expression" {
+            return;
+        }
+        let prefix = self.operand_prefix(right);
+        if !prefix.is_empty() {
+            let name = self.text(left).to_string();
+            self.group_prefixes.insert(name, prefix);
+        }
+    }
+
+    /// Path prefix of the router a registration is called on
+    fn operand_prefix(&self, operand: tree_sitter::Node<'_>) -> String {
+        match operand.kind() {
+            "identifier" => {
+                let name = self.text(operand);
+                // chi: `r.Route("/api", func(r chi.Router) { r.Get(...) })`
+                let mut ancestor = operand.parent();
+                while let Some(node) = ancestor {
+                    if node.kind() == "func_literal" && go_func_literal_declares_parameter(node, name, self.source) {
+                        return node
+                            .parent()
+                            .and_then(|arguments| arguments.parent())
+                            .filter(|call| call.kind() == "call_expression")
+                            .map(|call| self.operand_prefix(call))
+                            .unwrap_or_default();
+                    }
+                    ancestor = node.parent();
+                }
+                self.group_prefixes.get(name).cloned().unwrap_or_default()
+            }
+            "call_expression" => {
+                let Some(function) = operand.child_by_field_name("function").filter(|f| f.kind() == "selector_expression")
+                else {
+                    return String::new();
+                };
+                let inner = function
+                    .child_by_field_name("operand")
+                    .map(|o| self.operand_prefix(o))
+                    .unwrap_or_default();
+                let field = function.child_by_field_name("field").map(|f| self.text(f)).unwrap_or_default();
+                let first = call_argument_nodes(operand)
+                    .first()
+                    .and_then(|a| go_string_literal_value(*a, self.source));
+                match (field, first) {
+                    ("Group" | "PathPrefix" | "Route", Some(path)) if path.starts_with('/') => {
+                        join_http_route_path(&inner, &path)
+                    }
+                    _ => inner,
+                }
+            }
+            _ => String::new(),
+        }
+    }
+
+    /// (method, full path, handlers) registered by one call
+    fn route_registrations(&self, call: tree_sitter::Node<'_>) -> Vec<(String, String, Vec<RouteHandlerTarget>)> {
+        let Some(function) = call.child_by_field_name("function").filter(|f| f.kind() == "selector_expression") else {
+            return Vec::new();
+        };
+        let (Some(operand), Some(field)) = (function.child_by_field_name("operand"), function.child_by_field_name("field"))
+        else {
+            return Vec::new();
+        };
+        // `http.Get(url)`, `http.Post(url, ...)` are client calls
+        if self.imports.import_path_of(self.text(operand)) == Some("net/http") {
+            return Vec::new();
+        }
+        let name = self.text(field);
+        let arguments = call_argument_nodes(call);
+        let string_at = |at: usize| arguments.get(at).and_then(|a| go_string_literal_value(*a, self.source));
+        let upper = name.to_uppercase();
+        let is_method_name = HTTP_METHOD_NAMES.contains(&upper.as_str());
+
+        let (methods, path) = match name {
+            _ if is_method_name && name == upper && arguments.len() >= 2 => (vec![upper.clone()], string_at(0)),
+            _ if is_method_name && arguments.len() == 2 && name.starts_with(char::is_uppercase) => {
+                (vec![upper.clone()], string_at(0))
+            }
+            "Any" if arguments.len() >= 2 => (vec![ANY_HTTP_METHOD.to_string()], string_at(0)),
+            "Handle" | "Add" | "Method" | "MethodFunc" if arguments.len() >= 3 => {
+                match go_http_method_value(arguments[0], self.source) {
+                    Some(method) => (vec![method], string_at(1)),
+                    None => return Vec::new(),
+                }
+            }
+            "Handle" | "HandleFunc" if arguments.len() == 2 => {
+                let Some(pattern) = string_at(0) else { return Vec::new() };
+                let (method, rest) = match pattern.split_once(' ') {
+                    Some((method, rest)) if HTTP_METHOD_NAMES.contains(&method) => (Some(method.to_string()), rest.trim_start()),
+                    _ => (None, pattern.as_str()),
+                };
+                // `example.com/users` patterns carry a host before the path
+                let path = rest.find('/').map(|at| rest[at..].to_string());
+                let mut methods: Vec<String> = method.into_iter().collect();
+                if methods.is_empty() {
+                    methods = go_chained_route_methods(call, self.source);
+                }
+                if methods.is_empty() {
+                    methods.push(ANY_HTTP_METHOD.to_string());
+                }
+                (methods, path)
+            }
+            "Mount" if arguments.len() == 2 => (
+                vec![ANY_HTTP_METHOD.to_string()],
+                string_at(0).map(|path| join_http_route_path(&path, "*")),
+            ),
+            _ => return Vec::new(),
+        };
+        let Some(path) = path.filter(|p| p.starts_with('/')) else {
+            return Vec::new();
+        };
+        let path = join_http_route_path(&self.operand_prefix(operand), &path);
+        let Some(handler) = arguments.last() else { return Vec::new() };
+
+        methods
+            .into_iter()
+            .map(|method| {
+                let mut targets = Vec::new();
+                self.handler_targets(*handler, "handler", &mut targets);
+                (method, path.clone(), targets)
+            })
+            .collect()
+    }
+
+    /// Edge targets for a handler expression
+    fn handler_targets(&self, expression: tree_sitter::Node<'_>, binding: &'static str, targets: &mut Vec<RouteHandlerTarget>) {
+        match expression.kind() {
+            "identifier" => {
+                let name = self.text(expression);
+                if !self.locals.contains(name) && name != "nil" {
+                    targets.push(RouteHandlerTarget::new(format!("go:fn:{}:unresolved-reference:0-0", name), binding));
+                }
+            }
+            "selector_expression" => {
+                let (Some(operand), Some(field)) =
+                    (expression.child_by_field_name("operand"), expression.child_by_field_name("field"))
+                else {
+                    return;
+                };
+                let qualifier = self.text(operand);
+                let field = self.text(field);
+                let import_path = self
+                    .imports
+                    .import_path_of(qualifier)
+                    .filter(|_| operand.kind() == "identifier" && !self.locals.contains(qualifier));
+                let target = match import_path {
+                    Some(path) => RouteHandlerTarget {
+                        to_key: format!("go:fn:{}.{}:unresolved-reference:0-0", path, field),
+                        binding,
+                        metadata: vec![(IMPORT_PATH_METADATA_KEY, path.to_string())],
+                    },
+                    None => RouteHandlerTarget {
+                        to_key: format!("go:fn:{}:unresolved-reference:0-0", field),
+                        binding,
+                        metadata: vec![(RECEIVER_EXPR_METADATA_KEY, qualifier.to_string())],
+                    },
+                };
+                targets.push(target);
+            }
+            "func_literal" => {
+                let span = format!("{}-{}", expression.start_byte(), expression.end_byte());
+                if let Some(closure) = self.entities.iter().find(|e| e.metadata.get(BYTE_SPAN_METADATA_KEY) == Some(&span)) {
+                    targets.push(RouteHandlerTarget::new(format_go_parsed_entity_key(closure), binding));
+                }
+            }
+            "parenthesized_expression" => {
+                if let Some(inner) = expression.named_child(0) {
+                    self.handler_targets(inner, binding, targets);
+                }
+            }
+            "call_expression" => {
+                let Some(function) = expression.child_by_field_name("function") else { return };
+                let arguments = call_argument_nodes(expression);
+                let callee = match function.kind() {
+                    "selector_expression" => function.child_by_field_name("field").map(|f| self.text(f)),
+                    _ => Some(self.text(function)),
+                };
+                match (callee, arguments.as_slice()) {
+                    (Some("HandlerFunc"), [inner]) | (Some("StripPrefix"), [_, inner]) => {
+                        self.handler_targets(*inner, binding, targets);
+                    }
+                    // `auth(h)`: middleware convention, the wrapped handler comes last
+                    (_, [.., wrapped]) => {
+                        self.handler_targets(function, "wrapper", targets);
+                        self.handler_targets(*wrapped, binding, targets);
+                    }
+                    (_, []) => self.handler_targets(function, binding, targets),
+                }
+            }
+            _ => {}
+        }
+    }
+}
+
+/// True when a func literal declares a parameter `name`
+fn go_func_literal_declares_parameter(literal: tree_sitter::Node<'_>, name: &str, source: &str) -> bool {
+    let Some(parameters) = literal.child_by_field_name("parameters") else {
+        return false;
+    };
+    let mut cursor = parameters.walk();
+    let declared = parameters.named_children(&mut cursor).any(|parameter| {
+        let mut name_cursor = parameter.walk();
+        let found = parameter
+            .children_by_field_name("name", &mut name_cursor)
+            .any(|n| &source[n.byte_range()] == name);
+        found
+    });
+    declared
+}
+
+/// gorilla: `r.HandleFunc("/users", h).Methods("GET", "POST")`
+fn go_chained_route_methods(call: tree_sitter::Node<'_>, source: &str) -> Vec<String> {
+    let mut methods = Vec::new();
+    let mut current = call;
+    while let Some(selector) = current.parent().filter(|p| p.kind() == "selector_expression") {
+        let Some(outer) = selector.parent().filter(|p| p.kind() == "call_expression") else { break };
+        if selector.child_by_field_name("field").map(|f| &source[f.byte_range()]) == Some("Methods") {
+            methods.extend(call_argument_nodes(outer).into_iter().filter_map(|a| go_http_method_value(a, source)));
+        }
+        current = outer;
+    }
+    methods
+}
+
+/// `"GET"` or `http.MethodGet` → `GET`
+fn go_http_method_value(node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    let method = match node.kind() {
+        "selector_expression" => source[node.child_by_field_name("field")?.byte_range()]
+            .strip_prefix("Method")?
+            .to_uppercase(),
+        _ => go_string_literal_value(node, source)?.to_uppercase(),
+    };
+    HTTP_METHOD_NAMES.contains(&method.as_str()).then_some(method)
+}
+
+fn go_string_literal_value(node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    match node.kind() {
+        "interpreted_string_literal" | "raw_string_literal" => {
+            Some(source[node.byte_range()].trim_matches(|c| c == '"' || c == '`').to_string())
+        }
+        _ => None,
+    }
+}
+
+fn rust_string_literal_value(node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    match node.kind() {
+        "string_literal" | "raw_string_literal" => Some(
+            source[node.byte_range()]
+                .trim_start_matches('r')
+                .trim_matches('#')
+                .trim_matches('"')
+                .to_string(),
+        ),
+        _ => None,
+    }
+}
+
+/// `#[get("/users/<id>")]`, `#[route("/", method = "GET", method = "HEAD")]`
+fn rust_attribute_route(item: tree_sitter::Node<'_>, source: &str) -> Option<(Vec<String>, String)> {
+    let attribute = item.named_child(0).filter(|a| a.kind() == "attribute")?;
+    let path_node = attribute.named_child(0)?;
+    let name = source[path_node.byte_range()].rsplit("::").next().unwrap_or_default().to_string();
+    let arguments = attribute.child_by_field_name("arguments").or_else(|| {
+        let mut cursor = attribute.walk();
+        let found = attribute.named_children(&mut cursor).find(|c| c.kind() == "token_tree");
+        found
+    })?;
+    let mut cursor = arguments.walk();
+    let tokens: Vec<tree_sitter::Node<'_>> = arguments.children(&mut cursor).collect();
+    let path = tokens.iter().find_map(|t| rust_string_literal_value(*t, source))?;
+    if !path.starts_with('/') {
+        return None;
+    }
+
+    let upper = name.to_uppercase();
+    let methods = if HTTP_METHOD_NAMES.contains(&upper.as_str()) {
+        vec![upper]
+    } else if name == "route" {
+        // actix `method = "GET"`, rocket `#[route(GET, uri = "/")]`
+        let mut methods: Vec<String> = Vec::new();
+        for (at, token) in tokens.iter().enumerate() {
+            let text = &source[token.byte_range()];
+            if token.kind() == "identifier" && HTTP_METHOD_NAMES.contains(&text) {
+                methods.push(text.to_string());
+            } else if text == "method" {
+                if let Some(value) = tokens.get(at + 2).and_then(|v| rust_string_literal_value(*v, source)) {
+                    methods.push(value.to_uppercase());
+                }
+            }
+        }
+        if methods.is_empty() {
+            methods.push(ANY_HTTP_METHOD.to_string());
+        }
+        methods
+    } else {
+        return None;
+    };
+    Some((methods, path))
+}
+
+/// (method, handler) pairs of `get(a).post(b)` or `web::get().to(a)`
+fn rust_method_router_handlers<'t>(router: tree_sitter::Node<'t>, source: &str) -> Vec<(String, tree_sitter::Node<'t>)> {
+    // Innermost call first: `get(a)` before `.post(b)`
+    let mut links = Vec::new();
+    let mut current = Some(router);
+    while let Some(call) = current.filter(|n| n.kind() == "call_expression") {
+        let Some(function) = call.child_by_field_name("function") else { break };
+        let argument = call_argument_nodes(call).first().copied();
+        if function.kind() == "field_expression" {
+            let field = function.child_by_field_name("field").map(|f| &source[f.byte_range()]).unwrap_or_default();
+            links.push((field.to_string(), argument));
+            current = function.child_by_field_name("value");
+        } else {
+            let path = match function.kind() {
+                "generic_function" => function.child_by_field_name("function").unwrap_or(function),
+                _ => function,
+            };
+            let name = source[path.byte_range()].rsplit("::").next().unwrap_or_default();
+            links.push((name.to_string(), argument));
+            current = None;
+        }
+    }
+    links.reverse();
+
+    let mut handlers = Vec::new();
+    let mut pending: Option<String> = None;
+    for (name, argument) in links {
+        let name = name.strip_suffix("_service").unwrap_or(&name);
+        let upper = name.to_uppercase();
+        if HTTP_METHOD_NAMES.contains(&upper.as_str()) || name == "any" {
+            let method = if name == "any" { ANY_HTTP_METHOD.to_string() } else { upper };
+            match argument {
+                Some(handler) => handlers.push((method, handler)),
+                None => pending = Some(method),
+            }
+        } else if name == "route" && argument.is_none() {
+            pending = Some(ANY_HTTP_METHOD.to_string());
+        } else if name == "method" {
+            // actix `web::method(Method::GET)`
+            pending = argument
+                .map(|a| source[a.byte_range()].rsplit("::").next().unwrap_or_default().to_uppercase())
+                .filter(|m| HTTP_METHOD_NAMES.contains(&m.as_str()));
+        } else if name == "to" {
+            if let Some(handler) = argument {
+                handlers.push((pending.take().unwrap_or_else(|| ANY_HTTP_METHOD.to_string()), handler));
+            }
+        }
+    }
+    handlers
+}
+
+/// Function name of a Rust handler expression (`list`, `users::list`, `list::<T>`)
+fn rust_handler_name<'s>(handler: tree_sitter::Node<'_>, source: &'s str) -> Option<&'s str> {
+    match handler.kind() {
+        "identifier" => Some(&source[handler.byte_range()]),
+        "scoped_identifier" => Some(&source[handler.child_by_field_name("name")?.byte_range()]),
+        "generic_function" => rust_handler_name(handler.child_by_field_name("function")?, source),
+        _ => None,
+    }
+}
+
+/// Same-file function entity named `name`, else a placeholder
+fn rust_handler_target_key(name: &str, entities: &[ParsedEntity]) -> String {
+    let entity = entities
+        .iter()
+        .find(|e| e.name == name && e.entity_type == EntityType::Function);
+    match entity {
+        Some(entity) => format!(
+            "rust:{}:{}:{}:T{}",
+            key_component_for_entity_type(&entity.entity_type),
+            entity.name,
+            extract_semantic_path(&entity.file_path),
+            compute_birth_timestamp(&entity.file_path, &entity.name)
+        ),
+        None => format!("rust:fn:{}:unresolved-reference:0-0", name),
+    }
+}
+
+/// String argument of the call named `name` in a receiver chain
+/// (`web::resource("/users").name("users")` → `/users` for `resource`)
+fn rust_receiver_chain_path(receiver: tree_sitter::Node<'_>, source: &str, name: &str) -> Option<String> {
+    let mut current = receiver;
+    loop {
+        if current.kind() != "call_expression" {
+            return None;
+        }
+        let function = current.child_by_field_name("function")?;
+        let call = current;
+        let first = move || {
+            call_argument_nodes(call)
+                .first()
+                .and_then(|a| rust_string_literal_value(*a, source))
+        };
+        if function.kind() == "field_expression" {
+            if function.child_by_field_name("field").map(|f| &source[f.byte_range()]) == Some(name) {
+                return first();
+            }
+            current = function.child_by_field_name("value")?;
+        } else {
+            let last = source[function.byte_range()].rsplit("::").next().unwrap_or_default();
+            return if last == name { first() } else { None };
+        }
+    }
+}
+
+/// `.nest("/api", ...)` around a route, `web::scope("/api")` under it or around it
+fn rust_route_prefix(call: tree_sitter::Node<'_>, source: &str) -> String {
+    let receiver_scope = |node: tree_sitter::Node<'_>| {
+        node.child_by_field_name("function")
+            .filter(|f| f.kind() == "field_expression")
+            .and_then(|f| f.child_by_field_name("value"))
+            .and_then(|value| rust_receiver_chain_path(value, source, "scope"))
+    };
+    // Innermost first
+    let mut prefixes: Vec<String> = receiver_scope(call).into_iter().collect();
+    let mut child = call;
+    while let Some(parent) = child.parent() {
+        if parent.kind() == "arguments" {
+            if let Some(outer) = parent.parent().filter(|p| p.kind() == "call_expression") {
+                let arguments = call_argument_nodes(outer);
+                let is_nest = outer
+                    .child_by_field_name("function")
+                    .filter(|f| f.kind() == "field_expression")
+                    .and_then(|f| f.child_by_field_name("field"))
+                    .is_some_and(|f| &source[f.byte_range()] == "nest");
+                if is_nest && arguments.first().map(|a| a.id()) != Some(child.id()) {
+                    prefixes.extend(arguments.first().and_then(|a| rust_string_literal_value(*a, source)));
+                }
+                prefixes.extend(receiver_scope(outer));
+            }
+        }
+        child = parent;
+    }
+    prefixes.iter().rev().fold(String::new(), |prefix, segment| join_http_route_path(&prefix, segment))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType as GraphEntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    fn route_edges<'e>(edges: &'e [DependencyEdge], route: &str) -> Vec<&'e str> {
+        edges
+            .iter()
+            .filter(|e| e.from_key.as_str().split(':').nth(2) == Some(route))
+            .map(|e| e.to_key.as_str())
+            .collect()
+    }
+
+    #[test]
+    fn test_router_registrations_become_route_nodes() {
+        let go = r#"
+package api
+
+import (
+    "net/http"
+
+    "github.com/go-chi/chi/v5"
+    "example.com/app/users"
+)
+
+func (s *Server) Routes() http.Handler {
+    r := chi.NewRouter()
+    r.Get("/health", health)
+    r.Route("/api", func(r chi.Router) {
+        r.Post("/users", s.createUser)
+        r.Get("/users/{id:[0-9]+}", users.Show)
+        r.Method(http.MethodDelete, "/users/{id}", auth(http.HandlerFunc(s.deleteUser)))
+    })
+    r.HandleFunc("PUT /items/{name...}", func(w http.ResponseWriter, r *http.Request) {})
+    http.Get("/not-a-route")
+    return r
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(go, Path::new("api/routes.go"), Language::Go).unwrap();
+        let mut routes: Vec<&str> = entities
+            .iter()
+            .filter(|e| e.entity_type == EntityType::Route)
+            .map(|e| e.name.as_str())
+            .collect();
+        routes.sort();
+        assert_eq!(
+            routes,
+            vec!["DELETE /api/users/{id}", "GET /api/users/{id}", "GET /health", "POST /api/users", "PUT /items/*"]
+        );
+        let create = entities.iter().find(|e| e.name == "POST /api/users").unwrap();
+        assert_eq!(create.metadata.get("http_framework").map(String::as_str), Some("chi"));
+        assert_eq!(create.metadata.get("receiver_name").map(String::as_str), Some("s"));
+        assert_eq!(route_edges(&edges, "GET_/health"), vec!["go:fn:health:unresolved-reference:0-0"]);
+        assert_eq!(
+            route_edges(&edges, "GET_/api/users/__lc__id__rc__"),
+            vec!["go:fn:example.com/app/users.Show:unresolved-reference:0-0"]
+        );
+        let delete = route_edges(&edges, "DELETE_/api/users/__lc__id__rc__");
+        assert_eq!(delete, vec!["go:fn:auth:unresolved-reference:0-0", "go:fn:deleteUser:unresolved-reference:0-0"]);
+        assert!(route_edges(&edges, "PUT_/items/*")[0].contains(":Server.Routes.func2:"));
+
+        let rust = r#"
+use axum::{routing::get, Router};
+
+#[get("/legacy/<id>")]
+async fn legacy(id: u32) {}
+
+fn app() -> Router {
+    Router::new().nest("/v1", Router::new().route("/users", get(list_users).post(handlers::create_user)))
+}
+"#;
+        let (rust_entities, rust_edges) = extractor.parse_source(rust, Path::new("src/app.rs"), Language::Rust).unwrap();
+        let mut rust_routes: Vec<&str> = rust_entities
+            .iter()
+            .filter(|e| e.entity_type == EntityType::Route)
+            .map(|e| e.name.as_str())
+            .collect();
+        rust_routes.sort();
+        assert_eq!(rust_routes, vec!["GET /legacy/{id}", "GET /v1/users", "POST /v1/users"]);
+        assert!(route_edges(&rust_edges, "GET_/legacy/__lc__id__rc__")[0].starts_with("rust:fn:legacy:__src_app:T"));
+        assert_eq!(
+            route_edges(&rust_edges, "POST_/v1/users"),
+            vec!["rust:fn:create_user:unresolved-reference:0-0"]
+        );
+
+        // Stored graph: bare handler binds in the route's package, then URL lookup
+        let route_key = "go:route:GET_/users/__lc__id__rc__:__api_routes:T1";
+        let mut graph = vec![
+            create_go_test_entity(route_key, "GET /users/{id}", GraphEntityType::Route, "api/routes.go",
+                &[("http_method", "GET"), ("http_path", "/users/{id}"), ("http_framework", "chi")]),
+            create_go_test_entity("go:route:GET_/users/me:__api_routes:T2", "GET /users/me", GraphEntityType::Route,
+                "api/routes.go", &[("http_method", "GET"), ("http_path", "/users/me"), ("http_framework", "chi")]),
+            create_go_test_entity("go:fn:showUser:__api_users:T3", "showUser", GraphEntityType::Function, "api/users.go", &[]),
+            create_go_test_entity("go:fn:showUser:__billing:T4", "showUser", GraphEntityType::Function, "billing/users.go", &[]),
+        ];
+        graph[2].current_code = Some("func showUser(w http.ResponseWriter, r *http.Request) {}".to_string());
+        let mut graph_edges = vec![DependencyEdge::builder()
+            .from_key(route_key)
+            .to_key("go:fn:showUser:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .metadata_entry("route_binding", "handler")
+            .build()
+            .unwrap()];
+        assert_eq!(resolve_http_route_handler_edges(&graph, &mut graph_edges), 1);
+        assert_eq!(graph_edges[0].to_key.as_str(), "go:fn:showUser:__api_users:T3");
+
+        let found = match_http_route_entries(&graph, &graph_edges, Some("https://example.com/users/42?x=1"), Some("get"));
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0].params.get("id").map(String::as_str), Some("42"));
+        assert!(found[0].handlers[0].code.as_deref().unwrap().starts_with("func showUser"));
+        let me = match_http_route_entries(&graph, &graph_edges, Some("/users/me"), None);
+        assert_eq!(me.iter().map(|m| m.path.as_str()).collect::<Vec<_>>(), vec!["/users/me", "/users/{id}"]);
+        assert!(match_http_route_entries(&graph, &graph_edges, Some("/users/42"), Some("POST")).is_empty());
+        assert_eq!(normalize_http_route_path("/files/<path..>/:rev/{$}"), "/files/*/{rev}/");
+    }
+    fn route_names(entities: &[ParsedEntity]) -> Vec<&str> {
+        let mut names: Vec<&str> =
+            entities.iter().filter(|e| e.entity_type == EntityType::Route).map(|e| e.name.as_str()).collect();
+        names.sort();
+        names
+    }
+
+    fn parse_go_routes(source: &str) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        extractor.parse_source(source, Path::new("api/routes.go"), Language::Go).unwrap()
+    }
+
+    #[test]
+    fn test_gin_groups_any_and_handle() {
+        let (entities, edges) = parse_go_routes(
+            r#"
+package api
+
+import "github.com/gin-gonic/gin"
+
+func Register(r *gin.Engine) {
+    v1 := r.Group("/v1")
+    v1.GET("/users/:id", showUser)
+    v1.Any("/ping", ping)
+    r.Handle("PATCH", "/items/*path", patchItem)
+}
+"#,
+        );
+        assert_eq!(route_names(&entities), vec!["ANY /v1/ping", "GET /v1/users/{id}", "PATCH /items/*"]);
+        let show = entities.iter().find(|e| e.name == "GET /v1/users/{id}").unwrap();
+        assert_eq!(show.metadata.get(HTTP_FRAMEWORK_METADATA_KEY).map(String::as_str), Some("gin"));
+        let show_edges = route_edges(&edges, "GET_/v1/users/__lc__id__rc__");
+        assert_eq!(show_edges, vec!["go:fn:showUser:unresolved-reference:0-0"]);
+        assert_eq!(route_edges(&edges, "PATCH_/items/*"), vec!["go:fn:patchItem:unresolved-reference:0-0"]);
+    }
+
+    #[test]
+    fn test_echo_add_and_middleware_wrapper() {
+        let (entities, edges) = parse_go_routes(
+            r#"
+package api
+
+import "github.com/labstack/echo/v4"
+
+func (h *Handler) Register(e *echo.Echo) {
+    admin := e.Group("/admin")
+    admin.Add("DELETE", "/users/:id", h.deleteUser)
+    e.POST("/login", auth(login))
+}
+"#,
+        );
+        assert_eq!(route_names(&entities), vec!["DELETE /admin/users/{id}", "POST /login"]);
+        let delete = edges.iter().find(|e| e.from_key.as_str().contains(":DELETE_/admin/")).unwrap();
+        assert_eq!(delete.to_key.as_str(), "go:fn:deleteUser:unresolved-reference:0-0");
+        assert_eq!(delete.metadata_value(RECEIVER_EXPR_METADATA_KEY), Some("h"));
+        let login: Vec<(&str, Option<&str>)> = edges
+            .iter()
+            .filter(|e| e.from_key.as_str().contains(":POST_/login:"))
+            .map(|e| (e.to_key.as_str(), e.metadata_value(ROUTE_BINDING_METADATA_KEY)))
+            .collect();
+        assert_eq!(
+            login,
+            vec![
+                ("go:fn:auth:unresolved-reference:0-0", Some("wrapper")),
+                ("go:fn:login:unresolved-reference:0-0", Some("handler")),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_gorilla_subrouter_and_chained_methods() {
+        let (entities, edges) = parse_go_routes(
+            r#"
+package api
+
+import "github.com/gorilla/mux"
+
+func Register() *mux.Router {
+    r := mux.NewRouter()
+    api := r.PathPrefix("/api").Subrouter()
+    api.HandleFunc("/orders", listOrders).Methods("GET", "HEAD")
+    r.HandleFunc("/health", health)
+    return r
+}
+"#,
+        );
+        assert_eq!(route_names(&entities), vec!["ANY /health", "GET /api/orders", "HEAD /api/orders"]);
+        assert_eq!(route_edges(&edges, "HEAD_/api/orders"), vec!["go:fn:listOrders:unresolved-reference:0-0"]);
+        let health = entities.iter().find(|e| e.name == "ANY /health").unwrap();
+        assert_eq!(health.metadata.get(HTTP_METHOD_METADATA_KEY).map(String::as_str), Some(ANY_HTTP_METHOD));
+        assert_eq!(health.metadata.get(HTTP_FRAMEWORK_METADATA_KEY).map(String::as_str), Some("gorilla"));
+    }
+
+    #[test]
+    fn test_net_http_patterns_hosts_and_strip_prefix() {
+        let (entities, edges) = parse_go_routes(
+            r#"
+package main
+
+import "net/http"
+
+func main() {
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /users/{id}", showUser)
+    mux.HandleFunc("example.com/about", about)
+    mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
+    http.ListenAndServe(":8080", mux)
+}
+"#,
+        );
+        assert_eq!(route_names(&entities), vec!["ANY /about", "ANY /static/", "GET /users/{id}"]);
+        let users = entities.iter().find(|e| e.name == "GET /users/{id}").unwrap();
+        assert_eq!(users.metadata.get(HTTP_FRAMEWORK_METADATA_KEY).map(String::as_str), Some("net/http"));
+        assert_eq!(route_edges(&edges, "ANY_/static/"), vec!["go:fn:fileServer:unresolved-reference:0-0"]);
+    }
+
+    #[test]
+    fn test_actix_scopes_resources_and_rocket_attributes() {
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let actix = r#"
+use actix_web::{get, web};
+
+#[get("/users/{id}")]
+async fn show_user() {}
+
+fn config(cfg: &mut web::ServiceConfig) {
+    cfg.service(web::scope("/admin").route("/stats", web::get().to(stats)));
+    cfg.service(web::resource("/orders").route(web::post().to(orders::create)));
+}
+"#;
+        let (entities, edges) = extractor.parse_source(actix, Path::new("src/routes.rs"), Language::Rust).unwrap();
+        assert_eq!(route_names(&entities), vec!["GET /admin/stats", "GET /users/{id}", "POST /orders"]);
+        assert!(route_edges(&edges, "GET_/users/__lc__id__rc__")[0].starts_with("rust:fn:show_user:__src_routes:T"));
+        assert_eq!(route_edges(&edges, "GET_/admin/stats"), vec!["rust:fn:stats:unresolved-reference:0-0"]);
+        assert_eq!(route_edges(&edges, "POST_/orders"), vec!["rust:fn:create:unresolved-reference:0-0"]);
+        let stats = entities.iter().find(|e| e.name == "GET /admin/stats").unwrap();
+        assert_eq!(stats.metadata.get(HTTP_FRAMEWORK_METADATA_KEY).map(String::as_str), Some("actix-web"));
+
+        let rocket = r#"
+#[macro_use] extern crate rocket;
+
+#[get("/hello/<name>")]
+fn hello(name: &str) -> String { name.to_string() }
+
+#[route(PUT, uri = "/things/<id..>")]
+fn replace_things() {}
+"#;
+        let (entities, edges) = extractor.parse_source(rocket, Path::new("src/main.rs"), Language::Rust).unwrap();
+        assert_eq!(route_names(&entities), vec!["GET /hello/{name}", "PUT /things/*"]);
+        assert!(route_edges(&edges, "PUT_/things/*")[0].starts_with("rust:fn:replace_things:__src_main:T"));
+    }
+
+    #[test]
+    fn test_unrouted_code_yields_no_routes() {
+        let (entities, edges) = parse_go_routes(
+            r#"
+package api
+
+func Register(r Router) {
+    r.GET("/users", listUsers)
+}
+"#,
+        );
+        assert!(route_names(&entities).is_empty() && edges.iter().all(|e| !e.from_key.as_str().contains(":route:")));
+
+        // Non-literal and relative paths, and too few arguments, register nothing
+        let (entities, _) = parse_go_routes(
+            r#"
+package api
+
+import "github.com/go-chi/chi/v5"
+
+func Register(r chi.Router, prefix string) {
+    r.Get(prefix+"/users", listUsers)
+    r.HandleFunc("users", listUsers)
+    r.GET("/only-a-path")
+}
+"#,
+        );
+        assert!(route_names(&entities).is_empty());
+
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let plain = "fn app(router: Router) { router.route(\"/users\", get(list)); }\n";
+        let (entities, _) = extractor.parse_source(plain, Path::new("src/app.rs"), Language::Rust).unwrap();
+        assert!(route_names(&entities).is_empty());
+
+        // Two same-name functions, neither near the route: the placeholder stays
+        let route_key = "go:route:GET_/users:__api_routes:T1";
+        let graph = vec![
+            create_go_test_entity(route_key, "GET /users", GraphEntityType::Route, "api/routes.go",
+                &[("http_method", "GET"), ("http_path", "/users"), ("http_framework", "chi")]),
+            create_go_test_entity("go:fn:listUsers:__billing:T2", "listUsers", GraphEntityType::Function,
+                "billing/users.go", &[]),
+            create_go_test_entity("go:fn:listUsers:__admin:T3", "listUsers", GraphEntityType::Function,
+                "admin/users.go", &[]),
+        ];
+        let mut graph_edges = vec![DependencyEdge::builder()
+            .from_key(route_key)
+            .to_key("go:fn:listUsers:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .metadata_entry("route_binding", "handler")
+            .build()
+            .unwrap()];
+        assert_eq!(resolve_http_route_handler_edges(&graph, &mut graph_edges), 0);
+        assert_eq!(graph_edges[0].to_key.as_str(), "go:fn:listUsers:unresolved-reference:0-0");
+    }
+}
@@ -0,0 +1,190 @@
+//! Inline Suppression Directive Parser (v1.7.3)
+//!
+//! # 4-Word Naming: inline_suppression_directive_parser
+//!
+//! Source comments that tell the analyses what static edges cannot:
+//!
+//! ```text
+//! //companion:ignore generated by protoc
+//! func (x *User) Reset() { ... }
+//!
+//! func HandleWebhook(w http.ResponseWriter, r *http.Request) { ... } //companion:entrypoint registered by the router
+//! ```
+//!
+//! - `companion:ignore [reason]`: never report the symbol (dead code)
+//! - `companion:entrypoint [reason]`: the symbol is reached from outside the
+//!   graph (router tables, cron, RPC frameworks, `//export`); it is a root,
+//!   not dead code
+//!
+//! A directive belongs to the declaration it sits above (in the comment and
+//! attribute lines directly above it, as doc comments do) or ends the
+//! declaration line as a trailing comment. Any line comment marker of the
+//! language works (`//`, `#`, `--`), with or without a space before
+//! `companion:`. Directive lines are not part of the doc comment.
+//!
+//! pt01 stores them on the node: `directives` (comma-separated, source
+//! order) and `directive_reason` (the first reason given).
+
+use crate::entities::{CodeEntity, Language};
+use crate::symbol_doc_signature_extractor::{is_attribute_annotation_line, strip_line_comment_marker};
+
+/// Entity metadata key: directive names, comma-separated (`ignore,entrypoint`)
+pub const DIRECTIVES_METADATA_KEY: &str = "directives";
+
+/// Entity metadata key: free text after the first directive that has one
+pub const DIRECTIVE_REASON_METADATA_KEY: &str = "directive_reason";
+
+const COMPANION_DIRECTIVE_PREFIX: &str = "companion:";
+
+/// A recognised `companion:` directive
+///
+/// # 4-Word Name: InlineCompanionDirectiveKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum InlineCompanionDirectiveKind {
+    Ignore,
+    Entrypoint,
+}
+
+impl InlineCompanionDirectiveKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Ignore => "ignore",
+            Self::Entrypoint => "entrypoint",
+        }
+    }
+}
+
+/// Directive and reason of one comment's text (marker already stripped)
+///
+/// # Contract
+/// - Postcondition: None for ordinary comments and unknown directive names
+pub fn parse_companion_directive_text(text: &str) -> Option<(InlineCompanionDirectiveKind, Option<String>)> {
+    let rest = text.trim_start().strip_prefix(COMPANION_DIRECTIVE_PREFIX)?;
+    let (name, reason) = rest.split_once(char::is_whitespace).unwrap_or((rest, ""));
+    let kind = match name {
+        "ignore" => InlineCompanionDirectiveKind::Ignore,
+        "entrypoint" => InlineCompanionDirectiveKind::Entrypoint,
+        _ => return None,
+    };
+    let reason = reason.trim();
+    Some((kind, (!reason.is_empty()).then(|| reason.to_string())))
+}
+
+/// True for comment text starting with `companion:` (kept out of doc comments)
+pub fn is_companion_directive_text(text: &str) -> bool {
+    text.trim_start().starts_with(COMPANION_DIRECTIVE_PREFIX)
+}
+
+/// Node metadata for the directives of the symbol declared at `start_line` (1-based)
+///
+/// # 4-Word Name: extract_inline_directive_metadata
+///
+/// # Contract
+/// - Precondition: `source` is the whole file
+/// - Postcondition: empty when the symbol has no directive, else the
+///   `directives` entry and, when a reason was given, `directive_reason`
+pub fn extract_inline_directive_metadata(
+    source: &str,
+    start_line: usize,
+    language: Language,
+) -> Vec<(String, String)> {
+    let lines: Vec<&str> = source.lines().collect();
+    if start_line == 0 || start_line > lines.len() {
+        return Vec::new();
+    }
+
+    let mut found = Vec::new();
+    for line in lines[..start_line - 1].iter().rev() {
+        let trimmed = line.trim();
+        if trimmed.is_empty() {
+            break;
+        }
+        if is_attribute_annotation_line(trimmed, language) {
+            continue;
+        }
+        match strip_line_comment_marker(trimmed, language) {
+            Some(text) => found.extend(parse_companion_directive_text(&text)),
+            None => break,
+        }
+    }
+    found.reverse();
+    found.extend(trailing_directive_of_line(lines[start_line - 1], language));
+
+    let mut names: Vec<&str> = Vec::new();
+    for (kind, _) in &found {
+        if !names.contains(&kind.as_str()) {
+            names.push(kind.as_str());
+        }
+    }
+    let mut metadata = Vec::new();
+    if !names.is_empty() {
+        metadata.push((DIRECTIVES_METADATA_KEY.to_string(), names.join(",")));
+    }
+    if let Some(reason) = found.into_iter().find_map(|(_, reason)| reason) {
+        metadata.push((DIRECTIVE_REASON_METADATA_KEY.to_string(), reason));
+    }
+    metadata
+}
+
+/// True when the entity carries `kind` in its `directives` metadata
+///
+/// # 4-Word Name: has_inline_companion_directive
+pub fn has_inline_companion_directive(entity: &CodeEntity, kind: InlineCompanionDirectiveKind) -> bool {
+    entity
+        .metadata
+        .additional
+        .get(DIRECTIVES_METADATA_KEY)
+        .is_some_and(|names| names.split(',').any(|name| name.trim() == kind.as_str()))
+}
+
+/// `code //companion:entrypoint` on the declaration line itself
+fn trailing_directive_of_line(line: &str, language: Language) -> Option<(InlineCompanionDirectiveKind, Option<String>)> {
+    let markers: &[&str] = match language {
+        Language::Python | Language::Ruby => &["#"],
+        Language::Sql => &["--"],
+        Language::Php => &["//", "#"],
+        _ => &["//"],
+    };
+    markers.iter().find_map(|marker| {
+        line.match_indices(marker)
+            .find_map(|(at, _)| parse_companion_directive_text(&line[at + marker.len()..]))
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::dead_code_detection_analyzer::find_unreferenced_dead_code_entities;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_directives_above_and_trailing() {
+        let go = "package api\n\n// Reset clears the message.\n//companion:ignore generated by protoc\nfunc (x *User) Reset() {}\n\nfunc HandleWebhook() {} // companion:entrypoint\n\n// url http://example.com\nfunc plain() {}\n";
+        assert_eq!(
+            extract_inline_directive_metadata(go, 5, Language::Go),
+            vec![
+                ("directives".to_string(), "ignore".to_string()),
+                ("directive_reason".to_string(), "generated by protoc".to_string()),
+            ]
+        );
+        assert_eq!(
+            extract_inline_directive_metadata(go, 7, Language::Go),
+            vec![("directives".to_string(), "entrypoint".to_string())]
+        );
+        assert!(extract_inline_directive_metadata(go, 10, Language::Go).is_empty());
+
+        let python = "#companion:entrypoint celery task\n@app.task\ndef sync():\n    pass\n";
+        assert_eq!(extract_inline_directive_metadata(python, 3, Language::Python)[0].1, "entrypoint");
+        assert!(parse_companion_directive_text("companion:unknown").is_none());
+
+        let entities = vec![
+            create_go_test_entity("go:fn:Reset:__api:T1", "Reset", EntityType::Function, "api/user.go", &[("directives", "ignore")]),
+            create_go_test_entity("go:fn:HandleWebhook:__api:T2", "HandleWebhook", EntityType::Function, "api/hook.go", &[("directives", "entrypoint")]),
+            create_go_test_entity("go:fn:plain:__api:T3", "plain", EntityType::Function, "api/hook.go", &[]),
+        ];
+        assert!(has_inline_companion_directive(&entities[1], InlineCompanionDirectiveKind::Entrypoint));
+        let dead = find_unreferenced_dead_code_entities(&entities, &[]);
+        assert_eq!(dead.iter().map(|d| d.name.as_str()).collect::<Vec<_>>(), vec!["plain"]);
+    }
+}
@@ -0,0 +1,166 @@
+//! Interface implementation fan-out for context packs (v1.7.3)
+//!
+//! # 4-Word Naming: interface_impl_pack_expander
+//!
+//! A packed function that calls `store.Save(u)` through an interface shows
+//! the LLM only the call; what `Save` does lives in the implementations.
+//! `dynamic_dispatch_call_expander` already links such calls to every
+//! implementer method (`dispatch: dynamic` edges). With `--expand-impls N`
+//! the packer takes those edges out of the relevance walk and instead adds,
+//! for each interface method a ranked entity calls, at most N of its
+//! implementations, ranked as that entity's callees (relation
+//! `implementation`).
+//!
+//! ## Choosing the N
+//!
+//! Implementations named like test doubles (`Mock`, `Fake`, `Stub` in the
+//! key) come last; the rest in key order, so the choice is deterministic.
+//! Expansion is one level deep: interface calls made by an added
+//! implementation are not expanded again.
+
+use std::collections::{BTreeMap, HashSet};
+
+use crate::dynamic_dispatch_call_expander::{
+    DISPATCH_DYNAMIC_METADATA_VALUE, DISPATCH_METADATA_KEY, RECEIVER_STATIC_TYPE_METADATA_KEY,
+};
+use crate::entities::DependencyEdge;
+use crate::token_budget_context_packer::{RankedNeighborEntityEntry, DIRECT_CALLEE_RELEVANCE_SCORE};
+
+/// Relation of an implementation added by `--expand-impls`
+pub const INTERFACE_IMPLEMENTATION_RELATION: &str = "implementation";
+
+/// Separate fanned-out interface calls from the other edges
+///
+/// # 4-Word Name: split_dynamic_dispatch_edges
+///
+/// # Contract
+/// - Postcondition: `(static, dynamic)`; an edge is dynamic when it carries
+///   `dispatch: dynamic`
+pub fn split_dynamic_dispatch_edges(edges: Vec<DependencyEdge>) -> (Vec<DependencyEdge>, Vec<DependencyEdge>) {
+    edges
+        .into_iter()
+        .partition(|edge| edge.metadata_value(DISPATCH_METADATA_KEY) != Some(DISPATCH_DYNAMIC_METADATA_VALUE))
+}
+
+/// Add up to `limit` implementations per interface method a ranked entity calls
+///
+/// # 4-Word Name: expand_interface_method_implementations
+///
+/// # Contract
+/// - Precondition: `dynamic_edges` from `split_dynamic_dispatch_edges`
+/// - Postcondition: every added key is new to `ranked`, relation
+///   `implementation`, one hop deeper than its caller and scored as a callee
+///   at that depth; keys already ranked keep their entry
+/// - Postcondition: sorted by (score desc, key asc) again; `limit == 0`
+///   adds nothing
+pub fn expand_interface_method_implementations(
+    ranked: &mut Vec<RankedNeighborEntityEntry>,
+    dynamic_edges: &[DependencyEdge],
+    limit: usize,
+) {
+    // Caller → (interface type, method) → implementation keys
+    let mut fan_out: BTreeMap<&str, BTreeMap<(&str, &str), Vec<&str>>> = BTreeMap::new();
+    for edge in dynamic_edges {
+        let interface = edge.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY).unwrap_or_default();
+        let method = edge.to_key.as_str().split(':').nth(2).unwrap_or_default();
+        fan_out
+            .entry(edge.from_key.as_str())
+            .or_default()
+            .entry((interface, method))
+            .or_default()
+            .push(edge.to_key.as_str());
+    }
+
+    let mut known: HashSet<String> = ranked.iter().map(|entry| entry.entity_key.clone()).collect();
+    let mut added = Vec::new();
+    for entry in ranked.iter() {
+        let Some(calls) = fan_out.get(entry.entity_key.as_str()) else {
+            continue;
+        };
+        let depth = entry.depth + 1;
+        let score = match depth {
+            1 => DIRECT_CALLEE_RELEVANCE_SCORE,
+            d => (0.7 - 0.1 * (d - 2) as f64).max(0.1),
+        };
+        for implementations in calls.values() {
+            let mut implementations = implementations.clone();
+            implementations.sort_by_key(|key| (is_test_double_key(key), *key));
+            implementations.dedup();
+            for key in implementations.into_iter().take(limit) {
+                if known.insert(key.to_string()) {
+                    added.push(RankedNeighborEntityEntry {
+                        entity_key: key.to_string(),
+                        relation: INTERFACE_IMPLEMENTATION_RELATION.to_string(),
+                        depth,
+                        relevance_score: score,
+                    });
+                }
+            }
+        }
+    }
+
+    ranked.extend(added);
+    ranked.sort_by(|a, b| {
+        b.relevance_score
+            .partial_cmp(&a.relevance_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+}
+
+/// Whether a key names a mock, fake or stub
+fn is_test_double_key(key: &str) -> bool {
+    let lower = key.to_ascii_lowercase();
+    ["mock", "fake", "stub"].iter().any(|marker| lower.contains(marker))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+    use crate::token_budget_context_packer::rank_neighbors_by_relevance;
+
+    fn call(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    fn dynamic_call(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .metadata_entry(DISPATCH_METADATA_KEY, DISPATCH_DYNAMIC_METADATA_VALUE)
+            .metadata_entry(RECEIVER_STATIC_TYPE_METADATA_KEY, "UserStore")
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_expansion_caps_implementations_per_method() {
+        let register = "go:method:Register:__svc:T1";
+        let edges = vec![
+            call("go:fn:main:__cmd:T9", register),
+            call(register, "go:fn:Save:unresolved-reference:0-0"),
+            dynamic_call(register, "go:method:Save:__svc_pg:T3"),
+            dynamic_call(register, "go:method:Save:__svc_mock:T4"),
+            dynamic_call(register, "go:method:Save:__svc_mem:T5"),
+        ];
+        let (static_edges, dynamic_edges) = split_dynamic_dispatch_edges(edges);
+        assert_eq!((static_edges.len(), dynamic_edges.len()), (2, 3));
+
+        let mut ranked = rank_neighbors_by_relevance(register, &static_edges, 2);
+        expand_interface_method_implementations(&mut ranked, &dynamic_edges, 2);
+        let implementations: Vec<(&str, usize)> = ranked
+            .iter()
+            .filter(|entry| entry.relation == INTERFACE_IMPLEMENTATION_RELATION)
+            .map(|entry| (entry.entity_key.as_str(), entry.depth))
+            .collect();
+        // The mock sorts last and falls outside the cap
+        assert_eq!(implementations, vec![("go:method:Save:__svc_mem:T5", 1), ("go:method:Save:__svc_pg:T3", 1)]);
+        assert_eq!(ranked[0].entity_key, register);
+
+        let mut unexpanded = rank_neighbors_by_relevance(register, &static_edges, 2);
+        expand_interface_method_implementations(&mut unexpanded, &dynamic_edges, 0);
+        assert_eq!(unexpanded.len(), 3);
+    }
+}
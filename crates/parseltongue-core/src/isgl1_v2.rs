@@ -8,8 +8,6 @@
 
 use crate::entities::EntityType;
 use sha2::{Digest, Sha256};
-use std::collections::hash_map::DefaultHasher;
-use std::hash::{Hash, Hasher};
 
 /// Sanitize entity name for ISGL1 v2.1 key compatibility
 ///
@@ -109,6 +107,7 @@ pub fn format_key_v2(
         EntityType::Constant => "const",
         EntityType::Table => "table",    // v1.5.6: SQL table
         EntityType::View => "view",      // v1.5.6: SQL view
+        EntityType::Route => "route",    // v1.7.3: HTTP route
     };
 
     format!("{}:{}:{}:{}:T{}", language, type_str, name, semantic_path, birth_timestamp)
@@ -125,6 +124,10 @@ pub fn format_key_v2(
 /// assert_eq!(extract_semantic_path("crates/core/lib.py"), "__crates_core_lib");
 /// ```
 pub fn extract_semantic_path(file_path: &str) -> String {
+    // v1.7.3: `./src/a.rs` and `src\a.rs` name the same file as `src/a.rs`
+    let file_path = canonical_key_file_path(file_path);
+    let file_path = file_path.as_str();
+
     // Remove file extension
     let without_ext = if let Some(pos) = file_path.rfind('.') {
         &file_path[..pos]
@@ -145,6 +148,11 @@ pub fn extract_semantic_path(file_path: &str) -> String {
 /// Uses deterministic hash of file_path + entity_name to generate
 /// a stable timestamp. Same entity = same timestamp across runs.
 ///
+/// v1.7.3: The hash is SHA-256 (previously `DefaultHasher`, whose algorithm
+/// may change between Rust releases), and the path is canonicalized first,
+/// so keys do not depend on the toolchain, the `./` spelling of the indexed
+/// directory or the path separator.
+///
 /// # Arguments
 /// * `file_path` - File containing the entity
 /// * `entity_name` - Name of the entity
@@ -158,13 +166,30 @@ pub fn extract_semantic_path(file_path: &str) -> String {
 /// let ts1 = compute_birth_timestamp("src/main.rs", "main");
 /// let ts2 = compute_birth_timestamp("src/main.rs", "main");
 /// assert_eq!(ts1, ts2); // Deterministic
+/// assert_eq!(ts1, compute_birth_timestamp("./src/main.rs", "main"));
 /// ```
 pub fn compute_birth_timestamp(file_path: &str, entity_name: &str) -> i64 {
-    // Create deterministic hash from file + entity name
-    let mut hasher = DefaultHasher::new();
-    file_path.hash(&mut hasher);
-    entity_name.hash(&mut hasher);
-    let hash = hasher.finish();
+    let file_path = canonical_key_file_path(file_path);
+    stable_birth_timestamp_from_parts(&[file_path.as_str(), entity_name])
+}
+
+/// Map a sequence of strings to a birth timestamp
+///
+/// # 4-Word Name: stable_birth_timestamp_from_parts
+///
+/// # Contract
+/// - Postcondition: a pure function of `parts` (NUL-separated SHA-256), in
+///   the 2020-2030 range `compute_birth_timestamp` has always produced
+pub fn stable_birth_timestamp_from_parts(parts: &[&str]) -> i64 {
+    let mut hasher = Sha256::new();
+    for part in parts {
+        hasher.update(part.as_bytes());
+        hasher.update([0u8]);
+    }
+    let digest = hasher.finalize();
+    let mut prefix = [0u8; 8];
+    prefix.copy_from_slice(&digest[..8]);
+    let hash = u64::from_be_bytes(prefix);
 
     // Convert hash to reasonable timestamp range
     // Use modulo to keep it within recent years (2020-2030 range)
@@ -175,6 +200,17 @@ pub fn compute_birth_timestamp(file_path: &str, entity_name: &str) -> i64 {
     base_timestamp + offset
 }
 
+/// Canonical spelling of a file path inside entity keys
+///
+/// Forward slashes, no leading `./` segments.
+fn canonical_key_file_path(file_path: &str) -> String {
+    let mut path = file_path.replace('\\', "/");
+    while let Some(rest) = path.strip_prefix("./") {
+        path = rest.to_string();
+    }
+    path
+}
+
 /// Compute SHA-256 content hash for entity code
 ///
 /// Returns hex-encoded SHA-256 hash (64 characters) for change detection.
@@ -362,4 +398,12 @@ mod tests {
         let ts2 = compute_birth_timestamp("test.rs", "func");
         assert_eq!(ts1, ts2);
     }
+
+    #[test]
+    fn test_birth_timestamp_pinned_across_toolchains() {
+        // SHA-256 based: this value must never change between releases
+        assert_eq!(compute_birth_timestamp("test.rs", "func"), 1892321929);
+        assert_eq!(compute_birth_timestamp("./test.rs", "func"), 1892321929);
+        assert_eq!(extract_semantic_path("./src\\main.rs"), "__src_main");
+    }
 }
@@ -0,0 +1,819 @@
+//! JavaScript/TypeScript Module Import Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: javascript_module_import_resolver
+//!
+//! The per-file extractor stops frontend call edges at the import statement
+//! (`typescript:fn:fetchUser:unresolved-reference:0-0`). This cross-file pass
+//! follows each call through the module graph to the entity that runs:
+//!
+//! 1. `fetchUser()` where the file has `import { fetchUser } from './api'`
+//!    (or `const { fetchUser } = require('./api')`) → export of `./api`
+//! 2. `api.fetchUser()` where `api` is a namespace import / `require()` value
+//! 3. `this.save()` inside a class method → sibling method of that class
+//! 4. `helper()` not imported → top-level function/class in the same file
+//!
+//! Exports are followed through barrel files (`export { a } from './a'`,
+//! `export * from './b'`, `export default helper`) up to
+//! [`MAX_REEXPORT_DEPTH`] hops.
+//!
+//! ## Module specifiers
+//!
+//! - Relative (`./api`, `../lib/api.js`): probed with `.ts/.tsx/.d.ts/.js/...`
+//!   extensions and `/index.*`; a `.js` suffix also tries the `.ts` source
+//! - `tsconfig.json` / `jsconfig.json` `compilerOptions.paths` aliases and
+//!   `baseUrl`, taken from the nearest config above the importing file
+//!   (relative `extends` chains are followed)
+//! - Monorepo workspace packages (`@acme/ui`, `@acme/ui/button`) by the
+//!   `name` field of each `package.json` in the tree
+//!
+//! ## Limitations (syntactic)
+//!
+//! - Calls on locals/params (`user.save()`) stay unresolved
+//! - `node_modules` packages are never indexed; their calls stay unresolved
+//! - Inherited methods (`this.x()` defined on a base class) stay unresolved
+
+use std::collections::{HashMap, HashSet};
+use std::path::{Component, Path, PathBuf};
+
+use serde_json::Value;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{
+    parse_unresolved_reference_name, RECEIVER_EXPR_METADATA_KEY, UNRESOLVED_TARGET_METADATA_KEY,
+};
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Edge metadata: `named` / `default` / `namespace` / `reexport` / `reexport_all` / `local_export`
+pub const BINDING_KIND_METADATA_KEY: &str = "binding_kind";
+
+/// Edge metadata: name on the exporting side (`default`, `*` for namespaces)
+pub const IMPORTED_NAME_METADATA_KEY: &str = "imported_name";
+
+/// Edge metadata: module specifier as written (`./api`, `@/lib/http`)
+pub const IMPORT_SOURCE_METADATA_KEY: &str = "import_source";
+
+/// Barrel chains deeper than this are not followed
+pub const MAX_REEXPORT_DEPTH: usize = 8;
+
+/// Extensions probed for an extensionless specifier, in TypeScript's order
+const PROBED_MODULE_EXTENSIONS: &[&str] = &["ts", "tsx", "d.ts", "mts", "cts", "js", "jsx", "mjs", "cjs"];
+
+/// Directories never searched for tsconfig/package.json files
+const SKIPPED_CONFIG_DIRECTORIES: &[&str] = &["node_modules", "target", "dist", "build", "out", "coverage"];
+
+/// Config discovery stops below this many directory levels
+const MAX_CONFIG_SEARCH_DEPTH: usize = 8;
+
+/// True for JavaScript/TypeScript source files
+///
+/// # 4-Word Name: is_javascript_entity_file_path
+pub fn is_javascript_entity_file_path(path: &Path) -> bool {
+    matches!(
+        path.extension().and_then(|e| e.to_str()),
+        Some("js" | "jsx" | "mjs" | "cjs" | "ts" | "tsx" | "mts" | "cts")
+    )
+}
+
+/// Path aliases of one `tsconfig.json` / `jsconfig.json`
+#[derive(Debug, Clone, PartialEq)]
+pub struct TsconfigPathScope {
+    /// Directory of the config file; applies to files below it
+    pub config_dir: String,
+    /// `compilerOptions.baseUrl`, resolved against the config directory
+    pub base_url: Option<String>,
+    /// Directory `paths` targets are relative to (baseUrl, else config dir)
+    pub paths_base_dir: String,
+    /// `compilerOptions.paths` in declaration order (`@/*` → [`src/*`])
+    pub path_aliases: Vec<(String, Vec<String>)>,
+}
+
+/// A named package of a monorepo workspace
+#[derive(Debug, Clone, PartialEq)]
+pub struct WorkspacePackageEntry {
+    pub name: String,
+    pub package_dir: String,
+    /// `source` / `types` / `module` / `main`, resolved against the package dir
+    pub entry_path: Option<String>,
+}
+
+/// Module resolution settings discovered under a workspace root
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct JavascriptModuleConfig {
+    pub path_scopes: Vec<TsconfigPathScope>,
+    pub workspace_packages: Vec<WorkspacePackageEntry>,
+}
+
+/// Collapse `.` and `..` without touching the filesystem; `/`-separated
+///
+/// # 4-Word Name: normalize_module_path_lexically
+pub fn normalize_module_path_lexically(path: &Path) -> String {
+    let mut parts: Vec<Component<'_>> = Vec::new();
+    for component in path.components() {
+        match component {
+            Component::CurDir => {}
+            Component::ParentDir => match parts.last() {
+                Some(Component::Normal(_)) => {
+                    parts.pop();
+                }
+                Some(Component::RootDir | Component::Prefix(_)) => {}
+                _ => parts.push(component),
+            },
+            other => parts.push(other),
+        }
+    }
+    parts.iter().collect::<PathBuf>().to_string_lossy().replace('\\', "/")
+}
+
+/// Discover tsconfig/jsconfig aliases and workspace packages under `root`
+///
+/// # 4-Word Name: load_javascript_module_config
+///
+/// # Contract
+/// - Never fails: unreadable or malformed files are skipped
+/// - Skips hidden directories, `node_modules` and build output
+/// - Paths are lexically normalized and keep `root` as their prefix, so they
+///   compare equal to entity file paths produced by walking the same root
+pub fn load_javascript_module_config(root: &Path) -> JavascriptModuleConfig {
+    let mut config = JavascriptModuleConfig::default();
+    let mut pending = vec![(root.to_path_buf(), 0usize)];
+    while let Some((dir, depth)) = pending.pop() {
+        for config_name in ["tsconfig.json", "jsconfig.json"] {
+            let config_path = dir.join(config_name);
+            if config_path.is_file() {
+                if let Some(scope) = read_tsconfig_path_scope(&config_path, 0) {
+                    config.path_scopes.push(scope);
+                    break;
+                }
+            }
+        }
+        if let Some(package) = read_workspace_package_entry(&dir) {
+            config.workspace_packages.push(package);
+        }
+        if depth >= MAX_CONFIG_SEARCH_DEPTH {
+            continue;
+        }
+        let Ok(entries) = std::fs::read_dir(&dir) else { continue };
+        for entry in entries.flatten() {
+            let name = entry.file_name().to_string_lossy().to_string();
+            if name.starts_with('.') || SKIPPED_CONFIG_DIRECTORIES.contains(&name.as_str()) {
+                continue;
+            }
+            if entry.file_type().is_ok_and(|t| t.is_dir()) {
+                pending.push((entry.path(), depth + 1));
+            }
+        }
+    }
+    config.path_scopes.sort_by(|a, b| a.config_dir.cmp(&b.config_dir));
+    config.workspace_packages.sort_by(|a, b| a.name.cmp(&b.name));
+    config
+}
+
+/// tsconfig files allow comments and trailing commas; strict JSON does not
+fn strip_json_comments_and_commas(text: &str) -> String {
+    let mut out = String::with_capacity(text.len());
+    let mut chars = text.chars().peekable();
+    let mut in_string = false;
+    while let Some(c) = chars.next() {
+        if in_string {
+            out.push(c);
+            match c {
+                '\\' => {
+                    if let Some(escaped) = chars.next() {
+                        out.push(escaped);
+                    }
+                }
+                '"' => in_string = false,
+                _ => {}
+            }
+            continue;
+        }
+        let next = chars.peek().copied();
+        match (c, next) {
+            ('"', _) => {
+                in_string = true;
+                out.push(c);
+            }
+            ('/', Some('/')) => {
+                for skipped in chars.by_ref() {
+                    if skipped == '\n' {
+                        out.push('\n');
+                        break;
+                    }
+                }
+            }
+            ('/', Some('*')) => {
+                chars.next();
+                let mut previous = ' ';
+                for skipped in chars.by_ref() {
+                    if previous == '*' && skipped == '/' {
+                        break;
+                    }
+                    previous = skipped;
+                }
+            }
+            ('}' | ']', _) => {
+                let trimmed_len = out.trim_end().len();
+                if out[..trimmed_len].ends_with(',') {
+                    out.remove(trimmed_len - 1);
+                }
+                out.push(c);
+            }
+            _ => out.push(c),
+        }
+    }
+    out
+}
+
+fn read_json_file_lenient(path: &Path) -> Option<Value> {
+    let text = std::fs::read_to_string(path).ok()?;
+    serde_json::from_str(&strip_json_comments_and_commas(&text)).ok()
+}
+
+fn join_normalized(dir: &str, relative: &str) -> String {
+    normalize_module_path_lexically(&Path::new(dir).join(relative))
+}
+
+/// Parse one config, inheriting `baseUrl`/`paths` through relative `extends`
+fn read_tsconfig_path_scope(config_path: &Path, depth: usize) -> Option<TsconfigPathScope> {
+    let json = read_json_file_lenient(config_path)?;
+    let config_dir = normalize_module_path_lexically(config_path.parent().unwrap_or(Path::new("")));
+    let options = json.get("compilerOptions");
+
+    let base_url = options
+        .and_then(|o| o.get("baseUrl"))
+        .and_then(Value::as_str)
+        .map(|url| join_normalized(&config_dir, url));
+    let path_aliases: Option<Vec<(String, Vec<String>)>> = options
+        .and_then(|o| o.get("paths"))
+        .and_then(Value::as_object)
+        .map(|paths| {
+            paths
+                .iter()
+                .map(|(pattern, targets)| {
+                    let targets = targets
+                        .as_array()
+                        .map(|t| t.iter().filter_map(Value::as_str).map(str::to_string).collect())
+                        .unwrap_or_default();
+                    (pattern.clone(), targets)
+                })
+                .collect()
+        });
+
+    // Package-name extends (`@tsconfig/node18`) live in node_modules: not followed
+    let parent = json
+        .get("extends")
+        .and_then(Value::as_str)
+        .filter(|extends| extends.starts_with('.') && depth < 4)
+        .and_then(|extends| {
+            let parent_path = if extends.ends_with(".json") {
+                Path::new(&config_dir).join(extends)
+            } else {
+                Path::new(&config_dir).join(format!("{}.json", extends))
+            };
+            read_tsconfig_path_scope(&parent_path, depth + 1)
+        });
+
+    let (path_aliases, paths_base_dir) = match (path_aliases, &parent) {
+        (Some(aliases), _) => {
+            let base = base_url.clone().unwrap_or_else(|| config_dir.clone());
+            (aliases, base)
+        }
+        (None, Some(parent)) => (parent.path_aliases.clone(), parent.paths_base_dir.clone()),
+        (None, None) => (Vec::new(), config_dir.clone()),
+    };
+    let base_url = base_url.or_else(|| parent.and_then(|p| p.base_url));
+    if base_url.is_none() && path_aliases.is_empty() {
+        return None;
+    }
+    Some(TsconfigPathScope {
+        config_dir,
+        base_url,
+        paths_base_dir,
+        path_aliases,
+    })
+}
+
+fn read_workspace_package_entry(dir: &Path) -> Option<WorkspacePackageEntry> {
+    let json = read_json_file_lenient(&dir.join("package.json"))?;
+    let name = json.get("name").and_then(Value::as_str)?.to_string();
+    let package_dir = normalize_module_path_lexically(dir);
+    let entry_path = ["source", "types", "module", "main"]
+        .iter()
+        .find_map(|field| json.get(*field).and_then(Value::as_str))
+        .map(|entry| join_normalized(&package_dir, entry));
+    Some(WorkspacePackageEntry {
+        name,
+        package_dir,
+        entry_path,
+    })
+}
+
+/// `@/*` matched against `@/lib/http` captures `lib/http`
+fn match_path_alias_pattern<'s>(pattern: &str, specifier: &'s str) -> Option<&'s str> {
+    match pattern.split_once('*') {
+        Some((prefix, suffix)) => specifier
+            .strip_prefix(prefix)
+            .and_then(|rest| rest.strip_suffix(suffix)),
+        None => (pattern == specifier).then_some(""),
+    }
+}
+
+fn is_path_under_directory(path: &str, dir: &str) -> bool {
+    dir.is_empty() || path == dir || path.strip_prefix(dir).is_some_and(|rest| rest.starts_with('/'))
+}
+
+fn parent_directory_of(file: &str) -> &str {
+    file.rsplit_once('/').map(|(dir, _)| dir).unwrap_or("")
+}
+
+impl JavascriptModuleConfig {
+    /// Nearest config whose directory contains `file`
+    fn scope_for_file(&self, file: &str) -> Option<&TsconfigPathScope> {
+        self.path_scopes
+            .iter()
+            .filter(|scope| is_path_under_directory(file, &scope.config_dir))
+            .max_by_key(|scope| scope.config_dir.len())
+    }
+
+    /// Unprobed module paths a specifier may refer to, most specific first
+    fn module_specifier_candidates(&self, from_file: &str, specifier: &str) -> Vec<String> {
+        if specifier == "." || specifier == ".." || specifier.starts_with("./") || specifier.starts_with("../") {
+            return vec![join_normalized(parent_directory_of(from_file), specifier)];
+        }
+        let mut candidates = Vec::new();
+        if let Some(scope) = self.scope_for_file(from_file) {
+            for (pattern, targets) in &scope.path_aliases {
+                if let Some(captured) = match_path_alias_pattern(pattern, specifier) {
+                    for target in targets {
+                        candidates.push(join_normalized(&scope.paths_base_dir, &target.replacen('*', captured, 1)));
+                    }
+                }
+            }
+            if let Some(base_url) = &scope.base_url {
+                candidates.push(join_normalized(base_url, specifier));
+            }
+        }
+        for package in &self.workspace_packages {
+            if specifier == package.name {
+                candidates.extend(package.entry_path.iter().cloned());
+                candidates.push(join_normalized(&package.package_dir, "src"));
+                candidates.push(package.package_dir.clone());
+            } else if let Some(subpath) = specifier
+                .strip_prefix(package.name.as_str())
+                .and_then(|rest| rest.strip_prefix('/'))
+            {
+                candidates.push(join_normalized(&package.package_dir, subpath));
+                candidates.push(join_normalized(&package.package_dir, &format!("src/{}", subpath)));
+            }
+        }
+        candidates
+    }
+}
+
+/// First indexed source file a module path refers to
+fn probe_module_source_file(base: &str, known_files: &HashSet<String>) -> Option<String> {
+    let mut probes = vec![base.to_string()];
+    // ESM TypeScript imports name the emitted `.js` file
+    for (emitted, sources) in [
+        (".js", &["ts", "tsx"][..]),
+        (".jsx", &["tsx"][..]),
+        (".mjs", &["mts"][..]),
+        (".cjs", &["cts"][..]),
+    ] {
+        if let Some(stem) = base.strip_suffix(emitted) {
+            probes.extend(sources.iter().map(|ext| format!("{}.{}", stem, ext)));
+        }
+    }
+    probes.extend(PROBED_MODULE_EXTENSIONS.iter().map(|ext| format!("{}.{}", base, ext)));
+    probes.extend(PROBED_MODULE_EXTENSIONS.iter().map(|ext| format!("{}/index.{}", base, ext)));
+    probes.into_iter().find(|probe| known_files.contains(probe))
+}
+
+/// One import/export binding parsed back from an extractor `Uses` edge
+#[derive(Debug, Clone)]
+struct ModuleBindingRecord {
+    local_name: String,
+    binding_kind: String,
+    imported_name: String,
+    module_source: String,
+}
+
+/// Names bound by each importing file and exported by each module file
+#[derive(Debug, Default)]
+struct ModuleBindingIndex {
+    imports: HashMap<String, HashMap<String, ModuleBindingRecord>>,
+    exports: HashMap<String, Vec<ModuleBindingRecord>>,
+}
+
+impl ModuleBindingIndex {
+    /// Index binding edges; files already indexed from an earlier slice win
+    fn add_binding_edges(&mut self, edges: &[DependencyEdge]) {
+        let mut added_files: HashSet<String> = HashSet::new();
+        for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Uses) {
+            let Some(binding_kind) = edge.metadata_value(BINDING_KIND_METADATA_KEY) else { continue };
+            let Some((file, _line)) = edge.source_location.as_deref().and_then(|l| l.rsplit_once(':')) else {
+                continue;
+            };
+            let file = normalize_module_path_lexically(Path::new(file));
+            let fresh_file = !self.imports.contains_key(&file) && !self.exports.contains_key(&file);
+            if !fresh_file && !added_files.contains(&file) {
+                continue;
+            }
+            added_files.insert(file.clone());
+            let Some(local_name) = edge
+                .to_key
+                .as_str()
+                .splitn(3, ':')
+                .nth(2)
+                .and_then(|rest| rest.strip_suffix(":0-0"))
+            else {
+                continue;
+            };
+            let record = ModuleBindingRecord {
+                local_name: local_name.to_string(),
+                binding_kind: binding_kind.to_string(),
+                imported_name: edge.metadata_value(IMPORTED_NAME_METADATA_KEY).unwrap_or_default().to_string(),
+                module_source: edge.metadata_value(IMPORT_SOURCE_METADATA_KEY).unwrap_or_default().to_string(),
+            };
+            match binding_kind {
+                "named" | "default" | "namespace" => {
+                    self.imports
+                        .entry(file)
+                        .or_default()
+                        .insert(record.local_name.clone(), record);
+                }
+                _ => self.exports.entry(file).or_default().push(record),
+            }
+        }
+    }
+
+    fn binding_files(&self) -> impl Iterator<Item = &String> {
+        self.imports.keys().chain(self.exports.keys())
+    }
+}
+
+/// JavaScript/TypeScript definitions indexed for resolution
+struct JavascriptDefinitionIndex<'a> {
+    /// (file, name) → top-level function/class/interface/...
+    top_level: HashMap<(String, String), &'a CodeEntity>,
+    /// (file, class, method name) → method
+    methods: HashMap<(String, String, String), &'a CodeEntity>,
+}
+
+impl<'a> JavascriptDefinitionIndex<'a> {
+    fn build_from_code_entities(entities: &'a [CodeEntity]) -> Self {
+        let mut index = JavascriptDefinitionIndex {
+            top_level: HashMap::new(),
+            methods: HashMap::new(),
+        };
+        for entity in entities {
+            if !is_javascript_entity_file_path(&entity.interface_signature.file_path) {
+                continue;
+            }
+            let file = file_path_string(entity);
+            let name = entity.interface_signature.name.as_str();
+            if entity.interface_signature.entity_type == EntityType::Method {
+                if let Some(class) = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) {
+                    index.methods.insert((file, class.clone(), name.to_string()), entity);
+                }
+            } else {
+                index.top_level.entry((file, name.to_string())).or_insert(entity);
+            }
+        }
+        index
+    }
+}
+
+fn file_path_string(entity: &CodeEntity) -> String {
+    normalize_module_path_lexically(&entity.interface_signature.file_path)
+}
+
+/// Resolution state shared by one pass
+struct ModuleResolutionContext<'a, 'c> {
+    definitions: JavascriptDefinitionIndex<'a>,
+    bindings: ModuleBindingIndex,
+    known_files: HashSet<String>,
+    config: &'c JavascriptModuleConfig,
+}
+
+impl<'a, 'c> ModuleResolutionContext<'a, 'c> {
+    fn resolve_module_file(&self, from_file: &str, specifier: &str) -> Option<String> {
+        self.config
+            .module_specifier_candidates(from_file, specifier)
+            .iter()
+            .find_map(|candidate| probe_module_source_file(candidate, &self.known_files))
+    }
+
+    /// Entity exported as `name` by `file`, following barrels
+    fn resolve_exported_name(
+        &self,
+        file: &str,
+        name: &str,
+        depth: usize,
+        visited: &mut HashSet<(String, String)>,
+    ) -> Option<&'a CodeEntity> {
+        if depth > MAX_REEXPORT_DEPTH || !visited.insert((file.to_string(), name.to_string())) {
+            return None;
+        }
+        if name != "default" {
+            if let Some(entity) = self.definitions.top_level.get(&(file.to_string(), name.to_string())) {
+                return Some(*entity);
+            }
+        }
+        let exports = self.bindings.exports.get(file).map(Vec::as_slice).unwrap_or(&[][..]);
+        for record in exports.iter().filter(|r| r.local_name == name) {
+            let found = match record.binding_kind.as_str() {
+                "reexport" => self
+                    .resolve_module_file(file, &record.module_source)
+                    .and_then(|target| self.resolve_exported_name(&target, &record.imported_name, depth + 1, visited)),
+                // `export { a as b }`: `a` is local, or itself imported
+                "local_export" => self
+                    .definitions
+                    .top_level
+                    .get(&(file.to_string(), record.imported_name.clone()))
+                    .copied()
+                    .or_else(|| self.resolve_imported_binding(file, &record.imported_name, depth + 1, visited)),
+                _ => None,
+            };
+            if found.is_some() {
+                return found;
+            }
+        }
+        if name == "default" {
+            return None;
+        }
+        exports
+            .iter()
+            .filter(|r| r.binding_kind == "reexport_all")
+            .find_map(|record| {
+                let target = self.resolve_module_file(file, &record.module_source)?;
+                self.resolve_exported_name(&target, name, depth + 1, visited)
+            })
+    }
+
+    /// Entity behind a name `file` imports (`named` / `default` bindings)
+    fn resolve_imported_binding(
+        &self,
+        file: &str,
+        local_name: &str,
+        depth: usize,
+        visited: &mut HashSet<(String, String)>,
+    ) -> Option<&'a CodeEntity> {
+        let record = self.bindings.imports.get(file)?.get(local_name)?;
+        let exported = match record.binding_kind.as_str() {
+            "named" => record.imported_name.as_str(),
+            "default" => "default",
+            _ => return None,
+        };
+        let target = self.resolve_module_file(file, &record.module_source)?;
+        self.resolve_exported_name(&target, exported, depth, visited)
+    }
+
+    /// Member `name` of the module bound to namespace `receiver` in `file`
+    fn resolve_namespace_member(&self, file: &str, receiver: &str, name: &str) -> Option<&'a CodeEntity> {
+        let record = self.bindings.imports.get(file)?.get(receiver)?;
+        if record.binding_kind != "namespace" {
+            return None;
+        }
+        let target = self.resolve_module_file(file, &record.module_source)?;
+        self.resolve_exported_name(&target, name, 0, &mut HashSet::new())
+    }
+}
+
+/// Resolve JavaScript/TypeScript call and heritage targets in place
+///
+/// # 4-Word Name: resolve_javascript_module_calls
+///
+/// # Contract
+/// - Precondition: entities carry query_extractor metadata; `edges` (or
+///   `context_edges`) include the module-binding `Uses` edges of every
+///   caller's file and of every barrel file on the way
+/// - `context_edges`: bindings of files not re-parsed (incremental runs);
+///   a file's bindings in `edges` take precedence
+/// - Postcondition: resolved edges point at real entity keys and carry
+///   `resolution` (`module_import` / `namespace_import` / `this_method` /
+///   `same_file`) and `unresolved_target`; other edges are untouched
+/// - Returns: number of edges rewritten
+pub fn resolve_javascript_module_calls(
+    entities: &[CodeEntity],
+    edges: &mut [DependencyEdge],
+    context_edges: &[DependencyEdge],
+    config: &JavascriptModuleConfig,
+) -> usize {
+    let mut bindings = ModuleBindingIndex::default();
+    bindings.add_binding_edges(edges);
+    bindings.add_binding_edges(context_edges);
+    let definitions = JavascriptDefinitionIndex::build_from_code_entities(entities);
+    let mut known_files: HashSet<String> = definitions.top_level.keys().map(|(file, _)| file.clone()).collect();
+    known_files.extend(definitions.methods.keys().map(|(file, _, _)| file.clone()));
+    // Pure barrels (`index.ts` with only re-exports) define no entities
+    known_files.extend(bindings.binding_files().cloned());
+    let context = ModuleResolutionContext {
+        definitions,
+        bindings,
+        known_files,
+        config,
+    };
+    let entities_by_key: HashMap<&str, &CodeEntity> =
+        entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+
+    let mut rewritten = 0;
+    for edge in edges.iter_mut() {
+        if !matches!(edge.edge_type, EdgeType::Calls | EdgeType::Implements) {
+            continue;
+        }
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else {
+            continue;
+        };
+        if !is_javascript_entity_file_path(&from.interface_signature.file_path) {
+            continue;
+        }
+        let Some(target_name) = parse_unresolved_reference_name(edge.to_key.as_str()) else {
+            continue;
+        };
+        let target_name = target_name.to_string();
+        let file = file_path_string(from);
+
+        let resolved = match edge.metadata.get(RECEIVER_EXPR_METADATA_KEY).map(String::as_str) {
+            Some("this") if edge.edge_type == EdgeType::Calls => from
+                .metadata
+                .additional
+                .get(RECEIVER_TYPE_METADATA_KEY)
+                .and_then(|class| context.definitions.methods.get(&(file.clone(), class.clone(), target_name.clone())))
+                .map(|method| (*method, "this_method")),
+            Some(receiver) => context
+                .resolve_namespace_member(&file, receiver, &target_name)
+                .map(|entity| (entity, "namespace_import")),
+            None => context
+                .resolve_imported_binding(&file, &target_name, 0, &mut HashSet::new())
+                .map(|entity| (entity, "module_import"))
+                .or_else(|| {
+                    context
+                        .definitions
+                        .top_level
+                        .get(&(file.clone(), target_name.clone()))
+                        .map(|entity| (*entity, "same_file"))
+                })
+                .filter(|(target, _)| {
+                    edge.edge_type == EdgeType::Calls
+                        || matches!(
+                            target.interface_signature.entity_type,
+                            EntityType::Class | EntityType::Interface
+                        )
+                }),
+        };
+        let Some((target, resolution)) = resolved else { continue };
+        if target.isgl1_key == from.isgl1_key {
+            continue;
+        }
+        if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+            edge.metadata.insert(
+                UNRESOLVED_TARGET_METADATA_KEY.to_string(),
+                edge.to_key.as_str().to_string(),
+            );
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), resolution.to_string());
+            rewritten += 1;
+        }
+    }
+    rewritten
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call(from: &str, to: &str, location: &str, receiver: Option<&str>) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .source_location(location);
+        if let Some(receiver) = receiver {
+            builder = builder.metadata_entry(RECEIVER_EXPR_METADATA_KEY, receiver);
+        }
+        builder.build().unwrap()
+    }
+
+    fn binding(file: &str, local: &str, kind: &str, imported: &str, source: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(format!("typescript:file:{}:1-1", file))
+            .to_key(format!("typescript:module:{}:0-0", local))
+            .edge_type(EdgeType::Uses)
+            .source_location(format!("{}:1", file))
+            .metadata_entry(BINDING_KIND_METADATA_KEY, kind)
+            .metadata_entry(IMPORTED_NAME_METADATA_KEY, imported)
+            .metadata_entry(IMPORT_SOURCE_METADATA_KEY, source)
+            .build()
+            .unwrap()
+    }
+
+    fn fixture() -> Vec<CodeEntity> {
+        vec![
+            create_go_test_entity("typescript:fn:fetchUser:__web_src_api_users:T1", "fetchUser", EntityType::Function, "web/src/api/users.ts", &[]),
+            create_go_test_entity("typescript:fn:formatDate:__libs_util_src_date:T2", "formatDate", EntityType::Function, "libs/util/src/date.ts", &[]),
+            create_go_test_entity("typescript:class:Store:__web_src_store:T3", "Store", EntityType::Class, "web/src/store.ts", &[]),
+            create_go_test_entity(
+                "typescript:method:load:__web_src_store:T4",
+                "load",
+                EntityType::Method,
+                "web/src/store.ts",
+                &[("receiver_type", "Store")],
+            ),
+            create_go_test_entity(
+                "typescript:method:refresh:__web_src_store:T5",
+                "refresh",
+                EntityType::Method,
+                "web/src/store.ts",
+                &[("receiver_type", "Store")],
+            ),
+        ]
+    }
+
+    fn config() -> JavascriptModuleConfig {
+        JavascriptModuleConfig {
+            path_scopes: vec![TsconfigPathScope {
+                config_dir: "web".to_string(),
+                base_url: Some("web".to_string()),
+                paths_base_dir: "web".to_string(),
+                path_aliases: vec![("@/*".to_string(), vec!["src/*".to_string()])],
+            }],
+            workspace_packages: vec![WorkspacePackageEntry {
+                name: "@acme/util".to_string(),
+                package_dir: "libs/util".to_string(),
+                entry_path: Some("libs/util/dist/index.js".to_string()),
+            }],
+        }
+    }
+
+    #[test]
+    fn test_alias_barrel_and_workspace_package_resolved() {
+        let entities = fixture();
+        let mut edges = vec![
+            // web/src/api/index.ts: export * from './users'
+            binding("web/src/api/index.ts", "*", "reexport_all", "*", "./users"),
+            // libs/util/src/index.ts: export { formatDate as fmt } from './date'
+            binding("libs/util/src/index.ts", "fmt", "reexport", "formatDate", "./date"),
+            binding("web/src/store.ts", "api", "namespace", "*", "@/api"),
+            binding("web/src/store.ts", "fmt", "named", "fmt", "@acme/util"),
+            call("typescript:method:load:__web_src_store:T4", "typescript:fn:fetchUser:unresolved-reference:0-0", "web/src/store.ts:8", Some("api")),
+            call("typescript:method:load:__web_src_store:T4", "typescript:fn:fmt:unresolved-reference:0-0", "web/src/store.ts:9", None),
+            call("typescript:method:load:__web_src_store:T4", "typescript:fn:missing:unresolved-reference:0-0", "web/src/store.ts:10", Some("api")),
+        ];
+
+        assert_eq!(resolve_javascript_module_calls(&entities, &mut edges, &[], &config()), 2);
+        assert_eq!(edges[4].to_key.as_str(), "typescript:fn:fetchUser:__web_src_api_users:T1");
+        assert_eq!(edges[4].metadata_value("resolution"), Some("namespace_import"));
+        assert_eq!(edges[5].to_key.as_str(), "typescript:fn:formatDate:__libs_util_src_date:T2");
+        assert_eq!(edges[5].metadata_value("resolution"), Some("module_import"));
+        assert!(edges[6].to_key.as_str().contains("unresolved-reference"));
+    }
+
+    #[test]
+    fn test_this_call_and_context_bindings_resolved() {
+        let entities = fixture();
+        // Barrel not re-parsed this run: its bindings come from the database
+        let context = vec![binding("web/src/api/index.ts", "default", "local_export", "fetchUser", "")];
+        let mut edges = vec![
+            binding("web/src/api/index.ts", "fetchUser", "named", "fetchUser", "./users"),
+            binding("web/src/store.ts", "getUser", "default", "default", "./api/index.js"),
+            call("typescript:method:refresh:__web_src_store:T5", "typescript:fn:load:unresolved-reference:0-0", "web/src/store.ts:12", Some("this")),
+            call("typescript:method:refresh:__web_src_store:T5", "typescript:fn:getUser:unresolved-reference:0-0", "web/src/store.ts:13", None),
+        ];
+
+        assert_eq!(resolve_javascript_module_calls(&entities, &mut edges, &context, &config()), 1);
+        assert_eq!(edges[2].to_key.as_str(), "typescript:method:load:__web_src_store:T4");
+        assert_eq!(edges[2].metadata_value("resolution"), Some("this_method"));
+        // index.ts's edges in `edges` win over the stale context export
+        assert!(edges[3].to_key.as_str().contains("unresolved-reference"));
+    }
+
+    #[test]
+    fn test_tsconfig_comments_and_extends_parsed() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(
+            dir.path().join("tsconfig.base.json"),
+            "{\n  // shared\n  \"compilerOptions\": { \"baseUrl\": \".\", \"paths\": { \"@/*\": [\"src/*\",], }, },\n}\n",
+        )
+        .unwrap();
+        std::fs::create_dir(dir.path().join("app")).unwrap();
+        std::fs::write(
+            dir.path().join("app/tsconfig.json"),
+            "{ /* app */ \"extends\": \"../tsconfig.base\" }",
+        )
+        .unwrap();
+        std::fs::write(dir.path().join("app/package.json"), "{\"name\": \"@acme/app\"}").unwrap();
+
+        let config = load_javascript_module_config(dir.path());
+        let root = normalize_module_path_lexically(dir.path());
+        let app_scope = config
+            .path_scopes
+            .iter()
+            .find(|s| s.config_dir == format!("{}/app", root))
+            .expect("app tsconfig discovered");
+        assert_eq!(app_scope.path_aliases, vec![("@/*".to_string(), vec!["src/*".to_string()])]);
+        assert_eq!(app_scope.paths_base_dir, root);
+        assert_eq!(config.workspace_packages[0].name, "@acme/app");
+    }
+}
@@ -0,0 +1,350 @@
+//! JVM Type Hierarchy Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: jvm_type_hierarchy_resolver
+//!
+//! Java and Kotlin supertypes and constructor calls leave the extractor as
+//! unresolved names (`java:fn:BaseRepository:unresolved-reference:0-0`).
+//! This cross-file pass resolves them the way the compilers do, so class
+//! hierarchies and interface implementations connect across files, modules
+//! and the two languages (a Kotlin class implementing a Java interface):
+//!
+//! 1. Single-type import (`import com.acme.core.Repository`) → that type
+//! 2. Same package (`package_name` metadata of the referencing entity)
+//! 3. On-demand import (`import com.acme.core.*`) → first package declaring it
+//!
+//! Rewritten `Implements` edges keep the extractor's `hierarchy_kind`
+//! (`extends` / `implements`); constructor calls (`new Repository()`,
+//! `Repository()`) are rewritten only when the name resolves to a type.
+//!
+//! ## Limitations (syntactic)
+//!
+//! - Fully-qualified references in `extends` clauses are not captured
+//! - Nested types are indexed by their simple name within the package
+//! - Java on-demand imports are recognized by a lowercase last segment
+//!   (`com.acme.core`), the standard package naming convention
+
+use std::collections::HashMap;
+use std::path::Path;
+
+use crate::canonical_symbol_id_normalizer::PACKAGE_NAME_METADATA_KEY;
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{parse_unresolved_reference_name, UNRESOLVED_TARGET_METADATA_KEY};
+
+/// Edge metadata key: `extends` or `implements` on supertype edges
+pub const HIERARCHY_KIND_METADATA_KEY: &str = "hierarchy_kind";
+
+/// True for `.java`, `.kt` and `.kts` source files
+///
+/// # 4-Word Name: is_jvm_entity_file_path
+pub fn is_jvm_entity_file_path(path: &Path) -> bool {
+    matches!(path.extension().and_then(|e| e.to_str()), Some("java" | "kt" | "kts"))
+}
+
+/// Imports declared by one JVM source file
+#[derive(Debug, Clone, Default, PartialEq)]
+pub struct JvmFileImports {
+    /// Simple name → fully-qualified name (`Repository` → `com.acme.core.Repository`)
+    pub single_type: HashMap<String, String>,
+    /// Packages imported on demand (`com.acme.core`)
+    pub on_demand_packages: Vec<String>,
+}
+
+/// Imports per file, from the extractor's `java:module:` / `kotlin:module:` edges
+///
+/// # 4-Word Name: collect_jvm_file_imports
+///
+/// # Contract
+/// - Key: file path as written in the edge's source location (`path:line`)
+/// - `import static a.B.c` lands in `on_demand_packages` as `a.B.c` and never
+///   matches a package, which is harmless
+pub fn collect_jvm_file_imports(edges: &[DependencyEdge]) -> HashMap<String, JvmFileImports> {
+    let mut imports: HashMap<String, JvmFileImports> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Uses) {
+        let target = edge.to_key.as_str();
+        let Some(rest) = target
+            .strip_prefix("java:module:")
+            .or_else(|| target.strip_prefix("kotlin:module:"))
+        else {
+            continue;
+        };
+        let Some(path) = rest.strip_suffix(":0-0") else { continue };
+        let Some((file, _line)) = edge.source_location.as_deref().and_then(|l| l.rsplit_once(':')) else {
+            continue;
+        };
+        let entry = imports.entry(file.to_string()).or_default();
+        if let Some(package) = path.strip_suffix(".*") {
+            entry.on_demand_packages.push(package.to_string());
+            continue;
+        }
+        match path.rsplit_once('.') {
+            Some((_, simple)) if simple.chars().next().is_some_and(char::is_uppercase) => {
+                entry.single_type.insert(simple.to_string(), path.to_string());
+            }
+            _ => entry.on_demand_packages.push(path.to_string()),
+        }
+    }
+    imports
+}
+
+fn declared_package_of(entity: &CodeEntity) -> &str {
+    entity
+        .metadata
+        .additional
+        .get(PACKAGE_NAME_METADATA_KEY)
+        .map(String::as_str)
+        .unwrap_or_default()
+}
+
+/// Resolve Java/Kotlin supertype and constructor targets in place
+///
+/// # 4-Word Name: resolve_jvm_type_references
+///
+/// # Contract
+/// - Precondition: entities carry `package_name`; `edges` include the import
+///   `Uses` edges of every referencing file
+/// - Postcondition: resolved edges point at the class/interface/enum entity
+///   and carry `resolution` (`imported_type` / `same_package` /
+///   `on_demand_import`) and `unresolved_target`; other edges are untouched
+/// - Returns: number of edges rewritten
+pub fn resolve_jvm_type_references(entities: &[CodeEntity], edges: &mut [DependencyEdge]) -> usize {
+    let mut types_by_qualified_name: HashMap<String, &CodeEntity> = HashMap::new();
+    for entity in entities {
+        if !is_jvm_entity_file_path(&entity.interface_signature.file_path) {
+            continue;
+        }
+        if !matches!(
+            entity.interface_signature.entity_type,
+            EntityType::Class | EntityType::Interface | EntityType::Enum | EntityType::Struct
+        ) {
+            continue;
+        }
+        let package = declared_package_of(entity);
+        let qualified = if package.is_empty() {
+            entity.interface_signature.name.clone()
+        } else {
+            format!("{}.{}", package, entity.interface_signature.name)
+        };
+        types_by_qualified_name.entry(qualified).or_insert(entity);
+    }
+    let imports = collect_jvm_file_imports(edges);
+    let entities_by_key: HashMap<&str, &CodeEntity> =
+        entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let no_imports = JvmFileImports::default();
+
+    let mut rewritten = 0;
+    for edge in edges.iter_mut() {
+        if !matches!(edge.edge_type, EdgeType::Implements | EdgeType::Calls) {
+            continue;
+        }
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else {
+            continue;
+        };
+        if !is_jvm_entity_file_path(&from.interface_signature.file_path) {
+            continue;
+        }
+        let Some(name) = parse_unresolved_reference_name(edge.to_key.as_str()) else {
+            continue;
+        };
+        // Method calls are lowercase by convention; only constructors name a type
+        if edge.edge_type == EdgeType::Calls && !name.chars().next().is_some_and(char::is_uppercase) {
+            continue;
+        }
+        let name = name.to_string();
+        let file = from.interface_signature.file_path.to_string_lossy().to_string();
+        let file_imports = imports.get(&file).unwrap_or(&no_imports);
+        let package = declared_package_of(from);
+
+        let lookup = |qualified: &str| types_by_qualified_name.get(qualified).copied();
+        let same_package = if package.is_empty() { name.clone() } else { format!("{}.{}", package, name) };
+        let resolved = file_imports
+            .single_type
+            .get(&name)
+            .and_then(|qualified| lookup(qualified))
+            .map(|target| (target, "imported_type"))
+            .or_else(|| lookup(&same_package).map(|target| (target, "same_package")))
+            .or_else(|| {
+                file_imports
+                    .on_demand_packages
+                    .iter()
+                    .find_map(|p| lookup(&format!("{}.{}", p, name)))
+                    .map(|target| (target, "on_demand_import"))
+            });
+        let Some((target, resolution)) = resolved else { continue };
+        if target.isgl1_key == from.isgl1_key {
+            continue;
+        }
+        if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+            edge.metadata.insert(
+                UNRESOLVED_TARGET_METADATA_KEY.to_string(),
+                edge.to_key.as_str().to_string(),
+            );
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), resolution.to_string());
+            rewritten += 1;
+        }
+    }
+    rewritten
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType, location: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .source_location(location)
+            .build()
+            .unwrap()
+    }
+
+    fn fixture() -> Vec<CodeEntity> {
+        vec![
+            create_go_test_entity(
+                "java:interface:Repository:__core_Repository:T1",
+                "Repository",
+                EntityType::Interface,
+                "core/src/main/java/com/acme/core/Repository.java",
+                &[("package_name", "com.acme.core")],
+            ),
+            create_go_test_entity(
+                "java:class:BaseService:__app_BaseService:T2",
+                "BaseService",
+                EntityType::Class,
+                "app/src/main/java/com/acme/app/BaseService.java",
+                &[("package_name", "com.acme.app")],
+            ),
+            create_go_test_entity(
+                "java:class:UserService:__app_UserService:T3",
+                "UserService",
+                EntityType::Class,
+                "app/src/main/java/com/acme/app/UserService.java",
+                &[("package_name", "com.acme.app")],
+            ),
+            create_go_test_entity(
+                "kotlin:class:AuditRepository:__audit_AuditRepository:T4",
+                "AuditRepository",
+                EntityType::Class,
+                "audit/src/AuditRepository.kt",
+                &[("package_name", "com.acme.audit")],
+            ),
+            create_go_test_entity(
+                "kotlin:fn:wire:__audit_Wiring:T5",
+                "wire",
+                EntityType::Function,
+                "audit/src/Wiring.kt",
+                &[("package_name", "com.acme.audit")],
+            ),
+        ]
+    }
+
+    #[test]
+    fn test_java_supertypes_resolve_by_import_and_package() {
+        let entities = fixture();
+        let user_file = "app/src/main/java/com/acme/app/UserService.java";
+        let mut edges = vec![
+            edge(&format!("java:file:{}:1-1", user_file), "java:module:com.acme.core.Repository:0-0", EdgeType::Uses, &format!("{}:3", user_file)),
+            edge("java:class:UserService:__app_UserService:T3", "java:fn:BaseService:unresolved-reference:0-0", EdgeType::Implements, &format!("{}:5", user_file)),
+            edge("java:class:UserService:__app_UserService:T3", "java:fn:Repository:unresolved-reference:0-0", EdgeType::Implements, &format!("{}:5", user_file)),
+            // JDK type: not in the graph
+            edge("java:class:UserService:__app_UserService:T3", "java:fn:Serializable:unresolved-reference:0-0", EdgeType::Implements, &format!("{}:5", user_file)),
+        ];
+
+        assert_eq!(resolve_jvm_type_references(&entities, &mut edges), 2);
+        assert_eq!(edges[1].to_key.as_str(), "java:class:BaseService:__app_BaseService:T2");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("same_package"));
+        assert_eq!(edges[2].to_key.as_str(), "java:interface:Repository:__core_Repository:T1");
+        assert_eq!(edges[2].metadata_value("resolution"), Some("imported_type"));
+        assert!(edges[3].to_key.as_str().contains("unresolved-reference"));
+    }
+
+    #[test]
+    fn test_kotlin_resolves_java_types_and_constructors() {
+        let entities = fixture();
+        let mut edges = vec![
+            edge("kotlin:file:audit_src_AuditRepository.kt:1-1", "kotlin:module:com.acme.core.*:0-0", EdgeType::Uses, "audit/src/AuditRepository.kt:3"),
+            edge("kotlin:class:AuditRepository:__audit_AuditRepository:T4", "kotlin:fn:Repository:unresolved-reference:0-0", EdgeType::Implements, "audit/src/AuditRepository.kt:5"),
+            edge("kotlin:fn:wire:__audit_Wiring:T5", "kotlin:fn:AuditRepository:unresolved-reference:0-0", EdgeType::Calls, "audit/src/Wiring.kt:4"),
+            edge("kotlin:fn:wire:__audit_Wiring:T5", "kotlin:fn:save:unresolved-reference:0-0", EdgeType::Calls, "audit/src/Wiring.kt:5"),
+        ];
+
+        assert_eq!(resolve_jvm_type_references(&entities, &mut edges), 2);
+        assert_eq!(edges[1].to_key.as_str(), "java:interface:Repository:__core_Repository:T1");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("on_demand_import"));
+        assert_eq!(edges[2].to_key.as_str(), "kotlin:class:AuditRepository:__audit_AuditRepository:T4");
+        assert!(edges[3].to_key.as_str().contains("unresolved-reference"));
+    }
+
+    #[test]
+    fn test_import_beats_package_and_self_or_foreign_edges_stay() {
+        let mut entities = fixture();
+        // Same simple name in the referencing package: the explicit import wins
+        entities.push(create_go_test_entity(
+            "java:class:Repository:__app_Repository:T6",
+            "Repository",
+            EntityType::Class,
+            "app/src/main/java/com/acme/app/Repository.java",
+            &[("package_name", "com.acme.app")],
+        ));
+        entities.push(create_go_test_entity(
+            "go:fn:Handle:__api_handler:T7",
+            "Handle",
+            EntityType::Function,
+            "api/handler.go",
+            &[("package_name", "api")],
+        ));
+        let user_file = "app/src/main/java/com/acme/app/UserService.java";
+        let user = "java:class:UserService:__app_UserService:T3";
+        let at = |line: usize| format!("{}:{}", user_file, line);
+        let mut edges = vec![
+            edge(
+                &format!("java:file:{}:1-1", user_file),
+                "java:module:com.acme.core.Repository:0-0",
+                EdgeType::Uses,
+                &at(3),
+            ),
+            edge(user, "java:fn:Repository:unresolved-reference:0-0", EdgeType::Implements, &at(5)),
+            // `new UserService()` inside UserService: never a self-edge
+            edge(user, "java:fn:UserService:unresolved-reference:0-0", EdgeType::Calls, &at(9)),
+            // Go code naming a JVM type is not resolved here
+            edge(
+                "go:fn:Handle:__api_handler:T7",
+                "go:fn:BaseService:unresolved-reference:0-0",
+                EdgeType::Calls,
+                "api/handler.go:4",
+            ),
+        ];
+
+        assert_eq!(resolve_jvm_type_references(&entities, &mut edges), 1);
+        assert_eq!(edges[1].to_key.as_str(), "java:interface:Repository:__core_Repository:T1");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("imported_type"));
+        assert_eq!(
+            edges[1].metadata_value(UNRESOLVED_TARGET_METADATA_KEY),
+            Some("java:fn:Repository:unresolved-reference:0-0")
+        );
+        assert_eq!(edges[2].to_key.as_str(), "java:fn:UserService:unresolved-reference:0-0");
+        assert_eq!(edges[3].to_key.as_str(), "go:fn:BaseService:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_file_imports_split_types_from_packages() {
+        let file = "app/Main.java";
+        let edges = vec![
+            edge("java:file:Main:1-1", "java:module:com.acme.core.Repository:0-0", EdgeType::Uses, "app/Main.java:1"),
+            edge("java:file:Main:1-1", "java:module:com.acme.util.*:0-0", EdgeType::Uses, "app/Main.java:2"),
+            edge("java:file:Main:1-1", "java:module:com.acme.Config.load:0-0", EdgeType::Uses, "app/Main.java:3"),
+            // Not an import edge
+            edge("java:fn:main:Main:T1", "java:module:com.acme.Other:0-0", EdgeType::Calls, "app/Main.java:4"),
+        ];
+
+        let imports = collect_jvm_file_imports(&edges);
+        let main = &imports[file];
+        assert_eq!(main.single_type.len(), 1);
+        assert_eq!(main.single_type["Repository"], "com.acme.core.Repository");
+        // Wildcards drop `.*`; a static member import never names a package type
+        assert_eq!(main.on_demand_packages, vec!["com.acme.util".to_string(), "com.acme.Config.load".to_string()]);
+    }
+}
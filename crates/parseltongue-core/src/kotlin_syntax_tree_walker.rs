@@ -0,0 +1,520 @@
+//! Kotlin Syntax Tree Walker (v1.7.3)
+//!
+//! # 4-Word Naming: kotlin_syntax_tree_walker
+//!
+//! Kotlin entities and edges, extracted by walking the parse tree instead of
+//! running `entity_queries/kotlin.scm`. Kotlin grammar releases disagree on
+//! identifier node names (`simple_identifier` / `type_identifier` vs
+//! `identifier`), and a query naming an unknown node kind fails to compile;
+//! matching kinds in code accepts either spelling.
+//!
+//! ## Output (same shapes as the query path)
+//!
+//! - Entities: classes/objects (`Class`), interfaces, enum classes,
+//!   top-level/local functions (`Function`), member functions (`Method`)
+//! - Entity metadata: `package_name` (file `package` header),
+//!   `receiver_type` (enclosing class/object of a method)
+//! - `Calls`: `foo()`, `Foo()` (constructor) and `x.foo()` with
+//!   `receiver_expr` for identifier / `this` receivers
+//! - `Implements`: supertypes of a class/interface with `hierarchy_kind`
+//!   (`extends` when the supertype is constructed, `Base()`, else `implements`)
+//! - `Uses`: one file-level edge per `import` (`kotlin:module:{fqn}:0-0`,
+//!   wildcards keep their `.*`)
+
+use std::collections::HashMap;
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType, Language};
+use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path};
+use crate::query_extractor::{
+    key_component_for_entity_type, sanitize_name_double_colon, sanitize_path_for_key_format, EntityType,
+    ParsedEntity,
+};
+
+/// Node kinds used for names across Kotlin grammar releases
+const KOTLIN_NAME_NODE_KINDS: &[&str] = &["simple_identifier", "type_identifier", "identifier"];
+
+/// Class-like bodies whose supertypes are not part of the header
+const KOTLIN_BODY_NODE_KINDS: &[&str] = &["class_body", "enum_class_body"];
+
+/// A call or supertype reference awaiting its edge
+struct PendingKotlinReference {
+    from_index: usize,
+    target_name: String,
+    line: usize,
+    edge_type: EdgeType,
+    receiver_expr: Option<String>,
+    hierarchy_kind: Option<&'static str>,
+}
+
+/// Walk state: output plus the enclosing declarations of the current node
+struct KotlinWalkState<'s> {
+    source: &'s str,
+    file_path: String,
+    package_name: Option<String>,
+    entities: Vec<ParsedEntity>,
+    references: Vec<PendingKotlinReference>,
+}
+
+/// Extract Kotlin entities and dependency edges from a parsed file
+///
+/// # 4-Word Name: extract_kotlin_entities_and_edges
+///
+/// # Contract
+/// - Never fails: unknown node kinds are walked through
+/// - Edge keys match the query path (`kotlin:{kind}:{name}:{semantic_path}:T{ts}`
+///   → `kotlin:fn:{name}:unresolved-reference:0-0`)
+pub fn extract_kotlin_entities_and_edges(
+    tree: &tree_sitter::Tree,
+    source: &str,
+    file_path: &Path,
+) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+    let root = tree.root_node();
+    let mut state = KotlinWalkState {
+        source,
+        file_path: file_path.to_string_lossy().to_string(),
+        package_name: find_kotlin_package_name(root, source),
+        entities: Vec::new(),
+        references: Vec::new(),
+    };
+    walk_kotlin_declarations(root, &mut state, None, None);
+
+    let mut edges = build_kotlin_import_edges(root, source, file_path);
+    for reference in &state.references {
+        let from = &state.entities[reference.from_index];
+        let from_key = format!(
+            "kotlin:{}:{}:{}:T{}",
+            key_component_for_entity_type(&from.entity_type),
+            from.name,
+            extract_semantic_path(&from.file_path),
+            compute_birth_timestamp(&from.file_path, &from.name)
+        );
+        let mut builder = DependencyEdge::builder()
+            .from_key(from_key)
+            .to_key(format!(
+                "kotlin:fn:{}:unresolved-reference:0-0",
+                sanitize_name_double_colon(&reference.target_name)
+            ))
+            .edge_type(reference.edge_type)
+            .source_location(format!("{}:{}", state.file_path, reference.line));
+        if let Some(receiver) = &reference.receiver_expr {
+            builder = builder.metadata_entry("receiver_expr", receiver.clone());
+        }
+        if let Some(kind) = reference.hierarchy_kind {
+            builder = builder.metadata_entry("hierarchy_kind", kind);
+        }
+        if let Ok(edge) = builder.build() {
+            edges.push(edge);
+        }
+    }
+    (state.entities, edges)
+}
+
+fn node_text<'s>(node: tree_sitter::Node<'_>, source: &'s str) -> &'s str {
+    &source[node.byte_range()]
+}
+
+/// `name` field, else the first direct child with a name kind
+fn kotlin_declaration_name(node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    if let Some(name) = node.child_by_field_name("name") {
+        return Some(node_text(name, source).to_string());
+    }
+    let mut cursor = node.walk();
+    let found = node
+        .named_children(&mut cursor)
+        .find(|child| KOTLIN_NAME_NODE_KINDS.contains(&child.kind()))
+        .map(|child| node_text(child, source).to_string());
+    found
+}
+
+fn has_direct_child_of_kind(node: tree_sitter::Node<'_>, kinds: &[&str]) -> bool {
+    let mut cursor = node.walk();
+    let found = node.children(&mut cursor).any(|child| kinds.contains(&child.kind()));
+    found
+}
+
+fn first_descendant_of_kind<'t>(node: tree_sitter::Node<'t>, kind: &str) -> Option<tree_sitter::Node<'t>> {
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        if child.kind() == kind {
+            return Some(child);
+        }
+        if let Some(found) = first_descendant_of_kind(child, kind) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// `package com.acme.auth` → `com.acme.auth`
+fn find_kotlin_package_name(root: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    let mut cursor = root.walk();
+    let header = root
+        .named_children(&mut cursor)
+        .find(|child| child.kind() == "package_header")?;
+    let name = node_text(header, source)
+        .trim_start_matches("package")
+        .trim()
+        .trim_end_matches(';')
+        .trim()
+        .to_string();
+    (!name.is_empty()).then_some(name)
+}
+
+/// One `Uses` edge per import header; `import a.B as C` keeps `a.B`
+fn build_kotlin_import_edges(root: tree_sitter::Node<'_>, source: &str, file_path: &Path) -> Vec<DependencyEdge> {
+    let mut headers = Vec::new();
+    collect_kotlin_import_headers(root, &mut headers);
+    let display_path = file_path.display().to_string();
+    let from_key = format!("kotlin:file:{}:1-1", sanitize_path_for_key_format(&display_path));
+    headers
+        .into_iter()
+        .filter_map(|header| {
+            let text = node_text(header, source).trim().trim_start_matches("import").trim();
+            let path = text.split(" as ").next().unwrap_or(text).trim().trim_end_matches(';').trim();
+            if path.is_empty() {
+                return None;
+            }
+            let path: String = path.chars().filter(|c| !c.is_whitespace()).collect();
+            DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(format!("kotlin:module:{}:0-0", sanitize_name_double_colon(&path)))
+                .edge_type(EdgeType::Uses)
+                .source_location(format!("{}:{}", display_path, header.start_position().row + 1))
+                .build()
+                .ok()
+        })
+        .collect()
+}
+
+fn collect_kotlin_import_headers<'t>(node: tree_sitter::Node<'t>, headers: &mut Vec<tree_sitter::Node<'t>>) {
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        match child.kind() {
+            "import_header" | "import" => headers.push(child),
+            "import_list" | "imports" => collect_kotlin_import_headers(child, headers),
+            _ => {}
+        }
+    }
+}
+
+/// Recursive declaration walk
+///
+/// - `enclosing_type`: name of the innermost class/object/interface
+/// - `enclosing_function`: entity index calls are attributed to
+fn walk_kotlin_declarations(
+    node: tree_sitter::Node<'_>,
+    state: &mut KotlinWalkState<'_>,
+    enclosing_type: Option<&str>,
+    enclosing_function: Option<usize>,
+) {
+    let mut cursor = node.walk();
+    let children: Vec<_> = node.named_children(&mut cursor).collect();
+    for child in children {
+        match child.kind() {
+            "class_declaration" | "object_declaration" | "interface_declaration" => {
+                let Some(name) = kotlin_declaration_name(child, state.source) else {
+                    walk_kotlin_declarations(child, state, enclosing_type, enclosing_function);
+                    continue;
+                };
+                let entity_type = if child.kind() == "interface_declaration"
+                    || has_direct_child_of_kind(child, &["interface"])
+                {
+                    EntityType::Interface
+                } else if has_direct_child_of_kind(child, &["enum_class_body"]) {
+                    EntityType::Enum
+                } else {
+                    EntityType::Class
+                };
+                let is_interface = entity_type == EntityType::Interface;
+                let index = push_kotlin_entity(state, child, name.clone(), entity_type, None);
+                record_kotlin_supertypes(child, state, index, is_interface);
+                walk_kotlin_declarations(child, state, Some(&name), None);
+            }
+            "function_declaration" => {
+                let Some(name) = kotlin_declaration_name(child, state.source) else {
+                    continue;
+                };
+                // Local functions belong to no class
+                let (entity_type, receiver) = match (enclosing_type, enclosing_function) {
+                    (Some(owner), None) => (EntityType::Method, Some(owner)),
+                    _ => (EntityType::Function, None),
+                };
+                let index = push_kotlin_entity(state, child, name, entity_type, receiver);
+                walk_kotlin_declarations(child, state, enclosing_type, Some(index));
+            }
+            "call_expression" => {
+                if let Some(from_index) = enclosing_function {
+                    record_kotlin_call(child, state, from_index);
+                }
+                walk_kotlin_declarations(child, state, enclosing_type, enclosing_function);
+            }
+            _ => walk_kotlin_declarations(child, state, enclosing_type, enclosing_function),
+        }
+    }
+}
+
+fn push_kotlin_entity(
+    state: &mut KotlinWalkState<'_>,
+    node: tree_sitter::Node<'_>,
+    name: String,
+    entity_type: EntityType,
+    receiver_type: Option<&str>,
+) -> usize {
+    let mut metadata = HashMap::new();
+    if let Some(package) = &state.package_name {
+        metadata.insert("package_name".to_string(), package.clone());
+    }
+    if let Some(receiver) = receiver_type {
+        metadata.insert("receiver_type".to_string(), receiver.to_string());
+    }
+    state.entities.push(ParsedEntity {
+        entity_type,
+        name,
+        language: Language::Kotlin,
+        line_range: (node.start_position().row + 1, node.end_position().row + 1),
+        file_path: state.file_path.clone(),
+        metadata,
+    });
+    state.entities.len() - 1
+}
+
+/// `class A : Base(), Service<T>` → `Base` (extends), `Service` (implements)
+fn record_kotlin_supertypes(
+    node: tree_sitter::Node<'_>,
+    state: &mut KotlinWalkState<'_>,
+    from_index: usize,
+    is_interface: bool,
+) {
+    let mut specifiers = Vec::new();
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        if KOTLIN_BODY_NODE_KINDS.contains(&child.kind()) {
+            continue;
+        }
+        collect_delegation_specifiers(child, &mut specifiers);
+    }
+    for specifier in specifiers {
+        let Some(user_type) = first_descendant_of_kind(specifier, "user_type").or_else(|| {
+            KOTLIN_NAME_NODE_KINDS
+                .iter()
+                .find_map(|kind| first_descendant_of_kind(specifier, kind))
+        }) else {
+            continue;
+        };
+        let text = node_text(user_type, state.source);
+        let without_generics = text.split('<').next().unwrap_or(text);
+        let Some(name) = without_generics.rsplit('.').next().map(str::trim).filter(|n| !n.is_empty()) else {
+            continue;
+        };
+        let constructed = first_descendant_of_kind(specifier, "constructor_invocation").is_some();
+        state.references.push(PendingKotlinReference {
+            from_index,
+            target_name: name.to_string(),
+            line: specifier.start_position().row + 1,
+            edge_type: EdgeType::Implements,
+            receiver_expr: None,
+            hierarchy_kind: Some(if constructed || is_interface { "extends" } else { "implements" }),
+        });
+    }
+}
+
+fn collect_delegation_specifiers<'t>(node: tree_sitter::Node<'t>, specifiers: &mut Vec<tree_sitter::Node<'t>>) {
+    if node.kind() == "delegation_specifier" {
+        specifiers.push(node);
+        return;
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        collect_delegation_specifiers(child, specifiers);
+    }
+}
+
+/// `foo()`, `Foo()`, `x.foo()`, `this.foo()`
+fn record_kotlin_call(node: tree_sitter::Node<'_>, state: &mut KotlinWalkState<'_>, from_index: usize) {
+    let Some(callee) = node.named_child(0) else { return };
+    let (target, receiver) = match callee.kind() {
+        kind if KOTLIN_NAME_NODE_KINDS.contains(&kind) => (Some(node_text(callee, state.source)), None),
+        "navigation_expression" => {
+            let receiver = callee.named_child(0).and_then(|r| match r.kind() {
+                kind if KOTLIN_NAME_NODE_KINDS.contains(&kind) => Some(node_text(r, state.source).to_string()),
+                "this_expression" | "this" => Some("this".to_string()),
+                _ => None,
+            });
+            let count = callee.named_child_count();
+            let member = callee.named_child(count.saturating_sub(1)).filter(|_| count > 1).and_then(|last| {
+                if KOTLIN_NAME_NODE_KINDS.contains(&last.kind()) {
+                    Some(last)
+                } else {
+                    KOTLIN_NAME_NODE_KINDS.iter().find_map(|kind| first_descendant_of_kind(last, kind))
+                }
+            });
+            (member.map(|m| node_text(m, state.source)), receiver)
+        }
+        _ => (None, None),
+    };
+    let Some(target) = target.filter(|t| !t.is_empty()) else { return };
+    state.references.push(PendingKotlinReference {
+        from_index,
+        target_name: target.to_string(),
+        line: node.start_position().row + 1,
+        edge_type: EdgeType::Calls,
+        receiver_expr: receiver,
+        hierarchy_kind: None,
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn parse_kotlin(code: &str) -> (Vec<ParsedEntity>, Vec<DependencyEdge>) {
+        let mut parser = tree_sitter::Parser::new();
+        parser.set_language(&tree_sitter_kotlin_ng::LANGUAGE.into()).unwrap();
+        let tree = parser.parse(code, None).unwrap();
+        extract_kotlin_entities_and_edges(&tree, code, Path::new("src/auth/UserService.kt"))
+    }
+
+    #[test]
+    fn test_kotlin_classes_methods_and_hierarchy() {
+        let code = r#"
+package com.acme.auth
+
+import com.acme.core.Repository
+import com.acme.util.*
+
+interface Auditable
+
+class UserService(private val repo: Repository) : BaseService(), Auditable {
+    fun login(name: String) {
+        val hash = hashPassword(name)
+        repo.save(hash)
+        this.audit()
+    }
+
+    fun audit() {}
+}
+
+fun hashPassword(input: String): String = input
+"#;
+        let (entities, edges) = parse_kotlin(code);
+
+        let login = entities.iter().find(|e| e.name == "login").expect("login extracted");
+        assert_eq!(login.entity_type, EntityType::Method);
+        assert_eq!(login.metadata.get("receiver_type").map(String::as_str), Some("UserService"));
+        assert_eq!(login.metadata.get("package_name").map(String::as_str), Some("com.acme.auth"));
+        let top = entities.iter().find(|e| e.name == "hashPassword").expect("top-level fun");
+        assert_eq!(top.entity_type, EntityType::Function);
+        assert!(entities.iter().any(|e| e.name == "Auditable" && e.entity_type == EntityType::Interface));
+
+        let supertype = |name: &str| {
+            edges
+                .iter()
+                .find(|e| e.edge_type == EdgeType::Implements && e.to_key.as_str().contains(&format!(":{}:", name)))
+                .and_then(|e| e.metadata_value("hierarchy_kind"))
+        };
+        assert_eq!(supertype("BaseService"), Some("extends"));
+        assert_eq!(supertype("Auditable"), Some("implements"));
+
+        let receiver_of = |name: &str| {
+            edges
+                .iter()
+                .find(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(&format!(":{}:", name)))
+                .map(|e| e.metadata_value("receiver_expr"))
+        };
+        assert_eq!(receiver_of("hashPassword"), Some(None));
+        assert_eq!(receiver_of("save"), Some(Some("repo")));
+        assert_eq!(receiver_of("audit"), Some(Some("this")));
+
+        assert!(edges.iter().any(|e| e.to_key.as_str() == "kotlin:module:com.acme.core.Repository:0-0"));
+        assert!(edges.iter().any(|e| e.to_key.as_str() == "kotlin:module:com.acme.util.*:0-0"));
+    }
+
+    #[test]
+    fn test_kotlin_local_nested_enum_and_object_declarations() {
+        let code = r#"
+import com.acme.core.Repository as Repo
+
+val registry = buildRegistry()
+
+enum class Color { RED, GREEN }
+
+object Registry {
+    fun register() {}
+}
+
+class Outer {
+    class Inner {
+        fun run() {
+            fun local() {
+                helper()
+            }
+            local()
+        }
+    }
+}
+"#;
+        let (entities, edges) = parse_kotlin(code);
+        let entity = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .unwrap_or_else(|| panic!("{} extracted", name))
+        };
+
+        assert_eq!(entity("Color").entity_type, EntityType::Enum);
+        assert_eq!(entity("Registry").entity_type, EntityType::Class);
+        assert_eq!(entity("register").metadata.get("receiver_type").map(String::as_str), Some("Registry"));
+        // Innermost class owns the method; a local function belongs to no class
+        assert_eq!(entity("run").metadata.get("receiver_type").map(String::as_str), Some("Inner"));
+        assert_eq!(entity("local").entity_type, EntityType::Function);
+        assert_eq!(entity("local").metadata.get("receiver_type"), None);
+        // No package header: no package metadata
+        assert!(entities.iter().all(|e| !e.metadata.contains_key("package_name")));
+
+        let caller_of = |target: &str| {
+            edges
+                .iter()
+                .filter(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(&format!(":{}:", target)))
+                .map(|e| e.from_key.as_str().split(':').nth(2).unwrap_or_default().to_string())
+                .collect::<Vec<_>>()
+        };
+        assert_eq!(caller_of("helper"), vec!["local"]);
+        assert_eq!(caller_of("local"), vec!["run"]);
+        // Top-level property initializers have no enclosing function
+        assert!(caller_of("buildRegistry").is_empty());
+
+        // Aliased import keeps the imported path
+        assert!(edges.iter().any(|e| e.to_key.as_str() == "kotlin:module:com.acme.core.Repository:0-0"));
+        assert!(!edges.iter().any(|e| e.to_key.as_str().contains("Repo:0-0")));
+    }
+
+    #[test]
+    fn test_kotlin_interface_supertypes_extend() {
+        let code = r#"
+package com.acme.auth
+
+interface Admin : Auditable, Comparable<Admin>
+
+class Plain : Admin
+"#;
+        let (entities, edges) = parse_kotlin(code);
+        assert!(entities.iter().any(|e| e.name == "Admin" && e.entity_type == EntityType::Interface));
+
+        let kind = |from: &str, to: &str| {
+            edges
+                .iter()
+                .find(|e| {
+                    e.edge_type == EdgeType::Implements
+                        && e.from_key.as_str().contains(&format!(":{}:", from))
+                        && e.to_key.as_str().contains(&format!(":{}:", to))
+                })
+                .and_then(|e| e.metadata_value("hierarchy_kind"))
+        };
+        // An interface's supertypes are always interfaces it extends
+        assert_eq!(kind("Admin", "Auditable"), Some("extends"));
+        assert_eq!(kind("Admin", "Comparable"), Some("extends"));
+        // Not constructed: an interface the class implements
+        assert_eq!(kind("Plain", "Admin"), Some("implements"));
+    }
+}
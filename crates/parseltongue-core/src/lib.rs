@@ -8,21 +8,103 @@
 #![warn(rust_2018_idioms)]
 #![allow(missing_docs)]
 
+pub mod api_surface_diff_reporter; // v1.7.3: Exported symbol diff between revisions (api-diff)
+pub mod bm25_hybrid_keyword_retriever; // v1.7.3: BM25 keyword search blended with graph proximity
+pub mod c_include_header_resolver; // v1.7.3: C/C++ and cgo Includes edges bound to repo headers, header-aware calls
+pub mod call_hierarchy_tree_builder; // v1.7.3: IDE-style call hierarchy trees
+pub mod canonical_symbol_id_normalizer; // v1.7.3: Cross-language canonical symbol IDs
+pub mod codeowners_ownership_rule_matcher; // v1.7.3: CODEOWNERS owner teams as `code_owners` metadata
+pub mod colliding_symbol_key_disambiguator; // v1.7.3: Signature-hash keys for same-name symbols in one file
+pub mod config_file_reference_linker; // v1.7.3: ConfiguredBy edges to env vars, flag keys, handlers and binaries in YAML/JSON
+pub mod context_pack_token_accounting; // v1.7.3: Model presets, tokenizers and per-symbol token reports for packs
+pub mod context_prompt_template_renderer; // v1.7.3: Go text/template-style prompt layouts for context packs
+pub mod cypher_subset_query_evaluator; // v1.7.3: Read-only MATCH/WHERE/RETURN for Neo4j-style queries
+pub mod dead_code_detection_analyzer; // v1.7.3: Symbols with zero inbound edges
+pub mod dependency_cycle_breaking_analyzer; // v1.7.3: Package/symbol cycles with minimal break edges
+pub mod derived_symbol_artifact_invalidator; // v1.7.3: Keep summaries/embeddings of unchanged symbols on re-index
+pub mod dynamic_dispatch_call_expander; // v1.7.3: Interface / trait object call fan-out
+pub mod edge_confidence_provenance_scorer; // v1.7.3: Edge provenance + confidence score, --min-confidence
+pub mod embedded_sql_table_linker; // v1.7.3: Embedded SQL QueriesTable edges to migration schema
 pub mod entities;
 pub mod entity_class_specifications;
+pub mod entry_point_reachable_slicer; // v1.7.3: main/HTTP/gRPC/CLI/job entry points and their reachable slices
 // pub mod entity_conversion; // P5: Entity conversion utilities (TODO: implement)
 pub mod error;
+pub mod external_code_index_importer; // v1.7.3: SCIP/LSIF index import
+pub mod external_plugin_process_protocol; // v1.7.3: Out-of-process extractor/enricher plugins (NDJSON stdio)
+pub mod extractor_capability_manifest_builder; // v1.7.3: Per-language extractor capabilities and versions (capabilities)
+pub mod federated_repository_graph_merger; // v1.7.3: Multi-repository graph union with cross-repo edge binding
+pub mod ffi_symbol_bridge_linker; // v1.7.3: cgo / Rust extern / C callback calls bridged by exported symbol
+pub mod filtered_graph_traversal_queries; // v1.7.3: Edge-type filtered blast radius/paths
+pub mod fuzzy_symbol_name_matcher; // v1.7.3: Camel-hump fuzzy symbol search with package proximity
+pub mod git_blame_ownership_annotator; // v1.7.3: Last commit/author/owner from git blame
+pub mod git_diff_context_scoper; // v1.7.3: Context bundles scoped to a git diff
+pub mod go_build_constraint_evaluator; // v1.7.3: Go build tags and GOOS/GOARCH file selection
+pub mod go_channel_operation_extractor; // v1.7.3: Go channel SendsTo/ReceivesFrom edges
+pub mod go_closure_literal_extractor; // v1.7.3: Go func literals as Function nodes with captures
+pub mod go_context_propagation_auditor; // v1.7.3: Dropped/shadowed/replaced context.Context audit
+pub mod go_dynamic_usage_detector; // v1.7.3: Go reflect/plugin/registry dynamic_usage flags
+pub mod go_embedding_promotion_resolver; // v1.7.3: Go Embeds + promoted method calls
+pub mod go_error_propagation_extractor; // v1.7.3: Go error-return PropagatesErrorTo edges
+pub mod go_field_access_extractor; // v1.7.3: Go struct field Reads/Writes edges
+pub mod go_generate_lineage_tracker; // v1.7.3: go:generate / DO NOT EDIT lineage, GeneratedFrom edges
+pub mod go_import_alias_resolver; // v1.7.3: Go import aliases and dot imports
+pub mod go_module_version_resolver; // v1.7.3: Go external module path/version annotation
+pub mod go_package_init_extractor; // v1.7.3: Go package init node: init funcs, var initializers, blank imports
+pub mod go_package_value_extractor; // v1.7.3: Go package-level const/var Uses edges
+pub mod go_panic_recover_extractor; // v1.7.3: Go panic sources, recover handlers, deferred calls
+pub mod go_test_coverage_linker; // v1.7.3: Go test facet + Tests edges
+pub mod go_type_reference_extractor; // v1.7.3: Go type usage Uses edges (fields, params, returns, vars, assertions)
+pub mod go_typed_selector_resolver; // v1.7.3: --typed Go call binding via go/packages + go/types
 // pub mod file_parser; // P1: Thread-safe file parser facade (TODO: implement)
 pub mod graph_analysis; // v1.6.0: Shared graph infrastructure for 7 analysis algorithms
+pub mod graph_node_slice_filter; // v1.7.3: --match/--kind/--visibility node filters for exports and queries
+pub mod graph_query_dsl_evaluator; // v1.7.3: callers(X) & in_package("api") depth 2 query language
+pub mod graph_delta_narrative_reporter; // v1.7.3: Changelog-style bullets from graph diffs (`report delta`)
+pub mod graph_snapshot_diff_reporter; // v1.7.3: Added/removed/changed nodes and edges between snapshots
+pub mod http_route_handler_mapper; // v1.7.3: Router registrations as Route nodes bound to handler functions
+pub mod inline_suppression_directive_parser; // v1.7.3: //companion:ignore / //companion:entrypoint node directives
+pub mod interface_impl_pack_expander; // v1.7.3: --expand-impls: capped interface implementations in packs
 pub mod interfaces;
 pub mod isgl1_v2; // v1.4.5: ISGL1 v2 stable entity identity with birth timestamps
+pub mod javascript_module_import_resolver; // v1.7.3: JS/TS import, barrel and tsconfig alias resolution
+pub mod jvm_type_hierarchy_resolver; // v1.7.3: Java/Kotlin supertype and constructor resolution
+pub mod kotlin_syntax_tree_walker; // v1.7.3: Kotlin extraction (tree-sitter-kotlin-ng)
+pub mod llm_symbol_summary_cache; // v1.7.3: LLM symbol summaries with on-disk cache
+pub mod lsif_dump_graph_decoder; // v1.7.3: LSIF dump reader for index import
+pub mod otlp_trace_span_exporter; // v1.7.3: OpenTelemetry spans over OTLP/HTTP JSON
+pub mod outbound_http_post_client; // v1.7.3: Shared HTTP(S) client for summaries, OTLP and webhooks
 pub mod output_path_resolver; // v0.9.7: Timestamped folder creation
+pub mod package_boundary_subgraph_extractor; // v1.7.3: Package-scoped graph slice with frontier layers (subgraph)
+pub mod pr_review_bundle_generator; // v1.7.3: Markdown review pack for a git diff
+pub mod protobuf_service_definition_linker; // v1.7.3: .proto service/rpc nodes linked to gRPC stubs
+pub mod protobuf_wire_format_codec; // v1.7.3: Minimal protobuf codec for SCIP
+pub mod python_import_call_resolver; // v1.7.3: Python self/import call resolution
 pub mod query_extractor;
 pub mod query_json_graph_errors; // v0.9.7: Agent query error types
 pub mod query_json_graph_helpers; // v0.9.7: Agent JSON graph traversal
+pub mod recency_relevance_score_booster; // v1.7.3: Git-recency and ticket boosts for context ranking
+pub mod rename_impact_site_planner; // v1.7.3: Files/lines a symbol rename must touch
+pub mod rust_cfg_feature_evaluator; // v1.7.3: Cargo feature sets and #[cfg] conditions
+pub mod rust_macro_symbol_attributor; // v1.7.3: derive / macro_rules! generated symbols
+pub mod rust_trait_impl_bound_extractor; // v1.7.3: Rust impl Trait for Type / trait bound edges
+pub mod safety_boundary_facet_tagger; // v1.7.3: unsafe/FFI/cgo boundary facet + boundary-crossing paths
+pub mod security_sink_reachability_analyzer; // v1.7.3: Dangerous sinks reachable from HTTP/RPC entry points
+pub mod semantic_symbol_embedding_index; // v1.7.3: Symbol embeddings + semantic search
 pub mod serializers; // v0.10.0: Core serialization (JSON, TOON)
+pub mod source_extraction_fuzz_driver; // v1.7.3: Shared entry point of the extractor fuzzers
 pub mod storage;
+pub mod struct_tag_serialization_mapper; // v1.7.3: Struct tags / serde names as Serializes edges to wire fields
+pub mod structural_interface_matcher; // v1.7.3: Go implicit interface satisfaction
+pub mod symbol_centrality_score_ranker; // v1.7.3: PageRank/betweenness symbol importance
+pub mod symbol_doc_signature_extractor; // v1.7.3: Doc comments, signatures, visibility on nodes
+pub mod symbol_history_timeline_reporter; // v1.7.3: Appeared / signature / callers timeline across revisions
 pub mod temporal;
+pub mod token_budget_context_packer; // v1.7.3: Tokenizer-exact context bundles
+pub mod traversal_expansion_limit_guards; // v1.7.3: --max-depth/--max-fanout/--max-nodes guards + truncation markers
+pub mod type_hierarchy_tree_builder; // v1.7.3: Supertype/subtype trees (satisfies, implements, embeds, supertraits)
+pub mod unresolved_reference_cause_reporter; // v1.7.3: Unbound references grouped by cause (analyze unresolved)
+pub mod workspace_module_root_detector; // v1.7.3: Monorepo go.mod/Cargo.toml/package.json module roots
 
 // Re-export commonly used types
 pub use entities::*;
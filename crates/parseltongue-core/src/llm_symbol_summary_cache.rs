@@ -0,0 +1,355 @@
+//! LLM symbol summaries with an on-disk cache (v1.7.3)
+//!
+//! # 4-Word Naming: llm_symbol_summary_cache
+//!
+//! `parseltongue summarize` sends each symbol (functions, types, modules)
+//! to an OpenAI-compatible chat completions endpoint (Ollama, vLLM,
+//! llama.cpp, or a hosted https API) and stores the one-paragraph
+//! answer in the entity's `summary` metadata. Context packing then uses a
+//! summary where the body does not fit, so a tight budget still says what
+//! each symbol does.
+//!
+//! ## Cache
+//!
+//! Answers are also kept on disk, shared by every repository and branch:
+//!
+//! ```text
+//! ~/.cache/parseltongue-companion/summaries/v{SUMMARY_CACHE_SCHEMA_VERSION}/ab/abcdef....json
+//! ```
+//!
+//! The file name is the symbol hash: SHA-256 of the model name and the exact
+//! prompt (prompt version, kind, name, file path, code). An unchanged symbol
+//! never costs a second request, and any edit to it misses.
+//!
+//! ## Resumability and rate limiting
+//!
+//! Each answer is written to the cache as soon as it arrives (temporary file,
+//! then rename), so an interrupted run, or one stopped by `--limit`, resumes
+//! where it left off. Requests are paced to `requests_per_minute`; HTTP
+//! 429/502/503 answers are retried after `Retry-After` (else 2 s, doubling)
+//! up to `MAX_SUMMARY_REQUEST_ATTEMPTS` times.
+//!
+//! Requests go through `outbound_http_post_client`, so hosted `https://`
+//! APIs work directly.
+
+use std::path::{Path, PathBuf};
+use std::time::{Duration, Instant};
+
+use serde::{Deserialize, Serialize};
+use serde_json::{json, Value};
+
+use crate::entities::CodeEntity;
+use crate::isgl1_v2::compute_content_hash;
+use crate::outbound_http_post_client::{
+    parse_http_endpoint_url, post_json_body_blocking, HttpEndpointUrlParts, HttpPostResponseOutcome,
+};
+
+/// Bump when the stored record layout changes
+pub const SUMMARY_CACHE_SCHEMA_VERSION: u32 = 1;
+
+/// Bump when the prompt changes: every symbol hash changes with it
+pub const SUMMARY_PROMPT_VERSION: u32 = 1;
+
+/// Entity metadata holding the summary text
+pub const SUMMARY_METADATA_KEY: &str = "summary";
+
+/// Entity metadata holding the symbol hash the summary was made from
+pub const SUMMARY_HASH_METADATA_KEY: &str = "summary_hash";
+
+/// Entity metadata holding the model that wrote the summary
+pub const SUMMARY_MODEL_METADATA_KEY: &str = "summary_model";
+
+/// Request pacing when neither flag nor config sets one
+pub const DEFAULT_SUMMARY_REQUESTS_PER_MINUTE: u32 = 60;
+
+/// Attempts per symbol on 429/502/503 before giving up
+pub const MAX_SUMMARY_REQUEST_ATTEMPTS: u32 = 4;
+
+/// Source lines of one symbol sent at most
+const MAX_SUMMARY_INPUT_LINES: usize = 300;
+
+/// Answer length asked of the model
+const SUMMARY_MAX_OUTPUT_TOKENS: u32 = 200;
+
+const SUMMARY_REQUEST_TIMEOUT: Duration = Duration::from_secs(120);
+
+const SUMMARY_SYSTEM_PROMPT: &str = "You summarize source code for other developers. Answer with one plain \
+paragraph of at most three sentences: what the symbol does, its important inputs and side effects. Do not \
+restate the signature, do not use markdown.";
+
+/// Chat completions endpoint, model and pacing
+///
+/// # 4-Word Name: LlmSummaryEndpointSpec
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct LlmSummaryEndpointSpec {
+    /// `http(s)://host[:port]/path`, e.g. `http://localhost:11434/v1/chat/completions`
+    pub url: String,
+    pub model: String,
+    /// Sent as `Authorization: Bearer ...` when set
+    pub api_key: Option<String>,
+    pub requests_per_minute: u32,
+}
+
+/// One cached summary
+///
+/// # 4-Word Name: CachedSymbolSummaryRecord
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct CachedSymbolSummaryRecord {
+    pub model: String,
+    /// Key of the symbol that first produced the summary (informational)
+    pub isgl1_key: String,
+    pub summary: String,
+}
+
+/// Prompt text for one symbol, None when it has no stored code
+///
+/// # 4-Word Name: build_symbol_summary_prompt
+///
+/// # Contract
+/// - Postcondition: deterministic for the same entity; code past
+///   `MAX_SUMMARY_INPUT_LINES` lines is cut with a `...` marker
+pub fn build_symbol_summary_prompt(entity: &CodeEntity) -> Option<String> {
+    let code = entity.current_code.as_deref().filter(|code| !code.trim().is_empty())?;
+    let mut segments = entity.isgl1_key.split(':');
+    let language = segments.next().unwrap_or_default();
+    let kind = segments.next().unwrap_or("symbol");
+    let mut lines: Vec<&str> = code.trim_end().lines().take(MAX_SUMMARY_INPUT_LINES + 1).collect();
+    if lines.len() > MAX_SUMMARY_INPUT_LINES {
+        lines.truncate(MAX_SUMMARY_INPUT_LINES);
+        lines.push("...");
+    }
+    Some(format!(
+        "Summarize the {} {} `{}` from {}:\n\n{}",
+        language,
+        kind,
+        entity.interface_signature.name,
+        entity.interface_signature.file_path.display(),
+        lines.join("\n")
+    ))
+}
+
+/// Symbol hash: cache file name and `summary_hash` of one prompt
+///
+/// # 4-Word Name: compute_summary_cache_key
+pub fn compute_summary_cache_key(model: &str, prompt: &str) -> String {
+    compute_content_hash(&format!("v{}\n{}\n{}", SUMMARY_PROMPT_VERSION, model, prompt))
+}
+
+/// Summaries of one cache directory
+///
+/// # 4-Word Name: SymbolSummaryCacheStore
+#[derive(Debug, Clone)]
+pub struct SymbolSummaryCacheStore {
+    root: PathBuf,
+}
+
+impl SymbolSummaryCacheStore {
+    /// Store under `root/summaries/` (created lazily on first write)
+    ///
+    /// # 4-Word Name: open_summary_cache_store
+    pub fn open_summary_cache_store(root: impl Into<PathBuf>) -> Self {
+        Self { root: root.into() }
+    }
+
+    pub fn root(&self) -> &Path {
+        &self.root
+    }
+
+    /// Cached summary for a symbol hash; unreadable records are misses
+    ///
+    /// # 4-Word Name: load_cached_symbol_summary
+    pub fn load_cached_symbol_summary(&self, key: &str) -> Option<CachedSymbolSummaryRecord> {
+        let bytes = std::fs::read(self.summary_path(key)).ok()?;
+        serde_json::from_slice(&bytes).ok()
+    }
+
+    /// Write one summary (temporary file, then rename)
+    ///
+    /// # 4-Word Name: store_symbol_summary_record
+    pub fn store_symbol_summary_record(&self, key: &str, record: &CachedSymbolSummaryRecord) -> std::io::Result<()> {
+        let path = self.summary_path(key);
+        if let Some(parent) = path.parent() {
+            std::fs::create_dir_all(parent)?;
+        }
+        let bytes = serde_json::to_vec(record).map_err(|e| std::io::Error::new(std::io::ErrorKind::Other, e))?;
+        let temporary = path.with_extension(format!("tmp{}", std::process::id()));
+        std::fs::write(&temporary, bytes)?;
+        std::fs::rename(&temporary, &path)
+    }
+
+    fn summary_path(&self, key: &str) -> PathBuf {
+        let shard = key.get(..2).unwrap_or("00");
+        self.root
+            .join("summaries")
+            .join(format!("v{}", SUMMARY_CACHE_SCHEMA_VERSION))
+            .join(shard)
+            .join(format!("{}.json", key))
+    }
+}
+
+/// Spaces request starts `60 / requests_per_minute` seconds apart
+///
+/// # 4-Word Name: SummaryRequestPacerState
+#[derive(Debug)]
+pub struct SummaryRequestPacerState {
+    interval: Duration,
+    last_start: Option<Instant>,
+}
+
+impl SummaryRequestPacerState {
+    pub fn for_requests_per_minute(requests_per_minute: u32) -> Self {
+        let interval = Duration::from_secs_f64(60.0 / requests_per_minute.max(1) as f64);
+        Self { interval, last_start: None }
+    }
+
+    /// How long to wait before the next request may start; marks it started
+    ///
+    /// # 4-Word Name: reserve_next_request_slot
+    pub fn reserve_next_request_slot(&mut self) -> Duration {
+        let now = Instant::now();
+        let start = match self.last_start {
+            Some(last) => (last + self.interval).max(now),
+            None => now,
+        };
+        self.last_start = Some(start);
+        start - now
+    }
+}
+
+/// Ask the endpoint for one summary (blocking, with retries)
+///
+/// # 4-Word Name: request_symbol_summary_blocking
+///
+/// # Contract
+/// - Postcondition: the answer's first choice, whitespace collapsed into
+///   one paragraph; never empty
+/// - Error: transport failures, non-2xx answers other than the retried
+///   429/502/503, malformed JSON, empty answers, or retries exhausted
+pub fn request_symbol_summary_blocking(endpoint: &LlmSummaryEndpointSpec, prompt: &str) -> Result<String, String> {
+    let address = parse_llm_endpoint_url(&endpoint.url)?;
+    let authorization = endpoint.api_key.as_ref().map(|key| format!("Bearer {}", key));
+    let headers: Vec<(&str, &str)> = authorization.iter().map(|value| ("Authorization", value.as_str())).collect();
+    let body = json!({
+        "model": endpoint.model,
+        "messages": [
+            {"role": "system", "content": SUMMARY_SYSTEM_PROMPT},
+            {"role": "user", "content": prompt},
+        ],
+        "temperature": 0.2,
+        "max_tokens": SUMMARY_MAX_OUTPUT_TOKENS,
+        "stream": false,
+    })
+    .to_string();
+
+    let mut backoff = Duration::from_secs(2);
+    for attempt in 1..=MAX_SUMMARY_REQUEST_ATTEMPTS {
+        let HttpPostResponseOutcome { status, retry_after, body: text } =
+            post_json_body_blocking(&address, &headers, body.clone().into_bytes(), SUMMARY_REQUEST_TIMEOUT)
+                .map_err(|e| format!("summary request to {} failed: {}", endpoint.url, e))?;
+        if matches!(status, 429 | 502 | 503) && attempt < MAX_SUMMARY_REQUEST_ATTEMPTS {
+            std::thread::sleep(retry_after.unwrap_or(backoff));
+            backoff *= 2;
+            continue;
+        }
+        if !(200..300).contains(&status) {
+            let detail: String = text.chars().take(200).collect();
+            return Err(format!("{} answered HTTP {}: {}", endpoint.url, status, detail.trim()));
+        }
+        let reply: Value = serde_json::from_str(&text).map_err(|e| format!("{} sent invalid JSON: {}", endpoint.url, e))?;
+        let content = reply
+            .pointer("/choices/0/message/content")
+            .and_then(Value::as_str)
+            .unwrap_or_default();
+        let paragraph = content.split_whitespace().collect::<Vec<_>>().join(" ");
+        if paragraph.is_empty() {
+            return Err(format!("{} sent an empty answer", endpoint.url));
+        }
+        return Ok(paragraph);
+    }
+    Err(format!("{} kept rate limiting after {} attempts", endpoint.url, MAX_SUMMARY_REQUEST_ATTEMPTS))
+}
+
+/// Parse a chat completions endpoint URL
+///
+/// # 4-Word Name: parse_llm_endpoint_url
+///
+/// # Contract
+/// - Postcondition: `http://` or `https://`; port defaults to 80 / 443, a URL
+///   without a path gets `/v1/chat/completions`
+/// - Error: other schemes, no host, invalid port
+pub fn parse_llm_endpoint_url(url: &str) -> Result<HttpEndpointUrlParts, String> {
+    let mut address = parse_http_endpoint_url(url, "summary endpoint")?;
+    if address.path == "/" {
+        address.path = "/v1/chat/completions".to_string();
+    }
+    Ok(address)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+    use crate::outbound_http_post_client::serve_canned_http_test_answers;
+
+    #[test]
+    fn test_prompt_hash_and_cache_round_trip() {
+        let mut entity = create_go_test_entity("go:fn:Charge:__svc:T1", "Charge", EntityType::Function, "svc/pay.go", &[]);
+        entity.current_code = Some("func Charge(c Card) error {\n    return gateway.Charge(c)\n}".to_string());
+        let prompt = build_symbol_summary_prompt(&entity).unwrap();
+        assert!(prompt.contains("`Charge` from svc/pay.go") && prompt.contains("gateway.Charge"));
+        let key = compute_summary_cache_key("llama3.1", &prompt);
+        assert_ne!(key, compute_summary_cache_key("qwen2.5", &prompt));
+
+        entity.current_code = Some(format!("func Big() {{\n{}}}", "    step()\n".repeat(400)));
+        assert!(build_symbol_summary_prompt(&entity).unwrap().ends_with("\n..."));
+        entity.current_code = None;
+        assert!(build_symbol_summary_prompt(&entity).is_none());
+
+        let dir = tempfile::tempdir().unwrap();
+        let store = SymbolSummaryCacheStore::open_summary_cache_store(dir.path());
+        assert!(store.load_cached_symbol_summary(&key).is_none());
+        let record = CachedSymbolSummaryRecord {
+            model: "llama3.1".to_string(),
+            isgl1_key: "go:fn:Charge:__svc:T1".to_string(),
+            summary: "Charges a card through the gateway.".to_string(),
+        };
+        store.store_symbol_summary_record(&key, &record).unwrap();
+        assert_eq!(store.load_cached_symbol_summary(&key), Some(record));
+
+        let mut pacer = SummaryRequestPacerState::for_requests_per_minute(60);
+        assert_eq!(pacer.reserve_next_request_slot(), Duration::ZERO);
+        assert!(pacer.reserve_next_request_slot() > Duration::from_millis(900));
+    }
+
+    #[test]
+    fn test_request_retries_rate_limit_then_reads_chunked_answer() {
+        let local = parse_llm_endpoint_url("http://localhost:11434").unwrap();
+        assert_eq!((local.host.as_str(), local.port), ("localhost", 11434));
+        assert_eq!(local.path, "/v1/chat/completions");
+        let hosted = parse_llm_endpoint_url("https://api.openai.com/v1/chat/completions").unwrap();
+        assert_eq!((hosted.https, hosted.port, hosted.path.as_str()), (true, 443, "/v1/chat/completions"));
+        assert!(parse_llm_endpoint_url("ftp://models.local").is_err());
+
+        let body = r#"{"choices":[{"message":{"role":"assistant","content":"Charges a card.\n Retries once."}}]}"#;
+        let answers = vec![
+            b"HTTP/1.1 429 Too Many Requests\r\nRetry-After: 0\r\nContent-Length: 0\r\n\r\n".to_vec(),
+            format!("HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n{:x}\r\n{}\r\n0\r\n\r\n", body.len(), body)
+                .into_bytes(),
+        ];
+        let (port, server) = serve_canned_http_test_answers(answers);
+
+        let endpoint = LlmSummaryEndpointSpec {
+            url: format!("http://127.0.0.1:{}/v1/chat/completions", port),
+            model: "llama3.1".to_string(),
+            api_key: Some("secret".to_string()),
+            requests_per_minute: 60,
+        };
+        let summary = request_symbol_summary_blocking(&endpoint, "Summarize `Charge`").unwrap();
+        assert_eq!(summary, "Charges a card. Retries once.");
+        let requests = server.join().unwrap();
+        assert_eq!(requests.len(), 2);
+        assert!(requests[1].to_ascii_lowercase().contains("authorization: bearer secret"), "{}", requests[1]);
+        assert!(requests[1].contains("\"model\":\"llama3.1\""));
+    }
+}
@@ -0,0 +1,299 @@
+//! LSIF dump decoder (v1.7.3)
+//!
+//! # 4-Word Naming: lsif_dump_graph_decoder
+//!
+//! Reads an LSIF dump (newline-delimited JSON, or one JSON array) into the
+//! format-neutral `CodeIndexImportModel` of `external_code_index_importer`.
+//!
+//! ## Mapping
+//!
+//! - `document` vertices + `contains` edges → documents and their ranges
+//! - `range` → `next` → `resultSet` chain identifies the symbol; a `moniker`
+//!   on the result set gives a stable identifier (and export/local kind)
+//! - ranges listed by `item` edges of a `definitionResult` are definitions;
+//!   every other range is a reference
+//! - optional range `tag` (`text`, `kind`, `fullRange`) supplies name, LSP
+//!   SymbolKind and the enclosing range
+
+use std::collections::{HashMap, HashSet};
+
+use serde_json::Value;
+
+use crate::entities::EntityType;
+use crate::error::{ParseltongError, Result};
+use crate::external_code_index_importer::{
+    path_from_file_uri, CodeIndexImportModel, IndexedDocumentRecord, IndexedOccurrenceRecord,
+    IndexedSymbolRecord,
+};
+
+/// Decode an LSIF dump
+///
+/// # 4-Word Name: decode_lsif_dump_model
+///
+/// # Contract
+/// - Accepts NDJSON (one element per line) or a single JSON array
+/// - Symbols are `moniker identifier` when present, else `lsif:{resultSetId}`
+/// - Documents outside `projectRoot` keep their absolute path
+pub fn decode_lsif_dump_model(bytes: &[u8]) -> Result<CodeIndexImportModel> {
+    let text = String::from_utf8_lossy(bytes);
+    let elements: Vec<Value> = if text.trim_start().starts_with('[') {
+        serde_json::from_str(&text).map_err(lsif_json_error)?
+    } else {
+        text.lines()
+            .filter(|line| !line.trim().is_empty())
+            .map(|line| serde_json::from_str(line).map_err(lsif_json_error))
+            .collect::<Result<_>>()?
+    };
+
+    let mut vertices: HashMap<u64, &Value> = HashMap::new();
+    let mut next_of: HashMap<u64, u64> = HashMap::new();
+    let mut moniker_of: HashMap<u64, u64> = HashMap::new();
+    let mut document_of_range: HashMap<u64, u64> = HashMap::new();
+    let mut definition_ranges: HashSet<u64> = HashSet::new();
+    let mut definition_result_ids: HashSet<u64> = HashSet::new();
+    let mut definition_items: Vec<Vec<u64>> = Vec::new();
+    let mut project_root: Option<String> = None;
+
+    for element in &elements {
+        let Some(id) = element_id_value(element.get("id")) else { continue };
+        let label = element.get("label").and_then(Value::as_str).unwrap_or("");
+        match element.get("type").and_then(Value::as_str) {
+            Some("vertex") => {
+                if label == "metaData" {
+                    project_root = element.get("projectRoot").and_then(Value::as_str).map(str::to_string);
+                }
+                if label == "definitionResult" {
+                    definition_result_ids.insert(id);
+                }
+                vertices.insert(id, element);
+            }
+            Some("edge") => {
+                let out_v = element_id_value(element.get("outV"));
+                let in_vs = edge_target_ids(element);
+                match (label, out_v) {
+                    ("next", Some(out)) => {
+                        if let Some(&target) = in_vs.first() {
+                            next_of.insert(out, target);
+                        }
+                    }
+                    ("moniker", Some(out)) => {
+                        if let Some(&target) = in_vs.first() {
+                            moniker_of.insert(out, target);
+                        }
+                    }
+                    ("contains", Some(out)) => {
+                        for target in in_vs {
+                            document_of_range.insert(target, out);
+                        }
+                    }
+                    ("item", Some(out)) => {
+                        // Checked after the pass: definitionResult may come later
+                        definition_items.push(std::iter::once(out).chain(in_vs).collect());
+                    }
+                    _ => {}
+                }
+            }
+            _ => {}
+        }
+    }
+    for items in &definition_items {
+        if definition_result_ids.contains(&items[0]) {
+            definition_ranges.extend(items[1..].iter().copied());
+        }
+    }
+
+    let root_path = project_root.as_deref().map(path_from_file_uri);
+    let mut model = CodeIndexImportModel {
+        format_name: "lsif".to_string(),
+        project_root: root_path.clone(),
+        ..Default::default()
+    };
+
+    let mut document_indexes: HashMap<u64, usize> = HashMap::new();
+    let mut document_ids: Vec<u64> = vertices
+        .iter()
+        .filter(|(_, v)| v.get("label").and_then(Value::as_str) == Some("document"))
+        .map(|(id, _)| *id)
+        .collect();
+    document_ids.sort_unstable();
+    for id in document_ids {
+        let vertex = vertices[&id];
+        let uri = vertex.get("uri").and_then(Value::as_str).unwrap_or("");
+        let absolute = path_from_file_uri(uri);
+        let relative_path = root_path
+            .as_deref()
+            .and_then(|root| absolute.strip_prefix(root).ok())
+            .unwrap_or(absolute.as_path())
+            .to_string_lossy()
+            .trim_start_matches('/')
+            .to_string();
+        document_indexes.insert(id, model.documents.len());
+        model.documents.push(IndexedDocumentRecord {
+            relative_path,
+            language: vertex.get("languageId").and_then(Value::as_str).unwrap_or("").to_lowercase(),
+            text: None,
+        });
+    }
+
+    let mut range_ids: Vec<u64> = document_of_range.keys().copied().collect();
+    range_ids.sort_unstable();
+    for range_id in range_ids {
+        let Some(range) = vertices.get(&range_id).filter(|v| v.get("label").and_then(Value::as_str) == Some("range")) else {
+            continue;
+        };
+        let Some(&document_index) = document_of_range.get(&range_id).and_then(|doc| document_indexes.get(doc)) else {
+            continue;
+        };
+
+        // Follow range -> resultSet -> resultSet ... to the last link
+        let mut result_set = range_id;
+        let mut hops = 0;
+        while let Some(&next) = next_of.get(&result_set) {
+            result_set = next;
+            hops += 1;
+            if hops > 16 {
+                break;
+            }
+        }
+        let moniker = moniker_of.get(&result_set).and_then(|id| vertices.get(id));
+        let symbol = moniker
+            .and_then(|m| {
+                let scheme = m.get("scheme").and_then(Value::as_str).unwrap_or("lsif");
+                let identifier = m.get("identifier").and_then(Value::as_str)?;
+                Some(format!("{}:{}", scheme, identifier))
+            })
+            .unwrap_or_else(|| format!("lsif:{}", result_set));
+
+        let is_definition = definition_ranges.contains(&range_id);
+        let tag = range.get("tag");
+        let full_range_end = tag
+            .and_then(|t| t.pointer("/fullRange/end/line"))
+            .and_then(Value::as_u64)
+            .map(|l| l as u32);
+
+        model.occurrences.push(IndexedOccurrenceRecord {
+            document_index,
+            symbol: symbol.clone(),
+            line: range.pointer("/start/line").and_then(Value::as_u64).unwrap_or(0) as u32,
+            start_character: range.pointer("/start/character").and_then(Value::as_u64).unwrap_or(0) as u32,
+            end_character: range.pointer("/end/character").and_then(Value::as_u64).unwrap_or(0) as u32,
+            is_definition,
+            enclosing_end_line: if is_definition { full_range_end } else { None },
+        });
+
+        let moniker_kind = moniker.and_then(|m| m.get("kind")).and_then(Value::as_str);
+        let entry = model.symbols.entry(symbol).or_insert_with(|| IndexedSymbolRecord {
+            // No moniker, or an explicitly local one: not visible outside its scope
+            is_local: moniker.is_none() || moniker_kind == Some("local"),
+            ..Default::default()
+        });
+        if is_definition {
+            if let Some(text) = tag.and_then(|t| t.get("text")).and_then(Value::as_str) {
+                entry.display_name = Some(text.to_string());
+            }
+            if let Some(kind) = tag.and_then(|t| t.get("kind")).and_then(Value::as_u64) {
+                entry.kind = entity_type_from_lsp_symbol_kind(kind);
+            }
+        }
+    }
+
+    mark_definitions_without_moniker_exported(&mut model);
+    Ok(model)
+}
+
+/// Indexers without monikers (older LSIF) would otherwise yield no entities:
+/// when a dump has no monikers at all, treat every symbol as global.
+fn mark_definitions_without_moniker_exported(model: &mut CodeIndexImportModel) {
+    let any_moniker = model.symbols.keys().any(|s| !s.starts_with("lsif:"));
+    if !any_moniker {
+        for info in model.symbols.values_mut() {
+            info.is_local = false;
+        }
+    }
+}
+
+/// LSP SymbolKind values that map onto entity types
+fn entity_type_from_lsp_symbol_kind(kind: u64) -> Option<EntityType> {
+    match kind {
+        2 => Some(EntityType::Module),
+        5 => Some(EntityType::Class),
+        6 | 9 => Some(EntityType::Method),
+        10 => Some(EntityType::Enum),
+        11 => Some(EntityType::Interface),
+        12 => Some(EntityType::Function),
+        13 => Some(EntityType::Variable),
+        14 => Some(EntityType::Constant),
+        23 => Some(EntityType::Struct),
+        _ => None,
+    }
+}
+
+/// LSIF ids are numbers or strings
+fn element_id_value(value: Option<&Value>) -> Option<u64> {
+    match value? {
+        Value::Number(n) => n.as_u64(),
+        Value::String(s) => s.parse().ok(),
+        _ => None,
+    }
+}
+
+/// `inV` or `inVs` of an edge
+fn edge_target_ids(edge: &Value) -> Vec<u64> {
+    if let Some(list) = edge.get("inVs").and_then(Value::as_array) {
+        return list.iter().filter_map(|v| element_id_value(Some(v))).collect();
+    }
+    element_id_value(edge.get("inV")).into_iter().collect()
+}
+
+fn lsif_json_error(e: serde_json::Error) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: format!("invalid LSIF JSON: {}", e),
+        location: format!("line {}", e.line()),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+    use crate::external_code_index_importer::build_graph_from_index_model;
+
+    const LSIF_FIXTURE: &str = r#"{"id":1,"type":"vertex","label":"metaData","version":"0.4.3","projectRoot":"file:///repo"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///repo/src/lib.rs","languageId":"rust"}
+{"id":3,"type":"vertex","label":"range","start":{"line":0,"character":3},"end":{"line":0,"character":8},"tag":{"type":"definition","text":"start","kind":12,"fullRange":{"start":{"line":0,"character":0},"end":{"line":2,"character":1}}}}
+{"id":4,"type":"vertex","label":"range","start":{"line":1,"character":4},"end":{"line":1,"character":10},"tag":{"type":"reference","text":"helper"}}
+{"id":5,"type":"vertex","label":"range","start":{"line":3,"character":3},"end":{"line":3,"character":9},"tag":{"type":"definition","text":"helper","kind":12,"fullRange":{"start":{"line":3,"character":0},"end":{"line":3,"character":14}}}}
+{"id":6,"type":"edge","label":"contains","outV":2,"inVs":[3,4,5]}
+{"id":7,"type":"vertex","label":"resultSet"}
+{"id":8,"type":"vertex","label":"resultSet"}
+{"id":9,"type":"edge","label":"next","outV":3,"inV":7}
+{"id":10,"type":"edge","label":"next","outV":4,"inV":8}
+{"id":11,"type":"edge","label":"next","outV":5,"inV":8}
+{"id":12,"type":"vertex","label":"definitionResult"}
+{"id":13,"type":"edge","label":"textDocument/definition","outV":8,"inV":12}
+{"id":14,"type":"edge","label":"item","outV":12,"inVs":[5],"document":2}
+{"id":15,"type":"vertex","label":"definitionResult"}
+{"id":16,"type":"edge","label":"item","outV":15,"inVs":[3],"document":2}
+"#;
+
+    #[test]
+    fn test_lsif_ranges_become_definitions_and_calls() {
+        let model = decode_lsif_dump_model(LSIF_FIXTURE.as_bytes()).unwrap();
+        assert_eq!(model.documents[0].relative_path, "src/lib.rs");
+        assert_eq!(model.occurrences.iter().filter(|o| o.is_definition).count(), 2);
+
+        let graph = build_graph_from_index_model(&model);
+        assert_eq!(graph.entities.len(), 2);
+        assert_eq!(graph.edges.len(), 1);
+        assert!(graph.edges[0].from_key.as_str().starts_with("rust:fn:start:__src_lib:T"));
+        assert!(graph.edges[0].to_key.as_str().starts_with("rust:fn:helper:__src_lib:T"));
+        assert_eq!(graph.edges[0].edge_type, EdgeType::Calls);
+    }
+
+    #[test]
+    fn test_lsif_json_array_form_accepted() {
+        let array = format!("[{}]", LSIF_FIXTURE.trim().lines().collect::<Vec<_>>().join(","));
+        let model = decode_lsif_dump_model(array.as_bytes()).unwrap();
+        assert_eq!(model.occurrences.len(), 3);
+    }
+}
@@ -0,0 +1,409 @@
+//! OpenTelemetry trace spans over OTLP/HTTP (v1.7.3)
+//!
+//! # 4-Word Naming: otlp_trace_span_exporter
+//!
+//! Ingest phases (`scan`, `parse`, `resolve`, `store`) and daemon queries
+//! are reported as spans to any OTLP/HTTP collector (OpenTelemetry
+//! Collector, Jaeger, Tempo, Honeycomb). Configuration follows the
+//! OpenTelemetry environment variables, so a shared deployment configures
+//! parseltongue like every other service:
+//!
+//! ```text
+//! OTEL_EXPORTER_OTLP_ENDPOINT=http://collector:4318      # base; /v1/traces is appended
+//! OTEL_EXPORTER_OTLP_TRACES_ENDPOINT=http://c:4318/v1/traces  # used as-is, wins over the base
+//! OTEL_SERVICE_NAME=parseltongue-daemon                  # resource service.name
+//! ```
+//!
+//! Nothing is recorded or sent when neither endpoint is set.
+//!
+//! ## Wire format
+//!
+//! The OTLP JSON encoding of `ExportTraceServiceRequest` (hex trace/span
+//! ids, nanosecond timestamps as decimal strings), POSTed with a 5 s timeout
+//! through the shared `outbound_http_post_client`, so `https://` collectors
+//! work directly. IDs come from the std hasher's per-process random keys,
+//! so no RNG crate is needed.
+
+use std::collections::hash_map::RandomState;
+use std::hash::{BuildHasher, Hasher};
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::time::{Duration, SystemTime, UNIX_EPOCH};
+
+use serde_json::{json, Value};
+
+use crate::outbound_http_post_client::{parse_http_endpoint_url, post_json_body_blocking, HttpEndpointUrlParts};
+
+/// Base collector URL; `/v1/traces` is appended
+pub const OTLP_ENDPOINT_ENV_VAR: &str = "OTEL_EXPORTER_OTLP_ENDPOINT";
+
+/// Full traces URL, used as-is
+pub const OTLP_TRACES_ENDPOINT_ENV_VAR: &str = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT";
+
+/// Resource `service.name`
+pub const OTEL_SERVICE_NAME_ENV_VAR: &str = "OTEL_SERVICE_NAME";
+
+/// `service.name` when `OTEL_SERVICE_NAME` is unset
+pub const DEFAULT_TELEMETRY_SERVICE_NAME: &str = "parseltongue";
+
+/// Connect + write + response
+const OTLP_EXPORT_TIMEOUT: Duration = Duration::from_secs(5);
+
+/// OTLP `SpanKind`
+///
+/// # 4-Word Name: TelemetrySpanKindValue
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum TelemetrySpanKindValue {
+    /// A phase of work inside the process (ingest phases)
+    Internal,
+    /// An incoming request (daemon queries)
+    Server,
+}
+
+/// One attribute value
+///
+/// # 4-Word Name: TelemetryAttributeValueKind
+#[derive(Debug, Clone, PartialEq)]
+pub enum TelemetryAttributeValueKind {
+    Text(String),
+    Int(i64),
+}
+
+/// A finished span
+///
+/// # 4-Word Name: TelemetrySpanRecordEntry
+#[derive(Debug, Clone, PartialEq)]
+pub struct TelemetrySpanRecordEntry {
+    /// 32 lowercase hex digits
+    pub trace_id: String,
+    /// 16 lowercase hex digits
+    pub span_id: String,
+    pub parent_span_id: Option<String>,
+    pub name: String,
+    pub kind: TelemetrySpanKindValue,
+    pub start_unix_nanos: u64,
+    pub end_unix_nanos: u64,
+    pub attributes: Vec<(String, TelemetryAttributeValueKind)>,
+    /// OTLP status code 2 (error) instead of unset
+    pub error: bool,
+}
+
+/// `http(s)://host:port/path` of the traces endpoint
+///
+/// # 4-Word Name: OtlpHttpEndpointAddress
+pub type OtlpHttpEndpointAddress = HttpEndpointUrlParts;
+
+/// Traces endpoint from the OpenTelemetry environment variables
+///
+/// # 4-Word Name: otlp_endpoint_from_environment
+///
+/// # Contract
+/// - Postcondition: `None` when neither variable is set (or both are
+///   empty); `Some(Err)` for a URL that is not `http://` or `https://`
+pub fn otlp_endpoint_from_environment() -> Option<Result<OtlpHttpEndpointAddress, String>> {
+    let non_empty = |name: &str| std::env::var(name).ok().filter(|value| !value.trim().is_empty());
+    if let Some(url) = non_empty(OTLP_TRACES_ENDPOINT_ENV_VAR) {
+        return Some(parse_otlp_http_endpoint(&url, false));
+    }
+    non_empty(OTLP_ENDPOINT_ENV_VAR).map(|url| parse_otlp_http_endpoint(&url, true))
+}
+
+/// `service.name` from `OTEL_SERVICE_NAME`, else `default_name`
+///
+/// # 4-Word Name: telemetry_service_name_or
+pub fn telemetry_service_name_or(default_name: &str) -> String {
+    std::env::var(OTEL_SERVICE_NAME_ENV_VAR)
+        .ok()
+        .filter(|name| !name.trim().is_empty())
+        .unwrap_or_else(|| default_name.to_string())
+}
+
+/// Split an `http://` or `https://` collector URL
+///
+/// # 4-Word Name: parse_otlp_http_endpoint
+///
+/// # Contract
+/// - Precondition: `append_traces_path` is true for the base endpoint
+///   variable, whose path gets `/v1/traces` appended per the OTLP spec
+/// - Postcondition: port defaults to 80 / 443; an empty path becomes `/`
+pub fn parse_otlp_http_endpoint(url: &str, append_traces_path: bool) -> Result<OtlpHttpEndpointAddress, String> {
+    let mut endpoint = parse_http_endpoint_url(url, "OTLP endpoint")?;
+    if append_traces_path {
+        endpoint.path = format!("{}/v1/traces", endpoint.path.trim_end_matches('/'));
+    }
+    Ok(endpoint)
+}
+
+/// Random lowercase hex id of `byte_count` bytes (16 for traces, 8 for spans)
+///
+/// # 4-Word Name: generate_random_hex_identifier
+///
+/// # Contract
+/// - Postcondition: never all zeros (OTLP treats zero ids as invalid)
+pub fn generate_random_hex_identifier(byte_count: usize) -> String {
+    static SEQUENCE: AtomicU64 = AtomicU64::new(1);
+    let mut hex = String::with_capacity(byte_count * 2);
+    while hex.len() < byte_count * 2 {
+        let mut hasher = RandomState::new().build_hasher();
+        hasher.write_u64(SEQUENCE.fetch_add(1, Ordering::Relaxed));
+        hasher.write_u128(current_unix_time_nanos() as u128);
+        let word = hasher.finish().max(1);
+        hex.push_str(&format!("{:016x}", word));
+    }
+    hex.truncate(byte_count * 2);
+    hex
+}
+
+/// Nanoseconds since the Unix epoch
+///
+/// # 4-Word Name: current_unix_time_nanos
+pub fn current_unix_time_nanos() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).map_or(0, |elapsed| elapsed.as_nanos() as u64)
+}
+
+/// Trace and parent span of a W3C `traceparent` header
+///
+/// # 4-Word Name: parse_w3c_traceparent_header
+///
+/// # Contract
+/// - Postcondition: `Some((trace_id, parent_span_id))` for
+///   `00-<32 hex>-<16 hex>-<2 hex>` with non-zero ids; `None` otherwise,
+///   in which case the caller starts a new trace
+pub fn parse_w3c_traceparent_header(value: &str) -> Option<(String, String)> {
+    let parts: Vec<&str> = value.trim().split('-').collect();
+    let &[version, trace_id, span_id, flags] = parts.as_slice() else {
+        return None;
+    };
+    let is_hex = |text: &str, len: usize| text.len() == len && text.bytes().all(|b| b.is_ascii_hexdigit());
+    let valid = is_hex(version, 2)
+        && version != "ff"
+        && is_hex(trace_id, 32)
+        && is_hex(span_id, 16)
+        && is_hex(flags, 2)
+        && trace_id.bytes().any(|b| b != b'0')
+        && span_id.bytes().any(|b| b != b'0');
+    valid.then(|| (trace_id.to_ascii_lowercase(), span_id.to_ascii_lowercase()))
+}
+
+/// OTLP JSON `ExportTraceServiceRequest` for one service
+///
+/// # 4-Word Name: render_otlp_trace_export_json
+pub fn render_otlp_trace_export_json(service_name: &str, spans: &[TelemetrySpanRecordEntry]) -> Value {
+    let spans: Vec<Value> = spans
+        .iter()
+        .map(|span| {
+            let mut rendered = json!({
+                "traceId": span.trace_id,
+                "spanId": span.span_id,
+                "name": span.name,
+                "kind": match span.kind {
+                    TelemetrySpanKindValue::Internal => 1,
+                    TelemetrySpanKindValue::Server => 2,
+                },
+                "startTimeUnixNano": span.start_unix_nanos.to_string(),
+                "endTimeUnixNano": span.end_unix_nanos.to_string(),
+                "attributes": span.attributes.iter().map(|(key, value)| render_attribute(key, value)).collect::<Vec<_>>(),
+            });
+            if let Some(parent) = &span.parent_span_id {
+                rendered["parentSpanId"] = json!(parent);
+            }
+            if span.error {
+                rendered["status"] = json!({ "code": 2 });
+            }
+            rendered
+        })
+        .collect();
+    json!({
+        "resourceSpans": [{
+            "resource": { "attributes": [render_attribute("service.name", &TelemetryAttributeValueKind::Text(service_name.to_string()))] },
+            "scopeSpans": [{
+                "scope": { "name": "parseltongue", "version": env!("CARGO_PKG_VERSION") },
+                "spans": spans,
+            }],
+        }],
+    })
+}
+
+fn render_attribute(key: &str, value: &TelemetryAttributeValueKind) -> Value {
+    let value = match value {
+        TelemetryAttributeValueKind::Text(text) => json!({ "stringValue": text }),
+        // OTLP JSON carries 64-bit integers as strings
+        TelemetryAttributeValueKind::Int(number) => json!({ "intValue": number.to_string() }),
+    };
+    json!({ "key": key, "value": value })
+}
+
+/// POST spans to the collector; returns the HTTP status
+///
+/// # 4-Word Name: post_otlp_trace_export_blocking
+///
+/// # Contract
+/// - Blocking: call from a plain thread or `spawn_blocking`
+/// - Postcondition: an empty span list sends nothing and returns 200
+pub fn post_otlp_trace_export_blocking(
+    endpoint: &OtlpHttpEndpointAddress,
+    service_name: &str,
+    spans: &[TelemetrySpanRecordEntry],
+) -> Result<u16, String> {
+    if spans.is_empty() {
+        return Ok(200);
+    }
+    let body = render_otlp_trace_export_json(service_name, spans).to_string().into_bytes();
+    post_json_body_blocking(endpoint, &[], body, OTLP_EXPORT_TIMEOUT)
+        .map(|answer| answer.status)
+        .map_err(|e| format!("OTLP export to {} failed: {}", endpoint.url, e))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_otlp_json_and_endpoint_parsing() {
+        let base = parse_otlp_http_endpoint("http://collector:4318/", true).unwrap();
+        assert_eq!((base.host.as_str(), base.port, base.path.as_str()), ("collector", 4318, "/v1/traces"));
+        let exact = parse_otlp_http_endpoint("http://localhost/custom/traces", false).unwrap();
+        assert_eq!((exact.port, exact.path.as_str()), (80, "/custom/traces"));
+        let secure = parse_otlp_http_endpoint("https://collector:4318", true).unwrap();
+        assert_eq!((secure.https, secure.path.as_str()), (true, "/v1/traces"));
+        let bracketed = parse_otlp_http_endpoint("http://[::1]:4318", true).unwrap();
+        assert_eq!((bracketed.host.as_str(), bracketed.port), ("::1", 4318));
+
+        let trace_id = generate_random_hex_identifier(16);
+        assert_eq!(trace_id.len(), 32);
+        assert_ne!(trace_id, generate_random_hex_identifier(16));
+        assert_eq!(
+            parse_w3c_traceparent_header("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"),
+            Some(("4bf92f3577b34da6a3ce929d0e0e4736".to_string(), "00f067aa0ba902b7".to_string()))
+        );
+        assert_eq!(parse_w3c_traceparent_header("00-00000000000000000000000000000000-00f067aa0ba902b7-01"), None);
+
+        let span = TelemetrySpanRecordEntry {
+            trace_id,
+            span_id: generate_random_hex_identifier(8),
+            parent_span_id: Some("00f067aa0ba902b7".to_string()),
+            name: "parse".to_string(),
+            kind: TelemetrySpanKindValue::Internal,
+            start_unix_nanos: 1_000,
+            end_unix_nanos: 2_500,
+            attributes: vec![("files".to_string(), TelemetryAttributeValueKind::Int(42))],
+            error: false,
+        };
+        let export = render_otlp_trace_export_json("svc", &[span]);
+        let resource = &export["resourceSpans"][0];
+        assert_eq!(resource["resource"]["attributes"][0]["value"]["stringValue"], "svc");
+        let rendered = &resource["scopeSpans"][0]["spans"][0];
+        assert_eq!(rendered["endTimeUnixNano"], "2500");
+        assert_eq!(rendered["parentSpanId"], "00f067aa0ba902b7");
+        assert_eq!(rendered["attributes"][0]["value"]["intValue"], "42");
+        assert!(rendered.get("status").is_none());
+    }
+
+    /// Collector on a loopback port answering one request with `response`;
+    /// the thread returns the raw request it read
+    fn serve_one_otlp_test_response(
+        response: &'static [u8],
+    ) -> (OtlpHttpEndpointAddress, std::thread::JoinHandle<String>) {
+        let (port, server) = crate::outbound_http_post_client::serve_canned_http_test_answers(vec![response.to_vec()]);
+        let collector = std::thread::spawn(move || server.join().unwrap().remove(0));
+        (parse_otlp_http_endpoint(&format!("http://127.0.0.1:{}", port), true).unwrap(), collector)
+    }
+
+    fn create_test_span(name: &str, kind: TelemetrySpanKindValue, error: bool) -> TelemetrySpanRecordEntry {
+        TelemetrySpanRecordEntry {
+            trace_id: generate_random_hex_identifier(16),
+            span_id: generate_random_hex_identifier(8),
+            parent_span_id: None,
+            name: name.to_string(),
+            kind,
+            start_unix_nanos: 10,
+            end_unix_nanos: 20,
+            attributes: vec![("http.route".to_string(), TelemetryAttributeValueKind::Text("/x".to_string()))],
+            error,
+        }
+    }
+
+    #[test]
+    fn test_endpoint_and_traceparent_edge_cases() {
+        let base = parse_otlp_http_endpoint("http://collector", true).unwrap();
+        assert_eq!((base.port, base.path.as_str()), (80, "/v1/traces"));
+        let prefixed = parse_otlp_http_endpoint("http://collector:4318/otlp/", true).unwrap();
+        assert_eq!(prefixed.path, "/otlp/v1/traces");
+        let exact = parse_otlp_http_endpoint("  http://h:1/x  ", false).unwrap();
+        assert_eq!((exact.url.as_str(), exact.path.as_str()), ("http://h:1/x", "/x"));
+        assert_eq!(parse_otlp_http_endpoint("http://h:1", false).unwrap().path, "/");
+        assert!(parse_otlp_http_endpoint("http://:4318", true).unwrap_err().contains("no host"));
+        assert!(parse_otlp_http_endpoint("http://h:99999", true).unwrap_err().contains("invalid port"));
+        assert!(parse_otlp_http_endpoint("http://h:abc/v1", true).unwrap_err().contains("invalid port"));
+        assert!(parse_otlp_http_endpoint("collector:4318", true).unwrap_err().contains("http:// or https://"));
+
+        let trace = "4bf92f3577b34da6a3ce929d0e0e4736";
+        let span = "00f067aa0ba902b7";
+        let expected = Some((trace.to_string(), span.to_string()));
+        assert_eq!(parse_w3c_traceparent_header(&format!(" 00-{}-{}-01\n", trace, span)), expected);
+        // Future versions with the same layout are accepted, `ff` never is
+        assert_eq!(parse_w3c_traceparent_header(&format!("01-{}-{}-00", trace, span)), expected);
+        assert_eq!(parse_w3c_traceparent_header(&format!("ff-{}-{}-01", trace, span)), None);
+        assert_eq!(parse_w3c_traceparent_header(&format!("00-{}-{}", trace, span)), None);
+        assert_eq!(parse_w3c_traceparent_header(&format!("00-{}-{}-01-extra", trace, span)), None);
+        assert_eq!(parse_w3c_traceparent_header(&format!("00-{}-0000000000000000-01", trace)), None);
+        assert_eq!(parse_w3c_traceparent_header(&format!("00-{}z-{}-01", &trace[1..], span)), None);
+        assert_eq!(parse_w3c_traceparent_header(&format!("00-{}-{}-1", trace, span)), None);
+        assert_eq!(parse_w3c_traceparent_header(""), None);
+
+        for byte_count in [3, 8, 16] {
+            let id = generate_random_hex_identifier(byte_count);
+            assert_eq!(id.len(), byte_count * 2);
+            assert!(id.bytes().all(|b| b.is_ascii_digit() || (b'a'..=b'f').contains(&b)), "{}", id);
+            assert!(id.bytes().any(|b| b != b'0'));
+        }
+    }
+
+    #[test]
+    fn test_server_and_error_spans_render_kind_and_status() {
+        let spans = vec![
+            create_test_span("GET /x", TelemetrySpanKindValue::Server, true),
+            create_test_span("scan", TelemetrySpanKindValue::Internal, false),
+        ];
+        let export = render_otlp_trace_export_json("daemon", &spans);
+        let scope = &export["resourceSpans"][0]["scopeSpans"][0];
+        assert_eq!(scope["scope"]["name"], "parseltongue");
+        let rendered = scope["spans"].as_array().unwrap();
+        assert_eq!(rendered.len(), 2);
+        assert_eq!(rendered[0]["name"], "GET /x");
+        assert_eq!(rendered[0]["kind"], 2);
+        assert_eq!(rendered[0]["status"]["code"], 2);
+        assert!(rendered[0].get("parentSpanId").is_none(), "root spans carry no parent");
+        assert_eq!(rendered[0]["attributes"][0]["value"]["stringValue"], "/x");
+        assert_eq!(rendered[0]["startTimeUnixNano"], "10");
+        assert_eq!(rendered[1]["kind"], 1);
+        assert!(rendered[1].get("status").is_none());
+    }
+
+    #[test]
+    fn test_post_reports_status_and_collector_failures() {
+        let spans = vec![create_test_span("parse", TelemetrySpanKindValue::Internal, false)];
+
+        let (endpoint, collector) = serve_one_otlp_test_response(b"HTTP/1.1 202 Accepted\r\nContent-Length: 0\r\n\r\n");
+        assert_eq!(post_otlp_trace_export_blocking(&endpoint, "svc", &spans), Ok(202));
+        let request = collector.join().unwrap();
+        assert!(request.starts_with("POST /v1/traces HTTP/1.1\r\n"), "{}", request);
+        assert!(request.to_ascii_lowercase().contains(&format!("host: 127.0.0.1:{}\r\n", endpoint.port)));
+        let body: Value = serde_json::from_str(request.split_once("\r\n\r\n").unwrap().1).unwrap();
+        assert_eq!(body["resourceSpans"][0]["scopeSpans"][0]["spans"][0]["name"], "parse");
+
+        let (endpoint, collector) = serve_one_otlp_test_response(b"garbage\r\n");
+        let error = post_otlp_trace_export_blocking(&endpoint, "svc", &spans).unwrap_err();
+        assert!(error.starts_with(&format!("OTLP export to {} failed", endpoint.url)), "{}", error);
+        collector.join().unwrap();
+
+        // Nobody listens on a port released right after binding it
+        let closed = {
+            let listener = std::net::TcpListener::bind("127.0.0.1:0").unwrap();
+            format!("http://127.0.0.1:{}", listener.local_addr().unwrap().port())
+        };
+        let endpoint = parse_otlp_http_endpoint(&closed, true).unwrap();
+        let error = post_otlp_trace_export_blocking(&endpoint, "svc", &spans).unwrap_err();
+        assert!(error.starts_with(&format!("OTLP export to {} failed", closed)), "{}", error);
+        assert_eq!(post_otlp_trace_export_blocking(&endpoint, "svc", &[]), Ok(200), "nothing to send");
+    }
+}
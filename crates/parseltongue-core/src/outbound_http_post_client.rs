@@ -0,0 +1,250 @@
+//! Outbound HTTP POST client (v1.7.3)
+//!
+//! # 4-Word Naming: outbound_http_post_client
+//!
+//! The one client for everything parseltongue sends out: `summarize`
+//! requests, OTLP trace exports and `--webhook` events. It wraps `reqwest`'s
+//! blocking client over rustls (webpki roots), so all three accept
+//! `https://` endpoints and bracketed IPv6 hosts (`http://[::1]:4318`), read
+//! chunked and length-delimited answers alike and honour the usual
+//! `HTTPS_PROXY` / `NO_PROXY` variables. Redirects are not followed: a POST
+//! answered with 3xx is reported as that status.
+
+use std::time::Duration;
+
+use reqwest::header::{CONTENT_TYPE, RETRY_AFTER};
+
+/// Parsed `http://` or `https://` endpoint
+///
+/// # 4-Word Name: HttpEndpointUrlParts
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct HttpEndpointUrlParts {
+    /// The configured URL, trimmed, for log lines and errors
+    pub url: String,
+    pub https: bool,
+    /// Host name (lowercased) or address; IPv6 without brackets
+    pub host: String,
+    /// Explicit port, else 80 / 443
+    pub port: u16,
+    /// Path and query, at least `/`
+    pub path: String,
+}
+
+impl HttpEndpointUrlParts {
+    /// Absolute URL the request is sent to
+    ///
+    /// # 4-Word Name: build_request_url_string
+    pub fn build_request_url_string(&self) -> String {
+        let scheme = if self.https { "https" } else { "http" };
+        let host = if self.host.contains(':') { format!("[{}]", self.host) } else { self.host.clone() };
+        format!("{}://{}:{}{}", scheme, host, self.port, self.path)
+    }
+}
+
+/// Answer to one POST
+///
+/// # 4-Word Name: HttpPostResponseOutcome
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct HttpPostResponseOutcome {
+    pub status: u16,
+    /// `Retry-After` in seconds, when sent as a number
+    pub retry_after: Option<Duration>,
+    pub body: String,
+}
+
+/// Split an endpoint URL
+///
+/// # 4-Word Name: parse_http_endpoint_url
+///
+/// # Contract
+/// - Precondition: `what` names the setting in messages ("webhook", ...)
+/// - Postcondition: only `http` and `https`; a URL without a path gets `/`
+/// - Error: other schemes, no host, or a port outside 0..=65535
+pub fn parse_http_endpoint_url(url: &str, what: &str) -> Result<HttpEndpointUrlParts, String> {
+    let trimmed = url.trim();
+    let must_be_http = || format!("{} '{}' must be an http:// or https:// URL", what, url);
+    let parsed = url::Url::parse(trimmed).map_err(|e| match e {
+        url::ParseError::EmptyHost => format!("{} '{}' has no host", what, url),
+        url::ParseError::InvalidPort => format!("invalid port in {} '{}'", what, url),
+        _ => must_be_http(),
+    })?;
+    let https = match parsed.scheme() {
+        "http" => false,
+        "https" => true,
+        _ => return Err(must_be_http()),
+    };
+    let host = match parsed.host() {
+        Some(url::Host::Domain(name)) => name.to_string(),
+        Some(url::Host::Ipv4(address)) => address.to_string(),
+        Some(url::Host::Ipv6(address)) => address.to_string(),
+        None => return Err(format!("{} '{}' has no host", what, url)),
+    };
+    let path = match parsed.query() {
+        Some(query) => format!("{}?{}", parsed.path(), query),
+        None => parsed.path().to_string(),
+    };
+    Ok(HttpEndpointUrlParts {
+        url: trimmed.to_string(),
+        https,
+        host,
+        port: parsed.port_or_known_default().unwrap_or(if https { 443 } else { 80 }),
+        path,
+    })
+}
+
+/// POST a JSON body and read the whole answer
+///
+/// # 4-Word Name: post_json_body_blocking
+///
+/// # Contract
+/// - Blocking: call from a plain thread or `spawn_blocking`
+/// - Postcondition: any HTTP status is Ok (callers decide what is a
+///   failure); `timeout` covers connect, send and reading the answer
+/// - Error: connection, TLS, timeout or malformed-answer failures, with
+///   their causes
+pub fn post_json_body_blocking(
+    endpoint: &HttpEndpointUrlParts,
+    headers: &[(&str, &str)],
+    body: Vec<u8>,
+    timeout: Duration,
+) -> Result<HttpPostResponseOutcome, String> {
+    let client = reqwest::blocking::Client::builder()
+        .timeout(timeout)
+        .redirect(reqwest::redirect::Policy::none())
+        .user_agent(concat!("parseltongue/", env!("CARGO_PKG_VERSION")))
+        .build()
+        .map_err(|e| describe_error_with_causes(&e))?;
+    let mut request = client
+        .post(endpoint.build_request_url_string())
+        .header(CONTENT_TYPE, "application/json")
+        .body(body);
+    for &(name, value) in headers {
+        request = request.header(name, value);
+    }
+    let response = request.send().map_err(|e| describe_error_with_causes(&e))?;
+    let status = response.status().as_u16();
+    let retry_after = response
+        .headers()
+        .get(RETRY_AFTER)
+        .and_then(|value| value.to_str().ok())
+        .and_then(|value| value.trim().parse::<u64>().ok())
+        .map(Duration::from_secs);
+    let body = response.text().map_err(|e| describe_error_with_causes(&e))?;
+    Ok(HttpPostResponseOutcome { status, retry_after, body })
+}
+
+/// `error: cause: cause ...`; reqwest keeps the useful part in the sources
+fn describe_error_with_causes(error: &dyn std::error::Error) -> String {
+    let mut text = error.to_string();
+    let mut source = error.source();
+    while let Some(cause) = source {
+        let cause_text = cause.to_string();
+        if !text.contains(&cause_text) {
+            text.push_str(": ");
+            text.push_str(&cause_text);
+        }
+        source = cause.source();
+    }
+    text
+}
+
+/// Loopback server answering one request per entry of `answers`; the
+/// thread returns each raw request (head and full body) it read
+#[cfg(test)]
+pub(crate) fn serve_canned_http_test_answers(
+    answers: Vec<Vec<u8>>,
+) -> (u16, std::thread::JoinHandle<Vec<String>>) {
+    use std::io::{Read, Write};
+    let listener = std::net::TcpListener::bind("127.0.0.1:0").unwrap();
+    let port = listener.local_addr().unwrap().port();
+    let server = std::thread::spawn(move || {
+        let mut requests = Vec::new();
+        for answer in answers {
+            let (mut stream, _) = listener.accept().unwrap();
+            let mut received = Vec::new();
+            let mut chunk = [0u8; 4096];
+            loop {
+                let read = stream.read(&mut chunk).unwrap();
+                if read == 0 {
+                    break;
+                }
+                received.extend_from_slice(&chunk[..read]);
+                let text = String::from_utf8_lossy(&received).to_string();
+                if let Some((head, body)) = text.split_once("\r\n\r\n") {
+                    let length = head
+                        .lines()
+                        .find_map(|line| {
+                            let (name, value) = line.split_once(':')?;
+                            name.eq_ignore_ascii_case("content-length").then(|| value.trim().parse::<usize>().ok())?
+                        })
+                        .unwrap_or(0);
+                    if body.len() >= length {
+                        break;
+                    }
+                }
+            }
+            stream.write_all(&answer).unwrap();
+            requests.push(String::from_utf8_lossy(&received).into_owned());
+        }
+        requests
+    });
+    (port, server)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_endpoint_urls_accept_https_and_ipv6() {
+        let secure = parse_http_endpoint_url("https://API.example.com/v1/chat?x=1", "endpoint").unwrap();
+        assert_eq!((secure.https, secure.host.as_str(), secure.port), (true, "api.example.com", 443));
+        assert_eq!(secure.path, "/v1/chat?x=1");
+        assert_eq!(secure.build_request_url_string(), "https://api.example.com:443/v1/chat?x=1");
+
+        let loopback = parse_http_endpoint_url(" http://[::1]:4318 ", "OTLP endpoint").unwrap();
+        assert_eq!((loopback.host.as_str(), loopback.port, loopback.path.as_str()), ("::1", 4318, "/"));
+        assert_eq!(loopback.url, "http://[::1]:4318");
+        assert_eq!(loopback.build_request_url_string(), "http://[::1]:4318/");
+        assert_eq!(parse_http_endpoint_url("http://relay", "webhook").unwrap().port, 80);
+
+        assert!(parse_http_endpoint_url("http://:4318", "webhook").unwrap_err().contains("has no host"));
+        assert!(parse_http_endpoint_url("http://h:99999", "webhook").unwrap_err().contains("invalid port"));
+        for bad in ["ftp://x", "collector:4318", "not a url"] {
+            let error = parse_http_endpoint_url(bad, "webhook").unwrap_err();
+            assert!(error.contains("must be an http:// or https:// URL"), "{}", error);
+        }
+    }
+
+    #[test]
+    fn test_post_reads_status_retry_after_and_chunked_body() {
+        let answers = vec![
+            b"HTTP/1.1 429 Too Many Requests\r\nRetry-After: 7\r\nContent-Length: 0\r\n\r\n".to_vec(),
+            b"HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n2\r\nde\r\n0\r\n\r\n".to_vec(),
+            b"garbage\r\n".to_vec(),
+        ];
+        let (port, server) = serve_canned_http_test_answers(answers);
+        let endpoint = parse_http_endpoint_url(&format!("http://127.0.0.1:{}/hook", port), "webhook").unwrap();
+        let post = || {
+            let headers = [("Authorization", "Bearer k")];
+            post_json_body_blocking(&endpoint, &headers, b"{\"a\":1}".to_vec(), Duration::from_secs(5))
+        };
+
+        let limited = post().unwrap();
+        assert_eq!((limited.status, limited.retry_after), (429, Some(Duration::from_secs(7))));
+        assert_eq!(post().unwrap(), HttpPostResponseOutcome { status: 200, retry_after: None, body: "abcde".into() });
+        assert!(post().is_err(), "no status line is a transport error");
+
+        let requests = server.join().unwrap();
+        assert!(requests[0].starts_with("POST /hook HTTP/1.1\r\n"), "{}", requests[0]);
+        let head = requests[0].to_ascii_lowercase();
+        assert!(head.contains("content-type: application/json") && head.contains("authorization: bearer k"));
+        assert!(head.contains(&format!("host: 127.0.0.1:{}", port)));
+        assert!(requests[0].ends_with("\r\n\r\n{\"a\":1}"));
+
+        // Nobody listens on a port released right after binding it
+        let closed = std::net::TcpListener::bind("127.0.0.1:0").unwrap().local_addr().unwrap().port();
+        let endpoint = parse_http_endpoint_url(&format!("http://127.0.0.1:{}", closed), "webhook").unwrap();
+        assert!(post_json_body_blocking(&endpoint, &[], Vec::new(), Duration::from_secs(5)).is_err());
+    }
+}
@@ -0,0 +1,227 @@
+//! Package Boundary Subgraph Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: package_boundary_subgraph_extractor
+//!
+//! Cuts the graph down to a set of packages for `parseltongue subgraph`:
+//! every entity whose directory matches a `--package` pattern, plus
+//! `--include-externals` frontier layers of neighbours (callers and
+//! callees outside the selection, external and unresolved targets). The
+//! result is written as a JSON snapshot, which every command reads through
+//! `--db snapshot:FILE`.
+//!
+//! ## Patterns
+//!
+//! Go-style: `./internal/billing` is that directory only,
+//! `./internal/billing/...` includes its subdirectories, `./...` is
+//! everything. Relative entity paths are matched from their first
+//! component; absolute ones anywhere along the path.
+//!
+//! ## Edges
+//!
+//! An edge is kept when one endpoint is selected or in a frontier layer
+//! before the last one, and the other endpoint is kept too. Edges between
+//! two nodes of the outermost layer are dropped, so the frontier does not
+//! pull in its own neighbourhood.
+
+use std::collections::HashMap;
+use std::path::{Component, Path};
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::error::{ParseltongError, Result};
+
+/// Entity metadata key: frontier layer (1 = direct neighbour) of a node outside the selection
+pub const SUBGRAPH_FRONTIER_METADATA_KEY: &str = "subgraph_frontier_layer";
+
+/// One `--package` pattern
+///
+/// # 4-Word Name: PackageSelectionPatternSpec
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct PackageSelectionPatternSpec {
+    /// Directory components, `.` removed
+    pub components: Vec<String>,
+    /// `/...` suffix: subdirectories too
+    pub recursive: bool,
+}
+
+impl PackageSelectionPatternSpec {
+    /// Parse `./internal/billing/...`-style patterns
+    ///
+    /// # Contract
+    /// - Postcondition: `..` components are rejected; an empty pattern is
+    ///   an error rather than "everything" (`./...` says that explicitly)
+    pub fn parse_package_selection_pattern(pattern: &str) -> Result<Self> {
+        let trimmed = pattern.trim().trim_end_matches('/');
+        let (path, recursive) = match trimmed.strip_suffix("...") {
+            Some(rest) => (rest.trim_end_matches('/'), true),
+            None => (trimmed, false),
+        };
+        let components: Vec<String> = path
+            .split(['/', '\\'])
+            .filter(|part| !part.is_empty() && *part != ".")
+            .map(str::to_string)
+            .collect();
+        if components.iter().any(|part| part.as_str() == "..") || (components.is_empty() && !recursive) {
+            return Err(ParseltongError::ValidationError {
+                field: "package".to_string(),
+                expected: "a directory pattern such as ./internal/billing or ./internal/billing/...".to_string(),
+                actual: pattern.to_string(),
+            });
+        }
+        Ok(Self { components, recursive })
+    }
+
+    /// Test the directory of a file path
+    pub fn matches_file_directory(&self, file_path: &Path) -> bool {
+        let directory: Vec<String> = file_path
+            .parent()
+            .map(|parent| {
+                parent
+                    .components()
+                    .filter_map(|component| match component {
+                        Component::Normal(part) => Some(part.to_string_lossy().to_string()),
+                        _ => None,
+                    })
+                    .collect()
+            })
+            .unwrap_or_default();
+        let window = self.components.len();
+        if window > directory.len() {
+            return false;
+        }
+        let starts: Vec<usize> = if file_path.is_absolute() { (0..=directory.len() - window).collect() } else { vec![0] };
+        starts.into_iter().any(|start| {
+            directory[start..start + window] == self.components[..]
+                && (self.recursive || start + window == directory.len())
+        })
+    }
+}
+
+/// Extracted slice and what went into it
+///
+/// # 4-Word Name: PackageSubgraphExtractionResult
+#[derive(Debug, Clone)]
+pub struct PackageSubgraphExtractionResult {
+    /// Selected and frontier entities; frontier ones carry `SUBGRAPH_FRONTIER_METADATA_KEY`
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    pub selected_entity_count: usize,
+    /// Frontier nodes, entities and edge-only (external / unresolved) keys
+    pub frontier_node_count: usize,
+}
+
+/// Extract the selected packages plus `frontier_layers` rings of neighbours
+///
+/// # 4-Word Name: extract_package_boundary_subgraph
+///
+/// # Contract
+/// - Precondition: `patterns` is non-empty
+/// - Postcondition: every edge endpoint of the result is selected or in a
+///   frontier layer `<= frontier_layers`; with `frontier_layers == 0` only
+///   edges inside the selection remain
+pub fn extract_package_boundary_subgraph(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    patterns: &[PackageSelectionPatternSpec],
+    frontier_layers: usize,
+) -> PackageSubgraphExtractionResult {
+    let mut layer_of: HashMap<&str, usize> = entities
+        .iter()
+        .filter(|entity| patterns.iter().any(|p| p.matches_file_directory(&entity.interface_signature.file_path)))
+        .map(|entity| (entity.isgl1_key.as_str(), 0))
+        .collect();
+    let selected_entity_count = layer_of.len();
+
+    let mut adjacency: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges {
+        adjacency.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+        adjacency.entry(edge.to_key.as_str()).or_default().push(edge.from_key.as_str());
+    }
+    let mut ring: Vec<&str> = layer_of.keys().copied().collect();
+    for layer in 1..=frontier_layers {
+        let mut next = Vec::new();
+        for key in ring {
+            for &neighbour in adjacency.get(key).map(Vec::as_slice).unwrap_or(&[]) {
+                if !layer_of.contains_key(neighbour) {
+                    layer_of.insert(neighbour, layer);
+                    next.push(neighbour);
+                }
+            }
+        }
+        ring = next;
+    }
+
+    let expanded = |key: &str| layer_of.get(key).is_some_and(|&layer| layer < frontier_layers.max(1));
+    let kept_edges: Vec<DependencyEdge> = edges
+        .iter()
+        .filter(|edge| {
+            let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+            layer_of.contains_key(from) && layer_of.contains_key(to) && (expanded(from) || expanded(to))
+        })
+        .cloned()
+        .collect();
+
+    let kept_entities: Vec<CodeEntity> = entities
+        .iter()
+        .filter_map(|entity| {
+            let &layer = layer_of.get(entity.isgl1_key.as_str())?;
+            let mut entity = entity.clone();
+            if layer > 0 {
+                entity.metadata.additional.insert(SUBGRAPH_FRONTIER_METADATA_KEY.to_string(), layer.to_string());
+            }
+            Some(entity)
+        })
+        .collect();
+    let frontier_node_count = layer_of.values().filter(|&&layer| layer > 0).count();
+
+    PackageSubgraphExtractionResult {
+        entities: kept_entities,
+        edges: kept_edges,
+        selected_entity_count,
+        frontier_node_count,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_package_selection_with_frontier_layer() {
+        let entities = vec![
+            create_go_test_entity("go:fn:Charge:__billing:T1", "Charge", EntityType::Function, "internal/billing/charge.go", &[]),
+            create_go_test_entity("go:fn:Tax:__billing_tax:T2", "Tax", EntityType::Function, "internal/billing/tax/tax.go", &[]),
+            create_go_test_entity("go:fn:Checkout:__api:T3", "Checkout", EntityType::Function, "internal/api/checkout.go", &[]),
+            create_go_test_entity("go:fn:main:__cmd:T4", "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![
+            edge("go:fn:Charge:__billing:T1", "go:fn:Tax:__billing_tax:T2"),
+            edge("go:fn:Checkout:__api:T3", "go:fn:Charge:__billing:T1"),
+            edge("go:fn:main:__cmd:T4", "go:fn:Checkout:__api:T3"),
+            edge("go:fn:Charge:__billing:T1", "go:fn:Sprintf:unresolved-reference:0-0"),
+        ];
+
+        let recursive = PackageSelectionPatternSpec::parse_package_selection_pattern("./internal/billing/...").unwrap();
+        let result = extract_package_boundary_subgraph(&entities, &edges, &[recursive.clone()], 1);
+        assert_eq!(result.selected_entity_count, 2);
+        assert_eq!(result.frontier_node_count, 2, "Checkout and the Sprintf placeholder");
+        assert_eq!(result.edges.len(), 3, "main -> Checkout is beyond the frontier");
+        let checkout = result.entities.iter().find(|e| e.interface_signature.name == "Checkout").unwrap();
+        assert_eq!(checkout.metadata.additional.get(SUBGRAPH_FRONTIER_METADATA_KEY).map(String::as_str), Some("1"));
+
+        let exact = PackageSelectionPatternSpec::parse_package_selection_pattern("./internal/billing").unwrap();
+        let result = extract_package_boundary_subgraph(&entities, &edges, &[exact], 0);
+        assert_eq!(result.entities.len(), 1);
+        assert!(result.edges.is_empty());
+
+        assert!(recursive.matches_file_directory(Path::new("/src/repo/internal/billing/tax/tax.go")));
+        assert!(!recursive.matches_file_directory(Path::new("vendor/internal/billing/x.go")));
+        assert!(PackageSelectionPatternSpec::parse_package_selection_pattern("../billing").is_err());
+        assert!(PackageSelectionPatternSpec::parse_package_selection_pattern("./...").unwrap().matches_file_directory(Path::new("main.go")));
+    }
+}
@@ -0,0 +1,442 @@
+//! PR review bundle (v1.7.3)
+//!
+//! # 4-Word Naming: pr_review_bundle_generator
+//!
+//! One structured artifact per change, for review prompts. Built on the
+//! diff-to-symbol mapping of `git_diff_context_scoper`, rendered as markdown
+//! whose headings never change (empty sections say `_None._`), so a prompt
+//! template can refer to them:
+//!
+//! ```text
+//! # Review Pack: <revision>
+//! ## Changed Symbols       full body of every symbol the diff touches
+//! ## Callers               direct dependents (Calls, Uses, Spawns, Embeds, Reads, Writes)
+//! ## Implementers          types implementing a changed interface/trait
+//! ## Related Tests         tests covering a changed symbol (Tests edges, test callers)
+//! ## Public API Surface    exported symbols within `api_depth` reverse hops
+//! ## Outside Team Ownership  changed symbols, callers and implementers owned by other teams
+//! ```
+//!
+//! Every non-changed entry says which changed symbol it relates to (`via`).
+//! Ownership comes from CODEOWNERS (`code_owners`, see
+//! `codeowners_ownership_rule_matcher`); the team is `--team` or else the
+//! owner of most changed symbols, and the section is `_None._` when the
+//! graph has no CODEOWNERS data.
+
+use std::collections::{BTreeMap, HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::codeowners_ownership_rule_matcher::{
+    infer_team_from_owner_lists, is_outside_team_ownership, CODE_OWNERS_METADATA_KEY,
+};
+use crate::dead_code_detection_analyzer::is_entity_symbol_exported;
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass};
+use crate::error::Result;
+use crate::git_diff_context_scoper::{map_changed_lines_to_entities, ChangedLineRangeEntry};
+use crate::go_test_coverage_linker::has_test_facet_metadata;
+use crate::storage::CozoDbStorage;
+use crate::token_budget_context_packer::extract_signature_from_code;
+
+/// Section headings, in render order
+pub const REVIEW_BUNDLE_SECTION_HEADINGS: [&str; 6] = [
+    "Changed Symbols",
+    "Callers",
+    "Implementers",
+    "Related Tests",
+    "Public API Surface",
+    "Outside Team Ownership",
+];
+
+/// One symbol listed in a review section
+///
+/// # 4-Word Name: ReviewSymbolListingEntry
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct ReviewSymbolListingEntry {
+    pub entity_key: String,
+    pub name: String,
+    pub entity_type: String,
+    pub file_path: String,
+    pub line_start: u32,
+    pub line_end: u32,
+    pub signature: String,
+    /// Full body (changed symbols only)
+    pub code: Option<String>,
+    /// Changed symbol this entry relates to, and how
+    pub via: Option<String>,
+}
+
+/// Structured review artifact for one change
+///
+/// # 4-Word Name: PullRequestReviewBundle
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct PullRequestReviewBundle {
+    pub revision: String,
+    pub changed_symbols: Vec<ReviewSymbolListingEntry>,
+    pub callers: Vec<ReviewSymbolListingEntry>,
+    pub implementers: Vec<ReviewSymbolListingEntry>,
+    pub related_tests: Vec<ReviewSymbolListingEntry>,
+    pub public_api: Vec<ReviewSymbolListingEntry>,
+    /// Team the ownership section was computed for (v1.7.3)
+    pub ownership_team: Option<String>,
+    /// Touched symbols owned by other teams; `via` names their owners (v1.7.3)
+    pub outside_ownership: Vec<ReviewSymbolListingEntry>,
+}
+
+/// Assemble all sections for a set of changed symbols
+///
+/// # 4-Word Name: assemble_review_bundle_sections
+///
+/// # Contract
+/// - Precondition: `changed_keys` from `map_changed_lines_to_entities`
+/// - Postcondition: each key appears at most once per section; changed
+///   symbols are never listed as callers/implementers; tests only under
+///   Related Tests; entries sorted by (file, line, key)
+/// - Postcondition: Public API Surface = exported changed symbols plus
+///   exported non-test dependents up to `api_depth` reverse hops
+pub fn assemble_review_bundle_sections(
+    revision: &str,
+    changed_keys: &[String],
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    api_depth: usize,
+) -> PullRequestReviewBundle {
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let changed: HashSet<&str> = changed_keys.iter().map(String::as_str).collect();
+    let is_test = |key: &str| {
+        by_key
+            .get(key)
+            .is_some_and(|e| e.entity_class == EntityClass::TestImplementation || has_test_facet_metadata(e))
+    };
+    let changed_name = |key: &str| by_key.get(key).map_or(key.to_string(), |e| e.interface_signature.name.clone());
+
+    let mut callers: BTreeMap<&str, String> = BTreeMap::new();
+    let mut implementers: BTreeMap<&str, String> = BTreeMap::new();
+    let mut tests: BTreeMap<&str, String> = BTreeMap::new();
+    let mut reverse: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges {
+        let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+        // Error propagation runs origin → caller, against the dependency direction
+        if from == to || !by_key.contains_key(from) || edge.edge_type == EdgeType::PropagatesErrorTo {
+            continue;
+        }
+        if edge.edge_type != EdgeType::Tests {
+            reverse.entry(to).or_default().push(from);
+        }
+        if !changed.contains(to) || changed.contains(from) {
+            continue;
+        }
+        let relation = match edge.edge_type {
+            EdgeType::Tests => "tests",
+            EdgeType::Implements => "implements",
+            EdgeType::Calls => "calls",
+            EdgeType::Spawns => "spawns",
+            EdgeType::Embeds => "embeds",
+            EdgeType::Reads => "reads",
+            EdgeType::Writes => "writes",
+            EdgeType::Bound => "is bounded by",
+            EdgeType::QueriesTable => "queries",
+            EdgeType::Serializes => "serializes",
+            EdgeType::GeneratedFrom => "generated_from",
+            EdgeType::Includes => "includes",
+            EdgeType::ConfiguredBy => "configured_by",
+            _ => "uses",
+        };
+        let note = format!("{} `{}`", relation, changed_name(to));
+        let section = if edge.edge_type == EdgeType::Tests || is_test(from) {
+            &mut tests
+        } else if edge.edge_type == EdgeType::Implements {
+            &mut implementers
+        } else if matches!(edge.edge_type, EdgeType::SendsTo | EdgeType::ReceivesFrom) {
+            continue;
+        } else {
+            &mut callers
+        };
+        section.entry(from).or_insert(note);
+    }
+
+    // Exported dependents within api_depth reverse hops (first path wins)
+    let mut api: BTreeMap<&str, String> = BTreeMap::new();
+    let mut visited: HashSet<&str> = changed.clone();
+    let mut queue: VecDeque<(&str, &str, usize)> = VecDeque::new();
+    for key in changed_keys {
+        queue.push_back((key.as_str(), key.as_str(), 0));
+    }
+    while let Some((key, origin, depth)) = queue.pop_front() {
+        if let Some(entity) = by_key.get(key) {
+            if !is_test(key) && is_entity_symbol_exported(entity) {
+                let via = if depth == 0 {
+                    "changed".to_string()
+                } else {
+                    format!("depends on `{}` ({} hop{})", changed_name(origin), depth, if depth == 1 { "" } else { "s" })
+                };
+                api.entry(key).or_insert(via);
+            }
+        }
+        if depth >= api_depth {
+            continue;
+        }
+        for &dependent in reverse.get(key).into_iter().flatten() {
+            if visited.insert(dependent) {
+                queue.push_back((dependent, origin, depth + 1));
+            }
+        }
+    }
+
+    let listing = |keys: Vec<(&str, Option<String>)>, with_code: bool| -> Vec<ReviewSymbolListingEntry> {
+        let mut entries: Vec<ReviewSymbolListingEntry> = keys
+            .into_iter()
+            .filter_map(|(key, via)| {
+                let entity = by_key.get(key)?;
+                let code = entity.current_code.as_deref().unwrap_or_default();
+                Some(ReviewSymbolListingEntry {
+                    entity_key: key.to_string(),
+                    name: entity.interface_signature.name.clone(),
+                    entity_type: format!("{:?}", entity.interface_signature.entity_type),
+                    file_path: entity.interface_signature.file_path.display().to_string(),
+                    line_start: entity.interface_signature.line_range.start,
+                    line_end: entity.interface_signature.line_range.end,
+                    signature: extract_signature_from_code(code),
+                    code: with_code.then(|| code.trim_end().to_string()),
+                    via,
+                })
+            })
+            .collect();
+        entries.sort_by(|a, b| {
+            (&a.file_path, a.line_start, &a.entity_key).cmp(&(&b.file_path, b.line_start, &b.entity_key))
+        });
+        entries
+    };
+    let with_via = |map: BTreeMap<&str, String>| map.into_iter().map(|(k, v)| (k, Some(v))).collect::<Vec<_>>();
+
+    PullRequestReviewBundle {
+        revision: revision.to_string(),
+        changed_symbols: listing(changed_keys.iter().map(|k| (k.as_str(), None)).collect(), true),
+        callers: listing(with_via(callers), false),
+        implementers: listing(with_via(implementers), false),
+        related_tests: listing(with_via(tests), false),
+        public_api: listing(with_via(api), false),
+        ownership_team: None,
+        outside_ownership: Vec::new(),
+    }
+}
+
+/// Fill the Outside Team Ownership section
+///
+/// # 4-Word Name: attach_outside_team_ownership
+///
+/// # Contract
+/// - Precondition: `bundle` from `assemble_review_bundle_sections` over
+///   `entities`
+/// - Postcondition: `ownership_team` is `team`, else the owner of most
+///   changed symbols; the section lists each changed symbol, caller and
+///   implementer (once, changed first) whose code owners exclude the team,
+///   sorted by (file, line, key); both stay empty without ownership data
+pub fn attach_outside_team_ownership(
+    bundle: &mut PullRequestReviewBundle,
+    entities: &[CodeEntity],
+    team: Option<&str>,
+) {
+    let owners: HashMap<&str, &str> = entities
+        .iter()
+        .filter_map(|e| Some((e.isgl1_key.as_str(), e.metadata.additional.get(CODE_OWNERS_METADATA_KEY)?.as_str())))
+        .collect();
+    let team = team.map(str::to_string).or_else(|| {
+        let changed = bundle.changed_symbols.iter().filter_map(|e| owners.get(e.entity_key.as_str()).copied());
+        infer_team_from_owner_lists(changed)
+    });
+    let Some(team) = team else {
+        return;
+    };
+
+    let mut seen: HashSet<String> = HashSet::new();
+    let mut outside: Vec<ReviewSymbolListingEntry> = Vec::new();
+    for entry in bundle.changed_symbols.iter().chain(&bundle.callers).chain(&bundle.implementers) {
+        let Some(code_owners) = owners.get(entry.entity_key.as_str()) else {
+            continue;
+        };
+        if !is_outside_team_ownership(code_owners, &team) || !seen.insert(entry.entity_key.clone()) {
+            continue;
+        }
+        let relation = entry.via.as_deref().unwrap_or("changed");
+        outside.push(ReviewSymbolListingEntry {
+            code: None,
+            via: Some(format!("owned by {}; {}", code_owners.replace(',', ", "), relation)),
+            ..entry.clone()
+        });
+    }
+    outside.sort_by(|a, b| {
+        (&a.file_path, a.line_start, &a.entity_key).cmp(&(&b.file_path, b.line_start, &b.entity_key))
+    });
+    bundle.ownership_team = Some(team);
+    bundle.outside_ownership = outside;
+}
+
+/// Render the bundle as markdown with the fixed section headings
+///
+/// # 4-Word Name: render_review_bundle_markdown
+pub fn render_review_bundle_markdown(bundle: &PullRequestReviewBundle) -> String {
+    let mut out = format!("# Review Pack: {}\n", bundle.revision);
+    let sections = [
+        &bundle.changed_symbols,
+        &bundle.callers,
+        &bundle.implementers,
+        &bundle.related_tests,
+        &bundle.public_api,
+        &bundle.outside_ownership,
+    ];
+    for (heading, entries) in REVIEW_BUNDLE_SECTION_HEADINGS.iter().zip(sections) {
+        out.push_str(&format!("\n## {}\n\n", heading));
+        if let (Some(team), true) = (&bundle.ownership_team, *heading == REVIEW_BUNDLE_SECTION_HEADINGS[5]) {
+            out.push_str(&format!("Team: `{}`\n\n", team));
+        }
+        if entries.is_empty() {
+            out.push_str("_None._\n");
+            continue;
+        }
+        for entry in entries {
+            let location = format!("{}:{}-{}", entry.file_path, entry.line_start, entry.line_end);
+            match &entry.code {
+                Some(code) => {
+                    let fence = entry.entity_key.split(':').next().unwrap_or_default();
+                    out.push_str(&format!(
+                        "### `{}` ({}) — {}\n\n```{}\n{}\n```\n\n",
+                        entry.name, entry.entity_type, location, fence, code
+                    ));
+                }
+                None => {
+                    let via = entry.via.as_deref().map(|v| format!(" — {}", v)).unwrap_or_default();
+                    out.push_str(&format!(
+                        "- `{}` ({}) {}{}\n  `{}`\n",
+                        entry.name,
+                        entry.entity_type,
+                        location,
+                        via,
+                        entry.signature.replace('\n', " ")
+                    ));
+                }
+            }
+        }
+    }
+    out
+}
+
+/// Build the review bundle for a diff from the database
+///
+/// # 4-Word Name: build_review_bundle_from_storage
+///
+/// # Contract
+/// - Postcondition: all sections, ownership against `team` (or the
+///   inferred team, see `attach_outside_team_ownership`)
+pub async fn build_review_bundle_from_storage(
+    storage: &CozoDbStorage,
+    revision: &str,
+    ranges: &[ChangedLineRangeEntry],
+    api_depth: usize,
+    team: Option<&str>,
+) -> Result<PullRequestReviewBundle> {
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let changed_keys = map_changed_lines_to_entities(ranges, &entities);
+    let mut bundle = assemble_review_bundle_sections(revision, &changed_keys, &entities, &edges, api_depth);
+    attach_outside_team_ownership(&mut bundle, &entities, team);
+    Ok(bundle)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .build()
+            .unwrap()
+    }
+
+    fn sample_entities() -> Vec<CodeEntity> {
+        let file = "internal/store/store.go";
+        let store = (CODE_OWNERS_METADATA_KEY, "@acme/store");
+        let mut entities = vec![
+            create_go_test_entity("go:interface:Saver:__s:T1", "Saver", EntityType::Interface, file, &[store]),
+            create_go_test_entity("go:struct:DiskStore:__s:T2", "DiskStore", EntityType::Struct, file, &[store]),
+            create_go_test_entity("go:fn:save:__s:T3", "save", EntityType::Function, file, &[store]),
+            create_go_test_entity("go:fn:Handle:__api:T4", "Handle", EntityType::Function, "api/handler.go", &[(CODE_OWNERS_METADATA_KEY, "@acme/api")]),
+            create_go_test_entity("go:fn:TestSave:__t:T5", "TestSave", EntityType::Function, "internal/store/store_test.go", &[("facet", "test"), store]),
+        ];
+        for entity in &mut entities {
+            entity.current_code = Some(format!("func {}() {{\n}}", entity.interface_signature.name));
+        }
+        entities
+    }
+
+    fn sample_bundle() -> PullRequestReviewBundle {
+        let entities = sample_entities();
+        let edges = vec![
+            edge("go:struct:DiskStore:__s:T2", "go:interface:Saver:__s:T1", EdgeType::Implements),
+            edge("go:fn:Handle:__api:T4", "go:fn:save:__s:T3", EdgeType::Calls),
+            edge("go:fn:TestSave:__t:T5", "go:fn:save:__s:T3", EdgeType::Calls),
+            edge("go:fn:TestSave:__t:T5", "go:fn:save:__s:T3", EdgeType::Tests),
+        ];
+        let changed = vec!["go:interface:Saver:__s:T1".to_string(), "go:fn:save:__s:T3".to_string()];
+        assemble_review_bundle_sections("HEAD~1", &changed, &entities, &edges, 2)
+    }
+
+    #[test]
+    fn test_sections_sort_symbols_by_role() {
+        let bundle = sample_bundle();
+        let names = |entries: &[ReviewSymbolListingEntry]| -> Vec<String> {
+            entries.iter().map(|e| e.name.clone()).collect()
+        };
+        assert_eq!(names(&bundle.changed_symbols), vec!["save", "Saver"]);
+        assert!(bundle.changed_symbols.iter().all(|e| e.code.is_some()));
+        assert_eq!(names(&bundle.callers), vec!["Handle"]);
+        assert_eq!(bundle.callers[0].via.as_deref(), Some("calls `save`"));
+        assert_eq!(names(&bundle.implementers), vec!["DiskStore"]);
+        assert_eq!(names(&bundle.related_tests), vec!["TestSave"]);
+        // Saver is exported itself; Handle and DiskStore depend on changed symbols
+        assert_eq!(names(&bundle.public_api), vec!["Handle", "Saver", "DiskStore"]);
+    }
+
+    #[test]
+    fn test_markdown_keeps_every_heading() {
+        let mut bundle = sample_bundle();
+        bundle.implementers.clear();
+        let markdown = render_review_bundle_markdown(&bundle);
+        assert!(markdown.starts_with("# Review Pack: HEAD~1\n"));
+        for heading in REVIEW_BUNDLE_SECTION_HEADINGS {
+            assert!(markdown.contains(&format!("\n## {}\n", heading)), "missing {}", heading);
+        }
+        assert!(markdown.contains("## Implementers\n\n_None._"));
+        assert!(markdown.contains("```go\nfunc save() {\n}\n```"));
+        assert!(markdown.contains("## Outside Team Ownership\n\n_None._"));
+    }
+
+    #[test]
+    fn test_outside_ownership_against_team() {
+        let entities = sample_entities();
+        let names = |bundle: &PullRequestReviewBundle| -> Vec<String> {
+            bundle.outside_ownership.iter().map(|e| e.name.clone()).collect()
+        };
+
+        // Inferred: the changed symbols belong to @acme/store
+        let mut bundle = sample_bundle();
+        attach_outside_team_ownership(&mut bundle, &entities, None);
+        assert_eq!(bundle.ownership_team.as_deref(), Some("@acme/store"));
+        assert_eq!(names(&bundle), vec!["Handle"]);
+        assert_eq!(bundle.outside_ownership[0].via.as_deref(), Some("owned by @acme/api; calls `save`"));
+        let markdown = render_review_bundle_markdown(&bundle);
+        assert!(markdown.contains("## Outside Team Ownership\n\nTeam: `@acme/store`\n\n- `Handle` (Function) api/handler.go:1-5 — owned by @acme/api; calls `save`\n"));
+
+        // Explicit: the API team sees the store symbols it touches
+        let mut bundle = sample_bundle();
+        attach_outside_team_ownership(&mut bundle, &entities, Some("@acme/api"));
+        assert_eq!(names(&bundle), vec!["save", "Saver", "DiskStore"]);
+        assert_eq!(bundle.outside_ownership[0].via.as_deref(), Some("owned by @acme/store; changed"));
+        assert!(bundle.outside_ownership.iter().all(|e| e.code.is_none()));
+    }
+}
@@ -0,0 +1,564 @@
+//! Protobuf Service Definition Linker (v1.7.3)
+//!
+//! # 4-Word Naming: protobuf_service_definition_linker
+//!
+//! A gRPC call leaves the process: the client stub serializes the request
+//! and some server's handler runs it. Static edges stop at the stub. This
+//! module puts the `.proto` contract in the graph and links both generated
+//! sides to it, so a walk from an RPC handler reaches the client code that
+//! calls it, and the other way round.
+//!
+//! ## Nodes
+//!
+//! Every `service` of every `.proto` file under the ingest root becomes a
+//! `proto:interface:UserService:...` node, and every `rpc` a
+//! `proto:method:UserService.GetUser:...` node carrying `proto_package`,
+//! `rpc_path` (`/acme.user.v1.UserService/GetUser`), `rpc_request`,
+//! `rpc_response` and, for streams, `rpc_streaming` (`client`, `server`,
+//! `bidi`).
+//!
+//! ## Edges (`Calls`, with `rpc_path` and `resolution: grpc_stub`)
+//!
+//! - client stub -> rpc. Go (protoc-gen-go-grpc): the `GetUser` method of
+//!   `userServiceClient`. Rust (tonic): `get_user` in `impl UserServiceClient`
+//! - rpc -> server handler. Go: the `GetUser` method of every struct that
+//!   `Implements` `UserServiceServer` (except the generated
+//!   `UnimplementedUserServiceServer`). Rust: `get_user` in every
+//!   `impl UserService for ...`
+//!
+//! Callers of the client interface (`pb.UserServiceClient`) reach the
+//! concrete stub through the dispatch fan-out. Matching is by service and
+//! method name, so two `.proto` packages declaring the same service name
+//! share their stubs. Generated code must be in the tree (checked in, or
+//! tonic's `out_dir` inside the repository).
+
+use std::collections::{HashMap, HashSet};
+use std::path::{Path, PathBuf};
+
+use crate::entities::{
+    CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType, InterfaceSignature, LanguageSpecificSignature,
+    LineRange, RustSignature, Visibility,
+};
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::isgl1_v2::{compute_birth_timestamp, compute_content_hash, extract_semantic_path, format_key_v2};
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Entity/edge metadata key: full gRPC method path (`/pkg.Service/Method`)
+pub const RPC_PATH_METADATA_KEY: &str = "rpc_path";
+
+/// `resolution` value of edges linking generated stubs to rpc nodes
+pub const GRPC_STUB_RESOLUTION_METADATA_VALUE: &str = "grpc_stub";
+
+/// Directories never searched for `.proto` (and `.sql` schema) files
+const SKIPPED_CONTRACT_DIRECTORY_NAMES: &[&str] = &["node_modules", "target", "vendor", "dist", "build", "third_party"];
+
+/// One `rpc` of a service
+///
+/// # 4-Word Name: ProtoRpcMethodDefinition
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ProtoRpcMethodDefinition {
+    pub name: String,
+    pub request_type: String,
+    pub response_type: String,
+    pub client_streaming: bool,
+    pub server_streaming: bool,
+    /// 1-based
+    pub line: u32,
+}
+
+/// One `service` block
+///
+/// # 4-Word Name: ProtoServiceDefinitionEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ProtoServiceDefinitionEntry {
+    /// `package` of the file; empty when it declares none
+    pub package: String,
+    pub name: String,
+    pub line_start: u32,
+    pub line_end: u32,
+    pub methods: Vec<ProtoRpcMethodDefinition>,
+}
+
+impl ProtoServiceDefinitionEntry {
+    /// `/acme.user.v1.UserService/GetUser`
+    pub fn rpc_method_path(&self, method: &str) -> String {
+        if self.package.is_empty() {
+            format!("/{}/{}", self.name, method)
+        } else {
+            format!("/{}.{}/{}", self.package, self.name, method)
+        }
+    }
+}
+
+/// Services of one `.proto` file
+///
+/// # 4-Word Name: parse_proto_service_definitions
+///
+/// # Contract
+/// - Postcondition: services in file order, rpcs in declaration order;
+///   comments are ignored, option blocks after an rpc are skipped
+pub fn parse_proto_service_definitions(source: &str) -> Vec<ProtoServiceDefinitionEntry> {
+    let tokens = tokenize_proto_source(source);
+    let mut services = Vec::new();
+    let mut package = String::new();
+    let mut i = 0;
+    while i < tokens.len() {
+        match tokens[i].0.as_str() {
+            "package" => {
+                package = tokens.get(i + 1).map(|t| t.0.clone()).unwrap_or_default();
+                i += 2;
+            }
+            "service" if tokens.get(i + 2).is_some_and(|t| t.0.as_str() == "{") => {
+                let mut service = ProtoServiceDefinitionEntry {
+                    package: package.clone(),
+                    name: tokens[i + 1].0.clone(),
+                    line_start: tokens[i].1,
+                    line_end: tokens[i].1,
+                    methods: Vec::new(),
+                };
+                let (mut j, mut depth) = (i + 3, 1);
+                while j < tokens.len() && depth > 0 {
+                    match tokens[j].0.as_str() {
+                        "{" => depth += 1,
+                        "}" => depth -= 1,
+                        "rpc" if depth == 1 => {
+                            if let Some(method) = parse_rpc_signature_tokens(&tokens[j..]) {
+                                service.methods.push(method);
+                            }
+                        }
+                        _ => {}
+                    }
+                    service.line_end = tokens[j].1;
+                    j += 1;
+                }
+                services.push(service);
+                i = j;
+            }
+            _ => i += 1,
+        }
+    }
+    services
+}
+
+/// Service and rpc nodes of one `.proto` file
+///
+/// # 4-Word Name: build_proto_service_entities
+pub fn build_proto_service_entities(file_path: &str, source: &str) -> Vec<CodeEntity> {
+    let lines: Vec<&str> = source.lines().collect();
+    let mut entities = Vec::new();
+    for service in parse_proto_service_definitions(source) {
+        let body = lines
+            .get(service.line_start as usize - 1..(service.line_end as usize).min(lines.len()))
+            .map(|block| block.join("\n"));
+        let mut metadata = vec![("proto_package", service.package.clone())];
+        entities.extend(build_contract_file_entity(
+            "proto",
+            file_path,
+            &service.name,
+            EntityType::Interface,
+            (service.line_start, service.line_end),
+            body,
+            &metadata,
+        ));
+        for method in &service.methods {
+            let streaming = match (method.client_streaming, method.server_streaming) {
+                (true, true) => Some("bidi"),
+                (true, false) => Some("client"),
+                (false, true) => Some("server"),
+                (false, false) => None,
+            };
+            metadata.truncate(1);
+            metadata.push((RPC_PATH_METADATA_KEY, service.rpc_method_path(&method.name)));
+            metadata.push(("rpc_request", method.request_type.clone()));
+            metadata.push(("rpc_response", method.response_type.clone()));
+            metadata.extend(streaming.map(|s| ("rpc_streaming", s.to_string())));
+            let body = lines.get(method.line as usize - 1).map(|line| line.trim().to_string());
+            entities.extend(build_contract_file_entity(
+                "proto",
+                file_path,
+                &format!("{}.{}", service.name, method.name),
+                EntityType::Method,
+                (method.line, method.line),
+                body,
+                &metadata,
+            ));
+        }
+    }
+    entities
+}
+
+/// Service and rpc nodes of every `.proto` file under `root`
+///
+/// # 4-Word Name: load_proto_service_entities
+///
+/// # Contract
+/// - Postcondition: files sorted by path; build, vendor, dependency and
+///   hidden directories are not searched; unreadable files are skipped
+pub fn load_proto_service_entities(root: &Path) -> Vec<CodeEntity> {
+    find_contract_files_by_extension(root, ".proto")
+        .iter()
+        .filter_map(|path| Some((path, std::fs::read_to_string(path).ok()?)))
+        .flat_map(|(path, source)| build_proto_service_entities(&path.to_string_lossy(), &source))
+        .collect()
+}
+
+/// Files under `root` ending in `extension`, sorted; skipped directories as above
+pub fn find_contract_files_by_extension(root: &Path, extension: &str) -> Vec<PathBuf> {
+    let mut files: Vec<PathBuf> = Vec::new();
+    let mut pending = vec![root.to_path_buf()];
+    while let Some(directory) = pending.pop() {
+        let Ok(entries) = std::fs::read_dir(&directory) else { continue };
+        for entry in entries.flatten() {
+            let path = entry.path();
+            let name = entry.file_name().to_string_lossy().into_owned();
+            if entry.file_type().is_ok_and(|t| t.is_dir()) {
+                if !name.starts_with('.') && !SKIPPED_CONTRACT_DIRECTORY_NAMES.contains(&name.as_str()) {
+                    pending.push(path);
+                }
+            } else if name.ends_with(extension) {
+                files.push(path);
+            }
+        }
+    }
+    files.sort();
+    files
+}
+
+/// Edges from generated client stubs to rpc nodes and from rpc nodes to handlers
+///
+/// # 4-Word Name: link_grpc_stub_edges
+///
+/// # Contract
+/// - Precondition: `entities` include the proto nodes; `edges` include Go
+///   structural / Rust `Implements` edges; Go methods carry `receiver_type`
+/// - Postcondition: new edges only, none already present in `edges`;
+///   input unchanged
+pub fn link_grpc_stub_edges(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<DependencyEdge> {
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut by_file: HashMap<&Path, Vec<&CodeEntity>> = HashMap::new();
+    for entity in entities.iter().filter(|e| e.isgl1_key.starts_with("rust:")) {
+        by_file.entry(entity.interface_signature.file_path.as_path()).or_default().push(entity);
+    }
+    let mut seen: HashSet<(String, String)> = edges
+        .iter()
+        .map(|e| (e.from_key.as_str().to_string(), e.to_key.as_str().to_string()))
+        .collect();
+
+    let mut linked = Vec::new();
+    for rpc in entities.iter().filter(|e| e.isgl1_key.starts_with("proto:method:")) {
+        let Some(rpc_path) = rpc.metadata.additional.get(RPC_PATH_METADATA_KEY) else { continue };
+        let Some((service, method)) = rpc.interface_signature.name.split_once('.') else { continue };
+        let rust_method = snake_case_rpc_name(method);
+        let mut link = |from: &str, to: &str| {
+            if seen.insert((from.to_string(), to.to_string())) {
+                linked.extend(
+                    DependencyEdge::builder()
+                        .from_key(from)
+                        .to_key(to)
+                        .edge_type(EdgeType::Calls)
+                        .metadata_entry(RPC_PATH_METADATA_KEY, rpc_path.as_str())
+                        .metadata_entry("resolution", GRPC_STUB_RESOLUTION_METADATA_VALUE)
+                        .build()
+                        .ok(),
+                );
+            }
+        };
+
+        // Go client stub: func (c *userServiceClient) GetUser(...)
+        let client_receiver = lower_first_character(service) + "Client";
+        for stub in entities.iter().filter(|e| {
+            e.isgl1_key.starts_with("go:")
+                && e.interface_signature.name == method
+                && e.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) == Some(&client_receiver)
+        }) {
+            link(&stub.isgl1_key, &rpc.isgl1_key);
+        }
+
+        // Go servers: structs implementing UserServiceServer
+        let server_interface = format!("{}Server", service);
+        let unimplemented = format!("Unimplemented{}", server_interface);
+        for implements in edges.iter().filter(|e| e.edge_type == EdgeType::Implements) {
+            let (Some(implementer), Some(interface)) =
+                (by_key.get(implements.from_key.as_str()), by_key.get(implements.to_key.as_str()))
+            else {
+                continue;
+            };
+            if !implementer.isgl1_key.starts_with("go:")
+                || interface.interface_signature.name != server_interface
+                || implementer.interface_signature.name == unimplemented
+            {
+                continue;
+            }
+            let package = package_directory_of_entity(implementer);
+            for handler in entities.iter().filter(|e| {
+                e.isgl1_key.starts_with("go:")
+                    && e.interface_signature.name == method
+                    && e.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) == Some(&implementer.interface_signature.name)
+                    && package_directory_of_entity(e) == package
+            }) {
+                link(&rpc.isgl1_key, &handler.isgl1_key);
+            }
+        }
+
+        // Rust client stub: impl<T> UserServiceClient<T> { pub async fn get_user(...) }
+        let client_type = format!("{}Client", service);
+        for block in entities.iter().filter(|e| {
+            e.isgl1_key.starts_with("rust:")
+                && matches!(e.interface_signature.entity_type, EntityType::ImplBlock { .. })
+                && e.interface_signature.name == client_type
+        }) {
+            let range = &block.interface_signature.line_range;
+            let file = block.interface_signature.file_path.as_path();
+            for stub in rust_methods_within_lines(&by_file, file, range.start, range.end, &rust_method) {
+                link(&stub.isgl1_key, &rpc.isgl1_key);
+            }
+        }
+
+        // Rust servers: impl UserService for MyService { async fn get_user(...) }
+        for implements in edges.iter().filter(|e| {
+            e.edge_type == EdgeType::Implements
+                && e.from_key.as_str().starts_with("rust:")
+                && e.to_key.as_str().split(':').nth(2) == Some(service)
+        }) {
+            let Some((file, line)) = implements.source_location.as_deref().and_then(|l| l.rsplit_once(':')) else {
+                continue;
+            };
+            let Ok(impl_line) = line.parse::<u32>() else { continue };
+            let file = Path::new(file);
+            let impl_end = by_file
+                .get(file)
+                .into_iter()
+                .flatten()
+                .find(|e| {
+                    matches!(e.interface_signature.entity_type, EntityType::ImplBlock { .. })
+                        && e.interface_signature.line_range.start == impl_line
+                })
+                .map(|e| e.interface_signature.line_range.end)
+                .unwrap_or(impl_line);
+            for handler in rust_methods_within_lines(&by_file, file, impl_line, impl_end, &rust_method) {
+                link(&rpc.isgl1_key, &handler.isgl1_key);
+            }
+        }
+    }
+    linked
+}
+
+/// Rust methods named `name` strictly inside lines `start..=end` of `file`
+fn rust_methods_within_lines<'a>(
+    by_file: &HashMap<&Path, Vec<&'a CodeEntity>>,
+    file: &Path,
+    start: u32,
+    end: u32,
+    name: &str,
+) -> Vec<&'a CodeEntity> {
+    by_file
+        .get(file)
+        .into_iter()
+        .flatten()
+        .filter(|e| {
+            let range = &e.interface_signature.line_range;
+            e.interface_signature.entity_type == EntityType::Method
+                && e.interface_signature.name == name
+                && range.start > start
+                && range.end <= end
+        })
+        .copied()
+        .collect()
+}
+
+/// `GetUser` -> `get_user`, `GetHTTPStatus` -> `get_http_status` (tonic's naming)
+fn snake_case_rpc_name(name: &str) -> String {
+    let chars: Vec<char> = name.chars().collect();
+    let mut snake = String::new();
+    for (i, &c) in chars.iter().enumerate() {
+        if c.is_uppercase() && i > 0 {
+            let previous = chars[i - 1];
+            let next_is_lower = chars.get(i + 1).is_some_and(|n| n.is_lowercase());
+            if previous.is_lowercase() || previous.is_ascii_digit() || (previous.is_uppercase() && next_is_lower) {
+                snake.push('_');
+            }
+        }
+        snake.extend(c.to_lowercase());
+    }
+    snake
+}
+
+fn lower_first_character(name: &str) -> String {
+    let mut chars = name.chars();
+    chars.next().map(|first| first.to_lowercase().chain(chars).collect()).unwrap_or_default()
+}
+
+/// `rpc GetUser (stream GetUserRequest) returns (GetUserResponse)` from tokens at `rpc`
+fn parse_rpc_signature_tokens(tokens: &[(String, u32)]) -> Option<ProtoRpcMethodDefinition> {
+    let text: Vec<&str> = tokens.iter().take(12).map(|t| t.0.as_str()).collect();
+    let name = text.get(1)?.to_string();
+    let (request_type, client_streaming, rest) = split_rpc_message_tokens(text.get(2..)?)?;
+    let ["returns", rest @ ..] = rest else { return None };
+    let (response_type, server_streaming, _) = split_rpc_message_tokens(rest)?;
+    Some(ProtoRpcMethodDefinition {
+        name,
+        request_type,
+        response_type,
+        client_streaming,
+        server_streaming,
+        line: tokens[0].1,
+    })
+}
+
+/// `( [stream] Message )` at the start of `tokens`, and the tokens after it
+fn split_rpc_message_tokens<'a, 'b>(tokens: &'a [&'b str]) -> Option<(String, bool, &'a [&'b str])> {
+    let ["(", rest @ ..] = tokens else { return None };
+    let (streaming, rest) = match rest {
+        ["stream", tail @ ..] => (true, tail),
+        tail => (false, tail),
+    };
+    let [message, ")", tail @ ..] = rest else { return None };
+    Some((message.to_string(), streaming, tail))
+}
+
+/// Identifiers and punctuation with their 1-based line; comments and strings dropped
+fn tokenize_proto_source(source: &str) -> Vec<(String, u32)> {
+    let mut tokens = Vec::new();
+    let chars: Vec<char> = source.chars().collect();
+    let (mut i, mut line) = (0, 1u32);
+    while i < chars.len() {
+        let c = chars[i];
+        match c {
+            '\n' => {
+                line += 1;
+                i += 1;
+            }
+            '/' if chars.get(i + 1) == Some(&'/') => {
+                while i < chars.len() && chars[i] != '\n' {
+                    i += 1;
+                }
+            }
+            '/' if chars.get(i + 1) == Some(&'*') => {
+                i += 2;
+                while i < chars.len() && !(chars[i] == '*' && chars.get(i + 1) == Some(&'/')) {
+                    line += (chars[i] == '\n') as u32;
+                    i += 1;
+                }
+                i += 2;
+            }
+            '"' | '\'' => {
+                i += 1;
+                while i < chars.len() && chars[i] != c {
+                    i += if chars[i] == '\\' { 2 } else { 1 };
+                }
+                i += 1;
+            }
+            c if c.is_alphanumeric() || c == '_' || c == '.' => {
+                let start = i;
+                while i < chars.len() && (chars[i].is_alphanumeric() || chars[i] == '_' || chars[i] == '.') {
+                    i += 1;
+                }
+                tokens.push((chars[start..i].iter().collect(), line));
+            }
+            c if c.is_whitespace() => i += 1,
+            c => {
+                tokens.push((c.to_string(), line));
+                i += 1;
+            }
+        }
+    }
+    tokens
+}
+
+/// Entity for a declaration of a non-source contract file (`.proto`, `.sql`)
+pub(crate) fn build_contract_file_entity(
+    language: &str,
+    file_path: &str,
+    name: &str,
+    kind: EntityType,
+    (start_line, end_line): (u32, u32),
+    body: Option<String>,
+    metadata: &[(&str, String)],
+) -> Option<CodeEntity> {
+    let semantic_path = extract_semantic_path(file_path);
+    let birth = compute_birth_timestamp(file_path, name);
+    let key = format_key_v2(kind.clone(), name, language, &semantic_path, birth);
+    let signature = InterfaceSignature {
+        entity_type: kind,
+        name: name.to_string(),
+        visibility: Visibility::Public,
+        file_path: PathBuf::from(file_path),
+        line_range: LineRange::new(start_line, end_line.max(start_line)).ok()?,
+        module_path: vec![],
+        documentation: None,
+        language_specific: LanguageSpecificSignature::Rust(RustSignature {
+            generics: vec![],
+            lifetimes: vec![],
+            where_clauses: vec![],
+            attributes: vec![],
+            trait_impl: None,
+        }),
+    };
+    let content_hash = compute_content_hash(body.as_deref().unwrap_or(&key));
+    let mut entity =
+        CodeEntity::new_with_v2_fields(key, signature, EntityClass::CodeImplementation, birth, content_hash, semantic_path)
+            .ok()?;
+    entity.current_code = body;
+    for (key, value) in metadata.iter().filter(|(_, value)| !value.is_empty()) {
+        entity.metadata.additional.insert(key.to_string(), value.clone());
+    }
+    Some(entity)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    const USER_PROTO: &str = r#"syntax = "proto3";
+package acme.user.v1;
+
+// Users over the wire
+service UserService {
+  rpc GetUser(GetUserRequest) returns (GetUserResponse);
+  /* streamed */ rpc WatchUsers(stream WatchRequest) returns (stream UserEvent) {
+    option (google.api.http) = { get: "/v1/users:watch" };
+  }
+}
+"#;
+
+    #[test]
+    fn test_proto_nodes_link_go_client_and_server() {
+        let services = parse_proto_service_definitions(USER_PROTO);
+        assert_eq!(services.len(), 1);
+        assert_eq!((services[0].line_start, services[0].line_end), (5, 10));
+        let watch = &services[0].methods[1];
+        assert!(watch.client_streaming && watch.server_streaming);
+        assert_eq!((watch.request_type.as_str(), watch.line), ("WatchRequest", 7));
+
+        let mut entities = build_proto_service_entities("api/user.proto", USER_PROTO);
+        assert_eq!(entities.len(), 3);
+        let rpc_key = entities[1].isgl1_key.clone();
+        assert!(rpc_key.starts_with("proto:method:UserService.GetUser:"), "{}", rpc_key);
+        assert_eq!(entities[1].metadata.additional[RPC_PATH_METADATA_KEY], "/acme.user.v1.UserService/GetUser");
+        assert_eq!(entities[2].metadata.additional["rpc_streaming"], "bidi");
+
+        entities.extend([
+            create_go_test_entity("go:method:GetUser:__gen_user_grpc_pb:T1", "GetUser", EntityType::Method, "gen/user_grpc.pb.go", &[("receiver_type", "userServiceClient")]),
+            create_go_test_entity("go:interface:UserServiceServer:__gen_user_grpc_pb:T2", "UserServiceServer", EntityType::Interface, "gen/user_grpc.pb.go", &[]),
+            create_go_test_entity("go:struct:server:__internal_users:T3", "server", EntityType::Struct, "internal/users/server.go", &[]),
+            create_go_test_entity("go:method:GetUser:__internal_users:T4", "GetUser", EntityType::Method, "internal/users/server.go", &[("receiver_type", "server")]),
+        ]);
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:struct:server:__internal_users:T3")
+            .to_key("go:interface:UserServiceServer:__gen_user_grpc_pb:T2")
+            .edge_type(EdgeType::Implements)
+            .build()
+            .unwrap()];
+        let linked = link_grpc_stub_edges(&entities, &edges);
+        let pairs: Vec<(&str, &str)> = linked.iter().map(|e| (e.from_key.as_str(), e.to_key.as_str())).collect();
+        assert_eq!(
+            pairs,
+            vec![
+                ("go:method:GetUser:__gen_user_grpc_pb:T1", rpc_key.as_str()),
+                (rpc_key.as_str(), "go:method:GetUser:__internal_users:T4"),
+            ]
+        );
+        assert_eq!(snake_case_rpc_name("GetHTTPStatus"), "get_http_status");
+    }
+}
@@ -0,0 +1,260 @@
+//! Minimal protobuf wire format codec (v1.7.3)
+//!
+//! # 4-Word Naming: protobuf_wire_format_codec
+//!
+//! SCIP indexes are protobuf messages. Only a handful of fields matter for
+//! graph import/export, so instead of a generated binding (and protoc in the
+//! build) this module reads and writes the wire format directly:
+//! varint (type 0), 64-bit (1), length-delimited (2) and 32-bit (5) fields.
+//! Unknown fields are skipped, so newer SCIP producers remain readable.
+
+use crate::error::{ParseltongError, Result};
+
+/// Decoded value of one field
+///
+/// # 4-Word Name: ProtobufFieldValue
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ProtobufFieldValue<'a> {
+    Varint(u64),
+    Fixed64(u64),
+    LengthDelimited(&'a [u8]),
+    Fixed32(u32),
+}
+
+impl<'a> ProtobufFieldValue<'a> {
+    /// Bytes of a length-delimited field (strings, messages, packed lists)
+    pub fn as_bytes(&self) -> Option<&'a [u8]> {
+        match self {
+            ProtobufFieldValue::LengthDelimited(bytes) => Some(bytes),
+            _ => None,
+        }
+    }
+
+    /// UTF-8 string of a length-delimited field (lossy)
+    pub fn as_string_lossy(&self) -> Option<String> {
+        self.as_bytes().map(|b| String::from_utf8_lossy(b).into_owned())
+    }
+
+    /// Integer of a varint field
+    pub fn as_varint(&self) -> Option<u64> {
+        match self {
+            ProtobufFieldValue::Varint(v) => Some(*v),
+            _ => None,
+        }
+    }
+}
+
+/// Iterator over the fields of one encoded message
+///
+/// # 4-Word Name: ProtobufFieldReader
+pub struct ProtobufFieldReader<'a> {
+    buffer: &'a [u8],
+    position: usize,
+}
+
+impl<'a> ProtobufFieldReader<'a> {
+    pub fn new(buffer: &'a [u8]) -> Self {
+        Self { buffer, position: 0 }
+    }
+
+    fn read_varint_value(&mut self) -> Result<u64> {
+        let mut value = 0u64;
+        for shift in (0..64).step_by(7) {
+            let byte = *self.buffer.get(self.position).ok_or_else(|| truncated_message_error(self.position))?;
+            self.position += 1;
+            value |= u64::from(byte & 0x7f) << shift;
+            if byte & 0x80 == 0 {
+                return Ok(value);
+            }
+        }
+        Err(ParseltongError::ParseError {
+            reason: "protobuf varint longer than 10 bytes".to_string(),
+            location: format!("byte {}", self.position),
+        })
+    }
+
+    fn take_bytes_slice(&mut self, len: usize) -> Result<&'a [u8]> {
+        let end = self.position.checked_add(len).filter(|end| *end <= self.buffer.len());
+        let end = end.ok_or_else(|| truncated_message_error(self.position))?;
+        let slice = &self.buffer[self.position..end];
+        self.position = end;
+        Ok(slice)
+    }
+}
+
+impl<'a> Iterator for ProtobufFieldReader<'a> {
+    type Item = Result<(u32, ProtobufFieldValue<'a>)>;
+
+    fn next(&mut self) -> Option<Self::Item> {
+        if self.position >= self.buffer.len() {
+            return None;
+        }
+        let field = (|| {
+            let tag = self.read_varint_value()?;
+            let field_number = (tag >> 3) as u32;
+            let value = match tag & 0x7 {
+                0 => ProtobufFieldValue::Varint(self.read_varint_value()?),
+                1 => {
+                    let bytes = self.take_bytes_slice(8)?;
+                    ProtobufFieldValue::Fixed64(u64::from_le_bytes(bytes.try_into().unwrap_or([0; 8])))
+                }
+                2 => {
+                    let len = self.read_varint_value()? as usize;
+                    ProtobufFieldValue::LengthDelimited(self.take_bytes_slice(len)?)
+                }
+                5 => {
+                    let bytes = self.take_bytes_slice(4)?;
+                    ProtobufFieldValue::Fixed32(u32::from_le_bytes(bytes.try_into().unwrap_or([0; 4])))
+                }
+                other => {
+                    return Err(ParseltongError::ParseError {
+                        reason: format!("unsupported protobuf wire type {}", other),
+                        location: format!("byte {}", self.position),
+                    })
+                }
+            };
+            Ok((field_number, value))
+        })();
+        if field.is_err() {
+            // Stop after the first error: the rest of the buffer is unreliable
+            self.position = self.buffer.len();
+        }
+        Some(field)
+    }
+}
+
+fn truncated_message_error(position: usize) -> ParseltongError {
+    ParseltongError::ParseError {
+        reason: "truncated protobuf message".to_string(),
+        location: format!("byte {}", position),
+    }
+}
+
+/// Decode a packed repeated int32 field
+///
+/// # 4-Word Name: decode_packed_int32_list
+pub fn decode_packed_int32_list(bytes: &[u8]) -> Result<Vec<i32>> {
+    let mut reader = ProtobufFieldReader::new(bytes);
+    let mut values = Vec::new();
+    while reader.position < bytes.len() {
+        values.push(reader.read_varint_value()? as i32);
+    }
+    Ok(values)
+}
+
+/// Builder for one encoded message
+///
+/// # 4-Word Name: ProtobufMessageWriter
+#[derive(Debug, Default, Clone)]
+pub struct ProtobufMessageWriter {
+    buffer: Vec<u8>,
+}
+
+impl ProtobufMessageWriter {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    fn push_varint_value(&mut self, mut value: u64) {
+        while value >= 0x80 {
+            self.buffer.push((value as u8) | 0x80);
+            value >>= 7;
+        }
+        self.buffer.push(value as u8);
+    }
+
+    fn push_field_tag(&mut self, field_number: u32, wire_type: u8) {
+        self.push_varint_value((u64::from(field_number) << 3) | u64::from(wire_type));
+    }
+
+    /// Varint field; zero is the proto3 default and is omitted
+    pub fn write_varint_field(&mut self, field_number: u32, value: u64) -> &mut Self {
+        if value != 0 {
+            self.push_field_tag(field_number, 0);
+            self.push_varint_value(value);
+        }
+        self
+    }
+
+    /// 64-bit field (fixed64, `f64::to_bits` for doubles); zero is omitted
+    pub fn write_fixed64_field(&mut self, field_number: u32, value: u64) -> &mut Self {
+        if value != 0 {
+            self.push_field_tag(field_number, 1);
+            self.buffer.extend_from_slice(&value.to_le_bytes());
+        }
+        self
+    }
+
+    /// Length-delimited bytes field; empty is omitted
+    pub fn write_bytes_field(&mut self, field_number: u32, bytes: &[u8]) -> &mut Self {
+        if !bytes.is_empty() {
+            self.push_field_tag(field_number, 2);
+            self.push_varint_value(bytes.len() as u64);
+            self.buffer.extend_from_slice(bytes);
+        }
+        self
+    }
+
+    pub fn write_string_field(&mut self, field_number: u32, text: &str) -> &mut Self {
+        self.write_bytes_field(field_number, text.as_bytes())
+    }
+
+    /// Embedded message field (always written, even when empty)
+    pub fn write_message_field(&mut self, field_number: u32, message: &ProtobufMessageWriter) -> &mut Self {
+        self.push_field_tag(field_number, 2);
+        self.push_varint_value(message.buffer.len() as u64);
+        self.buffer.extend_from_slice(&message.buffer);
+        self
+    }
+
+    /// Packed repeated int32 field; empty is omitted
+    pub fn write_packed_int32_field(&mut self, field_number: u32, values: &[i32]) -> &mut Self {
+        let mut packed = ProtobufMessageWriter::new();
+        for value in values {
+            // Negative int32 values are sign-extended to 10 bytes, as protoc does
+            packed.push_varint_value(*value as i64 as u64);
+        }
+        self.write_bytes_field(field_number, &packed.buffer)
+    }
+
+    pub fn into_bytes(self) -> Vec<u8> {
+        self.buffer
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_writer_reader_roundtrip() {
+        let mut inner = ProtobufMessageWriter::new();
+        inner.write_string_field(1, "pkg/Foo#").write_varint_field(3, 1);
+        let mut outer = ProtobufMessageWriter::new();
+        outer
+            .write_string_field(1, "main.go")
+            .write_message_field(2, &inner)
+            .write_packed_int32_field(4, &[12, 300, 7]);
+        let bytes = outer.into_bytes();
+
+        let fields: Vec<(u32, ProtobufFieldValue<'_>)> =
+            ProtobufFieldReader::new(&bytes).collect::<Result<_>>().unwrap();
+        assert_eq!(fields.len(), 3);
+        assert_eq!(fields[0].1.as_string_lossy().as_deref(), Some("main.go"));
+
+        let nested: Vec<_> = ProtobufFieldReader::new(fields[1].1.as_bytes().unwrap())
+            .collect::<Result<Vec<_>>>()
+            .unwrap();
+        assert_eq!(nested[1], (3, ProtobufFieldValue::Varint(1)));
+        assert_eq!(decode_packed_int32_list(fields[2].1.as_bytes().unwrap()).unwrap(), vec![12, 300, 7]);
+    }
+
+    #[test]
+    fn test_truncated_message_is_error() {
+        // Field 1, length 5, only 2 bytes present
+        let bytes = [0x0a, 0x05, b'a', b'b'];
+        let results: Vec<_> = ProtobufFieldReader::new(&bytes).collect();
+        assert_eq!(results.len(), 1);
+        assert!(results[0].is_err());
+    }
+}
@@ -0,0 +1,338 @@
+//! Python Import & Self-Call Resolver (v1.7.3)
+//!
+//! # 4-Word Naming: python_import_call_resolver
+//!
+//! The per-file extractor emits every Python call as an unresolved target
+//! (`python:fn:save:unresolved-reference:0-0`). This cross-file pass points
+//! calls and base-class edges at the entity that actually runs, so mixed
+//! Rust/Go/Python services end up in one connected graph:
+//!
+//! 1. `self.save()` / `cls.create()` inside a method → method of the
+//!    enclosing class, else of its base classes (depth-first, declared order)
+//! 2. `helper()` → function or class (constructor call) in the same file
+//! 3. `helper()` where the file imports `helper` → the unique project-wide
+//!    top-level function/class of that name
+//! 4. `class Admin(User)` Implements edges → class `User` (same rules 2-3)
+//!
+//! ## Inputs (from query_extractor)
+//!
+//! - Methods: `receiver_type` (enclosing class); classes: `base_classes`
+//! - Call edges: `receiver_expr` (plain identifier operand)
+//! - Import `Uses` edges: `python:module:{name}:0-0`, source location = file
+//!
+//! ## Limitations (syntactic)
+//!
+//! - Calls on locals/params (`user.save()`) stay unresolved
+//! - Import targets are matched by name, not by module path; ambiguous
+//!   names (defined in several modules) stay unresolved
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType, Isgl1Key};
+use crate::go_embedding_promotion_resolver::{
+    parse_unresolved_reference_name, RECEIVER_EXPR_METADATA_KEY, UNRESOLVED_TARGET_METADATA_KEY,
+};
+use crate::structural_interface_matcher::RECEIVER_TYPE_METADATA_KEY;
+
+/// Metadata key holding a Python class's base class names
+pub const BASE_CLASSES_METADATA_KEY: &str = "base_classes";
+
+/// Key prefix of Python import targets emitted by the extractor
+const PYTHON_IMPORT_TARGET_PREFIX: &str = "python:module:";
+
+/// Base-class chains deeper than this are not followed
+const MAX_BASE_CLASS_DEPTH: usize = 8;
+
+/// True for `.py` / `.pyi` source files
+///
+/// # 4-Word Name: is_python_entity_file_path
+pub fn is_python_entity_file_path(path: &Path) -> bool {
+    matches!(path.extension().and_then(|e| e.to_str()), Some("py" | "pyi"))
+}
+
+/// Names imported per file, from the extractor's import `Uses` edges
+///
+/// # 4-Word Name: collect_python_imported_names
+///
+/// # Contract
+/// - Key: file path as written in the edge's source location (`path:line`)
+/// - Value: imported names (`Path` for `from pathlib import Path`)
+pub fn collect_python_imported_names(edges: &[DependencyEdge]) -> HashMap<String, HashSet<String>> {
+    let mut imports: HashMap<String, HashSet<String>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Uses) {
+        let Some(rest) = edge.to_key.as_str().strip_prefix(PYTHON_IMPORT_TARGET_PREFIX) else {
+            continue;
+        };
+        let Some(name) = rest.strip_suffix(":0-0") else { continue };
+        let Some((file, _line)) = edge.source_location.as_deref().and_then(|l| l.rsplit_once(':')) else {
+            continue;
+        };
+        imports.entry(file.to_string()).or_default().insert(name.to_string());
+    }
+    imports
+}
+
+/// Python definitions indexed for resolution
+struct PythonDefinitionIndex<'a> {
+    /// (file, name) → top-level function/class
+    top_level_by_file: HashMap<(String, String), &'a CodeEntity>,
+    /// name → every top-level function/class with that name
+    top_level_by_name: HashMap<&'a str, Vec<&'a CodeEntity>>,
+    /// (file, class, method name) → method
+    methods: HashMap<(String, String, String), &'a CodeEntity>,
+    /// (file, class) → class entity
+    classes: HashMap<(String, String), &'a CodeEntity>,
+}
+
+impl<'a> PythonDefinitionIndex<'a> {
+    fn build_from_code_entities(entities: &'a [CodeEntity]) -> Self {
+        let mut index = PythonDefinitionIndex {
+            top_level_by_file: HashMap::new(),
+            top_level_by_name: HashMap::new(),
+            methods: HashMap::new(),
+            classes: HashMap::new(),
+        };
+        for entity in entities {
+            if !is_python_entity_file_path(&entity.interface_signature.file_path) {
+                continue;
+            }
+            let file = file_path_string(entity);
+            let name = entity.interface_signature.name.as_str();
+            match entity.interface_signature.entity_type {
+                EntityType::Method => {
+                    if let Some(class) = entity.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) {
+                        index.methods.insert((file, class.clone(), name.to_string()), entity);
+                    }
+                }
+                EntityType::Class | EntityType::Function => {
+                    if entity.interface_signature.entity_type == EntityType::Class {
+                        index.classes.insert((file.clone(), name.to_string()), entity);
+                    }
+                    index.top_level_by_file.entry((file, name.to_string())).or_insert(entity);
+                    index.top_level_by_name.entry(name).or_default().push(entity);
+                }
+                _ => {}
+            }
+        }
+        index
+    }
+
+    /// Same file first, then a unique project-wide match if `name` is imported
+    fn resolve_name_from_file(
+        &self,
+        file: &str,
+        name: &str,
+        imported: Option<&HashSet<String>>,
+    ) -> Option<(&'a CodeEntity, &'static str)> {
+        if let Some(entity) = self.top_level_by_file.get(&(file.to_string(), name.to_string())) {
+            return Some((*entity, "same_file"));
+        }
+        if !imported.is_some_and(|names| names.contains(name)) {
+            return None;
+        }
+        match self.top_level_by_name.get(name).map(Vec::as_slice) {
+            Some([only]) => Some((*only, "imported_name")),
+            _ => None,
+        }
+    }
+
+    /// Method `name` on `class` (declared in `file`) or its base classes
+    fn resolve_method_on_class(
+        &self,
+        file: &str,
+        class: &str,
+        name: &str,
+        imports: &HashMap<String, HashSet<String>>,
+        depth: usize,
+    ) -> Option<(&'a CodeEntity, &'static str)> {
+        if depth > MAX_BASE_CLASS_DEPTH {
+            return None;
+        }
+        if let Some(method) = self.methods.get(&(file.to_string(), class.to_string(), name.to_string())) {
+            return Some((*method, if depth == 0 { "self_method" } else { "inherited_method" }));
+        }
+        let class_entity = self.classes.get(&(file.to_string(), class.to_string()))?;
+        let bases = class_entity.metadata.additional.get(BASE_CLASSES_METADATA_KEY)?;
+        for base in bases.split(',').filter(|b| !b.is_empty()) {
+            let Some((base_entity, _)) = self.resolve_name_from_file(file, base, imports.get(file)) else {
+                continue;
+            };
+            if base_entity.interface_signature.entity_type != EntityType::Class {
+                continue;
+            }
+            let base_file = file_path_string(base_entity);
+            // The base class's own file decides what *its* names refer to
+            if let Some((method, _)) = self.resolve_method_on_class(&base_file, base, name, imports, depth + 1) {
+                return Some((method, "inherited_method"));
+            }
+        }
+        None
+    }
+}
+
+fn file_path_string(entity: &CodeEntity) -> String {
+    entity.interface_signature.file_path.to_string_lossy().replace('\\', "/")
+}
+
+/// Resolve Python call and base-class targets in place
+///
+/// # 4-Word Name: resolve_python_import_calls
+///
+/// # Contract
+/// - Precondition: entities carry query_extractor metadata; `edges` include
+///   the import `Uses` edges of every caller's file
+/// - Postcondition: resolved edges point at real entity keys and carry
+///   `resolution` (`self_method` / `inherited_method` / `same_file` /
+///   `imported_name`) and `unresolved_target`; other edges are untouched
+/// - Returns: number of edges rewritten
+pub fn resolve_python_import_calls(entities: &[CodeEntity], edges: &mut [DependencyEdge]) -> usize {
+    let index = PythonDefinitionIndex::build_from_code_entities(entities);
+    let imports = collect_python_imported_names(edges);
+    let entities_by_key: HashMap<&str, &CodeEntity> =
+        entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+
+    let mut rewritten = 0;
+    for edge in edges.iter_mut() {
+        if !matches!(edge.edge_type, EdgeType::Calls | EdgeType::Implements) {
+            continue;
+        }
+        let Some(from) = entities_by_key.get(edge.from_key.as_str()) else {
+            continue;
+        };
+        if !is_python_entity_file_path(&from.interface_signature.file_path) {
+            continue;
+        }
+        let Some(target_name) = parse_unresolved_reference_name(edge.to_key.as_str()) else {
+            continue;
+        };
+        let target_name = target_name.to_string();
+        let file = file_path_string(from);
+        let imported = imports.get(&file);
+
+        let resolved = match edge.metadata.get(RECEIVER_EXPR_METADATA_KEY).map(String::as_str) {
+            Some("self" | "cls") if edge.edge_type == EdgeType::Calls => from
+                .metadata
+                .additional
+                .get(RECEIVER_TYPE_METADATA_KEY)
+                .and_then(|class| index.resolve_method_on_class(&file, class, &target_name, &imports, 0)),
+            // Attribute call on anything else (`user.save()`, `os.path.join()`): no type info
+            Some(_) => None,
+            None => index
+                .resolve_name_from_file(&file, &target_name, imported)
+                .filter(|(target, _)| {
+                    edge.edge_type == EdgeType::Calls || target.interface_signature.entity_type == EntityType::Class
+                }),
+        };
+        let Some((target, resolution)) = resolved else { continue };
+        if target.isgl1_key == from.isgl1_key {
+            // Recursion is real, but a self-loop to the caller adds nothing here
+            continue;
+        }
+        if let Ok(key) = Isgl1Key::new(target.isgl1_key.clone()) {
+            edge.metadata.insert(
+                UNRESOLVED_TARGET_METADATA_KEY.to_string(),
+                edge.to_key.as_str().to_string(),
+            );
+            edge.to_key = key;
+            edge.metadata.insert("resolution".to_string(), resolution.to_string());
+            rewritten += 1;
+        }
+    }
+    rewritten
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn call(from: &str, to: &str, location: &str, receiver: Option<&str>) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .source_location(location);
+        if let Some(receiver) = receiver {
+            builder = builder.metadata_entry(RECEIVER_EXPR_METADATA_KEY, receiver);
+        }
+        builder.build().unwrap()
+    }
+
+    fn import(file: &str, name: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(format!("python:file:{}:1-1", file))
+            .to_key(format!("python:module:{}:0-0", name))
+            .edge_type(EdgeType::Uses)
+            .source_location(format!("{}:1", file))
+            .build()
+            .unwrap()
+    }
+
+    fn fixture() -> Vec<CodeEntity> {
+        vec![
+            create_go_test_entity("python:class:Model:__app_base:T1", "Model", EntityType::Class, "app/base.py", &[]),
+            create_go_test_entity(
+                "python:method:save:__app_base:T2",
+                "save",
+                EntityType::Method,
+                "app/base.py",
+                &[("receiver_type", "Model")],
+            ),
+            create_go_test_entity(
+                "python:class:User:__app_models:T3",
+                "User",
+                EntityType::Class,
+                "app/models.py",
+                &[("base_classes", "Model")],
+            ),
+            create_go_test_entity(
+                "python:method:rename:__app_models:T4",
+                "rename",
+                EntityType::Method,
+                "app/models.py",
+                &[("receiver_type", "User")],
+            ),
+            create_go_test_entity("python:fn:hash_password:__app_auth:T5", "hash_password", EntityType::Function, "app/auth.py", &[]),
+            create_go_test_entity("python:fn:register:__app_views:T6", "register", EntityType::Function, "app/views.py", &[]),
+        ]
+    }
+
+    #[test]
+    fn test_imported_function_and_constructor_resolved() {
+        let entities = fixture();
+        let mut edges = vec![
+            import("app/views.py", "hash_password"),
+            import("app/views.py", "User"),
+            call("python:fn:register:__app_views:T6", "python:fn:hash_password:unresolved-reference:0-0", "app/views.py:3", None),
+            call("python:fn:register:__app_views:T6", "python:fn:User:unresolved-reference:0-0", "app/views.py:4", None),
+            // Not imported: stays unresolved
+            call("python:fn:register:__app_views:T6", "python:fn:Model:unresolved-reference:0-0", "app/views.py:5", None),
+        ];
+
+        assert_eq!(resolve_python_import_calls(&entities, &mut edges), 2);
+        assert_eq!(edges[2].to_key.as_str(), "python:fn:hash_password:__app_auth:T5");
+        assert_eq!(edges[2].metadata_value("resolution"), Some("imported_name"));
+        assert_eq!(edges[3].to_key.as_str(), "python:class:User:__app_models:T3");
+        assert!(edges[4].to_key.as_str().contains("unresolved-reference"));
+    }
+
+    #[test]
+    fn test_self_call_follows_base_classes() {
+        let entities = fixture();
+        let mut edges = vec![
+            import("app/models.py", "Model"),
+            call("python:method:rename:__app_models:T4", "python:fn:save:unresolved-reference:0-0", "app/models.py:9", Some("self")),
+            call("python:method:rename:__app_models:T4", "python:fn:save:unresolved-reference:0-0", "app/models.py:10", Some("other")),
+        ];
+
+        assert_eq!(resolve_python_import_calls(&entities, &mut edges), 1);
+        assert_eq!(edges[1].to_key.as_str(), "python:method:save:__app_base:T2");
+        assert_eq!(edges[1].metadata_value("resolution"), Some("inherited_method"));
+        assert_eq!(
+            edges[1].metadata_value(UNRESOLVED_TARGET_METADATA_KEY),
+            Some("python:fn:save:unresolved-reference:0-0")
+        );
+        assert!(edges[2].to_key.as_str().contains("unresolved-reference"));
+    }
+}
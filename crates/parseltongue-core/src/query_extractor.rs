@@ -20,16 +20,20 @@
 //!
 //! ## Supported Languages
 //!
-//! Currently supports: Rust, Python, C, C++, Ruby, JavaScript, TypeScript, Go, Java, PHP, C#, Swift (12 languages)
-//! Note: Kotlin support pending tree-sitter version upgrade (currently incompatible: 0.20 vs 0.25)
+//! Currently supports: Rust, Python, C, C++, Ruby, JavaScript, TypeScript, Go, Java, Kotlin, PHP, C#, Swift (13 languages)
+//! Note: Kotlin (v1.7.3, tree-sitter-kotlin-ng) is extracted by a syntax walk, not a query file
 //! Extensible: Add new languages by creating .scm query files (~1 hour per language)
 
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 use std::path::Path;
 use anyhow::{Context, Result};
 use tree_sitter::{Query, QueryCursor, Tree, Parser, StreamingIterator};
 
-use crate::entities::{Language, DependencyEdge, EdgeType};
+use crate::c_include_header_resolver::{
+    format_unresolved_include_target_key, unquote_include_path_text, INCLUDE_KIND_METADATA_KEY,
+    INCLUDE_PATH_METADATA_KEY,
+};
+use crate::entities::{Language, DependencyEdge, EdgeType, Isgl1Key};
 use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path};
 
 /// Parsed code entity representation
@@ -59,6 +63,9 @@ pub enum EntityType {
     Namespace,
     Table,      // v1.5.6: SQL tables
     View,       // v1.5.6: SQL views
+    Constant,   // v1.7.3: Go package-level const
+    Variable,   // v1.7.3: Go package-level var
+    Route,      // v1.7.3: HTTP route registration
 }
 
 /// Query-based extractor using .scm query files
@@ -68,11 +75,767 @@ pub struct QueryBasedExtractor {
     parsers: HashMap<Language, Parser>,
 }
 
+/// Normalize generic bracket text for metadata
+///
+/// Strips the surrounding `[...]` / `<...>` and collapses whitespace so
+/// `[K comparable,\n V any]` becomes `K comparable, V any`.
+fn normalize_generic_bracket_text(text: &str) -> String {
+    let trimmed = text.trim();
+    let inner = trimmed
+        .strip_prefix('[')
+        .and_then(|t| t.strip_suffix(']'))
+        .or_else(|| trimmed.strip_prefix('<').and_then(|t| t.strip_suffix('>')))
+        .unwrap_or(trimmed);
+    inner.split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+/// Record Go method-set facts used for structural interface matching
+///
+/// Go has no `implements` keyword, so satisfaction must be computed from
+/// method sets after all files are parsed. This captures the raw facts:
+/// - Methods: `receiver_type` = bare receiver type (`*Box[T]` → `Box`),
+///   `receiver_name` = receiver variable (`s` in `func (s *Server)`)
+/// - Interfaces: `interface_methods` = sorted, comma-separated method names
+/// - Structs/interfaces: `embedded_types` = bare names of embedded types
+/// - Functions/methods: `local_types` = named types of params and locals
+///   (`srv=Server,u=models.User`), see `insert_go_local_variable_types`
+fn enrich_go_structural_metadata(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    entity_type: &EntityType,
+    metadata: &mut HashMap<String, String>,
+) {
+    match entity_type {
+        EntityType::Method => {
+            let receiver_type = node
+                .child_by_field_name("receiver")
+                .and_then(|receiver| find_first_descendant_of_kind(receiver, "type_identifier"));
+            if let Some(type_node) = receiver_type {
+                metadata.insert(
+                    "receiver_type".to_string(),
+                    source[type_node.byte_range()].to_string(),
+                );
+            }
+            let receiver_name = node
+                .child_by_field_name("receiver")
+                .and_then(|receiver| find_first_descendant_of_kind(receiver, "parameter_declaration"))
+                .and_then(|param| param.child_by_field_name("name"));
+            if let Some(name_node) = receiver_name {
+                metadata.insert(
+                    "receiver_name".to_string(),
+                    source[name_node.byte_range()].to_string(),
+                );
+            }
+            insert_go_local_variable_types(node, source, metadata);
+        }
+        EntityType::Function => {
+            insert_go_local_variable_types(node, source, metadata);
+        }
+        EntityType::Interface => {
+            let mut methods = Vec::new();
+            collect_go_interface_method_names(node, source, &mut methods);
+            methods.sort();
+            methods.dedup();
+            if !methods.is_empty() {
+                metadata.insert("interface_methods".to_string(), methods.join(","));
+            }
+            insert_go_embedded_types(node, source, metadata);
+        }
+        EntityType::Struct => {
+            insert_go_embedded_types(node, source, metadata);
+        }
+        _ => {}
+    }
+}
+
+/// Function or method a Go local was bound to
+#[derive(Debug, Clone)]
+struct GoFunctionValueTarget {
+    name: String,
+    receiver: Option<String>,
+}
+
+/// Local function-value bindings of one Go function, in source order
+#[derive(Default)]
+struct GoFunctionValueScope {
+    /// Parameters and declared locals (not function names)
+    locals: std::collections::HashSet<String>,
+    /// (local, byte offset of the assignment, bound target or None = rebound)
+    bindings: Vec<(String, usize, Option<GoFunctionValueTarget>)>,
+    /// (call line, local called, target)
+    call_sites: Vec<(usize, String, GoFunctionValueTarget)>,
+}
+
+impl GoFunctionValueScope {
+    fn binding_before(&self, name: &str, byte: usize) -> Option<&GoFunctionValueTarget> {
+        self.bindings
+            .iter()
+            .rev()
+            .find(|(local, at, _)| local == name && *at < byte)
+            .and_then(|(_, _, target)| target.as_ref())
+    }
+
+    /// `handler` → function `handler` unless it is a local; `s.process` → method value
+    fn classify_bound_value(&self, value: tree_sitter::Node<'_>, source: &str) -> Option<GoFunctionValueTarget> {
+        match value.kind() {
+            "identifier" => {
+                let name = &source[value.byte_range()];
+                if let Some(target) = self.binding_before(name, value.start_byte()) {
+                    return Some(target.clone());
+                }
+                (!self.locals.contains(name)).then(|| GoFunctionValueTarget {
+                    name: name.to_string(),
+                    receiver: None,
+                })
+            }
+            "selector_expression" => {
+                let field = value.child_by_field_name("field")?;
+                let receiver = value
+                    .child_by_field_name("operand")
+                    .filter(|operand| operand.kind() == "identifier")
+                    .map(|operand| source[operand.byte_range()].to_string());
+                Some(GoFunctionValueTarget {
+                    name: source[field.byte_range()].to_string(),
+                    receiver,
+                })
+            }
+            "parenthesized_expression" => value
+                .named_child(0)
+                .and_then(|inner| self.classify_bound_value(inner, source)),
+            _ => None,
+        }
+    }
+}
+
+/// Retarget Go calls made through local function values (v1.7.3)
+///
+/// `handler := s.processUser; handler(ctx, u)` is extracted as a call to
+/// `handler`. Within each function, locals assigned a function name or a
+/// method value (`x.Method`, not called) are tracked in source order; a call
+/// through one is pointed at the bound name and tagged `confidence=indirect`
+/// and `via_binding=handler`. Method values also carry `receiver_expr`, so
+/// the receiver-method pass resolves `s.processUser`. Rebinding a local to
+/// anything else (a call result, a closure, nil) ends the binding.
+fn retarget_go_function_value_calls(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    edges: &mut [DependencyEdge],
+) {
+    let mut declarations = Vec::new();
+    let mut cursor = root.walk();
+    for child in root.named_children(&mut cursor) {
+        if matches!(child.kind(), "function_declaration" | "method_declaration") {
+            declarations.push(child);
+        }
+    }
+
+    let mut call_sites = Vec::new();
+    for declaration in declarations {
+        let mut scope = GoFunctionValueScope::default();
+        for field in ["receiver", "parameters"] {
+            if let Some(list) = declaration.child_by_field_name(field) {
+                collect_go_parameter_names(list, source, &mut scope.locals);
+            }
+        }
+        if let Some(body) = declaration.child_by_field_name("body") {
+            walk_go_function_value_scope(body, source, &mut scope);
+        }
+        call_sites.extend(scope.call_sites);
+    }
+
+    for (line, local, target) in call_sites {
+        let location = format!("{}:{}", file_path.display(), line);
+        let via_key = format!("go:fn:{}:unresolved-reference:0-0", sanitize_name_double_colon(&local));
+        for edge in edges.iter_mut().filter(|e| {
+            matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)
+                && e.to_key.as_str() == via_key
+                && e.source_location.as_deref() == Some(location.as_str())
+        }) {
+            edge.to_key = Isgl1Key::new_unchecked(format!(
+                "go:fn:{}:unresolved-reference:0-0",
+                sanitize_name_double_colon(&target.name)
+            ));
+            edge.metadata.insert("confidence".to_string(), "indirect".to_string());
+            edge.metadata.insert("via_binding".to_string(), local.clone());
+            if let Some(receiver) = &target.receiver {
+                edge.metadata.insert("receiver_expr".to_string(), receiver.clone());
+            }
+        }
+    }
+}
+
+fn collect_go_parameter_names(
+    list: tree_sitter::Node<'_>,
+    source: &str,
+    locals: &mut std::collections::HashSet<String>,
+) {
+    let mut cursor = list.walk();
+    for param in list.named_children(&mut cursor) {
+        let mut name_cursor = param.walk();
+        for name in param.children_by_field_name("name", &mut name_cursor) {
+            locals.insert(source[name.byte_range()].to_string());
+        }
+    }
+}
+
+/// Record assignments and calls in source order (preorder = byte order)
+fn walk_go_function_value_scope(node: tree_sitter::Node<'_>, source: &str, scope: &mut GoFunctionValueScope) {
+    match node.kind() {
+        "short_var_declaration" | "assignment_statement" => {
+            if node.kind() == "assignment_statement"
+                && node.child_by_field_name("operator").is_some_and(|op| &source[op.byte_range()] != "=")
+            {
+                // `x += y` and friends never bind a function
+            } else if let (Some(left), Some(right)) = (node.child_by_field_name("left"), node.child_by_field_name("right")) {
+                let mut left_cursor = left.walk();
+                let names: Vec<_> = left.named_children(&mut left_cursor).collect();
+                let mut right_cursor = right.walk();
+                let values: Vec<_> = right.named_children(&mut right_cursor).collect();
+                for (index, name) in names.iter().enumerate() {
+                    if name.kind() != "identifier" {
+                        continue;
+                    }
+                    let value = (names.len() == values.len())
+                        .then(|| values[index])
+                        .and_then(|value| scope.classify_bound_value(value, source));
+                    let local = source[name.byte_range()].to_string();
+                    scope.locals.insert(local.clone());
+                    scope.bindings.push((local, node.end_byte(), value));
+                }
+            }
+        }
+        "var_spec" => {
+            let mut name_cursor = node.walk();
+            let names: Vec<_> = node.children_by_field_name("name", &mut name_cursor).collect();
+            let values: Vec<_> = node
+                .child_by_field_name("value")
+                .map(|list| {
+                    let mut value_cursor = list.walk();
+                    let collected: Vec<_> = list.named_children(&mut value_cursor).collect();
+                    collected
+                })
+                .unwrap_or_default();
+            for (index, name) in names.iter().enumerate() {
+                let value = (names.len() == values.len())
+                    .then(|| values[index])
+                    .and_then(|value| scope.classify_bound_value(value, source));
+                let local = source[name.byte_range()].to_string();
+                scope.locals.insert(local.clone());
+                scope.bindings.push((local, node.end_byte(), value));
+            }
+        }
+        "call_expression" => {
+            if let Some(function) = node.child_by_field_name("function").filter(|f| f.kind() == "identifier") {
+                let local = &source[function.byte_range()];
+                if let Some(target) = scope.binding_before(local, node.start_byte()) {
+                    let target = target.clone();
+                    scope.call_sites.push((node.start_position().row + 1, local.to_string(), target));
+                }
+            }
+        }
+        _ => {}
+    }
+    let mut cursor = node.walk();
+    for child in node.named_children(&mut cursor) {
+        walk_go_function_value_scope(child, source, scope);
+    }
+}
+
+/// Record Python class structure for cross-file resolution (v1.7.3)
+///
+/// - Methods: `receiver_type` = enclosing class name (decorators skipped)
+/// - Classes: `base_classes` = superclass names, last dotted segment
+///   (`models.Model` → `Model`), comma-separated
+fn enrich_python_structural_metadata(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    entity_type: &EntityType,
+    metadata: &mut HashMap<String, String>,
+) {
+    match entity_type {
+        EntityType::Method => {
+            let mut ancestor = node.parent();
+            while let Some(current) = ancestor {
+                if current.kind() == "class_definition" {
+                    if let Some(name) = current.child_by_field_name("name") {
+                        metadata.insert("receiver_type".to_string(), source[name.byte_range()].to_string());
+                    }
+                    break;
+                }
+                if current.kind() == "function_definition" {
+                    break;
+                }
+                ancestor = current.parent();
+            }
+        }
+        EntityType::Class => {
+            let Some(superclasses) = node.child_by_field_name("superclasses") else {
+                return;
+            };
+            let mut bases = Vec::new();
+            let mut cursor = superclasses.walk();
+            for child in superclasses.named_children(&mut cursor) {
+                let name = match child.kind() {
+                    "identifier" => Some(&source[child.byte_range()]),
+                    "attribute" => child.child_by_field_name("attribute").map(|a| &source[a.byte_range()]),
+                    // Keyword arguments (metaclass=...) are not base classes
+                    _ => None,
+                };
+                if let Some(name) = name {
+                    bases.push(name.to_string());
+                }
+            }
+            if !bases.is_empty() {
+                metadata.insert("base_classes".to_string(), bases.join(","));
+            }
+        }
+        _ => {}
+    }
+}
+
+/// Record Java package and enclosing type for package-qualified IDs (v1.7.3)
+///
+/// - Every entity: `package_name` from the file's `package` declaration
+/// - Methods/constructors: `receiver_type` = innermost enclosing class,
+///   interface, enum or record
+fn enrich_java_structural_metadata(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    entity_type: &EntityType,
+    metadata: &mut HashMap<String, String>,
+) {
+    let mut root = node;
+    while let Some(parent) = root.parent() {
+        root = parent;
+    }
+    if let Some(package) = find_named_child_of_kind(root, "package_declaration") {
+        let mut cursor = package.walk();
+        let name = package
+            .named_children(&mut cursor)
+            .find(|n| matches!(n.kind(), "scoped_identifier" | "identifier"));
+        if let Some(name) = name {
+            metadata.insert("package_name".to_string(), source[name.byte_range()].to_string());
+        }
+    }
+    if *entity_type != EntityType::Method {
+        return;
+    }
+    let mut ancestor = node.parent();
+    while let Some(current) = ancestor {
+        if matches!(
+            current.kind(),
+            "class_declaration" | "interface_declaration" | "enum_declaration" | "record_declaration"
+        ) {
+            if let Some(name) = current.child_by_field_name("name") {
+                metadata.insert("receiver_type".to_string(), source[name.byte_range()].to_string());
+            }
+            return;
+        }
+        ancestor = current.parent();
+    }
+}
+
+/// Record the enclosing class of a JavaScript/TypeScript method (v1.7.3)
+///
+/// `receiver_type` lets the module resolver point `this.save()` at the
+/// sibling method. Object-literal methods have no class and get nothing.
+fn enrich_javascript_structural_metadata(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    entity_type: &EntityType,
+    metadata: &mut HashMap<String, String>,
+) {
+    if *entity_type != EntityType::Method {
+        return;
+    }
+    let mut ancestor = node.parent();
+    while let Some(current) = ancestor {
+        match current.kind() {
+            "class_declaration" | "abstract_class_declaration" | "class" => {
+                if let Some(name) = current.child_by_field_name("name") {
+                    metadata.insert("receiver_type".to_string(), source[name.byte_range()].to_string());
+                }
+                return;
+            }
+            "object" | "function_declaration" | "function_expression" | "arrow_function" => return,
+            _ => ancestor = current.parent(),
+        }
+    }
+}
+
+/// One name bound or exported by a JavaScript/TypeScript module statement
+struct JavascriptModuleBinding {
+    local_name: String,
+    binding_kind: &'static str,
+    imported_name: String,
+    module_source: String,
+    line: usize,
+}
+
+/// Module bindings of a JavaScript/TypeScript file as file-level `Uses` edges (v1.7.3)
+///
+/// The cross-file resolver follows call sites through these edges:
+///
+/// - `import { a as b } from './x'` → `b` (named), `import c from './x'` →
+///   `c` (default), `import * as ns` / `const ns = require('./x')` → `ns` (namespace)
+/// - `const { a, b: c } = require('./x')` → `a`, `c` (named)
+/// - `export { a as b } from './x'` → `b` (reexport), `export * from './x'` → `*` (reexport_all)
+/// - `export { a as b }` / `export default a` → `b` / `default` (local_export)
+///
+/// Edge metadata: `binding_kind`, `imported_name`, `import_source` (as written).
+fn extract_javascript_module_bindings(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    language: Language,
+) -> Vec<DependencyEdge> {
+    let text = |node: tree_sitter::Node<'_>| source[node.byte_range()].to_string();
+    let mut bindings = Vec::new();
+    let mut push = |local_name: String, binding_kind: &'static str, imported_name: String, module_source: &str, line: usize| {
+        bindings.push(JavascriptModuleBinding {
+            local_name,
+            binding_kind,
+            imported_name,
+            module_source: module_source.to_string(),
+            line,
+        });
+    };
+
+    let mut cursor = root.walk();
+    for statement in root.named_children(&mut cursor) {
+        let line = statement.start_position().row + 1;
+        let module_source = statement
+            .child_by_field_name("source")
+            .map(|s| unquote_javascript_string_literal(&source[s.byte_range()]));
+        match statement.kind() {
+            "import_statement" => {
+                let (Some(module_source), Some(clause)) = (module_source, find_named_child_of_kind(statement, "import_clause")) else {
+                    continue; // side-effect import
+                };
+                let mut clause_cursor = clause.walk();
+                for part in clause.named_children(&mut clause_cursor) {
+                    match part.kind() {
+                        "identifier" => push(text(part), "default", "default".to_string(), &module_source, line),
+                        "namespace_import" => {
+                            if let Some(alias) = find_named_child_of_kind(part, "identifier") {
+                                push(text(alias), "namespace", "*".to_string(), &module_source, line);
+                            }
+                        }
+                        "named_imports" => {
+                            let mut spec_cursor = part.walk();
+                            for spec in part.named_children(&mut spec_cursor).filter(|n| n.kind() == "import_specifier") {
+                                let Some(name) = spec.child_by_field_name("name") else { continue };
+                                let local = spec.child_by_field_name("alias").unwrap_or(name);
+                                push(text(local), "named", unquote_javascript_string_literal(&text(name)), &module_source, line);
+                            }
+                        }
+                        _ => {}
+                    }
+                }
+            }
+            "export_statement" => {
+                if let Some(clause) = find_named_child_of_kind(statement, "export_clause") {
+                    let kind = if module_source.is_some() { "reexport" } else { "local_export" };
+                    let module_source = module_source.unwrap_or_default();
+                    let mut spec_cursor = clause.walk();
+                    for spec in clause.named_children(&mut spec_cursor).filter(|n| n.kind() == "export_specifier") {
+                        let Some(name) = spec.child_by_field_name("name") else { continue };
+                        let exported = spec.child_by_field_name("alias").unwrap_or(name);
+                        push(text(exported), kind, text(name), &module_source, line);
+                    }
+                } else if let Some(module_source) = module_source {
+                    // `export * as ns from` binds a namespace object, not symbols
+                    if find_named_child_of_kind(statement, "namespace_export").is_none() {
+                        push("*".to_string(), "reexport_all", "*".to_string(), &module_source, line);
+                    }
+                } else if has_anonymous_child_of_kind(statement, "default") {
+                    let target = statement
+                        .child_by_field_name("declaration")
+                        .or_else(|| statement.child_by_field_name("value"));
+                    let exported = target.and_then(|t| {
+                        if t.kind() == "identifier" { Some(t) } else { t.child_by_field_name("name") }
+                    });
+                    if let Some(exported) = exported {
+                        push("default".to_string(), "local_export", text(exported), "", line);
+                    }
+                }
+            }
+            "lexical_declaration" | "variable_declaration" => {
+                let mut decl_cursor = statement.walk();
+                for declarator in statement.named_children(&mut decl_cursor).filter(|n| n.kind() == "variable_declarator") {
+                    let Some(module_source) = declarator
+                        .child_by_field_name("value")
+                        .and_then(|v| require_call_module_source(v, source))
+                    else {
+                        continue;
+                    };
+                    let Some(pattern) = declarator.child_by_field_name("name") else { continue };
+                    match pattern.kind() {
+                        "identifier" => push(text(pattern), "namespace", "*".to_string(), &module_source, line),
+                        "object_pattern" => {
+                            let mut prop_cursor = pattern.walk();
+                            for prop in pattern.named_children(&mut prop_cursor) {
+                                match prop.kind() {
+                                    "shorthand_property_identifier_pattern" => {
+                                        push(text(prop), "named", text(prop), &module_source, line)
+                                    }
+                                    "pair_pattern" => {
+                                        let (Some(key), Some(value)) = (prop.child_by_field_name("key"), prop.child_by_field_name("value")) else {
+                                            continue;
+                                        };
+                                        if value.kind() == "identifier" {
+                                            push(text(value), "named", text(key), &module_source, line);
+                                        }
+                                    }
+                                    _ => {}
+                                }
+                            }
+                        }
+                        _ => {}
+                    }
+                }
+            }
+            _ => {}
+        }
+    }
+
+    let from_key = format!("{}:file:{}:1-1", language, sanitize_path_for_key_format(&file_path.display().to_string()));
+    bindings
+        .into_iter()
+        .filter_map(|binding| {
+            DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(format!("{}:module:{}:0-0", language, sanitize_name_double_colon(&binding.local_name)))
+                .edge_type(EdgeType::Uses)
+                .source_location(format!("{}:{}", file_path.display(), binding.line))
+                .metadata_entry("binding_kind", binding.binding_kind)
+                .metadata_entry("imported_name", binding.imported_name)
+                .metadata_entry("import_source", binding.module_source)
+                .build()
+                .ok()
+        })
+        .collect()
+}
+
+/// `'./x'` / `"./x"` / `` `./x` `` → `./x`
+fn unquote_javascript_string_literal(text: &str) -> String {
+    text.trim_matches(|c| c == '\'' || c == '"' || c == '`').to_string()
+}
+
+/// Module specifier of a `require('./x')` call expression
+fn require_call_module_source(node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    if node.kind() != "call_expression" {
+        return None;
+    }
+    let function = node.child_by_field_name("function")?;
+    if &source[function.byte_range()] != "require" {
+        return None;
+    }
+    let arguments = node.child_by_field_name("arguments")?;
+    let first = arguments.named_child(0).filter(|a| a.kind() == "string")?;
+    Some(unquote_javascript_string_literal(&source[first.byte_range()]))
+}
+
+/// First named direct child of the given kind
+fn find_named_child_of_kind<'t>(node: tree_sitter::Node<'t>, kind: &str) -> Option<tree_sitter::Node<'t>> {
+    let mut cursor = node.walk();
+    let found = node.named_children(&mut cursor).find(|child| child.kind() == kind);
+    found
+}
+
+/// True if a direct (keyword) child has the given kind, e.g. `default`
+fn has_anonymous_child_of_kind(node: tree_sitter::Node<'_>, kind: &str) -> bool {
+    let mut cursor = node.walk();
+    let found = node.children(&mut cursor).any(|child| child.kind() == kind);
+    found
+}
+
+/// Record `embedded_types` for a Go struct or interface declaration
+///
+/// Embedded struct fields are `field_declaration`s without a name; embedded
+/// interfaces are single-type `type_elem`s. Qualified names keep only the
+/// type (`io.Reader` → `Reader`), matching how method receivers are recorded.
+fn insert_go_embedded_types(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    metadata: &mut HashMap<String, String>,
+) {
+    let mut embedded = Vec::new();
+    if let Some(body) = find_first_descendant_of_kind(node, "field_declaration_list") {
+        let mut cursor = body.walk();
+        for field in body.children(&mut cursor) {
+            if field.kind() == "field_declaration" && field.child_by_field_name("name").is_none() {
+                if let Some(type_name) = field
+                    .child_by_field_name("type")
+                    .and_then(|t| find_first_descendant_of_kind(t, "type_identifier"))
+                {
+                    embedded.push(source[type_name.byte_range()].to_string());
+                }
+            }
+        }
+    } else if let Some(body) = find_first_descendant_of_kind(node, "interface_type") {
+        let mut cursor = body.walk();
+        for elem in body.children(&mut cursor) {
+            if elem.kind() == "type_elem" && elem.named_child_count() == 1 {
+                if let Some(type_name) = find_first_descendant_of_kind(elem, "type_identifier") {
+                    let name = source[type_name.byte_range()].to_string();
+                    if !is_go_predeclared_type_name(&name) {
+                        embedded.push(name);
+                    }
+                }
+            }
+        }
+    }
+    if !embedded.is_empty() {
+        metadata.insert("embedded_types".to_string(), embedded.join(","));
+    }
+}
+
+/// Record the named type of each parameter and local of a Go function
+///
+/// Parameters, `var x T` and `x := T{...}` / `x := &T{...}` count; pointers
+/// are dropped (`*Server` → `Server`) and qualified names kept
+/// (`models.User`). A name declared with two different types (shadowing)
+/// is left out, as are predeclared, slice, map and function types.
+fn insert_go_local_variable_types(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    metadata: &mut HashMap<String, String>,
+) {
+    let mut types: BTreeMap<String, Option<String>> = BTreeMap::new();
+    let mut record = |name: tree_sitter::Node<'_>, type_node: tree_sitter::Node<'_>| {
+        if let Some(type_name) = go_named_type_text(type_node, source) {
+            let slot = types.entry(source[name.byte_range()].to_string()).or_insert_with(|| Some(type_name.clone()));
+            if slot.as_deref() != Some(type_name.as_str()) {
+                *slot = None;
+            }
+        }
+    };
+    if let Some(list) = node.child_by_field_name("parameters") {
+        let mut cursor = list.walk();
+        for param in list.named_children(&mut cursor) {
+            let Some(type_node) = param.child_by_field_name("type") else { continue };
+            let mut name_cursor = param.walk();
+            for name in param.children_by_field_name("name", &mut name_cursor) {
+                record(name, type_node);
+            }
+        }
+    }
+    let mut stack: Vec<tree_sitter::Node<'_>> = node.child_by_field_name("body").into_iter().collect();
+    while let Some(current) = stack.pop() {
+        match current.kind() {
+            "var_spec" => {
+                if let Some(type_node) = current.child_by_field_name("type") {
+                    let mut name_cursor = current.walk();
+                    for name in current.children_by_field_name("name", &mut name_cursor) {
+                        record(name, type_node);
+                    }
+                }
+            }
+            "short_var_declaration" => {
+                let sides = current.child_by_field_name("left").zip(current.child_by_field_name("right"));
+                if let Some((left, right)) = sides {
+                    let mut left_cursor = left.walk();
+                    let names: Vec<_> = left.named_children(&mut left_cursor).collect();
+                    let mut right_cursor = right.walk();
+                    let values: Vec<_> = right.named_children(&mut right_cursor).collect();
+                    if names.len() == values.len() {
+                        for (name, value) in names.into_iter().zip(values) {
+                            let literal = match value.kind() {
+                                "unary_expression" => value.child_by_field_name("operand"),
+                                _ => Some(value),
+                            };
+                            let literal_type = literal
+                                .filter(|literal| literal.kind() == "composite_literal")
+                                .and_then(|literal| literal.child_by_field_name("type"));
+                            if let Some(type_node) = literal_type.filter(|_| name.kind() == "identifier") {
+                                record(name, type_node);
+                            }
+                        }
+                    }
+                }
+            }
+            _ => {}
+        }
+        let mut cursor = current.walk();
+        stack.extend(current.named_children(&mut cursor));
+    }
+    let local_types: Vec<String> = types
+        .into_iter()
+        .filter_map(|(name, type_name)| type_name.map(|type_name| format!("{}={}", name, type_name)))
+        .collect();
+    if !local_types.is_empty() {
+        metadata.insert("local_types".to_string(), local_types.join(","));
+    }
+}
+
+/// `Server`, `*Server`, `models.User`, `Box[T]` → the named type; else None
+fn go_named_type_text(type_node: tree_sitter::Node<'_>, source: &str) -> Option<String> {
+    match type_node.kind() {
+        "type_identifier" => {
+            let name = &source[type_node.byte_range()];
+            (!is_go_predeclared_type_name(name) && name != "error").then(|| name.to_string())
+        }
+        "qualified_type" => Some(source[type_node.byte_range()].split_whitespace().collect()),
+        "pointer_type" => go_named_type_text(type_node.named_child(0)?, source),
+        "generic_type" => go_named_type_text(type_node.child_by_field_name("type")?, source),
+        _ => None,
+    }
+}
+
+/// Predeclared non-interface Go types (constraint elements, not embeddings)
+fn is_go_predeclared_type_name(name: &str) -> bool {
+    matches!(
+        name,
+        "any" | "bool" | "byte" | "comparable" | "complex64" | "complex128" | "float32" | "float64" | "int" | "int8" | "int16" | "int32" | "int64" | "rune"
+            | "string" | "uint" | "uint8" | "uint16" | "uint32" | "uint64" | "uintptr"
+    )
+}
+
+/// Depth-first search for the first node of the given kind (preorder)
+fn find_first_descendant_of_kind<'t>(
+    node: tree_sitter::Node<'t>,
+    kind: &str,
+) -> Option<tree_sitter::Node<'t>> {
+    if node.kind() == kind {
+        return Some(node);
+    }
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        if let Some(found) = find_first_descendant_of_kind(child, kind) {
+            return Some(found);
+        }
+    }
+    None
+}
+
+/// Collect method names declared directly in a Go interface body
+///
+/// Handles both grammar spellings (`method_elem` in tree-sitter-go 0.20+,
+/// `method_spec` in older releases).
+fn collect_go_interface_method_names(
+    node: tree_sitter::Node<'_>,
+    source: &str,
+    methods: &mut Vec<String>,
+) {
+    if matches!(node.kind(), "method_elem" | "method_spec") {
+        if let Some(name) = node.child_by_field_name("name") {
+            methods.push(source[name.byte_range()].to_string());
+        }
+        return;
+    }
+    let mut cursor = node.walk();
+    for child in node.children(&mut cursor) {
+        collect_go_interface_method_names(child, source, methods);
+    }
+}
+
 /// Sanitize file path for key format
 ///
 /// Ensures edge keys match entity keys by normalizing path separators.
 /// Entity keys use underscores, so edges must too.
-fn sanitize_path_for_key_format(path: &str) -> String {
+pub(crate) fn sanitize_path_for_key_format(path: &str) -> String {
     path.replace(['/', '\\', '.'], "_")
 }
 
@@ -92,7 +855,7 @@ fn sanitize_path_for_key_format(path: &str) -> String {
 /// assert_eq!(sanitize_name_double_colon("std::vector"), "std__vector");
 /// assert_eq!(sanitize_name_double_colon("ActiveRecord::Base"), "ActiveRecord__Base");
 /// ```
-fn sanitize_name_double_colon(name: &str) -> String {
+pub(crate) fn sanitize_name_double_colon(name: &str) -> String {
     name.replace("::", "__")
 }
 
@@ -231,11 +994,7 @@ impl QueryBasedExtractor {
             Language::Swift,
             include_str!("../../../entity_queries/swift.scm").to_string()
         );
-        // NOTE: Kotlin temporarily disabled due to tree-sitter version incompatibility (0.20 vs 0.25)
-        // queries.insert(
-        //     Language::Kotlin,
-        //     include_str!("../../../entity_queries/kotlin.scm").to_string()
-        // );
+        // NOTE: Kotlin has no query entry; parse_source hands it to kotlin_syntax_tree_walker
 
         // Initialize parsers
         let mut parsers = HashMap::new();
@@ -251,8 +1010,8 @@ impl QueryBasedExtractor {
         Self::init_parser(&mut parsers, Language::Php, &tree_sitter_php::LANGUAGE_PHP.into())?;
         Self::init_parser(&mut parsers, Language::CSharp, &tree_sitter_c_sharp::LANGUAGE.into())?;
         Self::init_parser(&mut parsers, Language::Swift, &tree_sitter_swift::LANGUAGE.into())?;
-        // NOTE: Kotlin temporarily disabled due to tree-sitter version incompatibility
-        // Self::init_parser(&mut parsers, Language::Kotlin, &tree_sitter_kotlin::language())?;
+        // v1.7.3: Kotlin via tree-sitter-kotlin-ng (0.25 ABI); extracted by kotlin_syntax_tree_walker
+        Self::init_parser(&mut parsers, Language::Kotlin, &tree_sitter_kotlin_ng::LANGUAGE.into())?;
 
         // v0.9.0: Load dependency query files for ALL languages
         let mut dependency_queries = HashMap::new();
@@ -369,21 +1128,194 @@ impl QueryBasedExtractor {
         let tree = parser.parse(source, None)
             .context("Failed to parse source")?;
 
+        // v1.7.3: Kotlin grammars disagree on node names; walk instead of querying
+        if language == Language::Kotlin {
+            return Ok(crate::kotlin_syntax_tree_walker::extract_kotlin_entities_and_edges(&tree, source, file_path));
+        }
+
         // Get entity query
         let query_source = self.queries.get(&language)
             .context(format!("No query for language {:?}", language))?;
 
         // Execute entity query
-        let entities = self.execute_query(&tree, source, file_path, language, query_source)?;
+        let mut entities = self.execute_query(&tree, source, file_path, language, query_source)?;
+
+        // v1.7.3: Go package-level const/var, before edges so initializers get an owner
+        if language == Language::Go {
+            entities.extend(crate::go_package_value_extractor::collect_go_package_value_entities(
+                tree.root_node(),
+                source,
+                file_path,
+            ));
+            // v1.7.3: several `func init()` in one file would share a key
+            crate::go_package_init_extractor::number_go_init_function_entities(&mut entities);
+        }
+
+        // v1.7.3: Go closures, before edges so calls in their bodies get them as owner
+        let mut closure_edges = Vec::new();
+        if language == Language::Go {
+            let (closures, edges) = crate::go_closure_literal_extractor::extract_go_closure_literal_entities(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            );
+            entities.extend(closures);
+            closure_edges = edges;
+            // v1.7.3: reflection, plugin and registry users get `dynamic_usage`
+            crate::go_dynamic_usage_detector::mark_go_dynamic_usage_entities(tree.root_node(), source, &mut entities);
+            // v1.7.3: `unsafe` and cgo users get a `boundary` facet
+            crate::safety_boundary_facet_tagger::tag_go_safety_boundary_entities(tree.root_node(), source, &mut entities);
+            // v1.7.3: direct panics and `recover()` callers
+            crate::go_panic_recover_extractor::mark_go_panic_recover_entities(tree.root_node(), source, &mut entities);
+        }
+
+        // v1.7.3: Items generated by same-file macro_rules! invocations
+        if language == Language::Rust {
+            entities.extend(crate::rust_macro_symbol_attributor::collect_rust_macro_generated_entities(
+                tree.root_node(),
+                source,
+                file_path,
+            ));
+            // v1.7.3: unsafe/extern items and `extern` block declarations get a `boundary` facet
+            crate::safety_boundary_facet_tagger::tag_rust_safety_boundary_entities(
+                tree.root_node(),
+                source,
+                file_path,
+                &mut entities,
+            );
+        }
 
         // v0.9.0: Execute dependency query if available
-        let dependencies = if let Some(dep_query_source) = self.dependency_queries.get(&language) {
+        let mut dependencies = if let Some(dep_query_source) = self.dependency_queries.get(&language) {
             self.execute_dependency_query(&tree, source, file_path, language, dep_query_source, &entities)?
         } else {
             // Graceful degradation: if no dependency query, return empty vec
             vec![]
         };
 
+        // v1.7.3: Rust impl/derive Implements, trait bound edges, dyn Trait call receivers
+        if language == Language::Rust {
+            dependencies.extend(crate::rust_trait_impl_bound_extractor::extract_rust_trait_impl_bound_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            dependencies.extend(crate::rust_macro_symbol_attributor::extract_rust_derive_impl_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            // v1.7.3: serde/sqlx field names of derived structs
+            dependencies.extend(crate::struct_tag_serialization_mapper::extract_rust_serde_field_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            crate::dynamic_dispatch_call_expander::annotate_rust_dyn_receiver_types(
+                tree.root_node(),
+                source,
+                &entities,
+                &mut dependencies,
+            );
+            // v1.7.3: axum/actix/rocket routes, after edges so no call is credited to them
+            let (routes, route_edges) = crate::http_route_handler_mapper::extract_rust_http_route_entities(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            );
+            entities.extend(routes);
+            dependencies.extend(route_edges);
+        }
+
+        // v1.7.3: Go calls through locally-bound function/method values
+        if language == Language::Go {
+            dependencies.extend(closure_edges);
+            dependencies.extend(crate::go_package_init_extractor::extract_go_package_init_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            retarget_go_function_value_calls(tree.root_node(), source, file_path, &mut dependencies);
+            crate::go_import_alias_resolver::qualify_go_import_call_targets(tree.root_node(), source, &mut dependencies);
+            dependencies.extend(crate::go_channel_operation_extractor::extract_go_channel_operation_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            dependencies.extend(crate::go_field_access_extractor::extract_go_field_access_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            // v1.7.3: json/yaml/db/... struct tag names
+            dependencies.extend(crate::struct_tag_serialization_mapper::extract_go_struct_tag_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            // v1.7.3: SQL strings passed to database/sql, sqlx and gorm
+            dependencies.extend(crate::embedded_sql_table_linker::extract_go_embedded_sql_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            dependencies.extend(crate::go_package_value_extractor::extract_go_package_value_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            dependencies.extend(crate::go_type_reference_extractor::extract_go_type_reference_edges(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            ));
+            crate::dynamic_dispatch_call_expander::annotate_go_call_receiver_types(
+                tree.root_node(),
+                source,
+                &entities,
+                &mut dependencies,
+            );
+            crate::go_error_propagation_extractor::annotate_go_error_propagation_edges(
+                tree.root_node(),
+                source,
+                &mut dependencies,
+            );
+            crate::go_panic_recover_extractor::annotate_go_deferred_call_edges(tree.root_node(), source, &mut dependencies);
+            // v1.7.3: context_param on functions, context_arg on the calls they make
+            crate::go_context_propagation_auditor::annotate_go_context_propagation(
+                tree.root_node(),
+                source,
+                &mut entities,
+                &mut dependencies,
+            );
+            // v1.7.3: router registrations, after edges so no call is credited to them
+            let (routes, route_edges) = crate::http_route_handler_mapper::extract_go_http_route_entities(
+                tree.root_node(),
+                source,
+                file_path,
+                &entities,
+            );
+            entities.extend(routes);
+            dependencies.extend(route_edges);
+        }
+
+        // v1.7.3: JS/TS import/export bindings for cross-file module resolution
+        if matches!(language, Language::JavaScript | Language::TypeScript) {
+            dependencies.extend(extract_javascript_module_bindings(tree.root_node(), source, file_path, language));
+        }
+
         Ok((entities, dependencies))
     }
 
@@ -403,14 +1335,18 @@ impl QueryBasedExtractor {
         // Execute query using streaming iterator
         let mut cursor = QueryCursor::new();
         let mut matches = cursor.matches(&query, tree.root_node(), source.as_bytes());
-        let mut entities = Vec::new();
-        let mut seen = std::collections::HashSet::new();
+        let mut entities: Vec<ParsedEntity> = Vec::new();
+        let mut seen: HashMap<(String, (usize, usize)), usize> = HashMap::new();
 
         while let Some(m) = matches.next() {
             if let Some(entity) = self.process_match(m, &query, source, file_path, language) {
-                // Deduplicate based on (name, line_range) - prevents duplicate extraction
+                // Deduplicate based on (name, line_range) - prevents duplicate extraction.
+                // Overlapping patterns (e.g. generic + plain function) merge their metadata.
                 let key = (entity.name.clone(), entity.line_range);
-                if seen.insert(key) {
+                if let Some(&index) = seen.get(&key) {
+                    entities[index].metadata.extend(entity.metadata);
+                } else {
+                    seen.insert(key, entities.len());
                     entities.push(entity);
                 }
             }
@@ -430,12 +1366,19 @@ impl QueryBasedExtractor {
         let mut entity_name = None;
         let mut entity_type = None;
         let mut node = None;
+        let mut metadata = HashMap::new();
 
         for capture in m.captures {
             let capture_name = &query.capture_names()[capture.index as usize];
 
             if *capture_name == "name" {
                 entity_name = Some(source[capture.node.byte_range()].to_string());
+            } else if *capture_name == "type_parameters" {
+                // Generic definitions (Go 1.18+): record type params, e.g. "T any"
+                metadata.insert(
+                    "type_parameters".to_string(),
+                    normalize_generic_bracket_text(&source[capture.node.byte_range()]),
+                );
             } else if capture_name.starts_with("definition.") {
                 entity_type = self.parse_entity_type(capture_name);
                 node = Some(capture.node);
@@ -443,6 +1386,18 @@ impl QueryBasedExtractor {
         }
 
         if let (Some(name), Some(entity_type), Some(node)) = (entity_name, entity_type, node) {
+            if language == Language::Go {
+                enrich_go_structural_metadata(node, source, &entity_type, &mut metadata);
+            }
+            if language == Language::Python {
+                enrich_python_structural_metadata(node, source, &entity_type, &mut metadata);
+            }
+            if matches!(language, Language::JavaScript | Language::TypeScript) {
+                enrich_javascript_structural_metadata(node, source, &entity_type, &mut metadata);
+            }
+            if language == Language::Java {
+                enrich_java_structural_metadata(node, source, &entity_type, &mut metadata);
+            }
             Some(ParsedEntity {
                 entity_type,
                 name,
@@ -452,7 +1407,7 @@ impl QueryBasedExtractor {
                     node.end_position().row + 1,
                 ),
                 file_path: file_path.to_string_lossy().to_string(),
-                metadata: HashMap::new(),
+                metadata,
             })
         } else {
             None
@@ -460,20 +1415,26 @@ impl QueryBasedExtractor {
     }
 
     fn parse_entity_type(&self, capture_name: &str) -> Option<EntityType> {
-        match capture_name {
-            "definition.function" => Some(EntityType::Function),
-            "definition.struct" => Some(EntityType::Struct),
-            "definition.class" => Some(EntityType::Class),
-            "definition.enum" => Some(EntityType::Enum),
-            "definition.trait" => Some(EntityType::Trait),
-            "definition.interface" => Some(EntityType::Interface),
-            "definition.impl" => Some(EntityType::Impl),
-            "definition.module" => Some(EntityType::Module),
-            "definition.method" => Some(EntityType::Method),
-            "definition.typedef" => Some(EntityType::Typedef),
-            "definition.namespace" => Some(EntityType::Namespace),
-            _ => None,
-        }
+        entity_type_of_definition_capture(capture_name)
+    }
+
+    /// Entity and dependency query texts compiled into this build (v1.7.3)
+    ///
+    /// # 4-Word Name: embedded_query_texts_for
+    ///
+    /// None for a side without a query (Kotlin entities, SQL).
+    pub fn embedded_query_texts_for(&self, language: Language) -> (Option<&str>, Option<&str>) {
+        (
+            self.queries.get(&language).map(String::as_str),
+            self.dependency_queries.get(&language).map(String::as_str),
+        )
+    }
+
+    /// ABI version of the tree-sitter grammar parsing `language` (v1.7.3)
+    ///
+    /// # 4-Word Name: grammar_abi_version_for
+    pub fn grammar_abi_version_for(&self, language: Language) -> Option<usize> {
+        self.get_ts_language(language).ok().map(|grammar| grammar.abi_version())
     }
 
     fn get_ts_language(&self, language: Language) -> Result<tree_sitter::Language> {
@@ -490,8 +1451,7 @@ impl QueryBasedExtractor {
             Language::Php => tree_sitter_php::LANGUAGE_PHP.into(),
             Language::CSharp => tree_sitter_c_sharp::LANGUAGE.into(),
             Language::Swift => tree_sitter_swift::LANGUAGE.into(),
-            // NOTE: Kotlin temporarily disabled due to tree-sitter version incompatibility
-            // Language::Kotlin => tree_sitter_kotlin::language(),
+            Language::Kotlin => tree_sitter_kotlin_ng::LANGUAGE.into(),
             _ => anyhow::bail!("Unsupported language: {:?}", language),
         })
     }
@@ -499,7 +1459,7 @@ impl QueryBasedExtractor {
     /// Execute dependency query and extract relationships (v0.9.0)
     ///
     /// Processes tree-sitter query matches to build DependencyEdge objects.
-    /// Handles three edge types: Calls, Uses, Implements.
+    /// Handles five edge types: Calls, Uses, Implements, Embeds, Spawns.
     fn execute_dependency_query(
         &self,
         tree: &Tree,
@@ -544,6 +1504,10 @@ impl QueryBasedExtractor {
         let mut to_name = None;
         let mut use_full_path = None; // Bug #4: Capture full use path for external detection
         let mut location = None;
+        let mut type_arguments = None; // Generic instantiation type args (edge metadata)
+        let mut receiver_expr = None; // Method call operand (edge metadata)
+        let mut hierarchy_kind = None; // extends vs implements (edge metadata)
+        let mut include_kind = None; // local vs system header (edge metadata)
 
         // Parse captures to identify relationship type and participants
         for capture in m.captures {
@@ -555,38 +1519,23 @@ impl QueryBasedExtractor {
             if capture_name.starts_with("dependency.") {
                 location = Some(format!("{}:{}", file_path.display(), node.start_position().row + 1));
 
-                if capture_name.contains("call")
-                    || capture_name.contains("method_call")
-                    || capture_name.contains("constructor")
-                    || capture_name.contains("collection_op")
-                    || capture_name.contains("collection_operation")
-                    || capture_name.contains("async_call")
-                    || capture_name.contains("async_method")
-                    || capture_name.contains("await_call")
-                    || capture_name.contains("await_method")
-                    || capture_name.contains("promise_op")
-                    || capture_name.contains("promise_operation")
-                {
-                    dependency_type = Some(EdgeType::Calls);
-                    // For calls, find containing function
-                    from_entity = self.find_containing_entity(node, entities);
-                } else if capture_name.contains("use")
-                    || capture_name.contains("import")
-                    || capture_name.contains("type_ref")
-                    || capture_name.contains("property_access")
-                    || capture_name.contains("attribute_access")
-                    || capture_name.contains("field_access")
-                    || capture_name.contains("decorator")
-                    || capture_name.contains("type_generic")
-                    || capture_name.contains("type_simple")
-                    || capture_name.contains("generic_type")
-                {
-                    dependency_type = Some(EdgeType::Uses);
-                } else if capture_name.contains("implement")
-                    || capture_name.contains("inherits")
-                    || capture_name.contains("extends")
-                {
-                    dependency_type = Some(EdgeType::Implements);
+                if let Some(edge_type) = classify_dependency_capture_edge_type(capture_name) {
+                    dependency_type = Some(edge_type);
+                    match edge_type {
+                        // Embeds attribute to the enclosing struct/interface, calls
+                        // and spawns to the containing function
+                        EdgeType::Embeds | EdgeType::Spawns | EdgeType::Calls => {
+                            from_entity = self.find_containing_entity(node, entities);
+                        }
+                        EdgeType::Implements => {
+                            hierarchy_kind =
+                                Some(if capture_name.contains("implement") { "implements" } else { "extends" });
+                        }
+                        EdgeType::Includes => {
+                            include_kind = Some(if capture_name.contains("system") { "system" } else { "local" });
+                        }
+                        _ => {}
+                    }
                 }
             }
 
@@ -595,13 +1544,24 @@ impl QueryBasedExtractor {
                 use_full_path = Some(node_text.to_string());
             }
 
+            // Generic instantiation: Map[int, string](...) records "int, string"
+            if capture_name.starts_with("type_arguments.") {
+                type_arguments = Some(normalize_generic_bracket_text(node_text));
+            }
+
+            // Method call operand: `s.Close()` records "s" (identifiers and JS/TS `this`)
+            if capture_name.starts_with("call_receiver.") && matches!(node.kind(), "identifier" | "this") {
+                receiver_expr = Some(node_text.to_string());
+            }
+
             // Extract reference name (what is being called/used/implemented)
             if capture_name.starts_with("reference.") {
                 to_name = Some(node_text.to_string());
             }
 
-            // Extract definition name (for impl blocks)
-            if capture_name.starts_with("definition.impl") {
+            // Extract definition name (impl blocks; v1.7.3: also the class/interface
+            // of extends/implements patterns, which previously produced no edge)
+            if capture_name.starts_with("definition.") {
                 from_entity = entities.iter().find(|e| {
                     e.name == node_text && e.line_range.0 <= node.start_position().row + 1
                         && e.line_range.1 > node.end_position().row
@@ -637,6 +1597,24 @@ impl QueryBasedExtractor {
 
         // Build DependencyEdge if we have enough information
         if let (Some(edge_type), Some(to)) = (dependency_type, to_name) {
+            // v1.7.3: Includes join file nodes; c_include_header_resolver binds repo headers
+            if edge_type == EdgeType::Includes {
+                let include_path = unquote_include_path_text(&to);
+                return DependencyEdge::builder()
+                    .from_key(format!(
+                        "{}:file:{}:1-1",
+                        language,
+                        sanitize_path_for_key_format(&file_path.display().to_string())
+                    ))
+                    .to_key(format_unresolved_include_target_key(&language.to_string(), &include_path))
+                    .edge_type(edge_type)
+                    .source_location(location.unwrap_or_default())
+                    .metadata_entry(INCLUDE_KIND_METADATA_KEY, include_kind.unwrap_or("local"))
+                    .metadata_entry(INCLUDE_PATH_METADATA_KEY, include_path)
+                    .build()
+                    .ok();
+            }
+
             // For Uses edges (imports, use declarations), create simplified keys
             if edge_type == EdgeType::Uses {
                 let from_key = format!("{}:file:{}:1-1", language, sanitize_path_for_key_format(&file_path.display().to_string()));
@@ -656,16 +1634,18 @@ impl QueryBasedExtractor {
                     format!("{}:module:{}:0-0", language, sanitize_name_double_colon(&to))
                 };
 
-                return DependencyEdge::builder()
+                let mut builder = DependencyEdge::builder()
                     .from_key(from_key)
                     .to_key(to_key)
                     .edge_type(edge_type)
-                    .source_location(location.unwrap_or_default())
-                    .build()
-                    .ok();
+                    .source_location(location.unwrap_or_default());
+                if let Some(args) = type_arguments {
+                    builder = builder.metadata_entry("type_arguments", args);
+                }
+                return builder.build().ok();
             }
 
-            // For Calls and Implements, we need a from_entity
+            // For Calls, Implements and Embeds, we need a from_entity
             if let Some(from) = from_entity {
                 // Bug Fix: Use ISGL1 v2 format with semantic path and birth timestamp
                 // Old format: rust:fn:name:path:10-20 (line-range based)
@@ -688,13 +1668,21 @@ impl QueryBasedExtractor {
                     sanitize_name_double_colon(&to)
                 );
 
-                return DependencyEdge::builder()
+                let mut builder = DependencyEdge::builder()
                     .from_key(from_key)
                     .to_key(to_key)
                     .edge_type(edge_type)
-                    .source_location(location.unwrap_or_default())
-                    .build()
-                    .ok();
+                    .source_location(location.unwrap_or_default());
+                if let Some(args) = type_arguments {
+                    builder = builder.metadata_entry("type_arguments", args);
+                }
+                if let Some(receiver) = receiver_expr {
+                    builder = builder.metadata_entry("receiver_expr", receiver);
+                }
+                if let (EdgeType::Implements, Some(kind)) = (edge_type, hierarchy_kind) {
+                    builder = builder.metadata_entry("hierarchy_kind", kind);
+                }
+                return builder.build().ok();
             }
         }
 
@@ -717,6 +1705,8 @@ impl QueryBasedExtractor {
         let mut candidates: Vec<&ParsedEntity> = entities
             .iter()
             .filter(|e| e.line_range.0 <= node_line && node_line <= e.line_range.1)
+            // v1.7.3: closures on one line with their caller: match by bytes
+            .filter(|e| crate::go_closure_literal_extractor::entity_byte_span_contains_node(e, node))
             .collect();
 
         if candidates.is_empty() {
@@ -750,21 +1740,100 @@ impl QueryBasedExtractor {
 
     /// Convert EntityType to ISGL1 key component
     fn entity_type_to_key_component(&self, entity_type: &EntityType) -> &'static str {
-        match entity_type {
-            EntityType::Function => "fn",
-            EntityType::Method => "method",
-            EntityType::Struct => "struct",
-            EntityType::Enum => "enum",
-            EntityType::Trait => "trait",
-            EntityType::Interface => "interface",
-            EntityType::Class => "class",
-            EntityType::Module => "module",
-            EntityType::Impl => "impl",
-            EntityType::Typedef => "typedef",
-            EntityType::Namespace => "namespace",
-            EntityType::Table => "table",    // v1.5.6: SQL table
-            EntityType::View => "view",      // v1.5.6: SQL view
-        }
+        key_component_for_entity_type(entity_type)
+    }
+}
+
+/// Edge type of a `@dependency.*` capture name (v1.7.3)
+///
+/// # 4-Word Name: classify_dependency_capture_edge_type
+///
+/// Substring rules, first match wins; shared by extraction and the
+/// capability report so both agree on what a query can produce.
+pub(crate) fn classify_dependency_capture_edge_type(capture_name: &str) -> Option<EdgeType> {
+    if capture_name.contains("embeds") {
+        // Go type embedding
+        Some(EdgeType::Embeds)
+    } else if capture_name.contains("goroutine") {
+        // Go `go f()`: the call itself is also captured as Calls
+        Some(EdgeType::Spawns)
+    } else if capture_name.contains("include") {
+        // C/C++ `#include "x.h"` / `<x.h>`, PHP `include 'x.php'`
+        Some(EdgeType::Includes)
+    } else if capture_name.contains("call")
+        || capture_name.contains("method_call")
+        || capture_name.contains("constructor")
+        || capture_name.contains("collection_op")
+        || capture_name.contains("collection_operation")
+        || capture_name.contains("async_call")
+        || capture_name.contains("async_method")
+        || capture_name.contains("await_call")
+        || capture_name.contains("await_method")
+        || capture_name.contains("promise_op")
+        || capture_name.contains("promise_operation")
+    {
+        Some(EdgeType::Calls)
+    } else if capture_name.contains("use")
+        || capture_name.contains("import")
+        || capture_name.contains("type_ref")
+        || capture_name.contains("property_access")
+        || capture_name.contains("attribute_access")
+        || capture_name.contains("field_access")
+        || capture_name.contains("decorator")
+        || capture_name.contains("type_generic")
+        || capture_name.contains("type_simple")
+        || capture_name.contains("generic_type")
+    {
+        Some(EdgeType::Uses)
+    } else if capture_name.contains("implement")
+        || capture_name.contains("inherits")
+        || capture_name.contains("extends")
+    {
+        Some(EdgeType::Implements)
+    } else {
+        None
+    }
+}
+
+/// Entity type of a `@definition.*` capture name (v1.7.3)
+///
+/// # 4-Word Name: entity_type_of_definition_capture
+pub(crate) fn entity_type_of_definition_capture(capture_name: &str) -> Option<EntityType> {
+    match capture_name {
+        "definition.function" => Some(EntityType::Function),
+        "definition.struct" => Some(EntityType::Struct),
+        "definition.class" => Some(EntityType::Class),
+        "definition.enum" => Some(EntityType::Enum),
+        "definition.trait" => Some(EntityType::Trait),
+        "definition.interface" => Some(EntityType::Interface),
+        "definition.impl" => Some(EntityType::Impl),
+        "definition.module" => Some(EntityType::Module),
+        "definition.method" => Some(EntityType::Method),
+        "definition.typedef" => Some(EntityType::Typedef),
+        "definition.namespace" => Some(EntityType::Namespace),
+        _ => None,
+    }
+}
+
+/// ISGL1 key type component (`fn`, `method`, `class`, ...) for an entity type
+pub(crate) fn key_component_for_entity_type(entity_type: &EntityType) -> &'static str {
+    match entity_type {
+        EntityType::Function => "fn",
+        EntityType::Method => "method",
+        EntityType::Struct => "struct",
+        EntityType::Enum => "enum",
+        EntityType::Trait => "trait",
+        EntityType::Interface => "interface",
+        EntityType::Class => "class",
+        EntityType::Module => "module",
+        EntityType::Impl => "impl",
+        EntityType::Typedef => "typedef",
+        EntityType::Namespace => "namespace",
+        EntityType::Table => "table",    // v1.5.6: SQL table
+        EntityType::View => "view",      // v1.5.6: SQL view
+        EntityType::Constant => "const", // v1.7.3: Go package-level const
+        EntityType::Variable => "var",   // v1.7.3: Go package-level var
+        EntityType::Route => "route",    // v1.7.3: HTTP route
     }
 }
 
@@ -772,6 +1841,15 @@ impl QueryBasedExtractor {
 mod sanitization_tests {
     use super::sanitize_name_double_colon;
 
+    #[test]
+    fn test_normalize_generic_bracket_text() {
+        use super::normalize_generic_bracket_text;
+        assert_eq!(normalize_generic_bracket_text("[int, string]"), "int, string");
+        assert_eq!(normalize_generic_bracket_text("[K comparable,\n\tV any]"), "K comparable, V any");
+        assert_eq!(normalize_generic_bracket_text("<T>"), "T");
+        assert_eq!(normalize_generic_bracket_text("int"), "int");
+    }
+
     #[test]
     fn test_sanitize_empty_string() {
         assert_eq!(sanitize_name_double_colon(""), "");
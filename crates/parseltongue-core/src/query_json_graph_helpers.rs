@@ -55,7 +55,7 @@ pub fn build_call_chain_from_root(
     Ok(chain)
 }
 
-/// Filter edges by type only (Calls, Uses, Implements)
+/// Filter edges by type only (Calls, Uses, Implements, Embeds, Spawns, SendsTo, ReceivesFrom, Reads, Writes, Tests, Bound, PropagatesErrorTo, QueriesTable, Serializes, GeneratedFrom, Includes, ConfiguredBy)
 ///
 /// # 4-Word Name: filter + edges + by_type + only
 pub fn filter_edges_by_type_only(
@@ -63,7 +63,7 @@ pub fn filter_edges_by_type_only(
     edge_type: &str,
 ) -> Result<Vec<Value>, JsonGraphQueryError> {
     match edge_type {
-        "Calls" | "Uses" | "Implements" => {},
+        "Calls" | "Uses" | "Implements" | "Embeds" | "Spawns" | "SendsTo" | "ReceivesFrom" | "Reads" | "Writes" | "Tests" | "Bound" | "PropagatesErrorTo" | "QueriesTable" | "Serializes" | "GeneratedFrom" | "Includes" | "ConfiguredBy" => {},
         _ => return Err(JsonGraphQueryError::InvalidEdgeType(edge_type.into())),
     }
 
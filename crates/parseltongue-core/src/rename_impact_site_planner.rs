@@ -0,0 +1,408 @@
+//! Rename impact planning (v1.7.3)
+//!
+//! # 4-Word Naming: rename_impact_site_planner
+//!
+//! Lists every file/line a rename of one symbol has to touch, without
+//! editing anything, so an agent can plan the multi-file change up front:
+//!
+//! | Kind | Certain | Source |
+//! |------|---------|--------|
+//! | `definition` | yes | the symbol's own declaration line |
+//! | `reference` | yes | inbound edges (calls, uses, embeds, tests, ...); the line is found by scanning the referencing symbol's code for the name |
+//! | `interface_method` | yes | Go: the method spec of every interface the receiver implements through this method |
+//! | `implementer_method` | yes | Go: the same method on the other implementers of those interfaces (renaming one alone breaks satisfaction), plus their references |
+//! | `unresolved_reference` | no | edges to an unresolved placeholder with the same name |
+//! | `string_literal` | no | quoted `"Name"` / `"pkg.Name"` in code: registries, reflection, route tables |
+//!
+//! One site per (file, line), the most certain kind winning. `conflicts`
+//! lists symbols that already use the new name in the same package (and, for
+//! methods, on the same receiver).
+//!
+//! Syntactic only: Rust trait methods are not linked to their trait, and text
+//! outside indexed symbols (comments between items, docs) is not scanned.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_embedding_promotion_resolver::package_directory_of_entity;
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::structural_interface_matcher::{INTERFACE_METHODS_METADATA_KEY, RECEIVER_TYPE_METADATA_KEY};
+
+/// One line that must (or may) change
+///
+/// # 4-Word Name: RenameImpactSiteEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct RenameImpactSiteEntry {
+    pub file_path: String,
+    pub line: u32,
+    /// `definition`, `reference`, `interface_method`, `implementer_method`,
+    /// `unresolved_reference` or `string_literal`
+    pub kind: String,
+    /// Symbol containing the line
+    pub entity_key: String,
+    /// False for sites found by name only (review before editing)
+    pub certain: bool,
+}
+
+/// Every site a rename touches
+///
+/// # 4-Word Name: RenameImpactPlanResult
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct RenameImpactPlanResult {
+    pub symbol_key: String,
+    pub old_name: String,
+    pub new_name: String,
+    /// Sorted by (file_path, line)
+    pub sites: Vec<RenameImpactSiteEntry>,
+    pub files_affected: usize,
+    /// Keys of symbols already named `new_name` in the same scope
+    pub conflicts: Vec<String>,
+}
+
+/// Entities a rename argument may mean: an exact key, else every symbol with that name
+///
+/// # 4-Word Name: resolve_rename_target_entities
+///
+/// # Contract
+/// - Postcondition: placeholders, modules and impl blocks never match a name;
+///   sorted by key
+pub fn resolve_rename_target_entities<'a>(symbol: &str, entities: &'a [CodeEntity]) -> Vec<&'a CodeEntity> {
+    if let Some(entity) = entities.iter().find(|e| e.isgl1_key == symbol) {
+        return vec![entity];
+    }
+    let mut matches: Vec<&CodeEntity> = entities
+        .iter()
+        .filter(|e| e.interface_signature.name == symbol)
+        .filter(|e| !is_external_graph_node_key(&e.isgl1_key))
+        .filter(|e| !matches!(e.interface_signature.entity_type, EntityType::Module | EntityType::ImplBlock { .. }))
+        .collect();
+    matches.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    matches
+}
+
+/// Plan a rename of `target` to `new_name`
+///
+/// # 4-Word Name: plan_symbol_rename_impact_sites
+///
+/// # Contract
+/// - Precondition: `entities` carry extractor metadata (`receiver_type`,
+///   `interface_methods`) and `current_code` for line-accurate sites
+/// - Postcondition: exactly one `definition` site; see the module docs for
+///   the other kinds
+pub fn plan_symbol_rename_impact_sites(
+    target: &CodeEntity,
+    new_name: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> RenameImpactPlanResult {
+    let old_name = target.interface_signature.name.as_str();
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut sites: BTreeMap<(String, u32), RenameImpactSiteEntry> = BTreeMap::new();
+
+    let mut renamed: Vec<&CodeEntity> = vec![target];
+    for (interface, implementers) in collect_satisfied_interface_methods(target, entities, edges) {
+        for line in find_identifier_lines_in_entity(interface, old_name) {
+            record_rename_site(&mut sites, interface, line, "interface_method", true);
+        }
+        renamed.extend(implementers);
+    }
+
+    for entity in &renamed {
+        let kind = if entity.isgl1_key == target.isgl1_key { "definition" } else { "implementer_method" };
+        record_rename_site(&mut sites, entity, entity.interface_signature.line_range.start, kind, true);
+    }
+    let renamed_keys: BTreeSet<&str> = renamed.iter().map(|e| e.isgl1_key.as_str()).collect();
+    for edge in edges.iter().filter(|e| e.from_key.as_str() != e.to_key.as_str()) {
+        let to = edge.to_key.as_str();
+        let (kind, certain) = if renamed_keys.contains(to) {
+            ("reference", true)
+        } else if is_external_graph_node_key(to) && placeholder_symbol_name(to) == Some(old_name) {
+            ("unresolved_reference", false)
+        } else {
+            continue;
+        };
+        let Some(source) = by_key.get(edge.from_key.as_str()) else {
+            continue;
+        };
+        let mut lines = find_identifier_lines_in_entity(source, old_name);
+        if lines.is_empty() {
+            lines.push(source_location_line(edge).unwrap_or(source.interface_signature.line_range.start));
+        }
+        for line in lines {
+            record_rename_site(&mut sites, source, line, kind, certain);
+        }
+    }
+
+    for entity in entities.iter().filter(|e| !is_external_graph_node_key(&e.isgl1_key)) {
+        for line in find_quoted_name_lines_in_entity(entity, old_name) {
+            record_rename_site(&mut sites, entity, line, "string_literal", false);
+        }
+    }
+
+    let sites: Vec<RenameImpactSiteEntry> = sites.into_values().collect();
+    let files_affected = sites.iter().map(|s| s.file_path.as_str()).collect::<BTreeSet<_>>().len();
+    RenameImpactPlanResult {
+        symbol_key: target.isgl1_key.clone(),
+        old_name: old_name.to_string(),
+        new_name: new_name.to_string(),
+        sites,
+        files_affected,
+        conflicts: find_rename_name_conflicts(target, new_name, entities),
+    }
+}
+
+/// Go interfaces `target` helps satisfy, each with its other implementers' same-named methods
+fn collect_satisfied_interface_methods<'a>(
+    target: &CodeEntity,
+    entities: &'a [CodeEntity],
+    edges: &[DependencyEdge],
+) -> Vec<(&'a CodeEntity, Vec<&'a CodeEntity>)> {
+    let Some(receiver) = target.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) else {
+        return Vec::new();
+    };
+    let name = target.interface_signature.name.as_str();
+    let package = package_directory_of_entity(target);
+    let by_key: HashMap<&str, &CodeEntity> = entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let receiver_keys: BTreeSet<&str> = entities
+        .iter()
+        .filter(|e| e.interface_signature.name == *receiver && package_directory_of_entity(e) == package)
+        .filter(|e| !e.metadata.additional.contains_key(RECEIVER_TYPE_METADATA_KEY))
+        .map(|e| e.isgl1_key.as_str())
+        .collect();
+
+    let implements = || edges.iter().filter(|e| e.edge_type == EdgeType::Implements);
+    let mut interfaces: Vec<&CodeEntity> = implements()
+        .filter(|e| receiver_keys.contains(e.from_key.as_str()))
+        .filter_map(|e| by_key.get(e.to_key.as_str()).copied())
+        .filter(|interface| {
+            interface
+                .metadata
+                .additional
+                .get(INTERFACE_METHODS_METADATA_KEY)
+                .is_some_and(|methods| methods.split(',').any(|m| m.trim() == name))
+        })
+        .collect();
+    interfaces.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    interfaces.dedup_by(|a, b| a.isgl1_key == b.isgl1_key);
+
+    interfaces
+        .into_iter()
+        .map(|interface| {
+            let implementers: Vec<&CodeEntity> = implements()
+                .filter(|e| e.to_key.as_str() == interface.isgl1_key && !receiver_keys.contains(e.from_key.as_str()))
+                .filter_map(|e| by_key.get(e.from_key.as_str()).copied())
+                .flat_map(|implementer| {
+                    let implementer_package = package_directory_of_entity(implementer);
+                    entities.iter().filter(move |method| {
+                        method.interface_signature.name == name
+                            && method.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY)
+                                == Some(&implementer.interface_signature.name)
+                            && package_directory_of_entity(method) == implementer_package
+                    })
+                })
+                .collect();
+            (interface, implementers)
+        })
+        .collect()
+}
+
+/// Symbols already named `new_name` where the rename would collide
+fn find_rename_name_conflicts(target: &CodeEntity, new_name: &str, entities: &[CodeEntity]) -> Vec<String> {
+    let package = package_directory_of_entity(target);
+    let receiver = target.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY);
+    let mut conflicts: Vec<String> = entities
+        .iter()
+        .filter(|e| e.interface_signature.name == new_name && e.isgl1_key != target.isgl1_key)
+        .filter(|e| !is_external_graph_node_key(&e.isgl1_key))
+        .filter(|e| package_directory_of_entity(e) == package)
+        .filter(|e| e.metadata.additional.get(RECEIVER_TYPE_METADATA_KEY) == receiver)
+        .map(|e| e.isgl1_key.clone())
+        .collect();
+    conflicts.sort();
+    conflicts
+}
+
+/// Keep the most certain site per (file, line); ties keep the first recorded
+fn record_rename_site(
+    sites: &mut BTreeMap<(String, u32), RenameImpactSiteEntry>,
+    entity: &CodeEntity,
+    line: u32,
+    kind: &str,
+    certain: bool,
+) {
+    let file_path = entity.interface_signature.file_path.display().to_string();
+    let slot = (file_path.clone(), line);
+    if sites.get(&slot).is_some_and(|existing| existing.certain || !certain) {
+        return;
+    }
+    sites.insert(
+        slot,
+        RenameImpactSiteEntry {
+            file_path,
+            line,
+            kind: kind.to_string(),
+            entity_key: entity.isgl1_key.clone(),
+            certain,
+        },
+    );
+}
+
+/// Name part of a placeholder key (`go:fn:Save:unresolved-reference:0-0` → `Save`)
+fn placeholder_symbol_name(key: &str) -> Option<&str> {
+    key.split(':').nth(2)
+}
+
+/// Line of `path/file.go:42` edge locations
+fn source_location_line(edge: &DependencyEdge) -> Option<u32> {
+    let (_, line) = edge.source_location.as_deref()?.rsplit_once(':')?;
+    line.parse().ok().filter(|line| *line > 0)
+}
+
+/// File lines of `entity.current_code` containing `name` as a whole identifier
+fn find_identifier_lines_in_entity(entity: &CodeEntity, name: &str) -> Vec<u32> {
+    find_entity_code_lines(entity, |line| contains_whole_identifier(line, name))
+}
+
+/// File lines of `entity.current_code` with a quoted `name` or `qualifier.name`
+fn find_quoted_name_lines_in_entity(entity: &CodeEntity, name: &str) -> Vec<u32> {
+    let qualified = format!(".{}", name);
+    find_entity_code_lines(entity, |line| {
+        ['"', '\'', '`'].iter().any(|&quote| {
+            // Odd segments lie between a pair of quotes
+            line.split(quote)
+                .skip(1)
+                .step_by(2)
+                .any(|literal| literal == name || literal.ends_with(&qualified))
+        })
+    })
+}
+
+fn find_entity_code_lines(entity: &CodeEntity, matches: impl Fn(&str) -> bool) -> Vec<u32> {
+    let Some(code) = entity.current_code.as_deref() else {
+        return Vec::new();
+    };
+    let start = entity.interface_signature.line_range.start;
+    code.lines()
+        .enumerate()
+        .filter(|(_, line)| matches(*line))
+        .map(|(offset, _)| start + offset as u32)
+        .collect()
+}
+
+fn contains_whole_identifier(line: &str, name: &str) -> bool {
+    let is_identifier_byte = |b: u8| b.is_ascii_alphanumeric() || b == b'_';
+    let bytes = line.as_bytes();
+    line.match_indices(name).any(|(index, _)| {
+        let before = index.checked_sub(1).map(|i| bytes[i]);
+        let after = bytes.get(index + name.len()).copied();
+        !before.is_some_and(is_identifier_byte) && !after.is_some_and(is_identifier_byte)
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .build()
+            .unwrap()
+    }
+
+    fn with_code(mut entity: CodeEntity, start: u32, code: &str) -> CodeEntity {
+        entity.interface_signature.line_range.start = start;
+        entity.interface_signature.line_range.end = start + code.lines().count() as u32 - 1;
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    fn sample_store_entities() -> Vec<CodeEntity> {
+        let store = "store/store.go";
+        vec![
+            with_code(
+                create_go_test_entity("go:interface:Store:__store:T1", "Store", EntityType::Interface, store, &[("interface_methods", "Load,Save")]),
+                3,
+                "type Store interface {\n\tLoad(id int) Item\n\tSave(item Item) error\n}",
+            ),
+            create_go_test_entity("go:struct:Disk:__store:T2", "Disk", EntityType::Struct, "store/disk.go", &[]),
+            with_code(
+                create_go_test_entity("go:method:Save:__store_disk:T3", "Save", EntityType::Method, "store/disk.go", &[("receiver_type", "Disk")]),
+                10,
+                "func (d *Disk) Save(item Item) error {\n\treturn d.write(item)\n}",
+            ),
+            create_go_test_entity("go:struct:Mem:__store:T4", "Mem", EntityType::Struct, "store/mem.go", &[]),
+            with_code(
+                create_go_test_entity("go:method:Save:__store_mem:T5", "Save", EntityType::Method, "store/mem.go", &[("receiver_type", "Mem")]),
+                8,
+                "func (m *Mem) Save(item Item) error {\n\treturn nil\n}",
+            ),
+            create_go_test_entity("go:method:Persist:__store_disk:T6", "Persist", EntityType::Method, "store/disk.go", &[("receiver_type", "Disk")]),
+            with_code(
+                create_go_test_entity("go:fn:handle:__api:T7", "handle", EntityType::Function, "api/handler.go", &[]),
+                20,
+                "func handle(d *store.Disk) {\n\tlog(\"saving\")\n\td.Save(item)\n}",
+            ),
+            with_code(
+                create_go_test_entity("go:fn:init:__api:T8", "init", EntityType::Function, "api/routes.go", &[]),
+                5,
+                "func init() {\n\tregister(\"store.Save\", handle)\n}",
+            ),
+            with_code(
+                create_go_test_entity("go:fn:flush:__api:T9", "flush", EntityType::Function, "api/flush.go", &[]),
+                1,
+                "func flush(s saver) {\n\ts.Save(pending)\n}",
+            ),
+        ]
+    }
+
+    #[test]
+    fn test_rename_lists_references_and_interface_sites() {
+        let entities = sample_store_entities();
+        let edges = vec![
+            edge("go:struct:Disk:__store:T2", "go:interface:Store:__store:T1", EdgeType::Implements),
+            edge("go:struct:Mem:__store:T4", "go:interface:Store:__store:T1", EdgeType::Implements),
+            edge("go:fn:handle:__api:T7", "go:method:Save:__store_disk:T3", EdgeType::Calls),
+            edge("go:fn:flush:__api:T9", "go:fn:Save:unresolved-reference:0-0", EdgeType::Calls),
+        ];
+        let target = resolve_rename_target_entities("go:method:Save:__store_disk:T3", &entities)[0];
+
+        let plan = plan_symbol_rename_impact_sites(target, "Persist", &entities, &edges);
+        let sites: Vec<(&str, u32, &str, bool)> = plan
+            .sites
+            .iter()
+            .map(|s| (s.file_path.as_str(), s.line, s.kind.as_str(), s.certain))
+            .collect();
+        assert_eq!(
+            sites,
+            vec![
+                ("api/flush.go", 2, "unresolved_reference", false),
+                ("api/handler.go", 22, "reference", true),
+                ("api/routes.go", 6, "string_literal", false),
+                ("store/disk.go", 10, "definition", true),
+                ("store/mem.go", 8, "implementer_method", true),
+                ("store/store.go", 5, "interface_method", true),
+            ]
+        );
+        assert_eq!(plan.files_affected, 6);
+        assert_eq!(plan.conflicts, vec!["go:method:Persist:__store_disk:T6"]);
+    }
+
+    #[test]
+    fn test_name_resolves_to_every_candidate() {
+        let entities = sample_store_entities();
+        let keys: Vec<&str> = resolve_rename_target_entities("Save", &entities)
+            .iter()
+            .map(|e| e.isgl1_key.as_str())
+            .collect();
+        assert_eq!(keys, vec!["go:method:Save:__store_disk:T3", "go:method:Save:__store_mem:T5"]);
+        assert!(resolve_rename_target_entities("Missing", &entities).is_empty());
+        assert!(contains_whole_identifier("d.Save(x)", "Save"));
+        assert!(!contains_whole_identifier("d.SaveAll(x)", "Save"));
+    }
+}
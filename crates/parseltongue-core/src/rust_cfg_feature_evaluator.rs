@@ -0,0 +1,584 @@
+//! Rust Cargo Feature Evaluator (v1.7.3)
+//!
+//! # 4-Word Naming: rust_cfg_feature_evaluator
+//!
+//! Rust gates items with `#[cfg(...)]` rather than whole files, so indexing
+//! everything puts `#[cfg(feature = "tokio")] fn spawn` next to
+//! `#[cfg(not(feature = "tokio"))] fn spawn`. This module finds the cfg
+//! attributes of every item, keeps the combined condition as entity metadata,
+//! and evaluates it against one [`RustFeatureSelectionConfig`] the way
+//! `cargo build --features ... / --all-features / --no-default-features` would.
+//!
+//! ## Feature resolution (per crate, from its `Cargo.toml`)
+//!
+//! - `default` unless `no_default_features`; requested features, bare or as
+//!   `crate-name/feature`; every declared feature and optional dependency
+//!   with `all_features`
+//! - Enabling a feature enables the features it lists; `dep:x` enables only
+//!   the dependency, `x/feat` also enables the implicit feature `x`
+//!
+//! ## Evaluation
+//!
+//! `feature = "..."` predicates are decided, everything else (`unix`,
+//! `test`, `target_os = "..."`) is unknown. `all` / `any` / `not` use
+//! three-valued logic and only a definitely false condition drops an item,
+//! so code is never lost to a platform cfg or a malformed attribute.
+//! `#![cfg(...)]` at the top of a file gates every item in it; items of a
+//! file gated by `#[cfg(...)] mod name;` in its parent are not followed.
+
+use std::collections::{HashMap, HashSet};
+use std::path::{Path, PathBuf};
+use std::sync::{Arc, Mutex};
+
+/// Entity metadata key carrying the combined cfg condition of an item
+pub const CFG_CONDITION_METADATA_KEY: &str = "cfg_condition";
+
+/// Cargo feature flags for one ingest
+///
+/// # 4-Word Name: RustFeatureSelectionConfig
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct RustFeatureSelectionConfig {
+    /// `--features a,b` (bare or `crate-name/feature`)
+    pub features: Vec<String>,
+    /// `--all-features`
+    pub all_features: bool,
+    /// `--no-default-features`
+    pub no_default_features: bool,
+}
+
+impl RustFeatureSelectionConfig {
+    /// True when any flag was given; otherwise items are tagged, never dropped
+    pub fn is_selection_active(&self) -> bool {
+        !self.features.is_empty() || self.all_features || self.no_default_features
+    }
+}
+
+/// Features enabled for the crate whose manifest is `manifest_text`
+///
+/// # 4-Word Name: resolve_enabled_crate_features
+///
+/// # Contract
+/// - Postcondition: closed under the `[features]` table; an unreadable
+///   manifest yields just the requested bare features
+pub fn resolve_enabled_crate_features(manifest_text: &str, selection: &RustFeatureSelectionConfig) -> HashSet<String> {
+    let manifest: toml::Value = manifest_text.parse().unwrap_or_else(|_| toml::Value::Table(Default::default()));
+    let package_name = manifest.get("package").and_then(|p| p.get("name")).and_then(|n| n.as_str());
+    let declared: HashMap<String, Vec<String>> = manifest
+        .get("features")
+        .and_then(|f| f.as_table())
+        .map(|table| {
+            table
+                .iter()
+                .map(|(name, value)| {
+                    let entries = value
+                        .as_array()
+                        .map(|list| list.iter().filter_map(|e| e.as_str().map(str::to_string)).collect())
+                        .unwrap_or_default();
+                    (name.clone(), entries)
+                })
+                .collect()
+        })
+        .unwrap_or_default();
+
+    let mut pending: Vec<String> = Vec::new();
+    if !selection.no_default_features && declared.contains_key("default") {
+        pending.push("default".to_string());
+    }
+    for requested in &selection.features {
+        match requested.split_once('/') {
+            Some((crate_name, feature)) if Some(crate_name) == package_name => pending.push(feature.to_string()),
+            Some(_) => {}
+            None => pending.push(requested.clone()),
+        }
+    }
+    if selection.all_features {
+        pending.extend(declared.keys().cloned());
+        pending.extend(optional_dependency_names(&manifest));
+    }
+
+    let mut enabled = HashSet::new();
+    while let Some(feature) = pending.pop() {
+        if !enabled.insert(feature.clone()) {
+            continue;
+        }
+        for entry in declared.get(&feature).into_iter().flatten() {
+            if entry.starts_with("dep:") {
+                continue;
+            }
+            match entry.split_once('/') {
+                Some((dependency, _)) if !dependency.ends_with('?') => pending.push(dependency.to_string()),
+                Some(_) => {}
+                None => pending.push(entry.clone()),
+            }
+        }
+    }
+    enabled
+}
+
+fn optional_dependency_names(manifest: &toml::Value) -> Vec<String> {
+    ["dependencies", "dev-dependencies", "build-dependencies"]
+        .iter()
+        .filter_map(|section| manifest.get(section).and_then(|s| s.as_table()))
+        .flat_map(|table| table.iter())
+        .filter(|(_, spec)| spec.get("optional").and_then(|o| o.as_bool()) == Some(true))
+        .map(|(name, _)| name.clone())
+        .collect()
+}
+
+/// Lines gated by one `#[cfg(...)]` attribute
+///
+/// # 4-Word Name: RustCfgRegionSpan
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RustCfgRegionSpan {
+    /// 1-based, the attribute's line
+    pub start_line: usize,
+    /// 1-based, the line ending the gated item
+    pub end_line: usize,
+    /// Predicate inside `cfg(...)`, normalized spacing
+    pub predicate: String,
+}
+
+/// Every cfg-gated region of a Rust source file, in source order
+///
+/// # 4-Word Name: extract_rust_cfg_regions
+///
+/// # Contract
+/// - Comments, strings and char literals are skipped
+/// - A top-level `#![cfg(...)]` spans the whole file
+/// - `cfg_attr` is not a gate and is ignored
+pub fn extract_rust_cfg_regions(source: &str) -> Vec<RustCfgRegionSpan> {
+    let tokens = tokenize_rust_cfg_source(source);
+    let last_line = source.lines().count().max(1);
+    let mut regions = Vec::new();
+    let mut brace_depth = 0usize;
+    for index in 0..tokens.len() {
+        match tokens[index].text.as_str() {
+            "{" => brace_depth += 1,
+            "}" => brace_depth = brace_depth.saturating_sub(1),
+            "#" => {
+                let inner = tokens.get(index + 1).is_some_and(|t| t.text == "!");
+                let open = index + 1 + usize::from(inner);
+                let Some((predicate, attribute_end)) = read_cfg_attribute(&tokens, open) else { continue };
+                let start_line = tokens[index].line;
+                let end_line = if inner {
+                    if brace_depth > 0 {
+                        continue;
+                    }
+                    last_line
+                } else {
+                    find_gated_item_end_line(&tokens, attribute_end + 1, last_line)
+                };
+                regions.push(RustCfgRegionSpan { start_line, end_line, predicate });
+            }
+            _ => {}
+        }
+    }
+    regions
+}
+
+/// Combined cfg condition of the item spanning `start_line..=end_line`
+///
+/// # 4-Word Name: rust_item_cfg_condition
+///
+/// # Contract
+/// - Postcondition: `None` for ungated items; several gates combine as
+///   `all(outer, inner)`
+pub fn rust_item_cfg_condition(regions: &[RustCfgRegionSpan], start_line: usize, end_line: usize) -> Option<String> {
+    let mut predicates: Vec<&str> = Vec::new();
+    for region in regions {
+        let encloses = region.start_line <= start_line && region.end_line >= end_line;
+        if encloses && !predicates.contains(&region.predicate.as_str()) {
+            predicates.push(&region.predicate);
+        }
+    }
+    match predicates.len() {
+        0 => None,
+        1 => Some(predicates[0].to_string()),
+        _ => Some(format!("all({})", predicates.join(", "))),
+    }
+}
+
+/// Evaluate a cfg predicate; `None` when it depends on more than features
+///
+/// # 4-Word Name: evaluate_rust_cfg_predicate
+///
+/// # Contract
+/// - Malformed predicates evaluate to `None`
+pub fn evaluate_rust_cfg_predicate(predicate: &str, enabled_features: &HashSet<String>) -> Option<bool> {
+    let tokens = tokenize_rust_cfg_source(predicate);
+    let mut position = 0;
+    let value = parse_cfg_predicate_tokens(&tokens, &mut position, enabled_features)?;
+    if position == tokens.len() {
+        value
+    } else {
+        None
+    }
+}
+
+/// Enabled features per crate below one ingest root, read lazily
+///
+/// # 4-Word Name: RustCrateFeatureIndex
+#[derive(Debug)]
+pub struct RustCrateFeatureIndex {
+    root_dir: PathBuf,
+    selection: RustFeatureSelectionConfig,
+    features_by_manifest_dir: Mutex<HashMap<PathBuf, Arc<HashSet<String>>>>,
+}
+
+impl RustCrateFeatureIndex {
+    pub fn for_ingest_root(root_dir: &Path, selection: RustFeatureSelectionConfig) -> Self {
+        Self {
+            root_dir: root_dir.to_path_buf(),
+            selection,
+            features_by_manifest_dir: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// True when the entity with `condition` is compiled in its crate
+    ///
+    /// # 4-Word Name: is_condition_compiled_in
+    ///
+    /// # Contract
+    /// - Always true without an active selection, or when no `Cargo.toml`
+    ///   with a `[package]` encloses `file_path` below the ingest root
+    pub fn is_condition_compiled_in(&self, file_path: &Path, condition: &str) -> bool {
+        if !self.selection.is_selection_active() {
+            return true;
+        }
+        let Some(enabled) = self.enabled_features_for_file(file_path) else {
+            return true;
+        };
+        evaluate_rust_cfg_predicate(condition, &enabled) != Some(false)
+    }
+
+    fn enabled_features_for_file(&self, file_path: &Path) -> Option<Arc<HashSet<String>>> {
+        let manifest_dir = file_path
+            .ancestors()
+            .skip(1)
+            .take_while(|dir| dir.starts_with(&self.root_dir))
+            .find(|dir| {
+                std::fs::read_to_string(dir.join("Cargo.toml")).is_ok_and(|text| text.contains("[package]"))
+            })?;
+        let mut cache = self.features_by_manifest_dir.lock().unwrap_or_else(|poisoned| poisoned.into_inner());
+        let features = cache.entry(manifest_dir.to_path_buf()).or_insert_with(|| {
+            let text = std::fs::read_to_string(manifest_dir.join("Cargo.toml")).unwrap_or_default();
+            Arc::new(resolve_enabled_crate_features(&text, &self.selection))
+        });
+        Some(features.clone())
+    }
+}
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+struct CfgLexToken {
+    text: String,
+    line: usize,
+    is_string: bool,
+}
+
+/// Identifiers, string literal contents and single punctuation characters
+fn tokenize_rust_cfg_source(source: &str) -> Vec<CfgLexToken> {
+    let chars: Vec<char> = source.chars().collect();
+    let mut tokens = Vec::new();
+    let mut line = 1;
+    let mut i = 0;
+    let push = |tokens: &mut Vec<CfgLexToken>, text: String, line: usize, is_string: bool| {
+        tokens.push(CfgLexToken { text, line, is_string });
+    };
+    while i < chars.len() {
+        let c = chars[i];
+        let next = chars.get(i + 1).copied();
+        if c == '\n' {
+            line += 1;
+            i += 1;
+        } else if c.is_whitespace() {
+            i += 1;
+        } else if c == '/' && next == Some('/') {
+            while i < chars.len() && chars[i] != '\n' {
+                i += 1;
+            }
+        } else if c == '/' && next == Some('*') {
+            let mut depth = 0;
+            while i < chars.len() {
+                if chars[i] == '/' && chars.get(i + 1) == Some(&'*') {
+                    depth += 1;
+                    i += 2;
+                } else if chars[i] == '*' && chars.get(i + 1) == Some(&'/') {
+                    depth -= 1;
+                    i += 2;
+                    if depth == 0 {
+                        break;
+                    }
+                } else {
+                    line += usize::from(chars[i] == '\n');
+                    i += 1;
+                }
+            }
+        } else if c == '"' {
+            let start_line = line;
+            let (text, end) = read_quoted_string(&chars, i + 1, &mut line);
+            push(&mut tokens, text, start_line, true);
+            i = end;
+        } else if c == '\'' {
+            // Char literal ('x', '\n', '\u{1F600}') or lifetime ('a)
+            if next == Some('\\') {
+                i += 2;
+                while i < chars.len() && chars[i] != '\'' {
+                    i += 1;
+                }
+                i += 1;
+            } else if chars.get(i + 2) == Some(&'\'') {
+                i += 3;
+            } else {
+                i += 1;
+            }
+        } else if c.is_alphanumeric() || c == '_' {
+            let start = i;
+            while i < chars.len() && (chars[i].is_alphanumeric() || chars[i] == '_') {
+                i += 1;
+            }
+            let word: String = chars[start..i].iter().collect();
+            let is_raw_prefix = word == "r" || word == "br";
+            let hashes = chars[i..].iter().take_while(|&&h| h == '#').count();
+            if is_raw_prefix && chars.get(i + hashes) == Some(&'"') {
+                let start_line = line;
+                let (text, end) = read_raw_string(&chars, i + hashes + 1, hashes, &mut line);
+                push(&mut tokens, text, start_line, true);
+                i = end;
+            } else if (word == "b" || word == "c") && chars.get(i) == Some(&'"') {
+                let start_line = line;
+                let (text, end) = read_quoted_string(&chars, i + 1, &mut line);
+                push(&mut tokens, text, start_line, true);
+                i = end;
+            } else {
+                push(&mut tokens, word, line, false);
+            }
+        } else {
+            push(&mut tokens, c.to_string(), line, false);
+            i += 1;
+        }
+    }
+    tokens
+}
+
+/// Contents of a `"..."` literal starting after the quote; returns the index after the closing quote
+fn read_quoted_string(chars: &[char], mut i: usize, line: &mut usize) -> (String, usize) {
+    let mut text = String::new();
+    while i < chars.len() && chars[i] != '"' {
+        if chars[i] == '\\' && i + 1 < chars.len() {
+            text.push(chars[i]);
+            i += 1;
+        }
+        *line += usize::from(chars[i] == '\n');
+        text.push(chars[i]);
+        i += 1;
+    }
+    (text, i + 1)
+}
+
+fn read_raw_string(chars: &[char], mut i: usize, hashes: usize, line: &mut usize) -> (String, usize) {
+    let mut text = String::new();
+    while i < chars.len() {
+        let closes = chars[i] == '"' && chars[i + 1..].iter().take(hashes).filter(|&&h| h == '#').count() == hashes;
+        if closes {
+            return (text, i + 1 + hashes);
+        }
+        *line += usize::from(chars[i] == '\n');
+        text.push(chars[i]);
+        i += 1;
+    }
+    (text, i)
+}
+
+/// `[cfg(...)]` at `open`: the rendered predicate and the index of `]`
+fn read_cfg_attribute(tokens: &[CfgLexToken], open: usize) -> Option<(String, usize)> {
+    let is = |offset: usize, text: &str| tokens.get(open + offset).is_some_and(|t| !t.is_string && t.text == text);
+    if !(is(0, "[") && is(1, "cfg") && is(2, "(")) {
+        return None;
+    }
+    let mut depth = 0usize;
+    for close in open + 2..tokens.len() {
+        match tokens[close].text.as_str() {
+            "(" if !tokens[close].is_string => depth += 1,
+            ")" if !tokens[close].is_string => {
+                depth -= 1;
+                if depth == 0 {
+                    let predicate = render_cfg_predicate_tokens(&tokens[open + 3..close]);
+                    return is(close - open + 1, "]").then_some((predicate, close + 1));
+                }
+            }
+            _ => {}
+        }
+    }
+    None
+}
+
+/// Line of the `;`, `,` or closing `}` ending the item that starts at `start`
+fn find_gated_item_end_line(tokens: &[CfgLexToken], start: usize, last_line: usize) -> usize {
+    let mut nesting = 0usize;
+    let mut braces = 0usize;
+    for (offset, token) in tokens[start.min(tokens.len())..].iter().enumerate() {
+        if token.is_string {
+            continue;
+        }
+        match token.text.as_str() {
+            "(" | "[" => nesting += 1,
+            ")" | "]" if nesting == 0 => {
+                // Closes the parent: the item ended with the previous token
+                return tokens[start + offset - 1].line;
+            }
+            ")" | "]" => nesting -= 1,
+            "{" => braces += 1,
+            "}" if braces == 0 => return token.line,
+            "}" => {
+                braces -= 1;
+                if braces == 0 && nesting == 0 {
+                    return token.line;
+                }
+            }
+            ";" | "," if braces == 0 && nesting == 0 => return token.line,
+            _ => {}
+        }
+    }
+    last_line
+}
+
+fn render_cfg_predicate_tokens(tokens: &[CfgLexToken]) -> String {
+    let mut rendered = String::new();
+    for token in tokens {
+        match token.text.as_str() {
+            _ if token.is_string => rendered.push_str(&format!("\"{}\"", token.text)),
+            "=" => rendered.push_str(" = "),
+            "," => rendered.push_str(", "),
+            text => rendered.push_str(text),
+        }
+    }
+    rendered
+}
+
+/// One predicate; outer `None` = malformed, inner `None` = unknown
+fn parse_cfg_predicate_tokens(
+    tokens: &[CfgLexToken],
+    position: &mut usize,
+    enabled_features: &HashSet<String>,
+) -> Option<Option<bool>> {
+    let name = tokens.get(*position).filter(|t| !t.is_string)?.text.clone();
+    *position += 1;
+    let next_is = |position: usize, text: &str| tokens.get(position).is_some_and(|t| !t.is_string && t.text == text);
+
+    if next_is(*position, "=") {
+        let value = tokens.get(*position + 1).filter(|t| t.is_string)?;
+        *position += 2;
+        return Some((name == "feature").then(|| enabled_features.contains(&value.text)));
+    }
+    if !next_is(*position, "(") {
+        return Some(None);
+    }
+    *position += 1;
+    let mut operands = Vec::new();
+    while !next_is(*position, ")") {
+        operands.push(parse_cfg_predicate_tokens(tokens, position, enabled_features)?);
+        if next_is(*position, ",") {
+            *position += 1;
+        } else if !next_is(*position, ")") {
+            return None;
+        }
+    }
+    *position += 1;
+
+    let combined = match name.as_str() {
+        "all" if operands.contains(&Some(false)) => Some(false),
+        "all" if operands.contains(&None) => None,
+        "all" => Some(true),
+        "any" if operands.contains(&Some(true)) => Some(true),
+        "any" if operands.contains(&None) => None,
+        "any" => Some(false),
+        "not" if operands.len() == 1 => operands[0].map(|value| !value),
+        _ => return None,
+    };
+    Some(combined)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_cfg_regions_and_item_conditions() {
+        let source = r#"//! crate docs
+#[cfg(feature = "tokio")]
+pub fn spawn() {
+    let s = "}"; // a brace in a string
+}
+
+#[cfg(not(feature = "tokio"))]
+#[derive(Debug)]
+pub struct Spawner<'a>(&'a str);
+
+#[cfg(any(unix, feature = "net"))]
+mod net {
+    #[cfg(feature = "tls")]
+    pub fn connect() {
+        let c = '{';
+    }
+}
+"#;
+        let regions = extract_rust_cfg_regions(source);
+        assert_eq!(
+            regions,
+            vec![
+                RustCfgRegionSpan { start_line: 2, end_line: 5, predicate: "feature = \"tokio\"".to_string() },
+                RustCfgRegionSpan { start_line: 7, end_line: 9, predicate: "not(feature = \"tokio\")".to_string() },
+                RustCfgRegionSpan { start_line: 11, end_line: 17, predicate: "any(unix, feature = \"net\")".to_string() },
+                RustCfgRegionSpan { start_line: 13, end_line: 16, predicate: "feature = \"tls\"".to_string() },
+            ]
+        );
+        assert_eq!(rust_item_cfg_condition(&regions, 3, 5).as_deref(), Some("feature = \"tokio\""));
+        assert_eq!(
+            rust_item_cfg_condition(&regions, 14, 16).as_deref(),
+            Some("all(any(unix, feature = \"net\"), feature = \"tls\")")
+        );
+        assert_eq!(rust_item_cfg_condition(&regions, 1, 1), None);
+
+        let whole_file = extract_rust_cfg_regions("#![cfg(feature = \"serde\")]\n\nfn a() {}\nfn b() {}\n");
+        assert_eq!(rust_item_cfg_condition(&whole_file, 4, 4).as_deref(), Some("feature = \"serde\""));
+    }
+
+    #[test]
+    fn test_feature_resolution_and_evaluation() {
+        let manifest = r#"
+[package]
+name = "client"
+
+[features]
+default = ["rt"]
+rt = ["dep:tokio"]
+tls = ["net", "rustls?/logging"]
+net = []
+
+[dependencies]
+tokio = { version = "1", optional = true }
+serde = { version = "1", optional = true }
+"#;
+        let resolve = |features: &[&str], all_features: bool, no_default_features: bool| {
+            let selection = RustFeatureSelectionConfig {
+                features: features.iter().map(|f| f.to_string()).collect(),
+                all_features,
+                no_default_features,
+            };
+            let mut enabled: Vec<String> = resolve_enabled_crate_features(manifest, &selection).into_iter().collect();
+            enabled.sort();
+            enabled
+        };
+        assert_eq!(resolve(&[], false, false), vec!["default", "rt"]);
+        assert_eq!(resolve(&["client/tls", "other/x"], false, true), vec!["net", "tls"]);
+        assert_eq!(resolve(&[], true, false), vec!["default", "net", "rt", "serde", "tls", "tokio"]);
+
+        let enabled: HashSet<String> = ["net".to_string()].into_iter().collect();
+        assert_eq!(evaluate_rust_cfg_predicate("feature = \"net\"", &enabled), Some(true));
+        assert_eq!(evaluate_rust_cfg_predicate("not(feature = \"net\")", &enabled), Some(false));
+        assert_eq!(evaluate_rust_cfg_predicate("all(unix, feature = \"tls\")", &enabled), Some(false));
+        assert_eq!(evaluate_rust_cfg_predicate("any(windows, feature = \"tls\")", &enabled), None);
+        assert_eq!(evaluate_rust_cfg_predicate("any(windows, feature = \"net\")", &enabled), Some(true));
+        assert_eq!(evaluate_rust_cfg_predicate("all(feature = ", &enabled), None);
+    }
+}
@@ -0,0 +1,418 @@
+//! Rust Macro Symbol Attributor (v1.7.3)
+//!
+//! # 4-Word Naming: rust_macro_symbol_attributor
+//!
+//! Code written by macros never shows up as syntax, so its symbols vanish
+//! from the graph. This per-file pass recovers the two common cases and tags
+//! everything it adds with `generated_by`:
+//!
+//! 1. `#[derive(Debug, Clone, serde::Serialize)]` (also inside `cfg_attr`):
+//!    one `Implements` edge per derived trait, from the annotated struct/enum
+//!    to the trait, with `generated_by: derive(Trait)` and `impl_type`, the
+//!    same shape rust_trait_impl_bound_extractor gives hand-written impls
+//! 2. Item-level invocations of a `macro_rules!` defined in the same file:
+//!    `fn` / `struct` / `enum` / `trait` / `type` / `const` / `static` / `mod`
+//!    items in the rule's transcriber become entities at the INVOCATION site,
+//!    with `generated_by: {macro}!`. Metavariable names (`fn $name()`) are
+//!    filled from the invocation's identifiers in order
+//!
+//! ## Limitations (no macro expansion)
+//!
+//! - Only the first rule producing items is used; `$(...)*` repetitions yield
+//!   one item
+//! - Identifier mapping stops at the first non-`ident` fragment (`$t:ty`)
+//! - Macros defined in other files and proc macros other than derives are not
+//!   expanded
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType, Language};
+use crate::query_extractor::{EntityType, ParsedEntity};
+use crate::rust_trait_impl_bound_extractor::{find_rust_item_entity_key, IMPL_TYPE_METADATA_KEY, TRAIT_PATH_METADATA_KEY};
+
+/// Entity/edge metadata key: origin of a generated symbol (`derive(Clone)`, `make_handler!`)
+pub const GENERATED_BY_METADATA_KEY: &str = "generated_by";
+
+/// Item keywords recognised in a macro transcriber
+const MACRO_ITEM_KEYWORDS: [&str; 8] = ["fn", "struct", "enum", "trait", "type", "const", "static", "mod"];
+
+/// Macro-generated items of one Rust file
+///
+/// # 4-Word Name: collect_rust_macro_generated_entities
+///
+/// # Contract
+/// - Precondition: `root` is the parse of `source`
+/// - Postcondition: one entity per item a same-file `macro_rules!` rule emits
+///   for each item-level invocation; line range = the invocation
+pub fn collect_rust_macro_generated_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+) -> Vec<ParsedEntity> {
+    let templates = collect_macro_rules_templates(root, source);
+    if templates.is_empty() {
+        return Vec::new();
+    }
+
+    let mut entities = Vec::new();
+    let mut seen = HashSet::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        if node.kind() != "macro_invocation" {
+            continue;
+        }
+        let Some(container) = item_level_container(node) else { continue };
+        let Some(macro_name) = node.child_by_field_name("macro").map(|m| &source[m.byte_range()]) else {
+            continue;
+        };
+        let Some(template) = templates.get(macro_name) else { continue };
+        let mut cursor = node.walk();
+        let Some(arguments) = node.named_children(&mut cursor).find(|c| c.kind() == "token_tree") else {
+            continue;
+        };
+        let argument_idents: Vec<String> = flatten_token_tree(arguments, source)
+            .into_iter()
+            .filter(|(kind, _)| *kind == "identifier")
+            .map(|(_, text)| text)
+            .collect();
+        let bindings: HashMap<&str, &str> = template
+            .ident_bindings
+            .iter()
+            .zip(argument_idents.iter())
+            .filter_map(|(binding, argument)| binding.as_deref().map(|b| (b, argument.as_str())))
+            .collect();
+
+        for (keyword, name) in &template.items {
+            let name = match name.strip_prefix('$') {
+                Some(metavariable) => match bindings.get(metavariable) {
+                    Some(argument) => argument.to_string(),
+                    None => continue,
+                },
+                None => name.clone(),
+            };
+            let entity_type = match keyword.as_str() {
+                "fn" if container == "impl_item" => EntityType::Method,
+                "fn" => EntityType::Function,
+                "struct" => EntityType::Struct,
+                "enum" => EntityType::Enum,
+                "trait" => EntityType::Trait,
+                "type" => EntityType::Typedef,
+                "mod" => EntityType::Module,
+                _ => EntityType::Constant,
+            };
+            let line_range = (node.start_position().row + 1, node.end_position().row + 1);
+            if !seen.insert((name.clone(), line_range)) {
+                continue;
+            }
+            let mut metadata = HashMap::new();
+            metadata.insert(GENERATED_BY_METADATA_KEY.to_string(), format!("{}!", macro_name));
+            entities.push(ParsedEntity {
+                entity_type,
+                name,
+                language: Language::Rust,
+                line_range,
+                file_path: file_path.to_string_lossy().to_string(),
+                metadata,
+            });
+        }
+    }
+    entities
+}
+
+/// Implements edges for derived traits
+///
+/// # 4-Word Name: extract_rust_derive_impl_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: one edge per (type, derived trait); types without an
+///   extracted entity produce no edges
+pub fn extract_rust_derive_impl_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let mut edges = Vec::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        let item_kind = match node.kind() {
+            "struct_item" | "union_item" => EntityType::Struct,
+            "enum_item" => EntityType::Enum,
+            _ => continue,
+        };
+
+        // Attributes are preceding siblings; doc comments may sit between them
+        let mut derives: Vec<(String, tree_sitter::Node<'_>)> = Vec::new();
+        let mut sibling = node.prev_named_sibling();
+        while let Some(attribute) = sibling {
+            match attribute.kind() {
+                "attribute_item" => derives.extend(
+                    parse_derive_trait_paths(&source[attribute.byte_range()])
+                        .into_iter()
+                        .map(|path| (path, attribute)),
+                ),
+                "line_comment" | "block_comment" => {}
+                _ => break,
+            }
+            sibling = attribute.prev_named_sibling();
+        }
+        if derives.is_empty() {
+            continue;
+        }
+        let Some(from_key) = find_rust_item_entity_key(node, &item_kind, source, entities) else {
+            continue;
+        };
+        let type_name = node
+            .child_by_field_name("name")
+            .map(|n| source[n.byte_range()].to_string())
+            .unwrap_or_default();
+
+        let mut seen = HashSet::new();
+        // Attributes were collected bottom-up
+        for (path, attribute) in derives.into_iter().rev() {
+            let name = path.rsplit("::").next().unwrap_or(&path).trim().to_string();
+            if name.is_empty() || !seen.insert(name.clone()) {
+                continue;
+            }
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.clone())
+                .to_key(format!("rust:fn:{}:unresolved-reference:0-0", name))
+                .edge_type(EdgeType::Implements)
+                .source_location(format!("{}:{}", path_text, attribute.start_position().row + 1))
+                .metadata_entry("hierarchy_kind", "implements")
+                .metadata_entry(IMPL_TYPE_METADATA_KEY, type_name.clone())
+                .metadata_entry(GENERATED_BY_METADATA_KEY, format!("derive({})", name));
+            if path.contains("::") {
+                builder = builder.metadata_entry(TRAIT_PATH_METADATA_KEY, path.clone());
+            }
+            if let Ok(edge) = builder.build() {
+                edges.push(edge);
+            }
+        }
+    }
+    edges
+}
+
+/// Trait paths listed in every `derive(...)` of an attribute
+///
+/// `#[cfg_attr(feature = "serde", derive(Serialize))]` counts: the derive
+/// applies whenever the feature is on.
+pub(crate) fn parse_derive_trait_paths(attribute_text: &str) -> Vec<String> {
+    let mut paths = Vec::new();
+    let mut rest = attribute_text;
+    while let Some(start) = rest.find("derive(") {
+        let after = &rest[start + "derive(".len()..];
+        let end = after.find(')').unwrap_or(after.len());
+        paths.extend(
+            after[..end]
+                .split(',')
+                .map(|p| p.split_whitespace().collect::<String>())
+                .filter(|p| !p.is_empty()),
+        );
+        rest = &after[end..];
+    }
+    paths
+}
+
+/// Items one `macro_rules!` emits, and how its matcher binds identifiers
+struct MacroRulesItemTemplate {
+    /// `(keyword, name)`; name is `$meta` when taken from the invocation
+    items: Vec<(String, String)>,
+    /// Matcher identifiers in order: `Some(meta)` for `$meta:ident`,
+    /// `None` for literal identifiers (consume one invocation identifier)
+    ident_bindings: Vec<Option<String>>,
+}
+
+/// `macro_rules!` name → item template, for rules that emit items
+fn collect_macro_rules_templates(root: tree_sitter::Node<'_>, source: &str) -> HashMap<String, MacroRulesItemTemplate> {
+    let mut templates = HashMap::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        if node.kind() != "macro_definition" {
+            continue;
+        }
+        let Some(name) = node.child_by_field_name("name").map(|n| source[n.byte_range()].to_string()) else {
+            continue;
+        };
+        let mut rules = node.walk();
+        for rule in node.named_children(&mut rules).filter(|r| r.kind() == "macro_rule") {
+            let (Some(left), Some(right)) = (rule.child_by_field_name("left"), rule.child_by_field_name("right")) else {
+                continue;
+            };
+            let items = transcriber_item_names(&flatten_token_tree(right, source));
+            if items.is_empty() {
+                continue;
+            }
+            templates.insert(
+                name,
+                MacroRulesItemTemplate { items, ident_bindings: matcher_ident_bindings(left, source) },
+            );
+            break;
+        }
+    }
+    templates
+}
+
+/// `(keyword, name)` for every `fn foo` / `struct $name` in a transcriber
+fn transcriber_item_names(tokens: &[(String, String)]) -> Vec<(String, String)> {
+    tokens
+        .windows(2)
+        .filter(|pair| MACRO_ITEM_KEYWORDS.contains(&pair[0].0.as_str()))
+        .filter(|pair| matches!(pair[1].0.as_str(), "identifier" | "metavariable"))
+        .map(|pair| (pair[0].0.clone(), pair[1].1.clone()))
+        .collect()
+}
+
+/// Identifier-consuming positions of a matcher, up to the first non-ident fragment
+fn matcher_ident_bindings(matcher: tree_sitter::Node<'_>, source: &str) -> Vec<Option<String>> {
+    let mut bindings = Vec::new();
+    let mut stack = vec![matcher];
+    while let Some(node) = stack.pop() {
+        match node.kind() {
+            "token_binding_pattern" => {
+                let fragment = node.child_by_field_name("type").map(|t| &source[t.byte_range()]);
+                if fragment != Some("ident") {
+                    break;
+                }
+                let name = node
+                    .child_by_field_name("name")
+                    .map(|n| source[n.byte_range()].trim_start_matches('$').to_string());
+                bindings.push(name);
+                continue;
+            }
+            "identifier" => {
+                bindings.push(None);
+                continue;
+            }
+            _ => {}
+        }
+        let mut cursor = node.walk();
+        let children: Vec<_> = node.named_children(&mut cursor).collect();
+        stack.extend(children.into_iter().rev());
+    }
+    bindings
+}
+
+/// Leaf tokens of a token tree in source order: `(kind, text)`
+fn flatten_token_tree(tree: tree_sitter::Node<'_>, source: &str) -> Vec<(String, String)> {
+    let mut tokens = Vec::new();
+    let mut stack = vec![tree];
+    while let Some(node) = stack.pop() {
+        if node.child_count() == 0 || node.kind() == "metavariable" {
+            tokens.push((node.kind().to_string(), source[node.byte_range()].to_string()));
+            continue;
+        }
+        let mut cursor = node.walk();
+        let children: Vec<_> = node.children(&mut cursor).collect();
+        stack.extend(children.into_iter().rev());
+    }
+    tokens
+}
+
+/// Kind of the item container of an item-level macro invocation
+///
+/// `Some("source_file")`, `Some("impl_item")`, `Some("mod_item")`, ...; `None`
+/// for invocations inside function bodies (`vec![..]`, `println!(..)`).
+fn item_level_container(invocation: tree_sitter::Node<'_>) -> Option<&'static str> {
+    let mut parent = invocation.parent()?;
+    if parent.kind() == "expression_statement" {
+        parent = parent.parent()?;
+    }
+    match parent.kind() {
+        "source_file" => Some("source_file"),
+        "declaration_list" => match parent.parent().map(|p| p.kind()) {
+            Some("impl_item") => Some("impl_item"),
+            Some("trait_item") => Some("trait_item"),
+            _ => Some("mod_item"),
+        },
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_derives_become_generated_implements_edges() {
+        let code = r#"
+/// A user
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "serde", derive(serde::Serialize))]
+pub struct User {
+    name: String,
+}
+
+#[derive(PartialEq)]
+enum Role { Admin }
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new("src/user.rs"), Language::Rust).unwrap();
+        let derived: Vec<(String, String, String)> = edges
+            .iter()
+            .filter(|e| e.metadata_value(GENERATED_BY_METADATA_KEY).is_some())
+            .map(|e| {
+                (
+                    e.from_key.as_str().split(':').take(3).collect::<Vec<_>>().join(":"),
+                    e.to_key.as_str().split(':').nth(2).unwrap_or_default().to_string(),
+                    e.metadata_value(GENERATED_BY_METADATA_KEY).unwrap_or_default().to_string(),
+                )
+            })
+            .collect();
+
+        assert_eq!(derived.len(), 4, "{:?}", derived);
+        assert!(derived.contains(&("rust:struct:User".into(), "Debug".into(), "derive(Debug)".into())));
+        assert!(derived.contains(&("rust:struct:User".into(), "Serialize".into(), "derive(Serialize)".into())));
+        assert!(derived.contains(&("rust:enum:Role".into(), "PartialEq".into(), "derive(PartialEq)".into())));
+        let serialize = edges.iter().find(|e| e.to_key.as_str().contains(":Serialize:")).unwrap();
+        assert_eq!(serialize.edge_type, EdgeType::Implements);
+        assert_eq!(serialize.metadata_value(TRAIT_PATH_METADATA_KEY), Some("serde::Serialize"));
+        assert_eq!(serialize.metadata_value(IMPL_TYPE_METADATA_KEY), Some("User"));
+    }
+
+    #[test]
+    fn test_macro_rules_items_attributed_to_invocation() {
+        let code = r#"
+macro_rules! make_handler {
+    ($name:ident, $body:expr) => {
+        pub fn $name() -> u32 { $body }
+    };
+}
+
+macro_rules! make_registry {
+    () => {
+        pub struct Registry;
+    };
+}
+
+make_handler!(ping, 1);
+make_registry!();
+
+fn main() {
+    let v = vec![1, 2];
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, _edges) = extractor.parse_source(code, Path::new("src/handlers.rs"), Language::Rust).unwrap();
+
+        let ping = entities.iter().find(|e| e.name == "ping").expect("generated fn ping");
+        assert_eq!(ping.entity_type, EntityType::Function);
+        assert_eq!(ping.line_range, (14, 14), "attributed to the invocation, not the macro body");
+        assert_eq!(ping.metadata.get(GENERATED_BY_METADATA_KEY).map(String::as_str), Some("make_handler!"));
+
+        let registry = entities.iter().find(|e| e.name == "Registry").expect("generated struct");
+        assert_eq!(registry.entity_type, EntityType::Struct);
+        assert_eq!(registry.line_range, (15, 15));
+        assert!(!entities.iter().any(|e| e.name == "$name"));
+    }
+}
@@ -0,0 +1,359 @@
+//! Rust Trait Impl and Bound Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: rust_trait_impl_bound_extractor
+//!
+//! Per-file pass recording how Rust types meet traits:
+//!
+//! - `Implements`: every `impl Trait for Type` block (generic, scoped and
+//!   generic-trait forms included), from the impl block to the trait, with
+//!   `impl_type` metadata holding the implementing type as written
+//! - `Bound`: generic parameters to their trait bounds, from the declaring
+//!   function, struct, enum, trait or impl. Covers `<T: A + B>`, `where`
+//!   clauses, `impl Trait` arguments (`type_parameter: impl`) and supertraits
+//!   (`trait Repo: Send + Sync` → `type_parameter: Self`)
+//!
+//! "Which types implement `Serialize`" is then the reverse `Implements`
+//! neighbourhood of `rust:fn:Serialize:unresolved-reference:0-0`.
+//!
+//! Edge metadata: `trait_path` when the trait is path-qualified
+//! (`serde::Serialize`), `type_arguments` for generic traits (`From<String>`).
+//! Lifetime bounds and `?Sized` are not traits and are skipped.
+
+use std::collections::HashSet;
+use std::path::Path;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::isgl1_v2::{compute_birth_timestamp, extract_semantic_path};
+use crate::query_extractor::{key_component_for_entity_type, EntityType, ParsedEntity};
+
+/// Edge metadata key: implementing type of an `Implements` edge (`Wrapper<T>`)
+pub const IMPL_TYPE_METADATA_KEY: &str = "impl_type";
+
+/// Edge metadata key: bounded parameter of a `Bound` edge (`T`, `Self`, `impl`)
+pub const TYPE_PARAMETER_METADATA_KEY: &str = "type_parameter";
+
+/// Edge metadata key: trait as written when path-qualified (`serde::Serialize`)
+pub const TRAIT_PATH_METADATA_KEY: &str = "trait_path";
+
+/// Trait named by a bound or impl, split into its parts
+pub(crate) struct TraitReferenceParts {
+    pub(crate) name: String,
+    path: Option<String>,
+    type_arguments: Option<String>,
+}
+
+/// Extract Implements and Bound edges for one Rust file
+///
+/// # 4-Word Name: extract_rust_trait_impl_bound_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: at most one edge per (source item, trait, edge type);
+///   items without an extracted entity produce no edges
+pub fn extract_rust_trait_impl_bound_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let mut edges = Vec::new();
+    let mut seen: HashSet<(String, String, EdgeType)> = HashSet::new();
+    let mut push_edge = |from_key: &str, reference: TraitReferenceParts, edge_type: EdgeType, node: tree_sitter::Node<'_>, extra: (&str, String)| {
+        let to_key = format!("rust:fn:{}:unresolved-reference:0-0", reference.name);
+        if !seen.insert((from_key.to_string(), to_key.clone(), edge_type)) {
+            return;
+        }
+        let mut builder = DependencyEdge::builder()
+            .from_key(from_key.to_string())
+            .to_key(to_key)
+            .edge_type(edge_type)
+            .source_location(format!("{}:{}", path_text, node.start_position().row + 1))
+            .metadata_entry(extra.0, extra.1);
+        if edge_type == EdgeType::Implements {
+            builder = builder.metadata_entry("hierarchy_kind", "implements");
+        }
+        if let Some(path) = reference.path {
+            builder = builder.metadata_entry(TRAIT_PATH_METADATA_KEY, path);
+        }
+        if let Some(arguments) = reference.type_arguments {
+            builder = builder.metadata_entry("type_arguments", arguments);
+        }
+        if let Ok(edge) = builder.build() {
+            edges.push(edge);
+        }
+    };
+
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+
+        let item_kind = match node.kind() {
+            "impl_item" => EntityType::Impl,
+            "function_item" | "function_signature_item" => EntityType::Function,
+            "struct_item" | "union_item" => EntityType::Struct,
+            "enum_item" => EntityType::Enum,
+            "trait_item" => EntityType::Trait,
+            _ => continue,
+        };
+        let Some(from_key) = find_rust_item_entity_key(node, &item_kind, source, entities) else {
+            continue;
+        };
+
+        if item_kind == EntityType::Impl {
+            let trait_reference = node
+                .child_by_field_name("trait")
+                .and_then(|trait_node| trait_reference_parts(trait_node, source));
+            if let (Some(reference), Some(type_node)) = (trait_reference, node.child_by_field_name("type")) {
+                let impl_type = source[type_node.byte_range()].to_string();
+                push_edge(&from_key, reference, EdgeType::Implements, node, (IMPL_TYPE_METADATA_KEY, impl_type));
+            }
+        }
+
+        for (parameter, bound, at) in collect_rust_item_bounds(node, source) {
+            push_edge(&from_key, bound, EdgeType::Bound, at, (TYPE_PARAMETER_METADATA_KEY, parameter));
+        }
+    }
+    edges
+}
+
+/// ISGL1 key of the entity extracted for a Rust item node
+pub(crate) fn find_rust_item_entity_key(
+    node: tree_sitter::Node<'_>,
+    item_kind: &EntityType,
+    source: &str,
+    entities: &[ParsedEntity],
+) -> Option<String> {
+    let name_node = match item_kind {
+        EntityType::Impl => {
+            let type_node = node.child_by_field_name("type")?;
+            if type_node.kind() == "generic_type" {
+                type_node.child_by_field_name("type")?
+            } else {
+                type_node
+            }
+        }
+        _ => node.child_by_field_name("name")?,
+    };
+    let name = &source[name_node.byte_range()];
+    let line = node.start_position().row + 1;
+    let matches_kind = |entity_type: &EntityType| match item_kind {
+        EntityType::Function => matches!(entity_type, EntityType::Function | EntityType::Method),
+        _ => entity_type == item_kind,
+    };
+    let entity = entities
+        .iter()
+        .find(|e| e.name == name && e.line_range.0 == line && matches_kind(&e.entity_type))?;
+    Some(format!(
+        "rust:{}:{}:{}:T{}",
+        key_component_for_entity_type(&entity.entity_type),
+        entity.name,
+        extract_semantic_path(&entity.file_path),
+        compute_birth_timestamp(&entity.file_path, &entity.name)
+    ))
+}
+
+/// `(parameter, trait, node)` for every trait bound an item declares
+fn collect_rust_item_bounds<'t>(
+    item: tree_sitter::Node<'t>,
+    source: &str,
+) -> Vec<(String, TraitReferenceParts, tree_sitter::Node<'t>)> {
+    let mut bounds = Vec::new();
+    let mut add_bounds = |parameter: &str, bounds_node: tree_sitter::Node<'t>| {
+        let mut cursor = bounds_node.walk();
+        for bound in bounds_node.named_children(&mut cursor) {
+            if let Some(reference) = trait_reference_parts(bound, source) {
+                bounds.push((parameter.to_string(), reference, bound));
+            }
+        }
+    };
+
+    // Supertraits: trait Repo: Send + Sync
+    if item.kind() == "trait_item" {
+        if let Some(supertraits) = item.child_by_field_name("bounds") {
+            add_bounds("Self", supertraits);
+        }
+    }
+
+    // <T: Clone, U: Into<String> = String>
+    if let Some(parameters) = item.child_by_field_name("type_parameters") {
+        let mut cursor = parameters.walk();
+        for parameter in parameters.named_children(&mut cursor) {
+            if !matches!(parameter.kind(), "type_parameter" | "constrained_type_parameter" | "optional_type_parameter") {
+                continue;
+            }
+            let name = parameter
+                .child_by_field_name("name")
+                .or_else(|| parameter.child_by_field_name("left"))
+                .map(|n| source[n.byte_range()].to_string());
+            let bounds_node = parameter.child_by_field_name("bounds").or_else(|| {
+                // optional_type_parameter wraps a constrained_type_parameter
+                parameter.child_by_field_name("name").and_then(|inner| inner.child_by_field_name("bounds"))
+            });
+            if let (Some(name), Some(bounds_node)) = (name, bounds_node) {
+                add_bounds(&name, bounds_node);
+            }
+        }
+    }
+
+    // where T: Serialize, for<'a> &'a T: IntoIterator
+    let mut cursor = item.walk();
+    let where_clause = item.named_children(&mut cursor).find(|child| child.kind() == "where_clause");
+    if let Some(where_clause) = where_clause {
+        let mut predicates = where_clause.walk();
+        for predicate in where_clause.named_children(&mut predicates) {
+            let (Some(left), Some(bounds_node)) =
+                (predicate.child_by_field_name("left"), predicate.child_by_field_name("bounds"))
+            else {
+                continue;
+            };
+            add_bounds(&source[left.byte_range()], bounds_node);
+        }
+    }
+
+    // fn save(value: impl Serialize + Send)
+    if let Some(parameters) = item.child_by_field_name("parameters") {
+        let mut stack = vec![parameters];
+        while let Some(node) = stack.pop() {
+            if node.kind() == "abstract_type" {
+                if let Some(trait_node) = node.child_by_field_name("trait") {
+                    if trait_node.kind() == "trait_bounds" {
+                        add_bounds("impl", trait_node);
+                    } else if let Some(reference) = trait_reference_parts(trait_node, source) {
+                        bounds.push(("impl".to_string(), reference, trait_node));
+                    }
+                }
+                continue;
+            }
+            let mut children = node.walk();
+            stack.extend(node.named_children(&mut children));
+        }
+    }
+    bounds
+}
+
+/// Trait name, path and type arguments of a bound or impl trait node
+pub(crate) fn trait_reference_parts(node: tree_sitter::Node<'_>, source: &str) -> Option<TraitReferenceParts> {
+    let text = |n: tree_sitter::Node<'_>| source[n.byte_range()].to_string();
+    match node.kind() {
+        "type_identifier" => Some(TraitReferenceParts { name: text(node), path: None, type_arguments: None }),
+        "scoped_type_identifier" => Some(TraitReferenceParts {
+            name: text(node.child_by_field_name("name")?),
+            path: Some(text(node)),
+            type_arguments: None,
+        }),
+        "generic_type" => {
+            let mut reference = trait_reference_parts(node.child_by_field_name("type")?, source)?;
+            reference.type_arguments = node.child_by_field_name("type_arguments").map(|arguments| {
+                text(arguments).trim_start_matches('<').trim_end_matches('>').trim().to_string()
+            });
+            Some(reference)
+        }
+        // Fn(u8) -> bool
+        "function_type" => trait_reference_parts(node.child_by_field_name("trait")?, source),
+        // for<'a> Fn(&'a str)
+        "higher_ranked_trait_bound" => trait_reference_parts(node.child_by_field_name("value")?, source),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    fn edges_of(code: &str) -> Vec<DependencyEdge> {
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_entities, edges) = extractor.parse_source(code, Path::new("src/store.rs"), Language::Rust).unwrap();
+        edges
+            .into_iter()
+            .filter(|e| matches!(e.edge_type, EdgeType::Implements | EdgeType::Bound))
+            .collect()
+    }
+
+    fn summary(edge: &DependencyEdge, key: &str) -> (String, String, String) {
+        let from = edge.from_key.as_str().split(':').take(3).collect::<Vec<_>>().join(":");
+        let to = edge.to_key.as_str().split(':').nth(2).unwrap_or_default().to_string();
+        (from, to, edge.metadata_value(key).unwrap_or_default().to_string())
+    }
+
+    #[test]
+    fn test_impl_blocks_record_implementing_type() {
+        let code = r#"
+struct Wrapper<T>(T);
+struct Plain;
+
+impl serde::Serialize for Plain {
+    fn serialize(&self) {}
+}
+
+impl<T: Clone> From<T> for Wrapper<T> {
+    fn from(value: T) -> Self { Wrapper(value) }
+}
+
+impl Plain {
+    fn new() -> Self { Plain }
+}
+"#;
+        let edges = edges_of(code);
+        let implements: Vec<_> = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Implements)
+            .map(|e| summary(e, IMPL_TYPE_METADATA_KEY))
+            .collect();
+        assert!(implements.contains(&("rust:impl:Plain".to_string(), "Serialize".to_string(), "Plain".to_string())));
+        assert!(implements.contains(&("rust:impl:Wrapper".to_string(), "From".to_string(), "Wrapper<T>".to_string())));
+        assert_eq!(implements.len(), 2, "{:?}", implements);
+
+        let serialize = edges.iter().find(|e| e.to_key.as_str().contains(":Serialize:")).unwrap();
+        assert_eq!(serialize.metadata_value(TRAIT_PATH_METADATA_KEY), Some("serde::Serialize"));
+        let from = edges.iter().find(|e| e.to_key.as_str().contains(":From:")).unwrap();
+        assert_eq!(from.metadata_value("type_arguments"), Some("T"));
+        assert!(edges
+            .iter()
+            .any(|e| e.edge_type == EdgeType::Bound && summary(e, TYPE_PARAMETER_METADATA_KEY) == ("rust:impl:Wrapper".to_string(), "Clone".to_string(), "T".to_string())));
+    }
+
+    #[test]
+    fn test_generic_where_impl_and_supertrait_bounds() {
+        let code = r#"
+pub trait Repo: Send + Sync {}
+
+pub fn save<T: serde::Serialize + ?Sized, F>(value: &T, on_done: F, sink: impl std::io::Write)
+where
+    F: for<'a> Fn(&'a str) -> bool,
+{
+}
+
+pub struct Cache<K: std::hash::Hash + Eq, V = ()> {
+    entries: Vec<(K, V)>,
+}
+"#;
+        let bounds: Vec<_> = edges_of(code)
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Bound)
+            .map(|e| summary(e, TYPE_PARAMETER_METADATA_KEY))
+            .collect();
+        let expect = |from: &str, to: &str, parameter: &str| {
+            assert!(
+                bounds.contains(&(from.to_string(), to.to_string(), parameter.to_string())),
+                "missing {} -> {} ({}) in {:?}",
+                from,
+                to,
+                parameter,
+                bounds
+            );
+        };
+        expect("rust:trait:Repo", "Send", "Self");
+        expect("rust:trait:Repo", "Sync", "Self");
+        expect("rust:fn:save", "Serialize", "T");
+        expect("rust:fn:save", "Fn", "F");
+        expect("rust:fn:save", "Write", "impl");
+        expect("rust:struct:Cache", "Hash", "K");
+        expect("rust:struct:Cache", "Eq", "K");
+        // ?Sized is not a trait bound
+        assert_eq!(bounds.len(), 7, "{:?}", bounds);
+    }
+}
@@ -0,0 +1,517 @@
+//! Safety Boundary Facet Tagger (v1.7.3)
+//!
+//! # 4-Word Naming: safety_boundary_facet_tagger
+//!
+//! Where memory safety is the caller's problem is the first thing a
+//! security review asks. Per-file passes tag such symbols with a `boundary`
+//! facet (sorted, comma-separated kinds):
+//!
+//! - Rust: `unsafe_fn`, `unsafe_block` (an `unsafe { }` in the body),
+//!   `unsafe_impl`, `extern_fn` (`extern "C" fn`), and `ffi_import`: items
+//!   declared in `extern "C" { ... }` blocks, which become `Function`
+//!   entities of their own
+//! - Go: `go_unsafe` (uses package `unsafe`), `cgo` (calls into `C.`) and
+//!   `cgo_export` (`//export Name` above the function)
+//!
+//! `find_safety_boundary_paths` then walks Calls/Spawns edges from an entry
+//! point and returns, for every boundary symbol it reaches, the shortest
+//! call path there. Unresolved call targets (how calls to an FFI import
+//! arrive) stand for every same-language entity of that name, the way
+//! `blast-radius` matches them.
+
+use std::collections::{BTreeMap, BTreeSet, HashMap, VecDeque};
+use std::path::Path;
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, Language};
+use crate::go_channel_operation_extractor::find_go_declaration_entity_key;
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::go_import_alias_resolver::collect_go_file_import_table;
+use crate::go_package_value_extractor::format_go_parsed_entity_key;
+use crate::query_extractor::{EntityType, ParsedEntity};
+
+/// Entity metadata key: boundary kinds of a symbol (`unsafe_block,extern_fn`)
+pub const BOUNDARY_FACET_METADATA_KEY: &str = "boundary";
+
+/// Boundary kind of declarations inside `extern "C" { ... }`
+pub const FFI_IMPORT_BOUNDARY_KIND: &str = "ffi_import";
+
+/// Call path from an entry point to one boundary symbol
+///
+/// # 4-Word Name: BoundaryCrossingPathEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct BoundaryCrossingPathEntry {
+    pub boundary_key: String,
+    pub boundary_kinds: Vec<String>,
+    /// Entry point first, boundary symbol last
+    pub path: Vec<String>,
+}
+
+/// Tag unsafe/extern Rust items and add `extern` block declarations
+///
+/// # 4-Word Name: tag_rust_safety_boundary_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: Function/Method/Impl entities of unsafe or extern items
+///   carry `boundary`; one `ffi_import` Function entity is appended per
+///   function declared in an `extern` block
+pub fn tag_rust_safety_boundary_entities(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &mut Vec<ParsedEntity>,
+) {
+    let mut tags: Vec<(String, usize, BTreeSet<&'static str>)> = Vec::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut child_cursor = node.walk();
+        stack.extend(node.named_children(&mut child_cursor));
+        match node.kind() {
+            "function_item" => {
+                let Some(name) = node.child_by_field_name("name") else { continue };
+                let mut kinds = BTreeSet::new();
+                let mut cursor = node.walk();
+                for modifiers in node.children(&mut cursor).filter(|c| c.kind() == "function_modifiers") {
+                    let mut modifier_cursor = modifiers.walk();
+                    for modifier in modifiers.children(&mut modifier_cursor) {
+                        match modifier.kind() {
+                            "unsafe" => {
+                                kinds.insert("unsafe_fn");
+                            }
+                            "extern_modifier" => {
+                                kinds.insert("extern_fn");
+                            }
+                            _ => {}
+                        }
+                    }
+                }
+                if node.child_by_field_name("body").is_some_and(contains_rust_unsafe_block) {
+                    kinds.insert("unsafe_block");
+                }
+                if !kinds.is_empty() {
+                    tags.push((source[name.byte_range()].to_string(), node.start_position().row + 1, kinds));
+                }
+            }
+            "impl_item" => {
+                let mut cursor = node.walk();
+                let is_unsafe = node.children(&mut cursor).any(|c| c.kind() == "unsafe");
+                let type_name = node.child_by_field_name("type").map(|t| match t.child_by_field_name("type") {
+                    Some(inner) => &source[inner.byte_range()],
+                    None => &source[t.byte_range()],
+                });
+                if let (true, Some(type_name)) = (is_unsafe, type_name) {
+                    tags.push((type_name.to_string(), node.start_position().row + 1, BTreeSet::from(["unsafe_impl"])));
+                }
+            }
+            _ => {}
+        }
+    }
+
+    for entity in entities.iter_mut() {
+        let kinds: BTreeSet<&str> = tags
+            .iter()
+            .filter(|(name, line, _)| *name == entity.name && *line == entity.line_range.0)
+            .flat_map(|(_, _, kinds)| kinds.iter().copied())
+            .collect();
+        if !kinds.is_empty() && matches!(entity.entity_type, EntityType::Function | EntityType::Method | EntityType::Impl) {
+            entity.metadata.insert(BOUNDARY_FACET_METADATA_KEY.to_string(), kinds.into_iter().collect::<Vec<_>>().join(","));
+        }
+    }
+    entities.extend(collect_rust_foreign_function_entities(root, source, file_path));
+}
+
+/// `unsafe { }` anywhere below `node`
+fn contains_rust_unsafe_block(node: tree_sitter::Node<'_>) -> bool {
+    let mut stack = vec![node];
+    while let Some(current) = stack.pop() {
+        if current.kind() == "unsafe_block" {
+            return true;
+        }
+        let mut cursor = current.walk();
+        stack.extend(current.named_children(&mut cursor));
+    }
+    false
+}
+
+/// Function declarations of `extern "C" { ... }` blocks
+fn collect_rust_foreign_function_entities(root: tree_sitter::Node<'_>, source: &str, file_path: &Path) -> Vec<ParsedEntity> {
+    let mut entities = Vec::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        if node.kind() != "foreign_mod_item" {
+            let mut cursor = node.walk();
+            stack.extend(node.named_children(&mut cursor));
+            continue;
+        }
+        let Some(body) = node.child_by_field_name("body") else { continue };
+        let mut cursor = body.walk();
+        for item in body.named_children(&mut cursor).filter(|i| i.kind() == "function_signature_item") {
+            let Some(name) = item.child_by_field_name("name") else { continue };
+            entities.push(ParsedEntity {
+                entity_type: EntityType::Function,
+                name: source[name.byte_range()].to_string(),
+                language: Language::Rust,
+                line_range: (item.start_position().row + 1, item.end_position().row + 1),
+                file_path: file_path.to_string_lossy().to_string(),
+                metadata: HashMap::from([(BOUNDARY_FACET_METADATA_KEY.to_string(), FFI_IMPORT_BOUNDARY_KIND.to_string())]),
+            });
+        }
+    }
+    entities
+}
+
+/// Tag Go functions that use `unsafe` or cgo
+///
+/// # 4-Word Name: tag_go_safety_boundary_entities
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of the file at `root`
+/// - Postcondition: Function/Method entities using package `unsafe`,
+///   calling `C.*` (with `import "C"`) or marked `//export` carry
+///   `boundary`; closures are credited to their declaration
+pub fn tag_go_safety_boundary_entities(root: tree_sitter::Node<'_>, source: &str, entities: &mut [ParsedEntity]) {
+    let table = collect_go_file_import_table(root, source);
+    let alias_of = |path: &str| table.aliases.iter().find(|(_, p)| p.as_str() == path).map(|(a, _)| a.clone());
+    let unsafe_alias = alias_of("unsafe");
+    let cgo_alias = alias_of("C");
+
+    let mut tags: BTreeMap<String, BTreeSet<&'static str>> = BTreeMap::new();
+    let mut cursor = root.walk();
+    let declarations: Vec<_> = root.named_children(&mut cursor).collect();
+    for (index, declaration) in declarations.iter().enumerate() {
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let Some((_, key)) = find_go_declaration_entity_key(*declaration, source, entities) else {
+            continue;
+        };
+        let mut kinds = BTreeSet::new();
+        let exported = index
+            .checked_sub(1)
+            .map(|previous| declarations[previous])
+            .filter(|previous| previous.kind() == "comment" && previous.end_position().row + 1 == declaration.start_position().row)
+            .is_some_and(|comment| source[comment.byte_range()].starts_with("//export "));
+        if exported {
+            kinds.insert("cgo_export");
+        }
+        let mut stack = vec![*declaration];
+        while let Some(node) = stack.pop() {
+            if node.kind() == "selector_expression" {
+                let operand = node
+                    .child_by_field_name("operand")
+                    .filter(|o| o.kind() == "identifier")
+                    .map(|o| &source[o.byte_range()]);
+                if operand.is_some() && operand == unsafe_alias.as_deref() {
+                    kinds.insert("go_unsafe");
+                }
+                if operand.is_some() && operand == cgo_alias.as_deref() {
+                    kinds.insert("cgo");
+                }
+            }
+            let mut child_cursor = node.walk();
+            stack.extend(node.named_children(&mut child_cursor));
+        }
+        if !kinds.is_empty() {
+            tags.insert(key, kinds);
+        }
+    }
+
+    for entity in entities.iter_mut() {
+        if let Some(kinds) = tags.get(&format_go_parsed_entity_key(entity)) {
+            entity.metadata.insert(
+                BOUNDARY_FACET_METADATA_KEY.to_string(),
+                kinds.iter().copied().collect::<Vec<_>>().join(","),
+            );
+        }
+    }
+}
+
+/// Shortest call path from `entry_key` to every boundary symbol it reaches
+///
+/// # 4-Word Name: find_safety_boundary_paths
+///
+/// # Contract
+/// - Precondition: `entities` carry extractor metadata (`boundary`)
+/// - Postcondition: one entry per boundary symbol within `max_depth` call
+///   hops (the entry itself included when tagged), following Calls/Spawns;
+///   sorted by path length, then boundary key
+/// - Performance: O(V + E)
+pub fn find_safety_boundary_paths(
+    entry_key: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    max_depth: usize,
+) -> Vec<BoundaryCrossingPathEntry> {
+    let boundary_of: HashMap<&str, &str> = entities
+        .iter()
+        .filter_map(|e| {
+            e.metadata.additional.get(BOUNDARY_FACET_METADATA_KEY).map(|kinds| (e.isgl1_key.as_str(), kinds.as_str()))
+        })
+        .collect();
+    // (language, name) → entities, for placeholder call targets
+    let mut by_language_name: HashMap<(&str, &str), Vec<&str>> = HashMap::new();
+    for entity in entities {
+        if let Some(language) = entity.isgl1_key.split(':').next() {
+            by_language_name
+                .entry((language, entity.interface_signature.name.as_str()))
+                .or_default()
+                .push(entity.isgl1_key.as_str());
+        }
+    }
+    let mut callees: HashMap<&str, BTreeSet<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)) {
+        let to_key = edge.to_key.as_str();
+        let targets = callees.entry(edge.from_key.as_str()).or_default();
+        match (parse_unresolved_reference_name(to_key), to_key.split(':').next()) {
+            (Some(name), Some(language)) => {
+                targets.extend(by_language_name.get(&(language, name)).into_iter().flatten().copied());
+            }
+            _ => {
+                targets.insert(to_key);
+            }
+        }
+    }
+
+    let mut parents: HashMap<&str, Option<&str>> = HashMap::from([(entry_key, None)]);
+    let mut queue = VecDeque::from([(entry_key, 0usize)]);
+    let mut paths = Vec::new();
+    while let Some((key, depth)) = queue.pop_front() {
+        if let Some(kinds) = boundary_of.get(key) {
+            let mut path = vec![key.to_string()];
+            let mut current = key;
+            while let Some(&Some(parent)) = parents.get(current) {
+                path.push(parent.to_string());
+                current = parent;
+            }
+            path.reverse();
+            paths.push(BoundaryCrossingPathEntry {
+                boundary_key: key.to_string(),
+                boundary_kinds: kinds.split(',').map(str::to_string).collect(),
+                path,
+            });
+        }
+        if depth >= max_depth {
+            continue;
+        }
+        for &callee in callees.get(key).into_iter().flatten() {
+            if !parents.contains_key(callee) {
+                parents.insert(callee, Some(key));
+                queue.push_back((callee, depth + 1));
+            }
+        }
+    }
+    paths.sort_by(|a, b| a.path.len().cmp(&b.path.len()).then_with(|| a.boundary_key.cmp(&b.boundary_key)));
+    paths
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_rust_and_go_boundaries_tagged_and_reached() {
+        let rust = r#"
+extern "C" {
+    fn strlen(s: *const u8) -> usize;
+}
+
+pub fn length(s: &[u8]) -> usize {
+    unsafe { strlen(s.as_ptr()) }
+}
+
+pub extern "C" fn exported_hook() {}
+
+pub fn entry(s: &[u8]) -> usize {
+    length(s)
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, edges) = extractor.parse_source(rust, Path::new("src/ffi.rs"), Language::Rust).unwrap();
+        let boundary = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(BOUNDARY_FACET_METADATA_KEY))
+                .map(String::as_str)
+        };
+        assert_eq!(boundary("strlen"), Some("ffi_import"));
+        assert_eq!(boundary("length"), Some("unsafe_block"));
+        assert_eq!(boundary("exported_hook"), Some("extern_fn"));
+        assert_eq!(boundary("entry"), None);
+
+        let go = r#"
+package mem
+
+// #include <string.h>
+import "C"
+import "unsafe"
+
+func Len(p *byte) int { return int(C.strlen((*C.char)(unsafe.Pointer(p)))) }
+
+//export Hook
+func Hook() {}
+"#;
+        let (go_entities, _) = extractor.parse_source(go, Path::new("mem/len.go"), Language::Go).unwrap();
+        let go_boundary = |name: &str| {
+            go_entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(BOUNDARY_FACET_METADATA_KEY))
+                .map(String::as_str)
+        };
+        assert_eq!(go_boundary("Len"), Some("cgo,go_unsafe"));
+        assert_eq!(go_boundary("Hook"), Some("cgo_export"));
+
+        // entry → length (unsafe block) → strlen (placeholder → ffi import)
+        let code_entities: Vec<CodeEntity> = entities
+            .iter()
+            .map(|e| {
+                let key = format!(
+                    "rust:fn:{}:{}:T{}",
+                    e.name,
+                    crate::isgl1_v2::extract_semantic_path(&e.file_path),
+                    crate::isgl1_v2::compute_birth_timestamp(&e.file_path, &e.name)
+                );
+                let metadata: Vec<(&str, &str)> = e.metadata.iter().map(|(k, v)| (k.as_str(), v.as_str())).collect();
+                crate::go_embedding_promotion_resolver::create_go_test_entity(
+                    &key,
+                    &e.name,
+                    crate::entities::EntityType::Function,
+                    "src/ffi.rs",
+                    &metadata,
+                )
+            })
+            .collect();
+        let entry = code_entities.iter().find(|e| e.interface_signature.name == "entry").unwrap();
+        let paths = find_safety_boundary_paths(&entry.isgl1_key, &code_entities, &edges, 5);
+        let names: Vec<Vec<&str>> = paths
+            .iter()
+            .map(|p| p.path.iter().map(|k| k.split(':').nth(2).unwrap()).collect())
+            .collect();
+        assert_eq!(names, vec![vec!["entry", "length"], vec!["entry", "length", "strlen"]]);
+    }
+
+    #[test]
+    fn test_unsafe_combinations_and_go_directive_guards() {
+        let rust = r#"
+pub struct Buffer(*mut u8);
+
+unsafe impl Send for Buffer {}
+
+impl Buffer {
+    pub unsafe fn raw(&self) -> *mut u8 {
+        unsafe { self.0 }
+    }
+}
+
+pub unsafe fn free(p: *mut u8) {}
+
+pub fn safe() {}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (entities, _) = extractor.parse_source(rust, Path::new("src/buffer.rs"), Language::Rust).unwrap();
+        let boundary = |name: &str| {
+            entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(BOUNDARY_FACET_METADATA_KEY))
+                .map(String::as_str)
+        };
+        assert_eq!(boundary("raw"), Some("unsafe_block,unsafe_fn"));
+        assert_eq!(boundary("free"), Some("unsafe_fn"));
+        assert_eq!(boundary("safe"), None);
+        // Only the `unsafe impl` block is tagged, not the inherent impl of the same type
+        let mut impls: Vec<Option<&str>> = entities
+            .iter()
+            .filter(|e| e.name == "Buffer" && e.entity_type == EntityType::Impl)
+            .map(|e| e.metadata.get(BOUNDARY_FACET_METADATA_KEY).map(String::as_str))
+            .collect();
+        impls.sort();
+        assert_eq!(impls, vec![None, Some("unsafe_impl")]);
+
+        let go = r#"
+package mem
+
+import u "unsafe"
+
+// export is not a cgo directive
+func Plain() {}
+
+//export Orphan
+
+func Gap() {}
+
+func Peek(p *int) uintptr {
+    f := func() uintptr { return u.Sizeof(*p) }
+    return f()
+}
+
+func NotCgo(C Config) { C.Load() }
+"#;
+        let (go_entities, _) = extractor.parse_source(go, Path::new("mem/peek.go"), Language::Go).unwrap();
+        let go_boundary = |name: &str| {
+            go_entities
+                .iter()
+                .find(|e| e.name == name)
+                .and_then(|e| e.metadata.get(BOUNDARY_FACET_METADATA_KEY))
+                .map(String::as_str)
+        };
+        // `//export` must be exact and directly above the function
+        assert_eq!(go_boundary("Plain"), None);
+        assert_eq!(go_boundary("Gap"), None);
+        // Aliased `unsafe` counts; the closure's use is credited to Peek
+        assert_eq!(go_boundary("Peek"), Some("go_unsafe"));
+        assert_eq!(go_boundary("Peek.func1"), None);
+        // Without `import "C"`, a variable named C is not cgo
+        assert_eq!(go_boundary("NotCgo"), None);
+    }
+
+    #[test]
+    fn test_boundary_paths_follow_calls_and_spawns_only() {
+        let entity = |name: &str, kinds: Option<&str>| {
+            let metadata: Vec<(&str, &str)> = kinds.map(|k| (BOUNDARY_FACET_METADATA_KEY, k)).into_iter().collect();
+            crate::go_embedding_promotion_resolver::create_go_test_entity(
+                &format!("go:fn:{}:__p:T1", name),
+                name,
+                crate::entities::EntityType::Function,
+                "p/p.go",
+                &metadata,
+            )
+        };
+        let entities = vec![
+            entity("a", Some("go_unsafe")),
+            entity("b", None),
+            entity("c", Some("cgo,go_unsafe")),
+            entity("d", Some("ffi_import")),
+        ];
+        let edge = |from: &str, to: &str, edge_type: EdgeType| {
+            DependencyEdge::builder()
+                .from_key(format!("go:fn:{}:__p:T1", from))
+                .to_key(format!("go:fn:{}:__p:T1", to))
+                .edge_type(edge_type)
+                .build()
+                .unwrap()
+        };
+        let edges = vec![
+            edge("a", "b", EdgeType::Calls),
+            edge("b", "a", EdgeType::Calls),
+            edge("b", "c", EdgeType::Spawns),
+            edge("a", "d", EdgeType::Uses),
+        ];
+
+        // A tagged entry is its own one-hop path; cycles end; Uses is not a call
+        let paths = find_safety_boundary_paths("go:fn:a:__p:T1", &entities, &edges, 5);
+        let names: Vec<Vec<&str>> = paths
+            .iter()
+            .map(|p| p.path.iter().map(|k| k.split(':').nth(2).unwrap()).collect())
+            .collect();
+        assert_eq!(names, vec![vec!["a"], vec!["a", "b", "c"]]);
+        assert_eq!(paths[1].boundary_kinds, vec!["cgo".to_string(), "go_unsafe".to_string()]);
+
+        assert_eq!(find_safety_boundary_paths("go:fn:a:__p:T1", &entities, &edges, 1).len(), 1);
+        assert!(find_safety_boundary_paths("go:fn:missing:__p:T1", &entities, &edges, 5).is_empty());
+    }
+}
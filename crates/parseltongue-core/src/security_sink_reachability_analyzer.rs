@@ -0,0 +1,693 @@
+//! Security Sink Reachability Analyzer (v1.7.3)
+//!
+//! # 4-Word Naming: security_sink_reachability_analyzer
+//!
+//! A taint-lite briefing for `parseltongue analyze sinks`: find the calls
+//! that turn input into damage, then show which HTTP routes and gRPC
+//! methods reach them through the call graph. No data flow is tracked; a
+//! reported path says "a request can get here", and the snippet lets the
+//! reader (or the model) judge whether request data does too.
+//!
+//! ## Sinks
+//!
+//! Function and method bodies are scanned per language (comments and test
+//! code skipped, definitions such as `def eval(` ignored):
+//!
+//! - `command-exec`: `exec.Command`, `Command::new`, `subprocess.*`,
+//!   `os.system`, `child_process.*`, `Runtime.getRuntime().exec`,
+//!   `ProcessBuilder`, `Process.Start`, `system`/`popen`, ...
+//! - `sql-concat`: a query call (`.Query`, `.Exec`, `.execute`,
+//!   `.executeQuery`, `sqlx::query`, `.raw`, ...) whose SQL is built by
+//!   concatenation or formatting (`+`, `Sprintf`, `format!`, f-strings,
+//!   `.format`, `%`, template literals), directly in the argument or in a
+//!   same-function assignment to the variable passed. Placeholder queries
+//!   (`$1`, `?`, `%s` with parameters) are not reported
+//! - `unsafe-deserialization`: `pickle.loads`, `yaml.load` without a safe
+//!   loader, `ObjectInputStream`, `.readObject`, `unserialize`,
+//!   `Marshal.load`, `BinaryFormatter`, ...
+//! - `code-eval`: `eval`, `exec` (Python), `new Function`, `instance_eval`
+//!
+//! A sink inside a closure is reported once, on the innermost symbol.
+//!
+//! ## Entry points
+//!
+//! `Route` nodes (`POST /users`, see `http_route_handler_mapper`) and rpc
+//! nodes (`proto:method:...`, see `protobuf_service_definition_linker`).
+//! Paths follow `Calls` and `Spawns` edges backwards from each sink, up to
+//! `max_depth` hops, and stop at the first entry point on each branch.
+
+use std::collections::{HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType};
+use crate::protobuf_service_definition_linker::RPC_PATH_METADATA_KEY;
+
+/// Hops followed back from a sink when no `--max-depth` is given
+pub const DEFAULT_SINK_PATH_MAX_DEPTH: usize = 12;
+
+/// Longest call argument text inspected
+const MAX_SINK_ARGUMENT_CHARS: usize = 600;
+
+/// Longest snippet stored per finding
+const MAX_SINK_SNIPPET_CHARS: usize = 160;
+
+/// Kind of dangerous operation
+///
+/// # 4-Word Name: SecuritySinkCategory
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize)]
+pub enum SecuritySinkCategory {
+    #[serde(rename = "command-exec")]
+    CommandExecution,
+    #[serde(rename = "sql-concat")]
+    SqlConcatenation,
+    #[serde(rename = "unsafe-deserialization")]
+    UnsafeDeserialization,
+    #[serde(rename = "code-eval")]
+    CodeEvaluation,
+}
+
+impl SecuritySinkCategory {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::CommandExecution => "command-exec",
+            Self::SqlConcatenation => "sql-concat",
+            Self::UnsafeDeserialization => "unsafe-deserialization",
+            Self::CodeEvaluation => "code-eval",
+        }
+    }
+
+    /// Parse a `--category` value
+    pub fn parse_sink_category_name(value: &str) -> Option<Self> {
+        [Self::CommandExecution, Self::SqlConcatenation, Self::UnsafeDeserialization, Self::CodeEvaluation]
+            .into_iter()
+            .find(|category| category.as_str() == value.trim())
+    }
+}
+
+/// One known sink call of some languages
+struct SinkCallPattern {
+    languages: &'static [&'static str],
+    /// Call text up to and including `(`; a leading `.` matches any receiver
+    needle: &'static str,
+    category: SecuritySinkCategory,
+}
+
+const JS_LANGUAGES: &[&str] = &["javascript", "typescript"];
+const JVM_LANGUAGES: &[&str] = &["java", "kotlin", "scala"];
+const C_LANGUAGES: &[&str] = &["c", "cpp"];
+
+const SINK_CALL_PATTERN_LIST: &[SinkCallPattern] = {
+    use SecuritySinkCategory::{CodeEvaluation as Eval, CommandExecution as Exec, UnsafeDeserialization as Deser};
+    &[
+        SinkCallPattern { languages: &["go"], needle: "exec.Command(", category: Exec },
+        SinkCallPattern { languages: &["go"], needle: "exec.CommandContext(", category: Exec },
+        SinkCallPattern { languages: &["go"], needle: "syscall.Exec(", category: Exec },
+        SinkCallPattern { languages: &["rust"], needle: "Command::new(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "os.system(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "os.popen(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "subprocess.run(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "subprocess.call(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "subprocess.Popen(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "subprocess.check_call(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "subprocess.check_output(", category: Exec },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "child_process.exec(", category: Exec },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "child_process.spawn(", category: Exec },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "execSync(", category: Exec },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "spawnSync(", category: Exec },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: "Runtime.getRuntime().exec(", category: Exec },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: "ProcessBuilder(", category: Exec },
+        SinkCallPattern { languages: &["csharp"], needle: "Process.Start(", category: Exec },
+        SinkCallPattern { languages: &["ruby"], needle: "system(", category: Exec },
+        SinkCallPattern { languages: &["ruby"], needle: "IO.popen(", category: Exec },
+        SinkCallPattern { languages: &["ruby"], needle: "Open3.capture3(", category: Exec },
+        SinkCallPattern { languages: &["ruby"], needle: "Open3.popen3(", category: Exec },
+        SinkCallPattern { languages: &["php"], needle: "shell_exec(", category: Exec },
+        SinkCallPattern { languages: &["php"], needle: "system(", category: Exec },
+        SinkCallPattern { languages: &["php"], needle: "passthru(", category: Exec },
+        SinkCallPattern { languages: &["php"], needle: "proc_open(", category: Exec },
+        SinkCallPattern { languages: C_LANGUAGES, needle: "system(", category: Exec },
+        SinkCallPattern { languages: C_LANGUAGES, needle: "popen(", category: Exec },
+        SinkCallPattern { languages: C_LANGUAGES, needle: "execvp(", category: Exec },
+        SinkCallPattern { languages: &["python"], needle: "pickle.loads(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "pickle.load(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "marshal.loads(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "yaml.load(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "yaml.unsafe_load(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "jsonpickle.decode(", category: Deser },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: "ObjectInputStream(", category: Deser },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: ".readObject(", category: Deser },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: "XMLDecoder(", category: Deser },
+        SinkCallPattern { languages: JVM_LANGUAGES, needle: ".fromXML(", category: Deser },
+        SinkCallPattern { languages: &["php"], needle: "unserialize(", category: Deser },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "unserialize(", category: Deser },
+        SinkCallPattern { languages: &["ruby"], needle: "Marshal.load(", category: Deser },
+        SinkCallPattern { languages: &["ruby"], needle: "YAML.load(", category: Deser },
+        SinkCallPattern { languages: &["csharp"], needle: "BinaryFormatter(", category: Deser },
+        SinkCallPattern { languages: &["csharp"], needle: "NetDataContractSerializer(", category: Deser },
+        SinkCallPattern { languages: &["python"], needle: "eval(", category: Eval },
+        SinkCallPattern { languages: &["python"], needle: "exec(", category: Eval },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "eval(", category: Eval },
+        SinkCallPattern { languages: JS_LANGUAGES, needle: "new Function(", category: Eval },
+        SinkCallPattern { languages: &["php"], needle: "eval(", category: Eval },
+        SinkCallPattern { languages: &["ruby"], needle: "eval(", category: Eval },
+        SinkCallPattern { languages: &["ruby"], needle: "instance_eval(", category: Eval },
+    ]
+};
+
+/// Calls that run SQL text given as their first argument
+const SQL_EXECUTION_CALL_NEEDLES: &[&str] = &[
+    ".Query(", ".QueryRow(", ".QueryContext(", ".QueryRowContext(", ".Exec(", ".ExecContext(", ".Prepare(",
+    ".Raw(", ".execute(", ".executemany(", ".executescript(", ".executeQuery(", ".executeUpdate(",
+    ".prepareStatement(", ".createQuery(", ".createNativeQuery(", ".raw(", ".query(", "sqlx::query(",
+    "sqlx::query_as(", "sql_query(",
+];
+
+/// Loader arguments that make `yaml.load` / `YAML.load` safe
+const SAFE_YAML_LOADER_MARKERS: &[&str] = &["SafeLoader", "CSafeLoader", "safe_load", "permitted_classes"];
+
+/// One dangerous call inside a symbol
+///
+/// # 4-Word Name: SecuritySinkSiteEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SecuritySinkSiteEntry {
+    pub category: SecuritySinkCategory,
+    /// Function or method containing the call
+    pub sink_key: String,
+    /// Matched call (`exec.Command`, `.Query`)
+    pub call: String,
+    /// `file:line`
+    pub location: Option<String>,
+    /// The source line, trimmed
+    pub snippet: String,
+}
+
+/// A path from one entry point to a sink
+///
+/// # 4-Word Name: SinkEntryPointPathEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SinkEntryPointPathEntry {
+    pub entry_key: String,
+    /// `POST /users`, or the rpc path
+    pub entry_label: String,
+    /// Keys from the entry point to the sink symbol, both included
+    pub path: Vec<String>,
+}
+
+/// A sink site with the entry points that reach it
+///
+/// # 4-Word Name: SecuritySinkFindingEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SecuritySinkFindingEntry {
+    #[serde(flatten)]
+    pub site: SecuritySinkSiteEntry,
+    /// Shortest path from each entry point, shortest first
+    pub reachable_from: Vec<SinkEntryPointPathEntry>,
+}
+
+/// Find every sink call in production functions and methods
+///
+/// # 4-Word Name: find_security_sink_sites
+///
+/// # Contract
+/// - Postcondition: test code and external placeholders are skipped; a
+///   call seen in nested symbols is kept on the one with the smallest line
+///   range; sorted by location
+pub fn find_security_sink_sites(entities: &[CodeEntity]) -> Vec<SecuritySinkSiteEntry> {
+    // (file, line, call) -> (line span, site)
+    let mut innermost: HashMap<(String, usize, String), (u32, SecuritySinkSiteEntry)> = HashMap::new();
+
+    for entity in entities {
+        let signature = &entity.interface_signature;
+        if !matches!(signature.entity_type, EntityType::Function | EntityType::Method)
+            || entity.entity_class == EntityClass::TestImplementation
+        {
+            continue;
+        }
+        let Some(code) = entity.current_code.as_deref() else {
+            continue;
+        };
+        let language = entity.isgl1_key.split(':').next().unwrap_or_default();
+        let file = signature.file_path.to_string_lossy().to_string();
+        let span = signature.line_range.end.saturating_sub(signature.line_range.start);
+
+        for (category, call, offset) in scan_code_for_sink_calls(code, language) {
+            let line_index = code[..offset].matches('\n').count();
+            let line = signature.line_range.start as usize + line_index;
+            let snippet: String = code.lines().nth(line_index).unwrap_or_default().trim().chars().take(MAX_SINK_SNIPPET_CHARS).collect();
+            let site = SecuritySinkSiteEntry {
+                category,
+                sink_key: entity.isgl1_key.clone(),
+                call: call.clone(),
+                location: (!file.is_empty()).then(|| format!("{}:{}", file, line)),
+                snippet,
+            };
+            match innermost.get(&(file.clone(), line, call.clone())) {
+                Some((existing, _)) if *existing <= span => {}
+                _ => {
+                    innermost.insert((file.clone(), line, call), (span, site));
+                }
+            }
+        }
+    }
+
+    let mut sites: Vec<SecuritySinkSiteEntry> = innermost.into_values().map(|(_, site)| site).collect();
+    sites.sort_by(|a, b| a.location.cmp(&b.location).then_with(|| a.sink_key.cmp(&b.sink_key)));
+    sites
+}
+
+/// Find sinks and the HTTP/RPC entry points that reach them
+///
+/// # 4-Word Name: analyze_security_sink_reachability
+///
+/// # Contract
+/// - Postcondition: one finding per sink site, reachable ones first (by
+///   shortest path), then unreachable ones by location
+pub fn analyze_security_sink_reachability(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    max_depth: usize,
+) -> Vec<SecuritySinkFindingEntry> {
+    let entry_labels: HashMap<&str, String> = entities
+        .iter()
+        .filter_map(|e| {
+            if e.interface_signature.entity_type == EntityType::Route {
+                Some((e.isgl1_key.as_str(), e.interface_signature.name.clone()))
+            } else if e.isgl1_key.starts_with("proto:method:") {
+                let label = e.metadata.additional.get(RPC_PATH_METADATA_KEY).cloned();
+                Some((e.isgl1_key.as_str(), label.unwrap_or_else(|| e.interface_signature.name.clone())))
+            } else {
+                None
+            }
+        })
+        .collect();
+
+    let mut callers: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| matches!(e.edge_type, EdgeType::Calls | EdgeType::Spawns)) {
+        callers.entry(edge.to_key.as_str()).or_default().push(edge.from_key.as_str());
+    }
+
+    let mut findings: Vec<SecuritySinkFindingEntry> = find_security_sink_sites(entities)
+        .into_iter()
+        .map(|site| {
+            let reachable_from = trace_entry_points_to_sink(&site.sink_key, &callers, &entry_labels, max_depth);
+            SecuritySinkFindingEntry { site, reachable_from }
+        })
+        .collect();
+
+    let shortest = |f: &SecuritySinkFindingEntry| f.reachable_from.first().map(|p| p.path.len()).unwrap_or(usize::MAX);
+    findings.sort_by(|a, b| {
+        shortest(a)
+            .cmp(&shortest(b))
+            .then_with(|| a.site.location.cmp(&b.site.location))
+            .then_with(|| a.site.sink_key.cmp(&b.site.sink_key))
+    });
+    findings
+}
+
+/// Breadth-first walk from a sink back to entry points
+fn trace_entry_points_to_sink(
+    sink_key: &str,
+    callers: &HashMap<&str, Vec<&str>>,
+    entry_labels: &HashMap<&str, String>,
+    max_depth: usize,
+) -> Vec<SinkEntryPointPathEntry> {
+    // node -> next node toward the sink
+    let mut next_hop: HashMap<&str, &str> = HashMap::new();
+    let mut visited: HashSet<&str> = HashSet::from([sink_key]);
+    let mut queue: VecDeque<(&str, usize)> = VecDeque::from([(sink_key, 0)]);
+    let mut reached: Vec<&str> = Vec::new();
+
+    while let Some((node, depth)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        for &caller in callers.get(node).map(Vec::as_slice).unwrap_or_default() {
+            if !visited.insert(caller) {
+                continue;
+            }
+            next_hop.insert(caller, node);
+            if entry_labels.contains_key(caller) {
+                reached.push(caller);
+            } else {
+                queue.push_back((caller, depth + 1));
+            }
+        }
+    }
+
+    reached
+        .into_iter()
+        .map(|entry| {
+            let mut path = vec![entry.to_string()];
+            let mut node = entry;
+            while let Some(&next) = next_hop.get(node) {
+                path.push(next.to_string());
+                node = next;
+            }
+            SinkEntryPointPathEntry {
+                entry_key: entry.to_string(),
+                entry_label: entry_labels[entry].clone(),
+                path,
+            }
+        })
+        .collect()
+}
+
+/// Sink calls of one body: (category, call, byte offset)
+fn scan_code_for_sink_calls(code: &str, language: &str) -> Vec<(SecuritySinkCategory, String, usize)> {
+    let mut found = Vec::new();
+    for pattern in SINK_CALL_PATTERN_LIST.iter().filter(|p| p.languages.contains(&language)) {
+        for offset in find_sink_call_offsets(code, pattern.needle) {
+            if pattern.needle.ends_with("load(") && pattern.needle.to_ascii_lowercase().starts_with("yaml") {
+                let argument = extract_call_argument_text(code, offset + pattern.needle.len() - 1);
+                if SAFE_YAML_LOADER_MARKERS.iter().any(|marker| argument.contains(marker)) {
+                    continue;
+                }
+            }
+            found.push((pattern.category, pattern.needle.trim_end_matches('(').to_string(), offset));
+        }
+    }
+    for needle in SQL_EXECUTION_CALL_NEEDLES {
+        for offset in find_sink_call_offsets(code, needle) {
+            let argument = extract_call_argument_text(code, offset + needle.len() - 1);
+            if is_sql_built_dynamically(code, argument, language) {
+                found.push((SecuritySinkCategory::SqlConcatenation, needle.trim_end_matches('(').to_string(), offset));
+            }
+        }
+    }
+    found
+}
+
+/// Offsets of `needle` used as a call, outside comment lines and definitions
+fn find_sink_call_offsets(code: &str, needle: &str) -> Vec<usize> {
+    let mut offsets = Vec::new();
+    let mut search_from = 0;
+    while let Some(relative) = code[search_from..].find(needle) {
+        let offset = search_from + relative;
+        search_from = offset + needle.len();
+
+        let before = &code[..offset];
+        if !needle.starts_with('.') {
+            let previous = before.chars().next_back();
+            if previous.is_some_and(|c| c.is_alphanumeric() || matches!(c, '_' | '.' | '$' | '>')) {
+                continue;
+            }
+        }
+        let line_start = before.rfind('\n').map(|i| i + 1).unwrap_or(0);
+        let line_prefix = code[line_start..offset].trim_start();
+        let is_comment = ["//", "#", "*", "/*", "--"].iter().any(|marker| line_prefix.starts_with(marker));
+        let is_definition = ["def", "fn", "func", "function"]
+            .iter()
+            .any(|keyword| line_prefix.trim_end() == *keyword);
+        if !is_comment && !is_definition {
+            offsets.push(offset);
+        }
+    }
+    offsets
+}
+
+/// Text between the parentheses opened at `open_index`, strings respected
+fn extract_call_argument_text(code: &str, open_index: usize) -> &str {
+    let body = &code[open_index + 1..];
+    let mut depth = 0usize;
+    let mut quote: Option<char> = None;
+    let mut escaped = false;
+    for (index, c) in body.char_indices() {
+        if index >= MAX_SINK_ARGUMENT_CHARS {
+            return &body[..index];
+        }
+        if let Some(q) = quote {
+            if escaped {
+                escaped = false;
+            } else if c == '\\' {
+                escaped = true;
+            } else if c == q {
+                quote = None;
+            }
+            continue;
+        }
+        match c {
+            '"' | '\'' | '`' => quote = Some(c),
+            '(' | '[' | '{' => depth += 1,
+            ')' | ']' | '}' if depth == 0 => return &body[..index],
+            ')' | ']' | '}' => depth -= 1,
+            _ => {}
+        }
+    }
+    body
+}
+
+/// String literals and the code around them (literals replaced by `\u{1}`)
+struct ScannedExpressionParts {
+    literals: Vec<String>,
+    code: String,
+    /// An f-string, template literal or `#{}` literal with a placeholder
+    interpolated: bool,
+}
+
+fn scan_expression_string_literals(text: &str, language: &str) -> ScannedExpressionParts {
+    let mut parts = ScannedExpressionParts { literals: Vec::new(), code: String::new(), interpolated: false };
+    let mut chars = text.chars().peekable();
+    let mut previous: Option<char> = None;
+    while let Some(c) = chars.next() {
+        if !matches!(c, '"' | '\'' | '`') {
+            parts.code.push(c);
+            previous = Some(c);
+            continue;
+        }
+        let formatted_prefix = language == "python" && matches!(previous, Some('f' | 'F'));
+        let mut literal = String::new();
+        let mut escaped = false;
+        for inner in chars.by_ref() {
+            if escaped {
+                escaped = false;
+            } else if inner == '\\' {
+                escaped = true;
+            } else if inner == c {
+                break;
+            }
+            literal.push(inner);
+        }
+        let php_variable = language == "php"
+            && c == '"'
+            && literal
+                .split('$')
+                .skip(1)
+                .any(|rest| rest.starts_with(|ch: char| ch.is_alphabetic() || ch == '_'));
+        if (formatted_prefix && literal.contains('{'))
+            || (c == '`' && literal.contains("${"))
+            || literal.contains("#{")
+            || php_variable
+        {
+            parts.interpolated = true;
+        }
+        parts.literals.push(literal);
+        parts.code.push('\u{1}');
+        previous = Some('\u{1}');
+    }
+    parts
+}
+
+/// Whether a string literal reads like SQL
+fn literal_contains_sql_keywords(literal: &str) -> bool {
+    let upper = literal.to_ascii_uppercase();
+    (upper.contains("SELECT ") && upper.contains(" FROM"))
+        || upper.contains("INSERT INTO")
+        || (upper.contains("UPDATE ") && upper.contains(" SET "))
+        || upper.contains("DELETE FROM")
+        || upper.contains(" WHERE ")
+}
+
+/// Literal-bearing expression built by concatenation or formatting
+///
+/// Returns (built dynamically, has a SQL-looking literal).
+fn classify_sql_expression_text(text: &str, language: &str) -> (bool, bool) {
+    let parts = scan_expression_string_literals(text, language);
+    let has_literal = !parts.literals.is_empty();
+    let compact: String = parts.code.chars().filter(|c| !c.is_whitespace()).collect();
+    let concatenated = compact.contains('+')
+        || compact.contains("Sprintf(")
+        || compact.contains("format!(")
+        || compact.contains("\u{1}.format(")
+        || compact.contains("String.format(")
+        || compact.contains("\u{1}%")
+        || compact.contains("\u{1}.concat(")
+        || (language == "php" && (compact.contains("\u{1}.") || compact.contains(".\u{1}")));
+    let dynamic = parts.interpolated || (has_literal && concatenated);
+    (dynamic, parts.literals.iter().any(|l| literal_contains_sql_keywords(l)))
+}
+
+/// Whether the SQL passed to a query call is concatenated or formatted
+fn is_sql_built_dynamically(code: &str, argument: &str, language: &str) -> bool {
+    let first_argument = split_first_top_level_argument(argument);
+    let (dynamic, has_sql) = classify_sql_expression_text(first_argument, language);
+    if dynamic {
+        return has_sql;
+    }
+
+    // A variable: look at its assignments in the same body
+    let variable = first_argument.trim().trim_start_matches(['&', '*']).trim();
+    if variable.is_empty() || !variable.chars().all(|c| c.is_alphanumeric() || c == '_') {
+        return false;
+    }
+    let (mut any_dynamic, mut any_sql) = (false, false);
+    for right_hand_side in collect_variable_assignment_texts(code, variable) {
+        let (dynamic, has_sql) = classify_sql_expression_text(&right_hand_side, language);
+        any_dynamic |= dynamic;
+        any_sql |= has_sql;
+    }
+    any_dynamic && any_sql
+}
+
+/// Text before the first top-level comma, strings respected
+fn split_first_top_level_argument(argument: &str) -> &str {
+    let mut depth = 0usize;
+    let mut quote: Option<char> = None;
+    let mut escaped = false;
+    for (index, c) in argument.char_indices() {
+        if let Some(q) = quote {
+            if escaped {
+                escaped = false;
+            } else if c == '\\' {
+                escaped = true;
+            } else if c == q {
+                quote = None;
+            }
+            continue;
+        }
+        match c {
+            '"' | '\'' | '`' => quote = Some(c),
+            '(' | '[' | '{' => depth += 1,
+            ')' | ']' | '}' => depth = depth.saturating_sub(1),
+            ',' if depth == 0 => return &argument[..index],
+            _ => {}
+        }
+    }
+    argument
+}
+
+/// Right-hand sides of `name =`, `name :=`, `name +=` in a body
+///
+/// A side ending in `+`, `,` or `(` continues on the next line.
+fn collect_variable_assignment_texts(code: &str, name: &str) -> Vec<String> {
+    let lines: Vec<&str> = code.lines().collect();
+    let mut texts = Vec::new();
+    for (index, line) in lines.iter().enumerate() {
+        let mut search_from = 0;
+        while let Some(relative) = line[search_from..].find(name) {
+            let start = search_from + relative;
+            search_from = start + name.len();
+            let bounded_before = line[..start]
+                .chars()
+                .next_back()
+                .map_or(true, |c| !(c.is_alphanumeric() || c == '_' || c == '.'));
+            let rest = line[search_from..].trim_start();
+            let operator = [":=", "+=", "="]
+                .into_iter()
+                .find(|op| rest.starts_with(op) && !rest.starts_with("=="));
+            let (true, Some(operator)) = (bounded_before, operator) else {
+                continue;
+            };
+            let mut text = rest[operator.len()..].trim().to_string();
+            let mut next = index + 1;
+            while next < lines.len() && next <= index + 3 && text.ends_with(['+', ',', '(']) {
+                text.push(' ');
+                text.push_str(lines[next].trim());
+                next += 1;
+            }
+            texts.push(text);
+            break;
+        }
+    }
+    texts
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn go_function(key: &str, name: &str, code: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, EntityType::Function, "api/run.go", &[]);
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    fn calls(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_route_reaches_command_sink_through_handler() {
+        let route = create_go_test_entity("go:route:POST_/run:__api:T1", "POST /run", EntityType::Route, "api/routes.go", &[]);
+        let handler = go_function(
+            "go:fn:handleRun:__api:T2",
+            "handleRun",
+            "func handleRun(w http.ResponseWriter, r *http.Request) {\n    runScript(r.FormValue(\"cmd\"))\n}",
+        );
+        let runner = go_function(
+            "go:fn:runScript:__api:T3",
+            "runScript",
+            "func runScript(cmd string) error {\n    // exec.Command(\"never\") in a comment\n    return exec.Command(\"sh\", \"-c\", cmd).Run()\n}",
+        );
+        let edges = vec![
+            calls("go:route:POST_/run:__api:T1", "go:fn:handleRun:__api:T2"),
+            calls("go:fn:handleRun:__api:T2", "go:fn:runScript:__api:T3"),
+        ];
+
+        let entities = vec![route, handler, runner];
+
+        let findings = analyze_security_sink_reachability(&entities, &edges, DEFAULT_SINK_PATH_MAX_DEPTH);
+        assert_eq!(findings.len(), 1);
+        let finding = &findings[0];
+        assert_eq!(finding.site.category, SecuritySinkCategory::CommandExecution);
+        assert_eq!(finding.site.call, "exec.Command");
+        assert_eq!(finding.site.location.as_deref(), Some("api/run.go:3"));
+        assert_eq!(finding.reachable_from[0].entry_label, "POST /run");
+        assert_eq!(
+            finding.reachable_from[0].path,
+            vec!["go:route:POST_/run:__api:T1", "go:fn:handleRun:__api:T2", "go:fn:runScript:__api:T3"]
+        );
+
+        // One hop back reaches the handler but not the route
+        let shallow = analyze_security_sink_reachability(&entities, &edges, 1);
+        assert!(shallow[0].reachable_from.is_empty());
+    }
+
+    #[test]
+    fn test_sql_concat_and_eval_detection() {
+        let concatenated = go_function(
+            "go:fn:findUser:__db:T1",
+            "findUser",
+            "func findUser(db *sql.DB, name string) {\n    q := \"SELECT * FROM users WHERE name = '\" + name + \"'\"\n    db.Query(q)\n}",
+        );
+        let parameterized = go_function(
+            "go:fn:getUser:__db:T2",
+            "getUser",
+            "func getUser(db *sql.DB, id int) {\n    db.QueryRow(\"SELECT * FROM users WHERE id = $1\", id)\n}",
+        );
+        let formatted = go_function(
+            "go:fn:dropTable:__db:T3",
+            "dropTable",
+            "func dropTable(db *sql.DB, t string) {\n    db.Exec(fmt.Sprintf(\"DELETE FROM %s WHERE 1=1\", t))\n}",
+        );
+        let mut python = go_function(
+            "python:fn:score:__ml:T4",
+            "score",
+            "def score(model, expr):\n    model.eval()\n    cursor.execute(\"SELECT * FROM t WHERE id = %s\", (expr,))\n    return eval(expr)",
+        );
+        python.interface_signature.file_path = "ml/score.py".into();
+
+        let sites = find_security_sink_sites(&[concatenated, parameterized, formatted, python]);
+        let summary: Vec<(&str, SecuritySinkCategory, &str)> =
+            sites.iter().map(|s| (s.sink_key.as_str(), s.category, s.call.as_str())).collect();
+        assert_eq!(
+            summary,
+            vec![
+                ("go:fn:dropTable:__db:T3", SecuritySinkCategory::SqlConcatenation, ".Exec"),
+                ("go:fn:findUser:__db:T1", SecuritySinkCategory::SqlConcatenation, ".Query"),
+                ("python:fn:score:__ml:T4", SecuritySinkCategory::CodeEvaluation, "eval"),
+            ]
+        );
+    }
+}
@@ -0,0 +1,557 @@
+//! Symbol embeddings and semantic search (v1.7.3)
+//!
+//! # 4-Word Naming: semantic_symbol_embedding_index
+//!
+//! Each symbol's name, signature line and doc comment are embedded into a
+//! vector; `search` embeds the query with the same model, ranks symbols by
+//! cosine similarity and re-ranks the head of the list by graph proximity
+//! (a candidate whose callers/callees are also good matches moves up) and by
+//! centrality (of two similar matches, the architecturally central one wins).
+//!
+//! ## Embedding providers
+//!
+//! - Endpoint: any OpenAI-compatible `/v1/embeddings` API (Ollama
+//!   `nomic-embed-text`, vLLM, text-embeddings-inference, hosted APIs),
+//!   configured by URL and model. This is what makes search semantic:
+//!   "hash passwords" finds `DeriveKey` without sharing a word with it.
+//! - `hashed-words-v1`, the offline fallback: feature hashing over
+//!   identifier words (camelCase/snake_case split, lowercased, light suffix
+//!   stemming) plus character trigrams of each word. No model and no
+//!   network, but it is lexical: only symbols sharing words or word pieces
+//!   with the query match.
+//!
+//! The model name is stored with every vector; vectors from a different
+//! model are never compared, so switching providers means re-running
+//! `embed`.
+
+use std::collections::{HashMap, HashSet};
+use std::time::Duration;
+
+use serde_json::{json, Value};
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::isgl1_v2::compute_content_hash;
+use crate::outbound_http_post_client::{
+    parse_http_endpoint_url, post_json_body_blocking, HttpEndpointUrlParts, HttpPostResponseOutcome,
+};
+
+/// Name stored with every vector produced by `embed_text_hashed_vector`
+pub const HASHED_EMBEDDING_MODEL_NAME: &str = "hashed-words-v1";
+
+/// Vector length of the hashed model
+pub const HASHED_EMBEDDING_DIMENSION_COUNT: usize = 512;
+
+/// Texts sent per embeddings request
+pub const EMBEDDING_REQUEST_BATCH_SIZE: usize = 64;
+
+/// Attempts per batch on 429/502/503 before giving up
+const MAX_EMBEDDING_REQUEST_ATTEMPTS: u32 = 4;
+
+const EMBEDDING_REQUEST_TIMEOUT: Duration = Duration::from_secs(120);
+
+/// Candidates (by similarity) considered for graph re-ranking
+const GRAPH_RERANK_CANDIDATE_COUNT: usize = 50;
+
+/// Weight of the neighbor-similarity boost
+const GRAPH_PROXIMITY_BOOST_WEIGHT: f32 = 0.25;
+
+/// Weight of the centrality boost (importance 1.0 adds this much)
+const CENTRALITY_SEARCH_BOOST_WEIGHT: f32 = 0.1;
+
+/// Words too common in code and questions to carry meaning
+pub(crate) const EMBEDDING_STOP_WORD_LIST: &[&str] = &[
+    "a", "an", "and", "are", "as", "at", "be", "by", "do", "does", "for", "from", "how", "in", "is", "it",
+    "of", "on", "or", "the", "this", "to", "we", "what", "where", "which", "with", "fn", "func", "pub",
+    "self", "return", "returns",
+];
+
+/// Stored embedding of one symbol
+///
+/// # 4-Word Name: SymbolEmbeddingRecord
+#[derive(Debug, Clone, PartialEq)]
+pub struct SymbolEmbeddingRecord {
+    pub isgl1_key: String,
+    pub model: String,
+    /// Hash of the embedded text; unchanged input needs no re-embedding
+    pub input_hash: String,
+    pub vector: Vec<f32>,
+}
+
+/// OpenAI-compatible embeddings endpoint and model
+///
+/// # 4-Word Name: EmbeddingEndpointSpec
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct EmbeddingEndpointSpec {
+    /// `http(s)://host[:port]/path`, e.g. `http://localhost:11434/v1/embeddings`
+    pub url: String,
+    /// Sent with every request and stored with every vector
+    pub model: String,
+    /// Sent as `Authorization: Bearer ...` when set
+    pub api_key: Option<String>,
+}
+
+/// Where symbol and query vectors come from
+///
+/// # 4-Word Name: SymbolEmbeddingProviderSpec
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum SymbolEmbeddingProviderSpec {
+    /// A real embedding model behind an HTTP endpoint
+    Endpoint(EmbeddingEndpointSpec),
+    /// Offline `hashed-words-v1`: lexical, no network
+    HashedWordsFallback,
+}
+
+impl SymbolEmbeddingProviderSpec {
+    /// Model name stored with (and matched against) the vectors
+    ///
+    /// # 4-Word Name: embedding_model_name_value
+    pub fn embedding_model_name_value(&self) -> &str {
+        match self {
+            Self::Endpoint(endpoint) => &endpoint.model,
+            Self::HashedWordsFallback => HASHED_EMBEDDING_MODEL_NAME,
+        }
+    }
+
+    /// Embed texts, one vector per text in input order
+    ///
+    /// # 4-Word Name: embed_texts_with_provider
+    ///
+    /// # Contract
+    /// - Blocking for endpoints: call from a plain thread or `spawn_blocking`
+    /// - Postcondition: `texts.len()` vectors
+    pub fn embed_texts_with_provider(&self, texts: &[String]) -> Result<Vec<Vec<f32>>, String> {
+        match self {
+            Self::Endpoint(endpoint) => {
+                let mut vectors = Vec::with_capacity(texts.len());
+                for batch in texts.chunks(EMBEDDING_REQUEST_BATCH_SIZE) {
+                    vectors.extend(request_text_embeddings_blocking(endpoint, batch)?);
+                }
+                Ok(vectors)
+            }
+            Self::HashedWordsFallback => Ok(texts.iter().map(|text| embed_text_hashed_vector(text)).collect()),
+        }
+    }
+}
+
+/// One ranked search result
+///
+/// # 4-Word Name: SemanticSearchHit
+#[derive(Debug, Clone, PartialEq)]
+pub struct SemanticSearchHit {
+    pub isgl1_key: String,
+    /// Cosine similarity to the query
+    pub similarity: f32,
+    /// Added by graph proximity re-ranking
+    pub graph_boost: f32,
+    /// Added for the symbol's centrality importance
+    pub centrality_boost: f32,
+    pub score: f32,
+}
+
+/// Text embedded for an entity: name words, signature line, documentation
+///
+/// # 4-Word Name: build_embedding_input_text
+pub fn build_embedding_input_text(entity: &CodeEntity) -> String {
+    let signature = &entity.interface_signature;
+    let mut parts = vec![signature.name.clone()];
+    // The name is the strongest signal: repeat its words once more
+    parts.push(split_identifier_into_words(&signature.name).join(" "));
+    if let Some(first_line) = entity.current_code.as_deref().and_then(|c| c.lines().next()) {
+        parts.push(first_line.trim().to_string());
+    }
+    if let Some(doc) = signature.documentation.as_deref() {
+        parts.push(doc.to_string());
+    }
+    parts.join("\n")
+}
+
+/// Split text into lowercase words, breaking camelCase and snake_case
+///
+/// # 4-Word Name: split_identifier_into_words
+///
+/// # Contract
+/// - `hashPassword` → `hash`, `password`; `HTTPServer` → `http`, `server`
+/// - Digits stay attached to the preceding word
+pub fn split_identifier_into_words(text: &str) -> Vec<String> {
+    let mut words = Vec::new();
+    for raw in text.split(|c: char| !c.is_alphanumeric()) {
+        let chars: Vec<char> = raw.chars().collect();
+        let mut current = String::new();
+        for (i, &c) in chars.iter().enumerate() {
+            let prev = i.checked_sub(1).map(|p| chars[p]);
+            let next = chars.get(i + 1).copied();
+            let boundary = c.is_uppercase()
+                && prev.is_some_and(|p| {
+                    p.is_lowercase() || p.is_ascii_digit() || (p.is_uppercase() && next.is_some_and(char::is_lowercase))
+                });
+            if boundary && !current.is_empty() {
+                words.push(std::mem::take(&mut current).to_lowercase());
+            }
+            current.push(c);
+        }
+        if !current.is_empty() {
+            words.push(current.to_lowercase());
+        }
+    }
+    words
+}
+
+/// Strip common English suffixes so `passwords`/`hashing` match `password`/`hash`
+pub(crate) fn stem_word_light_suffix(word: &str) -> &str {
+    for suffix in ["ing", "ers", "er", "es", "ed", "s"] {
+        if let Some(stem) = word.strip_suffix(suffix) {
+            if stem.len() >= 3 && !(suffix == "s" && stem.ends_with('s')) {
+                return stem;
+            }
+        }
+    }
+    word
+}
+
+/// FNV-1a, 64-bit: stable across platforms and releases
+fn fnv1a_hash_bytes(bytes: &[u8]) -> u64 {
+    let mut hash = 0xcbf2_9ce4_8422_2325u64;
+    for byte in bytes {
+        hash ^= u64::from(*byte);
+        hash = hash.wrapping_mul(0x0100_0000_01b3);
+    }
+    hash
+}
+
+/// Embed text with the hashed-words model
+///
+/// # 4-Word Name: embed_text_hashed_vector
+///
+/// # Contract
+/// - Postcondition: length `HASHED_EMBEDDING_DIMENSION_COUNT`, L2 norm 1
+///   (or all zeros when the text has no meaningful words)
+/// - Deterministic: same text → same vector
+pub fn embed_text_hashed_vector(text: &str) -> Vec<f32> {
+    let mut vector = vec![0f32; HASHED_EMBEDDING_DIMENSION_COUNT];
+    let mut add_feature = |feature: &str, weight: f32| {
+        let hash = fnv1a_hash_bytes(feature.as_bytes());
+        let index = (hash % HASHED_EMBEDDING_DIMENSION_COUNT as u64) as usize;
+        // Signed hashing: colliding features tend to cancel rather than pile up
+        let sign = if hash >> 63 == 0 { 1.0 } else { -1.0 };
+        vector[index] += sign * weight;
+    };
+
+    for word in split_identifier_into_words(text) {
+        if word.len() < 2 || EMBEDDING_STOP_WORD_LIST.contains(&word.as_str()) {
+            continue;
+        }
+        let stem = stem_word_light_suffix(&word);
+        add_feature(&format!("w:{}", stem), 1.0);
+        let padded: Vec<char> = format!("^{}$", stem).chars().collect();
+        for trigram in padded.windows(3) {
+            add_feature(&format!("t:{}", trigram.iter().collect::<String>()), 0.3);
+        }
+    }
+
+    let norm = vector.iter().map(|v| v * v).sum::<f32>().sqrt();
+    if norm > 0.0 {
+        for value in &mut vector {
+            *value /= norm;
+        }
+    }
+    vector
+}
+
+/// Embedding record for an entity under the hashed model
+///
+/// # 4-Word Name: compute_symbol_embedding_record
+pub fn compute_symbol_embedding_record(entity: &CodeEntity) -> SymbolEmbeddingRecord {
+    let text = build_embedding_input_text(entity);
+    SymbolEmbeddingRecord {
+        isgl1_key: entity.isgl1_key.clone(),
+        model: HASHED_EMBEDDING_MODEL_NAME.to_string(),
+        input_hash: compute_content_hash(&text),
+        vector: embed_text_hashed_vector(&text),
+    }
+}
+
+/// Hash of the text embedded for an entity, whatever the model
+///
+/// # 4-Word Name: compute_embedding_input_hash
+pub fn compute_embedding_input_hash(entity: &CodeEntity) -> String {
+    compute_content_hash(&build_embedding_input_text(entity))
+}
+
+/// Parse an embeddings endpoint URL
+///
+/// # 4-Word Name: parse_embedding_endpoint_url
+///
+/// # Contract
+/// - Postcondition: `http://` or `https://`; a URL without a path gets
+///   `/v1/embeddings`
+/// - Error: other schemes, no host, invalid port
+pub fn parse_embedding_endpoint_url(url: &str) -> Result<HttpEndpointUrlParts, String> {
+    let mut address = parse_http_endpoint_url(url, "embedding endpoint")?;
+    if address.path == "/" {
+        address.path = "/v1/embeddings".to_string();
+    }
+    Ok(address)
+}
+
+/// POST one batch to an OpenAI-compatible embeddings endpoint
+///
+/// # 4-Word Name: request_text_embeddings_blocking
+///
+/// # Contract
+/// - Postcondition: one vector per text, in input order (answers are
+///   matched by their `index`), all of the same non-zero length
+/// - Error: transport failures, non-2xx answers other than the retried
+///   429/502/503, malformed JSON, a missing or ragged vector, or retries
+///   exhausted
+pub fn request_text_embeddings_blocking(
+    endpoint: &EmbeddingEndpointSpec,
+    texts: &[String],
+) -> Result<Vec<Vec<f32>>, String> {
+    if texts.is_empty() {
+        return Ok(Vec::new());
+    }
+    let address = parse_embedding_endpoint_url(&endpoint.url)?;
+    let authorization = endpoint.api_key.as_ref().map(|key| format!("Bearer {}", key));
+    let headers: Vec<(&str, &str)> = authorization.iter().map(|value| ("Authorization", value.as_str())).collect();
+    let body = json!({ "model": endpoint.model, "input": texts }).to_string();
+
+    let mut backoff = Duration::from_secs(2);
+    for attempt in 1..=MAX_EMBEDDING_REQUEST_ATTEMPTS {
+        let HttpPostResponseOutcome { status, retry_after, body: text } =
+            post_json_body_blocking(&address, &headers, body.clone().into_bytes(), EMBEDDING_REQUEST_TIMEOUT)
+                .map_err(|e| format!("embedding request to {} failed: {}", endpoint.url, e))?;
+        if matches!(status, 429 | 502 | 503) && attempt < MAX_EMBEDDING_REQUEST_ATTEMPTS {
+            std::thread::sleep(retry_after.unwrap_or(backoff));
+            backoff *= 2;
+            continue;
+        }
+        if !(200..300).contains(&status) {
+            let detail: String = text.chars().take(200).collect();
+            return Err(format!("{} answered HTTP {}: {}", endpoint.url, status, detail.trim()));
+        }
+        let reply: Value =
+            serde_json::from_str(&text).map_err(|e| format!("{} sent invalid JSON: {}", endpoint.url, e))?;
+        return read_embedding_vectors_from_reply(&reply, texts.len())
+            .map_err(|reason| format!("{}: {}", endpoint.url, reason));
+    }
+    Err(format!("{} kept rate limiting after {} attempts", endpoint.url, MAX_EMBEDDING_REQUEST_ATTEMPTS))
+}
+
+/// `data[].embedding` of an embeddings answer, ordered by `index`
+fn read_embedding_vectors_from_reply(reply: &Value, expected: usize) -> Result<Vec<Vec<f32>>, String> {
+    let data = reply.get("data").and_then(Value::as_array).ok_or("answer has no `data` list")?;
+    let mut vectors: Vec<Option<Vec<f32>>> = vec![None; expected];
+    for (position, item) in data.iter().enumerate() {
+        let index = item.get("index").and_then(Value::as_u64).map_or(position, |index| index as usize);
+        let values = item.get("embedding").and_then(Value::as_array).ok_or("answer item has no `embedding`")?;
+        let vector: Option<Vec<f32>> = values.iter().map(|v| v.as_f64().map(|v| v as f32)).collect();
+        let slot = vectors.get_mut(index).ok_or_else(|| format!("answer index {} out of range", index))?;
+        *slot = Some(vector.ok_or("embedding holds a non-number")?);
+    }
+    let vectors: Vec<Vec<f32>> = vectors
+        .into_iter()
+        .collect::<Option<_>>()
+        .ok_or_else(|| format!("answer has fewer than {} embeddings", expected))?;
+    let dimension = vectors.first().map_or(0, Vec::len);
+    if dimension == 0 || vectors.iter().any(|v| v.len() != dimension) {
+        return Err("embeddings are empty or of different lengths".to_string());
+    }
+    Ok(vectors)
+}
+
+/// Cosine similarity; 0 for mismatched lengths or zero vectors
+///
+/// # 4-Word Name: cosine_similarity_of_vectors
+pub fn cosine_similarity_of_vectors(a: &[f32], b: &[f32]) -> f32 {
+    if a.len() != b.len() {
+        return 0.0;
+    }
+    let dot: f32 = a.iter().zip(b).map(|(x, y)| x * y).sum();
+    let norm_a = a.iter().map(|v| v * v).sum::<f32>().sqrt();
+    let norm_b = b.iter().map(|v| v * v).sum::<f32>().sqrt();
+    if norm_a == 0.0 || norm_b == 0.0 {
+        0.0
+    } else {
+        dot / (norm_a * norm_b)
+    }
+}
+
+/// Nearest symbols to a query vector, re-ranked by graph proximity
+///
+/// # 4-Word Name: search_symbols_by_embedding
+///
+/// # Contract
+/// - Only records of `model` are compared
+/// - The top `GRAPH_RERANK_CANDIDATE_COUNT` by similarity get
+///   `boost = weight × mean similarity of their 1-hop neighbors among the
+///   candidates`, plus `centrality weight × importance` (missing keys get
+///   none); `score = similarity + boosts`
+/// - Postcondition: at most `limit` hits, score descending, ties by key
+pub fn search_symbols_by_embedding(
+    query_vector: &[f32],
+    model: &str,
+    records: &[SymbolEmbeddingRecord],
+    edges: &[DependencyEdge],
+    importance: &HashMap<String, f64>,
+    limit: usize,
+) -> Vec<SemanticSearchHit> {
+    let mut scored: Vec<(&str, f32)> = records
+        .iter()
+        .filter(|r| r.model == model)
+        .map(|r| (r.isgl1_key.as_str(), cosine_similarity_of_vectors(query_vector, &r.vector)))
+        .filter(|(_, similarity)| *similarity > 0.0)
+        .collect();
+    scored.sort_by(|a, b| b.1.total_cmp(&a.1).then_with(|| a.0.cmp(b.0)));
+    scored.truncate(GRAPH_RERANK_CANDIDATE_COUNT.max(limit));
+
+    let candidate_similarity: HashMap<&str, f32> = scored.iter().copied().collect();
+    let mut neighbors: HashMap<&str, HashSet<&str>> = HashMap::new();
+    for edge in edges {
+        let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+        if from != to && candidate_similarity.contains_key(from) && candidate_similarity.contains_key(to) {
+            neighbors.entry(from).or_default().insert(to);
+            neighbors.entry(to).or_default().insert(from);
+        }
+    }
+
+    let mut hits: Vec<SemanticSearchHit> = scored
+        .iter()
+        .map(|(key, similarity)| {
+            let graph_boost = neighbors
+                .get(key)
+                .map(|adjacent| {
+                    let total: f32 = adjacent.iter().map(|n| candidate_similarity[n]).sum();
+                    GRAPH_PROXIMITY_BOOST_WEIGHT * total / adjacent.len() as f32
+                })
+                .unwrap_or(0.0);
+            let centrality_boost =
+                CENTRALITY_SEARCH_BOOST_WEIGHT * importance.get(*key).copied().unwrap_or(0.0).clamp(0.0, 1.0) as f32;
+            SemanticSearchHit {
+                isgl1_key: key.to_string(),
+                similarity: *similarity,
+                graph_boost,
+                centrality_boost,
+                score: similarity + graph_boost + centrality_boost,
+            }
+        })
+        .collect();
+    hits.sort_by(|a, b| b.score.total_cmp(&a.score).then_with(|| a.isgl1_key.cmp(&b.isgl1_key)));
+    hits.truncate(limit);
+    hits
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn entity(key: &str, name: &str, doc: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, name, EntityType::Function, "auth/auth.go", &[]);
+        entity.interface_signature.documentation = Some(doc.to_string());
+        entity
+    }
+
+    #[test]
+    fn test_identifier_words_split_camel_and_snake() {
+        assert_eq!(split_identifier_into_words("hashPassword"), vec!["hash", "password"]);
+        assert_eq!(split_identifier_into_words("HTTPServer_start"), vec!["http", "server", "start"]);
+        assert_eq!(split_identifier_into_words("bcrypt2Cost"), vec!["bcrypt2", "cost"]);
+    }
+
+    #[test]
+    fn test_search_ranks_matching_symbol_first() {
+        let entities = vec![
+            entity("go:fn:HashPassword:__auth:T1", "HashPassword", "HashPassword derives a bcrypt hash of the user password"),
+            entity("go:fn:RenderPage:__web:T2", "RenderPage", "RenderPage writes the HTML template"),
+            entity("go:fn:OpenDatabase:__db:T3", "OpenDatabase", "OpenDatabase connects to Postgres"),
+        ];
+        let records: Vec<_> = entities.iter().map(compute_symbol_embedding_record).collect();
+        let query = embed_text_hashed_vector("how do we hash passwords");
+
+        let hits = search_symbols_by_embedding(&query, HASHED_EMBEDDING_MODEL_NAME, &records, &[], &HashMap::new(), 2);
+        assert_eq!(hits[0].isgl1_key, "go:fn:HashPassword:__auth:T1");
+        assert!(hits.len() <= 2);
+    }
+
+    #[test]
+    fn test_graph_neighbors_boost_candidates() {
+        let records = vec![
+            SymbolEmbeddingRecord {
+                isgl1_key: "a".to_string(),
+                model: HASHED_EMBEDDING_MODEL_NAME.to_string(),
+                input_hash: String::new(),
+                vector: vec![1.0, 0.0],
+            },
+            SymbolEmbeddingRecord {
+                isgl1_key: "b".to_string(),
+                model: HASHED_EMBEDDING_MODEL_NAME.to_string(),
+                input_hash: String::new(),
+                vector: vec![0.6, 0.8],
+            },
+            SymbolEmbeddingRecord {
+                isgl1_key: "c".to_string(),
+                model: HASHED_EMBEDDING_MODEL_NAME.to_string(),
+                input_hash: String::new(),
+                vector: vec![0.61, 0.79],
+            },
+        ];
+        let edges = vec![DependencyEdge::builder().from_key("a").to_key("b").edge_type(EdgeType::Calls).build().unwrap()];
+
+        let hits = search_symbols_by_embedding(&[1.0, 0.0], HASHED_EMBEDDING_MODEL_NAME, &records, &edges, &HashMap::new(), 3);
+        // b is slightly less similar than c but is called by the best match
+        assert_eq!(hits[1].isgl1_key, "b");
+        assert!(hits[1].graph_boost > 0.0);
+        assert_eq!(hits[2].graph_boost, 0.0);
+    }
+
+    #[test]
+    fn test_central_symbol_wins_close_match() {
+        let record = |key: &str, vector: Vec<f32>| SymbolEmbeddingRecord {
+            isgl1_key: key.to_string(),
+            model: HASHED_EMBEDDING_MODEL_NAME.to_string(),
+            input_hash: String::new(),
+            vector,
+        };
+        let records = vec![record("leaf", vec![1.0, 0.0]), record("hub", vec![0.99, 0.14])];
+        let importance = HashMap::from([("hub".to_string(), 1.0)]);
+
+        let hits = search_symbols_by_embedding(&[1.0, 0.0], HASHED_EMBEDDING_MODEL_NAME, &records, &[], &importance, 2);
+        assert_eq!(hits[0].isgl1_key, "hub");
+        assert!(hits[0].centrality_boost > 0.0);
+        assert_eq!(hits[1].centrality_boost, 0.0);
+    }
+
+    #[test]
+    fn test_endpoint_provider_orders_batch_by_index() {
+        let answer = |json: &str| format!("HTTP/1.1 200 OK\r\nContent-Length: {}\r\n\r\n{}", json.len(), json);
+        let answers = vec![
+            b"HTTP/1.1 429 Too Many Requests\r\nRetry-After: 0\r\nContent-Length: 0\r\n\r\n".to_vec(),
+            answer(r#"{"data":[{"index":1,"embedding":[0.0,1.0]},{"index":0,"embedding":[1.0,0.0]}]}"#).into_bytes(),
+            answer(r#"{"data":[{"index":0,"embedding":[1.0]},{"index":1,"embedding":[1.0,0.5]}]}"#).into_bytes(),
+        ];
+        let (port, server) = crate::outbound_http_post_client::serve_canned_http_test_answers(answers);
+        let provider = SymbolEmbeddingProviderSpec::Endpoint(EmbeddingEndpointSpec {
+            url: format!("http://127.0.0.1:{}", port),
+            model: "nomic-embed-text".to_string(),
+            api_key: Some("k".to_string()),
+        });
+        let texts = vec!["hash password".to_string(), "render page".to_string()];
+
+        assert_eq!(provider.embedding_model_name_value(), "nomic-embed-text");
+        assert_eq!(provider.embed_texts_with_provider(&texts).unwrap(), vec![vec![1.0, 0.0], vec![0.0, 1.0]]);
+        let ragged = provider.embed_texts_with_provider(&texts).unwrap_err();
+        assert!(ragged.contains("different lengths"), "{}", ragged);
+
+        let requests = server.join().unwrap();
+        assert!(requests[1].starts_with("POST /v1/embeddings HTTP/1.1\r\n"), "{}", requests[1]);
+        assert!(requests[1].to_ascii_lowercase().contains("authorization: bearer k"));
+        let body: Value = serde_json::from_str(requests[1].split_once("\r\n\r\n").unwrap().1).unwrap();
+        assert_eq!(body, json!({"model": "nomic-embed-text", "input": ["hash password", "render page"]}));
+
+        let offline = SymbolEmbeddingProviderSpec::HashedWordsFallback;
+        assert_eq!(offline.embedding_model_name_value(), HASHED_EMBEDDING_MODEL_NAME);
+        assert_eq!(offline.embed_texts_with_provider(&texts).unwrap()[0], embed_text_hashed_vector("hash password"));
+        assert!(parse_embedding_endpoint_url("ftp://x").is_err());
+    }
+}
@@ -0,0 +1,120 @@
+//! Signatures-only API surface export (v1.7.3)
+//!
+//! # 4-Word Naming: render_api_surface_signatures
+//!
+//! `export --signatures-only` view of a package: exported symbols grouped
+//! by file, each as its doc comment plus one-line declaration signature,
+//! without bodies. Signatures come from the `signature` metadata captured at
+//! ingest (falling back to the stored code), docs from
+//! `interface_signature.documentation`.
+
+use std::collections::BTreeMap;
+
+use super::graph_export_node_table::{is_external_graph_node_key, matches_path_glob_pattern};
+use crate::dead_code_detection_analyzer::is_entity_symbol_exported;
+use crate::entities::CodeEntity;
+use crate::symbol_doc_signature_extractor::{extract_symbol_signature_line, SIGNATURE_METADATA_KEY};
+
+/// API surface rendering options
+///
+/// # 4-Word Name: ApiSurfaceConfig
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct ApiSurfaceConfig {
+    /// Keep only symbols whose file matches one of these (empty = all)
+    pub include_globs: Vec<String>,
+    /// Drop symbols whose file matches any of these
+    pub exclude_globs: Vec<String>,
+    /// Also list non-exported symbols
+    pub include_private: bool,
+}
+
+/// Render exported symbols as a signatures-only API listing
+///
+/// # 4-Word Name: render_api_surface_signatures
+///
+/// # Contract
+/// - Postcondition: one `## {file}` section per file in path order, symbols
+///   in line order; placeholder entities and symbols without a signature
+///   are skipped
+pub fn render_api_surface_signatures(entities: &[CodeEntity], config: &ApiSurfaceConfig) -> String {
+    let mut files: BTreeMap<String, Vec<(u32, &CodeEntity, String)>> = BTreeMap::new();
+
+    for entity in entities {
+        if is_external_graph_node_key(&entity.isgl1_key) {
+            continue;
+        }
+        if !config.include_private && !is_entity_symbol_exported(entity) {
+            continue;
+        }
+        let file_path = entity.interface_signature.file_path.to_string_lossy().to_string();
+        let included = config.include_globs.is_empty()
+            || config.include_globs.iter().any(|g| matches_path_glob_pattern(&file_path, g));
+        if !included || config.exclude_globs.iter().any(|g| matches_path_glob_pattern(&file_path, g)) {
+            continue;
+        }
+        let signature = match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+            Some(signature) => signature.clone(),
+            None => extract_symbol_signature_line(entity.current_code.as_deref().unwrap_or("")),
+        };
+        if signature.is_empty() {
+            continue;
+        }
+        files
+            .entry(file_path)
+            .or_default()
+            .push((entity.interface_signature.line_range.start, entity, signature));
+    }
+
+    let mut out = String::new();
+    for (file_path, mut symbols) in files {
+        symbols.sort_by(|a, b| a.0.cmp(&b.0).then_with(|| a.1.isgl1_key.cmp(&b.1.isgl1_key)));
+        out.push_str(&format!("## {}\n\n", file_path));
+        for (_, entity, signature) in symbols {
+            if let Some(doc) = &entity.interface_signature.documentation {
+                for line in doc.lines() {
+                    out.push_str(format!("// {}", line).trim_end());
+                    out.push('\n');
+                }
+            }
+            out.push_str(&signature);
+            out.push_str("\n\n");
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_api_surface_lists_exported_signatures_with_docs() {
+        let mut save = create_go_test_entity(
+            "go:method:Save:__svc:T2",
+            "Save",
+            EntityType::Method,
+            "svc/store.go",
+            &[("signature", "func (s *Store) Save(u User) error")],
+        );
+        save.interface_signature.documentation = Some("Save writes u.".to_string());
+        let entities = vec![
+            save,
+            create_go_test_entity("go:fn:helper:__svc:T3", "helper", EntityType::Function, "svc/store.go", &[("signature", "func helper()")]),
+            create_go_test_entity("go:fn:Run:__cmd:T4", "Run", EntityType::Function, "cmd/main.go", &[("signature", "func Run()")]),
+            create_go_test_entity("go:fn:Println:unresolved-reference:0-0", "Println", EntityType::Function, "", &[]),
+        ];
+
+        let surface = render_api_surface_signatures(&entities, &ApiSurfaceConfig::default());
+        assert_eq!(
+            surface,
+            "## cmd/main.go\n\nfunc Run()\n\n## svc/store.go\n\n// Save writes u.\nfunc (s *Store) Save(u User) error\n\n"
+        );
+
+        let config = ApiSurfaceConfig { include_globs: vec!["svc/**".to_string()], include_private: true, ..Default::default() };
+        let surface = render_api_surface_signatures(&entities, &config);
+        assert!(surface.contains("func helper()"));
+        assert!(!surface.contains("func Run()"));
+    }
+}
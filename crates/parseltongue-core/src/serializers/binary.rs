@@ -0,0 +1,476 @@
+//! Compact binary graph format (v1.7.3)
+//!
+//! # 4-Word Naming: encode_graph_as_binary
+//!
+//! A `.ptg` file holds the whole dependency graph in fixed-width records, so
+//! multi-million-node graphs can be memory-mapped and queried in place: no
+//! JSON parse, no per-node allocation. All integers are little-endian and
+//! every section starts on an 8-byte boundary.
+//!
+//! | Section | Layout |
+//! |---------|--------|
+//! | Header (64 B) | magic `PTGRAPH1`, version, string/node/edge counts, section offsets |
+//! | String offsets | `string_count + 1` × u32 into the string data |
+//! | String data | UTF-8, strings sorted and deduplicated (lookup by binary search) |
+//! | Nodes (32 B each) | key, name, kind, language, file (string ids), line_start, line_end, flags; sorted by key |
+//! | Edges (16 B each) | from, to (node ids), edge_type, source_location (string ids); sorted by (from, to, type) |
+//! | Adjacency | out offsets (`node_count + 1` × u32), in offsets (same), in edge ids (`edge_count` × u32) |
+//!
+//! Outgoing edges of node `n` are edges `out[n]..out[n + 1]` (CSR); incoming
+//! ones are the edge ids `in_ids[in[n]..in[n + 1]]`. String id `u32::MAX`
+//! means "none". Nodes are the same rows as the other graph exports (edge
+//! targets without an entity are `external`).
+
+use std::collections::{BTreeSet, HashMap};
+use std::path::Path;
+
+use anyhow::{bail, Context, Result};
+
+use super::graph_export_node_table::collect_graph_export_nodes;
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// First 8 bytes of every `.ptg` file
+pub const BINARY_GRAPH_MAGIC_BYTES: &[u8; 8] = b"PTGRAPH1";
+
+/// Format version written by `encode_graph_as_binary`
+pub const BINARY_GRAPH_FORMAT_VERSION: u32 = 1;
+
+/// String id meaning "no string"
+pub const BINARY_GRAPH_NO_STRING: u32 = u32::MAX;
+
+const HEADER_LENGTH_BYTES: usize = 64;
+const NODE_RECORD_BYTES: usize = 32;
+const EDGE_RECORD_BYTES: usize = 16;
+const EXTERNAL_NODE_FLAG_BIT: u32 = 1;
+
+/// Encode entities and edges as a `.ptg` document
+///
+/// # 4-Word Name: encode_graph_as_binary
+///
+/// # Contract
+/// - Postcondition: `CompactBinaryGraphView::parse` accepts the result; node
+///   and edge order is deterministic; duplicate edges are stored once
+/// - Limit: string data and every count must fit in u32
+pub fn encode_graph_as_binary(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<u8> {
+    let nodes = collect_graph_export_nodes(entities, edges);
+
+    let mut strings: BTreeSet<&str> = BTreeSet::new();
+    for node in &nodes {
+        strings.extend([node.key.as_str(), node.name.as_str(), node.kind.as_str(), node.language.as_str()]);
+        if !node.external && !node.file_path.is_empty() {
+            strings.insert(node.file_path.as_str());
+        }
+    }
+    for edge in edges {
+        strings.insert(edge.edge_type.as_str());
+        if let Some(location) = edge.source_location.as_deref().filter(|l| !l.is_empty()) {
+            strings.insert(location);
+        }
+    }
+    let strings: Vec<&str> = strings.into_iter().collect();
+    let string_ids: HashMap<&str, u32> = strings.iter().enumerate().map(|(i, s)| (*s, i as u32)).collect();
+    let node_ids: HashMap<&str, u32> = nodes.iter().enumerate().map(|(i, n)| (n.key.as_str(), i as u32)).collect();
+
+    let mut edge_rows: Vec<[u32; 4]> = edges
+        .iter()
+        .map(|edge| {
+            let location = edge
+                .source_location
+                .as_deref()
+                .and_then(|l| string_ids.get(l).copied())
+                .unwrap_or(BINARY_GRAPH_NO_STRING);
+            [
+                node_ids[edge.from_key.as_str()],
+                node_ids[edge.to_key.as_str()],
+                string_ids[edge.edge_type.as_str()],
+                location,
+            ]
+        })
+        .collect();
+    edge_rows.sort_unstable();
+    edge_rows.dedup();
+
+    let mut out = vec![0u8; HEADER_LENGTH_BYTES];
+
+    let string_offsets_position = out.len();
+    let mut offset = 0u32;
+    push_u32_little_endian(&mut out, offset);
+    for string in &strings {
+        offset += string.len() as u32;
+        push_u32_little_endian(&mut out, offset);
+    }
+    pad_to_eight_byte_boundary(&mut out);
+    let string_data_position = out.len();
+    for string in &strings {
+        out.extend_from_slice(string.as_bytes());
+    }
+    pad_to_eight_byte_boundary(&mut out);
+
+    let node_table_position = out.len();
+    for node in &nodes {
+        let file = if node.external || node.file_path.is_empty() {
+            BINARY_GRAPH_NO_STRING
+        } else {
+            string_ids[node.file_path.as_str()]
+        };
+        for value in [
+            string_ids[node.key.as_str()],
+            string_ids[node.name.as_str()],
+            string_ids[node.kind.as_str()],
+            string_ids[node.language.as_str()],
+            file,
+            node.line_start,
+            node.line_end,
+            if node.external { EXTERNAL_NODE_FLAG_BIT } else { 0 },
+        ] {
+            push_u32_little_endian(&mut out, value);
+        }
+    }
+
+    let edge_table_position = out.len();
+    for row in &edge_rows {
+        for value in row {
+            push_u32_little_endian(&mut out, *value);
+        }
+    }
+
+    let adjacency_position = out.len();
+    let mut out_offsets = vec![0u32; nodes.len() + 1];
+    let mut in_offsets = vec![0u32; nodes.len() + 1];
+    for row in &edge_rows {
+        out_offsets[row[0] as usize + 1] += 1;
+        in_offsets[row[1] as usize + 1] += 1;
+    }
+    for index in 1..out_offsets.len() {
+        out_offsets[index] += out_offsets[index - 1];
+        in_offsets[index] += in_offsets[index - 1];
+    }
+    let mut in_edge_ids: Vec<u32> = (0..edge_rows.len() as u32).collect();
+    in_edge_ids.sort_by_key(|&id| (edge_rows[id as usize][1], edge_rows[id as usize][0], id));
+    for value in out_offsets.iter().chain(&in_offsets).chain(&in_edge_ids) {
+        push_u32_little_endian(&mut out, *value);
+    }
+
+    out[0..8].copy_from_slice(BINARY_GRAPH_MAGIC_BYTES);
+    let header_fields: [(usize, u64); 8] = [
+        (8, BINARY_GRAPH_FORMAT_VERSION as u64),
+        (12, strings.len() as u64),
+        (16, nodes.len() as u64),
+        (20, edge_rows.len() as u64),
+        (24, string_offsets_position as u64),
+        (32, string_data_position as u64),
+        (40, node_table_position as u64),
+        (48, edge_table_position as u64),
+    ];
+    for (position, value) in header_fields {
+        if position < 24 {
+            out[position..position + 4].copy_from_slice(&(value as u32).to_le_bytes());
+        } else {
+            out[position..position + 8].copy_from_slice(&value.to_le_bytes());
+        }
+    }
+    out[56..64].copy_from_slice(&(adjacency_position as u64).to_le_bytes());
+    out
+}
+
+fn push_u32_little_endian(out: &mut Vec<u8>, value: u32) {
+    out.extend_from_slice(&value.to_le_bytes());
+}
+
+fn pad_to_eight_byte_boundary(out: &mut Vec<u8>) {
+    while out.len() % 8 != 0 {
+        out.push(0);
+    }
+}
+
+/// One node, borrowed from the underlying bytes
+///
+/// # 4-Word Name: BinaryGraphNodeView
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct BinaryGraphNodeView<'a> {
+    pub id: u32,
+    pub key: &'a str,
+    pub name: &'a str,
+    pub kind: &'a str,
+    pub language: &'a str,
+    /// `None` for external nodes
+    pub file_path: Option<&'a str>,
+    pub line_start: u32,
+    pub line_end: u32,
+    pub external: bool,
+}
+
+/// One edge, borrowed from the underlying bytes
+///
+/// # 4-Word Name: BinaryGraphEdgeView
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct BinaryGraphEdgeView<'a> {
+    pub from: u32,
+    pub to: u32,
+    pub edge_type: &'a str,
+    pub source_location: Option<&'a str>,
+}
+
+/// Zero-copy reader over a `.ptg` document (bytes in memory or mmapped)
+///
+/// # 4-Word Name: CompactBinaryGraphView
+///
+/// Accessors return `None` for ids or offsets that point outside the
+/// document, so a corrupt file cannot cause a panic.
+#[derive(Debug, Clone, Copy)]
+pub struct CompactBinaryGraphView<'a> {
+    bytes: &'a [u8],
+    string_count: u32,
+    node_count: u32,
+    edge_count: u32,
+    string_offsets_position: usize,
+    string_data_position: usize,
+    node_table_position: usize,
+    edge_table_position: usize,
+    adjacency_position: usize,
+}
+
+impl<'a> CompactBinaryGraphView<'a> {
+    /// Validate the header and section bounds (O(1): records are not scanned)
+    ///
+    /// # Contract
+    /// - Error: wrong magic, unknown version, or a section past end of input
+    pub fn parse(bytes: &'a [u8]) -> Result<Self> {
+        if bytes.len() < HEADER_LENGTH_BYTES || &bytes[0..8] != BINARY_GRAPH_MAGIC_BYTES {
+            bail!("not a parseltongue binary graph (missing PTGRAPH1 header)");
+        }
+        let version = read_u32_at(bytes, 8).unwrap_or(0);
+        if version != BINARY_GRAPH_FORMAT_VERSION {
+            bail!("unsupported binary graph version {}", version);
+        }
+        let position = |at: usize| read_u64_at(bytes, at).map(|v| v as usize).unwrap_or(usize::MAX);
+        let view = Self {
+            bytes,
+            string_count: read_u32_at(bytes, 12).unwrap_or(0),
+            node_count: read_u32_at(bytes, 16).unwrap_or(0),
+            edge_count: read_u32_at(bytes, 20).unwrap_or(0),
+            string_offsets_position: position(24),
+            string_data_position: position(32),
+            node_table_position: position(40),
+            edge_table_position: position(48),
+            adjacency_position: position(56),
+        };
+
+        let nodes = view.node_count as usize;
+        let edges = view.edge_count as usize;
+        let string_data_length = read_u32_at(bytes, view.string_offsets_position.saturating_add(view.string_count as usize * 4))
+            .unwrap_or(u32::MAX) as usize;
+        let sections = [
+            (view.string_offsets_position, (view.string_count as usize + 1) * 4),
+            (view.string_data_position, string_data_length),
+            (view.node_table_position, nodes * NODE_RECORD_BYTES),
+            (view.edge_table_position, edges * EDGE_RECORD_BYTES),
+            (view.adjacency_position, ((nodes + 1) * 2 + edges) * 4),
+        ];
+        for (start, length) in sections {
+            if start.checked_add(length).map_or(true, |end| end > bytes.len()) {
+                bail!("truncated binary graph: section at byte {} overruns the file", start);
+            }
+        }
+        Ok(view)
+    }
+
+    pub fn node_count(&self) -> usize {
+        self.node_count as usize
+    }
+
+    pub fn edge_count(&self) -> usize {
+        self.edge_count as usize
+    }
+
+    pub fn string_count(&self) -> usize {
+        self.string_count as usize
+    }
+
+    /// String `id`; `None` for `BINARY_GRAPH_NO_STRING`, bad ids or bad UTF-8
+    pub fn string_at(&self, id: u32) -> Option<&'a str> {
+        if id >= self.string_count {
+            return None;
+        }
+        let start = read_u32_at(self.bytes, self.string_offsets_position + id as usize * 4)? as usize;
+        let end = read_u32_at(self.bytes, self.string_offsets_position + id as usize * 4 + 4)? as usize;
+        let data = self.bytes.get(self.string_data_position + start..self.string_data_position + end)?;
+        std::str::from_utf8(data).ok()
+    }
+
+    pub fn node_at(&self, id: u32) -> Option<BinaryGraphNodeView<'a>> {
+        if id >= self.node_count {
+            return None;
+        }
+        let base = self.node_table_position + id as usize * NODE_RECORD_BYTES;
+        let field = |index: usize| read_u32_at(self.bytes, base + index * 4);
+        let file = field(4)?;
+        Some(BinaryGraphNodeView {
+            id,
+            key: self.string_at(field(0)?)?,
+            name: self.string_at(field(1)?)?,
+            kind: self.string_at(field(2)?)?,
+            language: self.string_at(field(3)?)?,
+            file_path: if file == BINARY_GRAPH_NO_STRING { None } else { self.string_at(file) },
+            line_start: field(5)?,
+            line_end: field(6)?,
+            external: field(7)? & EXTERNAL_NODE_FLAG_BIT != 0,
+        })
+    }
+
+    pub fn edge_at(&self, id: u32) -> Option<BinaryGraphEdgeView<'a>> {
+        if id >= self.edge_count {
+            return None;
+        }
+        let base = self.edge_table_position + id as usize * EDGE_RECORD_BYTES;
+        let field = |index: usize| read_u32_at(self.bytes, base + index * 4);
+        let location = field(3)?;
+        Some(BinaryGraphEdgeView {
+            from: field(0)?,
+            to: field(1)?,
+            edge_type: self.string_at(field(2)?)?,
+            source_location: if location == BINARY_GRAPH_NO_STRING { None } else { self.string_at(location) },
+        })
+    }
+
+    /// Node id of an ISGL1 key by binary search (O(log n), no allocation)
+    pub fn find_node_by_key(&self, key: &str) -> Option<u32> {
+        let (mut low, mut high) = (0u32, self.node_count);
+        while low < high {
+            let middle = low + (high - low) / 2;
+            let candidate = self.node_at(middle)?.key;
+            match candidate.cmp(key) {
+                std::cmp::Ordering::Less => low = middle + 1,
+                std::cmp::Ordering::Greater => high = middle,
+                std::cmp::Ordering::Equal => return Some(middle),
+            }
+        }
+        None
+    }
+
+    /// Edges leaving `node`, sorted by target
+    pub fn outgoing_edges(&self, node: u32) -> impl Iterator<Item = BinaryGraphEdgeView<'a>> + '_ {
+        let (start, end) = self.adjacency_range(0, node).unwrap_or((0, 0));
+        (start..end).filter_map(move |id| self.edge_at(id))
+    }
+
+    /// Edges entering `node`, sorted by source
+    pub fn incoming_edges(&self, node: u32) -> impl Iterator<Item = BinaryGraphEdgeView<'a>> + '_ {
+        let (start, end) = self.adjacency_range(self.node_count as usize + 1, node).unwrap_or((0, 0));
+        let in_ids_position = self.adjacency_position + (self.node_count as usize + 1) * 8;
+        (start..end)
+            .filter_map(move |slot| read_u32_at(self.bytes, in_ids_position + slot as usize * 4))
+            .filter_map(move |id| self.edge_at(id))
+    }
+
+    /// `(offsets[node], offsets[node + 1])` of the offset array starting at element `array_start`
+    fn adjacency_range(&self, array_start: usize, node: u32) -> Option<(u32, u32)> {
+        if node >= self.node_count {
+            return None;
+        }
+        let position = self.adjacency_position + (array_start + node as usize) * 4;
+        Some((read_u32_at(self.bytes, position)?, read_u32_at(self.bytes, position + 4)?))
+    }
+}
+
+/// A `.ptg` file mapped into memory
+///
+/// # 4-Word Name: MappedBinaryGraphFile
+pub struct MappedBinaryGraphFile {
+    map: memmap2::Mmap,
+}
+
+impl MappedBinaryGraphFile {
+    /// Map `path` read-only
+    ///
+    /// # Contract
+    /// - Precondition: nothing truncates the file while it is mapped
+    pub fn open(path: &Path) -> Result<Self> {
+        let file = std::fs::File::open(path).with_context(|| format!("opening {}", path.display()))?;
+        // SAFETY: read-only mapping; the reader bounds-checks every access and
+        // the precondition above rules out concurrent truncation
+        let map = unsafe { memmap2::Mmap::map(&file) }.with_context(|| format!("mapping {}", path.display()))?;
+        Ok(Self { map })
+    }
+
+    pub fn view(&self) -> Result<CompactBinaryGraphView<'_>> {
+        CompactBinaryGraphView::parse(&self.map)
+    }
+}
+
+fn read_u32_at(bytes: &[u8], position: usize) -> Option<u32> {
+    let slice = bytes.get(position..position.checked_add(4)?)?;
+    Some(u32::from_le_bytes([slice[0], slice[1], slice[2], slice[3]]))
+}
+
+fn read_u64_at(bytes: &[u8], position: usize) -> Option<u64> {
+    let slice = bytes.get(position..position.checked_add(8)?)?;
+    let mut buffer = [0u8; 8];
+    buffer.copy_from_slice(slice);
+    Some(u64::from_le_bytes(buffer))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn sample_graph_bytes() -> Vec<u8> {
+        let entities = vec![
+            create_go_test_entity("go:fn:handle:__api:T1", "handle", EntityType::Function, "api/handler.go", &[]),
+            create_go_test_entity("go:fn:save:__db:T2", "save", EntityType::Function, "db/save.go", &[]),
+        ];
+        let edge = |from: &str, to: &str, location: &str| {
+            DependencyEdge::builder()
+                .from_key(from)
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+                .source_location(location)
+                .build()
+                .unwrap()
+        };
+        let edges = vec![
+            edge("go:fn:handle:__api:T1", "go:fn:save:__db:T2", "api/handler.go:3"),
+            edge("go:fn:handle:__api:T1", "go:fn:save:__db:T2", "api/handler.go:3"),
+            edge("go:fn:save:__db:T2", "go:fn:Printf:unresolved-reference:0-0", "db/save.go:2"),
+        ];
+        encode_graph_as_binary(&entities, &edges)
+    }
+
+    #[test]
+    fn test_binary_graph_round_trip_queries() {
+        let bytes = sample_graph_bytes();
+        let view = CompactBinaryGraphView::parse(&bytes).unwrap();
+        assert_eq!((view.node_count(), view.edge_count()), (3, 2), "duplicate edge stored once");
+
+        let save = view.find_node_by_key("go:fn:save:__db:T2").unwrap();
+        let node = view.node_at(save).unwrap();
+        assert_eq!((node.name, node.file_path, node.external), ("save", Some("db/save.go"), false));
+
+        let callers: Vec<&str> = view
+            .incoming_edges(save)
+            .map(|e| view.node_at(e.from).unwrap().key)
+            .collect();
+        assert_eq!(callers, vec!["go:fn:handle:__api:T1"]);
+        let callees: Vec<(bool, &str, Option<&str>)> = view
+            .outgoing_edges(save)
+            .map(|e| (view.node_at(e.to).unwrap().external, e.edge_type, e.source_location))
+            .collect();
+        assert_eq!(callees, vec![(true, "Calls", Some("db/save.go:2"))]);
+        assert!(view.find_node_by_key("go:fn:missing:__x:T9").is_none());
+    }
+
+    #[test]
+    fn test_corrupt_input_is_rejected() {
+        let bytes = sample_graph_bytes();
+        assert!(CompactBinaryGraphView::parse(&bytes[..bytes.len() - 4]).is_err());
+        let mut wrong_magic = bytes.clone();
+        wrong_magic[0] = b'X';
+        assert!(CompactBinaryGraphView::parse(&wrong_magic).is_err());
+        assert!(CompactBinaryGraphView::parse(&[]).is_err());
+        let view = CompactBinaryGraphView::parse(&bytes).unwrap();
+        assert!(view.node_at(99).is_none());
+        assert_eq!(view.outgoing_edges(99).count(), 0);
+    }
+}
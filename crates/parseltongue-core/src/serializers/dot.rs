@@ -0,0 +1,237 @@
+//! Graphviz DOT export with package clustering (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_dot
+//!
+//! - Internal nodes are grouped into `subgraph cluster_*` blocks by package
+//!   (parent directory of the entity's file, as for Go packages)
+//! - `--include` / `--exclude` globs filter internal nodes by file path;
+//!   an edge is kept only when both of its endpoints are kept
+//! - External targets collapse into one node per dependency
+//!   (`external-dependency-{crate}`) or per language for unresolved calls;
+//!   parallel edges into a collapsed node are merged with a count
+
+use std::collections::{BTreeMap, HashMap};
+use std::path::Path;
+
+use super::graph_export_node_table::{
+    collect_graph_export_nodes, matches_path_glob_pattern, GraphExportNodeRow,
+    EXTERNAL_DEPENDENCY_SEGMENT_PREFIX,
+};
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// DOT rendering options
+///
+/// # 4-Word Name: DotConfig
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct DotConfig {
+    /// Keep only internal nodes whose file matches one of these (empty = all)
+    pub include_globs: Vec<String>,
+    /// Drop internal nodes whose file matches any of these
+    pub exclude_globs: Vec<String>,
+    /// One node per external dependency instead of one per symbol
+    pub collapse_external: bool,
+    /// Wrap nodes of the same package in a cluster
+    pub cluster_by_package: bool,
+}
+
+impl Default for DotConfig {
+    fn default() -> Self {
+        Self {
+            include_globs: Vec::new(),
+            exclude_globs: Vec::new(),
+            collapse_external: true,
+            cluster_by_package: true,
+        }
+    }
+}
+
+/// Render entities and edges as a Graphviz digraph
+///
+/// # 4-Word Name: render_graph_as_dot
+///
+/// # Contract
+/// - Postcondition: valid DOT; node ids (`n*` internal, `x*` external) are
+///   assigned in key order; clusters sorted by package path
+/// - External nodes are kept only if a kept internal node points at them
+pub fn render_graph_as_dot(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    config: &DotConfig,
+) -> String {
+    let nodes = collect_graph_export_nodes(entities, edges);
+
+    // key -> DOT node id; externals may share an id when collapsed
+    let mut node_ids: HashMap<&str, String> = HashMap::new();
+    let mut clusters: BTreeMap<String, Vec<(&GraphExportNodeRow, String)>> = BTreeMap::new();
+    let mut external_labels: BTreeMap<String, String> = BTreeMap::new();
+
+    for node in nodes.iter().filter(|n| !n.external) {
+        if !is_file_kept_by_globs(&node.file_path, config) {
+            continue;
+        }
+        let id = format!("n{}", node_ids.len());
+        node_ids.insert(node.key.as_str(), id.clone());
+        let package = Path::new(&node.file_path)
+            .parent()
+            .map(|p| p.to_string_lossy().to_string())
+            .unwrap_or_default();
+        clusters.entry(package).or_default().push((node, id));
+    }
+
+    let mut collapsed_ids: HashMap<String, String> = HashMap::new();
+    for node in nodes.iter().filter(|n| n.external) {
+        let (group, label) = if config.collapse_external {
+            let group = external_dependency_group_name(node);
+            (group.clone(), group)
+        } else {
+            (node.key.clone(), node.name.clone())
+        };
+        let next_index = collapsed_ids.len();
+        let id = collapsed_ids
+            .entry(group)
+            .or_insert_with(|| format!("x{}", next_index))
+            .clone();
+        external_labels.insert(id.clone(), label);
+        node_ids.insert(node.key.as_str(), id);
+    }
+
+    // Merge parallel edges (same endpoints and type) after collapsing
+    let mut merged_edges: BTreeMap<(String, String, &str), usize> = BTreeMap::new();
+    for edge in edges {
+        let (Some(from), Some(to)) = (node_ids.get(edge.from_key.as_str()), node_ids.get(edge.to_key.as_str())) else {
+            continue;
+        };
+        // Edges out of external nodes carry no information once collapsed
+        if from.starts_with('x') {
+            continue;
+        }
+        *merged_edges
+            .entry((from.clone(), to.clone(), edge.edge_type.as_str()))
+            .or_insert(0) += 1;
+    }
+    let referenced_externals: std::collections::HashSet<&str> = merged_edges
+        .keys()
+        .map(|(_, to, _)| to.as_str())
+        .filter(|to| to.starts_with('x'))
+        .collect();
+
+    let mut out = String::from("digraph parseltongue {\n");
+    out.push_str("    rankdir=LR;\n");
+    out.push_str("    node [shape=box, fontsize=10, fontname=\"Helvetica\"];\n");
+    out.push_str("    edge [fontsize=8, fontname=\"Helvetica\"];\n");
+
+    for (index, (package, members)) in clusters.iter().enumerate() {
+        let indent = if config.cluster_by_package {
+            out.push_str(&format!("    subgraph cluster_{} {{\n", index));
+            out.push_str(&format!("        label=\"{}\";\n", escape_dot_string_text(package)));
+            out.push_str("        style=rounded; color=\"#999999\";\n");
+            "        "
+        } else {
+            "    "
+        };
+        for (node, id) in members {
+            out.push_str(&format!(
+                "{}{} [label=\"{}\\n{}\", tooltip=\"{}\"];\n",
+                indent,
+                id,
+                escape_dot_string_text(&node.name),
+                escape_dot_string_text(&node.kind),
+                escape_dot_string_text(&node.key)
+            ));
+        }
+        if config.cluster_by_package {
+            out.push_str("    }\n");
+        }
+    }
+
+    for (id, label) in &external_labels {
+        if referenced_externals.contains(id.as_str()) {
+            out.push_str(&format!(
+                "    {} [label=\"{}\", shape=folder, style=dashed, color=\"#777777\"];\n",
+                id,
+                escape_dot_string_text(label)
+            ));
+        }
+    }
+
+    for ((from, to, edge_type), count) in &merged_edges {
+        let label = if *count > 1 {
+            format!("{} ×{}", edge_type, count)
+        } else {
+            edge_type.to_string()
+        };
+        out.push_str(&format!("    {} -> {} [label=\"{}\"];\n", from, to, label));
+    }
+
+    out.push_str("}\n");
+    out
+}
+
+/// Include/exclude decision for an internal node's file
+fn is_file_kept_by_globs(file_path: &str, config: &DotConfig) -> bool {
+    let included = config.include_globs.is_empty()
+        || config.include_globs.iter().any(|g| matches_path_glob_pattern(file_path, g));
+    included && !config.exclude_globs.iter().any(|g| matches_path_glob_pattern(file_path, g))
+}
+
+/// Collapsed node name: crate for external deps, `{lang} (unresolved)` otherwise
+fn external_dependency_group_name(node: &GraphExportNodeRow) -> String {
+    node.key
+        .split(':')
+        .find_map(|segment| segment.strip_prefix(EXTERNAL_DEPENDENCY_SEGMENT_PREFIX))
+        .map(str::to_string)
+        .unwrap_or_else(|| format!("{} (unresolved)", node.language))
+}
+
+/// Escape text for a double-quoted DOT string
+fn escape_dot_string_text(text: &str) -> String {
+    text.replace('\\', "\\\\").replace('"', "\\\"")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn calls(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    fn fixture() -> (Vec<CodeEntity>, Vec<DependencyEdge>) {
+        let entities = vec![
+            create_go_test_entity("go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[]),
+            create_go_test_entity("go:method:Save:__svc:T2", "Save", EntityType::Method, "svc/store.go", &[]),
+            create_go_test_entity("go:fn:TestSave:__svc:T3", "TestSave", EntityType::Function, "svc/store_test.go", &[]),
+        ];
+        let edges = vec![
+            calls("go:fn:main:__cmd:T1", "go:method:Save:__svc:T2"),
+            calls("go:fn:TestSave:__svc:T3", "go:method:Save:__svc:T2"),
+            calls("go:method:Save:__svc:T2", "go:fn:Println:unresolved-reference:0-0"),
+            calls("go:method:Save:__svc:T2", "go:fn:Sprintf:unresolved-reference:0-0"),
+        ];
+        (entities, edges)
+    }
+
+    #[test]
+    fn test_dot_clusters_by_package_and_collapses_external() {
+        let (entities, edges) = fixture();
+        let dot = render_graph_as_dot(&entities, &edges, &DotConfig::default());
+
+        assert!(dot.contains("label=\"cmd\";"));
+        assert!(dot.contains("label=\"svc\";"));
+        assert!(dot.contains("x0 [label=\"go (unresolved)\""));
+        assert!(dot.contains("-> x0 [label=\"Calls ×2\"];"));
+        assert!(!dot.contains("x1"));
+    }
+
+    #[test]
+    fn test_dot_exclude_glob_drops_nodes_and_edges() {
+        let (entities, edges) = fixture();
+        let config = DotConfig { exclude_globs: vec!["*_test.go".to_string()], ..Default::default() };
+        let dot = render_graph_as_dot(&entities, &edges, &config);
+
+        assert!(!dot.contains("TestSave"));
+        assert_eq!(dot.matches(" -> ").count(), 2);
+    }
+}
@@ -0,0 +1,262 @@
+//! Node table shared by graph export formats (v1.7.3)
+//!
+//! # 4-Word Naming: graph_export_node_table
+//!
+//! Graph formats (GraphML, Mermaid, DOT) need one node per edge endpoint.
+//! Edges may point at targets that are not entities (unresolved calls,
+//! external crates), so those endpoints become `external` nodes whose
+//! attributes are recovered from the ISGL1 key. pt01 also stores placeholder
+//! entities for such targets; those are flagged `external` as well.
+//!
+//! It also defines the export order every format shares (nodes by key,
+//! edges by `compare_edges_in_export_order`), so two snapshots of the same
+//! graph serialize byte-identically and diff line by line.
+
+use std::cmp::Ordering;
+use std::collections::BTreeMap;
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::filtered_graph_traversal_queries::is_placeholder_target_key;
+
+/// Key segment prefix of known external dependencies (`external-dependency-clap`)
+pub const EXTERNAL_DEPENDENCY_SEGMENT_PREFIX: &str = "external-dependency-";
+
+/// True for unresolved and external-dependency placeholder keys
+///
+/// # 4-Word Name: is_external_graph_node_key
+pub fn is_external_graph_node_key(key: &str) -> bool {
+    is_placeholder_target_key(key) || key.contains(&format!(":{}", EXTERNAL_DEPENDENCY_SEGMENT_PREFIX))
+}
+
+/// One node of an exported graph
+///
+/// # 4-Word Name: GraphExportNodeRow
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GraphExportNodeRow {
+    pub key: String,
+    pub name: String,
+    /// Key segment 2: fn, method, struct, trait, ...
+    pub kind: String,
+    pub language: String,
+    /// Empty for external nodes
+    pub file_path: String,
+    pub line_start: u32,
+    pub line_end: u32,
+    /// True when the node is only known as an edge target
+    pub external: bool,
+}
+
+/// Build the node table for a graph export
+///
+/// # 4-Word Name: collect_graph_export_nodes
+///
+/// # Contract
+/// - Postcondition: exactly one row per entity and per edge endpoint that is
+///   not an entity; sorted by key for deterministic output
+pub fn collect_graph_export_nodes(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> Vec<GraphExportNodeRow> {
+    let mut nodes: BTreeMap<String, GraphExportNodeRow> = BTreeMap::new();
+
+    for entity in entities {
+        let (language, kind, _) = split_key_head_segments(&entity.isgl1_key);
+        let external = is_external_graph_node_key(&entity.isgl1_key);
+        nodes.insert(
+            entity.isgl1_key.clone(),
+            GraphExportNodeRow {
+                key: entity.isgl1_key.clone(),
+                name: entity.interface_signature.name.clone(),
+                kind: kind.to_string(),
+                language: language.to_string(),
+                file_path: if external {
+                    String::new()
+                } else {
+                    entity.interface_signature.file_path.to_string_lossy().to_string()
+                },
+                line_start: if external { 0 } else { entity.interface_signature.line_range.start },
+                line_end: if external { 0 } else { entity.interface_signature.line_range.end },
+                external,
+            },
+        );
+    }
+
+    for edge in edges {
+        for key in [edge.from_key.as_str(), edge.to_key.as_str()] {
+            if nodes.contains_key(key) {
+                continue;
+            }
+            let (language, kind, name) = split_key_head_segments(key);
+            nodes.insert(
+                key.to_string(),
+                GraphExportNodeRow {
+                    key: key.to_string(),
+                    name: name.to_string(),
+                    kind: kind.to_string(),
+                    language: language.to_string(),
+                    file_path: String::new(),
+                    line_start: 0,
+                    line_end: 0,
+                    external: true,
+                },
+            );
+        }
+    }
+
+    nodes.into_values().collect()
+}
+
+/// Total order of edges in every export format
+///
+/// # 4-Word Name: compare_edges_in_export_order
+///
+/// # Contract
+/// - Orders by (from, to, edge_type, source_location, metadata), so edges
+///   that differ only in location or metadata never keep storage order
+pub fn compare_edges_in_export_order(a: &DependencyEdge, b: &DependencyEdge) -> Ordering {
+    (a.from_key.as_str(), a.to_key.as_str(), a.edge_type.as_str(), a.source_location.as_deref(), &a.metadata).cmp(&(
+        b.from_key.as_str(),
+        b.to_key.as_str(),
+        b.edge_type.as_str(),
+        b.source_location.as_deref(),
+        &b.metadata,
+    ))
+}
+
+/// Sort a loaded graph into export order (`export --sort`, the default)
+///
+/// # 4-Word Name: sort_graph_in_export_order
+///
+/// # Contract
+/// - Postcondition: entities sorted by key, edges by
+///   `compare_edges_in_export_order`; the result does not depend on the
+///   order storage returned them in
+pub fn sort_graph_in_export_order(entities: &mut [CodeEntity], edges: &mut [DependencyEdge]) {
+    entities.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    edges.sort_by(compare_edges_in_export_order);
+}
+
+/// `(language, kind, name)` from an ISGL1 key; missing segments are empty
+fn split_key_head_segments(key: &str) -> (&str, &str, &str) {
+    let mut parts = key.splitn(4, ':');
+    let language = parts.next().unwrap_or("");
+    let kind = parts.next().unwrap_or("");
+    let name = parts.next().unwrap_or(key);
+    (language, kind, name)
+}
+
+/// Match a path against a glob (`*`, `?`, `**`)
+///
+/// # 4-Word Name: matches_path_glob_pattern
+///
+/// # Contract
+/// - `*` and `?` never cross `/`; `**` matches any number of directories
+/// - Patterns not starting with `/` or `**` may match at any directory
+///   depth (`*_test.go` matches `svc/a_test.go`), like .gitignore
+pub fn matches_path_glob_pattern(path: &str, pattern: &str) -> bool {
+    let path = path.trim_start_matches("./");
+    let anchored = match pattern.strip_prefix('/') {
+        Some(rest) => rest.to_string(),
+        None if pattern.starts_with("**") => pattern.to_string(),
+        None => format!("**/{}", pattern),
+    };
+    glob_match_bytes_recursive(anchored.as_bytes(), path.as_bytes())
+}
+
+fn glob_match_bytes_recursive(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern {
+        [] => text.is_empty(),
+        [b'*', b'*', b'/', rest @ ..] => {
+            // Zero directories, or consume one segment and retry
+            glob_match_bytes_recursive(rest, text)
+                || text
+                    .iter()
+                    .position(|&c| c == b'/')
+                    .is_some_and(|slash| glob_match_bytes_recursive(pattern, &text[slash + 1..]))
+        }
+        [b'*', b'*', rest @ ..] => (0..=text.len()).any(|i| glob_match_bytes_recursive(rest, &text[i..])),
+        [b'*', rest @ ..] => {
+            let segment_end = text.iter().position(|&c| c == b'/').unwrap_or(text.len());
+            (0..=segment_end).any(|i| glob_match_bytes_recursive(rest, &text[i..]))
+        }
+        [b'?', rest @ ..] => matches!(text.first(), Some(&c) if c != b'/') && glob_match_bytes_recursive(rest, &text[1..]),
+        [c, rest @ ..] => text.first() == Some(c) && glob_match_bytes_recursive(rest, &text[1..]),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_glob_star_stays_in_segment() {
+        assert!(matches_path_glob_pattern("svc/a_test.go", "*_test.go"));
+        assert!(matches_path_glob_pattern("svc/a_test.go", "svc/*.go"));
+        assert!(!matches_path_glob_pattern("svc/inner/a.go", "/svc/*.go"));
+        assert!(matches_path_glob_pattern("svc/inner/a.go", "/svc/**"));
+        assert!(matches_path_glob_pattern("crates/core/src/lib.rs", "crates/**/lib.rs"));
+        assert!(matches_path_glob_pattern("./vendor/x/y.go", "vendor/**"));
+        assert!(!matches_path_glob_pattern("src/main.rs", "*.go"));
+    }
+
+    #[test]
+    fn test_external_placeholder_keys_detected() {
+        assert!(is_external_graph_node_key("rust:struct:Runtime:external-dependency-tokio:0-0"));
+        assert!(is_external_graph_node_key("go:fn:Println:unresolved-reference:0-0"));
+        assert!(!is_external_graph_node_key("go:fn:main:__cmd:T1"));
+    }
+
+    #[test]
+    fn test_every_format_ignores_input_order() {
+        use crate::entities::{EdgeType, EntityType};
+        use crate::go_embedding_promotion_resolver::create_go_test_entity;
+        use crate::serializers::{
+            encode_graph_as_binary, render_graph_as_dot, render_graph_as_graphml, render_graph_as_mermaid,
+            render_graph_as_scip, write_graph_as_ndjson, DotConfig, MermaidConfig,
+        };
+
+        let entities = vec![
+            create_go_test_entity("go:fn:main:__cmd_main:T1", "main", EntityType::Function, "cmd/main.go", &[]),
+            create_go_test_entity("go:fn:Save:__svc_store:T2", "Save", EntityType::Function, "svc/store.go", &[]),
+            create_go_test_entity("go:fn:load:__svc_store:T3", "load", EntityType::Function, "svc/store.go", &[]),
+        ];
+        let edge = |from: &str, to: &str, line: u32| {
+            DependencyEdge::builder()
+                .from_key(from)
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+                .source_location(format!("cmd/main.go:{}", line))
+                .build()
+                .unwrap()
+        };
+        // Two edges that only differ in source location
+        let edges = vec![
+            edge("go:fn:main:__cmd_main:T1", "go:fn:Save:__svc_store:T2", 7),
+            edge("go:fn:main:__cmd_main:T1", "go:fn:Save:__svc_store:T2", 3),
+            edge("go:fn:Save:__svc_store:T2", "go:fn:load:__svc_store:T3", 12),
+            edge("go:fn:main:__cmd_main:T1", "go:fn:Println:unresolved-reference:0-0", 4),
+        ];
+        let render_all = |entities: &[CodeEntity], edges: &[DependencyEdge]| -> Vec<Vec<u8>> {
+            let mut ndjson = Vec::new();
+            write_graph_as_ndjson(&mut ndjson, entities, edges).unwrap();
+            vec![
+                render_graph_as_graphml(entities, edges).into_bytes(),
+                render_graph_as_dot(entities, edges, &DotConfig::default()).into_bytes(),
+                render_graph_as_mermaid(entities, edges, &MermaidConfig::default()).into_bytes(),
+                render_graph_as_scip(entities, edges, "/repo"),
+                encode_graph_as_binary(entities, edges),
+                ndjson,
+            ]
+        };
+
+        let expected = render_all(&entities, &edges);
+        let mut reversed_entities: Vec<CodeEntity> = entities.iter().rev().cloned().collect();
+        let mut reversed_edges: Vec<DependencyEdge> = edges.iter().rev().cloned().collect();
+        assert_eq!(render_all(&reversed_entities, &reversed_edges), expected);
+
+        sort_graph_in_export_order(&mut reversed_entities, &mut reversed_edges);
+        assert_eq!(reversed_entities[0].isgl1_key, "go:fn:Save:__svc_store:T2");
+        assert_eq!(reversed_edges[2].source_location.as_deref(), Some("cmd/main.go:3"));
+        assert_eq!(reversed_edges[3].source_location.as_deref(), Some("cmd/main.go:7"));
+    }
+}
@@ -0,0 +1,142 @@
+//! GraphML export of the dependency graph (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_graphml
+//!
+//! Produces a GraphML document that Gephi and yEd open directly. Nodes carry
+//! `name`, `kind`, `language`, `file`, `line_start`, `line_end`, `external`;
+//! edges carry `edge_type` and `source_location`.
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order};
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// `(id, for, attr.name, attr.type)` of every declared GraphML key
+const GRAPHML_ATTRIBUTE_KEY_TABLE: &[(&str, &str, &str, &str)] = &[
+    ("d_name", "node", "name", "string"),
+    ("d_kind", "node", "kind", "string"),
+    ("d_language", "node", "language", "string"),
+    ("d_file", "node", "file", "string"),
+    ("d_line_start", "node", "line_start", "int"),
+    ("d_line_end", "node", "line_end", "int"),
+    ("d_external", "node", "external", "boolean"),
+    ("d_edge_type", "edge", "edge_type", "string"),
+    ("d_source_location", "edge", "source_location", "string"),
+];
+
+/// Render entities and edges as a GraphML document
+///
+/// # 4-Word Name: render_graph_as_graphml
+///
+/// # Contract
+/// - Postcondition: well-formed XML; every edge endpoint is a declared node
+///   (targets outside the entity set are emitted with `external = true`)
+/// - Node and edge order is deterministic (sorted by key)
+pub fn render_graph_as_graphml(entities: &[CodeEntity], edges: &[DependencyEdge]) -> String {
+    let mut out = String::new();
+    out.push_str("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n");
+    out.push_str("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" ");
+    out.push_str("xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" ");
+    out.push_str("xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\">\n");
+    for (id, domain, name, attr_type) in GRAPHML_ATTRIBUTE_KEY_TABLE {
+        out.push_str(&format!(
+            "  <key id=\"{}\" for=\"{}\" attr.name=\"{}\" attr.type=\"{}\"/>\n",
+            id, domain, name, attr_type
+        ));
+    }
+    out.push_str("  <graph id=\"parseltongue\" edgedefault=\"directed\">\n");
+
+    for node in collect_graph_export_nodes(entities, edges) {
+        out.push_str(&format!("    <node id=\"{}\">\n", escape_xml_attribute_text(&node.key)));
+        push_graphml_data_element(&mut out, "d_name", &node.name);
+        push_graphml_data_element(&mut out, "d_kind", &node.kind);
+        push_graphml_data_element(&mut out, "d_language", &node.language);
+        if !node.external {
+            push_graphml_data_element(&mut out, "d_file", &node.file_path);
+            push_graphml_data_element(&mut out, "d_line_start", &node.line_start.to_string());
+            push_graphml_data_element(&mut out, "d_line_end", &node.line_end.to_string());
+        }
+        push_graphml_data_element(&mut out, "d_external", if node.external { "true" } else { "false" });
+        out.push_str("    </node>\n");
+    }
+
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    for (index, edge) in sorted_edges.iter().enumerate() {
+        out.push_str(&format!(
+            "    <edge id=\"e{}\" source=\"{}\" target=\"{}\">\n",
+            index,
+            escape_xml_attribute_text(edge.from_key.as_str()),
+            escape_xml_attribute_text(edge.to_key.as_str())
+        ));
+        push_graphml_data_element(&mut out, "d_edge_type", edge.edge_type.as_str());
+        if let Some(location) = &edge.source_location {
+            push_graphml_data_element(&mut out, "d_source_location", location);
+        }
+        out.push_str("    </edge>\n");
+    }
+
+    out.push_str("  </graph>\n</graphml>\n");
+    out
+}
+
+fn push_graphml_data_element(out: &mut String, key: &str, value: &str) {
+    out.push_str(&format!(
+        "      <data key=\"{}\">{}</data>\n",
+        key,
+        escape_xml_attribute_text(value)
+    ));
+}
+
+/// Escape the five XML special characters
+fn escape_xml_attribute_text(text: &str) -> String {
+    let mut escaped = String::with_capacity(text.len());
+    for c in text.chars() {
+        match c {
+            '&' => escaped.push_str("&amp;"),
+            '<' => escaped.push_str("&lt;"),
+            '>' => escaped.push_str("&gt;"),
+            '"' => escaped.push_str("&quot;"),
+            '\'' => escaped.push_str("&apos;"),
+            _ => escaped.push(c),
+        }
+    }
+    escaped
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_graphml_declares_every_edge_endpoint() {
+        let entities = vec![create_go_test_entity(
+            "go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[],
+        )];
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:main:__cmd:T1")
+            .to_key("go:fn:Query:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .source_location("cmd/main.go:3")
+            .build()
+            .unwrap()];
+
+        let xml = render_graph_as_graphml(&entities, &edges);
+        assert!(xml.contains("<node id=\"go:fn:main:__cmd:T1\">"));
+        assert!(xml.contains("<data key=\"d_file\">cmd/main.go</data>"));
+        assert!(xml.contains("<data key=\"d_line_start\">1</data>"));
+        assert!(xml.contains("<node id=\"go:fn:Query:unresolved-reference:0-0\">"));
+        assert!(xml.contains("<data key=\"d_external\">true</data>"));
+        assert!(xml.contains("source=\"go:fn:main:__cmd:T1\" target=\"go:fn:Query:unresolved-reference:0-0\""));
+        assert!(xml.contains("<data key=\"d_edge_type\">Calls</data>"));
+        assert!(xml.trim_end().ends_with("</graphml>"));
+    }
+
+    #[test]
+    fn test_xml_special_characters_escaped() {
+        assert_eq!(
+            escape_xml_attribute_text("rust:impl:Vec<T&U>:\"x\""),
+            "rust:impl:Vec&lt;T&amp;U&gt;:&quot;x&quot;"
+        );
+    }
+}
@@ -0,0 +1,312 @@
+//! Self-contained interactive HTML viewer (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_html_viewer
+//!
+//! One `.html` file with the subgraph embedded as JSON and a small inline
+//! script, no network access or external assets: open it from disk or attach
+//! it to a ticket. The viewer runs a force-directed layout, has a search box
+//! (Enter reveals and selects the first match) and shows a node's key, kind
+//! and location when clicked. Nodes farther than `initial_depth` hops from the
+//! root are embedded but hidden; clicking a node expands its neighbours.
+
+use std::collections::{HashMap, VecDeque};
+
+use serde_json::json;
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order};
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// HTML viewer options
+///
+/// # 4-Word Name: HtmlViewerConfig
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct HtmlViewerConfig {
+    /// Page title and heading
+    pub title: String,
+    /// Highlighted node the hop distances are measured from
+    pub root_key: Option<String>,
+    /// Nodes within this many hops of the root are visible on load
+    pub initial_depth: usize,
+}
+
+impl Default for HtmlViewerConfig {
+    fn default() -> Self {
+        Self {
+            title: "Parseltongue graph".to_string(),
+            root_key: None,
+            initial_depth: usize::MAX,
+        }
+    }
+}
+
+/// Render entities and edges as a standalone interactive HTML page
+///
+/// # 4-Word Name: render_graph_as_html_viewer
+///
+/// # Contract
+/// - Postcondition: every node carries `hop`, its undirected distance from
+///   the root (`null` when unreachable or without a root; always visible)
+/// - The embedded JSON cannot close the `<script>` element (`<` is escaped)
+pub fn render_graph_as_html_viewer(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    config: &HtmlViewerConfig,
+) -> String {
+    let nodes = collect_graph_export_nodes(entities, edges);
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    let hops = undirected_hops_from_root(&sorted_edges, config.root_key.as_deref());
+
+    let data = json!({
+        "root": config.root_key,
+        "initialDepth": if config.initial_depth == usize::MAX { None } else { Some(config.initial_depth) },
+        "nodes": nodes.iter().map(|node| json!({
+            "id": node.key,
+            "name": node.name,
+            "kind": node.kind,
+            "lang": node.language,
+            "file": node.file_path,
+            "line": node.line_start,
+            "external": node.external,
+            "hop": hops.get(node.key.as_str()),
+        })).collect::<Vec<_>>(),
+        "edges": sorted_edges.iter().map(|edge| json!({
+            "s": edge.from_key.as_str(),
+            "t": edge.to_key.as_str(),
+            "type": edge.edge_type.as_str(),
+        })).collect::<Vec<_>>(),
+    });
+    let embedded = data.to_string().replace('<', "\\u003c");
+
+    HTML_VIEWER_TEMPLATE
+        .replace("__TITLE__", &escape_html_text(&config.title))
+        .replace("__GRAPH_DATA__", &embedded)
+}
+
+fn undirected_hops_from_root<'a>(edges: &[&'a DependencyEdge], root: Option<&'a str>) -> HashMap<&'a str, usize> {
+    let mut hops = HashMap::new();
+    let Some(root) = root else {
+        return hops;
+    };
+    let mut adjacent: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges {
+        adjacent.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+        adjacent.entry(edge.to_key.as_str()).or_default().push(edge.from_key.as_str());
+    }
+    hops.insert(root, 0);
+    let mut queue = VecDeque::from([root]);
+    while let Some(key) = queue.pop_front() {
+        let next_hop = hops[key] + 1;
+        for &next in adjacent.get(key).into_iter().flatten() {
+            if !hops.contains_key(next) {
+                hops.insert(next, next_hop);
+                queue.push_back(next);
+            }
+        }
+    }
+    hops
+}
+
+fn escape_html_text(text: &str) -> String {
+    text.replace('&', "&amp;").replace('<', "&lt;").replace('>', "&gt;").replace('"', "&quot;")
+}
+
+const HTML_VIEWER_TEMPLATE: &str = r##"<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>__TITLE__</title>
+<style>
+  html, body { margin: 0; height: 100%; font: 13px system-ui, sans-serif; color: #222; }
+  #bar { position: absolute; top: 0; left: 0; right: 0; padding: 8px 12px; background: #f6f6f6; border-bottom: 1px solid #ddd; display: flex; gap: 12px; align-items: center; }
+  #bar h1 { font-size: 14px; margin: 0; }
+  #search { width: 260px; padding: 4px 6px; }
+  #stats { color: #777; }
+  #view { position: absolute; top: 41px; left: 0; right: 300px; bottom: 0; }
+  #panel { position: absolute; top: 41px; right: 0; width: 284px; bottom: 0; padding: 8px; border-left: 1px solid #ddd; overflow: auto; word-break: break-all; }
+  svg { width: 100%; height: 100%; cursor: grab; }
+  line { stroke: #bbb; stroke-width: 1; }
+  line.hot { stroke: #e08a00; stroke-width: 2; }
+  circle { stroke: #fff; stroke-width: 1.5; cursor: pointer; }
+  circle.external { fill: #fff !important; stroke: #999; stroke-dasharray: 3 2; }
+  circle.root { stroke: #222; stroke-width: 3; }
+  circle.match { stroke: #e00; stroke-width: 3; }
+  circle.selected { stroke: #e08a00; stroke-width: 3; }
+  circle.more { stroke: #555; stroke-dasharray: 2 2; }
+  text { font-size: 11px; pointer-events: none; fill: #333; }
+  dt { color: #777; margin-top: 6px; }
+  dd { margin: 0; }
+</style>
+</head>
+<body>
+<div id="bar"><h1>__TITLE__</h1><input id="search" placeholder="Search symbols (Enter to reveal)"><span id="stats"></span></div>
+<div id="view"><svg id="svg"><g id="scene"><g id="edges"></g><g id="nodes"></g></g></svg></div>
+<div id="panel"><p>Click a node to see it and expand its neighbours. Dashed outline: more neighbours hidden.</p></div>
+<script>
+const DATA = __GRAPH_DATA__;
+const SVG_NS = "http://www.w3.org/2000/svg";
+const byId = new Map(DATA.nodes.map(n => [n.id, n]));
+const neighbours = new Map(DATA.nodes.map(n => [n.id, new Set()]));
+DATA.edges.forEach(e => { neighbours.get(e.s).add(e.t); neighbours.get(e.t).add(e.s); });
+const palette = {fn: "#4e79a7", method: "#59a14f", struct: "#f28e2b", class: "#f28e2b", interface: "#b07aa1", trait: "#b07aa1", enum: "#edc948", module: "#76b7b2"};
+const visible = new Set(DATA.nodes.filter(n => DATA.initialDepth === null || n.hop === null || n.hop <= DATA.initialDepth).map(n => n.id));
+let selected = null, matches = new Set(), alpha = 1;
+const view = {x: 0, y: 0, k: 1};
+
+DATA.nodes.forEach((n, i) => {
+  const ring = (n.hop === null ? 3 : n.hop) * 90 + 20, angle = i * 2.399;
+  n.x = Math.cos(angle) * ring; n.y = Math.sin(angle) * ring; n.vx = 0; n.vy = 0;
+});
+
+function reveal(ids) { ids.forEach(id => visible.add(id)); alpha = Math.max(alpha, 0.6); draw(); }
+
+function select(node) {
+  selected = node;
+  reveal(neighbours.get(node.id));
+  const panel = document.getElementById("panel");
+  panel.textContent = "";
+  const dl = document.createElement("dl");
+  [["Name", node.name], ["Kind", node.kind], ["Language", node.lang], ["Location", node.file ? node.file + ":" + node.line : "external"],
+   ["Hops from root", node.hop === null ? "-" : node.hop], ["Neighbours", neighbours.get(node.id).size], ["Key", node.id]].forEach(([k, v]) => {
+    const dt = document.createElement("dt"); dt.textContent = k; dl.appendChild(dt);
+    const dd = document.createElement("dd"); dd.textContent = v; dl.appendChild(dd);
+  });
+  panel.appendChild(dl);
+}
+
+function draw() {
+  const edgeLayer = document.getElementById("edges"), nodeLayer = document.getElementById("nodes");
+  edgeLayer.textContent = ""; nodeLayer.textContent = "";
+  DATA.edges.filter(e => visible.has(e.s) && visible.has(e.t)).forEach(e => {
+    const a = byId.get(e.s), b = byId.get(e.t), line = document.createElementNS(SVG_NS, "line");
+    line.setAttribute("x1", a.x); line.setAttribute("y1", a.y); line.setAttribute("x2", b.x); line.setAttribute("y2", b.y);
+    if (selected && (e.s === selected.id || e.t === selected.id)) line.setAttribute("class", "hot");
+    const title = document.createElementNS(SVG_NS, "title"); title.textContent = e.type; line.appendChild(title);
+    edgeLayer.appendChild(line);
+  });
+  visible.forEach(id => {
+    const n = byId.get(id), circle = document.createElementNS(SVG_NS, "circle");
+    const classes = [];
+    if (n.external) classes.push("external");
+    if (id === DATA.root) classes.push("root");
+    if (matches.has(id)) classes.push("match");
+    if (selected && selected.id === id) classes.push("selected");
+    if ([...neighbours.get(id)].some(other => !visible.has(other))) classes.push("more");
+    circle.setAttribute("class", classes.join(" "));
+    circle.setAttribute("r", id === DATA.root ? 9 : 6);
+    circle.setAttribute("cx", n.x); circle.setAttribute("cy", n.y);
+    circle.setAttribute("fill", palette[n.kind] || "#9c755f");
+    circle.addEventListener("mousedown", event => { event.stopPropagation(); dragging = n; });
+    circle.addEventListener("click", event => { event.stopPropagation(); select(n); });
+    nodeLayer.appendChild(circle);
+    const label = document.createElementNS(SVG_NS, "text");
+    label.setAttribute("x", n.x + 9); label.setAttribute("y", n.y + 4); label.textContent = n.name;
+    nodeLayer.appendChild(label);
+  });
+  document.getElementById("scene").setAttribute("transform", `translate(${view.x},${view.y}) scale(${view.k})`);
+  document.getElementById("stats").textContent = `${visible.size} of ${DATA.nodes.length} nodes shown`;
+}
+
+function tick() {
+  if (alpha > 0.02) {
+    const shown = [...visible].map(id => byId.get(id));
+    for (let i = 0; i < shown.length; i++) for (let j = i + 1; j < shown.length; j++) {
+      const a = shown[i], b = shown[j];
+      let dx = a.x - b.x, dy = a.y - b.y, d2 = dx * dx + dy * dy + 0.01;
+      const f = 900 * alpha / d2;
+      a.vx += dx * f; a.vy += dy * f; b.vx -= dx * f; b.vy -= dy * f;
+    }
+    DATA.edges.filter(e => visible.has(e.s) && visible.has(e.t)).forEach(e => {
+      const a = byId.get(e.s), b = byId.get(e.t), dx = b.x - a.x, dy = b.y - a.y;
+      const d = Math.sqrt(dx * dx + dy * dy) || 1, f = (d - 70) * 0.02 * alpha;
+      a.vx += dx / d * f; a.vy += dy / d * f; b.vx -= dx / d * f; b.vy -= dy / d * f;
+    });
+    shown.forEach(n => {
+      if (n === dragging) return;
+      n.vx -= n.x * 0.002 * alpha; n.vy -= n.y * 0.002 * alpha;
+      n.x += n.vx; n.y += n.vy; n.vx *= 0.6; n.vy *= 0.6;
+    });
+    alpha *= 0.985;
+    draw();
+  }
+  requestAnimationFrame(tick);
+}
+
+const svg = document.getElementById("svg");
+let dragging = null, panning = null;
+function center() { const box = svg.getBoundingClientRect(); view.x = box.width / 2; view.y = box.height / 2; }
+function toScene(event) { const box = svg.getBoundingClientRect(); return {x: (event.clientX - box.left - view.x) / view.k, y: (event.clientY - box.top - view.y) / view.k}; }
+svg.addEventListener("mousedown", event => { panning = {x: event.clientX - view.x, y: event.clientY - view.y}; });
+window.addEventListener("mousemove", event => {
+  if (dragging) { const p = toScene(event); dragging.x = p.x; dragging.y = p.y; alpha = Math.max(alpha, 0.3); draw(); }
+  else if (panning) { view.x = event.clientX - panning.x; view.y = event.clientY - panning.y; draw(); }
+});
+window.addEventListener("mouseup", () => { dragging = null; panning = null; });
+svg.addEventListener("wheel", event => {
+  event.preventDefault();
+  const p = toScene(event), k = Math.min(4, Math.max(0.2, view.k * (event.deltaY < 0 ? 1.1 : 0.9)));
+  const box = svg.getBoundingClientRect();
+  view.x = event.clientX - box.left - p.x * k; view.y = event.clientY - box.top - p.y * k; view.k = k; draw();
+}, {passive: false});
+
+const search = document.getElementById("search");
+search.addEventListener("input", () => {
+  const q = search.value.trim().toLowerCase();
+  matches = new Set(q ? DATA.nodes.filter(n => n.name.toLowerCase().includes(q) || n.id.toLowerCase().includes(q)).map(n => n.id) : []);
+  draw();
+});
+search.addEventListener("keydown", event => {
+  if (event.key !== "Enter" || matches.size === 0) return;
+  const first = byId.get([...matches][0]);
+  reveal([first.id]); select(first);
+  center(); view.x -= first.x * view.k; view.y -= first.y * view.k; draw();
+});
+
+center(); draw(); requestAnimationFrame(tick);
+window.addEventListener("resize", () => { center(); draw(); });
+</script>
+</body>
+</html>
+"##;
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_html_viewer_embeds_hops_and_escapes_script() {
+        let entities = vec![
+            create_go_test_entity("go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[]),
+            create_go_test_entity("go:fn:run:__cmd:T2", "run", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edge = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+        };
+        let edges = vec![
+            edge("go:fn:main:__cmd:T1", "go:fn:run:__cmd:T2"),
+            edge("go:fn:run:__cmd:T2", "go:fn:</script>:unresolved-reference:0-0"),
+        ];
+        let config = HtmlViewerConfig {
+            title: "main & friends".to_string(),
+            root_key: Some("go:fn:main:__cmd:T1".to_string()),
+            initial_depth: 1,
+        };
+
+        let page = render_graph_as_html_viewer(&entities, &edges, &config);
+        assert!(page.starts_with("<!DOCTYPE html>"));
+        assert!(page.contains("<title>main &amp; friends</title>"));
+        assert_eq!(page.matches("</script>").count(), 1);
+
+        let start = page.find("const DATA = ").unwrap() + "const DATA = ".len();
+        let end = start + page[start..].find(";\n").unwrap();
+        let data: serde_json::Value = serde_json::from_str(&page[start..end]).unwrap();
+        assert_eq!(data["initialDepth"], 1);
+        let hop = |name: &str| data["nodes"].as_array().unwrap().iter().find(|n| n["name"] == name).unwrap()["hop"].clone();
+        assert_eq!(hop("main"), 0);
+        assert_eq!(hop("run"), 1);
+        assert_eq!(hop("</script>"), 2);
+        assert_eq!(data["edges"].as_array().unwrap().len(), 2);
+    }
+}
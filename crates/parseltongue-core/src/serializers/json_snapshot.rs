@@ -0,0 +1,411 @@
+//! JSON graph snapshots, optionally zstd-compressed (v1.7.3)
+//!
+//! # 4-Word Naming: write_graph_as_json_snapshot
+//!
+//! `export --format json` writes the whole graph — entities with their
+//! extractor metadata, then edges — as one JSON document, one record per
+//! line:
+//!
+//! ```text
+//! {"format":"parseltongue-graph-snapshot","version":1,"capabilities":{...},"entities":[
+//! {...},
+//! ...],"edges":[
+//! ...]}
+//! ```
+//!
+//! `capabilities` is the extractor capability manifest the graph was built
+//! with (extractor_capability_manifest_builder); graphs indexed before
+//! manifests existed omit it, and readers ignore a shape they don't know.
+//!
+//! An output path ending in `.zst` is zstd-compressed while it is written.
+//! On read, compression is detected from the zstd frame magic whatever the
+//! file is called. Reading streams: bytes are decompressed and parsed
+//! incrementally and records are handed over in fixed-size batches, so
+//! loading a snapshot (`--db snapshot:graph.json.zst`) never holds the
+//! decompressed text, or more than one batch of records, in memory.
+
+use std::fmt;
+use std::fs::File;
+use std::io::{self, BufRead, BufReader, BufWriter, Read, Write};
+use std::marker::PhantomData;
+use std::path::Path;
+
+use serde::de::{self, DeserializeSeed, IgnoredAny, MapAccess, SeqAccess, Visitor};
+use serde::{Deserialize, Serialize};
+
+use super::graph_export_node_table::compare_edges_in_export_order;
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::extractor_capability_manifest_builder::ExtractorCapabilityManifestRecord;
+
+/// `format` field of every snapshot document
+pub const JSON_SNAPSHOT_FORMAT_NAME: &str = "parseltongue-graph-snapshot";
+
+/// Newest snapshot layout this build reads and the one it writes
+pub const JSON_SNAPSHOT_FORMAT_VERSION: u32 = 1;
+
+/// First four bytes of a zstd frame
+pub const ZSTD_FRAME_MAGIC_BYTES: [u8; 4] = [0x28, 0xB5, 0x2F, 0xFD];
+
+/// zstd level for `.zst` outputs (zstd's own default: fast, ~8-10x on JSON)
+pub(crate) const ZSTD_COMPRESSION_LEVEL: i32 = 3;
+
+/// Records handed to a snapshot reader callback
+///
+/// # 4-Word Name: SnapshotRecordBatch
+#[derive(Debug, Clone, PartialEq)]
+pub enum SnapshotRecordBatch {
+    /// The `capabilities` object, handed over when encountered
+    Capabilities(ExtractorCapabilityManifestRecord),
+    Entities(Vec<CodeEntity>),
+    Edges(Vec<DependencyEdge>),
+}
+
+/// Write the graph as a JSON snapshot document
+///
+/// # 4-Word Name: write_graph_as_json_snapshot
+///
+/// # Contract
+/// - Postcondition: entities sorted by key, edges by
+///   `compare_edges_in_export_order`; records are serialized one at a time
+///   (the document is never built in memory); returns (entities, edges)
+/// - `capabilities`: written before the records when given
+pub fn write_graph_as_json_snapshot(
+    out: &mut dyn Write,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    capabilities: Option<&ExtractorCapabilityManifestRecord>,
+) -> io::Result<(usize, usize)> {
+    let mut sorted_entities: Vec<&CodeEntity> = entities.iter().collect();
+    sorted_entities.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+
+    write!(out, "{{\"format\":\"{}\",\"version\":{},", JSON_SNAPSHOT_FORMAT_NAME, JSON_SNAPSHOT_FORMAT_VERSION)?;
+    if let Some(manifest) = capabilities {
+        out.write_all(b"\"capabilities\":")?;
+        serde_json::to_writer(&mut *out, manifest).map_err(io::Error::from)?;
+        out.write_all(b",")?;
+    }
+    out.write_all(b"\"entities\":[")?;
+    write_json_array_records(out, &sorted_entities)?;
+    out.write_all(b"],\"edges\":[")?;
+    write_json_array_records(out, &sorted_edges)?;
+    out.write_all(b"]}\n")?;
+    Ok((entities.len(), edges.len()))
+}
+
+fn write_json_array_records<T: Serialize>(out: &mut dyn Write, records: &[T]) -> io::Result<()> {
+    for (index, record) in records.iter().enumerate() {
+        out.write_all(if index == 0 { &b"\n"[..] } else { &b",\n"[..] })?;
+        serde_json::to_writer(&mut *out, record).map_err(io::Error::from)?;
+    }
+    Ok(())
+}
+
+/// Export output file, zstd-compressed when the path ends in `.zst`
+///
+/// # 4-Word Name: CompressibleExportOutputWriter
+///
+/// Call `finish` when done: it writes the closing zstd frame.
+pub enum CompressibleExportOutputWriter {
+    Plain(BufWriter<File>),
+    Zstd(zstd::stream::write::Encoder<'static, BufWriter<File>>),
+}
+
+impl CompressibleExportOutputWriter {
+    /// Create (truncate) the file at `path`
+    ///
+    /// # 4-Word Name: create_export_output_file
+    pub fn create_export_output_file(path: &Path) -> io::Result<Self> {
+        let file = BufWriter::new(File::create(path)?);
+        if is_zstd_compressed_path(path) {
+            Ok(Self::Zstd(zstd::stream::write::Encoder::new(file, ZSTD_COMPRESSION_LEVEL)?))
+        } else {
+            Ok(Self::Plain(file))
+        }
+    }
+
+    /// Complete the zstd frame (if any) and flush to disk
+    ///
+    /// # 4-Word Name: finish_export_output_file
+    pub fn finish_export_output_file(self) -> io::Result<()> {
+        match self {
+            Self::Plain(mut file) => file.flush(),
+            Self::Zstd(encoder) => encoder.finish()?.flush(),
+        }
+    }
+}
+
+impl Write for CompressibleExportOutputWriter {
+    fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
+        match self {
+            Self::Plain(file) => file.write(buf),
+            Self::Zstd(encoder) => encoder.write(buf),
+        }
+    }
+
+    fn flush(&mut self) -> io::Result<()> {
+        match self {
+            Self::Plain(file) => file.flush(),
+            Self::Zstd(encoder) => encoder.flush(),
+        }
+    }
+}
+
+/// True for `graph.json.zst`-style paths
+///
+/// # 4-Word Name: is_zstd_compressed_path
+pub fn is_zstd_compressed_path(path: &Path) -> bool {
+    path.extension().is_some_and(|ext| ext.eq_ignore_ascii_case("zst"))
+}
+
+/// Open a snapshot file, transparently decompressing zstd frames
+///
+/// # 4-Word Name: open_decompressed_snapshot_reader
+///
+/// # Contract
+/// - Compression is detected from the first bytes, not the file name
+/// - Decompression is streaming: memory is bounded by the zstd window
+pub fn open_decompressed_snapshot_reader(path: &Path) -> io::Result<Box<dyn Read + Send>> {
+    let mut file = BufReader::new(File::open(path)?);
+    let compressed = file.fill_buf()?.starts_with(&ZSTD_FRAME_MAGIC_BYTES);
+    if compressed {
+        Ok(Box::new(BufReader::new(zstd::stream::read::Decoder::with_buffer(file)?)))
+    } else {
+        Ok(Box::new(file))
+    }
+}
+
+/// Parse a snapshot incrementally, handing records over in batches
+///
+/// # 4-Word Name: read_json_snapshot_batches
+///
+/// # Contract
+/// - Precondition: `reader` yields a snapshot document (decompressed)
+/// - Postcondition: `on_batch` received every entity, then every edge, in
+///   batches of at most `batch_size`; returns (entities, edges) read
+/// - Error: not a snapshot, a newer `version`, malformed JSON, or an
+///   `on_batch` error (which stops parsing)
+pub fn read_json_snapshot_batches<F>(reader: impl Read, batch_size: usize, mut on_batch: F) -> io::Result<(usize, usize)>
+where
+    F: FnMut(SnapshotRecordBatch) -> io::Result<()>,
+{
+    let mut deserializer = serde_json::Deserializer::from_reader(reader);
+    let counts = SnapshotDocumentVisitor { batch_size: batch_size.max(1), on_batch: &mut on_batch }
+        .deserialize(&mut deserializer)
+        .map_err(io::Error::from)?;
+    deserializer.end().map_err(io::Error::from)?;
+    Ok(counts)
+}
+
+/// Read a whole snapshot into memory
+///
+/// # 4-Word Name: read_graph_json_snapshot
+pub fn read_graph_json_snapshot(reader: impl Read) -> io::Result<(Vec<CodeEntity>, Vec<DependencyEdge>)> {
+    let (mut entities, mut edges) = (Vec::new(), Vec::new());
+    read_json_snapshot_batches(reader, 4096, |batch| {
+        match batch {
+            SnapshotRecordBatch::Entities(batch) => entities.extend(batch),
+            SnapshotRecordBatch::Edges(batch) => edges.extend(batch),
+            SnapshotRecordBatch::Capabilities(_) => {}
+        }
+        Ok(())
+    })?;
+    Ok((entities, edges))
+}
+
+/// Top-level object: checks `format`/`version`, streams the two arrays
+struct SnapshotDocumentVisitor<'f, F> {
+    batch_size: usize,
+    on_batch: &'f mut F,
+}
+
+impl<'de, 'f, F> DeserializeSeed<'de> for SnapshotDocumentVisitor<'f, F>
+where
+    F: FnMut(SnapshotRecordBatch) -> io::Result<()>,
+{
+    type Value = (usize, usize);
+
+    fn deserialize<D: de::Deserializer<'de>>(self, deserializer: D) -> Result<Self::Value, D::Error> {
+        deserializer.deserialize_map(self)
+    }
+}
+
+impl<'de, 'f, F> Visitor<'de> for SnapshotDocumentVisitor<'f, F>
+where
+    F: FnMut(SnapshotRecordBatch) -> io::Result<()>,
+{
+    type Value = (usize, usize);
+
+    fn expecting(&self, formatter: &mut fmt::Formatter<'_>) -> fmt::Result {
+        formatter.write_str("a parseltongue graph snapshot object")
+    }
+
+    fn visit_map<A: MapAccess<'de>>(self, mut map: A) -> Result<Self::Value, A::Error> {
+        let batch_size = self.batch_size;
+        let on_batch = self.on_batch;
+        let (mut entities, mut edges) = (0, 0);
+        while let Some(key) = map.next_key::<String>()? {
+            match key.as_str() {
+                "format" => {
+                    let name: String = map.next_value()?;
+                    if name != JSON_SNAPSHOT_FORMAT_NAME {
+                        return Err(de::Error::custom(format!("not a graph snapshot (format '{}')", name)));
+                    }
+                }
+                "version" => {
+                    let version: u32 = map.next_value()?;
+                    if version > JSON_SNAPSHOT_FORMAT_VERSION {
+                        return Err(de::Error::custom(format!(
+                            "snapshot version {} is newer than this build reads ({})",
+                            version, JSON_SNAPSHOT_FORMAT_VERSION
+                        )));
+                    }
+                }
+                "capabilities" => {
+                    // A manifest shape this build doesn't know is skipped, not fatal
+                    let value: serde_json::Value = map.next_value()?;
+                    if let Ok(manifest) = serde_json::from_value::<ExtractorCapabilityManifestRecord>(value) {
+                        on_batch(SnapshotRecordBatch::Capabilities(manifest)).map_err(de::Error::custom)?;
+                    }
+                }
+                "entities" => {
+                    entities = map.next_value_seed(RecordArrayBatchSeed {
+                        batch_size,
+                        on_batch: &mut *on_batch,
+                        wrap: SnapshotRecordBatch::Entities,
+                        record: PhantomData,
+                    })?
+                }
+                "edges" => {
+                    edges = map.next_value_seed(RecordArrayBatchSeed {
+                        batch_size,
+                        on_batch: &mut *on_batch,
+                        wrap: SnapshotRecordBatch::Edges,
+                        record: PhantomData,
+                    })?
+                }
+                _ => {
+                    map.next_value::<IgnoredAny>()?;
+                }
+            }
+        }
+        Ok((entities, edges))
+    }
+}
+
+/// One record array, deserialized element by element into batches
+struct RecordArrayBatchSeed<'f, T, F> {
+    batch_size: usize,
+    on_batch: &'f mut F,
+    wrap: fn(Vec<T>) -> SnapshotRecordBatch,
+    record: PhantomData<T>,
+}
+
+impl<'de, 'f, T, F> DeserializeSeed<'de> for RecordArrayBatchSeed<'f, T, F>
+where
+    T: Deserialize<'de>,
+    F: FnMut(SnapshotRecordBatch) -> io::Result<()>,
+{
+    type Value = usize;
+
+    fn deserialize<D: de::Deserializer<'de>>(self, deserializer: D) -> Result<usize, D::Error> {
+        deserializer.deserialize_seq(self)
+    }
+}
+
+impl<'de, 'f, T, F> Visitor<'de> for RecordArrayBatchSeed<'f, T, F>
+where
+    T: Deserialize<'de>,
+    F: FnMut(SnapshotRecordBatch) -> io::Result<()>,
+{
+    type Value = usize;
+
+    fn expecting(&self, formatter: &mut fmt::Formatter<'_>) -> fmt::Result {
+        formatter.write_str("an array of snapshot records")
+    }
+
+    fn visit_seq<S: SeqAccess<'de>>(self, mut seq: S) -> Result<usize, S::Error> {
+        let (batch_size, on_batch, wrap) = (self.batch_size, self.on_batch, self.wrap);
+        let mut batch = Vec::with_capacity(batch_size.min(4096));
+        let mut count = 0;
+        while let Some(record) = seq.next_element::<T>()? {
+            batch.push(record);
+            count += 1;
+            if batch.len() >= batch_size {
+                on_batch(wrap(std::mem::take(&mut batch))).map_err(de::Error::custom)?;
+            }
+        }
+        if !batch.is_empty() {
+            on_batch(wrap(batch)).map_err(de::Error::custom)?;
+        }
+        Ok(count)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_zstd_snapshot_round_trips_in_batches() {
+        let entities: Vec<CodeEntity> = (0..5)
+            .map(|i| {
+                create_go_test_entity(
+                    &format!("go:fn:f{}:__svc:T{}", i, i),
+                    &format!("f{}", i),
+                    EntityType::Function,
+                    "svc/a.go",
+                    &[("signature", "func f()")],
+                )
+            })
+            .collect();
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:f0:__svc:T0")
+            .to_key("go:fn:f1:__svc:T1")
+            .edge_type(EdgeType::Calls)
+            .source_location("svc/a.go:3")
+            .build()
+            .unwrap()];
+
+        let dir = tempfile::TempDir::new().unwrap();
+        let path = dir.path().join("graph.json.zst");
+        let mut out = CompressibleExportOutputWriter::create_export_output_file(&path).unwrap();
+        let manifest = ExtractorCapabilityManifestRecord {
+            format_version: 1,
+            parseltongue_version: "1.7.2".to_string(),
+            languages: Vec::new(),
+        };
+        assert_eq!(write_graph_as_json_snapshot(&mut out, &entities, &edges, Some(&manifest)).unwrap(), (5, 1));
+        out.finish_export_output_file().unwrap();
+        assert!(std::fs::read(&path).unwrap().starts_with(&ZSTD_FRAME_MAGIC_BYTES));
+
+        let mut batches = Vec::new();
+        let reader = open_decompressed_snapshot_reader(&path).unwrap();
+        let counts = read_json_snapshot_batches(reader, 2, |batch| {
+            batches.push(batch);
+            Ok(())
+        })
+        .unwrap();
+        assert_eq!(counts, (5, 1));
+        // The manifest, 5 entities in batches of 2, then the edge
+        assert_eq!(batches.len(), 5);
+        assert_eq!(batches[0], SnapshotRecordBatch::Capabilities(manifest));
+
+        let (read_entities, read_edges) = read_graph_json_snapshot(open_decompressed_snapshot_reader(&path).unwrap()).unwrap();
+        assert_eq!(read_entities, entities);
+        assert_eq!(read_edges, edges);
+    }
+
+    #[test]
+    fn test_rejects_other_documents_and_newer_versions() {
+        let foreign = br#"{"format":"something-else","entities":[]}"#;
+        assert!(read_graph_json_snapshot(&foreign[..]).is_err());
+        let newer = br#"{"format":"parseltongue-graph-snapshot","version":99,"entities":[]}"#;
+        assert!(read_graph_json_snapshot(&newer[..]).is_err());
+        let minimal = br#"{"format":"parseltongue-graph-snapshot","version":1,"extra":{"a":1},"entities":[],"edges":[]}"#;
+        assert_eq!(read_graph_json_snapshot(&minimal[..]).unwrap(), (vec![], vec![]));
+    }
+}
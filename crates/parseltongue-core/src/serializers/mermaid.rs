@@ -0,0 +1,137 @@
+//! Mermaid flowchart rendering (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_mermaid
+//!
+//! Emits a `flowchart` block for a small subgraph, ready to paste into a PR
+//! description (GitHub renders it natively) or an LLM prompt. Mermaid ids
+//! cannot contain ISGL1 key characters, so nodes get short ids (`n0`, `n1`)
+//! and the entity name as label. Edge labels are the edge type; external
+//! targets (unresolved calls) are drawn dashed.
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order};
+use crate::entities::{CodeEntity, DependencyEdge};
+use std::collections::HashMap;
+
+/// Mermaid rendering options
+///
+/// # 4-Word Name: MermaidConfig
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct MermaidConfig {
+    /// Flowchart direction: `LR`, `TD`, ...
+    pub direction: String,
+    /// Highlighted node (usually the `--root` symbol)
+    pub root_key: Option<String>,
+    /// Append `kind` under the node name
+    pub show_entity_kind: bool,
+}
+
+impl Default for MermaidConfig {
+    fn default() -> Self {
+        Self {
+            direction: "LR".to_string(),
+            root_key: None,
+            show_entity_kind: true,
+        }
+    }
+}
+
+/// Render entities and edges as a Mermaid flowchart
+///
+/// # 4-Word Name: render_graph_as_mermaid
+///
+/// # Contract
+/// - Postcondition: one node per edge endpoint or entity, ids in key order;
+///   output starts with `flowchart {direction}` (no code fence)
+/// - Labels are escaped for Mermaid (`"` → `#quot;`)
+pub fn render_graph_as_mermaid(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    config: &MermaidConfig,
+) -> String {
+    let nodes = collect_graph_export_nodes(entities, edges);
+    let node_ids: HashMap<&str, String> = nodes
+        .iter()
+        .enumerate()
+        .map(|(index, node)| (node.key.as_str(), format!("n{}", index)))
+        .collect();
+
+    let mut out = format!("flowchart {}\n", config.direction);
+    for node in &nodes {
+        let label = if config.show_entity_kind && !node.kind.is_empty() {
+            format!("{}<br/><small>{}</small>", escape_mermaid_label_text(&node.name), node.kind)
+        } else {
+            escape_mermaid_label_text(&node.name)
+        };
+        out.push_str(&format!("    {}[\"{}\"]\n", node_ids[node.key.as_str()], label));
+    }
+
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    for edge in sorted_edges {
+        out.push_str(&format!(
+            "    {} -->|{}| {}\n",
+            node_ids[edge.from_key.as_str()],
+            edge.edge_type.as_str(),
+            node_ids[edge.to_key.as_str()]
+        ));
+    }
+
+    let external: Vec<&str> = nodes
+        .iter()
+        .filter(|n| n.external)
+        .map(|n| node_ids[n.key.as_str()].as_str())
+        .collect();
+    if !external.is_empty() {
+        out.push_str("    classDef external stroke-dasharray: 4 3,color:#777\n");
+        out.push_str(&format!("    class {} external\n", external.join(",")));
+    }
+    if let Some(root_id) = config.root_key.as_deref().and_then(|k| node_ids.get(k)) {
+        out.push_str("    classDef root stroke-width:3px,fill:#fff3c4\n");
+        out.push_str(&format!("    class {} root\n", root_id));
+    }
+    out
+}
+
+/// Escape text for a quoted Mermaid label
+fn escape_mermaid_label_text(text: &str) -> String {
+    text.replace('"', "#quot;")
+        .replace('<', "#lt;")
+        .replace('>', "#gt;")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_mermaid_flowchart_with_root_and_external() {
+        let entities = vec![
+            create_go_test_entity("go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[]),
+            create_go_test_entity("go:fn:run:__cmd:T2", "run", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![
+            DependencyEdge::builder().from_key("go:fn:main:__cmd:T1").to_key("go:fn:run:__cmd:T2")
+                .edge_type(EdgeType::Calls).build().unwrap(),
+            DependencyEdge::builder().from_key("go:fn:run:__cmd:T2").to_key("go:fn:Query:unresolved-reference:0-0")
+                .edge_type(EdgeType::Calls).build().unwrap(),
+        ];
+        let config = MermaidConfig { root_key: Some("go:fn:main:__cmd:T1".to_string()), ..Default::default() };
+
+        let chart = render_graph_as_mermaid(&entities, &edges, &config);
+        let lines: Vec<&str> = chart.lines().collect();
+        assert_eq!(lines[0], "flowchart LR");
+        // Key order: go:fn:Query... < go:fn:main... < go:fn:run...
+        assert_eq!(lines[1], "    n0[\"Query<br/><small>fn</small>\"]");
+        assert!(chart.contains("    n1 -->|Calls| n2\n"));
+        assert!(chart.contains("    n2 -->|Calls| n0\n"));
+        assert!(chart.contains("    class n0 external\n"));
+        assert!(chart.contains("    class n1 root\n"));
+    }
+
+    #[test]
+    fn test_mermaid_label_escaping() {
+        assert_eq!(escape_mermaid_label_text("Vec<\"T\">"), "Vec#lt;#quot;T#quot;#gt;");
+    }
+}
@@ -17,17 +17,51 @@
 //! - **JSON**: Standard format for tool compatibility
 //! - **TOON**: Tab-Oriented Object Notation for 30-40% token reduction
 //! - **Mermaid**: GitHub-native graph visualization with semantic edge directionality
+//! - **GraphML**: Dependency graph for Gephi/yEd (v1.7.3)
+//! - **DOT**: Graphviz digraph clustered by package (v1.7.3)
+//! - **SCIP**: Protobuf code-navigation index for Sourcegraph-style tools (v1.7.3)
+//! - **Binary** (`.ptg`): Compact mmappable graph with string interning (v1.7.3)
+//! - **NDJSON**: One node/edge per line, written without buffering (v1.7.3)
+//! - **API surface**: Signatures-only listing of exported symbols (v1.7.3)
+//! - **JSON snapshot**: Whole graph, optionally zstd-compressed (`.json.zst`) (v1.7.3)
+//! - **Neo4j CSV**: `neo4j-admin import` node/relationship files (v1.7.3)
+//! - **Parquet**: Node and edge tables for DuckDB/Spark analytics (v1.7.3)
+//! - **HTML viewer**: Self-contained interactive subgraph page (v1.7.3)
 
 use anyhow::Result;
 use serde::Serialize;
 use std::path::{Path, PathBuf};
 
+pub mod api_surface; // v1.7.3: Signatures-only API listing
+pub mod binary; // v1.7.3: Compact mmappable graph format
+pub mod dot; // v1.7.3: Graphviz export with package clusters
+pub mod graph_export_node_table; // v1.7.3: Shared node rows for graph formats
+pub mod graphml; // v1.7.3: Gephi/yEd export
+pub mod html_viewer; // v1.7.3: Single-file interactive subgraph viewer
 pub mod json;
-// pub mod mermaid; // v0.9.8+: Deferred per user request (agent queryability first)
+pub mod json_snapshot; // v1.7.3: Whole-graph snapshots, zstd-transparent
+pub mod mermaid; // v1.7.3: Subgraph flowcharts (was deferred in v0.9.8)
+pub mod ndjson; // v1.7.3: Line-per-record streaming export
+pub mod neo4j_csv; // v1.7.3: neo4j-admin bulk-import CSVs
+pub mod parquet; // v1.7.3: Node/edge tables for analytics engines
+pub mod scip; // v1.7.3: SCIP index for code-navigation tools
 pub mod toon;
 
+pub use api_surface::{render_api_surface_signatures, ApiSurfaceConfig};
+pub use binary::{encode_graph_as_binary, CompactBinaryGraphView, MappedBinaryGraphFile};
+pub use dot::{render_graph_as_dot, DotConfig};
+pub use graphml::render_graph_as_graphml;
+pub use html_viewer::{render_graph_as_html_viewer, HtmlViewerConfig};
 pub use json::JsonSerializer;
-// pub use mermaid::{render_graph_as_mermaid, MermaidConfig}; // v0.9.8+
+pub use json_snapshot::{
+    open_decompressed_snapshot_reader, read_json_snapshot_batches, write_graph_as_json_snapshot,
+    CompressibleExportOutputWriter, SnapshotRecordBatch,
+};
+pub use mermaid::{render_graph_as_mermaid, MermaidConfig};
+pub use ndjson::write_graph_as_ndjson;
+pub use neo4j_csv::{render_graph_as_neo4j_csv, Neo4jCsvImportFiles};
+pub use parquet::{render_graph_as_parquet_tables, ParquetGraphTableFiles};
+pub use scip::render_graph_as_scip;
 pub use toon::{ToonDelimiter, ToonSerializer};
 
 /// Core serialization trait for data export formats
@@ -0,0 +1,149 @@
+//! NDJSON export of the dependency graph (v1.7.3)
+//!
+//! # 4-Word Naming: write_graph_as_ndjson
+//!
+//! One JSON object per line, written straight to an `io::Write` so nothing
+//! is buffered beyond the current record. `jq -c 'select(.type == "edge")'`
+//! and BigQuery's newline-delimited JSON loader read it as is:
+//!
+//! ```text
+//! {"type":"node","key":"go:fn:Save:...","name":"Save","kind":"fn","language":"go","file_path":"store/disk.go","line_start":10,"line_end":14,"external":false}
+//! {"type":"edge","from":"go:fn:handle:...","to":"go:fn:Save:...","edge_type":"Calls","source_location":"api/handler.go:22"}
+//! ```
+//!
+//! Node fields match the GraphML/DOT node table; external nodes have an
+//! empty `file_path` and zero lines.
+
+use std::io::{self, Write};
+
+use serde::Serialize;
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order, GraphExportNodeRow};
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// One NDJSON line
+///
+/// # 4-Word Name: NdjsonGraphRecordLine
+#[derive(Debug, Serialize)]
+#[serde(tag = "type", rename_all = "snake_case")]
+pub enum NdjsonGraphRecordLine<'a> {
+    Node {
+        key: &'a str,
+        name: &'a str,
+        kind: &'a str,
+        language: &'a str,
+        file_path: &'a str,
+        line_start: u32,
+        line_end: u32,
+        external: bool,
+    },
+    Edge {
+        from: &'a str,
+        to: &'a str,
+        edge_type: &'a str,
+        #[serde(skip_serializing_if = "Option::is_none")]
+        source_location: Option<&'a str>,
+    },
+}
+
+/// Write the whole graph: every node line, then every edge line
+///
+/// # 4-Word Name: write_graph_as_ndjson
+///
+/// # Contract
+/// - Postcondition: same nodes as `collect_graph_export_nodes`, edges sorted
+///   by `compare_edges_in_export_order`; returns (nodes, edges) written
+pub fn write_graph_as_ndjson(
+    out: &mut dyn Write,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> io::Result<(usize, usize)> {
+    let nodes = write_node_rows_as_ndjson(out, &collect_graph_export_nodes(entities, edges))?;
+    let mut sorted: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    let edges = write_edges_as_ndjson(out, sorted)?;
+    Ok((nodes, edges))
+}
+
+/// Write one `node` line per row
+///
+/// # 4-Word Name: write_node_rows_as_ndjson
+pub fn write_node_rows_as_ndjson(out: &mut dyn Write, nodes: &[GraphExportNodeRow]) -> io::Result<usize> {
+    for node in nodes {
+        write_ndjson_record_line(
+            out,
+            &NdjsonGraphRecordLine::Node {
+                key: &node.key,
+                name: &node.name,
+                kind: &node.kind,
+                language: &node.language,
+                file_path: &node.file_path,
+                line_start: node.line_start,
+                line_end: node.line_end,
+                external: node.external,
+            },
+        )?;
+    }
+    Ok(nodes.len())
+}
+
+/// Write one `edge` line per edge, in iteration order
+///
+/// # 4-Word Name: write_edges_as_ndjson
+pub fn write_edges_as_ndjson<'e>(
+    out: &mut dyn Write,
+    edges: impl IntoIterator<Item = &'e DependencyEdge>,
+) -> io::Result<usize> {
+    let mut count = 0;
+    for edge in edges {
+        write_ndjson_record_line(
+            out,
+            &NdjsonGraphRecordLine::Edge {
+                from: edge.from_key.as_str(),
+                to: edge.to_key.as_str(),
+                edge_type: edge.edge_type.as_str(),
+                source_location: edge.source_location.as_deref().filter(|l| !l.is_empty()),
+            },
+        )?;
+        count += 1;
+    }
+    Ok(count)
+}
+
+fn write_ndjson_record_line(out: &mut dyn Write, record: &NdjsonGraphRecordLine<'_>) -> io::Result<()> {
+    serde_json::to_writer(&mut *out, record)?;
+    out.write_all(b"\n")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_one_json_object_per_line() {
+        let entities = vec![create_go_test_entity("go:fn:save:__db:T2", "save", EntityType::Function, "db/save.go", &[])];
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:save:__db:T2")
+            .to_key("go:fn:Printf:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .build()
+            .unwrap()];
+
+        let mut out = Vec::new();
+        assert_eq!(write_graph_as_ndjson(&mut out, &entities, &edges).unwrap(), (2, 1));
+        let lines: Vec<serde_json::Value> = String::from_utf8(out)
+            .unwrap()
+            .lines()
+            .map(|line| serde_json::from_str(line).unwrap())
+            .collect();
+        assert_eq!(lines.len(), 3);
+        assert_eq!(lines[0]["type"], "node");
+        assert_eq!(lines[0]["external"], true, "Printf sorts before save");
+        assert_eq!(lines[1]["name"], "save");
+        assert_eq!(lines[2]["type"], "edge");
+        assert_eq!(lines[2]["edge_type"], "Calls");
+        assert!(lines[2].get("source_location").is_none());
+    }
+}
@@ -0,0 +1,134 @@
+//! Neo4j bulk-import CSV export of the dependency graph (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_neo4j_csv
+//!
+//! Writes the two files `neo4j-admin database import full` reads:
+//!
+//! ```text
+//! nodes.csv          key:ID,name,kind,language,file,line_start:int,line_end:int,external:boolean,:LABEL
+//! relationships.csv  :START_ID,:END_ID,:TYPE,location
+//! ```
+//!
+//! Every node carries the `Entity` label plus one per kind (`Function`,
+//! `Method`, `Struct`, ...) and `External` for targets only known from an
+//! edge; relationship types are the edge types in SCREAMING_SNAKE
+//! (`CALLS`, `SENDS_TO`, ...). These are the labels and types the daemon's
+//! Cypher endpoint matches, so queries move between the two unchanged.
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order};
+use crate::cypher_subset_query_evaluator::{cypher_node_label_for_kind, cypher_relationship_type_name};
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// File name of the node CSV inside the export directory
+pub const NEO4J_NODES_FILE_NAME: &str = "nodes.csv";
+
+/// File name of the relationship CSV inside the export directory
+pub const NEO4J_RELATIONSHIPS_FILE_NAME: &str = "relationships.csv";
+
+/// Node and relationship CSV documents
+///
+/// # 4-Word Name: Neo4jCsvImportFiles
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Neo4jCsvImportFiles {
+    pub nodes_csv: String,
+    pub relationships_csv: String,
+    pub node_count: usize,
+    pub relationship_count: usize,
+}
+
+/// Render entities and edges as neo4j-admin import CSVs
+///
+/// # 4-Word Name: render_graph_as_neo4j_csv
+///
+/// # Contract
+/// - Postcondition: every relationship endpoint is a node ID (targets
+///   outside the entity set are emitted as `External` nodes)
+/// - Every field is double-quoted with embedded quotes doubled; lines are
+///   sorted by key / export edge order
+pub fn render_graph_as_neo4j_csv(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Neo4jCsvImportFiles {
+    let nodes = collect_graph_export_nodes(entities, edges);
+    let mut nodes_csv = String::from("key:ID,name,kind,language,file,line_start:int,line_end:int,external:boolean,:LABEL\n");
+    for node in &nodes {
+        let mut labels = vec!["Entity".to_string(), cypher_node_label_for_kind(&node.kind)];
+        if node.external {
+            labels.push("External".to_string());
+        }
+        labels.dedup();
+        // Empty unquoted fields import as missing properties
+        let (line_start, line_end) = if node.external {
+            (String::new(), String::new())
+        } else {
+            (node.line_start.to_string(), node.line_end.to_string())
+        };
+        nodes_csv.push_str(&format!(
+            "{},{},{},{},{},{},{},{},{}\n",
+            quote_csv_field_text(&node.key),
+            quote_csv_field_text(&node.name),
+            quote_csv_field_text(&node.kind),
+            quote_csv_field_text(&node.language),
+            if node.external { String::new() } else { quote_csv_field_text(&node.file_path) },
+            line_start,
+            line_end,
+            node.external,
+            quote_csv_field_text(&labels.join(";")),
+        ));
+    }
+
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    let mut relationships_csv = String::from(":START_ID,:END_ID,:TYPE,location\n");
+    for edge in &sorted_edges {
+        relationships_csv.push_str(&format!(
+            "{},{},{},{}\n",
+            quote_csv_field_text(edge.from_key.as_str()),
+            quote_csv_field_text(edge.to_key.as_str()),
+            cypher_relationship_type_name(edge.edge_type),
+            edge.source_location.as_deref().map(quote_csv_field_text).unwrap_or_default(),
+        ));
+    }
+
+    Neo4jCsvImportFiles {
+        nodes_csv,
+        relationships_csv,
+        node_count: nodes.len(),
+        relationship_count: sorted_edges.len(),
+    }
+}
+
+/// `"text"` with embedded quotes doubled
+fn quote_csv_field_text(text: &str) -> String {
+    format!("\"{}\"", text.replace('"', "\"\""))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_neo4j_csv_labels_types_and_external_nodes() {
+        let entities = vec![create_go_test_entity(
+            "go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[],
+        )];
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:main:__cmd:T1")
+            .to_key("go:chan:jobs:unresolved-reference:0-0")
+            .edge_type(EdgeType::SendsTo)
+            .source_location("cmd/main.go:3")
+            .build()
+            .unwrap()];
+
+        let files = render_graph_as_neo4j_csv(&entities, &edges);
+        let node_lines: Vec<&str> = files.nodes_csv.lines().collect();
+        assert_eq!(node_lines.len(), 3);
+        assert!(node_lines.contains(&"\"go:fn:main:__cmd:T1\",\"main\",\"fn\",\"go\",\"cmd/main.go\",1,5,false,\"Entity;Function\""));
+        assert!(node_lines.contains(&"\"go:chan:jobs:unresolved-reference:0-0\",\"jobs\",\"chan\",\"go\",,,,true,\"Entity;Chan;External\""));
+        assert_eq!(
+            files.relationships_csv.lines().nth(1),
+            Some("\"go:fn:main:__cmd:T1\",\"go:chan:jobs:unresolved-reference:0-0\",SENDS_TO,\"cmd/main.go:3\"")
+        );
+        assert_eq!((files.node_count, files.relationship_count), (2, 1));
+        assert_eq!(quote_csv_field_text("say \"hi\""), "\"say \"\"hi\"\"\"");
+    }
+}
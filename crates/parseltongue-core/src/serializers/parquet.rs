@@ -0,0 +1,761 @@
+//! Parquet export of the dependency graph (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_parquet_tables
+//!
+//! Writes two flat tables that DuckDB, Spark, Polars or pandas read
+//! directly:
+//!
+//! ```text
+//! nodes.parquet  key, name, kind, language, file_path, line_start, line_end, external, metadata
+//! edges.parquet  from_key, to_key, edge_type, source_location, provenance, confidence_score, metadata
+//! ```
+//!
+//! Nodes are the rows of `collect_graph_export_nodes` (edge targets that
+//! are not entities are `external = true`, with null file and lines);
+//! `metadata` is the extractor metadata as a JSON object string (null when
+//! empty), so `json_extract(metadata, '$.owner')` works in DuckDB.
+//! `provenance` / `confidence_score` are `assess_edge_resolution_confidence`.
+//!
+//! ## Encoding
+//!
+//! The writer is self-contained, like the SCIP protobuf codec: format
+//! version 1, row groups of `PARQUET_ROW_GROUP_ROW_COUNT` rows, one
+//! uncompressed PLAIN data page per column chunk, Thrift compact protocol
+//! footer. Text and integer columns are OPTIONAL (definition levels as a
+//! bit-packed hybrid run), `confidence_score` and `external` are REQUIRED.
+//! No statistics or dictionary pages are written; rows follow the shared
+//! export order, so the same graph always yields the same bytes.
+
+use std::collections::BTreeMap;
+
+use super::graph_export_node_table::{collect_graph_export_nodes, compare_edges_in_export_order};
+use crate::edge_confidence_provenance_scorer::assess_edge_resolution_confidence;
+use crate::entities::{CodeEntity, DependencyEdge};
+
+/// File name of the node table inside the export directory
+pub const PARQUET_NODES_FILE_NAME: &str = "nodes.parquet";
+
+/// File name of the edge table inside the export directory
+pub const PARQUET_EDGES_FILE_NAME: &str = "edges.parquet";
+
+/// Rows per row group (readers parallelize over row groups)
+pub const PARQUET_ROW_GROUP_ROW_COUNT: usize = 65_536;
+
+const PARQUET_MAGIC_BYTES: &[u8; 4] = b"PAR1";
+
+// Thrift compact protocol type ids
+const THRIFT_I32: u8 = 5;
+const THRIFT_I64: u8 = 6;
+const THRIFT_BINARY: u8 = 8;
+const THRIFT_LIST: u8 = 9;
+const THRIFT_STRUCT: u8 = 12;
+
+// parquet.thrift enums
+const PARQUET_TYPE_BOOLEAN: i32 = 0;
+const PARQUET_TYPE_INT64: i32 = 2;
+const PARQUET_TYPE_DOUBLE: i32 = 5;
+const PARQUET_TYPE_BYTE_ARRAY: i32 = 6;
+const REPETITION_REQUIRED: i32 = 0;
+const REPETITION_OPTIONAL: i32 = 1;
+const CONVERTED_TYPE_UTF8: i32 = 0;
+const ENCODING_PLAIN: i32 = 0;
+const ENCODING_RLE: i32 = 3;
+const CODEC_UNCOMPRESSED: i32 = 0;
+const PAGE_TYPE_DATA_PAGE: i32 = 0;
+
+/// Values of one table column
+///
+/// # 4-Word Name: ParquetColumnValueList
+#[derive(Debug, Clone, PartialEq)]
+pub enum ParquetColumnValueList {
+    /// OPTIONAL BYTE_ARRAY (UTF8)
+    Text(Vec<Option<String>>),
+    /// OPTIONAL INT64
+    Int64(Vec<Option<i64>>),
+    /// REQUIRED DOUBLE
+    Double(Vec<f64>),
+    /// REQUIRED BOOLEAN
+    Boolean(Vec<bool>),
+}
+
+impl ParquetColumnValueList {
+    fn row_count(&self) -> usize {
+        match self {
+            Self::Text(values) => values.len(),
+            Self::Int64(values) => values.len(),
+            Self::Double(values) => values.len(),
+            Self::Boolean(values) => values.len(),
+        }
+    }
+
+    fn physical_type(&self) -> i32 {
+        match self {
+            Self::Text(_) => PARQUET_TYPE_BYTE_ARRAY,
+            Self::Int64(_) => PARQUET_TYPE_INT64,
+            Self::Double(_) => PARQUET_TYPE_DOUBLE,
+            Self::Boolean(_) => PARQUET_TYPE_BOOLEAN,
+        }
+    }
+
+    fn is_optional(&self) -> bool {
+        matches!(self, Self::Text(_) | Self::Int64(_))
+    }
+
+    /// Definition levels (optional columns) and PLAIN values of rows `start..end`
+    fn encode_page_data(&self, start: usize, end: usize) -> Vec<u8> {
+        let mut page = Vec::new();
+        match self {
+            Self::Text(values) => {
+                let rows = &values[start..end];
+                encode_definition_levels(&mut page, rows.iter().map(Option::is_some));
+                for text in rows.iter().flatten() {
+                    page.extend_from_slice(&(text.len() as u32).to_le_bytes());
+                    page.extend_from_slice(text.as_bytes());
+                }
+            }
+            Self::Int64(values) => {
+                let rows = &values[start..end];
+                encode_definition_levels(&mut page, rows.iter().map(Option::is_some));
+                for value in rows.iter().flatten() {
+                    page.extend_from_slice(&value.to_le_bytes());
+                }
+            }
+            Self::Double(values) => {
+                for value in &values[start..end] {
+                    page.extend_from_slice(&value.to_le_bytes());
+                }
+            }
+            Self::Boolean(values) => page.extend(pack_bits_lsb_first(values[start..end].iter().copied())),
+        }
+        page
+    }
+}
+
+/// One named column of a Parquet table
+///
+/// # 4-Word Name: ParquetTableColumnEntry
+#[derive(Debug, Clone, PartialEq)]
+pub struct ParquetTableColumnEntry {
+    pub name: &'static str,
+    pub values: ParquetColumnValueList,
+}
+
+/// Node and edge table files
+///
+/// # 4-Word Name: ParquetGraphTableFiles
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ParquetGraphTableFiles {
+    pub nodes_parquet: Vec<u8>,
+    pub edges_parquet: Vec<u8>,
+    pub node_count: usize,
+    pub edge_count: usize,
+}
+
+/// Render entities and edges as the two Parquet tables
+///
+/// # 4-Word Name: render_graph_as_parquet_tables
+///
+/// # Contract
+/// - Postcondition: one node row per entity and per edge endpoint that is
+///   not an entity, sorted by key; one edge row per edge, in export order
+/// - Postcondition: entity metadata is read from `metadata.additional`
+///   (load entities with their extractor metadata to export it)
+pub fn render_graph_as_parquet_tables(entities: &[CodeEntity], edges: &[DependencyEdge]) -> ParquetGraphTableFiles {
+    let metadata_by_key: BTreeMap<&str, String> = entities
+        .iter()
+        .filter(|e| !e.metadata.additional.is_empty())
+        .map(|e| {
+            let sorted: BTreeMap<&String, &String> = e.metadata.additional.iter().collect();
+            (e.isgl1_key.as_str(), serde_json::to_string(&sorted).unwrap_or_default())
+        })
+        .collect();
+
+    let nodes = collect_graph_export_nodes(entities, edges);
+    let located = |external: bool, value: i64| (!external).then_some(value);
+    let node_columns = vec![
+        text_column("key", nodes.iter().map(|n| Some(n.key.clone()))),
+        text_column("name", nodes.iter().map(|n| Some(n.name.clone()))),
+        text_column("kind", nodes.iter().map(|n| Some(n.kind.clone()))),
+        text_column("language", nodes.iter().map(|n| Some(n.language.clone()))),
+        text_column("file_path", nodes.iter().map(|n| (!n.external).then(|| n.file_path.clone()))),
+        ParquetTableColumnEntry {
+            name: "line_start",
+            values: ParquetColumnValueList::Int64(nodes.iter().map(|n| located(n.external, n.line_start.into())).collect()),
+        },
+        ParquetTableColumnEntry {
+            name: "line_end",
+            values: ParquetColumnValueList::Int64(nodes.iter().map(|n| located(n.external, n.line_end.into())).collect()),
+        },
+        ParquetTableColumnEntry {
+            name: "external",
+            values: ParquetColumnValueList::Boolean(nodes.iter().map(|n| n.external).collect()),
+        },
+        text_column("metadata", nodes.iter().map(|n| metadata_by_key.get(n.key.as_str()).cloned())),
+    ];
+
+    let mut sorted_edges: Vec<&DependencyEdge> = edges.iter().collect();
+    sorted_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+    let assessments: Vec<_> = sorted_edges.iter().map(|e| assess_edge_resolution_confidence(e)).collect();
+    let edge_columns = vec![
+        text_column("from_key", sorted_edges.iter().map(|e| Some(e.from_key.as_str().to_string()))),
+        text_column("to_key", sorted_edges.iter().map(|e| Some(e.to_key.as_str().to_string()))),
+        text_column("edge_type", sorted_edges.iter().map(|e| Some(e.edge_type.as_str().to_string()))),
+        text_column("source_location", sorted_edges.iter().map(|e| e.source_location.clone())),
+        text_column("provenance", assessments.iter().map(|a| Some(a.provenance.as_str().to_string()))),
+        ParquetTableColumnEntry {
+            name: "confidence_score",
+            values: ParquetColumnValueList::Double(assessments.iter().map(|a| a.score).collect()),
+        },
+        text_column(
+            "metadata",
+            sorted_edges
+                .iter()
+                .map(|e| (!e.metadata.is_empty()).then(|| serde_json::to_string(&e.metadata).unwrap_or_default())),
+        ),
+    ];
+
+    ParquetGraphTableFiles {
+        nodes_parquet: encode_parquet_table_bytes(&node_columns),
+        edges_parquet: encode_parquet_table_bytes(&edge_columns),
+        node_count: nodes.len(),
+        edge_count: sorted_edges.len(),
+    }
+}
+
+/// Encode columns of equal length as one Parquet file
+///
+/// # 4-Word Name: encode_parquet_table_bytes
+///
+/// # Contract
+/// - Precondition: every column has the same number of rows
+/// - Postcondition: `PAR1` + row groups + footer + footer length + `PAR1`;
+///   an empty table has a schema and no row groups
+pub fn encode_parquet_table_bytes(columns: &[ParquetTableColumnEntry]) -> Vec<u8> {
+    let row_count = columns.first().map_or(0, |c| c.values.row_count());
+    let mut file = PARQUET_MAGIC_BYTES.to_vec();
+
+    // (per column: data page offset, chunk bytes), rows
+    let mut row_groups: Vec<(Vec<(usize, usize)>, usize)> = Vec::new();
+    for start in (0..row_count).step_by(PARQUET_ROW_GROUP_ROW_COUNT) {
+        let end = (start + PARQUET_ROW_GROUP_ROW_COUNT).min(row_count);
+        let mut chunks = Vec::new();
+        for column in columns {
+            let data = column.values.encode_page_data(start, end);
+            let mut header = ThriftCompactByteEncoder::default();
+            header.i32_field(1, PAGE_TYPE_DATA_PAGE);
+            header.i32_field(2, data.len() as i32);
+            header.i32_field(3, data.len() as i32);
+            header.begin_struct_field(5);
+            header.i32_field(1, (end - start) as i32);
+            header.i32_field(2, ENCODING_PLAIN);
+            header.i32_field(3, ENCODING_RLE);
+            header.i32_field(4, ENCODING_RLE);
+            header.end_struct();
+            let header = header.finish();
+
+            let offset = file.len();
+            file.extend_from_slice(&header);
+            file.extend_from_slice(&data);
+            chunks.push((offset, header.len() + data.len()));
+        }
+        row_groups.push((chunks, end - start));
+    }
+
+    let mut footer = ThriftCompactByteEncoder::default();
+    footer.i32_field(1, 1);
+    footer.list_field_header(2, THRIFT_STRUCT, columns.len() + 1);
+    footer.begin_struct_element();
+    footer.binary_field(4, b"schema");
+    footer.i32_field(5, columns.len() as i32);
+    footer.end_struct();
+    for column in columns {
+        footer.begin_struct_element();
+        footer.i32_field(1, column.values.physical_type());
+        let repetition = if column.values.is_optional() { REPETITION_OPTIONAL } else { REPETITION_REQUIRED };
+        footer.i32_field(3, repetition);
+        footer.binary_field(4, column.name.as_bytes());
+        if matches!(column.values, ParquetColumnValueList::Text(_)) {
+            footer.i32_field(6, CONVERTED_TYPE_UTF8);
+        }
+        footer.end_struct();
+    }
+    footer.i64_field(3, row_count as i64);
+    footer.list_field_header(4, THRIFT_STRUCT, row_groups.len());
+    for (chunks, rows) in &row_groups {
+        footer.begin_struct_element();
+        footer.list_field_header(1, THRIFT_STRUCT, chunks.len());
+        for (column, (offset, size)) in columns.iter().zip(chunks) {
+            footer.begin_struct_element();
+            footer.i64_field(2, *offset as i64);
+            footer.begin_struct_field(3);
+            footer.i32_field(1, column.values.physical_type());
+            footer.list_field_header(2, THRIFT_I32, 2);
+            footer.write_i32_value(ENCODING_PLAIN);
+            footer.write_i32_value(ENCODING_RLE);
+            footer.list_field_header(3, THRIFT_BINARY, 1);
+            footer.write_binary_value(column.name.as_bytes());
+            footer.i32_field(4, CODEC_UNCOMPRESSED);
+            footer.i64_field(5, *rows as i64);
+            footer.i64_field(6, *size as i64);
+            footer.i64_field(7, *size as i64);
+            footer.i64_field(9, *offset as i64);
+            footer.end_struct();
+            footer.end_struct();
+        }
+        footer.i64_field(2, chunks.iter().map(|(_, size)| *size as i64).sum());
+        footer.i64_field(3, *rows as i64);
+        footer.end_struct();
+    }
+    footer.binary_field(6, b"parseltongue");
+    let footer = footer.finish();
+
+    file.extend_from_slice(&footer);
+    file.extend_from_slice(&(footer.len() as u32).to_le_bytes());
+    file.extend_from_slice(PARQUET_MAGIC_BYTES);
+    file
+}
+
+fn text_column(name: &'static str, values: impl Iterator<Item = Option<String>>) -> ParquetTableColumnEntry {
+    ParquetTableColumnEntry { name, values: ParquetColumnValueList::Text(values.collect()) }
+}
+
+/// Max definition level 1: 4-byte length, then one bit-packed run of width 1
+fn encode_definition_levels(page: &mut Vec<u8>, present: impl ExactSizeIterator<Item = bool>) {
+    let groups = (present.len() + 7) / 8;
+    let mut levels = Vec::new();
+    write_unsigned_varint(&mut levels, ((groups as u64) << 1) | 1);
+    levels.extend(pack_bits_lsb_first(present));
+    page.extend_from_slice(&(levels.len() as u32).to_le_bytes());
+    page.extend(levels);
+}
+
+/// Booleans 8 per byte, first value in the lowest bit; last byte zero-padded
+fn pack_bits_lsb_first(bits: impl Iterator<Item = bool>) -> Vec<u8> {
+    let mut bytes = Vec::new();
+    for (index, bit) in bits.enumerate() {
+        if index % 8 == 0 {
+            bytes.push(0);
+        }
+        if bit {
+            *bytes.last_mut().unwrap() |= 1 << (index % 8);
+        }
+    }
+    bytes
+}
+
+fn write_unsigned_varint(bytes: &mut Vec<u8>, mut value: u64) {
+    while value >= 0x80 {
+        bytes.push((value as u8) | 0x80);
+        value >>= 7;
+    }
+    bytes.push(value as u8);
+}
+
+/// Thrift compact protocol writer for the footer and page headers
+#[derive(Default)]
+struct ThriftCompactByteEncoder {
+    bytes: Vec<u8>,
+    last_field_id: i16,
+    enclosing_field_ids: Vec<i16>,
+}
+
+impl ThriftCompactByteEncoder {
+    fn field_header(&mut self, id: i16, kind: u8) {
+        let delta = id - self.last_field_id;
+        if (1..=15).contains(&delta) {
+            self.bytes.push(((delta as u8) << 4) | kind);
+        } else {
+            self.bytes.push(kind);
+            write_unsigned_varint(&mut self.bytes, (((id as i64) << 1) ^ ((id as i64) >> 63)) as u64);
+        }
+        self.last_field_id = id;
+    }
+
+    fn write_i32_value(&mut self, value: i32) {
+        write_unsigned_varint(&mut self.bytes, (((value as i64) << 1) ^ ((value as i64) >> 63)) as u64);
+    }
+
+    fn write_binary_value(&mut self, value: &[u8]) {
+        write_unsigned_varint(&mut self.bytes, value.len() as u64);
+        self.bytes.extend_from_slice(value);
+    }
+
+    fn i32_field(&mut self, id: i16, value: i32) {
+        self.field_header(id, THRIFT_I32);
+        self.write_i32_value(value);
+    }
+
+    fn i64_field(&mut self, id: i16, value: i64) {
+        self.field_header(id, THRIFT_I64);
+        write_unsigned_varint(&mut self.bytes, ((value << 1) ^ (value >> 63)) as u64);
+    }
+
+    fn binary_field(&mut self, id: i16, value: &[u8]) {
+        self.field_header(id, THRIFT_BINARY);
+        self.write_binary_value(value);
+    }
+
+    fn list_field_header(&mut self, id: i16, element_kind: u8, size: usize) {
+        self.field_header(id, THRIFT_LIST);
+        if size < 15 {
+            self.bytes.push(((size as u8) << 4) | element_kind);
+        } else {
+            self.bytes.push(0xF0 | element_kind);
+            write_unsigned_varint(&mut self.bytes, size as u64);
+        }
+    }
+
+    fn begin_struct_field(&mut self, id: i16) {
+        self.field_header(id, THRIFT_STRUCT);
+        self.begin_struct_element();
+    }
+
+    fn begin_struct_element(&mut self) {
+        self.enclosing_field_ids.push(self.last_field_id);
+        self.last_field_id = 0;
+    }
+
+    fn end_struct(&mut self) {
+        self.bytes.push(0);
+        self.last_field_id = self.enclosing_field_ids.pop().unwrap_or(0);
+    }
+
+    /// Close the top-level struct
+    fn finish(mut self) -> Vec<u8> {
+        self.bytes.push(0);
+        self.bytes
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    #[test]
+    fn test_parquet_tables_have_framing_and_levels() {
+        assert_eq!(pack_bits_lsb_first([true, false, true, true, false, false, false, false, true].into_iter()), vec![0b1101, 1]);
+        let mut levels = Vec::new();
+        encode_definition_levels(&mut levels, [true, false, true].into_iter());
+        // length 2, run header (1 group << 1 | 1), bits 0b101
+        assert_eq!(levels, vec![2, 0, 0, 0, 3, 0b101]);
+
+        let mut encoder = ThriftCompactByteEncoder::default();
+        encoder.i32_field(1, 1);
+        encoder.i64_field(3, -2);
+        encoder.binary_field(20, b"x");
+        assert_eq!(encoder.finish(), vec![0x15, 0x02, 0x26, 0x03, 0x08, 0x28, 0x01, b'x', 0x00]);
+
+        let entities = vec![create_go_test_entity(
+            "go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[("owner", "Ada")],
+        )];
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:main:__cmd:T1")
+            .to_key("go:fn:Run:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .source_location("cmd/main.go:3")
+            .build()
+            .unwrap()];
+        let files = render_graph_as_parquet_tables(&entities, &edges);
+        assert_eq!((files.node_count, files.edge_count), (2, 1));
+        for table in [&files.nodes_parquet, &files.edges_parquet] {
+            assert_eq!(&table[..4], b"PAR1");
+            assert_eq!(&table[table.len() - 4..], b"PAR1");
+            let footer_len = u32::from_le_bytes(table[table.len() - 8..table.len() - 4].try_into().unwrap()) as usize;
+            assert!(footer_len + 12 <= table.len());
+        }
+        let contains = |table: &[u8], needle: &[u8]| table.windows(needle.len()).any(|w| w == needle);
+        assert!(contains(&files.nodes_parquet, b"{\"owner\":\"Ada\"}"));
+        assert!(contains(&files.edges_parquet, b"confidence_score"));
+        assert!(contains(&files.edges_parquet, b"cmd/main.go:3"));
+
+        let empty = encode_parquet_table_bytes(&[text_column("key", std::iter::empty())]);
+        assert_eq!(&empty[..4], b"PAR1");
+        assert_eq!(&empty[empty.len() - 4..], b"PAR1");
+    }
+
+    /// Decoded Thrift compact value (the types the encoder writes)
+    #[derive(Debug, Clone, PartialEq)]
+    enum ThriftTestValue {
+        Int(i64),
+        Binary(Vec<u8>),
+        List(Vec<ThriftTestValue>),
+        Struct(Vec<(i16, ThriftTestValue)>),
+    }
+
+    impl ThriftTestValue {
+        fn field(&self, id: i16) -> &ThriftTestValue {
+            match self {
+                Self::Struct(fields) => {
+                    &fields.iter().find(|(field, _)| *field == id).unwrap_or_else(|| panic!("no field {}", id)).1
+                }
+                other => panic!("not a struct: {:?}", other),
+            }
+        }
+
+        fn int(&self) -> i64 {
+            match self {
+                Self::Int(value) => *value,
+                other => panic!("not an integer: {:?}", other),
+            }
+        }
+
+        fn items(&self) -> &[ThriftTestValue] {
+            match self {
+                Self::List(items) => items,
+                other => panic!("not a list: {:?}", other),
+            }
+        }
+
+        fn text(&self) -> &str {
+            match self {
+                Self::Binary(bytes) => std::str::from_utf8(bytes).unwrap(),
+                other => panic!("not binary: {:?}", other),
+            }
+        }
+    }
+
+    fn read_test_varint(bytes: &[u8], at: &mut usize) -> u64 {
+        let (mut value, mut shift) = (0u64, 0);
+        loop {
+            let byte = bytes[*at];
+            *at += 1;
+            value |= u64::from(byte & 0x7f) << shift;
+            if byte < 0x80 {
+                return value;
+            }
+            shift += 7;
+        }
+    }
+
+    fn read_test_zigzag(bytes: &[u8], at: &mut usize) -> i64 {
+        let raw = read_test_varint(bytes, at);
+        (raw >> 1) as i64 ^ -((raw & 1) as i64)
+    }
+
+    fn read_thrift_test_value(bytes: &[u8], at: &mut usize, kind: u8) -> ThriftTestValue {
+        match kind {
+            THRIFT_I32 | THRIFT_I64 => ThriftTestValue::Int(read_test_zigzag(bytes, at)),
+            THRIFT_BINARY => {
+                let length = read_test_varint(bytes, at) as usize;
+                *at += length;
+                ThriftTestValue::Binary(bytes[*at - length..*at].to_vec())
+            }
+            THRIFT_LIST => {
+                let header = bytes[*at];
+                *at += 1;
+                let size = match header >> 4 {
+                    15 => read_test_varint(bytes, at) as usize,
+                    short => short as usize,
+                };
+                ThriftTestValue::List((0..size).map(|_| read_thrift_test_value(bytes, at, header & 0x0f)).collect())
+            }
+            THRIFT_STRUCT => {
+                let mut fields = Vec::new();
+                let mut last = 0i16;
+                loop {
+                    let header = bytes[*at];
+                    *at += 1;
+                    if header == 0 {
+                        break;
+                    }
+                    let id = match header >> 4 {
+                        0 => read_test_zigzag(bytes, at) as i16,
+                        delta => last + delta as i16,
+                    };
+                    last = id;
+                    fields.push((id, read_thrift_test_value(bytes, at, header & 0x0f)));
+                }
+                ThriftTestValue::Struct(fields)
+            }
+            other => panic!("unexpected thrift type {}", other),
+        }
+    }
+
+    fn footer_length(file: &[u8]) -> usize {
+        u32::from_le_bytes(file[file.len() - 8..file.len() - 4].try_into().unwrap()) as usize
+    }
+
+    /// FileMetaData of a Parquet file; the footer must be consumed exactly
+    fn read_parquet_test_footer(file: &[u8]) -> ThriftTestValue {
+        let mut at = file.len() - 8 - footer_length(file);
+        let footer = read_thrift_test_value(file, &mut at, THRIFT_STRUCT);
+        assert_eq!(at, file.len() - 8);
+        footer
+    }
+
+    /// (page header, page data) of a column chunk
+    fn read_parquet_test_page(file: &[u8], chunk: &ThriftTestValue) -> (ThriftTestValue, Vec<u8>) {
+        let offset = chunk.field(3).field(9).int() as usize;
+        let size = chunk.field(3).field(6).int() as usize;
+        let mut at = offset;
+        let header = read_thrift_test_value(file, &mut at, THRIFT_STRUCT);
+        (header, file[at..offset + size].to_vec())
+    }
+
+    #[test]
+    fn test_thrift_encoder_long_forms_and_nesting() {
+        let mut encoder = ThriftCompactByteEncoder::default();
+        encoder.i32_field(5, 7);
+        // A smaller id than the last one needs the long form
+        encoder.i32_field(2, -1);
+        assert_eq!(encoder.finish(), vec![0x55, 0x0E, 0x05, 0x04, 0x01, 0x00]);
+
+        let mut encoder = ThriftCompactByteEncoder::default();
+        encoder.list_field_header(1, THRIFT_I32, 15);
+        for value in 0..15 {
+            encoder.write_i32_value(value);
+        }
+        encoder.begin_struct_field(5);
+        encoder.i32_field(1, 0);
+        encoder.end_struct();
+        // Field ids resume after the nested struct: 6 is delta 1 from 5
+        encoder.i32_field(6, 300);
+        let bytes = encoder.finish();
+        assert_eq!(&bytes[..3], &[0x19, 0xF5, 0x0F]);
+        let mut at = 0;
+        let decoded = read_thrift_test_value(&bytes, &mut at, THRIFT_STRUCT);
+        assert_eq!(at, bytes.len());
+        let listed: Vec<i64> = decoded.field(1).items().iter().map(ThriftTestValue::int).collect();
+        assert_eq!(listed, (0..15).collect::<Vec<i64>>());
+        assert_eq!(decoded.field(5).field(1).int(), 0);
+        assert_eq!(decoded.field(6).int(), 300);
+
+        assert!(pack_bits_lsb_first(std::iter::empty()).is_empty());
+        assert_eq!(pack_bits_lsb_first([true; 8].into_iter()), vec![0xFF]);
+        let mut levels = Vec::new();
+        encode_definition_levels(&mut levels, std::iter::empty());
+        assert_eq!(levels, vec![1, 0, 0, 0, 1]);
+
+        let mut varint = Vec::new();
+        write_unsigned_varint(&mut varint, 300);
+        assert_eq!(varint, vec![0xAC, 0x02]);
+    }
+
+    #[test]
+    fn test_parquet_footer_and_pages_round_trip() {
+        let entities = vec![create_go_test_entity(
+            "go:fn:main:__cmd:T1", "main", EntityType::Function, "cmd/main.go", &[],
+        )];
+        let call = |to: &str, location: Option<&str>| {
+            let mut builder =
+                DependencyEdge::builder().from_key("go:fn:main:__cmd:T1").to_key(to).edge_type(EdgeType::Calls);
+            if let Some(location) = location {
+                builder = builder.source_location(location);
+            }
+            builder.build().unwrap()
+        };
+        let edges = vec![
+            call("go:fn:Stop:unresolved-reference:0-0", None),
+            call("go:fn:Run:unresolved-reference:0-0", Some("cmd/main.go:3")),
+        ];
+        let files = render_graph_as_parquet_tables(&entities, &edges);
+        assert_eq!((files.node_count, files.edge_count), (3, 2));
+
+        let mut reversed = edges.clone();
+        reversed.reverse();
+        assert_eq!(render_graph_as_parquet_tables(&entities, &reversed), files, "input order must not matter");
+
+        let footer = read_parquet_test_footer(&files.nodes_parquet);
+        assert_eq!(footer.field(1).int(), 1);
+        assert_eq!(footer.field(3).int(), 3);
+        assert_eq!(footer.field(6).text(), "parseltongue");
+        let schema = footer.field(2).items();
+        assert_eq!(schema[0].field(5).int(), 9);
+        let names: Vec<&str> = schema[1..].iter().map(|s| s.field(4).text()).collect();
+        assert_eq!(
+            names,
+            vec!["key", "name", "kind", "language", "file_path", "line_start", "line_end", "external", "metadata"]
+        );
+        let repetition = |name: &str| schema.iter().find(|s| s.field(4).text() == name).unwrap().field(3).int();
+        assert_eq!(repetition("file_path") as i32, REPETITION_OPTIONAL);
+        assert_eq!(repetition("external") as i32, REPETITION_REQUIRED);
+
+        let row_groups = footer.field(4).items();
+        assert_eq!(row_groups.len(), 1);
+        assert_eq!(row_groups[0].field(3).int(), 3);
+        let chunks = row_groups[0].field(1).items();
+        assert_eq!(chunks.len(), 9);
+        // Chunks are contiguous from the magic bytes to the footer
+        let mut expected_offset = 4;
+        for chunk in chunks {
+            assert_eq!(chunk.field(2).int(), expected_offset);
+            assert_eq!(chunk.field(3).field(9).int(), expected_offset);
+            let (header, data) = read_parquet_test_page(&files.nodes_parquet, chunk);
+            assert_eq!(header.field(1).int() as i32, PAGE_TYPE_DATA_PAGE);
+            assert_eq!(header.field(2).int(), data.len() as i64);
+            assert_eq!(header.field(5).field(1).int(), 3);
+            expected_offset += chunk.field(3).field(6).int();
+        }
+        assert_eq!(row_groups[0].field(2).int(), expected_offset - 4);
+
+        // Nodes sort by key: the two placeholders have no file, main does
+        let (_, file_paths) = read_parquet_test_page(&files.nodes_parquet, &chunks[4]);
+        let mut expected = vec![2, 0, 0, 0, 3, 0b100, 11, 0, 0, 0];
+        expected.extend_from_slice(b"cmd/main.go");
+        assert_eq!(file_paths, expected);
+        let (_, external) = read_parquet_test_page(&files.nodes_parquet, &chunks[7]);
+        assert_eq!(external, vec![0b011]);
+        let (_, metadata) = read_parquet_test_page(&files.nodes_parquet, &chunks[8]);
+        assert_eq!(metadata, vec![2, 0, 0, 0, 3, 0], "empty metadata is null");
+
+        // Edges in export order: Run (located) before Stop (no location)
+        let edge_footer = read_parquet_test_footer(&files.edges_parquet);
+        let edge_chunks = edge_footer.field(4).items()[0].field(1).items();
+        let (_, locations) = read_parquet_test_page(&files.edges_parquet, &edge_chunks[3]);
+        let mut expected = vec![2, 0, 0, 0, 3, 0b01, 13, 0, 0, 0];
+        expected.extend_from_slice(b"cmd/main.go:3");
+        assert_eq!(locations, expected);
+        let (_, edge_metadata) = read_parquet_test_page(&files.edges_parquet, &edge_chunks[6]);
+        assert_eq!(edge_metadata, vec![2, 0, 0, 0, 3, 0]);
+    }
+
+    #[test]
+    fn test_parquet_row_groups_split_and_empty_tables() {
+        let rows = PARQUET_ROW_GROUP_ROW_COUNT + 1;
+        let columns = vec![
+            ParquetTableColumnEntry {
+                name: "flag",
+                values: ParquetColumnValueList::Boolean((0..rows).map(|i| i % 2 == 0).collect()),
+            },
+            ParquetTableColumnEntry {
+                name: "n",
+                values: ParquetColumnValueList::Int64((0..rows).map(|i| (i % 5 == 0).then_some(i as i64)).collect()),
+            },
+        ];
+        let file = encode_parquet_table_bytes(&columns);
+        let footer = read_parquet_test_footer(&file);
+        assert_eq!(footer.field(3).int(), rows as i64);
+        let row_groups = footer.field(4).items();
+        let group_rows: Vec<i64> = row_groups.iter().map(|g| g.field(3).int()).collect();
+        assert_eq!(group_rows, vec![PARQUET_ROW_GROUP_ROW_COUNT as i64, 1]);
+
+        let first = row_groups[0].field(1).items();
+        let (_, flags) = read_parquet_test_page(&file, &first[0]);
+        assert_eq!(flags.len(), PARQUET_ROW_GROUP_ROW_COUNT / 8);
+        assert!(flags.iter().all(|&b| b == 0b0101_0101));
+        let second = row_groups[1].field(1).items();
+        assert_eq!(second[0].field(3).field(5).int(), 1);
+        let (header, flags) = read_parquet_test_page(&file, &second[0]);
+        assert_eq!(header.field(5).field(1).int(), 1);
+        assert_eq!(flags, vec![1], "row 65536 is even");
+        // 65536 % 5 != 0: one null level, no values
+        let (_, numbers) = read_parquet_test_page(&file, &second[1]);
+        assert_eq!(numbers, vec![2, 0, 0, 0, 3, 0]);
+
+        let values = ParquetColumnValueList::Int64(vec![None, Some(-1)]);
+        let mut expected = vec![2, 0, 0, 0, 3, 0b10];
+        expected.extend_from_slice(&(-1i64).to_le_bytes());
+        assert_eq!(values.encode_page_data(0, 2), expected);
+        let doubles = ParquetColumnValueList::Double(vec![1.0, 0.5]);
+        assert_eq!(doubles.encode_page_data(1, 2), 0.5f64.to_le_bytes().to_vec());
+
+        let empty = encode_parquet_table_bytes(&[text_column("key", std::iter::empty())]);
+        let footer = read_parquet_test_footer(&empty);
+        assert_eq!(footer.field(3).int(), 0);
+        assert!(footer.field(4).items().is_empty());
+        assert_eq!(footer.field(2).items().len(), 2);
+        assert_eq!(empty.len(), 4 + footer_length(&empty) + 8, "no pages without rows");
+    }
+}
@@ -0,0 +1,473 @@
+//! SCIP index export (v1.7.3)
+//!
+//! # 4-Word Naming: render_graph_as_scip
+//!
+//! Writes the graph as a SCIP `Index` protobuf so Sourcegraph-style code
+//! navigation tools can consume it:
+//!
+//! - One `Document` per source file; each entity is a definition occurrence
+//!   with its enclosing range and a `SymbolInformation`
+//! - Each edge with a `file:line` source location is a reference occurrence
+//!   of the target symbol at that line
+//! - `Implements` edges become `is_implementation` relationships
+//! - Edges to placeholder targets reference `external_symbols`
+//!   (package = dependency name when known)
+//!
+//! Symbols are built from the canonical ID: `parseltongue {language} . .`
+//! followed by module namespaces and the symbol path, e.g.
+//! `parseltongue go . . internal/auth/Service#Login().`
+//!
+//! Reference columns are found by searching the target name in the source
+//! line; references whose line text is unavailable are omitted.
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+
+use crate::canonical_symbol_id_normalizer::canonical_id_for_code_entity;
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::protobuf_wire_format_codec::ProtobufMessageWriter;
+use crate::serializers::graph_export_node_table::{
+    is_external_graph_node_key, EXTERNAL_DEPENDENCY_SEGMENT_PREFIX,
+};
+
+/// Scheme written into every exported symbol
+pub const SCIP_EXPORT_SYMBOL_SCHEME: &str = "parseltongue";
+
+/// SymbolRole.Definition bit
+const SCIP_ROLE_DEFINITION: u64 = 1;
+
+/// Render entities and edges as an encoded SCIP index
+///
+/// # 4-Word Name: render_graph_as_scip
+///
+/// # Contract
+/// - `project_root` is written as metadata (`file://` prefixed if bare)
+/// - Postcondition: documents sorted by path, occurrences by position;
+///   output is byte-identical for identical input
+pub fn render_graph_as_scip(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    project_root: &str,
+) -> Vec<u8> {
+    let internal: Vec<&CodeEntity> = entities
+        .iter()
+        .filter(|e| !is_external_graph_node_key(&e.isgl1_key))
+        .collect();
+    let symbols = assign_unique_scip_symbols(&internal);
+    let by_key: HashMap<&str, &CodeEntity> = internal.iter().map(|e| (e.isgl1_key.as_str(), *e)).collect();
+
+    // Implementations per entity key
+    let mut implements: HashMap<&str, Vec<&str>> = HashMap::new();
+    for edge in edges.iter().filter(|e| e.edge_type == EdgeType::Implements) {
+        if let Some(target) = symbols.get(edge.to_key.as_str()) {
+            implements.entry(edge.from_key.as_str()).or_default().push(target.as_str());
+        }
+    }
+
+    // relative_path -> (occurrences as (range, symbol, roles, enclosing), symbol infos)
+    let mut documents: BTreeMap<String, DocumentExportBuffer> = BTreeMap::new();
+    for entity in &internal {
+        let path = entity.interface_signature.file_path.to_string_lossy().replace('\\', "/");
+        let symbol = symbols[entity.isgl1_key.as_str()].clone();
+        let start = entity.interface_signature.line_range.start.saturating_sub(1);
+        let end = entity.interface_signature.line_range.end.saturating_sub(1);
+        let name = &entity.interface_signature.name;
+        let first_line = entity.current_code.as_deref().and_then(|code| code.lines().next()).unwrap_or("");
+        let range = match find_identifier_column_range(first_line, name) {
+            Some((from, to)) => vec![start, from, to],
+            None => vec![start, 0, 0],
+        };
+        let last_line_len = entity
+            .current_code
+            .as_deref()
+            .and_then(|code| code.lines().last())
+            .map(|l| l.chars().count() as u32)
+            .unwrap_or(0);
+
+        let document = documents.entry(path).or_default();
+        document.occurrences.push(ExportedOccurrenceRow {
+            range,
+            symbol: symbol.clone(),
+            roles: SCIP_ROLE_DEFINITION,
+            enclosing: vec![start, 0, end, last_line_len],
+        });
+        let mut relationships: Vec<&str> = implements.get(entity.isgl1_key.as_str()).cloned().unwrap_or_default();
+        relationships.sort_unstable();
+        relationships.dedup();
+        document.symbols.push(ExportedSymbolRow {
+            symbol,
+            kind: scip_kind_for_entity_type(&entity.interface_signature.entity_type),
+            display_name: name.clone(),
+            documentation: entity.interface_signature.documentation.clone(),
+            implements: relationships.into_iter().map(str::to_string).collect(),
+        });
+    }
+
+    let mut external_symbols: BTreeMap<String, ExportedSymbolRow> = BTreeMap::new();
+    let mut seen_references: HashSet<(String, u32, String)> = HashSet::new();
+    for edge in edges {
+        let Some(location) = edge.source_location.as_deref() else { continue };
+        let Some((path, line)) = split_source_location_line(location) else { continue };
+        let Some(from) = by_key.get(edge.from_key.as_str()) else { continue };
+
+        let target_name = key_name_segment(edge.to_key.as_str());
+        let target_symbol = match symbols.get(edge.to_key.as_str()) {
+            Some(symbol) => symbol.clone(),
+            None => {
+                let symbol = external_scip_symbol_for_key(edge.to_key.as_str());
+                external_symbols.entry(symbol.clone()).or_insert_with(|| ExportedSymbolRow {
+                    symbol: symbol.clone(),
+                    kind: scip_kind_for_key_segment(edge.to_key.as_str()),
+                    display_name: target_name.to_string(),
+                    documentation: None,
+                    implements: Vec::new(),
+                });
+                symbol
+            }
+        };
+
+        // The reference line lies inside the source entity's body
+        let offset = line.checked_sub(from.interface_signature.line_range.start);
+        let line_text = offset.and_then(|o| from.current_code.as_deref()?.lines().nth(o as usize));
+        let Some((start_column, end_column)) = line_text.and_then(|text| find_identifier_column_range(text, target_name))
+        else {
+            continue;
+        };
+        if !seen_references.insert((path.to_string(), line, target_symbol.clone())) {
+            continue;
+        }
+        documents.entry(path.to_string()).or_default().occurrences.push(ExportedOccurrenceRow {
+            range: vec![line - 1, start_column, end_column],
+            symbol: target_symbol,
+            roles: 0,
+            enclosing: Vec::new(),
+        });
+    }
+
+    let mut index = ProtobufMessageWriter::new();
+    let mut tool_info = ProtobufMessageWriter::new();
+    tool_info
+        .write_string_field(1, "parseltongue")
+        .write_string_field(2, env!("CARGO_PKG_VERSION"));
+    let mut metadata = ProtobufMessageWriter::new();
+    let root_uri = if project_root.contains("://") {
+        project_root.to_string()
+    } else {
+        format!("file://{}", project_root)
+    };
+    metadata
+        .write_message_field(2, &tool_info)
+        .write_string_field(3, &root_uri)
+        // TextEncoding.UTF8
+        .write_varint_field(4, 1);
+    index.write_message_field(1, &metadata);
+
+    for (path, mut document) in documents {
+        document.occurrences.sort_by(|a, b| (&a.range, &a.symbol).cmp(&(&b.range, &b.symbol)));
+        // v1.7.3: symbols arrive in entity input order; sort for stable output
+        document.symbols.sort_by(|a, b| a.symbol.cmp(&b.symbol));
+        let language = document
+            .symbols
+            .first()
+            .and_then(|s| s.symbol.split(' ').nth(1))
+            .map(scip_language_display_name)
+            .unwrap_or_default();
+
+        let mut message = ProtobufMessageWriter::new();
+        message.write_string_field(1, &path);
+        for occurrence in &document.occurrences {
+            let mut encoded = ProtobufMessageWriter::new();
+            encoded
+                .write_packed_int32_field(1, &as_int32_values(&occurrence.range))
+                .write_string_field(2, &occurrence.symbol)
+                .write_varint_field(3, occurrence.roles)
+                .write_packed_int32_field(7, &as_int32_values(&occurrence.enclosing));
+            message.write_message_field(2, &encoded);
+        }
+        for symbol in &document.symbols {
+            message.write_message_field(3, &encode_symbol_information(symbol));
+        }
+        message.write_string_field(4, &language);
+        index.write_message_field(2, &message);
+    }
+    for symbol in external_symbols.values() {
+        index.write_message_field(3, &encode_symbol_information(symbol));
+    }
+    index.into_bytes()
+}
+
+#[derive(Default)]
+struct DocumentExportBuffer {
+    occurrences: Vec<ExportedOccurrenceRow>,
+    symbols: Vec<ExportedSymbolRow>,
+}
+
+struct ExportedOccurrenceRow {
+    range: Vec<u32>,
+    symbol: String,
+    roles: u64,
+    enclosing: Vec<u32>,
+}
+
+struct ExportedSymbolRow {
+    symbol: String,
+    kind: u64,
+    display_name: String,
+    documentation: Option<String>,
+    implements: Vec<String>,
+}
+
+fn encode_symbol_information(row: &ExportedSymbolRow) -> ProtobufMessageWriter {
+    let mut message = ProtobufMessageWriter::new();
+    message.write_string_field(1, &row.symbol);
+    if let Some(doc) = row.documentation.as_deref().filter(|d| !d.is_empty()) {
+        message.write_string_field(3, doc);
+    }
+    for target in &row.implements {
+        let mut relationship = ProtobufMessageWriter::new();
+        relationship.write_string_field(1, target).write_varint_field(3, 1);
+        message.write_message_field(4, &relationship);
+    }
+    message.write_varint_field(5, row.kind).write_string_field(6, &row.display_name);
+    message
+}
+
+fn as_int32_values(values: &[u32]) -> Vec<i32> {
+    values.iter().map(|v| *v as i32).collect()
+}
+
+/// Symbol per entity key; colliding symbols get `(+N)` disambiguators
+fn assign_unique_scip_symbols<'a>(entities: &[&'a CodeEntity]) -> HashMap<&'a str, String> {
+    let mut sorted: Vec<&CodeEntity> = entities.to_vec();
+    sorted.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+
+    let mut used: HashMap<String, usize> = HashMap::new();
+    let mut symbols = HashMap::new();
+    for entity in sorted {
+        let base = scip_symbol_for_entity(entity, 0);
+        let count = used.entry(base.clone()).or_insert(0);
+        let symbol = if *count == 0 { base } else { scip_symbol_for_entity(entity, *count) };
+        *count += 1;
+        symbols.insert(entity.isgl1_key.as_str(), symbol);
+    }
+    symbols
+}
+
+/// `parseltongue {lang} . . {namespaces}/{Type#}{leaf}`
+fn scip_symbol_for_entity(entity: &CodeEntity, disambiguator: usize) -> String {
+    let canonical = canonical_id_for_code_entity(entity);
+    let mut descriptors = String::new();
+    for segment in &canonical.module_segments {
+        descriptors.push_str(&escape_scip_descriptor_name(segment));
+        descriptors.push('/');
+    }
+    let (leaf, parents) = canonical.symbol_segments.split_last().map(|(l, p)| (l.as_str(), p)).unwrap_or(("", &[][..]));
+    for parent in parents {
+        descriptors.push_str(&escape_scip_descriptor_name(parent));
+        descriptors.push('#');
+    }
+    descriptors.push_str(&escape_scip_descriptor_name(leaf));
+    let suffix = match entity.interface_signature.entity_type {
+        EntityType::Function | EntityType::Method | EntityType::TestFunction => {
+            if disambiguator == 0 {
+                "().".to_string()
+            } else {
+                format!("(+{}).", disambiguator)
+            }
+        }
+        EntityType::Macro => "!".to_string(),
+        EntityType::Module => "/".to_string(),
+        EntityType::Variable | EntityType::Constant => ".".to_string(),
+        _ => "#".to_string(),
+    };
+    descriptors.push_str(&suffix);
+    if disambiguator > 0 && !suffix.starts_with('(') {
+        // Non-callables have no disambiguator syntax: qualify by line instead
+        descriptors.push_str(&format!("L{}.", entity.interface_signature.line_range.start));
+    }
+    format!(
+        "{} {} . . {}",
+        SCIP_EXPORT_SYMBOL_SCHEME,
+        canonical.language,
+        descriptors
+    )
+}
+
+/// Symbol for a placeholder target (`external-dependency-{crate}` → package)
+fn external_scip_symbol_for_key(key: &str) -> String {
+    let language = key.split(':').next().unwrap_or("unknown");
+    let package = key
+        .split(':')
+        .find_map(|segment| segment.strip_prefix(EXTERNAL_DEPENDENCY_SEGMENT_PREFIX))
+        .unwrap_or(".");
+    let name = escape_scip_descriptor_name(key_name_segment(key));
+    let suffix = match key.split(':').nth(1) {
+        Some("fn" | "method") => "().",
+        Some("macro") => "!",
+        Some("var" | "const") => ".",
+        _ => "#",
+    };
+    format!("{} {} {} . {}{}", SCIP_EXPORT_SYMBOL_SCHEME, language, package, name, suffix)
+}
+
+fn key_name_segment(key: &str) -> &str {
+    key.split(':').nth(2).unwrap_or(key)
+}
+
+/// Backtick-escape names with characters outside SCIP's simple identifiers
+fn escape_scip_descriptor_name(name: &str) -> String {
+    let simple = !name.is_empty()
+        && name.chars().all(|c| c.is_alphanumeric() || matches!(c, '_' | '+' | '-' | '$'));
+    if simple {
+        name.to_string()
+    } else {
+        format!("`{}`", name.replace('`', "``"))
+    }
+}
+
+/// `path/file.go:42` → (`path/file.go`, 42)
+fn split_source_location_line(location: &str) -> Option<(&str, u32)> {
+    let (path, line) = location.rsplit_once(':')?;
+    let line: u32 = line.parse().ok()?;
+    (line > 0 && !path.is_empty()).then_some((path, line))
+}
+
+/// Column range of `name` as a whole identifier in `line`
+fn find_identifier_column_range(line: &str, name: &str) -> Option<(u32, u32)> {
+    if name.is_empty() {
+        return None;
+    }
+    let is_ident = |c: char| c.is_alphanumeric() || c == '_';
+    let mut search_from = 0;
+    while let Some(relative) = line[search_from..].find(name) {
+        let start = search_from + relative;
+        let end = start + name.len();
+        let before_ok = line[..start].chars().next_back().map_or(true, |c| !is_ident(c));
+        let after_ok = line[end..].chars().next().map_or(true, |c| !is_ident(c));
+        if before_ok && after_ok {
+            let start_column = line[..start].chars().count() as u32;
+            return Some((start_column, start_column + name.chars().count() as u32));
+        }
+        search_from = end;
+    }
+    None
+}
+
+/// SymbolInformation.Kind for an entity type (inverse of the importer table)
+fn scip_kind_for_entity_type(entity_type: &EntityType) -> u64 {
+    match entity_type {
+        EntityType::Class => 7,
+        EntityType::Constant => 8,
+        EntityType::Enum => 11,
+        EntityType::Function | EntityType::TestFunction => 17,
+        EntityType::Interface => 21,
+        EntityType::Macro => 25,
+        EntityType::Method => 26,
+        EntityType::Module => 29,
+        EntityType::Struct => 49,
+        EntityType::Trait => 53,
+        EntityType::Variable => 61,
+        _ => 0,
+    }
+}
+
+fn scip_kind_for_key_segment(key: &str) -> u64 {
+    match key.split(':').nth(1) {
+        Some("fn") => 17,
+        Some("method") => 26,
+        Some("struct") => 49,
+        Some("trait") => 53,
+        Some("interface") => 21,
+        Some("class") => 7,
+        Some("enum") => 11,
+        Some("macro") => 25,
+        Some("const") => 8,
+        Some("var") => 61,
+        _ => 0,
+    }
+}
+
+/// SCIP `Document.language` uses capitalized names (`Go`, `Rust`)
+fn scip_language_display_name(tag: &str) -> String {
+    match tag {
+        "go" => "Go".to_string(),
+        "rust" => "Rust".to_string(),
+        "python" => "Python".to_string(),
+        "javascript" => "JavaScript".to_string(),
+        "typescript" => "TypeScript".to_string(),
+        "java" => "Java".to_string(),
+        "cpp" => "CPP".to_string(),
+        "c" => "C".to_string(),
+        "csharp" => "CSharp".to_string(),
+        "ruby" => "Ruby".to_string(),
+        "php" => "PHP".to_string(),
+        "swift" => "Swift".to_string(),
+        other => other.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::external_code_index_importer::{build_graph_from_index_model, decode_scip_index_model};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn fixture() -> (Vec<CodeEntity>, Vec<DependencyEdge>) {
+        let mut main = create_go_test_entity("go:fn:main:__cmd_main:T1", "main", EntityType::Function, "cmd/main.go", &[]);
+        main.current_code = Some("func main() {\n\ts := store.New()\n\ts.Save()\n\tfmt.Println(\"ok\")\n}".to_string());
+        let mut save = create_go_test_entity(
+            "go:method:Save:__store_store:T2",
+            "Save",
+            EntityType::Method,
+            "store/store.go",
+            &[("receiver_type", "Store")],
+        );
+        save.current_code = Some("func (s *Store) Save() error {\n\treturn nil\n}".to_string());
+        let edges = vec![
+            DependencyEdge::builder()
+                .from_key("go:fn:main:__cmd_main:T1")
+                .to_key("go:method:Save:__store_store:T2")
+                .edge_type(EdgeType::Calls)
+                .source_location("cmd/main.go:3")
+                .build()
+                .unwrap(),
+            DependencyEdge::builder()
+                .from_key("go:fn:main:__cmd_main:T1")
+                .to_key("go:fn:Println:external-dependency-fmt:0-0")
+                .edge_type(EdgeType::Calls)
+                .source_location("cmd/main.go:4")
+                .build()
+                .unwrap(),
+        ];
+        (vec![main, save], edges)
+    }
+
+    #[test]
+    fn test_scip_symbols_follow_canonical_ids() {
+        let (entities, _) = fixture();
+        assert_eq!(scip_symbol_for_entity(&entities[1], 0), "parseltongue go . . store/Store#Save().");
+        assert_eq!(scip_symbol_for_entity(&entities[1], 2), "parseltongue go . . store/Store#Save(+2).");
+        assert_eq!(
+            external_scip_symbol_for_key("go:fn:Println:external-dependency-fmt:0-0"),
+            "parseltongue go fmt . Println()."
+        );
+    }
+
+    #[test]
+    fn test_scip_export_roundtrips_through_importer() {
+        let (entities, edges) = fixture();
+        let bytes = render_graph_as_scip(&entities, &edges, "/repo");
+
+        let model = decode_scip_index_model(&bytes).unwrap();
+        assert_eq!(model.documents.len(), 2);
+        assert_eq!(model.documents[0].relative_path, "cmd/main.go");
+        let reference = model.occurrences.iter().find(|o| !o.is_definition && o.symbol.ends_with("Save().")).unwrap();
+        assert_eq!((reference.line, reference.start_character, reference.end_character), (2, 3, 7));
+
+        let graph = build_graph_from_index_model(&model);
+        assert_eq!(graph.entities.len(), 2);
+        assert!(graph
+            .edges
+            .iter()
+            .any(|e| e.to_key.as_str() == "go:fn:Println:external-dependency-fmt:0-0"));
+    }
+}
@@ -8,7 +8,8 @@ use crate::error::{ParseltongError, Result};
 use crate::interfaces::*;
 use async_trait::async_trait;
 use cozo::{DataValue, DbInstance, ScriptMutability};
-use std::collections::BTreeMap;
+use std::collections::{BTreeMap, HashMap};
+use std::path::Path;
 
 /// Escape string for safe use in CozoDB query strings
 ///
@@ -31,6 +32,28 @@ pub fn escape_for_cozo_string(s: &str) -> String {
     s.replace('\\', "\\\\").replace('\'', "\\'")
 }
 
+/// Serialize edge metadata to a JSON object string
+///
+/// Returns `None` for edges without metadata so the column stays null
+/// (keeps storage identical to pre-metadata edges).
+fn serialize_edge_metadata_json(edge: &DependencyEdge) -> Option<String> {
+    if edge.metadata.is_empty() {
+        return None;
+    }
+    serde_json::to_string(&edge.metadata).ok()
+}
+
+/// Failed DependencyEdges migration: the database has to be ingested again
+fn legacy_edge_schema_error(cause: impl std::fmt::Display) -> ParseltongError {
+    ParseltongError::DatabaseError {
+        operation: "migrate_legacy_dependency_edges_schema".to_string(),
+        details: format!(
+            "DependencyEdges predates edge metadata and could not be migrated ({}); re-ingest required",
+            cause
+        ),
+    }
+}
+
 /// CozoDB storage client
 ///
 /// Provides real database storage with SQLite backend, supporting:
@@ -41,6 +64,9 @@ pub struct CozoDbStorage {
     db: DbInstance,
 }
 
+/// Records per insert while loading a JSON snapshot (v1.7.3)
+const SNAPSHOT_LOAD_BATCH_SIZE: usize = 2_000;
+
 impl CozoDbStorage {
     /// Create new CozoDB storage instance
     ///
@@ -49,6 +75,8 @@ impl CozoDbStorage {
     ///   - "mem" for in-memory
     ///   - "rocksdb:path/to/db" for RocksDB persistent storage (recommended for Mac/Linux, fastest)
     ///   - "sqlite:path/to/db.sqlite" for SQLite storage (recommended for Windows, stable)
+    ///   - "snapshot:graph.json.zst" for an in-memory graph loaded from an
+    ///     `export --format json` snapshot (v1.7.3; plain `.json` works too)
     ///
     /// # Performance Notes (v1.7.0)
     /// - RocksDB: Fastest for Cozo's workload, recommended for Mac/Linux
@@ -69,13 +97,151 @@ impl CozoDbStorage {
             (engine_spec, "")
         };
 
+        if engine == "snapshot" {
+            let db = DbInstance::new("mem", "", Default::default()).map_err(|e| ParseltongError::DatabaseError {
+                operation: "connection".to_string(),
+                details: format!("Failed to create in-memory CozoDB instance for snapshot '{}': {}", path, e),
+            })?;
+            let storage = Self { db };
+            storage.load_json_graph_snapshot(Path::new(path)).await?;
+            return Ok(storage);
+        }
+
         let db = DbInstance::new(engine, path, Default::default())
             .map_err(|e| ParseltongError::DatabaseError {
                 operation: "connection".to_string(),
                 details: format!("Failed to create CozoDB instance with engine '{}' and path '{}': {}", engine, path, e),
             })?;
 
-        Ok(Self { db })
+        let storage = Self { db };
+        storage.migrate_legacy_dependency_edges_schema().await?;
+        Ok(storage)
+    }
+
+    /// Bring a DependencyEdges relation from before edge metadata up to date
+    ///
+    /// # 4-Word Name: migrate_legacy_dependency_edges_schema
+    ///
+    /// # Contract
+    /// - Postcondition: no DependencyEdges relation, or one that already has
+    ///   `edge_metadata` → untouched, returns false
+    /// - Postcondition: a relation without `edge_metadata` is replaced by the
+    ///   current schema; every edge keeps its keys and source_location, with
+    ///   null metadata → returns true
+    /// - Error: the rewrite fails → DatabaseError asking for a re-ingest
+    pub async fn migrate_legacy_dependency_edges_schema(&self) -> Result<bool> {
+        let Ok(columns) = self
+            .db
+            .run_script("::columns DependencyEdges", Default::default(), ScriptMutability::Immutable)
+        else {
+            return Ok(false);
+        };
+        let has_metadata_column = columns
+            .rows
+            .iter()
+            .any(|row| matches!(row.first(), Some(DataValue::Str(name)) if name.as_str() == "edge_metadata"));
+        if has_metadata_column {
+            return Ok(false);
+        }
+
+        let stored = self
+            .db
+            .run_script(
+                "?[from_key, to_key, edge_type, source_location] := \
+                 *DependencyEdges{from_key, to_key, edge_type, source_location}",
+                Default::default(),
+                ScriptMutability::Immutable,
+            )
+            .map_err(legacy_edge_schema_error)?;
+        if stored.rows.is_empty() {
+            self.db
+                .run_script("::remove DependencyEdges", Default::default(), ScriptMutability::Mutable)
+                .map_err(legacy_edge_schema_error)?;
+            self.create_dependency_edges_schema().await.map_err(legacy_edge_schema_error)?;
+            return Ok(true);
+        }
+
+        let rows: Vec<DataValue> = stored
+            .rows
+            .into_iter()
+            .map(|mut row| {
+                row.push(DataValue::Null);
+                DataValue::List(row)
+            })
+            .collect();
+        let mut params = BTreeMap::new();
+        params.insert("rows".to_string(), DataValue::List(rows));
+        let replace = r#"
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] <- $rows
+            :replace DependencyEdges {
+                from_key: String,
+                to_key: String,
+                edge_type: String
+                =>
+                source_location: String?,
+                edge_metadata: String? default null
+            }
+        "#;
+        self.db
+            .run_script(replace, params, ScriptMutability::Mutable)
+            .map_err(legacy_edge_schema_error)?;
+        Ok(true)
+    }
+
+    /// Load an `export --format json` snapshot into this database
+    ///
+    /// # 4-Word Name: load_json_graph_snapshot
+    ///
+    /// # Contract
+    /// - Precondition: `path` is a JSON snapshot, zstd-compressed or not
+    /// - Postcondition: schemas exist; every entity (with extractor metadata)
+    ///   and edge is inserted; returns (entities, edges)
+    /// - Memory: decompression and parsing stream on a blocking thread and
+    ///   hand over batches through a two-slot channel, so at most a few
+    ///   batches are in flight — never the decompressed document
+    pub async fn load_json_graph_snapshot(&self, path: &Path) -> Result<(usize, usize)> {
+        use crate::serializers::json_snapshot::{
+            open_decompressed_snapshot_reader, read_json_snapshot_batches, SnapshotRecordBatch,
+        };
+
+        self.create_schema().await?;
+        self.create_dependency_edges_schema().await?;
+        self.create_entity_extractor_metadata_schema().await?;
+
+        let snapshot_error = |details: String| ParseltongError::DatabaseError {
+            operation: "snapshot_load".to_string(),
+            details: format!("{}: {}", path.display(), details),
+        };
+        let reader = open_decompressed_snapshot_reader(path).map_err(|e| snapshot_error(e.to_string()))?;
+
+        let (sender, mut receiver) = tokio::sync::mpsc::channel::<SnapshotRecordBatch>(2);
+        let decoder = tokio::task::spawn_blocking(move || {
+            read_json_snapshot_batches(reader, SNAPSHOT_LOAD_BATCH_SIZE, |batch| {
+                sender
+                    .blocking_send(batch)
+                    .map_err(|_| std::io::Error::new(std::io::ErrorKind::BrokenPipe, "snapshot loader stopped"))
+            })
+        });
+
+        let mut insert_result = Ok(());
+        while let Some(batch) = receiver.recv().await {
+            insert_result = match &batch {
+                SnapshotRecordBatch::Entities(entities) => match self.insert_entities_batch(entities).await {
+                    Ok(()) => self.upsert_entity_extractor_metadata_batch(entities).await,
+                    Err(e) => Err(e),
+                },
+                SnapshotRecordBatch::Edges(edges) => self.insert_edges_batch(edges).await,
+                SnapshotRecordBatch::Capabilities(manifest) => self.store_extractor_capability_manifest(manifest).await,
+            };
+            if insert_result.is_err() {
+                break;
+            }
+        }
+        // Dropping the receiver makes a still-running decoder stop with an error
+        drop(receiver);
+        let decoded = decoder.await.map_err(|e| snapshot_error(e.to_string()))?;
+        insert_result?;
+        decoded.map_err(|e| snapshot_error(e.to_string()))
     }
 
     /// Check if database connection is alive
@@ -149,7 +315,8 @@ impl CozoDbStorage {
     ///
     /// # Schema
     /// - **Keys**: from_key, to_key, edge_type (composite key for uniqueness)
-    /// - **Fields**: source_location (optional line/column info)
+    /// - **Fields**: source_location (optional line/column info),
+    ///   edge_metadata (optional JSON object, e.g. generic type arguments)
     ///
     /// # Performance Contracts
     /// - Single insert: <5ms (D10 specification)
@@ -168,7 +335,8 @@ impl CozoDbStorage {
                 to_key: String,
                 edge_type: String
                 =>
-                source_location: String?
+                source_location: String?,
+                edge_metadata: String? default null
             }
         "#;
 
@@ -308,12 +476,12 @@ impl CozoDbStorage {
     /// ```
     pub async fn insert_edge(&self, edge: &DependencyEdge) -> Result<()> {
         let query = r#"
-            ?[from_key, to_key, edge_type, source_location] <-
-            [[$from_key, $to_key, $edge_type, $source_location]]
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] <-
+            [[$from_key, $to_key, $edge_type, $source_location, $edge_metadata]]
 
             :put DependencyEdges {
                 from_key, to_key, edge_type =>
-                source_location
+                source_location, edge_metadata
             }
         "#;
 
@@ -328,6 +496,12 @@ impl CozoDbStorage {
                 .map(|s| DataValue::Str(s.as_str().into()))
                 .unwrap_or(DataValue::Null),
         );
+        params.insert(
+            "edge_metadata".to_string(),
+            serialize_edge_metadata_json(edge)
+                .map(|json| DataValue::Str(json.into()))
+                .unwrap_or(DataValue::Null),
+        );
 
         self.db
             .run_script(query, params, ScriptMutability::Mutable)
@@ -362,11 +536,11 @@ impl CozoDbStorage {
         // Build query with inline data for batch insert
         let query = format!(
             r#"
-            ?[from_key, to_key, edge_type, source_location] <- [{}]
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] <- [{}]
 
             :put DependencyEdges {{
                 from_key, to_key, edge_type =>
-                source_location
+                source_location, edge_metadata
             }}
             "#,
             edges
@@ -377,13 +551,17 @@ impl CozoDbStorage {
                         .as_ref()
                         .map(|s| format!("'{}'", escape_for_cozo_string(s)))
                         .unwrap_or_else(|| "null".to_string());
+                    let edge_meta = serialize_edge_metadata_json(edge)
+                        .map(|json| format!("'{}'", escape_for_cozo_string(&json)))
+                        .unwrap_or_else(|| "null".to_string());
 
                     format!(
-                        "['{}', '{}', '{}', {}]",
+                        "['{}', '{}', '{}', {}, {}]",
                         escape_for_cozo_string(edge.from_key.as_ref()),
                         escape_for_cozo_string(edge.to_key.as_ref()),
                         edge.edge_type.as_str(),
-                        source_loc
+                        source_loc,
+                        edge_meta
                     )
                 })
                 .collect::<Vec<_>>()
@@ -702,7 +880,7 @@ impl CozoDbStorage {
     /// # });
     /// ```
     pub async fn get_all_dependencies(&self) -> Result<Vec<DependencyEdge>> {
-        let query = "?[from_key, to_key, edge_type, source_location] := *DependencyEdges{from_key, to_key, edge_type, source_location}";
+        let query = "?[from_key, to_key, edge_type, source_location, edge_metadata] := *DependencyEdges{from_key, to_key, edge_type, source_location, edge_metadata}";
 
         let result = self
             .db
@@ -719,11 +897,9 @@ impl CozoDbStorage {
                 if let (Some(DataValue::Str(from_key)), Some(DataValue::Str(to_key)), Some(DataValue::Str(edge_type_str))) =
                     (row.first(), row.get(1), row.get(2))
                 {
-                    let edge_type = match edge_type_str.as_str() {
-                        "Calls" => EdgeType::Calls,
-                        "Uses" => EdgeType::Uses,
-                        "Implements" => EdgeType::Implements,
-                        _ => continue, // Skip unknown edge types
+                    let edge_type = match edge_type_str.as_str().parse::<EdgeType>() {
+                        Ok(edge_type) => edge_type,
+                        Err(_) => continue, // Skip unknown edge types
                     };
 
                     let source_location = row.get(3).and_then(|v| {
@@ -734,7 +910,7 @@ impl CozoDbStorage {
                         }
                     });
 
-                    let edge = DependencyEdge::builder()
+                    let mut edge = DependencyEdge::builder()
                         .from_key(from_key.to_string())
                         .to_key(to_key.to_string())
                         .edge_type(edge_type)
@@ -745,6 +921,10 @@ impl CozoDbStorage {
                             reason: format!("Failed to build DependencyEdge: {}", e),
                         })?;
 
+                    if let Some(DataValue::Str(meta_json)) = row.get(4) {
+                        edge.metadata = serde_json::from_str(meta_json.as_str()).unwrap_or_default();
+                    }
+
                     dependencies.push(edge);
                 }
             }
@@ -1646,6 +1826,7 @@ impl CozoDbStorage {
                     EntityType::Constant => "constant",
                     EntityType::Table => "table",    // v1.5.6: SQL table
                     EntityType::View => "view",      // v1.5.6: SQL view
+                    EntityType::Route => "route",    // v1.7.3: HTTP route
                 }
                 .into(),
             ),
@@ -2150,6 +2331,468 @@ impl CozoDbStorage {
         Ok(())
     }
 
+    // ============================================================================
+    // v1.7.3: INCREMENTAL INGEST BY CONTENT HASH
+    // ============================================================================
+
+    /// Get every cached file hash
+    ///
+    /// # 4-Word Name: get_all_cached_file_hashes
+    ///
+    /// # Returns
+    /// Map of file_path → content hash (empty if FileHashCache is missing)
+    ///
+    /// # Performance Contract
+    /// - Query: <50ms for 10k files
+    pub async fn get_all_cached_file_hashes(&self) -> Result<HashMap<String, String>> {
+        let query = "?[file_path, content_hash] := *FileHashCache{file_path, content_hash}";
+
+        let result = self
+            .db
+            .run_script(query, Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "get_all_cached_file_hashes".to_string(),
+                details: format!("Failed to query file hash cache: {}", e),
+            })?;
+
+        Ok(result
+            .rows
+            .iter()
+            .filter_map(|row| match (row.first(), row.get(1)) {
+                (Some(DataValue::Str(path)), Some(DataValue::Str(hash))) => {
+                    Some((path.to_string(), hash.to_string()))
+                }
+                _ => None,
+            })
+            .collect())
+    }
+
+    /// Set many cached file hashes in one transaction
+    ///
+    /// # 4-Word Name: set_cached_file_hashes_batch
+    ///
+    /// # Arguments
+    /// * `hashes` - (file_path, content hash) pairs
+    pub async fn set_cached_file_hashes_batch(&self, hashes: &[(String, String)]) -> Result<()> {
+        if hashes.is_empty() {
+            return Ok(());
+        }
+
+        let timestamp = chrono::Utc::now().to_rfc3339();
+        let query = format!(
+            r#"
+            ?[file_path, content_hash, last_updated] <- [{}]
+            :put FileHashCache {{
+                file_path =>
+                content_hash,
+                last_updated
+            }}
+            "#,
+            hashes
+                .iter()
+                .map(|(path, hash)| {
+                    format!(
+                        "['{}', '{}', '{}']",
+                        escape_for_cozo_string(path),
+                        escape_for_cozo_string(hash),
+                        timestamp
+                    )
+                })
+                .collect::<Vec<_>>()
+                .join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "set_cached_file_hashes_batch".to_string(),
+                details: format!("Failed to set {} file hashes: {}", hashes.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Delete cached hashes for removed files
+    ///
+    /// # 4-Word Name: delete_cached_file_hash_values
+    pub async fn delete_cached_file_hash_values(&self, file_paths: &[String]) -> Result<()> {
+        if file_paths.is_empty() {
+            return Ok(());
+        }
+
+        let query = format!(
+            r#"
+            ?[file_path] <- [{}]
+            :rm FileHashCache {{ file_path }}
+            "#,
+            file_paths
+                .iter()
+                .map(|p| format!("['{}']", escape_for_cozo_string(p)))
+                .collect::<Vec<_>>()
+                .join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "delete_cached_file_hash_values".to_string(),
+                details: format!("Failed to delete {} file hashes: {}", file_paths.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Create EntityExtractorMetadata schema
+    ///
+    /// # 4-Word Name: create_entity_extractor_metadata_schema
+    ///
+    /// Persists `CodeEntity.metadata.additional` (receiver types, interface
+    /// method sets, embedded types) so cross-file passes can run on an
+    /// incremental ingest without re-parsing unchanged files.
+    ///
+    /// # Schema
+    /// - **Key**: ISGL1_key
+    /// - **Fields**: file_path, metadata_json (JSON object of string pairs)
+    pub async fn create_entity_extractor_metadata_schema(&self) -> Result<()> {
+        let schema = r#"
+            :create EntityExtractorMetadata {
+                ISGL1_key: String =>
+                file_path: String,
+                metadata_json: String
+            }
+        "#;
+
+        self.db
+            .run_script(schema, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "create_entity_extractor_metadata_schema".to_string(),
+                details: format!("Failed to create EntityExtractorMetadata schema: {}", e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Upsert extractor metadata for entities that carry any
+    ///
+    /// # 4-Word Name: upsert_entity_extractor_metadata_batch
+    ///
+    /// Entities with empty `metadata.additional` are skipped.
+    pub async fn upsert_entity_extractor_metadata_batch(&self, entities: &[CodeEntity]) -> Result<()> {
+        let rows: Vec<String> = entities
+            .iter()
+            .filter(|e| !e.metadata.additional.is_empty())
+            .filter_map(|e| {
+                // BTreeMap for deterministic JSON
+                let sorted: BTreeMap<&String, &String> = e.metadata.additional.iter().collect();
+                let json = serde_json::to_string(&sorted).ok()?;
+                Some(format!(
+                    "['{}', '{}', '{}']",
+                    escape_for_cozo_string(&e.isgl1_key),
+                    escape_for_cozo_string(&e.interface_signature.file_path.to_string_lossy()),
+                    escape_for_cozo_string(&json)
+                ))
+            })
+            .collect();
+
+        if rows.is_empty() {
+            return Ok(());
+        }
+
+        let query = format!(
+            r#"
+            ?[ISGL1_key, file_path, metadata_json] <- [{}]
+            :put EntityExtractorMetadata {{
+                ISGL1_key =>
+                file_path,
+                metadata_json
+            }}
+            "#,
+            rows.join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "upsert_entity_extractor_metadata_batch".to_string(),
+                details: format!("Failed to upsert {} metadata rows: {}", rows.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Delete extractor metadata rows by entity key
+    ///
+    /// # 4-Word Name: delete_entity_extractor_metadata_keys
+    pub async fn delete_entity_extractor_metadata_keys(&self, keys: &[String]) -> Result<()> {
+        if keys.is_empty() {
+            return Ok(());
+        }
+
+        let query = format!(
+            r#"
+            ?[ISGL1_key] <- [{}]
+            :rm EntityExtractorMetadata {{ ISGL1_key }}
+            "#,
+            keys.iter()
+                .map(|k| format!("['{}']", escape_for_cozo_string(k)))
+                .collect::<Vec<_>>()
+                .join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "delete_entity_extractor_metadata_keys".to_string(),
+                details: format!("Failed to delete {} metadata rows: {}", keys.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Get entities of one language with extractor metadata restored
+    ///
+    /// # 4-Word Name: get_language_entities_with_metadata
+    ///
+    /// # Contract
+    /// - Postcondition: `metadata.additional` filled from EntityExtractorMetadata
+    ///   (left empty when the relation or row is missing)
+    pub async fn get_language_entities_with_metadata(&self, language: &str) -> Result<Vec<CodeEntity>> {
+        let query = r#"
+            ?[ISGL1_key, Current_Code, Future_Code, interface_signature, TDD_Classification,
+              lsp_meta_data, current_ind, future_ind, Future_Action, file_path, language,
+              last_modified, entity_type, entity_class, birth_timestamp, content_hash, semantic_path] :=
+            *CodeGraph{
+                ISGL1_key, Current_Code, Future_Code, interface_signature, TDD_Classification,
+                lsp_meta_data, current_ind, future_ind, Future_Action, file_path, language,
+                last_modified, entity_type, entity_class, birth_timestamp, content_hash, semantic_path
+            },
+            language == $target_language
+        "#;
+
+        let mut params = BTreeMap::new();
+        params.insert("target_language".to_string(), DataValue::Str(language.into()));
+
+        let result = self
+            .db
+            .run_script(query, params, ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "get_language_entities_with_metadata".to_string(),
+                details: format!("Failed to query {} entities: {}", language, e),
+            })?;
+
+        let metadata_by_key = self.load_extractor_metadata_by_key();
+
+        let mut entities = Vec::new();
+        for row in result.rows {
+            let mut entity = self.row_to_entity(&row)?;
+            if let Some(metadata) = metadata_by_key.get(&entity.isgl1_key) {
+                entity.metadata.additional = metadata.clone();
+            }
+            entities.push(entity);
+        }
+
+        Ok(entities)
+    }
+
+    /// Get every entity with extractor metadata restored
+    ///
+    /// # 4-Word Name: get_all_entities_with_metadata
+    ///
+    /// # Contract
+    /// - Postcondition: same rows as `get_all_entities`, `metadata.additional`
+    ///   filled as in `get_language_entities_with_metadata`
+    pub async fn get_all_entities_with_metadata(&self) -> Result<Vec<CodeEntity>> {
+        let mut entities = self.get_all_entities().await?;
+        let metadata_by_key = self.load_extractor_metadata_by_key();
+        for entity in &mut entities {
+            if let Some(metadata) = metadata_by_key.get(&entity.isgl1_key) {
+                entity.metadata.additional = metadata.clone();
+            }
+        }
+        Ok(entities)
+    }
+
+    /// Extractor metadata of every entity that has any
+    ///
+    /// # 4-Word Name: get_entity_extractor_metadata_map
+    ///
+    /// # Returns
+    /// `metadata.additional` by ISGL1 key (empty if the relation is missing)
+    pub async fn get_entity_extractor_metadata_map(&self) -> Result<HashMap<String, HashMap<String, String>>> {
+        Ok(self.load_extractor_metadata_by_key())
+    }
+
+    /// EntityExtractorMetadata rows by key (empty when the relation is missing)
+    fn load_extractor_metadata_by_key(&self) -> HashMap<String, HashMap<String, String>> {
+        let metadata_query = "?[ISGL1_key, metadata_json] := *EntityExtractorMetadata{ISGL1_key, metadata_json}";
+        self.db
+            .run_script(metadata_query, Default::default(), ScriptMutability::Immutable)
+            .map(|rows| {
+                rows.rows
+                    .iter()
+                    .filter_map(|row| match (row.first(), row.get(1)) {
+                        (Some(DataValue::Str(key)), Some(DataValue::Str(json))) => {
+                            serde_json::from_str(json.as_str()).ok().map(|m| (key.to_string(), m))
+                        }
+                        _ => None,
+                    })
+                    .collect()
+            })
+            .unwrap_or_default()
+    }
+
+    /// Get edges whose from_key or to_key is in the provided list
+    ///
+    /// # 4-Word Name: get_edges_touching_entity_keys
+    ///
+    /// # Performance Contract
+    /// - Query for 100 keys: <100ms
+    pub async fn get_edges_touching_entity_keys(&self, keys: &[String]) -> Result<Vec<DependencyEdge>> {
+        if keys.is_empty() {
+            return Ok(Vec::new());
+        }
+
+        let key_list = keys
+            .iter()
+            .map(|k| format!("'{}'", escape_for_cozo_string(k)))
+            .collect::<Vec<_>>()
+            .join(", ");
+        let query = format!(
+            r#"
+            touching[from_key, to_key, edge_type, source_location, edge_metadata] :=
+                *DependencyEdges{{from_key, to_key, edge_type, source_location, edge_metadata}},
+                from_key in [{keys}]
+            touching[from_key, to_key, edge_type, source_location, edge_metadata] :=
+                *DependencyEdges{{from_key, to_key, edge_type, source_location, edge_metadata}},
+                to_key in [{keys}]
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] :=
+                touching[from_key, to_key, edge_type, source_location, edge_metadata]
+            "#,
+            keys = key_list
+        );
+
+        let result = self
+            .db
+            .run_script(&query, Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DependencyError {
+                operation: "get_edges_touching_entity_keys".to_string(),
+                reason: format!("Failed to query edges for {} keys: {}", keys.len(), e),
+            })?;
+
+        let mut edges = Vec::new();
+        for row in result.rows {
+            let (Some(DataValue::Str(from_key)), Some(DataValue::Str(to_key)), Some(DataValue::Str(edge_type_str))) =
+                (row.first(), row.get(1), row.get(2))
+            else {
+                continue;
+            };
+            let Ok(edge_type) = edge_type_str.as_str().parse::<EdgeType>() else {
+                continue;
+            };
+
+            let mut builder = DependencyEdge::builder()
+                .from_key(from_key.to_string())
+                .to_key(to_key.to_string())
+                .edge_type(edge_type);
+            if let Some(DataValue::Str(loc)) = row.get(3) {
+                builder = builder.source_location(loc.to_string());
+            }
+            let Ok(mut edge) = builder.build() else {
+                continue;
+            };
+            if let Some(DataValue::Str(meta_json)) = row.get(4) {
+                edge.metadata = serde_json::from_str(meta_json.as_str()).unwrap_or_default();
+            }
+            edges.push(edge);
+        }
+
+        Ok(edges)
+    }
+
+    /// Delete exactly the given edges (matched on the composite key)
+    ///
+    /// # 4-Word Name: delete_edges_batch_exact
+    ///
+    /// # Returns
+    /// Number of edges requested for deletion
+    pub async fn delete_edges_batch_exact(&self, edges: &[DependencyEdge]) -> Result<usize> {
+        if edges.is_empty() {
+            return Ok(0);
+        }
+
+        let query = format!(
+            r#"
+            ?[from_key, to_key, edge_type] <- [{}]
+            :rm DependencyEdges {{ from_key, to_key, edge_type }}
+            "#,
+            edges
+                .iter()
+                .map(|edge| {
+                    format!(
+                        "['{}', '{}', '{}']",
+                        escape_for_cozo_string(edge.from_key.as_ref()),
+                        escape_for_cozo_string(edge.to_key.as_ref()),
+                        edge.edge_type.as_str()
+                    )
+                })
+                .collect::<Vec<_>>()
+                .join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DependencyError {
+                operation: "delete_edges_batch_exact".to_string(),
+                reason: format!("Failed to delete {} edges: {}", edges.len(), e),
+            })?;
+
+        Ok(edges.len())
+    }
+
+    /// Resolve a user-typed symbol to entity keys (v1.7.3)
+    ///
+    /// # 4-Word Name: find_entity_keys_by_symbol
+    ///
+    /// # Arguments
+    /// * `symbol` - Exact ISGL1 key, bare name (`Query`), or qualified name
+    ///   (`sql.DB.Query`, `auth::login`); qualified names match on the leaf
+    ///
+    /// # Returns
+    /// Sorted keys; an exact key match is returned alone
+    pub async fn find_entity_keys_by_symbol(&self, symbol: &str) -> Result<Vec<String>> {
+        let result = self
+            .db
+            .run_script("?[key] := *CodeGraph{ISGL1_key: key}", Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "find_entity_keys_by_symbol".to_string(),
+                details: format!("Failed to list entity keys: {}", e),
+            })?;
+
+        let keys: Vec<String> = result
+            .rows
+            .iter()
+            .filter_map(|row| match row.first() {
+                Some(DataValue::Str(s)) => Some(s.to_string()),
+                _ => None,
+            })
+            .collect();
+
+        if keys.iter().any(|k| k == symbol) {
+            return Ok(vec![symbol.to_string()]);
+        }
+
+        let leaf = symbol
+            .rsplit(|c: char| c == '.' || c == ':' || c == '#')
+            .find(|s| !s.is_empty())
+            .unwrap_or(symbol);
+        let mut matches: Vec<String> = keys
+            .into_iter()
+            .filter(|k| k.split(':').nth(2) == Some(leaf))
+            .collect();
+        matches.sort();
+        Ok(matches)
+    }
+
     /// Count total entities in database
     ///
     /// # 4-Word Name: count_all_entities_total
@@ -2207,6 +2850,389 @@ impl CozoDbStorage {
 
         Ok(0)
     }
+
+    /// Create SymbolEmbedding schema (v1.7.3)
+    ///
+    /// # 4-Word Name: create_symbol_embedding_schema
+    ///
+    /// # Schema
+    /// - **Key**: ISGL1_key
+    /// - **Fields**: model, input_hash (hash of the embedded text),
+    ///   vector_json (JSON array of floats)
+    pub async fn create_symbol_embedding_schema(&self) -> Result<()> {
+        let schema = r#"
+            :create SymbolEmbedding {
+                ISGL1_key: String =>
+                model: String,
+                input_hash: String,
+                vector_json: String
+            }
+        "#;
+
+        self.db
+            .run_script(schema, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "create_symbol_embedding_schema".to_string(),
+                details: format!("Failed to create SymbolEmbedding schema: {}", e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Upsert symbol embeddings in one transaction
+    ///
+    /// # 4-Word Name: upsert_symbol_embeddings_batch
+    pub async fn upsert_symbol_embeddings_batch(
+        &self,
+        records: &[crate::semantic_symbol_embedding_index::SymbolEmbeddingRecord],
+    ) -> Result<()> {
+        if records.is_empty() {
+            return Ok(());
+        }
+
+        let rows: Vec<String> = records
+            .iter()
+            .map(|record| {
+                let vector_json = serde_json::to_string(&record.vector).unwrap_or_else(|_| "[]".to_string());
+                format!(
+                    "['{}', '{}', '{}', '{}']",
+                    escape_for_cozo_string(&record.isgl1_key),
+                    escape_for_cozo_string(&record.model),
+                    escape_for_cozo_string(&record.input_hash),
+                    escape_for_cozo_string(&vector_json)
+                )
+            })
+            .collect();
+        let query = format!(
+            r#"
+            ?[ISGL1_key, model, input_hash, vector_json] <- [{}]
+            :put SymbolEmbedding {{
+                ISGL1_key =>
+                model,
+                input_hash,
+                vector_json
+            }}
+            "#,
+            rows.join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "upsert_symbol_embeddings_batch".to_string(),
+                details: format!("Failed to upsert {} embeddings: {}", records.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Load every stored symbol embedding
+    ///
+    /// # 4-Word Name: get_all_symbol_embeddings
+    ///
+    /// # Returns
+    /// All rows of SymbolEmbedding (empty if the relation is missing)
+    pub async fn get_all_symbol_embeddings(
+        &self,
+    ) -> Result<Vec<crate::semantic_symbol_embedding_index::SymbolEmbeddingRecord>> {
+        if !self.list_relations().await?.iter().any(|r| r == "SymbolEmbedding") {
+            return Ok(Vec::new());
+        }
+        let query = "?[ISGL1_key, model, input_hash, vector_json] := *SymbolEmbedding{ISGL1_key, model, input_hash, vector_json}";
+
+        let result = self
+            .db
+            .run_script(query, Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "get_all_symbol_embeddings".to_string(),
+                details: format!("Failed to query symbol embeddings: {}", e),
+            })?;
+
+        Ok(result
+            .rows
+            .iter()
+            .filter_map(|row| match (row.first(), row.get(1), row.get(2), row.get(3)) {
+                (
+                    Some(DataValue::Str(key)),
+                    Some(DataValue::Str(model)),
+                    Some(DataValue::Str(input_hash)),
+                    Some(DataValue::Str(vector_json)),
+                ) => Some(crate::semantic_symbol_embedding_index::SymbolEmbeddingRecord {
+                    isgl1_key: key.to_string(),
+                    model: model.to_string(),
+                    input_hash: input_hash.to_string(),
+                    vector: serde_json::from_str(vector_json).ok()?,
+                }),
+                _ => None,
+            })
+            .collect())
+    }
+
+    /// Delete stored symbol embeddings by entity key
+    ///
+    /// # 4-Word Name: delete_symbol_embeddings_by_keys
+    ///
+    /// # Contract
+    /// - Postcondition: no-op when the SymbolEmbedding relation is missing
+    pub async fn delete_symbol_embeddings_by_keys(&self, keys: &[String]) -> Result<()> {
+        if keys.is_empty() || !self.list_relations().await?.iter().any(|r| r == "SymbolEmbedding") {
+            return Ok(());
+        }
+
+        let query = format!(
+            r#"
+            ?[ISGL1_key] <- [{}]
+            :rm SymbolEmbedding {{ ISGL1_key }}
+            "#,
+            keys.iter()
+                .map(|k| format!("['{}']", escape_for_cozo_string(k)))
+                .collect::<Vec<_>>()
+                .join(", ")
+        );
+
+        self.db
+            .run_script(&query, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "delete_symbol_embeddings_by_keys".to_string(),
+                details: format!("Failed to delete {} embeddings: {}", keys.len(), e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Create SymbolCentrality schema (v1.7.3)
+    ///
+    /// # 4-Word Name: create_symbol_centrality_schema
+    ///
+    /// # Schema
+    /// - **Key**: ISGL1_key
+    /// - **Fields**: pagerank, betweenness, in_degree, out_degree, importance
+    pub async fn create_symbol_centrality_schema(&self) -> Result<()> {
+        let schema = r#"
+            :create SymbolCentrality {
+                ISGL1_key: String =>
+                pagerank: Float,
+                betweenness: Float,
+                in_degree: Int,
+                out_degree: Int,
+                importance: Float
+            }
+        "#;
+
+        self.db
+            .run_script(schema, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "create_symbol_centrality_schema".to_string(),
+                details: format!("Failed to create SymbolCentrality schema: {}", e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Replace all stored centrality scores
+    ///
+    /// # 4-Word Name: replace_symbol_centrality_scores
+    ///
+    /// Creates the relation when missing; rows of symbols no longer in the
+    /// graph are removed.
+    pub async fn replace_symbol_centrality_scores(
+        &self,
+        records: &[crate::symbol_centrality_score_ranker::SymbolCentralityScoreRecord],
+    ) -> Result<()> {
+        if self.list_relations().await?.iter().any(|r| r == "SymbolCentrality") {
+            let clear = r#"
+                ?[ISGL1_key] := *SymbolCentrality{ISGL1_key}
+                :rm SymbolCentrality { ISGL1_key }
+            "#;
+            self.db
+                .run_script(clear, Default::default(), ScriptMutability::Mutable)
+                .map_err(|e| ParseltongError::DatabaseError {
+                    operation: "replace_symbol_centrality_scores".to_string(),
+                    details: format!("Failed to clear centrality scores: {}", e),
+                })?;
+        } else {
+            self.create_symbol_centrality_schema().await?;
+        }
+
+        for chunk in records.chunks(1000) {
+            let rows: Vec<String> = chunk
+                .iter()
+                .map(|record| {
+                    format!(
+                        "['{}', {:.12}, {:.12}, {}, {}, {:.12}]",
+                        escape_for_cozo_string(&record.isgl1_key),
+                        record.pagerank,
+                        record.betweenness,
+                        record.in_degree,
+                        record.out_degree,
+                        record.importance
+                    )
+                })
+                .collect();
+            let query = format!(
+                r#"
+                ?[ISGL1_key, pagerank, betweenness, in_degree, out_degree, importance] <- [{}]
+                :put SymbolCentrality {{
+                    ISGL1_key =>
+                    pagerank,
+                    betweenness,
+                    in_degree,
+                    out_degree,
+                    importance
+                }}
+                "#,
+                rows.join(", ")
+            );
+            self.db
+                .run_script(&query, Default::default(), ScriptMutability::Mutable)
+                .map_err(|e| ParseltongError::DatabaseError {
+                    operation: "replace_symbol_centrality_scores".to_string(),
+                    details: format!("Failed to store {} centrality scores: {}", chunk.len(), e),
+                })?;
+        }
+
+        Ok(())
+    }
+
+    /// Load every stored centrality score, most important first
+    ///
+    /// # 4-Word Name: get_all_symbol_centrality_scores
+    ///
+    /// # Returns
+    /// All rows of SymbolCentrality (empty if the relation is missing)
+    pub async fn get_all_symbol_centrality_scores(
+        &self,
+    ) -> Result<Vec<crate::symbol_centrality_score_ranker::SymbolCentralityScoreRecord>> {
+        if !self.list_relations().await?.iter().any(|r| r == "SymbolCentrality") {
+            return Ok(Vec::new());
+        }
+        let query = "?[ISGL1_key, pagerank, betweenness, in_degree, out_degree, importance] := \
+            *SymbolCentrality{ISGL1_key, pagerank, betweenness, in_degree, out_degree, importance}";
+
+        let result = self
+            .db
+            .run_script(query, Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "get_all_symbol_centrality_scores".to_string(),
+                details: format!("Failed to query centrality scores: {}", e),
+            })?;
+
+        let number = |value: Option<&DataValue>| -> f64 {
+            match value {
+                Some(DataValue::Num(cozo::Num::Float(f))) => *f,
+                Some(DataValue::Num(cozo::Num::Int(i))) => *i as f64,
+                _ => 0.0,
+            }
+        };
+        let mut records: Vec<_> = result
+            .rows
+            .iter()
+            .filter_map(|row| match row.first() {
+                Some(DataValue::Str(key)) => Some(crate::symbol_centrality_score_ranker::SymbolCentralityScoreRecord {
+                    isgl1_key: key.to_string(),
+                    pagerank: number(row.get(1)),
+                    betweenness: number(row.get(2)),
+                    in_degree: number(row.get(3)) as usize,
+                    out_degree: number(row.get(4)) as usize,
+                    importance: number(row.get(5)),
+                }),
+                _ => None,
+            })
+            .collect();
+        crate::symbol_centrality_score_ranker::sort_centrality_records_by_importance(&mut records);
+        Ok(records)
+    }
+
+    /// Create ExtractorCapabilityManifest schema (v1.7.3)
+    ///
+    /// # 4-Word Name: create_extractor_capability_manifest_schema
+    ///
+    /// # Schema
+    /// - **Key**: name (`installed` for the extractors that built the graph)
+    /// - **Fields**: manifest_json
+    pub async fn create_extractor_capability_manifest_schema(&self) -> Result<()> {
+        let schema = r#"
+            :create ExtractorCapabilityManifest {
+                name: String =>
+                manifest_json: String
+            }
+        "#;
+
+        self.db
+            .run_script(schema, Default::default(), ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "create_extractor_capability_manifest_schema".to_string(),
+                details: format!("Failed to create ExtractorCapabilityManifest schema: {}", e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Store the capability manifest of the extractors that built the graph
+    ///
+    /// # 4-Word Name: store_extractor_capability_manifest
+    ///
+    /// Creates the relation when missing and replaces any earlier manifest.
+    pub async fn store_extractor_capability_manifest(
+        &self,
+        manifest: &crate::extractor_capability_manifest_builder::ExtractorCapabilityManifestRecord,
+    ) -> Result<()> {
+        if !self.list_relations().await?.iter().any(|r| r == "ExtractorCapabilityManifest") {
+            self.create_extractor_capability_manifest_schema().await?;
+        }
+        let json = serde_json::to_string(manifest).map_err(|e| ParseltongError::DatabaseError {
+            operation: "store_extractor_capability_manifest".to_string(),
+            details: format!("Failed to serialize capability manifest: {}", e),
+        })?;
+
+        let mut params = BTreeMap::new();
+        params.insert("manifest_json".to_string(), DataValue::Str(json.into()));
+        let query = r#"
+            ?[name, manifest_json] <- [['installed', $manifest_json]]
+            :put ExtractorCapabilityManifest { name => manifest_json }
+        "#;
+        self.db
+            .run_script(query, params, ScriptMutability::Mutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "store_extractor_capability_manifest".to_string(),
+                details: format!("Failed to store capability manifest: {}", e),
+            })?;
+
+        Ok(())
+    }
+
+    /// Load the capability manifest stored with the graph
+    ///
+    /// # 4-Word Name: get_extractor_capability_manifest
+    ///
+    /// # Returns
+    /// None for graphs indexed before manifests were recorded
+    pub async fn get_extractor_capability_manifest(
+        &self,
+    ) -> Result<Option<crate::extractor_capability_manifest_builder::ExtractorCapabilityManifestRecord>> {
+        if !self.list_relations().await?.iter().any(|r| r == "ExtractorCapabilityManifest") {
+            return Ok(None);
+        }
+        let query = "?[manifest_json] := *ExtractorCapabilityManifest{name: 'installed', manifest_json}";
+
+        let result = self
+            .db
+            .run_script(query, Default::default(), ScriptMutability::Immutable)
+            .map_err(|e| ParseltongError::DatabaseError {
+                operation: "get_extractor_capability_manifest".to_string(),
+                details: format!("Failed to query capability manifest: {}", e),
+            })?;
+
+        match result.rows.first().and_then(|row| row.first()) {
+            Some(DataValue::Str(json)) => serde_json::from_str(json).map(Some).map_err(|e| {
+                ParseltongError::DatabaseError {
+                    operation: "get_extractor_capability_manifest".to_string(),
+                    details: format!("Failed to parse capability manifest: {}", e),
+                }
+            }),
+            _ => Ok(None),
+        }
+    }
 }
 
 // Implement CodeGraphRepository trait
@@ -5,5 +5,7 @@
 
 pub mod cozo_client;
 pub mod path_utils;
+pub mod relational_sqlite_graph_store; // v1.7.3: Plain-SQL graph mirror (nodes/edges/files)
+pub mod revision_history_sqlite_store; // v1.7.3: Per-commit graph snapshots for --at and history
 
 pub use cozo_client::{CozoDbStorage, escape_for_cozo_string};
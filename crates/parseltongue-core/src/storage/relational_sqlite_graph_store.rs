@@ -0,0 +1,376 @@
+//! Relational SQLite graph store (v1.7.3)
+//!
+//! # 4-Word Naming: relational_sqlite_graph_store
+//!
+//! CozoDB's `sqlite:` engine persists the graph, but as opaque key/value
+//! blobs. This store writes the same graph as plain relational tables so it
+//! survives restarts in a single file, supports indexed lookups, and can be
+//! read with `sqlite3` or any SQL client:
+//!
+//! ```sql
+//! SELECT e.from_key FROM edges e JOIN nodes n ON n.key = e.to_key
+//! WHERE n.name = 'Save' AND e.edge_type = 'Calls';
+//! ```
+//!
+//! ## Tables
+//!
+//! - `nodes(key, name, kind, language, file_path, line_start, line_end, entity_class, code)`
+//! - `edges(from_key, to_key, edge_type, source_location)`
+//! - `files(path, content_hash)` — FileHashCache, for incremental re-ingest
+//! - `graph_meta(key, value)` — schema version, write timestamp
+//!
+//! Uses the `sqlite` crate: the same libsqlite3 link that cozo's
+//! `storage-sqlite` feature already pulls in (a second SQLite binding would
+//! conflict on `links = "sqlite3"`).
+
+use std::collections::HashMap;
+use std::path::Path;
+use std::str::FromStr;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass};
+use crate::error::{ParseltongError, Result};
+use crate::storage::CozoDbStorage;
+
+/// Schema version stored in `graph_meta`
+pub const RELATIONAL_SCHEMA_VERSION_VALUE: &str = "1";
+
+const RELATIONAL_GRAPH_SCHEMA_SQL: &str = "
+    CREATE TABLE IF NOT EXISTS nodes (
+        key TEXT PRIMARY KEY,
+        name TEXT NOT NULL,
+        kind TEXT NOT NULL,
+        language TEXT NOT NULL,
+        file_path TEXT NOT NULL,
+        line_start INTEGER NOT NULL,
+        line_end INTEGER NOT NULL,
+        entity_class TEXT NOT NULL,
+        code TEXT
+    );
+    CREATE TABLE IF NOT EXISTS edges (
+        from_key TEXT NOT NULL,
+        to_key TEXT NOT NULL,
+        edge_type TEXT NOT NULL,
+        source_location TEXT,
+        PRIMARY KEY (from_key, to_key, edge_type)
+    );
+    CREATE TABLE IF NOT EXISTS files (
+        path TEXT PRIMARY KEY,
+        content_hash TEXT NOT NULL
+    );
+    CREATE TABLE IF NOT EXISTS graph_meta (
+        key TEXT PRIMARY KEY,
+        value TEXT NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_nodes_name ON nodes(name);
+    CREATE INDEX IF NOT EXISTS idx_nodes_file_path ON nodes(file_path);
+    CREATE INDEX IF NOT EXISTS idx_edges_to_key ON edges(to_key);
+    CREATE INDEX IF NOT EXISTS idx_edges_edge_type ON edges(edge_type);
+";
+
+/// Row counts written by one snapshot
+///
+/// # 4-Word Name: SqliteSnapshotWriteCounts
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct SqliteSnapshotWriteCounts {
+    pub nodes: usize,
+    pub edges: usize,
+    pub files: usize,
+}
+
+/// Relational SQLite mirror of the code graph
+///
+/// # 4-Word Name: RelationalSqliteGraphStore
+pub struct RelationalSqliteGraphStore {
+    connection: sqlite::Connection,
+}
+
+/// Map a sqlite error into the core error type
+fn sqlite_error_for_operation(operation: &str) -> impl Fn(sqlite::Error) -> ParseltongError + '_ {
+    move |e| ParseltongError::DatabaseError {
+        operation: operation.to_string(),
+        details: format!("SQLite: {}", e),
+    }
+}
+
+impl RelationalSqliteGraphStore {
+    /// Open (or create) a store and ensure the schema exists
+    ///
+    /// # 4-Word Name: open_with_graph_schema
+    ///
+    /// # Contract
+    /// - `path` is a file path or `":memory:"`
+    /// - Postcondition: all tables and indexes exist; existing rows untouched
+    pub fn open_with_graph_schema(path: impl AsRef<Path>) -> Result<Self> {
+        let connection = sqlite::open(path.as_ref()).map_err(sqlite_error_for_operation("sqlite_open"))?;
+        connection
+            .execute(RELATIONAL_GRAPH_SCHEMA_SQL)
+            .map_err(sqlite_error_for_operation("sqlite_create_schema"))?;
+        Ok(Self { connection })
+    }
+
+    /// Replace the stored graph with a new snapshot
+    ///
+    /// # 4-Word Name: replace_graph_snapshot_atomically
+    ///
+    /// # Contract
+    /// - Postcondition: tables hold exactly the given rows; on error the
+    ///   transaction is rolled back and the previous snapshot is kept
+    /// - Duplicate edges (same from, to, type) collapse to one row
+    pub fn replace_graph_snapshot_atomically(
+        &self,
+        entities: &[CodeEntity],
+        edges: &[DependencyEdge],
+        file_hashes: &HashMap<String, String>,
+    ) -> Result<SqliteSnapshotWriteCounts> {
+        self.connection
+            .execute("BEGIN IMMEDIATE")
+            .map_err(sqlite_error_for_operation("sqlite_begin"))?;
+        match self.write_snapshot_rows_inside(entities, edges, file_hashes) {
+            Ok(counts) => {
+                self.connection
+                    .execute("COMMIT")
+                    .map_err(sqlite_error_for_operation("sqlite_commit"))?;
+                Ok(counts)
+            }
+            Err(e) => {
+                let _ = self.connection.execute("ROLLBACK");
+                Err(e)
+            }
+        }
+    }
+
+    fn write_snapshot_rows_inside(
+        &self,
+        entities: &[CodeEntity],
+        edges: &[DependencyEdge],
+        file_hashes: &HashMap<String, String>,
+    ) -> Result<SqliteSnapshotWriteCounts> {
+        let write_err = sqlite_error_for_operation("sqlite_write_snapshot");
+        self.connection
+            .execute("DELETE FROM nodes; DELETE FROM edges; DELETE FROM files;")
+            .map_err(&write_err)?;
+
+        let mut counts = SqliteSnapshotWriteCounts::default();
+
+        let mut insert_node = self
+            .connection
+            .prepare(
+                "INSERT OR REPLACE INTO nodes
+                 (key, name, kind, language, file_path, line_start, line_end, entity_class, code)
+                 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+            )
+            .map_err(&write_err)?;
+        for entity in entities {
+            let key = entity.isgl1_key.as_str();
+            let mut segments = key.split(':');
+            let language = segments.next().unwrap_or("");
+            let kind = segments.next().unwrap_or("");
+            let file_path = entity.interface_signature.file_path.to_string_lossy();
+            let entity_class = match entity.entity_class {
+                EntityClass::TestImplementation => "TEST",
+                EntityClass::CodeImplementation => "CODE",
+            };
+
+            insert_node.reset().map_err(&write_err)?;
+            insert_node.bind((1, key)).map_err(&write_err)?;
+            insert_node.bind((2, entity.interface_signature.name.as_str())).map_err(&write_err)?;
+            insert_node.bind((3, kind)).map_err(&write_err)?;
+            insert_node.bind((4, language)).map_err(&write_err)?;
+            insert_node.bind((5, file_path.as_ref())).map_err(&write_err)?;
+            insert_node.bind((6, entity.interface_signature.line_range.start as i64)).map_err(&write_err)?;
+            insert_node.bind((7, entity.interface_signature.line_range.end as i64)).map_err(&write_err)?;
+            insert_node.bind((8, entity_class)).map_err(&write_err)?;
+            insert_node.bind((9, entity.current_code.as_deref())).map_err(&write_err)?;
+            insert_node.next().map_err(&write_err)?;
+            counts.nodes += 1;
+        }
+
+        let mut insert_edge = self
+            .connection
+            .prepare(
+                "INSERT OR IGNORE INTO edges (from_key, to_key, edge_type, source_location)
+                 VALUES (?, ?, ?, ?)",
+            )
+            .map_err(&write_err)?;
+        for edge in edges {
+            insert_edge.reset().map_err(&write_err)?;
+            insert_edge.bind((1, edge.from_key.as_str())).map_err(&write_err)?;
+            insert_edge.bind((2, edge.to_key.as_str())).map_err(&write_err)?;
+            insert_edge.bind((3, edge.edge_type.as_str())).map_err(&write_err)?;
+            insert_edge.bind((4, edge.source_location.as_deref())).map_err(&write_err)?;
+            insert_edge.next().map_err(&write_err)?;
+            if self.connection.change_count() > 0 {
+                counts.edges += 1;
+            }
+        }
+
+        let mut insert_file = self
+            .connection
+            .prepare("INSERT OR REPLACE INTO files (path, content_hash) VALUES (?, ?)")
+            .map_err(&write_err)?;
+        for (path, hash) in file_hashes {
+            insert_file.reset().map_err(&write_err)?;
+            insert_file.bind((1, path.as_str())).map_err(&write_err)?;
+            insert_file.bind((2, hash.as_str())).map_err(&write_err)?;
+            insert_file.next().map_err(&write_err)?;
+            counts.files += 1;
+        }
+
+        let mut upsert_meta = self
+            .connection
+            .prepare("INSERT OR REPLACE INTO graph_meta (key, value) VALUES (?, ?)")
+            .map_err(&write_err)?;
+        let written_at = chrono::Utc::now().to_rfc3339();
+        for (key, value) in [
+            ("schema_version", RELATIONAL_SCHEMA_VERSION_VALUE),
+            ("written_at", written_at.as_str()),
+        ] {
+            upsert_meta.reset().map_err(&write_err)?;
+            upsert_meta.bind((1, key)).map_err(&write_err)?;
+            upsert_meta.bind((2, value)).map_err(&write_err)?;
+            upsert_meta.next().map_err(&write_err)?;
+        }
+
+        Ok(counts)
+    }
+
+    /// Load every stored edge
+    ///
+    /// # 4-Word Name: load_dependency_edges_all
+    ///
+    /// Rows with an edge type this build does not know are skipped.
+    pub fn load_dependency_edges_all(&self) -> Result<Vec<DependencyEdge>> {
+        let read_err = sqlite_error_for_operation("sqlite_load_edges");
+        let mut statement = self
+            .connection
+            .prepare("SELECT from_key, to_key, edge_type, source_location FROM edges ORDER BY from_key, to_key, edge_type")
+            .map_err(&read_err)?;
+
+        let mut edges = Vec::new();
+        while statement.next().map_err(&read_err)? == sqlite::State::Row {
+            let edge_type_text = statement.read::<String, _>(2).map_err(&read_err)?;
+            let Ok(edge_type) = EdgeType::from_str(&edge_type_text) else {
+                continue;
+            };
+            let mut builder = DependencyEdge::builder()
+                .from_key(statement.read::<String, _>(0).map_err(&read_err)?)
+                .to_key(statement.read::<String, _>(1).map_err(&read_err)?)
+                .edge_type(edge_type);
+            if let Some(location) = statement.read::<Option<String>, _>(3).map_err(&read_err)? {
+                builder = builder.source_location(location);
+            }
+            edges.push(builder.build()?);
+        }
+        Ok(edges)
+    }
+
+    /// Entity keys with an exact name (indexed lookup)
+    ///
+    /// # 4-Word Name: find_node_keys_by_name
+    pub fn find_node_keys_by_name(&self, name: &str) -> Result<Vec<String>> {
+        let read_err = sqlite_error_for_operation("sqlite_find_nodes");
+        let mut statement = self
+            .connection
+            .prepare("SELECT key FROM nodes WHERE name = ? ORDER BY key")
+            .map_err(&read_err)?;
+        statement.bind((1, name)).map_err(&read_err)?;
+
+        let mut keys = Vec::new();
+        while statement.next().map_err(&read_err)? == sqlite::State::Row {
+            keys.push(statement.read::<String, _>(0).map_err(&read_err)?);
+        }
+        Ok(keys)
+    }
+
+    /// Stored file content hashes (path -> hash)
+    ///
+    /// # 4-Word Name: load_file_hashes_map
+    pub fn load_file_hashes_map(&self) -> Result<HashMap<String, String>> {
+        let read_err = sqlite_error_for_operation("sqlite_load_files");
+        let mut statement = self
+            .connection
+            .prepare("SELECT path, content_hash FROM files")
+            .map_err(&read_err)?;
+
+        let mut hashes = HashMap::new();
+        while statement.next().map_err(&read_err)? == sqlite::State::Row {
+            hashes.insert(
+                statement.read::<String, _>(0).map_err(&read_err)?,
+                statement.read::<String, _>(1).map_err(&read_err)?,
+            );
+        }
+        Ok(hashes)
+    }
+}
+
+/// Mirror a CozoDB graph into a relational SQLite file
+///
+/// # 4-Word Name: export_cozo_graph_to_sqlite
+///
+/// # Contract
+/// - Precondition: `storage` holds an ingested graph (CodeGraph, DependencyEdges;
+///   FileHashCache is optional and treated as empty when missing)
+/// - Postcondition: `path` holds exactly the current graph
+pub async fn export_cozo_graph_to_sqlite(
+    storage: &CozoDbStorage,
+    path: impl AsRef<Path>,
+) -> Result<SqliteSnapshotWriteCounts> {
+    let entities = storage.get_all_entities().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let file_hashes = storage.get_all_cached_file_hashes().await.unwrap_or_default();
+
+    let store = RelationalSqliteGraphStore::open_with_graph_schema(path)?;
+    store.replace_graph_snapshot_atomically(&entities, &edges, &file_hashes)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .source_location("svc/service.go:3")
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_snapshot_roundtrips_edges_and_files() {
+        let store = RelationalSqliteGraphStore::open_with_graph_schema(":memory:").unwrap();
+        let entities = vec![
+            create_go_test_entity("go:method:Save:__svc:T1", "Save", EntityType::Method, "svc/service.go", &[]),
+            create_go_test_entity("go:fn:main:__cmd:T2", "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![
+            edge("go:fn:main:__cmd:T2", "go:method:Save:__svc:T1", EdgeType::Calls),
+            edge("go:fn:main:__cmd:T2", "go:method:Save:__svc:T1", EdgeType::Calls),
+        ];
+        let hashes: HashMap<String, String> = [("cmd/main.go".to_string(), "abc".to_string())].into_iter().collect();
+
+        let counts = store.replace_graph_snapshot_atomically(&entities, &edges, &hashes).unwrap();
+        assert_eq!(counts, SqliteSnapshotWriteCounts { nodes: 2, edges: 1, files: 1 });
+
+        let loaded = store.load_dependency_edges_all().unwrap();
+        assert_eq!(loaded, vec![edges[0].clone()]);
+        assert_eq!(store.load_file_hashes_map().unwrap(), hashes);
+        assert_eq!(store.find_node_keys_by_name("Save").unwrap(), vec!["go:method:Save:__svc:T1"]);
+    }
+
+    #[test]
+    fn test_second_snapshot_replaces_first() {
+        let store = RelationalSqliteGraphStore::open_with_graph_schema(":memory:").unwrap();
+        let first = vec![create_go_test_entity("go:fn:old:__x:T1", "old", EntityType::Function, "x/a.go", &[])];
+        store.replace_graph_snapshot_atomically(&first, &[], &HashMap::new()).unwrap();
+
+        let second = vec![create_go_test_entity("go:fn:new:__x:T2", "new", EntityType::Function, "x/a.go", &[])];
+        store.replace_graph_snapshot_atomically(&second, &[], &HashMap::new()).unwrap();
+
+        assert!(store.find_node_keys_by_name("old").unwrap().is_empty());
+        assert_eq!(store.find_node_keys_by_name("new").unwrap().len(), 1);
+    }
+}
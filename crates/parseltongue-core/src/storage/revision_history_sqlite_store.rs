@@ -0,0 +1,654 @@
+//! Per-revision graph history in SQLite (v1.7.3)
+//!
+//! # 4-Word Naming: revision_history_sqlite_store
+//!
+//! `parseltongue history record` indexes commits (or every tag) and keeps
+//! one lightweight snapshot per commit in a single SQLite file, so the graph
+//! can be queried as it was (`query ... --at v1.2.0`) and a symbol's
+//! evolution read back (`parseltongue history Save`) without re-indexing.
+//!
+//! ## Tables
+//!
+//! - `revisions(id, commit_sha, tags, committed_at, subject, recorded_at, symbol_count, call_count, snapshot)` —
+//!   `snapshot` is the `export --format json` document, zstd-compressed, that
+//!   `--at` loads as a `snapshot:` database
+//! - `revision_symbols(revision_id, key, name, kind, file_path, signature, body_hash)` —
+//!   what the history timeline compares: one-line signature and a hash of the body
+//! - `revision_calls(revision_id, caller_key, callee_key)` — Calls edges only,
+//!   for callers gained and lost
+//!
+//! Entity keys are a function of file and name, so the same symbol has the
+//! same key in every revision; a symbol moved to another file is a new key.
+
+use std::collections::{BTreeMap, BTreeSet};
+use std::io::Write;
+use std::path::Path;
+
+use serde::Serialize;
+use sha2::{Digest, Sha256};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType};
+use crate::error::{ParseltongError, Result};
+use crate::extractor_capability_manifest_builder::ExtractorCapabilityManifestRecord;
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::serializers::json_snapshot::{write_graph_as_json_snapshot, ZSTD_COMPRESSION_LEVEL};
+use crate::symbol_doc_signature_extractor::{extract_symbol_signature_line, SIGNATURE_METADATA_KEY};
+
+const REVISION_HISTORY_SCHEMA_SQL: &str = "
+    CREATE TABLE IF NOT EXISTS revisions (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        commit_sha TEXT NOT NULL UNIQUE,
+        tags TEXT NOT NULL,
+        committed_at TEXT NOT NULL,
+        subject TEXT NOT NULL,
+        recorded_at TEXT NOT NULL,
+        symbol_count INTEGER NOT NULL,
+        call_count INTEGER NOT NULL,
+        snapshot BLOB NOT NULL
+    );
+    CREATE TABLE IF NOT EXISTS revision_symbols (
+        revision_id INTEGER NOT NULL,
+        key TEXT NOT NULL,
+        name TEXT NOT NULL,
+        kind TEXT NOT NULL,
+        file_path TEXT NOT NULL,
+        signature TEXT NOT NULL,
+        body_hash TEXT NOT NULL,
+        PRIMARY KEY (revision_id, key)
+    );
+    CREATE TABLE IF NOT EXISTS revision_calls (
+        revision_id INTEGER NOT NULL,
+        caller_key TEXT NOT NULL,
+        callee_key TEXT NOT NULL,
+        PRIMARY KEY (revision_id, caller_key, callee_key)
+    );
+    CREATE INDEX IF NOT EXISTS idx_revision_symbols_name ON revision_symbols(name);
+    CREATE INDEX IF NOT EXISTS idx_revision_calls_callee ON revision_calls(revision_id, callee_key);
+";
+
+/// The commit a snapshot was taken at
+///
+/// # 4-Word Name: RevisionCommitDetailsEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct RevisionCommitDetailsEntry {
+    pub commit_sha: String,
+    /// Tags pointing at the commit
+    pub tags: Vec<String>,
+    /// Committer date, ISO 8601
+    pub committed_at: String,
+    pub subject: String,
+}
+
+/// A recorded revision and the size of its snapshot
+///
+/// # 4-Word Name: RecordedRevisionSummaryEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct RecordedRevisionSummaryEntry {
+    #[serde(flatten)]
+    pub commit: RevisionCommitDetailsEntry,
+    pub symbol_count: usize,
+    pub call_count: usize,
+}
+
+/// One symbol as it was at one revision
+///
+/// # 4-Word Name: SymbolRevisionStateEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SymbolRevisionStateEntry {
+    pub entity_key: String,
+    pub name: String,
+    /// Key segment 1: fn, method, struct, ...
+    pub kind: String,
+    pub file_path: String,
+    pub signature: String,
+    /// Truncated SHA-256 of the body; empty when the body was not stored
+    pub body_hash: String,
+    /// Keys calling it at this revision, sorted
+    pub callers: Vec<String>,
+}
+
+/// Rows written for one revision
+///
+/// # 4-Word Name: RevisionSnapshotWriteCounts
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct RevisionSnapshotWriteCounts {
+    pub symbols: usize,
+    pub calls: usize,
+    /// Compressed snapshot size
+    pub snapshot_bytes: usize,
+}
+
+/// Per-commit graph snapshots in one SQLite file
+///
+/// # 4-Word Name: RevisionHistorySqliteStore
+pub struct RevisionHistorySqliteStore {
+    connection: sqlite::Connection,
+}
+
+/// Map a sqlite error into the core error type
+fn sqlite_error_for_operation(operation: &str) -> impl Fn(sqlite::Error) -> ParseltongError + '_ {
+    move |e| ParseltongError::DatabaseError {
+        operation: operation.to_string(),
+        details: format!("SQLite: {}", e),
+    }
+}
+
+impl RevisionHistorySqliteStore {
+    /// Open (or create) a history file and ensure the schema exists
+    ///
+    /// # 4-Word Name: open_with_history_schema
+    ///
+    /// # Contract
+    /// - `path` is a file path or `":memory:"`
+    /// - Postcondition: all tables and indexes exist; recorded revisions untouched
+    pub fn open_with_history_schema(path: impl AsRef<Path>) -> Result<Self> {
+        let connection = sqlite::open(path.as_ref()).map_err(sqlite_error_for_operation("sqlite_open"))?;
+        connection
+            .execute(REVISION_HISTORY_SCHEMA_SQL)
+            .map_err(sqlite_error_for_operation("sqlite_create_schema"))?;
+        Ok(Self { connection })
+    }
+
+    /// Store the graph of one commit, replacing an earlier recording of it
+    ///
+    /// # 4-Word Name: record_revision_snapshot_atomically
+    ///
+    /// # Contract
+    /// - Precondition: file paths are relative to the repository root
+    /// - Postcondition: the revision row, its symbols (placeholders skipped)
+    ///   and its resolved Calls edges are stored; on error the transaction
+    ///   is rolled back and the previous recording is kept
+    pub fn record_revision_snapshot_atomically(
+        &self,
+        commit: &RevisionCommitDetailsEntry,
+        entities: &[CodeEntity],
+        edges: &[DependencyEdge],
+        capabilities: Option<&ExtractorCapabilityManifestRecord>,
+    ) -> Result<RevisionSnapshotWriteCounts> {
+        let snapshot = compress_graph_json_snapshot(entities, edges, capabilities)?;
+        self.connection
+            .execute("BEGIN IMMEDIATE")
+            .map_err(sqlite_error_for_operation("sqlite_begin"))?;
+        match self.write_revision_rows_inside(commit, entities, edges, &snapshot) {
+            Ok(counts) => {
+                self.connection
+                    .execute("COMMIT")
+                    .map_err(sqlite_error_for_operation("sqlite_commit"))?;
+                Ok(counts)
+            }
+            Err(e) => {
+                let _ = self.connection.execute("ROLLBACK");
+                Err(e)
+            }
+        }
+    }
+
+    fn write_revision_rows_inside(
+        &self,
+        commit: &RevisionCommitDetailsEntry,
+        entities: &[CodeEntity],
+        edges: &[DependencyEdge],
+        snapshot: &[u8],
+    ) -> Result<RevisionSnapshotWriteCounts> {
+        let write_err = sqlite_error_for_operation("sqlite_record_revision");
+        for table_filter in [
+            "DELETE FROM revision_symbols WHERE revision_id IN (SELECT id FROM revisions WHERE commit_sha = ?)",
+            "DELETE FROM revision_calls WHERE revision_id IN (SELECT id FROM revisions WHERE commit_sha = ?)",
+            "DELETE FROM revisions WHERE commit_sha = ?",
+        ] {
+            let mut delete_previous = self.connection.prepare(table_filter).map_err(&write_err)?;
+            delete_previous.bind((1, commit.commit_sha.as_str())).map_err(&write_err)?;
+            delete_previous.next().map_err(&write_err)?;
+        }
+
+        let symbols: Vec<SymbolRevisionStateEntry> = entities
+            .iter()
+            .filter(|entity| !is_external_graph_node_key(&entity.isgl1_key))
+            .map(symbol_state_from_entity)
+            .collect();
+        let symbol_keys: BTreeSet<&str> = symbols.iter().map(|symbol| symbol.entity_key.as_str()).collect();
+        let calls: BTreeSet<(&str, &str)> = edges
+            .iter()
+            .filter(|edge| edge.edge_type == EdgeType::Calls && symbol_keys.contains(edge.to_key.as_str()))
+            .map(|edge| (edge.from_key.as_str(), edge.to_key.as_str()))
+            .collect();
+
+        let mut insert_revision = self
+            .connection
+            .prepare(
+                "INSERT INTO revisions
+                 (commit_sha, tags, committed_at, subject, recorded_at, symbol_count, call_count, snapshot)
+                 VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+            )
+            .map_err(&write_err)?;
+        let tags = commit.tags.join(" ");
+        let recorded_at = chrono::Utc::now().to_rfc3339();
+        insert_revision.bind((1, commit.commit_sha.as_str())).map_err(&write_err)?;
+        insert_revision.bind((2, tags.as_str())).map_err(&write_err)?;
+        insert_revision.bind((3, commit.committed_at.as_str())).map_err(&write_err)?;
+        insert_revision.bind((4, commit.subject.as_str())).map_err(&write_err)?;
+        insert_revision.bind((5, recorded_at.as_str())).map_err(&write_err)?;
+        insert_revision.bind((6, symbols.len() as i64)).map_err(&write_err)?;
+        insert_revision.bind((7, calls.len() as i64)).map_err(&write_err)?;
+        insert_revision.bind((8, snapshot)).map_err(&write_err)?;
+        insert_revision.next().map_err(&write_err)?;
+
+        let mut select_id = self
+            .connection
+            .prepare("SELECT id FROM revisions WHERE commit_sha = ?")
+            .map_err(&write_err)?;
+        select_id.bind((1, commit.commit_sha.as_str())).map_err(&write_err)?;
+        select_id.next().map_err(&write_err)?;
+        let revision_id = select_id.read::<i64, _>(0).map_err(&write_err)?;
+
+        let mut insert_symbol = self
+            .connection
+            .prepare(
+                "INSERT OR REPLACE INTO revision_symbols
+                 (revision_id, key, name, kind, file_path, signature, body_hash)
+                 VALUES (?, ?, ?, ?, ?, ?, ?)",
+            )
+            .map_err(&write_err)?;
+        for symbol in &symbols {
+            insert_symbol.reset().map_err(&write_err)?;
+            insert_symbol.bind((1, revision_id)).map_err(&write_err)?;
+            insert_symbol.bind((2, symbol.entity_key.as_str())).map_err(&write_err)?;
+            insert_symbol.bind((3, symbol.name.as_str())).map_err(&write_err)?;
+            insert_symbol.bind((4, symbol.kind.as_str())).map_err(&write_err)?;
+            insert_symbol.bind((5, symbol.file_path.as_str())).map_err(&write_err)?;
+            insert_symbol.bind((6, symbol.signature.as_str())).map_err(&write_err)?;
+            insert_symbol.bind((7, symbol.body_hash.as_str())).map_err(&write_err)?;
+            insert_symbol.next().map_err(&write_err)?;
+        }
+
+        let mut insert_call = self
+            .connection
+            .prepare("INSERT INTO revision_calls (revision_id, caller_key, callee_key) VALUES (?, ?, ?)")
+            .map_err(&write_err)?;
+        for (caller, callee) in &calls {
+            insert_call.reset().map_err(&write_err)?;
+            insert_call.bind((1, revision_id)).map_err(&write_err)?;
+            insert_call.bind((2, *caller)).map_err(&write_err)?;
+            insert_call.bind((3, *callee)).map_err(&write_err)?;
+            insert_call.next().map_err(&write_err)?;
+        }
+
+        Ok(RevisionSnapshotWriteCounts { symbols: symbols.len(), calls: calls.len(), snapshot_bytes: snapshot.len() })
+    }
+
+    /// Whether a commit already has a snapshot
+    ///
+    /// # 4-Word Name: is_revision_already_recorded
+    pub fn is_revision_already_recorded(&self, commit_sha: &str) -> Result<bool> {
+        let read_err = sqlite_error_for_operation("sqlite_find_revision");
+        let mut statement = self
+            .connection
+            .prepare("SELECT 1 FROM revisions WHERE commit_sha = ?")
+            .map_err(&read_err)?;
+        statement.bind((1, commit_sha)).map_err(&read_err)?;
+        Ok(statement.next().map_err(&read_err)? == sqlite::State::Row)
+    }
+
+    /// Recorded revisions, oldest commit first
+    ///
+    /// # 4-Word Name: list_recorded_revisions_chronologically
+    pub fn list_recorded_revisions_chronologically(&self) -> Result<Vec<RecordedRevisionSummaryEntry>> {
+        Ok(self.load_revision_rows_chronologically()?.into_iter().map(|(_, summary)| summary).collect())
+    }
+
+    /// (revision id, summary), oldest commit first
+    fn load_revision_rows_chronologically(&self) -> Result<Vec<(i64, RecordedRevisionSummaryEntry)>> {
+        let read_err = sqlite_error_for_operation("sqlite_list_revisions");
+        let mut statement = self
+            .connection
+            .prepare(
+                "SELECT id, commit_sha, tags, committed_at, subject, symbol_count, call_count
+                 FROM revisions ORDER BY committed_at, id",
+            )
+            .map_err(&read_err)?;
+
+        let mut revisions = Vec::new();
+        while statement.next().map_err(&read_err)? == sqlite::State::Row {
+            let tags = statement.read::<String, _>(2).map_err(&read_err)?;
+            let summary = RecordedRevisionSummaryEntry {
+                commit: RevisionCommitDetailsEntry {
+                    commit_sha: statement.read::<String, _>(1).map_err(&read_err)?,
+                    tags: tags.split_whitespace().map(str::to_string).collect(),
+                    committed_at: statement.read::<String, _>(3).map_err(&read_err)?,
+                    subject: statement.read::<String, _>(4).map_err(&read_err)?,
+                },
+                symbol_count: statement.read::<i64, _>(5).map_err(&read_err)? as usize,
+                call_count: statement.read::<i64, _>(6).map_err(&read_err)? as usize,
+            };
+            revisions.push((statement.read::<i64, _>(0).map_err(&read_err)?, summary));
+        }
+        Ok(revisions)
+    }
+
+    /// Every recorded revision with the states of the symbols named `symbol`
+    ///
+    /// # 4-Word Name: load_symbol_revision_states
+    ///
+    /// # Contract
+    /// - `symbol` matches a symbol name or a full entity key
+    /// - Postcondition: oldest revision first; a revision where no symbol
+    ///   matched has an empty list (the symbol did not exist there)
+    pub fn load_symbol_revision_states(
+        &self,
+        symbol: &str,
+    ) -> Result<Vec<(RecordedRevisionSummaryEntry, Vec<SymbolRevisionStateEntry>)>> {
+        let read_err = sqlite_error_for_operation("sqlite_load_symbol_states");
+        let mut states: BTreeMap<(i64, String), SymbolRevisionStateEntry> = BTreeMap::new();
+
+        let mut select_symbols = self
+            .connection
+            .prepare(
+                "SELECT revision_id, key, name, kind, file_path, signature, body_hash
+                 FROM revision_symbols WHERE name = ?1 OR key = ?1",
+            )
+            .map_err(&read_err)?;
+        select_symbols.bind((1, symbol)).map_err(&read_err)?;
+        while select_symbols.next().map_err(&read_err)? == sqlite::State::Row {
+            let revision_id = select_symbols.read::<i64, _>(0).map_err(&read_err)?;
+            let entity_key = select_symbols.read::<String, _>(1).map_err(&read_err)?;
+            let state = SymbolRevisionStateEntry {
+                entity_key: entity_key.clone(),
+                name: select_symbols.read::<String, _>(2).map_err(&read_err)?,
+                kind: select_symbols.read::<String, _>(3).map_err(&read_err)?,
+                file_path: select_symbols.read::<String, _>(4).map_err(&read_err)?,
+                signature: select_symbols.read::<String, _>(5).map_err(&read_err)?,
+                body_hash: select_symbols.read::<String, _>(6).map_err(&read_err)?,
+                callers: Vec::new(),
+            };
+            states.insert((revision_id, entity_key), state);
+        }
+
+        let mut select_callers = self
+            .connection
+            .prepare(
+                "SELECT c.revision_id, c.callee_key, c.caller_key FROM revision_calls c
+                 JOIN revision_symbols s ON s.revision_id = c.revision_id AND s.key = c.callee_key
+                 WHERE s.name = ?1 OR s.key = ?1 ORDER BY c.caller_key",
+            )
+            .map_err(&read_err)?;
+        select_callers.bind((1, symbol)).map_err(&read_err)?;
+        while select_callers.next().map_err(&read_err)? == sqlite::State::Row {
+            let revision_id = select_callers.read::<i64, _>(0).map_err(&read_err)?;
+            let callee = select_callers.read::<String, _>(1).map_err(&read_err)?;
+            if let Some(state) = states.get_mut(&(revision_id, callee)) {
+                state.callers.push(select_callers.read::<String, _>(2).map_err(&read_err)?);
+            }
+        }
+
+        let mut by_revision: BTreeMap<i64, Vec<SymbolRevisionStateEntry>> = BTreeMap::new();
+        for ((revision_id, _), state) in states {
+            by_revision.entry(revision_id).or_default().push(state);
+        }
+        Ok(self
+            .load_revision_rows_chronologically()?
+            .into_iter()
+            .map(|(revision_id, summary)| (summary, by_revision.remove(&revision_id).unwrap_or_default()))
+            .collect())
+    }
+
+    /// Write the stored snapshot of a commit to `path` (zstd JSON)
+    ///
+    /// # 4-Word Name: write_revision_snapshot_file
+    ///
+    /// # Contract
+    /// - Postcondition: true and `path` loadable as `snapshot:<path>`; false
+    ///   (nothing written) when the commit was never recorded
+    pub fn write_revision_snapshot_file(&self, commit_sha: &str, path: &Path) -> Result<bool> {
+        let read_err = sqlite_error_for_operation("sqlite_load_snapshot");
+        let mut statement = self
+            .connection
+            .prepare("SELECT snapshot FROM revisions WHERE commit_sha = ?")
+            .map_err(&read_err)?;
+        statement.bind((1, commit_sha)).map_err(&read_err)?;
+        if statement.next().map_err(&read_err)? != sqlite::State::Row {
+            return Ok(false);
+        }
+        let snapshot = statement.read::<Vec<u8>, _>(0).map_err(&read_err)?;
+        std::fs::write(path, snapshot).map_err(|source| ParseltongError::FileSystemError {
+            path: path.display().to_string(),
+            source,
+        })?;
+        Ok(true)
+    }
+}
+
+/// Timeline-relevant fields of one entity
+fn symbol_state_from_entity(entity: &CodeEntity) -> SymbolRevisionStateEntry {
+    let body = entity.current_code.as_deref().unwrap_or("");
+    let signature = match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+        Some(signature) => signature.clone(),
+        None => extract_symbol_signature_line(body),
+    };
+    let body_hash: String = if body.is_empty() {
+        String::new()
+    } else {
+        Sha256::digest(body.as_bytes()).iter().take(8).map(|byte| format!("{:02x}", byte)).collect()
+    };
+    SymbolRevisionStateEntry {
+        entity_key: entity.isgl1_key.clone(),
+        name: entity.interface_signature.name.clone(),
+        kind: entity.isgl1_key.split(':').nth(1).unwrap_or_default().to_string(),
+        file_path: entity.interface_signature.file_path.to_string_lossy().to_string(),
+        signature,
+        body_hash,
+        callers: Vec::new(),
+    }
+}
+
+/// The `export --format json` document of a graph, zstd-compressed in memory
+fn compress_graph_json_snapshot(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+    capabilities: Option<&ExtractorCapabilityManifestRecord>,
+) -> Result<Vec<u8>> {
+    let snapshot_error = |e: std::io::Error| ParseltongError::SerializationError {
+        details: format!("revision snapshot: {}", e),
+    };
+    let mut encoder = zstd::stream::write::Encoder::new(Vec::new(), ZSTD_COMPRESSION_LEVEL).map_err(snapshot_error)?;
+    write_graph_as_json_snapshot(&mut encoder, entities, edges, capabilities).map_err(snapshot_error)?;
+    encoder.flush().map_err(snapshot_error)?;
+    encoder.finish().map_err(snapshot_error)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EntityType;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn commit(sha: &str, committed_at: &str) -> RevisionCommitDetailsEntry {
+        RevisionCommitDetailsEntry {
+            commit_sha: sha.to_string(),
+            tags: vec!["v1.0.0".to_string()],
+            committed_at: committed_at.to_string(),
+            subject: "Add store".to_string(),
+        }
+    }
+
+    fn call(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_revisions_record_states_and_snapshots() {
+        let store = RevisionHistorySqliteStore::open_with_history_schema(":memory:").unwrap();
+        let save = "go:method:Save:__svc:T1";
+        let main = "go:fn:main:__cmd:T2";
+        let signature = [(SIGNATURE_METADATA_KEY, "func Save() error")];
+        let entities = vec![
+            create_go_test_entity(save, "Save", EntityType::Method, "svc/store.go", &signature),
+            create_go_test_entity(main, "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let edges = vec![call(main, save), call(main, "go:fn:Println:unresolved-reference:0-0")];
+
+        // Recorded out of order; listed by commit date
+        let counts = store
+            .record_revision_snapshot_atomically(&commit("bbb", "2026-02-01T00:00:00Z"), &entities, &edges, None)
+            .unwrap();
+        assert_eq!((counts.symbols, counts.calls), (2, 1));
+        // A second recording of the same commit replaces the first
+        for _ in 0..2 {
+            let first = commit("aaa", "2026-01-01T00:00:00Z");
+            store.record_revision_snapshot_atomically(&first, &entities[..1], &[], None).unwrap();
+        }
+
+        let revisions = store.list_recorded_revisions_chronologically().unwrap();
+        let shas: Vec<&str> = revisions.iter().map(|r| r.commit.commit_sha.as_str()).collect();
+        assert_eq!(shas, vec!["aaa", "bbb"]);
+        assert_eq!(revisions[0].commit.tags, vec!["v1.0.0"]);
+        assert!(store.is_revision_already_recorded("bbb").unwrap());
+
+        let states = store.load_symbol_revision_states("Save").unwrap();
+        assert_eq!(states.len(), 2);
+        assert!(states[0].1[0].callers.is_empty());
+        assert_eq!(states[1].1[0].callers, vec![main]);
+        assert_eq!(states[1].1[0].signature, "func Save() error");
+
+        let file = tempfile::NamedTempFile::new().unwrap();
+        assert!(store.write_revision_snapshot_file("bbb", file.path()).unwrap());
+        assert!(!store.write_revision_snapshot_file("ccc", file.path()).unwrap());
+        let reader = crate::serializers::json_snapshot::open_decompressed_snapshot_reader(file.path()).unwrap();
+        let (loaded, loaded_edges) = crate::serializers::json_snapshot::read_graph_json_snapshot(reader).unwrap();
+        assert_eq!((loaded.len(), loaded_edges.len()), (2, 2));
+    }
+
+    #[test]
+    fn test_rerecording_a_commit_replaces_all_of_its_rows() {
+        let file = tempfile::NamedTempFile::new().unwrap();
+        let store = RevisionHistorySqliteStore::open_with_history_schema(file.path()).unwrap();
+        let save = "go:method:Save:__svc:T1";
+        let main = "go:fn:main:__cmd:T2";
+        let entities = vec![
+            create_go_test_entity(save, "Save", EntityType::Method, "svc/store.go", &[]),
+            create_go_test_entity(main, "main", EntityType::Function, "cmd/main.go", &[]),
+        ];
+        let original = commit("aaa", "2026-01-01T00:00:00Z");
+        let first = store.record_revision_snapshot_atomically(&original, &entities, &[call(main, save)], None).unwrap();
+        assert_eq!((first.symbols, first.calls), (2, 1));
+
+        // Amended details and a smaller graph for the same sha
+        let mut amended = commit("aaa", "2026-03-01T00:00:00Z");
+        amended.tags = vec!["v1.0.1".to_string(), "stable".to_string()];
+        amended.subject = "Amend store".to_string();
+        let second = store.record_revision_snapshot_atomically(&amended, &entities[..1], &[], None).unwrap();
+        assert_eq!((second.symbols, second.calls), (1, 0));
+        drop(store);
+
+        // Reopening keeps the recording; old symbol and call rows are gone, not merged
+        let store = RevisionHistorySqliteStore::open_with_history_schema(file.path()).unwrap();
+        let revisions = store.list_recorded_revisions_chronologically().unwrap();
+        assert_eq!(revisions, vec![RecordedRevisionSummaryEntry { commit: amended, symbol_count: 1, call_count: 0 }]);
+        let main_states = store.load_symbol_revision_states("main").unwrap();
+        assert_eq!(main_states.len(), 1);
+        assert!(main_states[0].1.is_empty());
+        let save_states = store.load_symbol_revision_states("Save").unwrap();
+        assert_eq!(save_states[0].1.len(), 1);
+        assert!(save_states[0].1[0].callers.is_empty());
+        assert!(!store.is_revision_already_recorded("bbb").unwrap());
+
+        let snapshot = tempfile::NamedTempFile::new().unwrap();
+        assert!(store.write_revision_snapshot_file("aaa", snapshot.path()).unwrap());
+        let reader = crate::serializers::json_snapshot::open_decompressed_snapshot_reader(snapshot.path()).unwrap();
+        let (loaded, loaded_edges) = crate::serializers::json_snapshot::read_graph_json_snapshot(reader).unwrap();
+        assert_eq!((loaded.len(), loaded_edges.len()), (1, 0));
+    }
+
+    #[test]
+    fn test_symbol_states_follow_signatures_and_callers() {
+        use crate::symbol_history_timeline_reporter::{build_symbol_history_timeline, SymbolHistoryEventKind};
+
+        let store = RevisionHistorySqliteStore::open_with_history_schema(":memory:").unwrap();
+        let save = "go:method:Save:__svc:T1";
+        let cache_save = "go:fn:Save:__cache:T3";
+        let main = "go:fn:main:__cmd:T2";
+        let register = "go:fn:Register:__api:T4";
+        let placeholder = "go:fn:Save:unresolved-reference:0-0";
+        let save_as = |signature: &str, body: &str| {
+            let metadata = [(SIGNATURE_METADATA_KEY, signature)];
+            let mut entity = create_go_test_entity(save, "Save", EntityType::Method, "svc/store.go", &metadata);
+            entity.current_code = Some(body.to_string());
+            entity
+        };
+        let function =
+            |key: &str, name: &str, file: &str| create_go_test_entity(key, name, EntityType::Function, file, &[]);
+        let old_signature = "func Save() error";
+        let new_signature = "func Save(ctx context.Context) error";
+        let uses = DependencyEdge::builder().from_key(main).to_key(save).edge_type(EdgeType::Uses).build().unwrap();
+
+        let revisions = [
+            (
+                commit("r1", "2026-01-01T00:00:00Z"),
+                vec![
+                    save_as(old_signature, "v1"),
+                    function(main, "main", "cmd/main.go"),
+                    function(placeholder, "Save", "svc/store.go"),
+                ],
+                vec![call(main, save), call(main, placeholder)],
+            ),
+            (
+                commit("r2", "2026-01-02T00:00:00Z"),
+                vec![
+                    save_as(new_signature, "v2"),
+                    function(main, "main", "cmd/main.go"),
+                    function(register, "Register", "api/routes.go"),
+                    function(cache_save, "Save", "cache/save.go"),
+                ],
+                vec![call(main, save), call(register, save), call(register, cache_save), uses],
+            ),
+            (
+                commit("r3", "2026-01-03T00:00:00Z"),
+                vec![save_as(new_signature, "v3"), function(register, "Register", "api/routes.go")],
+                vec![call(register, save)],
+            ),
+        ];
+        let mut counts = Vec::new();
+        for (details, entities, edges) in &revisions {
+            let written = store.record_revision_snapshot_atomically(details, entities, edges, None).unwrap();
+            counts.push((written.symbols, written.calls));
+        }
+        assert_eq!(counts, vec![(2, 1), (4, 3), (2, 1)], "placeholders and non-Calls edges are not stored");
+
+        // By name: both Save symbols, sorted by key; callers sorted too
+        let by_name = store.load_symbol_revision_states("Save").unwrap();
+        let keys: Vec<Vec<&str>> =
+            by_name.iter().map(|(_, states)| states.iter().map(|s| s.entity_key.as_str()).collect()).collect();
+        assert_eq!(keys, vec![vec![save], vec![cache_save, save], vec![save]]);
+        assert_eq!(by_name[1].1[1].callers, vec![register, main]);
+        assert_eq!(by_name[1].1[0].callers, vec![register]);
+        assert_eq!(by_name[0].1[0].signature, old_signature);
+        assert_eq!(by_name[0].1[0].body_hash.len(), 16);
+
+        // By key: one symbol per revision
+        let by_key = store.load_symbol_revision_states(save).unwrap();
+        assert!(by_key.iter().all(|(_, states)| states.len() == 1));
+        let hashes: BTreeSet<&str> = by_key.iter().map(|(_, states)| states[0].body_hash.as_str()).collect();
+        assert_eq!(hashes.len(), 3);
+        let unknown = store.load_symbol_revision_states("Nope").unwrap();
+        assert_eq!(unknown.len(), 3, "every revision is listed");
+        assert!(unknown.iter().all(|(_, states)| states.is_empty()));
+
+        let report = build_symbol_history_timeline(save, &by_key);
+        let events: Vec<(&str, SymbolHistoryEventKind, Vec<String>)> = report
+            .events
+            .iter()
+            .map(|event| (event.commit_sha.as_str(), event.kind, event.callers.clone()))
+            .collect();
+        assert_eq!(
+            events,
+            vec![
+                ("r1", SymbolHistoryEventKind::Appeared, vec![main.to_string()]),
+                ("r2", SymbolHistoryEventKind::SignatureChanged, Vec::new()),
+                ("r2", SymbolHistoryEventKind::CallersGained, vec![register.to_string()]),
+                ("r3", SymbolHistoryEventKind::BodyChanged, Vec::new()),
+                ("r3", SymbolHistoryEventKind::CallersLost, vec![main.to_string()]),
+            ]
+        );
+        assert_eq!(report.events[1].before_signature.as_deref(), Some(old_signature));
+        assert_eq!(report.events[1].after_signature.as_deref(), Some(new_signature));
+    }
+}
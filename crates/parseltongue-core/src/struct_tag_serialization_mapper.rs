@@ -0,0 +1,764 @@
+//! Struct Tag Serialization Mapper (v1.7.3)
+//!
+//! # 4-Word Naming: struct_tag_serialization_mapper
+//!
+//! "Which Go struct produces the `created_at` JSON field?" The answer sits
+//! in struct tags and serde attributes, not in code the other passes see:
+//!
+//! ```text
+//! type User struct {
+//!     CreatedAt time.Time `json:"created_at" db:"created_at"`
+//! }
+//!
+//! #[derive(Serialize)]
+//! #[serde(rename_all = "camelCase")]
+//! struct Session { user_id: u64, #[serde(rename = "ttl")] ttl_seconds: u32 }
+//! ```
+//!
+//! Each field's serialized name becomes a `Serializes` edge from the struct
+//! to a virtual node shared by every type using that name in that format:
+//! `wire:field:{name}:__{format}:0-0` (`wire:field:created_at:__json:0-0`).
+//!
+//! ## Sources
+//!
+//! - Go tags: `json`, `yaml`, `xml`, `toml`, `bson`, `msgpack`,
+//!   `mapstructure`, `form`, `query` and `db` (sqlx); `gorm:"column:x"` is
+//!   format `db`, `protobuf:"...,name=x"` is format `protobuf`. An empty
+//!   name (`json:",omitempty"`) is the field name (lower case for `yaml`,
+//!   `bson` and `db`, as their encoders do); `-` and `,inline` fields are
+//!   skipped. Untagged fields are not mapped
+//! - Rust `#[derive(Serialize)]` / `#[derive(Deserialize)]` structs: every
+//!   named field, format `serde`, after `rename_all` and `rename`
+//!   (`rename(serialize = "x")` wins); `skip`, `skip_serializing` and
+//!   `flatten` fields are skipped. `#[derive(FromRow)]` does the same for
+//!   `#[sqlx(...)]` with format `db`
+//!
+//! Edge metadata: `wire_name` (as written), `wire_format`, `struct_field`
+//! (the declared field), `wire_options` (`omitempty`, `string`, ...) and,
+//! for Go, `field_key`: the `go:field:` node the field access edges use, so
+//! serialized names join the readers and writers of the field.
+
+use std::collections::HashSet;
+use std::path::Path;
+
+use serde::Serialize;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::go_channel_operation_extractor::format_package_scope_segment;
+use crate::go_type_reference_extractor::find_go_spec_entity_key;
+use crate::isgl1_v2::sanitize_entity_name_for_isgl1;
+use crate::query_extractor::{EntityType, ParsedEntity};
+use crate::rust_macro_symbol_attributor::parse_derive_trait_paths;
+use crate::rust_trait_impl_bound_extractor::find_rust_item_entity_key;
+
+/// Edge metadata key: the serialized name as written (`created_at`)
+pub const WIRE_NAME_METADATA_KEY: &str = "wire_name";
+
+/// Edge metadata key: `json`, `yaml`, `db`, `serde`, ... (see module docs)
+pub const WIRE_FORMAT_METADATA_KEY: &str = "wire_format";
+
+/// Edge metadata key: the declared field name (`CreatedAt`)
+pub const STRUCT_FIELD_METADATA_KEY: &str = "struct_field";
+
+/// Edge metadata key: tag options after the name, comma-separated
+pub const WIRE_OPTIONS_METADATA_KEY: &str = "wire_options";
+
+/// Edge metadata key: `go:field:` node of the tagged Go field
+pub const FIELD_KEY_METADATA_KEY: &str = "field_key";
+
+/// Go tag keys whose value starts with the serialized name
+const GO_NAME_TAG_KEYS: &[&str] = &["json", "yaml", "xml", "toml", "bson", "msgpack", "mapstructure", "form", "query", "db"];
+
+/// Formats serde names cover: a `serde` edge answers for each of them
+const SERDE_COVERED_FORMATS: &[&str] = &["json", "yaml", "toml", "xml", "bson", "msgpack", "form", "query", "mapstructure"];
+
+/// One serialized name of one struct field
+///
+/// # 4-Word Name: SerializedWireFieldName
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SerializedWireFieldName {
+    pub format: String,
+    pub name: String,
+    /// Options after the name, in tag order
+    pub options: Vec<String>,
+}
+
+/// One struct producing a serialized field name
+///
+/// # 4-Word Name: WireFieldProducerEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct WireFieldProducerEntry {
+    pub struct_key: String,
+    pub struct_field: String,
+    pub wire_name: String,
+    pub wire_format: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub options: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub location: Option<String>,
+}
+
+/// Serialized names a Go struct tag gives the field `field_name`
+///
+/// # 4-Word Name: parse_go_struct_tag_names
+///
+/// # Contract
+/// - Precondition: `tag` is the tag text without its quotes
+/// - Postcondition: tag order, first occurrence of each key; malformed
+///   tags yield the pairs before the error, as `reflect.StructTag` does
+pub fn parse_go_struct_tag_names(tag: &str, field_name: &str) -> Vec<SerializedWireFieldName> {
+    let mut names = Vec::new();
+    let mut seen = HashSet::new();
+    for (key, value) in parse_go_struct_tag_pairs(tag) {
+        if !seen.insert(key.clone()) {
+            continue;
+        }
+        let entry = match key.as_str() {
+            "gorm" => value
+                .split(';')
+                .find_map(|setting| setting.trim().strip_prefix("column:"))
+                .map(|column| ("db".to_string(), column.trim().to_string(), Vec::new())),
+            "protobuf" => {
+                let mut parts = value.split(',');
+                let wire_type = parts.next().unwrap_or_default().to_string();
+                parts
+                    .find_map(|part| part.strip_prefix("name="))
+                    .map(|name| ("protobuf".to_string(), name.to_string(), vec![wire_type]))
+            }
+            key if GO_NAME_TAG_KEYS.contains(&key) => {
+                let mut parts = value.split(',');
+                let name = parts.next().unwrap_or_default();
+                let options: Vec<String> = parts.filter(|o| !o.is_empty()).map(str::to_string).collect();
+                if (name == "-" && options.is_empty()) || options.iter().any(|o| o == "inline") {
+                    None
+                } else if name.is_empty() {
+                    let default = match key {
+                        "yaml" | "bson" | "db" => field_name.to_lowercase(),
+                        _ => field_name.to_string(),
+                    };
+                    Some((key.to_string(), default, options))
+                } else {
+                    // xml:"a>b" nests the element; the field is `b`
+                    let name = name.rsplit('>').next().unwrap_or(name);
+                    Some((key.to_string(), name.to_string(), options))
+                }
+            }
+            _ => None,
+        };
+        if let Some((format, name, options)) = entry.filter(|(_, name, _)| !name.is_empty()) {
+            names.push(SerializedWireFieldName { format, name, options });
+        }
+    }
+    names
+}
+
+/// `Serializes` edges for the tagged struct fields of one Go file
+///
+/// # 4-Word Name: extract_go_struct_tag_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: one edge per (struct, format, name), at the first field
+///   using it; structs without an extracted entity produce no edges
+pub fn extract_go_struct_tag_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let package_scope = format_package_scope_segment(
+        &file_path.parent().map(|p| p.to_string_lossy().to_string()).unwrap_or_default(),
+    );
+
+    let mut edges = Vec::new();
+    let mut cursor = root.walk();
+    for declaration in root.named_children(&mut cursor) {
+        if declaration.kind() != "type_declaration" {
+            continue;
+        }
+        let mut spec_cursor = declaration.walk();
+        for spec in declaration.named_children(&mut spec_cursor) {
+            let Some(fields) = spec
+                .child_by_field_name("type")
+                .filter(|t| t.kind() == "struct_type")
+                .and_then(|t| t.named_children(&mut t.walk()).find(|c| c.kind() == "field_declaration_list"))
+            else {
+                continue;
+            };
+            let Some(from_key) = find_go_spec_entity_key(spec, source, entities) else { continue };
+            let type_name = spec
+                .child_by_field_name("name")
+                .map(|n| &source[n.byte_range()])
+                .unwrap_or_default();
+
+            let mut seen = HashSet::new();
+            let mut field_cursor = fields.walk();
+            for field in fields.named_children(&mut field_cursor) {
+                let Some(tag) = field.child_by_field_name("tag").and_then(|t| go_tag_literal_text(&source[t.byte_range()]))
+                else {
+                    continue;
+                };
+                let mut name_cursor = field.walk();
+                let mut field_names: Vec<String> = field
+                    .children_by_field_name("name", &mut name_cursor)
+                    .map(|n| source[n.byte_range()].to_string())
+                    .collect();
+                if field_names.is_empty() {
+                    // Embedded `*models.Base`: the field is named after the type
+                    let embedded = field.child_by_field_name("type").map(|t| &source[t.byte_range()]).unwrap_or_default();
+                    let embedded = embedded.trim_start_matches('*');
+                    let embedded = embedded.split('[').next().unwrap_or(embedded);
+                    field_names.push(embedded.rsplit('.').next().unwrap_or(embedded).to_string());
+                }
+                let line = field.start_position().row + 1;
+                for field_name in field_names {
+                    for wire in parse_go_struct_tag_names(&tag, &field_name) {
+                        if !seen.insert((wire.format.clone(), wire.name.clone())) {
+                            continue;
+                        }
+                        let field_key = format!("go:field:{}.{}:{}:0-0", type_name, field_name, package_scope);
+                        if let Some(edge) = build_serializes_edge(&from_key, &field_name, &wire, &path_text, line, Some(field_key)) {
+                            edges.push(edge);
+                        }
+                    }
+                }
+            }
+        }
+    }
+    edges
+}
+
+/// `Serializes` edges for serde and sqlx structs of one Rust file
+///
+/// # 4-Word Name: extract_rust_serde_field_edges
+///
+/// # Contract
+/// - Precondition: `entities` come from the same parse of `file_path`
+/// - Postcondition: one edge per (struct, format, name) for named fields of
+///   structs deriving `Serialize`/`Deserialize` (format `serde`) or
+///   `FromRow` (format `db`); tuple structs and enums produce no edges
+pub fn extract_rust_serde_field_edges(
+    root: tree_sitter::Node<'_>,
+    source: &str,
+    file_path: &Path,
+    entities: &[ParsedEntity],
+) -> Vec<DependencyEdge> {
+    let path_text = file_path.to_string_lossy().to_string();
+    let mut edges = Vec::new();
+    let mut stack = vec![root];
+    while let Some(node) = stack.pop() {
+        let mut cursor = node.walk();
+        stack.extend(node.named_children(&mut cursor));
+        if node.kind() != "struct_item" {
+            continue;
+        }
+        let Some(body) = node.child_by_field_name("body").filter(|b| b.kind() == "field_declaration_list") else {
+            continue;
+        };
+        let attributes = preceding_rust_attribute_texts(node, source);
+        let derives: Vec<String> = attributes
+            .iter()
+            .flat_map(|a| parse_derive_trait_paths(a))
+            .map(|path| path.rsplit("::").next().unwrap_or(&path).to_string())
+            .collect();
+        let mut formats: Vec<(&str, &str)> = Vec::new();
+        if derives.iter().any(|d| d == "Serialize" || d == "Deserialize") {
+            formats.push(("serde", "serde"));
+        }
+        if derives.iter().any(|d| d == "FromRow") {
+            formats.push(("sqlx", "db"));
+        }
+        if formats.is_empty() {
+            continue;
+        }
+        let Some(from_key) = find_rust_item_entity_key(node, &EntityType::Struct, source, entities) else {
+            continue;
+        };
+
+        let mut seen = HashSet::new();
+        for (attribute_path, format) in formats {
+            let container: Vec<(String, String)> =
+                attributes.iter().flat_map(|a| rust_attribute_meta_items(a, attribute_path)).collect();
+            if container.iter().any(|(key, _)| key == "transparent") {
+                continue;
+            }
+            let rename_all = container
+                .iter()
+                .find(|(key, _)| key == "rename_all")
+                .map(|(_, value)| serialize_side_of_meta_value(value));
+
+            let mut field_cursor = body.walk();
+            for field in body.named_children(&mut field_cursor) {
+                if field.kind() != "field_declaration" {
+                    continue;
+                }
+                let Some(declared) = field.child_by_field_name("name").map(|n| &source[n.byte_range()]) else {
+                    continue;
+                };
+                let declared = declared.strip_prefix("r#").unwrap_or(declared);
+                let items: Vec<(String, String)> = preceding_rust_attribute_texts(field, source)
+                    .iter()
+                    .flat_map(|a| rust_attribute_meta_items(a, attribute_path))
+                    .collect();
+                if items.iter().any(|(key, _)| matches!(key.as_str(), "skip" | "skip_serializing" | "flatten")) {
+                    continue;
+                }
+                let name = match items.iter().find(|(key, _)| key == "rename") {
+                    Some((_, value)) => serialize_side_of_meta_value(value),
+                    None => match &rename_all {
+                        Some(rule) => apply_rust_rename_all_rule(declared, rule),
+                        None => declared.to_string(),
+                    },
+                };
+                let wire = SerializedWireFieldName { format: format.to_string(), name, options: Vec::new() };
+                if wire.name.is_empty() || !seen.insert((wire.format.clone(), wire.name.clone())) {
+                    continue;
+                }
+                let line = field.start_position().row + 1;
+                if let Some(edge) = build_serializes_edge(&from_key, declared, &wire, &path_text, line, None) {
+                    edges.push(edge);
+                }
+            }
+        }
+    }
+    edges
+}
+
+/// Structs producing `wire_name`, optionally in one format
+///
+/// # 4-Word Name: find_wire_field_producers
+///
+/// # Contract
+/// - Postcondition: exact name match; a `serde` edge matches every format
+///   serde covers (`json`, `yaml`, `toml`, ...) but not `db` or `protobuf`;
+///   sorted by (format, struct key, field)
+pub fn find_wire_field_producers(
+    edges: &[DependencyEdge],
+    wire_name: &str,
+    format: Option<&str>,
+) -> Vec<WireFieldProducerEntry> {
+    let mut producers: Vec<WireFieldProducerEntry> = edges
+        .iter()
+        .filter(|edge| edge.edge_type == EdgeType::Serializes)
+        .filter(|edge| edge.metadata.get(WIRE_NAME_METADATA_KEY).is_some_and(|name| name == wire_name))
+        .filter_map(|edge| {
+            let edge_format = edge.metadata.get(WIRE_FORMAT_METADATA_KEY)?;
+            let wanted = format.map_or(true, |wanted| {
+                let wanted = wanted.to_lowercase();
+                *edge_format == wanted || (edge_format == "serde" && SERDE_COVERED_FORMATS.contains(&wanted.as_str()))
+            });
+            wanted.then(|| WireFieldProducerEntry {
+                struct_key: edge.from_key.as_str().to_string(),
+                struct_field: edge.metadata.get(STRUCT_FIELD_METADATA_KEY).cloned().unwrap_or_default(),
+                wire_name: wire_name.to_string(),
+                wire_format: edge_format.clone(),
+                options: edge.metadata.get(WIRE_OPTIONS_METADATA_KEY).cloned(),
+                location: edge.source_location.clone(),
+            })
+        })
+        .collect();
+    producers.sort_by(|a, b| {
+        (&a.wire_format, &a.struct_key, &a.struct_field).cmp(&(&b.wire_format, &b.struct_key, &b.struct_field))
+    });
+    producers
+}
+
+fn build_serializes_edge(
+    from_key: &str,
+    struct_field: &str,
+    wire: &SerializedWireFieldName,
+    path_text: &str,
+    line: usize,
+    field_key: Option<String>,
+) -> Option<DependencyEdge> {
+    let mut builder = DependencyEdge::builder()
+        .from_key(from_key)
+        .to_key(format!("wire:field:{}:__{}:0-0", sanitize_entity_name_for_isgl1(&wire.name), wire.format))
+        .edge_type(EdgeType::Serializes)
+        .source_location(format!("{}:{}", path_text, line))
+        .metadata_entry(WIRE_NAME_METADATA_KEY, wire.name.clone())
+        .metadata_entry(WIRE_FORMAT_METADATA_KEY, wire.format.clone())
+        .metadata_entry(STRUCT_FIELD_METADATA_KEY, struct_field);
+    if !wire.options.is_empty() {
+        builder = builder.metadata_entry(WIRE_OPTIONS_METADATA_KEY, wire.options.join(","));
+    }
+    if let Some(field_key) = field_key {
+        builder = builder.metadata_entry(FIELD_KEY_METADATA_KEY, field_key);
+    }
+    builder.build().ok()
+}
+
+/// `key:"value"` pairs of a Go struct tag (`reflect.StructTag` syntax)
+fn parse_go_struct_tag_pairs(tag: &str) -> Vec<(String, String)> {
+    let mut pairs = Vec::new();
+    let mut rest = tag;
+    loop {
+        rest = rest.trim_start();
+        let key_end = rest
+            .find(|c: char| c == ':' || c == '"' || c.is_whitespace() || c.is_control())
+            .unwrap_or(rest.len());
+        if key_end == 0 || !rest[key_end..].starts_with(":\"") {
+            break;
+        }
+        let key = &rest[..key_end];
+        let quoted = &rest[key_end + 1..];
+        let mut value = String::new();
+        let mut escaped = false;
+        let mut close = None;
+        for (at, c) in quoted.char_indices().skip(1) {
+            match c {
+                _ if escaped => {
+                    value.push(c);
+                    escaped = false;
+                }
+                '\\' => escaped = true,
+                '"' => {
+                    close = Some(at);
+                    break;
+                }
+                _ => value.push(c),
+            }
+        }
+        let Some(close) = close else { break };
+        pairs.push((key.to_string(), value));
+        rest = &quoted[close + 1..];
+    }
+    pairs
+}
+
+/// Tag text of a Go raw (`` `...` ``) or interpreted (`"..."`) string literal
+fn go_tag_literal_text(literal: &str) -> Option<String> {
+    if let Some(raw) = literal.strip_prefix('`').and_then(|l| l.strip_suffix('`')) {
+        return Some(raw.to_string());
+    }
+    let inner = literal.strip_prefix('"')?.strip_suffix('"')?;
+    let mut text = String::new();
+    let mut chars = inner.chars();
+    while let Some(c) = chars.next() {
+        if c == '\\' {
+            text.extend(chars.next());
+        } else {
+            text.push(c);
+        }
+    }
+    Some(text)
+}
+
+/// Texts of the attributes directly above an item or field (nearest last)
+fn preceding_rust_attribute_texts(node: tree_sitter::Node<'_>, source: &str) -> Vec<String> {
+    let mut texts = Vec::new();
+    let mut sibling = node.prev_named_sibling();
+    while let Some(attribute) = sibling {
+        match attribute.kind() {
+            "attribute_item" => texts.push(source[attribute.byte_range()].to_string()),
+            "line_comment" | "block_comment" => {}
+            _ => break,
+        }
+        sibling = attribute.prev_named_sibling();
+    }
+    texts.reverse();
+    texts
+}
+
+/// `(key, value)` items of every `path(...)` in an attribute, also inside
+/// `cfg_attr`; values are unquoted, or the inner text of `key(...)`
+fn rust_attribute_meta_items(attribute_text: &str, path: &str) -> Vec<(String, String)> {
+    let opener = format!("{}(", path);
+    let mut items = Vec::new();
+    let mut rest = attribute_text;
+    while let Some(start) = rest.find(&opener) {
+        let before = rest[..start].chars().next_back();
+        let after = &rest[start + opener.len()..];
+        if before.is_some_and(|c| c.is_alphanumeric() || c == '_') {
+            rest = after;
+            continue;
+        }
+        let inner = balanced_parenthesis_prefix(after);
+        items.extend(split_rust_meta_list(inner));
+        rest = &after[inner.len()..];
+    }
+    items
+}
+
+/// Text up to the `)` closing an already opened parenthesis
+fn balanced_parenthesis_prefix(text: &str) -> &str {
+    let mut depth = 0usize;
+    let mut quoted = false;
+    let mut escaped = false;
+    for (at, c) in text.char_indices() {
+        match c {
+            _ if escaped => escaped = false,
+            '\\' if quoted => escaped = true,
+            '"' => quoted = !quoted,
+            '(' if !quoted => depth += 1,
+            ')' if !quoted && depth == 0 => return &text[..at],
+            ')' if !quoted => depth -= 1,
+            _ => {}
+        }
+    }
+    text
+}
+
+/// `rename = "x", skip, rename(serialize = "y")` → (key, value) items
+fn split_rust_meta_list(list: &str) -> Vec<(String, String)> {
+    let mut parts = Vec::new();
+    let mut depth = 0usize;
+    let mut quoted = false;
+    let mut escaped = false;
+    let mut start = 0;
+    for (at, c) in list.char_indices() {
+        match c {
+            _ if escaped => escaped = false,
+            '\\' if quoted => escaped = true,
+            '"' => quoted = !quoted,
+            '(' if !quoted => depth += 1,
+            ')' if !quoted => depth = depth.saturating_sub(1),
+            ',' if !quoted && depth == 0 => {
+                parts.push(&list[start..at]);
+                start = at + 1;
+            }
+            _ => {}
+        }
+    }
+    parts.push(&list[start..]);
+
+    parts
+        .into_iter()
+        .map(str::trim)
+        .filter(|part| !part.is_empty())
+        .map(|part| {
+            let paren = part.find('(');
+            match part.find('=') {
+                Some(eq) if paren.map_or(true, |p| eq < p) => {
+                    let value = part[eq + 1..].trim();
+                    let value = value.strip_prefix('"').and_then(|v| v.strip_suffix('"')).unwrap_or(value);
+                    (part[..eq].trim().to_string(), value.to_string())
+                }
+                _ => match paren {
+                    Some(p) => {
+                        let inner = part[p + 1..].strip_suffix(')').unwrap_or(&part[p + 1..]);
+                        (part[..p].trim().to_string(), inner.to_string())
+                    }
+                    None => (part.to_string(), String::new()),
+                },
+            }
+        })
+        .collect()
+}
+
+/// `"x"` as is; `serialize = "x", deserialize = "y"` → `x`
+fn serialize_side_of_meta_value(value: &str) -> String {
+    let items = split_rust_meta_list(value);
+    match items.iter().find(|(key, _)| key == "serialize") {
+        Some((_, side)) => side.clone(),
+        None if items.iter().any(|(key, _)| key == "deserialize") => String::new(),
+        None => value.to_string(),
+    }
+}
+
+/// serde `rename_all` applied to a snake_case field name
+fn apply_rust_rename_all_rule(field: &str, rule: &str) -> String {
+    let words: Vec<&str> = field.split('_').filter(|w| !w.is_empty()).collect();
+    let capitalized = || -> Vec<String> {
+        words
+            .iter()
+            .map(|w| {
+                let mut chars = w.chars();
+                chars.next().map(|f| f.to_uppercase().chain(chars).collect()).unwrap_or_default()
+            })
+            .collect()
+    };
+    match rule {
+        "lowercase" => field.to_lowercase(),
+        "UPPERCASE" | "SCREAMING_SNAKE_CASE" => field.to_uppercase(),
+        "PascalCase" => capitalized().concat(),
+        "camelCase" => {
+            let pascal = capitalized().concat();
+            let mut chars = pascal.chars();
+            chars.next().map(|f| f.to_lowercase().chain(chars).collect()).unwrap_or_default()
+        }
+        "kebab-case" => field.replace('_', "-"),
+        "SCREAMING-KEBAB-CASE" => field.replace('_', "-").to_uppercase(),
+        _ => field.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::Language;
+    use crate::query_extractor::QueryBasedExtractor;
+
+    #[test]
+    fn test_tags_and_serde_names_become_serializes_edges() {
+        let go = r#"package models
+
+type User struct {
+	ID        int64     `json:"id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at,omitempty" gorm:"column:created_at;not null"`
+	Name      string    `yaml:",omitempty"`
+	Secret    string    `json:"-"`
+	*Base     `json:",inline"`
+	untagged  string
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, edges) = extractor.parse_source(go, Path::new("internal/models/user.go"), Language::Go).unwrap();
+        let serialized: Vec<(&str, &str)> = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Serializes)
+            .map(|e| (e.to_key.as_str(), e.metadata[STRUCT_FIELD_METADATA_KEY].as_str()))
+            .collect();
+        assert_eq!(
+            serialized,
+            vec![
+                ("wire:field:id:__json:0-0", "ID"),
+                ("wire:field:user_id:__db:0-0", "ID"),
+                ("wire:field:created_at:__json:0-0", "CreatedAt"),
+                ("wire:field:created_at:__db:0-0", "CreatedAt"),
+                ("wire:field:name:__yaml:0-0", "Name"),
+            ]
+        );
+        let created = edges.iter().find(|e| e.to_key.as_str() == "wire:field:created_at:__json:0-0").unwrap();
+        assert!(created.from_key.as_str().starts_with("go:struct:User:"));
+        assert_eq!(created.metadata[WIRE_OPTIONS_METADATA_KEY], "omitempty");
+        assert_eq!(created.metadata[FIELD_KEY_METADATA_KEY], "go:field:User.CreatedAt:__internal_models:0-0");
+
+        let rust = r#"
+#[derive(Debug, Serialize, Deserialize, sqlx::FromRow)]
+#[serde(rename_all = "camelCase")]
+pub struct Session {
+    pub user_id: u64,
+    /// Seconds until expiry
+    #[serde(rename(serialize = "ttl", deserialize = "expires_in"))]
+    pub ttl_seconds: u32,
+    #[serde(skip)]
+    #[sqlx(rename = "created")]
+    pub created_at: i64,
+}
+"#;
+        let (_, rust_edges) = extractor.parse_source(rust, Path::new("src/session.rs"), Language::Rust).unwrap();
+        let names: Vec<&str> = rust_edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Serializes)
+            .map(|e| e.to_key.as_str())
+            .collect();
+        assert_eq!(
+            names,
+            vec![
+                "wire:field:userId:__serde:0-0",
+                "wire:field:ttl:__serde:0-0",
+                "wire:field:user_id:__db:0-0",
+                "wire:field:ttl_seconds:__db:0-0",
+                "wire:field:created:__db:0-0",
+            ]
+        );
+
+        let mut all = edges;
+        all.extend(rust_edges);
+        let producers = find_wire_field_producers(&all, "created_at", Some("json"));
+        assert_eq!(producers.len(), 1);
+        assert_eq!(producers[0].struct_field, "CreatedAt");
+        assert_eq!(find_wire_field_producers(&all, "userId", Some("JSON"))[0].wire_format, "serde");
+        assert!(find_wire_field_producers(&all, "userId", Some("db")).is_empty());
+        assert_eq!(find_wire_field_producers(&all, "created_at", None).len(), 2);
+    }
+
+    #[test]
+    fn test_go_tag_parsing_edge_cases() {
+        let names = |tag: &str, field: &str| -> Vec<(String, String, String)> {
+            parse_go_struct_tag_names(tag, field)
+                .into_iter()
+                .map(|w| (w.format, w.name, w.options.join(",")))
+                .collect()
+        };
+        let owned = |format: &str, name: &str, options: &str| {
+            (format.to_string(), name.to_string(), options.to_string())
+        };
+
+        // Empty names fall back to the field, lower case only for yaml/bson/db
+        assert_eq!(
+            names(r#"json:",omitempty" yaml:"" bson:"" db:"""#, "UserID"),
+            vec![
+                owned("json", "UserID", "omitempty"),
+                owned("yaml", "userid", ""),
+                owned("bson", "userid", ""),
+                owned("db", "userid", ""),
+            ]
+        );
+        // Options keep tag order; repeated keys keep the first value
+        assert_eq!(
+            names(r#"json:"total,omitempty,string" json:"other""#, "Total"),
+            vec![owned("json", "total", "omitempty,string")]
+        );
+        // `-` skips the format only; unknown keys are ignored
+        assert_eq!(names(r#"json:"-" db:"secret" validate:"required""#, "Secret"), vec![owned("db", "secret", "")]);
+        assert!(names(r#"yaml:",inline""#, "Base").is_empty());
+        // Malformed tags keep the pairs before the error
+        assert_eq!(names(r#"json:"a" broken yaml:"b""#, "A"), vec![owned("json", "a", "")]);
+        assert_eq!(names(r#"json:"a\"b""#, "A"), vec![owned("json", "a\"b", "")]);
+        // xml paths name the last element, protobuf keeps its wire type
+        assert_eq!(names(r#"xml:"items>item""#, "Items"), vec![owned("xml", "item", "")]);
+        assert_eq!(
+            names(r#"protobuf:"bytes,1,opt,name=user_id,proto3""#, "UserId"),
+            vec![owned("protobuf", "user_id", "bytes")]
+        );
+        assert!(names(r#"gorm:"primaryKey;autoIncrement""#, "ID").is_empty());
+    }
+
+    #[test]
+    fn test_embedded_and_shared_go_fields() {
+        let go = r#"package shop
+
+type Order struct {
+	models.Base  `json:"base"`
+	*pkg.Page[T] `yaml:",omitempty"`
+	A, B         string `json:"code"`
+	Total        int64  `json:"total,omitempty,string" db:""`
+	Legacy       string "json:\"legacy\""
+	Ignored      string `json:"-" validate:"required"`
+}
+
+type Invoice struct {
+	Total int64 `json:"total"`
+}
+"#;
+        let mut extractor = QueryBasedExtractor::new().unwrap();
+        let (_, edges) = extractor.parse_source(go, Path::new("internal/shop/order.go"), Language::Go).unwrap();
+        let serialized: Vec<(&str, &str, &str)> = edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Serializes)
+            .map(|e| {
+                let field = e.metadata[STRUCT_FIELD_METADATA_KEY].as_str();
+                (e.to_key.as_str(), field, e.metadata[FIELD_KEY_METADATA_KEY].as_str())
+            })
+            .collect();
+        assert_eq!(
+            serialized,
+            vec![
+                ("wire:field:base:__json:0-0", "Base", "go:field:Order.Base:__internal_shop:0-0"),
+                ("wire:field:page:__yaml:0-0", "Page", "go:field:Order.Page:__internal_shop:0-0"),
+                ("wire:field:code:__json:0-0", "A", "go:field:Order.A:__internal_shop:0-0"),
+                ("wire:field:total:__json:0-0", "Total", "go:field:Order.Total:__internal_shop:0-0"),
+                ("wire:field:total:__db:0-0", "Total", "go:field:Order.Total:__internal_shop:0-0"),
+                ("wire:field:legacy:__json:0-0", "Legacy", "go:field:Order.Legacy:__internal_shop:0-0"),
+                ("wire:field:total:__json:0-0", "Total", "go:field:Invoice.Total:__internal_shop:0-0"),
+            ]
+        );
+        let page = edges.iter().find(|e| e.to_key.as_str() == "wire:field:page:__yaml:0-0").unwrap();
+        assert_eq!(page.metadata[WIRE_OPTIONS_METADATA_KEY], "omitempty");
+        assert_eq!(page.source_location.as_deref(), Some("internal/shop/order.go:5"));
+        let db_total = edges.iter().find(|e| e.to_key.as_str() == "wire:field:total:__db:0-0").unwrap();
+        assert!(!db_total.metadata.contains_key(WIRE_OPTIONS_METADATA_KEY));
+
+        // Both structs share the json node; producers sort by struct key
+        let producers = find_wire_field_producers(&edges, "total", Some("json"));
+        let owners: Vec<&str> = producers.iter().map(|p| p.struct_key.split(':').nth(2).unwrap()).collect();
+        assert_eq!(owners, vec!["Invoice", "Order"]);
+        assert_eq!(producers[1].options.as_deref(), Some("omitempty,string"));
+        assert_eq!(find_wire_field_producers(&edges, "total", Some("db")).len(), 1);
+        assert!(find_wire_field_producers(&edges, "Total", None).is_empty());
+        assert!(find_wire_field_producers(&edges, "total", Some("protobuf")).is_empty());
+    }
+}
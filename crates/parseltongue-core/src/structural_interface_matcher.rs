@@ -0,0 +1,242 @@
+//! Structural Interface Matcher (Go implicit interface satisfaction)
+//!
+//! # 4-Word Naming: structural_interface_matcher
+//!
+//! Go types satisfy interfaces implicitly: `UserService` implements
+//! `UserStore` as soon as its method set covers every method the interface
+//! declares. No syntax links them, so per-file extraction cannot emit the
+//! edge. This pass runs once all entities are parsed and emits
+//! `UserService Implements UserStore` edges so blast-radius queries on an
+//! interface method surface every implementer.
+//!
+//! ## Inputs (entity metadata from query_extractor)
+//!
+//! - Methods: `receiver_type` = bare receiver type name
+//! - Interfaces: `interface_methods` = sorted, comma-separated method names
+//! - Structs/interfaces: `embedded_types` (promoted methods count toward the
+//!   struct's method set, embedded interfaces toward the requirement)
+//!
+//! ## Limitations (syntactic, no go/types)
+//!
+//! - Matches by method NAME only (signatures are not compared)
+//! - Pointer vs value receivers are not distinguished
+//! - Empty interfaces (`any`, `interface{}`) are skipped: every type satisfies them
+
+use std::collections::{BTreeSet, HashSet};
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_embedding_promotion_resolver::{
+    is_go_entity_file_path, package_directory_of_entity, GoTypeMethodSetIndex,
+};
+
+/// Metadata key holding a Go method's receiver type name
+pub const RECEIVER_TYPE_METADATA_KEY: &str = "receiver_type";
+
+/// Metadata key holding a Go interface's method names
+pub const INTERFACE_METHODS_METADATA_KEY: &str = "interface_methods";
+
+/// Compute structural Implements edges for Go entities
+///
+/// # 4-Word Name: compute_go_structural_implements_edges
+///
+/// # Contract
+/// - Precondition: entities carry query_extractor metadata in `metadata.additional`
+/// - Postcondition: one edge per (struct, interface) pair where the struct's
+///   method set (including promoted methods) ⊇ the interface's method set
+///   (including embedded interfaces); sorted by (from_key, to_key)
+/// - Performance: O(S × I × M) for S structs, I interfaces, M methods
+pub fn compute_go_structural_implements_edges(entities: &[CodeEntity]) -> Vec<DependencyEdge> {
+    compute_structural_edges_with_scope(entities, None)
+}
+
+/// Compute structural Implements edges touching a set of entity keys
+///
+/// # 4-Word Name: compute_go_structural_edges_touching
+///
+/// # Contract
+/// - Precondition: `touched_keys` from `collect_go_dirty_subgraph_keys`
+/// - Postcondition: same edges as the full pass, restricted to pairs whose
+///   struct or interface key is in `touched_keys` (v1.7.3 incremental ingest)
+pub fn compute_go_structural_edges_touching(
+    entities: &[CodeEntity],
+    touched_keys: &HashSet<String>,
+) -> Vec<DependencyEdge> {
+    compute_structural_edges_with_scope(entities, Some(touched_keys))
+}
+
+/// Shared implementation: `scope = None` means every pair
+fn compute_structural_edges_with_scope(
+    entities: &[CodeEntity],
+    scope: Option<&HashSet<String>>,
+) -> Vec<DependencyEdge> {
+    let index = GoTypeMethodSetIndex::build_from_code_entities(entities);
+    let go_entities: Vec<&CodeEntity> = entities
+        .iter()
+        .filter(|e| is_go_entity_file_path(&e.interface_signature.file_path))
+        .collect();
+
+    let interfaces: Vec<(&CodeEntity, BTreeSet<String>)> = go_entities
+        .iter()
+        .filter(|e| matches!(e.interface_signature.entity_type, EntityType::Trait | EntityType::Interface))
+        .filter_map(|e| {
+            let location = (package_directory_of_entity(e), e.interface_signature.name.clone());
+            let required = index.effective_method_names_set(&location);
+            // Empty interfaces (any, interface{}) are satisfied by everything: skip
+            if required.is_empty() {
+                None
+            } else {
+                Some((*e, required))
+            }
+        })
+        .collect();
+
+    let mut edges = Vec::new();
+    for entity in &go_entities {
+        if entity.interface_signature.entity_type != EntityType::Struct {
+            continue;
+        }
+        let location = (package_directory_of_entity(entity), entity.interface_signature.name.clone());
+        let struct_methods = index.effective_method_names_set(&location);
+        if struct_methods.is_empty() {
+            continue;
+        }
+
+        let struct_in_scope = match scope {
+            None => true,
+            Some(keys) => keys.contains(&entity.isgl1_key),
+        };
+        for (interface, required) in &interfaces {
+            if !struct_in_scope && !scope.is_some_and(|keys| keys.contains(&interface.isgl1_key)) {
+                continue;
+            }
+            if required.is_subset(&struct_methods) {
+                let source_location = format!(
+                    "{}:{}",
+                    entity.interface_signature.file_path.display(),
+                    entity.interface_signature.line_range.start
+                );
+                if let Ok(edge) = DependencyEdge::builder()
+                    .from_key(entity.isgl1_key.clone())
+                    .to_key(interface.isgl1_key.clone())
+                    .edge_type(EdgeType::Implements)
+                    .source_location(source_location)
+                    .metadata_entry("match_kind", "structural")
+                    .build()
+                {
+                    edges.push(edge);
+                }
+            }
+        }
+    }
+
+    edges.sort_by(|a, b| {
+        (a.from_key.as_str(), a.to_key.as_str()).cmp(&(b.from_key.as_str(), b.to_key.as_str()))
+    });
+    edges
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::{
+        create_go_test_entity as make_go_entity, EMBEDDED_TYPES_METADATA_KEY,
+    };
+
+    fn user_store_fixture() -> Vec<CodeEntity> {
+        vec![
+            make_go_entity("go:trait:UserStore:__store:T1", "UserStore", EntityType::Trait,
+                "store/store.go", &[(INTERFACE_METHODS_METADATA_KEY, "Get,Save")]),
+            make_go_entity("go:struct:UserService:__svc:T2", "UserService", EntityType::Struct,
+                "svc/service.go", &[]),
+            make_go_entity("go:method:Get:__svc:T3", "Get", EntityType::Method,
+                "svc/service.go", &[(RECEIVER_TYPE_METADATA_KEY, "UserService")]),
+            make_go_entity("go:method:Save:__svc:T4", "Save", EntityType::Method,
+                "svc/methods.go", &[(RECEIVER_TYPE_METADATA_KEY, "UserService")]),
+            // Partial implementer: only Get
+            make_go_entity("go:struct:ReadOnly:__svc:T5", "ReadOnly", EntityType::Struct,
+                "svc/readonly.go", &[]),
+            make_go_entity("go:method:Get:__svc_ro:T6", "Get", EntityType::Method,
+                "svc/readonly.go", &[(RECEIVER_TYPE_METADATA_KEY, "ReadOnly")]),
+        ]
+    }
+
+    #[test]
+    fn test_struct_with_full_method_set_implements() {
+        let edges = compute_go_structural_implements_edges(&user_store_fixture());
+
+        assert_eq!(edges.len(), 1, "Only UserService covers Get+Save: {:?}", edges);
+        assert_eq!(edges[0].from_key.as_str(), "go:struct:UserService:__svc:T2");
+        assert_eq!(edges[0].to_key.as_str(), "go:trait:UserStore:__store:T1");
+        assert_eq!(edges[0].edge_type, EdgeType::Implements);
+        assert_eq!(edges[0].metadata_value("match_kind"), Some("structural"));
+    }
+
+    #[test]
+    fn test_methods_in_other_package_do_not_count() {
+        let mut entities = user_store_fixture();
+        // Same receiver name, different package directory
+        entities.push(make_go_entity("go:struct:Cache:__a:T7", "Cache", EntityType::Struct,
+            "a/cache.go", &[]));
+        entities.push(make_go_entity("go:method:Get:__b:T8", "Get", EntityType::Method,
+            "b/cache.go", &[(RECEIVER_TYPE_METADATA_KEY, "Cache")]));
+        entities.push(make_go_entity("go:method:Save:__b:T9", "Save", EntityType::Method,
+            "b/cache.go", &[(RECEIVER_TYPE_METADATA_KEY, "Cache")]));
+
+        let edges = compute_go_structural_implements_edges(&entities);
+        assert!(edges.iter().all(|e| !e.from_key.as_str().contains("Cache")));
+    }
+
+    #[test]
+    fn test_empty_interface_and_non_go_ignored() {
+        let entities = vec![
+            make_go_entity("go:trait:Any:__x:T1", "Any", EntityType::Trait, "x/any.go", &[]),
+            make_go_entity("rust:trait:Store:__lib:T2", "Store", EntityType::Trait,
+                "src/lib.rs", &[(INTERFACE_METHODS_METADATA_KEY, "get")]),
+            make_go_entity("go:struct:S:__x:T3", "S", EntityType::Struct, "x/s.go", &[]),
+            make_go_entity("go:method:get:__x:T4", "get", EntityType::Method,
+                "x/s.go", &[(RECEIVER_TYPE_METADATA_KEY, "S")]),
+        ];
+
+        assert!(compute_go_structural_implements_edges(&entities).is_empty());
+    }
+
+    #[test]
+    fn test_touching_scope_limits_pairs() {
+        let entities = user_store_fixture();
+        let untouched: HashSet<String> = ["go:struct:ReadOnly:__svc:T5".to_string()].into_iter().collect();
+        assert!(compute_go_structural_edges_touching(&entities, &untouched).is_empty());
+
+        let interface_touched: HashSet<String> = ["go:trait:UserStore:__store:T1".to_string()].into_iter().collect();
+        assert_eq!(
+            compute_go_structural_edges_touching(&entities, &interface_touched),
+            compute_go_structural_implements_edges(&entities)
+        );
+    }
+
+    #[test]
+    fn test_promoted_methods_and_embedded_interfaces_count() {
+        let entities = vec![
+            // ReadCloser embeds Reader (Read) and declares Close
+            make_go_entity("go:trait:ReadCloser:__io:T1", "ReadCloser", EntityType::Trait,
+                "io/io.go", &[(INTERFACE_METHODS_METADATA_KEY, "Close"), (EMBEDDED_TYPES_METADATA_KEY, "Reader")]),
+            make_go_entity("go:trait:Reader:__io:T2", "Reader", EntityType::Trait,
+                "io/io.go", &[(INTERFACE_METHODS_METADATA_KEY, "Read")]),
+            // File declares Close, gets Read promoted from embedded buffer
+            make_go_entity("go:struct:File:__fs:T3", "File", EntityType::Struct,
+                "fs/file.go", &[(EMBEDDED_TYPES_METADATA_KEY, "buffer")]),
+            make_go_entity("go:struct:buffer:__fs:T4", "buffer", EntityType::Struct, "fs/buffer.go", &[]),
+            make_go_entity("go:method:Close:__fs:T5", "Close", EntityType::Method,
+                "fs/file.go", &[(RECEIVER_TYPE_METADATA_KEY, "File")]),
+            make_go_entity("go:method:Read:__fs_buffer:T6", "Read", EntityType::Method,
+                "fs/buffer.go", &[(RECEIVER_TYPE_METADATA_KEY, "buffer")]),
+        ];
+
+        let edges = compute_go_structural_implements_edges(&entities);
+        let file_targets: Vec<&str> = edges
+            .iter()
+            .filter(|e| e.from_key.as_str() == "go:struct:File:__fs:T3")
+            .map(|e| e.to_key.as_str())
+            .collect();
+        assert_eq!(file_targets, vec!["go:trait:ReadCloser:__io:T1", "go:trait:Reader:__io:T2"]);
+    }
+}
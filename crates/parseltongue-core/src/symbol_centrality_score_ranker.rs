@@ -0,0 +1,190 @@
+//! Symbol centrality ranking (v1.7.3)
+//!
+//! # 4-Word Naming: symbol_centrality_score_ranker
+//!
+//! Scores every symbol of the dependency graph by how architecturally central
+//! it is, so context packing and search can prefer load-bearing code over
+//! leaf helpers:
+//!
+//! - **PageRank** (d = 0.85): heavily depended-upon symbols, directly or
+//!   through other important symbols
+//! - **Betweenness** (Brandes): bridges many dependency paths run through;
+//!   exact, so only computed up to `MAX_EXACT_BETWEENNESS_NODE_COUNT` nodes
+//! - **Importance**: `0.6 × PageRank/max + 0.4 × betweenness/max`, in [0, 1]
+//!   (PageRank alone when betweenness was skipped)
+//!
+//! `Tests` edges and external/unresolved placeholders are left out: a test
+//! suite calling a helper does not make it architectural, and `fmt.Printf`
+//! would otherwise top every ranking.
+//!
+//! Scores are recomputed and stored (SymbolCentrality relation) at the end of
+//! every ingest; `query rank` falls back to computing them on the fly.
+
+use std::collections::{BTreeSet, HashMap};
+
+use serde::Serialize;
+
+use crate::entities::{DependencyEdge, EdgeType};
+use crate::error::Result;
+use crate::graph_analysis::{
+    compute_betweenness_centrality_scores, compute_pagerank_centrality_scores, AdjacencyListGraphRepresentation,
+};
+use crate::serializers::graph_export_node_table::is_external_graph_node_key;
+use crate::storage::CozoDbStorage;
+
+/// Above this many nodes betweenness (O(V·E)) is skipped
+pub const MAX_EXACT_BETWEENNESS_NODE_COUNT: usize = 5_000;
+
+const PAGERANK_IMPORTANCE_WEIGHT: f64 = 0.6;
+const BETWEENNESS_IMPORTANCE_WEIGHT: f64 = 0.4;
+
+/// Centrality scores of one symbol
+///
+/// # 4-Word Name: SymbolCentralityScoreRecord
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct SymbolCentralityScoreRecord {
+    pub isgl1_key: String,
+    pub pagerank: f64,
+    pub betweenness: f64,
+    pub in_degree: usize,
+    pub out_degree: usize,
+    /// Normalized blend of PageRank and betweenness, 0.0 - 1.0
+    pub importance: f64,
+}
+
+/// Compute centrality scores for every symbol with an internal edge
+///
+/// # 4-Word Name: compute_symbol_centrality_score_records
+///
+/// # Contract
+/// - Postcondition: one record per node of the graph without `Tests` edges,
+///   self-loops and placeholder endpoints; sorted by (importance desc, key)
+/// - Postcondition: betweenness is 0.0 everywhere above
+///   `MAX_EXACT_BETWEENNESS_NODE_COUNT` nodes
+pub fn compute_symbol_centrality_score_records(edges: &[DependencyEdge]) -> Vec<SymbolCentralityScoreRecord> {
+    let pairs: BTreeSet<(&str, &str)> = edges
+        .iter()
+        .filter(|e| e.edge_type != EdgeType::Tests)
+        .map(|e| (e.from_key.as_str(), e.to_key.as_str()))
+        .filter(|(from, to)| from != to && !is_external_graph_node_key(from) && !is_external_graph_node_key(to))
+        .collect();
+    if pairs.is_empty() {
+        return Vec::new();
+    }
+
+    let mut in_degree: HashMap<&str, usize> = HashMap::new();
+    let mut out_degree: HashMap<&str, usize> = HashMap::new();
+    for (from, to) in &pairs {
+        *out_degree.entry(*from).or_default() += 1;
+        *in_degree.entry(*to).or_default() += 1;
+    }
+    let triples: Vec<(String, String, String)> = pairs
+        .iter()
+        .map(|(from, to)| (from.to_string(), to.to_string(), "depends".to_string()))
+        .collect();
+    let graph = AdjacencyListGraphRepresentation::build_from_dependency_edges(&triples);
+
+    let pagerank = compute_pagerank_centrality_scores(&graph, 0.85, 100, 1e-10);
+    let betweenness = if graph.count_total_graph_nodes() <= MAX_EXACT_BETWEENNESS_NODE_COUNT {
+        compute_betweenness_centrality_scores(&graph)
+    } else {
+        HashMap::new()
+    };
+    let max_pagerank = pagerank.values().copied().fold(0.0, f64::max);
+    let max_betweenness = betweenness.values().copied().fold(0.0, f64::max);
+
+    let mut records: Vec<SymbolCentralityScoreRecord> = pagerank
+        .iter()
+        .map(|(key, &rank)| {
+            let between = betweenness.get(key).copied().unwrap_or(0.0);
+            let rank_part = if max_pagerank > 0.0 { rank / max_pagerank } else { 0.0 };
+            let importance = if max_betweenness > 0.0 {
+                PAGERANK_IMPORTANCE_WEIGHT * rank_part + BETWEENNESS_IMPORTANCE_WEIGHT * between / max_betweenness
+            } else {
+                rank_part
+            };
+            SymbolCentralityScoreRecord {
+                isgl1_key: key.clone(),
+                pagerank: rank,
+                betweenness: between,
+                in_degree: in_degree.get(key.as_str()).copied().unwrap_or(0),
+                out_degree: out_degree.get(key.as_str()).copied().unwrap_or(0),
+                importance,
+            }
+        })
+        .collect();
+    sort_centrality_records_by_importance(&mut records);
+    records
+}
+
+/// Sort by (importance desc, key asc)
+///
+/// # 4-Word Name: sort_centrality_records_by_importance
+pub fn sort_centrality_records_by_importance(records: &mut [SymbolCentralityScoreRecord]) {
+    records.sort_by(|a, b| b.importance.total_cmp(&a.importance).then_with(|| a.isgl1_key.cmp(&b.isgl1_key)));
+}
+
+/// Recompute centrality from stored edges and replace the stored scores
+///
+/// # 4-Word Name: refresh_stored_symbol_centrality_scores
+///
+/// # Contract
+/// - Postcondition: SymbolCentrality holds exactly the freshly computed
+///   records; returns their count
+pub async fn refresh_stored_symbol_centrality_scores(storage: &CozoDbStorage) -> Result<usize> {
+    let edges = storage.get_all_dependencies().await?;
+    let records = compute_symbol_centrality_score_records(&edges);
+    storage.replace_symbol_centrality_scores(&records).await?;
+    Ok(records.len())
+}
+
+/// Stored importance by key, computing (without storing) when none is stored
+///
+/// # 4-Word Name: load_symbol_importance_lookup_map
+pub async fn load_symbol_importance_lookup_map(storage: &CozoDbStorage) -> Result<HashMap<String, f64>> {
+    let mut records = storage.get_all_symbol_centrality_scores().await?;
+    if records.is_empty() {
+        records = compute_symbol_centrality_score_records(&storage.get_all_dependencies().await?);
+    }
+    Ok(records.into_iter().map(|r| (r.isgl1_key, r.importance)).collect())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(edge_type)
+            .build()
+            .unwrap()
+    }
+
+    #[test]
+    fn test_hub_ranks_above_leaves() {
+        let edges = vec![
+            edge("go:fn:HandleA:__api:T1", "go:fn:Store:__db:T9", EdgeType::Calls),
+            edge("go:fn:HandleB:__api:T2", "go:fn:Store:__db:T9", EdgeType::Calls),
+            edge("go:fn:HandleC:__api:T3", "go:fn:Store:__db:T9", EdgeType::Calls),
+            edge("go:fn:Store:__db:T9", "go:fn:encode:__db:T10", EdgeType::Calls),
+            edge("go:fn:Store:__db:T9", "go:fn:Printf:unresolved-reference:0-0", EdgeType::Calls),
+            edge("go:fn:TestStore:__t:T11", "go:fn:encode:__db:T10", EdgeType::Tests),
+        ];
+
+        let records = compute_symbol_centrality_score_records(&edges);
+        assert_eq!(records.len(), 5, "placeholder and test-only nodes are not ranked");
+        let store = records.iter().find(|r| r.isgl1_key == "go:fn:Store:__db:T9").unwrap();
+        assert_eq!((store.in_degree, store.out_degree), (3, 1));
+        assert!(store.betweenness > 0.0, "every handler → encode path runs through Store");
+        assert!(records.iter().all(|r| (0.0..=1.0).contains(&r.importance)));
+        let leaf = records.iter().find(|r| r.isgl1_key == "go:fn:HandleA:__api:T1").unwrap();
+        assert!(store.importance > leaf.importance);
+    }
+
+    #[test]
+    fn test_empty_graph_has_no_scores() {
+        assert!(compute_symbol_centrality_score_records(&[]).is_empty());
+    }
+}
@@ -0,0 +1,323 @@
+//! Symbol Doc and Signature Extractor (v1.7.3)
+//!
+//! # 4-Word Naming: symbol_doc_signature_extractor
+//!
+//! Captures, at ingest time, what an API reader needs without opening the
+//! file: the doc comment, the one-line declaration signature and the
+//! visibility of each symbol. pt01 stores them on the node
+//! (`interface_signature.documentation`, `interface_signature.visibility`,
+//! `signature` metadata) so context packs and `export --signatures-only`
+//! read them straight from the graph.
+//!
+//! ## Doc comments
+//!
+//! - Comment lines directly above the declaration (`///`, `//`, `/** */`,
+//!   `#`, `--`), skipping attributes/annotations (`#[..]`, `@Foo`, `[Attr]`);
+//!   a blank line ends the block (Go doc comment rule); `companion:`
+//!   directive lines are skipped
+//! - Python: the docstring after the `def`/`class` line wins over comments
+//!
+//! ## Attributes
+//!
+//! The attribute/annotation/decorator lines directly above the declaration
+//! (`#[derive(Parser)]`, `@app.task`, `@Scheduled(cron = "0 * * * *")`) sit
+//! outside the entity's line range; pt01 keeps them, in source order, in
+//! `attributes` metadata (one per line) so analyses such as
+//! `analyze entrypoints` can read registrations made by decorators.
+//!
+//! ## Visibility
+//!
+//! Keyword-based from the signature (`pub(crate)`, `private`, `export`, ...)
+//! with language defaults: Go/Python by name (capitalised / leading `_`),
+//! Java package-private and TS/JS non-exported symbols are `Module`.
+
+use crate::entities::{Language, Visibility};
+use crate::inline_suppression_directive_parser::is_companion_directive_text;
+use crate::token_budget_context_packer::extract_signature_from_code;
+
+/// Entity metadata key holding the one-line declaration signature
+pub const SIGNATURE_METADATA_KEY: &str = "signature";
+
+/// Entity metadata key holding the attribute lines above the declaration
+pub const ATTRIBUTES_METADATA_KEY: &str = "attributes";
+
+/// Doc comment of the symbol declared at `start_line` (1-based)
+///
+/// # 4-Word Name: extract_symbol_doc_comment
+///
+/// # Contract
+/// - Precondition: `source` is the whole file, `start_line..=end_line` the entity
+/// - Postcondition: comment markers stripped, lines joined with `\n`;
+///   None when the symbol is undocumented
+pub fn extract_symbol_doc_comment(source: &str, start_line: usize, end_line: usize, language: Language) -> Option<String> {
+    let lines: Vec<&str> = source.lines().collect();
+    if start_line == 0 || start_line > lines.len() {
+        return None;
+    }
+    if language == Language::Python {
+        if let Some(docstring) = python_docstring_after_line(&lines, start_line, end_line) {
+            return Some(docstring);
+        }
+    }
+
+    let mut collected: Vec<String> = Vec::new();
+    let mut in_block = false;
+    for line in lines[..start_line - 1].iter().rev() {
+        let trimmed = line.trim();
+        if in_block {
+            // Walking upwards: the block started at `/*`
+            collected.push(strip_block_comment_line(trimmed));
+            if trimmed.starts_with("/*") {
+                in_block = false;
+            }
+            continue;
+        }
+        if trimmed.is_empty() {
+            break;
+        }
+        if is_attribute_annotation_line(trimmed, language) {
+            if collected.is_empty() {
+                continue;
+            }
+            break;
+        }
+        if trimmed.ends_with("*/") {
+            collected.push(strip_block_comment_line(trimmed));
+            in_block = !trimmed.starts_with("/*");
+            continue;
+        }
+        match strip_line_comment_marker(trimmed, language) {
+            Some(text) if is_companion_directive_text(&text) => continue,
+            Some(text) => collected.push(text),
+            None => break,
+        }
+    }
+
+    collected.reverse();
+    let doc = collected.join("\n").trim().to_string();
+    (!doc.is_empty()).then_some(doc)
+}
+
+/// Attribute lines of the symbol declared at `start_line` (1-based)
+///
+/// # 4-Word Name: extract_symbol_attribute_lines
+///
+/// # Contract
+/// - Precondition: `source` is the whole file
+/// - Postcondition: trimmed lines in source order; comment lines between
+///   attributes are skipped, a blank line or code line ends the block;
+///   None when the symbol has no attribute
+pub fn extract_symbol_attribute_lines(source: &str, start_line: usize, language: Language) -> Option<String> {
+    let lines: Vec<&str> = source.lines().collect();
+    if start_line == 0 || start_line > lines.len() {
+        return None;
+    }
+
+    let mut collected: Vec<&str> = Vec::new();
+    for line in lines[..start_line - 1].iter().rev() {
+        let trimmed = line.trim();
+        if trimmed.is_empty() {
+            break;
+        }
+        if is_attribute_annotation_line(trimmed, language) {
+            collected.push(trimmed);
+        } else if strip_line_comment_marker(trimmed, language).is_none() {
+            break;
+        }
+    }
+    collected.reverse();
+    (!collected.is_empty()).then(|| collected.join("\n"))
+}
+
+/// One-line declaration signature of an entity's code
+///
+/// # 4-Word Name: extract_symbol_signature_line
+///
+/// Declaration up to the body (see `extract_signature_from_code`), with
+/// whitespace runs collapsed: `pub fn save(&self, u: &User) -> Result<()>`.
+pub fn extract_symbol_signature_line(code: &str) -> String {
+    extract_signature_from_code(code).split_whitespace().collect::<Vec<_>>().join(" ")
+}
+
+/// Visibility of a symbol from its signature and name
+///
+/// # 4-Word Name: infer_symbol_visibility_level
+///
+/// # Contract
+/// - Postcondition: keyword in the signature wins; otherwise the language
+///   default (see module docs)
+pub fn infer_symbol_visibility_level(signature: &str, name: &str, language: Language) -> Visibility {
+    let words: Vec<&str> = signature
+        .split(|c: char| c.is_whitespace() || c == '(' || c == ')')
+        .filter(|w| !w.is_empty())
+        .collect();
+    let has = |keyword: &str| words.iter().take_while(|w| **w != name).any(|w| *w == keyword);
+
+    match language {
+        Language::Rust => {
+            let compact: String = signature.split_whitespace().collect();
+            if compact.starts_with("pub(crate)") {
+                Visibility::Crate
+            } else if compact.starts_with("pub(super)") || compact.starts_with("pub(in") || compact.starts_with("pub(self)") {
+                Visibility::Module
+            } else if compact.starts_with("pub") {
+                Visibility::Public
+            } else {
+                Visibility::Private
+            }
+        }
+        Language::Go => {
+            if name.starts_with(|c: char| c.is_uppercase()) {
+                Visibility::Public
+            } else {
+                Visibility::Module
+            }
+        }
+        Language::Python => {
+            let dunder = name.starts_with("__") && name.ends_with("__");
+            if name.starts_with('_') && !dunder {
+                Visibility::Private
+            } else {
+                Visibility::Public
+            }
+        }
+        Language::JavaScript | Language::TypeScript => {
+            if has("private") || name.starts_with('#') {
+                Visibility::Private
+            } else if has("protected") {
+                Visibility::Protected
+            } else if has("export") || has("public") {
+                Visibility::Public
+            } else {
+                Visibility::Module
+            }
+        }
+        Language::C | Language::Cpp => {
+            if has("static") || has("private") {
+                Visibility::Private
+            } else if has("protected") {
+                Visibility::Protected
+            } else {
+                Visibility::Public
+            }
+        }
+        _ => {
+            if has("private") || has("fileprivate") {
+                Visibility::Private
+            } else if has("protected") {
+                Visibility::Protected
+            } else if has("internal") {
+                Visibility::Crate
+            } else if has("public") || has("open") {
+                Visibility::Public
+            } else {
+                match language {
+                    // Java: package-private; C#: members default to private
+                    Language::Java => Visibility::Module,
+                    Language::CSharp => Visibility::Private,
+                    Language::Swift => Visibility::Crate,
+                    _ => Visibility::Public,
+                }
+            }
+        }
+    }
+}
+
+/// `"""docstring"""` on the lines after a Python `def`/`class` header
+fn python_docstring_after_line(lines: &[&str], start_line: usize, end_line: usize) -> Option<String> {
+    let body_start = (start_line - 1..end_line.min(lines.len()))
+        .find(|&i| lines[i].trim_end().ends_with(':'))?
+        + 1;
+    let first = lines.get(body_start)?.trim();
+    let quote = ["\"\"\"", "'''"].into_iter().find(|q| first.starts_with(q))?;
+    let opened = &first[quote.len()..];
+    if let Some(end) = opened.find(quote) {
+        return Some(opened[..end].trim().to_string()).filter(|d| !d.is_empty());
+    }
+    let mut doc = vec![opened.trim().to_string()];
+    for line in lines.iter().skip(body_start + 1) {
+        if let Some(end) = line.find(quote) {
+            doc.push(line[..end].trim().to_string());
+            break;
+        }
+        doc.push(line.trim().to_string());
+    }
+    Some(doc.join("\n").trim().to_string()).filter(|d| !d.is_empty())
+}
+
+/// Attribute / annotation / decorator lines sitting between doc and item
+pub(crate) fn is_attribute_annotation_line(trimmed: &str, language: Language) -> bool {
+    match language {
+        Language::Rust => trimmed.starts_with("#["),
+        Language::CSharp => trimmed.starts_with('[') && trimmed.ends_with(']'),
+        Language::Php => trimmed.starts_with("#["),
+        _ => trimmed.starts_with('@'),
+    }
+}
+
+/// Comment text of a single-line comment, None for code
+pub(crate) fn strip_line_comment_marker(trimmed: &str, language: Language) -> Option<String> {
+    let markers: &[&str] = match language {
+        Language::Python | Language::Ruby => &["#"],
+        Language::Sql => &["--"],
+        Language::Php => &["///", "//", "#"],
+        _ => &["///", "//"],
+    };
+    markers
+        .iter()
+        .find(|m| trimmed.starts_with(**m))
+        .map(|m| trimmed[m.len()..].trim().to_string())
+}
+
+/// One line of a `/* ... */` block without its markers
+fn strip_block_comment_line(trimmed: &str) -> String {
+    let text = trimmed.trim_end_matches("*/");
+    let text = text.strip_prefix("/**").or_else(|| text.strip_prefix("/*")).unwrap_or(text);
+    let text = text.trim_start();
+    text.strip_prefix('*').unwrap_or(text).trim().to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_doc_comments_per_language() {
+        let rust = "use std::fmt;\n\n/// Saves a user.\n/// Returns the id.\n#[inline]\npub fn save() {}\n";
+        assert_eq!(
+            extract_symbol_doc_comment(rust, 6, 6, Language::Rust).as_deref(),
+            Some("Saves a user.\nReturns the id.")
+        );
+
+        let go = "// Store persists users.\n\n// Save writes u.\nfunc (s *Store) Save(u User) error {\n}\n";
+        assert_eq!(extract_symbol_doc_comment(go, 4, 5, Language::Go).as_deref(), Some("Save writes u."));
+
+        let java = "/**\n * Loads a user.\n */\n@Override\npublic User load() {\n}\n";
+        assert_eq!(extract_symbol_doc_comment(java, 5, 6, Language::Java).as_deref(), Some("Loads a user."));
+        assert_eq!(extract_symbol_attribute_lines(java, 5, Language::Java).as_deref(), Some("@Override"));
+        assert_eq!(extract_symbol_attribute_lines(rust, 6, Language::Rust).as_deref(), Some("#[inline]"));
+        assert_eq!(extract_symbol_attribute_lines(go, 4, Language::Go), None);
+
+        let python = "# helper\ndef run(x):\n    \"\"\"Run x.\n\n    Twice.\n    \"\"\"\n    return x\n";
+        assert_eq!(extract_symbol_doc_comment(python, 2, 7, Language::Python).as_deref(), Some("Run x.\n\nTwice."));
+
+        assert_eq!(extract_symbol_doc_comment("let x = 1;\nfn bare() {}\n", 2, 2, Language::Rust), None);
+    }
+
+    #[test]
+    fn test_signature_and_visibility() {
+        assert_eq!(
+            extract_symbol_signature_line("pub(crate) fn save(\n    &self,\n    u: &User,\n) -> Result<()> {\n    Ok(())\n}"),
+            "pub(crate) fn save( &self, u: &User, ) -> Result<()>"
+        );
+        assert_eq!(infer_symbol_visibility_level("pub(crate) fn save()", "save", Language::Rust), Visibility::Crate);
+        assert_eq!(infer_symbol_visibility_level("pub struct User", "User", Language::Rust), Visibility::Public);
+        assert_eq!(infer_symbol_visibility_level("fn helper()", "helper", Language::Rust), Visibility::Private);
+        assert_eq!(infer_symbol_visibility_level("func save()", "save", Language::Go), Visibility::Module);
+        assert_eq!(infer_symbol_visibility_level("def _load(self):", "_load", Language::Python), Visibility::Private);
+        assert_eq!(infer_symbol_visibility_level("def __init__(self):", "__init__", Language::Python), Visibility::Public);
+        assert_eq!(infer_symbol_visibility_level("User load()", "load", Language::Java), Visibility::Module);
+        assert_eq!(infer_symbol_visibility_level("export function load()", "load", Language::TypeScript), Visibility::Public);
+        assert_eq!(infer_symbol_visibility_level("private void run()", "run", Language::CSharp), Visibility::Private);
+    }
+}
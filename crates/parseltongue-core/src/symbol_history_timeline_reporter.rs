@@ -0,0 +1,307 @@
+//! Symbol evolution across recorded revisions (v1.7.3)
+//!
+//! # 4-Word Naming: symbol_history_timeline_reporter
+//!
+//! `parseltongue history <symbol>` reads the per-revision symbol states of
+//! `revision_history_sqlite_store` and compares each recorded revision with
+//! the one before it, oldest first:
+//!
+//! ```text
+//! # History: Save (3 revisions recorded)
+//! ## 1a2b3c4d 2026-01-10 (v1.0.0) Add user store
+//! - appeared `go:method:Save:__svc:T1` — `func Save(u User)`
+//! ## 5e6f7a8b 2026-02-02 Save with context
+//! - signature changed `go:method:Save:__svc:T1` — `func Save(u User)` → `func Save(ctx Context, u User)`
+//! - callers gained `go:method:Save:__svc:T1` — go:fn:Register:__api:T4
+//! ```
+//!
+//! ## Events
+//!
+//! `appeared` / `removed` (the key is new or gone), `signature_changed`,
+//! `body_changed` (same signature, different body), `callers_gained` and
+//! `callers_lost` (resolved Calls edges). The history is only as fine as
+//! the recorded revisions: recording tags only compares tag to tag.
+
+use std::collections::{BTreeMap, BTreeSet};
+
+use serde::Serialize;
+
+use crate::storage::revision_history_sqlite_store::{RecordedRevisionSummaryEntry, SymbolRevisionStateEntry};
+
+/// Change one revision made to a symbol
+///
+/// # 4-Word Name: SymbolHistoryEventKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "snake_case")]
+pub enum SymbolHistoryEventKind {
+    Appeared,
+    Removed,
+    SignatureChanged,
+    BodyChanged,
+    CallersGained,
+    CallersLost,
+}
+
+impl SymbolHistoryEventKind {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            SymbolHistoryEventKind::Appeared => "appeared",
+            SymbolHistoryEventKind::Removed => "removed",
+            SymbolHistoryEventKind::SignatureChanged => "signature changed",
+            SymbolHistoryEventKind::BodyChanged => "body changed",
+            SymbolHistoryEventKind::CallersGained => "callers gained",
+            SymbolHistoryEventKind::CallersLost => "callers lost",
+        }
+    }
+}
+
+/// One change to one symbol at one revision
+///
+/// # 4-Word Name: SymbolHistoryEventEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SymbolHistoryEventEntry {
+    pub commit_sha: String,
+    pub committed_at: String,
+    pub tags: Vec<String>,
+    pub subject: String,
+    pub entity_key: String,
+    pub kind: SymbolHistoryEventKind,
+    /// Signature before the change (removed, signature_changed)
+    pub before_signature: Option<String>,
+    /// Signature after the change (appeared, signature_changed)
+    pub after_signature: Option<String>,
+    /// Callers gained or lost; the initial callers for `appeared`
+    pub callers: Vec<String>,
+}
+
+/// Timeline of a symbol over the recorded revisions
+///
+/// # 4-Word Name: SymbolHistoryTimelineReport
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SymbolHistoryTimelineReport {
+    pub symbol: String,
+    pub revisions_recorded: usize,
+    /// Oldest first
+    pub events: Vec<SymbolHistoryEventEntry>,
+}
+
+/// Compare each recorded revision of a symbol with the previous one
+///
+/// # 4-Word Name: build_symbol_history_timeline
+///
+/// # Contract
+/// - Precondition: `revisions` oldest first, as from
+///   `load_symbol_revision_states`
+/// - Postcondition: events in revision order, then by entity key; within a
+///   key: appeared/removed, signature or body, callers gained, callers lost
+/// - Postcondition: the first revision only yields `appeared` events
+pub fn build_symbol_history_timeline(
+    symbol: &str,
+    revisions: &[(RecordedRevisionSummaryEntry, Vec<SymbolRevisionStateEntry>)],
+) -> SymbolHistoryTimelineReport {
+    let mut events = Vec::new();
+    let mut previous: BTreeMap<&str, &SymbolRevisionStateEntry> = BTreeMap::new();
+    for (revision, states) in revisions {
+        let current: BTreeMap<&str, &SymbolRevisionStateEntry> =
+            states.iter().map(|state| (state.entity_key.as_str(), state)).collect();
+        let keys: BTreeSet<&str> = previous.keys().chain(current.keys()).copied().collect();
+        for key in keys {
+            let event = |kind, before: Option<&str>, after: Option<&str>, callers| SymbolHistoryEventEntry {
+                commit_sha: revision.commit.commit_sha.clone(),
+                committed_at: revision.commit.committed_at.clone(),
+                tags: revision.commit.tags.clone(),
+                subject: revision.commit.subject.clone(),
+                entity_key: key.to_string(),
+                kind,
+                before_signature: before.map(str::to_string),
+                after_signature: after.map(str::to_string),
+                callers,
+            };
+            match (previous.get(key), current.get(key)) {
+                (None, Some(now)) => events.push(event(
+                    SymbolHistoryEventKind::Appeared,
+                    None,
+                    Some(now.signature.as_str()),
+                    now.callers.clone(),
+                )),
+                (Some(was), None) => {
+                    events.push(event(SymbolHistoryEventKind::Removed, Some(was.signature.as_str()), None, Vec::new()))
+                }
+                (Some(was), Some(now)) => {
+                    if was.signature != now.signature {
+                        events.push(event(
+                            SymbolHistoryEventKind::SignatureChanged,
+                            Some(was.signature.as_str()),
+                            Some(now.signature.as_str()),
+                            Vec::new(),
+                        ));
+                    } else if was.body_hash != now.body_hash {
+                        events.push(event(SymbolHistoryEventKind::BodyChanged, None, None, Vec::new()));
+                    }
+                    let before: BTreeSet<&String> = was.callers.iter().collect();
+                    let after: BTreeSet<&String> = now.callers.iter().collect();
+                    let gained: Vec<String> = after.difference(&before).map(|key| key.to_string()).collect();
+                    let lost: Vec<String> = before.difference(&after).map(|key| key.to_string()).collect();
+                    if !gained.is_empty() {
+                        events.push(event(SymbolHistoryEventKind::CallersGained, None, None, gained));
+                    }
+                    if !lost.is_empty() {
+                        events.push(event(SymbolHistoryEventKind::CallersLost, None, None, lost));
+                    }
+                }
+                (None, None) => {}
+            }
+        }
+        previous = current;
+    }
+    SymbolHistoryTimelineReport { symbol: symbol.to_string(), revisions_recorded: revisions.len(), events }
+}
+
+/// Markdown timeline, one section per revision that changed the symbol
+///
+/// # 4-Word Name: render_symbol_history_markdown
+pub fn render_symbol_history_markdown(report: &SymbolHistoryTimelineReport) -> String {
+    let mut out = format!("# History: {} ({} revisions recorded)\n", report.symbol, report.revisions_recorded);
+    if report.events.is_empty() {
+        out.push_str("\nNo recorded revision contains this symbol.\n");
+        return out;
+    }
+    let mut section = "";
+    for event in &report.events {
+        if event.commit_sha != section {
+            section = &event.commit_sha;
+            let short = &event.commit_sha[..event.commit_sha.len().min(8)];
+            let date = &event.committed_at[..event.committed_at.len().min(10)];
+            let tags = if event.tags.is_empty() { String::new() } else { format!(" ({})", event.tags.join(", ")) };
+            out.push_str(&format!("## {} {}{} {}\n", short, date, tags, event.subject));
+        }
+        let detail = match (&event.before_signature, &event.after_signature) {
+            (Some(before), Some(after)) => format!(" — `{}` → `{}`", before, after),
+            (Some(signature), None) | (None, Some(signature)) if !signature.is_empty() => {
+                format!(" — `{}`", signature)
+            }
+            _ if !event.callers.is_empty() && event.kind != SymbolHistoryEventKind::Appeared => {
+                format!(" — {}", event.callers.join(", "))
+            }
+            _ => String::new(),
+        };
+        out.push_str(&format!("- {} `{}`{}\n", event.kind.as_str(), event.entity_key, detail));
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::storage::revision_history_sqlite_store::RevisionCommitDetailsEntry;
+
+    fn revision(sha: &str, date: &str) -> RecordedRevisionSummaryEntry {
+        RecordedRevisionSummaryEntry {
+            commit: RevisionCommitDetailsEntry {
+                commit_sha: sha.to_string(),
+                tags: Vec::new(),
+                committed_at: date.to_string(),
+                subject: format!("commit {}", sha),
+            },
+            symbol_count: 1,
+            call_count: 0,
+        }
+    }
+
+    fn save(signature: &str, body_hash: &str, callers: &[&str]) -> SymbolRevisionStateEntry {
+        SymbolRevisionStateEntry {
+            entity_key: "go:method:Save:__svc:T1".to_string(),
+            name: "Save".to_string(),
+            kind: "method".to_string(),
+            file_path: "svc/store.go".to_string(),
+            signature: signature.to_string(),
+            body_hash: body_hash.to_string(),
+            callers: callers.iter().map(|caller| caller.to_string()).collect(),
+        }
+    }
+
+    #[test]
+    fn test_timeline_reports_each_kind_of_change() {
+        let old = "func Save(u User) error";
+        let new = "func Save(ctx context.Context, u User) error";
+        let revisions = vec![
+            (revision("aaaa", "2026-01-01T00:00:00Z"), vec![]),
+            (revision("bbbb", "2026-01-02T00:00:00Z"), vec![save(old, "h1", &["main"])]),
+            (revision("cccc", "2026-01-03T00:00:00Z"), vec![save(old, "h2", &["main", "Register"])]),
+            (revision("dddd", "2026-01-04T00:00:00Z"), vec![save(new, "h3", &["Register"])]),
+            (revision("eeee", "2026-01-05T00:00:00Z"), vec![]),
+        ];
+        let report = build_symbol_history_timeline("Save", &revisions);
+        let kinds: Vec<(&str, SymbolHistoryEventKind)> =
+            report.events.iter().map(|event| (event.commit_sha.as_str(), event.kind)).collect();
+        assert_eq!(
+            kinds,
+            vec![
+                ("bbbb", SymbolHistoryEventKind::Appeared),
+                ("cccc", SymbolHistoryEventKind::BodyChanged),
+                ("cccc", SymbolHistoryEventKind::CallersGained),
+                ("dddd", SymbolHistoryEventKind::SignatureChanged),
+                ("dddd", SymbolHistoryEventKind::CallersLost),
+                ("eeee", SymbolHistoryEventKind::Removed),
+            ]
+        );
+        assert_eq!(report.events[2].callers, vec!["Register"]);
+        assert_eq!(report.events[3].after_signature.as_deref(), Some(new));
+
+        let markdown = render_symbol_history_markdown(&report);
+        assert!(markdown.starts_with("# History: Save (5 revisions recorded)\n"), "{}", markdown);
+        let changed = format!("- signature changed `go:method:Save:__svc:T1` — `{}` → `{}`\n", old, new);
+        assert!(markdown.contains(&changed), "{}", markdown);
+        assert!(markdown.contains("- callers lost `go:method:Save:__svc:T1` — main\n"), "{}", markdown);
+    }
+
+    #[test]
+    fn test_reappearance_sibling_keys_and_markdown_headers() {
+        let empty = build_symbol_history_timeline("Gone", &[(revision("aaaa", "2026-01-01T00:00:00Z"), vec![])]);
+        assert!(empty.events.is_empty());
+        assert_eq!(
+            render_symbol_history_markdown(&empty),
+            "# History: Gone (1 revisions recorded)\n\nNo recorded revision contains this symbol.\n"
+        );
+
+        let mut tagged = revision("0123456789abcdef", "2026-02-03T04:05:06Z");
+        tagged.commit.tags = vec!["v1.0.0".to_string(), "stable".to_string()];
+        let mut cached = save("func Save()", "h1", &[]);
+        cached.entity_key = "go:fn:Save:__cache:T2".to_string();
+        let revisions = vec![
+            (tagged, vec![save("", "h1", &["main"]), cached.clone()]),
+            (revision("bbbb", "2026-02-04"), vec![cached.clone()]),
+            (revision("cccc", "2026-02-05T00:00:00Z"), vec![save("func Save()", "h1", &[]), cached]),
+        ];
+        let report = build_symbol_history_timeline("Save", &revisions);
+        let events: Vec<(&str, &str, SymbolHistoryEventKind)> = report
+            .events
+            .iter()
+            .map(|event| (event.commit_sha.as_str(), event.entity_key.as_str(), event.kind))
+            .collect();
+        assert_eq!(
+            events,
+            vec![
+                ("0123456789abcdef", "go:fn:Save:__cache:T2", SymbolHistoryEventKind::Appeared),
+                ("0123456789abcdef", "go:method:Save:__svc:T1", SymbolHistoryEventKind::Appeared),
+                ("bbbb", "go:method:Save:__svc:T1", SymbolHistoryEventKind::Removed),
+                ("cccc", "go:method:Save:__svc:T1", SymbolHistoryEventKind::Appeared),
+            ]
+        );
+        assert_eq!(report.events[1].callers, vec!["main"], "appeared lists the initial callers");
+        assert_eq!(report.events[2].before_signature.as_deref(), Some(""));
+
+        let markdown = render_symbol_history_markdown(&report);
+        for expected in [
+            "## 01234567 2026-02-03 (v1.0.0, stable) commit 0123456789abcdef\n",
+            "- appeared `go:fn:Save:__cache:T2` — `func Save()`\n",
+            // No signature and, for appeared, no caller list in the line
+            "- appeared `go:method:Save:__svc:T1`\n",
+            "## bbbb 2026-02-04 commit bbbb\n- removed `go:method:Save:__svc:T1`\n",
+            "## cccc 2026-02-05 commit cccc\n- appeared `go:method:Save:__svc:T1` — `func Save()`\n",
+        ] {
+            assert!(markdown.contains(expected), "missing {:?} in\n{}", expected, markdown);
+        }
+        assert_eq!(markdown.matches("## ").count(), 3, "one section per changing revision");
+    }
+}
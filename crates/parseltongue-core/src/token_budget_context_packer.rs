@@ -0,0 +1,1264 @@
+//! Token-budgeted context packing (v1.7.3)
+//!
+//! # 4-Word Naming: token_budget_context_packer
+//!
+//! Given a seed entity, walks callers/callees by relevance and builds a
+//! context bundle for an LLM prompt that is guaranteed to fit a token budget.
+//!
+//! ## Strategy
+//!
+//! 1. Rank neighbours by graph distance (callers 1.0, callees 0.95, then
+//!    0.7 - 0.1 × extra depth), plus up to 0.04 for centrality (so
+//!    architecturally important symbols lead their tier without jumping to
+//!    the next), ties broken by key for determinism
+//! 2. Pass 1: add each entity's SIGNATURE in rank order while it fits
+//! 3. Pass 2: upgrade signatures to full BODIES in rank order while they fit
+//! 4. Pass 3: entities left as signatures get their stored SUMMARY
+//!    (`parseltongue summarize`) while it fits
+//! 5. Pass 4: leftover budget goes to the leading CHUNKS of bodies too large
+//!    to fit whole and without a summary (see below)
+//! 6. Render and re-count the whole bundle; drop lowest-ranked items until
+//!    the rendered text fits (BPE counts of joined text may differ from the
+//!    sum of the parts, so the final check is on the exact output)
+//!
+//! With `--expand-impls N`, interface calls of ranked entities bring in at
+//! most N implementations each, ranked as callees (relation
+//! `implementation`, see `interface_impl_pack_expander`).
+//!
+//! A pack can also start from a set of seeds (e.g. every symbol touched by a
+//! diff, see `git_diff_context_scoper`): each key keeps its best score over
+//! all seeds.
+//!
+//! ## Tests
+//!
+//! Test entities (Go `_test.go` symbols) are left out unless requested. With
+//! tests included, test functions reached through `Tests` edges are ranked
+//! as `test` ("the tests covering the function you're editing").
+//!
+//! ## Generated code
+//!
+//! Entities of generated files (`generated: true`, see
+//! `go_generate_lineage_tracker`) lose `GENERATED_OUTPUT_RELEVANCE_PENALTY`
+//! unless they are seeds, so the hand-written source wins the budget, and
+//! they are packed as signatures only. Their header names the source to
+//! edit instead (`[generated from internal/color.go]`).
+//!
+//! ## Recency
+//!
+//! With a `RecencyScoringConfigSpec` (`--recency`, `--ticket PROJ-123`),
+//! candidates are also boosted by how recently they changed and whether
+//! their commits name the ticket (`recency_relevance_score_booster`), before
+//! the candidate cut.
+//!
+//! ## Ownership
+//!
+//! When the graph was indexed with `--git-blame`, each item header names
+//! the symbol's owner (`[owner: Ada]`) so the LLM knows whom to ask.
+//! Owner teams from CODEOWNERS (see `codeowners_ownership_rule_matcher`)
+//! show as `[code owners: @acme/payments]`. With a team set on the bundle
+//! (`--team`), the output ends with the packed symbols owned by other
+//! teams; that section is part of the rendered text, so the final check
+//! keeps it inside the budget too.
+//!
+//! ## Documentation
+//!
+//! Doc comments captured at ingest (`interface_signature.documentation`)
+//! are rendered under each item header, and signature items use the stored
+//! `signature` line when present, so nothing is re-read from source files.
+//!
+//! ## Summaries
+//!
+//! A one-paragraph LLM summary stored in `summary` metadata (see
+//! `llm_symbol_summary_cache`) stands in for a body that did not fit: the
+//! item keeps its signature and adds the summary under its header, so a
+//! tight budget still tells the model what every packed symbol does.
+//!
+//! ## Chunks
+//!
+//! A body that does not fit whole is cut into windows of
+//! `BODY_CHUNK_LINE_COUNT` lines, each repeating the last
+//! `BODY_CHUNK_OVERLAP_LINES` lines of the one before. The item then holds
+//! as many leading chunks as fit, under a `// lines 120-159 of 120-410`
+//! header, and `chunks` records the emitted chunk numbers and every chunk's
+//! line span so a follow-up (`parseltongue body-chunk KEY --chunk 3`) can
+//! fetch the rest; the overlap gives that follow-up its context.
+//!
+//! ## Templates
+//!
+//! Output can go through a prompt template (`context_prompt_template_renderer`,
+//! `--template claude-xml`). Items are then measured as rendered by that
+//! template: the template's fixed text is charged once up front, each item
+//! costs what it adds to a one-item render, and the final check counts the
+//! template output itself.
+//!
+//! ## Traversal limits
+//!
+//! The storage packers take a `TraversalExpansionLimitSpec`: `--max-fanout`
+//! caps the neighbours ranked per node, `--max-nodes` cuts the ranking
+//! before loading, and the bundle's `truncation` report says what the walk
+//! left out (see `traversal_expansion_limit_guards`).
+//!
+//! ## Tokenizer
+//!
+//! Counts use the `cl100k_base` BPE (tiktoken) rather than byte heuristics,
+//! or the tokenizer of a `--model` preset (`o200k_base` for GPT-4o, see
+//! `context_pack_token_accounting`); the bundle records which. If the BPE
+//! tables fail to load, a conservative 1-token-per-2-bytes bound is used so
+//! the budget guarantee still holds.
+
+use std::collections::{HashMap, HashSet, VecDeque};
+
+use serde::Serialize;
+
+use crate::codeowners_ownership_rule_matcher::{is_outside_team_ownership, CODE_OWNERS_METADATA_KEY};
+use crate::context_pack_token_accounting::{count_tokens_with_encoding, ContextTokenizerEncodingKind};
+use crate::context_prompt_template_renderer::ContextPackOutputTemplate;
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityClass};
+use crate::error::{ParseltongError, Result};
+use crate::git_blame_ownership_annotator::OWNER_METADATA_KEY;
+use crate::go_generate_lineage_tracker::{GENERATED_FROM_METADATA_KEY, GENERATED_METADATA_KEY};
+use crate::interface_impl_pack_expander::{expand_interface_method_implementations, split_dynamic_dispatch_edges};
+use crate::llm_symbol_summary_cache::SUMMARY_METADATA_KEY;
+use crate::recency_relevance_score_booster::{apply_recency_relevance_boost, RecencyScoringConfigSpec};
+use crate::storage::CozoDbStorage;
+use crate::symbol_centrality_score_ranker::load_symbol_importance_lookup_map;
+use crate::symbol_doc_signature_extractor::SIGNATURE_METADATA_KEY;
+use crate::traversal_expansion_limit_guards::{
+    cap_edge_list_fanout, TraversalExpansionLimitSpec, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+};
+
+/// Upper bound on ranked entities loaded from storage per pack
+pub const MAX_PACK_CANDIDATE_ENTITIES: usize = 500;
+
+/// Relevance of the seed itself (always ranked first)
+pub const SEED_ENTITY_RELEVANCE_SCORE: f64 = 2.0;
+
+/// Relevance of a direct caller of the seed
+pub const DIRECT_CALLER_RELEVANCE_SCORE: f64 = 1.0;
+
+/// Relevance of a direct callee of the seed
+pub const DIRECT_CALLEE_RELEVANCE_SCORE: f64 = 0.95;
+
+/// Relevance of a test function exercising the seed directly
+pub const DIRECT_TEST_RELEVANCE_SCORE: f64 = 0.9;
+
+/// Relevance added for importance 1.0 (smaller than the gap between tiers)
+pub const CENTRALITY_RELEVANCE_BOOST_WEIGHT: f64 = 0.04;
+
+/// Relevance removed from generated (non-seed) entities: below the next tier
+pub const GENERATED_OUTPUT_RELEVANCE_PENALTY: f64 = 0.5;
+
+/// Lines per chunk of a body too large to pack whole
+pub const BODY_CHUNK_LINE_COUNT: usize = 40;
+
+/// Lines each chunk repeats from the end of the previous one
+pub const BODY_CHUNK_OVERLAP_LINES: usize = 5;
+
+/// Count tokens with the cl100k_base tokenizer
+///
+/// # 4-Word Name: count_text_tokens_exactly
+///
+/// # Contract
+/// - Postcondition: exact BPE token count; conservative upper bound
+///   (`ceil(bytes / 2)`) if the tokenizer is unavailable
+pub fn count_text_tokens_exactly(text: &str) -> usize {
+    count_tokens_with_encoding(text, ContextTokenizerEncodingKind::Cl100kBase)
+}
+
+/// Entity reached from the seed, with relevance
+///
+/// # 4-Word Name: RankedNeighborEntityEntry
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct RankedNeighborEntityEntry {
+    pub entity_key: String,
+    /// "seed", "caller", "callee", "test", "transitive_caller", "transitive_callee"
+    pub relation: String,
+    pub depth: usize,
+    pub relevance_score: f64,
+}
+
+/// Rank entities around a seed by graph relevance
+///
+/// # 4-Word Name: rank_neighbors_by_relevance
+///
+/// # Contract
+/// - Precondition: `edges` are the graph's dependency edges (any edge type)
+/// - Postcondition: seed first, then every entity within `max_depth` hops in
+///   either direction, sorted by (score desc, key asc); each key once, at its
+///   shortest distance; callers that are the source of `Tests` edges are `test`
+pub fn rank_neighbors_by_relevance(
+    seed_key: &str,
+    edges: &[DependencyEdge],
+    max_depth: usize,
+) -> Vec<RankedNeighborEntityEntry> {
+    let mut forward: HashMap<&str, Vec<&str>> = HashMap::new();
+    let mut reverse: HashMap<&str, Vec<&str>> = HashMap::new();
+    let mut test_functions: HashSet<&str> = HashSet::new();
+    for edge in edges {
+        forward.entry(edge.from_key.as_str()).or_default().push(edge.to_key.as_str());
+        reverse.entry(edge.to_key.as_str()).or_default().push(edge.from_key.as_str());
+        if edge.edge_type == EdgeType::Tests {
+            test_functions.insert(edge.from_key.as_str());
+        }
+    }
+
+    let mut ranked = vec![RankedNeighborEntityEntry {
+        entity_key: seed_key.to_string(),
+        relation: "seed".to_string(),
+        depth: 0,
+        relevance_score: SEED_ENTITY_RELEVANCE_SCORE,
+    }];
+    let mut visited: HashSet<&str> = HashSet::new();
+    visited.insert(seed_key);
+
+    // BFS: (key, depth, is_caller_direction)
+    let mut queue: VecDeque<(&str, usize, bool)> = VecDeque::new();
+    queue.push_back((seed_key, 0, true));
+    queue.push_back((seed_key, 0, false));
+
+    while let Some((key, depth, caller_direction)) = queue.pop_front() {
+        if depth >= max_depth {
+            continue;
+        }
+        let adjacency = if caller_direction { &reverse } else { &forward };
+        let Some(neighbors) = adjacency.get(key) else {
+            continue;
+        };
+        for &neighbor in neighbors {
+            if !visited.insert(neighbor) {
+                continue;
+            }
+            let next_depth = depth + 1;
+            let is_test = caller_direction && test_functions.contains(neighbor);
+            let (relation, score) = match (next_depth, caller_direction) {
+                (1, true) if is_test => ("test", DIRECT_TEST_RELEVANCE_SCORE),
+                (d, true) if is_test => ("test", (0.7 - 0.1 * (d - 2) as f64).max(0.1)),
+                (1, true) => ("caller", DIRECT_CALLER_RELEVANCE_SCORE),
+                (1, false) => ("callee", DIRECT_CALLEE_RELEVANCE_SCORE),
+                (d, true) => ("transitive_caller", (0.7 - 0.1 * (d - 2) as f64).max(0.1)),
+                (d, false) => ("transitive_callee", (0.7 - 0.1 * (d - 2) as f64).max(0.1)),
+            };
+            ranked.push(RankedNeighborEntityEntry {
+                entity_key: neighbor.to_string(),
+                relation: relation.to_string(),
+                depth: next_depth,
+                relevance_score: score,
+            });
+            queue.push_back((neighbor, next_depth, caller_direction));
+        }
+    }
+
+    ranked.sort_by(|a, b| {
+        b.relevance_score
+            .partial_cmp(&a.relevance_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+    ranked
+}
+
+/// Add a centrality boost to every non-seed entry and re-sort
+///
+/// # 4-Word Name: apply_centrality_relevance_boost
+///
+/// # Contract
+/// - Precondition: `importance` values in [0, 1] (see
+///   `symbol_centrality_score_ranker`); missing keys get no boost
+/// - Postcondition: seeds (depth 0) are not boosted and stay first; order
+///   otherwise (score desc, key asc)
+pub fn apply_centrality_relevance_boost(ranked: &mut [RankedNeighborEntityEntry], importance: &HashMap<String, f64>) {
+    for entry in ranked.iter_mut().filter(|e| e.depth > 0) {
+        let boost = importance.get(&entry.entity_key).copied().unwrap_or(0.0).clamp(0.0, 1.0);
+        entry.relevance_score += CENTRALITY_RELEVANCE_BOOST_WEIGHT * boost;
+    }
+    ranked.sort_by(|a, b| {
+        b.relevance_score
+            .partial_cmp(&a.relevance_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+}
+
+/// Rank generated (non-seed) entities below hand-written ones and re-sort
+///
+/// # 4-Word Name: apply_generated_output_penalty
+///
+/// # Contract
+/// - Precondition: `metadata` maps entity keys to their extractor metadata
+/// - Postcondition: entries with `generated: true` and depth > 0 lose
+///   `GENERATED_OUTPUT_RELEVANCE_PENALTY` (floor 0.01); order (score desc, key asc)
+pub fn apply_generated_output_penalty(
+    ranked: &mut [RankedNeighborEntityEntry],
+    metadata: &HashMap<String, HashMap<String, String>>,
+) {
+    for entry in ranked.iter_mut().filter(|e| e.depth > 0) {
+        let generated = metadata
+            .get(&entry.entity_key)
+            .is_some_and(|additional| additional.get(GENERATED_METADATA_KEY).map(String::as_str) == Some("true"));
+        if generated {
+            entry.relevance_score = (entry.relevance_score - GENERATED_OUTPUT_RELEVANCE_PENALTY).max(0.01);
+        }
+    }
+    ranked.sort_by(|a, b| {
+        b.relevance_score
+            .partial_cmp(&a.relevance_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+}
+
+/// Extract a declaration signature from entity source
+///
+/// # 4-Word Name: extract_signature_from_code
+///
+/// Takes source up to the opening `{` (or `:` line end for Python-style
+/// blocks), at most 4 lines. Falls back to the first line.
+pub fn extract_signature_from_code(code: &str) -> String {
+    let mut signature = String::new();
+    for line in code.lines().take(4) {
+        if let Some(pos) = line.find('{') {
+            signature.push_str(line[..pos].trim_end());
+            return signature;
+        }
+        signature.push_str(line);
+        if line.trim_end().ends_with(':') || line.trim_end().ends_with(';') {
+            return signature;
+        }
+        signature.push('\n');
+    }
+    code.lines().next().unwrap_or_default().trim_end().to_string()
+}
+
+/// One entity in the packed bundle
+///
+/// # 4-Word Name: PackedContextItemEntry
+#[derive(Debug, Clone, Serialize)]
+pub struct PackedContextItemEntry {
+    pub entity_key: String,
+    pub relation: String,
+    pub relevance_score: f64,
+    pub file_path: String,
+    /// Main author of the symbol (`owner` from git blame), when annotated
+    pub owner: Option<String>,
+    /// Owner teams from CODEOWNERS (`code_owners`), comma-separated (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub code_owners: Option<String>,
+    /// Doc comment captured at ingest (v1.7.3)
+    pub documentation: Option<String>,
+    /// Source of a generated entity (`generated_from`), the file to edit (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub generated_from: Option<String>,
+    /// LLM summary packed in place of the body (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub summary: Option<String>,
+    /// True when `text` holds the full body, false for signature only
+    pub body_included: bool,
+    /// Leading chunks of a body too large to fit whole (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub chunks: Option<EmittedBodyChunkRecord>,
+    pub text: String,
+    /// Tokens this item adds to the rendered bundle, in the bundle's tokenizer
+    pub tokens: usize,
+}
+
+impl PackedContextItemEntry {
+    /// What the item holds: `body`, `summary`, `chunks` or `signature`
+    ///
+    /// # 4-Word Name: packed_content_kind_label
+    pub fn packed_content_kind_label(&self) -> &'static str {
+        match &self.chunks {
+            Some(_) => "chunks",
+            None if self.body_included => "body",
+            None if self.summary.is_some() => "summary",
+            None => "signature",
+        }
+    }
+}
+
+/// Line window of an oversized body
+///
+/// # 4-Word Name: BodyChunkLineSpan
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct BodyChunkLineSpan {
+    /// 1-based chunk number
+    pub number: usize,
+    /// First source line (1-based, inclusive)
+    pub line_start: usize,
+    /// Last source line (1-based, inclusive)
+    pub line_end: usize,
+}
+
+/// Chunks of a body held by a packed item
+///
+/// # 4-Word Name: EmittedBodyChunkRecord
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct EmittedBodyChunkRecord {
+    /// Chunk numbers in `text`: always `1..=n` for some `n < spans.len()`
+    pub emitted: Vec<usize>,
+    /// Every chunk of the body, emitted or not
+    pub spans: Vec<BodyChunkLineSpan>,
+}
+
+/// Split a body into overlapping line windows
+///
+/// # 4-Word Name: split_body_into_chunk_spans
+///
+/// # Contract
+/// - Precondition: `first_line` is the 1-based line of the body's first line
+/// - Postcondition: spans cover every line; consecutive spans share
+///   `BODY_CHUNK_OVERLAP_LINES` lines; a body of at most
+///   `BODY_CHUNK_LINE_COUNT` lines is one span
+pub fn split_body_into_chunk_spans(line_count: usize, first_line: usize) -> Vec<BodyChunkLineSpan> {
+    let step = BODY_CHUNK_LINE_COUNT - BODY_CHUNK_OVERLAP_LINES;
+    let mut spans = Vec::new();
+    let mut start = 0;
+    while start < line_count {
+        let end = (start + BODY_CHUNK_LINE_COUNT).min(line_count);
+        spans.push(BodyChunkLineSpan {
+            number: spans.len() + 1,
+            line_start: first_line + start,
+            line_end: first_line + end - 1,
+        });
+        if end == line_count {
+            break;
+        }
+        start += step;
+    }
+    spans
+}
+
+/// Text of `spans[0]..=spans[last]` under a line-number header
+///
+/// # 4-Word Name: render_body_chunk_text
+///
+/// # Contract
+/// - Precondition: `spans` is a non-empty consecutive run from
+///   `split_body_into_chunk_spans(.., first_line)` over `code`
+/// - Postcondition: `// lines A-B of F-L` followed by lines A..=B, without
+///   repeating the overlap between the spans
+pub fn render_body_chunk_text(code: &str, first_line: usize, spans: &[BodyChunkLineSpan]) -> String {
+    let lines: Vec<&str> = code.trim_end().lines().collect();
+    let (Some(first), Some(last)) = (spans.first(), spans.last()) else {
+        return String::new();
+    };
+    let selected = lines
+        .get(first.line_start - first_line..=last.line_end - first_line)
+        .unwrap_or_default();
+    format!(
+        "// lines {}-{} of {}-{}\n{}",
+        first.line_start,
+        last.line_end,
+        first_line,
+        first_line + lines.len().saturating_sub(1),
+        selected.join("\n")
+    )
+}
+
+/// Packed context bundle
+///
+/// # 4-Word Name: PackedContextBundleResult
+#[derive(Debug, Clone, Serialize)]
+pub struct PackedContextBundleResult {
+    pub seed_key: String,
+    pub token_budget: usize,
+    /// Exact token count of `render_packed_context_text`
+    pub tokens_used: usize,
+    pub candidates_considered: usize,
+    pub items: Vec<PackedContextItemEntry>,
+    /// Team whose out-of-ownership symbols are listed after the items (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub ownership_team: Option<String>,
+    /// Tokenizer of every count in the bundle (v1.7.3)
+    pub tokenizer: ContextTokenizerEncodingKind,
+    /// What the graph walk left out; set by the storage packers (v1.7.3)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub truncation: Option<TraversalTruncationMarkerReport>,
+}
+
+impl PackedContextBundleResult {
+    /// Packed items owned by teams other than `ownership_team`
+    ///
+    /// # 4-Word Name: outside_team_ownership_items
+    ///
+    /// # Contract
+    /// - Postcondition: empty without a team; unowned items never listed
+    pub fn outside_team_ownership_items(&self) -> Vec<&PackedContextItemEntry> {
+        let Some(team) = self.ownership_team.as_deref() else {
+            return Vec::new();
+        };
+        self.items
+            .iter()
+            .filter(|item| item.code_owners.as_deref().is_some_and(|owners| is_outside_team_ownership(owners, team)))
+            .collect()
+    }
+}
+
+/// Render one item as prompt text
+fn render_item_block_text(item: &PackedContextItemEntry) -> String {
+    let content = match &item.chunks {
+        Some(chunks) => format!("chunks 1-{} of {}", chunks.emitted.len(), chunks.spans.len()),
+        None => item.packed_content_kind_label().to_string(),
+    };
+    let owner = item.owner.as_deref().map(|o| format!(" [owner: {}]", o)).unwrap_or_default();
+    let code_owners = item
+        .code_owners
+        .as_deref()
+        .map(|owners| format!(" [code owners: {}]", owners.replace(',', ", ")))
+        .unwrap_or_default();
+    let generated = item
+        .generated_from
+        .as_deref()
+        .map(|from| format!(" [generated from {}]", from))
+        .unwrap_or_default();
+    let documentation: String = item
+        .documentation
+        .iter()
+        .flat_map(|doc| doc.lines())
+        .map(|line| format!("{}\n", format!("// {}", line).trim_end()))
+        .collect();
+    let summary = item.summary.as_deref().map(|s| format!("// summary: {}\n", s)).unwrap_or_default();
+    format!(
+        "// {} ({}, {}) {}{}{}{}\n{}{}{}\n\n",
+        item.entity_key,
+        item.relation,
+        content,
+        item.file_path,
+        owner,
+        code_owners,
+        generated,
+        documentation,
+        summary,
+        item.text
+    )
+}
+
+/// Render the bundle as prompt text
+///
+/// # 4-Word Name: render_packed_context_text
+pub fn render_packed_context_text(bundle: &PackedContextBundleResult) -> String {
+    let mut text: String = bundle.items.iter().map(render_item_block_text).collect();
+    let outside = bundle.outside_team_ownership_items();
+    if let (Some(team), false) = (bundle.ownership_team.as_deref(), outside.is_empty()) {
+        text.push_str(&format!("// Outside {} ownership:\n", team));
+        for item in outside {
+            text.push_str(&format!(
+                "// - {} ({}) owned by {}\n",
+                item.entity_key,
+                item.file_path,
+                item.code_owners.as_deref().unwrap_or_default().replace(',', ", ")
+            ));
+        }
+    }
+    text
+}
+
+/// Set the bundle's team and re-fit it to its budget
+///
+/// # 4-Word Name: apply_ownership_team_to_bundle
+///
+/// # Contract
+/// - Postcondition: `ownership_team == Some(team)`; lowest-ranked items are
+///   dropped until the rendered output, ownership section included, fits
+///   `token_budget` again; `tokens_used` is recounted
+pub fn apply_ownership_team_to_bundle(
+    bundle: &mut PackedContextBundleResult,
+    team: &str,
+    template: &ContextPackOutputTemplate,
+) {
+    bundle.ownership_team = Some(team.to_string());
+    fit_bundle_to_rendered_budget(bundle, template);
+}
+
+/// Drop lowest-ranked items until the exact rendered output fits
+fn fit_bundle_to_rendered_budget(bundle: &mut PackedContextBundleResult, template: &ContextPackOutputTemplate) {
+    loop {
+        bundle.tokens_used = count_tokens_with_encoding(&template.render_context_pack_bundle(bundle), bundle.tokenizer);
+        if bundle.tokens_used <= bundle.token_budget || bundle.items.is_empty() {
+            break;
+        }
+        bundle.items.pop();
+    }
+}
+
+/// Pack ranked entities into a token budget
+///
+/// # 4-Word Name: pack_ranked_entities_within_budget
+///
+/// # Contract
+/// - Precondition: `ranked` from `rank_neighbors_by_relevance`; `entities`
+///   holds code for (a subset of) the ranked keys
+/// - Postcondition: `count_text_tokens_exactly(render_packed_context_text(..))
+///   == tokens_used <= budget`; signatures of higher-ranked entities are never
+///   displaced by bodies of lower-ranked ones; item order follows rank
+pub fn pack_ranked_entities_within_budget(
+    seed_key: &str,
+    ranked: &[RankedNeighborEntityEntry],
+    entities: &HashMap<String, CodeEntity>,
+    budget: usize,
+) -> PackedContextBundleResult {
+    pack_ranked_entities_with_template(seed_key, ranked, entities, budget, &ContextPackOutputTemplate::Plain)
+}
+
+/// Pack ranked entities into a budget measured in template output
+///
+/// # 4-Word Name: pack_ranked_entities_with_template
+///
+/// # Contract
+/// - Postcondition: as `pack_ranked_entities_within_budget`, with
+///   `tokens_used` counting `template.render_context_pack_bundle(..)`
+/// - Edge case: a template whose fixed text alone exceeds `budget` yields
+///   an empty bundle with `tokens_used > budget`
+pub fn pack_ranked_entities_with_template(
+    seed_key: &str,
+    ranked: &[RankedNeighborEntityEntry],
+    entities: &HashMap<String, CodeEntity>,
+    budget: usize,
+    template: &ContextPackOutputTemplate,
+) -> PackedContextBundleResult {
+    pack_ranked_entities_with_tokenizer(seed_key, ranked, entities, budget, template, Default::default())
+}
+
+/// Pack ranked entities into a budget counted by `tokenizer`
+///
+/// # 4-Word Name: pack_ranked_entities_with_tokenizer
+///
+/// # Contract
+/// - Postcondition: as `pack_ranked_entities_with_template`, every count
+///   (items, overhead, `tokens_used`) taken with `tokenizer`
+pub fn pack_ranked_entities_with_tokenizer(
+    seed_key: &str,
+    ranked: &[RankedNeighborEntityEntry],
+    entities: &HashMap<String, CodeEntity>,
+    budget: usize,
+    template: &ContextPackOutputTemplate,
+    tokenizer: ContextTokenizerEncodingKind,
+) -> PackedContextBundleResult {
+    let candidates: Vec<(&RankedNeighborEntityEntry, &CodeEntity, &str)> = ranked
+        .iter()
+        .filter_map(|r| {
+            let entity = entities.get(&r.entity_key)?;
+            let code = entity.current_code.as_deref()?;
+            Some((r, entity, code))
+        })
+        .collect();
+
+    let render_items = |items: Vec<PackedContextItemEntry>| {
+        template.render_context_pack_bundle(&PackedContextBundleResult {
+            seed_key: seed_key.to_string(),
+            token_budget: budget,
+            tokens_used: 0,
+            candidates_considered: candidates.len(),
+            items,
+            ownership_team: None,
+            tokenizer,
+            truncation: None,
+        })
+    };
+    // Fixed template text (headers, wrappers) is paid once
+    let overhead = match template {
+        ContextPackOutputTemplate::Plain => 0,
+        ContextPackOutputTemplate::Parsed(_) => count_tokens_with_encoding(&render_items(Vec::new()), tokenizer),
+    };
+
+    let make_item = |rank: &RankedNeighborEntityEntry,
+                     entity: &CodeEntity,
+                     text: String,
+                     body: bool,
+                     chunks: Option<EmittedBodyChunkRecord>,
+                     summary: Option<String>| {
+        let mut item = PackedContextItemEntry {
+            entity_key: rank.entity_key.clone(),
+            relation: rank.relation.clone(),
+            relevance_score: rank.relevance_score,
+            file_path: entity.interface_signature.file_path.display().to_string(),
+            owner: entity.metadata.additional.get(OWNER_METADATA_KEY).cloned(),
+            code_owners: entity.metadata.additional.get(CODE_OWNERS_METADATA_KEY).cloned(),
+            documentation: entity.interface_signature.documentation.clone(),
+            generated_from: entity.metadata.additional.get(GENERATED_FROM_METADATA_KEY).cloned(),
+            summary,
+            body_included: body,
+            chunks,
+            text,
+            tokens: 0,
+        };
+        item.tokens = match template {
+            ContextPackOutputTemplate::Plain => count_tokens_with_encoding(&render_item_block_text(&item), tokenizer),
+            ContextPackOutputTemplate::Parsed(_) => {
+                count_tokens_with_encoding(&render_items(vec![item.clone()]), tokenizer).saturating_sub(overhead)
+            }
+        };
+        item
+    };
+
+    // Pass 1: signatures in rank order
+    let mut slots: Vec<Option<PackedContextItemEntry>> = vec![None; candidates.len()];
+    let mut used = overhead;
+    for (slot, (rank, entity, code)) in slots.iter_mut().zip(&candidates) {
+        let signature = match entity.metadata.additional.get(SIGNATURE_METADATA_KEY) {
+            Some(stored) => stored.clone(),
+            None => extract_signature_from_code(code),
+        };
+        let item = make_item(rank, entity, signature, false, None, None);
+        if used + item.tokens <= budget {
+            used += item.tokens;
+            *slot = Some(item);
+        }
+    }
+
+    // Generated entities other than seeds stay signatures
+    let is_generated_output = |rank: &RankedNeighborEntityEntry, entity: &CodeEntity| {
+        rank.depth > 0 && entity.metadata.additional.get(GENERATED_METADATA_KEY).map(String::as_str) == Some("true")
+    };
+
+    // Pass 2: upgrade to bodies in rank order
+    for (slot, (rank, entity, code)) in slots.iter_mut().zip(&candidates) {
+        let Some(current) = slot.as_ref() else {
+            continue;
+        };
+        if is_generated_output(rank, entity) {
+            continue;
+        }
+        let body_item = make_item(rank, entity, code.trim_end().to_string(), true, None, None);
+        if body_item.tokens > current.tokens && used - current.tokens + body_item.tokens <= budget {
+            used = used - current.tokens + body_item.tokens;
+            *slot = Some(body_item);
+        }
+    }
+
+    // Pass 3: summaries in place of bodies that did not fit
+    for (slot, (rank, entity, _)) in slots.iter_mut().zip(&candidates) {
+        let Some(current) = slot.as_ref() else {
+            continue;
+        };
+        let Some(summary) = entity.metadata.additional.get(SUMMARY_METADATA_KEY) else {
+            continue;
+        };
+        if current.body_included {
+            continue;
+        }
+        let summary_item = make_item(rank, entity, current.text.clone(), false, None, Some(summary.clone()));
+        if used - current.tokens + summary_item.tokens <= budget {
+            used = used - current.tokens + summary_item.tokens;
+            *slot = Some(summary_item);
+        }
+    }
+
+    // Pass 4: leading chunks of bodies that did not fit whole
+    for (slot, (rank, entity, code)) in slots.iter_mut().zip(&candidates) {
+        let Some(current) = slot.as_ref() else {
+            continue;
+        };
+        if current.body_included || current.summary.is_some() || is_generated_output(rank, entity) {
+            continue;
+        }
+        let first_line = entity.interface_signature.line_range.start.max(1) as usize;
+        let spans = split_body_into_chunk_spans(code.trim_end().lines().count(), first_line);
+        let mut best: Option<PackedContextItemEntry> = None;
+        // All chunks together would be the whole body, which did not fit
+        for count in 1..spans.len() {
+            let record = EmittedBodyChunkRecord { emitted: (1..=count).collect(), spans: spans.clone() };
+            let text = render_body_chunk_text(code, first_line, &spans[..count]);
+            let chunk_item = make_item(rank, entity, text, false, Some(record), None);
+            if used - current.tokens + chunk_item.tokens > budget {
+                break;
+            }
+            best = Some(chunk_item);
+        }
+        if let Some(chunk_item) = best.filter(|item| item.tokens > current.tokens) {
+            used = used - current.tokens + chunk_item.tokens;
+            *slot = Some(chunk_item);
+        }
+    }
+
+    let mut bundle = PackedContextBundleResult {
+        seed_key: seed_key.to_string(),
+        token_budget: budget,
+        tokens_used: 0,
+        candidates_considered: candidates.len(),
+        items: slots.into_iter().flatten().collect(),
+        ownership_team: None,
+        tokenizer,
+        truncation: None,
+    };
+
+    // Final guarantee on the exact rendered output
+    fit_bundle_to_rendered_budget(&mut bundle, template);
+    bundle
+}
+
+/// Build a context pack straight from the database
+///
+/// # 4-Word Name: build_context_pack_from_storage
+///
+/// # Contract
+/// - Precondition: `seed_key` is an ISGL1 key present in CodeGraph
+///   (see `CozoDbStorage::find_entity_keys_by_symbol`)
+/// - Postcondition: same guarantee as `pack_ranked_entities_within_budget`;
+///   at most `MAX_PACK_CANDIDATE_ENTITIES` ranked entities are loaded
+/// - Postcondition: without `include_tests`, no test entity (other than the
+///   seed) is packed and `Tests` edges are not followed
+/// - Postcondition: the budget is measured in `template` output, counted by
+///   `tokenizer`; `recency` boosts recently changed / same-ticket symbols
+/// - Postcondition: with `expand_impls`, interface calls add at most that
+///   many implementations each instead of every fanned-out implementer
+///   (see `interface_impl_pack_expander`)
+/// - Postcondition: ranking walks `limits.max_depth` hops, capped by
+///   `limits.max_fanout` / `limits.max_nodes`; the bundle's `truncation`
+///   records the cuts
+#[allow(clippy::too_many_arguments)]
+pub async fn build_context_pack_from_storage(
+    storage: &CozoDbStorage,
+    seed_key: &str,
+    budget: usize,
+    limits: TraversalExpansionLimitSpec,
+    include_tests: bool,
+    template: &ContextPackOutputTemplate,
+    tokenizer: ContextTokenizerEncodingKind,
+    recency: Option<&RecencyScoringConfigSpec>,
+    expand_impls: Option<usize>,
+) -> Result<PackedContextBundleResult> {
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let edges = load_packing_edges_from_storage(storage, include_tests).await?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Undirected, &mut truncation);
+    let mut ranked = match expand_impls {
+        Some(limit) => {
+            let (static_edges, dynamic_edges) = split_dynamic_dispatch_edges(edges);
+            let rank = |depth| rank_neighbors_by_relevance(seed_key, &static_edges, depth);
+            let mut ranked = rank_within_depth_limit(rank, &mut truncation);
+            expand_interface_method_implementations(&mut ranked, &dynamic_edges, limit);
+            ranked
+        }
+        None => rank_within_depth_limit(|depth| rank_neighbors_by_relevance(seed_key, &edges, depth), &mut truncation),
+    };
+    truncation.truncate_entries_to_node_limit(&mut ranked);
+    let mut bundle = pack_ranked_entities_from_storage(
+        storage,
+        seed_key,
+        ranked,
+        budget,
+        include_tests,
+        template,
+        tokenizer,
+        recency,
+    )
+    .await?;
+    bundle.truncation = Some(truncation);
+    Ok(bundle)
+}
+
+/// Rank at `max_depth` and count what one more hop would add
+///
+/// # 4-Word Name: rank_within_depth_limit
+///
+/// # Contract
+/// - Postcondition: `rank(report.limits.max_depth)`, after recording the
+///   entries `rank(max_depth + 1)` has beyond it
+pub fn rank_within_depth_limit(
+    rank: impl Fn(usize) -> Vec<RankedNeighborEntityEntry>,
+    report: &mut TraversalTruncationMarkerReport,
+) -> Vec<RankedNeighborEntityEntry> {
+    let max_depth = report.limits.max_depth;
+    let ranked = rank(max_depth);
+    if max_depth < usize::MAX {
+        report.record_beyond_depth_count(rank(max_depth + 1).len().saturating_sub(ranked.len()));
+    }
+    ranked
+}
+
+/// Rank entities around several seeds at once
+///
+/// # 4-Word Name: rank_neighbors_of_seed_set
+///
+/// # Contract
+/// - Postcondition: every seed first (relation `seed`, depth 0); every other
+///   key once, with its best score over all seeds; sorted like
+///   `rank_neighbors_by_relevance`
+pub fn rank_neighbors_of_seed_set(
+    seed_keys: &[String],
+    edges: &[DependencyEdge],
+    max_depth: usize,
+) -> Vec<RankedNeighborEntityEntry> {
+    let mut best: HashMap<String, RankedNeighborEntityEntry> = HashMap::new();
+    for seed in seed_keys {
+        for entry in rank_neighbors_by_relevance(seed, edges, max_depth) {
+            let better_known = best
+                .get(&entry.entity_key)
+                .is_some_and(|current| current.relevance_score >= entry.relevance_score);
+            if !better_known {
+                best.insert(entry.entity_key.clone(), entry);
+            }
+        }
+    }
+    for seed in seed_keys {
+        best.insert(
+            seed.clone(),
+            RankedNeighborEntityEntry {
+                entity_key: seed.clone(),
+                relation: "seed".to_string(),
+                depth: 0,
+                relevance_score: SEED_ENTITY_RELEVANCE_SCORE,
+            },
+        );
+    }
+
+    let mut ranked: Vec<RankedNeighborEntityEntry> = best.into_values().collect();
+    ranked.sort_by(|a, b| {
+        b.relevance_score
+            .partial_cmp(&a.relevance_score)
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.entity_key.cmp(&b.entity_key))
+    });
+    ranked
+}
+
+/// Dependency edges used for packing (`Tests` edges only with tests)
+///
+/// # 4-Word Name: load_packing_edges_from_storage
+pub async fn load_packing_edges_from_storage(
+    storage: &CozoDbStorage,
+    include_tests: bool,
+) -> Result<Vec<DependencyEdge>> {
+    let mut edges = storage.get_all_dependencies().await?;
+    if !include_tests {
+        edges.retain(|e| e.edge_type != EdgeType::Tests);
+    }
+    Ok(edges)
+}
+
+/// Boost by centrality, load code and pack a ranking into the budget
+///
+/// # 4-Word Name: pack_ranked_entities_from_storage
+///
+/// # Contract
+/// - Precondition: `ranked` seeds have depth 0; `label` becomes `seed_key`
+/// - Postcondition: as `build_context_pack_from_storage`; test entities are
+///   skipped unless `include_tests` or they are a seed
+#[allow(clippy::too_many_arguments)]
+pub async fn pack_ranked_entities_from_storage(
+    storage: &CozoDbStorage,
+    label: &str,
+    mut ranked: Vec<RankedNeighborEntityEntry>,
+    budget: usize,
+    include_tests: bool,
+    template: &ContextPackOutputTemplate,
+    tokenizer: ContextTokenizerEncodingKind,
+    recency: Option<&RecencyScoringConfigSpec>,
+) -> Result<PackedContextBundleResult> {
+    let importance = load_symbol_importance_lookup_map(storage).await?;
+    apply_centrality_relevance_boost(&mut ranked, &importance);
+    let mut metadata = storage.get_entity_extractor_metadata_map().await?;
+    if let Some(config) = recency {
+        apply_recency_relevance_boost(&mut ranked, &metadata, config);
+    }
+    apply_generated_output_penalty(&mut ranked, &metadata);
+    ranked.truncate(MAX_PACK_CANDIDATE_ENTITIES);
+
+    let mut entities = HashMap::new();
+    for entry in &ranked {
+        // Unresolved/external targets have no CodeGraph row: skip them
+        if let Ok(mut entity) = storage.get_entity(&entry.entity_key).await {
+            if let Some(additional) = metadata.remove(&entry.entity_key) {
+                entity.metadata.additional = additional;
+            }
+            if !include_tests && entity.entity_class == EntityClass::TestImplementation && entry.depth > 0 {
+                continue;
+            }
+            entities.insert(entry.entity_key.clone(), entity);
+        }
+    }
+
+    Ok(pack_ranked_entities_with_tokenizer(label, &ranked, &entities, budget, template, tokenizer))
+}
+
+/// One chunk of a stored body, fetched after a pack
+///
+/// # 4-Word Name: FetchedBodyChunkResult
+#[derive(Debug, Clone, Serialize)]
+pub struct FetchedBodyChunkResult {
+    pub entity_key: String,
+    pub chunk: BodyChunkLineSpan,
+    pub chunk_count: usize,
+    /// `render_body_chunk_text` of the chunk
+    pub text: String,
+}
+
+/// Fetch chunk `number` of an entity body, as a pack would cut it
+///
+/// # 4-Word Name: load_body_chunk_from_storage
+///
+/// # Contract
+/// - Postcondition: spans match the `chunks.spans` a pack recorded for the
+///   same entity
+/// - Error: `EntityNotFound` for unknown keys; `ParseError` when the entity
+///   has no stored code or `number` is outside `1..=chunk_count`
+pub async fn load_body_chunk_from_storage(
+    storage: &CozoDbStorage,
+    entity_key: &str,
+    number: usize,
+) -> Result<FetchedBodyChunkResult> {
+    let entity = storage.get_entity(entity_key).await?;
+    let code = entity.current_code.as_deref().ok_or_else(|| ParseltongError::ParseError {
+        reason: "entity has no stored code".to_string(),
+        location: entity_key.to_string(),
+    })?;
+    let first_line = entity.interface_signature.line_range.start.max(1) as usize;
+    let spans = split_body_into_chunk_spans(code.trim_end().lines().count(), first_line);
+    let chunk = spans
+        .get(number.wrapping_sub(1))
+        .cloned()
+        .ok_or_else(|| ParseltongError::ParseError {
+            reason: format!("chunk {} out of range 1-{}", number, spans.len()),
+            location: entity_key.to_string(),
+        })?;
+    Ok(FetchedBodyChunkResult {
+        entity_key: entity_key.to_string(),
+        text: render_body_chunk_text(code, first_line, std::slice::from_ref(&chunk)),
+        chunk,
+        chunk_count: spans.len(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder()
+            .from_key(from)
+            .to_key(to)
+            .edge_type(EdgeType::Calls)
+            .build()
+            .unwrap()
+    }
+
+    fn entity_with_code(key: &str, code: &str) -> CodeEntity {
+        let mut entity = create_go_test_entity(key, key, EntityType::Function, "svc/a.go", &[]);
+        entity.current_code = Some(code.to_string());
+        entity
+    }
+
+    #[test]
+    fn test_token_count_is_not_bytes() {
+        let text = "fn main() { println!(\"hello world\"); }";
+        let tokens = count_text_tokens_exactly(text);
+        assert!(tokens > 0 && tokens < text.len());
+    }
+
+    #[test]
+    fn test_rank_orders_callers_callees_transitive() {
+        let edges = vec![edge("caller", "seed"), edge("seed", "callee"), edge("callee", "deep")];
+        let ranked = rank_neighbors_by_relevance("seed", &edges, 2);
+        let keys: Vec<&str> = ranked.iter().map(|r| r.entity_key.as_str()).collect();
+        assert_eq!(keys, vec!["seed", "caller", "callee", "deep"]);
+        assert_eq!(ranked[3].relation, "transitive_callee");
+
+        assert_eq!(rank_neighbors_by_relevance("seed", &edges, 1).len(), 3);
+    }
+
+    #[test]
+    fn test_rank_limits_record_depth_and_node_cuts() {
+        let edges = vec![edge("caller", "seed"), edge("seed", "callee"), edge("callee", "deep")];
+        let limits = TraversalExpansionLimitSpec { max_depth: 1, max_fanout: None, max_nodes: Some(2) };
+        let mut report = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+        let rank = |depth| rank_neighbors_by_relevance("seed", &edges, depth);
+        let mut ranked = rank_within_depth_limit(rank, &mut report);
+        report.truncate_entries_to_node_limit(&mut ranked);
+
+        let keys: Vec<&str> = ranked.iter().map(|r| r.entity_key.as_str()).collect();
+        assert_eq!(keys, vec!["seed", "caller"]);
+        assert_eq!((report.beyond_depth_count, report.nodes_omitted), (1, 1));
+        assert!(report.truncated);
+    }
+
+    #[test]
+    fn test_rank_marks_covering_tests() {
+        let tests_edge = DependencyEdge::builder()
+            .from_key("TestSeed")
+            .to_key("seed")
+            .edge_type(EdgeType::Tests)
+            .build()
+            .unwrap();
+        let edges = vec![edge("TestSeed", "seed"), tests_edge, edge("caller", "seed")];
+        let ranked = rank_neighbors_by_relevance("seed", &edges, 2);
+        let relations: Vec<(&str, &str)> = ranked
+            .iter()
+            .map(|r| (r.entity_key.as_str(), r.relation.as_str()))
+            .collect();
+        assert_eq!(relations, vec![("seed", "seed"), ("caller", "caller"), ("TestSeed", "test")]);
+    }
+
+    #[test]
+    fn test_centrality_orders_within_tier() {
+        let edges = vec![edge("caller", "seed"), edge("seed", "a_leaf"), edge("seed", "b_hub")];
+        let mut ranked = rank_neighbors_by_relevance("seed", &edges, 1);
+        let importance = HashMap::from([("b_hub".to_string(), 1.0), ("seed".to_string(), 1.0)]);
+        apply_centrality_relevance_boost(&mut ranked, &importance);
+        let keys: Vec<&str> = ranked.iter().map(|r| r.entity_key.as_str()).collect();
+        // The hub callee leads the callees but stays behind the caller
+        assert_eq!(keys, vec!["seed", "caller", "b_hub", "a_leaf"]);
+        assert_eq!(ranked[0].relevance_score, SEED_ENTITY_RELEVANCE_SCORE);
+    }
+
+    #[test]
+    fn test_seed_set_keeps_best_score_per_key() {
+        let edges = vec![edge("a", "shared"), edge("b", "mid"), edge("mid", "shared"), edge("a", "b")];
+        let seeds = vec!["a".to_string(), "b".to_string()];
+        let ranked = rank_neighbors_of_seed_set(&seeds, &edges, 2);
+        let summary: Vec<(&str, &str)> = ranked
+            .iter()
+            .map(|r| (r.entity_key.as_str(), r.relation.as_str()))
+            .collect();
+        // b is a callee of a but stays a seed; shared is a direct callee of a
+        assert_eq!(summary, vec![("a", "seed"), ("b", "seed"), ("mid", "callee"), ("shared", "callee")]);
+    }
+
+    #[test]
+    fn test_signature_stops_at_brace() {
+        assert_eq!(extract_signature_from_code("pub fn add(a: i32) -> i32 {\n    a\n}"), "pub fn add(a: i32) -> i32");
+        assert_eq!(extract_signature_from_code("def run(x):\n    return x"), "def run(x):");
+    }
+
+    #[test]
+    fn test_pack_fits_budget_signatures_first() {
+        let body = format!("func Seed() {{\n{}\n}}", "    doWork()\n".repeat(200));
+        let entities: HashMap<String, CodeEntity> = [
+            ("seed".to_string(), entity_with_code("seed", &body)),
+            ("callee".to_string(), entity_with_code("callee", "func Callee() {\n    return\n}")),
+        ]
+        .into_iter()
+        .collect();
+        let ranked = rank_neighbors_by_relevance("seed", &[edge("seed", "callee")], 2);
+
+        // Small budget: both signatures, no room for the big seed body
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 120);
+        assert!(bundle.tokens_used <= 120);
+        assert_eq!(bundle.items.len(), 2);
+        assert!(!bundle.items[0].body_included);
+        assert_eq!(count_text_tokens_exactly(&render_packed_context_text(&bundle)), bundle.tokens_used);
+
+        // Large budget: bodies included
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 100_000);
+        assert!(bundle.items.iter().all(|i| i.body_included));
+    }
+
+    #[test]
+    fn test_template_output_fits_budget() {
+        let entities: HashMap<String, CodeEntity> = (0..20)
+            .map(|i| {
+                let key = format!("n{}", i);
+                let code = format!("func N{}() {{\n    step()\n}}", i);
+                (key.clone(), entity_with_code(&key, &code))
+            })
+            .collect();
+        let edges: Vec<DependencyEdge> = (1..20).map(|i| edge("n0", &format!("n{}", i))).collect();
+        let ranked = rank_neighbors_by_relevance("n0", &edges, 1);
+        let template = ContextPackOutputTemplate::resolve_context_pack_template("claude-xml").unwrap();
+
+        let bundle = pack_ranked_entities_with_template("n0", &ranked, &entities, 300, &template);
+        assert!(!bundle.items.is_empty() && bundle.items.len() < 20);
+        assert_eq!(count_text_tokens_exactly(&template.render_context_pack_bundle(&bundle)), bundle.tokens_used);
+        assert!(bundle.tokens_used <= 300);
+    }
+
+    #[test]
+    fn test_ownership_section_lists_other_teams() {
+        let entities: HashMap<String, CodeEntity> = (0..10)
+            .map(|i| {
+                let key = format!("n{}", i);
+                let mut entity = entity_with_code(&key, &format!("func N{}() {{\n    step()\n}}", i));
+                let owners = if i % 2 == 0 { "@acme/payments" } else { "@acme/core" };
+                entity.metadata.additional.insert(CODE_OWNERS_METADATA_KEY.to_string(), owners.to_string());
+                (key, entity)
+            })
+            .collect();
+        let edges: Vec<DependencyEdge> = (1..10).map(|i| edge("n0", &format!("n{}", i))).collect();
+        let ranked = rank_neighbors_by_relevance("n0", &edges, 1);
+        let template = ContextPackOutputTemplate::Plain;
+
+        let mut bundle = pack_ranked_entities_within_budget("n0", &ranked, &entities, 100_000);
+        apply_ownership_team_to_bundle(&mut bundle, "@acme/payments", &template);
+        let text = render_packed_context_text(&bundle);
+        assert!(text.contains("// n1 (callee, body) svc/a.go [code owners: @acme/core]\n"));
+        assert!(text.contains("// Outside @acme/payments ownership:\n// - n1 (svc/a.go) owned by @acme/core\n"));
+        assert_eq!(bundle.outside_team_ownership_items().len(), 5);
+
+        // A budget the items alone fill: the section displaces the last items
+        let exact = pack_ranked_entities_within_budget("n0", &ranked, &entities, 100_000).tokens_used;
+        let mut tight = pack_ranked_entities_within_budget("n0", &ranked, &entities, exact);
+        apply_ownership_team_to_bundle(&mut tight, "@acme/payments", &template);
+        assert!(tight.tokens_used <= exact && tight.items.len() < 10);
+        assert_eq!(count_text_tokens_exactly(&render_packed_context_text(&tight)), tight.tokens_used);
+    }
+
+    #[test]
+    fn test_generated_output_ranks_last_as_signature() {
+        let edges = vec![edge("seed", "a_stringer"), edge("seed", "z_source")];
+        let mut ranked = rank_neighbors_by_relevance("seed", &edges, 1);
+        let metadata = HashMap::from([(
+            "a_stringer".to_string(),
+            HashMap::from([(GENERATED_METADATA_KEY.to_string(), "true".to_string())]),
+        )]);
+        apply_generated_output_penalty(&mut ranked, &metadata);
+        let keys: Vec<&str> = ranked.iter().map(|r| r.entity_key.as_str()).collect();
+        assert_eq!(keys, vec!["seed", "z_source", "a_stringer"]);
+
+        let mut generated = entity_with_code("a_stringer", "func (i Color) String() string {\n    return names[i]\n}");
+        generated.metadata.additional.insert(GENERATED_METADATA_KEY.to_string(), "true".to_string());
+        generated.metadata.additional.insert(GENERATED_FROM_METADATA_KEY.to_string(), "svc/color.go".to_string());
+        let entities: HashMap<String, CodeEntity> = [
+            ("seed".to_string(), entity_with_code("seed", "func Seed() {\n    paint()\n}")),
+            ("z_source".to_string(), entity_with_code("z_source", "type Color int")),
+            ("a_stringer".to_string(), generated),
+        ]
+        .into_iter()
+        .collect();
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 100_000);
+        let last = bundle.items.last().unwrap();
+        assert_eq!((last.entity_key.as_str(), last.body_included), ("a_stringer", false));
+        assert!(render_packed_context_text(&bundle).contains("[generated from svc/color.go]"));
+    }
+
+    #[test]
+    fn test_summary_stands_in_for_body_that_does_not_fit() {
+        let body = format!("func Seed() {{\n{}\n}}", "    doWork()\n".repeat(200));
+        let mut seed = entity_with_code("seed", &body);
+        seed.metadata.additional.insert(SUMMARY_METADATA_KEY.to_string(), "Runs the nightly batch.".to_string());
+        let entities: HashMap<String, CodeEntity> = [("seed".to_string(), seed)].into_iter().collect();
+        let ranked = rank_neighbors_by_relevance("seed", &[], 1);
+
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 120);
+        let item = &bundle.items[0];
+        assert_eq!(item.summary.as_deref(), Some("Runs the nightly batch."));
+        assert!(!item.body_included && item.chunks.is_none());
+        assert!(render_packed_context_text(&bundle).contains("(seed, summary)"));
+        assert!(bundle.tokens_used <= 120);
+
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 100_000);
+        assert!(bundle.items[0].body_included && bundle.items[0].summary.is_none());
+    }
+
+    #[test]
+    fn test_oversized_body_packs_leading_chunks() {
+        assert_eq!(split_body_into_chunk_spans(30, 10).len(), 1);
+        let spans = split_body_into_chunk_spans(100, 10);
+        assert_eq!(
+            spans.iter().map(|s| (s.line_start, s.line_end)).collect::<Vec<_>>(),
+            vec![(10, 49), (45, 84), (80, 109)]
+        );
+
+        let body: String = (0..150).map(|i| format!("    step{}()\n", i)).collect();
+        let entities: HashMap<String, CodeEntity> =
+            [("seed".to_string(), entity_with_code("seed", &body))].into_iter().collect();
+        let ranked = rank_neighbors_by_relevance("seed", &[], 1);
+        let bundle = pack_ranked_entities_within_budget("seed", &ranked, &entities, 400);
+
+        let item = &bundle.items[0];
+        let chunks = item.chunks.as_ref().expect("body chunked");
+        assert!(!item.body_included && !chunks.emitted.is_empty() && chunks.emitted.len() < chunks.spans.len());
+        assert!(item.text.starts_with("// lines 1-"));
+        assert!(item.text.contains("step0()") && !item.text.contains("step149()"));
+        assert!(bundle.tokens_used <= 400);
+    }
+}
@@ -0,0 +1,306 @@
+//! Traversal Expansion Limit Guards (v1.7.3)
+//!
+//! # 4-Word Naming: traversal_expansion_limit_guards
+//!
+//! The `--max-depth`, `--max-fanout` and `--max-nodes` guards shared by
+//! every traversal command (blast-radius, path, hierarchy, visualize, ...),
+//! so one god object with 5k callers cannot blow the token budget or the
+//! runtime, plus the truncation markers that say what was left out.
+//!
+//! ## Guards
+//!
+//! - `--max-depth`: hops from the start (the commands' `--depth`)
+//! - `--max-fanout`: neighbours followed per node in the walk direction.
+//!   Applied to the edge list before the walk, so every traversal obeys it
+//!   without knowing about it; the kept neighbours are the first ones in
+//!   key order, real entities before unresolved placeholders
+//! - `--max-nodes`: entries reported; results are ordered nearest first, so
+//!   the cut drops the farthest ones
+//!
+//! ## Markers
+//!
+//! `TraversalTruncationMarkerReport` goes into `--json` output as
+//! `truncation` and renders as `… ` lines after the text output. A report
+//! with nothing cut renders no lines.
+
+use std::collections::{BTreeMap, BTreeSet, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::DependencyEdge;
+use crate::filtered_graph_traversal_queries::is_placeholder_target_key;
+
+/// Capped nodes listed by name in text markers; the rest are counted
+pub const MAX_LISTED_FANOUT_MARKERS: usize = 10;
+
+/// Guard values of one traversal
+///
+/// # 4-Word Name: TraversalExpansionLimitSpec
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+pub struct TraversalExpansionLimitSpec {
+    pub max_depth: usize,
+    /// None = every neighbour
+    pub max_fanout: Option<usize>,
+    /// None = every entry
+    pub max_nodes: Option<usize>,
+}
+
+/// Which neighbours of a node the walk follows
+///
+/// # 4-Word Name: TraversalFanoutDirectionKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum TraversalFanoutDirectionKind {
+    /// `from -> to`: callees, dependencies
+    Outgoing,
+    /// `to <- from`: callers, dependents
+    Incoming,
+    /// Both ways (diagrams); an edge survives when both endpoints keep it
+    Undirected,
+}
+
+/// One node whose neighbours were capped
+///
+/// # 4-Word Name: FanoutTruncationMarkerEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct FanoutTruncationMarkerEntry {
+    pub entity_key: String,
+    pub shown_count: usize,
+    pub total_count: usize,
+}
+
+/// What a traversal left out, and why
+///
+/// # 4-Word Name: TraversalTruncationMarkerReport
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TraversalTruncationMarkerReport {
+    pub truncated: bool,
+    pub limits: TraversalExpansionLimitSpec,
+    /// Entries one hop beyond `max_depth`
+    pub beyond_depth_count: usize,
+    /// Capped nodes, most neighbours first
+    pub fanout_capped: Vec<FanoutTruncationMarkerEntry>,
+    /// Entries dropped by `max_nodes`
+    pub nodes_omitted: usize,
+}
+
+impl TraversalTruncationMarkerReport {
+    /// Empty report for a traversal run with `limits`
+    pub fn create_empty_marker_report(limits: TraversalExpansionLimitSpec) -> Self {
+        Self { truncated: false, limits, beyond_depth_count: 0, fanout_capped: Vec::new(), nodes_omitted: 0 }
+    }
+
+    /// Record how many entries the next hop would add
+    pub fn record_beyond_depth_count(&mut self, count: usize) {
+        self.beyond_depth_count = count;
+        self.truncated |= count > 0;
+    }
+
+    /// Record entries a walk bounded by `max_nodes` never reached
+    pub fn record_nodes_omitted_count(&mut self, count: usize) {
+        self.nodes_omitted += count;
+        self.truncated |= count > 0;
+    }
+
+    /// Cut `entries` (nearest first) to `max_nodes`, recording the cut
+    ///
+    /// # 4-Word Name: truncate_entries_to_node_limit
+    pub fn truncate_entries_to_node_limit<T>(&mut self, entries: &mut Vec<T>) {
+        if let Some(limit) = self.limits.max_nodes {
+            if entries.len() > limit {
+                self.record_nodes_omitted_count(entries.len() - limit);
+                entries.truncate(limit);
+            }
+        }
+    }
+
+    /// Cut one node's neighbours (kept-first order) to `max_fanout`, recording the cap
+    ///
+    /// # 4-Word Name: cap_node_neighbour_list
+    ///
+    /// # Contract
+    /// - For walks that expand node by node instead of over an edge list
+    ///   (the daemon's snapshot BFS); markers stay most neighbours first
+    pub fn cap_node_neighbour_list<T>(&mut self, entity_key: &str, neighbours: &mut Vec<T>) {
+        let Some(limit) = self.limits.max_fanout else {
+            return;
+        };
+        if neighbours.len() <= limit {
+            return;
+        }
+        let total_count = neighbours.len();
+        let position = self.fanout_capped.partition_point(|e| {
+            e.total_count > total_count || (e.total_count == total_count && e.entity_key.as_str() < entity_key)
+        });
+        let entry = FanoutTruncationMarkerEntry { entity_key: entity_key.to_string(), shown_count: limit, total_count };
+        self.fanout_capped.insert(position, entry);
+        self.truncated = true;
+        neighbours.truncate(limit);
+    }
+
+    /// Marker lines for text output; empty when nothing was cut
+    ///
+    /// # 4-Word Name: render_truncation_marker_lines
+    pub fn render_truncation_marker_lines(&self) -> Vec<String> {
+        let mut lines = Vec::new();
+        if self.beyond_depth_count > 0 {
+            lines.push(format!(
+                "… depth limit {} reached: {} more at hop {} (raise --max-depth)",
+                self.limits.max_depth,
+                self.beyond_depth_count,
+                self.limits.max_depth + 1
+            ));
+        }
+        if let (Some(limit), false) = (self.limits.max_fanout, self.fanout_capped.is_empty()) {
+            lines.push(format!(
+                "… fan-out capped at {} for {} nodes (raise --max-fanout):",
+                limit,
+                self.fanout_capped.len()
+            ));
+            for entry in self.fanout_capped.iter().take(MAX_LISTED_FANOUT_MARKERS) {
+                lines.push(format!("    {}  {} of {} neighbours", entry.entity_key, entry.shown_count, entry.total_count));
+            }
+            if self.fanout_capped.len() > MAX_LISTED_FANOUT_MARKERS {
+                lines.push(format!("    … and {} more nodes", self.fanout_capped.len() - MAX_LISTED_FANOUT_MARKERS));
+            }
+        }
+        if self.nodes_omitted > 0 {
+            lines.push(format!(
+                "… node limit {} reached: {} more not shown (raise --max-nodes)",
+                self.limits.max_nodes.unwrap_or_default(),
+                self.nodes_omitted
+            ));
+        }
+        lines
+    }
+}
+
+/// Cap the neighbours every node can reach through `edges`
+///
+/// # 4-Word Name: cap_edge_list_fanout
+///
+/// # Contract
+/// - Postcondition: in `direction`, no node has more than
+///   `report.limits.max_fanout` distinct neighbours; every capped node is
+///   recorded in `report`. Edge order is preserved
+/// - None or a limit no node exceeds returns the edges unchanged
+/// - Performance: O(E log E)
+pub fn cap_edge_list_fanout(
+    edges: &[DependencyEdge],
+    direction: TraversalFanoutDirectionKind,
+    report: &mut TraversalTruncationMarkerReport,
+) -> Vec<DependencyEdge> {
+    let Some(limit) = report.limits.max_fanout else {
+        return edges.to_vec();
+    };
+
+    // node -> (placeholder?, neighbour) in keep order
+    let mut neighbours: BTreeMap<&str, BTreeSet<(bool, &str)>> = BTreeMap::new();
+    for edge in edges {
+        let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+        if direction != TraversalFanoutDirectionKind::Incoming {
+            neighbours.entry(from).or_default().insert((is_placeholder_target_key(to), to));
+        }
+        if direction != TraversalFanoutDirectionKind::Outgoing {
+            neighbours.entry(to).or_default().insert((is_placeholder_target_key(from), from));
+        }
+    }
+
+    let mut kept: HashSet<(&str, &str)> = HashSet::new();
+    let mut capped = Vec::new();
+    for (&node, list) in &neighbours {
+        if list.len() <= limit {
+            continue;
+        }
+        kept.extend(list.iter().take(limit).map(|&(_, neighbour)| (node, neighbour)));
+        capped.push(FanoutTruncationMarkerEntry {
+            entity_key: node.to_string(),
+            shown_count: limit,
+            total_count: list.len(),
+        });
+    }
+    if capped.is_empty() {
+        return edges.to_vec();
+    }
+    let capped_nodes: HashSet<&str> = capped.iter().map(|entry| entry.entity_key.as_str()).collect();
+    let allows = |node: &str, neighbour: &str| !capped_nodes.contains(node) || kept.contains(&(node, neighbour));
+
+    let result = edges
+        .iter()
+        .filter(|edge| {
+            let (from, to) = (edge.from_key.as_str(), edge.to_key.as_str());
+            match direction {
+                TraversalFanoutDirectionKind::Outgoing => allows(from, to),
+                TraversalFanoutDirectionKind::Incoming => allows(to, from),
+                TraversalFanoutDirectionKind::Undirected => allows(from, to) && allows(to, from),
+            }
+        })
+        .cloned()
+        .collect();
+
+    capped.sort_by(|a, b| b.total_count.cmp(&a.total_count).then_with(|| a.entity_key.cmp(&b.entity_key)));
+    report.fanout_capped.extend(capped);
+    report.truncated = true;
+    result
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::EdgeType;
+
+    fn edge(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_fanout_cap_keeps_first_neighbours_and_marks() {
+        let god = "go:struct:Store:__db:T1";
+        let mut edges: Vec<DependencyEdge> =
+            (0..5).map(|i| edge(&format!("go:fn:caller{}:__api:T{}", i, i + 10), god)).collect();
+        edges.push(edge("go:fn:Log:unresolved-reference:0-0", god));
+        edges.push(edge("go:fn:caller0:__api:T10", "go:fn:helper:__api:T20"));
+
+        let limits = TraversalExpansionLimitSpec { max_depth: 2, max_fanout: Some(2), max_nodes: Some(1) };
+        let mut report = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+        let capped = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Incoming, &mut report);
+        let callers: Vec<&str> = capped.iter().filter(|e| e.to_key.as_str() == god).map(|e| e.from_key.as_str()).collect();
+        assert_eq!(callers, vec!["go:fn:caller0:__api:T10", "go:fn:caller1:__api:T11"], "placeholders are cut first");
+        assert_eq!(capped.len(), 3);
+        assert_eq!(report.fanout_capped, vec![FanoutTruncationMarkerEntry { entity_key: god.to_string(), shown_count: 2, total_count: 6 }]);
+
+        let outgoing = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Outgoing, &mut report);
+        assert_eq!(outgoing.len(), edges.len(), "no node calls more than two symbols");
+
+        let mut entries = vec![1, 2, 3];
+        report.truncate_entries_to_node_limit(&mut entries);
+        report.record_beyond_depth_count(4);
+        assert_eq!(entries, vec![1]);
+        let lines = report.render_truncation_marker_lines();
+        assert_eq!(lines.len(), 4);
+        assert!(lines[0].contains("4 more at hop 3"));
+        assert!(lines[3].contains("2 more not shown"));
+
+        let unlimited = TraversalExpansionLimitSpec { max_depth: 2, max_fanout: None, max_nodes: None };
+        let mut quiet = TraversalTruncationMarkerReport::create_empty_marker_report(unlimited);
+        assert_eq!(cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Undirected, &mut quiet).len(), edges.len());
+        assert!(!quiet.truncated && quiet.render_truncation_marker_lines().is_empty());
+    }
+
+    #[test]
+    fn test_node_neighbour_cap_orders_markers() {
+        let limits = TraversalExpansionLimitSpec { max_depth: 3, max_fanout: Some(1), max_nodes: None };
+        let mut report = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+        let mut few = vec!["a", "b"];
+        let mut many = vec!["c", "d", "e"];
+        let mut single = vec!["f"];
+        report.cap_node_neighbour_list("few", &mut few);
+        report.cap_node_neighbour_list("many", &mut many);
+        report.cap_node_neighbour_list("single", &mut single);
+
+        assert_eq!((few, many, single), (vec!["a"], vec!["c"], vec!["f"]));
+        let order: Vec<(&str, usize)> =
+            report.fanout_capped.iter().map(|e| (e.entity_key.as_str(), e.total_count)).collect();
+        assert_eq!(order, vec![("many", 3), ("few", 2)]);
+        assert!(report.truncated);
+    }
+}
@@ -0,0 +1,383 @@
+//! Type hierarchy trees (v1.7.3)
+//!
+//! # 4-Word Naming: type_hierarchy_tree_builder
+//!
+//! Supertypes and subtypes of a type, the way an IDE's type hierarchy view
+//! shows them, built from the edges earlier passes emit:
+//!
+//! - `Implements` (subtype → supertype): Go structural satisfaction
+//!   (`satisfies`), Rust `impl Trait for Type` and derives (`implements`),
+//!   Java/Kotlin supertypes (`extends` / `implements`, from `hierarchy_kind`)
+//! - `Embeds`: a Go struct or interface embedding another type (`embeds`)
+//! - `Bound` with `type_parameter: Self` from a trait: Rust super-traits
+//!   (`trait Repo: Send + Sync` → `supertrait`)
+//!
+//! Rust `Implements` edges start at the impl block; the hierarchy shows the
+//! implementing type instead (`impl_type` metadata, falling back to the
+//! impl block's name). Unresolved targets (`rust:fn:Serialize:
+//! unresolved-reference:0-0`) are matched by name to a type of the same
+//! language when one exists, so a hand-written trait and the impls pointing
+//! at it meet in one node; otherwise they stay external.
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+
+use serde::Serialize;
+
+use crate::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
+use crate::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+use crate::jvm_type_hierarchy_resolver::HIERARCHY_KIND_METADATA_KEY;
+use crate::rust_trait_impl_bound_extractor::{IMPL_TYPE_METADATA_KEY, TYPE_PARAMETER_METADATA_KEY};
+
+/// Node budget per tree; widely implemented traits stop expanding beyond it
+pub const MAX_TYPE_HIERARCHY_NODES: usize = 2000;
+
+/// Which way a type hierarchy walks
+///
+/// # 4-Word Name: TypeHierarchyDirectionKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum TypeHierarchyDirectionKind {
+    /// What this type implements, embeds or extends
+    Supertypes,
+    /// What implements, embeds or extends this type
+    Subtypes,
+}
+
+/// One subtype → supertype relation
+///
+/// # 4-Word Name: TypeHierarchyLinkEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeHierarchyLinkEntry {
+    pub subtype_key: String,
+    pub supertype_key: String,
+    /// `satisfies`, `implements`, `extends`, `embeds` or `supertrait`
+    pub relation: String,
+    pub source_location: Option<String>,
+}
+
+/// One entry of a type hierarchy
+///
+/// # 4-Word Name: TypeHierarchyTreeNode
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TypeHierarchyTreeNode {
+    pub entity_key: String,
+    pub name: String,
+    /// `file:line` of the definition; `None` for external types
+    pub location: Option<String>,
+    /// Relation to the parent entry; `None` at the root
+    pub relation: Option<String>,
+    /// Already on the path from the root; not expanded again
+    pub recursive: bool,
+    /// Related types left out at the depth limit
+    pub unexpanded_count: usize,
+    pub children: Vec<TypeHierarchyTreeNode>,
+}
+
+fn is_hierarchy_type_entity(entity: &CodeEntity) -> bool {
+    matches!(
+        entity.interface_signature.entity_type,
+        EntityType::Struct | EntityType::Enum | EntityType::Trait | EntityType::Interface | EntityType::Class
+    )
+}
+
+/// Bare type name of a Rust `impl_type` (`&'a mut pg::Wrapper<T>` → `Wrapper`)
+fn bare_rust_type_name(impl_type: &str) -> &str {
+    let without_generics = impl_type.split('<').next().unwrap_or(impl_type);
+    let last_word = without_generics.split_whitespace().last().unwrap_or(without_generics);
+    let last_segment = last_word.rsplit("::").next().unwrap_or(last_word);
+    last_segment.trim_start_matches('&').trim_start_matches('*')
+}
+
+struct TypeEndpointResolver<'a> {
+    entities: HashMap<&'a str, &'a CodeEntity>,
+    /// (language prefix, name) → type entities, sorted by key
+    types_by_name: HashMap<(&'a str, &'a str), Vec<&'a CodeEntity>>,
+}
+
+impl<'a> TypeEndpointResolver<'a> {
+    fn new(entities: &'a [CodeEntity]) -> Self {
+        let mut types_by_name: HashMap<(&str, &str), Vec<&CodeEntity>> = HashMap::new();
+        for entity in entities.iter().filter(|e| is_hierarchy_type_entity(e)) {
+            let language = entity.isgl1_key.split(':').next().unwrap_or_default();
+            types_by_name.entry((language, entity.interface_signature.name.as_str())).or_default().push(entity);
+        }
+        for candidates in types_by_name.values_mut() {
+            candidates.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+        }
+        Self { entities: entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect(), types_by_name }
+    }
+
+    /// Type named `name` in `key`'s language, preferring `near_file`
+    fn find_named_type(&self, key: &str, name: &str, near_file: Option<&std::path::Path>) -> Option<&'a CodeEntity> {
+        let language = key.split(':').next().unwrap_or_default();
+        let candidates = self.types_by_name.get(&(language, name))?;
+        candidates
+            .iter()
+            .find(|e| Some(e.interface_signature.file_path.as_path()) == near_file)
+            .or_else(|| candidates.first())
+            .copied()
+    }
+
+    /// Hierarchy node for an edge endpoint
+    fn resolve(&self, key: &str, impl_type: Option<&str>) -> String {
+        if let Some(entity) = self.entities.get(key) {
+            if !matches!(entity.interface_signature.entity_type, EntityType::ImplBlock { .. }) {
+                return key.to_string();
+            }
+            let name = impl_type.map_or(entity.interface_signature.name.as_str(), bare_rust_type_name);
+            return self
+                .find_named_type(key, name, Some(&entity.interface_signature.file_path))
+                .map_or_else(|| key.to_string(), |t| t.isgl1_key.clone());
+        }
+        parse_unresolved_reference_name(key)
+            .and_then(|name| self.find_named_type(key, name, None))
+            .map_or_else(|| key.to_string(), |t| t.isgl1_key.clone())
+    }
+}
+
+/// Collect every subtype → supertype relation in the graph
+///
+/// # 4-Word Name: collect_type_hierarchy_links
+///
+/// # Contract
+/// - Postcondition: one entry per (subtype, supertype, relation), sorted;
+///   impl blocks replaced by their type; no self links
+pub fn collect_type_hierarchy_links(entities: &[CodeEntity], edges: &[DependencyEdge]) -> Vec<TypeHierarchyLinkEntry> {
+    let resolver = TypeEndpointResolver::new(entities);
+    let mut seen = HashSet::new();
+    let mut links = Vec::new();
+    for edge in edges {
+        let relation = match edge.edge_type {
+            EdgeType::Implements if edge.metadata_value("match_kind") == Some("structural") => "satisfies",
+            EdgeType::Implements => edge.metadata_value(HIERARCHY_KIND_METADATA_KEY).unwrap_or("implements"),
+            EdgeType::Embeds => "embeds",
+            EdgeType::Bound
+                if edge.metadata_value(TYPE_PARAMETER_METADATA_KEY) == Some("Self")
+                    && resolver
+                        .entities
+                        .get(edge.from_key.as_str())
+                        .is_some_and(|e| e.interface_signature.entity_type == EntityType::Trait) =>
+            {
+                "supertrait"
+            }
+            _ => continue,
+        };
+        let subtype_key = resolver.resolve(edge.from_key.as_str(), edge.metadata_value(IMPL_TYPE_METADATA_KEY));
+        let supertype_key = resolver.resolve(edge.to_key.as_str(), None);
+        if subtype_key == supertype_key || !seen.insert((subtype_key.clone(), supertype_key.clone(), relation)) {
+            continue;
+        }
+        links.push(TypeHierarchyLinkEntry {
+            subtype_key,
+            supertype_key,
+            relation: relation.to_string(),
+            source_location: edge.source_location.clone(),
+        });
+    }
+    links.sort_by(|a, b| {
+        (&a.subtype_key, &a.supertype_key, &a.relation).cmp(&(&b.subtype_key, &b.supertype_key, &b.relation))
+    });
+    links
+}
+
+/// Build the supertype or subtype tree of `root_key`
+///
+/// # 4-Word Name: build_type_hierarchy_tree
+///
+/// # Contract
+/// - Precondition: `links` from `collect_type_hierarchy_links` over the same entities
+/// - Postcondition: nodes at depth `max_depth` have no children; a type
+///   already on the path is marked `recursive` and not expanded; at most
+///   `MAX_TYPE_HIERARCHY_NODES` nodes below the root
+/// - Deterministic: children ordered by name, then key, then relation
+pub fn build_type_hierarchy_tree(
+    root_key: &str,
+    entities: &[CodeEntity],
+    links: &[TypeHierarchyLinkEntry],
+    direction: TypeHierarchyDirectionKind,
+    max_depth: usize,
+) -> TypeHierarchyTreeNode {
+    build_bounded_type_hierarchy_tree(root_key, entities, links, direction, max_depth, MAX_TYPE_HIERARCHY_NODES)
+}
+
+/// Build the supertype or subtype tree of `root_key` with a node budget (`--max-nodes`)
+///
+/// # 4-Word Name: build_bounded_type_hierarchy_tree
+///
+/// # Contract
+/// - Postcondition: as `build_type_hierarchy_tree`, with at most
+///   `max_nodes` nodes below the root; entries beyond the budget are
+///   counted in their parent's `unexpanded_count`
+pub fn build_bounded_type_hierarchy_tree(
+    root_key: &str,
+    entities: &[CodeEntity],
+    links: &[TypeHierarchyLinkEntry],
+    direction: TypeHierarchyDirectionKind,
+    max_depth: usize,
+    max_nodes: usize,
+) -> TypeHierarchyTreeNode {
+    let resolver = TypeEndpointResolver::new(entities);
+    let mut related: HashMap<&str, Vec<(&str, &str)>> = HashMap::new();
+    for link in links {
+        let (from, to) = match direction {
+            TypeHierarchyDirectionKind::Supertypes => (&link.subtype_key, &link.supertype_key),
+            TypeHierarchyDirectionKind::Subtypes => (&link.supertype_key, &link.subtype_key),
+        };
+        related.entry(from.as_str()).or_default().push((to.as_str(), link.relation.as_str()));
+    }
+
+    let leaf_node = |key: &str, relation: Option<&str>| -> TypeHierarchyTreeNode {
+        let entity = resolver.entities.get(key);
+        TypeHierarchyTreeNode {
+            entity_key: key.to_string(),
+            name: entity.map_or_else(
+                || parse_unresolved_reference_name(key).unwrap_or(key).to_string(),
+                |e| e.interface_signature.name.clone(),
+            ),
+            location: entity.map(|e| {
+                format!("{}:{}", e.interface_signature.file_path.display(), e.interface_signature.line_range.start)
+            }),
+            relation: relation.map(str::to_string),
+            recursive: false,
+            unexpanded_count: 0,
+            children: Vec::new(),
+        }
+    };
+
+    fn expand<'k>(
+        node: &mut TypeHierarchyTreeNode,
+        depth: usize,
+        max_depth: usize,
+        related: &HashMap<&str, Vec<(&'k str, &'k str)>>,
+        leaf_node: &dyn Fn(&str, Option<&str>) -> TypeHierarchyTreeNode,
+        path: &mut Vec<String>,
+        budget: &mut usize,
+    ) {
+        let neighbors = related.get(node.entity_key.as_str()).cloned().unwrap_or_default();
+        if depth >= max_depth || *budget == 0 {
+            node.unexpanded_count = neighbors.len();
+            return;
+        }
+        let mut ordered: BTreeMap<(String, &str, &str), TypeHierarchyTreeNode> = BTreeMap::new();
+        for (key, relation) in neighbors {
+            let child = leaf_node(key, Some(relation));
+            ordered.insert((child.name.clone(), key, relation), child);
+        }
+        path.push(node.entity_key.clone());
+        for (_, mut child) in ordered {
+            if *budget == 0 {
+                node.unexpanded_count += 1;
+                continue;
+            }
+            *budget -= 1;
+            child.recursive = path.contains(&child.entity_key);
+            if !child.recursive {
+                expand(&mut child, depth + 1, max_depth, related, leaf_node, path, budget);
+            }
+            node.children.push(child);
+        }
+        path.pop();
+    }
+
+    let root_key = resolver.resolve(root_key, None);
+    let mut root = leaf_node(&root_key, None);
+    let mut budget = max_nodes;
+    expand(&mut root, 0, max_depth, &related, &leaf_node, &mut Vec::new(), &mut budget);
+    root
+}
+
+/// Render a hierarchy as an indented tree, two spaces per level
+///
+/// # 4-Word Name: render_type_hierarchy_text
+pub fn render_type_hierarchy_text(root: &TypeHierarchyTreeNode) -> String {
+    fn render_node(node: &TypeHierarchyTreeNode, indent: usize, out: &mut String) {
+        out.push_str(&"  ".repeat(indent));
+        out.push_str(&node.name);
+        out.push_str("  ");
+        out.push_str(node.location.as_deref().unwrap_or("(external)"));
+        if let Some(relation) = &node.relation {
+            out.push_str(&format!("  ({})", relation));
+        }
+        if node.recursive {
+            out.push_str("  (recursive)");
+        }
+        if node.unexpanded_count > 0 {
+            out.push_str(&format!("  … {} more", node.unexpanded_count));
+        }
+        out.push('\n');
+        for child in &node.children {
+            render_node(child, indent + 1, out);
+        }
+    }
+    let mut out = String::new();
+    render_node(root, 0, &mut out);
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType, metadata: &[(&str, &str)]) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type);
+        for (key, value) in metadata {
+            builder = builder.metadata_entry(*key, *value);
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_go_and_rust_hierarchies_resolve_endpoints() {
+        let impl_block = EntityType::ImplBlock { trait_name: None, struct_name: "Unknown".to_string() };
+        let entities = vec![
+            create_go_test_entity("go:interface:Store:__s:T1", "Store", EntityType::Interface, "s/store.go", &[]),
+            create_go_test_entity("go:interface:Reader:__s:T2", "Reader", EntityType::Interface, "s/store.go", &[]),
+            create_go_test_entity("go:struct:DiskStore:__s:T3", "DiskStore", EntityType::Struct, "s/disk.go", &[]),
+            create_go_test_entity("go:struct:CachedStore:__s:T4", "CachedStore", EntityType::Struct, "s/cache.go", &[]),
+            create_go_test_entity("rust:trait:Repo:__src_repo:T5", "Repo", EntityType::Trait, "src/repo.rs", &[]),
+            create_go_test_entity("rust:struct:PgRepo:__src_pg:T6", "PgRepo", EntityType::Struct, "src/pg.rs", &[]),
+            create_go_test_entity("rust:impl:PgRepo:__src_pg:T7", "PgRepo", impl_block, "src/pg.rs", &[]),
+        ];
+        let edges = vec![
+            edge("go:struct:DiskStore:__s:T3", "go:interface:Store:__s:T1", EdgeType::Implements, &[("match_kind", "structural")]),
+            edge("go:interface:Store:__s:T1", "go:interface:Reader:__s:T2", EdgeType::Embeds, &[]),
+            edge("go:struct:CachedStore:__s:T4", "go:struct:DiskStore:__s:T3", EdgeType::Embeds, &[]),
+            edge("rust:impl:PgRepo:__src_pg:T7", "rust:fn:Repo:unresolved-reference:0-0", EdgeType::Implements,
+                &[(IMPL_TYPE_METADATA_KEY, "PgRepo"), (HIERARCHY_KIND_METADATA_KEY, "implements")]),
+            edge("rust:trait:Repo:__src_repo:T5", "rust:fn:Send:unresolved-reference:0-0", EdgeType::Bound, &[(TYPE_PARAMETER_METADATA_KEY, "Self")]),
+            edge("rust:struct:PgRepo:__src_pg:T6", "rust:fn:T:unresolved-reference:0-0", EdgeType::Bound, &[(TYPE_PARAMETER_METADATA_KEY, "T")]),
+        ];
+        let links = collect_type_hierarchy_links(&entities, &edges);
+        assert_eq!(links.len(), 5, "{:?}", links);
+
+        let subtypes = build_type_hierarchy_tree("go:interface:Reader:__s:T2", &entities, &links, TypeHierarchyDirectionKind::Subtypes, 5);
+        assert_eq!(
+            render_type_hierarchy_text(&subtypes),
+            "Reader  s/store.go:1\n  Store  s/store.go:1  (embeds)\n    DiskStore  s/disk.go:1  (satisfies)\n      CachedStore  s/cache.go:1  (embeds)\n"
+        );
+
+        let supertypes = build_type_hierarchy_tree("rust:impl:PgRepo:__src_pg:T7", &entities, &links, TypeHierarchyDirectionKind::Supertypes, 5);
+        assert_eq!(
+            render_type_hierarchy_text(&supertypes),
+            "PgRepo  src/pg.rs:1\n  Repo  src/repo.rs:1  (implements)\n    Send  (external)  (supertrait)\n"
+        );
+
+        // Node budget: the third subtype is counted, not shown
+        let bounded = build_bounded_type_hierarchy_tree(
+            "go:interface:Reader:__s:T2",
+            &entities,
+            &links,
+            TypeHierarchyDirectionKind::Subtypes,
+            5,
+            2,
+        );
+        assert_eq!(
+            render_type_hierarchy_text(&bounded),
+            concat!(
+                "Reader  s/store.go:1\n  Store  s/store.go:1  (embeds)\n",
+                "    DiskStore  s/disk.go:1  (satisfies)  … 1 more\n"
+            )
+        );
+    }
+}
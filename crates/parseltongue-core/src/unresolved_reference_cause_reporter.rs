@@ -0,0 +1,218 @@
+//! Unresolved Reference Cause Reporter (v1.7.3)
+//!
+//! # 4-Word Naming: unresolved_reference_cause_reporter
+//!
+//! Lists every reference the resolvers could not bind to an entity, i.e.
+//! every edge ending in a placeholder (`unresolved-reference`, `unknown`,
+//! `external-dependency-`), for `parseltongue analyze unresolved`. The graph
+//! is blind past each of them; grouping by cause tells the user what to fix.
+//!
+//! ## Causes (first match wins)
+//!
+//! - `dynamic-dispatch`: the callee is only known at run time. Calls through
+//!   an interface / trait object (`receiver_static_type`) and through
+//!   function values (`confidence: indirect`). Fanned-out implementer edges
+//!   may exist next to the placeholder one
+//! - `missing-dependency`: the target lives outside the indexed tree. Go
+//!   targets tied to a module (`external`), `external-dependency-{crate}`
+//!   keys, and names no indexed entity of the language defines (standard
+//!   library, builtins, packages that were not ingested)
+//! - `parse-gap`: an indexed entity of that name exists but the reference
+//!   was not bound to it: an ambiguous name, or a scope the extractor did
+//!   not follow. `candidate_count` says how many entities share the name
+//!
+//! Placeholders are grouped per target key, with every referencing site.
+
+use std::collections::{BTreeMap, HashMap};
+
+use serde::Serialize;
+
+use crate::dynamic_dispatch_call_expander::RECEIVER_STATIC_TYPE_METADATA_KEY;
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::filtered_graph_traversal_queries::is_placeholder_target_key;
+use crate::go_module_version_resolver::EXTERNAL_METADATA_KEY;
+
+/// Why a reference stayed unbound
+///
+/// # 4-Word Name: UnresolvedReferenceCauseKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Serialize)]
+#[serde(rename_all = "kebab-case")]
+pub enum UnresolvedReferenceCauseKind {
+    MissingDependency,
+    ParseGap,
+    DynamicDispatch,
+}
+
+impl UnresolvedReferenceCauseKind {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::MissingDependency => "missing-dependency",
+            Self::ParseGap => "parse-gap",
+            Self::DynamicDispatch => "dynamic-dispatch",
+        }
+    }
+
+    /// Parse a `--cause` value
+    pub fn parse_cause_kind_name(value: &str) -> Option<Self> {
+        [Self::MissingDependency, Self::ParseGap, Self::DynamicDispatch]
+            .into_iter()
+            .find(|cause| cause.as_str() == value.trim())
+    }
+}
+
+/// One edge that points at the placeholder
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UnresolvedReferenceSiteEntry {
+    pub from_key: String,
+    pub edge_type: String,
+    /// `file:line` when the extractor recorded it
+    pub location: Option<String>,
+}
+
+/// One placeholder target and every site referencing it
+///
+/// # 4-Word Name: UnresolvedReferenceTargetEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UnresolvedReferenceTargetEntry {
+    pub target_key: String,
+    /// Referenced name as written (`Save`, `chi.NewRouter`)
+    pub name: String,
+    pub cause: UnresolvedReferenceCauseKind,
+    /// Indexed entities of the same language with that name
+    pub candidate_count: usize,
+    pub sites: Vec<UnresolvedReferenceSiteEntry>,
+}
+
+/// Targets sharing one cause
+///
+/// # 4-Word Name: UnresolvedReferenceCauseGroup
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UnresolvedReferenceCauseGroup {
+    pub cause: UnresolvedReferenceCauseKind,
+    /// Sites over all targets of the group
+    pub reference_count: usize,
+    pub targets: Vec<UnresolvedReferenceTargetEntry>,
+}
+
+/// Group every unbound reference of the graph by cause
+///
+/// # 4-Word Name: collect_unresolved_reference_report
+///
+/// # Contract
+/// - Postcondition: every edge ending in a placeholder is one site of exactly
+///   one target; groups in cause order, empty groups omitted; targets by
+///   site count (descending) then key, sites by (from_key, location)
+/// - A target's cause is the strongest one among its sites
+///   (`dynamic-dispatch` over the others)
+pub fn collect_unresolved_reference_report(
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> Vec<UnresolvedReferenceCauseGroup> {
+    let mut defined_names: HashMap<(&str, &str), usize> = HashMap::new();
+    for entity in entities.iter().filter(|e| !is_unbound_reference_key(&e.isgl1_key)) {
+        let language = entity.isgl1_key.split(':').next().unwrap_or("");
+        *defined_names.entry((language, entity.interface_signature.name.as_str())).or_default() += 1;
+    }
+
+    let mut targets: BTreeMap<&str, UnresolvedReferenceTargetEntry> = BTreeMap::new();
+    for edge in edges.iter().filter(|e| is_unbound_reference_key(e.to_key.as_str())) {
+        let key = edge.to_key.as_str();
+        let mut segments = key.splitn(4, ':');
+        let language = segments.next().unwrap_or("");
+        let _kind = segments.next();
+        let name = segments.next().unwrap_or(key);
+        let short_name = name.rsplit(['.', '/']).next().unwrap_or(name);
+        let candidate_count = defined_names.get(&(language, short_name)).copied().unwrap_or(0);
+
+        let cause = if edge.metadata_value(RECEIVER_STATIC_TYPE_METADATA_KEY).is_some()
+            || edge.metadata_value("confidence") == Some("indirect")
+        {
+            UnresolvedReferenceCauseKind::DynamicDispatch
+        } else if edge.metadata_value(EXTERNAL_METADATA_KEY) == Some("true")
+            || key.contains(":external-dependency-")
+            || candidate_count == 0
+        {
+            UnresolvedReferenceCauseKind::MissingDependency
+        } else {
+            UnresolvedReferenceCauseKind::ParseGap
+        };
+
+        let target = targets.entry(key).or_insert_with(|| UnresolvedReferenceTargetEntry {
+            target_key: key.to_string(),
+            name: name.to_string(),
+            cause,
+            candidate_count,
+            sites: Vec::new(),
+        });
+        target.cause = target.cause.max(cause);
+        target.sites.push(UnresolvedReferenceSiteEntry {
+            from_key: edge.from_key.as_str().to_string(),
+            edge_type: format!("{:?}", edge.edge_type),
+            location: edge.source_location.clone(),
+        });
+    }
+
+    let mut groups: BTreeMap<UnresolvedReferenceCauseKind, Vec<UnresolvedReferenceTargetEntry>> = BTreeMap::new();
+    for (_, mut target) in targets {
+        target.sites.sort_by(|a, b| (&a.from_key, &a.location).cmp(&(&b.from_key, &b.location)));
+        groups.entry(target.cause).or_default().push(target);
+    }
+    groups
+        .into_iter()
+        .map(|(cause, mut targets)| {
+            targets.sort_by(|a, b| b.sites.len().cmp(&a.sites.len()).then_with(|| a.target_key.cmp(&b.target_key)));
+            UnresolvedReferenceCauseGroup {
+                cause,
+                reference_count: targets.iter().map(|t| t.sites.len()).sum(),
+                targets,
+            }
+        })
+        .collect()
+}
+
+fn is_unbound_reference_key(key: &str) -> bool {
+    is_placeholder_target_key(key) || key.contains(":external-dependency-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::entities::{EdgeType, EntityType};
+    use crate::go_embedding_promotion_resolver::create_go_test_entity;
+
+    fn edge(from: &str, to: &str, metadata: &[(&str, &str)]) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls);
+        for (key, value) in metadata {
+            builder = builder.metadata_entry(*key, *value);
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_unbound_references_grouped_by_cause() {
+        let entities = vec![
+            create_go_test_entity("go:fn:Handle:__api:T1", "Handle", EntityType::Function, "api/handle.go", &[]),
+            create_go_test_entity("go:fn:validate:__api:T2", "validate", EntityType::Function, "api/check.go", &[]),
+            create_go_test_entity("go:fn:validate:__billing:T3", "validate", EntityType::Function, "billing/check.go", &[]),
+        ];
+        let edges = vec![
+            edge("go:fn:Handle:__api:T1", "go:fn:validate:__api:T2", &[]),
+            edge("go:fn:Handle:__api:T1", "go:fn:validate:unresolved-reference:0-0", &[]),
+            edge("go:fn:Handle:__api:T1", "go:fn:Save:unresolved-reference:0-0", &[("receiver_static_type", "Store")]),
+            edge("go:fn:Handle:__api:T1", "go:fn:github.com/go-chi/chi/v5.NewRouter:unresolved-reference:0-0", &[("external", "true")]),
+            edge("go:fn:validate:__api:T2", "go:fn:Sprintf:unresolved-reference:0-0", &[]),
+            edge("go:fn:validate:__billing:T3", "go:fn:Sprintf:unresolved-reference:0-0", &[]),
+        ];
+
+        let report = collect_unresolved_reference_report(&entities, &edges);
+        let causes: Vec<&str> = report.iter().map(|g| g.cause.as_str()).collect();
+        assert_eq!(causes, vec!["missing-dependency", "parse-gap", "dynamic-dispatch"]);
+        let missing = &report[0];
+        assert_eq!(missing.reference_count, 3);
+        assert_eq!(missing.targets[0].name, "Sprintf", "most referenced first");
+        assert_eq!(missing.targets[0].sites.len(), 2);
+        assert_eq!(report[1].targets[0].candidate_count, 2, "validate is defined twice");
+        assert_eq!(report[2].targets[0].name, "Save");
+        assert_eq!(UnresolvedReferenceCauseKind::parse_cause_kind_name("parse-gap"), Some(UnresolvedReferenceCauseKind::ParseGap));
+    }
+}
@@ -0,0 +1,329 @@
+//! Workspace module roots in a monorepo (v1.7.3)
+//!
+//! # 4-Word Naming: workspace_module_root_detector
+//!
+//! A monorepo holds many modules: every directory with a `go.mod`, a
+//! `Cargo.toml` with a `[package]`, or a `package.json` is one. Each entity is
+//! tagged with the module owning its file (the closest enclosing root):
+//!
+//! | Key | Value |
+//! |-----|-------|
+//! | `workspace_module` | module name (`module` path, crate or package name) |
+//! | `workspace_module_kind` | `go`, `cargo` or `npm` |
+//! | `workspace_module_root` | module directory relative to the ingest root (`.` for the root) |
+//!
+//! Go edges are annotated against the build list of their own module, and
+//! imports of any workspace module count as in-repository, not external.
+
+use std::collections::{HashMap, HashSet};
+use std::path::{Component, Path};
+
+use crate::entities::{CodeEntity, DependencyEdge};
+use crate::go_module_version_resolver::{
+    annotate_go_external_module_edge, load_go_module_records, parse_go_mod_directives, GoModuleRecordEntry,
+};
+
+/// Entity metadata key: owning module name
+pub const WORKSPACE_MODULE_METADATA_KEY: &str = "workspace_module";
+
+/// Entity metadata key: owning module manifest kind
+pub const WORKSPACE_MODULE_KIND_METADATA_KEY: &str = "workspace_module_kind";
+
+/// Entity metadata key: owning module directory, relative to the ingest root
+pub const WORKSPACE_MODULE_ROOT_METADATA_KEY: &str = "workspace_module_root";
+
+/// Directories never searched for manifests
+const SKIPPED_MANIFEST_DIRECTORY_NAMES: &[&str] =
+    &[".git", "node_modules", "target", "vendor", "dist", "build", "testdata", "__pycache__", ".venv"];
+
+/// Manifest kind of a module root
+///
+/// # 4-Word Name: WorkspaceModuleKindValue
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, PartialOrd, Ord)]
+pub enum WorkspaceModuleKindValue {
+    Go,
+    Cargo,
+    Npm,
+}
+
+impl WorkspaceModuleKindValue {
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Self::Go => "go",
+            Self::Cargo => "cargo",
+            Self::Npm => "npm",
+        }
+    }
+}
+
+/// One module root of the workspace
+///
+/// # 4-Word Name: WorkspaceModuleRootEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WorkspaceModuleRootEntry {
+    pub kind: WorkspaceModuleKindValue,
+    pub name: String,
+    /// Relative to the ingest root, `/`-separated; `.` for the root itself
+    pub relative_dir: String,
+}
+
+/// Find every module manifest under `root`
+///
+/// # 4-Word Name: detect_workspace_module_roots
+///
+/// # Contract
+/// - Postcondition: sorted by directory then kind; build, vendor and
+///   dependency directories are not searched; virtual Cargo workspaces
+///   (`[workspace]` without `[package]`) are not modules
+pub fn detect_workspace_module_roots(root: &Path) -> Vec<WorkspaceModuleRootEntry> {
+    let mut modules = Vec::new();
+    let mut pending = vec![root.to_path_buf()];
+    while let Some(directory) = pending.pop() {
+        let relative_dir = relative_slash_path(root, &directory);
+        modules.extend(read_directory_module_manifests(&directory, &relative_dir));
+        let Ok(entries) = std::fs::read_dir(&directory) else { continue };
+        for entry in entries.flatten() {
+            let is_directory = entry.file_type().is_ok_and(|t| t.is_dir());
+            let name = entry.file_name().to_string_lossy().into_owned();
+            if is_directory && !SKIPPED_MANIFEST_DIRECTORY_NAMES.contains(&name.as_str()) && !name.starts_with('.') {
+                pending.push(entry.path());
+            }
+        }
+    }
+    modules.sort_by(|a, b| (&a.relative_dir, a.kind).cmp(&(&b.relative_dir, b.kind)));
+    modules
+}
+
+fn read_directory_module_manifests(directory: &Path, relative_dir: &str) -> Vec<WorkspaceModuleRootEntry> {
+    let fallback_name = || {
+        directory
+            .file_name()
+            .map(|name| name.to_string_lossy().into_owned())
+            .unwrap_or_else(|| relative_dir.to_string())
+    };
+    let mut found = Vec::new();
+    if let Ok(text) = std::fs::read_to_string(directory.join("go.mod")) {
+        let name = parse_go_mod_directives(&text)
+            .into_iter()
+            .find(|module| module.main)
+            .map(|module| module.path)
+            .unwrap_or_else(fallback_name);
+        found.push((WorkspaceModuleKindValue::Go, name));
+    }
+    if let Some(name) = std::fs::read_to_string(directory.join("Cargo.toml"))
+        .ok()
+        .and_then(|text| parse_cargo_package_name(&text))
+    {
+        found.push((WorkspaceModuleKindValue::Cargo, name));
+    }
+    if let Ok(text) = std::fs::read_to_string(directory.join("package.json")) {
+        let name = serde_json::from_str::<serde_json::Value>(&text)
+            .ok()
+            .and_then(|json| json.get("name").and_then(|n| n.as_str()).map(str::to_string))
+            .unwrap_or_else(fallback_name);
+        found.push((WorkspaceModuleKindValue::Npm, name));
+    }
+    found
+        .into_iter()
+        .map(|(kind, name)| WorkspaceModuleRootEntry { kind, name, relative_dir: relative_dir.to_string() })
+        .collect()
+}
+
+/// `name` of the `[package]` table of a Cargo manifest
+///
+/// # 4-Word Name: parse_cargo_package_name
+pub fn parse_cargo_package_name(text: &str) -> Option<String> {
+    let mut in_package = false;
+    for line in text.lines().map(|line| line.split('#').next().unwrap_or_default().trim()) {
+        if line.starts_with('[') {
+            in_package = line == "[package]";
+            continue;
+        }
+        let Some((key, value)) = line.split_once('=') else { continue };
+        if in_package && key.trim() == "name" {
+            return Some(value.trim().trim_matches('"').trim_matches('\'').to_string());
+        }
+    }
+    None
+}
+
+/// Module owning a file: the closest enclosing module root
+///
+/// # 4-Word Name: find_owning_workspace_module
+///
+/// # Contract
+/// - Precondition: `relative_file_path` is relative to the ingest root
+/// - Postcondition: among roots of the same directory, the first kind
+///   (go, cargo, npm) wins
+pub fn find_owning_workspace_module<'m>(
+    modules: &'m [WorkspaceModuleRootEntry],
+    relative_file_path: &str,
+) -> Option<&'m WorkspaceModuleRootEntry> {
+    let path = relative_file_path.trim_start_matches("./");
+    modules
+        .iter()
+        .filter(|module| {
+            module.relative_dir == "."
+                || path.strip_prefix(module.relative_dir.as_str()).is_some_and(|rest| rest.starts_with('/'))
+        })
+        .max_by(|a, b| {
+            let depth = |m: &WorkspaceModuleRootEntry| if m.relative_dir == "." { 0 } else { m.relative_dir.len() };
+            depth(a).cmp(&depth(b)).then(b.kind.cmp(&a.kind))
+        })
+}
+
+/// Tag each entity with the module owning its file
+///
+/// # 4-Word Name: tag_entities_with_workspace_module
+///
+/// # Contract
+/// - Postcondition: entities under a module root carry the three
+///   `workspace_module*` keys; returns how many were tagged
+pub fn tag_entities_with_workspace_module(
+    entities: &mut [CodeEntity],
+    root: &Path,
+    modules: &[WorkspaceModuleRootEntry],
+) -> usize {
+    if modules.is_empty() {
+        return 0;
+    }
+    let mut tagged = 0;
+    for entity in entities.iter_mut() {
+        let relative = relative_slash_path(root, &entity.interface_signature.file_path);
+        let Some(module) = find_owning_workspace_module(modules, &relative) else { continue };
+        let additional = &mut entity.metadata.additional;
+        additional.insert(WORKSPACE_MODULE_METADATA_KEY.to_string(), module.name.clone());
+        additional.insert(WORKSPACE_MODULE_KIND_METADATA_KEY.to_string(), module.kind.as_str().to_string());
+        additional.insert(WORKSPACE_MODULE_ROOT_METADATA_KEY.to_string(), module.relative_dir.clone());
+        tagged += 1;
+    }
+    tagged
+}
+
+/// Go module annotation with each edge's own module build list
+///
+/// # 4-Word Name: annotate_go_edges_per_module
+///
+/// # Contract
+/// - Precondition: `entities` are tagged (`tag_entities_with_workspace_module`)
+/// - Postcondition: as `annotate_go_external_module_edges`, with the build
+///   list of the Go module owning the edge's source; every workspace Go
+///   module counts as a main module
+/// - Returns: number of edges annotated
+pub fn annotate_go_edges_per_module(
+    edges: &mut [DependencyEdge],
+    entities: &[CodeEntity],
+    root: &Path,
+    modules: &[WorkspaceModuleRootEntry],
+) -> usize {
+    let go_modules: Vec<&WorkspaceModuleRootEntry> =
+        modules.iter().filter(|m| m.kind == WorkspaceModuleKindValue::Go).collect();
+    let workspace_paths: HashSet<&str> = go_modules.iter().map(|m| m.name.as_str()).collect();
+    let mark_workspace_main = |mut records: Vec<GoModuleRecordEntry>| {
+        for record in records.iter_mut() {
+            record.main |= workspace_paths.contains(record.path.as_str());
+        }
+        records
+    };
+
+    let workspace_only: Vec<GoModuleRecordEntry> = go_modules
+        .iter()
+        .map(|m| GoModuleRecordEntry { path: m.name.clone(), main: true, ..Default::default() })
+        .collect();
+    let build_lists: HashMap<&str, Vec<GoModuleRecordEntry>> = go_modules
+        .iter()
+        .map(|m| {
+            let records = load_go_module_records(&root.join(&m.relative_dir));
+            let mut records = mark_workspace_main(records);
+            records.extend(workspace_only.iter().cloned());
+            (m.relative_dir.as_str(), records)
+        })
+        .collect();
+    let module_root_by_key: HashMap<&str, &str> = entities
+        .iter()
+        .filter(|e| e.metadata.additional.get(WORKSPACE_MODULE_KIND_METADATA_KEY).map(String::as_str) == Some("go"))
+        .filter_map(|e| {
+            let dir = e.metadata.additional.get(WORKSPACE_MODULE_ROOT_METADATA_KEY)?;
+            Some((e.isgl1_key.as_str(), dir.as_str()))
+        })
+        .collect();
+
+    let mut annotated = 0;
+    for edge in edges.iter_mut() {
+        let records = module_root_by_key
+            .get(edge.from_key.as_str())
+            .and_then(|dir| build_lists.get(dir))
+            .unwrap_or(&workspace_only);
+        if annotate_go_external_module_edge(edge, records) {
+            annotated += 1;
+        }
+    }
+    annotated
+}
+
+fn relative_slash_path(root: &Path, path: &Path) -> String {
+    let relative = path.strip_prefix(root).unwrap_or(path);
+    let parts: Vec<String> = relative
+        .components()
+        .filter_map(|component| match component {
+            Component::Normal(part) => Some(part.to_string_lossy().into_owned()),
+            _ => None,
+        })
+        .collect();
+    if parts.is_empty() {
+        ".".to_string()
+    } else {
+        parts.join("/")
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn module(kind: WorkspaceModuleKindValue, name: &str, dir: &str) -> WorkspaceModuleRootEntry {
+        WorkspaceModuleRootEntry { kind, name: name.to_string(), relative_dir: dir.to_string() }
+    }
+
+    #[test]
+    fn test_detect_roots_and_owning_module() {
+        let root = tempfile::tempdir().unwrap();
+        let write = |path: &str, text: &str| {
+            let full = root.path().join(path);
+            std::fs::create_dir_all(full.parent().unwrap()).unwrap();
+            std::fs::write(full, text).unwrap();
+        };
+        write("Cargo.toml", "[workspace]\nmembers = [\"tools/cli\"]\n");
+        write("tools/cli/Cargo.toml", "[package]\nname = \"repo-cli\" # the CLI\nversion = \"0.1.0\"\n");
+        write("services/payments/go.mod", "module example.com/payments\n\ngo 1.22\n");
+        write("web/package.json", "{\"name\": \"@acme/web\", \"private\": true}");
+        write("web/node_modules/left-pad/package.json", "{\"name\": \"left-pad\"}");
+
+        let modules = detect_workspace_module_roots(root.path());
+        assert_eq!(
+            modules,
+            vec![
+                module(WorkspaceModuleKindValue::Go, "example.com/payments", "services/payments"),
+                module(WorkspaceModuleKindValue::Cargo, "repo-cli", "tools/cli"),
+                module(WorkspaceModuleKindValue::Npm, "@acme/web", "web"),
+            ]
+        );
+        let owner = |path: &str| find_owning_workspace_module(&modules, path).map(|m| m.name.as_str());
+        assert_eq!(owner("services/payments/internal/charge.go"), Some("example.com/payments"));
+        assert_eq!(owner("./web/src/app.ts"), Some("@acme/web"));
+        assert_eq!(owner("services/paymentsx/a.go"), None);
+    }
+
+    #[test]
+    fn test_closest_root_wins_over_repository_root() {
+        let modules = vec![
+            module(WorkspaceModuleKindValue::Npm, "root", "."),
+            module(WorkspaceModuleKindValue::Go, "example.com/api", "api"),
+            module(WorkspaceModuleKindValue::Npm, "api-docs", "api"),
+        ];
+        let owner = |path: &str| find_owning_workspace_module(&modules, path).map(|m| m.name.as_str());
+        assert_eq!(owner("api/server.go"), Some("example.com/api"));
+        assert_eq!(owner("scripts/build.js"), Some("root"));
+        assert_eq!(parse_cargo_package_name("[dependencies]\nname = \"x\"\n"), None);
+    }
+}
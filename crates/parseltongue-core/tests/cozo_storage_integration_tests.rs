@@ -361,6 +361,88 @@ async fn test_batch_insert_empty_slice() {
     db.insert_edges_batch(&edges).await.unwrap();
 }
 
+#[tokio::test]
+async fn test_edge_metadata_roundtrip_batch_and_single() {
+    // Test: Edge metadata (generic type args) persists through both insert paths
+    let db = CozoDbStorage::new("mem").await.unwrap();
+    db.create_dependency_edges_schema().await.unwrap();
+
+    let generic_call = DependencyEdge::builder()
+        .from_key("go:fn:run:__main:T1700000000")
+        .to_key("go:fn:Map:unresolved-reference:0-0")
+        .edge_type(EdgeType::Calls)
+        .source_location("main.go:5")
+        .metadata_entry("type_arguments", "int, string")
+        .build()
+        .unwrap();
+    let plain_call = DependencyEdge::builder()
+        .from_key("go:fn:run:__main:T1700000000")
+        .to_key("go:fn:Sum:unresolved-reference:0-0")
+        .edge_type(EdgeType::Calls)
+        .build()
+        .unwrap();
+    let generic_literal = DependencyEdge::builder()
+        .from_key("go:fn:build:__main:T1700000001")
+        .to_key("go:fn:Box:unresolved-reference:0-0")
+        .edge_type(EdgeType::Calls)
+        .metadata_entry("type_arguments", "map[string]int")
+        .build()
+        .unwrap();
+
+    db.insert_edges_batch(&[generic_call.clone(), plain_call.clone()]).await.unwrap();
+    db.insert_edge(&generic_literal).await.unwrap();
+
+    let all = db.get_all_dependencies().await.unwrap();
+    let find = |to: &str| all.iter().find(|e| e.to_key.as_str() == to).unwrap().clone();
+
+    assert_eq!(find("go:fn:Map:unresolved-reference:0-0").metadata_value("type_arguments"), Some("int, string"));
+    assert!(find("go:fn:Sum:unresolved-reference:0-0").metadata.is_empty());
+    assert_eq!(find("go:fn:Box:unresolved-reference:0-0").metadata_value("type_arguments"), Some("map[string]int"));
+}
+
+#[tokio::test]
+async fn test_legacy_dependency_edges_schema_migrates_on_open() {
+    // Test: A database ingested before edge metadata existed opens with its edges intact
+    let temp_dir = tempfile::TempDir::new().unwrap();
+    let spec = format!("sqlite:{}", temp_dir.path().join("legacy.db").display());
+    {
+        let legacy = CozoDbStorage::new(&spec).await.unwrap();
+        legacy
+            .execute_query(
+                ":create DependencyEdges { \
+                 from_key: String, to_key: String, edge_type: String => source_location: String? }",
+            )
+            .await
+            .unwrap();
+        legacy
+            .execute_query(
+                "?[from_key, to_key, edge_type, source_location] <- \
+                 [['rust:fn:main:__src_main:T1', 'rust:fn:helper:__src_lib:T2', 'Calls', 'src/main.rs:3']] \
+                 :put DependencyEdges { from_key, to_key, edge_type => source_location }",
+            )
+            .await
+            .unwrap();
+    }
+
+    let db = CozoDbStorage::new(&spec).await.unwrap();
+    assert!(!db.migrate_legacy_dependency_edges_schema().await.unwrap(), "already migrated by new()");
+    let edges = db.get_all_dependencies().await.unwrap();
+    assert_eq!(edges.len(), 1);
+    assert_eq!(edges[0].to_key.as_str(), "rust:fn:helper:__src_lib:T2");
+    assert_eq!(edges[0].source_location.as_deref(), Some("src/main.rs:3"));
+    assert!(edges[0].metadata.is_empty());
+
+    let typed = DependencyEdge::builder()
+        .from_key("rust:fn:main:__src_main:T1")
+        .to_key("rust:fn:parse:__src_lib:T3")
+        .edge_type(EdgeType::Calls)
+        .metadata_entry("type_arguments", "u32")
+        .build()
+        .unwrap();
+    db.insert_edges_batch(&[typed]).await.unwrap();
+    assert_eq!(db.get_all_dependencies().await.unwrap().len(), 2);
+}
+
 #[tokio::test]
 async fn test_single_edge_insert_performance_contract() {
     // Performance Contract: Single insert <5ms (D10 specification)
@@ -1021,3 +1103,34 @@ async fn test_forward_dependencies_performance_10k_nodes() {
         "Should find forward dependencies for first node"
     );
 }
+
+#[tokio::test]
+async fn test_snapshot_engine_loads_zstd_json_export() {
+    // v1.7.3: "snapshot:graph.json.zst" is an in-memory DB filled from an export
+    use parseltongue_core::serializers::{write_graph_as_json_snapshot, CompressibleExportOutputWriter};
+
+    let entities = vec![create_test_entity_with_key("a-key"), create_test_entity_with_key("b-key")];
+    let edges = vec![DependencyEdge::builder()
+        .from_key("a-key")
+        .to_key("b-key")
+        .edge_type(EdgeType::Uses)
+        .build()
+        .unwrap()];
+
+    let dir = tempfile::TempDir::new().unwrap();
+    let path = dir.path().join("graph.json.zst");
+    let mut out = CompressibleExportOutputWriter::create_export_output_file(&path).unwrap();
+    let manifest = parseltongue_core::extractor_capability_manifest_builder::build_installed_capability_manifest().unwrap();
+    write_graph_as_json_snapshot(&mut out, &entities, &edges, Some(&manifest)).unwrap();
+    out.finish_export_output_file().unwrap();
+
+    let db = CozoDbStorage::new(&format!("snapshot:{}", path.display())).await.unwrap();
+    let mut keys: Vec<String> = db.get_all_entities().await.unwrap().into_iter().map(|e| e.isgl1_key).collect();
+    keys.sort();
+    assert_eq!(keys, vec!["a-key", "b-key"]);
+    assert_eq!(db.get_all_dependencies().await.unwrap(), edges);
+    // v1.7.3: the manifest travels with the snapshot
+    assert_eq!(db.get_extractor_capability_manifest().await.unwrap(), Some(manifest));
+
+    assert!(CozoDbStorage::new("snapshot:/nonexistent/graph.json.zst").await.is_err());
+}
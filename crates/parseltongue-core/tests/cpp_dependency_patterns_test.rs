@@ -276,7 +276,6 @@ void process() {
 }
 
 #[test]
-#[ignore = "C++ #include directives are preprocessor-level; tree-sitter parses post-preprocessor AST"]
 fn test_cpp_existing_includes() {
     let code = r#"
 #include <iostream>
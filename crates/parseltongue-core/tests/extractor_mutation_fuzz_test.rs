@@ -0,0 +1,145 @@
+// Extractor Mutation Fuzz Tests (v1.7.3)
+//
+// Stable-toolchain counterpart of the cargo-fuzz targets in fuzz/: every
+// language extractor is fed mutated copies of the test-fixtures/ sources
+// (truncations, deletions, stray delimiters, flipped bytes) and must not
+// panic. Files saved under fuzz/crash-corpus/extract_{language}/ — inputs
+// that once crashed a fuzz target — are replayed on every run.
+
+use std::path::{Path, PathBuf};
+
+use parseltongue_core::entities::Language;
+use parseltongue_core::source_extraction_fuzz_driver::run_extractors_on_source_bytes;
+use proptest::prelude::*;
+
+const FIXTURE_CORPUS_DIRECTORY: &str = "../../test-fixtures";
+const CRASH_CORPUS_DIRECTORY: &str = "../../fuzz/crash-corpus";
+
+const FUZZED_LANGUAGES: [Language; 15] = [
+    Language::Rust,
+    Language::JavaScript,
+    Language::TypeScript,
+    Language::Python,
+    Language::Java,
+    Language::C,
+    Language::Cpp,
+    Language::Go,
+    Language::Ruby,
+    Language::Php,
+    Language::CSharp,
+    Language::Swift,
+    Language::Kotlin,
+    Language::Scala,
+    Language::Sql,
+];
+
+/// Fragments that open or close syntax, inserted at random offsets
+const SYNTAX_FRAGMENTS: [&str; 16] = [
+    "{", "}", "(", ")", "[", "<", ">", "\"", "'", "/*", "*/", "\n", "::", "\\", "\u{0}", "é",
+];
+
+#[derive(Debug, Clone)]
+enum SourceMutation {
+    Truncate(usize),
+    DeleteRange(usize, usize),
+    InsertFragment(usize, usize),
+    FlipByte(usize, u8),
+}
+
+fn source_mutation_strategy() -> impl Strategy<Value = SourceMutation> {
+    prop_oneof![
+        any::<usize>().prop_map(SourceMutation::Truncate),
+        (any::<usize>(), 1usize..64).prop_map(|(at, len)| SourceMutation::DeleteRange(at, len)),
+        (any::<usize>(), 0..SYNTAX_FRAGMENTS.len()).prop_map(|(at, f)| SourceMutation::InsertFragment(at, f)),
+        (any::<usize>(), any::<u8>()).prop_map(|(at, b)| SourceMutation::FlipByte(at, b)),
+    ]
+}
+
+fn apply_source_mutation(bytes: &mut Vec<u8>, mutation: &SourceMutation) {
+    let len = bytes.len().max(1);
+    match *mutation {
+        SourceMutation::Truncate(at) => bytes.truncate(at % len),
+        SourceMutation::DeleteRange(at, count) => {
+            let start = (at % len).min(bytes.len());
+            let end = (start + count).min(bytes.len());
+            bytes.drain(start..end);
+        }
+        SourceMutation::InsertFragment(at, fragment) => {
+            let at = (at % len).min(bytes.len());
+            bytes.splice(at..at, SYNTAX_FRAGMENTS[fragment].bytes());
+        }
+        SourceMutation::FlipByte(at, value) => {
+            if !bytes.is_empty() {
+                let at = at % bytes.len();
+                bytes[at] ^= value;
+            }
+        }
+    }
+}
+
+/// Fixture sources of a language; a one-line seed when the corpus has none
+fn seed_sources_for_language(language: Language) -> Vec<Vec<u8>> {
+    let mut files = Vec::new();
+    collect_files_recursively(Path::new(FIXTURE_CORPUS_DIRECTORY), &mut files);
+    files.sort();
+    let mut seeds: Vec<Vec<u8>> = files
+        .iter()
+        .filter(|path| Language::from_file_path(path) == Some(language))
+        .filter_map(|path| std::fs::read(path).ok())
+        .collect();
+    if seeds.is_empty() {
+        let fallback: &str = match language {
+            Language::Kotlin => "class Store { fun save(u: User): Int { return repo.put(u) } }\n",
+            Language::Scala => "object Store { def save(u: User): Int = repo.put(u) }\n",
+            _ => "main() { call(1); }\n",
+        };
+        seeds.push(fallback.as_bytes().to_vec());
+    }
+    seeds
+}
+
+fn collect_files_recursively(dir: &Path, files: &mut Vec<PathBuf>) {
+    for entry in std::fs::read_dir(dir).into_iter().flatten().flatten() {
+        let path = entry.path();
+        if path.is_dir() {
+            collect_files_recursively(&path, files);
+        } else {
+            files.push(path);
+        }
+    }
+}
+
+proptest! {
+    #![proptest_config(ProptestConfig::with_cases(96))]
+
+    #[test]
+    fn extractors_survive_mutated_fixture_sources(
+        language_index in 0..FUZZED_LANGUAGES.len(),
+        seed_index in any::<usize>(),
+        mutations in prop::collection::vec(source_mutation_strategy(), 1..8),
+    ) {
+        let language = FUZZED_LANGUAGES[language_index];
+        let seeds = seed_sources_for_language(language);
+        let mut bytes = seeds[seed_index % seeds.len()].clone();
+        for mutation in &mutations {
+            apply_source_mutation(&mut bytes, mutation);
+        }
+        // A panic fails the case; proptest shrinks it to a minimal input
+        run_extractors_on_source_bytes(language, &bytes);
+    }
+}
+
+#[test]
+fn extractors_survive_crash_corpus_replay() {
+    for language in FUZZED_LANGUAGES {
+        let target_dir = Path::new(CRASH_CORPUS_DIRECTORY).join(format!("extract_{}", language));
+        let mut inputs = Vec::new();
+        collect_files_recursively(&target_dir, &mut inputs);
+        inputs.sort();
+        for input in inputs {
+            let bytes = std::fs::read(&input).unwrap();
+            println!("replaying {}", input.display());
+            run_extractors_on_source_bytes(language, &bytes);
+        }
+    }
+}
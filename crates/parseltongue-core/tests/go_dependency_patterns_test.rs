@@ -2,7 +2,7 @@
 // Test comprehensive dependency detection for Go language
 // Following TDD spec: docs/TDD-SPEC-multi-language-dependency-patterns-v1.4.9.md
 
-use parseltongue_core::entities::{DependencyEdge, Language};
+use parseltongue_core::entities::{DependencyEdge, EdgeType, Language};
 use parseltongue_core::query_extractor::{ParsedEntity, QueryBasedExtractor};
 use std::path::Path;
 
@@ -330,3 +330,508 @@ func (s *Server) Start() {
     assert!(!config_edges.is_empty(), "Expected edge to Config");
     assert!(!goroutine_edges.is_empty(), "Expected edge to handleRequests");
 }
+
+// ============================================================================
+// REQ-GO-006.0: Generic Instantiation (Go 1.18+)
+// ============================================================================
+
+#[test]
+fn test_go_generic_function_definition_entity() {
+    let code = r#"
+package main
+
+func Map[T any, U any](xs []T, f func(T) U) []U {
+    out := make([]U, 0, len(xs))
+    for _, x := range xs {
+        out = append(out, f(x))
+    }
+    return out
+}
+
+type Box[T any] struct {
+    Value T
+}
+    "#;
+
+    let (entities, _edges) = parse_go_code(code);
+
+    println!("=== GO GENERIC DEFINITIONS ===");
+    for entity in &entities {
+        println!("  {:?} {} {:?}", entity.entity_type, entity.name, entity.metadata);
+    }
+
+    let map_fns: Vec<_> = entities.iter().filter(|e| e.name == "Map").collect();
+    assert_eq!(map_fns.len(), 1, "Generic function should produce exactly one entity");
+    assert_eq!(
+        map_fns[0].metadata.get("type_parameters").map(String::as_str),
+        Some("T any, U any")
+    );
+
+    let boxes: Vec<_> = entities.iter().filter(|e| e.name == "Box").collect();
+    assert_eq!(boxes.len(), 1, "Generic struct should produce exactly one entity");
+    assert_eq!(
+        boxes[0].metadata.get("type_parameters").map(String::as_str),
+        Some("T any")
+    );
+}
+
+#[test]
+fn test_go_generic_call_records_type_arguments() {
+    let code = r#"
+package main
+
+func run() {
+    names := Map[int, string](ids, format)
+    only := Filter[User](users, isActive)
+    total := Sum(values)
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    println!("=== GO GENERIC CALLS ===");
+    for edge in &edges {
+        println!("  {} -> {} {:?}", edge.from_key, edge.to_key, edge.metadata);
+    }
+
+    let map_edges: Vec<_> = edges.iter().filter(|e| e.to_key.as_str().contains(":Map:")).collect();
+    assert_eq!(map_edges.len(), 1, "Expected one Calls edge to Map");
+    assert_eq!(map_edges[0].edge_type, EdgeType::Calls);
+    assert!(map_edges[0].from_key.as_str().contains(":run:"));
+    assert_eq!(map_edges[0].metadata_value("type_arguments"), Some("int, string"));
+
+    // Single type argument parses as index_expression - still a generic call
+    let filter_edges: Vec<_> = edges.iter().filter(|e| e.to_key.as_str().contains(":Filter:")).collect();
+    assert_eq!(filter_edges.len(), 1, "Expected one Calls edge to Filter");
+    assert_eq!(filter_edges[0].metadata_value("type_arguments"), Some("User"));
+
+    // Inferred instantiation: plain call, no metadata
+    let sum_edges: Vec<_> = edges.iter().filter(|e| e.to_key.as_str().contains(":Sum:")).collect();
+    assert_eq!(sum_edges.len(), 1, "Expected one Calls edge to Sum");
+    assert!(sum_edges[0].metadata.is_empty());
+}
+
+#[test]
+fn test_go_generic_struct_literal_records_type_arguments() {
+    let code = r#"
+package main
+
+func build() {
+    b := Box[int]{Value: 1}
+    p := &Pair[string, int]{Key: "a", Val: 1}
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    println!("=== GO GENERIC STRUCT LITERALS ===");
+    for edge in &edges {
+        println!("  {} -> {} {:?}", edge.from_key, edge.to_key, edge.metadata);
+    }
+
+    let box_edge = edges
+        .iter()
+        .find(|e| e.to_key.as_str().contains(":Box:"))
+        .expect("Expected edge to generic Box type");
+    assert_eq!(box_edge.edge_type, EdgeType::Calls);
+    assert_eq!(box_edge.metadata_value("type_arguments"), Some("int"));
+
+    let pair_edge = edges
+        .iter()
+        .find(|e| e.to_key.as_str().contains(":Pair:"))
+        .expect("Expected edge to generic Pair type");
+    assert_eq!(pair_edge.metadata_value("type_arguments"), Some("string, int"));
+}
+
+#[test]
+fn test_go_index_call_not_treated_as_generic() {
+    let code = r#"
+package main
+
+func dispatch() {
+    handlers[i](request)
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    let handler_edges: Vec<_> = edges.iter().filter(|e| e.to_key.as_str().contains(":handlers:")).collect();
+    assert!(handler_edges.is_empty(), "Indexing a slice of funcs is not a generic instantiation");
+}
+
+// ============================================================================
+// REQ-GO-007.0: Method-Set Facts for Structural Implements
+// ============================================================================
+
+#[test]
+fn test_go_method_set_metadata_for_structural_matching() {
+    let code = r#"
+package store
+
+type UserStore interface {
+    Save(u User) error
+    Get(id string) (User, error)
+}
+
+type UserService struct {
+    db *DB
+}
+
+func (s *UserService) Get(id string) (User, error) {
+    return User{}, nil
+}
+
+func (s UserService) Save(u User) error {
+    return nil
+}
+
+func (b *Box[T]) Value() T {
+    return b.v
+}
+    "#;
+
+    let (entities, _edges) = parse_go_code(code);
+
+    println!("=== GO METHOD SETS ===");
+    for entity in &entities {
+        println!("  {:?} {} {:?}", entity.entity_type, entity.name, entity.metadata);
+    }
+
+    let store = entities.iter().find(|e| e.name == "UserStore").expect("UserStore interface");
+    assert_eq!(store.metadata.get("interface_methods").map(String::as_str), Some("Get,Save"));
+
+    let receivers: Vec<_> = entities
+        .iter()
+        .filter(|e| e.name == "Get" || e.name == "Save")
+        .map(|e| e.metadata.get("receiver_type").map(String::as_str))
+        .collect();
+    assert_eq!(receivers, vec![Some("UserService"), Some("UserService")]);
+
+    // Generic receiver strips pointer and type params
+    let value = entities.iter().find(|e| e.name == "Value").expect("Value method");
+    assert_eq!(value.metadata.get("receiver_type").map(String::as_str), Some("Box"));
+}
+
+// ============================================================================
+// REQ-GO-008.0: Embeds Edges (Struct/Interface Embedding)
+// ============================================================================
+
+#[test]
+fn test_go_embeds_edges_only_for_embedded_fields() {
+    let code = r#"
+package server
+
+type Server struct {
+    *Conn
+    Logger
+    sync.Mutex
+    Name string
+    port Port
+}
+
+type ReadCloser interface {
+    io.Reader
+    Closer
+    Name() string
+}
+
+func (s *Server) Shutdown() {
+    s.Close()
+}
+    "#;
+
+    let (entities, edges) = parse_go_code(code);
+
+    println!("=== GO EMBEDS ===");
+    for edge in &edges {
+        println!("  {} -{:?}-> {} {:?}", edge.from_key, edge.edge_type, edge.to_key, edge.metadata);
+    }
+
+    let embeds: Vec<_> = edges.iter().filter(|e| e.edge_type == EdgeType::Embeds).collect();
+    let targets: Vec<&str> = embeds
+        .iter()
+        .map(|e| e.to_key.as_str().split(':').nth(2).unwrap_or(""))
+        .collect();
+
+    for expected in ["Conn", "Logger", "Mutex", "Reader", "Closer"] {
+        assert!(targets.contains(&expected), "Expected Embeds edge to {}: {:?}", expected, targets);
+    }
+    // Named fields are not embeddings
+    assert!(!targets.contains(&"string"));
+    assert!(!targets.contains(&"Port"));
+
+    assert!(embeds
+        .iter()
+        .filter(|e| e.to_key.as_str().contains(":Conn:"))
+        .all(|e| e.from_key.as_str().contains(":struct:Server:")));
+
+    // Entity facts for cross-file promotion
+    let server = entities.iter().find(|e| e.name == "Server").expect("Server struct");
+    assert_eq!(server.metadata.get("embedded_types").map(String::as_str), Some("Conn,Logger,Mutex"));
+    let rc = entities.iter().find(|e| e.name == "ReadCloser").expect("ReadCloser interface");
+    assert_eq!(rc.metadata.get("embedded_types").map(String::as_str), Some("Reader,Closer"));
+    let shutdown = entities.iter().find(|e| e.name == "Shutdown").expect("Shutdown method");
+    assert_eq!(shutdown.metadata.get("receiver_name").map(String::as_str), Some("s"));
+
+    // Promoted call keeps its operand so the resolver can follow the embedding
+    let close = edges
+        .iter()
+        .find(|e| e.to_key.as_str().contains(":Close:"))
+        .expect("Expected call edge to Close");
+    assert_eq!(close.metadata_value("receiver_expr"), Some("s"));
+}
+
+#[test]
+fn test_go_local_variable_types_recorded_for_calls() {
+    let code = r#"
+package srv
+
+func Drain(s *Server, n int, u models.User) {
+    var c Conn
+    t := &Timer{}
+    q := Queue{}
+    x := NewThing()
+    s.Close()
+}
+"#;
+
+    let (entities, edges) = parse_go_code(code);
+
+    let drain = entities.iter().find(|e| e.name == "Drain").expect("Drain function");
+    assert_eq!(
+        drain.metadata.get("local_types").map(String::as_str),
+        Some("c=Conn,q=Queue,s=Server,t=Timer,u=models.User"),
+        "params, var declarations and composite literals; not ints or call results"
+    );
+    let close = edges
+        .iter()
+        .find(|e| e.to_key.as_str().contains(":Close:"))
+        .expect("Expected call edge to Close");
+    assert_eq!(close.metadata_value("receiver_expr"), Some("s"));
+}
+
+#[test]
+fn test_go_function_value_calls_marked_indirect() {
+    let code = r#"
+package server
+
+func (s *Server) Run(ctx Context, u User) {
+    handler := s.processUser
+    handler(ctx, u)
+
+    var fallback = logUser
+    fallback(u)
+
+    fallback = nil
+    fallback(u)
+
+    cb := func() {}
+    cb()
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    println!("=== GO FUNCTION VALUES ===");
+    for edge in &edges {
+        println!("  {} -{:?}-> {} {:?}", edge.from_key, edge.edge_type, edge.to_key, edge.metadata);
+    }
+
+    let method_value = edges
+        .iter()
+        .find(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(":processUser:"))
+        .expect("Expected indirect call edge to processUser");
+    assert_eq!(method_value.metadata_value("confidence"), Some("indirect"));
+    assert_eq!(method_value.metadata_value("via_binding"), Some("handler"));
+    assert_eq!(method_value.metadata_value("receiver_expr"), Some("s"));
+
+    let function_value: Vec<_> = edges
+        .iter()
+        .filter(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(":logUser:"))
+        .collect();
+    assert_eq!(function_value.len(), 1, "only the call before `fallback = nil` is retargeted");
+    assert_eq!(function_value[0].metadata_value("via_binding"), Some("fallback"));
+
+    // Rebinding to nil and closures keep the plain call edge
+    assert!(edges
+        .iter()
+        .filter(|e| e.to_key.as_str().contains(":fallback:") || e.to_key.as_str().contains(":cb:"))
+        .all(|e| e.metadata_value("confidence").is_none()));
+    assert!(!edges.iter().any(|e| e.to_key.as_str().contains(":handler:")));
+}
+
+#[test]
+fn test_go_function_value_binding_edge_cases() {
+    let code = r#"
+package server
+
+func Relay(next func()) {
+    next()
+
+    first := logUser
+    second := first
+    second()
+
+    wrapped := (logUser)
+    wrapped()
+
+    alias := next
+    alias()
+
+    f, err := lookup()
+    f()
+}
+
+func Other() {
+    second()
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    let calls_into = |name: &str| -> Vec<&DependencyEdge> {
+        edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(&format!(":{}:", name)))
+            .collect()
+    };
+
+    // A binding copied from another binding, or parenthesized, still names logUser
+    let mut via: Vec<_> = calls_into("logUser").iter().filter_map(|e| e.metadata_value("via_binding")).collect();
+    via.sort_unstable();
+    assert_eq!(via, vec!["second", "wrapped"]);
+
+    // Parameters and locals copied from them are not function names
+    assert_eq!(calls_into("next").len(), 1);
+    assert_eq!(calls_into("alias").len(), 1);
+    assert!(calls_into("alias")[0].metadata_value("confidence").is_none());
+    // `f, err := lookup()` binds nothing
+    assert_eq!(calls_into("f").len(), 1);
+
+    // Bindings do not leak into other functions
+    let other = calls_into("second");
+    assert_eq!(other.len(), 1);
+    assert!(other[0].from_key.as_str().contains(":Other:"));
+    assert!(other[0].metadata_value("via_binding").is_none());
+}
+
+#[test]
+fn test_go_statements_emit_spawns_edges() {
+    let code = r#"
+package server
+
+func (s *Server) Handle(req Request) {
+    go processData(req)
+    go s.flush()
+    validate(req)
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+
+    let spawns: Vec<_> = edges.iter().filter(|e| e.edge_type == EdgeType::Spawns).collect();
+    let targets: Vec<&str> = spawns
+        .iter()
+        .map(|e| e.to_key.as_str().split(':').nth(2).unwrap_or(""))
+        .collect();
+    assert_eq!(targets.len(), 2, "Expected one Spawns edge per go statement: {:?}", targets);
+    assert!(targets.contains(&"processData") && targets.contains(&"flush"));
+    assert!(spawns.iter().all(|e| e.from_key.as_str().contains(":method:Handle:")));
+
+    // The receiver is kept so `go s.flush()` resolves like `s.flush()`
+    let flush = spawns.iter().find(|e| e.to_key.as_str().contains(":flush:")).unwrap();
+    assert_eq!(flush.metadata_value("receiver_expr"), Some("s"));
+
+    // Regular calls are not spawns; spawned calls are still calls
+    assert!(!targets.contains(&"validate"));
+    assert!(edges
+        .iter()
+        .any(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(":processData:")));
+}
+
+#[test]
+fn test_go_package_value_references_emit_uses_edges() {
+    let code = r#"
+package api
+
+import "net/http"
+
+const maxRetries = 3
+
+func Health(w http.ResponseWriter, r *http.Request) {
+    for i := 0; i < maxRetries; i++ {
+        w.WriteHeader(http.StatusOK)
+    }
+}
+    "#;
+
+    let (entities, edges) = parse_go_code(code);
+
+    let constant = entities
+        .iter()
+        .find(|e| e.name == "maxRetries")
+        .expect("Expected maxRetries as an entity");
+    assert_eq!(constant.entity_type, parseltongue_core::query_extractor::EntityType::Constant);
+
+    let uses: Vec<&str> = edges
+        .iter()
+        .filter(|e| e.edge_type == EdgeType::Uses && e.from_key.as_str().contains(":fn:Health:"))
+        .map(|e| e.to_key.as_str())
+        .collect();
+    assert!(uses.iter().any(|k| k.starts_with("go:const:maxRetries:")), "{:?}", uses);
+    assert!(uses.contains(&"go:var:net/http.StatusOK:unresolved-reference:0-0"), "{:?}", uses);
+    // The loop variable is a local, not a package value
+    assert!(!uses.iter().any(|k| k.contains(":i:")));
+}
+
+#[test]
+fn test_go_spawns_edge_cases() {
+    let code = r#"
+package server
+
+func main() {
+    go jobs.Run(cfg)
+    job := worker
+    go job()
+}
+
+func (s *Server) Start() {
+    go s.pool.drain()
+    go func() {
+        tick()
+    }()
+}
+    "#;
+
+    let (_entities, edges) = parse_go_code(code);
+    let spawns: Vec<_> = edges.iter().filter(|e| e.edge_type == EdgeType::Spawns).collect();
+
+    // Package-qualified launch keeps its operand, like any selector call
+    let run = spawns.iter().find(|e| e.to_key.as_str().contains(":Run:")).expect("go jobs.Run");
+    assert!(run.from_key.as_str().starts_with("go:fn:main:"));
+    assert_eq!(run.metadata_value("receiver_expr"), Some("jobs"));
+
+    // A function value is retargeted for the Spawns edge as well as the Calls edge
+    let job = spawns.iter().find(|e| e.to_key.as_str().contains(":worker:")).expect("go job()");
+    assert_eq!(job.metadata_value("via_binding"), Some("job"));
+    assert!(!spawns.iter().any(|e| e.to_key.as_str().contains(":job:")));
+
+    // A chained operand is not a receiver name, so promotion cannot misfire on it
+    let drain = spawns.iter().find(|e| e.to_key.as_str().contains(":drain:")).expect("go s.pool.drain()");
+    assert!(drain.from_key.as_str().contains(":method:Start:"));
+    assert_eq!(drain.metadata_value("receiver_expr"), None);
+
+    // `go func() {...}()` spawns the closure entity, which owns the calls inside
+    assert_eq!(spawns.len(), 4, "{:?}", spawns);
+    let closure = spawns
+        .iter()
+        .find(|e| e.metadata_value("closure") == Some("literal"))
+        .expect("go func() {...}()");
+    assert!(closure.from_key.as_str().contains(":method:Start:"));
+    assert_eq!(closure.metadata_value("invocation"), Some("goroutine"));
+    let tick = edges
+        .iter()
+        .find(|e| e.edge_type == EdgeType::Calls && e.to_key.as_str().contains(":tick:"))
+        .expect("tick() call");
+    assert_eq!(tick.from_key, closure.to_key);
+}
@@ -290,3 +290,44 @@ public class UserService {
     assert!(has_constructor, "Expected constructor call edges");
     assert!(has_stream_ops, "Expected stream operation edges");
 }
+
+// ============================================================================
+// v1.7.3: Type Hierarchy and Package Metadata
+// ============================================================================
+
+#[test]
+fn test_java_type_hierarchy_edges_and_package() {
+    let code = r#"
+package com.acme.app;
+
+public interface Repository<T> extends AutoCloseable, Iterable<T> {}
+
+public class UserService extends BaseService<User> implements Repository<User>, Auditable {
+    public void save() { }
+}
+"#;
+
+    let mut extractor = QueryBasedExtractor::new().expect("Failed to create extractor");
+    let (entities, edges) = extractor
+        .parse_source(code, Path::new("UserService.java"), Language::Java)
+        .expect("Failed to parse Java code");
+
+    let save = entities.iter().find(|e| e.name == "save").expect("Expected method save");
+    assert_eq!(save.metadata.get("receiver_type").map(String::as_str), Some("UserService"));
+    assert_eq!(save.metadata.get("package_name").map(String::as_str), Some("com.acme.app"));
+
+    let supertype = |from: &str, to: &str| {
+        edges
+            .iter()
+            .find(|e| {
+                e.from_key.as_str().contains(&format!(":{}:", from))
+                    && e.to_key.as_str().contains(&format!(":{}:", to))
+            })
+            .and_then(|e| e.metadata_value("hierarchy_kind"))
+    };
+    assert_eq!(supertype("UserService", "BaseService"), Some("extends"));
+    assert_eq!(supertype("UserService", "Repository"), Some("implements"));
+    assert_eq!(supertype("UserService", "Auditable"), Some("implements"));
+    assert_eq!(supertype("Repository", "AutoCloseable"), Some("extends"));
+    assert_eq!(supertype("Repository", "Iterable"), Some("extends"));
+}
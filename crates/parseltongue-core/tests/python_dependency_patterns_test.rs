@@ -10,7 +10,7 @@
 // TDD Phase: RED (tests written first, should fail)
 
 use parseltongue_core::entities::{DependencyEdge, Language};
-use parseltongue_core::query_extractor::{ParsedEntity, QueryBasedExtractor};
+use parseltongue_core::query_extractor::{EntityType, ParsedEntity, QueryBasedExtractor};
 use std::path::Path;
 
 /// Helper: Parse Python code and extract entities + edges
@@ -425,3 +425,51 @@ class UserService:
         edges.len()
     );
 }
+
+// ============================================================================
+// PATTERN 6: Structural Metadata (v1.7.3)
+// ============================================================================
+
+#[test]
+fn test_python_method_structural_metadata() {
+    let code = r#"
+from .models import Record
+
+class Repository(BaseRepository):
+    @property
+    def config(self):
+        return self._config
+
+    def persist(self, record):
+        self.save(record)
+"#;
+
+    let (entities, edges) = extract_python_dependencies(code);
+
+    // Decorated methods stay methods and record their enclosing class
+    let config = entities
+        .iter()
+        .find(|e| e.name == "config")
+        .expect("Expected decorated method 'config'");
+    assert_eq!(config.entity_type, EntityType::Method);
+    assert_eq!(config.metadata.get("receiver_type").map(String::as_str), Some("Repository"));
+
+    let class = entities
+        .iter()
+        .find(|e| e.name == "Repository")
+        .expect("Expected class 'Repository'");
+    assert_eq!(class.metadata.get("base_classes").map(String::as_str), Some("BaseRepository"));
+
+    // self.save() keeps its receiver for post-pass resolution
+    let save_call = edges
+        .iter()
+        .find(|e| e.to_key.contains(":save:"))
+        .expect("Expected call edge to save");
+    assert_eq!(save_call.metadata_value("receiver_expr"), Some("self"));
+
+    // Relative imports produce module Uses edges
+    assert!(
+        edges.iter().any(|e| e.to_key == "python:module:Record:0-0"),
+        "Expected relative import edge to Record"
+    );
+}
@@ -392,11 +392,10 @@ fn t158_cpp_existing_function_calls() {
 }
 
 // ============================================================================
-// T155: C++ Include Directive Edges (Known Limitation - Ignored)
+// T155: C++ Include Directive Edges
 // ============================================================================
 
 #[test]
-#[ignore = "C++ #include directives are preprocessor-level; tree-sitter parses post-preprocessor AST"]
 fn t155_cpp_existing_includes() {
     let (_entities, edges) = parse_fixture_extract_results(
         "T155-cpp-include-directive-edges",
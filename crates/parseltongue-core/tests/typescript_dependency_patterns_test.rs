@@ -400,3 +400,50 @@ class DataService {
     // Should have edges for multiple patterns
     assert!(edges.len() >= 5, "Expected at least 5 edges from various patterns, found {}", edges.len());
 }
+
+// ============================================================================
+// v1.7.3: Module Bindings (imports, re-exports, CommonJS)
+// ============================================================================
+
+#[test]
+fn test_typescript_module_bindings_recorded() {
+    let code = r#"
+import { fetchUser as loadUser } from '@/api/users';
+import * as http from './http';
+import Store from '../store';
+export * from './models';
+export { formatDate } from './date';
+const { join } = require('path');
+
+class Page {
+    render() {
+        this.refresh();
+        http.get('/x');
+    }
+    refresh() { }
+}
+"#;
+
+    let edges = parse_typescript_code_extract_edges(code);
+    let binding = |local: &str| {
+        edges
+            .iter()
+            .find(|e| e.to_key.as_str() == format!("typescript:module:{}:0-0", local) && e.metadata_value("binding_kind").is_some())
+            .unwrap_or_else(|| panic!("Expected module binding for {}", local))
+    };
+
+    let renamed = binding("loadUser");
+    assert_eq!(renamed.metadata_value("binding_kind"), Some("named"));
+    assert_eq!(renamed.metadata_value("imported_name"), Some("fetchUser"));
+    assert_eq!(renamed.metadata_value("import_source"), Some("@/api/users"));
+    assert_eq!(binding("http").metadata_value("binding_kind"), Some("namespace"));
+    assert_eq!(binding("Store").metadata_value("binding_kind"), Some("default"));
+    assert_eq!(binding("*").metadata_value("binding_kind"), Some("reexport_all"));
+    assert_eq!(binding("formatDate").metadata_value("binding_kind"), Some("reexport"));
+    assert_eq!(binding("join").metadata_value("import_source"), Some("path"));
+
+    // Method call receivers feed `this.x()` / `ns.x()` resolution
+    let receivers: Vec<_> = edges.iter().filter_map(|e| e.metadata_value("receiver_expr")).collect();
+    assert!(receivers.contains(&"this"), "Expected this receiver, found {:?}", receivers);
+    assert!(receivers.contains(&"http"), "Expected http receiver, found {:?}", receivers);
+}
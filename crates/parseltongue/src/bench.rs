@@ -0,0 +1,169 @@
+//! Ingest Benchmark API (v1.7.3)
+//!
+//! # 4-Word Naming: run_repeated_ingest_benchmark
+//!
+//! Backs `parseltongue bench ingest`: the full parallel ingest runs `repeat`
+//! times into a fresh in-memory database, and each run reports throughput
+//! (files/s, MB/s) plus the time spent per phase: `scan` (walk), `parse`,
+//! `resolve` (cross-file passes) and `store` (persist). The median run and
+//! the process's peak resident set size summarize the series, so numbers
+//! from two releases on the same machine and repository can be compared.
+//!
+//! The in-memory database keeps disk speed out of `store`; it measures the
+//! CozoDB writes themselves.
+
+use std::collections::BTreeMap;
+
+use anyhow::Result;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+use pt01_folder_to_cozodb_streamer::streamer::FileStreamer;
+use pt01_folder_to_cozodb_streamer::ToolFactory;
+use serde::Serialize;
+
+use crate::ingest::DirectoryIngestRunOptions;
+
+/// Measurements of one ingest run
+///
+/// # 4-Word Name: IngestBenchmarkRunSample
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct IngestBenchmarkRunSample {
+    /// 1-based run number
+    pub run: usize,
+    pub files: usize,
+    pub input_bytes: u64,
+    pub entities: usize,
+    pub total_ms: u64,
+    pub files_per_sec: f64,
+    pub mb_per_sec: f64,
+    /// Milliseconds per phase: `scan`, `parse`, `resolve`, `store`
+    pub phase_ms: BTreeMap<String, u64>,
+}
+
+/// Median of every measurement across runs
+///
+/// # 4-Word Name: IngestBenchmarkMedianSummary
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct IngestBenchmarkMedianSummary {
+    pub total_ms: u64,
+    pub files_per_sec: f64,
+    pub mb_per_sec: f64,
+    pub phase_ms: BTreeMap<String, u64>,
+}
+
+/// Everything `bench ingest` prints
+///
+/// # 4-Word Name: IngestBenchmarkRunReport
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct IngestBenchmarkRunReport {
+    pub directory: String,
+    pub repeat: usize,
+    pub runs: Vec<IngestBenchmarkRunSample>,
+    pub median: IngestBenchmarkMedianSummary,
+    /// High-water mark of the process's resident set over all runs;
+    /// `None` where the platform does not expose it
+    pub peak_rss_bytes: Option<u64>,
+}
+
+/// Ingest `options`' directory `repeat` times and measure each run
+///
+/// # 4-Word Name: run_repeated_ingest_benchmark
+///
+/// # Contract
+/// - Precondition: `repeat >= 1`
+/// - Postcondition: the configured database is ignored; every run starts
+///   from an empty in-memory one, with progress output off
+pub async fn run_repeated_ingest_benchmark(
+    options: &DirectoryIngestRunOptions,
+    repeat: usize,
+) -> Result<IngestBenchmarkRunReport> {
+    anyhow::ensure!(repeat >= 1, "repeat must be at least 1");
+    let mut runs = Vec::with_capacity(repeat);
+    for run in 1..=repeat {
+        let mut config = options.clone().with_progress_output(IngestProgressOutputMode::Off).to_streamer_config();
+        config.db_path = "mem".to_string();
+        let streamer = ToolFactory::create_streamer(config).await?;
+        let result = streamer.stream_directory_with_parallel_rayon().await?;
+
+        let seconds = result.duration.as_secs_f64().max(f64::EPSILON);
+        runs.push(IngestBenchmarkRunSample {
+            run,
+            files: result.processed_files,
+            input_bytes: result.input_bytes,
+            entities: result.entities_created,
+            total_ms: result.duration.as_millis() as u64,
+            files_per_sec: result.processed_files as f64 / seconds,
+            mb_per_sec: result.input_bytes as f64 / (1024.0 * 1024.0) / seconds,
+            phase_ms: result.phase_timings_ms.iter().map(|(phase, ms)| (phase.to_string(), *ms)).collect(),
+        });
+    }
+    Ok(IngestBenchmarkRunReport {
+        directory: options.root_dir().display().to_string(),
+        repeat,
+        median: summarize_benchmark_run_medians(&runs),
+        runs,
+        peak_rss_bytes: read_peak_resident_set_bytes(),
+    })
+}
+
+/// Median run time, throughput and phase times (upper median for even counts)
+///
+/// # 4-Word Name: summarize_benchmark_run_medians
+pub fn summarize_benchmark_run_medians(runs: &[IngestBenchmarkRunSample]) -> IngestBenchmarkMedianSummary {
+    fn median<T: Copy + PartialOrd>(mut values: Vec<T>) -> Option<T> {
+        values.sort_by(|a, b| a.partial_cmp(b).unwrap_or(std::cmp::Ordering::Equal));
+        values.get(values.len() / 2).copied()
+    }
+    let phases: std::collections::BTreeSet<&String> = runs.iter().flat_map(|r| r.phase_ms.keys()).collect();
+    IngestBenchmarkMedianSummary {
+        total_ms: median(runs.iter().map(|r| r.total_ms).collect()).unwrap_or(0),
+        files_per_sec: median(runs.iter().map(|r| r.files_per_sec).collect()).unwrap_or(0.0),
+        mb_per_sec: median(runs.iter().map(|r| r.mb_per_sec).collect()).unwrap_or(0.0),
+        phase_ms: phases
+            .into_iter()
+            .map(|phase| {
+                let values = runs.iter().map(|r| r.phase_ms.get(phase).copied().unwrap_or(0)).collect();
+                (phase.clone(), median(values).unwrap_or(0))
+            })
+            .collect(),
+    }
+}
+
+/// `VmHWM` of `/proc/self/status`, in bytes
+fn read_peak_resident_set_bytes() -> Option<u64> {
+    let status = std::fs::read_to_string("/proc/self/status").ok()?;
+    parse_vm_hwm_status_bytes(&status)
+}
+
+fn parse_vm_hwm_status_bytes(status: &str) -> Option<u64> {
+    let line = status.lines().find(|line| line.starts_with("VmHWM:"))?;
+    let kilobytes: u64 = line.trim_start_matches("VmHWM:").trim().trim_end_matches("kB").trim().parse().ok()?;
+    Some(kilobytes * 1024)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_median_summary_and_peak_rss_parsing() {
+        let sample = |run: usize, total_ms: u64, parse_ms: u64| IngestBenchmarkRunSample {
+            run,
+            files: 100,
+            input_bytes: 1024 * 1024,
+            entities: 900,
+            total_ms,
+            files_per_sec: 100_000.0 / total_ms as f64,
+            mb_per_sec: 1000.0 / total_ms as f64,
+            phase_ms: BTreeMap::from([("parse".to_string(), parse_ms), ("store".to_string(), total_ms - parse_ms)]),
+        };
+        let median = summarize_benchmark_run_medians(&[sample(1, 900, 600), sample(2, 500, 300), sample(3, 700, 400)]);
+        assert_eq!(median.total_ms, 700);
+        assert_eq!(median.phase_ms["parse"], 400);
+        assert_eq!(median.phase_ms["store"], 300);
+        assert!((median.files_per_sec - 100_000.0 / 700.0).abs() < 1e-9);
+
+        let status = "Name:\tparseltongue\nVmPeak:\t  900000 kB\nVmHWM:\t   51200 kB\nVmRSS:\t   40000 kB\n";
+        assert_eq!(parse_vm_hwm_status_bytes(status), Some(51200 * 1024));
+        assert_eq!(parse_vm_hwm_status_bytes("Name:\tx\n"), None);
+    }
+}
@@ -0,0 +1,622 @@
+//! Analyze CLI command (v1.7.3)
+//!
+//! # 4-Word Naming: analyze_subcommand_definition_list
+//!
+//! Backs `analyze`: dead code, cycles, rename impact, unresolved references,
+//! context propagation, security sinks and entry points.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: analyze_subcommand_definition_list
+pub(crate) fn analyze_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("analyze")
+            .about("Whole-graph analyses (dead code, cycles, rename impact, unresolved references, context propagation, security sinks, entry points)")
+            .subcommand_required(true)
+            .subcommand(
+                Command::new("dead-code")
+                    .about("Report symbols with zero inbound edges")
+                    .long_about(
+                        "Lists functions, types, consts and vars nothing in the graph points at.\n\
+                        main/init, tests, dynamic-usage (reflection/registry) symbols and Go methods\n\
+                        required by an implemented interface are never reported. Exported symbols\n\
+                        are flagged: code outside the indexed tree may still use them.\n\n\
+                        Examples:\n  \
+                        parseltongue analyze dead-code --db rocksdb:analysis.db\n  \
+                        parseltongue analyze dead-code --db rocksdb:analysis.db --unexported-only --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("unexported-only")
+                            .long("unexported-only")
+                            .help("Only report symbols not visible outside their package (safe to delete)")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("cycles")
+                    .about("Find dependency cycles and a minimal edge set that breaks each")
+                    .long_about(
+                        "Reports strongly connected components of the dependency graph at package\n\
+                        (directory) level and at symbol level. For each cycle, lists a minimal set of\n\
+                        edges whose removal makes it acyclic, preferring light edges (package edges are\n\
+                        weighted by how many symbol edges they stand for).\n\n\
+                        Examples:\n  \
+                        parseltongue analyze cycles --db rocksdb:analysis.db\n  \
+                        parseltongue analyze cycles --db rocksdb:analysis.db --level package --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("level")
+                            .long("level")
+                            .value_parser(["package", "symbol", "all"])
+                            .default_value("all")
+                            .help("Which graph to analyze"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("rename")
+                    .about("List every file/line a rename must touch (does not edit)")
+                    .long_about(
+                        "Plans a rename without performing it: the definition, every reference, Go\n\
+                        interface methods and sibling implementer methods that must change with it,\n\
+                        plus name-only matches (unresolved calls, string registrations) to review.\n\
+                        SYMBOL is an entity key or a name; an ambiguous name lists the candidates.\n\n\
+                        Examples:\n  \
+                        parseltongue analyze rename Save Persist --db rocksdb:analysis.db\n  \
+                        parseltongue analyze rename 'go:method:Save:__store_disk:T10' Persist --db rocksdb:analysis.db --json"
+                    )
+                    .arg(Arg::new("symbol").help("Entity key or symbol name").required(true))
+                    .arg(Arg::new("new-name").help("New identifier").required(true))
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("unresolved")
+                    .about("List references the resolvers could not bind, grouped by cause")
+                    .long_about(
+                        "Lists every edge that ends in a placeholder instead of an entity: the places\n\
+                        where the graph is blind. Causes: missing-dependency (target outside the\n\
+                        indexed tree), parse-gap (an indexed symbol of that name exists but the\n\
+                        reference was not bound) and dynamic-dispatch (interface, trait object or\n\
+                        function value calls).\n\n\
+                        Examples:\n  \
+                        parseltongue analyze unresolved --db rocksdb:analysis.db\n  \
+                        parseltongue analyze unresolved --db rocksdb:analysis.db --cause parse-gap --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("cause")
+                            .long("cause")
+                            .value_parser(["missing-dependency", "parse-gap", "dynamic-dispatch"])
+                            .help("Only report this cause"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("context-propagation")
+                    .about("Go functions that drop, shadow or replace their context.Context")
+                    .long_about(
+                        "Follows context.Context through Go call chains and reports where it breaks:\n\
+                        replaced (passes context.Background()/TODO() or nil while holding a context),\n\
+                        unrelated (passes a context not derived from its own), chain-broken (takes no\n\
+                        context although context-holding functions call it, and mints a fresh one),\n\
+                        shadowed (rebinds ctx to an unrelated context) and unused (never reads ctx).\n\
+                        Calls inside `go func() {...}()` are not checked.\n\n\
+                        Examples:\n  \
+                        parseltongue analyze context-propagation --db rocksdb:analysis.db\n  \
+                        parseltongue analyze context-propagation --db rocksdb:analysis.db --kind replaced --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("kind")
+                            .long("kind")
+                            .value_parser(["replaced", "unrelated", "chain-broken", "shadowed", "unused"])
+                            .help("Only report this kind of issue"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("sinks")
+                    .about("Dangerous sinks (exec, SQL concatenation, unsafe deserialization, eval) reachable from HTTP/RPC entry points")
+                    .long_about(
+                        "Scans function bodies for command execution, SQL built by concatenation or\n\
+                        formatting, unsafe deserialization and eval, then walks the call graph back\n\
+                        from each sink to the HTTP routes and gRPC methods that reach it. Reachability\n\
+                        only: no data flow is tracked, so read the snippet to judge each path.\n\n\
+                        Examples:\n  \
+                        parseltongue analyze sinks --db rocksdb:analysis.db\n  \
+                        parseltongue analyze sinks --db rocksdb:analysis.db --category command-exec --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("category")
+                            .long("category")
+                            .value_parser(["command-exec", "sql-concat", "unsafe-deserialization", "code-eval"])
+                            .help("Only report this kind of sink"),
+                    )
+                    .arg(
+                        Arg::new("max-depth")
+                            .long("max-depth")
+                            .value_name("HOPS")
+                            .help("Call hops followed back from a sink (default 12)")
+                            .value_parser(clap::value_parser!(usize)),
+                    )
+                    .arg(
+                        Arg::new("include-unreachable")
+                            .long("include-unreachable")
+                            .help("Also list sinks no entry point reaches")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("entrypoints")
+                    .about("Where execution enters the code: main, HTTP routes, gRPC methods, CLI commands, cron jobs")
+                    .long_about(
+                        "Lists main functions, HTTP routes and gRPC methods with their handlers, CLI\n\
+                        command registrations (cobra, urfave/cli, commander, click, clap derives,\n\
+                        picocli) and cron/job registrations (robfig/cron, node-cron, APScheduler,\n\
+                        Celery, @Scheduled), plus symbols marked companion:entrypoint. Each label\n\
+                        is a valid `parseltongue slice --from` argument.\n\n\
+                        Examples:\n  \
+                        parseltongue analyze entrypoints --db rocksdb:analysis.db\n  \
+                        parseltongue analyze entrypoints --db rocksdb:analysis.db --kind cli --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("kind")
+                            .long("kind")
+                            .value_parser(["main", "http", "grpc", "cli", "job", "directive"])
+                            .help("Only report this kind of entry point"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            ),
+    ]
+}
+
+pub(crate) async fn run_graph_analyze_command(matches: &ArgMatches) -> Result<()> {
+    match matches.subcommand() {
+        Some(("dead-code", sub_matches)) => run_dead_code_report_command(sub_matches).await,
+        Some(("cycles", sub_matches)) => run_dependency_cycles_report_command(sub_matches).await,
+        Some(("rename", sub_matches)) => run_rename_impact_report_command(sub_matches).await,
+        Some(("unresolved", sub_matches)) => run_unresolved_references_report_command(sub_matches).await,
+        Some(("context-propagation", sub_matches)) => run_context_propagation_audit_command(sub_matches).await,
+        Some(("sinks", sub_matches)) => run_security_sink_report_command(sub_matches).await,
+        Some(("entrypoints", sub_matches)) => run_entry_point_report_command(sub_matches).await,
+        _ => anyhow::bail!("Unknown analysis; see `parseltongue analyze --help`"),
+    }
+}
+
+async fn run_dead_code_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::dead_code_detection_analyzer::find_unreferenced_dead_code_entities;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut dead = find_unreferenced_dead_code_entities(&entities, &edges);
+    if matches.get_flag("unexported-only") {
+        dead.retain(|d| !d.exported);
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total_dead_code": dead.len(),
+            "exported": dead.iter().filter(|d| d.exported).count(),
+            "dead_code": dead,
+        }))?);
+        return Ok(());
+    }
+
+    if dead.is_empty() {
+        println!("{}", style("✓ No unreferenced symbols").green());
+        return Ok(());
+    }
+    let name_width = dead.iter().map(|d| d.name.len()).max().unwrap_or(4).clamp(4, 40);
+    println!("{:<8}  {:<10}  {:<name_width$}  LOCATION", "EXPORTED", "KIND", "NAME");
+    for entry in &dead {
+        println!(
+            "{:<8}  {:<10}  {:<name_width$}  {}:{}",
+            if entry.exported { "yes" } else { "no" },
+            entry.entity_type,
+            entry.name,
+            entry.file_path,
+            entry.line_start
+        );
+    }
+    println!(
+        "Total unreferenced: {} ({} exported)",
+        dead.len(),
+        dead.iter().filter(|d| d.exported).count()
+    );
+    Ok(())
+}
+
+async fn run_dependency_cycles_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::dependency_cycle_breaking_analyzer::analyze_dependency_cycles_both_levels;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let level = matches.get_one::<String>("level").map(String::as_str).unwrap_or("all");
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut result = analyze_dependency_cycles_both_levels(&entities, &edges);
+    if level == "symbol" {
+        result.package_cycles.clear();
+    }
+    if level == "package" {
+        result.symbol_cycles.clear();
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "level": level,
+            "total_package_cycles": result.package_cycles.len(),
+            "total_symbol_cycles": result.symbol_cycles.len(),
+            "package_cycles": result.package_cycles,
+            "symbol_cycles": result.symbol_cycles,
+        }))?);
+        return Ok(());
+    }
+
+    let sections = [
+        ("package", "Package cycles", &result.package_cycles),
+        ("symbol", "Symbol cycles", &result.symbol_cycles),
+    ];
+    for (_, title, cycles) in sections.into_iter().filter(|(section, _, _)| level == "all" || level == *section) {
+        if cycles.is_empty() {
+            println!("{}", style(format!("✓ No {}", title.to_lowercase())).green());
+            continue;
+        }
+        println!("{} ({}):", style(title).bold(), cycles.len());
+        for (index, cycle) in cycles.iter().enumerate() {
+            println!(
+                "  #{} {} members, {} internal edges",
+                index + 1,
+                cycle.members.len(),
+                cycle.internal_edge_count
+            );
+            for member in &cycle.members {
+                println!("      {}", member);
+            }
+            println!("    break by removing:");
+            for edge in &cycle.break_edges {
+                println!("      {} -> {}  {}", edge.from, edge.to, style(format!("(weight {})", edge.weight)).dim());
+            }
+        }
+    }
+    Ok(())
+}
+
+async fn run_rename_impact_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::rename_impact_site_planner::{plan_symbol_rename_impact_sites, resolve_rename_target_entities};
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let new_name = matches.get_one::<String>("new-name").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let is_identifier = new_name.starts_with(|c: char| c.is_alphabetic() || c == '_')
+        && new_name.chars().all(|c| c.is_alphanumeric() || c == '_');
+    if !is_identifier {
+        anyhow::bail!("`{}` is not a valid identifier", new_name);
+    }
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let targets = resolve_rename_target_entities(symbol, &entities);
+    let target = match targets.as_slice() {
+        [] => anyhow::bail!("No symbol matches `{}`", symbol),
+        [target] => *target,
+        candidates => {
+            let keys: Vec<&str> = candidates.iter().map(|e| e.isgl1_key.as_str()).collect();
+            anyhow::bail!("`{}` is ambiguous; pass one of these keys:\n  {}", symbol, keys.join("\n  "));
+        }
+    };
+    let plan = plan_symbol_rename_impact_sites(target, new_name, &entities, &edges);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&plan)?);
+        return Ok(());
+    }
+
+    println!(
+        "{} {} -> {} ({})",
+        style("Rename").bold(),
+        plan.old_name,
+        plan.new_name,
+        plan.symbol_key
+    );
+    let mut current_file = "";
+    for site in &plan.sites {
+        if site.file_path != current_file {
+            current_file = &site.file_path;
+            println!("{}", style(current_file).cyan());
+        }
+        let marker = if site.certain { String::new() } else { style(" (review)").yellow().to_string() };
+        println!("  {:>5}  {:<20}  {}{}", site.line, site.kind, site.entity_key, marker);
+    }
+    println!("{} sites in {} files", plan.sites.len(), plan.files_affected);
+    for conflict in &plan.conflicts {
+        println!("{} `{}` already exists: {}", style("⚠ conflict:").red(), plan.new_name, conflict);
+    }
+    Ok(())
+}
+
+async fn run_unresolved_references_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::unresolved_reference_cause_reporter::collect_unresolved_reference_report;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut groups = collect_unresolved_reference_report(&entities, &edges);
+    if let Some(cause) = matches.get_one::<String>("cause") {
+        groups.retain(|group| group.cause.as_str() == cause.as_str());
+    }
+    let total_references: usize = groups.iter().map(|g| g.reference_count).sum();
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total_references": total_references,
+            "total_edges": edges.len(),
+            "groups": groups,
+        }))?);
+        return Ok(());
+    }
+
+    if groups.is_empty() {
+        println!("{}", style("✓ Every reference is bound to an entity").green());
+        return Ok(());
+    }
+    for group in &groups {
+        println!(
+            "{} ({} references, {} targets)",
+            style(group.cause.as_str()).bold(),
+            group.reference_count,
+            group.targets.len()
+        );
+        for target in &group.targets {
+            let candidates = if target.candidate_count > 0 {
+                format!("  [{} candidates]", target.candidate_count)
+            } else {
+                String::new()
+            };
+            println!("  {:>5}  {}{}", target.sites.len(), target.name, candidates);
+            for site in target.sites.iter().take(3) {
+                println!("         {}  {}", site.location.as_deref().unwrap_or("-"), site.from_key);
+            }
+            if target.sites.len() > 3 {
+                println!("         … and {} more", target.sites.len() - 3);
+            }
+        }
+    }
+    println!(
+        "Total unresolved: {} of {} edges ({:.1}%)",
+        total_references,
+        edges.len(),
+        total_references as f64 * 100.0 / edges.len().max(1) as f64
+    );
+    Ok(())
+}
+
+async fn run_context_propagation_audit_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::go_context_propagation_auditor::audit_go_context_propagation;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut issues = audit_go_context_propagation(&entities, &edges);
+    if let Some(kind) = matches.get_one::<String>("kind") {
+        issues.retain(|issue| issue.kind.as_str() == kind.as_str());
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total_issues": issues.len(),
+            "issues": issues,
+        }))?);
+        return Ok(());
+    }
+
+    if issues.is_empty() {
+        println!("{}", style("✓ Every context.Context reaches its callees").green());
+        return Ok(());
+    }
+    let mut current_kind = None;
+    for issue in &issues {
+        if current_kind != Some(issue.kind) {
+            current_kind = Some(issue.kind);
+            let count = issues.iter().filter(|i| i.kind == issue.kind).count();
+            println!("{} ({})", style(issue.kind.as_str()).bold(), count);
+        }
+        println!("  {}  {}", issue.location.as_deref().unwrap_or("-"), issue.entity_key);
+        println!("      {}", style(&issue.detail).dim());
+    }
+    println!("Total issues: {}", issues.len());
+    Ok(())
+}
+
+async fn run_security_sink_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::security_sink_reachability_analyzer::{
+        analyze_security_sink_reachability, SecuritySinkCategory, DEFAULT_SINK_PATH_MAX_DEPTH,
+    };
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let max_depth = matches.get_one::<usize>("max-depth").copied().unwrap_or(DEFAULT_SINK_PATH_MAX_DEPTH);
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut findings = analyze_security_sink_reachability(&entities, &edges, max_depth);
+    let total_sinks = findings.len();
+    if let Some(category) = matches.get_one::<String>("category").and_then(|c| SecuritySinkCategory::parse_sink_category_name(c)) {
+        findings.retain(|f| f.site.category == category);
+    }
+    if !matches.get_flag("include-unreachable") {
+        findings.retain(|f| !f.reachable_from.is_empty());
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total_sinks": total_sinks,
+            "reported": findings.len(),
+            "findings": findings,
+        }))?);
+        return Ok(());
+    }
+
+    if findings.is_empty() {
+        println!("{}", style(format!("✓ No reachable sinks ({} sink(s) found in total)", total_sinks)).green());
+        return Ok(());
+    }
+    let name_of: std::collections::HashMap<&str, &str> = entities
+        .iter()
+        .map(|e| (e.isgl1_key.as_str(), e.interface_signature.name.as_str()))
+        .collect();
+    for finding in &findings {
+        println!(
+            "{} {}  {}",
+            style(finding.site.category.as_str()).bold(),
+            finding.site.call,
+            finding.site.location.as_deref().unwrap_or("-")
+        );
+        println!("    {}", style(&finding.site.snippet).dim());
+        if finding.reachable_from.is_empty() {
+            println!("    not reached from an entry point");
+        }
+        for reach in finding.reachable_from.iter().take(5) {
+            let hops: Vec<&str> = reach.path[1..].iter().map(|k| name_of.get(k.as_str()).copied().unwrap_or(k.as_str())).collect();
+            println!("    {} -> {}", style(&reach.entry_label).cyan(), hops.join(" -> "));
+        }
+        if finding.reachable_from.len() > 5 {
+            println!("    ... {} more entry point(s) (--json lists all)", finding.reachable_from.len() - 5);
+        }
+    }
+    println!("Reported sinks: {} of {}", findings.len(), total_sinks);
+    Ok(())
+}
+
+async fn run_entry_point_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::entry_point_reachable_slicer::{find_feature_entry_points, FeatureEntryPointKind};
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let mut entry_points = find_feature_entry_points(&entities, &edges);
+    if let Some(kind) = matches.get_one::<String>("kind").and_then(|k| FeatureEntryPointKind::parse_entry_kind_name(k)) {
+        entry_points.retain(|e| e.kind == kind);
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total": entry_points.len(),
+            "entry_points": entry_points,
+        }))?);
+        return Ok(());
+    }
+
+    if entry_points.is_empty() {
+        println!("No entry points found");
+        return Ok(());
+    }
+    let name_of: std::collections::HashMap<&str, &str> = entities
+        .iter()
+        .map(|e| (e.isgl1_key.as_str(), e.interface_signature.name.as_str()))
+        .collect();
+    for entry in &entry_points {
+        let handlers: Vec<&str> = entry
+            .handler_keys
+            .iter()
+            .map(|k| name_of.get(k.as_str()).copied().unwrap_or(k.as_str()))
+            .collect();
+        let via = if handlers.is_empty() { String::new() } else { format!(" -> {}", handlers.join(", ")) };
+        println!(
+            "{} {}  {}{}  {}",
+            style(format!("{:<9}", entry.kind.as_str())).bold(),
+            style(&entry.label).cyan(),
+            name_of.get(entry.entry_key.as_str()).copied().unwrap_or(entry.entry_key.as_str()),
+            via,
+            style(entry.location.as_deref().unwrap_or("-")).dim()
+        );
+    }
+    println!("Entry points: {}", entry_points.len());
+    Ok(())
+}
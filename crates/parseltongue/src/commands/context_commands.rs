@@ -0,0 +1,674 @@
+//! Context CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: context_subcommand_definition_list
+//!
+//! Backs `pack-context`, `body-chunk`, `context`, `review-pack` and `api-diff`:
+//! token-budgeted bundles around a symbol or a git change.
+
+use clap::parser::ValueSource;
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use parseltongue::config::ProjectConfigFileSpec;
+use parseltongue_core::traversal_expansion_limit_guards::TraversalExpansionLimitSpec;
+
+use crate::commands::history_commands::RevisionIndexCacheLayout;
+use crate::commands::shared_cli_arg_helpers::{
+    flag_or_config_value, load_working_directory_config, open_storage_resolve_symbol, traversal_limit_args,
+    traversal_limits_from_matches,
+};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: context_subcommand_definition_list
+pub(crate) fn context_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("pack-context")
+            .about("Emit a context bundle around a symbol that fits an LLM token budget")
+            .long_about(
+                "Walks callers/callees of the seed by relevance. Signatures are added first,\n\
+                then upgraded to full bodies while budget remains. Token counts use the\n\
+                cl100k_base tokenizer, or the --model preset's; the rendered output is\n\
+                guaranteed to fit. --token-report writes each packed symbol's token cost.\n\n\
+                Examples:\n  \
+                parseltongue pack-context handle_login --db rocksdb:analysis.db --budget 8000\n  \
+                parseltongue pack-context handle_login --db rocksdb:analysis.db --model gpt-4o   # 128k, o200k_base\n  \
+                parseltongue pack-context handle_login --db rocksdb:analysis.db --model gpt-4-32k --token-report pack.tokens.json\n  \
+                parseltongue pack-context 'rust:fn:main:src_main_rs:T1' --db rocksdb:analysis.db --json\n  \
+                parseltongue pack-context CreateUser --db rocksdb:analysis.db --include-tests   # + covering Go tests\n  \
+                parseltongue pack-context Register --db rocksdb:analysis.db --expand-impls 2   # 2 impls per interface call\n  \
+                parseltongue pack-context CreateUser --db rocksdb:analysis.db --template claude-xml\n  \
+                parseltongue pack-context CreateUser --db rocksdb:analysis.db --recency --ticket PAY-123\n  \
+                parseltongue pack-context Charge --db rocksdb:analysis.db --team @acme/payments   # + symbols other teams own"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Seed symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("budget")
+                    .long("budget")
+                    .help("Token budget for the whole bundle")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("8000"),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum caller/callee hops from the seed")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("2"),
+            )
+            .args(traversal_limit_args())
+            .arg(
+                Arg::new("include-tests")
+                    .long("include-tests")
+                    .help("Also pack test entities; tests covering the seed rank right after its callees")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("recency")
+                    .long("recency")
+                    .help("Rank recently changed symbols higher (index built with --git-blame)")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("half-life")
+                    .long("half-life")
+                    .help("Days after which the recency boost halves (implies --recency)")
+                    .value_parser(clap::value_parser!(f64)),
+            )
+            .arg(
+                Arg::new("ticket")
+                    .long("ticket")
+                    .help("Rank symbols changed by commits naming this ticket (e.g. PAY-123, #45) higher"),
+            )
+            .arg(
+                Arg::new("team")
+                    .long("team")
+                    .help("Team handles (CODEOWNERS, e.g. @acme/payments); list packed symbols other teams own"),
+            )
+            .arg(
+                Arg::new("template")
+                    .long("template")
+                    .help("Prompt template: plain, claude-xml, gpt-markdown, markdown, or a template file")
+                    .conflicts_with("json"),
+            )
+            .arg(
+                Arg::new("expand-impls")
+                    .long("expand-impls")
+                    .value_name("N")
+                    .help("Pack up to N implementations of each interface/trait method a packed symbol calls")
+                    .value_parser(clap::value_parser!(usize)),
+            )
+            .arg(
+                Arg::new("model")
+                    .long("model")
+                    .help("Model preset (gpt-4, gpt-4-32k, gpt-4o, claude-3-5-sonnet, ...): budget and tokenizer"),
+            )
+            .arg(
+                Arg::new("token-report")
+                    .long("token-report")
+                    .value_name("PATH")
+                    .help("Write per-symbol token costs as JSON to PATH"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the bundle as JSON instead of prompt text")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("body-chunk")
+            .about("Print one chunk of a symbol body that a context pack only partly included")
+            .long_about(
+                "Bodies too large for the remaining budget are packed as leading chunks of\n\
+                overlapping lines; the item's `chunks` field lists every chunk's line span.\n\
+                This prints one of the chunks the pack left out, under the same line header.\n\n\
+                Examples:\n  \
+                parseltongue body-chunk 'go:fn:Migrate:__internal_db:T12' --chunk 3 --db rocksdb:analysis.db\n  \
+                parseltongue body-chunk Migrate --chunk 2 --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("chunk")
+                    .long("chunk")
+                    .help("Chunk number (1-based, as in the pack's chunks.spans)")
+                    .value_parser(clap::value_parser!(usize))
+                    .required(true),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the chunk as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("context")
+            .about("Emit a context bundle for the symbols a git change touches")
+            .long_about(
+                "Maps the changed lines of `git diff` to indexed symbols, then packs them with\n\
+                their callers/callees into the token budget (same guarantee as pack-context).\n\
+                Index the new side of the diff (the working tree for --since) so line numbers match.\n\n\
+                Examples:\n  \
+                parseltongue context --since HEAD~1 --db rocksdb:analysis.db\n  \
+                parseltongue context --diff main..feature --db rocksdb:analysis.db --budget 16000 --json\n  \
+                parseltongue context --since HEAD~1 --db rocksdb:analysis.db --template ./prompt.tmpl\n  \
+                parseltongue context --since HEAD~1 --db rocksdb:analysis.db --team @acme/payments"
+            )
+            .arg(
+                Arg::new("since")
+                    .long("since")
+                    .help("Revision to diff the working tree against (e.g. HEAD~1)")
+                    .conflicts_with("diff")
+                    .required_unless_present("diff"),
+            )
+            .arg(
+                Arg::new("diff")
+                    .long("diff")
+                    .help("Revision range to diff (e.g. main..feature)"),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("repo")
+                    .long("repo")
+                    .help("Git repository to diff")
+                    .default_value("."),
+            )
+            .arg(
+                Arg::new("budget")
+                    .long("budget")
+                    .help("Token budget for the whole bundle")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("8000"),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum caller/callee hops from the changed symbols")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("2"),
+            )
+            .args(traversal_limit_args())
+            .arg(
+                Arg::new("include-tests")
+                    .long("include-tests")
+                    .help("Also pack test entities (tests covering the change)")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("recency")
+                    .long("recency")
+                    .help("Rank recently changed symbols higher (index built with --git-blame)")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("half-life")
+                    .long("half-life")
+                    .help("Days after which the recency boost halves (implies --recency)")
+                    .value_parser(clap::value_parser!(f64)),
+            )
+            .arg(
+                Arg::new("ticket")
+                    .long("ticket")
+                    .help("Rank symbols changed by commits naming this ticket (e.g. PAY-123, #45) higher"),
+            )
+            .arg(
+                Arg::new("team")
+                    .long("team")
+                    .help("Team handles (CODEOWNERS, e.g. @acme/payments); list packed symbols other teams own"),
+            )
+            .arg(
+                Arg::new("template")
+                    .long("template")
+                    .help("Prompt template: plain, claude-xml, gpt-markdown, markdown, or a template file")
+                    .conflicts_with("json"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the bundle as JSON instead of prompt text")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("review-pack")
+            .about("Emit a markdown review artifact for a git change")
+            .long_about(
+                "Sections with fixed headings: Changed Symbols (full bodies), Callers, Implementers,\n\
+                Related Tests, Public API Surface (exported symbols within --api-depth reverse hops)\n\
+                and Outside Team Ownership (touched symbols CODEOWNERS assigns to other teams; the\n\
+                team is --team or else the owner of most changed symbols).\n\
+                Empty sections are kept and say `_None._`, so prompt templates can rely on them.\n\n\
+                Examples:\n  \
+                parseltongue review-pack --since HEAD~1 --db rocksdb:analysis.db > review.md\n  \
+                parseltongue review-pack --diff main..feature --db rocksdb:analysis.db --json\n  \
+                parseltongue review-pack --since HEAD~1 --db rocksdb:analysis.db --team @acme/payments"
+            )
+            .arg(
+                Arg::new("since")
+                    .long("since")
+                    .help("Revision to diff the working tree against (e.g. HEAD~1)")
+                    .conflicts_with("diff")
+                    .required_unless_present("diff"),
+            )
+            .arg(
+                Arg::new("diff")
+                    .long("diff")
+                    .help("Revision range to diff (e.g. main..feature)"),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("repo")
+                    .long("repo")
+                    .help("Git repository to diff")
+                    .default_value("."),
+            )
+            .arg(
+                Arg::new("api-depth")
+                    .long("api-depth")
+                    .help("Reverse hops searched for affected exported symbols")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("3"),
+            )
+            .arg(
+                Arg::new("team")
+                    .long("team")
+                    .help("Team handles (CODEOWNERS) for Outside Team Ownership; default: owner of most changed symbols"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the bundle as JSON instead of markdown")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("api-diff")
+            .about("Report exported symbols added, removed or changed between two revisions")
+            .long_about(
+                "Indexes both revisions in a cached git worktree (incremental after the first run)\n\
+                and compares their exported symbols by signature. Sections with fixed headings:\n\
+                Removed, Changed Signatures, Added; the header states the semver impact.\n\n\
+                Examples:\n  \
+                parseltongue api-diff v1.4.0 HEAD\n  \
+                parseltongue api-diff main feature --repo ./service --json"
+            )
+            .arg(
+                Arg::new("from")
+                    .help("Old revision (tag, branch, commit)")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("to")
+                    .help("New revision")
+                    .required(true)
+                    .index(2),
+            )
+            .arg(
+                Arg::new("repo")
+                    .long("repo")
+                    .help("Git repository to compare")
+                    .default_value("."),
+            )
+            .arg(
+                Arg::new("cache-dir")
+                    .long("cache-dir")
+                    .help("Worktree and database cache [default: <git dir>/parseltongue-api-diff]"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the report as JSON instead of markdown")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+    ]
+}
+
+/// Recency scoring from `--recency` / `--half-life` / `--ticket` or `[context]`
+///
+/// # 4-Word Name: resolve_recency_scoring_config
+///
+/// `None` (no recency boost) unless one of the flags is given or the config
+/// sets `recency = true`.
+fn resolve_recency_scoring_config(
+    matches: &ArgMatches,
+    project_config: &ProjectConfigFileSpec,
+) -> Option<parseltongue_core::recency_relevance_score_booster::RecencyScoringConfigSpec> {
+    let context = &project_config.context;
+    let half_life = matches.get_one::<f64>("half-life").copied();
+    let ticket = matches.get_one::<String>("ticket").cloned();
+    let enabled = matches.get_flag("recency") || half_life.is_some() || ticket.is_some() || context.recency == Some(true);
+    if !enabled {
+        return None;
+    }
+    let mut config = parseltongue_core::recency_relevance_score_booster::RecencyScoringConfigSpec::with_current_time_defaults();
+    if let Some(days) = half_life.or(context.recency_half_life_days) {
+        config.half_life_days = days;
+    }
+    if let Some(weight) = context.recency_weight {
+        config.recency_weight = weight;
+    }
+    if let Some(weight) = context.ticket_weight {
+        config.ticket_weight = weight;
+    }
+    config.ticket = ticket;
+    Some(config)
+}
+
+/// Budget and model preset from `--model` / `--budget` or `[context]` (v1.7.3)
+///
+/// # 4-Word Name: resolve_model_token_budget
+///
+/// # Contract
+/// - Postcondition: without a model, the `--budget` / `[context] budget`
+///   value; with one, an explicit budget or else the model's context window
+/// - Error: unknown model, or an explicit budget above the model's window
+fn resolve_model_token_budget(
+    matches: &ArgMatches,
+    project_config: &ProjectConfigFileSpec,
+) -> Result<(usize, Option<&'static parseltongue_core::context_pack_token_accounting::ModelContextWindowPreset>)> {
+    let configured_budget = project_config.context.budget;
+    let Some(name) = matches.get_one::<String>("model").or(project_config.context.model.as_ref()) else {
+        return Ok((flag_or_config_value(matches, "budget", configured_budget), None));
+    };
+    let preset = parseltongue_core::context_pack_token_accounting::resolve_model_context_preset(name)?;
+    let explicit = match matches.value_source("budget") {
+        Some(ValueSource::CommandLine) => matches.get_one::<usize>("budget").copied(),
+        _ => configured_budget,
+    };
+    match explicit {
+        Some(budget) if budget > preset.context_window => anyhow::bail!(
+            "Budget {} exceeds the {}-token context window of {}",
+            budget,
+            preset.context_window,
+            preset.name
+        ),
+        Some(budget) => Ok((budget, Some(preset))),
+        None => Ok((preset.context_window, Some(preset))),
+    }
+}
+
+/// Prompt template from `--template` or `[context] template`
+///
+/// # 4-Word Name: resolve_context_output_template
+///
+/// A template file named in the config resolves against the config file's
+/// directory; `--template` paths resolve against the working directory.
+fn resolve_context_output_template(
+    matches: &ArgMatches,
+    project_config: &ProjectConfigFileSpec,
+) -> Result<parseltongue_core::context_prompt_template_renderer::ContextPackOutputTemplate> {
+    use parseltongue_core::context_prompt_template_renderer::{ContextPackOutputTemplate, BUILT_IN_TEMPLATE_NAMES};
+
+    let spec = match (matches.get_one::<String>("template"), &project_config.context.template) {
+        (Some(flag), _) => flag.clone(),
+        (None, Some(configured)) if BUILT_IN_TEMPLATE_NAMES.contains(&configured.as_str()) => configured.clone(),
+        (None, Some(configured)) => project_config.config_dir.join(configured).display().to_string(),
+        (None, None) => return Ok(ContextPackOutputTemplate::Plain),
+    };
+    Ok(ContextPackOutputTemplate::resolve_context_pack_template(&spec)?)
+}
+
+pub(crate) async fn run_pack_context_command(matches: &ArgMatches) -> Result<()> {
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let project_config = load_working_directory_config()?;
+    let (budget, model) = resolve_model_token_budget(matches, &project_config)?;
+    let tokenizer = model.map(|preset| preset.tokenizer).unwrap_or_default();
+    let depth = flag_or_config_value(matches, "depth", project_config.context.depth);
+    let include_tests = matches.get_flag("include-tests") || project_config.context.include_tests.unwrap_or(false);
+    let template = resolve_context_output_template(matches, &project_config)?;
+    let recency = resolve_recency_scoring_config(matches, &project_config);
+
+    let limits = TraversalExpansionLimitSpec { max_depth: depth, ..traversal_limits_from_matches(matches) };
+
+    let (storage, seed_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let mut bundle = parseltongue_core::token_budget_context_packer::build_context_pack_from_storage(
+        &storage, &seed_key, budget, limits, include_tests, &template, tokenizer, recency.as_ref(),
+        matches.get_one::<usize>("expand-impls").copied(),
+    ).await?;
+    if let Some(team) = matches.get_one::<String>("team") {
+        parseltongue_core::token_budget_context_packer::apply_ownership_team_to_bundle(&mut bundle, team, &template);
+    }
+    if let Some(path) = matches.get_one::<String>("token-report") {
+        let report = parseltongue_core::context_pack_token_accounting::build_context_pack_token_report(&bundle, model);
+        std::fs::write(path, serde_json::to_string_pretty(&report)?)?;
+        eprintln!("{} token report: {}", style("✓").green(), path);
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&bundle)?);
+    } else {
+        print!("{}", template.render_context_pack_bundle(&bundle));
+    }
+    // Summary on stderr so stdout stays pipeable into a prompt
+    eprintln!(
+        "{} {} entities ({} with bodies), {}/{} tokens ({})",
+        style("✓ Packed").green(),
+        bundle.items.len(),
+        bundle.items.iter().filter(|i| i.body_included).count(),
+        bundle.tokens_used,
+        bundle.token_budget,
+        bundle.tokenizer.as_str()
+    );
+    print_partial_body_chunk_hints(&bundle);
+    print_outside_ownership_summary(&bundle);
+    print_pack_truncation_markers(&bundle);
+
+    Ok(())
+}
+
+/// Count of packed symbols other teams own, when a team was given (stderr)
+fn print_outside_ownership_summary(bundle: &parseltongue_core::token_budget_context_packer::PackedContextBundleResult) {
+    if let Some(team) = &bundle.ownership_team {
+        eprintln!("  {} {} outside {} ownership", style("◆").cyan(), bundle.outside_team_ownership_items().len(), team);
+    }
+}
+
+/// Traversal cuts of a pack (stderr)
+fn print_pack_truncation_markers(bundle: &parseltongue_core::token_budget_context_packer::PackedContextBundleResult) {
+    for line in bundle.truncation.iter().flat_map(|report| report.render_truncation_marker_lines()) {
+        eprintln!("  {}", style(line).dim());
+    }
+}
+
+/// Point at `body-chunk` for every body a pack cut short (stderr)
+fn print_partial_body_chunk_hints(bundle: &parseltongue_core::token_budget_context_packer::PackedContextBundleResult) {
+    for item in &bundle.items {
+        if let Some(chunks) = &item.chunks {
+            eprintln!(
+                "  {} chunks 1-{} of {}; rest: parseltongue body-chunk '{}' --chunk {}",
+                style("◐").yellow(),
+                chunks.emitted.len(),
+                chunks.spans.len(),
+                item.entity_key,
+                chunks.emitted.len() + 1
+            );
+        }
+    }
+}
+
+pub(crate) async fn run_body_chunk_command(matches: &ArgMatches) -> Result<()> {
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let number = *matches.get_one::<usize>("chunk").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+
+    let (storage, entity_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let fetched =
+        parseltongue_core::token_budget_context_packer::load_body_chunk_from_storage(&storage, &entity_key, number)
+            .await?;
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&fetched)?);
+    } else {
+        println!("// {} (chunk {} of {})", fetched.entity_key, fetched.chunk.number, fetched.chunk_count);
+        println!("{}", fetched.text);
+    }
+    Ok(())
+}
+
+pub(crate) async fn run_diff_context_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::git_diff_context_scoper::{
+        build_diff_context_pack_from_storage, collect_git_diff_changed_lines,
+    };
+
+    let revision = matches
+        .get_one::<String>("since")
+        .or_else(|| matches.get_one::<String>("diff"))
+        .unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let repo = matches.get_one::<String>("repo").unwrap();
+    let project_config = load_working_directory_config()?;
+    let budget = flag_or_config_value(matches, "budget", project_config.context.budget);
+    let depth = flag_or_config_value(matches, "depth", project_config.context.depth);
+    let include_tests = matches.get_flag("include-tests") || project_config.context.include_tests.unwrap_or(false);
+    let template = resolve_context_output_template(matches, &project_config)?;
+    let recency = resolve_recency_scoring_config(matches, &project_config);
+
+    let limits = TraversalExpansionLimitSpec { max_depth: depth, ..traversal_limits_from_matches(matches) };
+
+    let ranges = collect_git_diff_changed_lines(std::path::Path::new(repo), revision)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut bundle = build_diff_context_pack_from_storage(
+        &storage, revision, &ranges, budget, limits, include_tests, &template, Default::default(), recency.as_ref(),
+    )
+    .await?;
+    if let Some(team) = matches.get_one::<String>("team") {
+        parseltongue_core::token_budget_context_packer::apply_ownership_team_to_bundle(&mut bundle, team, &template);
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "revision": revision,
+            "changed_hunks": ranges,
+            "bundle": bundle,
+        }))?);
+    } else {
+        print!("{}", template.render_context_pack_bundle(&bundle));
+    }
+    // Summary on stderr so stdout stays pipeable into a prompt
+    eprintln!(
+        "{} {} changed symbols in {} hunks, {} entities packed ({} with bodies), {}/{} tokens",
+        style("✓ Packed").green(),
+        bundle.items.iter().filter(|i| i.relation == "changed").count(),
+        ranges.len(),
+        bundle.items.len(),
+        bundle.items.iter().filter(|i| i.body_included).count(),
+        bundle.tokens_used,
+        bundle.token_budget
+    );
+    print_partial_body_chunk_hints(&bundle);
+    print_outside_ownership_summary(&bundle);
+    print_pack_truncation_markers(&bundle);
+
+    Ok(())
+}
+
+pub(crate) async fn run_review_pack_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::git_diff_context_scoper::collect_git_diff_changed_lines;
+    use parseltongue_core::pr_review_bundle_generator::{
+        build_review_bundle_from_storage, render_review_bundle_markdown,
+    };
+
+    let revision = matches
+        .get_one::<String>("since")
+        .or_else(|| matches.get_one::<String>("diff"))
+        .unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let repo = matches.get_one::<String>("repo").unwrap();
+    let api_depth = *matches.get_one::<usize>("api-depth").unwrap();
+
+    let ranges = collect_git_diff_changed_lines(std::path::Path::new(repo), revision)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let team = matches.get_one::<String>("team").map(String::as_str);
+    let bundle = build_review_bundle_from_storage(&storage, revision, &ranges, api_depth, team).await?;
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&bundle)?);
+    } else {
+        print!("{}", render_review_bundle_markdown(&bundle));
+    }
+    // Summary on stderr so stdout stays pipeable into a prompt
+    eprintln!(
+        "{} {} changed, {} callers, {} implementers, {} tests, {} public API, {} outside {}",
+        style("✓ Review pack").green(),
+        bundle.changed_symbols.len(),
+        bundle.callers.len(),
+        bundle.implementers.len(),
+        bundle.related_tests.len(),
+        bundle.public_api.len(),
+        bundle.outside_ownership.len(),
+        bundle.ownership_team.as_deref().unwrap_or("team ownership")
+    );
+
+    Ok(())
+}
+
+/// `api-diff`: semver impact of the exported API between two revisions
+///
+/// # 4-Word Name: run_api_diff_command
+///
+/// # Contract
+/// - Both revisions are indexed from the same worktree path into the same
+///   database, so the second ingest only re-parses changed files and
+///   entity keys of unchanged symbols match
+pub(crate) async fn run_api_diff_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::api_surface_diff_reporter::{
+        collect_exported_api_symbols, diff_api_surface_snapshots, render_api_surface_diff_markdown,
+    };
+
+    let from_revision = matches.get_one::<String>("from").unwrap();
+    let to_revision = matches.get_one::<String>("to").unwrap();
+    let cache = RevisionIndexCacheLayout::prepare_for_command(matches, "parseltongue-api-diff")?;
+
+    let mut snapshots = Vec::new();
+    for revision in [from_revision, to_revision] {
+        let storage = cache.index_revision_into_database(revision).await?;
+        let entities = storage.get_all_entities_with_metadata().await?;
+        snapshots.push(collect_exported_api_symbols(&entities, &cache.worktree));
+    }
+
+    let report = diff_api_surface_snapshots(from_revision, &snapshots[0], to_revision, &snapshots[1]);
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&report)?);
+    } else {
+        print!("{}", render_api_surface_diff_markdown(&report));
+    }
+    eprintln!(
+        "{} {}: {} removed, {} changed, {} added",
+        style("✓ API diff").green(),
+        report.semver_impact.as_str(),
+        report.removed.len(),
+        report.changed.len(),
+        report.added.len()
+    );
+    Ok(())
+}
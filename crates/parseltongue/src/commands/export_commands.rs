@@ -0,0 +1,891 @@
+//! Export CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: export_subcommand_definition_list
+//!
+//! Backs `export`, `visualize`, `explore`, `slice` and `subgraph`: whole-graph
+//! formats, bounded diagrams and standalone snapshots.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use std::path::Path;
+use anyhow::Result;
+
+use parseltongue_core::go_build_constraint_evaluator::GoBuildContextConfig;
+use parseltongue_core::rust_cfg_feature_evaluator::RustFeatureSelectionConfig;
+use parseltongue_core::serializers::CompressibleExportOutputWriter;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+use parseltongue::config::EXPORT_FORMAT_NAME_LIST;
+
+use crate::commands::ingest_commands::{IngestPathSelectionFlags, build_ingest_streamer_config};
+use crate::commands::shared_cli_arg_helpers::{
+    flag_or_config_value, load_working_directory_config, max_fanout_limit_arg, min_confidence_arg,
+    node_slice_filter_args, node_slice_filter_from_matches, open_storage_resolve_symbol, traversal_limit_args,
+    traversal_limits_from_matches,
+};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: export_subcommand_definition_list
+pub(crate) fn export_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("export")
+            .about("Export the whole dependency graph for external tools")
+            .long_about(
+                "Examples:\n  \
+                parseltongue export --db rocksdb:analysis.db --format graphml --output graph.graphml\n  \
+                parseltongue export --db rocksdb:analysis.db --format graphml > graph.graphml\n  \
+                parseltongue export --db rocksdb:analysis.db --format dot --include 'internal/**' --exclude '*_test.go' | dot -Tsvg > graph.svg\n  \
+                parseltongue export --db rocksdb:analysis.db --format scip --root . -o index.scip   # src upload / code navigation\n  \
+                parseltongue export --db rocksdb:analysis.db --format binary -o graph.ptg          # mmap with `query graph-file`\n  \
+                parseltongue export --db rocksdb:analysis.db --signatures-only --include 'internal/store/**'  # API surface\n  \
+                parseltongue export --format ndjson --stream --root ./repo | jq -c 'select(.type == \"edge\")'\n  \
+                parseltongue export --db rocksdb:analysis.db --format json -o graph.json.zst       # zstd snapshot\n  \
+                parseltongue export --db rocksdb:analysis.db --format neo4j -o neo4j-import/       # neo4j-admin CSVs\n  \
+                parseltongue export --db rocksdb:analysis.db --format parquet -o graph-parquet/    # DuckDB/Spark tables\n  \
+                parseltongue blast-radius UserStore --db snapshot:graph.json.zst                  # query a snapshot"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required_unless_present("stream"),
+            )
+            .arg(
+                Arg::new("format")
+                    .long("format")
+                    .help("Output format [default: [output] format of .parseltongue.toml, else graphml]")
+                    .value_parser(clap::builder::PossibleValuesParser::new(EXPORT_FORMAT_NAME_LIST.iter().copied()))
+                    .default_value("graphml"),
+            )
+            .arg(
+                Arg::new("root")
+                    .long("root")
+                    .help("SCIP: project root the indexed paths are relative to; --stream: directory to parse [default: current directory]"),
+            )
+            .arg(
+                Arg::new("stream")
+                    .long("stream")
+                    .help("NDJSON: parse --root and emit each file's nodes/edges as it is parsed (no database, no cross-file resolution)")
+                    .action(clap::ArgAction::SetTrue)
+                    .conflicts_with("db"),
+            )
+            .arg(
+                Arg::new("include")
+                    .long("include")
+                    .help("DOT / --signatures-only: keep only entities whose file matches this glob (repeatable)")
+                    .action(clap::ArgAction::Append),
+            )
+            .arg(
+                Arg::new("exclude")
+                    .long("exclude")
+                    .help("DOT / --signatures-only: drop entities whose file matches this glob (repeatable)")
+                    .action(clap::ArgAction::Append),
+            )
+            .arg(
+                Arg::new("signatures-only")
+                    .long("signatures-only")
+                    .help("Instead of a graph, list exported symbols as doc comment + signature, grouped by file")
+                    .action(clap::ArgAction::SetTrue)
+                    .conflicts_with("stream"),
+            )
+            .arg(
+                Arg::new("include-private")
+                    .long("include-private")
+                    .help("--signatures-only: also list non-exported symbols")
+                    .action(clap::ArgAction::SetTrue)
+                    .requires("signatures-only"),
+            )
+            .arg(
+                Arg::new("no-collapse-external")
+                    .long("no-collapse-external")
+                    .help("DOT: one node per external symbol instead of one per dependency")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("sort")
+                    .long("sort")
+                    .help("Nodes by key, edges by (from, to, type, location), so two snapshots diff line by line. --sort=false writes NDJSON edges in storage / parse order; other formats always sort")
+                    .value_parser(clap::value_parser!(bool))
+                    .num_args(0..=1)
+                    .default_missing_value("true")
+                    .default_value("true"),
+            )
+            .arg(
+                Arg::new("output")
+                    .long("output")
+                    .short('o')
+                    .help("Write to this file instead of stdout (neo4j / parquet: directory for the node and edge files)"),
+            )
+            .args(node_slice_filter_args())
+            .arg(min_confidence_arg()),
+        Command::new("visualize")
+            .about("Draw the subgraph around a symbol (callers and callees within --depth hops)")
+            .long_about(
+                "Examples:\n  \
+                parseltongue visualize --db rocksdb:analysis.db --root handleLogin --depth 2\n  \
+                parseltongue visualize --db rocksdb:analysis.db --root UserStore --edges implements,calls --format mermaid\n  \
+                parseltongue visualize --db rocksdb:analysis.db --root handleLogin --format html --output login.html"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("root")
+                    .long("root")
+                    .help("Center symbol: ISGL1 key or entity name")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Hops from the root in either direction")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("2"),
+            )
+            .arg(max_fanout_limit_arg())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("edges")
+                    .long("edges")
+                    .help("Edge types to follow: calls,uses,implements,embeds,spawns,sendsto,receivesfrom,reads,writes,tests,queriestable,serializes,generatedfrom,configuredby")
+                    .default_value("all"),
+            )
+            .arg(
+                Arg::new("max-nodes")
+                    .long("max-nodes")
+                    .help("Stop adding nodes beyond this count (keeps diagrams readable)")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("60"),
+            )
+            .arg(
+                Arg::new("format")
+                    .long("format")
+                    .help("Diagram format; html is a single-file interactive viewer (one more ring loads on click)")
+                    .value_parser(["mermaid", "dot", "html"])
+                    .default_value("mermaid"),
+            )
+            .arg(
+                Arg::new("output")
+                    .long("output")
+                    .short('o')
+                    .help("Write the diagram to this file instead of stdout"),
+            )
+            .args(node_slice_filter_args()),
+        Command::new("explore")
+            .about("Interactive terminal explorer: symbol list, callers/callees pane, context-pack yank")
+            .long_about(
+                "Keys: arrows or j/k move, Tab switches pane, c/e show callers/callees, Enter walks\n\
+                to the highlighted neighbour, Backspace walks back, / filters symbols by name,\n\
+                y yanks a context pack for the selection (clipboard via OSC 52), q quits.\n\n\
+                Examples:\n  \
+                parseltongue explore --db rocksdb:analysis.db\n  \
+                parseltongue explore --db rocksdb:analysis.db --symbol handleLogin --yank-file /tmp/ctx.txt"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("symbol")
+                    .long("symbol")
+                    .help("Start at this symbol: ISGL1 key or entity name"),
+            )
+            .arg(
+                Arg::new("budget")
+                    .long("budget")
+                    .help("Token budget of a yanked context pack")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("4000"),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .help("Maximum caller/callee hops of a yanked context pack")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("2"),
+            )
+            .arg(
+                Arg::new("yank-file")
+                    .long("yank-file")
+                    .help("Also write each yanked pack to this file (for terminals without OSC 52)"),
+            ),
+        Command::new("slice")
+            .about("Everything reachable from one entry point: the seed set of a whole-feature context pack")
+            .long_about(
+                "--from takes a label from `parseltongue analyze entrypoints` (POST /users,\n\
+                /billing.Billing/Charge, serve, @hourly), an entity key or a symbol name.\n\
+                Outgoing edges of the entry symbol and its handlers are followed (all kinds\n\
+                but Tests); --output writes the slice as a snapshot every command reads\n\
+                through --db snapshot:FILE, e.g. to pack the whole feature.\n\n\
+                Examples:\n  \
+                parseltongue slice --db rocksdb:analysis.db --from 'POST /users'\n  \
+                parseltongue slice --db rocksdb:analysis.db --from serve --depth 4 --json\n  \
+                parseltongue slice --db rocksdb:analysis.db --from 'POST /users' -o users.json"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("from")
+                    .long("from")
+                    .help("Entry point label, entity key or symbol name")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .value_name("HOPS")
+                    .help("Maximum hops from the entry point (default: unlimited)")
+                    .value_parser(clap::value_parser!(usize)),
+            )
+            .args(traversal_limit_args())
+            .arg(
+                Arg::new("output")
+                    .long("output")
+                    .short('o')
+                    .help("Also write the slice as a snapshot file (.zst compresses)"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("subgraph")
+            .about("Write the selected packages plus frontier layers as a standalone graph snapshot")
+            .long_about(
+                "Patterns are Go-style directories: ./internal/billing is that directory,\n\
+                ./internal/billing/... includes subdirectories. Frontier layers add callers,\n\
+                callees and external targets outside the selection; frontier entities carry a\n\
+                subgraph_frontier_layer metadata entry. Every command reads the result through\n\
+                --db snapshot:FILE.\n\n\
+                Examples:\n  \
+                parseltongue subgraph --db rocksdb:analysis.db --package ./internal/billing/... -o billing.json\n  \
+                parseltongue subgraph --db rocksdb:analysis.db --package ./api --package ./auth --include-externals=0 -o core.json.zst"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("package")
+                    .long("package")
+                    .help("Package directory pattern (repeatable)")
+                    .action(clap::ArgAction::Append)
+                    .required(true),
+            )
+            .arg(
+                Arg::new("include-externals")
+                    .long("include-externals")
+                    .help("Frontier layers of neighbours outside the selection")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("1"),
+            )
+            .arg(
+                Arg::new("output")
+                    .long("output")
+                    .short('o')
+                    .help("Snapshot file to write (.zst compresses)")
+                    .required(true),
+            ),
+    ]
+}
+
+pub(crate) async fn run_graph_export_command(matches: &ArgMatches) -> Result<()> {
+    if matches.get_flag("signatures-only") {
+        return run_api_surface_export(matches).await;
+    }
+    let format = flag_or_config_value(matches, "format", load_working_directory_config()?.output.format);
+    if format == "ndjson" {
+        return run_ndjson_graph_export(matches).await;
+    }
+    if matches.get_flag("stream") {
+        anyhow::bail!("--stream is only supported with --format ndjson");
+    }
+    if format == "json" {
+        return run_json_snapshot_export(matches).await;
+    }
+    if format == "neo4j" {
+        return run_neo4j_csv_export(matches).await;
+    }
+    if format == "parquet" {
+        return run_parquet_table_export(matches).await;
+    }
+    let db = matches.get_one::<String>("db").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities().await?;
+    let mut edges = storage.get_all_dependencies().await?;
+    filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+    if export_sort_flag_enabled(matches) {
+        parseltongue_core::serializers::graph_export_node_table::sort_graph_in_export_order(&mut entities, &mut edges);
+    }
+
+    let document: Vec<u8> = match format.as_str() {
+        "graphml" => parseltongue_core::serializers::render_graph_as_graphml(&entities, &edges).into_bytes(),
+        "dot" => parseltongue_core::serializers::render_graph_as_dot(&entities, &edges, &dot_config_from_matches(matches))
+            .into_bytes(),
+        "scip" => {
+            let root = match matches.get_one::<String>("root") {
+                Some(root) => std::fs::canonicalize(root)?,
+                None => std::env::current_dir()?,
+            };
+            parseltongue_core::serializers::render_graph_as_scip(&entities, &edges, &root.to_string_lossy())
+        }
+        "binary" => parseltongue_core::serializers::encode_graph_as_binary(&entities, &edges),
+        other => anyhow::bail!("Unsupported export format '{}'", other),
+    };
+
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            // `.zst` outputs are compressed on the way out
+            use std::io::Write;
+            let mut file = CompressibleExportOutputWriter::create_export_output_file(Path::new(path))?;
+            file.write_all(&document)?;
+            file.finish_export_output_file()?;
+            eprintln!(
+                "{} {} entities, {} edges → {}",
+                style("✓ Exported").green(),
+                entities.len(),
+                edges.len(),
+                style(path).yellow()
+            );
+        }
+        None => {
+            use std::io::Write;
+            std::io::stdout().write_all(&document)?;
+        }
+    }
+    Ok(())
+}
+
+/// `export --format json`: whole-graph snapshot, zstd when `-o` ends in `.zst`
+///
+/// # 4-Word Name: run_json_snapshot_export
+async fn run_json_snapshot_export(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::write_graph_as_json_snapshot;
+    use std::io::Write;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    // Extractor metadata travels with the snapshot so loading it loses nothing
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    let mut edges = storage.get_all_dependencies().await?;
+    filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+    let capabilities = storage.get_extractor_capability_manifest().await?;
+
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            let mut file = CompressibleExportOutputWriter::create_export_output_file(Path::new(path))?;
+            let (entity_count, edge_count) =
+                write_graph_as_json_snapshot(&mut file, &entities, &edges, capabilities.as_ref())?;
+            file.finish_export_output_file()?;
+            eprintln!(
+                "{} {} entities, {} edges → {}",
+                style("✓ Exported").green(),
+                entity_count,
+                edge_count,
+                style(path).yellow()
+            );
+        }
+        None => {
+            let mut out = std::io::BufWriter::new(std::io::stdout().lock());
+            write_graph_as_json_snapshot(&mut out, &entities, &edges, capabilities.as_ref())?;
+            out.flush()?;
+        }
+    }
+    Ok(())
+}
+
+/// `export --format neo4j -o DIR`: nodes.csv + relationships.csv for neo4j-admin
+///
+/// # 4-Word Name: run_neo4j_csv_export
+async fn run_neo4j_csv_export(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::neo4j_csv::{NEO4J_NODES_FILE_NAME, NEO4J_RELATIONSHIPS_FILE_NAME};
+
+    let Some(directory) = matches.get_one::<String>("output") else {
+        anyhow::bail!("--format neo4j writes {} and {}; pass the target directory with -o", NEO4J_NODES_FILE_NAME, NEO4J_RELATIONSHIPS_FILE_NAME);
+    };
+    let db = matches.get_one::<String>("db").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities().await?;
+    let mut edges = storage.get_all_dependencies().await?;
+    filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+    let files = parseltongue_core::serializers::render_graph_as_neo4j_csv(&entities, &edges);
+
+    let directory = Path::new(directory);
+    std::fs::create_dir_all(directory)?;
+    let nodes_path = directory.join(NEO4J_NODES_FILE_NAME);
+    let relationships_path = directory.join(NEO4J_RELATIONSHIPS_FILE_NAME);
+    std::fs::write(&nodes_path, &files.nodes_csv)?;
+    std::fs::write(&relationships_path, &files.relationships_csv)?;
+    eprintln!(
+        "{} {} nodes, {} relationships → {}",
+        style("✓ Exported").green(),
+        files.node_count,
+        files.relationship_count,
+        style(directory.display()).yellow()
+    );
+    eprintln!(
+        "  neo4j-admin database import full --nodes={} --relationships={} neo4j",
+        nodes_path.display(),
+        relationships_path.display()
+    );
+    Ok(())
+}
+
+/// `export --format parquet -o DIR`: nodes.parquet + edges.parquet for analytics engines
+///
+/// # 4-Word Name: run_parquet_table_export
+async fn run_parquet_table_export(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::parquet::{PARQUET_EDGES_FILE_NAME, PARQUET_NODES_FILE_NAME};
+
+    let Some(directory) = matches.get_one::<String>("output") else {
+        anyhow::bail!("--format parquet writes {} and {}; pass the target directory with -o", PARQUET_NODES_FILE_NAME, PARQUET_EDGES_FILE_NAME);
+    };
+    let db = matches.get_one::<String>("db").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    let mut edges = storage.get_all_dependencies().await?;
+    filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+    let files = parseltongue_core::serializers::render_graph_as_parquet_tables(&entities, &edges);
+
+    let directory = Path::new(directory);
+    std::fs::create_dir_all(directory)?;
+    let nodes_path = directory.join(PARQUET_NODES_FILE_NAME);
+    let edges_path = directory.join(PARQUET_EDGES_FILE_NAME);
+    std::fs::write(&nodes_path, &files.nodes_parquet)?;
+    std::fs::write(&edges_path, &files.edges_parquet)?;
+    eprintln!(
+        "{} {} nodes, {} edges → {}",
+        style("✓ Exported").green(),
+        files.node_count,
+        files.edge_count,
+        style(directory.display()).yellow()
+    );
+    Ok(())
+}
+
+/// `export --sort` (default true)
+fn export_sort_flag_enabled(matches: &ArgMatches) -> bool {
+    matches.get_one::<bool>("sort").copied().unwrap_or(true)
+}
+
+/// `export --signatures-only`: doc comment + signature of each exported symbol
+///
+/// # 4-Word Name: run_api_surface_export
+async fn run_api_surface_export(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::{render_api_surface_signatures, ApiSurfaceConfig};
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    // Signatures live in extractor metadata
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    let filter = node_slice_filter_from_matches(matches)?;
+    entities.retain(|entity| filter.matches_graph_node_entry(&entity.isgl1_key, Some(entity)));
+    let dot_config = dot_config_from_matches(matches);
+    let config = ApiSurfaceConfig {
+        include_globs: dot_config.include_globs,
+        exclude_globs: dot_config.exclude_globs,
+        include_private: matches.get_flag("include-private"),
+    };
+    let surface = render_api_surface_signatures(&entities, &config);
+
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            std::fs::write(path, &surface)?;
+            eprintln!("{} API surface → {}", style("✓ Exported").green(), style(path).yellow());
+        }
+        None => print!("{}", surface),
+    }
+    Ok(())
+}
+
+/// NDJSON export, either from the database or streamed straight from the parser
+///
+/// # 4-Word Name: run_ndjson_graph_export
+async fn run_ndjson_graph_export(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::graph_export_node_table::{
+        collect_graph_export_nodes, compare_edges_in_export_order,
+    };
+    use parseltongue_core::serializers::ndjson::{write_edges_as_ndjson, write_node_rows_as_ndjson};
+    use std::io::Write;
+
+    let sort = export_sort_flag_enabled(matches);
+    let filter = node_slice_filter_from_matches(matches)?;
+
+    // A `.zst` file needs `finish`, so the file writer is kept unboxed
+    let mut file_out = match matches.get_one::<String>("output") {
+        Some(path) => Some(CompressibleExportOutputWriter::create_export_output_file(Path::new(path))?),
+        None => None,
+    };
+    let mut stdout_out = std::io::BufWriter::new(std::io::stdout().lock());
+    let mut out: &mut dyn Write = match file_out.as_mut() {
+        Some(file) => file,
+        None => &mut stdout_out,
+    };
+
+    let (nodes, edges) = if matches.get_flag("stream") {
+        let root = matches.get_one::<String>("root").map(String::as_str).unwrap_or(".");
+        let path_selection = IngestPathSelectionFlags::for_ingest_directory(root)?;
+        let config = build_ingest_streamer_config(
+            root,
+            "mem",
+            GoBuildContextConfig::default(),
+            RustFeatureSelectionConfig::default(),
+            false,
+            0,
+            false,
+            IngestProgressOutputMode::Off,
+            &path_selection,
+        );
+        let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(config).await?;
+        let (mut nodes, mut edges) = (0, 0);
+        // Files arrive in path order; --sort orders the edges within each file
+        streamer.for_each_parsed_file_batch(|entities, dependencies| {
+            // Endpoints in other files are judged from their keys
+            let (mut entities, mut dependencies) = (entities.to_vec(), dependencies.to_vec());
+            filter.retain_matching_graph_slice(&mut entities, &mut dependencies, &[]);
+            nodes += write_node_rows_as_ndjson(&mut out, &collect_graph_export_nodes(&entities, &[]))?;
+            let mut file_edges: Vec<_> = dependencies.iter().collect();
+            if sort {
+                file_edges.sort_by(|a, b| compare_edges_in_export_order(a, b));
+            }
+            edges += write_edges_as_ndjson(&mut out, file_edges)?;
+            // Per-file flush: downstream sees each file as soon as it is parsed
+            out.flush()
+        })?;
+        (nodes, edges)
+    } else {
+        let db = matches.get_one::<String>("db").unwrap();
+        let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+        let mut entities = storage.get_all_entities().await?;
+        let mut dependencies = storage.get_all_dependencies().await?;
+        filter.retain_matching_graph_slice(&mut entities, &mut dependencies, &[]);
+        if sort {
+            parseltongue_core::serializers::write_graph_as_ndjson(&mut out, &entities, &dependencies)?
+        } else {
+            let nodes = write_node_rows_as_ndjson(&mut out, &collect_graph_export_nodes(&entities, &dependencies))?;
+            (nodes, write_edges_as_ndjson(&mut out, &dependencies)?)
+        }
+    };
+    out.flush()?;
+    if let Some(file) = file_out {
+        file.finish_export_output_file()?;
+    }
+
+    if let Some(path) = matches.get_one::<String>("output") {
+        eprintln!("{} {} nodes, {} edges → {}", style("✓ Exported").green(), nodes, edges, style(path).yellow());
+    }
+    Ok(())
+}
+
+pub(crate) async fn run_subgraph_visualize_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::filtered_graph_traversal_queries::{
+        collect_bounded_neighborhood_edges, parse_edge_type_filter_list,
+    };
+    use parseltongue_core::serializers::{
+        render_graph_as_html_viewer, render_graph_as_mermaid, HtmlViewerConfig, MermaidConfig,
+    };
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let root = matches.get_one::<String>("root").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+    let max_nodes = *matches.get_one::<usize>("max-nodes").unwrap();
+    let edge_types = parse_edge_type_filter_list(matches.get_one::<String>("edges").unwrap())?;
+
+    let format = matches.get_one::<String>("format").map(String::as_str).unwrap_or("mermaid");
+
+    let filter = node_slice_filter_from_matches(matches)?;
+
+    let (storage, root_key) = open_storage_resolve_symbol(db, root).await?;
+    let mut all_edges = storage.get_all_dependencies().await?;
+    if !filter.is_unrestricted() {
+        // The walk stays inside the slice; the root is always drawn
+        let mut all_entities = storage.get_all_entities().await?;
+        filter.retain_matching_graph_slice(&mut all_entities, &mut all_edges, &[root_key.as_str()]);
+    }
+    // html embeds one ring beyond --depth, hidden until a node is expanded
+    let walk_depth = if format == "html" { depth + 1 } else { depth };
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+    let all_edges = cap_edge_list_fanout(&all_edges, TraversalFanoutDirectionKind::Undirected, &mut truncation);
+    let edges = collect_bounded_neighborhood_edges(&all_edges, &root_key, walk_depth, max_nodes, &edge_types);
+    // Unbounded walks measure what --max-nodes and --depth left out
+    let node_count = |edges: &[parseltongue_core::entities::DependencyEdge]| -> usize {
+        edges
+            .iter()
+            .flat_map(|e| [e.from_key.as_str(), e.to_key.as_str()])
+            .collect::<std::collections::HashSet<_>>()
+            .len()
+    };
+    let unbounded = node_count(&collect_bounded_neighborhood_edges(&all_edges, &root_key, walk_depth, usize::MAX, &edge_types));
+    truncation.record_nodes_omitted_count(unbounded.saturating_sub(node_count(&edges)));
+    let deeper = node_count(&collect_bounded_neighborhood_edges(&all_edges, &root_key, walk_depth + 1, usize::MAX, &edge_types));
+    truncation.record_beyond_depth_count(deeper.saturating_sub(unbounded));
+
+    // Entity rows give names and kinds; keys without one render as external
+    let mut node_keys: Vec<&str> = edges
+        .iter()
+        .flat_map(|e| [e.from_key.as_str(), e.to_key.as_str()])
+        .chain(std::iter::once(root_key.as_str()))
+        .collect();
+    node_keys.sort_unstable();
+    node_keys.dedup();
+    let mut entities = Vec::new();
+    for key in node_keys {
+        if let Ok(entity) = storage.get_entity(key).await {
+            entities.push(entity);
+        }
+    }
+
+    let rendered = match format {
+        "dot" => {
+            let config = parseltongue_core::serializers::DotConfig::default();
+            parseltongue_core::serializers::render_graph_as_dot(&entities, &edges, &config)
+        }
+        "html" => {
+            let config = HtmlViewerConfig {
+                title: format!("{} (parseltongue)", root),
+                root_key: Some(root_key.clone()),
+                initial_depth: depth,
+            };
+            render_graph_as_html_viewer(&entities, &edges, &config)
+        }
+        _ => {
+            let config = MermaidConfig { root_key: Some(root_key.clone()), ..Default::default() };
+            render_graph_as_mermaid(&entities, &edges, &config)
+        }
+    };
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            std::fs::write(path, rendered)?;
+            eprintln!("{} {}", style("✓ Wrote").green(), style(path).yellow());
+        }
+        None => print!("{}", rendered),
+    }
+    // stderr: the diagram on stdout stays parseable
+    for line in truncation.render_truncation_marker_lines() {
+        eprintln!("{}", style(line).dim());
+    }
+    Ok(())
+}
+
+/// Open the terminal explorer on an in-memory copy of the graph (v1.7.3)
+///
+/// # 4-Word Name: run_graph_explorer_command
+pub(crate) async fn run_graph_explorer_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue::explore::{run_graph_explorer_terminal, ExplorerYankPackSettings};
+    use parseltongue::graph::InMemoryCodeGraphSnapshot;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let (storage, start_key) = match matches.get_one::<String>("symbol") {
+        Some(symbol) => {
+            let (storage, key) = open_storage_resolve_symbol(db, symbol).await?;
+            (storage, Some(key))
+        }
+        None => (parseltongue_core::storage::CozoDbStorage::new(db).await?, None),
+    };
+    let graph = InMemoryCodeGraphSnapshot::load_from_storage(&storage).await?;
+    let yank = ExplorerYankPackSettings {
+        token_budget: *matches.get_one::<usize>("budget").unwrap(),
+        max_depth: *matches.get_one::<usize>("depth").unwrap(),
+        include_tests: false,
+    };
+    let yank_file = matches.get_one::<String>("yank-file").map(Path::new);
+    run_graph_explorer_terminal(&graph, start_key.as_deref(), &yank, yank_file)
+}
+
+/// Write `--package` selections plus frontier layers as a JSON snapshot (v1.7.3)
+///
+/// # 4-Word Name: run_package_subgraph_command
+pub(crate) async fn run_package_subgraph_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::package_boundary_subgraph_extractor::{
+        extract_package_boundary_subgraph, PackageSelectionPatternSpec,
+    };
+    use parseltongue_core::serializers::write_graph_as_json_snapshot;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let output = matches.get_one::<String>("output").unwrap();
+    let frontier_layers = *matches.get_one::<usize>("include-externals").unwrap();
+    let patterns = matches
+        .get_many::<String>("package")
+        .unwrap()
+        .map(|pattern| PackageSelectionPatternSpec::parse_package_selection_pattern(pattern))
+        .collect::<parseltongue_core::error::Result<Vec<_>>>()?;
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let capabilities = storage.get_extractor_capability_manifest().await?;
+    let subgraph = extract_package_boundary_subgraph(&entities, &edges, &patterns, frontier_layers);
+    if subgraph.selected_entity_count == 0 {
+        eprintln!("{} no entity lives under the given --package patterns", style("⚠").yellow());
+    }
+
+    let mut file = CompressibleExportOutputWriter::create_export_output_file(Path::new(output))?;
+    let (entity_count, edge_count) =
+        write_graph_as_json_snapshot(&mut file, &subgraph.entities, &subgraph.edges, capabilities.as_ref())?;
+    file.finish_export_output_file()?;
+    eprintln!(
+        "{} {} entities ({} selected, {} frontier nodes), {} edges → {}",
+        style("✓ Exported").green(),
+        entity_count,
+        subgraph.selected_entity_count,
+        subgraph.frontier_node_count,
+        edge_count,
+        style(output).yellow()
+    );
+    println!("  Use it with: --db snapshot:{}", output);
+    Ok(())
+}
+
+/// Reachable subgraph of one entry point (v1.7.3)
+///
+/// # 4-Word Name: run_entry_point_slice_command
+///
+/// `--from` is matched against entry point labels and keys first, then
+/// resolved as a symbol.
+pub(crate) async fn run_entry_point_slice_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::entry_point_reachable_slicer::{
+        extract_reachable_entry_slice, find_feature_entry_points, match_entry_point_reference,
+    };
+    use parseltongue_core::serializers::write_graph_as_json_snapshot;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let from = matches.get_one::<String>("from").unwrap();
+    let max_depth = matches.get_one::<usize>("depth").copied();
+    let limits = traversal_limits_from_matches(matches);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Outgoing, &mut truncation);
+    let capabilities = storage.get_extractor_capability_manifest().await?;
+    let entry_points = find_feature_entry_points(&entities, &edges);
+    let matched = match_entry_point_reference(&entry_points, from);
+    let seeds = match matched.as_slice() {
+        [entry] => entry.seed_keys(),
+        [] => {
+            drop(storage);
+            let (_, key) = open_storage_resolve_symbol(db, from).await?;
+            vec![key]
+        }
+        several => {
+            eprintln!("'{}' names {} entry points; pass one entity key:", from, several.len());
+            for entry in several.iter().take(20) {
+                eprintln!("  {:<9} {}  {}", entry.kind.as_str(), entry.entry_key, entry.location.as_deref().unwrap_or("-"));
+            }
+            anyhow::bail!("Ambiguous entry point '{}'", from)
+        }
+    };
+
+    let mut slice = extract_reachable_entry_slice(&entities, &edges, &seeds, max_depth);
+    if let Some(depth) = max_depth {
+        let beyond = extract_reachable_entry_slice(&entities, &edges, &seeds, Some(depth + 1));
+        truncation.record_beyond_depth_count(beyond.members.len() - slice.members.len());
+    }
+    if let Some(limit) = truncation.limits.max_nodes {
+        truncation.record_nodes_omitted_count(slice.retain_nearest_slice_members(limit));
+    }
+    if let Some(output) = matches.get_one::<String>("output") {
+        let mut file = CompressibleExportOutputWriter::create_export_output_file(Path::new(output))?;
+        let (entity_count, edge_count) =
+            write_graph_as_json_snapshot(&mut file, &slice.entities, &slice.edges, capabilities.as_ref())?;
+        file.finish_export_output_file()?;
+        eprintln!(
+            "{} {} entities, {} edges → {}",
+            style("✓ Exported slice").green(),
+            entity_count,
+            edge_count,
+            style(output).yellow()
+        );
+        eprintln!("  Use it with: --db snapshot:{}", output);
+    }
+
+    let by_key: std::collections::HashMap<&str, &parseltongue_core::entities::CodeEntity> =
+        slice.entities.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    if matches.get_flag("json") {
+        let members: Vec<serde_json::Value> = slice
+            .members
+            .iter()
+            .map(|member| {
+                let entity = by_key.get(member.key.as_str());
+                serde_json::json!({
+                    "key": member.key,
+                    "depth": member.depth,
+                    "name": entity.map(|e| e.interface_signature.name.clone()),
+                    "file": entity.map(|e| e.interface_signature.file_path.to_string_lossy().to_string()),
+                    "external": entity.is_none(),
+                })
+            })
+            .collect();
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "from": from,
+            "seeds": seeds,
+            "members": members,
+            "edges": slice.edges,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    let internal = slice.members.iter().filter(|m| by_key.contains_key(m.key.as_str())).count();
+    println!(
+        "Slice from {}: {} symbols ({} external), {} edges",
+        style(from).bold(),
+        internal,
+        slice.members.len() - internal,
+        slice.edges.len()
+    );
+    for member in slice.members.iter().filter(|m| by_key.contains_key(m.key.as_str())) {
+        let signature = &by_key[member.key.as_str()].interface_signature;
+        println!(
+            "  {:>2}  {}  {}",
+            member.depth,
+            signature.name,
+            style(format!("{}:{}", signature.file_path.display(), signature.line_range.start)).dim()
+        );
+    }
+    for line in truncation.render_truncation_marker_lines() {
+        println!("{}", style(line).dim());
+    }
+    Ok(())
+}
+
+/// DOT options from `export` flags
+///
+/// # 4-Word Name: dot_config_from_matches
+fn dot_config_from_matches(matches: &ArgMatches) -> parseltongue_core::serializers::DotConfig {
+    let globs = |name: &str| -> Vec<String> {
+        matches.get_many::<String>(name).map(|v| v.cloned().collect()).unwrap_or_default()
+    };
+    parseltongue_core::serializers::DotConfig {
+        include_globs: globs("include"),
+        exclude_globs: globs("exclude"),
+        collapse_external: !matches.get_flag("no-collapse-external"),
+        ..Default::default()
+    }
+}
@@ -0,0 +1,472 @@
+//! History CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: history_subcommand_definition_list
+//!
+//! Backs `graph-diff`, `report delta` and `history`, plus the per-revision
+//! index cache and history database locations `context` and `query` reuse.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use std::path::Path;
+use anyhow::Result;
+
+use pt01_folder_to_cozodb_streamer::streamer::FileStreamer;
+
+use parseltongue_core::go_build_constraint_evaluator::GoBuildContextConfig;
+use parseltongue_core::rust_cfg_feature_evaluator::RustFeatureSelectionConfig;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+
+use crate::commands::ingest_commands::{IngestPathSelectionFlags, build_ingest_streamer_config};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: history_subcommand_definition_list
+pub(crate) fn history_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("graph-diff")
+            .about("Compare two graph snapshots: nodes and edges added, removed or changed, as JSON")
+            .long_about(
+                "Nodes are matched by entity key and reported changed when body, signature or\n\
+                visibility differ; edges are matched by (from, to, type). The summary counts\n\
+                public symbols and groups edge changes by the target's package, e.g. for a CI\n\
+                comment like \"adds 3 public symbols, removes 2 call edges into payments\".\n\
+                Either side may be an `export --format json` snapshot file or a --db spec.\n\n\
+                Examples:\n  \
+                parseltongue graph-diff base.json.zst head.json.zst\n  \
+                parseltongue graph-diff base.json.zst rocksdb:parseltongueXXX/analysis.db --summary"
+            )
+            .arg(
+                Arg::new("old")
+                    .help("Old snapshot file (.json / .json.zst) or database spec")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("new")
+                    .help("New snapshot file or database spec")
+                    .required(true)
+                    .index(2),
+            )
+            .arg(
+                Arg::new("summary")
+                    .long("summary")
+                    .help("Print the one-line summaries instead of the JSON report")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("output")
+                    .long("output")
+                    .short('o')
+                    .help("Write the JSON report to this file instead of stdout"),
+            ),
+        Command::new("report")
+            .about("Human-readable reports built from the graph")
+            .subcommand_required(true)
+            .subcommand(
+                Command::new("delta")
+                    .about("Changelog-style bullets of what changed in the graph since a revision")
+                    .long_about(
+                        "Indexes both revisions in a cached git worktree, diffs the graphs and phrases\n\
+                        the result as short bullets (\"CreateUser now calls AuditLogger.Record\",\n\
+                        \"User gained field Email\") for commit messages and standups. The text is\n\
+                        generated from node and edge diffs, without an LLM, so it is deterministic.\n\n\
+                        Examples:\n  \
+                        parseltongue report delta --since HEAD~1\n  \
+                        parseltongue report delta --since v1.4.0 --to main --repo ./service --json"
+                    )
+                    .arg(
+                        Arg::new("since")
+                            .long("since")
+                            .help("Old revision (tag, branch, commit)")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("to")
+                            .long("to")
+                            .help("New revision")
+                            .default_value("HEAD"),
+                    )
+                    .arg(
+                        Arg::new("repo")
+                            .long("repo")
+                            .help("Git repository to compare")
+                            .default_value("."),
+                    )
+                    .arg(
+                        Arg::new("cache-dir")
+                            .long("cache-dir")
+                            .help("Worktree and database cache [default: <git dir>/parseltongue-report-delta]"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit the bullets as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            ),
+        Command::new("history")
+            .about("Record per-commit graph snapshots; show when a symbol appeared, changed or gained callers")
+            .long_about(
+                "`history record` indexes revisions in a cached git worktree (incremental after the\n\
+                first) and stores a compressed snapshot of each in one SQLite file; commits already\n\
+                recorded are skipped. `history <symbol>` compares the recorded revisions oldest\n\
+                first and lists when the symbol (a name or an ISGL1 key) appeared or was removed,\n\
+                changed signature or body, and gained or lost callers. Without a symbol, lists the\n\
+                recorded revisions. `query ... --at <rev>` runs a query on a recorded snapshot.\n\n\
+                Examples:\n  \
+                parseltongue history record --tags\n  \
+                parseltongue history record HEAD~2 HEAD~1 HEAD\n  \
+                parseltongue history Save\n  \
+                parseltongue history 'go:method:Save:__internal_store:T1712' --json"
+            )
+            .args_conflicts_with_subcommands(true)
+            .arg(
+                Arg::new("symbol")
+                    .help("Symbol name or ISGL1 key")
+                    .index(1),
+            )
+            .args(revision_history_location_args())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the timeline as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .subcommand(
+                Command::new("record")
+                    .about("Index revisions and store their graph snapshots in the history file")
+                    .arg(
+                        Arg::new("revisions")
+                            .help("Revisions to record (tags, branches, commits) [default: HEAD]")
+                            .num_args(0..)
+                            .index(1),
+                    )
+                    .arg(
+                        Arg::new("tags")
+                            .long("tags")
+                            .help("Also record every tag, oldest first")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .arg(
+                        Arg::new("force")
+                            .long("force")
+                            .help("Re-record revisions that are already recorded")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .args(revision_history_location_args())
+                    .arg(
+                        Arg::new("cache-dir")
+                            .long("cache-dir")
+                            .help("Worktree and database cache [default: <git dir>/parseltongue-history]"),
+                    ),
+            ),
+    ]
+}
+
+/// Cached worktree and database that revisions are indexed into
+///
+/// # 4-Word Name: RevisionIndexCacheLayout
+///
+/// Every revision is checked out at the same worktree path and indexed into
+/// the same database, so later ingests only re-parse changed files and
+/// entity keys of unchanged symbols match across revisions.
+pub(crate) struct RevisionIndexCacheLayout {
+    repo: std::path::PathBuf,
+    pub(crate) worktree: std::path::PathBuf,
+    database_dir: std::path::PathBuf,
+    db: String,
+}
+
+impl RevisionIndexCacheLayout {
+    /// Layout under `--cache-dir`, else `<git dir>/<default_name>`, for `--repo`
+    pub(crate) fn prepare_for_command(matches: &ArgMatches, default_name: &str) -> Result<Self> {
+        use parseltongue_core::api_surface_diff_reporter::resolve_git_common_directory;
+
+        let repo = std::fs::canonicalize(matches.get_one::<String>("repo").unwrap())?;
+        let cache_dir = match matches.get_one::<String>("cache-dir") {
+            Some(dir) => std::path::PathBuf::from(dir),
+            None => resolve_git_common_directory(&repo)?.join(default_name),
+        };
+        std::fs::create_dir_all(&cache_dir)?;
+        let cache_dir = std::fs::canonicalize(cache_dir)?;
+        let database_dir = cache_dir.join("analysis.db");
+        Ok(Self {
+            repo,
+            worktree: cache_dir.join("worktree"),
+            db: format!("rocksdb:{}", database_dir.display()),
+            database_dir,
+        })
+    }
+
+    /// Check `revision` out into the worktree, index it, and open the database
+    pub(crate) async fn index_revision_into_database(
+        &self,
+        revision: &str,
+    ) -> Result<parseltongue_core::storage::CozoDbStorage> {
+        use parseltongue_core::api_surface_diff_reporter::checkout_revision_into_worktree;
+
+        checkout_revision_into_worktree(&self.repo, revision, &self.worktree)?;
+        let directory = self.worktree.to_string_lossy().to_string();
+        let incremental = self.database_dir.exists();
+        {
+            let config = build_ingest_streamer_config(
+                &directory,
+                &self.db,
+                GoBuildContextConfig::default(),
+                RustFeatureSelectionConfig::default(),
+                false,
+                0,
+                false,
+                IngestProgressOutputMode::Bar,
+                &IngestPathSelectionFlags::for_ingest_directory(&directory)?,
+            );
+            let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(config).await?;
+            let result = if incremental {
+                streamer.stream_directory_incremental_by_hash().await?
+            } else {
+                streamer.stream_directory_with_parallel_rayon().await?
+            };
+            eprintln!(
+                "{} {} ({} files re-parsed)",
+                style("✓ Indexed").green(),
+                style(revision).yellow(),
+                result.processed_files
+            );
+        }
+        // Streamer dropped: the database is free to reopen
+        Ok(parseltongue_core::storage::CozoDbStorage::new(&self.db).await?)
+    }
+}
+
+/// `report delta`: natural-language graph changes between two revisions
+///
+/// # 4-Word Name: run_graph_delta_report_command
+pub(crate) async fn run_graph_delta_report_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::graph_delta_narrative_reporter::{narrate_graph_snapshot_delta, render_graph_delta_bullets};
+
+    let since = matches.get_one::<String>("since").unwrap();
+    let to = matches.get_one::<String>("to").unwrap();
+    let cache = RevisionIndexCacheLayout::prepare_for_command(matches, "parseltongue-report-delta")?;
+
+    let mut snapshots = Vec::new();
+    for revision in [since, to] {
+        let storage = cache.index_revision_into_database(revision).await?;
+        let mut entities = storage.get_all_entities_with_metadata().await?;
+        // Paths relative to the repository, not the cached worktree
+        for entity in &mut entities {
+            let path = &mut entity.interface_signature.file_path;
+            if let Ok(relative) = path.strip_prefix(&cache.worktree) {
+                *path = relative.to_path_buf();
+            }
+        }
+        snapshots.push((entities, storage.get_all_dependencies().await?));
+    }
+
+    let (old, new) = (&snapshots[0], &snapshots[1]);
+    let bullets = narrate_graph_snapshot_delta(&old.0, &old.1, &new.0, &new.1);
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "since": since,
+            "to": to,
+            "bullets": bullets,
+        }))?);
+    } else {
+        print!("{}", render_graph_delta_bullets(&bullets));
+    }
+    eprintln!("{} {} bullets for {}..{}", style("✓ Delta").green(), bullets.len(), since, to);
+    Ok(())
+}
+
+/// `history record`: index revisions and store their graph snapshots
+///
+/// # 4-Word Name: run_history_record_command
+///
+/// # Contract
+/// - Tags (with --tags) are recorded first, oldest first, then the given
+///   revisions; without either, HEAD
+/// - Commits already in the history file are skipped unless --force, so
+///   re-running `history record --tags` only indexes new tags
+pub(crate) async fn run_history_record_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::api_surface_diff_reporter::{
+        list_repository_tags_chronologically, resolve_revision_commit_details,
+    };
+    use parseltongue_core::storage::revision_history_sqlite_store::RevisionHistorySqliteStore;
+
+    let cache = RevisionIndexCacheLayout::prepare_for_command(matches, "parseltongue-history")?;
+    let history_path = history_database_path_from_matches(matches)?;
+    let store = RevisionHistorySqliteStore::open_with_history_schema(&history_path)?;
+
+    let mut revisions = Vec::new();
+    if matches.get_flag("tags") {
+        revisions.extend(list_repository_tags_chronologically(&cache.repo)?);
+    }
+    revisions.extend(matches.get_many::<String>("revisions").into_iter().flatten().cloned());
+    if revisions.is_empty() && !matches.get_flag("tags") {
+        revisions.push("HEAD".to_string());
+    }
+
+    let (mut recorded, mut skipped) = (0, 0);
+    for revision in &revisions {
+        let commit = resolve_revision_commit_details(&cache.repo, revision)?;
+        if !matches.get_flag("force") && store.is_revision_already_recorded(&commit.commit_sha)? {
+            skipped += 1;
+            continue;
+        }
+        let storage = cache.index_revision_into_database(revision).await?;
+        let mut entities = storage.get_all_entities_with_metadata().await?;
+        // Paths relative to the repository, not the cached worktree
+        for entity in &mut entities {
+            let path = &mut entity.interface_signature.file_path;
+            if let Ok(relative) = path.strip_prefix(&cache.worktree) {
+                *path = relative.to_path_buf();
+            }
+        }
+        let edges = storage.get_all_dependencies().await?;
+        let capabilities = storage.get_extractor_capability_manifest().await?;
+        let counts = store.record_revision_snapshot_atomically(&commit, &entities, &edges, capabilities.as_ref())?;
+        recorded += 1;
+        eprintln!(
+            "{} {} {}: {} symbols, {} calls, {} KiB",
+            style("✓ Recorded").green(),
+            style(&commit.commit_sha[..8]).yellow(),
+            commit.subject,
+            counts.symbols,
+            counts.calls,
+            counts.snapshot_bytes / 1024
+        );
+    }
+    eprintln!(
+        "{} {} recorded, {} already recorded → {}",
+        style("✓ History").green(),
+        recorded,
+        skipped,
+        history_path.display()
+    );
+    Ok(())
+}
+
+/// `history <symbol>`: timeline of a symbol, or the recorded revisions
+///
+/// # 4-Word Name: run_symbol_history_command
+pub(crate) fn run_symbol_history_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::storage::revision_history_sqlite_store::RevisionHistorySqliteStore;
+    use parseltongue_core::symbol_history_timeline_reporter::{
+        build_symbol_history_timeline, render_symbol_history_markdown,
+    };
+
+    let store = RevisionHistorySqliteStore::open_with_history_schema(history_database_path_from_matches(matches)?)?;
+    let Some(symbol) = matches.get_one::<String>("symbol") else {
+        let revisions = store.list_recorded_revisions_chronologically()?;
+        if matches.get_flag("json") {
+            println!("{}", serde_json::to_string_pretty(&revisions)?);
+        } else if revisions.is_empty() {
+            println!("No recorded revisions; run `parseltongue history record`");
+        } else {
+            for revision in &revisions {
+                let commit = &revision.commit;
+                let tags = if commit.tags.is_empty() {
+                    String::new()
+                } else {
+                    format!("({}) ", commit.tags.join(", "))
+                };
+                println!(
+                    "{}  {}  {:>7} symbols  {}{}",
+                    &commit.commit_sha[..commit.commit_sha.len().min(8)],
+                    &commit.committed_at[..commit.committed_at.len().min(10)],
+                    revision.symbol_count,
+                    tags,
+                    commit.subject
+                );
+            }
+        }
+        return Ok(());
+    };
+
+    let report = build_symbol_history_timeline(symbol, &store.load_symbol_revision_states(symbol)?);
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&report)?);
+    } else {
+        print!("{}", render_symbol_history_markdown(&report));
+    }
+    eprintln!(
+        "{} {} events across {} recorded revisions",
+        style("✓ History").green(),
+        report.events.len(),
+        report.revisions_recorded
+    );
+    Ok(())
+}
+
+/// `--repo` / `--history` of the history commands and of `query --at` (v1.7.3)
+///
+/// # 4-Word Name: revision_history_location_args
+pub(crate) fn revision_history_location_args() -> [Arg; 2] {
+    [
+        Arg::new("repo")
+            .long("repo")
+            .help("Git repository the revisions belong to")
+            .default_value("."),
+        Arg::new("history")
+            .long("history")
+            .value_name("FILE")
+            .help("History file [default: <git dir>/parseltongue-history/history.sqlite]"),
+    ]
+}
+
+/// History file from `--history`, else under the git directory of `--repo`
+///
+/// # 4-Word Name: history_database_path_from_matches
+pub(crate) fn history_database_path_from_matches(matches: &ArgMatches) -> Result<std::path::PathBuf> {
+    use parseltongue_core::api_surface_diff_reporter::resolve_git_common_directory;
+
+    if let Some(path) = matches.get_one::<String>("history") {
+        return Ok(std::path::PathBuf::from(path));
+    }
+    let repo = std::fs::canonicalize(matches.get_one::<String>("repo").unwrap())?;
+    let directory = resolve_git_common_directory(&repo)?.join("parseltongue-history");
+    std::fs::create_dir_all(&directory)?;
+    Ok(directory.join("history.sqlite"))
+}
+
+/// `graph-diff`: structural changes between two graph snapshots
+///
+/// # 4-Word Name: run_graph_diff_command
+pub(crate) async fn run_graph_diff_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::graph_snapshot_diff_reporter::{diff_graph_snapshot_pair, render_graph_diff_headlines};
+
+    let (old_entities, old_edges) = load_graph_diff_side(matches.get_one::<String>("old").unwrap()).await?;
+    let (new_entities, new_edges) = load_graph_diff_side(matches.get_one::<String>("new").unwrap()).await?;
+    let report = diff_graph_snapshot_pair(&old_entities, &old_edges, &new_entities, &new_edges);
+
+    if matches.get_flag("summary") {
+        for line in render_graph_diff_headlines(&report.summary) {
+            println!("{}", line);
+        }
+        return Ok(());
+    }
+    let json = serde_json::to_string_pretty(&report)?;
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            std::fs::write(path, format!("{}\n", json))?;
+            eprintln!("{} Graph diff written to {}", style("✓").green(), path);
+        }
+        None => println!("{}", json),
+    }
+    Ok(())
+}
+
+/// Entities and edges of a snapshot file, or of a database spec
+async fn load_graph_diff_side(
+    source: &str,
+) -> Result<(Vec<parseltongue_core::entities::CodeEntity>, Vec<parseltongue_core::entities::DependencyEdge>)> {
+    use parseltongue_core::serializers::json_snapshot::{open_decompressed_snapshot_reader, read_graph_json_snapshot};
+
+    if Path::new(source).is_file() {
+        let reader = open_decompressed_snapshot_reader(Path::new(source))?;
+        return Ok(read_graph_json_snapshot(reader)?);
+    }
+    let storage = parseltongue_core::storage::CozoDbStorage::new(source).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    Ok((entities, edges))
+}
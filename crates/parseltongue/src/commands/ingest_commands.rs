@@ -0,0 +1,685 @@
+//! Ingest CLI command (v1.7.3)
+//!
+//! # 4-Word Naming: ingest_subcommand_definition_list
+//!
+//! Backs `pt01-folder-to-cozodb-streamer`: flag and project-config merging into a
+//! `StreamerConfig`, full and `--incremental` ingests, `--import` of SCIP/LSIF
+//! indexes and the `--sqlite-mirror` relational copy.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use pt01_folder_to_cozodb_streamer::streamer::FileStreamer;
+
+use parseltongue_core::go_build_constraint_evaluator::GoBuildContextConfig;
+use parseltongue_core::rust_cfg_feature_evaluator::RustFeatureSelectionConfig;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+use pt01_folder_to_cozodb_streamer::sharded_ingest_graph_merger::IngestDirectoryShardSpec;
+use parseltongue::config::{load_nearest_project_config, ProjectConfigFileSpec};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: ingest_subcommand_definition_list
+pub(crate) fn ingest_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("pt01-folder-to-cozodb-streamer")
+            .about("Tool 1: Stream folder contents to CozoDB with ISGL1 keys")
+            .long_about(
+                "Examples:\n  \
+                parseltongue pt01-folder-to-cozodb-streamer .            # Index current directory\n  \
+                parseltongue pt01-folder-to-cozodb-streamer ./src --db rocksdb:analysis.db --verbose\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --db rocksdb:parseltongue20260101/analysis.db --incremental\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --sqlite-graph graph.sqlite   # SQL-queryable mirror\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --from-index index.scip       # reuse a CI-built SCIP/LSIF index\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --goos windows --build-tags integration   # one Go build configuration\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --no-default-features --features tls   # one Cargo feature set\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --exclude '*.pb.go' --allow 'build/generated/**'   # .gitignore is honored by default"
+            )
+            .arg(
+                Arg::new("directory")
+                    .help("Directory to index [default: current directory]")
+                    .default_value(".")
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path")
+                    .default_value("parseltongue.db"),
+            )
+            .arg(
+                Arg::new("verbose")
+                    .long("verbose")
+                    .short('v')
+                    .help("Enable verbose output")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("quiet")
+                    .long("quiet")
+                    .short('q')
+                    .help("Suppress output")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("incremental")
+                    .long("incremental")
+                    .help("Update the existing --db in place; only re-parse files whose content hash changed")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("shard")
+                    .long("shard")
+                    .value_name("I/N")
+                    .help("Ingest only shard I of N (split by directory); combine the shard databases with `parseltongue merge`")
+                    .conflicts_with("incremental"),
+            )
+            .arg(
+                Arg::new("sqlite-graph")
+                    .long("sqlite-graph")
+                    .value_name("PATH")
+                    .help("Also write the graph as plain SQL tables (nodes, edges, files) to this SQLite file"),
+            )
+            .arg(
+                Arg::new("from-index")
+                    .long("from-index")
+                    .value_name("PATH")
+                    .help("Build the graph from an existing SCIP (index.scip) or LSIF (dump.lsif) index instead of parsing")
+                    .conflicts_with("incremental"),
+            )
+            .arg(
+                Arg::new("build-tags")
+                    .long("build-tags")
+                    .value_name("TAGS")
+                    .help("Go build tags, comma-separated (like go build -tags)"),
+            )
+            .arg(
+                Arg::new("goos")
+                    .long("goos")
+                    .value_name("GOOS")
+                    .help("Go target OS for build-constrained files [default: $GOOS or host]"),
+            )
+            .arg(
+                Arg::new("goarch")
+                    .long("goarch")
+                    .value_name("GOARCH")
+                    .help("Go target architecture for build-constrained files [default: $GOARCH or host]"),
+            )
+            .arg(
+                Arg::new("all-build-configurations")
+                    .long("all-build-configurations")
+                    .help("Index every Go file and tag entities with their build constraint instead of filtering")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("features")
+                    .long("features")
+                    .short('F')
+                    .value_name("FEATURES")
+                    .help("Cargo features for #[cfg(feature)] items, comma or space separated, `crate/feature` for one crate (like cargo)")
+                    .action(clap::ArgAction::Append),
+            )
+            .arg(
+                Arg::new("all-features")
+                    .long("all-features")
+                    .help("Activate every Cargo feature of every crate")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("no-default-features")
+                    .long("no-default-features")
+                    .help("Do not activate the `default` Cargo feature")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("git-blame")
+                    .long("git-blame")
+                    .help("Annotate symbols with last commit, author, age and owner from git blame")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("typed")
+                    .long("typed")
+                    .help("Bind Go calls with go/packages + go/types (slower, exact method sets; needs a Go toolchain)")
+                    .action(clap::ArgAction::SetTrue)
+                    .conflicts_with("incremental"),
+            )
+            .arg(
+                Arg::new("jobs")
+                    .long("jobs")
+                    .short('j')
+                    .help("Parse worker threads (default: GOMAXPROCS if set, else all CPUs)")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("0"),
+            )
+            .arg(
+                Arg::new("exclude")
+                    .long("exclude")
+                    .value_name("GLOB")
+                    .help("Skip files matching this glob, e.g. '*.pb.go' or 'third_party/**' (repeatable)")
+                    .action(clap::ArgAction::Append),
+            )
+            .arg(
+                Arg::new("allow")
+                    .long("allow")
+                    .value_name("GLOB")
+                    .help("Index files matching this glob even if excluded or git-ignored, e.g. generated code (repeatable)")
+                    .action(clap::ArgAction::Append),
+            )
+            .arg(
+                Arg::new("no-gitignore")
+                    .long("no-gitignore")
+                    .help("Also index files ignored by .gitignore / .git/info/exclude")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("quarantine")
+                    .long("quarantine")
+                    .help("Skip and report files whose parse fails or panics instead of aborting the ingest")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("parse-cache")
+                    .long("parse-cache")
+                    .help("Reuse per-file parse results cached by earlier runs, of any repo or branch (~/.cache/parseltongue-companion)")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("parse-cache-dir")
+                    .long("parse-cache-dir")
+                    .value_name("DIR")
+                    .help("Parse cache directory (implies --parse-cache)"),
+            )
+            .arg(
+                Arg::new("progress")
+                    .long("progress")
+                    .value_name("MODE")
+                    .help("Progress output: bar (TTY progress bar), json (one JSON metrics object per line on stderr), none")
+                    .value_parser(["bar", "json", "none"])
+                    .default_value("bar"),
+            ),
+    ]
+}
+
+pub(crate) async fn run_folder_to_cozodb_streamer(matches: &ArgMatches) -> Result<()> {
+    let directory = matches.get_one::<String>("directory").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let verbose = matches.get_flag("verbose");
+    let quiet = matches.get_flag("quiet");
+    let sqlite_graph = matches.get_one::<String>("sqlite-graph").map(String::as_str);
+    let from_index = matches.get_one::<String>("from-index");
+    let go_build_context = parse_go_build_context_args(matches);
+    let rust_features = parse_rust_feature_selection_args(matches);
+    let git_blame = matches.get_flag("git-blame");
+    let worker_threads = *matches.get_one::<usize>("jobs").unwrap();
+    let quarantine = matches.get_flag("quarantine");
+    let parse_cache_dir = resolve_parse_cache_directory_arg(matches)?;
+    let shard: Option<IngestDirectoryShardSpec> =
+        matches.get_one::<String>("shard").map(|s| s.parse()).transpose().map_err(anyhow::Error::msg)?;
+    let progress: IngestProgressOutputMode = matches.get_one::<String>("progress").unwrap().parse().map_err(anyhow::Error::msg)?;
+    // v1.7.3: .parseltongue.toml / .companion.yaml plus --exclude / --allow / --no-gitignore
+    let path_selection = IngestPathSelectionFlags::from_ingest_matches(matches, directory)?;
+
+    // v1.7.3: Incremental mode updates an existing database instead of creating a workspace
+    if matches.get_flag("incremental") {
+        return run_incremental_hash_reindex(
+            directory,
+            db,
+            quiet,
+            sqlite_graph,
+            go_build_context,
+            rust_features,
+            git_blame,
+            worker_threads,
+            quarantine,
+            progress,
+            &path_selection,
+            parse_cache_dir,
+        )
+        .await;
+    }
+
+    // Create timestamped workspace directory
+    let timestamp = chrono::Local::now().format("%Y%m%d%H%M%S").to_string();
+    let workspace_dir = format!("parseltongue{}", timestamp);
+    std::fs::create_dir_all(&workspace_dir)?;
+
+    // Construct database path within workspace
+    let (workspace_db_path, backup_target): (String, Option<String>) = if db == "mem" {
+        ("mem".to_string(), None)
+    } else {
+        // v1.7.2: Windows uses in-memory ingestion + SQLite backup (avoids Defender filesystem interference)
+        // Mac/Linux uses RocksDB directly (fastest, no issues)
+        #[cfg(target_os = "windows")]
+        {
+            println!("  Engine: {} (in-memory ingestion, SQLite backup)", style("mem→SQLite").green());
+            ("mem".to_string(), Some(format!("{}/analysis.db", workspace_dir)))
+        }
+        #[cfg(not(target_os = "windows"))]
+        {
+            (format!("rocksdb:{}/analysis.db", workspace_dir), None)
+        }
+    };
+
+    println!("{}", style("Running Tool 1: folder-to-cozodb-streamer").cyan());
+    if !quiet {
+        println!("  Workspace: {}", style(&workspace_dir).yellow().bold());
+        println!("  Database: {}", &workspace_db_path);
+        path_selection.print_config_file_line();
+    }
+
+    // v1.7.3: A prebuilt SCIP/LSIF index replaces the tree-sitter parse
+    if let Some(index_path) = from_index {
+        return run_code_index_import(
+            index_path,
+            directory,
+            &workspace_db_path,
+            backup_target.as_deref(),
+            quiet,
+            sqlite_graph,
+        )
+        .await;
+    }
+
+    // Create config (S01 ultra-minimalist: let tree-sitter decide what to parse)
+    let mut config = build_ingest_streamer_config(
+        directory,
+        &workspace_db_path,
+        go_build_context,
+        rust_features,
+        git_blame,
+        worker_threads,
+        quarantine,
+        progress,
+        &path_selection,
+    );
+    config.parse_cache_dir = parse_cache_dir;
+    config.shard = shard;
+    config.go_typed_resolution = matches.get_flag("typed");
+
+    // Create and run streamer
+    let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(config.clone()).await?;
+    // Phase 5: Use parallel streaming by default for better performance
+    let result = streamer.stream_directory_with_parallel_rayon().await?;
+
+    // v1.7.2: Windows - backup in-memory database to SQLite file
+    if let Some(ref target) = backup_target {
+        if !quiet {
+            println!("  {} Saving database to disk...", style("↓").cyan());
+        }
+        streamer.backup_to_sqlite(target).await?;
+        if !quiet {
+            println!("  {} Database saved: {}", style("✓").green(), style(target).yellow());
+        }
+    }
+
+    // v1.7.3: Optional relational mirror for SQL tooling
+    if let Some(target) = sqlite_graph {
+        write_relational_sqlite_mirror(streamer.as_ref(), target, quiet).await?;
+    }
+
+    // Write ingestion error log
+    {
+        use std::io::Write;
+        let error_log_path = format!("{}/ingestion-errors.txt", workspace_dir);
+        let mut error_file = std::fs::File::create(&error_log_path)?;
+        writeln!(error_file, "# Parseltongue Ingestion Error Log")?;
+        writeln!(error_file, "# Generated: {}", chrono::Local::now().format("%Y-%m-%dT%H:%M:%S"))?;
+        writeln!(error_file, "# Database: {}", workspace_db_path)?;
+        writeln!(error_file, "# Source: {}", directory)?;
+        writeln!(error_file, "# Total files: {}, Processed: {}, Errors: {}",
+            result.total_files, result.processed_files, result.errors.len())?;
+        writeln!(error_file, "#")?;
+        if result.errors.is_empty() {
+            writeln!(error_file, "# No errors encountered during ingestion.")?;
+        } else {
+            for error in &result.errors {
+                writeln!(error_file, "{}", error)?;
+            }
+        }
+    }
+
+    // Determine display path for next command
+    let display_db_path = if let Some(target) = &backup_target {
+        format!("sqlite:{}", target)
+    } else {
+        workspace_db_path.clone()
+    };
+
+    if !quiet {
+        println!("{}", style("✓ Indexing completed").green().bold());
+        println!("  Files processed: {}", result.processed_files);
+        println!("  Entities created: {}", result.entities_created);
+        if !result.errors.is_empty() {
+            println!("  Errors: {} (see {}/ingestion-errors.txt)", result.errors.len(), workspace_dir);
+        }
+        print_quarantined_file_lines(&result.errors);
+        println!();
+        println!("{}", style("📁 Workspace location:").green().bold());
+        println!("  {}", style(&workspace_dir).yellow().bold());
+        println!();
+        println!("{}", style("Database:").green().bold());
+        println!("  {}", style(&display_db_path).yellow());
+        println!();
+        println!("{}", style("Next step:").cyan());
+        println!("  parseltongue pt08-http-code-query-server \\");
+        println!("    --db \"{}\"", display_db_path);
+        println!();
+        println!("{}", style("Quick test:").cyan());
+        println!("  curl http://localhost:7777/server-health-check-status");
+        println!("  curl http://localhost:7777/codebase-statistics-overview-summary");
+        if verbose {
+            println!("  Duration: {:?}", result.duration);
+        }
+    }
+
+    Ok(())
+}
+
+/// Go build configuration from `--goos` / `--goarch` / `--build-tags` (v1.7.3)
+///
+/// # 4-Word Name: parse_go_build_context_args
+fn parse_go_build_context_args(matches: &ArgMatches) -> GoBuildContextConfig {
+    let mut context = GoBuildContextConfig::default();
+    if let Some(goos) = matches.get_one::<String>("goos") {
+        context.goos = goos.clone();
+    }
+    if let Some(goarch) = matches.get_one::<String>("goarch") {
+        context.goarch = goarch.clone();
+    }
+    if let Some(tags) = matches.get_one::<String>("build-tags") {
+        context.build_tags = tags
+            .split(',')
+            .map(str::trim)
+            .filter(|tag| !tag.is_empty())
+            .map(str::to_string)
+            .collect();
+    }
+    context.index_all_configurations = matches.get_flag("all-build-configurations");
+    context
+}
+
+/// Cargo feature selection from `--features` / `--all-features` / `--no-default-features` (v1.7.3)
+///
+/// # 4-Word Name: parse_rust_feature_selection_args
+fn parse_rust_feature_selection_args(matches: &ArgMatches) -> RustFeatureSelectionConfig {
+    RustFeatureSelectionConfig {
+        features: matches
+            .get_many::<String>("features")
+            .into_iter()
+            .flatten()
+            .flat_map(|list| list.split(|c: char| c == ',' || c.is_whitespace()))
+            .filter(|feature| !feature.is_empty())
+            .map(str::to_string)
+            .collect(),
+        all_features: matches.get_flag("all-features"),
+        no_default_features: matches.get_flag("no-default-features"),
+    }
+}
+
+/// Parse cache directory from `--parse-cache-dir` / `--parse-cache` (v1.7.3)
+///
+/// # 4-Word Name: resolve_parse_cache_directory_arg
+fn resolve_parse_cache_directory_arg(matches: &ArgMatches) -> Result<Option<std::path::PathBuf>> {
+    use pt01_folder_to_cozodb_streamer::persistent_parse_artifact_cache::default_parse_cache_directory;
+
+    if let Some(dir) = matches.get_one::<String>("parse-cache-dir") {
+        return Ok(Some(std::path::PathBuf::from(dir)));
+    }
+    if !matches.get_flag("parse-cache") {
+        return Ok(None);
+    }
+    default_parse_cache_directory()
+        .map(Some)
+        .ok_or_else(|| anyhow::anyhow!("--parse-cache: no HOME or XDG_CACHE_HOME; pass --parse-cache-dir"))
+}
+
+/// Build the pt01 streamer config shared by full and incremental ingest
+///
+/// # 4-Word Name: build_ingest_streamer_config
+pub(crate) fn build_ingest_streamer_config(
+    directory: &str,
+    db_path: &str,
+    go_build_context: GoBuildContextConfig,
+    rust_features: RustFeatureSelectionConfig,
+    git_blame: bool,
+    worker_threads: usize,
+    quarantine: bool,
+    progress: IngestProgressOutputMode,
+    path_selection: &IngestPathSelectionFlags,
+) -> pt01_folder_to_cozodb_streamer::StreamerConfig {
+    // v1.7.3: S01 defaults live in the library so embedders get the same ingest
+    let mut options = parseltongue::ingest::DirectoryIngestRunOptions::for_directory(directory)
+        .with_database(db_path)
+        .with_go_build_context(go_build_context)
+        .with_rust_features(rust_features)
+        .with_git_blame(git_blame)
+        .with_worker_threads(worker_threads)
+        .with_quarantine(quarantine)
+        .with_progress_output(progress);
+    if let Some((_, spec)) = &path_selection.project_config {
+        options = options.with_project_config(spec);
+    }
+    if path_selection.no_gitignore {
+        options = options.with_gitignore(false);
+    }
+    options
+        .with_exclude_globs(path_selection.exclude_globs.iter().cloned())
+        .with_allow_globs(path_selection.allow_globs.iter().cloned())
+        .to_streamer_config()
+}
+
+/// Ingest file selection: project config file plus path flags (v1.7.3)
+///
+/// # 4-Word Name: IngestPathSelectionFlags
+#[derive(Default)]
+pub(crate) struct IngestPathSelectionFlags {
+    pub(crate) project_config: Option<(std::path::PathBuf, ProjectConfigFileSpec)>,
+    exclude_globs: Vec<String>,
+    allow_globs: Vec<String>,
+    no_gitignore: bool,
+}
+
+impl IngestPathSelectionFlags {
+    /// Config file nearest to `directory` only (ingests without path flags)
+    pub(crate) fn for_ingest_directory(directory: &str) -> Result<Self> {
+        Ok(Self {
+            project_config: load_nearest_project_config(std::path::Path::new(directory))?,
+            ..Self::default()
+        })
+    }
+
+    /// Config file plus `--exclude` / `--allow` / `--no-gitignore`
+    fn from_ingest_matches(matches: &ArgMatches, directory: &str) -> Result<Self> {
+        let globs = |id: &str| -> Vec<String> {
+            matches.get_many::<String>(id).map(|values| values.cloned().collect()).unwrap_or_default()
+        };
+        Ok(Self {
+            exclude_globs: globs("exclude"),
+            allow_globs: globs("allow"),
+            no_gitignore: matches.get_flag("no-gitignore"),
+            ..Self::for_ingest_directory(directory)?
+        })
+    }
+
+    fn print_config_file_line(&self) {
+        if let Some((path, _)) = &self.project_config {
+            println!("  Config: {}", path.display());
+        }
+    }
+}
+
+/// Re-index an existing database by file content hash (v1.7.3)
+///
+/// # 4-Word Name: run_incremental_hash_reindex
+///
+/// # Contract
+/// - Precondition: `db` points at a database from a previous pt01 run over the
+///   same `directory` argument (paths are compared verbatim)
+/// - Postcondition: only added/modified files are re-parsed, deleted files are
+///   removed, cross-file Go edges are recomputed for the changed subgraph
+async fn run_incremental_hash_reindex(
+    directory: &str,
+    db: &str,
+    quiet: bool,
+    sqlite_graph: Option<&str>,
+    go_build_context: GoBuildContextConfig,
+    rust_features: RustFeatureSelectionConfig,
+    git_blame: bool,
+    worker_threads: usize,
+    quarantine: bool,
+    progress: IngestProgressOutputMode,
+    path_selection: &IngestPathSelectionFlags,
+    parse_cache_dir: Option<std::path::PathBuf>,
+) -> Result<()> {
+    if db == "mem" {
+        anyhow::bail!("--incremental requires a persistent --db (e.g. rocksdb:parseltongue20260101/analysis.db)");
+    }
+
+    println!("{}", style("Running Tool 1: folder-to-cozodb-streamer (incremental)").cyan());
+    if !quiet {
+        println!("  Database: {}", db);
+        path_selection.print_config_file_line();
+    }
+
+    let mut config = build_ingest_streamer_config(
+        directory,
+        db,
+        go_build_context,
+        rust_features,
+        git_blame,
+        worker_threads,
+        quarantine,
+        progress,
+        path_selection,
+    );
+    config.parse_cache_dir = parse_cache_dir;
+
+    let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(config).await?;
+    let result = streamer.stream_directory_incremental_by_hash().await?;
+
+    if let Some(target) = sqlite_graph {
+        write_relational_sqlite_mirror(streamer.as_ref(), target, quiet).await?;
+    }
+
+    if !quiet {
+        println!("{}", style("✓ Incremental indexing completed").green().bold());
+        println!("  Files re-parsed: {}", result.processed_files);
+        println!("  Entities created: {}", result.entities_created);
+        if !result.errors.is_empty() {
+            println!("  Errors: {}", result.errors.len());
+            for error in result.errors.iter().take(10) {
+                println!("    {}", error);
+            }
+        }
+        print_quarantined_file_lines(&result.errors);
+        println!("  Duration: {:?}", result.duration);
+    }
+
+    Ok(())
+}
+
+/// `--quarantine` report: every skipped file, not just the first errors
+///
+/// # 4-Word Name: print_quarantined_file_lines
+fn print_quarantined_file_lines(errors: &[String]) {
+    use pt01_folder_to_cozodb_streamer::parse_failure_quarantine_guard::QUARANTINE_ERROR_LINE_PREFIX;
+
+    let quarantined: Vec<&String> = errors.iter().filter(|e| e.starts_with(QUARANTINE_ERROR_LINE_PREFIX)).collect();
+    if quarantined.is_empty() {
+        return;
+    }
+    println!("  {} {} files quarantined (not in the graph):", style("⚠").yellow(), quarantined.len());
+    for line in quarantined {
+        println!("    {}", line.trim_start_matches(QUARANTINE_ERROR_LINE_PREFIX).trim_start());
+    }
+}
+
+/// Ingest a SCIP or LSIF index into a fresh database (v1.7.3)
+///
+/// # 4-Word Name: run_code_index_import
+///
+/// # Contract
+/// - Precondition: `index_path` was produced by an indexer (scip-go,
+///   rust-analyzer, ...) for the source tree at `directory`
+/// - Postcondition: entities/edges from the index are stored with the same
+///   key and placeholder conventions as a parsed ingest; source text is read
+///   from `directory` when available
+async fn run_code_index_import(
+    index_path: &str,
+    directory: &str,
+    db_path: &str,
+    backup_target: Option<&str>,
+    quiet: bool,
+    sqlite_graph: Option<&str>,
+) -> Result<()> {
+    use parseltongue_core::external_code_index_importer::import_code_index_file;
+
+    let started = std::time::Instant::now();
+    if !quiet {
+        println!("  Index: {}", style(index_path).yellow());
+    }
+    let mut graph = import_code_index_file(
+        std::path::Path::new(index_path),
+        Some(std::path::Path::new(directory)),
+    )?;
+    // v1.7.3: compiler-index edges are type-resolved
+    parseltongue_core::edge_confidence_provenance_scorer::stamp_edge_confidence_provenance(&mut graph.edges);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db_path).await?;
+    storage.create_schema().await?;
+    storage.create_dependency_edges_schema().await?;
+    storage.insert_entities_batch(&graph.entities).await?;
+    storage.insert_edges_batch(&graph.edges).await?;
+
+    if let Some(target) = backup_target {
+        storage.backup_to_sqlite_file(target).await?;
+    }
+    if let Some(target) = sqlite_graph {
+        let counts = parseltongue_core::storage::relational_sqlite_graph_store::export_cozo_graph_to_sqlite(&storage, target).await?;
+        if !quiet {
+            println!(
+                "  {} SQL graph: {} ({} nodes, {} edges, {} files)",
+                style("✓").green(),
+                style(target).yellow(),
+                counts.nodes,
+                counts.edges,
+                counts.files
+            );
+        }
+    }
+
+    if !quiet {
+        println!("{}", style("✓ Index import completed").green().bold());
+        println!("  Entities created: {}", graph.entities.len());
+        println!("  Edges created: {}", graph.edges.len());
+        if graph.unattributed_references > 0 {
+            println!("  References outside any definition: {}", graph.unattributed_references);
+        }
+        println!("  Duration: {:?}", started.elapsed());
+    }
+    Ok(())
+}
+
+/// Write the relational SQLite mirror after an ingest (v1.7.3)
+///
+/// # 4-Word Name: write_relational_sqlite_mirror
+async fn write_relational_sqlite_mirror(
+    streamer: &impl FileStreamer,
+    target: &str,
+    quiet: bool,
+) -> Result<()> {
+    let counts = streamer.export_relational_sqlite_graph(target).await?;
+    if !quiet {
+        println!(
+            "  {} SQL graph: {} ({} nodes, {} edges, {} files)",
+            style("✓").green(),
+            style(target).yellow(),
+            counts.nodes,
+            counts.edges,
+            counts.files
+        );
+    }
+    Ok(())
+}
@@ -0,0 +1,495 @@
+//! Maintenance CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: maintenance_subcommand_definition_list
+//!
+//! Backs `bench`, `cache`, `merge`, `federate` and `capabilities`: index
+//! benchmarks, cache upkeep, shard and repository merges.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use crate::commands::ingest_commands::IngestPathSelectionFlags;
+use crate::commands::shared_cli_arg_helpers::load_working_directory_config;
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: maintenance_subcommand_definition_list
+pub(crate) fn maintenance_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("bench")
+            .about("Measure parseltongue performance")
+            .subcommand_required(true)
+            .subcommand(
+                Command::new("ingest")
+                    .about("Ingest a directory repeatedly and report throughput and phase timings as JSON")
+                    .long_about(
+                        "Runs the full parallel ingest N times, each into a fresh in-memory database, and\n\
+                        reports files/s, MB/s and milliseconds per phase (scan, parse, resolve, store)\n\
+                        for every run, their median, and the peak resident set size (Linux).\n\
+                        Ingest summaries go to stdout as usual; use --output for a clean JSON file.\n\n\
+                        Examples:\n  \
+                        parseltongue bench ingest . --repeat 5\n  \
+                        parseltongue bench ingest ./service --repeat 3 --jobs 4 --output bench.json"
+                    )
+                    .arg(
+                        Arg::new("directory")
+                            .help("Directory to ingest")
+                            .default_value(".")
+                            .index(1),
+                    )
+                    .arg(
+                        Arg::new("repeat")
+                            .long("repeat")
+                            .help("Number of ingest runs")
+                            .value_parser(clap::value_parser!(usize))
+                            .default_value("3"),
+                    )
+                    .arg(
+                        Arg::new("jobs")
+                            .long("jobs")
+                            .short('j')
+                            .help("Parse worker threads (default: GOMAXPROCS if set, else all CPUs)")
+                            .value_parser(clap::value_parser!(usize))
+                            .default_value("0"),
+                    )
+                    .arg(
+                        Arg::new("output")
+                            .long("output")
+                            .short('o')
+                            .help("Write the JSON report to this file instead of stdout"),
+                    ),
+            ),
+        Command::new("cache")
+            .about("Inspect or trim the cross-run parse cache (--parse-cache); verify stored summaries/embeddings")
+            .subcommand_required(true)
+            .arg(
+                Arg::new("dir")
+                    .long("dir")
+                    .value_name("DIR")
+                    .global(true)
+                    .help("Cache directory (default: $PARSELTONGUE_CACHE_DIR, else ~/.cache/parseltongue-companion)"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .global(true)
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .subcommand(
+                Command::new("stats")
+                    .about("Number and size of cached parse artifacts"),
+            )
+            .subcommand(
+                Command::new("gc")
+                    .about("Remove artifacts of older extractor versions, plus old ones beyond an age or size budget")
+                    .long_about(
+                        "Artifacts written by an older parse cache schema are always removed.\n\
+                        --max-age-days drops artifacts not rewritten for N days; --max-size-mb then\n\
+                        drops the oldest until the cache fits.\n\n\
+                        Examples:\n  \
+                        parseltongue cache gc\n  \
+                        parseltongue cache gc --max-age-days 30 --max-size-mb 2048"
+                    )
+                    .arg(
+                        Arg::new("max-age-days")
+                            .long("max-age-days")
+                            .value_name("DAYS")
+                            .help("Remove artifacts older than this")
+                            .value_parser(clap::value_parser!(u64)),
+                    )
+                    .arg(
+                        Arg::new("max-size-mb")
+                            .long("max-size-mb")
+                            .value_name("MB")
+                            .help("Remove the oldest artifacts until the cache is at most this size")
+                            .value_parser(clap::value_parser!(u64)),
+                    ),
+            )
+            .subcommand(
+                Command::new("verify")
+                    .about("Report stored summaries and embeddings that no longer match their symbol")
+                    .long_about(
+                        "Recomputes the summary hash and embedding input hash of every symbol and lists\n\
+                        stale entries (made from older code) and orphan embeddings (symbol removed).\n\
+                        Incremental re-indexes invalidate changed symbols only; verify checks that nothing\n\
+                        stale was left behind. `summarize` and `embed` refresh what it reports.\n\n\
+                        Examples:\n  \
+                        parseltongue cache verify --db rocksdb:analysis.db\n  \
+                        parseltongue cache verify --db rocksdb:analysis.db --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required(true),
+                    ),
+            ),
+        Command::new("merge")
+            .about("Combine databases of a sharded ingest (--shard I/N) into one index")
+            .long_about(
+                "Unions the entities and edges of every shard database, then runs the cross-file\n\
+                resolution a single-process ingest would (imports, embeddings, interface\n\
+                satisfaction, dispatch fan-out), so cross-shard references are resolved.\n\
+                Every shard must be ingested from the same directory argument with the same N.\n\n\
+                Examples:\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --shard 1/2\n  \
+                parseltongue pt01-folder-to-cozodb-streamer . --shard 2/2\n  \
+                parseltongue merge rocksdb:parseltongueA/analysis.db rocksdb:parseltongueB/analysis.db --db rocksdb:merged.db"
+            )
+            .arg(
+                Arg::new("shards")
+                    .help("Shard databases as printed by each sharded ingest, e.g. rocksdb:parseltongueXXX/analysis.db")
+                    .required(true)
+                    .num_args(1..)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database to write the merged graph to")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("root")
+                    .long("root")
+                    .help("Directory the shards were ingested from (module and tsconfig discovery)")
+                    .default_value("."),
+            ),
+        Command::new("federate")
+            .about("Join the indexes of several repositories, binding cross-repository calls")
+            .long_about(
+                "Unions the graphs of registered repositories (a service and the client library\n\
+                it imports, or a whole org) into one database. Calls that end in an external\n\
+                placeholder in one repository are bound to the public symbol of the repository\n\
+                publishing that Go module or Rust crate, so blast-radius and path cross repos.\n\
+                Repositories come from --repo and from [repos.<name>] db = \"...\" in the config.\n\n\
+                Examples:\n  \
+                parseltongue federate --repo billing=rocksdb:billing/analysis.db --repo client=rocksdb:client/analysis.db --db rocksdb:org.db\n  \
+                parseltongue blast-radius NewToken --db rocksdb:org.db"
+            )
+            .arg(
+                Arg::new("repo")
+                    .long("repo")
+                    .value_name("NAME=DB")
+                    .action(clap::ArgAction::Append)
+                    .help("Repository name and its database (repeatable; adds to [repos] of the config)"),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database to write the federated graph to")
+                    .required(true),
+            ),
+        Command::new("capabilities")
+            .about("Node kinds, edge types, features and versions of each language's extractors")
+            .long_about(
+                "Lists per language what the installed extractors produce: node kinds (key\n\
+                kind segments), edge types, features such as generics, imports or macros, and\n\
+                an extractor version pinned to the embedded queries and grammar ABI. Every\n\
+                ingest stores this manifest with the graph and every JSON snapshot embeds it;\n\
+                with --db the graph's manifest is compared with the installed one, so a graph\n\
+                built without (say) generics support is told apart from code without generics.\n\n\
+                Examples:\n  \
+                parseltongue capabilities --json\n  \
+                parseltongue capabilities --db rocksdb:analysis.db"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+    ]
+}
+
+pub(crate) async fn run_benchmark_command(matches: &ArgMatches) -> Result<()> {
+    match matches.subcommand() {
+        Some(("ingest", sub_matches)) => run_ingest_benchmark_command(sub_matches).await,
+        _ => anyhow::bail!("Unknown benchmark; see `parseltongue bench --help`"),
+    }
+}
+
+async fn run_ingest_benchmark_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue::bench::run_repeated_ingest_benchmark;
+    use parseltongue::ingest::DirectoryIngestRunOptions;
+
+    let directory = matches.get_one::<String>("directory").unwrap();
+    let repeat = *matches.get_one::<usize>("repeat").unwrap();
+    let jobs = *matches.get_one::<usize>("jobs").unwrap();
+
+    let path_selection = IngestPathSelectionFlags::for_ingest_directory(directory)?;
+    let mut options = DirectoryIngestRunOptions::for_directory(directory).with_worker_threads(jobs);
+    if let Some((_, spec)) = &path_selection.project_config {
+        options = options.with_project_config(spec);
+    }
+    let report = run_repeated_ingest_benchmark(&options, repeat).await?;
+    let json = serde_json::to_string_pretty(&report)?;
+
+    match matches.get_one::<String>("output") {
+        Some(path) => {
+            std::fs::write(path, format!("{}\n", json))?;
+            eprintln!("{} Benchmark report written to {}", style("✓").green(), path);
+        }
+        None => println!("{}", json),
+    }
+    Ok(())
+}
+
+pub(crate) async fn run_shard_merge_command(matches: &ArgMatches) -> Result<()> {
+    let shards: Vec<String> = matches.get_many::<String>("shards").unwrap().cloned().collect();
+    let db = matches.get_one::<String>("db").unwrap();
+    let root = matches.get_one::<String>("root").unwrap();
+
+    println!("{}", style(format!("Merging {} shard databases into {}", shards.len(), db)).cyan());
+    let mut options = parseltongue::ingest::DirectoryIngestRunOptions::for_directory(root).with_database(db);
+    if let Some((_, spec)) = &IngestPathSelectionFlags::for_ingest_directory(root)?.project_config {
+        options = options.with_project_config(spec);
+    }
+    let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(options.to_streamer_config()).await?;
+    let result = streamer.merge_shard_databases(&shards).await?;
+
+    println!("{}", style("✓ Merge completed").green().bold());
+    println!("  Files: {}", result.processed_files);
+    println!("  Entities: {}", result.entities_created);
+    if !result.errors.is_empty() {
+        println!("  Errors: {}", result.errors.len());
+        for error in result.errors.iter().take(10) {
+            println!("    {}", error);
+        }
+    }
+    println!("  Duration: {:?}", result.duration);
+    Ok(())
+}
+
+pub(crate) async fn run_repository_federation_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::edge_confidence_provenance_scorer::stamp_edge_confidence_provenance;
+    use parseltongue_core::federated_repository_graph_merger::{
+        federate_repository_graph_parts, FederatedRepositoryGraphPart,
+    };
+
+    let mut repos = load_working_directory_config()?.federated_repo_database_specs();
+    for spec in matches.get_many::<String>("repo").into_iter().flatten() {
+        let Some((name, db)) = spec.split_once('=') else {
+            anyhow::bail!("--repo '{}' is not NAME=DB", spec);
+        };
+        repos.retain(|(existing, _)| existing.as_str() != name);
+        repos.push((name.to_string(), db.to_string()));
+    }
+    if repos.len() < 2 {
+        anyhow::bail!("Federation needs at least two repositories (--repo NAME=DB or [repos.<name>] in the config)");
+    }
+
+    let db = matches.get_one::<String>("db").unwrap();
+    println!("{}", style(format!("Federating {} repositories into {}", repos.len(), db)).cyan());
+    let mut parts = Vec::with_capacity(repos.len());
+    for (name, repo_db) in &repos {
+        let storage = parseltongue_core::storage::CozoDbStorage::new(repo_db).await?;
+        let entities = storage.get_all_entities_with_metadata().await?;
+        let edges = storage.get_all_dependencies().await?;
+        println!("  {}: {} entities, {} edges ({})", name, entities.len(), edges.len(), repo_db);
+        parts.push(FederatedRepositoryGraphPart { name: name.clone(), entities, edges });
+    }
+    let mut merged = federate_repository_graph_parts(parts);
+    stamp_edge_confidence_provenance(&mut merged.edges);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    storage.create_schema().await?;
+    storage.create_dependency_edges_schema().await?;
+    storage.insert_entities_batch(&merged.entities).await?;
+    storage.insert_edges_batch(&merged.edges).await?;
+    parseltongue_core::symbol_centrality_score_ranker::refresh_stored_symbol_centrality_scores(&storage).await?;
+
+    println!("{}", style("✓ Federation completed").green().bold());
+    println!("  Entities: {}", merged.entities.len());
+    println!("  Edges: {}", merged.edges.len());
+    println!("  Cross-repository edges bound: {}", merged.cross_repo_edge_count);
+    if !merged.key_collisions.is_empty() {
+        println!("  Key collisions (first repository kept): {}", merged.key_collisions.len());
+        for key in merged.key_collisions.iter().take(10) {
+            println!("    {}", key);
+        }
+    }
+    Ok(())
+}
+
+pub(crate) fn run_parse_cache_command(matches: &ArgMatches) -> Result<()> {
+    use pt01_folder_to_cozodb_streamer::persistent_parse_artifact_cache::{
+        default_parse_cache_directory, ParseArtifactCacheStore,
+    };
+
+    let dir = match matches.get_one::<String>("dir") {
+        Some(dir) => std::path::PathBuf::from(dir),
+        None => default_parse_cache_directory()
+            .ok_or_else(|| anyhow::anyhow!("No HOME or XDG_CACHE_HOME; pass --dir"))?,
+    };
+    let store = ParseArtifactCacheStore::open_parse_cache_store(dir);
+
+    match matches.subcommand() {
+        Some(("stats", sub_matches)) => {
+            let stats = store.collect_cache_usage_stats();
+            if sub_matches.get_flag("json") {
+                println!("{}", serde_json::to_string_pretty(&stats)?);
+                return Ok(());
+            }
+            println!("Parse cache: {} (schema v{})", stats.directory, stats.schema_version);
+            println!("  Entries: {} ({:.1} MB)", stats.entries, stats.total_bytes as f64 / (1024.0 * 1024.0));
+            if let Some(days) = stats.oldest_entry_age_days {
+                println!("  Oldest entry: {} days", days);
+            }
+            if stats.stale_entries > 0 {
+                println!(
+                    "  Stale (older schema): {} ({:.1} MB) - run `parseltongue cache gc`",
+                    stats.stale_entries,
+                    stats.stale_bytes as f64 / (1024.0 * 1024.0)
+                );
+            }
+            Ok(())
+        }
+        Some(("gc", sub_matches)) => {
+            let max_age = sub_matches
+                .get_one::<u64>("max-age-days")
+                .map(|days| std::time::Duration::from_secs(days * 86_400));
+            let max_bytes = sub_matches.get_one::<u64>("max-size-mb").map(|mb| mb * 1024 * 1024);
+            let outcome = store.remove_expired_cache_entries(max_age, max_bytes);
+            if sub_matches.get_flag("json") {
+                println!("{}", serde_json::to_string_pretty(&outcome)?);
+                return Ok(());
+            }
+            println!(
+                "{} Removed {} artifacts ({:.1} MB); {} remain ({:.1} MB)",
+                style("✓").green(),
+                outcome.removed_entries,
+                outcome.freed_bytes as f64 / (1024.0 * 1024.0),
+                outcome.remaining_entries,
+                outcome.remaining_bytes as f64 / (1024.0 * 1024.0)
+            );
+            Ok(())
+        }
+        _ => anyhow::bail!("Unknown cache command; see `parseltongue cache --help`"),
+    }
+}
+
+/// Report summaries and embeddings made from code that has since changed (v1.7.3)
+///
+/// # 4-Word Name: run_derived_cache_verify_command
+pub(crate) async fn run_derived_cache_verify_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::derived_symbol_artifact_invalidator::verify_derived_symbol_artifacts;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let embeddings = storage.get_all_symbol_embeddings().await?;
+    let report = verify_derived_symbol_artifacts(&entities, &embeddings);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&report)?);
+        return Ok(());
+    }
+    println!("Derived artifacts of {} symbol(s):", report.symbols_checked);
+    println!(
+        "  Summaries: {} current, {} stale",
+        report.summaries_current,
+        report.stale_summary_keys.len()
+    );
+    println!(
+        "  Embeddings: {} current, {} stale, {} orphaned",
+        report.embeddings_current,
+        report.stale_embedding_keys.len(),
+        report.orphan_embedding_keys.len()
+    );
+    for key in report.stale_summary_keys.iter().take(20) {
+        println!("  {} stale summary: {}", style("⚠").yellow(), key);
+    }
+    for key in report.stale_embedding_keys.iter().chain(&report.orphan_embedding_keys).take(20) {
+        println!("  {} stale embedding: {}", style("⚠").yellow(), key);
+    }
+    if report.has_stale_artifact_entries() {
+        println!("  Refresh with `parseltongue summarize` and `parseltongue embed`");
+    } else {
+        println!("{} Nothing stale", style("✓").green());
+    }
+    Ok(())
+}
+
+/// Print the installed extractor capabilities, and their drift from a graph (v1.7.3)
+///
+/// # 4-Word Name: run_extractor_capabilities_command
+pub(crate) async fn run_extractor_capabilities_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::extractor_capability_manifest_builder::{
+        build_installed_capability_manifest, diff_capability_manifest_pair,
+    };
+
+    let installed = build_installed_capability_manifest()?;
+    let graph = match matches.get_one::<String>("db") {
+        Some(db) => {
+            let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+            Some(storage.get_extractor_capability_manifest().await?)
+        }
+        None => None,
+    };
+    let drift = match &graph {
+        Some(Some(manifest)) => diff_capability_manifest_pair(manifest, &installed),
+        _ => Vec::new(),
+    };
+
+    if matches.get_flag("json") {
+        let document = match &graph {
+            Some(manifest) => serde_json::json!({ "graph": manifest, "installed": installed, "drift": drift }),
+            None => serde_json::to_value(&installed)?,
+        };
+        println!("{}", serde_json::to_string_pretty(&document)?);
+        return Ok(());
+    }
+
+    println!("Installed extractors (parseltongue {}):", installed.parseltongue_version);
+    for entry in &installed.languages {
+        println!(
+            "  {:<11} {:<19} {}  {} node kinds, {} edge types; {}",
+            entry.language,
+            entry.extractor,
+            entry.extractor_version,
+            entry.node_kinds.len(),
+            entry.edge_types.len(),
+            if entry.features.is_empty() { "-".to_string() } else { entry.features.join(", ") }
+        );
+    }
+    match graph {
+        None => {}
+        Some(None) => println!(
+            "{} The graph records no capabilities (indexed before they were stored); re-index to add them",
+            style("⚠").yellow()
+        ),
+        Some(Some(manifest)) if drift.is_empty() => println!(
+            "{} Graph was built by the installed extractors (parseltongue {})",
+            style("✓").green(),
+            manifest.parseltongue_version
+        ),
+        Some(Some(manifest)) => {
+            println!("Graph built by parseltongue {}; extractors differ for:", manifest.parseltongue_version);
+            for entry in &drift {
+                println!(
+                    "  {} {}: {} -> {}",
+                    style("⚠").yellow(),
+                    entry.language,
+                    entry.graph_version.as_deref().unwrap_or("absent"),
+                    entry.installed_version.as_deref().unwrap_or("absent")
+                );
+                if !entry.missing_in_graph.is_empty() {
+                    println!("      not in graph: {}", entry.missing_in_graph.join(", "));
+                }
+                if !entry.missing_in_installed.is_empty() {
+                    println!("      no longer extracted: {}", entry.missing_in_installed.join(", "));
+                }
+            }
+            println!("  Re-index without --incremental to apply the installed extractors everywhere");
+        }
+    }
+    Ok(())
+}
@@ -0,0 +1,20 @@
+//! Subcommand implementations behind `main.rs` (v1.7.3)
+//!
+//! # 4-Word Naming: commands
+//!
+//! Each module owns a family of subcommands: its `*_subcommand_definition_list`
+//! returns the clap definitions `build_cli` registers, and its `run_*`
+//! functions are what `main` dispatches to. Helpers used by more than one
+//! family live in `shared_cli_arg_helpers`.
+
+pub(crate) mod analyze_commands;
+pub(crate) mod context_commands;
+pub(crate) mod export_commands;
+pub(crate) mod history_commands;
+pub(crate) mod ingest_commands;
+pub(crate) mod maintenance_commands;
+pub(crate) mod query_commands;
+pub(crate) mod search_commands;
+pub(crate) mod server_commands;
+pub(crate) mod shared_cli_arg_helpers;
+pub(crate) mod traversal_commands;
@@ -0,0 +1,725 @@
+//! Query CLI command (v1.7.3)
+//!
+//! # 4-Word Naming: query_subcommand_definition_list
+//!
+//! Backs `query`: centrality rank, fuzzy find, HTTP routes, wire fields,
+//! mmapped graph files and stale code.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use crate::commands::history_commands::{history_database_path_from_matches, revision_history_location_args};
+use crate::commands::shared_cli_arg_helpers::{node_slice_filter_args, node_slice_filter_from_matches};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: query_subcommand_definition_list
+pub(crate) fn query_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("query")
+            .about("Queries over the stored graph (centrality rank, stale code, binary graph files, fuzzy find)")
+            .subcommand_required(true)
+            .subcommand(
+                Command::new("rank")
+                    .about("Most architecturally central symbols")
+                    .long_about(
+                        "Ranks symbols by importance: a blend of PageRank (heavily depended upon)\n\
+                        and betweenness (bridges between parts of the graph). Scores are stored at\n\
+                        ingest time; --recompute refreshes them from the current edges.\n\n\
+                        Examples:\n  \
+                        parseltongue query rank --top 50 --db rocksdb:analysis.db\n  \
+                        parseltongue query rank --top 20 --db rocksdb:analysis.db --json\n  \
+                        parseltongue query rank --top 20 --at v1.2.0   # as recorded by `history record`"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required_unless_present("at"),
+                    )
+                    .args(revision_snapshot_at_args())
+                    .arg(
+                        Arg::new("top")
+                            .long("top")
+                            .help("Number of symbols to list")
+                            .value_parser(clap::value_parser!(usize))
+                            .default_value("50"),
+                    )
+                    .arg(
+                        Arg::new("recompute")
+                            .long("recompute")
+                            .help("Recompute and store scores before ranking")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .args(node_slice_filter_args()),
+            )
+            .subcommand(
+                Command::new("stale")
+                    .about("Symbols untouched for at least --days (needs ingest --git-blame)")
+                    .long_about(
+                        "Lists symbols whose newest blamed line is at least --days old, oldest first.\n\
+                        Requires a graph indexed with `pt01-folder-to-cozodb-streamer --git-blame`.\n\n\
+                        Examples:\n  \
+                        parseltongue query stale --days 365 --db rocksdb:analysis.db\n  \
+                        parseltongue query stale --days 180 --owner \"Ada Lovelace\" --db rocksdb:analysis.db --json"
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required_unless_present("at"),
+                    )
+                    .args(revision_snapshot_at_args())
+                    .arg(
+                        Arg::new("days")
+                            .long("days")
+                            .help("Minimum age in days since the last change")
+                            .value_parser(clap::value_parser!(i64))
+                            .default_value("365"),
+                    )
+                    .arg(
+                        Arg::new("owner")
+                            .long("owner")
+                            .help("Only symbols owned by this author"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .args(node_slice_filter_args()),
+            )
+            .subcommand(
+                Command::new("graph-file")
+                    .about("Query a binary graph file (export --format binary) in place via mmap")
+                    .long_about(
+                        "Memory-maps a .ptg file and answers lookups without loading the database\n\
+                        or deserializing the graph. Without --callers/--callees, prints its size.\n\n\
+                        Examples:\n  \
+                        parseltongue query graph-file --file graph.ptg\n  \
+                        parseltongue query graph-file --file graph.ptg --callers 'go:fn:Save:__store:T10' --json"
+                    )
+                    .arg(
+                        Arg::new("file")
+                            .long("file")
+                            .help("Path of the .ptg file")
+                            .required(true),
+                    )
+                    .arg(
+                        Arg::new("callers")
+                            .long("callers")
+                            .help("List edges into this entity key")
+                            .conflicts_with("callees"),
+                    )
+                    .arg(
+                        Arg::new("callees")
+                            .long("callees")
+                            .help("List edges out of this entity key"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .args(node_slice_filter_args()),
+            )
+            .subcommand(
+                Command::new("find")
+                    .about("Fuzzy symbol search ranked by camel-hump matches and package proximity")
+                    .long_about(
+                        "Each dotted segment of the pattern matches a segment of the qualified name in\n\
+                        order (package directories, receiver type, name) as a case-insensitive\n\
+                        subsequence; the last one must match the name. --near boosts symbols whose\n\
+                        package shares leading directories with the given one.\n\n\
+                        Examples:\n  \
+                        parseltongue query find usrSvc.creat --db rocksdb:analysis.db\n  \
+                        parseltongue query find hndlLgn --near internal/api --top 5 --db rocksdb:analysis.db --json\n  \
+                        parseltongue query find usrSvc.creat --at HEAD~10"
+                    )
+                    .arg(
+                        Arg::new("pattern")
+                            .help("Abbreviated symbol name, e.g. usrSvc.creat")
+                            .required(true)
+                            .index(1),
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required_unless_present("at"),
+                    )
+                    .args(revision_snapshot_at_args())
+                    .arg(
+                        Arg::new("top")
+                            .long("top")
+                            .help("Number of matches to list")
+                            .value_parser(clap::value_parser!(usize))
+                            .default_value("20"),
+                    )
+                    .arg(
+                        Arg::new("near")
+                            .long("near")
+                            .help("Package directory to rank nearby symbols higher (e.g. internal/api)"),
+                    )
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    )
+                    .args(node_slice_filter_args()),
+            )
+            .subcommand(
+                Command::new("route")
+                    .about("Routes serving a URL and the handler code behind them")
+                    .long_about(
+                        "Matches a concrete URL against the Route nodes ingested from router\n\
+                        registrations (net/http, gorilla, gin, echo, chi, axum, actix-web, rocket)\n\
+                        and prints each matching route, its bound path parameters and the code of\n\
+                        its handlers, most specific route first. Without a URL, lists every route.\n\n\
+                        Examples:\n  \
+                        parseltongue query route /users/42 --method GET --db rocksdb:analysis.db\n  \
+                        parseltongue query route https://api.example.com/v1/orders --db rocksdb:analysis.db --json\n  \
+                        parseltongue query route --db rocksdb:analysis.db"
+                    )
+                    .arg(
+                        Arg::new("url")
+                            .help("Request path or URL, e.g. /users/42")
+                            .index(1),
+                    )
+                    .arg(
+                        Arg::new("method")
+                            .long("method")
+                            .help("HTTP method of the request (routes registered for any method always match)"),
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required_unless_present("at"),
+                    )
+                    .args(revision_snapshot_at_args())
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            )
+            .subcommand(
+                Command::new("wire-field")
+                    .about("Structs whose tags or serde attributes produce a serialized field name")
+                    .long_about(
+                        "Lists the Go structs (json/yaml/db/... tags, gorm columns) and Rust structs\n\
+                        (serde, sqlx) that encode a field under NAME, with the declared field and\n\
+                        its location. Names match exactly; serde names answer every format serde\n\
+                        writes (json, yaml, toml, ...) but not db.\n\n\
+                        Examples:\n  \
+                        parseltongue query wire-field created_at --format json --db rocksdb:analysis.db\n  \
+                        parseltongue query wire-field user_id --format db --db rocksdb:analysis.db --json"
+                    )
+                    .arg(
+                        Arg::new("name")
+                            .help("Serialized field name, e.g. created_at")
+                            .required(true)
+                            .index(1),
+                    )
+                    .arg(
+                        Arg::new("format")
+                            .long("format")
+                            .help("Only this format: json, yaml, xml, toml, bson, db, protobuf, ..."),
+                    )
+                    .arg(
+                        Arg::new("db")
+                            .long("db")
+                            .help("Database file path (rocksdb:path or sqlite:path)")
+                            .required_unless_present("at"),
+                    )
+                    .args(revision_snapshot_at_args())
+                    .arg(
+                        Arg::new("json")
+                            .long("json")
+                            .help("Emit results as JSON")
+                            .action(clap::ArgAction::SetTrue),
+                    ),
+            ),
+    ]
+}
+
+/// `--at` of the query subcommands, with the history location flags (v1.7.3)
+///
+/// # 4-Word Name: revision_snapshot_at_args
+fn revision_snapshot_at_args() -> [Arg; 3] {
+    let [repo, history] = revision_history_location_args();
+    [
+        Arg::new("at")
+            .long("at")
+            .value_name("REV")
+            .help("Query the graph as recorded at this revision (see `history record`) instead of --db")
+            .conflicts_with("db"),
+        repo,
+        history,
+    ]
+}
+
+/// `--db`, or for `--at REV` the snapshot recorded at REV as a `snapshot:` spec
+///
+/// # 4-Word Name: query_database_spec_from_matches
+///
+/// # Contract
+/// - Postcondition: a snapshot is extracted once per commit, next to the
+///   history file (`history.snapshots/<sha>.json.zst`), and reused afterwards
+/// - Error: REV unknown to git, or never recorded
+fn query_database_spec_from_matches(matches: &ArgMatches) -> Result<String> {
+    use parseltongue_core::api_surface_diff_reporter::resolve_revision_commit_details;
+    use parseltongue_core::storage::revision_history_sqlite_store::RevisionHistorySqliteStore;
+
+    let Some(revision) = matches.get_one::<String>("at") else {
+        return Ok(matches.get_one::<String>("db").unwrap().clone());
+    };
+    let repo = std::fs::canonicalize(matches.get_one::<String>("repo").unwrap())?;
+    let commit = resolve_revision_commit_details(&repo, revision)?;
+    let history_path = history_database_path_from_matches(matches)?;
+    let snapshot_dir = history_path.with_extension("snapshots");
+    let snapshot = snapshot_dir.join(format!("{}.json.zst", commit.commit_sha));
+    if !snapshot.is_file() {
+        std::fs::create_dir_all(&snapshot_dir)?;
+        let store = RevisionHistorySqliteStore::open_with_history_schema(&history_path)?;
+        // Written aside and renamed, so an interrupted extraction is never reused
+        let partial = snapshot.with_extension("partial");
+        if !store.write_revision_snapshot_file(&commit.commit_sha, &partial)? {
+            anyhow::bail!(
+                "{} ({}) is not recorded in {}; run `parseltongue history record {}`",
+                revision,
+                &commit.commit_sha[..8],
+                history_path.display(),
+                revision
+            );
+        }
+        std::fs::rename(&partial, &snapshot)?;
+    }
+    Ok(format!("snapshot:{}", snapshot.display()))
+}
+
+pub(crate) async fn run_graph_query_command(matches: &ArgMatches) -> Result<()> {
+    match matches.subcommand() {
+        Some(("rank", sub_matches)) => run_centrality_rank_query_command(sub_matches).await,
+        Some(("stale", sub_matches)) => run_stale_code_query_command(sub_matches).await,
+        Some(("graph-file", sub_matches)) => run_binary_graph_file_query(sub_matches),
+        Some(("find", sub_matches)) => run_fuzzy_symbol_find_query(sub_matches).await,
+        Some(("route", sub_matches)) => run_http_route_lookup_query(sub_matches).await,
+        Some(("wire-field", sub_matches)) => run_wire_field_producer_query(sub_matches).await,
+        _ => anyhow::bail!("Unknown query; see `parseltongue query --help`"),
+    }
+}
+
+async fn run_centrality_rank_query_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::symbol_centrality_score_ranker::refresh_stored_symbol_centrality_scores;
+
+    let db = &query_database_spec_from_matches(matches)?;
+    let top = *matches.get_one::<usize>("top").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut records = storage.get_all_symbol_centrality_scores().await?;
+    if records.is_empty() || matches.get_flag("recompute") {
+        refresh_stored_symbol_centrality_scores(&storage).await?;
+        records = storage.get_all_symbol_centrality_scores().await?;
+    }
+    if !filter.is_unrestricted() {
+        // Scores stay those of the whole graph; only the listing is sliced
+        let entities = storage.get_all_entities().await?;
+        let by_key: std::collections::HashMap<&str, &parseltongue_core::entities::CodeEntity> =
+            entities.iter().map(|entity| (entity.isgl1_key.as_str(), entity)).collect();
+        records.retain(|record| filter.matches_graph_node_entry(&record.isgl1_key, by_key.get(record.isgl1_key.as_str()).copied()));
+    }
+    let total = records.len();
+    records.truncate(top);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "total_ranked": total,
+            "ranked": records,
+        }))?);
+        return Ok(());
+    }
+
+    if records.is_empty() {
+        println!("No dependency edges to rank");
+        return Ok(());
+    }
+    println!("{:>4}  {:>10}  {:>9}  {:>11}  {:>4}  {:>4}  KEY", "#", "IMPORTANCE", "PAGERANK", "BETWEENNESS", "IN", "OUT");
+    for (index, record) in records.iter().enumerate() {
+        println!(
+            "{:>4}  {:>10.3}  {:>9.5}  {:>11.1}  {:>4}  {:>4}  {}",
+            index + 1,
+            record.importance,
+            record.pagerank,
+            record.betweenness,
+            record.in_degree,
+            record.out_degree,
+            record.isgl1_key
+        );
+    }
+    println!("Showing {} of {} ranked symbols", records.len(), total);
+    Ok(())
+}
+
+async fn run_fuzzy_symbol_find_query(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::fuzzy_symbol_name_matcher::rank_fuzzy_symbol_matches;
+
+    let pattern = matches.get_one::<String>("pattern").unwrap();
+    let db = &query_database_spec_from_matches(matches)?;
+    let top = *matches.get_one::<usize>("top").unwrap();
+    let near = matches.get_one::<String>("near").map(|near| near.trim_end_matches('/'));
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    entities.retain(|entity| filter.matches_graph_node_entry(&entity.isgl1_key, Some(entity)));
+    let hits = rank_fuzzy_symbol_matches(&entities, pattern, near, top);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "pattern": pattern,
+            "matches": hits,
+        }))?);
+        return Ok(());
+    }
+
+    if hits.is_empty() {
+        println!("No symbol matches '{}'", pattern);
+        return Ok(());
+    }
+    println!("{:>6}  {:<40}  {:<40}  KEY", "SCORE", "SYMBOL", "LOCATION");
+    for hit in &hits {
+        println!(
+            "{:>6.3}  {:<40}  {:<40}  {}",
+            hit.score,
+            hit.qualified_name,
+            format!("{}:{}", hit.file_path, hit.line),
+            hit.entity_key
+        );
+    }
+    Ok(())
+}
+
+async fn run_http_route_lookup_query(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::http_route_handler_mapper::match_http_route_entries;
+
+    let url = matches.get_one::<String>("url").map(String::as_str);
+    let method = matches.get_one::<String>("method").map(String::as_str);
+    let db = &query_database_spec_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let routes = match_http_route_entries(&entities, &edges, url, method);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "url": url,
+            "method": method,
+            "routes": routes,
+        }))?);
+        return Ok(());
+    }
+
+    if routes.is_empty() {
+        match url {
+            Some(url) => {
+                let method = method.map(|m| format!("{} ", m.to_uppercase())).unwrap_or_default();
+                println!("No route serves {}{}", method, url);
+            }
+            None => println!("No routes in the graph (ingest Go or Rust router code first)"),
+        }
+        return Ok(());
+    }
+    for route in &routes {
+        let params: Vec<String> = route.params.iter().map(|(k, v)| format!("{}={}", k, v)).collect();
+        println!(
+            "{} {}  {}",
+            style(format!("{} {}", route.method, route.path)).bold(),
+            style(format!("({}, {})", route.framework, route.location)).dim(),
+            params.join(" ")
+        );
+        for handler in &route.handlers {
+            println!("  {:<8} {}  {}", handler.binding, handler.key, handler.location.as_deref().unwrap_or("-"));
+            // Code only for a URL lookup; a full listing stays one line per handler
+            if let (Some(_), Some(code)) = (url, handler.code.as_deref()) {
+                for line in code.lines() {
+                    println!("    {}", line);
+                }
+            }
+        }
+    }
+    Ok(())
+}
+
+async fn run_wire_field_producer_query(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::struct_tag_serialization_mapper::find_wire_field_producers;
+
+    let name = matches.get_one::<String>("name").unwrap();
+    let format = matches.get_one::<String>("format").map(String::as_str);
+    let db = &query_database_spec_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let edges = storage.get_all_dependencies().await?;
+    let producers = find_wire_field_producers(&edges, name, format);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "name": name,
+            "format": format,
+            "producers": producers,
+        }))?);
+        return Ok(());
+    }
+
+    if producers.is_empty() {
+        let format = format.map(|f| format!(" {}", f)).unwrap_or_default();
+        println!("No struct serializes a{} field named `{}`", format, name);
+        return Ok(());
+    }
+    for producer in &producers {
+        println!(
+            "{:<8} {}.{}  {}",
+            producer.wire_format,
+            style(&producer.struct_key).yellow(),
+            producer.struct_field,
+            style(producer.location.as_deref().unwrap_or("-")).dim()
+        );
+        if let Some(options) = &producer.options {
+            println!("         options: {}", options);
+        }
+    }
+    Ok(())
+}
+
+fn run_binary_graph_file_query(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::serializers::MappedBinaryGraphFile;
+
+    let path = matches.get_one::<String>("file").unwrap();
+    let filter = node_slice_filter_from_matches(matches)?;
+    if matches.get_one::<String>("visibility").is_some() {
+        anyhow::bail!("--visibility needs entity visibility, which a binary graph file does not store");
+    }
+    let file = MappedBinaryGraphFile::open(std::path::Path::new(path))?;
+    let view = file.view()?;
+    let lookup = match (matches.get_one::<String>("callers"), matches.get_one::<String>("callees")) {
+        (Some(key), _) => Some(("callers", key)),
+        (None, Some(key)) => Some(("callees", key)),
+        (None, None) => None,
+    };
+
+    let Some((direction, key)) = lookup else {
+        if matches.get_flag("json") {
+            println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+                "nodes": view.node_count(),
+                "edges": view.edge_count(),
+                "strings": view.string_count(),
+            }))?);
+        } else {
+            println!(
+                "{}: {} nodes, {} edges, {} interned strings",
+                path,
+                view.node_count(),
+                view.edge_count(),
+                view.string_count()
+            );
+        }
+        return Ok(());
+    };
+
+    let node = view
+        .find_node_by_key(key)
+        .ok_or_else(|| anyhow::anyhow!("`{}` is not a node of {}", key, path))?;
+    let edges: Vec<_> = if direction == "callers" {
+        view.incoming_edges(node).collect()
+    } else {
+        view.outgoing_edges(node).collect()
+    };
+    let rows: Vec<(&str, &str, Option<&str>)> = edges
+        .iter()
+        .filter_map(|edge| {
+            let other = view.node_at(if direction == "callers" { edge.from } else { edge.to })?;
+            filter.matches_graph_node_entry(other.key, None).then_some((other.key, edge.edge_type, edge.source_location))
+        })
+        .collect();
+
+    if matches.get_flag("json") {
+        let entries: Vec<serde_json::Value> = rows
+            .iter()
+            .map(|(other, edge_type, location)| serde_json::json!({
+                "entity_key": other,
+                "edge_type": edge_type,
+                "source_location": location,
+            }))
+            .collect();
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "entity_key": key,
+            "direction": direction,
+            "total": entries.len(),
+            "edges": entries,
+        }))?);
+        return Ok(());
+    }
+
+    for (other, edge_type, location) in &rows {
+        println!("  {:<12}  {}  {}", edge_type, other, style(location.unwrap_or("")).dim());
+    }
+    println!("{} {} of {}", rows.len(), direction, key);
+    Ok(())
+}
+
+async fn run_stale_code_query_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::git_blame_ownership_annotator::{
+        entity_age_in_days, LAST_AUTHOR_METADATA_KEY, LAST_COMMIT_METADATA_KEY, OWNER_METADATA_KEY,
+    };
+
+    let db = &query_database_spec_from_matches(matches)?;
+    let days = *matches.get_one::<i64>("days").unwrap();
+    let owner_filter = matches.get_one::<String>("owner");
+    let filter = node_slice_filter_from_matches(matches)?;
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    entities.retain(|entity| filter.matches_graph_node_entry(&entity.isgl1_key, Some(entity)));
+    let now = chrono::Utc::now().timestamp();
+
+    let annotated = entities
+        .iter()
+        .filter(|e| e.metadata.additional.contains_key(OWNER_METADATA_KEY))
+        .count();
+    let mut stale: Vec<(i64, &parseltongue_core::entities::CodeEntity)> = entities
+        .iter()
+        .filter_map(|entity| entity_age_in_days(entity, now).map(|age| (age, entity)))
+        .filter(|(age, _)| *age >= days)
+        .filter(|(_, entity)| {
+            owner_filter.map_or(true, |owner| entity.metadata.additional.get(OWNER_METADATA_KEY) == Some(owner))
+        })
+        .collect();
+    stale.sort_by(|(a_age, a), (b_age, b)| b_age.cmp(a_age).then_with(|| a.isgl1_key.cmp(&b.isgl1_key)));
+
+    let field = |entity: &parseltongue_core::entities::CodeEntity, key: &str| {
+        entity.metadata.additional.get(key).cloned().unwrap_or_default()
+    };
+    if matches.get_flag("json") {
+        let rows: Vec<serde_json::Value> = stale
+            .iter()
+            .map(|(age, entity)| serde_json::json!({
+                "entity_key": entity.isgl1_key,
+                "name": entity.interface_signature.name,
+                "file_path": entity.interface_signature.file_path.display().to_string(),
+                "last_author": field(entity, LAST_AUTHOR_METADATA_KEY),
+                "owner": field(entity, OWNER_METADATA_KEY),
+                "last_commit": field(entity, LAST_COMMIT_METADATA_KEY),
+                "age_days": age,
+            }))
+            .collect();
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "min_age_days": days,
+            "annotated_symbols": annotated,
+            "stale": rows,
+        }))?);
+        return Ok(());
+    }
+
+    if annotated == 0 {
+        println!("No git blame annotations; re-index with `pt01-folder-to-cozodb-streamer --git-blame`");
+        return Ok(());
+    }
+    println!("{:>6}  {:<20}  {:<8}  KEY", "DAYS", "OWNER", "COMMIT");
+    for (age, entity) in &stale {
+        let commit = field(entity, LAST_COMMIT_METADATA_KEY);
+        println!(
+            "{:>6}  {:<20}  {:<8}  {}",
+            age,
+            field(entity, OWNER_METADATA_KEY),
+            &commit[..commit.len().min(8)],
+            entity.isgl1_key
+        );
+    }
+    println!("{} of {} annotated symbols untouched for {}+ days", stale.len(), annotated, days);
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::storage::revision_history_sqlite_store::{
+        RevisionCommitDetailsEntry, RevisionHistorySqliteStore,
+    };
+    use std::path::Path;
+
+    fn query_at_matches(args: &[&str]) -> ArgMatches {
+        Command::new("rank")
+            .arg(Arg::new("db").long("db").required_unless_present("at"))
+            .args(revision_snapshot_at_args())
+            .try_get_matches_from(std::iter::once("rank").chain(args.iter().copied()))
+            .unwrap()
+    }
+
+    fn git_in(directory: &Path, args: &[&str]) -> String {
+        let output = std::process::Command::new("git")
+            .args(["-c", "user.name=test", "-c", "user.email=test@example.com"])
+            .args(args)
+            .current_dir(directory)
+            .output()
+            .unwrap();
+        assert!(output.status.success(), "git {:?}: {}", args, String::from_utf8_lossy(&output.stderr));
+        String::from_utf8_lossy(&output.stdout).trim().to_string()
+    }
+
+    #[test]
+    fn test_query_at_unknown_unrecorded_and_recorded_revisions() {
+        let repo = std::env::temp_dir().join(format!("pt-query-at-{}", std::process::id()));
+        let _ = std::fs::remove_dir_all(&repo);
+        std::fs::create_dir_all(&repo).unwrap();
+        git_in(&repo, &["init", "-q"]);
+        git_in(&repo, &["commit", "-q", "--allow-empty", "-m", "first"]);
+        let head = git_in(&repo, &["rev-parse", "HEAD"]);
+        let history = repo.join("history.sqlite");
+        let location = ["--repo", repo.to_str().unwrap(), "--history", history.to_str().unwrap()];
+        let at = |revision: &str| query_at_matches(&[&["--at", revision][..], &location[..]].concat());
+
+        // Without --at the --db spec is passed through untouched
+        let plain = query_at_matches(&["--db", "rocksdb:parseltongue.db"]);
+        assert_eq!(query_database_spec_from_matches(&plain).unwrap(), "rocksdb:parseltongue.db");
+
+        // A revision git cannot resolve fails before the history is opened
+        let unknown = query_database_spec_from_matches(&at("v9.9.9")).unwrap_err().to_string();
+        assert!(unknown.contains("git rev-parse --verify v9.9.9^{commit} failed"), "{}", unknown);
+        assert!(!history.exists());
+
+        // A real commit that was never recorded: a hint, and no snapshot left behind
+        let unrecorded = query_database_spec_from_matches(&at("HEAD")).unwrap_err().to_string();
+        assert!(unrecorded.starts_with(&format!("HEAD ({}) is not recorded in", &head[..8])), "{}", unrecorded);
+        assert!(unrecorded.ends_with("run `parseltongue history record HEAD`"), "{}", unrecorded);
+        let snapshot = history.with_extension("snapshots").join(format!("{}.json.zst", head));
+        assert!(!snapshot.exists());
+        assert!(!snapshot.with_extension("partial").exists());
+
+        // Once recorded it is extracted, then reused without the history file
+        let store = RevisionHistorySqliteStore::open_with_history_schema(&history).unwrap();
+        let commit = RevisionCommitDetailsEntry {
+            commit_sha: head.clone(),
+            tags: Vec::new(),
+            committed_at: "2026-01-01T00:00:00Z".to_string(),
+            subject: "first".to_string(),
+        };
+        store.record_revision_snapshot_atomically(&commit, &[], &[], None).unwrap();
+        drop(store);
+        let expected = format!("snapshot:{}", snapshot.display());
+        assert_eq!(query_database_spec_from_matches(&at("HEAD")).unwrap(), expected);
+        assert!(snapshot.is_file());
+        std::fs::remove_file(&history).unwrap();
+        assert_eq!(query_database_spec_from_matches(&at(&head)).unwrap(), expected);
+
+        std::fs::remove_dir_all(&repo).unwrap();
+    }
+}
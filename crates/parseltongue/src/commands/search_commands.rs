@@ -0,0 +1,616 @@
+//! Search CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: search_subcommand_definition_list
+//!
+//! Backs `embed`, `summarize` and `search`: symbol embeddings, summaries and
+//! semantic or hybrid keyword lookup.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use crate::commands::shared_cli_arg_helpers::{load_working_directory_config, open_storage_resolve_symbol};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: search_subcommand_definition_list
+pub(crate) fn search_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("embed")
+            .about("Compute an embedding per symbol (name + signature + doc) and store it in the database")
+            .long_about(
+                "Sends each symbol's name, signature and doc comment to an OpenAI-compatible\n\
+                embeddings endpoint (Ollama, vLLM, text-embeddings-inference, hosted https APIs)\n\
+                and stores the vectors with the model name. Endpoint, model and API key\n\
+                variable default to [embed] in .parseltongue.toml. Without an endpoint the\n\
+                offline hashed-words-v1 fallback is used: no network, but lexical, so only\n\
+                symbols sharing words with a query match. Only symbols whose embedded text\n\
+                changed since the last run with the same model are re-embedded.\n\n\
+                Examples:\n  \
+                parseltongue embed --db rocksdb:analysis.db --endpoint http://localhost:11434 --model all-minilm\n  \
+                parseltongue embed --db rocksdb:analysis.db --offline --force"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("force")
+                    .long("force")
+                    .help("Re-embed every symbol")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .args(embedding_provider_args()),
+        Command::new("summarize")
+            .about("Summarize each symbol with an LLM endpoint, cached on disk, for budget-tight context packs")
+            .long_about(
+                "Sends every symbol's code to an OpenAI-compatible chat completions endpoint\n\
+                (Ollama, vLLM, llama.cpp, or hosted https APIs) and stores a\n\
+                one-paragraph summary in the database. pack-context uses it where a body does\n\
+                not fit. Answers are cached by symbol hash under the cache directory, so an\n\
+                interrupted or --limit run resumes and unchanged symbols are never re-sent.\n\
+                Endpoint, model, API key variable and rate default to [summarize] in\n\
+                .parseltongue.toml.\n\n\
+                Examples:\n  \
+                parseltongue summarize --db rocksdb:analysis.db --endpoint http://localhost:11434/v1/chat/completions --model llama3.1\n  \
+                parseltongue summarize --db rocksdb:analysis.db --limit 200 --rpm 20   # most central symbols first"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("endpoint")
+                    .long("endpoint")
+                    .value_name("URL")
+                    .help("Chat completions URL (http://host:port/v1/chat/completions)"),
+            )
+            .arg(
+                Arg::new("model")
+                    .long("model")
+                    .help("Model name sent with every request"),
+            )
+            .arg(
+                Arg::new("rpm")
+                    .long("rpm")
+                    .value_name("N")
+                    .help("Requests per minute (default: [summarize] requests_per_minute, else 60)")
+                    .value_parser(clap::value_parser!(u32).range(1..)),
+            )
+            .arg(
+                Arg::new("limit")
+                    .long("limit")
+                    .value_name("N")
+                    .help("Send at most N requests this run; cached summaries are still applied")
+                    .value_parser(clap::value_parser!(usize)),
+            )
+            .arg(
+                Arg::new("include-tests")
+                    .long("include-tests")
+                    .help("Also summarize test functions")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("cache-dir")
+                    .long("cache-dir")
+                    .value_name("DIR")
+                    .help("Cache directory (default: $PARSELTONGUE_CACHE_DIR, else ~/.cache/parseltongue-companion)"),
+            )
+            .arg(
+                Arg::new("force")
+                    .long("force")
+                    .help("Ignore cached and stored summaries and ask again")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("search")
+            .about("Semantic symbol search: nearest embeddings, re-ranked by graph proximity and centrality")
+            .long_about(
+                "Semantic mode requires `parseltongue embed` to have run on the database with\n\
+                the same model: the query is embedded by the same endpoint (or the offline\n\
+                hashed-words-v1 fallback, which matches words, not meaning). Keyword mode\n\
+                needs nothing extra: BM25 over identifiers, doc comments and string literals,\n\
+                optionally blended with graph distance from --near.\n\n\
+                Examples:\n  \
+                parseltongue search \"how do we hash passwords\" --db rocksdb:analysis.db\n  \
+                parseltongue search \"retry with backoff\" --db rocksdb:analysis.db --limit 5 --json\n  \
+                parseltongue search \"rate limiting\" --mode keyword --near HandleLogin --db rocksdb:analysis.db"
+            )
+            .arg(
+                Arg::new("query")
+                    .help("Natural-language description of the code you are looking for")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("limit")
+                    .long("limit")
+                    .help("Number of results")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("10"),
+            )
+            .arg(
+                Arg::new("mode")
+                    .long("mode")
+                    .help("semantic (stored embeddings) or keyword (BM25, no embeddings needed)")
+                    .value_parser(["semantic", "keyword"])
+                    .default_value("semantic"),
+            )
+            .arg(
+                Arg::new("near")
+                    .long("near")
+                    .help("Keyword mode: prefer matches close to this symbol in the graph"),
+            )
+            .arg(
+                Arg::new("proximity-weight")
+                    .long("proximity-weight")
+                    .help("Keyword mode: share of the score from graph distance to --near (0-1)")
+                    .value_parser(clap::value_parser!(f64))
+                    .default_value("0.4"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .args(embedding_provider_args()),
+    ]
+}
+
+/// `--endpoint` / `--model` / `--offline` of `embed` and `search` (v1.7.3)
+///
+/// # 4-Word Name: embedding_provider_args
+fn embedding_provider_args() -> [Arg; 3] {
+    [
+        Arg::new("endpoint")
+            .long("endpoint")
+            .value_name("URL")
+            .help("OpenAI-compatible embeddings URL (default: [embed] endpoint)"),
+        Arg::new("model")
+            .long("model")
+            .help("Embedding model name, stored with every vector (default: [embed] model)"),
+        Arg::new("offline")
+            .long("offline")
+            .help("Use the offline hashed-words-v1 fallback: lexical word matching, not semantic")
+            .action(clap::ArgAction::SetTrue),
+    ]
+}
+
+/// Embedding provider from the `embedding_provider_args` flags or `[embed]`
+///
+/// # 4-Word Name: resolve_embedding_provider_spec
+///
+/// # Contract
+/// - Postcondition: the offline hashed model for `--offline`, or (with a
+///   note on stderr) when no endpoint is configured
+/// - Error: an invalid endpoint URL, or an endpoint without a model
+fn resolve_embedding_provider_spec(
+    matches: &ArgMatches,
+) -> Result<parseltongue_core::semantic_symbol_embedding_index::SymbolEmbeddingProviderSpec> {
+    use parseltongue_core::semantic_symbol_embedding_index::{
+        parse_embedding_endpoint_url, EmbeddingEndpointSpec, SymbolEmbeddingProviderSpec, HASHED_EMBEDDING_MODEL_NAME,
+    };
+
+    if matches.get_flag("offline") {
+        return Ok(SymbolEmbeddingProviderSpec::HashedWordsFallback);
+    }
+    let section = load_working_directory_config()?.embed;
+    let Some(url) = matches.get_one::<String>("endpoint").cloned().or(section.endpoint) else {
+        eprintln!(
+            "{} No embedding endpoint (--endpoint or [embed] endpoint): using the offline {} fallback, \
+            which matches words, not meaning",
+            style("⚠").yellow(),
+            HASHED_EMBEDDING_MODEL_NAME
+        );
+        return Ok(SymbolEmbeddingProviderSpec::HashedWordsFallback);
+    };
+    parse_embedding_endpoint_url(&url).map_err(anyhow::Error::msg)?;
+    let model = matches
+        .get_one::<String>("model")
+        .cloned()
+        .or(section.model)
+        .ok_or_else(|| anyhow::anyhow!("No embedding model: pass --model or set [embed] model"))?;
+    Ok(SymbolEmbeddingProviderSpec::Endpoint(EmbeddingEndpointSpec {
+        url,
+        model,
+        api_key: section
+            .api_key_env
+            .as_deref()
+            .and_then(|name| std::env::var(name).ok())
+            .filter(|key| !key.is_empty()),
+    }))
+}
+
+/// Embed every symbol whose input text changed (v1.7.3)
+///
+/// # 4-Word Name: run_symbol_embedding_command
+pub(crate) async fn run_symbol_embedding_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::isgl1_v2::compute_content_hash;
+    use parseltongue_core::semantic_symbol_embedding_index::{build_embedding_input_text, SymbolEmbeddingRecord};
+    use parseltongue_core::serializers::graph_export_node_table::is_external_graph_node_key;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let force = matches.get_flag("force");
+    let provider = resolve_embedding_provider_spec(matches)?;
+    let model = provider.embedding_model_name_value().to_string();
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    if !storage.list_relations().await?.iter().any(|r| r == "SymbolEmbedding") {
+        storage.create_symbol_embedding_schema().await?;
+    }
+    let stored = storage.get_all_symbol_embeddings().await?;
+    // Vectors of another model are replaced, never compared
+    let existing: std::collections::HashMap<&str, &str> = stored
+        .iter()
+        .filter(|r| r.model == model)
+        .map(|r| (r.isgl1_key.as_str(), r.input_hash.as_str()))
+        .collect();
+
+    let entities = storage.get_all_entities().await?;
+    let started = std::time::Instant::now();
+    let symbols: Vec<_> = entities.iter().filter(|e| !is_external_graph_node_key(&e.isgl1_key)).collect();
+    let pending: Vec<(String, String, String)> = symbols
+        .iter()
+        .map(|e| {
+            let text = build_embedding_input_text(e);
+            let hash = compute_content_hash(&text);
+            (e.isgl1_key.clone(), text, hash)
+        })
+        .filter(|(key, _, hash)| force || existing.get(key.as_str()) != Some(&hash.as_str()))
+        .collect();
+
+    // Stored as we go, so an interrupted run keeps what the endpoint answered
+    for chunk in pending.chunks(500) {
+        let texts: Vec<String> = chunk.iter().map(|(_, text, _)| text.clone()).collect();
+        let batch_provider = provider.clone();
+        let vectors = tokio::task::spawn_blocking(move || batch_provider.embed_texts_with_provider(&texts))
+            .await?
+            .map_err(anyhow::Error::msg)?;
+        let records: Vec<SymbolEmbeddingRecord> = chunk
+            .iter()
+            .zip(vectors)
+            .map(|((key, _, hash), vector)| SymbolEmbeddingRecord {
+                isgl1_key: key.clone(),
+                model: model.clone(),
+                input_hash: hash.clone(),
+                vector,
+            })
+            .collect();
+        storage.upsert_symbol_embeddings_batch(&records).await?;
+    }
+    // v1.7.3: embeddings of symbols no longer in the graph
+    let live: std::collections::HashSet<&str> = symbols.iter().map(|e| e.isgl1_key.as_str()).collect();
+    let orphans: Vec<String> =
+        stored.iter().filter(|r| !live.contains(r.isgl1_key.as_str())).map(|r| r.isgl1_key.clone()).collect();
+    storage.delete_symbol_embeddings_by_keys(&orphans).await?;
+    println!(
+        "{} {} symbol(s) embedded, {} unchanged ({}, {:?})",
+        style("✓").green(),
+        pending.len(),
+        symbols.len() - pending.len(),
+        model,
+        started.elapsed()
+    );
+    Ok(())
+}
+
+/// Summarize symbols through an LLM endpoint, most central first (v1.7.3)
+///
+/// # 4-Word Name: run_symbol_summarize_command
+pub(crate) async fn run_symbol_summarize_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::entities::EntityClass;
+    use parseltongue_core::llm_symbol_summary_cache::{
+        build_symbol_summary_prompt, compute_summary_cache_key, parse_llm_endpoint_url,
+        request_symbol_summary_blocking, CachedSymbolSummaryRecord, LlmSummaryEndpointSpec,
+        SummaryRequestPacerState, SymbolSummaryCacheStore, DEFAULT_SUMMARY_REQUESTS_PER_MINUTE,
+        SUMMARY_HASH_METADATA_KEY, SUMMARY_METADATA_KEY, SUMMARY_MODEL_METADATA_KEY,
+    };
+    use parseltongue_core::serializers::graph_export_node_table::is_external_graph_node_key;
+    use parseltongue_core::symbol_centrality_score_ranker::load_symbol_importance_lookup_map;
+    use pt01_folder_to_cozodb_streamer::persistent_parse_artifact_cache::default_parse_cache_directory;
+
+    // A dead or misconfigured endpoint should not be hit once per symbol
+    const MAX_CONSECUTIVE_SUMMARY_FAILURES: usize = 3;
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let section = load_working_directory_config()?.summarize;
+    let url = matches
+        .get_one::<String>("endpoint")
+        .cloned()
+        .or(section.endpoint)
+        .ok_or_else(|| anyhow::anyhow!("No LLM endpoint: pass --endpoint or set [summarize] endpoint"))?;
+    parse_llm_endpoint_url(&url).map_err(anyhow::Error::msg)?;
+    let model = matches
+        .get_one::<String>("model")
+        .cloned()
+        .or(section.model)
+        .ok_or_else(|| anyhow::anyhow!("No model: pass --model or set [summarize] model"))?;
+    let endpoint = LlmSummaryEndpointSpec {
+        url,
+        model,
+        api_key: section
+            .api_key_env
+            .as_deref()
+            .and_then(|name| std::env::var(name).ok())
+            .filter(|key| !key.is_empty()),
+        requests_per_minute: matches
+            .get_one::<u32>("rpm")
+            .copied()
+            .or(section.requests_per_minute)
+            .unwrap_or(DEFAULT_SUMMARY_REQUESTS_PER_MINUTE),
+    };
+    let limit = matches.get_one::<usize>("limit").copied();
+    let force = matches.get_flag("force");
+    let include_tests = matches.get_flag("include-tests");
+    let cache_dir = match matches.get_one::<String>("cache-dir") {
+        Some(dir) => std::path::PathBuf::from(dir),
+        None => default_parse_cache_directory()
+            .ok_or_else(|| anyhow::anyhow!("No HOME or XDG_CACHE_HOME; pass --cache-dir"))?,
+    };
+    let cache = SymbolSummaryCacheStore::open_summary_cache_store(cache_dir);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    if !storage.list_relations().await?.iter().any(|r| r == "EntityExtractorMetadata") {
+        storage.create_entity_extractor_metadata_schema().await?;
+    }
+    let importance = load_symbol_importance_lookup_map(&storage).await?;
+    let mut symbols: Vec<_> = storage
+        .get_all_entities_with_metadata()
+        .await?
+        .into_iter()
+        .filter(|e| !is_external_graph_node_key(&e.isgl1_key))
+        .filter(|e| include_tests || e.entity_class != EntityClass::TestImplementation)
+        .filter_map(|e| {
+            let prompt = build_symbol_summary_prompt(&e)?;
+            let hash = compute_summary_cache_key(&endpoint.model, &prompt);
+            Some((e, prompt, hash))
+        })
+        .collect();
+    // Most central first, so a --limit run covers the symbols packs use most
+    let importance_of = |key: &str| importance.get(key).copied().unwrap_or(0.0);
+    symbols.sort_by(|(a, _, _), (b, _, _)| {
+        importance_of(&b.isgl1_key)
+            .partial_cmp(&importance_of(&a.isgl1_key))
+            .unwrap_or(std::cmp::Ordering::Equal)
+            .then_with(|| a.isgl1_key.cmp(&b.isgl1_key))
+    });
+
+    let started = std::time::Instant::now();
+    let mut pacer = SummaryRequestPacerState::for_requests_per_minute(endpoint.requests_per_minute);
+    let (mut unchanged, mut from_cache, mut requested, mut failed, mut deferred) = (0, 0, 0, 0, 0);
+    let mut consecutive_failures = 0;
+    let mut pending = Vec::new();
+    for (mut entity, prompt, hash) in symbols {
+        let additional = &entity.metadata.additional;
+        if !force && additional.contains_key(SUMMARY_METADATA_KEY) && additional.get(SUMMARY_HASH_METADATA_KEY) == Some(&hash) {
+            unchanged += 1;
+            continue;
+        }
+        let summary = match cache.load_cached_symbol_summary(&hash).filter(|_| !force) {
+            Some(record) => {
+                from_cache += 1;
+                record.summary
+            }
+            None => {
+                let over_limit = limit.is_some_and(|limit| requested + failed >= limit);
+                if over_limit || consecutive_failures >= MAX_CONSECUTIVE_SUMMARY_FAILURES {
+                    deferred += 1;
+                    continue;
+                }
+                tokio::time::sleep(pacer.reserve_next_request_slot()).await;
+                let request_endpoint = endpoint.clone();
+                let answer = tokio::task::spawn_blocking(move || request_symbol_summary_blocking(&request_endpoint, &prompt)).await?;
+                match answer {
+                    Ok(summary) => {
+                        requested += 1;
+                        consecutive_failures = 0;
+                        let record = CachedSymbolSummaryRecord {
+                            model: endpoint.model.clone(),
+                            isgl1_key: entity.isgl1_key.clone(),
+                            summary: summary.clone(),
+                        };
+                        if let Err(e) = cache.store_symbol_summary_record(&hash, &record) {
+                            eprintln!("Warning: could not cache summary of {}: {}", entity.isgl1_key, e);
+                        }
+                        summary
+                    }
+                    Err(reason) => {
+                        failed += 1;
+                        consecutive_failures += 1;
+                        eprintln!("{} {}: {}", style("⚠").yellow(), entity.isgl1_key, reason);
+                        if consecutive_failures == MAX_CONSECUTIVE_SUMMARY_FAILURES {
+                            eprintln!("  {} failures in a row: no more requests this run", MAX_CONSECUTIVE_SUMMARY_FAILURES);
+                        }
+                        continue;
+                    }
+                }
+            }
+        };
+        entity.metadata.additional.insert(SUMMARY_METADATA_KEY.to_string(), summary);
+        entity.metadata.additional.insert(SUMMARY_HASH_METADATA_KEY.to_string(), hash);
+        entity.metadata.additional.insert(SUMMARY_MODEL_METADATA_KEY.to_string(), endpoint.model.clone());
+        pending.push(entity);
+        // Stored as we go, so an interrupted run keeps its summaries
+        if pending.len() >= 50 {
+            storage.upsert_entity_extractor_metadata_batch(&pending).await?;
+            pending.clear();
+        }
+    }
+    storage.upsert_entity_extractor_metadata_batch(&pending).await?;
+
+    println!(
+        "{} {} symbol(s) summarized, {} from cache, {} unchanged ({}, {:?})",
+        style("✓").green(),
+        requested,
+        from_cache,
+        unchanged,
+        endpoint.model,
+        started.elapsed()
+    );
+    if failed > 0 {
+        println!("  {} request(s) failed", failed);
+    }
+    if deferred > 0 {
+        println!("  {} symbol(s) left; run again to resume", deferred);
+    }
+    Ok(())
+}
+
+/// BM25 keyword search, blended with graph distance from `--near` (v1.7.3)
+///
+/// # 4-Word Name: run_hybrid_keyword_search
+async fn run_hybrid_keyword_search(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::bm25_hybrid_keyword_retriever::{
+        search_keywords_near_seed, HybridRetrievalOptionsSpec, KeywordSearchIndexStore,
+    };
+
+    let query = matches.get_one::<String>("query").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let (storage, seed_key) = match matches.get_one::<String>("near") {
+        Some(near) => {
+            let (storage, key) = open_storage_resolve_symbol(db, near).await?;
+            (storage, Some(key))
+        }
+        None => (parseltongue_core::storage::CozoDbStorage::new(db).await?, None),
+    };
+    let entities = storage.get_all_entities().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let index = KeywordSearchIndexStore::build_from_code_entities(&entities);
+    let options = HybridRetrievalOptionsSpec {
+        seed_key,
+        proximity_weight: *matches.get_one::<f64>("proximity-weight").unwrap(),
+        limit: *matches.get_one::<usize>("limit").unwrap(),
+        ..HybridRetrievalOptionsSpec::default()
+    };
+    let hits = search_keywords_near_seed(&index, query, &edges, &options);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "query": query,
+            "mode": "keyword",
+            "near": options.seed_key,
+            "indexed_symbols": index.indexed_document_count_value(),
+            "results": hits,
+        }))?);
+        return Ok(());
+    }
+
+    if hits.is_empty() {
+        println!("No symbols match '{}'", query);
+        return Ok(());
+    }
+    let locations: std::collections::HashMap<&str, String> = entities
+        .iter()
+        .map(|e| {
+            let location =
+                format!("  {}:{}", e.interface_signature.file_path.display(), e.interface_signature.line_range.start);
+            (e.isgl1_key.as_str(), location)
+        })
+        .collect();
+    for hit in &hits {
+        let hops = hit.graph_distance.map(|d| format!(" ({} hops)", d)).unwrap_or_default();
+        let location = locations.get(hit.entity_key.as_str()).cloned().unwrap_or_default();
+        println!("{:.3}  {}{}{}", hit.score, hit.entity_key, style(hops).cyan(), style(location).dim());
+    }
+    Ok(())
+}
+
+/// Nearest-neighbor symbol search over stored embeddings (v1.7.3)
+///
+/// # 4-Word Name: run_semantic_search_command
+pub(crate) async fn run_semantic_search_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::semantic_symbol_embedding_index::search_symbols_by_embedding;
+
+    let query = matches.get_one::<String>("query").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let limit = *matches.get_one::<usize>("limit").unwrap();
+    if matches.get_one::<String>("mode").map(String::as_str) == Some("keyword") {
+        return run_hybrid_keyword_search(matches).await;
+    }
+    if matches.get_one::<String>("near").is_some() {
+        anyhow::bail!("--near applies to --mode keyword");
+    }
+
+    let provider = resolve_embedding_provider_spec(matches)?;
+    let model = provider.embedding_model_name_value().to_string();
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let records = storage.get_all_symbol_embeddings().await?;
+    if records.is_empty() {
+        anyhow::bail!("No embeddings in {}; run `parseltongue embed --db {}` first", db, db);
+    }
+    if !records.iter().any(|r| r.model == model) {
+        let stored: std::collections::BTreeSet<&str> = records.iter().map(|r| r.model.as_str()).collect();
+        anyhow::bail!(
+            "No {} embeddings in {} (stored: {}); search with the model `embed` used, or re-run `embed`",
+            model,
+            db,
+            stored.into_iter().collect::<Vec<_>>().join(", ")
+        );
+    }
+    let query_provider = provider.clone();
+    let query_text = query.clone();
+    let query_vector = tokio::task::spawn_blocking(move || query_provider.embed_texts_with_provider(&[query_text]))
+        .await?
+        .map_err(anyhow::Error::msg)?
+        .remove(0);
+    let edges = storage.get_all_dependencies().await?;
+    let importance =
+        parseltongue_core::symbol_centrality_score_ranker::load_symbol_importance_lookup_map(&storage).await?;
+    let hits = search_symbols_by_embedding(
+        &query_vector,
+        &model,
+        &records,
+        &edges,
+        &importance,
+        limit,
+    );
+
+    if matches.get_flag("json") {
+        let mut results = Vec::new();
+        for hit in &hits {
+            let entity = storage.get_entity(&hit.isgl1_key).await.ok();
+            results.push(serde_json::json!({
+                "key": hit.isgl1_key,
+                "score": hit.score,
+                "similarity": hit.similarity,
+                "graph_boost": hit.graph_boost,
+                "centrality_boost": hit.centrality_boost,
+                "file_path": entity.as_ref().map(|e| e.interface_signature.file_path.to_string_lossy().to_string()),
+                "line": entity.as_ref().map(|e| e.interface_signature.line_range.start),
+            }));
+        }
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "query": query,
+            "model": model,
+            "results": results,
+        }))?);
+        return Ok(());
+    }
+
+    if hits.is_empty() {
+        println!("No symbols match '{}'", query);
+        return Ok(());
+    }
+    for hit in &hits {
+        let location = match storage.get_entity(&hit.isgl1_key).await {
+            Ok(entity) => format!(
+                "  {}:{}",
+                entity.interface_signature.file_path.display(),
+                entity.interface_signature.line_range.start
+            ),
+            Err(_) => String::new(),
+        };
+        println!("{:.3}  {}{}", hit.score, hit.isgl1_key, style(location).dim());
+    }
+    Ok(())
+}
@@ -0,0 +1,237 @@
+//! Server CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: server_subcommand_definition_list
+//!
+//! Backs the long-running front ends over one graph: `pt08-http-code-query-server`,
+//! `daemon`, `pt09-mcp-stdio-tool-server` and `lsp`.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use pt08_http_code_query_server::{graph_change_webhook_notifier, HttpServerStartupConfig, http_server_startup_runner};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: server_subcommand_definition_list
+pub(crate) fn server_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("pt08-http-code-query-server")
+            .about("Tool 8: HTTP server for code queries (REST API)")
+            .long_about(
+                "Start an HTTP server exposing CozoDB queries via REST endpoints.\n\n\
+                File watching is always enabled - code changes are automatically reindexed.\n\n\
+                Examples:\n  \
+                parseltongue pt08-http-code-query-server --db rocksdb:analysis.db\n  \
+                parseltongue pt08-http-code-query-server --port 7777 --db rocksdb:analysis.db"
+            )
+            .arg(
+                Arg::new("port")
+                    .long("port")
+                    .short('p')
+                    .help("Port to listen on [default: 7777]"),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or mem)")
+                    .default_value("mem"),
+            )
+            .arg(
+                Arg::new("verbose")
+                    .long("verbose")
+                    .short('v')
+                    .help("Enable verbose logging")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("daemon")
+            .about("Watch a workspace and serve a hot in-memory graph over HTTP")
+            .long_about(
+                "Ingests the workspace into an in-memory CozoDB, then re-parses files as they\n\
+                change (filesystem events). Queries hit the live graph - no separate pt01 run.\n\n\
+                Examples:\n  \
+                parseltongue daemon .\n  \
+                parseltongue daemon ./my-project --port 8080\n  \
+                parseltongue daemon . --webhook http://localhost:9000/graph-events\n  \
+                OTEL_EXPORTER_OTLP_ENDPOINT=http://collector:4318 parseltongue daemon .   # query spans; GET /metrics for Prometheus\n  \
+                parseltongue daemon . --stdio   # JSON-RPC 2.0 on stdin/stdout for editor extensions"
+            )
+            .arg(
+                Arg::new("directory")
+                    .help("Workspace directory to watch [default: current directory]")
+                    .default_value(".")
+                    .index(1),
+            )
+            .arg(
+                Arg::new("port")
+                    .long("port")
+                    .short('p')
+                    .help("Port to listen on [default: 7777]"),
+            )
+            .arg(
+                Arg::new("stdio")
+                    .long("stdio")
+                    .help("Serve the daemon API as JSON-RPC 2.0 on stdin/stdout instead of a port")
+                    .conflicts_with("port")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("webhook")
+                    .long("webhook")
+                    .value_name("URL")
+                    .help("POST graph change events (new/removed public symbols, new cross-package edges) to this http:// URL"),
+            )
+            .arg(
+                Arg::new("verbose")
+                    .long("verbose")
+                    .short('v')
+                    .help("Enable verbose logging")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("pt09-mcp-stdio-tool-server")
+            .about("Tool 9: MCP (Model Context Protocol) server over stdio")
+            .long_about(
+                "Serves the graph as MCP tools (get_callers, get_blast_radius, get_context_pack, ...)\n\
+                over stdin/stdout. Register it as a stdio MCP server in your LLM client.\n\n\
+                Examples:\n  \
+                parseltongue pt09-mcp-stdio-tool-server --db rocksdb:parseltongue20260101/analysis.db"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            ),
+        Command::new("lsp")
+            .about("Read-only LSP server over the graph (definition, references, call hierarchy, workspace symbols)")
+            .long_about(
+                "Speaks the Language Server Protocol on stdin/stdout, answering from the same graph\n\
+                the LLM endpoints read, so cross-language navigation in the editor matches what the\n\
+                model sees. Configure it as the server command of a generic LSP client.\n\n\
+                Examples:\n  \
+                parseltongue lsp --db rocksdb:parseltongue20260101/analysis.db\n  \
+                parseltongue lsp --db rocksdb:analysis.db --root ./my-project"
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("root")
+                    .long("root")
+                    .default_value(".")
+                    .help("Directory the graph was ingested from; the client's rootUri overrides it"),
+            ),
+    ]
+}
+
+/// Run the HTTP server for code queries
+///
+/// # 4-Word Name: run_http_code_query_server
+pub(crate) async fn run_http_code_query_server(matches: &ArgMatches) -> Result<()> {
+    let port = matches.get_one::<String>("port");
+    let db = matches.get_one::<String>("db").unwrap();
+    let verbose = matches.get_flag("verbose");
+
+    println!("{}", style("Running Tool 8: HTTP Code Query Server").cyan());
+    if verbose {
+        if let Some(p) = port {
+            println!("  Port: {}", p);
+        } else {
+            println!("  Port: 7777 (default)");
+        }
+        println!("  Database: {}", db);
+        println!("  File watching: always enabled (v1.4.2+)");
+    }
+
+    // Build configuration
+    let config = HttpServerStartupConfig {
+        target_directory_path_value: std::path::PathBuf::from("."),
+        database_connection_string_value: db.clone(),
+        http_port_override_option: port.and_then(|p| p.parse().ok()),
+        force_reindex_enabled_flag: false,
+        daemon_background_mode_flag: false,
+        idle_timeout_minutes_option: None,
+        verbose_logging_enabled_flag: verbose,
+        graph_change_webhook_url_option: None,
+        stdio_jsonrpc_mode_flag: false,
+    };
+
+    // Start the server (blocks until shutdown)
+    http_server_startup_runner::start_http_server_blocking_loop(config).await
+}
+
+pub(crate) async fn run_mcp_stdio_tool_server(matches: &ArgMatches) -> Result<()> {
+    let db = matches.get_one::<String>("db").unwrap();
+
+    // stdout is the protocol channel: diagnostics go to stderr only
+    eprintln!("Running Tool 9: MCP stdio tool server");
+    eprintln!("  Database: {}", db);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let state = http_server_startup_runner::SharedApplicationStateContainer::create_with_database_storage(storage);
+    {
+        let mut stats = state.codebase_statistics_metadata_arc.write().await;
+        stats.database_file_path_string = db.clone();
+    }
+    state.populate_languages_from_database().await;
+
+    pt08_http_code_query_server::run_mcp_stdio_server_loop(state).await
+}
+
+pub(crate) async fn run_lsp_graph_navigation_server(matches: &ArgMatches) -> Result<()> {
+    use pt08_http_code_query_server::lsp_graph_navigation_server::{
+        run_lsp_stdio_server_loop, LspGraphNavigationIndex, LspNavigationSessionState,
+    };
+
+    let db = matches.get_one::<String>("db").unwrap();
+    let root = matches.get_one::<String>("root").unwrap();
+    let root = std::fs::canonicalize(root).unwrap_or_else(|_| std::path::PathBuf::from(root));
+
+    // stdout is the protocol channel: diagnostics go to stderr only
+    eprintln!("Running LSP graph navigation server");
+    eprintln!("  Database: {}", db);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities().await?;
+    let edges = storage.get_all_dependencies().await?;
+    let index = LspGraphNavigationIndex::build_lsp_navigation_index(&root, &entities, &edges);
+
+    run_lsp_stdio_server_loop(LspNavigationSessionState::create_lsp_navigation_session(index, root)).await
+}
+
+pub(crate) async fn run_workspace_watch_daemon(matches: &ArgMatches) -> Result<()> {
+    let directory = matches.get_one::<String>("directory").unwrap();
+    let port = matches.get_one::<String>("port");
+    let verbose = matches.get_flag("verbose");
+    let webhook = matches.get_one::<String>("webhook").cloned();
+    if let Some(url) = &webhook {
+        graph_change_webhook_notifier::parse_http_webhook_url(url).map_err(anyhow::Error::msg)?;
+    }
+    let stdio = matches.get_flag("stdio");
+
+    // stdout is the protocol channel under --stdio: diagnostics go to stderr
+    let say = |line: String| if stdio { eprintln!("{}", line) } else { println!("{}", line) };
+    say(style("Running daemon: live workspace graph").cyan().to_string());
+    if verbose {
+        say(format!("  Workspace: {}", directory));
+        say("  Database: mem (hot, rebuilt on startup)".to_string());
+    }
+
+    let config = HttpServerStartupConfig {
+        target_directory_path_value: std::path::PathBuf::from(directory),
+        database_connection_string_value: "mem".to_string(),
+        http_port_override_option: port.and_then(|p| p.parse().ok()),
+        force_reindex_enabled_flag: false,
+        daemon_background_mode_flag: true,
+        idle_timeout_minutes_option: None,
+        verbose_logging_enabled_flag: verbose,
+        graph_change_webhook_url_option: webhook,
+        stdio_jsonrpc_mode_flag: stdio,
+    };
+
+    // Blocks until shutdown; file watcher keeps the graph current
+    http_server_startup_runner::start_http_server_blocking_loop(config).await
+}
@@ -0,0 +1,166 @@
+//! Shared CLI argument helpers (v1.7.3)
+//!
+//! # 4-Word Naming: flag_or_config_value
+//!
+//! Arguments and lookups several commands share: project config defaults,
+//! node slice filters, confidence and traversal limits, symbol resolution.
+
+use clap::parser::ValueSource;
+use clap::{Arg, ArgMatches};
+use anyhow::Result;
+
+use parseltongue::config::{load_nearest_project_config, ProjectConfigFileSpec};
+
+/// Flag value, or the project config value when the flag was not given (v1.7.3)
+///
+/// # 4-Word Name: flag_or_config_value
+///
+/// # Contract
+/// - Precondition: `id` has a default value, so `get_one` always succeeds
+/// - Postcondition: command line > config file > clap default
+pub(crate) fn flag_or_config_value<T: Clone + Send + Sync + 'static>(
+    matches: &ArgMatches,
+    id: &str,
+    configured: Option<T>,
+) -> T {
+    match configured {
+        Some(value) if matches.value_source(id) != Some(ValueSource::CommandLine) => value,
+        _ => matches.get_one::<T>(id).unwrap().clone(),
+    }
+}
+
+/// Project config nearest to the working directory, or built-in defaults (v1.7.3)
+///
+/// # 4-Word Name: load_working_directory_config
+pub(crate) fn load_working_directory_config() -> Result<ProjectConfigFileSpec> {
+    Ok(load_nearest_project_config(std::path::Path::new("."))?
+        .map(|(_, spec)| spec)
+        .unwrap_or_default())
+}
+
+/// Open a database and resolve a symbol to exactly one entity key
+///
+/// # 4-Word Name: open_storage_resolve_symbol
+///
+/// Ambiguous names list the candidates on stderr and fail.
+pub(crate) async fn open_storage_resolve_symbol(
+    db: &str,
+    symbol: &str,
+) -> Result<(parseltongue_core::storage::CozoDbStorage, String)> {
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut keys = storage.find_entity_keys_by_symbol(symbol).await?;
+    match keys.len() {
+        0 => {
+            let entities = storage.get_all_entities_with_metadata().await?;
+            let suggestions =
+                parseltongue_core::fuzzy_symbol_name_matcher::rank_fuzzy_symbol_matches(&entities, symbol, None, 5);
+            if !suggestions.is_empty() {
+                eprintln!("Closest matches (see `parseltongue query find`):");
+                for hit in &suggestions {
+                    eprintln!("  {}  {}", hit.qualified_name, hit.entity_key);
+                }
+            }
+            anyhow::bail!("No entity matches '{}'", symbol)
+        }
+        1 => Ok((storage, keys.remove(0))),
+        n => {
+            eprintln!("'{}' matches {} entities; pass one ISGL1 key:", symbol, n);
+            for key in keys.iter().take(20) {
+                eprintln!("  {}", key);
+            }
+            anyhow::bail!("Ambiguous symbol '{}'", symbol)
+        }
+    }
+}
+
+/// `--match` / `--kind` / `--visibility` node filters of exports and queries (v1.7.3)
+///
+/// # 4-Word Name: node_slice_filter_args
+pub(crate) fn node_slice_filter_args() -> [Arg; 3] {
+    [
+        Arg::new("match")
+            .long("match")
+            .value_name("REGEX")
+            .help("Keep only nodes whose name or ISGL1 key matches this regex"),
+        Arg::new("kind")
+            .long("kind")
+            .help("Keep only nodes of these kinds: function, type, const, or a key kind (var, module, ...); repeatable")
+            .action(clap::ArgAction::Append),
+        Arg::new("visibility")
+            .long("visibility")
+            .help("Keep only exported (public) or unexported (private) symbols")
+            .value_parser(["public", "private"]),
+    ]
+}
+
+/// Filter from the `node_slice_filter_args` flags
+///
+/// # 4-Word Name: node_slice_filter_from_matches
+pub(crate) fn node_slice_filter_from_matches(
+    matches: &ArgMatches,
+) -> Result<parseltongue_core::graph_node_slice_filter::GraphNodeSliceFilter> {
+    let kinds: Vec<&str> = matches.get_many::<String>("kind").map(|v| v.map(String::as_str).collect()).unwrap_or_default();
+    Ok(parseltongue_core::graph_node_slice_filter::GraphNodeSliceFilter::parse_node_slice_filter(
+        matches.get_one::<String>("match").map(String::as_str),
+        &kinds,
+        matches.get_one::<String>("visibility").map(String::as_str),
+    )?
+    .with_min_edge_confidence(min_confidence_from_matches(matches)?))
+}
+
+/// `--min-confidence` edge filter of traversals and exports (v1.7.3)
+///
+/// # 4-Word Name: min_confidence_arg
+pub(crate) fn min_confidence_arg() -> Arg {
+    Arg::new("min-confidence")
+        .long("min-confidence")
+        .value_name("SCORE")
+        .help("Ignore edges scored below this confidence, 0-1 (0.6 drops dynamic dispatch and name-only guesses)")
+}
+
+/// Edges passing `--min-confidence`, when the command has the flag
+///
+/// # 4-Word Name: min_confidence_from_matches
+pub(crate) fn min_confidence_from_matches(matches: &ArgMatches) -> Result<Option<f64>> {
+    match matches.try_get_one::<String>("min-confidence").ok().flatten() {
+        Some(value) => Ok(Some(parseltongue_core::edge_confidence_provenance_scorer::parse_min_confidence_value(value)?)),
+        None => Ok(None),
+    }
+}
+
+/// `--max-fanout` guard of traversal commands (v1.7.3)
+///
+/// # 4-Word Name: max_fanout_limit_arg
+pub(crate) fn max_fanout_limit_arg() -> Arg {
+    Arg::new("max-fanout")
+        .long("max-fanout")
+        .help("Follow at most this many neighbours per node (god objects); capped nodes are listed")
+        .value_parser(clap::value_parser!(usize))
+}
+
+/// `--max-fanout` / `--max-nodes` guards of traversal commands (v1.7.3)
+///
+/// # 4-Word Name: traversal_limit_args
+pub(crate) fn traversal_limit_args() -> [Arg; 2] {
+    [
+        max_fanout_limit_arg(),
+        Arg::new("max-nodes")
+            .long("max-nodes")
+            .help("Report at most this many entries, nearest first; the rest are counted")
+            .value_parser(clap::value_parser!(usize)),
+    ]
+}
+
+/// Guards from `--depth`/`--max-depth` and the `traversal_limit_args` flags
+///
+/// # 4-Word Name: traversal_limits_from_matches
+pub(crate) fn traversal_limits_from_matches(
+    matches: &ArgMatches,
+) -> parseltongue_core::traversal_expansion_limit_guards::TraversalExpansionLimitSpec {
+    parseltongue_core::traversal_expansion_limit_guards::TraversalExpansionLimitSpec {
+        // q, uses-of and an unbounded slice have no --depth
+        max_depth: matches.try_get_one::<usize>("depth").ok().flatten().copied().unwrap_or(usize::MAX),
+        max_fanout: matches.try_get_one::<usize>("max-fanout").ok().flatten().copied(),
+        max_nodes: matches.try_get_one::<usize>("max-nodes").ok().flatten().copied(),
+    }
+}
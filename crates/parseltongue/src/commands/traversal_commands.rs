@@ -0,0 +1,1076 @@
+//! Traversal CLI commands (v1.7.3)
+//!
+//! # 4-Word Naming: traversal_subcommand_definition_list
+//!
+//! Backs the graph walks from one symbol: `blast-radius`, `q`, `path`,
+//! `hierarchy`, `type-hierarchy`, `uses-of`, `boundary-paths`, `error-flow`,
+//! `panic-flow` and `concurrent`.
+
+use clap::{Arg, ArgMatches, Command};
+use console::style;
+use anyhow::Result;
+
+use crate::commands::shared_cli_arg_helpers::{
+    min_confidence_arg, min_confidence_from_matches, node_slice_filter_args,
+    node_slice_filter_from_matches, open_storage_resolve_symbol, traversal_limit_args, traversal_limits_from_matches,
+};
+
+/// Subcommands backed by this module, in `--help` order
+///
+/// # 4-Word Name: traversal_subcommand_definition_list
+pub(crate) fn traversal_subcommand_definition_list() -> Vec<Command> {
+    vec![
+        Command::new("blast-radius")
+            .about("List every symbol transitively affected by changing a symbol")
+            .long_about(
+                "Walks dependency edges backwards from the symbol (who calls it, implements it,\n\
+                embeds it, uses it), following only the requested edge types.\n\n\
+                Examples:\n  \
+                parseltongue blast-radius UserStore --db rocksdb:analysis.db --depth 3 --edges calls,implements\n  \
+                parseltongue blast-radius 'go:method:Get:__svc:T1' --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Changed symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum hops to traverse")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("3"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("edges")
+                    .long("edges")
+                    .help("Edge types to follow: calls,uses,implements,embeds,spawns,sendsto,receivesfrom,reads,writes,tests,queriestable,serializes,generatedfrom,configuredby")
+                    .default_value("all"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("q")
+            .about("Evaluate a graph query expression")
+            .long_about(
+                "Combines traversals and filters with set operators: & (intersect), | (union),\n\
+                - (difference), ! (complement). Traversals follow `depth N` hops (default 1).\n\n\
+                Functions: callers callees dependents dependencies implementers symbol\n\
+                in_package in_file kind lang name all\n\n\
+                Examples:\n  \
+                parseltongue q 'callers(UserService.CreateUser) & in_package(\"api\") depth 2' --db rocksdb:analysis.db\n  \
+                parseltongue q 'dependents(store.Save) - in_file(\"*_test.go\") limit 50' --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("query")
+                    .help("Query expression")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            )
+            .args(node_slice_filter_args())
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg()),
+        Command::new("path")
+            .about("Show the shortest chain of edges from one symbol to another")
+            .long_about(
+                "Follows dependency edges forwards (A calls/uses/implements/embeds ...) until B is\n\
+                reached. B may be an external symbol known only as an unresolved call target.\n\n\
+                Examples:\n  \
+                parseltongue path main sql.DB.Query --db rocksdb:analysis.db\n  \
+                parseltongue path handleLogin Save --db rocksdb:analysis.db --edges calls --json"
+            )
+            .arg(
+                Arg::new("from")
+                    .help("Start symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("to")
+                    .help("Target symbol: ISGL1 key or (qualified) name")
+                    .required(true)
+                    .index(2),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum path length in hops")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("12"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("edges")
+                    .long("edges")
+                    .help("Edge types to follow: calls,uses,implements,embeds,spawns,sendsto,receivesfrom,reads,writes,tests,queriestable,serializes,generatedfrom,configuredby")
+                    .default_value("all"),
+            )
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("hierarchy")
+            .about("Print the call hierarchy of a symbol as an indented tree")
+            .long_about(
+                "Incoming (callers) or outgoing (callees) calls as an IDE's call hierarchy shows\n\
+                them: one entry per line, indented per level, with the definition's file:line.\n\
+                Symbols already on the path are marked (recursive); entries at the depth limit\n\
+                show how many further calls were not expanded.\n\n\
+                Examples:\n  \
+                parseltongue hierarchy CreateUser --db rocksdb:analysis.db\n  \
+                parseltongue hierarchy main --direction callees --depth 5 --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Root symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("direction")
+                    .long("direction")
+                    .help("callers (incoming calls) or callees (outgoing calls)")
+                    .value_parser(["callers", "callees"])
+                    .default_value("callers"),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Levels below the root to expand")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("3"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the tree as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("type-hierarchy")
+            .about("Print the supertypes and subtypes of a type")
+            .long_about(
+                "Supertypes are what the type satisfies, implements, embeds or extends; subtypes\n\
+                are the reverse. Covers Go interface satisfaction and embedding, Rust trait impls\n\
+                and super-traits, and Java/Kotlin extends/implements. External types (a trait from\n\
+                a dependency) can be the root too.\n\n\
+                Examples:\n  \
+                parseltongue type-hierarchy UserStore --direction subtypes --db rocksdb:analysis.db\n  \
+                parseltongue type-hierarchy Serialize --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("type")
+                    .help("Root type: ISGL1 key or type name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("direction")
+                    .long("direction")
+                    .help("supertypes, subtypes or both")
+                    .value_parser(["supertypes", "subtypes", "both"])
+                    .default_value("both"),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Levels below the root to expand")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("5"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit the trees as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("uses-of")
+            .about("List where a type is used, grouped by usage kind")
+            .long_about(
+                "Every declaration mentioning the type: struct fields, parameters, return types,\n\
+                variable declarations, type assertions and embeddings (Go). Types outside the\n\
+                graph (time.Time) are matched by their qualified name.\n\n\
+                Examples:\n  \
+                parseltongue uses-of User --db rocksdb:analysis.db\n  \
+                parseltongue uses-of time.Time --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("type")
+                    .help("Type: ISGL1 key, name, or qualified name of an external type")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .args(traversal_limit_args())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("boundary-paths")
+            .about("List call paths from an entry point that cross a safety boundary")
+            .long_about(
+                "Symbols with a `boundary` facet: Rust unsafe fns/blocks/impls, extern \"C\" fns and\n\
+                extern block declarations; Go code using `unsafe` or cgo, and //export functions.\n\
+                Prints the shortest call path from the entry point to each one it reaches.\n\n\
+                Examples:\n  \
+                parseltongue boundary-paths main --db rocksdb:analysis.db\n  \
+                parseltongue boundary-paths handle_request --depth 6 --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Entry point: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum call hops from the entry point")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("10"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit paths as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("error-flow")
+            .about("Show where an error produced by a symbol can surface to callers")
+            .long_about(
+                "Follows PropagatesErrorTo edges from a Go function or sentinel error var: calls whose\n\
+                error is returned (`if err != nil { return err }`) or wrapped (`fmt.Errorf(\"%w\")`),\n\
+                and functions returning the sentinel. Terminal sites handle the error or expose it.\n\n\
+                Examples:\n  \
+                parseltongue error-flow ErrNotFound --db rocksdb:analysis.db\n  \
+                parseltongue error-flow LoadConfig --depth 4 --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Error origin: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum propagation hops from the origin")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("10"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit sites as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("panic-flow")
+            .about("Show whether a panic in a symbol is recovered before it reaches main")
+            .long_about(
+                "Walks callers of a Go symbol that may panic. A caller catches the panic when it defers\n\
+                a function calling recover(); `main`, goroutine entries and callerless symbols are\n\
+                where an uncaught panic ends the program or escapes the graph.\n\n\
+                Examples:\n  \
+                parseltongue panic-flow parseConfig --db rocksdb:analysis.db\n  \
+                parseltongue panic-flow mustLoad --depth 6 --db rocksdb:analysis.db --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Panicking symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum caller hops from the symbol")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("10"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit paths as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+        Command::new("concurrent")
+            .about("List everything that runs concurrently downstream of a symbol")
+            .long_about(
+                "Follows Calls and Spawns (Go `go f()`) edges forwards from the symbol and reports\n\
+                every entity reached through at least one goroutine launch, with the spawn site.\n\n\
+                Examples:\n  \
+                parseltongue concurrent HandleUpload --db rocksdb:analysis.db\n  \
+                parseltongue concurrent 'go:fn:main:__cmd:T1' --db rocksdb:analysis.db --depth 8 --json"
+            )
+            .arg(
+                Arg::new("symbol")
+                    .help("Root symbol: ISGL1 key or entity name")
+                    .required(true)
+                    .index(1),
+            )
+            .arg(
+                Arg::new("db")
+                    .long("db")
+                    .help("Database file path (rocksdb:path or sqlite:path)")
+                    .required(true),
+            )
+            .arg(
+                Arg::new("depth")
+                    .long("depth")
+                    .visible_alias("max-depth")
+                    .help("Maximum hops to traverse")
+                    .value_parser(clap::value_parser!(usize))
+                    .default_value("6"),
+            )
+            .args(traversal_limit_args())
+            .arg(min_confidence_arg())
+            .arg(
+                Arg::new("json")
+                    .long("json")
+                    .help("Emit results as JSON")
+                    .action(clap::ArgAction::SetTrue),
+            ),
+    ]
+}
+
+pub(crate) async fn run_blast_radius_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::filtered_graph_traversal_queries::{
+        compute_filtered_blast_radius, parse_edge_type_filter_list,
+    };
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+    let edge_types = parse_edge_type_filter_list(matches.get_one::<String>("edges").unwrap())?;
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+
+    let (storage, changed_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Incoming, &mut truncation);
+    let mut affected = compute_filtered_blast_radius(&edges, &changed_key, depth, &edge_types);
+    let beyond = compute_filtered_blast_radius(&edges, &changed_key, depth + 1, &edge_types);
+    truncation.record_beyond_depth_count(beyond.len().saturating_sub(affected.len()));
+    let total_affected = affected.len();
+    truncation.truncate_entries_to_node_limit(&mut affected);
+
+    // v1.7.3: reflection/plugin/registry symbols have edges the parser cannot see
+    let metadata = storage.get_entity_extractor_metadata_map().await?;
+    let dynamic_usage: Vec<&str> = std::iter::once(changed_key.as_str())
+        .chain(affected.iter().map(|entry| entry.entity_key.as_str()))
+        .filter(|key| {
+            metadata
+                .get(*key)
+                .and_then(|m| m.get(parseltongue_core::dead_code_detection_analyzer::DYNAMIC_USAGE_METADATA_KEY))
+                .is_some_and(|v| v == "true")
+        })
+        .collect();
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "changed_entity": changed_key,
+            "depth": depth,
+            "edges": edge_types.iter().map(|t| t.as_str()).collect::<Vec<_>>(),
+            "total_affected": total_affected,
+            "affected": affected,
+            "dynamic_usage": dynamic_usage,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} {}", style("Blast radius of").cyan(), style(&changed_key).yellow());
+    let mut current_depth = 0;
+    for entry in &affected {
+        if entry.depth != current_depth {
+            current_depth = entry.depth;
+            println!("{}", style(format!("  hop {}:", current_depth)).green());
+        }
+        println!("    {}  ({} {})", entry.entity_key, entry.via_edge_type, entry.via_entity_key);
+    }
+    println!("Total affected: {}", total_affected);
+    print_truncation_marker_lines(&truncation);
+    if !dynamic_usage.is_empty() {
+        println!(
+            "  {} {} symbols use reflection, plugins or registries; static edges may be incomplete:",
+            style("⚠").yellow(),
+            dynamic_usage.len()
+        );
+        for key in &dynamic_usage {
+            println!("    {}", key);
+        }
+    }
+
+    Ok(())
+}
+
+pub(crate) async fn run_graph_query_dsl_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::graph_query_dsl_evaluator::evaluate_graph_query_text;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let query = matches.get_one::<String>("query").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+
+    let filter = node_slice_filter_from_matches(matches)?;
+    // The query's own `depth N` bounds the walk; `limit` cuts like --max-nodes
+    let limits = traversal_limits_from_matches(matches);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let mut entities = storage.get_all_entities_with_metadata().await?;
+    let mut edges = storage.get_all_dependencies().await?;
+    // Evaluated on the slice: traversals only cross matching nodes
+    filter.retain_matching_graph_slice(&mut entities, &mut edges, &[]);
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Undirected, &mut truncation);
+    let mut result = evaluate_graph_query_text(query, &entities, &edges)?;
+    truncation.truncate_entries_to_node_limit(&mut result.matches);
+    result.truncated |= truncation.nodes_omitted > 0;
+
+    if matches.get_flag("json") {
+        let mut output = serde_json::to_value(&result)?;
+        output["truncation"] = serde_json::to_value(&truncation)?;
+        println!("{}", serde_json::to_string_pretty(&output)?);
+        return Ok(());
+    }
+
+    for entry in &result.matches {
+        match (&entry.file_path, entry.line_start) {
+            (Some(file), Some(line)) => println!("{}  {}:{}", entry.key, file, line),
+            (Some(file), None) => println!("{}  {}", entry.key, file),
+            _ => println!("{}", entry.key),
+        }
+    }
+    if result.truncated {
+        println!("Total: {} (showing {})", result.total_count, result.matches.len());
+    } else {
+        println!("Total: {}", result.total_count);
+    }
+    print_truncation_marker_lines(&truncation);
+
+    Ok(())
+}
+
+pub(crate) async fn run_shortest_path_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::filtered_graph_traversal_queries::{
+        find_bounded_shortest_dependency_path, is_placeholder_target_key, parse_edge_type_filter_list,
+    };
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let from = matches.get_one::<String>("from").unwrap();
+    let to = matches.get_one::<String>("to").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+    let edge_types = parse_edge_type_filter_list(matches.get_one::<String>("edges").unwrap())?;
+
+    let (storage, from_key) = open_storage_resolve_symbol(db, from).await?;
+    let edges = storage.get_all_dependencies().await?;
+
+    // Any match of the target will do; external symbols (sql.DB.Query) only
+    // exist as placeholder call targets, so those count too
+    let mut target_keys: std::collections::HashSet<String> =
+        storage.find_entity_keys_by_symbol(to).await?.into_iter().collect();
+    let leaf = to
+        .rsplit(|c: char| c == '.' || c == ':' || c == '#')
+        .find(|s| !s.is_empty())
+        .unwrap_or(to);
+    for edge in &edges {
+        let to_key = edge.to_key.as_str();
+        if to_key == to || (is_placeholder_target_key(to_key) && to_key.split(':').nth(2) == Some(leaf)) {
+            target_keys.insert(to_key.to_string());
+        }
+    }
+    if target_keys.is_empty() {
+        anyhow::bail!("No entity or call target matches '{}'", to);
+    }
+
+    // A capped fan-out can hide the only route, which the markers say
+    let limits = traversal_limits_from_matches(matches);
+    let max_nodes = limits.max_nodes.unwrap_or(usize::MAX);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let walk_edges = confident_dependency_edges(matches, edges.clone())?;
+    let walk_edges = cap_edge_list_fanout(&walk_edges, TraversalFanoutDirectionKind::Outgoing, &mut truncation);
+    let (path, turned_away) =
+        find_bounded_shortest_dependency_path(&walk_edges, &from_key, &target_keys, depth, max_nodes, &edge_types);
+    if path.is_none() {
+        truncation.record_nodes_omitted_count(turned_away);
+        let deeper = find_bounded_shortest_dependency_path(
+            &walk_edges,
+            &from_key,
+            &target_keys,
+            depth + 1,
+            max_nodes,
+            &edge_types,
+        );
+        if deeper.0.is_some() {
+            truncation.record_beyond_depth_count(1);
+        }
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "from_entity": from_key,
+            "to_symbol": to,
+            "edges": edge_types.iter().map(|t| t.as_str()).collect::<Vec<_>>(),
+            "found": path.is_some(),
+            "hop_count": path.as_ref().map(|p| p.len()),
+            "path": path.unwrap_or_default(),
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    let Some(path) = path else {
+        println!("No path from {} to '{}' within {} hops", from_key, to, depth);
+        print_truncation_marker_lines(&truncation);
+        return Ok(());
+    };
+    println!("{}", style(&from_key).yellow());
+    for hop in &path {
+        let location = hop.source_location.as_deref().map(|l| format!("  @ {}", l)).unwrap_or_default();
+        println!("  {} {}{}", style(format!("--{}-->", hop.edge_type)).green(), hop.to_key, location);
+    }
+    println!("{} hop(s)", path.len());
+
+    Ok(())
+}
+
+pub(crate) async fn run_call_hierarchy_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::call_hierarchy_tree_builder::{
+        build_bounded_call_hierarchy_tree, render_call_hierarchy_text, CallHierarchyDirectionKind,
+        MAX_CALL_HIERARCHY_NODES,
+    };
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+    let direction = match matches.get_one::<String>("direction").map(String::as_str) {
+        Some("callees") => CallHierarchyDirectionKind::Callees,
+        _ => CallHierarchyDirectionKind::Callers,
+    };
+
+    // Depth and node cuts show in the tree itself (`… N more`)
+    let limits = traversal_limits_from_matches(matches);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let fanout_direction = match direction {
+        CallHierarchyDirectionKind::Callers => TraversalFanoutDirectionKind::Incoming,
+        CallHierarchyDirectionKind::Callees => TraversalFanoutDirectionKind::Outgoing,
+    };
+
+    let (storage, root_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let entities = storage.get_all_entities().await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, fanout_direction, &mut truncation);
+    let max_nodes = limits.max_nodes.unwrap_or(MAX_CALL_HIERARCHY_NODES);
+    let tree = build_bounded_call_hierarchy_tree(&root_key, &entities, &edges, direction, depth, max_nodes);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "root_entity": root_key,
+            "direction": direction,
+            "depth": depth,
+            "tree": tree,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    // Plain text, no colour: meant to be pasted into prompts as-is
+    print!("{}", render_call_hierarchy_text(&tree));
+    for line in truncation.render_truncation_marker_lines() {
+        println!("{}", line);
+    }
+    Ok(())
+}
+
+pub(crate) async fn run_type_hierarchy_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+    use parseltongue_core::type_hierarchy_tree_builder::{
+        build_bounded_type_hierarchy_tree, collect_type_hierarchy_links, render_type_hierarchy_text,
+        TypeHierarchyDirectionKind, MAX_TYPE_HIERARCHY_NODES,
+    };
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let type_name = matches.get_one::<String>("type").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+    let direction = matches.get_one::<String>("direction").map_or("both", String::as_str);
+
+    // Depth and node cuts show in the trees themselves (`… N more`)
+    let limits = traversal_limits_from_matches(matches);
+    let max_nodes = limits.max_nodes.unwrap_or(MAX_TYPE_HIERARCHY_NODES);
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities().await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Undirected, &mut truncation);
+    let links = collect_type_hierarchy_links(&entities, &edges);
+
+    // Impl blocks share their type's name but are not hierarchy nodes; an
+    // external type (a trait from a dependency) only exists as a link end
+    let mut keys: Vec<String> = storage
+        .find_entity_keys_by_symbol(type_name)
+        .await?
+        .into_iter()
+        .filter(|key| key.split(':').nth(1) != Some("impl"))
+        .collect();
+    if keys.is_empty() {
+        let leaf = type_name.rsplit(|c: char| c == '.' || c == ':').find(|s| !s.is_empty()).unwrap_or(type_name);
+        let mut external: Vec<String> = links
+            .iter()
+            .map(|link| link.supertype_key.clone())
+            .filter(|key| key == type_name || parse_unresolved_reference_name(key) == Some(leaf))
+            .collect();
+        external.sort();
+        external.dedup();
+        keys = external;
+    }
+    let root_key = match keys.len() {
+        0 => anyhow::bail!("No type matches '{}'", type_name),
+        1 => keys.remove(0),
+        n => {
+            eprintln!("'{}' matches {} types; pass one ISGL1 key:", type_name, n);
+            for key in keys.iter().take(20) {
+                eprintln!("  {}", key);
+            }
+            anyhow::bail!("Ambiguous type '{}'", type_name)
+        }
+    };
+
+    let mut trees = Vec::new();
+    for (label, kind) in [
+        ("supertypes", TypeHierarchyDirectionKind::Supertypes),
+        ("subtypes", TypeHierarchyDirectionKind::Subtypes),
+    ] {
+        if direction == "both" || direction == label {
+            let tree = build_bounded_type_hierarchy_tree(&root_key, &entities, &links, kind, depth, max_nodes);
+            trees.push((label, tree));
+        }
+    }
+
+    if matches.get_flag("json") {
+        let mut output = serde_json::json!({ "root_entity": root_key, "depth": depth, "truncation": truncation });
+        for (label, tree) in &trees {
+            output[*label] = serde_json::to_value(tree)?;
+        }
+        println!("{}", serde_json::to_string_pretty(&output)?);
+        return Ok(());
+    }
+
+    // Plain text like `hierarchy`, one section per direction
+    for (index, (label, tree)) in trees.iter().enumerate() {
+        if index > 0 {
+            println!();
+        }
+        println!("{}:", label);
+        print!("{}", render_type_hierarchy_text(tree));
+    }
+    for line in truncation.render_truncation_marker_lines() {
+        println!("{}", line);
+    }
+    Ok(())
+}
+
+pub(crate) async fn run_type_uses_of_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::go_embedding_promotion_resolver::parse_unresolved_reference_name;
+    use parseltongue_core::go_type_reference_extractor::group_type_usage_sites;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalExpansionLimitSpec, TraversalFanoutDirectionKind,
+        TraversalTruncationMarkerReport,
+    };
+
+    let type_name = matches.get_one::<String>("type").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    // One hop: the declarations that mention the type
+    let limits = TraversalExpansionLimitSpec { max_depth: 1, ..traversal_limits_from_matches(matches) };
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+
+    let storage = parseltongue_core::storage::CozoDbStorage::new(db).await?;
+    let entities = storage.get_all_entities().await?;
+    let edges = storage.get_all_dependencies().await?;
+
+    let mut type_keys: Vec<String> = storage
+        .find_entity_keys_by_symbol(type_name)
+        .await?
+        .into_iter()
+        .filter(|key| matches!(key.split(':').nth(1), Some("struct" | "interface" | "class" | "enum" | "trait")))
+        .collect();
+    if type_keys.len() > 1 {
+        eprintln!("'{}' matches {} types; pass one ISGL1 key:", type_name, type_keys.len());
+        for key in type_keys.iter().take(20) {
+            eprintln!("  {}", key);
+        }
+        anyhow::bail!("Ambiguous type '{}'", type_name);
+    }
+    if type_keys.is_empty() {
+        // External types only exist as placeholders: `time.Time`, or
+        // `example.com/app/models.User` for `models.User` / `User`
+        let suffix = format!("/{}", type_name);
+        let matches_name = |name: &str| {
+            name == type_name.as_str()
+                || name.ends_with(&suffix)
+                || (!type_name.contains('.') && name.rsplit('.').next() == Some(type_name.as_str()))
+        };
+        type_keys = edges
+            .iter()
+            .map(|edge| edge.to_key.as_str())
+            .filter(|key| key.starts_with("go:type:") && parse_unresolved_reference_name(key).is_some_and(|name| matches_name(name)))
+            .map(str::to_string)
+            .collect();
+        type_keys.sort();
+        type_keys.dedup();
+    }
+    if type_keys.is_empty() {
+        anyhow::bail!("No type matches '{}'", type_name);
+    }
+
+    let key_set: std::collections::HashSet<String> = type_keys.iter().cloned().collect();
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Incoming, &mut truncation);
+    let mut usages = group_type_usage_sites(&key_set, &entities, &edges);
+    let usage_count = usages.values().map(Vec::len).sum::<usize>();
+    // --max-nodes counts sites across kinds, in kind order
+    if let Some(limit) = limits.max_nodes {
+        let mut left = limit;
+        for sites in usages.values_mut() {
+            let keep = sites.len().min(left);
+            truncation.record_nodes_omitted_count(sites.len() - keep);
+            sites.truncate(keep);
+            left -= keep;
+        }
+        usages.retain(|_, sites| !sites.is_empty());
+    }
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "type": type_name,
+            "type_entity_keys": type_keys,
+            "usage_count": usage_count,
+            "usages": usages,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} ({})", style(type_name).yellow(), type_keys.join(", "));
+    if usages.is_empty() {
+        println!("No usages recorded (fields, parameters, returns, variables, assertions, embeddings)");
+    }
+    for (kind, sites) in &usages {
+        println!("{} ({}):", style(kind).green(), sites.len());
+        for site in sites {
+            println!("  {}  {}", site.name, site.location.as_deref().unwrap_or("-"));
+        }
+    }
+    print_truncation_marker_lines(&truncation);
+    Ok(())
+}
+
+pub(crate) async fn run_boundary_paths_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::safety_boundary_facet_tagger::find_safety_boundary_paths;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+
+    let (storage, entry_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Outgoing, &mut truncation);
+    let mut paths = find_safety_boundary_paths(&entry_key, &entities, &edges, depth);
+    let beyond = find_safety_boundary_paths(&entry_key, &entities, &edges, depth + 1);
+    truncation.record_beyond_depth_count(beyond.len().saturating_sub(paths.len()));
+    let boundary_count = paths.len();
+    truncation.truncate_entries_to_node_limit(&mut paths);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "entry_point": entry_key,
+            "depth": depth,
+            "boundary_count": boundary_count,
+            "paths": paths,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} {}", style("Safety boundaries reachable from").cyan(), style(&entry_key).yellow());
+    if paths.is_empty() {
+        println!("No unsafe, FFI or cgo code within {} calls", depth);
+    }
+    for entry in &paths {
+        println!("  {}  [{}]", style(&entry.boundary_key).yellow(), entry.boundary_kinds.join(", "));
+        println!("    {}", entry.path.join(" → "));
+    }
+    print_truncation_marker_lines(&truncation);
+    Ok(())
+}
+
+pub(crate) async fn run_error_flow_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::go_error_propagation_extractor::trace_error_surface_sites;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+
+    let (storage, origin_key) = open_storage_resolve_symbol(db, symbol).await?;
+    // PropagatesErrorTo points from callee to caller: the walk goes forward
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Outgoing, &mut truncation);
+    let mut sites = trace_error_surface_sites(&origin_key, &edges, depth);
+    let beyond = trace_error_surface_sites(&origin_key, &edges, depth + 1);
+    truncation.record_beyond_depth_count(beyond.len().saturating_sub(sites.len()));
+    let site_count = sites.len();
+    truncation.truncate_entries_to_node_limit(&mut sites);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "origin": origin_key,
+            "depth": depth,
+            "site_count": site_count,
+            "sites": sites,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} {}", style("Errors of").cyan(), style(&origin_key).yellow());
+    if sites.is_empty() {
+        println!("Not returned by any caller within {} hops", depth);
+    }
+    for site in &sites {
+        let marker = if site.terminal { " (surfaces here)" } else { "" };
+        println!("  {}{} {}{}", "  ".repeat(site.depth - 1), site.error_flow, style(&site.entity_key).yellow(), marker);
+        println!("  {}  at {}", "  ".repeat(site.depth - 1), site.source_location.as_deref().unwrap_or("-"));
+    }
+    print_truncation_marker_lines(&truncation);
+    Ok(())
+}
+
+pub(crate) async fn run_panic_flow_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::go_panic_recover_extractor::{trace_panic_propagation_paths, PANIC_KINDS_METADATA_KEY};
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+
+    let (storage, origin_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let entities = storage.get_all_entities_with_metadata().await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Incoming, &mut truncation);
+    let mut paths = trace_panic_propagation_paths(&origin_key, &entities, &edges, depth);
+    let beyond = trace_panic_propagation_paths(&origin_key, &entities, &edges, depth + 1);
+    truncation.record_beyond_depth_count(beyond.len().saturating_sub(paths.len()));
+    let panic_kinds = entities
+        .iter()
+        .find(|e| e.isgl1_key == origin_key)
+        .and_then(|e| e.metadata.additional.get(PANIC_KINDS_METADATA_KEY).cloned());
+    let uncaught = paths.iter().filter(|p| p.outcome != "caught").count();
+    let reaches_main_uncaught = paths.iter().any(|p| p.outcome == "main");
+    truncation.truncate_entries_to_node_limit(&mut paths);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "origin": origin_key,
+            "panic_kinds": panic_kinds,
+            "depth": depth,
+            "reaches_main_uncaught": reaches_main_uncaught,
+            "uncaught_count": uncaught,
+            "paths": paths,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} {}", style("Panics of").cyan(), style(&origin_key).yellow());
+    match &panic_kinds {
+        Some(kinds) => println!("  may panic: {}", kinds),
+        None => println!("  {} no direct panic recorded; tracing anyway", style("⚠").yellow()),
+    }
+    for entry in &paths {
+        let label = match entry.recovered_by.as_deref() {
+            Some(handler) => format!("caught (recover in {})", handler),
+            None => entry.outcome.clone(),
+        };
+        println!("  {}  {}", style(label).yellow(), entry.path.join(" → "));
+    }
+    if uncaught == 0 && !paths.is_empty() {
+        println!("Recovered on every path within {} calls", depth);
+    }
+    print_truncation_marker_lines(&truncation);
+    Ok(())
+}
+
+pub(crate) async fn run_concurrent_downstream_command(matches: &ArgMatches) -> Result<()> {
+    use parseltongue_core::filtered_graph_traversal_queries::collect_concurrent_downstream_entities;
+    use parseltongue_core::traversal_expansion_limit_guards::{
+        cap_edge_list_fanout, TraversalFanoutDirectionKind, TraversalTruncationMarkerReport,
+    };
+
+    let symbol = matches.get_one::<String>("symbol").unwrap();
+    let db = matches.get_one::<String>("db").unwrap();
+    let depth = *matches.get_one::<usize>("depth").unwrap();
+
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(traversal_limits_from_matches(matches));
+
+    let (storage, root_key) = open_storage_resolve_symbol(db, symbol).await?;
+    let edges = confident_dependency_edges(matches, storage.get_all_dependencies().await?)?;
+    let edges = cap_edge_list_fanout(&edges, TraversalFanoutDirectionKind::Outgoing, &mut truncation);
+    let mut reached = collect_concurrent_downstream_entities(&edges, &root_key, depth);
+    let beyond = collect_concurrent_downstream_entities(&edges, &root_key, depth + 1);
+    truncation.record_beyond_depth_count(beyond.len().saturating_sub(reached.len()));
+    let total_concurrent = reached.len();
+    truncation.truncate_entries_to_node_limit(&mut reached);
+
+    if matches.get_flag("json") {
+        println!("{}", serde_json::to_string_pretty(&serde_json::json!({
+            "root_entity": root_key,
+            "depth": depth,
+            "total_concurrent": total_concurrent,
+            "concurrent": reached,
+            "truncation": truncation,
+        }))?);
+        return Ok(());
+    }
+
+    println!("{} {}", style("Runs concurrently downstream of").cyan(), style(&root_key).yellow());
+    for entry in &reached {
+        let location = entry.spawn_location.as_deref().map(|l| format!(" @ {}", l)).unwrap_or_default();
+        println!("  {}  (spawned by {}{})", entry.entity_key, entry.spawned_by_key, location);
+    }
+    println!("Total concurrent: {}", total_concurrent);
+    print_truncation_marker_lines(&truncation);
+
+    Ok(())
+}
+
+/// Drop edges below `--min-confidence` before a traversal
+fn confident_dependency_edges(
+    matches: &ArgMatches,
+    mut edges: Vec<parseltongue_core::entities::DependencyEdge>,
+) -> Result<Vec<parseltongue_core::entities::DependencyEdge>> {
+    if let Some(min) = min_confidence_from_matches(matches)? {
+        parseltongue_core::edge_confidence_provenance_scorer::retain_edges_meeting_confidence(&mut edges, min);
+    }
+    Ok(edges)
+}
+
+/// Print truncation markers after text output
+fn print_truncation_marker_lines(
+    report: &parseltongue_core::traversal_expansion_limit_guards::TraversalTruncationMarkerReport,
+) {
+    for line in report.render_truncation_marker_lines() {
+        println!("{}", style(line).dim());
+    }
+}
@@ -0,0 +1,488 @@
+//! Project configuration file (v1.7.3)
+//!
+//! # 4-Word Naming: load_project_config_file
+//!
+//! Team defaults checked in at the repository root, so ingest filters,
+//! context budgets and the export format are not repeated on every
+//! invocation. The first of `.parseltongue.toml`, `.companion.yaml` and
+//! `.companion.yml` found walking up from the working directory (stopping at
+//! the directory holding `.git`) is used.
+//!
+//! ```toml
+//! languages = ["go", "rust"]
+//! include = ["cmd/**", "internal/**"]
+//! exclude = ["vendor/**", "*_gen.go"]
+//! allow = ["gen/openapi/**"]   # index even if excluded or git-ignored
+//! gitignore = true             # honor .gitignore (default)
+//!
+//! [context]          # pack-context / context defaults
+//! budget = 16000
+//! model = "gpt-4o"           # --model preset: budget defaults to its window, counted by its tokenizer
+//! depth = 3
+//! include_tests = true
+//! template = "claude-xml"   # built-in name or path relative to this file
+//! recency = true             # boost recently changed symbols (--git-blame index)
+//! recency_half_life_days = 14
+//!
+//! [output]
+//! format = "ndjson"  # export --format default
+//!
+//! [overrides."tools/scripts"]
+//! languages = ["python"]
+//! exclude = ["fixtures/**"]
+//!
+//! [repos.client-lib]   # `parseltongue federate` inputs
+//! db = "rocksdb:../client-lib/parseltongue20250101/analysis.db"
+//!
+//! [summarize]        # `parseltongue summarize` LLM endpoint
+//! endpoint = "http://localhost:11434/v1/chat/completions"
+//! model = "llama3.1"
+//! api_key_env = "OPENAI_API_KEY"   # variable holding the key, if any
+//! requests_per_minute = 60
+//!
+//! [embed]            # `parseltongue embed` / `search` embedding model
+//! endpoint = "http://localhost:11434/v1/embeddings"
+//! model = "nomic-embed-text"
+//! api_key_env = "OPENAI_API_KEY"
+//!
+//! [[plugins]]          # external extractors/enrichers, see external_plugin_process_protocol
+//! name = "terraform"
+//! command = ["./tools/pt-terraform"]
+//! extensions = [".tf"]
+//! ```
+//!
+//! Flags given on the command line always win over the file, and the file
+//! wins over built-in defaults. Unknown keys are rejected so typos surface.
+
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+
+use anyhow::{bail, Context, Result};
+use parseltongue_core::entities::Language;
+use parseltongue_core::external_plugin_process_protocol::ExternalPluginProcessSpec;
+use parseltongue_core::llm_symbol_summary_cache::parse_llm_endpoint_url;
+use parseltongue_core::semantic_symbol_embedding_index::parse_embedding_endpoint_url;
+use pt01_folder_to_cozodb_streamer::project_path_filter_rules::{PathFilterRuleSet, ProjectPathFilterRules};
+use serde::Deserialize;
+
+/// Config file names, in lookup order
+pub const PROJECT_CONFIG_FILE_NAMES: &[&str] = &[".parseltongue.toml", ".companion.yaml", ".companion.yml"];
+
+/// Formats `parseltongue export --format` accepts
+pub const EXPORT_FORMAT_NAME_LIST: &[&str] = &["graphml", "dot", "scip", "binary", "ndjson", "json", "neo4j", "parquet"];
+
+const KNOWN_LANGUAGE_LIST: &[Language] = &[
+    Language::Rust,
+    Language::JavaScript,
+    Language::TypeScript,
+    Language::Python,
+    Language::Java,
+    Language::C,
+    Language::Cpp,
+    Language::Go,
+    Language::Ruby,
+    Language::Php,
+    Language::CSharp,
+    Language::Swift,
+    Language::Kotlin,
+    Language::Scala,
+    Language::Sql,
+];
+
+/// Parsed `.parseltongue.toml` / `.companion.yaml`
+///
+/// # 4-Word Name: ProjectConfigFileSpec
+#[derive(Debug, Clone, Default, PartialEq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct ProjectConfigFileSpec {
+    /// Languages to index (`go`, `rust`, `typescript`, ...); empty = all
+    pub languages: Vec<String>,
+    /// Index only files matching one of these globs; empty = all
+    pub include: Vec<String>,
+    /// Skip files matching any of these globs (on top of target/, node_modules/, ...)
+    pub exclude: Vec<String>,
+    /// Index files matching these even when excluded or git-ignored
+    pub allow: Vec<String>,
+    /// Honor `.gitignore` (default: true)
+    pub gitignore: Option<bool>,
+    pub context: ContextDefaultsConfigSection,
+    pub output: OutputDefaultsConfigSection,
+    pub summarize: SummarizeEndpointConfigSection,
+    pub embed: EmbedEndpointConfigSection,
+    /// Directory (relative to the config file) -> rules for files under it
+    pub overrides: BTreeMap<String, DirectoryOverrideConfigSection>,
+    /// Repository name -> its index, for `parseltongue federate`
+    pub repos: BTreeMap<String, FederatedRepoConfigSection>,
+    /// `[[plugins]]`: external extractor/enricher processes, run by ingest
+    pub plugins: Vec<ExternalPluginProcessSpec>,
+    /// Directory holding the file; set by `load_project_config_file`
+    #[serde(skip)]
+    pub config_dir: PathBuf,
+}
+
+/// `[context]`: token budget defaults
+///
+/// # 4-Word Name: ContextDefaultsConfigSection
+#[derive(Debug, Clone, Default, PartialEq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct ContextDefaultsConfigSection {
+    pub budget: Option<usize>,
+    /// `--model` preset of `pack-context` (see `context_pack_token_accounting`)
+    pub model: Option<String>,
+    pub depth: Option<usize>,
+    pub include_tests: Option<bool>,
+    /// Prompt template: built-in name or file path (relative to the config file)
+    pub template: Option<String>,
+    /// Rank recently changed symbols higher (needs a `--git-blame` index)
+    pub recency: Option<bool>,
+    pub recency_half_life_days: Option<f64>,
+    pub recency_weight: Option<f64>,
+    /// Boost for symbols whose commits name the `--ticket`
+    pub ticket_weight: Option<f64>,
+}
+
+/// `[output]`: output format defaults
+///
+/// # 4-Word Name: OutputDefaultsConfigSection
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct OutputDefaultsConfigSection {
+    pub format: Option<String>,
+}
+
+/// `[summarize]`: LLM endpoint of `parseltongue summarize`
+///
+/// # 4-Word Name: SummarizeEndpointConfigSection
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct SummarizeEndpointConfigSection {
+    /// OpenAI-compatible chat completions URL (`http://` or `https://`)
+    pub endpoint: Option<String>,
+    pub model: Option<String>,
+    /// Environment variable holding the API key; keys never go in the file
+    pub api_key_env: Option<String>,
+    pub requests_per_minute: Option<u32>,
+}
+
+/// `[embed]`: embedding model of `parseltongue embed` and `search`
+///
+/// # 4-Word Name: EmbedEndpointConfigSection
+///
+/// Without an endpoint both commands fall back to the offline, lexical
+/// `hashed-words-v1` model.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct EmbedEndpointConfigSection {
+    /// OpenAI-compatible embeddings URL (`http://` or `https://`)
+    pub endpoint: Option<String>,
+    pub model: Option<String>,
+    /// Environment variable holding the API key; keys never go in the file
+    pub api_key_env: Option<String>,
+}
+
+/// `[overrides."<dir>"]`: file selection for one directory
+///
+/// # 4-Word Name: DirectoryOverrideConfigSection
+///
+/// Set languages/include replace the project-wide ones under the directory;
+/// excludes add to them. Globs are relative to the directory.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct DirectoryOverrideConfigSection {
+    pub languages: Vec<String>,
+    pub include: Vec<String>,
+    pub exclude: Vec<String>,
+}
+
+/// `[repos.<name>]`: one repository of a federated index
+///
+/// # 4-Word Name: FederatedRepoConfigSection
+#[derive(Debug, Clone, Default, PartialEq, Eq, Deserialize)]
+#[serde(default, deny_unknown_fields)]
+pub struct FederatedRepoConfigSection {
+    /// Database spec (`rocksdb:path`, `sqlite:path`, `snapshot:path`); relative
+    /// paths are relative to the config file
+    pub db: String,
+}
+
+impl ProjectConfigFileSpec {
+    /// `(name, database)` of every `[repos.<name>]`, paths resolved
+    ///
+    /// # 4-Word Name: federated_repo_database_specs
+    pub fn federated_repo_database_specs(&self) -> Vec<(String, String)> {
+        self.repos
+            .iter()
+            .map(|(name, section)| {
+                let db = match section.db.split_once(':') {
+                    Some((engine, path)) if Path::new(path).is_relative() && !self.config_dir.as_os_str().is_empty() => {
+                        format!("{}:{}", engine, self.config_dir.join(path).display())
+                    }
+                    _ => section.db.clone(),
+                };
+                (name.clone(), db)
+            })
+            .collect()
+    }
+
+    /// Every `[[plugins]]` entry, command paths resolved
+    ///
+    /// # 4-Word Name: resolved_plugin_specs
+    ///
+    /// # Contract
+    /// - Postcondition: a program given as a relative path (`./tools/x`,
+    ///   `tools/x`) is relative to the config file; bare names are looked
+    ///   up on PATH
+    pub fn resolved_plugin_specs(&self) -> Vec<ExternalPluginProcessSpec> {
+        self.plugins
+            .iter()
+            .map(|plugin| {
+                let mut plugin = plugin.clone();
+                if let Some(program) = plugin.command.first_mut() {
+                    let is_relative_path = program.contains('/') && Path::new(program.as_str()).is_relative();
+                    if is_relative_path && !self.config_dir.as_os_str().is_empty() {
+                        *program = self.config_dir.join(program.trim_start_matches("./")).display().to_string();
+                    }
+                }
+                plugin
+            })
+            .collect()
+    }
+
+    /// Ingest path rules for an ingest rooted at `ingest_root`
+    ///
+    /// # 4-Word Name: to_path_filter_rules
+    ///
+    /// # Contract
+    /// - Postcondition: None when the file sets no languages, globs or
+    ///   overrides (the ingest then behaves exactly as without a config)
+    /// - Rules stay relative to `config_dir` even when `ingest_root` is a
+    ///   subdirectory of it
+    pub fn to_path_filter_rules(&self, ingest_root: &Path) -> Option<ProjectPathFilterRules> {
+        let nothing_set = self.languages.is_empty()
+            && self.include.is_empty()
+            && self.exclude.is_empty()
+            && self.allow.is_empty()
+            && self.overrides.is_empty();
+        if nothing_set {
+            return None;
+        }
+        Some(ProjectPathFilterRules {
+            ingest_root_offset: ingest_root_offset_within(&self.config_dir, ingest_root),
+            project_rules: PathFilterRuleSet {
+                languages: self.languages.clone(),
+                include_globs: self.include.clone(),
+                exclude_globs: self.exclude.clone(),
+            },
+            directory_overrides: self
+                .overrides
+                .iter()
+                .map(|(directory, section)| {
+                    let rules = PathFilterRuleSet {
+                        languages: section.languages.clone(),
+                        include_globs: section.include.clone(),
+                        exclude_globs: section.exclude.clone(),
+                    };
+                    (directory.trim_start_matches("./").to_string(), rules)
+                })
+                .collect(),
+            allow_globs: self.allow.clone(),
+        })
+    }
+
+    fn validate_config_values(&self) -> Result<()> {
+        let override_languages = self.overrides.values().flat_map(|section| section.languages.iter());
+        for name in self.languages.iter().chain(override_languages) {
+            if !KNOWN_LANGUAGE_LIST.iter().any(|language| name.eq_ignore_ascii_case(&language.to_string())) {
+                let known: Vec<String> = KNOWN_LANGUAGE_LIST.iter().map(ToString::to_string).collect();
+                bail!("unknown language '{}' (known: {})", name, known.join(", "));
+            }
+        }
+        if let Some(format) = &self.output.format {
+            if !EXPORT_FORMAT_NAME_LIST.contains(&format.as_str()) {
+                bail!("unknown output format '{}' (known: {})", format, EXPORT_FORMAT_NAME_LIST.join(", "));
+            }
+        }
+        if let Some((name, _)) = self.repos.iter().find(|(_, section)| section.db.trim().is_empty()) {
+            bail!("repos.{}.db must name a database", name);
+        }
+        if let Some(Err(reason)) = self.summarize.endpoint.as_deref().map(parse_llm_endpoint_url) {
+            bail!("summarize.endpoint: {}", reason);
+        }
+        if self.summarize.requests_per_minute == Some(0) {
+            bail!("summarize.requests_per_minute must be positive");
+        }
+        if let Some(Err(reason)) = self.embed.endpoint.as_deref().map(parse_embedding_endpoint_url) {
+            bail!("embed.endpoint: {}", reason);
+        }
+        if self.embed.endpoint.is_some() && self.embed.model.is_none() {
+            bail!("embed.endpoint needs embed.model");
+        }
+        let mut plugin_names = std::collections::HashSet::new();
+        for plugin in &self.plugins {
+            if plugin.name.trim().is_empty() {
+                bail!("plugins.name must be set");
+            }
+            if !plugin_names.insert(plugin.name.as_str()) {
+                bail!("plugin '{}' is declared twice", plugin.name);
+            }
+            if plugin.command.first().map_or(true, |program| program.trim().is_empty()) {
+                bail!("plugin '{}' needs a command", plugin.name);
+            }
+            if plugin.extensions.is_empty() && !plugin.enrich {
+                bail!("plugin '{}' needs extensions or enrich = true", plugin.name);
+            }
+            if plugin.timeout_seconds == Some(0) {
+                bail!("plugin '{}' timeout_seconds must be positive", plugin.name);
+            }
+        }
+        if self.context.budget == Some(0) {
+            bail!("context.budget must be positive");
+        }
+        if self.context.recency_half_life_days.is_some_and(|days| days <= 0.0) {
+            bail!("context.recency_half_life_days must be positive");
+        }
+        Ok(())
+    }
+}
+
+/// Nearest config file at or above `start_dir`
+///
+/// # 4-Word Name: find_project_config_file
+///
+/// # Contract
+/// - Postcondition: the search stops at the first directory containing
+///   `.git` (the repository root) or at the filesystem root
+pub fn find_project_config_file(start_dir: &Path) -> Option<PathBuf> {
+    let start = std::fs::canonicalize(start_dir).unwrap_or_else(|_| start_dir.to_path_buf());
+    for directory in start.ancestors() {
+        let found = PROJECT_CONFIG_FILE_NAMES
+            .iter()
+            .map(|name| directory.join(name))
+            .find(|candidate| candidate.is_file());
+        if found.is_some() {
+            return found;
+        }
+        if directory.join(".git").exists() {
+            return None;
+        }
+    }
+    None
+}
+
+/// Read and validate one config file
+///
+/// # 4-Word Name: load_project_config_file
+pub fn load_project_config_file(path: &Path) -> Result<ProjectConfigFileSpec> {
+    let text = std::fs::read_to_string(path).with_context(|| format!("reading {}", path.display()))?;
+    let mut spec = parse_project_config_text(&text, path).with_context(|| format!("invalid {}", path.display()))?;
+    spec.config_dir = path.parent().map(Path::to_path_buf).unwrap_or_default();
+    Ok(spec)
+}
+
+/// `find_project_config_file` + `load_project_config_file`
+///
+/// # 4-Word Name: load_nearest_project_config
+pub fn load_nearest_project_config(start_dir: &Path) -> Result<Option<(PathBuf, ProjectConfigFileSpec)>> {
+    let Some(path) = find_project_config_file(start_dir) else {
+        return Ok(None);
+    };
+    let spec = load_project_config_file(&path)?;
+    Ok(Some((path, spec)))
+}
+
+/// Parse config text; YAML for `.yaml`/`.yml` file names, TOML otherwise
+///
+/// # 4-Word Name: parse_project_config_text
+pub fn parse_project_config_text(text: &str, file_name: &Path) -> Result<ProjectConfigFileSpec> {
+    let is_yaml = file_name
+        .extension()
+        .and_then(|extension| extension.to_str())
+        .is_some_and(|extension| extension == "yaml" || extension == "yml");
+    let spec: ProjectConfigFileSpec = if is_yaml {
+        // An empty YAML document is null, not an empty mapping
+        if text.trim().is_empty() {
+            ProjectConfigFileSpec::default()
+        } else {
+            serde_yaml_ng::from_str(text)?
+        }
+    } else {
+        toml::from_str(text)?
+    };
+    spec.validate_config_values()?;
+    Ok(spec)
+}
+
+fn ingest_root_offset_within(config_dir: &Path, ingest_root: &Path) -> String {
+    let config_dir = if config_dir.as_os_str().is_empty() { Path::new(".") } else { config_dir };
+    let canonical = |path: &Path| std::fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf());
+    canonical(ingest_root)
+        .strip_prefix(canonical(config_dir))
+        .map(|offset| offset.to_string_lossy().replace('\\', "/"))
+        .unwrap_or_default()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_toml_and_yaml_parse_alike() {
+        let toml_text = r#"
+languages = ["go"]
+exclude = ["vendor/**"]
+
+[context]
+budget = 16000
+
+[output]
+format = "ndjson"
+
+[overrides."tools/scripts"]
+languages = ["python"]
+"#;
+        let yaml_text = "languages: [go]\nexclude: ['vendor/**']\ncontext:\n  budget: 16000\noutput:\n  format: ndjson\noverrides:\n  tools/scripts:\n    languages: [python]\n";
+
+        let from_toml = parse_project_config_text(toml_text, Path::new(".parseltongue.toml")).unwrap();
+        let from_yaml = parse_project_config_text(yaml_text, Path::new(".companion.yaml")).unwrap();
+        assert_eq!(from_toml, from_yaml);
+        assert_eq!(from_toml.context.budget, Some(16000));
+        assert_eq!(from_toml.context.depth, None);
+        assert_eq!(from_toml.overrides["tools/scripts"].languages, vec!["python".to_string()]);
+    }
+
+    #[test]
+    fn test_invalid_values_and_filter_rules() {
+        let path = Path::new(".parseltongue.toml");
+        assert!(parse_project_config_text("languages = [\"klingon\"]", path).is_err());
+        assert!(parse_project_config_text("[output]\nformat = \"xml\"", path).is_err());
+        assert!(parse_project_config_text("exclud = [\"vendor/**\"]", path).is_err());
+        assert!(parse_project_config_text("[summarize]\nendpoint = \"ftp://api.example.com\"", path).is_err());
+        assert!(parse_project_config_text("[summarize]\nendpoint = \"https://api.example.com\"", path).is_ok());
+        assert!(parse_project_config_text("[embed]\nendpoint = \"http://localhost:11434\"", path).is_err());
+        let embed = "[embed]\nendpoint = \"http://localhost:11434\"\nmodel = \"nomic-embed-text\"";
+        assert_eq!(parse_project_config_text(embed, path).unwrap().embed.model.as_deref(), Some("nomic-embed-text"));
+
+        let empty = parse_project_config_text("[context]\ndepth = 4", path).unwrap();
+        assert!(empty.to_path_filter_rules(Path::new(".")).is_none());
+
+        let spec = parse_project_config_text("exclude = [\"vendor/**\"]", path).unwrap();
+        let rules = spec.to_path_filter_rules(Path::new(".")).unwrap();
+        assert!(!rules.allows_ingest_relative_path(Path::new("vendor/lib/a.go")));
+        assert!(rules.allows_ingest_relative_path(Path::new("cmd/main.go")));
+    }
+
+    #[test]
+    fn test_plugin_entries_validated_and_resolved() {
+        let path = Path::new(".parseltongue.toml");
+        let text = "[[plugins]]\nname = \"tf\"\ncommand = [\"./tools/pt-tf\", \"-v\"]\nextensions = [\".tf\"]\n\n[[plugins]]\nname = \"owners\"\ncommand = [\"pt-owners\"]\nenrich = true\n";
+        let mut spec = parse_project_config_text(text, path).unwrap();
+        spec.config_dir = PathBuf::from("/repo");
+        let plugins = spec.resolved_plugin_specs();
+        assert_eq!(plugins[0].command, vec!["/repo/tools/pt-tf".to_string(), "-v".to_string()]);
+        assert_eq!(plugins[1].command, vec!["pt-owners".to_string()]);
+        assert!(spec.to_path_filter_rules(Path::new(".")).is_none());
+
+        assert!(parse_project_config_text("[[plugins]]\nname = \"x\"\ncommand = [\"x\"]", path).is_err());
+        assert!(parse_project_config_text("[[plugins]]\nname = \"x\"\nenrich = true", path).is_err());
+    }
+}
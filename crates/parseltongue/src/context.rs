@@ -0,0 +1,92 @@
+//! Context packing API (v1.7.3)
+//!
+//! # 4-Word Naming: pack_context_from_snapshot
+//!
+//! Token-budgeted context around a seed symbol, as `parseltongue
+//! pack-context` produces it: the seed first, then callers, callees and
+//! tests by relevance, bodies where they fit and signatures otherwise.
+//! Token counts are exact (`cl100k_base`).
+
+use std::collections::HashMap;
+
+use anyhow::Result;
+use parseltongue_core::context_prompt_template_renderer::ContextPackOutputTemplate;
+use parseltongue_core::entities::{EdgeType, EntityClass};
+use parseltongue_core::storage::CozoDbStorage;
+use parseltongue_core::token_budget_context_packer::{
+    build_context_pack_from_storage, pack_ranked_entities_within_budget, rank_neighbors_by_relevance,
+    MAX_PACK_CANDIDATE_ENTITIES,
+};
+use parseltongue_core::traversal_expansion_limit_guards::TraversalExpansionLimitSpec;
+
+use crate::graph::InMemoryCodeGraphSnapshot;
+
+pub use parseltongue_core::token_budget_context_packer::{
+    render_packed_context_text, PackedContextBundleResult, PackedContextItemEntry,
+};
+
+/// Pack context from an in-memory graph
+///
+/// # 4-Word Name: pack_context_from_snapshot
+///
+/// # Contract
+/// - Postcondition: same budget guarantee as `pack-context`; test entities
+///   other than the seed and `Tests` edges are left out unless
+///   `include_tests`
+/// - Stored centrality scores are not consulted (an in-memory graph has
+///   none), so ties may order differently than `pack_context_from_database`
+pub fn pack_context_from_snapshot(
+    graph: &InMemoryCodeGraphSnapshot,
+    seed_key: &str,
+    budget: usize,
+    max_depth: usize,
+    include_tests: bool,
+) -> PackedContextBundleResult {
+    let edges: Vec<_> = graph
+        .edges()
+        .iter()
+        .filter(|edge| include_tests || edge.edge_type != EdgeType::Tests)
+        .cloned()
+        .collect();
+    let mut ranked = rank_neighbors_by_relevance(seed_key, &edges, max_depth);
+    ranked.truncate(MAX_PACK_CANDIDATE_ENTITIES);
+
+    let entities: HashMap<_, _> = ranked
+        .iter()
+        .filter_map(|entry| {
+            let entity = graph.entity(&entry.entity_key)?;
+            let skipped_test = !include_tests && entity.entity_class == EntityClass::TestImplementation && entry.depth > 0;
+            (!skipped_test).then(|| (entry.entity_key.clone(), entity.clone()))
+        })
+        .collect();
+    pack_ranked_entities_within_budget(seed_key, &ranked, &entities, budget)
+}
+
+/// Pack context from a database, centrality boost included
+///
+/// # 4-Word Name: pack_context_from_database
+///
+/// # Contract
+/// - Precondition: `seed_key` is an ISGL1 key of the database
+/// - Postcondition: identical to `parseltongue pack-context`
+pub async fn pack_context_from_database(
+    db_path: &str,
+    seed_key: &str,
+    budget: usize,
+    max_depth: usize,
+    include_tests: bool,
+) -> Result<PackedContextBundleResult> {
+    let storage = CozoDbStorage::new(db_path).await?;
+    Ok(build_context_pack_from_storage(
+        &storage,
+        seed_key,
+        budget,
+        TraversalExpansionLimitSpec { max_depth, max_fanout: None, max_nodes: None },
+        include_tests,
+        &ContextPackOutputTemplate::Plain,
+        Default::default(),
+        None,
+        None,
+    )
+    .await?)
+}
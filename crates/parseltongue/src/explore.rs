@@ -0,0 +1,653 @@
+//! Terminal graph explorer (v1.7.3)
+//!
+//! # 4-Word Naming: explore
+//!
+//! State and rendering behind `parseltongue explore`: a filterable symbol
+//! list on the left, the selection's callers or callees on the right, and a
+//! details line at the bottom. Keys are handled by a pure state machine
+//! (`apply_explorer_key_press`) so the terminal loop only reads keys and
+//! repaints.
+//!
+//! | Key | Action |
+//! |-----|--------|
+//! | `↑`/`↓`, `k`/`j`, PgUp/PgDn | Move in the focused pane |
+//! | `Tab` | Switch focus between symbols and neighbours |
+//! | `c` / `e` | Show callers / callees of the selection |
+//! | `Enter` | Walk to the highlighted neighbour |
+//! | `Backspace` | Walk back |
+//! | `/` | Filter symbols by name (`Enter`/`Esc` to finish) |
+//! | `y` | Yank a context pack for the selection |
+//! | `q`, `Esc` | Quit |
+
+use std::collections::HashMap;
+
+use anyhow::{bail, Result};
+use console::{Key, Term};
+use parseltongue_core::serializers::graph_export_node_table::{collect_graph_export_nodes, GraphExportNodeRow};
+
+use crate::context::{pack_context_from_snapshot, render_packed_context_text};
+use crate::graph::InMemoryCodeGraphSnapshot;
+
+/// Which side of the selection the neighbour pane lists
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum NeighbourPaneDirection {
+    Callers,
+    Callees,
+}
+
+/// Pane receiving movement keys
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ExplorerFocusedPane {
+    Symbols,
+    Neighbours,
+    Filter,
+}
+
+/// What the terminal loop should do after a key press
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum ExplorerKeyOutcome {
+    Redraw,
+    Yank(String),
+    Quit,
+}
+
+/// One row of the neighbour pane
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct NeighbourPaneEntry {
+    pub key: String,
+    pub edge_type: String,
+    /// `path:line` of the reference
+    pub source_location: Option<String>,
+}
+
+/// Context pack settings for `y`
+#[derive(Debug, Clone, Copy)]
+pub struct ExplorerYankPackSettings {
+    pub token_budget: usize,
+    pub max_depth: usize,
+    pub include_tests: bool,
+}
+
+impl Default for ExplorerYankPackSettings {
+    fn default() -> Self {
+        Self { token_budget: 4000, max_depth: 2, include_tests: false }
+    }
+}
+
+/// Cursor, filter and walk history of one explore session
+///
+/// # 4-Word Name: GraphExplorerSessionState
+#[derive(Debug, Clone)]
+pub struct GraphExplorerSessionState {
+    nodes: HashMap<String, GraphExportNodeRow>,
+    symbol_keys: Vec<String>,
+    visible_symbols: Vec<usize>,
+    filter_query: String,
+    symbol_cursor: usize,
+    neighbour_cursor: usize,
+    direction: NeighbourPaneDirection,
+    focus: ExplorerFocusedPane,
+    selected_key: Option<String>,
+    walk_history: Vec<String>,
+    status_message: String,
+}
+
+impl GraphExplorerSessionState {
+    /// Start a session over every in-repo node, optionally selecting `start_key`
+    ///
+    /// # Contract
+    /// - Postcondition: symbols are sorted by name, then file and line;
+    ///   external placeholders only show up as neighbours
+    pub fn create_explorer_session_state(graph: &InMemoryCodeGraphSnapshot, start_key: Option<&str>) -> Self {
+        let nodes: HashMap<String, GraphExportNodeRow> = collect_graph_export_nodes(graph.entities(), graph.edges())
+            .into_iter()
+            .map(|row| (row.key.clone(), row))
+            .collect();
+        let mut listed: Vec<&GraphExportNodeRow> = nodes.values().filter(|row| !row.external).collect();
+        listed.sort_by(|a, b| {
+            (a.name.as_str(), a.file_path.as_str(), a.line_start, a.key.as_str())
+                .cmp(&(b.name.as_str(), b.file_path.as_str(), b.line_start, b.key.as_str()))
+        });
+        let symbol_keys: Vec<String> = listed.into_iter().map(|row| row.key.clone()).collect();
+
+        let mut session = Self {
+            nodes,
+            visible_symbols: (0..symbol_keys.len()).collect(),
+            symbol_keys,
+            filter_query: String::new(),
+            symbol_cursor: 0,
+            neighbour_cursor: 0,
+            direction: NeighbourPaneDirection::Callers,
+            focus: ExplorerFocusedPane::Symbols,
+            selected_key: None,
+            walk_history: Vec::new(),
+            status_message: String::new(),
+        };
+        match start_key {
+            Some(key) => session.select_key(key),
+            None => session.select_symbol_under_cursor(),
+        }
+        session
+    }
+
+    pub fn selected_key(&self) -> Option<&str> {
+        self.selected_key.as_deref()
+    }
+
+    pub fn focus(&self) -> ExplorerFocusedPane {
+        self.focus
+    }
+
+    pub fn direction(&self) -> NeighbourPaneDirection {
+        self.direction
+    }
+
+    pub fn visible_symbol_count(&self) -> usize {
+        self.visible_symbols.len()
+    }
+
+    /// Callers or callees of the selection, one row per edge
+    pub fn neighbour_entries(&self, graph: &InMemoryCodeGraphSnapshot) -> Vec<NeighbourPaneEntry> {
+        let Some(key) = self.selected_key.as_deref() else {
+            return Vec::new();
+        };
+        let edges = match self.direction {
+            NeighbourPaneDirection::Callers => graph.callers_of(key),
+            NeighbourPaneDirection::Callees => graph.callees_of(key),
+        };
+        edges
+            .into_iter()
+            .map(|edge| NeighbourPaneEntry {
+                key: match self.direction {
+                    NeighbourPaneDirection::Callers => edge.from_key.as_str().to_string(),
+                    NeighbourPaneDirection::Callees => edge.to_key.as_str().to_string(),
+                },
+                edge_type: format!("{:?}", edge.edge_type),
+                source_location: edge.source_location.clone(),
+            })
+            .collect()
+    }
+
+    /// Apply one key press
+    ///
+    /// # 4-Word Name: apply_explorer_key_press
+    ///
+    /// # Contract
+    /// - Postcondition: `Yank` carries the rendered pack for the selection;
+    ///   keys that do nothing in the current pane leave the state unchanged
+    pub fn apply_explorer_key_press(
+        &mut self,
+        graph: &InMemoryCodeGraphSnapshot,
+        key: &Key,
+        yank: &ExplorerYankPackSettings,
+        page_rows: usize,
+    ) -> ExplorerKeyOutcome {
+        if self.focus == ExplorerFocusedPane::Filter {
+            match key {
+                Key::Enter | Key::Escape | Key::Tab => self.focus = ExplorerFocusedPane::Symbols,
+                Key::Backspace => {
+                    self.filter_query.pop();
+                    self.apply_symbol_filter();
+                }
+                Key::Char(c) if !c.is_control() => {
+                    self.filter_query.push(*c);
+                    self.apply_symbol_filter();
+                }
+                Key::CtrlC => return ExplorerKeyOutcome::Quit,
+                _ => {}
+            }
+            return ExplorerKeyOutcome::Redraw;
+        }
+
+        let page_rows = page_rows.max(1);
+        match key {
+            Key::Char('q') | Key::Escape | Key::CtrlC => return ExplorerKeyOutcome::Quit,
+            Key::ArrowDown | Key::Char('j') => self.move_cursor(graph, 1),
+            Key::ArrowUp | Key::Char('k') => self.move_cursor(graph, -1),
+            Key::PageDown => self.move_cursor(graph, page_rows as isize),
+            Key::PageUp => self.move_cursor(graph, -(page_rows as isize)),
+            Key::Tab | Key::BackTab => {
+                self.focus = match self.focus {
+                    ExplorerFocusedPane::Symbols => ExplorerFocusedPane::Neighbours,
+                    _ => ExplorerFocusedPane::Symbols,
+                };
+            }
+            Key::Char('c') => self.show_direction(NeighbourPaneDirection::Callers),
+            Key::Char('e') => self.show_direction(NeighbourPaneDirection::Callees),
+            Key::Char('/') => {
+                self.focus = ExplorerFocusedPane::Filter;
+                self.status_message.clear();
+            }
+            Key::Enter | Key::ArrowRight | Key::Char('l') => self.walk_to_highlighted_neighbour(graph),
+            Key::Backspace | Key::ArrowLeft | Key::Char('h') => self.walk_back(),
+            Key::Char('y') => {
+                let Some(key) = self.selected_key.clone() else {
+                    return ExplorerKeyOutcome::Redraw;
+                };
+                let pack = pack_context_from_snapshot(graph, &key, yank.token_budget, yank.max_depth, yank.include_tests);
+                self.status_message = format!(
+                    "Yanked {} symbols, {} of {} tokens",
+                    pack.items.len(),
+                    pack.tokens_used,
+                    pack.token_budget
+                );
+                return ExplorerKeyOutcome::Yank(render_packed_context_text(&pack));
+            }
+            _ => {}
+        }
+        ExplorerKeyOutcome::Redraw
+    }
+
+    /// Overwrite the status line (e.g. where a yank went)
+    pub fn set_status_message(&mut self, message: String) {
+        self.status_message = message;
+    }
+
+    /// Screen contents for a `width` x `height` terminal
+    ///
+    /// # 4-Word Name: render_explorer_screen_lines
+    ///
+    /// # Contract
+    /// - Postcondition: exactly `height` lines (minimum 6), none wider than
+    ///   `width` characters; the highlighted row of the focused pane starts
+    ///   with `>`
+    pub fn render_explorer_screen_lines(
+        &self,
+        graph: &InMemoryCodeGraphSnapshot,
+        width: usize,
+        height: usize,
+    ) -> Vec<String> {
+        let width = width.max(20);
+        let height = height.max(6);
+        let body_rows = height - 4;
+        let left_width = (width * 2 / 5).max(10);
+        let right_width = width - left_width - 1;
+
+        let filter = match self.focus {
+            ExplorerFocusedPane::Filter => format!("  filter: /{}_", self.filter_query),
+            _ if !self.filter_query.is_empty() => format!("  filter: /{}", self.filter_query),
+            _ => String::new(),
+        };
+        let neighbours = self.neighbour_entries(graph);
+        let selected_name = self.selected_key.as_deref().map(|key| self.display_name(key)).unwrap_or_default();
+        let pane_title = match self.direction {
+            NeighbourPaneDirection::Callers => format!("Callers of {} ({})", selected_name, neighbours.len()),
+            NeighbourPaneDirection::Callees => format!("Callees of {} ({})", selected_name, neighbours.len()),
+        };
+
+        let mut lines = vec![fit_to_width(
+            &format!(
+                "parseltongue explore  {} of {} symbols{}",
+                self.visible_symbols.len(),
+                self.symbol_keys.len(),
+                filter
+            ),
+            width,
+        )];
+
+        let mut left = vec![fit_to_width("Symbols", left_width)];
+        let symbol_start = scroll_window_start(self.symbol_cursor, body_rows - 1);
+        for (row, &index) in self.visible_symbols.iter().enumerate().skip(symbol_start).take(body_rows - 1) {
+            let key = &self.symbol_keys[index];
+            let marker = if row == self.symbol_cursor && self.focus != ExplorerFocusedPane::Neighbours {
+                ">"
+            } else if self.selected_key.as_deref() == Some(key.as_str()) {
+                "*"
+            } else {
+                " "
+            };
+            left.push(fit_to_width(&format!("{} {}", marker, self.symbol_label(key)), left_width));
+        }
+
+        let mut right = vec![fit_to_width(&pane_title, right_width)];
+        let neighbour_start = scroll_window_start(self.neighbour_cursor, body_rows - 1);
+        for (row, entry) in neighbours.iter().enumerate().skip(neighbour_start).take(body_rows - 1) {
+            let marker = if row == self.neighbour_cursor && self.focus == ExplorerFocusedPane::Neighbours { ">" } else { " " };
+            let location = entry.source_location.as_deref().unwrap_or("");
+            right.push(fit_to_width(
+                &format!("{} {:<12} {}  {}", marker, entry.edge_type, self.display_name(&entry.key), location),
+                right_width,
+            ));
+        }
+
+        for row in 0..body_rows {
+            let l = left.get(row).cloned().unwrap_or_else(|| " ".repeat(left_width));
+            let r = right.get(row).map(String::as_str).unwrap_or("");
+            lines.push(format!("{}│{}", l, r).trim_end().to_string());
+        }
+
+        let details = match self.selected_key.as_deref() {
+            Some(key) => {
+                let trail = if self.walk_history.is_empty() {
+                    String::new()
+                } else {
+                    format!("  (back: {})", self.walk_history.len())
+                };
+                format!("{}{}", key, trail)
+            }
+            None => "No symbol selected".to_string(),
+        };
+        lines.push(fit_to_width(&details, width));
+        lines.push(fit_to_width(&self.status_message, width));
+        lines.push(fit_to_width(
+            "↑↓ move  Tab pane  c callers  e callees  Enter walk  ⌫ back  / filter  y yank  q quit",
+            width,
+        ));
+        lines
+    }
+
+    fn move_cursor(&mut self, graph: &InMemoryCodeGraphSnapshot, delta: isize) {
+        match self.focus {
+            ExplorerFocusedPane::Symbols => {
+                self.symbol_cursor = offset_cursor(self.symbol_cursor, delta, self.visible_symbols.len());
+                self.select_symbol_under_cursor();
+            }
+            ExplorerFocusedPane::Neighbours => {
+                let count = self.neighbour_entries(graph).len();
+                self.neighbour_cursor = offset_cursor(self.neighbour_cursor, delta, count);
+            }
+            ExplorerFocusedPane::Filter => {}
+        }
+    }
+
+    fn show_direction(&mut self, direction: NeighbourPaneDirection) {
+        self.direction = direction;
+        self.neighbour_cursor = 0;
+        self.focus = ExplorerFocusedPane::Neighbours;
+    }
+
+    fn walk_to_highlighted_neighbour(&mut self, graph: &InMemoryCodeGraphSnapshot) {
+        if self.focus != ExplorerFocusedPane::Neighbours {
+            self.focus = ExplorerFocusedPane::Neighbours;
+            return;
+        }
+        let Some(entry) = self.neighbour_entries(graph).into_iter().nth(self.neighbour_cursor) else {
+            return;
+        };
+        if let Some(current) = self.selected_key.take() {
+            self.walk_history.push(current);
+        }
+        self.select_key(&entry.key);
+        self.focus = ExplorerFocusedPane::Neighbours;
+    }
+
+    fn walk_back(&mut self) {
+        if let Some(previous) = self.walk_history.pop() {
+            self.select_key(&previous);
+            self.focus = ExplorerFocusedPane::Neighbours;
+        }
+    }
+
+    fn select_key(&mut self, key: &str) {
+        self.selected_key = Some(key.to_string());
+        self.neighbour_cursor = 0;
+        self.status_message.clear();
+        if let Some(row) = self.visible_symbols.iter().position(|&index| self.symbol_keys[index] == key) {
+            self.symbol_cursor = row;
+        }
+    }
+
+    fn select_symbol_under_cursor(&mut self) {
+        if let Some(&index) = self.visible_symbols.get(self.symbol_cursor) {
+            self.selected_key = Some(self.symbol_keys[index].clone());
+            self.neighbour_cursor = 0;
+            self.walk_history.clear();
+        }
+    }
+
+    fn apply_symbol_filter(&mut self) {
+        let needle = self.filter_query.to_lowercase();
+        self.visible_symbols = (0..self.symbol_keys.len())
+            .filter(|&index| needle.is_empty() || self.display_name(&self.symbol_keys[index]).to_lowercase().contains(&needle))
+            .collect();
+        self.symbol_cursor = 0;
+        self.select_symbol_under_cursor();
+    }
+
+    fn display_name(&self, key: &str) -> String {
+        self.nodes.get(key).map(|row| row.name.clone()).unwrap_or_else(|| key.to_string())
+    }
+
+    fn symbol_label(&self, key: &str) -> String {
+        match self.nodes.get(key) {
+            Some(row) if !row.file_path.is_empty() => {
+                format!("{} {}  {}:{}", row.kind, row.name, row.file_path, row.line_start)
+            }
+            Some(row) => format!("{} {}", row.kind, row.name),
+            None => key.to_string(),
+        }
+    }
+}
+
+/// Run the explorer on the controlling terminal until the user quits
+///
+/// # 4-Word Name: run_graph_explorer_terminal
+///
+/// # Contract
+/// - Precondition: stdout is an interactive terminal
+/// - Postcondition: `y` copies the pack to the system clipboard through the
+///   terminal (OSC 52) and, with `yank_file`, also writes it there
+pub fn run_graph_explorer_terminal(
+    graph: &InMemoryCodeGraphSnapshot,
+    start_key: Option<&str>,
+    yank: &ExplorerYankPackSettings,
+    yank_file: Option<&std::path::Path>,
+) -> Result<()> {
+    let term = Term::stdout();
+    if !term.is_term() {
+        bail!("explore needs an interactive terminal");
+    }
+    let mut session = GraphExplorerSessionState::create_explorer_session_state(graph, start_key);
+
+    term.hide_cursor()?;
+    let outcome = (|| -> Result<()> {
+        loop {
+            let (rows, cols) = term.size();
+            let lines = session.render_explorer_screen_lines(graph, cols as usize, rows as usize);
+            term.clear_screen()?;
+            term.write_str(&lines.join("\n"))?;
+            term.flush()?;
+
+            let key = term.read_key()?;
+            let page_rows = (rows as usize).saturating_sub(5);
+            match session.apply_explorer_key_press(graph, &key, yank, page_rows) {
+                ExplorerKeyOutcome::Redraw => {}
+                ExplorerKeyOutcome::Quit => return Ok(()),
+                ExplorerKeyOutcome::Yank(text) => {
+                    term.write_str(&format!("\x1b]52;c;{}\x07", encode_base64_text(text.as_bytes())))?;
+                    if let Some(path) = yank_file {
+                        std::fs::write(path, &text)?;
+                        let note = format!("Context pack copied and written to {}", path.display());
+                        session.set_status_message(note);
+                    }
+                }
+            }
+        }
+    })();
+    term.clear_screen()?;
+    term.show_cursor()?;
+    outcome
+}
+
+fn offset_cursor(cursor: usize, delta: isize, count: usize) -> usize {
+    if count == 0 {
+        return 0;
+    }
+    (cursor as isize + delta).clamp(0, count as isize - 1) as usize
+}
+
+fn scroll_window_start(cursor: usize, rows: usize) -> usize {
+    if rows == 0 || cursor < rows {
+        0
+    } else {
+        cursor + 1 - rows
+    }
+}
+
+fn fit_to_width(text: &str, width: usize) -> String {
+    let count = text.chars().count();
+    if count > width {
+        let mut cut: String = text.chars().take(width.saturating_sub(1)).collect();
+        cut.push('…');
+        cut
+    } else {
+        format!("{}{}", text, " ".repeat(width - count))
+    }
+}
+
+fn encode_base64_text(bytes: &[u8]) -> String {
+    const ALPHABET: &[u8; 64] = b"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+    let mut out = String::with_capacity((bytes.len() + 2) / 3 * 4);
+    for chunk in bytes.chunks(3) {
+        let b = [chunk[0], *chunk.get(1).unwrap_or(&0), *chunk.get(2).unwrap_or(&0)];
+        let n = (b[0] as u32) << 16 | (b[1] as u32) << 8 | b[2] as u32;
+        for i in 0..4 {
+            if i <= chunk.len() {
+                out.push(ALPHABET[(n >> (18 - 6 * i) & 63) as usize] as char);
+            } else {
+                out.push('=');
+            }
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::{DependencyEdge, EdgeType};
+
+    fn edge(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_keys_walk_callers_and_callees() {
+        let graph = InMemoryCodeGraphSnapshot::from_entities_and_edges(
+            Vec::new(),
+            vec![
+                edge("go:fn:main:cmd:T1", "go:fn:handle:api:T1"),
+                edge("go:fn:handle:api:T1", "go:fn:Save:store:T1"),
+                edge("go:fn:handle:api:T1", "go:fn:Printf:unresolved-reference:0-0"),
+            ],
+        );
+        let yank = ExplorerYankPackSettings::default();
+        let mut session = GraphExplorerSessionState::create_explorer_session_state(&graph, Some("go:fn:handle:api:T1"));
+        assert_eq!(session.visible_symbol_count(), 3, "placeholders are not listed");
+
+        session.apply_explorer_key_press(&graph, &Key::Char('e'), &yank, 10);
+        let callees: Vec<String> = session.neighbour_entries(&graph).into_iter().map(|entry| entry.key).collect();
+        assert_eq!(callees, vec!["go:fn:Printf:unresolved-reference:0-0", "go:fn:Save:store:T1"]);
+
+        session.apply_explorer_key_press(&graph, &Key::ArrowDown, &yank, 10);
+        session.apply_explorer_key_press(&graph, &Key::Enter, &yank, 10);
+        assert_eq!(session.selected_key(), Some("go:fn:Save:store:T1"));
+        session.apply_explorer_key_press(&graph, &Key::Char('c'), &yank, 10);
+        assert_eq!(session.neighbour_entries(&graph)[0].key, "go:fn:handle:api:T1");
+        session.apply_explorer_key_press(&graph, &Key::Backspace, &yank, 10);
+        assert_eq!(session.selected_key(), Some("go:fn:handle:api:T1"));
+
+        session.apply_explorer_key_press(&graph, &Key::Char('/'), &yank, 10);
+        for c in "mai".chars() {
+            session.apply_explorer_key_press(&graph, &Key::Char(c), &yank, 10);
+        }
+        assert_eq!(session.focus(), ExplorerFocusedPane::Filter);
+        assert_eq!(session.visible_symbol_count(), 1);
+        assert_eq!(session.selected_key(), Some("go:fn:main:cmd:T1"));
+
+        let lines = session.render_explorer_screen_lines(&graph, 60, 12);
+        assert_eq!(lines.len(), 12);
+        assert!(lines.iter().all(|line| line.chars().count() <= 60));
+        assert!(lines[0].contains("1 of 3 symbols") && lines[0].contains("/mai_"));
+
+        assert_eq!(encode_base64_text(b"ctx"), "Y3R4");
+        assert_eq!(encode_base64_text(b"pack"), "cGFjaw==");
+        assert_eq!(
+            session.apply_explorer_key_press(&graph, &Key::Escape, &yank, 10),
+            ExplorerKeyOutcome::Redraw
+        );
+        assert_eq!(session.apply_explorer_key_press(&graph, &Key::Char('q'), &yank, 10), ExplorerKeyOutcome::Quit);
+    }
+
+    #[test]
+    fn test_keys_at_boundaries_and_empty_panes() {
+        let graph = InMemoryCodeGraphSnapshot::from_entities_and_edges(
+            Vec::new(),
+            vec![edge("go:fn:main:cmd:T1", "go:fn:handle:api:T1"), edge("go:fn:handle:api:T1", "go:fn:Save:store:T1")],
+        );
+        let yank = ExplorerYankPackSettings::default();
+        let mut session = GraphExplorerSessionState::create_explorer_session_state(&graph, None);
+        let press = |session: &mut GraphExplorerSessionState, key: Key| {
+            session.apply_explorer_key_press(&graph, &key, &yank, 10)
+        };
+        assert_eq!(session.selected_key(), Some("go:fn:Save:store:T1"), "first symbol by name");
+
+        // Cursor clamps at both ends
+        press(&mut session, Key::ArrowUp);
+        assert_eq!(session.selected_key(), Some("go:fn:Save:store:T1"));
+        press(&mut session, Key::PageDown);
+        assert_eq!(session.selected_key(), Some("go:fn:main:cmd:T1"));
+
+        // Enter from the symbol list only focuses the neighbours; nothing to walk or go back to
+        press(&mut session, Key::Enter);
+        assert_eq!(session.focus(), ExplorerFocusedPane::Neighbours);
+        press(&mut session, Key::Enter);
+        press(&mut session, Key::Backspace);
+        assert_eq!(session.selected_key(), Some("go:fn:main:cmd:T1"));
+
+        press(&mut session, Key::Char('e'));
+        press(&mut session, Key::Enter);
+        assert_eq!(session.selected_key(), Some("go:fn:handle:api:T1"));
+        let lines = session.render_explorer_screen_lines(&graph, 80, 10);
+        assert!(lines[lines.len() - 3].contains("(back: 1)"));
+
+        // Picking from the symbol list starts a new walk
+        press(&mut session, Key::Tab);
+        press(&mut session, Key::ArrowUp);
+        assert_eq!(session.selected_key(), Some("go:fn:Save:store:T1"));
+        let lines = session.render_explorer_screen_lines(&graph, 80, 10);
+        assert!(!lines[lines.len() - 3].contains("back:"));
+
+        // A filter matching nothing keeps the selection; control characters are ignored
+        press(&mut session, Key::Char('/'));
+        for key in [Key::Char('z'), Key::Char('\u{7}'), Key::Char('z')] {
+            press(&mut session, key);
+        }
+        assert_eq!(session.visible_symbol_count(), 0);
+        assert_eq!(session.selected_key(), Some("go:fn:Save:store:T1"));
+        press(&mut session, Key::Backspace);
+        press(&mut session, Key::Backspace);
+        assert_eq!(session.visible_symbol_count(), 3);
+        press(&mut session, Key::Tab);
+        assert_eq!(session.focus(), ExplorerFocusedPane::Symbols);
+
+        assert!(matches!(press(&mut session, Key::Char('y')), ExplorerKeyOutcome::Yank(_)));
+        let lines = session.render_explorer_screen_lines(&graph, 80, 10);
+        assert!(lines[lines.len() - 2].starts_with("Yanked"));
+        press(&mut session, Key::Char('/'));
+        assert_eq!(press(&mut session, Key::CtrlC), ExplorerKeyOutcome::Quit);
+
+        // An empty graph selects nothing, yanks nothing and renders at minimum size
+        let empty = InMemoryCodeGraphSnapshot::from_entities_and_edges(Vec::new(), Vec::new());
+        let mut idle = GraphExplorerSessionState::create_explorer_session_state(&empty, None);
+        assert_eq!(idle.selected_key(), None);
+        assert_eq!(idle.apply_explorer_key_press(&empty, &Key::Char('y'), &yank, 10), ExplorerKeyOutcome::Redraw);
+        let lines = idle.render_explorer_screen_lines(&empty, 5, 2);
+        assert_eq!(lines.len(), 6);
+        assert!(lines.iter().all(|line| line.chars().count() <= 20));
+        assert!(lines[3].starts_with("No symbol selected"));
+    }
+
+    #[test]
+    fn test_layout_helpers_edge_cases() {
+        assert_eq!(fit_to_width("abcdef", 4), "abc…");
+        assert_eq!(fit_to_width("ab", 4), "ab  ");
+        assert_eq!(fit_to_width("→→", 2), "→→");
+        assert_eq!(offset_cursor(0, -1, 0), 0);
+        assert_eq!(offset_cursor(2, 5, 3), 2);
+        assert_eq!(scroll_window_start(4, 5), 0);
+        assert_eq!(scroll_window_start(7, 5), 3);
+        assert_eq!(encode_base64_text(b""), "");
+        assert_eq!(encode_base64_text(b"hello"), "aGVsbG8=");
+    }
+}
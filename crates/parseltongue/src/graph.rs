@@ -0,0 +1,193 @@
+//! Query engine API (v1.7.3)
+//!
+//! # 4-Word Naming: graph
+//!
+//! A loaded graph with key/adjacency indexes. Traversals are the same
+//! functions the CLI and HTTP server use (`filtered_graph_traversal_queries`),
+//! so results match `parseltongue blast-radius` / `path` exactly.
+
+use std::collections::{HashMap, HashSet};
+
+use anyhow::Result;
+use parseltongue_core::entities::{CodeEntity, DependencyEdge, EdgeType};
+use parseltongue_core::filtered_graph_traversal_queries::{
+    all_edge_type_variants_list, compute_filtered_blast_radius, find_shortest_dependency_path,
+};
+use parseltongue_core::storage::CozoDbStorage;
+
+pub use parseltongue_core::filtered_graph_traversal_queries::{AffectedEntityHopEntry, DependencyPathHopEntry};
+
+/// Entities and edges of one graph, indexed for lookups
+///
+/// # 4-Word Name: InMemoryCodeGraphSnapshot
+#[derive(Debug, Clone, Default)]
+pub struct InMemoryCodeGraphSnapshot {
+    entities: Vec<CodeEntity>,
+    edges: Vec<DependencyEdge>,
+    entity_index_by_key: HashMap<String, usize>,
+    outgoing_edge_indexes: HashMap<String, Vec<usize>>,
+    incoming_edge_indexes: HashMap<String, Vec<usize>>,
+}
+
+impl InMemoryCodeGraphSnapshot {
+    /// Index a graph you already hold (e.g. from `ingest::parse_directory_into_memory`)
+    pub fn from_entities_and_edges(entities: Vec<CodeEntity>, edges: Vec<DependencyEdge>) -> Self {
+        let entity_index_by_key = entities
+            .iter()
+            .enumerate()
+            .map(|(index, entity)| (entity.isgl1_key.clone(), index))
+            .collect();
+        let mut outgoing_edge_indexes: HashMap<String, Vec<usize>> = HashMap::new();
+        let mut incoming_edge_indexes: HashMap<String, Vec<usize>> = HashMap::new();
+        for (index, edge) in edges.iter().enumerate() {
+            outgoing_edge_indexes.entry(edge.from_key.as_str().to_string()).or_default().push(index);
+            incoming_edge_indexes.entry(edge.to_key.as_str().to_string()).or_default().push(index);
+        }
+        Self {
+            entities,
+            edges,
+            entity_index_by_key,
+            outgoing_edge_indexes,
+            incoming_edge_indexes,
+        }
+    }
+
+    /// Load every entity (with extractor metadata) and edge of a database
+    pub async fn load_from_database(db_path: &str) -> Result<Self> {
+        let storage = CozoDbStorage::new(db_path).await?;
+        Self::load_from_storage(&storage).await
+    }
+
+    /// Same as `load_from_database`, over an open connection
+    pub async fn load_from_storage(storage: &CozoDbStorage) -> Result<Self> {
+        let entities = storage.get_all_entities_with_metadata().await?;
+        let edges = storage.get_all_dependencies().await?;
+        Ok(Self::from_entities_and_edges(entities, edges))
+    }
+
+    pub fn entities(&self) -> &[CodeEntity] {
+        &self.entities
+    }
+
+    pub fn edges(&self) -> &[DependencyEdge] {
+        &self.edges
+    }
+
+    /// Entity by ISGL1 key; None for unknown and external/placeholder keys
+    pub fn entity(&self, key: &str) -> Option<&CodeEntity> {
+        self.entity_index_by_key.get(key).map(|&index| &self.entities[index])
+    }
+
+    /// Entities whose name is exactly `name`, sorted by key
+    pub fn find_entities_by_name(&self, name: &str) -> Vec<&CodeEntity> {
+        self.sorted_entities_where(|entity| entity.interface_signature.name == name)
+    }
+
+    /// Entities whose name contains `fragment` (case-insensitive), sorted by key
+    pub fn search_entities_by_name(&self, fragment: &str) -> Vec<&CodeEntity> {
+        let needle = fragment.to_lowercase();
+        self.sorted_entities_where(|entity| entity.interface_signature.name.to_lowercase().contains(&needle))
+    }
+
+    /// Edges pointing at `key`, sorted by source key
+    pub fn callers_of(&self, key: &str) -> Vec<&DependencyEdge> {
+        let mut edges = self.edges_at(&self.incoming_edge_indexes, key);
+        edges.sort_by(|a, b| a.from_key.as_str().cmp(b.from_key.as_str()));
+        edges
+    }
+
+    /// Edges leaving `key`, sorted by target key
+    pub fn callees_of(&self, key: &str) -> Vec<&DependencyEdge> {
+        let mut edges = self.edges_at(&self.outgoing_edge_indexes, key);
+        edges.sort_by(|a, b| a.to_key.as_str().cmp(b.to_key.as_str()));
+        edges
+    }
+
+    /// Everything that transitively depends on `key` within `hops`
+    ///
+    /// # Contract
+    /// - Postcondition: as `compute_filtered_blast_radius`; an empty
+    ///   `edge_types` follows every edge type
+    pub fn blast_radius(&self, key: &str, hops: usize, edge_types: &[EdgeType]) -> Vec<AffectedEntityHopEntry> {
+        compute_filtered_blast_radius(&self.edges, key, hops, &allowed_or_all_types(edge_types))
+    }
+
+    /// Fewest-hop dependency chain `from -> ... -> to`, if any within `max_depth`
+    pub fn shortest_path(
+        &self,
+        from: &str,
+        to: &str,
+        max_depth: usize,
+        edge_types: &[EdgeType],
+    ) -> Option<Vec<DependencyPathHopEntry>> {
+        let targets: HashSet<String> = std::iter::once(to.to_string()).collect();
+        find_shortest_dependency_path(&self.edges, from, &targets, max_depth, &allowed_or_all_types(edge_types))
+    }
+
+    fn edges_at(&self, index: &HashMap<String, Vec<usize>>, key: &str) -> Vec<&DependencyEdge> {
+        index
+            .get(key)
+            .map(|indexes| indexes.iter().map(|&i| &self.edges[i]).collect())
+            .unwrap_or_default()
+    }
+
+    fn sorted_entities_where(&self, keep: impl Fn(&CodeEntity) -> bool) -> Vec<&CodeEntity> {
+        let mut matches: Vec<&CodeEntity> = self.entities.iter().filter(|entity| keep(entity)).collect();
+        matches.sort_by(|a, b| a.isgl1_key.cmp(&b.isgl1_key));
+        matches
+    }
+}
+
+fn allowed_or_all_types(edge_types: &[EdgeType]) -> Vec<EdgeType> {
+    if edge_types.is_empty() {
+        all_edge_type_variants_list()
+    } else {
+        edge_types.to_vec()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type).build().unwrap()
+    }
+
+    fn sample_graph() -> InMemoryCodeGraphSnapshot {
+        InMemoryCodeGraphSnapshot::from_entities_and_edges(
+            Vec::new(),
+            vec![
+                edge("go:fn:handle:api:T1", "go:fn:Save:store:T1", EdgeType::Calls),
+                edge("go:fn:main:cmd:T1", "go:fn:handle:api:T1", EdgeType::Calls),
+                edge("go:fn:audit:api:T1", "go:fn:Save:store:T1", EdgeType::Uses),
+            ],
+        )
+    }
+
+    #[test]
+    fn test_neighbor_queries_use_edge_indexes() {
+        let graph = sample_graph();
+        let callers: Vec<&str> = graph.callers_of("go:fn:Save:store:T1").into_iter().map(|e| e.from_key.as_str()).collect();
+        assert_eq!(callers, vec!["go:fn:audit:api:T1", "go:fn:handle:api:T1"]);
+        assert_eq!(graph.callees_of("go:fn:main:cmd:T1").len(), 1);
+        assert!(graph.callees_of("go:fn:Save:store:T1").is_empty());
+        assert!(graph.entity("go:fn:Save:store:T1").is_none());
+    }
+
+    #[test]
+    fn test_blast_radius_and_path_filters() {
+        let graph = sample_graph();
+        let all: Vec<(String, usize)> = graph
+            .blast_radius("go:fn:Save:store:T1", 3, &[])
+            .into_iter()
+            .map(|hit| (hit.entity_key, hit.depth))
+            .collect();
+        assert_eq!(all.len(), 3);
+        assert_eq!(all[2], ("go:fn:main:cmd:T1".to_string(), 2));
+
+        assert_eq!(graph.blast_radius("go:fn:Save:store:T1", 3, &[EdgeType::Calls]).len(), 2);
+        let path = graph.shortest_path("go:fn:main:cmd:T1", "go:fn:Save:store:T1", 5, &[]).unwrap();
+        assert_eq!(path.len(), 2);
+    }
+}
@@ -0,0 +1,339 @@
+//! Indexer API (v1.7.3)
+//!
+//! # 4-Word Naming: index_directory_into_database
+//!
+//! Runs the same pipeline as `parseltongue pt01-folder-to-cozodb-streamer`:
+//! every file tree-sitter understands is parsed, common build/vendor
+//! directories and git-ignored files are skipped, and entities/edges land
+//! in a CozoDB database.
+//! `parse_directory_into_memory` skips the database entirely.
+
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use std::time::Duration;
+
+use anyhow::Result;
+use parseltongue_core::external_plugin_process_protocol::ExternalPluginProcessSpec;
+use parseltongue_core::go_build_constraint_evaluator::GoBuildContextConfig;
+use parseltongue_core::rust_cfg_feature_evaluator::RustFeatureSelectionConfig;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+use pt01_folder_to_cozodb_streamer::project_path_filter_rules::ProjectPathFilterRules;
+use pt01_folder_to_cozodb_streamer::sharded_ingest_graph_merger::IngestDirectoryShardSpec;
+use pt01_folder_to_cozodb_streamer::streamer::{FileStreamer, StreamResult};
+use pt01_folder_to_cozodb_streamer::{StreamerConfig, ToolFactory};
+
+use crate::config::ProjectConfigFileSpec;
+use crate::graph::InMemoryCodeGraphSnapshot;
+
+/// Directories never worth indexing
+const DEFAULT_EXCLUDED_DIRECTORY_NAMES: &[&str] =
+    &["target", "node_modules", ".git", "build", "dist", "__pycache__", ".venv", "venv"];
+
+/// Options of one ingest run
+///
+/// # 4-Word Name: DirectoryIngestRunOptions
+///
+/// Built with `for_directory` and `with_*` methods so new options can be
+/// added without breaking callers.
+#[derive(Debug, Clone)]
+pub struct DirectoryIngestRunOptions {
+    root_dir: PathBuf,
+    db_path: String,
+    go_build_context: GoBuildContextConfig,
+    rust_features: RustFeatureSelectionConfig,
+    git_blame: bool,
+    worker_threads: usize,
+    path_filter_rules: Option<ProjectPathFilterRules>,
+    respect_gitignore: bool,
+    extra_exclude_globs: Vec<String>,
+    allow_globs: Vec<String>,
+    quarantine_parse_failures: bool,
+    progress_output: IngestProgressOutputMode,
+    parse_cache_dir: Option<PathBuf>,
+    shard: Option<IngestDirectoryShardSpec>,
+    go_typed_resolution: bool,
+    plugins: Vec<ExternalPluginProcessSpec>,
+}
+
+impl DirectoryIngestRunOptions {
+    /// Index `root_dir` into an in-memory database (`mem`)
+    pub fn for_directory(root_dir: impl Into<PathBuf>) -> Self {
+        Self {
+            root_dir: root_dir.into(),
+            db_path: "mem".to_string(),
+            go_build_context: GoBuildContextConfig::default(),
+            rust_features: RustFeatureSelectionConfig::default(),
+            git_blame: false,
+            worker_threads: 0,
+            path_filter_rules: None,
+            respect_gitignore: true,
+            extra_exclude_globs: Vec::new(),
+            allow_globs: Vec::new(),
+            quarantine_parse_failures: false,
+            progress_output: IngestProgressOutputMode::Bar,
+            parse_cache_dir: None,
+            shard: None,
+            go_typed_resolution: false,
+            plugins: Vec::new(),
+        }
+    }
+
+    /// Database engine spec, e.g. `rocksdb:mycode.db` or `sqlite:mycode.db`
+    pub fn with_database(mut self, db_path: impl Into<String>) -> Self {
+        self.db_path = db_path.into();
+        self
+    }
+
+    /// GOOS/GOARCH/tags selecting build-constrained Go files (default: host)
+    pub fn with_go_build_context(mut self, go_build_context: GoBuildContextConfig) -> Self {
+        self.go_build_context = go_build_context;
+        self
+    }
+
+    /// Cargo `--features` / `--all-features` / `--no-default-features` for
+    /// `#[cfg(feature = ...)]` items (default: none given, every item indexed)
+    pub fn with_rust_features(mut self, rust_features: RustFeatureSelectionConfig) -> Self {
+        self.rust_features = rust_features;
+        self
+    }
+
+    /// Annotate entities with `git blame` ownership (default: off)
+    pub fn with_git_blame(mut self, git_blame: bool) -> Self {
+        self.git_blame = git_blame;
+        self
+    }
+
+    /// Parse worker threads; 0 = GOMAXPROCS or available parallelism
+    pub fn with_worker_threads(mut self, worker_threads: usize) -> Self {
+        self.worker_threads = worker_threads;
+        self
+    }
+
+    /// Languages, globs and directory overrides of a project config file
+    ///
+    /// # Contract
+    /// - Precondition: called after `for_directory` with the final root
+    ///   (config globs are resolved against it)
+    pub fn with_project_config(mut self, config: &ProjectConfigFileSpec) -> Self {
+        self.path_filter_rules = config.to_path_filter_rules(&self.root_dir);
+        if let Some(gitignore) = config.gitignore {
+            self.respect_gitignore = gitignore;
+        }
+        self.plugins = config.resolved_plugin_specs();
+        self
+    }
+
+    /// Skip files ignored by `.gitignore` / `.git/info/exclude` (default: on)
+    pub fn with_gitignore(mut self, respect_gitignore: bool) -> Self {
+        self.respect_gitignore = respect_gitignore;
+        self
+    }
+
+    /// Extra exclude globs (`--exclude`), on top of the project config's
+    pub fn with_exclude_globs(mut self, globs: impl IntoIterator<Item = String>) -> Self {
+        self.extra_exclude_globs.extend(globs);
+        self
+    }
+
+    /// Globs indexed even when excluded or git-ignored (`--allow`), e.g. generated code
+    pub fn with_allow_globs(mut self, globs: impl IntoIterator<Item = String>) -> Self {
+        self.allow_globs.extend(globs);
+        self
+    }
+
+    /// Skip and report files whose parse fails or panics (`--quarantine`)
+    /// instead of letting a parser panic abort the ingest (default: off)
+    pub fn with_quarantine(mut self, quarantine: bool) -> Self {
+        self.quarantine_parse_failures = quarantine;
+        self
+    }
+
+    /// Progress as a TTY bar, JSON lines on stderr (`--progress=json`), or
+    /// not at all (default: bar)
+    pub fn with_progress_output(mut self, progress_output: IngestProgressOutputMode) -> Self {
+        self.progress_output = progress_output;
+        self
+    }
+
+    /// Reuse per-file parse artifacts stored in `cache_dir` by earlier runs,
+    /// of any repository (`--parse-cache`, default: off)
+    pub fn with_parse_cache_dir(mut self, cache_dir: Option<PathBuf>) -> Self {
+        self.parse_cache_dir = cache_dir;
+        self
+    }
+
+    /// Ingest only one shard's directories (`--shard I/N`), leaving
+    /// cross-file resolution to `parseltongue merge` (default: whole tree)
+    pub fn with_shard(mut self, shard: Option<IngestDirectoryShardSpec>) -> Self {
+        self.shard = shard;
+        self
+    }
+
+    /// Rebind Go calls with the Go type checker (`--typed`): slower, needs
+    /// a Go toolchain, exact method sets and selectors (default: off)
+    pub fn with_go_typed_resolution(mut self, go_typed_resolution: bool) -> Self {
+        self.go_typed_resolution = go_typed_resolution;
+        self
+    }
+
+    /// External extractor/enricher processes (default: the config's
+    /// `[[plugins]]`, none without a config)
+    pub fn with_plugins(mut self, plugins: Vec<ExternalPluginProcessSpec>) -> Self {
+        self.plugins = plugins;
+        self
+    }
+
+    pub fn root_dir(&self) -> &Path {
+        &self.root_dir
+    }
+
+    pub fn db_path(&self) -> &str {
+        &self.db_path
+    }
+
+    /// Streamer configuration the CLI would use for these options
+    pub fn to_streamer_config(&self) -> StreamerConfig {
+        StreamerConfig {
+            root_dir: self.root_dir.clone(),
+            db_path: self.db_path.clone(),
+            max_file_size: 100 * 1024 * 1024, // 100MB - no artificial limits
+            include_patterns: vec!["*".to_string()], // ALL files - tree-sitter handles it
+            exclude_patterns: DEFAULT_EXCLUDED_DIRECTORY_NAMES.iter().map(|d| d.to_string()).collect(),
+            parsing_library: "tree-sitter".to_string(),
+            chunking: "ISGL1".to_string(),
+            go_build_context: self.go_build_context.clone(),
+            rust_features: self.rust_features.clone(),
+            git_blame: self.git_blame,
+            worker_threads: self.worker_threads,
+            path_filter_rules: self.merged_path_filter_rules(),
+            respect_gitignore: self.respect_gitignore,
+            quarantine_parse_failures: self.quarantine_parse_failures,
+            progress_output: self.progress_output,
+            parse_cache_dir: self.parse_cache_dir.clone(),
+            shard: self.shard,
+            go_typed_resolution: self.go_typed_resolution,
+            plugins: self.plugins.clone(),
+        }
+    }
+
+    fn merged_path_filter_rules(&self) -> Option<ProjectPathFilterRules> {
+        if self.extra_exclude_globs.is_empty() && self.allow_globs.is_empty() {
+            return self.path_filter_rules.clone();
+        }
+        let mut rules = self.path_filter_rules.clone().unwrap_or_default();
+        rules.project_rules.exclude_globs.extend(self.extra_exclude_globs.iter().cloned());
+        rules.allow_globs.extend(self.allow_globs.iter().cloned());
+        Some(rules)
+    }
+}
+
+/// Outcome of an ingest run
+///
+/// # 4-Word Name: DirectoryIngestRunSummary
+#[derive(Debug, Clone)]
+pub struct DirectoryIngestRunSummary {
+    pub total_files: usize,
+    pub processed_files: usize,
+    pub entities_created: usize,
+    /// Per-file failures; the run itself still succeeded
+    pub errors: Vec<String>,
+    pub duration: Duration,
+    /// Milliseconds per phase: `scan`, `parse`, `resolve`, `store`
+    pub phase_timings_ms: BTreeMap<&'static str, u64>,
+    /// Bytes of source parsed
+    pub input_bytes: u64,
+}
+
+impl From<StreamResult> for DirectoryIngestRunSummary {
+    fn from(result: StreamResult) -> Self {
+        Self {
+            total_files: result.total_files,
+            processed_files: result.processed_files,
+            entities_created: result.entities_created,
+            errors: result.errors,
+            duration: result.duration,
+            phase_timings_ms: result.phase_timings_ms,
+            input_bytes: result.input_bytes,
+        }
+    }
+}
+
+/// Full parallel ingest into the configured database
+///
+/// # 4-Word Name: index_directory_into_database
+///
+/// # Contract
+/// - Postcondition: the database holds the directory's entities and edges,
+///   cross-file passes included, exactly as after the CLI ingest
+pub async fn index_directory_into_database(options: &DirectoryIngestRunOptions) -> Result<DirectoryIngestRunSummary> {
+    let streamer = ToolFactory::create_streamer(options.to_streamer_config()).await?;
+    Ok(streamer.stream_directory_with_parallel_rayon().await?.into())
+}
+
+/// Re-ingest only files whose content hash changed since the last run
+///
+/// # 4-Word Name: reindex_changed_files_only
+///
+/// # Contract
+/// - Precondition: the database was built from the same root directory
+pub async fn reindex_changed_files_only(options: &DirectoryIngestRunOptions) -> Result<DirectoryIngestRunSummary> {
+    let streamer = ToolFactory::create_streamer(options.to_streamer_config()).await?;
+    Ok(streamer.stream_directory_incremental_by_hash().await?.into())
+}
+
+/// Parse a directory straight into an in-memory graph, without a database
+///
+/// # 4-Word Name: parse_directory_into_memory
+///
+/// # Contract
+/// - Postcondition: per-file entities and edges in sorted file order; the
+///   cross-file passes (Go embedding/interfaces, import resolution) need the
+///   stored graph and are not applied — use `index_directory_into_database`
+///   plus `InMemoryCodeGraphSnapshot::load_from_database` when they matter
+pub async fn parse_directory_into_memory(options: &DirectoryIngestRunOptions) -> Result<InMemoryCodeGraphSnapshot> {
+    let mut config = options.to_streamer_config();
+    config.db_path = "mem".to_string();
+    let streamer = ToolFactory::create_streamer(config).await?;
+
+    let (mut entities, mut edges) = (Vec::new(), Vec::new());
+    streamer.for_each_parsed_file_batch(|file_entities, file_edges| {
+        entities.extend_from_slice(file_entities);
+        edges.extend_from_slice(file_edges);
+        Ok(())
+    })?;
+    Ok(InMemoryCodeGraphSnapshot::from_entities_and_edges(entities, edges))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_options_map_to_cli_streamer_config() {
+        let config = DirectoryIngestRunOptions::for_directory("repo")
+            .with_database("rocksdb:repo.db")
+            .with_worker_threads(4)
+            .to_streamer_config();
+        assert_eq!(config.root_dir, PathBuf::from("repo"));
+        assert_eq!(config.db_path, "rocksdb:repo.db");
+        assert_eq!(config.include_patterns, vec!["*".to_string()]);
+        assert!(config.exclude_patterns.contains(&"node_modules".to_string()));
+        assert_eq!(config.worker_threads, 4);
+        assert!(!config.git_blame);
+        assert!(config.path_filter_rules.is_none());
+        assert!(config.respect_gitignore);
+    }
+
+    #[test]
+    fn test_cli_globs_merge_into_filter_rules() {
+        let config = DirectoryIngestRunOptions::for_directory("repo")
+            .with_gitignore(false)
+            .with_exclude_globs(vec!["*.pb.go".to_string()])
+            .with_allow_globs(vec!["build/gen/**".to_string()])
+            .to_streamer_config();
+        let rules = config.path_filter_rules.unwrap();
+        assert!(!config.respect_gitignore);
+        assert!(!rules.allows_ingest_relative_path(Path::new("api/user.pb.go")));
+        assert!(rules.is_allow_listed_path(Path::new("build/gen/client.go")));
+    }
+}
@@ -0,0 +1,47 @@
+//! # parseltongue (library)
+//!
+//! v1.7.3: Embeddable API over the indexer and query engine, for tools that
+//! want to build and query the code graph in-process instead of shelling out
+//! to the CLI and parsing stdout. The CLI itself is built on these modules.
+//!
+//! | Module | Purpose |
+//! |--------|---------|
+//! | [`ingest`] | Index a directory into a database, or parse it into memory |
+//! | [`bench`] | Repeated ingest with throughput, phase timings and peak RSS |
+//! | [`graph`] | Load a graph; callers, callees, blast radius, paths, search |
+//! | [`context`] | Token-budgeted context packs around a symbol |
+//! | [`config`] | `.parseltongue.toml` / `.companion.yaml` project defaults |
+//! | [`explore`] | Terminal symbol/neighbour explorer behind `parseltongue explore` |
+//!
+//! ```no_run
+//! # async fn demo() -> anyhow::Result<()> {
+//! use parseltongue::{context, graph::InMemoryCodeGraphSnapshot, ingest};
+//!
+//! let options = ingest::DirectoryIngestRunOptions::for_directory("./my-project")
+//!     .with_database("rocksdb:mycode.db");
+//! ingest::index_directory_into_database(&options).await?;
+//!
+//! let graph = InMemoryCodeGraphSnapshot::load_from_database("rocksdb:mycode.db").await?;
+//! let seed = graph.find_entities_by_name("Save")[0].isgl1_key.clone();
+//! let impact = graph.blast_radius(&seed, 3, &[]);
+//! let pack = context::pack_context_from_snapshot(&graph, &seed, 4000, 2, false);
+//! println!("{} affected, {} tokens of context", impact.len(), pack.tokens_used);
+//! # Ok(())
+//! # }
+//! ```
+//!
+//! ## Stability
+//!
+//! Items reachable from these modules follow this crate's semver.
+//! The `parseltongue_core` / `pt01_*` / `pt08_*` crates underneath are
+//! implementation crates and may change between minor versions; the entity
+//! and edge types are re-exported here so callers need not depend on them.
+
+pub mod bench;
+pub mod config;
+pub mod context;
+pub mod explore;
+pub mod graph;
+pub mod ingest;
+
+pub use parseltongue_core::entities::{CodeEntity, DependencyEdge, EdgeType, EntityType};
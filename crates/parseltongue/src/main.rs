@@ -3,16 +3,43 @@
 //! This binary provides subcommands that dispatch to the individual tools:
 //! - pt01-folder-to-cozodb-streamer (Tool 1: Ingest)
 //! - pt08-http-code-query-server (Tool 8: HTTP Server - primary interface)
+//! - daemon (v1.7.3: watch workspace, serve a hot in-memory graph)
+//! - pt09-mcp-stdio-tool-server (v1.7.3: MCP tools for agentic LLM clients)
+//! - lsp (v1.7.3: read-only language server over the graph)
+//! - pack-context (v1.7.3: token-budgeted context bundle for one symbol)
+//! - body-chunk (v1.7.3: one chunk of an oversized body left out of a pack)
+//! - context (v1.7.3: token-budgeted context bundle for a git diff)
+//! - review-pack (v1.7.3: markdown review artifact for a git diff)
+//! - graph-diff (v1.7.3: nodes and edges added/removed/changed between two snapshots)
+//! - blast-radius (v1.7.3: transitive impact with edge-type filters)
+//! - path (v1.7.3: shortest dependency chain between two symbols)
+//! - hierarchy (v1.7.3: IDE-style call hierarchy tree)
+//! - type-hierarchy (v1.7.3: supertypes and subtypes of a type)
+//! - uses-of (v1.7.3: where a type is used, by usage kind)
+//! - boundary-paths (v1.7.3: call paths into unsafe/FFI/cgo code)
+//! - error-flow (v1.7.3: where a Go error can surface to callers)
+//! - panic-flow (v1.7.3: whether a Go panic is recovered before main)
+//! - export (v1.7.3: whole-graph export, GraphML or clustered DOT)
+//! - visualize (v1.7.3: bounded subgraph diagram, Mermaid, DOT or interactive HTML)
+//! - explore (v1.7.3: terminal explorer - symbols, callers/callees, yank a context pack)
+//! - subgraph (v1.7.3: packages plus frontier layers as a standalone snapshot)
+//! - analyze (v1.7.3: whole-graph analyses - dead code, cycles, unresolved references, context propagation)
+//! - query (v1.7.3: stored graph queries - centrality rank, fuzzy symbol find, HTTP routes, wire fields)
+//! - bench (v1.7.3: repeated ingest with throughput and phase timings)
+//! - cache (v1.7.3: size and garbage collection of the --parse-cache directory)
+//! - merge (v1.7.3: union --shard databases and resolve cross-shard edges)
+//! - federate (v1.7.3: join several repositories' indexes, binding cross-repo calls)
 
-use clap::{Arg, ArgMatches, Command};
+mod commands;
+
+use clap::Command;
 use console::style;
 use anyhow::Result;
 
-// Import traits to enable trait methods
-use pt01_folder_to_cozodb_streamer::streamer::FileStreamer;
-
-// Import HTTP server types
-use pt08_http_code_query_server::{HttpServerStartupConfig, http_server_startup_runner};
+use commands::{
+    analyze_commands, context_commands, export_commands, history_commands, ingest_commands, maintenance_commands,
+    query_commands, search_commands, server_commands, traversal_commands,
+};
 
 #[tokio::main]
 async fn main() -> Result<()> {
@@ -20,10 +47,123 @@ async fn main() -> Result<()> {
 
     match matches.subcommand() {
         Some(("pt01-folder-to-cozodb-streamer", sub_matches)) => {
-            run_folder_to_cozodb_streamer(sub_matches).await
+            ingest_commands::run_folder_to_cozodb_streamer(sub_matches).await
         }
         Some(("pt08-http-code-query-server", sub_matches)) => {
-            run_http_code_query_server(sub_matches).await
+            server_commands::run_http_code_query_server(sub_matches).await
+        }
+        Some(("daemon", sub_matches)) => {
+            server_commands::run_workspace_watch_daemon(sub_matches).await
+        }
+        Some(("pt09-mcp-stdio-tool-server", sub_matches)) => {
+            server_commands::run_mcp_stdio_tool_server(sub_matches).await
+        }
+        Some(("lsp", sub_matches)) => {
+            server_commands::run_lsp_graph_navigation_server(sub_matches).await
+        }
+        Some(("pack-context", sub_matches)) => {
+            context_commands::run_pack_context_command(sub_matches).await
+        }
+        Some(("body-chunk", sub_matches)) => {
+            context_commands::run_body_chunk_command(sub_matches).await
+        }
+        Some(("context", sub_matches)) => {
+            context_commands::run_diff_context_command(sub_matches).await
+        }
+        Some(("review-pack", sub_matches)) => {
+            context_commands::run_review_pack_command(sub_matches).await
+        }
+        Some(("api-diff", sub_matches)) => {
+            context_commands::run_api_diff_command(sub_matches).await
+        }
+        Some(("graph-diff", sub_matches)) => {
+            history_commands::run_graph_diff_command(sub_matches).await
+        }
+        Some(("report", sub_matches)) => match sub_matches.subcommand() {
+            Some(("delta", delta_matches)) => history_commands::run_graph_delta_report_command(delta_matches).await,
+            _ => anyhow::bail!("Unknown report; see `parseltongue report --help`"),
+        },
+        Some(("history", sub_matches)) => match sub_matches.subcommand() {
+            Some(("record", record_matches)) => history_commands::run_history_record_command(record_matches).await,
+            _ => history_commands::run_symbol_history_command(sub_matches).await,
+        },
+        Some(("blast-radius", sub_matches)) => {
+            traversal_commands::run_blast_radius_command(sub_matches).await
+        }
+        Some(("path", sub_matches)) => {
+            traversal_commands::run_shortest_path_command(sub_matches).await
+        }
+        Some(("hierarchy", sub_matches)) => {
+            traversal_commands::run_call_hierarchy_command(sub_matches).await
+        }
+        Some(("type-hierarchy", sub_matches)) => {
+            traversal_commands::run_type_hierarchy_command(sub_matches).await
+        }
+        Some(("uses-of", sub_matches)) => {
+            traversal_commands::run_type_uses_of_command(sub_matches).await
+        }
+        Some(("boundary-paths", sub_matches)) => {
+            traversal_commands::run_boundary_paths_command(sub_matches).await
+        }
+        Some(("error-flow", sub_matches)) => {
+            traversal_commands::run_error_flow_command(sub_matches).await
+        }
+        Some(("panic-flow", sub_matches)) => {
+            traversal_commands::run_panic_flow_command(sub_matches).await
+        }
+        Some(("q", sub_matches)) => {
+            traversal_commands::run_graph_query_dsl_command(sub_matches).await
+        }
+        Some(("concurrent", sub_matches)) => {
+            traversal_commands::run_concurrent_downstream_command(sub_matches).await
+        }
+        Some(("export", sub_matches)) => {
+            export_commands::run_graph_export_command(sub_matches).await
+        }
+        Some(("visualize", sub_matches)) => {
+            export_commands::run_subgraph_visualize_command(sub_matches).await
+        }
+        Some(("explore", sub_matches)) => {
+            export_commands::run_graph_explorer_command(sub_matches).await
+        }
+        Some(("slice", sub_matches)) => {
+            export_commands::run_entry_point_slice_command(sub_matches).await
+        }
+        Some(("subgraph", sub_matches)) => {
+            export_commands::run_package_subgraph_command(sub_matches).await
+        }
+        Some(("embed", sub_matches)) => {
+            search_commands::run_symbol_embedding_command(sub_matches).await
+        }
+        Some(("summarize", sub_matches)) => {
+            search_commands::run_symbol_summarize_command(sub_matches).await
+        }
+        Some(("search", sub_matches)) => {
+            search_commands::run_semantic_search_command(sub_matches).await
+        }
+        Some(("analyze", sub_matches)) => {
+            analyze_commands::run_graph_analyze_command(sub_matches).await
+        }
+        Some(("query", sub_matches)) => {
+            query_commands::run_graph_query_command(sub_matches).await
+        }
+        Some(("bench", sub_matches)) => {
+            maintenance_commands::run_benchmark_command(sub_matches).await
+        }
+        Some(("cache", sub_matches)) => match sub_matches.subcommand() {
+            Some(("verify", verify_matches)) => {
+                maintenance_commands::run_derived_cache_verify_command(verify_matches).await
+            }
+            _ => maintenance_commands::run_parse_cache_command(sub_matches),
+        },
+        Some(("merge", sub_matches)) => {
+            maintenance_commands::run_shard_merge_command(sub_matches).await
+        }
+        Some(("federate", sub_matches)) => {
+            maintenance_commands::run_repository_federation_command(sub_matches).await
+        }
+        Some(("capabilities", sub_matches)) => {
+            maintenance_commands::run_extractor_capabilities_command(sub_matches).await
         }
         _ => {
             println!("{}", style("Parseltongue CLI Toolkit").blue().bold());
@@ -34,6 +174,48 @@ async fn main() -> Result<()> {
             println!("Available commands:");
             println!("  pt01-folder-to-cozodb-streamer       - Index codebase into CozoDB");
             println!("  pt08-http-code-query-server              - HTTP server for REST API (15 endpoints)");
+            println!("  daemon                                   - Watch workspace, keep graph hot in memory");
+            println!("  pt09-mcp-stdio-tool-server               - MCP server for LLM clients (stdio)");
+            println!("  lsp                                      - Read-only language server: definition, references, call hierarchy");
+            println!("  pack-context                             - Context bundle for a symbol within a token budget");
+            println!("  body-chunk                               - Fetch a chunk of a body a context pack cut short");
+            println!("  context                                  - Context bundle for a git change (--since / --diff)");
+            println!("  review-pack                              - Markdown review artifact for a git change");
+            println!("  api-diff                                 - Exported symbols added/removed/changed between two revisions");
+            println!("  graph-diff                               - Nodes/edges added, removed or changed between two graph snapshots (JSON)");
+            println!("  history                                  - Per-commit graph snapshots; when a symbol appeared, changed, gained callers");
+            println!("  blast-radius                             - Everything affected by changing a symbol");
+            println!("  path                                     - Shortest dependency chain from A to B");
+            println!("  hierarchy                                - Call hierarchy tree (callers or callees) with file:line");
+            println!("  type-hierarchy                           - Supertypes/subtypes: interface satisfiers, embedders, trait impls");
+            println!("  uses-of                                  - Where a type is used: fields, params, returns, vars, assertions");
+            println!("  boundary-paths                           - Call paths from an entry point into unsafe, FFI or cgo code");
+            println!("  error-flow                               - Where a Go error or sentinel can surface, returned or wrapped");
+            println!("  panic-flow                               - Whether a panic is recovered before reaching main or a goroutine entry");
+            println!("  q                                        - Graph query language (callers(X) & in_package(\"api\"))");
+            println!("  concurrent                               - Everything run on goroutines downstream of a symbol");
+            println!("  export                                   - Export the dependency graph (GraphML, DOT)");
+            println!("  visualize                                - Diagram of the subgraph around a symbol");
+            println!("  explore                                  - Terminal explorer: walk callers/callees, yank context");
+            println!("  subgraph                                 - Selected packages plus frontier as a standalone snapshot");
+            println!("  embed                                    - Compute symbol embeddings for semantic search");
+            println!("  search                                   - Find symbols by meaning (\"how do we hash passwords\")");
+            println!("  analyze dead-code                        - Symbols nothing in the graph references");
+            println!("  analyze cycles                           - Package/symbol cycles and edges that break them");
+            println!("  analyze rename                           - Files/lines a rename must touch (no edits)");
+            println!("  analyze unresolved                       - References the graph could not bind, by cause");
+            println!("  analyze context-propagation              - Go functions dropping or replacing their context.Context");
+            println!("  query rank                               - Most central symbols (PageRank + betweenness)");
+            println!("  query stale                              - Symbols untouched for N days (git blame)");
+            println!("  query graph-file                         - Callers/callees from an mmapped binary graph");
+            println!("  query find                               - Fuzzy symbol search (usrSvc.creat -> UserService.CreateUser)");
+            println!("  query route                              - Handler code behind an endpoint (GET /users/42)");
+            println!("  query wire-field                         - Structs serializing a field name (created_at)");
+            println!("  bench ingest                             - Repeated ingest: files/s, MB/s, phase timings, peak RSS (JSON)");
+            println!("  cache stats | cache gc                   - Size of the --parse-cache directory / evict old artifacts");
+            println!("  merge                                    - Combine --shard databases into one index");
+            println!("  federate                                 - Join several repositories into one index");
+            println!("  capabilities                             - Node kinds, edge types and versions of each language's extractors");
             Ok(())
         }
     }
@@ -46,232 +228,16 @@ fn build_cli() -> Command {
         .about("Ultra-minimalist CLI toolkit for code analysis")
         .subcommand_required(false)
         .arg_required_else_help(false)
-        .subcommand(
-            Command::new("pt01-folder-to-cozodb-streamer")
-                .about("Tool 1: Stream folder contents to CozoDB with ISGL1 keys")
-                .long_about(
-                    "Examples:\n  \
-                    parseltongue pt01-folder-to-cozodb-streamer .            # Index current directory\n  \
-                    parseltongue pt01-folder-to-cozodb-streamer ./src --db rocksdb:analysis.db --verbose"
-                )
-                .arg(
-                    Arg::new("directory")
-                        .help("Directory to index [default: current directory]")
-                        .default_value(".")
-                        .index(1),
-                )
-                .arg(
-                    Arg::new("db")
-                        .long("db")
-                        .help("Database file path")
-                        .default_value("parseltongue.db"),
-                )
-                .arg(
-                    Arg::new("verbose")
-                        .long("verbose")
-                        .short('v')
-                        .help("Enable verbose output")
-                        .action(clap::ArgAction::SetTrue),
-                )
-                .arg(
-                    Arg::new("quiet")
-                        .long("quiet")
-                        .short('q')
-                        .help("Suppress output")
-                        .action(clap::ArgAction::SetTrue),
-                ),
-        )
-        .subcommand(
-            Command::new("pt08-http-code-query-server")
-                .about("Tool 8: HTTP server for code queries (REST API)")
-                .long_about(
-                    "Start an HTTP server exposing CozoDB queries via REST endpoints.\n\n\
-                    File watching is always enabled - code changes are automatically reindexed.\n\n\
-                    Examples:\n  \
-                    parseltongue pt08-http-code-query-server --db rocksdb:analysis.db\n  \
-                    parseltongue pt08-http-code-query-server --port 7777 --db rocksdb:analysis.db"
-                )
-                .arg(
-                    Arg::new("port")
-                        .long("port")
-                        .short('p')
-                        .help("Port to listen on [default: 7777]"),
-                )
-                .arg(
-                    Arg::new("db")
-                        .long("db")
-                        .help("Database file path (rocksdb:path or mem)")
-                        .default_value("mem"),
-                )
-                .arg(
-                    Arg::new("verbose")
-                        .long("verbose")
-                        .short('v')
-                        .help("Enable verbose logging")
-                        .action(clap::ArgAction::SetTrue),
-                ),
-        )
-}
-
-async fn run_folder_to_cozodb_streamer(matches: &ArgMatches) -> Result<()> {
-    let directory = matches.get_one::<String>("directory").unwrap();
-    let db = matches.get_one::<String>("db").unwrap();
-    let verbose = matches.get_flag("verbose");
-    let quiet = matches.get_flag("quiet");
-
-    // Create timestamped workspace directory
-    let timestamp = chrono::Local::now().format("%Y%m%d%H%M%S").to_string();
-    let workspace_dir = format!("parseltongue{}", timestamp);
-    std::fs::create_dir_all(&workspace_dir)?;
-
-    // Construct database path within workspace
-    let (workspace_db_path, backup_target): (String, Option<String>) = if db == "mem" {
-        ("mem".to_string(), None)
-    } else {
-        // v1.7.2: Windows uses in-memory ingestion + SQLite backup (avoids Defender filesystem interference)
-        // Mac/Linux uses RocksDB directly (fastest, no issues)
-        #[cfg(target_os = "windows")]
-        {
-            println!("  Engine: {} (in-memory ingestion, SQLite backup)", style("mem→SQLite").green());
-            ("mem".to_string(), Some(format!("{}/analysis.db", workspace_dir)))
-        }
-        #[cfg(not(target_os = "windows"))]
-        {
-            (format!("rocksdb:{}/analysis.db", workspace_dir), None)
-        }
-    };
-
-    println!("{}", style("Running Tool 1: folder-to-cozodb-streamer").cyan());
-    if !quiet {
-        println!("  Workspace: {}", style(&workspace_dir).yellow().bold());
-        println!("  Database: {}", &workspace_db_path);
-    }
-
-    // Create config (S01 ultra-minimalist: let tree-sitter decide what to parse)
-    let config = pt01_folder_to_cozodb_streamer::StreamerConfig {
-        root_dir: std::path::PathBuf::from(directory),
-        db_path: workspace_db_path.clone(),
-        max_file_size: 100 * 1024 * 1024,  // 100MB - no artificial limits
-        include_patterns: vec!["*".to_string()],  // ALL files - tree-sitter handles it
-        exclude_patterns: vec![
-            "target".to_string(),
-            "node_modules".to_string(),
-            ".git".to_string(),
-            "build".to_string(),
-            "dist".to_string(),
-            "__pycache__".to_string(),
-            ".venv".to_string(),
-            "venv".to_string(),
-        ],
-        parsing_library: "tree-sitter".to_string(),
-        chunking: "ISGL1".to_string(),
-    };
-
-    // Create and run streamer
-    let streamer = pt01_folder_to_cozodb_streamer::ToolFactory::create_streamer(config.clone()).await?;
-    // Phase 5: Use parallel streaming by default for better performance
-    let result = streamer.stream_directory_with_parallel_rayon().await?;
-
-    // v1.7.2: Windows - backup in-memory database to SQLite file
-    if let Some(ref target) = backup_target {
-        if !quiet {
-            println!("  {} Saving database to disk...", style("↓").cyan());
-        }
-        streamer.backup_to_sqlite(target).await?;
-        if !quiet {
-            println!("  {} Database saved: {}", style("✓").green(), style(target).yellow());
-        }
-    }
-
-    // Write ingestion error log
-    {
-        use std::io::Write;
-        let error_log_path = format!("{}/ingestion-errors.txt", workspace_dir);
-        let mut error_file = std::fs::File::create(&error_log_path)?;
-        writeln!(error_file, "# Parseltongue Ingestion Error Log")?;
-        writeln!(error_file, "# Generated: {}", chrono::Local::now().format("%Y-%m-%dT%H:%M:%S"))?;
-        writeln!(error_file, "# Database: {}", workspace_db_path)?;
-        writeln!(error_file, "# Source: {}", directory)?;
-        writeln!(error_file, "# Total files: {}, Processed: {}, Errors: {}",
-            result.total_files, result.processed_files, result.errors.len())?;
-        writeln!(error_file, "#")?;
-        if result.errors.is_empty() {
-            writeln!(error_file, "# No errors encountered during ingestion.")?;
-        } else {
-            for error in &result.errors {
-                writeln!(error_file, "{}", error)?;
-            }
-        }
-    }
-
-    // Determine display path for next command
-    let display_db_path = if let Some(target) = &backup_target {
-        format!("sqlite:{}", target)
-    } else {
-        workspace_db_path.clone()
-    };
-
-    if !quiet {
-        println!("{}", style("✓ Indexing completed").green().bold());
-        println!("  Files processed: {}", result.processed_files);
-        println!("  Entities created: {}", result.entities_created);
-        if !result.errors.is_empty() {
-            println!("  Errors: {} (see {}/ingestion-errors.txt)", result.errors.len(), workspace_dir);
-        }
-        println!();
-        println!("{}", style("📁 Workspace location:").green().bold());
-        println!("  {}", style(&workspace_dir).yellow().bold());
-        println!();
-        println!("{}", style("Database:").green().bold());
-        println!("  {}", style(&display_db_path).yellow());
-        println!();
-        println!("{}", style("Next step:").cyan());
-        println!("  parseltongue pt08-http-code-query-server \\");
-        println!("    --db \"{}\"", display_db_path);
-        println!();
-        println!("{}", style("Quick test:").cyan());
-        println!("  curl http://localhost:7777/server-health-check-status");
-        println!("  curl http://localhost:7777/codebase-statistics-overview-summary");
-        if verbose {
-            println!("  Duration: {:?}", result.duration);
-        }
-    }
-
-    Ok(())
-}
-
-/// Run the HTTP server for code queries
-///
-/// # 4-Word Name: run_http_code_query_server
-async fn run_http_code_query_server(matches: &ArgMatches) -> Result<()> {
-    let port = matches.get_one::<String>("port");
-    let db = matches.get_one::<String>("db").unwrap();
-    let verbose = matches.get_flag("verbose");
-
-    println!("{}", style("Running Tool 8: HTTP Code Query Server").cyan());
-    if verbose {
-        if let Some(p) = port {
-            println!("  Port: {}", p);
-        } else {
-            println!("  Port: 7777 (default)");
-        }
-        println!("  Database: {}", db);
-        println!("  File watching: always enabled (v1.4.2+)");
-    }
-
-    // Build configuration
-    let config = HttpServerStartupConfig {
-        target_directory_path_value: std::path::PathBuf::from("."),
-        database_connection_string_value: db.clone(),
-        http_port_override_option: port.and_then(|p| p.parse().ok()),
-        force_reindex_enabled_flag: false,
-        daemon_background_mode_flag: false,
-        idle_timeout_minutes_option: None,
-        verbose_logging_enabled_flag: verbose,
-    };
-
-    // Start the server (blocks until shutdown)
-    http_server_startup_runner::start_http_server_blocking_loop(config).await
+        .subcommands(ingest_commands::ingest_subcommand_definition_list())
+        .subcommands(server_commands::server_subcommand_definition_list())
+        .subcommands(context_commands::context_subcommand_definition_list())
+        .subcommands(history_commands::history_subcommand_definition_list())
+        .subcommands(traversal_commands::traversal_subcommand_definition_list())
+        .subcommands(export_commands::export_subcommand_definition_list())
+        .subcommands(search_commands::search_subcommand_definition_list())
+        .subcommands(analyze_commands::analyze_subcommand_definition_list())
+        .subcommands(query_commands::query_subcommand_definition_list())
+        .subcommands(maintenance_commands::maintenance_subcommand_definition_list())
 }
 
 #[cfg(test)]
@@ -285,6 +251,33 @@ mod tests {
         let subcommands: Vec<&str> = cli.get_subcommands().map(|cmd| cmd.get_name()).collect();
         assert!(subcommands.contains(&"pt01-folder-to-cozodb-streamer")); // Ingest
         assert!(subcommands.contains(&"pt08-http-code-query-server")); // HTTP server (primary)
+        assert!(subcommands.contains(&"daemon")); // v1.7.3: watch daemon
+        assert!(subcommands.contains(&"pt09-mcp-stdio-tool-server")); // v1.7.3: MCP
+        assert!(subcommands.contains(&"lsp")); // v1.7.3: LSP facade
+        assert!(subcommands.contains(&"pack-context")); // v1.7.3: token-budgeted context
+        assert!(subcommands.contains(&"body-chunk")); // v1.7.3: chunks left out of a pack
+        assert!(subcommands.contains(&"blast-radius")); // v1.7.3: filtered impact
+        assert!(subcommands.contains(&"path")); // v1.7.3: shortest dependency chain
+        assert!(subcommands.contains(&"hierarchy")); // v1.7.3: call hierarchy tree
+        assert!(subcommands.contains(&"type-hierarchy")); // v1.7.3: supertypes/subtypes
+        assert!(subcommands.contains(&"uses-of")); // v1.7.3: type usages by kind
+        assert!(subcommands.contains(&"boundary-paths")); // v1.7.3: unsafe/FFI crossings
+        assert!(subcommands.contains(&"error-flow")); // v1.7.3: Go error propagation
+        assert!(subcommands.contains(&"panic-flow")); // v1.7.3: Go panic/recover
+        assert!(subcommands.contains(&"q")); // v1.7.3: graph query language
+        assert!(subcommands.contains(&"concurrent")); // v1.7.3: goroutine reachability
+        assert!(subcommands.contains(&"export")); // v1.7.3: graph export formats
+        assert!(subcommands.contains(&"visualize")); // v1.7.3: subgraph diagrams
+        assert!(subcommands.contains(&"explore")); // v1.7.3: terminal explorer
+        assert!(subcommands.contains(&"subgraph")); // v1.7.3: package subgraph snapshot
+        assert!(subcommands.contains(&"embed")); // v1.7.3: symbol embeddings
+        assert!(subcommands.contains(&"search")); // v1.7.3: semantic search
+        assert!(subcommands.contains(&"bench")); // v1.7.3: ingest benchmark
+        assert!(subcommands.contains(&"cache")); // v1.7.3: parse cache stats/gc
+        assert!(subcommands.contains(&"merge")); // v1.7.3: sharded ingest merge
+        assert!(subcommands.contains(&"federate")); // v1.7.3: multi-repository index
+        assert!(subcommands.contains(&"graph-diff")); // v1.7.3: snapshot graph diff
+        assert!(subcommands.contains(&"history")); // v1.7.3: per-commit snapshots, symbol timeline
         // Note: pt02 (JSON export) and pt07 (terminal viz) removed in v1.0.3
         // All visualization available via HTTP endpoints
         // Note: v1.4.2+ - File watching is always enabled, no CLI flags needed
@@ -118,6 +118,18 @@ Patterns are simple substring matches (not regex)."),
             exclude_patterns,
             parsing_library: "tree-sitter".to_string(),
             chunking: "ISGL1".to_string(),
+            go_build_context: Default::default(),
+            rust_features: Default::default(),
+            git_blame: false,
+            worker_threads: 0,
+            path_filter_rules: None,
+            respect_gitignore: true,
+            quarantine_parse_failures: false,
+            progress_output: Default::default(),
+            parse_cache_dir: None,
+            shard: None,
+            go_typed_resolution: false,
+            plugins: Vec::new(),
         }
     }
 
@@ -54,6 +54,13 @@ pub enum StreamerError {
     UnsupportedFileType {
         path: String,
     },
+
+    /// File skipped by `--quarantine` after its parse failed or panicked (v1.7.3)
+    #[error("Quarantined: {path} - {cause}")]
+    ParseQuarantined {
+        path: String,
+        cause: String,
+    },
 }
 
 impl From<StreamerError> for ParseltongError {
@@ -567,12 +567,14 @@ mod tests {
                 to_key: Isgl1Key::new("rust:module:Parser:external-dependency-clap:0-0").unwrap(),
                 edge_type: EdgeType::Uses,
                 source_location: None,
+                metadata: Default::default(),
             },
             DependencyEdge {
                 from_key: Isgl1Key::new("rust:fn:build_cli:src/cli.rs:5-10").unwrap(),
                 to_key: Isgl1Key::new("rust:module:Parser:external-dependency-clap:0-0").unwrap(), // Same external dep
                 edge_type: EdgeType::Uses,
                 source_location: None,
+                metadata: Default::default(),
             },
         ];
 
@@ -596,12 +598,14 @@ mod tests {
                 to_key: Isgl1Key::new("rust:fn:helper:src/utils.rs:5-10").unwrap(), // Local dependency
                 edge_type: EdgeType::Calls,
                 source_location: None,
+                metadata: Default::default(),
             },
             DependencyEdge {
                 from_key: Isgl1Key::new("rust:fn:main:src/main.rs:12-13").unwrap(),
                 to_key: Isgl1Key::new("rust:module:Parser:external-dependency-clap:0-0").unwrap(), // External
                 edge_type: EdgeType::Uses,
                 source_location: None,
+                metadata: Default::default(),
             },
         ];
 
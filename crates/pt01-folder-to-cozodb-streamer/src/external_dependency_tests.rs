@@ -256,6 +256,7 @@ mod external_dependency_placeholder_tests {
                 to_key: Isgl1Key::new("rust:module:Parser:external-dependency-clap:0-0").unwrap(),
                 edge_type: EdgeType::Uses,
                 source_location: None,
+                metadata: Default::default(),
             },
             // Unresolved reference pattern (edges now use :unresolved-reference:0-0)
             DependencyEdge {
@@ -263,6 +264,7 @@ mod external_dependency_placeholder_tests {
                 to_key: Isgl1Key::new("rust:fn:build_cli:unresolved-reference:0-0").unwrap(),
                 edge_type: EdgeType::Calls,
                 source_location: None,
+                metadata: Default::default(),
             },
             // Another unresolved reference pattern
             DependencyEdge {
@@ -270,6 +272,7 @@ mod external_dependency_placeholder_tests {
                 to_key: Isgl1Key::new("rust:struct:Config:unresolved-reference:0-0").unwrap(),
                 edge_type: EdgeType::Uses,
                 source_location: None,
+                metadata: Default::default(),
             },
         ];
 
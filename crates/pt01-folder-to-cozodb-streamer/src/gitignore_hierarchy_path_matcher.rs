@@ -0,0 +1,187 @@
+//! .gitignore honoring for the directory walk (v1.7.3)
+//!
+//! # 4-Word Naming: gitignore_hierarchy_path_matcher
+//!
+//! Files git ignores (vendored trees, build output, generated bundles) are
+//! skipped by default. Every `.gitignore` between the ingest root and a file
+//! applies, plus `.git/info/exclude` at the root, with git's rules:
+//!
+//! - the last matching line wins; `!pattern` re-includes
+//! - `dir/` matches directories only; a pattern with a `/` other than a
+//!   trailing one is anchored to its `.gitignore`'s directory, otherwise it
+//!   matches a name at any depth
+//! - a file inside an ignored directory stays ignored, whatever deeper
+//!   negations say
+//!
+//! `.gitignore` files are read lazily, once per directory.
+
+use std::collections::HashMap;
+use std::path::{Path, PathBuf};
+use std::sync::{Arc, Mutex};
+
+use parseltongue_core::serializers::graph_export_node_table::matches_path_glob_pattern;
+
+/// One non-comment line of a `.gitignore`
+///
+/// # 4-Word Name: GitignoreRuleLineEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct GitignoreRuleLineEntry {
+    /// Glob with `!`, trailing `/` and leading `/` removed
+    pub pattern: String,
+    pub negated: bool,
+    pub directory_only: bool,
+    /// Matched against the path relative to the `.gitignore`'s directory
+    pub anchored: bool,
+}
+
+/// Parse `.gitignore` text into rule lines
+///
+/// # 4-Word Name: parse_gitignore_rule_lines
+pub fn parse_gitignore_rule_lines(text: &str) -> Vec<GitignoreRuleLineEntry> {
+    text.lines()
+        .filter_map(|line| {
+            let line = line.trim_end();
+            if line.is_empty() || line.starts_with('#') {
+                return None;
+            }
+            let (negated, line) = match line.strip_prefix('!') {
+                Some(rest) => (true, rest),
+                None => (false, line.strip_prefix('\\').unwrap_or(line)),
+            };
+            let directory_only = line.ends_with('/');
+            let line = line.trim_end_matches('/');
+            let anchored = line.contains('/');
+            let pattern = line.trim_start_matches('/');
+            (!pattern.is_empty()).then(|| GitignoreRuleLineEntry {
+                pattern: pattern.to_string(),
+                negated,
+                directory_only,
+                anchored,
+            })
+        })
+        .collect()
+}
+
+/// Lazily loaded `.gitignore` rules below one ingest root
+///
+/// # 4-Word Name: GitignoreHierarchyPathMatcher
+#[derive(Debug)]
+pub struct GitignoreHierarchyPathMatcher {
+    root_dir: PathBuf,
+    enabled: bool,
+    rules_by_directory: Mutex<HashMap<PathBuf, Arc<Vec<GitignoreRuleLineEntry>>>>,
+}
+
+impl GitignoreHierarchyPathMatcher {
+    /// Matcher for `root_dir`; a disabled one ignores nothing
+    pub fn for_ingest_root(root_dir: &Path, enabled: bool) -> Self {
+        Self {
+            root_dir: root_dir.to_path_buf(),
+            enabled,
+            rules_by_directory: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// Whether git would ignore `file_path` (a file under the ingest root)
+    ///
+    /// # 4-Word Name: is_path_git_ignored
+    ///
+    /// # Contract
+    /// - Postcondition: false when disabled or when the path is not under
+    ///   the ingest root
+    pub fn is_path_git_ignored(&self, file_path: &Path) -> bool {
+        if !self.enabled {
+            return false;
+        }
+        let Ok(relative) = file_path.strip_prefix(&self.root_dir) else {
+            return false;
+        };
+        let components: Vec<String> = relative
+            .components()
+            .filter_map(|component| match component {
+                std::path::Component::Normal(name) => Some(name.to_string_lossy().into_owned()),
+                _ => None,
+            })
+            .collect();
+
+        // Directories first: nothing below an ignored directory comes back
+        (1..=components.len()).any(|depth| {
+            let is_directory = depth < components.len();
+            self.is_prefix_ignored(&components[..depth], is_directory)
+        })
+    }
+
+    fn is_prefix_ignored(&self, components: &[String], is_directory: bool) -> bool {
+        let mut ignored = false;
+        // Rules of every directory above the entry, shallow to deep
+        for owner_depth in 0..components.len() {
+            let owner_dir = components[..owner_depth].iter().fold(self.root_dir.clone(), |dir, c| dir.join(c));
+            let relative = components[owner_depth..].join("/");
+            let name = &components[components.len() - 1];
+            for rule in self.rules_for_directory(&owner_dir, owner_depth == 0).iter() {
+                if rule.directory_only && !is_directory {
+                    continue;
+                }
+                let target = if rule.anchored { relative.as_str() } else { name.as_str() };
+                if matches_path_glob_pattern(target, &format!("/{}", rule.pattern)) {
+                    ignored = !rule.negated;
+                }
+            }
+        }
+        ignored
+    }
+
+    fn rules_for_directory(&self, directory: &Path, is_root: bool) -> Arc<Vec<GitignoreRuleLineEntry>> {
+        let mut cache = self.rules_by_directory.lock().unwrap_or_else(|poisoned| poisoned.into_inner());
+        cache
+            .entry(directory.to_path_buf())
+            .or_insert_with(|| {
+                let mut text = std::fs::read_to_string(directory.join(".gitignore")).unwrap_or_default();
+                if is_root {
+                    // info/exclude has lower precedence than .gitignore
+                    let exclude = std::fs::read_to_string(directory.join(".git/info/exclude")).unwrap_or_default();
+                    text = format!("{}\n{}", exclude, text);
+                }
+                Arc::new(parse_gitignore_rule_lines(&text))
+            })
+            .clone()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parse_gitignore_rule_lines() {
+        let rules = parse_gitignore_rule_lines("# deps\nvendor/\n/build\n!keep.go\ndocs/*.html\n\n");
+        assert_eq!(rules.len(), 4);
+        assert!(rules[0].directory_only && !rules[0].anchored);
+        assert!(rules[1].anchored && rules[1].pattern == "build");
+        assert!(rules[2].negated);
+        assert!(rules[3].anchored && rules[3].pattern == "docs/*.html");
+    }
+
+    #[test]
+    fn test_nested_gitignore_and_negation() {
+        let root = tempfile::tempdir().unwrap();
+        std::fs::write(root.path().join(".gitignore"), "vendor/\n*.gen.go\n!keep.gen.go\n/out\n").unwrap();
+        std::fs::create_dir_all(root.path().join("svc/assets")).unwrap();
+        std::fs::write(root.path().join("svc/.gitignore"), "assets/\n").unwrap();
+
+        let matcher = GitignoreHierarchyPathMatcher::for_ingest_root(root.path(), true);
+        let ignored = |relative: &str| matcher.is_path_git_ignored(&root.path().join(relative));
+        assert!(ignored("vendor/x/y.go"));
+        assert!(ignored("svc/vendor/y.go"));
+        assert!(ignored("svc/api.gen.go"));
+        assert!(!ignored("svc/keep.gen.go"));
+        assert!(ignored("out/main.js"));
+        assert!(!ignored("svc/out/main.js"));
+        assert!(ignored("svc/assets/app.js"));
+        assert!(!ignored("assets/app.js"));
+        assert!(!ignored("svc/api.go"));
+
+        let disabled = GitignoreHierarchyPathMatcher::for_ingest_root(root.path(), false);
+        assert!(!disabled.is_path_git_ignored(&root.path().join("vendor/x/y.go")));
+    }
+}
@@ -0,0 +1,235 @@
+//! Incremental graph relink planner (v1.7.3)
+//!
+//! # 4-Word Naming: incremental_graph_relink_planner
+//!
+//! An incremental ingest re-runs the repo-wide cross-file passes (includes,
+//! FFI, Go annotation/tests/lineage, dispatch, proto, SQL, config, plugins,
+//! routes, enrichers, confidence) over the stored graph plus the re-parsed
+//! files, exactly as a full ingest does. Before the run, edges those passes
+//! append are taken out of the stored graph so they are derived afresh, and
+//! nodes they synthesize from non-source files are dropped so they are
+//! regenerated. The difference between the result and the stored graph is
+//! all the ingest writes. Contract files only those passes read (`.proto`,
+//! `.sql`, YAML/JSON config, plugin-extracted files) are hashed like
+//! sources, so editing one is a change worth relinking.
+
+use std::collections::HashMap;
+
+use parseltongue_core::dynamic_dispatch_call_expander::DISPATCH_METADATA_KEY;
+use parseltongue_core::entities::{DependencyEdge, EdgeType};
+use parseltongue_core::external_plugin_process_protocol::PLUGIN_SOURCE_METADATA_KEY;
+use parseltongue_core::ffi_symbol_bridge_linker::{FFI_BRIDGE_METADATA_KEY, FFI_SYMBOL_METADATA_KEY};
+use parseltongue_core::go_embedding_promotion_resolver::{
+    revert_go_resolved_edge_targets, UNRESOLVED_TARGET_METADATA_KEY,
+};
+use parseltongue_core::protobuf_service_definition_linker::GRPC_STUB_RESOLUTION_METADATA_VALUE;
+
+/// Key language segments of `.proto`, `.sql` and YAML/JSON nodes
+const SYNTHESIZED_ENTITY_LANGUAGE_SEGMENTS: &[&str] = &["proto", "sql", "yaml", "json"];
+
+/// Extensions of the non-source files the repo-wide passes read
+pub const CONTRACT_FILE_EXTENSIONS: &[&str] = &[".proto", ".sql", ".yaml", ".yml", ".json"];
+
+/// Edge identity in the store: (from, to, type)
+type StoredEdgeIdentityKey = (String, String, &'static str);
+
+/// True for edges a repo-wide pass appends rather than rewrites
+///
+/// # 4-Word Name: is_repo_wide_pass_edge
+///
+/// # Contract
+/// - Postcondition: Go test coverage, generated-file lineage, config
+///   references, cgo preamble includes, dynamic dispatch fan-out, gRPC stub
+///   links, Rust extern declaration bridges and plugin edges
+/// - Postcondition: rewritten placeholders (include, FFI call, SQL table,
+///   route handler bindings) are not listed; they keep `unresolved_target`
+pub fn is_repo_wide_pass_edge(edge: &DependencyEdge) -> bool {
+    match edge.edge_type {
+        EdgeType::Tests | EdgeType::GeneratedFrom | EdgeType::ConfiguredBy => return true,
+        EdgeType::Includes if edge.from_key.as_str().starts_with("go:file:") => return true,
+        _ => {}
+    }
+    edge.metadata_value(DISPATCH_METADATA_KEY).is_some()
+        || edge.metadata_value(PLUGIN_SOURCE_METADATA_KEY).is_some()
+        || edge.metadata_value("resolution") == Some(GRPC_STUB_RESOLUTION_METADATA_VALUE)
+        || (edge.metadata_value(FFI_BRIDGE_METADATA_KEY).is_some()
+            && edge.metadata_value(UNRESOLVED_TARGET_METADATA_KEY).is_none())
+}
+
+/// True for nodes a repo-wide pass builds from non-source files
+///
+/// # 4-Word Name: is_pass_synthesized_entity_key
+///
+/// # Contract
+/// - Precondition: `plugin_languages` = key segments of extracting plugins
+/// - Postcondition: `proto:`, `sql:`, `yaml:`, `json:` and plugin keys
+pub fn is_pass_synthesized_entity_key(key: &str, plugin_languages: &[&str]) -> bool {
+    let Some((language, _)) = key.split_once(':') else {
+        return false;
+    };
+    SYNTHESIZED_ENTITY_LANGUAGE_SEGMENTS.contains(&language) || plugin_languages.contains(&language)
+}
+
+/// True when `path` is a contract file of one of `extensions`
+///
+/// # 4-Word Name: is_contract_file_path
+///
+/// # Contract
+/// - Precondition: `extensions` carry the leading dot (`.proto`)
+/// - Postcondition: suffix match, as the passes' own file discovery does
+pub fn is_contract_file_path(path: &str, extensions: &[String]) -> bool {
+    extensions.iter().any(|extension| path.ends_with(extension.as_str()))
+}
+
+/// Point bound edges back at their placeholder, for any resolver
+///
+/// # 4-Word Name: revert_bound_edge_targets
+///
+/// # Contract
+/// - Postcondition: as `revert_go_resolved_edge_targets`; reverted FFI
+///   bridges also lose `ffi_bridge` / `ffi_symbol`, so they no longer read
+///   as appended extern declaration edges
+/// - Returns: number of edges reverted
+pub fn revert_bound_edge_targets(edges: &mut [DependencyEdge]) -> usize {
+    let mut reverted = 0;
+    for edge in edges.iter_mut() {
+        if revert_go_resolved_edge_targets(std::slice::from_mut(edge)) == 0 {
+            continue;
+        }
+        edge.metadata.remove(FFI_BRIDGE_METADATA_KEY);
+        edge.metadata.remove(FFI_SYMBOL_METADATA_KEY);
+        reverted += 1;
+    }
+    reverted
+}
+
+/// Edges to delete and to write so the store matches `relinked`
+///
+/// # 4-Word Name: diff_relinked_edge_sets
+///
+/// # Contract
+/// - Postcondition: `.0` = stored edges whose (from, to, type) is not in
+///   `relinked`
+/// - Postcondition: `.1` = relinked edges not stored verbatim; of several
+///   with one identity the last is kept, as a batch `:put` would
+pub fn diff_relinked_edge_sets(
+    stored: &[DependencyEdge],
+    relinked: Vec<DependencyEdge>,
+) -> (Vec<DependencyEdge>, Vec<DependencyEdge>) {
+    let identity = |edge: &DependencyEdge| -> StoredEdgeIdentityKey {
+        (edge.from_key.as_str().to_string(), edge.to_key.as_str().to_string(), edge.edge_type.as_str())
+    };
+    let mut order: Vec<StoredEdgeIdentityKey> = Vec::new();
+    let mut latest: HashMap<StoredEdgeIdentityKey, DependencyEdge> = HashMap::new();
+    for edge in relinked {
+        let key = identity(&edge);
+        if latest.insert(key.clone(), edge).is_none() {
+            order.push(key);
+        }
+    }
+    let stored_by_identity: HashMap<StoredEdgeIdentityKey, &DependencyEdge> =
+        stored.iter().map(|edge| (identity(edge), edge)).collect();
+
+    let removed = stored.iter().filter(|edge| !latest.contains_key(&identity(edge))).cloned().collect();
+    let written = order
+        .into_iter()
+        .filter_map(|key| {
+            let edge = latest.remove(&key)?;
+            match stored_by_identity.get(&key) {
+                Some(stored) if **stored == edge => None,
+                _ => Some(edge),
+            }
+        })
+        .collect();
+    (removed, written)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn edge(from: &str, to: &str, edge_type: EdgeType, metadata: &[(&str, &str)]) -> DependencyEdge {
+        let mut builder = DependencyEdge::builder().from_key(from).to_key(to).edge_type(edge_type);
+        for (key, value) in metadata {
+            builder = builder.metadata_entry(*key, *value);
+        }
+        builder.build().unwrap()
+    }
+
+    #[test]
+    fn test_pass_edges_appended_not_rewritten() {
+        let calls = "go:fn:Handle:__api:T1";
+        let target = "go:fn:Save:__store:T2";
+        assert!(is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Tests, &[])));
+        assert!(is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &[("dispatch", "dynamic")])));
+        assert!(is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &[("resolution", "grpc_stub")])));
+        assert!(is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &[("plugin", "terraform")])));
+        let cgo = edge("go:file:api_handler_go:1-1", "c:file:codec_h:0-0", EdgeType::Includes, &[]);
+        assert!(is_repo_wide_pass_edge(&cgo));
+
+        let extern_bridge = [("ffi_bridge", "rust_to_c"), ("resolution", "ffi_symbol")];
+        assert!(is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &extern_bridge)));
+        let rewritten_call = [("ffi_bridge", "go_to_c"), ("unresolved_target", "go:fn:C.f:unresolved-reference:0-0")];
+        assert!(!is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &rewritten_call)));
+        let header = edge("c:file:main_c:1-1", "c:file:codec_h:1-1", EdgeType::Includes, &[]);
+        assert!(!is_repo_wide_pass_edge(&header));
+        assert!(!is_repo_wide_pass_edge(&edge(calls, target, EdgeType::Calls, &[("resolution", "imported_package")])));
+    }
+
+    #[test]
+    fn test_synthesized_keys_by_language_segment() {
+        assert!(is_pass_synthesized_entity_key("proto:service:Users:__api_users_proto:T1", &[]));
+        assert!(is_pass_synthesized_entity_key("yaml:setting:DATABASE_URL:__deploy_yaml:T2", &[]));
+        assert!(is_pass_synthesized_entity_key("terraform:resource:bucket:__main_tf:T3", &["terraform"]));
+        assert!(!is_pass_synthesized_entity_key("terraform:resource:bucket:__main_tf:T3", &[]));
+        assert!(!is_pass_synthesized_entity_key("go:fn:Save:__store:T2", &["terraform"]));
+        assert!(!is_pass_synthesized_entity_key("sqlish", &[]));
+    }
+
+    #[test]
+    fn test_contract_paths_by_extension_suffix() {
+        let extensions: Vec<String> = CONTRACT_FILE_EXTENSIONS.iter().map(|e| e.to_string()).collect();
+        assert!(is_contract_file_path("/repo/api/users.proto", &extensions));
+        assert!(is_contract_file_path("/repo/db/0001_init.up.sql", &extensions));
+        assert!(is_contract_file_path("/repo/deploy/api.yml", &extensions));
+        assert!(!is_contract_file_path("/repo/api/users.pb.go", &extensions));
+        assert!(!is_contract_file_path("/repo/api/proto", &extensions));
+        assert!(!is_contract_file_path("/repo/api/users.proto", &[]));
+    }
+
+    #[test]
+    fn test_reverted_bridge_is_not_an_extern_edge() {
+        let bridged = [
+            ("ffi_bridge", "go_to_c"),
+            ("ffi_symbol", "deflate"),
+            ("resolution", "ffi_symbol"),
+            ("unresolved_target", "go:fn:C.deflate:unresolved-reference:0-0"),
+        ];
+        let mut edges = vec![
+            edge("go:fn:Compress:__zip:T1", "c:fn:deflate:__zip_zlib:T2", EdgeType::Calls, &bridged),
+            edge("go:fn:Compress:__zip:T1", "go:fn:C.free:unresolved-reference:0-0", EdgeType::Calls, &[]),
+        ];
+        assert_eq!(revert_bound_edge_targets(&mut edges), 1);
+        assert_eq!(edges[0].to_key.as_str(), "go:fn:C.deflate:unresolved-reference:0-0");
+        assert!(edges[0].metadata.is_empty(), "{:?}", edges[0].metadata);
+        assert!(!is_repo_wide_pass_edge(&edges[0]));
+        assert_eq!(edges[1].to_key.as_str(), "go:fn:C.free:unresolved-reference:0-0");
+    }
+
+    #[test]
+    fn test_diff_writes_only_changed_edges() {
+        let kept = edge("a", "b", EdgeType::Calls, &[]);
+        let gone = edge("a", "c", EdgeType::Calls, &[]);
+        let restamped = edge("a", "d", EdgeType::Calls, &[("confidence_score", "0.50")]);
+        let stored = vec![kept.clone(), gone.clone(), restamped];
+
+        let rescored = edge("a", "d", EdgeType::Calls, &[("confidence_score", "0.90")]);
+        let fresh = edge("a", "e", EdgeType::Uses, &[]);
+        let superseded = edge("a", "e", EdgeType::Uses, &[("note", "older")]);
+        let relinked = vec![kept, superseded, rescored.clone(), fresh.clone()];
+
+        let (removed, written) = diff_relinked_edge_sets(&stored, relinked);
+        assert_eq!(removed, vec![gone]);
+        assert_eq!(written, vec![fresh, rescored]);
+    }
+}
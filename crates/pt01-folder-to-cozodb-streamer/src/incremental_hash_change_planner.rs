@@ -0,0 +1,132 @@
+//! Incremental hash change planner (v1.7.3)
+//!
+//! # 4-Word Naming: incremental_hash_change_planner
+//!
+//! Diffs current file content hashes against the FileHashCache of a previous
+//! ingest. Only added/modified files are re-parsed; unchanged files keep their
+//! stored entities and edges; deleted files have their rows removed.
+
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+
+/// Classification of files between two ingests
+///
+/// # 4-Word Name: FileChangeSetPlan
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct FileChangeSetPlan {
+    /// On disk, not in cache (sorted)
+    pub added: Vec<String>,
+    /// On disk and in cache, hash differs (sorted)
+    pub modified: Vec<String>,
+    /// In cache, no longer on disk (sorted)
+    pub deleted: Vec<String>,
+    /// Hash identical: stored parse results are reused
+    pub unchanged_count: usize,
+}
+
+impl FileChangeSetPlan {
+    /// True when nothing needs to be re-parsed or removed
+    ///
+    /// # 4-Word Name: is_empty_change_set
+    pub fn is_empty_change_set(&self) -> bool {
+        self.added.is_empty() && self.modified.is_empty() && self.deleted.is_empty()
+    }
+
+    /// Files that must be parsed again (added + modified)
+    ///
+    /// # 4-Word Name: files_needing_reparse_list
+    pub fn files_needing_reparse_list(&self) -> Vec<String> {
+        self.added.iter().chain(&self.modified).cloned().collect()
+    }
+
+    /// Files whose stored rows are stale (modified + deleted)
+    ///
+    /// # 4-Word Name: files_with_stale_rows
+    pub fn files_with_stale_rows(&self) -> Vec<String> {
+        self.modified.iter().chain(&self.deleted).cloned().collect()
+    }
+}
+
+/// Diff current hashes against cached hashes
+///
+/// # 4-Word Name: plan_file_changes_by_hash
+///
+/// # Contract
+/// - Precondition: both maps keyed by the same path spelling (same root argument)
+/// - Postcondition: every path lands in exactly one bucket; lists are sorted
+pub fn plan_file_changes_by_hash(
+    current: &HashMap<String, String>,
+    cached: &HashMap<String, String>,
+) -> FileChangeSetPlan {
+    let mut plan = FileChangeSetPlan::default();
+
+    for (path, hash) in current {
+        match cached.get(path) {
+            None => plan.added.push(path.clone()),
+            Some(old) if old != hash => plan.modified.push(path.clone()),
+            Some(_) => plan.unchanged_count += 1,
+        }
+    }
+    plan.deleted = cached
+        .keys()
+        .filter(|path| !current.contains_key(*path))
+        .cloned()
+        .collect();
+
+    plan.added.sort();
+    plan.modified.sort();
+    plan.deleted.sort();
+    plan
+}
+
+/// Package directories of changed Go files
+///
+/// # 4-Word Name: collect_changed_go_packages
+///
+/// Matches `package_directory_of_entity` (parent directory of the file path).
+pub fn collect_changed_go_packages<'a>(paths: impl IntoIterator<Item = &'a String>) -> HashSet<String> {
+    paths
+        .into_iter()
+        .map(Path::new)
+        .filter(|p| p.extension().and_then(|e| e.to_str()) == Some("go"))
+        .map(|p| p.parent().map(|d| d.to_string_lossy().to_string()).unwrap_or_default())
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn hashes(pairs: &[(&str, &str)]) -> HashMap<String, String> {
+        pairs.iter().map(|(p, h)| (p.to_string(), h.to_string())).collect()
+    }
+
+    #[test]
+    fn test_plan_buckets_added_modified_deleted() {
+        let cached = hashes(&[("a.rs", "1"), ("b.rs", "2"), ("gone.rs", "3")]);
+        let current = hashes(&[("a.rs", "1"), ("b.rs", "CHANGED"), ("new.rs", "4")]);
+
+        let plan = plan_file_changes_by_hash(&current, &cached);
+        assert_eq!(plan.added, vec!["new.rs"]);
+        assert_eq!(plan.modified, vec!["b.rs"]);
+        assert_eq!(plan.deleted, vec!["gone.rs"]);
+        assert_eq!(plan.unchanged_count, 1);
+        assert_eq!(plan.files_needing_reparse_list(), vec!["new.rs", "b.rs"]);
+        assert_eq!(plan.files_with_stale_rows(), vec!["b.rs", "gone.rs"]);
+    }
+
+    #[test]
+    fn test_identical_hashes_yield_empty_plan() {
+        let same = hashes(&[("a.rs", "1"), ("b.go", "2")]);
+        let plan = plan_file_changes_by_hash(&same, &same);
+        assert!(plan.is_empty_change_set());
+        assert_eq!(plan.unchanged_count, 2);
+    }
+
+    #[test]
+    fn test_changed_go_packages_ignore_other_languages() {
+        let paths = vec!["svc/a.go".to_string(), "svc/b.go".to_string(), "src/lib.rs".to_string()];
+        let packages = collect_changed_go_packages(&paths);
+        assert_eq!(packages, ["svc".to_string()].into_iter().collect());
+    }
+}
@@ -0,0 +1,441 @@
+//! Ingest progress reporting (v1.7.3)
+//!
+//! # 4-Word Naming: ingest_progress_event_reporter
+//!
+//! One set of counters — files parsed, nodes, edges, unresolved references,
+//! elapsed time — rendered one of three ways (`--progress`):
+//!
+//! - `bar` (default): an indicatif bar on stderr, hidden when stderr is not a
+//!   terminal
+//! - `json`: one JSON object per line on stderr, at most every 500 ms while
+//!   parsing plus one per phase change and a final `"event":"done"` line, so
+//!   CI logs and wrapping tools can follow long ingests
+//! - `none`: nothing
+//!
+//! ```text
+//! {"event":"progress","phase":"parse","files_total":5120,"files_parsed":812,"nodes":20344,"edges":61210,"unresolved_refs":7311,"elapsed_ms":2500}
+//! {"event":"done","phase":"store","files_total":5120,"files_parsed":5120,"nodes":128001,"edges":402877,"unresolved_refs":40210,"elapsed_ms":18233}
+//! ```
+//!
+//! Counters are atomics: parse workers report files concurrently. The time
+//! spent in each phase is kept as well (`phase_duration_totals`), for
+//! `parseltongue bench ingest`, and the same phase windows become
+//! OpenTelemetry spans (`ingest` with one child per phase) when an OTLP
+//! endpoint is configured (`otlp_trace_span_exporter`).
+
+use std::collections::BTreeMap;
+use std::io::Write;
+use std::str::FromStr;
+use std::sync::atomic::{AtomicU64, AtomicUsize, Ordering};
+use std::sync::Mutex;
+use std::time::Instant;
+
+use indicatif::{ProgressBar, ProgressStyle};
+use parseltongue_core::entities::DependencyEdge;
+use parseltongue_core::filtered_graph_traversal_queries::is_placeholder_target_key;
+use parseltongue_core::otlp_trace_span_exporter::{
+    current_unix_time_nanos, generate_random_hex_identifier, TelemetryAttributeValueKind, TelemetrySpanKindValue,
+    TelemetrySpanRecordEntry,
+};
+use serde::Serialize;
+
+/// Minimum gap between two JSON `progress` lines of the same phase
+const JSON_PROGRESS_EMIT_INTERVAL_MS: u64 = 500;
+
+/// How ingest progress is shown
+///
+/// # 4-Word Name: IngestProgressOutputMode
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub enum IngestProgressOutputMode {
+    #[default]
+    Bar,
+    Json,
+    Off,
+}
+
+impl FromStr for IngestProgressOutputMode {
+    type Err = String;
+
+    fn from_str(value: &str) -> Result<Self, Self::Err> {
+        match value {
+            "bar" => Ok(Self::Bar),
+            "json" => Ok(Self::Json),
+            "none" => Ok(Self::Off),
+            other => Err(format!("unknown progress mode '{}' (known: bar, json, none)", other)),
+        }
+    }
+}
+
+/// Counters of an ingest at one moment, as emitted in `json` mode
+///
+/// # 4-Word Name: IngestProgressSnapshotEvent
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct IngestProgressSnapshotEvent {
+    /// `progress` while running, `done` once
+    pub event: &'static str,
+    /// `scan`, `parse`, `resolve` or `store`
+    pub phase: &'static str,
+    pub files_total: usize,
+    pub files_parsed: usize,
+    pub nodes: usize,
+    pub edges: usize,
+    /// Edges whose target is still an unresolved placeholder
+    pub unresolved_refs: usize,
+    pub elapsed_ms: u64,
+}
+
+/// Shared progress counters plus the selected renderer
+///
+/// # 4-Word Name: IngestProgressEventReporter
+pub struct IngestProgressEventReporter {
+    mode: IngestProgressOutputMode,
+    started: Instant,
+    /// Wall clock at `started`, for span timestamps
+    started_unix_nanos: u64,
+    phase: Mutex<&'static str>,
+    /// Milliseconds since `started` at which the current phase began
+    phase_started_ms: AtomicU64,
+    /// Closed phases, in order: (phase, began_ms, ended_ms) since `started`
+    phase_windows_ms: Mutex<Vec<(&'static str, u64, u64)>>,
+    files_total: AtomicUsize,
+    files_parsed: AtomicUsize,
+    nodes: AtomicUsize,
+    edges: AtomicUsize,
+    unresolved_refs: AtomicUsize,
+    last_json_emit_ms: AtomicU64,
+    bar: Option<ProgressBar>,
+    json_sink: Mutex<Box<dyn Write + Send>>,
+}
+
+impl IngestProgressEventReporter {
+    /// Reporter writing to stderr
+    ///
+    /// # 4-Word Name: create_ingest_progress_reporter
+    pub fn create_ingest_progress_reporter(mode: IngestProgressOutputMode) -> Self {
+        Self::create_reporter_with_sink(mode, Box::new(std::io::stderr()))
+    }
+
+    /// Reporter writing JSON lines to `json_sink` (the bar always uses stderr)
+    ///
+    /// # 4-Word Name: create_reporter_with_sink
+    pub fn create_reporter_with_sink(mode: IngestProgressOutputMode, json_sink: Box<dyn Write + Send>) -> Self {
+        let bar = (mode == IngestProgressOutputMode::Bar).then(|| {
+            let bar = ProgressBar::new_spinner();
+            bar.set_style(
+                ProgressStyle::default_spinner()
+                    .template("{spinner:.green} [{elapsed_precise}] {msg}")
+                    .unwrap(),
+            );
+            bar.set_message("Scanning files...");
+            bar
+        });
+        Self {
+            mode,
+            started: Instant::now(),
+            started_unix_nanos: current_unix_time_nanos(),
+            phase: Mutex::new("scan"),
+            phase_started_ms: AtomicU64::new(0),
+            phase_windows_ms: Mutex::new(Vec::new()),
+            files_total: AtomicUsize::new(0),
+            files_parsed: AtomicUsize::new(0),
+            nodes: AtomicUsize::new(0),
+            edges: AtomicUsize::new(0),
+            unresolved_refs: AtomicUsize::new(0),
+            last_json_emit_ms: AtomicU64::new(0),
+            bar,
+            json_sink: Mutex::new(json_sink),
+        }
+    }
+
+    /// Enter the parse phase with `files_total` files queued
+    ///
+    /// # 4-Word Name: begin_file_parse_phase
+    pub fn begin_file_parse_phase(&self, files_total: usize, workers: usize) {
+        self.files_total.store(files_total, Ordering::Relaxed);
+        self.switch_to_ingest_phase("parse");
+        if let Some(bar) = &self.bar {
+            bar.set_length(files_total as u64);
+            bar.set_style(
+                ProgressStyle::default_bar()
+                    .template("{spinner:.green} [{elapsed_precise}] {bar:30.cyan/blue} {pos}/{len} files ({eta}) {msg}")
+                    .unwrap(),
+            );
+            bar.set_message(format!("on {} workers", workers));
+        }
+        self.emit_json_progress_line("progress");
+    }
+
+    /// Count one parsed file and its extracted nodes and edges
+    ///
+    /// # 4-Word Name: record_parsed_file_counts
+    ///
+    /// # Contract
+    /// - Thread-safe: called from parse workers
+    /// - Postcondition: in `json` mode a line is emitted when the previous
+    ///   one is older than 500 ms
+    pub fn record_parsed_file_counts(&self, nodes: usize, edges: &[DependencyEdge]) {
+        let unresolved = count_unresolved_reference_edges(edges);
+        self.files_parsed.fetch_add(1, Ordering::Relaxed);
+        self.nodes.fetch_add(nodes, Ordering::Relaxed);
+        self.edges.fetch_add(edges.len(), Ordering::Relaxed);
+        self.unresolved_refs.fetch_add(unresolved, Ordering::Relaxed);
+        match self.mode {
+            IngestProgressOutputMode::Bar => {
+                if let Some(bar) = &self.bar {
+                    bar.inc(1);
+                }
+            }
+            IngestProgressOutputMode::Json => {
+                let now_ms = self.started.elapsed().as_millis() as u64;
+                let last = self.last_json_emit_ms.load(Ordering::Relaxed);
+                let due = now_ms.saturating_sub(last) >= JSON_PROGRESS_EMIT_INTERVAL_MS;
+                // Only the worker that wins the exchange writes this interval's line
+                if due
+                    && self
+                        .last_json_emit_ms
+                        .compare_exchange(last, now_ms, Ordering::Relaxed, Ordering::Relaxed)
+                        .is_ok()
+                {
+                    self.emit_json_progress_line("progress");
+                }
+            }
+            IngestProgressOutputMode::Off => {}
+        }
+    }
+
+    /// Count a file that produced nothing (quarantined or failed)
+    ///
+    /// # 4-Word Name: record_failed_file_parse
+    pub fn record_failed_file_parse(&self) {
+        self.record_parsed_file_counts(0, &[]);
+    }
+
+    /// Enter a post-parse phase (`resolve`, `store`)
+    ///
+    /// # 4-Word Name: begin_named_ingest_phase
+    pub fn begin_named_ingest_phase(&self, phase: &'static str, message: &str) {
+        self.switch_to_ingest_phase(phase);
+        if let Some(bar) = &self.bar {
+            bar.set_message(message.to_string());
+        }
+        self.emit_json_progress_line("progress");
+    }
+
+    /// Replace the running totals with the post-resolution graph counts
+    ///
+    /// # 4-Word Name: update_resolved_graph_totals
+    ///
+    /// Cross-file passes add edges and resolve placeholders, so the counts
+    /// summed while parsing are superseded once they ran.
+    pub fn update_resolved_graph_totals(&self, nodes: usize, edges: &[DependencyEdge]) {
+        self.nodes.store(nodes, Ordering::Relaxed);
+        self.edges.store(edges.len(), Ordering::Relaxed);
+        self.unresolved_refs.store(count_unresolved_reference_edges(edges), Ordering::Relaxed);
+    }
+
+    /// Print a line without tearing the progress bar
+    ///
+    /// # 4-Word Name: print_line_above_progress
+    pub fn print_line_above_progress(&self, line: &str) {
+        match &self.bar {
+            Some(bar) => bar.println(line),
+            None if self.mode == IngestProgressOutputMode::Off => eprintln!("{}", line),
+            // JSON mode: stderr is for JSON lines only
+            None => {}
+        }
+    }
+
+    /// Stop the bar / emit the `done` line; returns the final counters
+    ///
+    /// # 4-Word Name: finish_ingest_progress_report
+    pub fn finish_ingest_progress_report(&self) -> IngestProgressSnapshotEvent {
+        if let Some(bar) = &self.bar {
+            bar.finish_with_message("Ingest completed");
+        }
+        self.close_current_ingest_phase();
+        self.emit_json_progress_line("done");
+        self.snapshot_progress_counters("done")
+    }
+
+    /// Current counters
+    ///
+    /// # 4-Word Name: snapshot_progress_counters
+    pub fn snapshot_progress_counters(&self, event: &'static str) -> IngestProgressSnapshotEvent {
+        IngestProgressSnapshotEvent {
+            event,
+            phase: *self.phase.lock().unwrap(),
+            files_total: self.files_total.load(Ordering::Relaxed),
+            files_parsed: self.files_parsed.load(Ordering::Relaxed),
+            nodes: self.nodes.load(Ordering::Relaxed),
+            edges: self.edges.load(Ordering::Relaxed),
+            unresolved_refs: self.unresolved_refs.load(Ordering::Relaxed),
+            elapsed_ms: self.started.elapsed().as_millis() as u64,
+        }
+    }
+
+    /// Milliseconds spent per phase; a phase entered twice is summed
+    ///
+    /// # 4-Word Name: phase_duration_totals_ms
+    ///
+    /// # Contract
+    /// - Postcondition: after `finish_ingest_progress_report`, every phase
+    ///   entered is present, the last one included
+    pub fn phase_duration_totals_ms(&self) -> BTreeMap<&'static str, u64> {
+        let mut totals = BTreeMap::new();
+        for (phase, began, ended) in self.phase_windows_ms.lock().unwrap().iter() {
+            *totals.entry(*phase).or_insert(0) += ended.saturating_sub(*began);
+        }
+        totals
+    }
+
+    /// Root `ingest` span plus one child span per closed phase
+    ///
+    /// # 4-Word Name: build_ingest_phase_spans
+    ///
+    /// # Contract
+    /// - Precondition: called after `finish_ingest_progress_report`
+    /// - Postcondition: all spans share a new trace id; the root carries the
+    ///   final counters and `ingest.mode` (`full` / `incremental`)
+    pub fn build_ingest_phase_spans(&self, mode: &str, root_dir: &str) -> Vec<TelemetrySpanRecordEntry> {
+        let trace_id = generate_random_hex_identifier(16);
+        let root_span_id = generate_random_hex_identifier(8);
+        let at = |ms: u64| self.started_unix_nanos + ms * 1_000_000;
+        let windows = self.phase_windows_ms.lock().unwrap().clone();
+        let ended_ms = windows.last().map_or(0, |(_, _, ended)| *ended);
+        let counters = self.snapshot_progress_counters("done");
+        let count = |key: &str, value: usize| (key.to_string(), TelemetryAttributeValueKind::Int(value as i64));
+
+        let mut spans = vec![TelemetrySpanRecordEntry {
+            trace_id: trace_id.clone(),
+            span_id: root_span_id.clone(),
+            parent_span_id: None,
+            name: "ingest".to_string(),
+            kind: TelemetrySpanKindValue::Internal,
+            start_unix_nanos: at(0),
+            end_unix_nanos: at(ended_ms),
+            attributes: vec![
+                ("ingest.mode".to_string(), TelemetryAttributeValueKind::Text(mode.to_string())),
+                ("ingest.root_dir".to_string(), TelemetryAttributeValueKind::Text(root_dir.to_string())),
+                count("ingest.files_total", counters.files_total),
+                count("ingest.files_parsed", counters.files_parsed),
+                count("graph.nodes", counters.nodes),
+                count("graph.edges", counters.edges),
+                count("graph.unresolved_refs", counters.unresolved_refs),
+            ],
+            error: false,
+        }];
+        spans.extend(windows.iter().map(|(phase, began, ended)| TelemetrySpanRecordEntry {
+            trace_id: trace_id.clone(),
+            span_id: generate_random_hex_identifier(8),
+            parent_span_id: Some(root_span_id.clone()),
+            name: format!("ingest.{}", phase),
+            kind: TelemetrySpanKindValue::Internal,
+            start_unix_nanos: at(*began),
+            end_unix_nanos: at(*ended),
+            attributes: Vec::new(),
+            error: false,
+        }));
+        spans
+    }
+
+    fn switch_to_ingest_phase(&self, phase: &'static str) {
+        self.close_current_ingest_phase();
+        *self.phase.lock().unwrap() = phase;
+    }
+
+    fn close_current_ingest_phase(&self) {
+        let now_ms = self.started.elapsed().as_millis() as u64;
+        let began = self.phase_started_ms.swap(now_ms, Ordering::Relaxed);
+        let phase = *self.phase.lock().unwrap();
+        self.phase_windows_ms.lock().unwrap().push((phase, began, now_ms.max(began)));
+    }
+
+    fn emit_json_progress_line(&self, event: &'static str) {
+        if self.mode != IngestProgressOutputMode::Json {
+            return;
+        }
+        let snapshot = self.snapshot_progress_counters(event);
+        let Ok(line) = serde_json::to_string(&snapshot) else { return };
+        let mut sink = self.json_sink.lock().unwrap();
+        // Progress output is best-effort: a closed pipe must not fail the ingest
+        let _ = writeln!(sink, "{}", line).and_then(|_| sink.flush());
+    }
+}
+
+/// Edges whose target is an extractor placeholder
+///
+/// # 4-Word Name: count_unresolved_reference_edges
+pub fn count_unresolved_reference_edges(edges: &[DependencyEdge]) -> usize {
+    edges.iter().filter(|edge| is_placeholder_target_key(edge.to_key.as_str())).count()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::EdgeType;
+    use std::sync::Arc;
+
+    struct SharedLineBuffer(Arc<Mutex<Vec<u8>>>);
+
+    impl Write for SharedLineBuffer {
+        fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+            self.0.lock().unwrap().extend_from_slice(buf);
+            Ok(buf.len())
+        }
+
+        fn flush(&mut self) -> std::io::Result<()> {
+            Ok(())
+        }
+    }
+
+    #[test]
+    fn test_json_mode_emits_phase_and_done_lines() {
+        let buffer = Arc::new(Mutex::new(Vec::new()));
+        let reporter = IngestProgressEventReporter::create_reporter_with_sink(
+            IngestProgressOutputMode::Json,
+            Box::new(SharedLineBuffer(buffer.clone())),
+        );
+        let edge = |to: &str| {
+            DependencyEdge::builder()
+                .from_key("go:fn:main:__main:T1")
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+                .build()
+                .unwrap()
+        };
+
+        reporter.begin_file_parse_phase(2, 4);
+        reporter.record_parsed_file_counts(
+            3,
+            &[edge("go:fn:Save:__store:T2"), edge("go:fn:Println:unresolved-reference:0-0")],
+        );
+        reporter.record_failed_file_parse();
+        reporter.begin_named_ingest_phase("store", "Inserting to database...");
+        let done = reporter.finish_ingest_progress_report();
+
+        assert_eq!((done.files_total, done.files_parsed, done.nodes, done.edges, done.unresolved_refs), (2, 2, 3, 2, 1));
+        let output = String::from_utf8(buffer.lock().unwrap().clone()).unwrap();
+        let lines: Vec<serde_json::Value> = output.lines().map(|l| serde_json::from_str(l).unwrap()).collect();
+        // Per-file lines are throttled; phase changes and `done` always print
+        assert_eq!(lines.first().unwrap()["phase"], "parse");
+        assert_eq!(lines[lines.len() - 2]["phase"], "store");
+        assert_eq!(lines.last().unwrap()["event"], "done");
+        assert_eq!(lines.last().unwrap()["unresolved_refs"], 1);
+
+        let phases: Vec<&str> = reporter.phase_duration_totals_ms().into_keys().collect();
+        assert_eq!(phases, vec!["parse", "scan", "store"]);
+
+        let spans = reporter.build_ingest_phase_spans("full", "/repo");
+        let names: Vec<&str> = spans.iter().map(|span| span.name.as_str()).collect();
+        assert_eq!(names, vec!["ingest", "ingest.scan", "ingest.parse", "ingest.store"]);
+        assert!(spans[1..].iter().all(|span| span.parent_span_id.as_ref() == Some(&spans[0].span_id)));
+        assert!(spans.iter().all(|span| span.trace_id == spans[0].trace_id && span.start_unix_nanos <= span.end_unix_nanos));
+    }
+
+    #[test]
+    fn test_progress_mode_parses_cli_values() {
+        assert_eq!("json".parse::<IngestProgressOutputMode>(), Ok(IngestProgressOutputMode::Json));
+        assert_eq!("none".parse::<IngestProgressOutputMode>(), Ok(IngestProgressOutputMode::Off));
+        assert!("xml".parse::<IngestProgressOutputMode>().is_err());
+    }
+}
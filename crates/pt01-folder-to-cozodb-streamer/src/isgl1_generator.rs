@@ -115,10 +115,14 @@ pub enum EntityType {
 
     // Variables
     Variable,   // Module-level or global variables
+    Constant,   // v1.7.3: Go package-level constants
 
     // SQL-specific (v1.5.6)
     Table,      // SQL CREATE TABLE
     View,       // SQL CREATE VIEW
+
+    // HTTP routes (v1.7.3)
+    Route,      // Router registration: `POST /users`
 }
 
 /// ISGL1 key generator implementation using tree-sitter
@@ -192,8 +196,10 @@ impl Isgl1KeyGeneratorImpl {
             EntityType::Namespace => "namespace",
             EntityType::Typedef => "typedef",
             EntityType::Variable => "var",
+            EntityType::Constant => "const",  // v1.7.3: Go package-level const
             EntityType::Table => "table",  // v1.5.6: SQL table
             EntityType::View => "view",    // v1.5.6: SQL view
+            EntityType::Route => "route",  // v1.7.3: HTTP route
         };
 
         // ISGL1 v2.1: Sanitize entity name to handle generic types
@@ -399,6 +405,9 @@ impl Isgl1KeyGeneratorImpl {
             parseltongue_core::query_extractor::EntityType::Typedef => EntityType::Typedef,
             parseltongue_core::query_extractor::EntityType::Table => EntityType::Table,  // v1.5.6: SQL table
             parseltongue_core::query_extractor::EntityType::View => EntityType::View,    // v1.5.6: SQL view
+            parseltongue_core::query_extractor::EntityType::Constant => EntityType::Constant,  // v1.7.3: Go const
+            parseltongue_core::query_extractor::EntityType::Variable => EntityType::Variable,  // v1.7.3: Go var
+            parseltongue_core::query_extractor::EntityType::Route => EntityType::Route,        // v1.7.3: HTTP route
         }
     }
 
@@ -25,12 +25,28 @@
 use std::path::PathBuf;
 use std::sync::Arc;
 
+use parseltongue_core::external_plugin_process_protocol::ExternalPluginProcessSpec;
+use parseltongue_core::go_build_constraint_evaluator::GoBuildContextConfig;
+use parseltongue_core::rust_cfg_feature_evaluator::RustFeatureSelectionConfig;
+use ingest_progress_event_reporter::IngestProgressOutputMode;
+use project_path_filter_rules::ProjectPathFilterRules;
+use sharded_ingest_graph_merger::IngestDirectoryShardSpec;
+
 pub mod cli;
 pub mod errors;
 pub mod external_dependency_handler;
 pub mod file_watcher;
+pub mod gitignore_hierarchy_path_matcher; // v1.7.3: .gitignore / .git/info/exclude honoring
+pub mod incremental_graph_relink_planner; // v1.7.3: Repo-wide passes re-run over the stored graph
+pub mod incremental_hash_change_planner; // v1.7.3: Content-hash incremental ingest
+pub mod ingest_progress_event_reporter; // v1.7.3: --progress bar / JSON lines
 pub mod isgl1_generator;
 pub mod lsp_client;
+pub mod parse_failure_quarantine_guard; // v1.7.3: --quarantine parser panic/failure isolation
+pub mod parse_worker_pool_runner; // v1.7.3: Bounded worker pool for per-file parsing
+pub mod persistent_parse_artifact_cache; // v1.7.3: --parse-cache cross-run per-file artifacts
+pub mod project_path_filter_rules; // v1.7.3: Config-file languages/globs/directory overrides
+pub mod sharded_ingest_graph_merger; // v1.7.3: --shard I/N partial graphs + merge
 pub mod streamer;
 pub mod test_detector;
 pub mod v090_specifications;
@@ -64,6 +80,30 @@ pub struct StreamerConfig {
     pub parsing_library: String,
     /// Chunking strategy to use (default: "ISGL1")
     pub chunking: String,
+    /// Go build configuration selecting build-constrained files (v1.7.3, default: host GOOS/GOARCH)
+    pub go_build_context: GoBuildContextConfig,
+    /// Cargo features selecting `#[cfg(feature = ...)]` items (v1.7.3, default: none, index every item)
+    pub rust_features: RustFeatureSelectionConfig,
+    /// Annotate entities with last commit/author/owner from `git blame` (v1.7.3, default: false)
+    pub git_blame: bool,
+    /// Parse worker threads; 0 = GOMAXPROCS or available parallelism (v1.7.3, default: 0)
+    pub worker_threads: usize,
+    /// Languages/globs from a project config file, on top of the patterns above (v1.7.3, default: None)
+    pub path_filter_rules: Option<ProjectPathFilterRules>,
+    /// Skip files ignored by `.gitignore` / `.git/info/exclude` (v1.7.3, default: true)
+    pub respect_gitignore: bool,
+    /// Catch parser panics and skip failing files, reporting them as quarantined (v1.7.3, default: false)
+    pub quarantine_parse_failures: bool,
+    /// Progress rendering: TTY bar, JSON lines on stderr, or nothing (v1.7.3, default: bar)
+    pub progress_output: IngestProgressOutputMode,
+    /// Cross-run per-file parse artifact cache directory (v1.7.3, default: none)
+    pub parse_cache_dir: Option<PathBuf>,
+    /// Ingest only this shard's directories and skip cross-file resolution (v1.7.3, default: none)
+    pub shard: Option<IngestDirectoryShardSpec>,
+    /// Rebind Go calls with go/packages + go/types after the syntactic passes (v1.7.3, default: false)
+    pub go_typed_resolution: bool,
+    /// External extractor/enricher plugins from the project config (v1.7.3, default: none)
+    pub plugins: Vec<ExternalPluginProcessSpec>,
 }
 
 impl Default for StreamerConfig {
@@ -76,6 +116,18 @@ impl Default for StreamerConfig {
             exclude_patterns: vec!["target/**".to_string(), "node_modules/**".to_string()],
             parsing_library: "tree-sitter".to_string(), // PRD default
             chunking: "ISGL1".to_string(), // PRD default
+            go_build_context: GoBuildContextConfig::default(),
+            rust_features: RustFeatureSelectionConfig::default(),
+            git_blame: false,
+            worker_threads: 0,
+            path_filter_rules: None,
+            respect_gitignore: true,
+            quarantine_parse_failures: false,
+            progress_output: IngestProgressOutputMode::Bar,
+            parse_cache_dir: None,
+            shard: None,
+            go_typed_resolution: false,
+            plugins: Vec::new(),
         }
     }
 }
@@ -91,4 +143,15 @@ impl ToolFactory {
         let streamer = FileStreamerImpl::new(config, generator, test_detector).await?;
         Ok(Arc::new(streamer))
     }
+
+    /// Create a file streamer that ingests into an existing database instance (v1.7.3)
+    pub async fn create_streamer_with_storage(
+        config: StreamerConfig,
+        storage: Arc<parseltongue_core::storage::CozoDbStorage>,
+    ) -> Result<Arc<FileStreamerImpl>> {
+        let generator = Isgl1KeyGeneratorFactory::new();
+        let test_detector = Arc::new(crate::test_detector::DefaultTestDetector::new());
+        let streamer = FileStreamerImpl::new_with_shared_storage(config, generator, test_detector, storage).await?;
+        Ok(Arc::new(streamer))
+    }
 }
\ No newline at end of file
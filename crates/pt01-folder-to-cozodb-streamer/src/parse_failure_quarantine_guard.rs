@@ -0,0 +1,101 @@
+//! Parse failure quarantine (v1.7.3)
+//!
+//! # 4-Word Naming: parse_failure_quarantine_guard
+//!
+//! `--quarantine` isolates files the extractors cannot handle. Every parse
+//! on the worker pool (full, incremental and streaming ingests) runs under
+//! `catch_unwind`, so a panicking grammar or extractor takes down one file
+//! rather than the whole ingest (a panic on a rayon worker otherwise
+//! propagates to the caller). A quarantined file:
+//!
+//! - contributes no entities or edges
+//! - is recorded in `IgnoredFiles` with reason `parse_quarantined`
+//! - is reported in the run's errors as `[QUARANTINED] path: cause`
+//!
+//! Parse errors (as opposed to panics) are quarantined the same way, so the
+//! report lists every file that is missing from the graph.
+
+use std::any::Any;
+use std::panic::{catch_unwind, AssertUnwindSafe};
+use std::path::Path;
+
+use crate::errors::StreamerError;
+
+/// `IgnoredFiles.reason` of quarantined files
+pub const QUARANTINE_IGNORED_FILE_REASON: &str = "parse_quarantined";
+
+/// Prefix of quarantine lines in `StreamResult::errors`
+pub const QUARANTINE_ERROR_LINE_PREFIX: &str = "[QUARANTINED]";
+
+/// Run one file's parse, turning a panic or error into `ParseQuarantined`
+///
+/// # 4-Word Name: run_file_parse_guarded
+///
+/// # Contract
+/// - Postcondition: `Ok` exactly when `parse` returned `Ok`; a panic is
+///   caught (the panic hook has already logged it) and never unwinds further
+/// - `StreamerError::ParseQuarantined` carries `panic: ...` or the error text
+pub fn run_file_parse_guarded<T>(
+    file_path: &Path,
+    parse: impl FnOnce() -> Result<T, StreamerError>,
+) -> Result<T, StreamerError> {
+    let path = file_path.to_string_lossy().to_string();
+    match catch_unwind(AssertUnwindSafe(parse)) {
+        Ok(Ok(value)) => Ok(value),
+        Ok(Err(StreamerError::ParseQuarantined { path, cause })) => Err(StreamerError::ParseQuarantined { path, cause }),
+        Ok(Err(error)) => Err(StreamerError::ParseQuarantined { path, cause: error.to_string() }),
+        Err(payload) => Err(StreamerError::ParseQuarantined {
+            path,
+            cause: format!("panic: {}", describe_panic_payload_message(payload.as_ref())),
+        }),
+    }
+}
+
+/// Error line for a quarantined file
+///
+/// # 4-Word Name: format_quarantine_error_line
+pub fn format_quarantine_error_line(path: &str, cause: &str) -> String {
+    format!("{} {}: {}", QUARANTINE_ERROR_LINE_PREFIX, path, cause)
+}
+
+/// `panic!` message of a caught payload (`&str` or `String`)
+fn describe_panic_payload_message(payload: &(dyn Any + Send)) -> String {
+    if let Some(message) = payload.downcast_ref::<&str>() {
+        return message.to_string();
+    }
+    if let Some(message) = payload.downcast_ref::<String>() {
+        return message.clone();
+    }
+    "non-string panic payload".to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_guard_quarantines_panics_and_errors() {
+        let path = Path::new("src/broken.go");
+
+        let ok: Result<usize, StreamerError> = run_file_parse_guarded(path, || Ok(3));
+        assert_eq!(ok.unwrap(), 3);
+
+        let panicked: Result<usize, StreamerError> = run_file_parse_guarded(path, || panic!("index out of bounds"));
+        match panicked {
+            Err(StreamerError::ParseQuarantined { path, cause }) => {
+                assert_eq!(path, "src/broken.go");
+                assert_eq!(cause, "panic: index out of bounds");
+            }
+            other => panic!("expected quarantine, got {:?}", other.map(|_| ())),
+        }
+
+        let failed: Result<usize, StreamerError> = run_file_parse_guarded(path, || {
+            Err(StreamerError::ParsingError { file: "src/broken.go".to_string(), reason: "bad tree".to_string() })
+        });
+        assert!(matches!(failed, Err(StreamerError::ParseQuarantined { cause, .. }) if cause.contains("bad tree")));
+        assert_eq!(
+            format_quarantine_error_line("src/broken.go", "panic: x"),
+            "[QUARANTINED] src/broken.go: panic: x"
+        );
+    }
+}
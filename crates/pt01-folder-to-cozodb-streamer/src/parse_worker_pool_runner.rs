@@ -0,0 +1,94 @@
+//! Bounded parse worker pool (v1.7.3)
+//!
+//! # 4-Word Naming: parse_worker_pool_runner
+//!
+//! Per-file parsing fans out on a dedicated rayon pool rather than the global
+//! one, so an ingest uses a known, bounded number of threads:
+//!
+//! 1. `--jobs N` (`StreamerConfig::worker_threads`) when non-zero
+//! 2. else `GOMAXPROCS` when set to a positive number (container CPU quotas
+//!    are commonly exported that way)
+//! 3. else `std::thread::available_parallelism()`
+//!
+//! Determinism: file lists are sorted before the fan-out and results are
+//! collected in input order, so the merge phase (cross-file edge resolution,
+//! batch inserts) sees the same sequence on every run no matter which worker
+//! finishes first.
+
+use rayon::ThreadPoolBuilder;
+
+/// Environment variable honoured for the default worker count
+pub const GOMAXPROCS_ENV_VAR_NAME: &str = "GOMAXPROCS";
+
+/// Worker count for an ingest
+///
+/// # 4-Word Name: resolve_parse_worker_count
+///
+/// # Contract
+/// - Postcondition: >= 1; `requested` wins when non-zero
+pub fn resolve_parse_worker_count(requested: usize) -> usize {
+    let available = std::thread::available_parallelism().map(|n| n.get()).unwrap_or(1);
+    choose_parse_worker_count(requested, std::env::var(GOMAXPROCS_ENV_VAR_NAME).ok().as_deref(), available)
+}
+
+/// Pure part of `resolve_parse_worker_count`
+fn choose_parse_worker_count(requested: usize, gomaxprocs: Option<&str>, available: usize) -> usize {
+    if requested > 0 {
+        return requested;
+    }
+    gomaxprocs
+        .and_then(|value| value.trim().parse::<usize>().ok())
+        .filter(|&count| count > 0)
+        .unwrap_or(available)
+        .max(1)
+}
+
+/// Run `job` inside a pool of `workers` threads
+///
+/// # 4-Word Name: run_on_parse_worker_pool
+///
+/// # Contract
+/// - Postcondition: rayon parallel iterators inside `job` use at most
+///   `workers` threads; if the pool cannot be created `job` runs on the
+///   global pool instead
+pub fn run_on_parse_worker_pool<R, F>(workers: usize, job: F) -> R
+where
+    R: Send,
+    F: FnOnce() -> R + Send,
+{
+    match ThreadPoolBuilder::new()
+        .num_threads(workers.max(1))
+        .thread_name(|index| format!("pt01-parse-{}", index))
+        .build()
+    {
+        Ok(pool) => pool.install(job),
+        Err(_) => job(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use rayon::prelude::*;
+
+    #[test]
+    fn test_worker_count_precedence() {
+        assert_eq!(choose_parse_worker_count(3, Some("8"), 16), 3);
+        assert_eq!(choose_parse_worker_count(0, Some("8"), 16), 8);
+        assert_eq!(choose_parse_worker_count(0, Some(" 2 "), 16), 2);
+        assert_eq!(choose_parse_worker_count(0, Some("0"), 16), 16);
+        assert_eq!(choose_parse_worker_count(0, Some("many"), 16), 16);
+        assert_eq!(choose_parse_worker_count(0, None, 0), 1);
+    }
+
+    #[test]
+    fn test_pool_is_bounded_and_ordered() {
+        let (threads, squares) = run_on_parse_worker_pool(2, || {
+            let squares: Vec<u64> = (0..1_000u64).into_par_iter().map(|n| n * n).collect();
+            (rayon::current_num_threads(), squares)
+        });
+        assert_eq!(threads, 2);
+        assert_eq!(squares[999], 998_001);
+        assert!(squares.windows(2).all(|pair| pair[0] < pair[1]));
+    }
+}
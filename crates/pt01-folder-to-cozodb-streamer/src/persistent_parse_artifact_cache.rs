@@ -0,0 +1,320 @@
+//! Persistent parse artifact cache (v1.7.3)
+//!
+//! # 4-Word Naming: persistent_parse_artifact_cache
+//!
+//! `--parse-cache` keeps each file's parse output on disk, so re-indexing a
+//! branch, a second clone or a CI checkout skips files whose content was
+//! parsed before. One JSON artifact per file (entities, edges, excluded
+//! tests, word coverage) lives under
+//!
+//! ```text
+//! ~/.cache/parseltongue-companion/parse/v{PARSE_CACHE_SCHEMA_VERSION}/ab/abcdef....json
+//! ```
+//!
+//! (`$PARSELTONGUE_CACHE_DIR`, then `$XDG_CACHE_HOME/parseltongue-companion`,
+//! override the location). The file name is the SHA-256 of:
+//!
+//! - the file's content hash
+//! - the extractor version (crate version plus `PARSE_CACHE_SCHEMA_VERSION`,
+//!   bumped whenever extractor output changes)
+//! - the file path as ingested: ISGL1 keys embed it, so the same content at
+//!   another path is a different artifact
+//! - the Go build context and Cargo feature selection
+//!
+//! Repositories and branches share the directory. `cache stats` reports its
+//! size, `cache gc` drops artifacts of older schema versions plus those past
+//! an age or size budget (oldest first). Writes go through a temporary file
+//! and a rename, so concurrent ingests never read half an artifact.
+
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::time::{Duration, SystemTime};
+
+use parseltongue_core::entities::{CodeEntity, DependencyEdge, ExcludedTestEntity, FileWordCoverageRow};
+use parseltongue_core::isgl1_v2::compute_content_hash;
+use serde::{Deserialize, Serialize};
+
+/// Bump when extractor output for unchanged input changes
+pub const PARSE_CACHE_SCHEMA_VERSION: u32 = 1;
+
+/// Directory name under the user cache directory
+pub const PARSE_CACHE_DIRECTORY_NAME: &str = "parseltongue-companion";
+
+/// Environment variable overriding the cache location
+pub const PARSE_CACHE_DIR_ENV_VAR: &str = "PARSELTONGUE_CACHE_DIR";
+
+/// One file's parse output, as stored
+///
+/// # 4-Word Name: CachedFileParseArtifacts
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct CachedFileParseArtifacts {
+    pub code_count: usize,
+    pub test_count: usize,
+    /// Extraction warnings, joined as in `FileResult::error`
+    pub error: Option<String>,
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    pub excluded_tests: Vec<ExcludedTestEntity>,
+    pub word_coverages: Vec<FileWordCoverageRow>,
+}
+
+/// Size of the cache on disk
+///
+/// # 4-Word Name: ParseCacheUsageStats
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct ParseCacheUsageStats {
+    pub directory: String,
+    pub schema_version: u32,
+    /// Artifacts of the current schema version
+    pub entries: usize,
+    pub total_bytes: u64,
+    /// Artifacts of older schema versions, removed by the next `cache gc`
+    pub stale_entries: usize,
+    pub stale_bytes: u64,
+    /// Age of the oldest current artifact, in days
+    pub oldest_entry_age_days: Option<u64>,
+}
+
+/// What `cache gc` removed
+///
+/// # 4-Word Name: ParseCacheGcOutcome
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct ParseCacheGcOutcome {
+    pub removed_entries: usize,
+    pub freed_bytes: u64,
+    pub remaining_entries: usize,
+    pub remaining_bytes: u64,
+}
+
+/// Cache location: `$PARSELTONGUE_CACHE_DIR`, `$XDG_CACHE_HOME/...`, `~/.cache/...`
+///
+/// # 4-Word Name: default_parse_cache_directory
+pub fn default_parse_cache_directory() -> Option<PathBuf> {
+    if let Some(dir) = std::env::var_os(PARSE_CACHE_DIR_ENV_VAR).filter(|d| !d.is_empty()) {
+        return Some(PathBuf::from(dir));
+    }
+    if let Some(dir) = std::env::var_os("XDG_CACHE_HOME").filter(|d| !d.is_empty()) {
+        return Some(PathBuf::from(dir).join(PARSE_CACHE_DIRECTORY_NAME));
+    }
+    let home = std::env::var_os("HOME").or_else(|| std::env::var_os("USERPROFILE"))?;
+    Some(PathBuf::from(home).join(".cache").join(PARSE_CACHE_DIRECTORY_NAME))
+}
+
+/// Parse artifacts of one cache directory, with hit/miss counters
+///
+/// # 4-Word Name: ParseArtifactCacheStore
+#[derive(Debug)]
+pub struct ParseArtifactCacheStore {
+    root: PathBuf,
+    hits: AtomicUsize,
+    misses: AtomicUsize,
+}
+
+impl ParseArtifactCacheStore {
+    /// Store rooted at `root` (created lazily on first write)
+    ///
+    /// # 4-Word Name: open_parse_cache_store
+    pub fn open_parse_cache_store(root: impl Into<PathBuf>) -> Self {
+        Self { root: root.into(), hits: AtomicUsize::new(0), misses: AtomicUsize::new(0) }
+    }
+
+    pub fn root(&self) -> &Path {
+        &self.root
+    }
+
+    /// Cache key of one file's parse
+    ///
+    /// # 4-Word Name: compute_artifact_cache_key
+    ///
+    /// `config_fingerprint` covers settings that change extractor output
+    /// (Go build context, Cargo features).
+    pub fn compute_artifact_cache_key(content: &str, file_path: &Path, config_fingerprint: &str) -> String {
+        compute_content_hash(&format!(
+            "{}\n{}.{}\n{}\n{}",
+            compute_content_hash(content),
+            env!("CARGO_PKG_VERSION"),
+            PARSE_CACHE_SCHEMA_VERSION,
+            file_path.to_string_lossy(),
+            config_fingerprint
+        ))
+    }
+
+    /// Stored artifacts for `key`, counting a hit or miss
+    ///
+    /// # 4-Word Name: load_cached_file_artifacts
+    ///
+    /// # Contract
+    /// - Postcondition: unreadable or undecodable artifacts are misses
+    pub fn load_cached_file_artifacts(&self, key: &str) -> Option<CachedFileParseArtifacts> {
+        let loaded = std::fs::read(self.artifact_path(key))
+            .ok()
+            .and_then(|bytes| serde_json::from_slice::<CachedFileParseArtifacts>(&bytes).ok());
+        let counter = if loaded.is_some() { &self.hits } else { &self.misses };
+        counter.fetch_add(1, Ordering::Relaxed);
+        loaded
+    }
+
+    /// Write artifacts for `key` (temporary file, then rename)
+    ///
+    /// # 4-Word Name: store_file_parse_artifacts
+    pub fn store_file_parse_artifacts(&self, key: &str, artifacts: &CachedFileParseArtifacts) -> std::io::Result<()> {
+        let path = self.artifact_path(key);
+        if let Some(parent) = path.parent() {
+            std::fs::create_dir_all(parent)?;
+        }
+        let bytes = serde_json::to_vec(artifacts).map_err(|e| std::io::Error::new(std::io::ErrorKind::Other, e))?;
+        let temporary = path.with_extension(format!("tmp{}", std::process::id()));
+        std::fs::write(&temporary, bytes)?;
+        std::fs::rename(&temporary, &path)
+    }
+
+    /// (hits, misses) since the store was opened
+    ///
+    /// # 4-Word Name: cache_hit_miss_counts
+    pub fn cache_hit_miss_counts(&self) -> (usize, usize) {
+        (self.hits.load(Ordering::Relaxed), self.misses.load(Ordering::Relaxed))
+    }
+
+    /// Entry counts and sizes, current and stale
+    ///
+    /// # 4-Word Name: collect_cache_usage_stats
+    pub fn collect_cache_usage_stats(&self) -> ParseCacheUsageStats {
+        let now = SystemTime::now();
+        let mut stats = ParseCacheUsageStats {
+            directory: self.root.display().to_string(),
+            schema_version: PARSE_CACHE_SCHEMA_VERSION,
+            ..ParseCacheUsageStats::default()
+        };
+        let mut oldest: Option<Duration> = None;
+        for (path, bytes, modified) in self.list_artifact_files() {
+            if self.is_current_schema_path(&path) {
+                stats.entries += 1;
+                stats.total_bytes += bytes;
+                let age = now.duration_since(modified).unwrap_or_default();
+                oldest = oldest.max(Some(age));
+            } else {
+                stats.stale_entries += 1;
+                stats.stale_bytes += bytes;
+            }
+        }
+        stats.oldest_entry_age_days = oldest.map(|age| age.as_secs() / 86_400);
+        stats
+    }
+
+    /// Remove stale-schema artifacts, then any older than `max_age`, then the
+    /// oldest until at most `max_bytes` remain
+    ///
+    /// # 4-Word Name: remove_expired_cache_entries
+    ///
+    /// # Contract
+    /// - Postcondition: current artifacts are never removed without an age
+    ///   or size budget; empty shard directories are left in place
+    pub fn remove_expired_cache_entries(&self, max_age: Option<Duration>, max_bytes: Option<u64>) -> ParseCacheGcOutcome {
+        let now = SystemTime::now();
+        let mut outcome = ParseCacheGcOutcome::default();
+        let mut kept: Vec<(PathBuf, u64, SystemTime)> = Vec::new();
+        for (path, bytes, modified) in self.list_artifact_files() {
+            let expired = max_age.is_some_and(|max| now.duration_since(modified).unwrap_or_default() > max);
+            if !self.is_current_schema_path(&path) || expired {
+                if std::fs::remove_file(&path).is_ok() {
+                    outcome.removed_entries += 1;
+                    outcome.freed_bytes += bytes;
+                }
+            } else {
+                kept.push((path, bytes, modified));
+            }
+        }
+        kept.sort_by_key(|(_, _, modified)| *modified);
+        let mut remaining: u64 = kept.iter().map(|(_, bytes, _)| bytes).sum();
+        let mut kept = kept.into_iter();
+        if let Some(budget) = max_bytes {
+            while remaining > budget {
+                let Some((path, bytes, _)) = kept.next() else { break };
+                if std::fs::remove_file(&path).is_ok() {
+                    outcome.removed_entries += 1;
+                    outcome.freed_bytes += bytes;
+                    remaining -= bytes;
+                }
+            }
+        }
+        outcome.remaining_entries = kept.len();
+        outcome.remaining_bytes = remaining;
+        outcome
+    }
+
+    fn schema_directory(&self) -> PathBuf {
+        self.root.join("parse").join(format!("v{}", PARSE_CACHE_SCHEMA_VERSION))
+    }
+
+    fn artifact_path(&self, key: &str) -> PathBuf {
+        let shard = key.get(..2).unwrap_or("00");
+        self.schema_directory().join(shard).join(format!("{}.json", key))
+    }
+
+    fn is_current_schema_path(&self, path: &Path) -> bool {
+        path.starts_with(self.schema_directory())
+    }
+
+    /// Every `*.json` artifact under `parse/`, any schema version
+    fn list_artifact_files(&self) -> Vec<(PathBuf, u64, SystemTime)> {
+        let mut files = Vec::new();
+        let mut stack = vec![self.root.join("parse")];
+        while let Some(dir) = stack.pop() {
+            let Ok(entries) = std::fs::read_dir(&dir) else { continue };
+            for entry in entries.flatten() {
+                let path = entry.path();
+                let Ok(meta) = entry.metadata() else { continue };
+                if meta.is_dir() {
+                    stack.push(path);
+                } else if path.extension().is_some_and(|e| e == "json") {
+                    files.push((path, meta.len(), meta.modified().unwrap_or(SystemTime::UNIX_EPOCH)));
+                }
+            }
+        }
+        files
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_artifacts_round_trip_and_gc_drops_stale_versions() {
+        let dir = tempfile::tempdir().unwrap();
+        let store = ParseArtifactCacheStore::open_parse_cache_store(dir.path());
+        let key = ParseArtifactCacheStore::compute_artifact_cache_key("package a\n", Path::new("a/a.go"), "linux/amd64");
+        assert_ne!(key, ParseArtifactCacheStore::compute_artifact_cache_key("package a\n", Path::new("b/a.go"), "linux/amd64"));
+        assert_ne!(key, ParseArtifactCacheStore::compute_artifact_cache_key("package a\n", Path::new("a/a.go"), "windows/arm64"));
+
+        assert!(store.load_cached_file_artifacts(&key).is_none());
+        let artifacts = CachedFileParseArtifacts {
+            code_count: 1,
+            test_count: 0,
+            error: None,
+            entities: Vec::new(),
+            edges: vec![DependencyEdge::builder()
+                .from_key("go:fn:A:__a:T1")
+                .to_key("go:fn:B:__a:T2")
+                .edge_type(parseltongue_core::entities::EdgeType::Calls)
+                .build()
+                .unwrap()],
+            excluded_tests: Vec::new(),
+            word_coverages: Vec::new(),
+        };
+        store.store_file_parse_artifacts(&key, &artifacts).unwrap();
+        assert_eq!(store.load_cached_file_artifacts(&key), Some(artifacts));
+        assert_eq!(store.cache_hit_miss_counts(), (1, 1));
+
+        let stale = dir.path().join("parse").join("v0").join("ab");
+        std::fs::create_dir_all(&stale).unwrap();
+        std::fs::write(stale.join("old.json"), b"{}").unwrap();
+        let stats = store.collect_cache_usage_stats();
+        assert_eq!((stats.entries, stats.stale_entries), (1, 1));
+
+        let outcome = store.remove_expired_cache_entries(None, None);
+        assert_eq!((outcome.removed_entries, outcome.remaining_entries), (1, 1));
+        let outcome = store.remove_expired_cache_entries(None, Some(0));
+        assert_eq!((outcome.removed_entries, outcome.remaining_entries), (1, 0));
+    }
+}
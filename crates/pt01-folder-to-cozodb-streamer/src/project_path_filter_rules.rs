@@ -0,0 +1,180 @@
+//! Project path filter rules (v1.7.3)
+//!
+//! # 4-Word Naming: project_path_filter_rules
+//!
+//! File selection from a project configuration file (`.parseltongue.toml`):
+//! languages and include/exclude globs for the whole project, plus
+//! per-directory overrides. Globs follow `matches_path_glob_pattern`
+//! (.gitignore-like: `*_test.go` matches at any depth, `vendor/**` anything
+//! under a `vendor` directory).
+//!
+//! Paths are matched relative to the directory holding the config file, so
+//! ingesting a subdirectory applies the same rules as ingesting the root.
+//! The most specific override for a file replaces the project-wide languages
+//! and includes when it sets them, and adds its excludes to the project-wide
+//! ones; override globs are relative to the override directory.
+//!
+//! Allow-listed globs (`--allow`, `allow = [...]`) win over every exclusion,
+//! .gitignore and the built-in `target`/`build`/`dist` skips included, for
+//! generated code that is ignored by git but still worth indexing.
+
+use std::path::Path;
+
+use parseltongue_core::entities::Language;
+use parseltongue_core::serializers::graph_export_node_table::matches_path_glob_pattern;
+
+/// Languages and globs of one scope (project or directory)
+///
+/// # 4-Word Name: PathFilterRuleSet
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct PathFilterRuleSet {
+    /// Language names as `Language` displays them (`go`, `rust`, ...); empty = all
+    pub languages: Vec<String>,
+    /// Keep only files matching one of these; empty = all
+    pub include_globs: Vec<String>,
+    /// Drop files matching any of these
+    pub exclude_globs: Vec<String>,
+}
+
+/// Project-wide rules plus per-directory overrides
+///
+/// # 4-Word Name: ProjectPathFilterRules
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct ProjectPathFilterRules {
+    /// Ingest root relative to the config directory (`""` when they coincide)
+    pub ingest_root_offset: String,
+    pub project_rules: PathFilterRuleSet,
+    /// `(directory relative to the config directory, rules)`
+    pub directory_overrides: Vec<(String, PathFilterRuleSet)>,
+    /// Index files matching these even when excluded or git-ignored
+    pub allow_globs: Vec<String>,
+}
+
+impl ProjectPathFilterRules {
+    /// Whether a file (relative to the ingest root) passes the rules
+    ///
+    /// # 4-Word Name: allows_ingest_relative_path
+    ///
+    /// # Contract
+    /// - Postcondition: false when any applicable exclude matches, when
+    ///   includes are set and none matches, or when languages are set and the
+    ///   file's language (by extension) is not among them
+    pub fn allows_ingest_relative_path(&self, relative_path: &Path) -> bool {
+        let project_path = self.project_relative_path(relative_path);
+
+        if self.project_rules.exclude_globs.iter().any(|glob| matches_path_glob_pattern(&project_path, glob)) {
+            return false;
+        }
+
+        let Some((directory, override_rules)) = self.most_specific_override_for(&project_path) else {
+            return includes_allow_path(&self.project_rules.include_globs, &project_path)
+                && languages_allow_path(&self.project_rules.languages, &project_path);
+        };
+        let within_directory = project_path[directory.len()..].trim_start_matches('/');
+        if override_rules.exclude_globs.iter().any(|glob| matches_path_glob_pattern(within_directory, glob)) {
+            return false;
+        }
+
+        let languages = if override_rules.languages.is_empty() {
+            &self.project_rules.languages
+        } else {
+            &override_rules.languages
+        };
+        let included = if override_rules.include_globs.is_empty() {
+            includes_allow_path(&self.project_rules.include_globs, &project_path)
+        } else {
+            includes_allow_path(&override_rules.include_globs, within_directory)
+        };
+        included && languages_allow_path(languages, &project_path)
+    }
+
+    /// Whether a file (relative to the ingest root) matches an allow glob
+    pub fn is_allow_listed_path(&self, relative_path: &Path) -> bool {
+        if self.allow_globs.is_empty() {
+            return false;
+        }
+        let project_path = self.project_relative_path(relative_path);
+        self.allow_globs.iter().any(|glob| matches_path_glob_pattern(&project_path, glob))
+    }
+
+    fn project_relative_path(&self, relative_path: &Path) -> String {
+        let relative = relative_path.to_string_lossy().replace('\\', "/");
+        let relative = relative.trim_start_matches("./");
+        match self.ingest_root_offset.trim_matches('/') {
+            "" => relative.to_string(),
+            offset => format!("{}/{}", offset, relative),
+        }
+    }
+
+    fn most_specific_override_for(&self, project_path: &str) -> Option<(&str, &PathFilterRuleSet)> {
+        self.directory_overrides
+            .iter()
+            .map(|(directory, rules)| (directory.trim_matches('/'), rules))
+            .filter(|(directory, _)| {
+                project_path
+                    .strip_prefix(directory)
+                    .is_some_and(|rest| rest.is_empty() || rest.starts_with('/'))
+            })
+            .max_by_key(|(directory, _)| directory.len())
+    }
+}
+
+fn includes_allow_path(include_globs: &[String], path: &str) -> bool {
+    include_globs.is_empty() || include_globs.iter().any(|glob| matches_path_glob_pattern(path, glob))
+}
+
+fn languages_allow_path(languages: &[String], path: &str) -> bool {
+    if languages.is_empty() {
+        return true;
+    }
+    Language::from_file_path(Path::new(path))
+        .is_some_and(|language| languages.iter().any(|name| name.eq_ignore_ascii_case(&language.to_string())))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn sample_rules() -> ProjectPathFilterRules {
+        ProjectPathFilterRules {
+            ingest_root_offset: String::new(),
+            project_rules: PathFilterRuleSet {
+                languages: vec!["go".to_string()],
+                include_globs: Vec::new(),
+                exclude_globs: vec!["vendor/**".to_string(), "*_gen.go".to_string()],
+            },
+            directory_overrides: vec![(
+                "tools/scripts".to_string(),
+                PathFilterRuleSet {
+                    languages: vec!["python".to_string()],
+                    include_globs: vec!["/*.py".to_string()],
+                    exclude_globs: Vec::new(),
+                },
+            )],
+            allow_globs: vec!["vendor/internal-gen/**".to_string()],
+        }
+    }
+
+    #[test]
+    fn test_project_rules_filter_language_and_excludes() {
+        let rules = sample_rules();
+        assert!(rules.allows_ingest_relative_path(Path::new("cmd/api/main.go")));
+        assert!(!rules.allows_ingest_relative_path(Path::new("./vendor/x/y.go")));
+        assert!(!rules.allows_ingest_relative_path(Path::new("store/models_gen.go")));
+        assert!(!rules.allows_ingest_relative_path(Path::new("web/app.ts")));
+        assert!(rules.is_allow_listed_path(Path::new("vendor/internal-gen/api.go")));
+        assert!(!rules.is_allow_listed_path(Path::new("vendor/x/y.go")));
+    }
+
+    #[test]
+    fn test_directory_override_and_ingest_offset() {
+        let rules = sample_rules();
+        assert!(rules.allows_ingest_relative_path(Path::new("tools/scripts/release.py")));
+        assert!(!rules.allows_ingest_relative_path(Path::new("tools/scripts/lib/util.py")));
+        assert!(!rules.allows_ingest_relative_path(Path::new("tools/scripts/gen.go")));
+
+        let offset = ProjectPathFilterRules { ingest_root_offset: "tools".to_string(), ..sample_rules() };
+        assert!(offset.allows_ingest_relative_path(Path::new("scripts/release.py")));
+        assert!(!offset.allows_ingest_relative_path(Path::new("release.py")));
+    }
+}
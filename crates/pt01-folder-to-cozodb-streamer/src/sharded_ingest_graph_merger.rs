@@ -0,0 +1,194 @@
+//! Sharded ingest graph merger (v1.7.3)
+//!
+//! # 4-Word Naming: sharded_ingest_graph_merger
+//!
+//! Splits one ingest across processes or machines. `--shard I/N` keeps the
+//! files whose directory hashes to shard I of N, so a Go package, a Python
+//! package or a Rust module directory always lands in one shard. A sharded
+//! run stores its per-file entities and edges but skips the cross-file
+//! resolve phase: cross-shard references are still placeholders.
+//! `parseltongue merge` unions the shard databases and then runs that
+//! resolve phase once over the whole graph, so the merged index matches what
+//! a single-process ingest of the same tree produces.
+//!
+//! Every shard must be ingested with the same `directory` argument (entity
+//! keys embed file paths as walked) and the same N. The hash is FNV-1a over
+//! the `/`-separated directory relative to the ingest root, stable across
+//! platforms and releases.
+//!
+//! Word coverage, excluded tests and ignored files stay in the shard
+//! databases; the merged database gets entities, edges and the incremental
+//! baseline (file hashes).
+
+use std::collections::{HashMap, HashSet};
+use std::fmt;
+use std::path::Path;
+use std::str::FromStr;
+
+use parseltongue_core::entities::{CodeEntity, DependencyEdge};
+
+/// One shard of N (1-based), as given to `--shard I/N`
+///
+/// # 4-Word Name: IngestDirectoryShardSpec
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct IngestDirectoryShardSpec {
+    pub index: usize,
+    pub count: usize,
+}
+
+impl FromStr for IngestDirectoryShardSpec {
+    type Err = String;
+
+    fn from_str(value: &str) -> Result<Self, Self::Err> {
+        let (index, count) = value
+            .split_once('/')
+            .ok_or_else(|| format!("shard '{}' is not I/N, e.g. 1/4", value))?;
+        let index: usize = index.trim().parse().map_err(|_| format!("shard index '{}' is not a number", index))?;
+        let count: usize = count.trim().parse().map_err(|_| format!("shard count '{}' is not a number", count))?;
+        if count == 0 || index == 0 || index > count {
+            return Err(format!("shard {}/{} out of range (1 <= I <= N)", index, count));
+        }
+        Ok(Self { index, count })
+    }
+}
+
+impl fmt::Display for IngestDirectoryShardSpec {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}/{}", self.index, self.count)
+    }
+}
+
+impl IngestDirectoryShardSpec {
+    /// Whether `file_path` (under `root`) belongs to this shard
+    ///
+    /// # 4-Word Name: shard_owns_file_path
+    ///
+    /// # Contract
+    /// - Postcondition: files of one directory share a shard; for a fixed N
+    ///   every file is owned by exactly one shard
+    pub fn shard_owns_file_path(&self, root: &Path, file_path: &Path) -> bool {
+        let relative = file_path.strip_prefix(root).unwrap_or(file_path);
+        let directory = relative
+            .parent()
+            .map(|dir| dir.components().map(|c| c.as_os_str().to_string_lossy()).collect::<Vec<_>>().join("/"))
+            .unwrap_or_default();
+        (fnv1a_directory_hash(&directory) % self.count as u64) as usize + 1 == self.index
+    }
+}
+
+fn fnv1a_directory_hash(directory: &str) -> u64 {
+    directory.bytes().fold(0xcbf2_9ce4_8422_2325_u64, |hash, byte| {
+        (hash ^ byte as u64).wrapping_mul(0x0100_0000_01b3)
+    })
+}
+
+/// Entities, edges and file hashes read from one shard database
+///
+/// # 4-Word Name: ShardGraphPartContents
+#[derive(Debug, Clone, Default)]
+pub struct ShardGraphPartContents {
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    /// File path → content hash (incremental baseline)
+    pub file_hashes: HashMap<String, String>,
+}
+
+/// Union of every shard, before cross-file resolution
+///
+/// # 4-Word Name: MergedShardGraphUnion
+#[derive(Debug, Clone, Default)]
+pub struct MergedShardGraphUnion {
+    pub entities: Vec<CodeEntity>,
+    pub edges: Vec<DependencyEdge>,
+    /// Sorted by path
+    pub file_hashes: Vec<(String, String)>,
+    /// Files present in more than one shard (mismatched N or directory)
+    pub overlapping_files: Vec<String>,
+}
+
+/// Union of shard graphs: entities by key, edges by (from, to, type)
+///
+/// # 4-Word Name: merge_shard_graph_parts
+///
+/// # Contract
+/// - Postcondition: the first shard's copy wins for shared keys (external
+///   placeholders appear in every shard that references them)
+pub fn merge_shard_graph_parts(parts: Vec<ShardGraphPartContents>) -> MergedShardGraphUnion {
+    let mut merged = MergedShardGraphUnion::default();
+    let mut entity_keys: HashSet<String> = HashSet::new();
+    let mut edge_keys: HashSet<(String, String, String)> = HashSet::new();
+    let mut hashes: HashMap<String, String> = HashMap::new();
+    let mut overlapping: HashSet<String> = HashSet::new();
+
+    for part in parts {
+        for entity in part.entities {
+            if entity_keys.insert(entity.isgl1_key.clone()) {
+                merged.entities.push(entity);
+            }
+        }
+        for edge in part.edges {
+            let key = (
+                edge.from_key.as_str().to_string(),
+                edge.to_key.as_str().to_string(),
+                edge.edge_type.as_str().to_string(),
+            );
+            if edge_keys.insert(key) {
+                merged.edges.push(edge);
+            }
+        }
+        for (path, hash) in part.file_hashes {
+            if hashes.insert(path.clone(), hash).is_some() {
+                overlapping.insert(path);
+            }
+        }
+    }
+
+    merged.file_hashes = hashes.into_iter().collect();
+    merged.file_hashes.sort();
+    merged.overlapping_files = overlapping.into_iter().collect();
+    merged.overlapping_files.sort();
+    merged
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::EdgeType;
+    use std::path::PathBuf;
+
+    #[test]
+    fn test_shards_partition_directories_and_merge_dedupes() {
+        assert_eq!("2/4".parse::<IngestDirectoryShardSpec>(), Ok(IngestDirectoryShardSpec { index: 2, count: 4 }));
+        assert!("0/4".parse::<IngestDirectoryShardSpec>().is_err());
+        assert!("5/4".parse::<IngestDirectoryShardSpec>().is_err());
+        assert!("2".parse::<IngestDirectoryShardSpec>().is_err());
+
+        let root = PathBuf::from("repo");
+        let shards: Vec<IngestDirectoryShardSpec> = (1..=3).map(|index| IngestDirectoryShardSpec { index, count: 3 }).collect();
+        for dir in ["", "cmd/api", "internal/store", "internal/store/sql", "pkg/a", "pkg/b", "web"] {
+            let file = root.join(dir).join("main.go");
+            let owners: Vec<usize> = shards.iter().filter(|s| s.shard_owns_file_path(&root, &file)).map(|s| s.index).collect();
+            assert_eq!(owners.len(), 1, "{} owned by {:?}", dir, owners);
+            let sibling = root.join(dir).join("other.go");
+            assert!(shards[owners[0] - 1].shard_owns_file_path(&root, &sibling));
+        }
+
+        let edge = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+        };
+        let placeholder = "go:fn:Save:unresolved-reference:0-0";
+        let part = |from: &str, file: &str| ShardGraphPartContents {
+            entities: Vec::new(),
+            edges: vec![edge(from, placeholder)],
+            file_hashes: HashMap::from([(file.to_string(), "h".to_string())]),
+        };
+        let merged = merge_shard_graph_parts(vec![
+            part("go:fn:A:__a:T1", "a/a.go"),
+            part("go:fn:B:__b:T2", "b/b.go"),
+            part("go:fn:A:__a:T1", "a/a.go"),
+        ]);
+        assert_eq!(merged.edges.len(), 2);
+        assert_eq!(merged.file_hashes.len(), 2);
+        assert_eq!(merged.overlapping_files, vec!["a/a.go".to_string()]);
+    }
+}
@@ -1,5 +1,6 @@
 //! File streaming implementation for folder-to-cozoDB processing.
 
+use std::collections::{BTreeMap, HashMap, HashSet};
 use std::path::{Path, PathBuf};
 use std::sync::Arc;
 use std::time::Instant;
@@ -12,12 +13,90 @@ use rayon::prelude::*;
 
 use parseltongue_core::entities::*;
 use parseltongue_core::storage::CozoDbStorage;
+use parseltongue_core::storage::relational_sqlite_graph_store::{export_cozo_graph_to_sqlite, SqliteSnapshotWriteCounts};
 use parseltongue_core::storage::path_utils::normalize_split_file_path;
 use parseltongue_core::query_extractor::{count_top_level_comment_words, compute_import_word_count_safely};
+use parseltongue_core::structural_interface_matcher::{
+    compute_go_structural_edges_touching, compute_go_structural_implements_edges,
+};
+use parseltongue_core::go_embedding_promotion_resolver::{
+    collect_go_dirty_subgraph_keys, is_go_entity_file_path, resolve_go_embedding_promoted_calls,
+    revert_go_resolved_edge_targets, UNRESOLVED_TARGET_METADATA_KEY,
+};
+use parseltongue_core::go_build_constraint_evaluator::{
+    extract_go_file_constraint, is_go_file_in_build, BUILD_CONSTRAINT_METADATA_KEY,
+};
+use parseltongue_core::derived_symbol_artifact_invalidator::{
+    collect_derived_artifact_carryover, restore_unchanged_derived_artifacts,
+};
+use parseltongue_core::edge_confidence_provenance_scorer::stamp_edge_confidence_provenance;
+use parseltongue_core::external_plugin_process_protocol::{
+    enrich_graph_with_plugin, extract_files_with_plugin, plugin_file_extensions,
+};
+use parseltongue_core::config_file_reference_linker::link_config_reference_edges;
+use parseltongue_core::embedded_sql_table_linker::{load_sql_schema_entities, resolve_embedded_sql_table_edges};
+use parseltongue_core::http_route_handler_mapper::resolve_http_route_handler_edges;
+use parseltongue_core::protobuf_service_definition_linker::{
+    find_contract_files_by_extension, link_grpc_stub_edges, load_proto_service_entities,
+};
+use parseltongue_core::go_error_propagation_extractor::derive_go_error_propagation_edges;
+use parseltongue_core::go_generate_lineage_tracker::link_go_generated_file_lineage;
+use parseltongue_core::go_typed_selector_resolver::{apply_go_typed_call_targets, load_go_typed_call_targets};
+use parseltongue_core::go_module_version_resolver::annotate_go_external_placeholder_entities;
+use parseltongue_core::go_test_coverage_linker::{
+    compute_go_test_coverage_edges, is_go_test_file_path, TEST_FACET_METADATA_KEY, TEST_FACET_METADATA_VALUE,
+};
+use parseltongue_core::codeowners_ownership_rule_matcher::annotate_entities_with_codeowners;
+use parseltongue_core::git_blame_ownership_annotator::annotate_entities_with_git_blame;
+use parseltongue_core::otlp_trace_span_exporter::{
+    otlp_endpoint_from_environment, post_otlp_trace_export_blocking, telemetry_service_name_or,
+    DEFAULT_TELEMETRY_SERVICE_NAME,
+};
+use parseltongue_core::isgl1_v2::compute_content_hash;
+use parseltongue_core::inline_suppression_directive_parser::extract_inline_directive_metadata;
+use parseltongue_core::colliding_symbol_key_disambiguator::disambiguate_colliding_symbol_keys;
+use parseltongue_core::symbol_centrality_score_ranker::refresh_stored_symbol_centrality_scores;
+use parseltongue_core::extractor_capability_manifest_builder::{
+    build_installed_capability_manifest, diff_capability_manifest_pair,
+};
+use parseltongue_core::python_import_call_resolver::{is_python_entity_file_path, resolve_python_import_calls};
+use parseltongue_core::c_include_header_resolver::{is_c_family_file_path, resolve_c_include_header_edges};
+use parseltongue_core::ffi_symbol_bridge_linker::link_ffi_bridge_edges;
+use parseltongue_core::jvm_type_hierarchy_resolver::{is_jvm_entity_file_path, resolve_jvm_type_references};
+use parseltongue_core::rust_cfg_feature_evaluator::{
+    extract_rust_cfg_regions, rust_item_cfg_condition, RustCfgRegionSpan, RustCrateFeatureIndex,
+    CFG_CONDITION_METADATA_KEY,
+};
+use parseltongue_core::symbol_doc_signature_extractor::{
+    extract_symbol_attribute_lines, extract_symbol_doc_comment, extract_symbol_signature_line,
+    infer_symbol_visibility_level, ATTRIBUTES_METADATA_KEY, SIGNATURE_METADATA_KEY,
+};
+use parseltongue_core::workspace_module_root_detector::{
+    annotate_go_edges_per_module, detect_workspace_module_roots, tag_entities_with_workspace_module,
+    WorkspaceModuleRootEntry,
+};
+use parseltongue_core::dynamic_dispatch_call_expander::expand_dynamic_dispatch_call_edges;
+use parseltongue_core::javascript_module_import_resolver::{
+    is_javascript_entity_file_path, load_javascript_module_config, resolve_javascript_module_calls,
+    BINDING_KIND_METADATA_KEY,
+};
 use crate::errors::*;
 use crate::external_dependency_handler::extract_placeholders_from_edges_deduplicated;
+use crate::incremental_graph_relink_planner::{
+    diff_relinked_edge_sets, is_contract_file_path, is_pass_synthesized_entity_key, is_repo_wide_pass_edge,
+    revert_bound_edge_targets, CONTRACT_FILE_EXTENSIONS,
+};
+use crate::incremental_hash_change_planner::{collect_changed_go_packages, plan_file_changes_by_hash};
+use crate::ingest_progress_event_reporter::IngestProgressEventReporter;
+use crate::gitignore_hierarchy_path_matcher::GitignoreHierarchyPathMatcher;
 use crate::isgl1_generator::*;
 use crate::lsp_client::*;
+use crate::parse_failure_quarantine_guard::{
+    format_quarantine_error_line, run_file_parse_guarded, QUARANTINE_IGNORED_FILE_REASON,
+};
+use crate::parse_worker_pool_runner::{resolve_parse_worker_count, run_on_parse_worker_pool};
+use crate::persistent_parse_artifact_cache::{CachedFileParseArtifacts, ParseArtifactCacheStore};
+use crate::sharded_ingest_graph_merger::{merge_shard_graph_parts, ShardGraphPartContents};
 use crate::test_detector::{TestDetector, EntityClass};
 use crate::StreamerConfig;
 
@@ -35,6 +114,14 @@ pub trait FileStreamer: Send + Sync {
     /// v1.5.4: Parallel implementation using Rayon for 5-7x speedup on multi-core systems
     async fn stream_directory_with_parallel_rayon(&self) -> Result<StreamResult>;
 
+    /// Re-ingest only files whose content hash changed since the last run
+    ///
+    /// v1.7.3: Reuses stored entities/edges of unchanged files and recomputes
+    /// cross-file Go edges only for the changed subgraph; edges of unchanged
+    /// files into removed entities are re-resolved or dropped, and the
+    /// repo-wide passes run again over the whole graph
+    async fn stream_directory_incremental_by_hash(&self) -> Result<StreamResult>;
+
     /// Stream a single file to database
     async fn stream_file(&self, file_path: &Path) -> Result<FileResult>;
 
@@ -45,6 +132,11 @@ pub trait FileStreamer: Send + Sync {
     ///
     /// # 4-Word Name: backup_to_sqlite
     async fn backup_to_sqlite(&self, path: &str) -> Result<()>;
+
+    /// Mirror the graph into relational SQLite tables (v1.7.3)
+    ///
+    /// # 4-Word Name: export_relational_sqlite_graph
+    async fn export_relational_sqlite_graph(&self, path: &str) -> Result<SqliteSnapshotWriteCounts>;
 }
 
 /// Streaming operation results
@@ -55,6 +147,10 @@ pub struct StreamResult {
     pub entities_created: usize,
     pub errors: Vec<String>,
     pub duration: std::time::Duration,
+    /// Milliseconds per ingest phase: `scan`, `parse`, `resolve`, `store` (v1.7.3)
+    pub phase_timings_ms: BTreeMap<&'static str, u64>,
+    /// Bytes of source handed to the parse phase (v1.7.3)
+    pub input_bytes: u64,
 }
 
 /// Single file processing result
@@ -64,6 +160,8 @@ pub struct FileResult {
     pub entities_created: usize,
     pub success: bool,
     pub error: Option<String>,
+    /// SHA-256 of the file content (v1.7.3: FileHashCache baseline)
+    pub content_hash: Option<String>,
 }
 
 /// Streaming statistics
@@ -84,6 +182,10 @@ pub struct FileStreamerImpl {
     test_detector: Arc<dyn TestDetector>,
     db: Arc<CozoDbStorage>,
     stats: std::sync::Mutex<StreamStats>,
+    gitignore_matcher: GitignoreHierarchyPathMatcher,
+    rust_feature_index: RustCrateFeatureIndex,
+    /// v1.7.3: `--parse-cache` artifacts shared across runs and repositories
+    parse_cache: Option<ParseArtifactCacheStore>,
 }
 
 impl FileStreamerImpl {
@@ -100,16 +202,22 @@ impl FileStreamerImpl {
                 details: format!("Failed to create database: {}", e),
             })?;
 
-        // Create schema
-        db.create_schema()
-            .await
-            .map_err(|e| StreamerError::StorageError {
-                details: format!("Failed to create schema: {}", e),
-            })?;
+        // Create schema (v1.7.3: reopening an existing database keeps its schema)
+        let existing_relations = db.list_relations().await.unwrap_or_default();
+        if !existing_relations.iter().any(|r| r == "CodeGraph") {
+            db.create_schema()
+                .await
+                .map_err(|e| StreamerError::StorageError {
+                    details: format!("Failed to create schema: {}", e),
+                })?;
+        }
 
         // Initialize LSP client (graceful degradation if unavailable)
         let lsp_client = RustAnalyzerClientImpl::new().await;
 
+        let gitignore_matcher = GitignoreHierarchyPathMatcher::for_ingest_root(&config.root_dir, config.respect_gitignore);
+        let rust_feature_index = RustCrateFeatureIndex::for_ingest_root(&config.root_dir, config.rust_features.clone());
+        let parse_cache = config.parse_cache_dir.clone().map(ParseArtifactCacheStore::open_parse_cache_store);
         Ok(Self {
             config,
             key_generator,
@@ -117,6 +225,49 @@ impl FileStreamerImpl {
             test_detector,
             db: Arc::new(db),
             stats: std::sync::Mutex::new(StreamStats::default()),
+            gitignore_matcher,
+            rust_feature_index,
+            parse_cache,
+        })
+    }
+
+    /// Create file streamer writing into an already-open database (v1.7.3)
+    ///
+    /// # 4-Word Name: new_with_shared_storage
+    ///
+    /// Used by the watch daemon: the in-memory graph the HTTP server queries
+    /// must be the same instance the streamer ingests into. `config.db_path`
+    /// is ignored.
+    pub async fn new_with_shared_storage(
+        config: StreamerConfig,
+        key_generator: Arc<dyn Isgl1KeyGenerator>,
+        test_detector: Arc<dyn TestDetector>,
+        db: Arc<CozoDbStorage>,
+    ) -> Result<Self> {
+        let existing_relations = db.list_relations().await.unwrap_or_default();
+        if !existing_relations.iter().any(|r| r == "CodeGraph") {
+            db.create_schema()
+                .await
+                .map_err(|e| StreamerError::StorageError {
+                    details: format!("Failed to create schema: {}", e),
+                })?;
+        }
+
+        let lsp_client = RustAnalyzerClientImpl::new().await;
+
+        let gitignore_matcher = GitignoreHierarchyPathMatcher::for_ingest_root(&config.root_dir, config.respect_gitignore);
+        let rust_feature_index = RustCrateFeatureIndex::for_ingest_root(&config.root_dir, config.rust_features.clone());
+        let parse_cache = config.parse_cache_dir.clone().map(ParseArtifactCacheStore::open_parse_cache_store);
+        Ok(Self {
+            config,
+            key_generator,
+            lsp_client: Arc::new(lsp_client),
+            test_detector,
+            db,
+            stats: std::sync::Mutex::new(StreamStats::default()),
+            gitignore_matcher,
+            rust_feature_index,
+            parse_cache,
         })
     }
 
@@ -142,6 +293,9 @@ impl FileStreamerImpl {
                 details: format!("Failed to create schema: {}", e),
             })?;
 
+        let gitignore_matcher = GitignoreHierarchyPathMatcher::for_ingest_root(&config.root_dir, config.respect_gitignore);
+        let rust_feature_index = RustCrateFeatureIndex::for_ingest_root(&config.root_dir, config.rust_features.clone());
+        let parse_cache = config.parse_cache_dir.clone().map(ParseArtifactCacheStore::open_parse_cache_store);
         Ok(Self {
             config,
             key_generator,
@@ -149,6 +303,9 @@ impl FileStreamerImpl {
             test_detector,
             db: Arc::new(db),
             stats: std::sync::Mutex::new(StreamStats::default()),
+            gitignore_matcher,
+            rust_feature_index,
+            parse_cache,
         })
     }
 
@@ -160,15 +317,26 @@ impl FileStreamerImpl {
         source_code: &str,
         file_path: &Path,
     ) -> std::result::Result<CodeEntity, parseltongue_core::error::ParseltongError> {
+        // Extract the code snippet from the source
+        let code_snippet = self.extract_code_snippet(source_code, parsed.line_range.0, parsed.line_range.1);
+
+        // v1.7.3: Doc comment, declaration signature and visibility live on the node
+        let signature_line = extract_symbol_signature_line(&code_snippet);
+
         // Create InterfaceSignature
         let interface_signature = InterfaceSignature {
             entity_type: self.convert_entity_type(&parsed.entity_type),
             name: parsed.name.clone(),
-            visibility: Visibility::Public, // Default to public for now
+            visibility: infer_symbol_visibility_level(&signature_line, &parsed.name, parsed.language),
             file_path: PathBuf::from(&parsed.file_path),
             line_range: LineRange::new(parsed.line_range.0 as u32, parsed.line_range.1 as u32)?,
             module_path: self.derive_file_module_path(file_path),
-            documentation: None,
+            documentation: extract_symbol_doc_comment(
+                source_code,
+                parsed.line_range.0,
+                parsed.line_range.1,
+                parsed.language,
+            ),
             language_specific: self.create_language_signature(&parsed.language),
         };
 
@@ -187,9 +355,6 @@ impl FileStreamerImpl {
         
         let mut entity = CodeEntity::new(isgl1_key.to_string(), interface_signature, entity_class)?;
 
-        // Extract the code snippet from the source
-        let code_snippet = self.extract_code_snippet(source_code, parsed.line_range.0, parsed.line_range.1);
-
         // ISGL1 v2: Compute v2 fields BEFORE code_snippet is moved
         use parseltongue_core::isgl1_v2::{
             compute_birth_timestamp,
@@ -208,6 +373,33 @@ impl FileStreamerImpl {
         // GREEN Phase: Apply TDD classification based on parsed metadata
         entity.tdd_classification = self.classify_entity(parsed);
 
+        // Carry extractor facts (receiver_type, interface_methods, ...) for cross-file passes
+        entity.metadata.additional.extend(parsed.metadata.clone());
+        if !signature_line.is_empty() {
+            entity.metadata.additional.insert(SIGNATURE_METADATA_KEY.to_string(), signature_line);
+        }
+        if let Some(attributes) = extract_symbol_attribute_lines(source_code, parsed.line_range.0, parsed.language) {
+            entity.metadata.additional.insert(ATTRIBUTES_METADATA_KEY.to_string(), attributes);
+        }
+
+        // v1.7.3: `//companion:ignore` / `//companion:entrypoint` directives on the declaration
+        entity
+            .metadata
+            .additional
+            .extend(extract_inline_directive_metadata(source_code, parsed.line_range.0, parsed.language));
+
+        // v1.7.3: Go `_test.go` entities stay in the graph, tagged with the `test` facet
+        if is_go_test_file_path(file_path) {
+            entity.metadata.additional.insert(TEST_FACET_METADATA_KEY.to_string(), TEST_FACET_METADATA_VALUE.to_string());
+        }
+
+        // v1.7.3: Overlay mode keeps every Go file; tag entities with the file's build constraint
+        if self.config.go_build_context.index_all_configurations && is_go_entity_file_path(file_path) {
+            if let Some(constraint) = extract_go_file_constraint(file_path, source_code) {
+                entity.metadata.additional.insert(BUILD_CONSTRAINT_METADATA_KEY.to_string(), constraint);
+            }
+        }
+
         Ok(entity)
     }
 
@@ -258,6 +450,7 @@ impl FileStreamerImpl {
             crate::isgl1_generator::EntityType::Method => parseltongue_core::entities::EntityType::Method,
             crate::isgl1_generator::EntityType::Module => parseltongue_core::entities::EntityType::Module,
             crate::isgl1_generator::EntityType::Variable => parseltongue_core::entities::EntityType::Variable,
+            crate::isgl1_generator::EntityType::Constant => parseltongue_core::entities::EntityType::Constant,
 
             // Pragmatic mappings (v0.8.9 MVP)
             crate::isgl1_generator::EntityType::Namespace => parseltongue_core::entities::EntityType::Module,   // C++/C# namespace → Module
@@ -275,6 +468,9 @@ impl FileStreamerImpl {
             // SQL-specific entities (v1.5.6)
             crate::isgl1_generator::EntityType::Table => parseltongue_core::entities::EntityType::Table,
             crate::isgl1_generator::EntityType::View => parseltongue_core::entities::EntityType::View,
+
+            // HTTP routes (v1.7.3)
+            crate::isgl1_generator::EntityType::Route => parseltongue_core::entities::EntityType::Route,
         }
     }
 
@@ -378,9 +574,24 @@ impl FileStreamerImpl {
             return false;
         }
 
-        // Check exclude patterns
-        for pattern in &self.config.exclude_patterns {
-            if self.matches_pattern(&path_str, pattern) {
+        // v1.7.3: Allow-listed paths (e.g. generated code) skip every exclusion below
+        let relative_path = file_path.strip_prefix(&self.config.root_dir).unwrap_or(file_path);
+        let rules = self.config.path_filter_rules.as_ref();
+        if !rules.is_some_and(|rules| rules.is_allow_listed_path(relative_path)) {
+            // Check exclude patterns
+            for pattern in &self.config.exclude_patterns {
+                if self.matches_pattern(&path_str, pattern) {
+                    return false;
+                }
+            }
+
+            // v1.7.3: .gitignore'd files (vendored trees, build output)
+            if self.gitignore_matcher.is_path_git_ignored(file_path) {
+                return false;
+            }
+
+            // v1.7.3: Project config file / --exclude languages, globs, directory overrides
+            if rules.is_some_and(|rules| !rules.allows_ingest_relative_path(relative_path)) {
                 return false;
             }
         }
@@ -395,6 +606,152 @@ impl FileStreamerImpl {
         false
     }
 
+    /// Check if a Go file is left out of the configured build (v1.7.3)
+    ///
+    /// `//go:build` / `// +build` headers and `_GOOS` / `_GOARCH` suffixes are
+    /// evaluated against `config.go_build_context`; non-Go files are never excluded.
+    fn is_outside_go_build_context(&self, file_path: &Path, source: &str) -> bool {
+        is_go_entity_file_path(file_path) && !is_go_file_in_build(file_path, source, &self.config.go_build_context)
+    }
+
+    /// `#[cfg]` regions of a `.rs` file; empty for other languages (v1.7.3)
+    fn extract_rust_file_cfg_regions(file_path: &Path, source: &str) -> Vec<RustCfgRegionSpan> {
+        if file_path.extension().and_then(|e| e.to_str()) == Some("rs") {
+            extract_rust_cfg_regions(source)
+        } else {
+            Vec::new()
+        }
+    }
+
+    /// Tag a Rust entity with its `#[cfg]` condition (v1.7.3)
+    ///
+    /// Returns false when the configured Cargo feature selection compiles the
+    /// entity out; ungated entities and non-Rust files are always kept.
+    fn apply_rust_cfg_condition(
+        &self,
+        entity: &mut CodeEntity,
+        line_range: (usize, usize),
+        cfg_regions: &[RustCfgRegionSpan],
+        file_path: &Path,
+    ) -> bool {
+        let Some(condition) = rust_item_cfg_condition(cfg_regions, line_range.0, line_range.1) else {
+            return true;
+        };
+        let compiled_in = self.rust_feature_index.is_condition_compiled_in(file_path, &condition);
+        entity.metadata.additional.insert(CFG_CONDITION_METADATA_KEY.to_string(), condition);
+        compiled_in
+    }
+
+    /// Per-file parse on the worker pool, under the quarantine guard with `--quarantine` (v1.7.3)
+    fn process_file_for_worker_pool(
+        &self,
+        file_path: &Path,
+    ) -> Result<(FileResult, Vec<CodeEntity>, Vec<parseltongue_core::entities::DependencyEdge>, Vec<ExcludedTestEntity>, Vec<FileWordCoverageRow>)> {
+        if self.config.quarantine_parse_failures {
+            run_file_parse_guarded(file_path, || self.process_file_sync_for_parallel(file_path))
+        } else {
+            self.process_file_sync_for_parallel(file_path)
+        }
+    }
+
+    /// ISGL1 keys of one file's entities, same-name collisions split (v1.7.3)
+    ///
+    /// Colliding keys are re-derived from the declaration signature hash and
+    /// the file's edges re-pointed (see `colliding_symbol_key_disambiguator`),
+    /// so keys never depend on which duplicate happens to be written last.
+    fn generate_stable_entity_keys(
+        &self,
+        parsed_entities: &[ParsedEntity],
+        content: &str,
+        dependencies: &mut [DependencyEdge],
+    ) -> Result<Vec<String>> {
+        let mut keys = parsed_entities
+            .iter()
+            .map(|entity| self.key_generator.generate_key(entity))
+            .collect::<Result<Vec<String>>>()?;
+        let line_ranges: Vec<(usize, usize)> = parsed_entities.iter().map(|entity| entity.line_range).collect();
+        disambiguate_colliding_symbol_keys(&mut keys, &line_ranges, content, dependencies);
+        Ok(keys)
+    }
+
+    /// Ignored-file row of a file skipped by `--quarantine` (v1.7.3)
+    fn quarantined_ignored_file_row(&self, file_path: &Path) -> IgnoredFileRow {
+        let (folder_path, filename) = normalize_split_file_path(file_path, &self.config.root_dir);
+        IgnoredFileRow {
+            folder_path,
+            filename,
+            extension: file_path.extension().map(|e| e.to_string_lossy().to_string()).unwrap_or_default(),
+            reason: QUARANTINE_IGNORED_FILE_REASON.to_string(),
+        }
+    }
+
+    /// Go build-constraint exclusion for walked files, recorded as an ignored file (v1.7.3)
+    fn record_go_build_exclusion(&self, file_path: &Path, ignored_files: &mut Vec<IgnoredFileRow>) -> bool {
+        if !is_go_entity_file_path(file_path) {
+            return false;
+        }
+        let Ok(source) = std::fs::read_to_string(file_path) else {
+            return false;
+        };
+        if !self.is_outside_go_build_context(file_path, &source) {
+            return false;
+        }
+        let (folder_path, filename) = normalize_split_file_path(file_path, &self.config.root_dir);
+        ignored_files.push(IgnoredFileRow {
+            folder_path,
+            filename,
+            extension: "go".to_string(),
+            reason: "build_constraint".to_string(),
+        });
+        true
+    }
+
+    /// Parse every selected file and hand each file's entities/edges to `on_file`
+    /// without storing anything (v1.7.3: streaming exports)
+    ///
+    /// # 4-Word Name: for_each_parsed_file_batch
+    ///
+    /// # Contract
+    /// - Postcondition: files are visited in sorted path order, parsed on the
+    ///   bounded worker pool a chunk at a time, so memory stays proportional
+    ///   to the chunk rather than the repository; returns the file count
+    /// - Postcondition: edges are as extracted per file; cross-file passes
+    ///   (Go embedding/interfaces, Python/JS/JVM import resolution) need the
+    ///   whole graph and are not applied
+    /// - Error: the first error `on_file` returns; unreadable or unparsable
+    ///   files are skipped
+    pub fn for_each_parsed_file_batch<F>(&self, mut on_file: F) -> std::io::Result<usize>
+    where
+        F: FnMut(&[CodeEntity], &[parseltongue_core::entities::DependencyEdge]) -> std::io::Result<()>,
+    {
+        let mut ignored_files: Vec<IgnoredFileRow> = Vec::new();
+        let mut files: Vec<PathBuf> = WalkDir::new(&self.config.root_dir)
+            .follow_links(false)
+            .into_iter()
+            .filter_map(|entry| entry.ok())
+            .map(|entry| entry.into_path())
+            .filter(|path| path.is_file() && self.should_process_file(path))
+            .filter(|path| !self.record_go_build_exclusion(path, &mut ignored_files))
+            .collect();
+        files.sort();
+
+        let workspace_modules = detect_workspace_module_roots(&self.config.root_dir);
+        let workers = resolve_parse_worker_count(self.config.worker_threads);
+        for chunk in files.chunks(workers * 4) {
+            let parsed: Vec<_> = run_on_parse_worker_pool(workers, || {
+                chunk
+                    .par_iter()
+                    .map(|file_path| self.process_file_for_worker_pool(file_path))
+                    .collect()
+            });
+            for (_, mut entities, dependencies, _, _) in parsed.into_iter().flatten() {
+                tag_entities_with_workspace_module(&mut entities, &self.config.root_dir, &workspace_modules);
+                on_file(&entities, &dependencies)?;
+            }
+        }
+        Ok(files.len())
+    }
+
     /// Simple glob pattern matching
     fn matches_pattern(&self, path: &str, pattern: &str) -> bool {
         if pattern.contains('*') {
@@ -489,6 +846,22 @@ impl FileStreamerImpl {
 
 }
 
+/// Feed one worker's file result into the progress counters
+fn record_worker_result_progress(
+    progress: &IngestProgressEventReporter,
+    result: &Result<(FileResult, Vec<CodeEntity>, Vec<parseltongue_core::entities::DependencyEdge>, Vec<ExcludedTestEntity>, Vec<FileWordCoverageRow>)>,
+) {
+    match result {
+        Ok((_, entities, dependencies, ..)) => progress.record_parsed_file_counts(entities.len(), dependencies),
+        Err(_) => progress.record_failed_file_parse(),
+    }
+}
+
+/// Total on-disk size of the files queued for parsing
+fn sum_source_file_sizes(paths: &[PathBuf]) -> u64 {
+    paths.iter().filter_map(|path| std::fs::metadata(path).ok()).map(|meta| meta.len()).sum()
+}
+
 #[async_trait::async_trait]
 impl FileStreamer for FileStreamerImpl {
     async fn stream_directory(&self) -> Result<StreamResult> {
@@ -556,6 +929,9 @@ impl FileStreamer for FileStreamerImpl {
                     }
                     continue;
                 }
+                if self.record_go_build_exclusion(path, &mut ignored_files) {
+                    continue;
+                }
 
                 total_files += 1;
                 pb.set_message(format!("Processing: {}", path.display()));
@@ -591,6 +967,12 @@ impl FileStreamer for FileStreamerImpl {
 
         pb.finish_with_message("Directory streaming completed");
 
+        // v1.7.3: Centrality scores used by context packing and search ranking
+        if let Err(e) = refresh_stored_symbol_centrality_scores(&self.db).await {
+            errors.push(format!("[v1.7.3] Failed to store centrality scores: {}", e));
+        }
+        errors.extend(self.record_installed_capability_manifest().await);
+
         let duration = start_time.elapsed();
 
         // Get final stats for CODE/TEST breakdown
@@ -634,6 +1016,8 @@ impl FileStreamer for FileStreamerImpl {
             entities_created,
             errors,
             duration,
+            phase_timings_ms: BTreeMap::new(),
+            input_bytes: 0,
         })
     }
 
@@ -673,18 +1057,12 @@ impl FileStreamer for FileStreamerImpl {
             style("Starting PARALLEL directory streaming (v1.5.4 Rayon)...").blue().bold()
         );
 
-        // Setup progress bar
-        let pb = ProgressBar::new_spinner();
-        pb.set_style(
-            ProgressStyle::default_spinner()
-                .template("{spinner:.green} [{elapsed_precise}] {msg}")
-                .unwrap()
-        );
-        pb.set_message("Scanning files...");
+        // v1.7.3: Bar, JSON lines or nothing, per --progress
+        let progress = IngestProgressEventReporter::create_ingest_progress_reporter(self.config.progress_output);
 
         // Step 1: Collect all file paths upfront (trade-off: memory for parallelism)
         let mut walk_errors: Vec<String> = Vec::new();
-        let files_to_process: Vec<PathBuf> = WalkDir::new(&self.config.root_dir)
+        let mut files_to_process: Vec<PathBuf> = WalkDir::new(&self.config.root_dir)
             .follow_links(false)
             .into_iter()
             .filter_map(|entry_result| match entry_result {
@@ -698,6 +1076,10 @@ impl FileStreamer for FileStreamerImpl {
             .filter_map(|entry| {
                 let path = entry.path();
                 if path.is_file() {
+                    // v1.7.3: Other shards' directories are neither parsed nor reported as ignored
+                    if self.config.shard.is_some_and(|shard| !shard.shard_owns_file_path(&self.config.root_dir, path)) {
+                        return None;
+                    }
                     // v1.6.5 Wave 1: Collect ignored files
                     if !self.should_process_file(path) {
                         if Language::from_file_path(path).is_none() {
@@ -717,6 +1099,9 @@ impl FileStreamer for FileStreamerImpl {
                         }
                         return None;
                     }
+                    if self.record_go_build_exclusion(path, &mut ignored_files) {
+                        return None;
+                    }
                     Some(path.to_path_buf())
                 } else {
                     None
@@ -724,22 +1109,28 @@ impl FileStreamer for FileStreamerImpl {
             })
             .collect();
 
+        // v1.7.3: Sorted input + ordered collect = deterministic merge phase
+        files_to_process.sort();
         let total_files = files_to_process.len();
-        pb.set_message(format!("Found {} files, processing in parallel...", total_files));
+        let input_bytes = sum_source_file_sizes(&files_to_process);
+        let workers = resolve_parse_worker_count(self.config.worker_threads);
+        progress.begin_file_parse_phase(total_files, workers);
 
-        // Step 2: Process files in parallel using Rayon
+        // Step 2: Process files in parallel on a bounded Rayon pool
         // Note: tree-sitter Parser is !Send, so we use thread-local initialization
         let results: Vec<Result<(FileResult, Vec<CodeEntity>, Vec<parseltongue_core::entities::DependencyEdge>, Vec<ExcludedTestEntity>, Vec<FileWordCoverageRow>)>> =
-            files_to_process
-                .par_iter()
-                .map(|file_path| {
-                    // Synchronous file processing (trade-off: blocking I/O for simplicity)
-                    // In parallel context, async adds complexity without benefit
-                    self.process_file_sync_for_parallel(file_path)
-                })
-                .collect();
-
-        pb.finish_with_message("Parallel processing completed, inserting to database...");
+            run_on_parse_worker_pool(workers, || {
+                files_to_process
+                    .par_iter()
+                    .map(|file_path| {
+                        // Synchronous file processing (trade-off: blocking I/O for simplicity)
+                        // In parallel context, async adds complexity without benefit
+                        let result = self.process_file_for_worker_pool(file_path);
+                        record_worker_result_progress(&progress, &result);
+                        result
+                    })
+                    .collect()
+            });
 
         // Step 3: Aggregate results and collect entities/edges for batch insertion
         let mut processed_files = 0;
@@ -749,12 +1140,16 @@ impl FileStreamer for FileStreamerImpl {
         let mut all_dependencies: Vec<parseltongue_core::entities::DependencyEdge> = Vec::new();
         let mut all_excluded_tests: Vec<ExcludedTestEntity> = Vec::new();
         let mut all_word_coverages: Vec<FileWordCoverageRow> = Vec::new();
+        let mut file_hashes: Vec<(String, String)> = Vec::new();
 
         for result in results {
             match result {
                 Ok((file_result, entities, dependencies, excluded_tests, word_coverages)) => {
                     processed_files += 1;
                     entities_created += file_result.entities_created;
+                    if let Some(hash) = &file_result.content_hash {
+                        file_hashes.push((file_result.file_path.clone(), hash.clone()));
+                    }
                     all_entities.extend(entities);
                     all_dependencies.extend(dependencies);
                     all_excluded_tests.extend(excluded_tests);
@@ -763,6 +1158,10 @@ impl FileStreamer for FileStreamerImpl {
                         errors.push(error);
                     }
                 }
+                Err(StreamerError::ParseQuarantined { path, cause }) => {
+                    errors.push(format_quarantine_error_line(&path, &cause));
+                    ignored_files.push(self.quarantined_ignored_file_row(Path::new(&path)));
+                }
                 Err(e) => {
                     errors.push(format!("[EXTRACT_FAIL] Processing error: {}", e));
                 }
@@ -772,6 +1171,24 @@ impl FileStreamer for FileStreamerImpl {
         // Merge walk errors
         errors.extend(walk_errors);
 
+        progress.begin_named_ingest_phase("resolve", "Resolving cross-file references...");
+
+        // v1.7.3: A shard leaves cross-file references to `parseltongue merge`
+        if let Some(shard) = &self.config.shard {
+            println!("Shard {}: cross-file resolution deferred to `parseltongue merge`", shard);
+        } else {
+            self.resolve_cross_file_references(&mut all_entities, &mut all_dependencies);
+        }
+        // v1.7.3: Ownership facts (last commit/author, owner) from git blame
+        if self.config.git_blame {
+            annotate_entities_with_git_blame(&mut all_entities);
+        }
+        // v1.7.3: Owner teams from CODEOWNERS, when the repository has one
+        annotate_entities_with_codeowners(&mut all_entities, &self.config.root_dir);
+
+        progress.update_resolved_graph_totals(all_entities.len(), &all_dependencies);
+        progress.begin_named_ingest_phase("store", "Inserting to database...");
+
         // Step 4 & 5 & v1.6.5: Batch inserts for all 5 relations
         // Ensure dependency schema exists before writes
         if !all_dependencies.is_empty() {
@@ -869,7 +1286,21 @@ impl FileStreamer for FileStreamerImpl {
             ));
         }
 
+        // v1.7.3: Record hashes + extractor metadata as the incremental baseline
+        file_hashes.extend(self.hash_contract_files_on_disk());
+        errors.extend(self.persist_incremental_baseline_state(&file_hashes, &all_entities).await);
+
+        // v1.7.3: Centrality scores and extractor capabilities (merged graph only)
+        if self.config.shard.is_none() {
+            if let Err(e) = refresh_stored_symbol_centrality_scores(&self.db).await {
+                errors.push(format!("[v1.7.3] Failed to store centrality scores: {}", e));
+            }
+            errors.extend(self.record_installed_capability_manifest().await);
+        }
+
         let duration = start_time.elapsed();
+        let metrics = progress.finish_ingest_progress_report();
+        self.export_ingest_trace_spans(&progress, "full").await;
 
         // Get final stats for CODE/TEST breakdown
         let final_stats = self.get_stats();
@@ -884,8 +1315,10 @@ impl FileStreamer for FileStreamerImpl {
             style(final_stats.test_entities_created).yellow(),
             style("(excluded for optimal LLM context)").dim()
         );
+        println!("Edges created: {} ({} unresolved references)", metrics.edges, metrics.unresolved_refs);
         println!("Errors encountered: {}", errors.len());
         println!("Duration: {:?}", duration);
+        self.print_parse_cache_hit_summary();
         println!("Speedup estimate: {}",
             style("5-7x vs sequential (typical on 8+ cores)").cyan()
         );
@@ -910,6 +1343,407 @@ impl FileStreamer for FileStreamerImpl {
             entities_created,
             errors,
             duration,
+            phase_timings_ms: progress.phase_duration_totals_ms(),
+            input_bytes,
+        })
+    }
+
+    async fn stream_directory_incremental_by_hash(&self) -> Result<StreamResult> {
+        let start_time = Instant::now();
+
+        println!(
+            "{}",
+            style("Starting INCREMENTAL directory streaming (v1.7.3 content hash)...").blue().bold()
+        );
+
+        // Step 1: Ensure side relations exist (errors mean "already created")
+        let _ = self.db.create_dependency_edges_schema().await;
+        let _ = self.db.create_file_hash_cache_schema().await;
+        let _ = self.db.create_entity_extractor_metadata_schema().await;
+
+        // Step 2: Hash every processable file on disk
+        let mut errors: Vec<String> = Vec::new();
+        let mut current_hashes: HashMap<String, String> = HashMap::new();
+        for entry_result in WalkDir::new(&self.config.root_dir).follow_links(false) {
+            let entry = match entry_result {
+                Ok(e) => e,
+                Err(e) => {
+                    errors.push(format!("[WALK_ERROR] {}", e));
+                    continue;
+                }
+            };
+            let path = entry.path();
+            if !path.is_file() || !self.should_process_file(path) {
+                continue;
+            }
+            match std::fs::read_to_string(path) {
+                // v1.7.3: Files leaving the Go build configuration show up as deleted
+                Ok(content) if self.is_outside_go_build_context(path, &content) => {}
+                Ok(content) => {
+                    current_hashes.insert(path.to_string_lossy().to_string(), compute_content_hash(&content));
+                }
+                Err(e) => errors.push(format!("[READ_FAIL] {}: {}", path.display(), e)),
+            }
+        }
+        current_hashes.extend(self.hash_contract_files_on_disk());
+
+        // Step 3: Diff against the previous ingest
+        let cached_hashes = self.db.get_all_cached_file_hashes().await.unwrap_or_default();
+        let plan = plan_file_changes_by_hash(&current_hashes, &cached_hashes);
+        println!(
+            "  {} added, {} modified, {} deleted, {} unchanged (reused)",
+            style(plan.added.len()).green(),
+            style(plan.modified.len()).yellow(),
+            style(plan.deleted.len()).red(),
+            plan.unchanged_count
+        );
+
+        if plan.is_empty_change_set() {
+            println!("{}", style("✓ Database already up to date").green());
+            return Ok(StreamResult {
+                total_files: current_hashes.len(),
+                processed_files: 0,
+                entities_created: 0,
+                errors,
+                duration: start_time.elapsed(),
+                phase_timings_ms: BTreeMap::new(),
+                input_bytes: 0,
+            });
+        }
+
+        // Step 4: Remove stale rows of modified/deleted files
+        let stale_paths = plan.files_with_stale_rows();
+        let mut stale_entities: Vec<CodeEntity> = Vec::new();
+        for path in &stale_paths {
+            match self.db.get_entities_by_file_path(path).await {
+                Ok(entities) => stale_entities.extend(entities),
+                Err(e) => errors.push(format!("[DB_QUERY] {}: {}", path, e)),
+            }
+        }
+        let stale_keys: Vec<String> = stale_entities.iter().map(|e| e.isgl1_key.clone()).collect();
+        // v1.7.3: summaries of symbols that come back unchanged survive the re-parse
+        let stored_metadata = self.db.get_entity_extractor_metadata_map().await.unwrap_or_default();
+        let derived_carryover = collect_derived_artifact_carryover(&stale_entities, &stored_metadata);
+        drop(stored_metadata);
+        if let Err(e) = self.db.delete_edges_by_from_keys(&stale_keys).await {
+            errors.push(format!("[DB_DELETE] Failed to delete stale edges: {}", e));
+        }
+        if let Err(e) = self.db.delete_entities_batch_by_keys(&stale_keys).await {
+            errors.push(format!("[DB_DELETE] Failed to delete stale entities: {}", e));
+        }
+        if let Err(e) = self.db.delete_entity_extractor_metadata_keys(&stale_keys).await {
+            errors.push(format!("[DB_DELETE] Failed to delete stale metadata: {}", e));
+        }
+        if let Err(e) = self.db.delete_cached_file_hash_values(&plan.deleted).await {
+            errors.push(format!("[DB_DELETE] Failed to delete stale file hashes: {}", e));
+        }
+
+        // Step 5: Re-parse added/modified files in parallel; contract files are
+        // read by the repo-wide passes in Step 6e instead
+        let contract_extensions = self.contract_file_extension_list();
+        let (contract_paths, source_paths): (Vec<String>, Vec<String>) = plan
+            .files_needing_reparse_list()
+            .into_iter()
+            .partition(|path| is_contract_file_path(path, &contract_extensions));
+        let mut reparse_paths: Vec<PathBuf> = source_paths.into_iter().map(PathBuf::from).collect();
+        reparse_paths.sort();
+        let workers = resolve_parse_worker_count(self.config.worker_threads);
+        let progress = IngestProgressEventReporter::create_ingest_progress_reporter(self.config.progress_output);
+        progress.begin_file_parse_phase(reparse_paths.len(), workers);
+        let results: Vec<_> = run_on_parse_worker_pool(workers, || {
+            reparse_paths
+                .par_iter()
+                .map(|file_path| {
+                    let result = self.process_file_for_worker_pool(file_path);
+                    record_worker_result_progress(&progress, &result);
+                    result
+                })
+                .collect()
+        });
+        progress.begin_named_ingest_phase("resolve", "Resolving changed packages...");
+
+        let mut processed_files = 0;
+        let mut entities_created = 0;
+        let mut new_entities: Vec<CodeEntity> = Vec::new();
+        let mut new_dependencies: Vec<parseltongue_core::entities::DependencyEdge> = Vec::new();
+        let mut excluded_tests: Vec<ExcludedTestEntity> = Vec::new();
+        let mut word_coverages: Vec<FileWordCoverageRow> = Vec::new();
+        let mut file_hashes: Vec<(String, String)> = contract_paths
+            .into_iter()
+            .filter_map(|path| current_hashes.get(&path).cloned().map(|hash| (path, hash)))
+            .collect();
+        let mut quarantined_files: Vec<IgnoredFileRow> = Vec::new();
+        for result in results {
+            match result {
+                Ok((file_result, entities, dependencies, excluded, coverages)) => {
+                    processed_files += 1;
+                    entities_created += file_result.entities_created;
+                    if let Some(hash) = &file_result.content_hash {
+                        file_hashes.push((file_result.file_path.clone(), hash.clone()));
+                    }
+                    new_entities.extend(entities);
+                    new_dependencies.extend(dependencies);
+                    excluded_tests.extend(excluded);
+                    word_coverages.extend(coverages);
+                    if let Some(error) = file_result.error {
+                        errors.push(error);
+                    }
+                }
+                Err(StreamerError::ParseQuarantined { path, cause }) => {
+                    errors.push(format_quarantine_error_line(&path, &cause));
+                    quarantined_files.push(self.quarantined_ignored_file_row(Path::new(&path)));
+                }
+                Err(e) => errors.push(format!("[EXTRACT_FAIL] Processing error: {}", e)),
+            }
+        }
+        // No content hash is recorded, so quarantined files are retried next run
+        if !quarantined_files.is_empty() {
+            if let Err(e) = self.db.insert_ignored_files_batch(&quarantined_files).await {
+                errors.push(format!("[v1.7.3] Failed to record {} quarantined files: {}", quarantined_files.len(), e));
+            }
+        }
+
+        // v1.7.3: Owning workspace module of re-parsed entities
+        let workspace_modules = detect_workspace_module_roots(&self.config.root_dir);
+        tag_entities_with_workspace_module(&mut new_entities, &self.config.root_dir, &workspace_modules);
+
+        // Step 5b: Edges of unchanged files into entities that are gone (deleted,
+        // renamed or re-keyed) go back to their placeholder for re-resolution
+        let reparsed_keys: HashSet<&str> = new_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+        let removed_keys: Vec<String> =
+            stale_keys.iter().filter(|key| !reparsed_keys.contains(key.as_str())).cloned().collect();
+        new_dependencies.extend(self.detach_edges_into_removed_entities(&removed_keys, &mut errors).await);
+        let changed_paths: Vec<&String> = stale_paths.iter().chain(&plan.added).collect();
+        let any_changed_path = |matches: fn(&Path) -> bool| changed_paths.iter().any(|path| matches(Path::new(path)));
+
+        // Step 6: Cross-file Go passes restricted to the changed subgraph
+        let changed_go_packages = collect_changed_go_packages(changed_paths.iter().copied());
+        if !changed_go_packages.is_empty() {
+            let mut go_entities = self
+                .db
+                .get_language_entities_with_metadata("go")
+                .await
+                .unwrap_or_default();
+            go_entities.extend(
+                new_entities
+                    .iter()
+                    .filter(|e| is_go_entity_file_path(&e.interface_signature.file_path))
+                    .cloned(),
+            );
+
+            let dirty_keys = collect_go_dirty_subgraph_keys(&go_entities, &changed_go_packages);
+            let touched_keys: HashSet<String> = dirty_keys.iter().cloned().chain(stale_keys.iter().cloned()).collect();
+            let touched_list: Vec<String> = touched_keys.iter().cloned().collect();
+            let existing_edges = self
+                .db
+                .get_edges_touching_entity_keys(&touched_list)
+                .await
+                .unwrap_or_default();
+
+            // Structural Implements edges touching the subgraph are recomputed from scratch
+            let mut outdated_edges: Vec<parseltongue_core::entities::DependencyEdge> = existing_edges
+                .iter()
+                .filter(|e| e.edge_type == EdgeType::Implements && e.metadata_value("match_kind") == Some("structural"))
+                .cloned()
+                .collect();
+
+            // Embeds/receiver calls/imported fields/values of unchanged dirty entities: revert, re-resolve, keep if different
+            for edge in existing_edges.iter().filter(|e| {
+                matches!(
+                    e.edge_type,
+                    EdgeType::Calls
+                        | EdgeType::Spawns
+                        | EdgeType::Embeds
+                        | EdgeType::Reads
+                        | EdgeType::Writes
+                        | EdgeType::Uses
+                ) && dirty_keys.contains(e.from_key.as_str())
+            }) {
+                let mut refreshed = [edge.clone()];
+                revert_go_resolved_edge_targets(&mut refreshed);
+                resolve_go_embedding_promoted_calls(&go_entities, &mut refreshed);
+                let [refreshed] = refreshed;
+                if refreshed != *edge {
+                    outdated_edges.push(edge.clone());
+                    new_dependencies.push(refreshed);
+                }
+            }
+
+            // Error propagation edges point into re-parsed files or follow re-resolved calls
+            let mut outdated_propagation = derive_go_error_propagation_edges(&outdated_edges);
+            outdated_propagation.extend(
+                existing_edges
+                    .iter()
+                    .filter(|e| e.edge_type == EdgeType::PropagatesErrorTo && stale_keys.iter().any(|k| k.as_str() == e.to_key.as_str()))
+                    .cloned(),
+            );
+            outdated_edges.extend(outdated_propagation);
+
+            if let Err(e) = self.db.delete_edges_batch_exact(&outdated_edges).await {
+                errors.push(format!("[DB_DELETE] Failed to delete {} outdated Go edges: {}", outdated_edges.len(), e));
+            }
+
+            resolve_go_embedding_promoted_calls(&go_entities, &mut new_dependencies);
+            let propagation_edges = derive_go_error_propagation_edges(&new_dependencies);
+            new_dependencies.extend(propagation_edges);
+            new_dependencies.extend(compute_go_structural_edges_touching(&go_entities, &dirty_keys));
+        }
+
+        // Step 6b: Python calls from re-parsed files (imports travel with the file)
+        if any_changed_path(is_python_entity_file_path) {
+            let mut python_entities = self
+                .db
+                .get_language_entities_with_metadata("python")
+                .await
+                .unwrap_or_default();
+            let new_keys: HashSet<&str> = new_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+            python_entities.retain(|e| !new_keys.contains(e.isgl1_key.as_str()));
+            python_entities.extend(
+                new_entities
+                    .iter()
+                    .filter(|e| is_python_entity_file_path(&e.interface_signature.file_path))
+                    .cloned(),
+            );
+            resolve_python_import_calls(&python_entities, &mut new_dependencies);
+        }
+
+        // Step 6c: JS/TS calls from re-parsed files; barrels that did not change
+        // contribute their stored binding edges
+        if any_changed_path(is_javascript_entity_file_path) {
+            let new_keys: HashSet<&str> = new_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+            let mut javascript_entities = Vec::new();
+            for language in ["javascript", "typescript"] {
+                javascript_entities.extend(
+                    self.db
+                        .get_language_entities_with_metadata(language)
+                        .await
+                        .unwrap_or_default()
+                        .into_iter()
+                        .filter(|e| !new_keys.contains(e.isgl1_key.as_str())),
+                );
+            }
+            javascript_entities.extend(
+                new_entities
+                    .iter()
+                    .filter(|e| is_javascript_entity_file_path(&e.interface_signature.file_path))
+                    .cloned(),
+            );
+            let stored_bindings: Vec<_> = self
+                .db
+                .get_all_dependencies()
+                .await
+                .unwrap_or_default()
+                .into_iter()
+                .filter(|e| e.metadata_value(BINDING_KIND_METADATA_KEY).is_some())
+                .collect();
+            let module_config = load_javascript_module_config(&self.config.root_dir);
+            resolve_javascript_module_calls(&javascript_entities, &mut new_dependencies, &stored_bindings, &module_config);
+        }
+
+        // Step 6d: Java/Kotlin types from re-parsed files (imports travel with the file)
+        if any_changed_path(is_jvm_entity_file_path) {
+            let new_keys: HashSet<&str> = new_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+            let mut jvm_entities = Vec::new();
+            for language in ["java", "kotlin"] {
+                jvm_entities.extend(
+                    self.db
+                        .get_language_entities_with_metadata(language)
+                        .await
+                        .unwrap_or_default()
+                        .into_iter()
+                        .filter(|e| !new_keys.contains(e.isgl1_key.as_str())),
+                );
+            }
+            jvm_entities.extend(
+                new_entities
+                    .iter()
+                    .filter(|e| is_jvm_entity_file_path(&e.interface_signature.file_path))
+                    .cloned(),
+            );
+            resolve_jvm_type_references(&jvm_entities, &mut new_dependencies);
+        }
+
+        // Step 6e: Repo-wide passes over the stored graph plus the re-parsed
+        // files; only what they change is written
+        let relinked_entities = self
+            .relink_stored_graph_incrementally(
+                &mut new_entities,
+                &mut new_dependencies,
+                &workspace_modules,
+                &mut errors,
+            )
+            .await;
+
+        // Step 6f: Ownership facts for re-parsed files and regenerated nodes only
+        if self.config.git_blame {
+            annotate_entities_with_git_blame(&mut new_entities);
+        }
+        annotate_entities_with_codeowners(&mut new_entities, &self.config.root_dir);
+
+        progress.update_resolved_graph_totals(new_entities.len(), &new_dependencies);
+        progress.begin_named_ingest_phase("store", "Inserting to database...");
+
+        // Step 6g: Summaries/embeddings only lapse for symbols whose content changed
+        let derived = restore_unchanged_derived_artifacts(&mut new_entities, &derived_carryover);
+        new_entities.extend(relinked_entities);
+
+        // Step 7: Insert fresh rows
+        if let Err(e) = self.db.insert_entities_batch(&new_entities).await {
+            errors.push(format!("[DB_INSERT] Failed to batch insert {} entities: {}", new_entities.len(), e));
+        }
+        if let Err(e) = self.db.insert_edges_batch(&new_dependencies).await {
+            errors.push(format!("[DB_INSERT] Failed to batch insert {} dependencies: {}", new_dependencies.len(), e));
+        }
+        if let Err(e) = self.db.delete_symbol_embeddings_by_keys(&derived.invalidated_keys).await {
+            errors.push(format!("[DB_DELETE] Failed to delete stale embeddings: {}", e));
+        }
+        if !excluded_tests.is_empty() {
+            if let Err(e) = self.db.insert_test_entities_excluded_batch(&excluded_tests).await {
+                errors.push(format!("[v1.6.5] Failed to insert {} excluded tests: {}", excluded_tests.len(), e));
+            }
+        }
+        if !word_coverages.is_empty() {
+            if let Err(e) = self.db.insert_file_word_coverage_batch(&word_coverages).await {
+                errors.push(format!("[v1.6.5] Failed to insert {} word coverage rows: {}", word_coverages.len(), e));
+            }
+        }
+        errors.extend(self.persist_incremental_baseline_state(&file_hashes, &new_entities).await);
+
+        // v1.7.3: Centrality scores used by context packing and search ranking
+        if let Err(e) = refresh_stored_symbol_centrality_scores(&self.db).await {
+            errors.push(format!("[v1.7.3] Failed to store centrality scores: {}", e));
+        }
+        self.warn_on_capability_manifest_drift().await;
+
+        let duration = start_time.elapsed();
+        let metrics = progress.finish_ingest_progress_report();
+        self.export_ingest_trace_spans(&progress, "incremental").await;
+
+        println!("\n{}", style("Incremental Streaming Summary:").green().bold());
+        println!("Total files found: {}", current_hashes.len());
+        println!("Files re-parsed: {}", processed_files);
+        println!("Files removed: {}", plan.deleted.len());
+        println!("Entities created: {}", style(entities_created).cyan().bold());
+        println!("Edges created: {} ({} unresolved references)", metrics.edges, metrics.unresolved_refs);
+        if derived.kept_summary_count > 0 || !derived.invalidated_keys.is_empty() {
+            println!(
+                "Summaries kept: {} ({} changed or removed symbols invalidated)",
+                derived.kept_summary_count,
+                derived.invalidated_keys.len()
+            );
+        }
+        println!("Errors encountered: {}", errors.len());
+        println!("Duration: {:?}", duration);
+        self.print_parse_cache_hit_summary();
+
+        Ok(StreamResult {
+            total_files: current_hashes.len(),
+            processed_files,
+            entities_created,
+            errors,
+            duration,
+            phase_timings_ms: progress.phase_duration_totals_ms(),
+            input_bytes: sum_source_file_sizes(&reparse_paths),
         })
     }
 
@@ -920,7 +1754,10 @@ impl FileStreamer for FileStreamerImpl {
         let content = self.read_file_content(file_path).await?;
 
         // Parse code entities AND dependencies (two-pass extraction)
-        let (parsed_entities, dependencies, extraction_warnings) = self.key_generator.parse_source(&content, file_path)?;
+        let (parsed_entities, mut dependencies, extraction_warnings) = self.key_generator.parse_source(&content, file_path)?;
+        // v1.7.3: `#[cfg(...)]` gates of Rust items, evaluated against the Cargo feature selection
+        let rust_cfg_regions = Self::extract_rust_file_cfg_regions(file_path, &content);
+        let mut compiled_out_keys: HashSet<String> = HashSet::new();
 
         let mut entities_created = 0;
         let mut code_count = 0;  // v0.9.3: Track CODE entities
@@ -956,9 +1793,8 @@ impl FileStreamer for FileStreamerImpl {
         }
 
         // Process each parsed entity and collect them for batch insertion
-        for parsed_entity in parsed_entities {
-            // Generate ISGL1 key
-            let isgl1_key = self.key_generator.generate_key(&parsed_entity)?;
+        let entity_keys = self.generate_stable_entity_keys(&parsed_entities, &content, &mut dependencies)?;
+        for (parsed_entity, isgl1_key) in parsed_entities.into_iter().zip(entity_keys) {
 
             // Enrich with LSP metadata for Rust files (sequential hover requests)
             let lsp_metadata = self.fetch_lsp_metadata_for_entity(&parsed_entity, file_path).await;
@@ -971,11 +1807,19 @@ impl FileStreamer for FileStreamerImpl {
                         code_entity.lsp_metadata = Some(metadata);
                     }
 
+                    if !self.apply_rust_cfg_condition(&mut code_entity, parsed_entity.line_range, &rust_cfg_regions, file_path) {
+                        compiled_out_keys.insert(code_entity.isgl1_key.clone());
+                        continue;
+                    }
+
                     // v0.9.3: Track entity_class for stats
                     let entity_class = code_entity.entity_class;
 
                     // ✅ v0.9.6: Skip test entities - they pollute LLM context
-                    if matches!(entity_class, parseltongue_core::EntityClass::TestImplementation) {
+                    // v1.7.3: except Go `_test.go` entities (test facet, source of Tests edges)
+                    if matches!(entity_class, parseltongue_core::EntityClass::TestImplementation)
+                        && !is_go_test_file_path(file_path)
+                    {
                         // v1.6.5: Collect excluded test entity for diagnostics
                         excluded_tests.push(ExcludedTestEntity {
                             entity_name: code_entity.isgl1_key.clone(),
@@ -1002,6 +1846,8 @@ impl FileStreamer for FileStreamerImpl {
             }
         }
 
+        dependencies.retain(|edge| !compiled_out_keys.contains(edge.from_key.as_str()));
+
         // Batch insert all entities at once (external placeholders + regular entities)
         if !entities_to_insert.is_empty() {
             match self.db.insert_entities_batch(&entities_to_insert).await {
@@ -1125,6 +1971,7 @@ impl FileStreamer for FileStreamerImpl {
             } else {
                 Some(errors.join("; "))
             },
+            content_hash: Some(compute_content_hash(&content)),
         })
     }
 
@@ -1140,9 +1987,223 @@ impl FileStreamer for FileStreamerImpl {
             details: format!("Backup to SQLite failed: {}", e),
         })
     }
+
+    /// Mirror the graph into relational SQLite tables (v1.7.3)
+    ///
+    /// # 4-Word Name: export_relational_sqlite_graph
+    async fn export_relational_sqlite_graph(&self, path: &str) -> Result<SqliteSnapshotWriteCounts> {
+        export_cozo_graph_to_sqlite(&self.db, path).await.map_err(|e| StreamerError::StorageError {
+            details: format!("Relational SQLite export failed: {}", e),
+        })
+    }
 }
 
 impl FileStreamerImpl {
+    /// Take stored edges pointing at removed entities out of the graph (v1.7.3).
+    ///
+    /// # 4-Word Name: detach_edges_into_removed_entities
+    ///
+    /// # Contract
+    /// - Precondition: edges leaving the removed entities are already deleted
+    /// - Postcondition: every stored edge into `removed_keys` is deleted
+    /// - Returns: the resolved ones among them pointed back at their
+    ///   `unresolved_target`, to be resolved again and inserted
+    async fn detach_edges_into_removed_entities(
+        &self,
+        removed_keys: &[String],
+        errors: &mut Vec<String>,
+    ) -> Vec<DependencyEdge> {
+        if removed_keys.is_empty() {
+            return Vec::new();
+        }
+        let removed: HashSet<&str> = removed_keys.iter().map(String::as_str).collect();
+        let dangling: Vec<DependencyEdge> = self
+            .db
+            .get_edges_touching_entity_keys(removed_keys)
+            .await
+            .unwrap_or_default()
+            .into_iter()
+            .filter(|e| removed.contains(e.to_key.as_str()))
+            .collect();
+        if let Err(e) = self.db.delete_edges_batch_exact(&dangling).await {
+            errors.push(format!("[DB_DELETE] Failed to delete {} edges into removed entities: {}", dangling.len(), e));
+        }
+        let mut rebound: Vec<DependencyEdge> = dangling
+            .into_iter()
+            .filter(|e| e.metadata_value(UNRESOLVED_TARGET_METADATA_KEY).is_some())
+            .collect();
+        revert_bound_edge_targets(&mut rebound);
+        rebound
+    }
+
+    /// Re-run the repo-wide passes over the stored graph plus re-parsed files (v1.7.3)
+    ///
+    /// # 4-Word Name: relink_stored_graph_incrementally
+    ///
+    /// # Contract
+    /// - Precondition: stale rows are deleted; `new_entities` / `new_dependencies`
+    ///   hold the re-parsed files after the language resolvers
+    /// - Postcondition: stored edges the passes no longer derive and stored
+    ///   synthesized nodes that were not regenerated are deleted
+    /// - Postcondition: `new_dependencies` = edges to write (new or changed);
+    ///   `new_entities` = re-parsed entities + regenerated synthesized nodes
+    /// - Returns: stored entities the passes changed (to write again)
+    async fn relink_stored_graph_incrementally(
+        &self,
+        new_entities: &mut Vec<CodeEntity>,
+        new_dependencies: &mut Vec<DependencyEdge>,
+        workspace_modules: &[WorkspaceModuleRootEntry],
+        errors: &mut Vec<String>,
+    ) -> Vec<CodeEntity> {
+        let plugin_languages: Vec<&str> = self
+            .config
+            .plugins
+            .iter()
+            .filter(|plugin| !plugin.extensions.is_empty())
+            .map(|plugin| plugin.language_segment())
+            .collect();
+        let is_synthesized = |key: &str| is_pass_synthesized_entity_key(key, &plugin_languages);
+        let (stored_synthesized, stored_entities): (Vec<CodeEntity>, Vec<CodeEntity>) = self
+            .db
+            .get_all_entities_with_metadata()
+            .await
+            .unwrap_or_default()
+            .into_iter()
+            .partition(|e| is_synthesized(&e.isgl1_key));
+        let stored_edges = self.db.get_all_dependencies().await.unwrap_or_default();
+
+        // Appended edges are derived afresh; edges into synthesized nodes bind again
+        let mut edges: Vec<DependencyEdge> =
+            stored_edges.iter().filter(|e| !is_repo_wide_pass_edge(e)).cloned().collect();
+        for edge in edges.iter_mut().filter(|e| is_synthesized(e.to_key.as_str())) {
+            revert_bound_edge_targets(std::slice::from_mut(edge));
+        }
+        edges.append(new_dependencies);
+        let (stored_count, parsed_count) = (stored_entities.len(), new_entities.len());
+        let mut graph = stored_entities.clone();
+        graph.append(new_entities);
+        self.link_repo_wide_graph_passes(&mut graph, &mut edges, workspace_modules);
+
+        // Passes only append nodes: [stored | re-parsed | synthesized]
+        let mut synthesized = graph.split_off(stored_count + parsed_count);
+        *new_entities = graph.split_off(stored_count);
+        let regenerated: HashSet<&str> = synthesized.iter().map(|e| e.isgl1_key.as_str()).collect();
+        let dropped: Vec<String> = stored_synthesized
+            .iter()
+            .map(|e| e.isgl1_key.clone())
+            .filter(|key| !regenerated.contains(key.as_str()))
+            .collect();
+        if let Err(e) = self.db.delete_entities_batch_by_keys(&dropped).await {
+            errors.push(format!("[DB_DELETE] Failed to delete {} synthesized nodes: {}", dropped.len(), e));
+        }
+        if let Err(e) = self.db.delete_entity_extractor_metadata_keys(&dropped).await {
+            errors.push(format!("[DB_DELETE] Failed to delete synthesized node metadata: {}", e));
+        }
+        new_entities.append(&mut synthesized);
+
+        let (removed, written) = diff_relinked_edge_sets(&stored_edges, edges);
+        if let Err(e) = self.db.delete_edges_batch_exact(&removed).await {
+            errors.push(format!("[DB_DELETE] Failed to delete {} outdated derived edges: {}", removed.len(), e));
+        }
+        *new_dependencies = written;
+        graph.into_iter().zip(&stored_entities).filter(|(now, was)| now != *was).map(|(now, _)| now).collect()
+    }
+
+    /// Extensions of the contract files the repo-wide passes read (v1.7.3).
+    ///
+    /// # 4-Word Name: contract_file_extension_list
+    ///
+    /// Plugin extensions a built-in parser also reads stay ordinary sources.
+    fn contract_file_extension_list(&self) -> Vec<String> {
+        let mut extensions: Vec<String> = CONTRACT_FILE_EXTENSIONS.iter().map(|e| e.to_string()).collect();
+        for extension in self.config.plugins.iter().flat_map(plugin_file_extensions) {
+            let parsed = Language::from_file_path(Path::new(&format!("file{}", extension))).is_some();
+            if !parsed && !extensions.contains(&extension) {
+                extensions.push(extension);
+            }
+        }
+        extensions
+    }
+
+    /// Content hashes of those contract files, keyed like the walk (v1.7.3).
+    ///
+    /// # 4-Word Name: hash_contract_files_on_disk
+    ///
+    /// Found the way the passes find them; unreadable files are skipped.
+    fn hash_contract_files_on_disk(&self) -> Vec<(String, String)> {
+        self.contract_file_extension_list()
+            .iter()
+            .flat_map(|extension| find_contract_files_by_extension(&self.config.root_dir, extension))
+            .filter_map(|path| {
+                let content = std::fs::read_to_string(&path).ok()?;
+                Some((path.to_string_lossy().to_string(), compute_content_hash(&content)))
+            })
+            .collect()
+    }
+
+    /// Persist file hashes and extractor metadata for later incremental runs (v1.7.3).
+    ///
+    /// # 4-Word Name: persist_incremental_baseline_state
+    ///
+    /// Returns error messages (never fails the ingest).
+    async fn persist_incremental_baseline_state(
+        &self,
+        file_hashes: &[(String, String)],
+        entities: &[CodeEntity],
+    ) -> Vec<String> {
+        let mut errors = Vec::new();
+        let _ = self.db.create_file_hash_cache_schema().await;
+        let _ = self.db.create_entity_extractor_metadata_schema().await;
+
+        if let Err(e) = self.db.set_cached_file_hashes_batch(file_hashes).await {
+            errors.push(format!("[v1.7.3] Failed to record {} file hashes: {}", file_hashes.len(), e));
+        }
+        if let Err(e) = self.db.upsert_entity_extractor_metadata_batch(entities).await {
+            errors.push(format!("[v1.7.3] Failed to record extractor metadata: {}", e));
+        }
+        errors
+    }
+
+    /// Store what the installed extractors support with the graph (v1.7.3).
+    ///
+    /// # 4-Word Name: record_installed_capability_manifest
+    ///
+    /// Returns error messages (never fails the ingest).
+    async fn record_installed_capability_manifest(&self) -> Vec<String> {
+        let stored = match build_installed_capability_manifest() {
+            Ok(manifest) => self.db.store_extractor_capability_manifest(&manifest).await,
+            Err(e) => Err(e),
+        };
+        match stored {
+            Ok(()) => Vec::new(),
+            Err(e) => vec![format!("[v1.7.3] Failed to record extractor capabilities: {}", e)],
+        }
+    }
+
+    /// Warn when the graph was built by other extractor versions (v1.7.3).
+    ///
+    /// # 4-Word Name: warn_on_capability_manifest_drift
+    ///
+    /// The stored manifest is kept: an incremental ingest leaves unchanged
+    /// files as the older extractors saw them.
+    async fn warn_on_capability_manifest_drift(&self) {
+        let (Ok(Some(graph)), Ok(installed)) =
+            (self.db.get_extractor_capability_manifest().await, build_installed_capability_manifest())
+        else {
+            return;
+        };
+        let drift = diff_capability_manifest_pair(&graph, &installed);
+        if drift.is_empty() {
+            return;
+        }
+        let languages: Vec<&str> = drift.iter().map(|entry| entry.language.as_str()).collect();
+        eprintln!(
+            "Warning: extractors for {} changed since the graph was built; re-index without --incremental \
+             to apply them everywhere (see `parseltongue capabilities --db ...`)",
+            languages.join(", ")
+        );
+    }
+
     /// Compute comment word count safely with tree-sitter re-parse (v1.6.5).
     ///
     /// # 4-Word Name: compute_comment_word_count_safely
@@ -1261,8 +2322,30 @@ impl FileStreamerImpl {
             });
         }
 
+        // v1.7.3: `--parse-cache` hit skips parsing entirely
+        let cache_key = self.parse_cache.as_ref().map(|_| {
+            ParseArtifactCacheStore::compute_artifact_cache_key(&content, file_path, &self.parse_cache_config_fingerprint())
+        });
+        if let (Some(cache), Some(key)) = (&self.parse_cache, &cache_key) {
+            if let Some(cached) = cache.load_cached_file_artifacts(key) {
+                let entities_created = cached.entities.len();
+                self.update_stats(entities_created, cached.code_count, cached.test_count, cached.error.is_some());
+                let file_result = FileResult {
+                    file_path: file_path_str,
+                    entities_created,
+                    success: cached.error.is_none(),
+                    error: cached.error,
+                    content_hash: Some(compute_content_hash(&content)),
+                };
+                return Ok((file_result, cached.entities, cached.edges, cached.excluded_tests, cached.word_coverages));
+            }
+        }
+
         // Parse code entities AND dependencies (two-pass extraction)
-        let (parsed_entities, dependencies, extraction_warnings) = self.key_generator.parse_source(&content, file_path)?;
+        let (parsed_entities, mut dependencies, extraction_warnings) = self.key_generator.parse_source(&content, file_path)?;
+        // v1.7.3: `#[cfg(...)]` gates of Rust items, evaluated against the Cargo feature selection
+        let rust_cfg_regions = Self::extract_rust_file_cfg_regions(file_path, &content);
+        let mut compiled_out_keys: HashSet<String> = HashSet::new();
 
         let mut code_count = 0;
         let mut test_count = 0;
@@ -1294,20 +2377,26 @@ impl FileStreamerImpl {
         }
 
         // Process each parsed entity
-        for parsed_entity in parsed_entities {
-            // Generate ISGL1 key
-            let isgl1_key = self.key_generator.generate_key(&parsed_entity)?;
+        let entity_keys = self.generate_stable_entity_keys(&parsed_entities, &content, &mut dependencies)?;
+        for (parsed_entity, isgl1_key) in parsed_entities.into_iter().zip(entity_keys) {
 
             // Note: LSP metadata skipped in parallel mode (would require async)
             // This is a trade-off: speed vs metadata richness
 
             // Convert ParsedEntity to CodeEntity
             match self.parsed_entity_to_code_entity(&parsed_entity, &isgl1_key, &content, file_path) {
-                Ok(code_entity) => {
+                Ok(mut code_entity) => {
+                    if !self.apply_rust_cfg_condition(&mut code_entity, parsed_entity.line_range, &rust_cfg_regions, file_path) {
+                        compiled_out_keys.insert(code_entity.isgl1_key.clone());
+                        continue;
+                    }
+
                     let entity_class = code_entity.entity_class;
 
-                    // Skip test entities
-                    if matches!(entity_class, parseltongue_core::EntityClass::TestImplementation) {
+                    // Skip test entities (v1.7.3: Go `_test.go` entities are kept, see stream_file)
+                    if matches!(entity_class, parseltongue_core::EntityClass::TestImplementation)
+                        && !is_go_test_file_path(file_path)
+                    {
                         // v1.6.5: Collect excluded test entity
                         excluded_tests.push(ExcludedTestEntity {
                             entity_name: code_entity.isgl1_key.clone(),
@@ -1334,6 +2423,7 @@ impl FileStreamerImpl {
             }
         }
 
+        dependencies.retain(|edge| !compiled_out_keys.contains(edge.from_key.as_str()));
         let entities_created = entities_to_insert.len();
 
         // v1.6.5: Compute word coverage
@@ -1377,11 +2467,237 @@ impl FileStreamerImpl {
             } else {
                 Some(errors.join("; "))
             },
+            content_hash: Some(compute_content_hash(&content)),
         };
 
+        if let (Some(cache), Some(key)) = (&self.parse_cache, &cache_key) {
+            let artifacts = CachedFileParseArtifacts {
+                code_count,
+                test_count,
+                error: file_result.error.clone(),
+                entities: entities_to_insert,
+                edges: dependencies,
+                excluded_tests,
+                word_coverages,
+            };
+            // A failed write only costs the next run a re-parse
+            let _ = cache.store_file_parse_artifacts(key, &artifacts);
+            return Ok((file_result, artifacts.entities, artifacts.edges, artifacts.excluded_tests, artifacts.word_coverages));
+        }
+
         Ok((file_result, entities_to_insert, dependencies, excluded_tests, word_coverages))
     }
 
+    /// Union `--shard` databases into this streamer's database and resolve
+    /// cross-file references over the whole graph (v1.7.3)
+    ///
+    /// # 4-Word Name: merge_shard_databases
+    ///
+    /// # Contract
+    /// - Precondition: every shard was ingested from `config.root_dir` with
+    ///   the same directory argument and shard count
+    /// - Postcondition: entities, edges, file hashes and centrality scores are
+    ///   written as a single-process ingest would; files found in more than
+    ///   one shard are reported in `errors`
+    pub async fn merge_shard_databases(&self, shard_db_paths: &[String]) -> Result<StreamResult> {
+        let start_time = Instant::now();
+        let mut errors: Vec<String> = Vec::new();
+
+        let mut parts = Vec::with_capacity(shard_db_paths.len());
+        for path in shard_db_paths {
+            let shard_db = CozoDbStorage::new(path)
+                .await
+                .map_err(|e| StreamerError::StorageError {
+                    details: format!("Failed to open shard database {}: {}", path, e),
+                })?;
+            let entities = shard_db.get_all_entities_with_metadata().await.map_err(|e| StreamerError::StorageError {
+                details: format!("Failed to read entities of {}: {}", path, e),
+            })?;
+            let edges = shard_db.get_all_dependencies().await.unwrap_or_default();
+            let file_hashes = shard_db.get_all_cached_file_hashes().await.unwrap_or_default();
+            println!("  Shard {}: {} entities, {} edges, {} files", path, entities.len(), edges.len(), file_hashes.len());
+            parts.push(ShardGraphPartContents { entities, edges, file_hashes });
+        }
+
+        let merged = merge_shard_graph_parts(parts);
+        for file in &merged.overlapping_files {
+            errors.push(format!("[SHARD_OVERLAP] {}: present in more than one shard", file));
+        }
+        let (mut all_entities, mut all_dependencies) = (merged.entities, merged.edges);
+        self.resolve_cross_file_references(&mut all_entities, &mut all_dependencies);
+
+        if !all_dependencies.is_empty() {
+            let _ = self.db.create_dependency_edges_schema().await;
+        }
+        if let Err(e) = self.db.insert_entities_batch(&all_entities).await {
+            errors.push(format!("[DB_INSERT] Failed to batch insert {} entities: {}", all_entities.len(), e));
+        }
+        if let Err(e) = self.db.insert_edges_batch(&all_dependencies).await {
+            errors.push(format!("[DB_INSERT] Failed to batch insert {} dependencies: {}", all_dependencies.len(), e));
+        }
+        errors.extend(self.persist_incremental_baseline_state(&merged.file_hashes, &all_entities).await);
+        if let Err(e) = refresh_stored_symbol_centrality_scores(&self.db).await {
+            errors.push(format!("[v1.7.3] Failed to store centrality scores: {}", e));
+        }
+        errors.extend(self.record_installed_capability_manifest().await);
+
+        Ok(StreamResult {
+            total_files: merged.file_hashes.len(),
+            processed_files: merged.file_hashes.len(),
+            entities_created: all_entities.len(),
+            errors,
+            duration: start_time.elapsed(),
+            phase_timings_ms: BTreeMap::new(),
+            input_bytes: 0,
+        })
+    }
+
+    /// Cross-file passes of a full ingest over the whole graph (v1.7.3)
+    ///
+    /// # 4-Word Name: resolve_cross_file_references
+    ///
+    /// Shared by the parallel ingest and `merge_shard_databases`.
+    fn resolve_cross_file_references(&self, all_entities: &mut Vec<CodeEntity>, all_dependencies: &mut Vec<DependencyEdge>) {
+        // v1.7.3: Owning go.mod / Cargo.toml / package.json module of every entity
+        let workspace_modules = detect_workspace_module_roots(&self.config.root_dir);
+        tag_entities_with_workspace_module(all_entities, &self.config.root_dir, &workspace_modules);
+
+        // v1.7.3: Cross-file Go passes - resolve embeddings/promoted methods,
+        // then structs implicitly implementing interfaces
+        resolve_go_embedding_promoted_calls(all_entities, all_dependencies);
+        // v1.7.3: --typed - go/types answers replace the syntactic Go call bindings
+        if self.config.go_typed_resolution {
+            match load_go_typed_call_targets(&self.config.root_dir) {
+                Ok(targets) => {
+                    apply_go_typed_call_targets(all_entities, all_dependencies, &self.config.root_dir, &targets);
+                }
+                Err(reason) => eprintln!("Warning: --typed Go resolution skipped: {}", reason),
+            }
+        }
+        let propagation_edges = derive_go_error_propagation_edges(all_dependencies);
+        all_dependencies.extend(propagation_edges);
+        all_dependencies.extend(compute_go_structural_implements_edges(all_entities));
+        // v1.7.3: Python self/imported calls and base classes
+        resolve_python_import_calls(all_entities, all_dependencies);
+        // v1.7.3: JS/TS calls through imports, barrels, tsconfig paths and workspace packages
+        if all_entities.iter().any(|e| is_javascript_entity_file_path(&e.interface_signature.file_path)) {
+            let module_config = load_javascript_module_config(&self.config.root_dir);
+            resolve_javascript_module_calls(all_entities, all_dependencies, &[], &module_config);
+        }
+        // v1.7.3: Java/Kotlin supertypes and constructors by import and package
+        resolve_jvm_type_references(all_entities, all_dependencies);
+        self.link_repo_wide_graph_passes(all_entities, all_dependencies, &workspace_modules);
+    }
+
+    /// Cross-file passes that need the whole graph, after the language resolvers (v1.7.3)
+    ///
+    /// # 4-Word Name: link_repo_wide_graph_passes
+    ///
+    /// # Contract
+    /// - Precondition: language resolvers already ran over `all_dependencies`
+    /// - Postcondition: includes, FFI, Go module/test/lineage, dispatch, proto,
+    ///   SQL, config, plugin, route and enricher passes ran in that order, and
+    ///   every edge is stamped with its confidence
+    ///
+    /// Shared by full ingests and `stream_directory_incremental_by_hash`.
+    fn link_repo_wide_graph_passes(
+        &self,
+        all_entities: &mut Vec<CodeEntity>,
+        all_dependencies: &mut Vec<DependencyEdge>,
+        workspace_modules: &[WorkspaceModuleRootEntry],
+    ) {
+        // v1.7.3: C/C++ and cgo preamble includes → repo headers; C/C++ calls through them
+        if all_entities.iter().any(|e| {
+            is_c_family_file_path(&e.interface_signature.file_path) || is_go_entity_file_path(&e.interface_signature.file_path)
+        }) {
+            resolve_c_include_header_edges(all_entities, all_dependencies, &self.config.root_dir);
+        }
+        // v1.7.3: cgo C.f calls, Rust extern declarations and C callbacks → exported symbol definitions
+        link_ffi_bridge_edges(all_entities, all_dependencies);
+        // v1.7.3: Go edges still pointing at other modules get module path/version
+        if all_entities.iter().any(|e| is_go_entity_file_path(&e.interface_signature.file_path)) {
+            annotate_go_edges_per_module(all_dependencies, all_entities, &self.config.root_dir, workspace_modules);
+            annotate_go_external_placeholder_entities(all_entities, all_dependencies);
+            // v1.7.3: Go test functions → production symbols they exercise
+            let test_edges = compute_go_test_coverage_edges(all_entities, all_dependencies);
+            all_dependencies.extend(test_edges);
+            // v1.7.3: Generated files (DO NOT EDIT) → their //go:generate file or source
+            let lineage_edges = link_go_generated_file_lineage(all_entities, &self.config.root_dir);
+            all_dependencies.extend(lineage_edges);
+        }
+        // v1.7.3: Interface / trait object calls fan out to every implementer
+        let dispatch_edges = expand_dynamic_dispatch_call_edges(all_entities, all_dependencies);
+        all_dependencies.extend(dispatch_edges);
+        // v1.7.3: .proto services/rpcs, linked to generated gRPC client and server stubs
+        all_entities.extend(load_proto_service_entities(&self.config.root_dir));
+        let grpc_edges = link_grpc_stub_edges(all_entities, all_dependencies);
+        all_dependencies.extend(grpc_edges);
+        // v1.7.3: Tables/views of .sql migrations, bound to the embedded SQL naming them
+        all_entities.extend(load_sql_schema_entities(&self.config.root_dir));
+        resolve_embedded_sql_table_edges(all_entities, all_dependencies);
+        // v1.7.3: YAML/JSON settings (env vars, flag keys, handlers, binaries) the code refers to
+        let (config_entities, config_edges) = link_config_reference_edges(all_entities, &self.config.root_dir);
+        all_entities.extend(config_entities);
+        all_dependencies.extend(config_edges);
+        // v1.7.3: Project-config plugins extract the files of their extensions
+        for plugin in self.config.plugins.iter().filter(|plugin| !plugin.extensions.is_empty()) {
+            match extract_files_with_plugin(plugin, &self.config.root_dir) {
+                Ok((entities, edges, warnings)) => {
+                    for warning in warnings {
+                        eprintln!("Warning: plugin {}: {}", plugin.name, warning);
+                    }
+                    all_entities.extend(entities);
+                    all_dependencies.extend(edges);
+                }
+                Err(reason) => eprintln!("Warning: plugin {} skipped: {}", plugin.name, reason),
+            }
+        }
+        // v1.7.3: Route handlers the language resolvers left unbound, by name near the route
+        resolve_http_route_handler_edges(all_entities, all_dependencies);
+        // v1.7.3: Enricher plugins see the resolved graph and add edges/metadata
+        for plugin in self.config.plugins.iter().filter(|plugin| plugin.enrich) {
+            if let Err(reason) = enrich_graph_with_plugin(plugin, &self.config.root_dir, all_entities, all_dependencies) {
+                eprintln!("Warning: plugin {} enrichment skipped: {}", plugin.name, reason);
+            }
+        }
+        // v1.7.3: Provenance and confidence of every edge, once all resolvers ran
+        stamp_edge_confidence_provenance(all_dependencies);
+    }
+
+    /// `--parse-cache` hits and misses of this run, when the cache is on (v1.7.3)
+    fn print_parse_cache_hit_summary(&self) {
+        if let Some(cache) = &self.parse_cache {
+            let (hits, misses) = cache.cache_hit_miss_counts();
+            println!("Parse cache: {} hits, {} misses ({})", hits, misses, cache.root().display());
+        }
+    }
+
+    /// Phase spans of this run to the OTLP collector, when one is configured (v1.7.3)
+    async fn export_ingest_trace_spans(&self, progress: &IngestProgressEventReporter, mode: &str) {
+        let endpoint = match otlp_endpoint_from_environment() {
+            None => return,
+            Some(Ok(endpoint)) => endpoint,
+            Some(Err(e)) => {
+                eprintln!("Warning: ingest trace not exported: {}", e);
+                return;
+            }
+        };
+        let spans = progress.build_ingest_phase_spans(mode, &self.config.root_dir.display().to_string());
+        let service = telemetry_service_name_or(DEFAULT_TELEMETRY_SERVICE_NAME);
+        let posted = tokio::task::spawn_blocking(move || post_otlp_trace_export_blocking(&endpoint, &service, &spans)).await;
+        match posted {
+            Ok(Ok(status)) if (200..300).contains(&status) => {}
+            Ok(Ok(status)) => eprintln!("Warning: OTLP collector rejected the ingest trace (HTTP {})", status),
+            Ok(Err(e)) => eprintln!("Warning: {}", e),
+            Err(e) => eprintln!("Warning: ingest trace export aborted: {}", e),
+        }
+    }
+
+    /// Settings besides content and path that change a file's parse output
+    fn parse_cache_config_fingerprint(&self) -> String {
+        format!("{:?}|{:?}", self.config.go_build_context, self.config.rust_features)
+    }
+
     /// Fetch LSP metadata for an entity using rust-analyzer hover
     /// Returns LspMetadata if successful, None if unavailable or failed (graceful degradation)
     async fn fetch_lsp_metadata_for_entity(
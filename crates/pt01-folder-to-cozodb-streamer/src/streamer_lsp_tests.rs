@@ -62,6 +62,7 @@ struct Calculator {
             exclude_patterns: vec![],
             parsing_library: "tree-sitter".to_string(),
             chunking: "ISGL1".to_string(),
+            ..Default::default()
         };
 
         let key_generator = Isgl1KeyGeneratorFactory::new();
@@ -112,6 +113,7 @@ struct Calculator {
             exclude_patterns: vec![],
             parsing_library: "tree-sitter".to_string(),
             chunking: "ISGL1".to_string(),
+            ..Default::default()
         };
 
         let key_generator = Isgl1KeyGeneratorFactory::new();
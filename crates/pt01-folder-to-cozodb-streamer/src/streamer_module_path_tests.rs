@@ -20,6 +20,7 @@ mod tests {
             exclude_patterns: vec![],
             parsing_library: "tree-sitter".to_string(),
             chunking: "ISGL1".to_string(),
+            ..Default::default()
         };
 
         let key_generator = Isgl1KeyGeneratorFactory::new();
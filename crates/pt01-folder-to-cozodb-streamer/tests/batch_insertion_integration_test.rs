@@ -49,6 +49,7 @@ async fn test_stream_file_uses_batch_insertion() {
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let key_generator = Arc::new(Isgl1KeyGeneratorImpl::new());
@@ -154,6 +155,7 @@ pub fn helper_function_three() -> i32 { 3 }
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let key_generator = Arc::new(Isgl1KeyGeneratorImpl::new());
@@ -227,6 +229,7 @@ pub fn process_with_dependencies() {
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let key_generator = Arc::new(Isgl1KeyGeneratorImpl::new());
@@ -29,6 +29,7 @@ async fn create_test_streamer() -> (FileStreamerImpl, TempDir, String) {
         exclude_patterns: vec![],
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let key_generator = Isgl1KeyGeneratorFactory::new();
@@ -0,0 +1,225 @@
+//! v1.7.3 Golden-file snapshots of the test-fixtures/ corpus
+//!
+//! Ingests every folder under test-fixtures/ through the full pt01 pipeline
+//! (cross-file passes included), serializes the stored nodes and edges as
+//! sorted lines and compares them with the folder's checked-in `GOLDEN.txt`.
+//!
+//! Runs without the libtest harness (`harness = false`) so it can take flags:
+//!
+//! ```text
+//! cargo test -p pt01-folder-to-cozodb-streamer --test golden_fixture_snapshot_test
+//! cargo test -p pt01-folder-to-cozodb-streamer --test golden_fixture_snapshot_test -- --update
+//! cargo test -p pt01-folder-to-cozodb-streamer --test golden_fixture_snapshot_test -- T106
+//! ```
+//!
+//! `--update` (or `-update`) rewrites every golden file; a free argument only
+//! runs fixtures whose folder name contains it. A missing golden file is
+//! written and reported as new, so adding a fixture never fails the suite.
+
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+use std::sync::Arc;
+
+use parseltongue_core::entities::{CodeEntity, DependencyEdge};
+use parseltongue_core::storage::CozoDbStorage;
+use pt01_folder_to_cozodb_streamer::*;
+
+/// Fixture corpus, relative to the crate directory cargo runs tests in
+const FIXTURE_CORPUS_DIRECTORY: &str = "../../test-fixtures";
+
+/// Golden snapshot file inside each fixture folder
+const GOLDEN_SNAPSHOT_FILE_NAME: &str = "GOLDEN.txt";
+
+/// Files in a fixture folder that are not ingested source
+const NON_SOURCE_FIXTURE_FILES: &[&str] = &["EXPECTED.txt", GOLDEN_SNAPSHOT_FILE_NAME, "README.md"];
+
+#[tokio::main]
+async fn main() {
+    let args: Vec<String> = std::env::args().skip(1).collect();
+    let update = args.iter().any(|a| a == "--update" || a == "-update");
+    // libtest-style flags cargo may forward (--nocapture, --quiet) are ignored
+    let filters: Vec<&String> = args.iter().filter(|a| !a.starts_with('-')).collect();
+
+    let mut failures = Vec::new();
+    let mut checked = 0;
+    for fixture in list_fixture_folder_names() {
+        if !filters.is_empty() && !filters.iter().any(|f| fixture.contains(f.as_str())) {
+            continue;
+        }
+        let fixture_dir = format!("{}/{}", FIXTURE_CORPUS_DIRECTORY, fixture);
+        if !has_source_files(Path::new(&fixture_dir)) {
+            continue;
+        }
+        checked += 1;
+
+        let actual = render_fixture_golden_snapshot(&fixture, &fixture_dir).await;
+        let golden_path = PathBuf::from(&fixture_dir).join(GOLDEN_SNAPSHOT_FILE_NAME);
+        match std::fs::read_to_string(&golden_path) {
+            Ok(expected) if expected == actual => {}
+            Ok(_) if update => {
+                std::fs::write(&golden_path, &actual).unwrap();
+                println!("updated {}", golden_path.display());
+            }
+            Ok(expected) => {
+                print_snapshot_line_diff(&fixture, &expected, &actual);
+                failures.push(fixture);
+            }
+            Err(_) => {
+                std::fs::write(&golden_path, &actual).unwrap();
+                println!("new     {} (review and commit it)", golden_path.display());
+            }
+        }
+    }
+
+    println!("\n{} fixtures checked, {} mismatched", checked, failures.len());
+    if !failures.is_empty() {
+        println!("Mismatched: {}", failures.join(", "));
+        println!("If the change is intended, re-run with `-- --update` and commit the golden files.");
+        std::process::exit(1);
+    }
+}
+
+/// Sorted names of the folders under test-fixtures/
+fn list_fixture_folder_names() -> Vec<String> {
+    let mut names: Vec<String> = std::fs::read_dir(FIXTURE_CORPUS_DIRECTORY)
+        .expect("test-fixtures/ directory missing")
+        .filter_map(|entry| entry.ok())
+        .filter(|entry| entry.path().is_dir())
+        .map(|entry| entry.file_name().to_string_lossy().to_string())
+        .collect();
+    names.sort();
+    names
+}
+
+/// True when the folder holds anything besides EXPECTED.txt / GOLDEN.txt
+fn has_source_files(fixture_dir: &Path) -> bool {
+    list_files_recursively(fixture_dir)
+        .iter()
+        .any(|path| !NON_SOURCE_FIXTURE_FILES.iter().any(|n| path.ends_with(n)))
+}
+
+fn list_files_recursively(dir: &Path) -> Vec<PathBuf> {
+    let mut files = Vec::new();
+    for entry in std::fs::read_dir(dir).into_iter().flatten().flatten() {
+        let path = entry.path();
+        if path.is_dir() {
+            files.extend(list_files_recursively(&path));
+        } else {
+            files.push(path);
+        }
+    }
+    files
+}
+
+/// Ingest one fixture into a fresh in-memory database and serialize it
+///
+/// Keys are kept verbatim (their semantic path is derived from the relative
+/// fixture path, so they are the same on every machine); file paths are
+/// shown relative to the fixture folder.
+async fn render_fixture_golden_snapshot(fixture: &str, fixture_dir: &str) -> String {
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let config = StreamerConfig {
+        root_dir: PathBuf::from(fixture_dir),
+        db_path: "mem".to_string(),
+        include_patterns: vec!["*".to_string()],
+        exclude_patterns: vec![],
+        max_file_size: 10_000_000,
+        parsing_library: "tree-sitter".to_string(),
+        chunking: "ISGL1".to_string(),
+        worker_threads: 1,
+        ..Default::default()
+    };
+    let streamer = FileStreamerImpl::new_with_shared_storage(
+        config,
+        Isgl1KeyGeneratorFactory::new(),
+        Arc::new(DefaultTestDetector::new()),
+        storage.clone(),
+    )
+    .await
+    .unwrap();
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let entities = storage.get_all_entities_with_metadata().await.unwrap();
+    let edges = storage.get_all_dependencies().await.unwrap();
+    format_golden_snapshot_text(fixture, fixture_dir, &entities, &edges)
+}
+
+/// `# golden` header, then one sorted line per node and per edge
+fn format_golden_snapshot_text(
+    fixture: &str,
+    fixture_dir: &str,
+    entities: &[CodeEntity],
+    edges: &[DependencyEdge],
+) -> String {
+    let relative = |path: &str| -> String {
+        let path = path.trim_start_matches("./");
+        path.strip_prefix(fixture_dir.trim_start_matches("./"))
+            .map(|rest| rest.trim_start_matches('/').to_string())
+            .unwrap_or_else(|| path.to_string())
+    };
+    let metadata_suffix = |metadata: &BTreeMap<String, String>| -> String {
+        if metadata.is_empty() {
+            return String::new();
+        }
+        let pairs: Vec<String> = metadata.iter().map(|(k, v)| format!("{}={}", k, v.replace('\n', "\\n"))).collect();
+        format!("\t{{{}}}", pairs.join(", "))
+    };
+
+    let mut nodes: Vec<String> = entities
+        .iter()
+        .map(|entity| {
+            let signature = &entity.interface_signature;
+            let metadata: BTreeMap<String, String> =
+                entity.metadata.additional.iter().map(|(k, v)| (k.clone(), v.clone())).collect();
+            format!(
+                "{}\t{}:{}-{}{}",
+                entity.isgl1_key,
+                relative(&signature.file_path.to_string_lossy()),
+                signature.line_range.start,
+                signature.line_range.end,
+                metadata_suffix(&metadata)
+            )
+        })
+        .collect();
+    nodes.sort();
+
+    let mut edge_lines: Vec<String> = edges
+        .iter()
+        .map(|edge| {
+            format!(
+                "{} -[{}]-> {}\t{}{}",
+                edge.from_key.as_str(),
+                edge.edge_type.as_str(),
+                edge.to_key.as_str(),
+                edge.source_location.as_deref().map(relative).unwrap_or_default(),
+                metadata_suffix(&edge.metadata)
+            )
+        })
+        .collect();
+    edge_lines.sort();
+
+    let mut out = format!("# golden: {}\n\n## nodes ({})\n", fixture, nodes.len());
+    for line in nodes {
+        out.push_str(&line);
+        out.push('\n');
+    }
+    out.push_str(&format!("\n## edges ({})\n", edge_lines.len()));
+    for line in edge_lines {
+        out.push_str(&line);
+        out.push('\n');
+    }
+    out
+}
+
+/// Lines only in the golden file (`-`) and only in the new output (`+`)
+fn print_snapshot_line_diff(fixture: &str, expected: &str, actual: &str) {
+    let expected_lines: Vec<&str> = expected.lines().collect();
+    let actual_lines: Vec<&str> = actual.lines().collect();
+    println!("\nMISMATCH {}", fixture);
+    for line in expected_lines.iter().filter(|l| !actual_lines.contains(l)) {
+        println!("  - {}", line);
+    }
+    for line in actual_lines.iter().filter(|l| !expected_lines.contains(l)) {
+        println!("  + {}", line);
+    }
+}
@@ -0,0 +1,366 @@
+//! v1.7.3 Incremental ingest tests
+//!
+//! Full ingest, edit files on disk, incremental ingest against the same
+//! in-memory database: the stored graph must match what the edit implies
+//! without a full re-ingest.
+
+use std::path::Path;
+use std::sync::Arc;
+
+use parseltongue_core::entities::EdgeType;
+use parseltongue_core::storage::CozoDbStorage;
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
+use pt01_folder_to_cozodb_streamer::*;
+use tempfile::TempDir;
+
+/// Streamer over `root` that shares `storage` between runs
+async fn create_shared_test_streamer(root: &Path, storage: &Arc<CozoDbStorage>) -> FileStreamerImpl {
+    let config = StreamerConfig {
+        root_dir: root.to_path_buf(),
+        db_path: "mem".to_string(),
+        include_patterns: vec!["*".to_string()],
+        worker_threads: 1,
+        respect_gitignore: false,
+        progress_output: IngestProgressOutputMode::Off,
+        ..Default::default()
+    };
+    FileStreamerImpl::new_with_shared_storage(
+        config,
+        Isgl1KeyGeneratorFactory::new(),
+        Arc::new(DefaultTestDetector::new()),
+        storage.clone(),
+    )
+    .await
+    .unwrap()
+}
+
+fn write_fixture_file(root: &Path, relative: &str, content: &str) {
+    let path = root.join(relative);
+    std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+    std::fs::write(path, content).unwrap();
+}
+
+/// Renaming a callee sends the unchanged caller's edge back to its placeholder
+#[tokio::test]
+async fn test_renamed_callee_unbinds_unchanged_caller() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(root, "store/store.go", "package store\n\nfunc Save() {}\n");
+    write_fixture_file(
+        root,
+        "api/handler.go",
+        "package api\n\nimport \"example.com/app/store\"\n\nfunc Handle() {\n\tstore.Save()\n}\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let handle_calls = |edges: &[parseltongue_core::entities::DependencyEdge]| {
+        edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Calls && e.from_key.as_str().starts_with("go:fn:Handle:"))
+            .cloned()
+            .collect::<Vec<_>>()
+    };
+    let edges = storage.get_all_dependencies().await.unwrap();
+    let calls = handle_calls(&edges);
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    let old_save_key = calls[0].to_key.as_str().to_string();
+    assert!(old_save_key.starts_with("go:fn:Save:"), "{}", old_save_key);
+    assert!(!old_save_key.contains("unresolved-reference"), "{}", old_save_key);
+
+    write_fixture_file(root, "store/store.go", "package store\n\nfunc Persist() {}\n");
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 1, "only store.go changed: {:?}", result.errors);
+
+    let edges = storage.get_all_dependencies().await.unwrap();
+    assert!(
+        !edges.iter().any(|e| e.to_key.as_str() == old_save_key),
+        "edge into the removed Save survived: {:?}",
+        edges
+    );
+    let calls = handle_calls(&edges);
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    assert!(calls[0].to_key.as_str().contains("unresolved-reference"), "{:?}", calls[0]);
+    assert_eq!(calls[0].metadata_value("unresolved_target"), None);
+    assert_eq!(calls[0].metadata_value("resolution"), None);
+}
+
+/// A definition added next to an included header binds the unchanged caller
+#[tokio::test]
+async fn test_added_definition_binds_unchanged_includer() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(root, "native/codec.h", "#define CODEC_VERSION 1\n");
+    write_fixture_file(root, "native/main.c", "#include \"codec.h\"\n\nint run(void) { return encode(1); }\n");
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let run_calls = |edges: &[parseltongue_core::entities::DependencyEdge]| {
+        edges
+            .iter()
+            .filter(|e| e.edge_type == EdgeType::Calls && e.from_key.as_str().starts_with("c:fn:run:"))
+            .cloned()
+            .collect::<Vec<_>>()
+    };
+    let calls = run_calls(&storage.get_all_dependencies().await.unwrap());
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    assert!(calls[0].to_key.as_str().contains("unresolved-reference"), "{:?}", calls[0]);
+
+    write_fixture_file(root, "native/codec.c", "int encode(int x) { return x; }\n");
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 1, "only codec.c is new: {:?}", result.errors);
+
+    let calls = run_calls(&storage.get_all_dependencies().await.unwrap());
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    assert!(calls[0].to_key.as_str().starts_with("c:fn:encode:"), "{:?}", calls[0]);
+    assert!(!calls[0].to_key.as_str().contains("unresolved-reference"), "{:?}", calls[0]);
+    assert_eq!(calls[0].metadata_value("resolution"), Some("included_file"));
+}
+
+/// cgo calls bridge to a C definition added later, and unbridge when it goes
+#[tokio::test]
+async fn test_cgo_bridge_follows_c_definition_edits() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(
+        root,
+        "zip/zip.go",
+        "package zip\n\n// #include \"zlib.h\"\nimport \"C\"\n\nfunc Compress() {\n\tC.deflate(1)\n}\n",
+    );
+    write_fixture_file(root, "zip/zlib.h", "#define ZLIB_LEVEL 6\n");
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let deflate_call = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges
+            .into_iter()
+            .find(|e| {
+                e.edge_type == EdgeType::Calls
+                    && e.from_key.as_str().starts_with("go:fn:Compress:")
+                    && (e.to_key.as_str().contains("deflate") || e.metadata_value("ffi_symbol") == Some("deflate"))
+            })
+            .expect("Compress calls C.deflate")
+    };
+    let call = deflate_call(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(call.to_key.as_str(), "go:fn:C.deflate:unresolved-reference:0-0");
+
+    write_fixture_file(root, "zip/zlib.c", "int deflate(int level) { return level; }\n");
+    streamer.stream_directory_incremental_by_hash().await.unwrap();
+    let call = deflate_call(storage.get_all_dependencies().await.unwrap());
+    assert!(call.to_key.as_str().starts_with("c:fn:deflate:"), "{:?}", call);
+    assert_eq!(call.metadata_value("ffi_bridge"), Some("go_to_c"));
+
+    write_fixture_file(root, "zip/zlib.c", "int inflate(int level) { return level; }\n");
+    streamer.stream_directory_incremental_by_hash().await.unwrap();
+    let call = deflate_call(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(call.to_key.as_str(), "go:fn:C.deflate:unresolved-reference:0-0");
+    assert_eq!(call.metadata_value("ffi_bridge"), None);
+}
+
+/// A directive added to a hand-written file claims the unchanged generated file
+#[tokio::test]
+async fn test_added_directive_links_unchanged_generated_file() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(root, "color/color.go", "package color\n\ntype Color int\n");
+    write_fixture_file(
+        root,
+        "color/color_string.go",
+        "// Code generated by \"stringer -type=Color\"; DO NOT EDIT.\n\npackage color\n\n\
+         func (i Color) String() string { return \"\" }\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let lineage_edges = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges.into_iter().filter(|e| e.edge_type == EdgeType::GeneratedFrom).collect::<Vec<_>>()
+    };
+    assert!(lineage_edges(storage.get_all_dependencies().await.unwrap()).is_empty());
+
+    write_fixture_file(root, "color/color.go", "package color\n\n//go:generate stringer -type=Color\n\ntype Color int\n");
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 1, "only color.go changed: {:?}", result.errors);
+
+    let edges = lineage_edges(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(edges.len(), 1, "{:?}", edges);
+    assert!(edges[0].from_key.as_str().ends_with("color_color_string_go:1-1"), "{:?}", edges[0]);
+    assert!(edges[0].to_key.as_str().ends_with("color_color_go:1-1"), "{:?}", edges[0]);
+    assert_eq!(edges[0].metadata_value("directive_line"), Some("3"));
+
+    let entities = storage.get_all_entities_with_metadata().await.unwrap();
+    let string_method = entities
+        .iter()
+        .find(|e| e.interface_signature.name == "String")
+        .expect("String method stored");
+    let generated_from = string_method.metadata.additional.get("generated_from");
+    assert_eq!(generated_from.map(String::as_str), Some("color/color.go"));
+}
+
+/// An rpc added to a `.proto` links the unchanged generated client stub
+#[tokio::test]
+async fn test_edited_proto_links_unchanged_client_stub() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    let contract = "syntax = \"proto3\";\npackage acme.v1;\n\nservice Users {\n  rpc Get(GetRequest) returns (User);\n";
+    write_fixture_file(root, "api/users.proto", &format!("{}}}\n", contract));
+    write_fixture_file(
+        root,
+        "gen/users_grpc.pb.go",
+        "package gen\n\ntype usersClient struct{}\n\nfunc (c *usersClient) Get() {}\n\n\
+         func (c *usersClient) Delete() {}\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let stub_links = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges
+            .into_iter()
+            .filter(|e| e.metadata_value("resolution") == Some("grpc_stub"))
+            .map(|e| e.to_key.as_str().to_string())
+            .collect::<Vec<_>>()
+    };
+    let links = stub_links(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(links.len(), 1, "{:?}", links);
+    assert!(links[0].starts_with("proto:method:Users.Get:"), "{:?}", links);
+    let cached_hashes = storage.get_all_cached_file_hashes().await.unwrap();
+    assert!(cached_hashes.keys().any(|path| path.ends_with("users.proto")), "{:?}", cached_hashes);
+
+    let edited = format!("{}  rpc Delete(DeleteRequest) returns (User);\n}}\n", contract);
+    write_fixture_file(root, "api/users.proto", &edited);
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 0, "no source file changed: {:?}", result.errors);
+
+    let mut links = stub_links(storage.get_all_dependencies().await.unwrap());
+    links.sort();
+    assert_eq!(links.len(), 2, "{:?}", links);
+    assert!(links[0].starts_with("proto:method:Users.Delete:"), "{:?}", links);
+    assert!(links[1].starts_with("proto:method:Users.Get:"), "{:?}", links);
+    let entities = storage.get_all_entities_with_metadata().await.unwrap();
+    let rpc_paths: Vec<_> = entities.iter().filter_map(|e| e.metadata.additional.get("rpc_path")).collect();
+    assert_eq!(rpc_paths.len(), 2, "{:?}", rpc_paths);
+}
+
+/// A table added to a migration binds the unchanged query naming it
+#[tokio::test]
+async fn test_added_migration_table_binds_unchanged_query() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(root, "db/0001_init.up.sql", "CREATE TABLE users (id BIGINT);\n");
+    write_fixture_file(
+        root,
+        "store/accounts.go",
+        "package store\n\nfunc Find(db DB) {\n\tdb.Query(\"SELECT id, owner FROM accounts\")\n}\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let accounts_query = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges
+            .into_iter()
+            .find(|e| e.edge_type == EdgeType::QueriesTable && e.from_key.as_str().starts_with("go:fn:Find:"))
+            .expect("Find queries accounts")
+    };
+    let query = accounts_query(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(query.to_key.as_str(), "sql:table:accounts:unresolved-reference:0-0");
+
+    write_fixture_file(
+        root,
+        "db/0001_init.up.sql",
+        "CREATE TABLE users (id BIGINT);\n\nCREATE TABLE accounts (id BIGINT, owner BIGINT);\n",
+    );
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 0, "no source file changed: {:?}", result.errors);
+
+    let query = accounts_query(storage.get_all_dependencies().await.unwrap());
+    assert!(query.to_key.as_str().starts_with("sql:table:accounts:"), "{:?}", query);
+    assert!(!query.to_key.as_str().contains("unresolved-reference"), "{:?}", query);
+    assert_eq!(query.metadata_value("resolution"), Some("sql_schema"));
+    assert_eq!(query.metadata_value("sql_columns"), Some("id,owner"));
+
+    write_fixture_file(root, "db/0001_init.up.sql", "CREATE TABLE users (id BIGINT);\n");
+    streamer.stream_directory_incremental_by_hash().await.unwrap();
+    let query = accounts_query(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(query.to_key.as_str(), "sql:table:accounts:unresolved-reference:0-0");
+}
+
+/// A variable added to a manifest configures the unchanged code reading it
+#[tokio::test]
+async fn test_added_manifest_variable_configures_unchanged_code() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    let manifest = "spec:\n  containers:\n  - name: api\n    env:\n    - name: LOG_LEVEL\n";
+    write_fixture_file(root, "deploy/api.yaml", manifest);
+    write_fixture_file(
+        root,
+        "svc/checkout.go",
+        "package svc\n\nimport \"os\"\n\nfunc Checkout() bool {\n\t\
+         return os.Getenv(\"FEATURE_NEW_CHECKOUT\") != \"\"\n}\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let configured_by = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges.into_iter().filter(|e| e.edge_type == EdgeType::ConfiguredBy).collect::<Vec<_>>()
+    };
+    assert!(configured_by(storage.get_all_dependencies().await.unwrap()).is_empty());
+
+    write_fixture_file(root, "deploy/api.yaml", &format!("{}    - name: FEATURE_NEW_CHECKOUT\n", manifest));
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 0, "no source file changed: {:?}", result.errors);
+
+    let edges = configured_by(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(edges.len(), 1, "{:?}", edges);
+    assert!(edges[0].from_key.as_str().starts_with("go:fn:Checkout:"), "{:?}", edges[0]);
+    assert!(edges[0].to_key.as_str().starts_with("yaml:var:"), "{:?}", edges[0]);
+    assert_eq!(edges[0].metadata_value("config_kind"), Some("env"));
+
+    write_fixture_file(root, "deploy/api.yaml", manifest);
+    streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert!(configured_by(storage.get_all_dependencies().await.unwrap()).is_empty());
+    let entities = storage.get_all_entities_with_metadata().await.unwrap();
+    assert!(!entities.iter().any(|e| e.isgl1_key.starts_with("yaml:")), "unreferenced settings get no node");
+}
+
+/// A handler added next to the router binds the unchanged route
+#[tokio::test]
+async fn test_added_handler_binds_unchanged_route() {
+    let temp_dir = TempDir::new().unwrap();
+    let root = temp_dir.path();
+    write_fixture_file(
+        root,
+        "api/routes.go",
+        "package api\n\nimport \"github.com/go-chi/chi/v5\"\n\n\
+         func Routes(r chi.Router) {\n\tr.Get(\"/users\", listUsers)\n}\n",
+    );
+    let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+    let streamer = create_shared_test_streamer(root, &storage).await;
+    streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+    let route_handler = |edges: Vec<parseltongue_core::entities::DependencyEdge>| {
+        edges
+            .into_iter()
+            .find(|e| e.edge_type == EdgeType::Calls && e.from_key.as_str().starts_with("go:route:GET_/users:"))
+            .expect("GET /users has a handler edge")
+    };
+    let handler = route_handler(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(handler.to_key.as_str(), "go:fn:listUsers:unresolved-reference:0-0");
+
+    write_fixture_file(root, "api/users.go", "package api\n\nfunc listUsers() {}\n");
+    let result = streamer.stream_directory_incremental_by_hash().await.unwrap();
+    assert_eq!(result.processed_files, 1, "only users.go is new: {:?}", result.errors);
+    let handler = route_handler(storage.get_all_dependencies().await.unwrap());
+    assert!(handler.to_key.as_str().starts_with("go:fn:listUsers:"), "{:?}", handler);
+    assert!(!handler.to_key.as_str().contains("unresolved-reference"), "{:?}", handler);
+
+    std::fs::remove_file(root.join("api/users.go")).unwrap();
+    streamer.stream_directory_incremental_by_hash().await.unwrap();
+    let handler = route_handler(storage.get_all_dependencies().await.unwrap());
+    assert_eq!(handler.to_key.as_str(), "go:fn:listUsers:unresolved-reference:0-0");
+}
@@ -55,6 +55,7 @@ pub fn multiply(x: i32, y: i32) -> i32 {
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let key_gen = Isgl1KeyGeneratorFactory::new();
@@ -75,6 +76,7 @@ pub fn multiply(x: i32, y: i32) -> i32 {
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let par_streamer = FileStreamerImpl::new(par_config, key_gen, test_detector)
@@ -100,6 +102,72 @@ pub fn multiply(x: i32, y: i32) -> i32 {
     );
 }
 
+/// v1.7.3: Keys and edges are identical for 1 and 4 workers, and same-name
+/// overloads in one file keep distinct keys
+#[tokio::test]
+async fn test_stable_keys_independent_of_worker_count() {
+    let temp_dir = TempDir::new().unwrap();
+    std::fs::write(
+        temp_dir.path().join("Store.java"),
+        "class Store {\n  void save(User u) {\n    write(u);\n  }\n  void save(User u, boolean flush) {\n    write(u);\n    sync();\n  }\n}\n",
+    )
+    .unwrap();
+    for i in 0..12 {
+        std::fs::write(
+            temp_dir.path().join(format!("worker_{}.go", i)),
+            format!("package main\n\nfunc run{}() {{ helper() }}\n\nfunc helper() {{}}\n", i),
+        )
+        .unwrap();
+    }
+
+    let mut snapshots = Vec::new();
+    for worker_threads in [1, 4] {
+        let storage = Arc::new(CozoDbStorage::new("mem").await.unwrap());
+        let config = StreamerConfig {
+            root_dir: temp_dir.path().to_path_buf(),
+            db_path: "mem".to_string(),
+            include_patterns: vec!["*".to_string()],
+            exclude_patterns: vec![],
+            max_file_size: 10_000_000,
+            parsing_library: "tree-sitter".to_string(),
+            chunking: "ISGL1".to_string(),
+            worker_threads,
+            ..Default::default()
+        };
+        let streamer = FileStreamerImpl::new_with_shared_storage(
+            config,
+            Isgl1KeyGeneratorFactory::new(),
+            Arc::new(DefaultTestDetector::new()),
+            storage.clone(),
+        )
+        .await
+        .unwrap();
+        streamer.stream_directory_with_parallel_rayon().await.unwrap();
+
+        let mut keys: Vec<String> = storage
+            .get_all_entities()
+            .await
+            .unwrap()
+            .into_iter()
+            .map(|e| e.isgl1_key)
+            .collect();
+        keys.sort();
+        let mut edges: Vec<String> = storage
+            .get_all_dependencies()
+            .await
+            .unwrap()
+            .iter()
+            .map(|e| format!("{} -[{}]-> {}", e.from_key.as_str(), e.edge_type.as_str(), e.to_key.as_str()))
+            .collect();
+        edges.sort();
+        snapshots.push((keys, edges));
+    }
+
+    assert_eq!(snapshots[0], snapshots[1], "worker count must not change keys or edges");
+    let save_overloads = snapshots[0].0.iter().filter(|k| k.starts_with("java:method:save:")).count();
+    assert_eq!(save_overloads, 2, "overloads must not collapse into one key");
+}
+
 /// Test parallel streaming performance benchmark
 #[tokio::test]
 #[ignore] // Run with --ignored for manual performance testing
@@ -145,6 +213,7 @@ pub struct Struct{} {{
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let seq_streamer = FileStreamerImpl::new(seq_config, key_gen.clone(), test_detector.clone())
@@ -165,6 +234,7 @@ pub struct Struct{} {{
         max_file_size: 10_000_000,
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let par_streamer = FileStreamerImpl::new(par_config, key_gen, test_detector)
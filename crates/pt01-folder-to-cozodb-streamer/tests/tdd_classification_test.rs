@@ -69,6 +69,7 @@ fn test_example() {
         exclude_patterns: vec![],
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     // Execute: Index with Tool 1
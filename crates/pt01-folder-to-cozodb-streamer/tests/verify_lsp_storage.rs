@@ -42,6 +42,7 @@ impl Calculator {
         exclude_patterns: vec![],
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     let streamer = ToolFactory::create_streamer(config).await.unwrap();
@@ -22,7 +22,8 @@ pub struct HttpServerStartupConfig {
     /// Force fresh ingestion even if DB exists
     pub force_reindex_enabled_flag: bool,
 
-    /// Run in background/daemon mode
+    /// Run as watch daemon: ingest into an in-memory graph on startup,
+    /// keep it hot via the file watcher (v1.7.3)
     pub daemon_background_mode_flag: bool,
 
     /// Auto-shutdown after idle period (minutes)
@@ -30,6 +31,12 @@ pub struct HttpServerStartupConfig {
 
     /// Show verbose query logs
     pub verbose_logging_enabled_flag: bool,
+
+    /// `http://` URL receiving graph change events (v1.7.3)
+    pub graph_change_webhook_url_option: Option<String>,
+
+    /// Serve JSON-RPC 2.0 on stdin/stdout instead of a TCP port (v1.7.3)
+    pub stdio_jsonrpc_mode_flag: bool,
 }
 
 impl Default for HttpServerStartupConfig {
@@ -42,6 +49,8 @@ impl Default for HttpServerStartupConfig {
             daemon_background_mode_flag: false,
             idle_timeout_minutes_option: None,
             verbose_logging_enabled_flag: false,
+            graph_change_webhook_url_option: None,
+            stdio_jsonrpc_mode_flag: false,
         }
     }
 }
@@ -91,6 +100,15 @@ impl HttpServerStartupConfig {
                 "--verbose" => {
                     config.verbose_logging_enabled_flag = true;
                 }
+                "--stdio" => {
+                    config.stdio_jsonrpc_mode_flag = true;
+                }
+                "--webhook" => {
+                    i += 1;
+                    config.graph_change_webhook_url_option = Some(
+                        args.get(i).context("--webhook requires a URL")?.clone()
+                    );
+                }
                 arg if !arg.starts_with('-') => {
                     // Positional argument = target directory
                     config.target_directory_path_value = PathBuf::from(arg);
@@ -159,6 +177,15 @@ mod tests {
         assert_eq!(config.http_port_override_option, Some(7777));
     }
 
+    #[test]
+    fn test_parse_daemon_mode_flag() {
+        let args = vec!["pt08".to_string(), "./repo".to_string(), "--daemon".to_string()];
+        let config = HttpServerStartupConfig::parse_from_argument_vector(&args).unwrap();
+
+        assert!(config.daemon_background_mode_flag);
+        assert_eq!(config.target_directory_path_value, PathBuf::from("./repo"));
+    }
+
     #[test]
     fn test_find_available_port_number() {
         let port = find_available_port_number(7777).unwrap();
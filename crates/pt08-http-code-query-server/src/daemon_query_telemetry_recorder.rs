@@ -0,0 +1,440 @@
+//! Daemon query telemetry: Prometheus metrics and OTLP spans (v1.7.3)
+//!
+//! # 4-Word Naming: daemon_query_telemetry_recorder
+//!
+//! Operators running the daemon as a shared service scrape `GET /metrics`
+//! (Prometheus text format 0.0.4; the path is the Prometheus default rather
+//! than a 4-word name so stock scrape configs work):
+//!
+//! ```text
+//! parseltongue_graph_entities / parseltongue_graph_edges   gauges, live graph size
+//! parseltongue_graph_revision                               gauge, bumped per changing reindex
+//! parseltongue_http_requests_total{method,route,status}     counter
+//! parseltongue_http_request_duration_seconds{method,route}  histogram
+//! parseltongue_http_cache_lookups_total / _hits_total       conditional GETs / 304s (ETag cache)
+//! parseltongue_http_cache_hit_ratio                         gauge, hits / lookups
+//! ```
+//!
+//! `route` is the matched route template (`/code-entity-detail-view/{*key}`),
+//! never the raw path, so label cardinality stays bounded; requests that
+//! match no route are `route="unmatched"`. MCP, gRPC and `--stdio` calls
+//! are dispatched through the same router and are measured too.
+//!
+//! When `OTEL_EXPORTER_OTLP_ENDPOINT` is set, every request also becomes a
+//! SERVER span (joining the caller's trace when a W3C `traceparent` header
+//! is sent), batched and exported every 5 s by
+//! `spawn_query_span_export_loop`. At most 10,000 spans wait for export;
+//! beyond that new spans are dropped and counted.
+
+use std::collections::BTreeMap;
+use std::fmt::Write as _;
+use std::sync::atomic::{AtomicBool, AtomicU64, Ordering};
+use std::sync::{Arc, Mutex};
+use std::time::{Duration, Instant};
+
+use axum::{
+    extract::{MatchedPath, Request, State},
+    http::{header, StatusCode},
+    middleware::Next,
+    response::Response,
+};
+use parseltongue_core::otlp_trace_span_exporter::{
+    current_unix_time_nanos, generate_random_hex_identifier, parse_w3c_traceparent_header,
+    post_otlp_trace_export_blocking, OtlpHttpEndpointAddress, TelemetryAttributeValueKind, TelemetrySpanKindValue,
+    TelemetrySpanRecordEntry,
+};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Scrape path (Prometheus default)
+pub const PROMETHEUS_METRICS_ENDPOINT_PATH: &str = "/metrics";
+
+/// Upper bounds of the latency histogram buckets, in seconds
+pub const QUERY_LATENCY_BUCKET_SECONDS: [f64; 12] = [0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0];
+
+/// Spans held for the next export before new ones are dropped
+const MAX_PENDING_QUERY_SPANS: usize = 10_000;
+
+/// Gap between two span exports
+const QUERY_SPAN_EXPORT_INTERVAL: Duration = Duration::from_secs(5);
+
+/// Latency histogram and status counts of one (method, route)
+#[derive(Debug, Clone, Default)]
+struct RouteLatencyHistogramEntry {
+    /// Non-cumulative counts per bucket of `QUERY_LATENCY_BUCKET_SECONDS`
+    bucket_counts: [u64; 12],
+    count: u64,
+    sum_seconds: f64,
+    status_counts: BTreeMap<u16, u64>,
+}
+
+/// Graph gauges read at scrape time
+///
+/// # 4-Word Name: GraphSizeGaugeSnapshot
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct GraphSizeGaugeSnapshot {
+    pub entities: usize,
+    pub edges: usize,
+    pub revision: u64,
+    pub uptime_seconds: i64,
+}
+
+/// Counters shared by the middleware, `/metrics` and the span exporter
+///
+/// # 4-Word Name: DaemonQueryTelemetryRegistry
+#[derive(Debug, Default)]
+pub struct DaemonQueryTelemetryRegistry {
+    routes: Mutex<BTreeMap<(String, String), RouteLatencyHistogramEntry>>,
+    cache_lookups: AtomicU64,
+    cache_hits: AtomicU64,
+    span_export_enabled: AtomicBool,
+    pending_spans: Mutex<Vec<TelemetrySpanRecordEntry>>,
+    dropped_spans: AtomicU64,
+}
+
+impl DaemonQueryTelemetryRegistry {
+    /// Count one finished request
+    ///
+    /// # 4-Word Name: record_query_request_observation
+    ///
+    /// # Contract
+    /// - Precondition: `conditional` is true for a GET carrying `If-None-Match`
+    /// - Postcondition: a conditional request answered 304 is a cache hit
+    pub fn record_query_request_observation(&self, method: &str, route: &str, status: u16, seconds: f64, conditional: bool) {
+        {
+            let mut routes = self.routes.lock().unwrap();
+            let entry = routes.entry((method.to_string(), route.to_string())).or_default();
+            if let Some(bucket) = QUERY_LATENCY_BUCKET_SECONDS.iter().position(|bound| seconds <= *bound) {
+                entry.bucket_counts[bucket] += 1;
+            }
+            entry.count += 1;
+            entry.sum_seconds += seconds;
+            *entry.status_counts.entry(status).or_insert(0) += 1;
+        }
+        if conditional {
+            self.cache_lookups.fetch_add(1, Ordering::Relaxed);
+            if status == StatusCode::NOT_MODIFIED.as_u16() {
+                self.cache_hits.fetch_add(1, Ordering::Relaxed);
+            }
+        }
+    }
+
+    /// Whether requests should be recorded as spans
+    ///
+    /// # 4-Word Name: is_span_export_enabled
+    pub fn is_span_export_enabled(&self) -> bool {
+        self.span_export_enabled.load(Ordering::Relaxed)
+    }
+
+    /// Queue a span for the next export
+    ///
+    /// # 4-Word Name: push_pending_query_span
+    pub fn push_pending_query_span(&self, span: TelemetrySpanRecordEntry) {
+        let mut pending = self.pending_spans.lock().unwrap();
+        if pending.len() >= MAX_PENDING_QUERY_SPANS {
+            self.dropped_spans.fetch_add(1, Ordering::Relaxed);
+            return;
+        }
+        pending.push(span);
+    }
+
+    /// Take every queued span
+    ///
+    /// # 4-Word Name: drain_pending_query_spans
+    pub fn drain_pending_query_spans(&self) -> Vec<TelemetrySpanRecordEntry> {
+        std::mem::take(&mut *self.pending_spans.lock().unwrap())
+    }
+
+    /// Prometheus text exposition of all metrics
+    ///
+    /// # 4-Word Name: render_prometheus_exposition_text
+    ///
+    /// # Contract
+    /// - Postcondition: histogram buckets are cumulative and end with
+    ///   `le="+Inf"` equal to `_count`; series are sorted by method, route
+    pub fn render_prometheus_exposition_text(&self, graph: GraphSizeGaugeSnapshot) -> String {
+        let mut text = String::new();
+        let mut gauge = |name: &str, help: &str, value: String| {
+            let _ = writeln!(text, "# HELP {} {}\n# TYPE {} gauge\n{} {}", name, help, name, name, value);
+        };
+        gauge("parseltongue_graph_entities", "Entities in the served graph", graph.entities.to_string());
+        gauge("parseltongue_graph_edges", "Dependency edges in the served graph", graph.edges.to_string());
+        gauge("parseltongue_graph_revision", "Graph revision, bumped by every reindex that changes the graph", graph.revision.to_string());
+        gauge("parseltongue_uptime_seconds", "Seconds since the server started", graph.uptime_seconds.to_string());
+
+        let lookups = self.cache_lookups.load(Ordering::Relaxed);
+        let hits = self.cache_hits.load(Ordering::Relaxed);
+        let ratio = if lookups == 0 { 0.0 } else { hits as f64 / lookups as f64 };
+        gauge("parseltongue_http_cache_hit_ratio", "Conditional GETs answered 304 Not Modified, over all conditional GETs", ratio.to_string());
+        let mut counter = |name: &str, help: &str, value: u64| {
+            let _ = writeln!(text, "# HELP {} {}\n# TYPE {} counter\n{} {}", name, help, name, name, value);
+        };
+        counter("parseltongue_http_cache_lookups_total", "GET requests carrying If-None-Match", lookups);
+        counter("parseltongue_http_cache_hits_total", "GET requests answered 304 from the graph revision ETag", hits);
+        counter("parseltongue_trace_spans_dropped_total", "Query spans dropped because the export queue was full", self.dropped_spans.load(Ordering::Relaxed));
+
+        let routes = self.routes.lock().unwrap();
+        text.push_str("# HELP parseltongue_http_requests_total Requests handled, by route template and status\n");
+        text.push_str("# TYPE parseltongue_http_requests_total counter\n");
+        for ((method, route), entry) in routes.iter() {
+            for (status, count) in &entry.status_counts {
+                let _ = writeln!(
+                    text,
+                    "parseltongue_http_requests_total{{method=\"{}\",route=\"{}\",status=\"{}\"}} {}",
+                    escape_label_value(method),
+                    escape_label_value(route),
+                    status,
+                    count
+                );
+            }
+        }
+        text.push_str("# HELP parseltongue_http_request_duration_seconds Request latency, by route template\n");
+        text.push_str("# TYPE parseltongue_http_request_duration_seconds histogram\n");
+        for ((method, route), entry) in routes.iter() {
+            let labels = format!("method=\"{}\",route=\"{}\"", escape_label_value(method), escape_label_value(route));
+            let mut cumulative = 0;
+            for (bound, count) in QUERY_LATENCY_BUCKET_SECONDS.iter().zip(entry.bucket_counts.iter()) {
+                cumulative += count;
+                let _ = writeln!(text, "parseltongue_http_request_duration_seconds_bucket{{{},le=\"{}\"}} {}", labels, bound, cumulative);
+            }
+            let _ = writeln!(text, "parseltongue_http_request_duration_seconds_bucket{{{},le=\"+Inf\"}} {}", labels, entry.count);
+            let _ = writeln!(text, "parseltongue_http_request_duration_seconds_sum{{{}}} {}", labels, entry.sum_seconds);
+            let _ = writeln!(text, "parseltongue_http_request_duration_seconds_count{{{}}} {}", labels, entry.count);
+        }
+        text
+    }
+}
+
+fn escape_label_value(value: &str) -> String {
+    value.replace('\\', "\\\\").replace('"', "\\\"").replace('\n', "\\n")
+}
+
+/// Middleware: latency/status/cache metrics and, if exporting, a span per request
+///
+/// # 4-Word Name: record_daemon_query_telemetry
+///
+/// # Contract
+/// - Precondition: installed outside the ETag middleware, so 304s are seen
+/// - Postcondition: `/metrics` itself is not measured
+pub async fn record_daemon_query_telemetry(
+    State(state): State<SharedApplicationStateContainer>,
+    request: Request,
+    next: Next,
+) -> Response {
+    if request.uri().path() == PROMETHEUS_METRICS_ENDPOINT_PATH {
+        return next.run(request).await;
+    }
+    let registry = state.query_telemetry_registry_arc.clone();
+    let method = request.method().to_string();
+    let route = request
+        .extensions()
+        .get::<MatchedPath>()
+        .map_or_else(|| "unmatched".to_string(), |matched| matched.as_str().to_string());
+    let conditional = method == "GET" && request.headers().contains_key(header::IF_NONE_MATCH);
+    let record_span = registry.is_span_export_enabled();
+    let parent = record_span
+        .then(|| request.headers().get("traceparent").and_then(|value| value.to_str().ok()).and_then(parse_w3c_traceparent_header))
+        .flatten();
+    let query = request.uri().query().map(str::to_string);
+    let start_unix_nanos = current_unix_time_nanos();
+    let started = Instant::now();
+
+    let response = next.run(request).await;
+
+    let elapsed = started.elapsed();
+    let status = response.status().as_u16();
+    registry.record_query_request_observation(&method, &route, status, elapsed.as_secs_f64(), conditional);
+    if record_span {
+        let (trace_id, parent_span_id) = match parent {
+            Some((trace_id, parent_span_id)) => (trace_id, Some(parent_span_id)),
+            None => (generate_random_hex_identifier(16), None),
+        };
+        let mut attributes = vec![
+            ("http.request.method".to_string(), TelemetryAttributeValueKind::Text(method.clone())),
+            ("http.route".to_string(), TelemetryAttributeValueKind::Text(route.clone())),
+            ("http.response.status_code".to_string(), TelemetryAttributeValueKind::Int(status.into())),
+        ];
+        if let Some(query) = query {
+            attributes.push(("url.query".to_string(), TelemetryAttributeValueKind::Text(query)));
+        }
+        registry.push_pending_query_span(TelemetrySpanRecordEntry {
+            trace_id,
+            span_id: generate_random_hex_identifier(8),
+            parent_span_id,
+            name: format!("{} {}", method, route),
+            kind: TelemetrySpanKindValue::Server,
+            start_unix_nanos,
+            end_unix_nanos: start_unix_nanos + elapsed.as_nanos() as u64,
+            attributes,
+            error: status >= 500,
+        });
+    }
+    response
+}
+
+/// Turn on span recording and export queued spans every 5 s
+///
+/// # 4-Word Name: spawn_query_span_export_loop
+///
+/// # Contract
+/// - Postcondition: export failures are logged and the batch is dropped;
+///   the loop runs for the life of the process
+pub fn spawn_query_span_export_loop(
+    registry: Arc<DaemonQueryTelemetryRegistry>,
+    endpoint: OtlpHttpEndpointAddress,
+    service_name: String,
+) {
+    registry.span_export_enabled.store(true, Ordering::Relaxed);
+    tokio::spawn(async move {
+        let mut interval = tokio::time::interval(QUERY_SPAN_EXPORT_INTERVAL);
+        loop {
+            interval.tick().await;
+            let spans = registry.drain_pending_query_spans();
+            if spans.is_empty() {
+                continue;
+            }
+            let (endpoint, service_name) = (endpoint.clone(), service_name.clone());
+            let posted = tokio::task::spawn_blocking(move || post_otlp_trace_export_blocking(&endpoint, &service_name, &spans)).await;
+            match posted {
+                Ok(Ok(status)) if (200..300).contains(&status) => {}
+                Ok(Ok(status)) => eprintln!("[Telemetry] OTLP collector answered HTTP {}", status),
+                Ok(Err(e)) => eprintln!("[Telemetry] {}", e),
+                Err(e) => eprintln!("[Telemetry] span export aborted: {}", e),
+            }
+        }
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_exposition_has_cumulative_buckets_and_cache_ratio() {
+        let registry = DaemonQueryTelemetryRegistry::default();
+        registry.record_query_request_observation("GET", "/code-entities-list-all", 200, 0.003, true);
+        registry.record_query_request_observation("GET", "/code-entities-list-all", 304, 0.0005, true);
+        registry.record_query_request_observation("GET", "/code-entities-list-all", 200, 30.0, false);
+
+        let text = registry.render_prometheus_exposition_text(GraphSizeGaugeSnapshot { entities: 7, edges: 9, revision: 2, uptime_seconds: 60 });
+        let labels = "method=\"GET\",route=\"/code-entities-list-all\"";
+        assert!(text.contains("parseltongue_graph_entities 7\n"));
+        assert!(text.contains(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"0.001\"}} 1\n", labels)));
+        assert!(text.contains(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"0.005\"}} 2\n", labels)));
+        assert!(text.contains(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"10\"}} 2\n", labels)));
+        assert!(text.contains(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"+Inf\"}} 3\n", labels)));
+        assert!(text.contains(&format!("parseltongue_http_requests_total{{{},status=\"304\"}} 1\n", labels)));
+        assert!(text.contains("parseltongue_http_cache_hits_total 1\n"));
+        assert!(text.contains("parseltongue_http_cache_hit_ratio 0.5\n"));
+        assert_eq!(escape_label_value("a\"b\\"), "a\\\"b\\\\");
+    }
+
+    fn create_pending_test_span(name: &str) -> TelemetrySpanRecordEntry {
+        TelemetrySpanRecordEntry {
+            trace_id: generate_random_hex_identifier(16),
+            span_id: generate_random_hex_identifier(8),
+            parent_span_id: None,
+            name: name.to_string(),
+            kind: TelemetrySpanKindValue::Server,
+            start_unix_nanos: 1,
+            end_unix_nanos: 2,
+            attributes: Vec::new(),
+            error: false,
+        }
+    }
+
+    #[test]
+    fn test_series_order_cache_edge_cases_and_span_queue_cap() {
+        let registry = DaemonQueryTelemetryRegistry::default();
+        let snapshot = GraphSizeGaugeSnapshot::default();
+        assert!(registry.render_prometheus_exposition_text(snapshot).contains("parseltongue_http_cache_hit_ratio 0\n"));
+
+        // A 304 without If-None-Match is not a cache lookup
+        registry.record_query_request_observation("POST", "/a\"q", 200, 0.001, false);
+        registry.record_query_request_observation("GET", "/b", 304, 0.01, false);
+        registry.record_query_request_observation("GET", "/a", 500, 2.0, false);
+        let text = registry.render_prometheus_exposition_text(snapshot);
+        assert!(text.contains("parseltongue_http_cache_lookups_total 0\n"));
+        assert!(text.contains("parseltongue_http_cache_hits_total 0\n"));
+        let position = |needle: &str| text.find(needle).unwrap_or_else(|| panic!("missing {:?} in\n{}", needle, text));
+        let get_a = position("parseltongue_http_requests_total{method=\"GET\",route=\"/a\",status=\"500\"} 1\n");
+        let get_b = position("parseltongue_http_requests_total{method=\"GET\",route=\"/b\",status=\"304\"} 1\n");
+        let post = position("parseltongue_http_requests_total{method=\"POST\",route=\"/a\\\"q\",status=\"200\"} 1\n");
+        assert!(get_a < get_b && get_b < post, "series sort by method, then route");
+        // Bucket bounds are inclusive
+        let b_labels = "method=\"GET\",route=\"/b\"";
+        position(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"0.005\"}} 0\n", b_labels));
+        position(&format!("parseltongue_http_request_duration_seconds_bucket{{{},le=\"0.01\"}} 1\n", b_labels));
+        position(&format!("parseltongue_http_request_duration_seconds_sum{{{}}} 0.01\n", b_labels));
+        assert_eq!(escape_label_value("line\nbreak"), "line\\nbreak");
+
+        assert!(!registry.is_span_export_enabled());
+        for index in 0..MAX_PENDING_QUERY_SPANS + 2 {
+            registry.push_pending_query_span(create_pending_test_span(&index.to_string()));
+        }
+        let drained = registry.drain_pending_query_spans();
+        assert_eq!(drained.len(), MAX_PENDING_QUERY_SPANS);
+        assert_eq!(drained.last().unwrap().name, (MAX_PENDING_QUERY_SPANS - 1).to_string(), "newest spans are dropped");
+        assert!(registry.drain_pending_query_spans().is_empty());
+        registry.push_pending_query_span(create_pending_test_span("after"));
+        assert_eq!(registry.drain_pending_query_spans().len(), 1, "draining frees the queue");
+        let text = registry.render_prometheus_exposition_text(snapshot);
+        assert!(text.contains("parseltongue_trace_spans_dropped_total 2\n"));
+    }
+
+    #[tokio::test]
+    async fn test_middleware_spans_join_traces_and_skip_metrics() {
+        use axum::{body::Body, routing::get, Router};
+        use tower::ServiceExt;
+
+        let state = SharedApplicationStateContainer::create_new_application_state();
+        let registry = state.query_telemetry_registry_arc.clone();
+        let app = Router::new()
+            .route("/items/:id", get(|| async { "ok" }))
+            .route("/boom", get(|| async { StatusCode::INTERNAL_SERVER_ERROR }))
+            .route(PROMETHEUS_METRICS_ENDPOINT_PATH, get(|| async { "" }))
+            .layer(axum::middleware::from_fn_with_state(state.clone(), record_daemon_query_telemetry))
+            .with_state(state);
+        let send = |uri: &str, traceparent: Option<&str>| {
+            let mut request = axum::http::Request::builder().uri(uri);
+            if let Some(value) = traceparent {
+                request = request.header("traceparent", value);
+            }
+            app.clone().oneshot(request.body(Body::empty()).unwrap())
+        };
+
+        // Spans are only recorded once the export loop enables them
+        assert_eq!(send("/items/1", None).await.unwrap().status(), StatusCode::OK);
+        assert!(registry.drain_pending_query_spans().is_empty());
+        registry.span_export_enabled.store(true, Ordering::Relaxed);
+
+        let trace = "4bf92f3577b34da6a3ce929d0e0e4736";
+        let traceparent = format!("00-{}-00f067aa0ba902b7-01", trace);
+        send("/items/7?depth=2", Some(&traceparent)).await.unwrap();
+        assert_eq!(send("/boom", Some("garbage")).await.unwrap().status(), StatusCode::INTERNAL_SERVER_ERROR);
+        send(PROMETHEUS_METRICS_ENDPOINT_PATH, None).await.unwrap();
+        assert_eq!(send("/nope", None).await.unwrap().status(), StatusCode::NOT_FOUND);
+
+        let spans = registry.drain_pending_query_spans();
+        let names: Vec<&str> = spans.iter().map(|s| s.name.as_str()).collect();
+        assert_eq!(names, vec!["GET /items/:id", "GET /boom", "GET unmatched"]);
+        assert_eq!((spans[0].trace_id.as_str(), spans[0].parent_span_id.as_deref()), (trace, Some("00f067aa0ba902b7")));
+        assert_eq!(spans[0].kind, TelemetrySpanKindValue::Server);
+        assert!(!spans[0].error);
+        let query = ("url.query".to_string(), TelemetryAttributeValueKind::Text("depth=2".to_string()));
+        assert!(spans[0].attributes.contains(&query));
+        assert!(spans[0].end_unix_nanos >= spans[0].start_unix_nanos);
+        // A malformed traceparent starts a new trace
+        assert_ne!(spans[1].trace_id, trace);
+        assert_eq!((spans[1].trace_id.len(), spans[1].parent_span_id.as_ref()), (32, None));
+        assert!(spans[1].error);
+        let status = ("http.response.status_code".to_string(), TelemetryAttributeValueKind::Int(500));
+        assert!(spans[1].attributes.contains(&status));
+        assert!(!spans[2].error, "a 404 is not a server error");
+
+        let text = registry.render_prometheus_exposition_text(GraphSizeGaugeSnapshot::default());
+        let items = "parseltongue_http_requests_total{method=\"GET\",route=\"/items/:id\",status=\"200\"} 2\n";
+        assert!(text.contains(items), "{}", text);
+        assert!(!text.contains("route=\"/metrics\""), "{}", text);
+    }
+}
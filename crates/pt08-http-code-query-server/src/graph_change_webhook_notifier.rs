@@ -0,0 +1,313 @@
+//! Graph change webhook notifier (v1.7.3)
+//!
+//! # 4-Word Naming: graph_change_webhook_notifier
+//!
+//! `parseltongue daemon --webhook URL` POSTs a JSON event whenever a reindex
+//! changes the shape of the graph, so a Slack bot or CI job can react to
+//! architectural changes as they are saved. A reindex is reported when it
+//! adds or removes an exported symbol (Go capitalization, Python leading
+//! underscore, visibility elsewhere) or adds an edge into another package:
+//! a stored entity in a different directory, an unresolved reference whose
+//! name matches exactly one entity in another directory, or a new external
+//! dependency. Body-only edits stay silent.
+//!
+//! Delivery is fire-and-forget with a 5 s timeout through the shared
+//! `outbound_http_post_client` (`http://` and `https://`); failures are
+//! logged and never block the watcher.
+
+use std::collections::{BTreeSet, HashMap, HashSet};
+use std::path::Path;
+use std::time::Duration;
+
+use anyhow::{Context, Result};
+use parseltongue_core::dead_code_detection_analyzer::is_entity_symbol_exported;
+use parseltongue_core::entities::{CodeEntity, DependencyEdge};
+use parseltongue_core::go_embedding_promotion_resolver::package_directory_of_entity;
+use parseltongue_core::outbound_http_post_client::{
+    parse_http_endpoint_url, post_json_body_blocking, HttpEndpointUrlParts,
+};
+use parseltongue_core::storage::CozoDbStorage;
+use serde::Serialize;
+
+/// `event` field of every payload
+pub const GRAPH_CHANGE_EVENT_NAME: &str = "graph.changed";
+
+/// Connect + write + response
+const WEBHOOK_DELIVERY_TIMEOUT: Duration = Duration::from_secs(5);
+
+/// Exported symbol added or removed by a reindex
+///
+/// # 4-Word Name: WebhookSymbolChangeEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct WebhookSymbolChangeEntry {
+    pub key: String,
+    pub name: String,
+    pub kind: String,
+    pub file_path: String,
+}
+
+/// New edge whose target lives in another package
+///
+/// # 4-Word Name: WebhookCrossModuleEdgeEntry
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct WebhookCrossModuleEdgeEntry {
+    pub from_key: String,
+    pub to_key: String,
+    pub edge_type: String,
+    pub from_package: String,
+    /// Directory of the target, or `external:<name>` for dependencies
+    pub to_package: String,
+}
+
+/// Payload POSTed to the webhook
+///
+/// # 4-Word Name: GraphChangeWebhookEvent
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct GraphChangeWebhookEvent {
+    pub event: String,
+    pub file_path: String,
+    pub graph_revision: u64,
+    pub timestamp: String,
+    pub added_public_symbols: Vec<WebhookSymbolChangeEntry>,
+    pub removed_public_symbols: Vec<WebhookSymbolChangeEntry>,
+    pub new_cross_module_edges: Vec<WebhookCrossModuleEdgeEntry>,
+}
+
+/// Split an `http(s)://host[:port][/path]` webhook URL
+///
+/// # 4-Word Name: parse_http_webhook_url
+///
+/// # Contract
+/// - Postcondition: other schemes are rejected with a message suitable for
+///   the CLI; the path defaults to `/`
+pub fn parse_http_webhook_url(url: &str) -> Result<HttpEndpointUrlParts, String> {
+    parse_http_endpoint_url(url, "webhook")
+}
+
+/// Reindex outcome as seen by the notifier
+///
+/// # 4-Word Name: ReindexGraphChangeInputs
+pub struct ReindexGraphChangeInputs<'a> {
+    pub file_path: &'a str,
+    pub added_entities: Vec<&'a CodeEntity>,
+    pub removed_entities: Vec<&'a CodeEntity>,
+    /// Edges leaving the file before the reindex
+    pub previous_edges: &'a [DependencyEdge],
+    /// Edges leaving the file after the reindex
+    pub current_edges: &'a [DependencyEdge],
+}
+
+/// Build the event for a reindex, or `None` when nothing structural changed
+///
+/// # 4-Word Name: build_graph_change_event
+///
+/// # Contract
+/// - Precondition: `target_packages` maps edge targets to their package
+///   (see `resolve_edge_target_packages`); unmapped targets are ignored
+/// - Postcondition: an edge counts as new when no previous edge had the same
+///   source name, target and type, so re-keyed entities do not re-fire
+pub fn build_graph_change_event(
+    inputs: &ReindexGraphChangeInputs<'_>,
+    target_packages: &HashMap<String, String>,
+    graph_revision: u64,
+) -> Option<GraphChangeWebhookEvent> {
+    let symbol_entries = |entities: &[&CodeEntity]| -> Vec<WebhookSymbolChangeEntry> {
+        let mut entries: Vec<WebhookSymbolChangeEntry> = entities
+            .iter()
+            .filter(|entity| is_entity_symbol_exported(entity))
+            .map(|entity| WebhookSymbolChangeEntry {
+                key: entity.isgl1_key.clone(),
+                name: entity.interface_signature.name.clone(),
+                kind: key_segment(&entity.isgl1_key, 1).to_string(),
+                file_path: entity.interface_signature.file_path.to_string_lossy().to_string(),
+            })
+            .collect();
+        entries.sort_by(|a, b| a.key.cmp(&b.key));
+        entries
+    };
+    let added_public_symbols = symbol_entries(&inputs.added_entities);
+    let removed_public_symbols = symbol_entries(&inputs.removed_entities);
+
+    let from_package = Path::new(inputs.file_path)
+        .parent()
+        .map(|p| p.to_string_lossy().to_string())
+        .unwrap_or_default();
+    let previous: HashSet<(&str, &str, &str)> = inputs.previous_edges.iter().map(edge_identity).collect();
+    let mut seen = HashSet::new();
+    let mut new_cross_module_edges: Vec<WebhookCrossModuleEdgeEntry> = inputs
+        .current_edges
+        .iter()
+        .filter(|edge| !previous.contains(&edge_identity(edge)) && seen.insert(edge_identity(edge)))
+        .filter_map(|edge| {
+            let to_package = target_packages.get(edge.to_key.as_str())?;
+            (to_package != &from_package).then(|| WebhookCrossModuleEdgeEntry {
+                from_key: edge.from_key.as_str().to_string(),
+                to_key: edge.to_key.as_str().to_string(),
+                edge_type: edge.edge_type.as_str().to_string(),
+                from_package: from_package.clone(),
+                to_package: to_package.clone(),
+            })
+        })
+        .collect();
+    new_cross_module_edges.sort_by(|a, b| (&a.from_key, &a.to_key).cmp(&(&b.from_key, &b.to_key)));
+
+    if added_public_symbols.is_empty() && removed_public_symbols.is_empty() && new_cross_module_edges.is_empty() {
+        return None;
+    }
+    Some(GraphChangeWebhookEvent {
+        event: GRAPH_CHANGE_EVENT_NAME.to_string(),
+        file_path: inputs.file_path.to_string(),
+        graph_revision,
+        timestamp: chrono::Utc::now().to_rfc3339(),
+        added_public_symbols,
+        removed_public_symbols,
+        new_cross_module_edges,
+    })
+}
+
+fn key_segment(key: &str, index: usize) -> &str {
+    key.split(':').nth(index).unwrap_or("")
+}
+
+fn edge_identity(edge: &DependencyEdge) -> (&str, &str, &str) {
+    (key_segment(edge.from_key.as_str(), 2), edge.to_key.as_str(), edge.edge_type.as_str())
+}
+
+/// Package of every distinct edge target that can be placed
+///
+/// # 4-Word Name: resolve_edge_target_packages
+///
+/// # Contract
+/// - Postcondition: stored targets map to their directory, external
+///   dependencies to `external:<name>`, unresolved references to the
+///   directory of the single entity with that name; ambiguous or unknown
+///   targets are left out
+pub async fn resolve_edge_target_packages(
+    storage: &CozoDbStorage,
+    edges: &[DependencyEdge],
+) -> HashMap<String, String> {
+    let targets: BTreeSet<&str> = edges.iter().map(|edge| edge.to_key.as_str()).collect();
+    let mut packages = HashMap::new();
+    for target in targets {
+        let segment = key_segment(target, 3);
+        let package = if let Some(dependency) = segment.strip_prefix("external-dependency-") {
+            Some(format!("external:{}", dependency))
+        } else if segment == "unresolved-reference" {
+            match storage.find_entity_keys_by_symbol(key_segment(target, 2)).await {
+                Ok(keys) if keys.len() == 1 => storage.get_entity(&keys[0]).await.ok().map(|e| package_directory_of_entity(&e)),
+                _ => None,
+            }
+        } else {
+            storage.get_entity(target).await.ok().map(|e| package_directory_of_entity(&e))
+        };
+        if let Some(package) = package {
+            packages.insert(target.to_string(), package);
+        }
+    }
+    packages
+}
+
+/// Deliver `event` in the background
+///
+/// # 4-Word Name: spawn_graph_change_webhook_post
+pub fn spawn_graph_change_webhook_post(url: String, event: GraphChangeWebhookEvent) {
+    tokio::spawn(async move {
+        match post_json_webhook_event(&url, &event).await {
+            Ok(status) => println!("[Webhook] {} {} ({})", GRAPH_CHANGE_EVENT_NAME, event.file_path, status),
+            Err(e) => eprintln!("[Webhook] Delivery to {} failed: {:#}", url, e),
+        }
+    });
+}
+
+/// POST `event` as JSON; returns the HTTP status code
+///
+/// # 4-Word Name: post_json_webhook_event
+///
+/// # Contract
+/// - Postcondition: non-2xx responses are errors
+pub async fn post_json_webhook_event(url: &str, event: &GraphChangeWebhookEvent) -> Result<u16> {
+    let address = parse_http_webhook_url(url).map_err(anyhow::Error::msg)?;
+    let body = serde_json::to_vec(event)?;
+    let answer =
+        tokio::task::spawn_blocking(move || post_json_body_blocking(&address, &[], body, WEBHOOK_DELIVERY_TIMEOUT))
+            .await
+            .context("delivery task failed")?
+            .map_err(anyhow::Error::msg)?;
+    anyhow::ensure!((200..300).contains(&answer.status), "HTTP {}", answer.status);
+    Ok(answer.status)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::{
+        EdgeType, EntityClass, EntityType, InterfaceSignature, LanguageSpecificSignature, LineRange,
+        RustSignature, Visibility,
+    };
+    use std::path::PathBuf;
+
+    fn go_entity(key: &str, name: &str, file: &str) -> CodeEntity {
+        let signature = InterfaceSignature {
+            entity_type: EntityType::Function,
+            name: name.to_string(),
+            visibility: Visibility::Public,
+            file_path: PathBuf::from(file),
+            line_range: LineRange::new(1, 3).unwrap(),
+            module_path: vec![],
+            documentation: None,
+            language_specific: LanguageSpecificSignature::Rust(RustSignature {
+                generics: vec![],
+                lifetimes: vec![],
+                where_clauses: vec![],
+                attributes: vec![],
+                trait_impl: None,
+            }),
+        };
+        CodeEntity::new(key.to_string(), signature, EntityClass::CodeImplementation).unwrap()
+    }
+
+    #[test]
+    fn test_event_reports_exported_symbols_and_cross_package_edges() {
+        let exported = go_entity("go:fn:Handle:__api_server:T1", "Handle", "api/server.go");
+        let private = go_entity("go:fn:helper:__api_server:T2", "helper", "api/server.go");
+        let edge = |from: &str, to: &str| {
+            DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+        };
+        let previous = vec![edge("go:fn:Handle:__api_server:T0", "go:fn:Open:__store_db:T5")];
+        let current = vec![
+            edge("go:fn:Handle:__api_server:T1", "go:fn:Open:__store_db:T5"),
+            edge("go:fn:Handle:__api_server:T1", "go:fn:Save:unresolved-reference:0-0"),
+            edge("go:fn:helper:__api_server:T2", "go:fn:format:__api_util:T6"),
+        ];
+        let packages = HashMap::from([
+            ("go:fn:Open:__store_db:T5".to_string(), "store".to_string()),
+            ("go:fn:Save:unresolved-reference:0-0".to_string(), "store".to_string()),
+            ("go:fn:format:__api_util:T6".to_string(), "api".to_string()),
+        ]);
+        let inputs = ReindexGraphChangeInputs {
+            file_path: "api/server.go",
+            added_entities: vec![&exported, &private],
+            removed_entities: vec![],
+            previous_edges: &previous,
+            current_edges: &current,
+        };
+        let event = build_graph_change_event(&inputs, &packages, 7).unwrap();
+        assert_eq!(event.event, GRAPH_CHANGE_EVENT_NAME);
+        assert_eq!(event.graph_revision, 7);
+        assert_eq!(event.added_public_symbols.len(), 1);
+        assert_eq!(event.added_public_symbols[0].name, "Handle");
+        assert_eq!(event.new_cross_module_edges.len(), 1);
+        assert_eq!(event.new_cross_module_edges[0].to_key, "go:fn:Save:unresolved-reference:0-0");
+        assert_eq!(event.new_cross_module_edges[0].to_package, "store");
+
+        let quiet = ReindexGraphChangeInputs { added_entities: vec![&private], current_edges: &previous, ..inputs };
+        assert!(build_graph_change_event(&quiet, &packages, 8).is_none());
+
+        let hook = parse_http_webhook_url("http://hooks.local:9000/ci/graph").unwrap();
+        assert_eq!((hook.host.as_str(), hook.port, hook.path.as_str()), ("hooks.local", 9000, "/ci/graph"));
+        assert_eq!(parse_http_webhook_url("http://relay").unwrap().path, "/");
+        let slack = parse_http_webhook_url("https://hooks.slack.com/x").unwrap();
+        assert_eq!((slack.https, slack.port), (true, 443));
+        assert!(parse_http_webhook_url("ftp://x").is_err());
+    }
+}
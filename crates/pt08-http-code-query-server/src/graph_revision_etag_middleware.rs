@@ -0,0 +1,117 @@
+//! ETag support tied to the graph revision
+//!
+//! # 4-Word Naming: graph_revision_etag_middleware
+//!
+//! v1.7.3: Dashboards poll the same GET endpoints over and over; between
+//! reindexes the answers cannot change. Every successful GET response carries
+//! `ETag: "<server start>-<graph revision>"`, and a request whose
+//! `If-None-Match` holds the current tag gets `304 Not Modified` without the
+//! handler running.
+//!
+//! The graph revision (`SharedApplicationStateContainer::graph_revision_counter_arc`)
+//! is bumped by every incremental reindex that changes the graph; the server
+//! start time keeps tags from one process from matching another's.
+//! Endpoints reporting live server state (health, file watcher, metrics)
+//! are exempt.
+
+use axum::{
+    extract::{Request, State},
+    http::{header, HeaderValue, Method, StatusCode},
+    middleware::Next,
+    response::{IntoResponse, Response},
+};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// GET endpoints whose answers change without a graph change
+pub const ETAG_EXEMPT_ENDPOINT_PATHS: &[&str] = &[
+    "/server-health-check-status",
+    "/file-watcher-status-check",
+    "/metrics",
+];
+
+/// Strong ETag for one graph revision of this server process
+///
+/// # 4-Word Name: format_graph_revision_etag
+pub fn format_graph_revision_etag(server_start_millis: i64, revision: u64) -> String {
+    format!("\"{:x}-{}\"", server_start_millis, revision)
+}
+
+/// Whether an `If-None-Match` value matches `etag`
+///
+/// # 4-Word Name: if_none_match_contains_etag
+///
+/// # Contract
+/// - Postcondition: true for `*`, or for any listed tag equal to `etag`
+///   (weak `W/` prefixes compare equal, as RFC 9110 requires for GET)
+pub fn if_none_match_contains_etag(if_none_match: &str, etag: &str) -> bool {
+    if_none_match
+        .split(',')
+        .map(str::trim)
+        .any(|candidate| candidate == "*" || candidate.trim_start_matches("W/") == etag)
+}
+
+/// Middleware: ETag on GET responses, 304 on a matching `If-None-Match`
+///
+/// # 4-Word Name: apply_graph_revision_etag
+///
+/// # Contract
+/// - Precondition: installed with `from_fn_with_state` over graph endpoints
+/// - Postcondition: only 2xx responses get an ETag; the tag is taken before
+///   the handler runs, so a reindex racing the request never yields a tag
+///   newer than the body
+pub async fn apply_graph_revision_etag(
+    State(state): State<SharedApplicationStateContainer>,
+    request: Request,
+    next: Next,
+) -> Response {
+    if request.method() != Method::GET || ETAG_EXEMPT_ENDPOINT_PATHS.contains(&request.uri().path()) {
+        return next.run(request).await;
+    }
+
+    let etag = format_graph_revision_etag(
+        state.server_start_timestamp_utc.timestamp_millis(),
+        state.current_graph_revision_value(),
+    );
+    let Ok(etag_value) = HeaderValue::from_str(&etag) else {
+        return next.run(request).await;
+    };
+
+    let not_modified = request
+        .headers()
+        .get(header::IF_NONE_MATCH)
+        .and_then(|value| value.to_str().ok())
+        .is_some_and(|value| if_none_match_contains_etag(value, &etag));
+    if not_modified {
+        return (StatusCode::NOT_MODIFIED, [(header::ETAG, etag_value)]).into_response();
+    }
+
+    let mut response = next.run(request).await;
+    if response.status().is_success() {
+        response.headers_mut().insert(header::ETAG, etag_value);
+    }
+    response
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_etag_changes_with_revision() {
+        let first = format_graph_revision_etag(1_700_000_000_000, 0);
+        assert_eq!(first, "\"18bcfe56800-0\"");
+        assert_ne!(first, format_graph_revision_etag(1_700_000_000_000, 1));
+        assert_ne!(first, format_graph_revision_etag(1_700_000_000_001, 0));
+    }
+
+    #[test]
+    fn test_if_none_match_list_and_weak_tags() {
+        let etag = "\"a-3\"";
+        assert!(if_none_match_contains_etag("\"a-3\"", etag));
+        assert!(if_none_match_contains_etag("\"a-2\", W/\"a-3\"", etag));
+        assert!(if_none_match_contains_etag("*", etag));
+        assert!(!if_none_match_contains_etag("\"a-2\"", etag));
+        assert!(!if_none_match_contains_etag("", etag));
+    }
+}
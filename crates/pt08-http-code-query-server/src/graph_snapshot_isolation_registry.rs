@@ -0,0 +1,390 @@
+//! Immutable graph snapshots pinned per query (v1.7.3)
+//!
+//! # 4-Word Naming: graph_snapshot_isolation_registry
+//!
+//! An incremental reindex rewrites a file's entities and edges in several
+//! steps (delete edges, delete entities, upsert, insert edges). A query
+//! issuing one database read per BFS hop, as blast radius does, could see
+//! the graph before some steps and after others. Multi-step queries instead
+//! pin an `ImmutableGraphSnapshotView` when they start: they keep reading
+//! that `Arc` however many reindexes complete meanwhile.
+//!
+//! ## Protocol
+//!
+//! - Writers (reindexes) hold `lock_graph_writer_exclusive` for their whole
+//!   mutation, bump the graph revision, then `advance_published_graph_snapshot`
+//!   derives the next snapshot from the previous one plus the rewritten file
+//!   and swaps it in (a pointer swap; readers never wait for a reindex).
+//!   The workspace ingest may rewrite edges of any file (it re-parses every
+//!   changed file and reruns the cross-file passes), so it calls
+//!   `reload_published_graph_snapshot` instead
+//! - Readers call `pin_graph_snapshot_for_query`. The snapshot is built
+//!   lazily on first use, under the writer lock so no reindex is half done
+//!   while the graph is loaded; servers that never run such a query never
+//!   hold a second copy of the graph
+//! - A snapshot that cannot be derived (an error, or a revision gap) is
+//!   dropped, and the next query reloads it from the database
+
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::ops::Bound;
+use std::sync::{Arc, RwLock as StdRwLock};
+
+use parseltongue_core::entities::{CodeEntity, DependencyEdge};
+use parseltongue_core::storage::path_utils::extract_subfolder_levels_from_path;
+use parseltongue_core::storage::CozoDbStorage;
+use tokio::sync::{Mutex, MutexGuard};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Read-only graph state of one revision
+///
+/// # 4-Word Name: ImmutableGraphSnapshotView
+#[derive(Debug)]
+pub struct ImmutableGraphSnapshotView {
+    /// Graph revision this snapshot reflects
+    pub revision: u64,
+    /// Entity key → file path
+    entity_file_paths: HashMap<String, String>,
+    edges: Vec<DependencyEdge>,
+    /// to_key → indices into `edges` (ordered for prefix lookups)
+    incoming_edge_index: BTreeMap<String, Vec<usize>>,
+}
+
+impl ImmutableGraphSnapshotView {
+    /// Snapshot of a whole graph
+    ///
+    /// # 4-Word Name: build_from_graph_parts
+    pub fn build_from_graph_parts(revision: u64, entities: &[CodeEntity], edges: Vec<DependencyEdge>) -> Self {
+        let entity_file_paths = entities
+            .iter()
+            .map(|e| (e.isgl1_key.clone(), e.interface_signature.file_path.to_string_lossy().to_string()))
+            .collect();
+        Self::index_snapshot_edge_list(revision, entity_file_paths, edges)
+    }
+
+    /// Next snapshot: this one with one file's entities and outgoing edges replaced
+    ///
+    /// # 4-Word Name: derive_with_file_replaced
+    ///
+    /// # Contract
+    /// - Precondition: `file_entities` / `outgoing_edges` are the stored
+    ///   entities of `file_path` and every edge leaving them or the file's
+    ///   previous entities, read after the reindex
+    /// - Postcondition: `self` is untouched (pinned readers keep it)
+    pub fn derive_with_file_replaced(
+        &self,
+        revision: u64,
+        file_path: &str,
+        file_entities: &[CodeEntity],
+        outgoing_edges: Vec<DependencyEdge>,
+    ) -> Self {
+        let rewritten_keys = self.file_rewritten_source_keys(file_path, file_entities);
+        let mut entity_file_paths: HashMap<String, String> = self
+            .entity_file_paths
+            .iter()
+            .filter(|(_, path)| path.as_str() != file_path)
+            .map(|(key, path)| (key.clone(), path.clone()))
+            .collect();
+        entity_file_paths.extend(file_entities.iter().map(|e| (e.isgl1_key.clone(), file_path.to_string())));
+
+        let mut edges: Vec<DependencyEdge> = self
+            .edges
+            .iter()
+            .filter(|edge| !rewritten_keys.contains(edge.from_key.as_str()))
+            .cloned()
+            .collect();
+        edges.extend(outgoing_edges.into_iter().filter(|edge| rewritten_keys.contains(edge.from_key.as_str())));
+        Self::index_snapshot_edge_list(revision, entity_file_paths, edges)
+    }
+
+    /// Keys of the file's entities before and after a reindex
+    ///
+    /// # 4-Word Name: file_rewritten_source_keys
+    pub fn file_rewritten_source_keys(&self, file_path: &str, file_entities: &[CodeEntity]) -> HashSet<String> {
+        self.entity_file_paths
+            .iter()
+            .filter(|(_, path)| path.as_str() == file_path)
+            .map(|(key, _)| key.clone())
+            .chain(file_entities.iter().map(|e| e.isgl1_key.clone()))
+            .collect()
+    }
+
+    /// Edges whose target is exactly `to_key`
+    ///
+    /// # 4-Word Name: incoming_edges_to_target
+    pub fn incoming_edges_to_target<'a>(&'a self, to_key: &str) -> impl Iterator<Item = &'a DependencyEdge> + 'a {
+        self.incoming_edge_index
+            .get(to_key)
+            .into_iter()
+            .flatten()
+            .map(move |&index| &self.edges[index])
+    }
+
+    /// Edges whose target key starts with `prefix`
+    ///
+    /// # 4-Word Name: incoming_edges_with_prefix
+    pub fn incoming_edges_with_prefix<'a>(&'a self, prefix: &str) -> impl Iterator<Item = &'a DependencyEdge> + 'a {
+        let prefix = prefix.to_string();
+        self.incoming_edge_index
+            .range::<String, _>((Bound::Included(prefix.clone()), Bound::Unbounded))
+            .take_while(move |(to_key, _)| to_key.starts_with(&prefix))
+            .flat_map(|(_, indices)| indices.iter())
+            .map(move |&index| &self.edges[index])
+    }
+
+    /// Whether `key` is an entity under the `L1` / `L2` root subfolders
+    ///
+    /// # 4-Word Name: entity_within_scope_levels
+    ///
+    /// Empty levels match anything; keys without an entity never match a
+    /// non-empty scope (the database join drops them too).
+    pub fn entity_within_scope_levels(&self, key: &str, l1: &str, l2: &str) -> bool {
+        if l1.is_empty() && l2.is_empty() {
+            return true;
+        }
+        let Some(path) = self.entity_file_paths.get(key) else {
+            return false;
+        };
+        let (entity_l1, entity_l2) = extract_subfolder_levels_from_path(path);
+        (l1.is_empty() || entity_l1 == l1) && (l2.is_empty() || entity_l2 == l2)
+    }
+
+    pub fn entity_count(&self) -> usize {
+        self.entity_file_paths.len()
+    }
+
+    pub fn edge_count(&self) -> usize {
+        self.edges.len()
+    }
+
+    fn index_snapshot_edge_list(
+        revision: u64,
+        entity_file_paths: HashMap<String, String>,
+        edges: Vec<DependencyEdge>,
+    ) -> Self {
+        let mut incoming_edge_index: BTreeMap<String, Vec<usize>> = BTreeMap::new();
+        for (index, edge) in edges.iter().enumerate() {
+            incoming_edge_index.entry(edge.to_key.to_string()).or_default().push(index);
+        }
+        Self { revision, entity_file_paths, edges, incoming_edge_index }
+    }
+}
+
+/// Published snapshot slot plus the lock serializing graph writers
+///
+/// # 4-Word Name: GraphSnapshotSwapRegistry
+#[derive(Debug, Default)]
+pub struct GraphSnapshotSwapRegistry {
+    /// Held only for the pointer read or swap, never across an await
+    published_snapshot_slot: StdRwLock<Option<Arc<ImmutableGraphSnapshotView>>>,
+    graph_writer_serial_lock: Mutex<()>,
+}
+
+impl GraphSnapshotSwapRegistry {
+    /// The published snapshot, whatever its revision
+    ///
+    /// # 4-Word Name: current_published_graph_snapshot
+    pub fn current_published_graph_snapshot(&self) -> Option<Arc<ImmutableGraphSnapshotView>> {
+        self.published_snapshot_slot.read().unwrap_or_else(|e| e.into_inner()).clone()
+    }
+
+    /// Atomically replace (or clear) the published snapshot
+    ///
+    /// # 4-Word Name: swap_published_graph_snapshot
+    pub fn swap_published_graph_snapshot(&self, snapshot: Option<Arc<ImmutableGraphSnapshotView>>) {
+        *self.published_snapshot_slot.write().unwrap_or_else(|e| e.into_inner()) = snapshot;
+    }
+
+    /// Exclusive right to mutate the graph until the guard drops
+    ///
+    /// # 4-Word Name: lock_graph_writer_exclusive
+    pub async fn lock_graph_writer_exclusive(&self) -> MutexGuard<'_, ()> {
+        self.graph_writer_serial_lock.lock().await
+    }
+}
+
+/// Snapshot of the current graph revision for one query
+///
+/// # 4-Word Name: pin_graph_snapshot_for_query
+///
+/// # Contract
+/// - Postcondition: the returned snapshot stays valid and unchanged for as
+///   long as the caller holds it
+/// - None without a database, or when loading the graph failed
+pub async fn pin_graph_snapshot_for_query(
+    state: &SharedApplicationStateContainer,
+) -> Option<Arc<ImmutableGraphSnapshotView>> {
+    let registry = &state.graph_snapshot_registry_arc;
+    let fresh = |snapshot: &Option<Arc<ImmutableGraphSnapshotView>>| {
+        snapshot.as_ref().is_some_and(|s| s.revision == state.current_graph_revision_value())
+    };
+    let published = registry.current_published_graph_snapshot();
+    if fresh(&published) {
+        return published;
+    }
+
+    let _writer = registry.lock_graph_writer_exclusive().await;
+    // Another query may have loaded it while we waited
+    let published = registry.current_published_graph_snapshot();
+    if fresh(&published) {
+        return published;
+    }
+    let storage = state.database_storage_connection_arc.read().await.as_ref()?.clone();
+    let revision = state.current_graph_revision_value();
+    let snapshot = Arc::new(load_graph_snapshot_from_storage(&storage, revision).await?);
+    registry.swap_published_graph_snapshot(Some(snapshot.clone()));
+    Some(snapshot)
+}
+
+/// Publish the snapshot of `revision` reloaded from the database
+///
+/// # 4-Word Name: reload_published_graph_snapshot
+///
+/// # Contract
+/// - Precondition: called under `lock_graph_writer_exclusive`, after the
+///   writes and the revision bump
+/// - Postcondition: the published snapshot is `revision`'s, read whole from
+///   `storage`; none when no snapshot was loaded yet or a read failed
+pub async fn reload_published_graph_snapshot(
+    state: &SharedApplicationStateContainer,
+    storage: &CozoDbStorage,
+    revision: u64,
+) {
+    let registry = &state.graph_snapshot_registry_arc;
+    if registry.current_published_graph_snapshot().is_none() {
+        return;
+    }
+    let snapshot = load_graph_snapshot_from_storage(storage, revision).await;
+    registry.swap_published_graph_snapshot(snapshot.map(Arc::new));
+}
+
+/// Snapshot of every stored entity and edge
+async fn load_graph_snapshot_from_storage(
+    storage: &CozoDbStorage,
+    revision: u64,
+) -> Option<ImmutableGraphSnapshotView> {
+    let entities = storage.get_all_entities().await.ok()?;
+    let edges = storage.get_all_dependencies().await.ok()?;
+    Some(ImmutableGraphSnapshotView::build_from_graph_parts(revision, &entities, edges))
+}
+
+/// Publish the snapshot of `revision` after a reindex of `file_path`
+///
+/// # 4-Word Name: advance_published_graph_snapshot
+///
+/// # Contract
+/// - Precondition: called under `lock_graph_writer_exclusive`, after the
+///   writes and the revision bump
+/// - Postcondition: the published snapshot is `revision`'s, or none (no
+///   snapshot was loaded yet, it was not `revision - 1`'s, or a read failed)
+pub async fn advance_published_graph_snapshot(
+    state: &SharedApplicationStateContainer,
+    storage: &CozoDbStorage,
+    file_path: &str,
+    revision: u64,
+) {
+    let registry = &state.graph_snapshot_registry_arc;
+    let Some(previous) = registry.current_published_graph_snapshot() else {
+        return;
+    };
+    if previous.revision + 1 != revision {
+        registry.swap_published_graph_snapshot(None);
+        return;
+    }
+
+    let Ok(file_entities) = storage.get_entities_by_file_path(file_path).await else {
+        registry.swap_published_graph_snapshot(None);
+        return;
+    };
+    let source_keys: Vec<String> = previous.file_rewritten_source_keys(file_path, &file_entities).into_iter().collect();
+    let Ok(touching_edges) = storage.get_edges_touching_entity_keys(&source_keys).await else {
+        registry.swap_published_graph_snapshot(None);
+        return;
+    };
+    let next = previous.derive_with_file_replaced(revision, file_path, &file_entities, touching_edges);
+    registry.swap_published_graph_snapshot(Some(Arc::new(next)));
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::{
+        EdgeType, EntityClass, EntityType, InterfaceSignature, LanguageSpecificSignature, LineRange,
+        RustSignature, Visibility,
+    };
+    use std::path::PathBuf;
+
+    fn go_entity(key: &str, name: &str, file: &str) -> CodeEntity {
+        let signature = InterfaceSignature {
+            entity_type: EntityType::Function,
+            name: name.to_string(),
+            visibility: Visibility::Public,
+            file_path: PathBuf::from(file),
+            line_range: LineRange::new(1, 3).unwrap(),
+            module_path: vec![],
+            documentation: None,
+            language_specific: LanguageSpecificSignature::Rust(RustSignature {
+                generics: vec![],
+                lifetimes: vec![],
+                where_clauses: vec![],
+                attributes: vec![],
+                trait_impl: None,
+            }),
+        };
+        CodeEntity::new(key.to_string(), signature, EntityClass::CodeImplementation).unwrap()
+    }
+
+    fn calls(from: &str, to: &str) -> DependencyEdge {
+        DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+    }
+
+    #[test]
+    fn test_derived_snapshot_leaves_pinned_snapshot_unchanged() {
+        let entities = vec![
+            go_entity("go:fn:Handle:__api_server:T1", "Handle", "api/server.go"),
+            go_entity("go:fn:Save:__store_db:T2", "Save", "store/db.go"),
+        ];
+        let edges = vec![calls("go:fn:Handle:__api_server:T1", "go:fn:Save:__store_db:T2")];
+        let pinned = Arc::new(ImmutableGraphSnapshotView::build_from_graph_parts(4, &entities, edges));
+
+        // server.go now holds Serve, which calls Save; Handle is gone
+        let rewritten = vec![go_entity("go:fn:Serve:__api_server:T3", "Serve", "api/server.go")];
+        let next = pinned.derive_with_file_replaced(
+            5,
+            "api/server.go",
+            &rewritten,
+            vec![calls("go:fn:Serve:__api_server:T3", "go:fn:Save:__store_db:T2")],
+        );
+
+        let callers = |snapshot: &ImmutableGraphSnapshotView| -> Vec<String> {
+            snapshot.incoming_edges_to_target("go:fn:Save:__store_db:T2").map(|e| e.from_key.to_string()).collect()
+        };
+        assert_eq!(callers(&pinned), vec!["go:fn:Handle:__api_server:T1"]);
+        assert_eq!(callers(&next), vec!["go:fn:Serve:__api_server:T3"]);
+        assert_eq!((pinned.revision, next.revision), (4, 5));
+        assert_eq!(next.entity_count(), 2);
+        assert_eq!(next.incoming_edges_with_prefix("go:fn:Save:").count(), 1);
+        assert!(next.entity_within_scope_levels("go:fn:Serve:__api_server:T3", "api", ""));
+        assert!(!next.entity_within_scope_levels("go:fn:Handle:__api_server:T1", "api", ""));
+    }
+
+    #[tokio::test]
+    async fn test_pinned_snapshot_survives_swap() {
+        let storage = CozoDbStorage::new("mem").await.unwrap();
+        storage.create_schema().await.unwrap();
+        storage.create_dependency_edges_schema().await.unwrap();
+        let state = SharedApplicationStateContainer::create_with_database_storage(storage);
+
+        let pinned = pin_graph_snapshot_for_query(&state).await.unwrap();
+        assert_eq!(pinned.revision, 0);
+        let again = pin_graph_snapshot_for_query(&state).await.unwrap();
+        assert!(Arc::ptr_eq(&pinned, &again));
+
+        // A reindex elsewhere publishes revision 1; the pinned view stays at 0
+        let revision = state.bump_graph_revision_counter();
+        let storage = state.database_storage_connection_arc.read().await.as_ref().unwrap().clone();
+        advance_published_graph_snapshot(&state, &storage, "api/server.go", revision).await;
+        let latest = pin_graph_snapshot_for_query(&state).await.unwrap();
+        assert_eq!((pinned.revision, latest.revision), (0, 1));
+    }
+}
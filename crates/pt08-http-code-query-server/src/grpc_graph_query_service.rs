@@ -0,0 +1,581 @@
+//! gRPC GraphQuery service
+//!
+//! # 4-Word Naming: grpc_graph_query_service
+//!
+//! v1.7.3: Serves `parseltongue.v1.GraphQuery` (see
+//! `proto/parseltongue/v1/graph_query.proto`) so backend services — CI bots,
+//! internal portals — can query the graph with typed, streaming RPCs.
+//!
+//! ## Transport
+//!
+//! `POST /parseltongue.v1.GraphQuery/{Method}` over HTTP/2 cleartext on the
+//! REST port. The response body is a sequence of length-prefixed messages
+//! (one per list item for streaming RPCs) followed by `grpc-status` /
+//! `grpc-message` trailers. Compressed request messages are rejected.
+//! When the endpoint's `max_fanout` / `max_nodes` guards cut the result, a
+//! `parseltongue-truncation` trailer carries its `truncation` report as
+//! percent-encoded JSON.
+//!
+//! ## Implementation Strategy
+//!
+//! Same as the MCP server: every RPC maps to an existing HTTP endpoint and
+//! is dispatched through the axum router in-process, so gRPC, MCP and REST
+//! answers are identical. Protobuf is read and written with
+//! `protobuf_wire_format_codec` — no generated bindings, no protoc in the
+//! build. The endpoint's JSON `data` is re-encoded as the RPC's messages.
+
+use std::collections::VecDeque;
+use std::convert::Infallible;
+use std::pin::Pin;
+use std::task::{Context, Poll};
+
+use axum::{
+    body::{Body, Bytes},
+    extract::{Path, State},
+    http::{header, HeaderMap, HeaderValue, Request, StatusCode},
+    response::Response,
+    Router,
+};
+use http_body::Frame;
+use parseltongue_core::protobuf_wire_format_codec::{ProtobufFieldReader, ProtobufFieldValue, ProtobufMessageWriter};
+use serde_json::Value;
+use tower::ServiceExt;
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+use crate::route_definition_builder_module::build_complete_router_instance;
+
+/// Fully qualified service name (route prefix)
+pub const GRPC_SERVICE_NAME_STRING: &str = "parseltongue.v1.GraphQuery";
+
+/// gRPC status codes used by this service
+const GRPC_STATUS_OK: u32 = 0;
+const GRPC_STATUS_INVALID_ARGUMENT: u32 = 3;
+const GRPC_STATUS_NOT_FOUND: u32 = 5;
+const GRPC_STATUS_UNIMPLEMENTED: u32 = 12;
+const GRPC_STATUS_INTERNAL: u32 = 13;
+const GRPC_STATUS_UNAVAILABLE: u32 = 14;
+
+/// gRPC status code + message
+type GrpcCallError = (u32, String);
+
+/// Trailer carrying the endpoint's truncation report
+pub const GRPC_TRUNCATION_TRAILER_NAME: &str = "parseltongue-truncation";
+
+/// Successful RPC outcome
+///
+/// # 4-Word Name: GrpcQueryCallOutput
+#[derive(Debug, Clone, PartialEq)]
+pub struct GrpcQueryCallOutput {
+    pub messages: Vec<Vec<u8>>,
+    /// Endpoint `data.truncation`, only when something was cut
+    pub truncation: Option<Value>,
+}
+
+/// How a protobuf field maps to JSON / query parameters
+///
+/// # 4-Word Name: GrpcWireFieldKind
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum GrpcWireFieldKind {
+    /// `string`
+    Text,
+    /// `uint32`; 0 is the proto3 default and means "not set"
+    Unsigned,
+    /// `double`
+    Double,
+    /// enum value choosing one of the method's endpoints
+    EndpointSelector,
+}
+
+/// Request field mapped to a query parameter
+///
+/// # 4-Word Name: GrpcRequestFieldSpec
+#[derive(Debug, Clone, Copy)]
+pub struct GrpcRequestFieldSpec {
+    pub number: u32,
+    /// Query parameter name (also the proto field name)
+    pub param: &'static str,
+    pub kind: GrpcWireFieldKind,
+    pub required: bool,
+}
+
+/// RPC backed by HTTP endpoints
+///
+/// # 4-Word Name: GrpcQueryMethodEntry
+#[derive(Debug, Clone, Copy)]
+pub struct GrpcQueryMethodEntry {
+    pub name: &'static str,
+    /// Endpoint per `EndpointSelector` value; the first is the default
+    pub endpoints: &'static [&'static str],
+    pub fields: &'static [GrpcRequestFieldSpec],
+    /// Endpoint JSON `data` → encoded response messages
+    pub encode_response: fn(&Value) -> Vec<Vec<u8>>,
+}
+
+const SCOPE_FIELD_NUMBER_TWO: GrpcRequestFieldSpec = GrpcRequestFieldSpec {
+    number: 2,
+    param: "scope",
+    kind: GrpcWireFieldKind::Text,
+    required: false,
+};
+
+const ENTITY_FIELD_NUMBER_ONE: GrpcRequestFieldSpec = GrpcRequestFieldSpec {
+    number: 1,
+    param: "entity",
+    kind: GrpcWireFieldKind::Text,
+    required: true,
+};
+
+/// Method table: RPC name → endpoints + request fields
+pub const GRPC_QUERY_METHOD_TABLE: &[GrpcQueryMethodEntry] = &[
+    GrpcQueryMethodEntry {
+        name: "GetNode",
+        endpoints: &["/code-entity-detail-view"],
+        fields: &[
+            GrpcRequestFieldSpec { number: 1, param: "key", kind: GrpcWireFieldKind::Text, required: true },
+            SCOPE_FIELD_NUMBER_TWO,
+        ],
+        encode_response: encode_node_response_messages,
+    },
+    GrpcQueryMethodEntry {
+        name: "GetNeighbors",
+        // NeighborDirection: CALLERS = 0, CALLEES = 1
+        endpoints: &["/reverse-callers-query-graph", "/forward-callees-query-graph"],
+        fields: &[
+            ENTITY_FIELD_NUMBER_ONE,
+            GrpcRequestFieldSpec { number: 2, param: "direction", kind: GrpcWireFieldKind::EndpointSelector, required: false },
+            GrpcRequestFieldSpec { number: 3, param: "scope", kind: GrpcWireFieldKind::Text, required: false },
+            GrpcRequestFieldSpec { number: 4, param: "max_fanout", kind: GrpcWireFieldKind::Unsigned, required: false },
+            GrpcRequestFieldSpec { number: 5, param: "max_nodes", kind: GrpcWireFieldKind::Unsigned, required: false },
+        ],
+        encode_response: encode_neighbor_response_messages,
+    },
+    GrpcQueryMethodEntry {
+        name: "BlastRadius",
+        endpoints: &["/blast-radius-impact-analysis"],
+        fields: &[
+            ENTITY_FIELD_NUMBER_ONE,
+            GrpcRequestFieldSpec { number: 2, param: "hops", kind: GrpcWireFieldKind::Unsigned, required: false },
+            GrpcRequestFieldSpec { number: 3, param: "edges", kind: GrpcWireFieldKind::Text, required: false },
+            GrpcRequestFieldSpec { number: 4, param: "scope", kind: GrpcWireFieldKind::Text, required: false },
+            GrpcRequestFieldSpec { number: 5, param: "max_fanout", kind: GrpcWireFieldKind::Unsigned, required: false },
+            GrpcRequestFieldSpec { number: 6, param: "max_nodes", kind: GrpcWireFieldKind::Unsigned, required: false },
+        ],
+        encode_response: encode_impact_response_messages,
+    },
+    GrpcQueryMethodEntry {
+        name: "PackContext",
+        endpoints: &["/smart-context-token-budget"],
+        fields: &[
+            GrpcRequestFieldSpec { number: 1, param: "focus", kind: GrpcWireFieldKind::Text, required: true },
+            GrpcRequestFieldSpec { number: 2, param: "tokens", kind: GrpcWireFieldKind::Unsigned, required: false },
+            GrpcRequestFieldSpec { number: 3, param: "scope", kind: GrpcWireFieldKind::Text, required: false },
+        ],
+        encode_response: encode_context_response_messages,
+    },
+    GrpcQueryMethodEntry {
+        name: "Search",
+        endpoints: &["/code-entities-search-fuzzy"],
+        fields: &[
+            GrpcRequestFieldSpec { number: 1, param: "q", kind: GrpcWireFieldKind::Text, required: true },
+            SCOPE_FIELD_NUMBER_TWO,
+        ],
+        encode_response: encode_search_response_messages,
+    },
+];
+
+/// Response message field read from endpoint JSON: (number, JSON key, kind)
+type JsonMessageFieldSpec = (u32, &'static str, GrpcWireFieldKind);
+
+const NODE_MESSAGE_FIELDS: &[JsonMessageFieldSpec] = &[
+    (1, "key", GrpcWireFieldKind::Text),
+    (2, "file_path", GrpcWireFieldKind::Text),
+    (3, "entity_type", GrpcWireFieldKind::Text),
+    (4, "entity_class", GrpcWireFieldKind::Text),
+    (5, "language", GrpcWireFieldKind::Text),
+    (6, "code", GrpcWireFieldKind::Text),
+];
+
+const NEIGHBOR_MESSAGE_FIELDS: &[JsonMessageFieldSpec] = &[
+    (1, "from_key", GrpcWireFieldKind::Text),
+    (2, "to_key", GrpcWireFieldKind::Text),
+    (3, "edge_type", GrpcWireFieldKind::Text),
+    (4, "source_location", GrpcWireFieldKind::Text),
+];
+
+const CONTEXT_ITEM_MESSAGE_FIELDS: &[JsonMessageFieldSpec] = &[
+    (1, "entity_key", GrpcWireFieldKind::Text),
+    (2, "relevance_score", GrpcWireFieldKind::Double),
+    (3, "relevance_type", GrpcWireFieldKind::Text),
+    (4, "estimated_tokens", GrpcWireFieldKind::Unsigned),
+];
+
+/// Split a request body into its length-prefixed messages
+///
+/// # 4-Word Name: decode_grpc_message_frames
+///
+/// # Contract
+/// - Postcondition: Err(UNIMPLEMENTED) for compressed messages,
+///   Err(INVALID_ARGUMENT) for truncated frames
+pub fn decode_grpc_message_frames(body: &[u8]) -> Result<Vec<&[u8]>, GrpcCallError> {
+    let mut frames = Vec::new();
+    let mut rest = body;
+    while !rest.is_empty() {
+        if rest.len() < 5 {
+            return Err((GRPC_STATUS_INVALID_ARGUMENT, "Truncated gRPC message frame".to_string()));
+        }
+        if rest[0] != 0 {
+            return Err((GRPC_STATUS_UNIMPLEMENTED, "Compressed gRPC messages are not supported".to_string()));
+        }
+        let end = 5 + u32::from_be_bytes([rest[1], rest[2], rest[3], rest[4]]) as usize;
+        if rest.len() < end {
+            return Err((GRPC_STATUS_INVALID_ARGUMENT, "Truncated gRPC message frame".to_string()));
+        }
+        frames.push(&rest[5..end]);
+        rest = &rest[end..];
+    }
+    Ok(frames)
+}
+
+/// Prefix one message with the uncompressed flag and its length
+///
+/// # 4-Word Name: encode_grpc_message_frame
+pub fn encode_grpc_message_frame(message: &[u8]) -> Bytes {
+    let mut frame = Vec::with_capacity(5 + message.len());
+    frame.push(0);
+    frame.extend_from_slice(&(message.len() as u32).to_be_bytes());
+    frame.extend_from_slice(message);
+    Bytes::from(frame)
+}
+
+/// Build endpoint URI (path + query) from an encoded request message
+///
+/// # 4-Word Name: build_grpc_endpoint_uri
+///
+/// # Contract
+/// - Postcondition: Err(INVALID_ARGUMENT) names the first missing required
+///   field; unknown fields are skipped; for repeated fields the last wins
+///   (protobuf semantics); values are percent-encoded
+pub fn build_grpc_endpoint_uri(method: &GrpcQueryMethodEntry, message: &[u8]) -> Result<String, GrpcCallError> {
+    let mut values: Vec<Option<String>> = vec![None; method.fields.len()];
+    let mut endpoint_index = 0usize;
+    for field in ProtobufFieldReader::new(message) {
+        let (number, value) = field.map_err(|e| (GRPC_STATUS_INVALID_ARGUMENT, e.to_string()))?;
+        let Some(position) = method.fields.iter().position(|f| f.number == number) else {
+            continue;
+        };
+        let spec = &method.fields[position];
+        let wrong_type = || (GRPC_STATUS_INVALID_ARGUMENT, format!("Field {} has the wrong wire type", spec.param));
+        match spec.kind {
+            GrpcWireFieldKind::Text => {
+                let text = value.as_string_lossy().ok_or_else(wrong_type)?;
+                values[position] = Some(text).filter(|t| !t.is_empty());
+            }
+            GrpcWireFieldKind::Unsigned => {
+                let number = value.as_varint().ok_or_else(wrong_type)?;
+                values[position] = Some(number.to_string()).filter(|_| number != 0);
+            }
+            GrpcWireFieldKind::Double => {
+                let ProtobufFieldValue::Fixed64(bits) = value else {
+                    return Err(wrong_type());
+                };
+                let number = f64::from_bits(bits);
+                values[position] = Some(number.to_string()).filter(|_| number != 0.0);
+            }
+            GrpcWireFieldKind::EndpointSelector => {
+                endpoint_index = value.as_varint().ok_or_else(wrong_type)? as usize;
+            }
+        }
+    }
+
+    let endpoint = method
+        .endpoints
+        .get(endpoint_index)
+        .ok_or_else(|| (GRPC_STATUS_INVALID_ARGUMENT, format!("Unknown enum value {}", endpoint_index)))?;
+    let mut query_pairs = Vec::new();
+    for (spec, value) in method.fields.iter().zip(values) {
+        match value {
+            Some(value) => query_pairs.push(format!("{}={}", spec.param, urlencoding::encode(&value))),
+            None if spec.required => {
+                return Err((GRPC_STATUS_INVALID_ARGUMENT, format!("Missing required field: {}", spec.param)))
+            }
+            None => {}
+        }
+    }
+    if query_pairs.is_empty() {
+        Ok(endpoint.to_string())
+    } else {
+        Ok(format!("{}?{}", endpoint, query_pairs.join("&")))
+    }
+}
+
+/// Run one RPC through the HTTP router
+///
+/// # 4-Word Name: run_grpc_query_method_call
+///
+/// # Contract
+/// - Postcondition: Ok holds the encoded response messages (possibly none)
+///   and the truncation report if the result was cut; endpoint failures map to gRPC codes (400 → INVALID_ARGUMENT,
+///   404 → NOT_FOUND, 503 → UNAVAILABLE, else INTERNAL) with the
+///   endpoint's `error` text
+pub async fn run_grpc_query_method_call(
+    router: &Router,
+    method_name: &str,
+    body: &[u8],
+) -> Result<GrpcQueryCallOutput, GrpcCallError> {
+    let method = GRPC_QUERY_METHOD_TABLE
+        .iter()
+        .find(|m| m.name == method_name)
+        .ok_or_else(|| (GRPC_STATUS_UNIMPLEMENTED, format!("Unknown method: {}", method_name)))?;
+    let message = match decode_grpc_message_frames(body)?.as_slice() {
+        [message] => *message,
+        _ => return Err((GRPC_STATUS_INVALID_ARGUMENT, "Expected exactly one request message".to_string())),
+    };
+    let uri = build_grpc_endpoint_uri(method, message)?;
+
+    let request = Request::builder()
+        .uri(uri)
+        .body(Body::empty())
+        .map_err(|e| (GRPC_STATUS_INTERNAL, e.to_string()))?;
+    let response = router
+        .clone()
+        .oneshot(request)
+        .await
+        .map_err(|e| (GRPC_STATUS_INTERNAL, format!("Router error: {}", e)))?;
+    let status = response.status();
+    let bytes = axum::body::to_bytes(response.into_body(), usize::MAX)
+        .await
+        .map_err(|e| (GRPC_STATUS_INTERNAL, format!("Failed to read response body: {}", e)))?;
+    let json: Value = serde_json::from_slice(&bytes)
+        .map_err(|e| (GRPC_STATUS_INTERNAL, format!("Endpoint returned invalid JSON: {}", e)))?;
+
+    if !status.is_success() || json["success"] == Value::Bool(false) {
+        let error = json["error"].as_str().unwrap_or("Request failed").to_string();
+        return Err((grpc_status_for_http_status(status), error));
+    }
+    let truncation = Some(json["data"]["truncation"].clone()).filter(|t| t["truncated"] == Value::Bool(true));
+    Ok(GrpcQueryCallOutput {
+        messages: (method.encode_response)(&json["data"]),
+        truncation,
+    })
+}
+
+fn grpc_status_for_http_status(status: StatusCode) -> u32 {
+    match status {
+        StatusCode::BAD_REQUEST => GRPC_STATUS_INVALID_ARGUMENT,
+        StatusCode::NOT_FOUND => GRPC_STATUS_NOT_FOUND,
+        StatusCode::SERVICE_UNAVAILABLE => GRPC_STATUS_UNAVAILABLE,
+        _ => GRPC_STATUS_INTERNAL,
+    }
+}
+
+/// Handle `POST /parseltongue.v1.GraphQuery/{method}`
+///
+/// # 4-Word Name: handle_grpc_graph_query_call
+///
+/// # Contract
+/// - Postcondition: always HTTP 200 with `application/grpc`; the outcome is
+///   carried by the `grpc-status` trailer
+pub async fn handle_grpc_graph_query_call(
+    State(state): State<SharedApplicationStateContainer>,
+    Path(method_name): Path<String>,
+    body: Bytes,
+) -> Response {
+    let router = build_complete_router_instance(state);
+    match run_grpc_query_method_call(&router, &method_name, &body).await {
+        Ok(output) => build_grpc_stream_response(output.messages, GRPC_STATUS_OK, "", output.truncation.as_ref()),
+        Err((status, message)) => build_grpc_stream_response(Vec::new(), status, &message, None),
+    }
+}
+
+fn build_grpc_stream_response(
+    messages: Vec<Vec<u8>>,
+    status: u32,
+    message: &str,
+    truncation: Option<&Value>,
+) -> Response {
+    let mut trailers = HeaderMap::new();
+    trailers.insert("grpc-status", HeaderValue::from(status));
+    if let Ok(value) = HeaderValue::from_str(&urlencoding::encode(message)) {
+        if !message.is_empty() {
+            trailers.insert("grpc-message", value);
+        }
+    }
+    if let Some(report) = truncation {
+        if let Ok(value) = HeaderValue::from_str(&urlencoding::encode(&report.to_string())) {
+            trailers.insert(GRPC_TRUNCATION_TRAILER_NAME, value);
+        }
+    }
+    let body = GrpcMessageStreamBody {
+        frames: messages.iter().map(|m| encode_grpc_message_frame(m)).collect(),
+        trailers: Some(trailers),
+    };
+    let mut response = Response::new(Body::new(body));
+    response
+        .headers_mut()
+        .insert(header::CONTENT_TYPE, HeaderValue::from_static("application/grpc"));
+    response
+}
+
+/// Response body: message frames, then the status trailers
+///
+/// # 4-Word Name: GrpcMessageStreamBody
+struct GrpcMessageStreamBody {
+    frames: VecDeque<Bytes>,
+    trailers: Option<HeaderMap>,
+}
+
+impl http_body::Body for GrpcMessageStreamBody {
+    type Data = Bytes;
+    type Error = Infallible;
+
+    fn poll_frame(
+        mut self: Pin<&mut Self>,
+        _cx: &mut Context<'_>,
+    ) -> Poll<Option<Result<Frame<Self::Data>, Self::Error>>> {
+        if let Some(data) = self.frames.pop_front() {
+            return Poll::Ready(Some(Ok(Frame::data(data))));
+        }
+        Poll::Ready(self.trailers.take().map(|trailers| Ok(Frame::trailers(trailers))))
+    }
+
+    fn is_end_stream(&self) -> bool {
+        self.frames.is_empty() && self.trailers.is_none()
+    }
+}
+
+/// Encode selected fields of a JSON object as one protobuf message
+///
+/// # 4-Word Name: encode_json_object_message
+fn encode_json_object_message(object: &Value, fields: &[JsonMessageFieldSpec]) -> Vec<u8> {
+    let mut writer = ProtobufMessageWriter::new();
+    for &(number, name, kind) in fields {
+        let value = &object[name];
+        match kind {
+            GrpcWireFieldKind::Text => {
+                if let Some(text) = value.as_str() {
+                    writer.write_string_field(number, text);
+                }
+            }
+            GrpcWireFieldKind::Unsigned | GrpcWireFieldKind::EndpointSelector => {
+                if let Some(n) = value.as_u64() {
+                    writer.write_varint_field(number, n);
+                }
+            }
+            GrpcWireFieldKind::Double => {
+                if let Some(x) = value.as_f64() {
+                    writer.write_fixed64_field(number, x.to_bits());
+                }
+            }
+        }
+    }
+    writer.into_bytes()
+}
+
+fn encode_json_array_messages(items: &Value, fields: &[JsonMessageFieldSpec]) -> Vec<Vec<u8>> {
+    items
+        .as_array()
+        .map(|items| items.iter().map(|item| encode_json_object_message(item, fields)).collect())
+        .unwrap_or_default()
+}
+
+fn encode_node_response_messages(data: &Value) -> Vec<Vec<u8>> {
+    vec![encode_json_object_message(data, NODE_MESSAGE_FIELDS)]
+}
+
+fn encode_neighbor_response_messages(data: &Value) -> Vec<Vec<u8>> {
+    let edges = if data["callees"].is_array() { &data["callees"] } else { &data["callers"] };
+    encode_json_array_messages(edges, NEIGHBOR_MESSAGE_FIELDS)
+}
+
+fn encode_impact_response_messages(data: &Value) -> Vec<Vec<u8>> {
+    let mut messages = Vec::new();
+    for hop in data["by_hop"].as_array().into_iter().flatten() {
+        let distance = hop["hop"].as_u64().unwrap_or(0);
+        for key in hop["entities"].as_array().into_iter().flatten().filter_map(Value::as_str) {
+            let mut writer = ProtobufMessageWriter::new();
+            writer.write_string_field(1, key).write_varint_field(2, distance);
+            messages.push(writer.into_bytes());
+        }
+    }
+    messages
+}
+
+fn encode_context_response_messages(data: &Value) -> Vec<Vec<u8>> {
+    encode_json_array_messages(&data["context"], CONTEXT_ITEM_MESSAGE_FIELDS)
+}
+
+fn encode_search_response_messages(data: &Value) -> Vec<Vec<u8>> {
+    encode_json_array_messages(&data["entities"], NODE_MESSAGE_FIELDS)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use serde_json::json;
+
+    fn find_method(name: &str) -> &'static GrpcQueryMethodEntry {
+        GRPC_QUERY_METHOD_TABLE.iter().find(|m| m.name == name).unwrap()
+    }
+
+    #[test]
+    fn test_frames_roundtrip_and_reject_compression() {
+        let mut body = encode_grpc_message_frame(b"abc").to_vec();
+        body.extend_from_slice(&encode_grpc_message_frame(b""));
+        assert_eq!(decode_grpc_message_frames(&body).unwrap(), vec![&b"abc"[..], &b""[..]]);
+
+        assert_eq!(decode_grpc_message_frames(&body[..6]).unwrap_err().0, GRPC_STATUS_INVALID_ARGUMENT);
+        body[0] = 1;
+        assert_eq!(decode_grpc_message_frames(&body).unwrap_err().0, GRPC_STATUS_UNIMPLEMENTED);
+    }
+
+    #[test]
+    fn test_request_fields_become_endpoint_uri() {
+        let mut request = ProtobufMessageWriter::new();
+        request.write_string_field(1, "go:fn:Save:a b:T1").write_varint_field(2, 1);
+        let uri = build_grpc_endpoint_uri(find_method("GetNeighbors"), &request.into_bytes()).unwrap();
+        assert_eq!(uri, "/forward-callees-query-graph?entity=go%3Afn%3ASave%3Aa%20b%3AT1");
+
+        let mut request = ProtobufMessageWriter::new();
+        request.write_string_field(1, "k").write_varint_field(2, 2).write_string_field(3, "calls");
+        let uri = build_grpc_endpoint_uri(find_method("BlastRadius"), &request.into_bytes()).unwrap();
+        assert_eq!(uri, "/blast-radius-impact-analysis?entity=k&hops=2&edges=calls");
+
+        let mut request = ProtobufMessageWriter::new();
+        request.write_string_field(1, "k").write_varint_field(5, 3).write_varint_field(6, 40);
+        let uri = build_grpc_endpoint_uri(find_method("BlastRadius"), &request.into_bytes()).unwrap();
+        assert_eq!(uri, "/blast-radius-impact-analysis?entity=k&max_fanout=3&max_nodes=40");
+
+        let missing = build_grpc_endpoint_uri(find_method("BlastRadius"), &[]).unwrap_err();
+        assert_eq!(missing, (GRPC_STATUS_INVALID_ARGUMENT, "Missing required field: entity".to_string()));
+    }
+
+    #[test]
+    fn test_list_results_stream_one_message_each() {
+        let data = json!({ "context": [
+            { "entity_key": "a", "relevance_score": 0.5, "relevance_type": "direct_caller", "estimated_tokens": 40 },
+            { "entity_key": "b", "relevance_score": 0.0, "relevance_type": "direct_callee", "estimated_tokens": 10 },
+        ]});
+        let messages = encode_context_response_messages(&data);
+        assert_eq!(messages.len(), 2);
+        let fields: Vec<(u32, ProtobufFieldValue)> =
+            ProtobufFieldReader::new(&messages[0]).map(|f| f.unwrap()).collect();
+        assert_eq!(fields[1], (2, ProtobufFieldValue::Fixed64(0.5f64.to_bits())));
+        assert_eq!(fields[3], (4, ProtobufFieldValue::Varint(40)));
+
+        let impact = json!({ "by_hop": [{ "hop": 1, "count": 2, "entities": ["x", "y"] }] });
+        assert_eq!(encode_impact_response_messages(&impact).len(), 2);
+    }
+
+    #[tokio::test]
+    async fn test_unknown_entity_maps_to_grpc_status() {
+        let router = build_complete_router_instance(SharedApplicationStateContainer::create_new_application_state());
+        let mut request = ProtobufMessageWriter::new();
+        request.write_string_field(1, "rust:fn:missing:x:T1");
+        let body = encode_grpc_message_frame(&request.into_bytes());
+
+        let error = run_grpc_query_method_call(&router, "GetNode", &body).await.unwrap_err();
+        assert_eq!(error.0, GRPC_STATUS_NOT_FOUND);
+        let unknown = run_grpc_query_method_call(&router, "Nope", &body).await.unwrap_err();
+        assert_eq!(unknown.0, GRPC_STATUS_UNIMPLEMENTED);
+    }
+}
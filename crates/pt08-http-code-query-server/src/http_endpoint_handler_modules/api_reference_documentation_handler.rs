@@ -146,6 +146,12 @@ fn build_api_documentation_categories() -> Vec<EndpointCategoryDocPayload> {
                     description: "Returns this API documentation".to_string(),
                     parameters: vec![],
                 },
+                EndpointDocumentationEntryPayload {
+                    path: "/metrics".to_string(),
+                    method: "GET".to_string(),
+                    description: "Prometheus metrics: graph size, per-route query latency, ETag cache hit rate".to_string(),
+                    parameters: vec![],
+                },
             ],
         },
         EndpointCategoryDocPayload {
@@ -192,6 +198,69 @@ fn build_api_documentation_categories() -> Vec<EndpointCategoryDocPayload> {
                         create_scope_parameter_doc(),
                     ],
                 },
+                EndpointDocumentationEntryPayload {
+                    path: "/canonical-symbol-id-resolve".to_string(),
+                    method: "GET".to_string(),
+                    description: "Resolve a canonical symbol ID (lang://module/path#Type::member) to entity keys".to_string(),
+                    parameters: vec![
+                        EndpointParameterDocPayload {
+                            name: "id".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Canonical ID, e.g. go://internal/auth#Service::Login".to_string(),
+                        },
+                        EndpointParameterDocPayload {
+                            name: "symbol".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Raw qualified name (crate::auth::login, fmt.Println, os.path.join); needs language".to_string(),
+                        },
+                        EndpointParameterDocPayload {
+                            name: "language".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Language tag for symbol (rust, go, python, ...)".to_string(),
+                        },
+                        create_scope_parameter_doc(),
+                    ],
+                },
+                EndpointDocumentationEntryPayload {
+                    path: "/keyword-search-near-seed".to_string(),
+                    method: "GET".to_string(),
+                    description: "BM25 search over identifiers, doc comments and string literals, blended with graph distance from a seed".to_string(),
+                    parameters: vec![
+                        EndpointParameterDocPayload {
+                            name: "q".to_string(),
+                            param_type: "query".to_string(),
+                            required: true,
+                            description: "Search words, e.g. rate limiting".to_string(),
+                        },
+                        EndpointParameterDocPayload {
+                            name: "near".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Seed entity key; matches closer to it rank higher".to_string(),
+                        },
+                        EndpointParameterDocPayload {
+                            name: "proximity_weight".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Share of the score from graph distance, 0-1 (default: 0.4)".to_string(),
+                        },
+                        EndpointParameterDocPayload {
+                            name: "limit".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Maximum results (default: 10)".to_string(),
+                        },
+                    ],
+                },
+                EndpointDocumentationEntryPayload {
+                    path: "/workspace-modules-list-all".to_string(),
+                    method: "GET".to_string(),
+                    description: "Monorepo go.mod/Cargo.toml/package.json modules with entity counts and the scope selecting each".to_string(),
+                    parameters: vec![],
+                },
             ],
         },
         EndpointCategoryDocPayload {
@@ -253,9 +322,41 @@ fn build_api_documentation_categories() -> Vec<EndpointCategoryDocPayload> {
                             required: false,
                             description: "Maximum depth to traverse (default: 3)".to_string(),
                         },
+                        EndpointParameterDocPayload {
+                            name: "edges".to_string(),
+                            param_type: "query".to_string(),
+                            required: false,
+                            description: "Edge types to follow: calls,uses,implements,embeds,spawns,sendsto,receivesfrom,reads,writes,tests,queriestable,serializes,generatedfrom (default: all)".to_string(),
+                        },
                         create_scope_parameter_doc(),
                     ],
                 },
+                EndpointDocumentationEntryPayload {
+                    path: "/graph-query-dsl-evaluate".to_string(),
+                    method: "GET".to_string(),
+                    description: "Evaluates a graph query: traversals and filters combined with & | - !".to_string(),
+                    parameters: vec![
+                        EndpointParameterDocPayload {
+                            name: "q".to_string(),
+                            param_type: "query".to_string(),
+                            required: true,
+                            description: "Query, e.g. callers(UserService.CreateUser) & in_package(\"api\") depth 2".to_string(),
+                        },
+                    ],
+                },
+                EndpointDocumentationEntryPayload {
+                    path: "/cypher-subset-query-execute".to_string(),
+                    method: "GET".to_string(),
+                    description: "Runs read-only Cypher (MATCH/WHERE/RETURN) over node kinds and edge types".to_string(),
+                    parameters: vec![
+                        EndpointParameterDocPayload {
+                            name: "query".to_string(),
+                            param_type: "query".to_string(),
+                            required: true,
+                            description: "Cypher, e.g. MATCH (a:Function)-[:CALLS]->(b {name: 'Save'}) RETURN a.name LIMIT 20".to_string(),
+                        },
+                    ],
+                },
                 EndpointDocumentationEntryPayload {
                     path: "/circular-dependency-detection-scan".to_string(),
                     method: "GET".to_string(),
@@ -2,7 +2,7 @@
 //!
 //! # 4-Word Naming: blast_radius_impact_handler
 //!
-//! Endpoint: GET /blast-radius-impact-analysis?entity={key}&hops=N
+//! Endpoint: GET /blast-radius-impact-analysis?entity={key}&hops=N&edges=calls,implements
 //!
 //! Returns all entities that would be affected if the source entity changes.
 //! This is a transitive closure of REVERSE dependencies (callers) up to N hops.
@@ -11,6 +11,11 @@
 //! v1.0.4 FIX: Added fuzzy key matching for stdlib function calls.
 //! Edge targets may use simplified keys like `rust:fn:new:unknown:0-0`
 //! but we query with full entity keys like `rust:method:new:__path:38-54`.
+//!
+//! v1.7.3: Optional `edges` allow-list restricts which edge types are followed.
+//! v1.7.3: Traverses one pinned graph snapshot (`snapshot_revision` in the response).
+//! v1.7.3: `max_fanout` / `max_nodes` guard the walk like the CLI's
+//! `--max-fanout` / `--max-nodes`; `truncation` in the response says what was cut.
 
 use axum::{
     extract::{Query, State},
@@ -19,10 +24,16 @@ use axum::{
     response::IntoResponse,
 };
 use serde::{Deserialize, Serialize};
-use std::collections::{HashSet, VecDeque};
+use std::collections::{BTreeSet, HashSet, VecDeque};
+
+use parseltongue_core::entities::{DependencyEdge, EdgeType};
+use parseltongue_core::filtered_graph_traversal_queries::parse_edge_type_filter_list;
+use parseltongue_core::traversal_expansion_limit_guards::{
+    TraversalExpansionLimitSpec, TraversalTruncationMarkerReport,
+};
 
+use crate::graph_snapshot_isolation_registry::{pin_graph_snapshot_for_query, ImmutableGraphSnapshotView};
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
-use crate::scope_filter_utilities_module::parse_scope_build_filter_clause;
 
 /// Extract function name from ISGL1 key for fuzzy matching
 ///
@@ -54,6 +65,12 @@ pub struct BlastRadiusQueryParamsStruct {
     pub hops: usize,
     /// Filter by folder scope (e.g., "crates||parseltongue-core")
     pub scope: Option<String>,
+    /// Edge types to follow, comma-separated (default: all)
+    pub edges: Option<String>,
+    /// Callers followed per entity (default: all)
+    pub max_fanout: Option<usize>,
+    /// Affected entities reported, nearest first (default: all)
+    pub max_nodes: Option<usize>,
 }
 
 fn default_hops() -> usize {
@@ -79,6 +96,10 @@ pub struct BlastRadiusDataPayloadStruct {
     pub hops_requested: usize,
     pub total_affected: usize,
     pub by_hop: Vec<BlastRadiusHopDataItem>,
+    /// v1.7.3: Graph revision of the snapshot that answered
+    pub snapshot_revision: u64,
+    /// v1.7.3: What the depth, fan-out and node guards left out
+    pub truncation: TraversalTruncationMarkerReport,
 }
 
 /// Blast radius response payload
@@ -114,8 +135,8 @@ pub struct BlastRadiusErrorResponseStruct {
 /// - Error Handling: Returns 400 for missing entity, 404 for no results
 ///
 /// # URL Pattern
-/// - Endpoint: GET /blast-radius-impact-analysis?entity={key}&hops=N
-/// - Default hops: 3
+/// - Endpoint: GET /blast-radius-impact-analysis?entity={key}&hops=N&max_fanout=F&max_nodes=M
+/// - Default hops: 3; no fan-out or node limit
 pub async fn handle_blast_radius_impact_analysis(
     State(state): State<SharedApplicationStateContainer>,
     Query(params): Query<BlastRadiusQueryParamsStruct>,
@@ -136,8 +157,36 @@ pub async fn handle_blast_radius_impact_analysis(
         ).into_response();
     }
 
+    // v1.7.3: Parse edge type allow-list
+    let edge_types = match parse_edge_type_filter_list(params.edges.as_deref().unwrap_or_default()) {
+        Ok(types) => types,
+        Err(e) => {
+            return (
+                StatusCode::BAD_REQUEST,
+                Json(BlastRadiusErrorResponseStruct {
+                    success: false,
+                    error: e.to_string(),
+                    endpoint: "/blast-radius-impact-analysis".to_string(),
+                    tokens: 40,
+                }),
+            ).into_response();
+        }
+    };
+
+    // v1.7.3: Pin one graph snapshot for the whole traversal
+    let snapshot = pin_graph_snapshot_for_query(&state).await;
+
     // Compute blast radius using BFS traversal
-    let by_hop = compute_blast_radius_by_hops(&state, &params.entity, params.hops, &params.scope).await;
+    let limits = TraversalExpansionLimitSpec {
+        max_depth: params.hops,
+        max_fanout: params.max_fanout,
+        max_nodes: params.max_nodes,
+    };
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let by_hop = snapshot
+        .as_ref()
+        .map(|s| compute_blast_radius_by_hops(s, &params.entity, &params.scope, &edge_types, &mut truncation))
+        .unwrap_or_default();
 
     // Calculate total affected
     let total_affected: usize = by_hop.iter().map(|h| h.count).sum();
@@ -167,6 +216,8 @@ pub async fn handle_blast_radius_impact_analysis(
                 hops_requested: params.hops,
                 total_affected,
                 by_hop,
+                snapshot_revision: snapshot.map_or(0, |s| s.revision),
+                truncation,
             },
             tokens,
         }),
@@ -185,28 +236,27 @@ pub async fn handle_blast_radius_impact_analysis(
 /// Added fuzzy key matching: Edge targets may use simplified keys like
 /// `rust:fn:new:unknown:0-0` but we query with full entity keys.
 /// Now matches on function name pattern when exact key match fails.
-async fn compute_blast_radius_by_hops(
-    state: &SharedApplicationStateContainer,
+///
+/// # v1.7.3 Snapshot Isolation
+/// Every hop reads the one pinned snapshot, so a reindex finishing mid-query
+/// cannot mix old and new edges into the result.
+///
+/// # v1.7.3 Traversal Limits
+/// Walks `truncation.limits.max_depth` hops. Each entity follows at most
+/// `max_fanout` callers (in key order) and the walk stops admitting entities
+/// after `max_nodes`; one more hop is walked only to count what lies beyond.
+fn compute_blast_radius_by_hops(
+    snapshot: &ImmutableGraphSnapshotView,
     source_entity: &str,
-    max_hops: usize,
     scope_filter: &Option<String>,
+    edge_types: &[EdgeType],
+    truncation: &mut TraversalTruncationMarkerReport,
 ) -> Vec<BlastRadiusHopDataItem> {
-    // Clone Arc, release lock, then await
-    let storage = {
-        let db_guard = state.database_storage_connection_arc.read().await;
-        match db_guard.as_ref() {
-            Some(s) => s.clone(),
-            None => return Vec::new(),
-        }
-    }; // Lock released here
-
-    // Build scope filter clause
-    let scope_clause = parse_scope_build_filter_clause(scope_filter);
-    let scope_join = if scope_clause.is_empty() {
-        String::new()
-    } else {
-        format!(", *CodeGraph{{ISGL1_key: from_key, root_subfolder_L1, root_subfolder_L2}}{}", scope_clause)
-    };
+    let (scope_l1, scope_l2) = parse_scope_level_pair(scope_filter);
+    let followed_types: HashSet<&str> = edge_types.iter().map(|t| t.as_str()).collect();
+    let max_hops = truncation.limits.max_depth;
+    let max_nodes = truncation.limits.max_nodes.unwrap_or(usize::MAX);
+    let mut turned_away = 0usize;
 
     let mut result: Vec<BlastRadiusHopDataItem> = Vec::new();
     let mut visited: HashSet<String> = HashSet::new();
@@ -216,7 +266,8 @@ async fn compute_blast_radius_by_hops(
     visited.insert(source_entity.to_string());
     current_frontier.push_back(source_entity.to_string());
 
-    for hop in 1..=max_hops {
+    for hop in 1..=max_hops.saturating_add(1) {
+        let beyond_depth = hop > max_hops;
         let mut next_frontier: VecDeque<String> = VecDeque::new();
         let mut hop_entities: Vec<String> = Vec::new();
 
@@ -224,52 +275,50 @@ async fn compute_blast_radius_by_hops(
         while let Some(entity) = current_frontier.pop_front() {
             // Query REVERSE dependencies (what CALLS this entity)
             // Blast radius = entities that depend on source
-            let escaped_entity = entity
-                .replace('\\', "\\\\")
-                .replace('"', "\\\"");
-
-            // v1.0.4: Build fuzzy matching query based on function name
-            let query = match extract_function_name_key(&entity) {
-                Some(func_name) => {
-                    let escaped_func_name = func_name
-                        .replace('\\', "\\\\")
-                        .replace('"', "\\\"");
-                    // Fuzzy match: exact key OR keys with matching function name
-                    format!(
-                        r#"
-                        ?[from_key] := *DependencyEdges{{from_key, to_key}},
-                            (to_key == "{}" or
-                             starts_with(to_key, "rust:fn:{}:") or
-                             starts_with(to_key, "rust:method:{}:")){}
-                        "#,
-                        escaped_entity, escaped_func_name, escaped_func_name, scope_join
-                    )
+            let mut incoming: Vec<&DependencyEdge> = snapshot.incoming_edges_to_target(&entity).collect();
+
+            // v1.0.4: Fuzzy match: exact key OR keys with matching function name
+            if let Some(func_name) = extract_function_name_key(&entity) {
+                for prefix in [format!("rust:fn:{}:", func_name), format!("rust:method:{}:", func_name)] {
+                    let fuzzy = snapshot.incoming_edges_with_prefix(&prefix).filter(|e| e.to_key.as_str() != entity);
+                    incoming.extend(fuzzy);
                 }
-                None => {
-                    // Fallback to exact match only
-                    format!(
-                        r#"
-                        ?[from_key] := *DependencyEdges{{from_key, to_key}},
-                            to_key == "{}"{}
-                        "#,
-                        escaped_entity, scope_join
-                    )
+            }
+
+            // Sorted and deduplicated, as the per-hop database query returned them
+            let mut callers: Vec<&str> = incoming
+                .into_iter()
+                .filter(|e| followed_types.contains(e.edge_type.as_str()))
+                .map(|e| e.from_key.as_str())
+                .filter(|from_key| snapshot.entity_within_scope_levels(from_key, &scope_l1, &scope_l2))
+                .collect::<BTreeSet<&str>>()
+                .into_iter()
+                .collect();
+            if beyond_depth {
+                callers.truncate(truncation.limits.max_fanout.unwrap_or(usize::MAX));
+            } else {
+                truncation.cap_node_neighbour_list(&entity, &mut callers);
+            }
+
+            for caller_key in callers {
+                if !visited.insert(caller_key.to_string()) {
+                    continue;
                 }
-            };
-
-            if let Ok(query_result) = storage.raw_query(&query).await {
-                for row in query_result.rows {
-                    if let Some(caller_key) = extract_string_value(&row[0]) {
-                        if !visited.contains(&caller_key) {
-                            visited.insert(caller_key.clone());
-                            hop_entities.push(caller_key.clone());
-                            next_frontier.push_back(caller_key);
-                        }
-                    }
+                // `visited` holds the source, which the budget does not count
+                if !beyond_depth && visited.len() - 1 > max_nodes {
+                    turned_away += 1;
+                    continue;
                 }
+                hop_entities.push(caller_key.to_string());
+                next_frontier.push_back(caller_key.to_string());
             }
         }
 
+        if beyond_depth {
+            truncation.record_beyond_depth_count(hop_entities.len());
+            break;
+        }
+
         // Only add hop if we found entities
         if !hop_entities.is_empty() {
             result.push(BlastRadiusHopDataItem {
@@ -288,16 +337,17 @@ async fn compute_blast_radius_by_hops(
         }
     }
 
+    truncation.record_nodes_omitted_count(turned_away);
     result
 }
 
-/// Extract string value from CozoDB DataValue
+/// Split a "L1||L2" scope into its trimmed levels (empty when absent)
 ///
-/// # 4-Word Name: extract_string_value_helper
-fn extract_string_value(value: &cozo::DataValue) -> Option<String> {
-    match value {
-        cozo::DataValue::Str(s) => Some(s.to_string()),
-        cozo::DataValue::Null => None,
-        _ => Some(format!("{:?}", value)),
-    }
+/// # 4-Word Name: parse_scope_level_pair
+fn parse_scope_level_pair(scope: &Option<String>) -> (String, String) {
+    let Some(scope) = scope else {
+        return (String::new(), String::new());
+    };
+    let mut parts = scope.split("||").map(|s| s.trim().to_string());
+    (parts.next().unwrap_or_default(), parts.next().unwrap_or_default())
 }
@@ -0,0 +1,274 @@
+//! Canonical symbol ID resolve endpoint handler
+//!
+//! # 4-Word Naming: canonical_symbol_id_resolve_handler
+//!
+//! Endpoint: GET /canonical-symbol-id-resolve?id=go://internal/auth#Service::Login
+//!       or: GET /canonical-symbol-id-resolve?symbol=crate::auth::login&language=rust
+//!
+//! v1.7.3: Resolves a language-neutral canonical symbol ID (or a raw
+//! qualified name normalized through the per-language separator adapter)
+//! to the ISGL1 keys of matching entities.
+
+use axum::{
+    extract::{Query, State},
+    http::StatusCode,
+    Json,
+    response::IntoResponse,
+};
+use serde::{Deserialize, Serialize};
+use std::path::Path;
+
+use parseltongue_core::canonical_symbol_id_normalizer::{
+    canonicalize_qualified_symbol_name, module_segments_from_file_path,
+    parse_canonical_symbol_identifier, CanonicalSymbolIdentifier,
+};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+use crate::scope_filter_utilities_module::parse_scope_build_filter_clause;
+
+/// Query parameters for canonical symbol resolve endpoint
+///
+/// # 4-Word Name: CanonicalSymbolResolveQueryParams
+#[derive(Debug, Deserialize)]
+pub struct CanonicalSymbolResolveQueryParams {
+    /// Canonical ID (`lang://module/path#Type::member`)
+    pub id: Option<String>,
+    /// Raw qualified name as written in source (requires `language`)
+    pub symbol: Option<String>,
+    /// Language tag for `symbol` (`rust`, `go`, `python`, ...)
+    pub language: Option<String>,
+    /// Filter entities by folder scope (e.g., "src||core" or "src")
+    pub scope: Option<String>,
+}
+
+/// Single resolved entity
+///
+/// # 4-Word Name: ResolvedCanonicalEntityItem
+#[derive(Debug, Serialize)]
+pub struct ResolvedCanonicalEntityItem {
+    pub key: String,
+    pub canonical_id: String,
+    pub file_path: String,
+    pub entity_type: String,
+    pub language: String,
+}
+
+/// Canonical resolve data payload
+///
+/// # 4-Word Name: CanonicalResolveDataPayload
+#[derive(Debug, Serialize)]
+pub struct CanonicalResolveDataPayload {
+    pub canonical_id: String,
+    pub total_count: usize,
+    pub entities: Vec<ResolvedCanonicalEntityItem>,
+}
+
+/// Canonical resolve response payload
+///
+/// # 4-Word Name: CanonicalResolveResponsePayload
+#[derive(Debug, Serialize)]
+pub struct CanonicalResolveResponsePayload {
+    pub success: bool,
+    pub endpoint: String,
+    pub data: CanonicalResolveDataPayload,
+    pub tokens: usize,
+}
+
+/// Canonical resolve error response
+///
+/// # 4-Word Name: CanonicalResolveErrorResponse
+#[derive(Debug, Serialize)]
+pub struct CanonicalResolveErrorResponse {
+    pub success: bool,
+    pub error: String,
+    pub endpoint: String,
+    pub tokens: usize,
+}
+
+/// Handle canonical symbol ID resolve request
+///
+/// # 4-Word Name: handle_canonical_symbol_id_resolve
+///
+/// # Contract
+/// - Precondition: `id`, or `symbol` + `language`, provided
+/// - Postcondition: Returns entities whose language, leaf name and module path match
+/// - Module match is a segment-wise suffix match in either direction, so Go
+///   import paths (`github.com/org/app/auth`) resolve to repo-relative dirs (`auth`)
+/// - Performance: <200ms for 1000 entities
+pub async fn handle_canonical_symbol_id_resolve(
+    State(state): State<SharedApplicationStateContainer>,
+    Query(params): Query<CanonicalSymbolResolveQueryParams>,
+) -> impl IntoResponse {
+    // Update last request timestamp
+    state.update_last_request_timestamp().await;
+
+    let requested = match (&params.id, &params.symbol, &params.language) {
+        (Some(id), _, _) => parse_canonical_symbol_identifier(id),
+        (None, Some(symbol), Some(language)) => canonicalize_qualified_symbol_name(language, symbol),
+        _ => None,
+    };
+
+    let Some(requested) = requested else {
+        return (
+            StatusCode::BAD_REQUEST,
+            Json(CanonicalResolveErrorResponse {
+                success: false,
+                error: "Provide id=lang://module/path#Type::member or symbol=X&language=Y".to_string(),
+                endpoint: "/canonical-symbol-id-resolve".to_string(),
+                tokens: 40,
+            }),
+        ).into_response();
+    };
+
+    let entities = resolve_canonical_entities_from_database(&state, &requested, &params.scope).await;
+    let total_count = entities.len();
+
+    // Estimate tokens (~25 per entity)
+    let tokens = 60 + (total_count * 25);
+
+    (
+        StatusCode::OK,
+        Json(CanonicalResolveResponsePayload {
+            success: true,
+            endpoint: "/canonical-symbol-id-resolve".to_string(),
+            data: CanonicalResolveDataPayload {
+                canonical_id: requested.to_string(),
+                total_count,
+                entities,
+            },
+            tokens,
+        }),
+    ).into_response()
+}
+
+/// Resolve canonical symbol ID against CodeGraph
+///
+/// # 4-Word Name: resolve_canonical_entities_from_database
+async fn resolve_canonical_entities_from_database(
+    state: &SharedApplicationStateContainer,
+    requested: &CanonicalSymbolIdentifier,
+    scope_filter: &Option<String>,
+) -> Vec<ResolvedCanonicalEntityItem> {
+    // Clone Arc, release lock, then await
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        match db_guard.as_ref() {
+            Some(s) => s.clone(),
+            None => return Vec::new(),
+        }
+    }; // Lock released here
+
+    let scope_clause = parse_scope_build_filter_clause(scope_filter);
+    let query = format!(
+        "?[key, file_path, entity_type, language, code] := *CodeGraph{{ISGL1_key: key, file_path, entity_type, language, Current_Code: code, root_subfolder_L1, root_subfolder_L2}}{}",
+        scope_clause
+    );
+
+    let Ok(named_rows) = storage.raw_query(&query).await else {
+        return Vec::new();
+    };
+
+    let Some(leaf_name) = requested.leaf_symbol_name_str() else {
+        return Vec::new();
+    };
+
+    let mut entities: Vec<ResolvedCanonicalEntityItem> = named_rows.rows.iter().filter_map(|row| {
+        let key = extract_string_value_from_row(row, 0)?;
+        let file_path = extract_string_value_from_row(row, 1)?;
+        let entity_type = extract_string_value_from_row(row, 2)?;
+        let language = extract_string_value_from_row(row, 3)?;
+
+        if language.to_lowercase() != requested.language {
+            return None;
+        }
+        let name = key.split(':').nth(2)?;
+        if name != leaf_name {
+            return None;
+        }
+
+        let code = extract_string_value_from_row(row, 4);
+        let candidate = canonical_id_from_entity_columns(&key, &file_path, &entity_type, code.as_deref());
+
+        let module_matches = candidate.module_path_ends_with(requested)
+            || requested.module_path_ends_with(&candidate);
+        // Type qualifier only checked when the candidate has one (Go methods)
+        let symbol_matches = candidate.symbol_segments.len() < 2
+            || requested.symbol_segments.len() < 2
+            || candidate.symbol_segments.ends_with(&requested.symbol_segments[requested.symbol_segments.len() - 2..]);
+        if !(module_matches && symbol_matches) {
+            return None;
+        }
+
+        Some(ResolvedCanonicalEntityItem {
+            key,
+            canonical_id: candidate.to_string(),
+            file_path,
+            entity_type,
+            language,
+        })
+    }).collect();
+
+    entities.sort_by(|a, b| a.key.cmp(&b.key));
+    entities
+}
+
+/// Canonical ID of a stored entity from its CodeGraph columns
+///
+/// # 4-Word Name: canonical_id_from_entity_columns
+///
+/// # Contract
+/// - Language: ISGL1 key prefix; module: `module_segments_from_file_path`
+/// - Symbol: `[Receiver, name]` for Go methods (receiver read from the
+///   method source), `[name]` otherwise
+pub(crate) fn canonical_id_from_entity_columns(
+    key: &str,
+    file_path: &str,
+    entity_type: &str,
+    code: Option<&str>,
+) -> CanonicalSymbolIdentifier {
+    let language = key.split(':').next().unwrap_or_default().to_lowercase();
+    let name = key.split(':').nth(2).unwrap_or_default().to_string();
+    let mut symbol_segments = Vec::new();
+    if language == "go" && entity_type.to_lowercase() == "method" {
+        if let Some(receiver) = code.and_then(extract_go_receiver_type_name) {
+            symbol_segments.push(receiver);
+        }
+    }
+    symbol_segments.push(name);
+    CanonicalSymbolIdentifier {
+        module_segments: module_segments_from_file_path(&language, Path::new(file_path)),
+        language,
+        symbol_segments,
+    }
+}
+
+/// Extract Go receiver type from method source (`func (s *Server[T]) Close()` → `Server`)
+///
+/// # 4-Word Name: extract_go_receiver_type_name
+fn extract_go_receiver_type_name(code: &str) -> Option<String> {
+    let after_func = code.trim_start().strip_prefix("func")?.trim_start();
+    let receiver = after_func.strip_prefix('(')?;
+    let receiver = receiver[..receiver.find(')')?].trim();
+    // Named receiver `s *T` vs unnamed `*T`; generics may contain spaces (`Map[K, V]`)
+    let type_text = match receiver.find(char::is_whitespace) {
+        Some(pos) if !receiver[..pos].contains('[') => receiver[pos..].trim(),
+        _ => receiver,
+    };
+    let type_text = type_text.trim_start_matches('*');
+    let type_name = type_text.split('[').next()?;
+    if type_name.is_empty() {
+        None
+    } else {
+        Some(type_name.to_string())
+    }
+}
+
+/// Extract string column from a CozoDB row
+///
+/// # 4-Word Name: extract_string_value_from_row
+fn extract_string_value_from_row(row: &[cozo::DataValue], index: usize) -> Option<String> {
+    match row.get(index)? {
+        cozo::DataValue::Str(s) => Some(s.to_string()),
+        _ => None,
+    }
+}
@@ -15,6 +15,7 @@ use axum::{
 };
 use serde::{Deserialize, Serialize};
 
+use crate::http_endpoint_handler_modules::canonical_symbol_id_resolve_handler::canonical_id_from_entity_columns;
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
 use crate::scope_filter_utilities_module::parse_scope_build_filter_clause;
 
@@ -35,6 +36,8 @@ pub struct EntityDetailQueryParams {
 #[derive(Debug, Serialize)]
 pub struct EntityDetailDataPayload {
     pub key: String,
+    /// v1.7.3: Language-neutral address (`go://internal/auth#Service::Login`)
+    pub canonical_id: String,
     pub file_path: String,
     pub entity_type: String,
     pub entity_class: String,
@@ -101,6 +104,13 @@ pub async fn handle_code_entity_detail_view(
     // Query for entity details
     if let Some(entity_details) = fetch_entity_details_from_database(&state, &entity_key, &params.scope).await {
         let tokens = 100 + entity_details.code.len();
+        let canonical_id = canonical_id_from_entity_columns(
+            &entity_details.key,
+            &entity_details.file_path,
+            &entity_details.entity_type,
+            Some(&entity_details.code),
+        )
+        .to_string();
         (
             StatusCode::OK,
             Json(EntityDetailResponsePayload {
@@ -108,6 +118,7 @@ pub async fn handle_code_entity_detail_view(
                 endpoint: "/code-entity-detail-view".to_string(),
                 data: EntityDetailDataPayload {
                     key: entity_details.key,
+                    canonical_id,
                     file_path: entity_details.file_path,
                     entity_type: entity_details.entity_type,
                     entity_class: entity_details.entity_class,
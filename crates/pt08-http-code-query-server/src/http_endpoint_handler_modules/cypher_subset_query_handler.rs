@@ -0,0 +1,120 @@
+//! Cypher subset query endpoint handler
+//!
+//! # 4-Word Naming: cypher_subset_query_handler
+//!
+//! Endpoint: GET /cypher-subset-query-execute?query={cypher}
+//!
+//! v1.7.3: Runs read-only Cypher (`MATCH ... WHERE ... RETURN`) against the
+//! graph so existing Neo4j snippets work. Labels are node kinds
+//! (`Function`, `Method`, `Struct`, `External`), relationship types are edge
+//! types (`CALLS`, `IMPLEMENTS`, ...). Writing clauses and syntax outside the
+//! subset come back as 400 with the parser's message.
+
+use axum::{
+    extract::{Query, State},
+    http::StatusCode,
+    Json,
+    response::IntoResponse,
+};
+use serde::{Deserialize, Serialize};
+
+use parseltongue_core::cypher_subset_query_evaluator::{execute_cypher_subset_query, CypherQueryResultPayload};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Query parameters for Cypher execution
+///
+/// # 4-Word Name: CypherSubsetQueryParams
+#[derive(Debug, Deserialize)]
+pub struct CypherSubsetQueryParams {
+    /// Cypher query text
+    pub query: String,
+}
+
+/// Cypher query response payload
+///
+/// # 4-Word Name: CypherSubsetResponsePayload
+#[derive(Debug, Serialize)]
+pub struct CypherSubsetResponsePayload {
+    pub success: bool,
+    pub endpoint: String,
+    pub data: CypherQueryResultPayload,
+    pub tokens: usize,
+}
+
+/// Cypher query error response
+///
+/// # 4-Word Name: CypherSubsetErrorResponse
+#[derive(Debug, Serialize)]
+pub struct CypherSubsetErrorResponse {
+    pub success: bool,
+    pub endpoint: String,
+    pub error: String,
+}
+
+/// Handle Cypher subset query request
+///
+/// # 4-Word Name: handle_cypher_subset_query_execute
+///
+/// # Contract
+/// - Precondition: `query` is read-only Cypher within the supported subset
+/// - Postcondition: `columns` plus `rows`, paged by the query's SKIP/LIMIT
+/// - Error Handling: 400 on unsupported syntax or writes, 500 if database unavailable
+pub async fn handle_cypher_subset_query_execute(
+    State(state): State<SharedApplicationStateContainer>,
+    Query(params): Query<CypherSubsetQueryParams>,
+) -> impl IntoResponse {
+    // Update last request timestamp
+    state.update_last_request_timestamp().await;
+
+    let error_response = |status: StatusCode, error: String| {
+        (
+            status,
+            Json(CypherSubsetErrorResponse {
+                success: false,
+                endpoint: "/cypher-subset-query-execute".to_string(),
+                error,
+            }),
+        )
+            .into_response()
+    };
+
+    // Clone Arc inside RwLock scope, release lock
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        match db_guard.as_ref() {
+            Some(s) => s.clone(),
+            None => {
+                return error_response(StatusCode::INTERNAL_SERVER_ERROR, "Database not connected".to_string())
+            }
+        }
+    }; // Lock released here
+
+    let entities = match storage.get_all_entities_with_metadata().await {
+        Ok(entities) => entities,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query entities: {}", e)),
+    };
+    let edges = match storage.get_all_dependencies().await {
+        Ok(edges) => edges,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query edges: {}", e)),
+    };
+
+    let result = match execute_cypher_subset_query(&params.query, &entities, &edges) {
+        Ok(result) => result,
+        Err(e) => return error_response(StatusCode::BAD_REQUEST, e.to_string()),
+    };
+
+    // Estimate tokens (~10 per cell)
+    let tokens = 60 + (result.rows.len() * result.columns.len().max(1) * 10);
+
+    (
+        StatusCode::OK,
+        Json(CypherSubsetResponsePayload {
+            success: true,
+            endpoint: "/cypher-subset-query-execute".to_string(),
+            data: result,
+            tokens,
+        }),
+    )
+        .into_response()
+}
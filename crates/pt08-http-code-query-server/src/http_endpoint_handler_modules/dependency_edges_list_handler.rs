@@ -3,8 +3,14 @@
 //! # 4-Word Naming: dependency_edges_list_handler
 //!
 //! Endpoint: GET /dependency-edges-list-all?limit=N&offset=M
+//! Endpoint: GET /dependency-edges-list-all?limit=N&cursor=C
 //!
 //! Returns all dependency edges from the graph database with pagination.
+//!
+//! v1.7.3: Edges are ordered by (from_key, to_key, edge_type). Every page
+//! carries `next_cursor` (null on the last page); passing it back as
+//! `cursor` resumes after the page's last edge, so pages stay consistent
+//! while a reindex inserts or deletes edges, unlike offsets.
 
 use axum::{
     extract::{Query, State},
@@ -25,9 +31,11 @@ pub struct DependencyEdgesQueryParams {
     /// Maximum edges to return (default: 100)
     #[serde(default = "default_limit")]
     pub limit: usize,
-    /// Offset for pagination (default: 0)
+    /// Offset for pagination (default: 0); ignored when `cursor` is set
     #[serde(default)]
     pub offset: usize,
+    /// Opaque `next_cursor` of the previous page
+    pub cursor: Option<String>,
     /// Filter by folder scope (e.g., "crates||parseltongue-core")
     pub scope: Option<String>,
 }
@@ -45,6 +53,9 @@ pub struct EdgeDataPayloadItem {
     pub to_key: String,
     pub edge_type: String,
     pub source_location: String,
+    /// Extra edge facts (e.g. generic `type_arguments`), omitted when absent
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub edge_metadata: Option<serde_json::Value>,
 }
 
 /// Edges list response data
@@ -56,6 +67,10 @@ pub struct DependencyEdgesDataPayload {
     pub returned_count: usize,
     pub limit: usize,
     pub offset: usize,
+    /// Cursor of the next page; None on the last page
+    pub next_cursor: Option<String>,
+    /// Graph revision the page was read at (see the `ETag` header)
+    pub graph_revision: u64,
     pub edges: Vec<EdgeDataPayloadItem>,
 }
 
@@ -70,6 +85,47 @@ pub struct DependencyEdgesResponsePayload {
     pub tokens: usize,
 }
 
+/// Edges list error response
+///
+/// # 4-Word Name: DependencyEdgesErrorResponse
+#[derive(Debug, Serialize)]
+pub struct DependencyEdgesErrorResponse {
+    pub success: bool,
+    pub error: String,
+    pub endpoint: String,
+    pub tokens: usize,
+}
+
+/// Position after one edge in (from_key, to_key, edge_type) order
+///
+/// # 4-Word Name: EdgePageCursorPosition
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct EdgePageCursorPosition {
+    pub from_key: String,
+    pub to_key: String,
+    pub edge_type: String,
+}
+
+/// Encode a cursor as an opaque URL-safe token
+///
+/// # 4-Word Name: encode_edge_page_cursor
+pub fn encode_edge_page_cursor(position: &EdgePageCursorPosition) -> String {
+    hex::encode(format!("{}\n{}\n{}", position.from_key, position.to_key, position.edge_type))
+}
+
+/// Decode a cursor token; None when malformed
+///
+/// # 4-Word Name: decode_edge_page_cursor
+pub fn decode_edge_page_cursor(cursor: &str) -> Option<EdgePageCursorPosition> {
+    let text = String::from_utf8(hex::decode(cursor).ok()?).ok()?;
+    let mut parts = text.splitn(3, '\n');
+    Some(EdgePageCursorPosition {
+        from_key: parts.next()?.to_string(),
+        to_key: parts.next()?.to_string(),
+        edge_type: parts.next()?.to_string(),
+    })
+}
+
 /// Handle dependency edges list request
 ///
 /// # 4-Word Name: handle_dependency_edges_list_all
@@ -82,7 +138,9 @@ pub struct DependencyEdgesResponsePayload {
 ///
 /// # URL Pattern
 /// - Endpoint: GET /dependency-edges-list-all?limit=N&offset=M
+/// - Endpoint: GET /dependency-edges-list-all?limit=N&cursor=C
 /// - Default limit: 100, offset: 0
+/// - Malformed cursor: 400
 pub async fn handle_dependency_edges_list_all(
     State(state): State<SharedApplicationStateContainer>,
     Query(params): Query<DependencyEdgesQueryParams>,
@@ -90,8 +148,29 @@ pub async fn handle_dependency_edges_list_all(
     // Update last request timestamp
     state.update_last_request_timestamp().await;
 
+    let cursor = match params.cursor.as_deref().filter(|c| !c.is_empty()) {
+        None => None,
+        Some(token) => match decode_edge_page_cursor(token) {
+            Some(position) => Some(position),
+            None => {
+                return (
+                    StatusCode::BAD_REQUEST,
+                    Json(DependencyEdgesErrorResponse {
+                        success: false,
+                        error: "Malformed cursor; pass back next_cursor unchanged".to_string(),
+                        endpoint: "/dependency-edges-list-all".to_string(),
+                        tokens: 40,
+                    }),
+                ).into_response();
+            }
+        },
+    };
+    let offset = if cursor.is_some() { 0 } else { params.offset };
+    let graph_revision = state.current_graph_revision_value();
+
     // Query all edges with pagination
-    let (edges, total_count) = query_dependency_edges_paginated(&state, params.limit, params.offset, &params.scope).await;
+    let (edges, total_count, next_cursor) =
+        query_dependency_edges_paginated(&state, params.limit, offset, cursor.as_ref(), &params.scope).await;
     let returned_count = edges.len();
 
     // Estimate tokens (~40 per edge + overhead)
@@ -106,7 +185,9 @@ pub async fn handle_dependency_edges_list_all(
                 total_count,
                 returned_count,
                 limit: params.limit,
-                offset: params.offset,
+                offset,
+                next_cursor,
+                graph_revision,
                 edges,
             },
             tokens,
@@ -117,18 +198,21 @@ pub async fn handle_dependency_edges_list_all(
 /// Query dependency edges with pagination
 ///
 /// # 4-Word Name: query_dependency_edges_paginated
+///
+/// Fetches one edge more than `limit` to learn whether a next page exists.
 async fn query_dependency_edges_paginated(
     state: &SharedApplicationStateContainer,
     limit: usize,
     offset: usize,
+    cursor: Option<&EdgePageCursorPosition>,
     scope_filter: &Option<String>,
-) -> (Vec<EdgeDataPayloadItem>, usize) {
+) -> (Vec<EdgeDataPayloadItem>, usize, Option<String>) {
     // Clone Arc, release lock, then await
     let storage = {
         let db_guard = state.database_storage_connection_arc.read().await;
         match db_guard.as_ref() {
             Some(s) => s.clone(),
-            None => return (Vec::new(), 0),
+            None => return (Vec::new(), 0, None),
         }
     }; // Lock released here
 
@@ -156,33 +240,47 @@ async fn query_dependency_edges_paginated(
             Err(_) => 0,
     };
 
+    // Resume strictly after the cursor edge, in key order
+    let cursor_clause = cursor
+        .map(|position| {
+            let from = escape_datalog_string_literal(&position.from_key);
+            let to = escape_datalog_string_literal(&position.to_key);
+            let edge = escape_datalog_string_literal(&position.edge_type);
+            format!(
+                ",\n                (from_key > {from} || (from_key == {from} && (to_key > {to} || (to_key == {to} && edge_type > {edge}))))"
+            )
+        })
+        .unwrap_or_default();
+
     // Build edges query with scope filtering
     let query = if scope_clause.is_empty() {
         format!(
             r#"
-            ?[from_key, to_key, edge_type, source_location] :=
-                *DependencyEdges{{from_key, to_key, edge_type, source_location}}
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] :=
+                *DependencyEdges{{from_key, to_key, edge_type, source_location, edge_metadata}}{}
+            :order from_key, to_key, edge_type
             :limit {}
             :offset {}
             "#,
-            limit, offset
+            cursor_clause, limit.saturating_add(1), offset
         )
     } else {
         format!(
             r#"
-            ?[from_key, to_key, edge_type, source_location] :=
-                *DependencyEdges{{from_key, to_key, edge_type, source_location}},
-                *CodeGraph{{ISGL1_key: from_key, root_subfolder_L1, root_subfolder_L2}}{}
+            ?[from_key, to_key, edge_type, source_location, edge_metadata] :=
+                *DependencyEdges{{from_key, to_key, edge_type, source_location, edge_metadata}},
+                *CodeGraph{{ISGL1_key: from_key, root_subfolder_L1, root_subfolder_L2}}{}{}
+            :order from_key, to_key, edge_type
             :limit {}
             :offset {}
             "#,
-            scope_clause, limit, offset
+            scope_clause, cursor_clause, limit.saturating_add(1), offset
         )
     };
 
     match storage.raw_query(&query).await {
         Ok(result) => {
-            let edges: Vec<EdgeDataPayloadItem> = result.rows.iter()
+            let mut edges: Vec<EdgeDataPayloadItem> = result.rows.iter()
                 .filter_map(|row| {
                     if row.len() >= 4 {
                         Some(EdgeDataPayloadItem {
@@ -190,18 +288,40 @@ async fn query_dependency_edges_paginated(
                             to_key: extract_string_value(&row[1]).unwrap_or_default(),
                             edge_type: extract_string_value(&row[2]).unwrap_or_default(),
                             source_location: extract_string_value(&row[3]).unwrap_or_default(),
+                            edge_metadata: row.get(4)
+                                .and_then(extract_string_value)
+                                .and_then(|json| serde_json::from_str(&json).ok()),
                         })
                     } else {
                         None
                     }
                 })
                 .collect();
-            (edges, total_count)
+            let next_cursor = if edges.len() > limit {
+                edges.truncate(limit);
+                edges.last().map(|last| {
+                    encode_edge_page_cursor(&EdgePageCursorPosition {
+                        from_key: last.from_key.clone(),
+                        to_key: last.to_key.clone(),
+                        edge_type: last.edge_type.clone(),
+                    })
+                })
+            } else {
+                None
+            };
+            (edges, total_count, next_cursor)
         }
-        Err(_) => (Vec::new(), 0),
+        Err(_) => (Vec::new(), 0, None),
     }
 }
 
+/// Quote a string for a Datalog query
+///
+/// # 4-Word Name: escape_datalog_string_literal
+fn escape_datalog_string_literal(value: &str) -> String {
+    format!("\"{}\"", value.replace('\\', "\\\\").replace('"', "\\\""))
+}
+
 /// Extract string value from CozoDB DataValue
 ///
 /// # 4-Word Name: extract_string_value_helper
@@ -6,6 +6,8 @@
 //!
 //! Returns all entities that the target entity calls/depends on.
 //! This is the inverse of reverse_callers - shows what THIS entity uses.
+//!
+//! v1.7.3: `max_fanout` / `max_nodes` guard the list as on reverse_callers.
 
 use axum::{
     extract::{Query, State},
@@ -15,6 +17,9 @@ use axum::{
 };
 use serde::{Deserialize, Serialize};
 
+use parseltongue_core::traversal_expansion_limit_guards::TraversalTruncationMarkerReport;
+
+use crate::http_endpoint_handler_modules::reverse_callers_query_graph_handler::limit_neighbor_edge_entries;
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
 use crate::scope_filter_utilities_module::parse_scope_build_filter_clause;
 
@@ -27,6 +32,10 @@ pub struct ForwardCalleesQueryParams {
     pub entity: String,
     /// Filter by folder scope (e.g., "crates||parseltongue-core")
     pub scope: Option<String>,
+    /// Distinct callees listed (default: all)
+    pub max_fanout: Option<usize>,
+    /// Callee edges listed (default: all)
+    pub max_nodes: Option<usize>,
 }
 
 /// Callee edge data
@@ -45,8 +54,11 @@ pub struct CalleeEdgeDataPayload {
 /// # 4-Word Name: ForwardCalleesDataPayload
 #[derive(Debug, Serialize)]
 pub struct ForwardCalleesDataPayload {
+    /// Callee edges found, before `max_fanout` / `max_nodes`
     pub total_count: usize,
     pub callees: Vec<CalleeEdgeDataPayload>,
+    /// v1.7.3: What the fan-out and node guards left out
+    pub truncation: TraversalTruncationMarkerReport,
 }
 
 /// Forward callees response for success case
@@ -109,11 +121,18 @@ pub async fn handle_forward_callees_query_graph(
     };
 
     // Query forward callees using direct CozoDB query
-    let callees = query_forward_callees_direct_method(&state, &entity_key, &params.scope).await;
+    let mut callees = query_forward_callees_direct_method(&state, &entity_key, &params.scope).await;
     let total_count = callees.len();
+    let truncation = limit_neighbor_edge_entries(
+        &entity_key,
+        &mut callees,
+        |callee| callee.to_key.as_str(),
+        params.max_fanout,
+        params.max_nodes,
+    );
 
     // Estimate tokens (~50 per callee + entity key)
-    let tokens = 80 + (total_count * 50) + entity_key.len();
+    let tokens = 80 + (callees.len() * 50) + entity_key.len();
 
     if callees.is_empty() {
         return (
@@ -135,6 +154,7 @@ pub async fn handle_forward_callees_query_graph(
             data: ForwardCalleesDataPayload {
                 total_count,
                 callees,
+                truncation,
             },
             tokens,
         }),
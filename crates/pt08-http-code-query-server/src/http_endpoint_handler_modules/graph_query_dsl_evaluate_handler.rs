@@ -0,0 +1,118 @@
+//! Graph query DSL evaluate endpoint handler
+//!
+//! # 4-Word Naming: graph_query_dsl_evaluate_handler
+//!
+//! Endpoint: GET /graph-query-dsl-evaluate?q={expression}
+//!
+//! v1.7.3: Evaluates the query language shared with `parseltongue q`, e.g.
+//! `callers(UserService.CreateUser) & in_package("api") depth 2`. Syntax
+//! errors come back as 400 with the parser's message.
+
+use axum::{
+    extract::{Query, State},
+    http::StatusCode,
+    Json,
+    response::IntoResponse,
+};
+use serde::{Deserialize, Serialize};
+
+use parseltongue_core::graph_query_dsl_evaluator::{evaluate_graph_query_text, GraphQueryResultPayload};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Query parameters for graph query evaluation
+///
+/// # 4-Word Name: GraphQueryDslQueryParams
+#[derive(Debug, Deserialize)]
+pub struct GraphQueryDslQueryParams {
+    /// Query expression
+    pub q: String,
+}
+
+/// Graph query response payload
+///
+/// # 4-Word Name: GraphQueryDslResponsePayload
+#[derive(Debug, Serialize)]
+pub struct GraphQueryDslResponsePayload {
+    pub success: bool,
+    pub endpoint: String,
+    pub data: GraphQueryResultPayload,
+    pub tokens: usize,
+}
+
+/// Graph query error response
+///
+/// # 4-Word Name: GraphQueryDslErrorResponse
+#[derive(Debug, Serialize)]
+pub struct GraphQueryDslErrorResponse {
+    pub success: bool,
+    pub endpoint: String,
+    pub error: String,
+}
+
+/// Handle graph query DSL evaluation request
+///
+/// # 4-Word Name: handle_graph_query_dsl_evaluate
+///
+/// # Contract
+/// - Precondition: `q` is a query expression (see graph_query_dsl_evaluator)
+/// - Postcondition: Matching entities sorted by key, cut at `limit`
+/// - Error Handling: 400 on syntax errors, 500 if database unavailable
+pub async fn handle_graph_query_dsl_evaluate(
+    State(state): State<SharedApplicationStateContainer>,
+    Query(params): Query<GraphQueryDslQueryParams>,
+) -> impl IntoResponse {
+    // Update last request timestamp
+    state.update_last_request_timestamp().await;
+
+    let error_response = |status: StatusCode, error: String| {
+        (
+            status,
+            Json(GraphQueryDslErrorResponse {
+                success: false,
+                endpoint: "/graph-query-dsl-evaluate".to_string(),
+                error,
+            }),
+        )
+            .into_response()
+    };
+
+    // Clone Arc inside RwLock scope, release lock
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        match db_guard.as_ref() {
+            Some(s) => s.clone(),
+            None => {
+                return error_response(StatusCode::INTERNAL_SERVER_ERROR, "Database not connected".to_string())
+            }
+        }
+    }; // Lock released here
+
+    let entities = match storage.get_all_entities_with_metadata().await {
+        Ok(entities) => entities,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query entities: {}", e)),
+    };
+    let edges = match storage.get_all_dependencies().await {
+        Ok(edges) => edges,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query edges: {}", e)),
+    };
+
+    let result = match evaluate_graph_query_text(&params.q, &entities, &edges) {
+        Ok(result) => result,
+        Err(e) => return error_response(StatusCode::BAD_REQUEST, e.to_string()),
+    };
+
+    // Estimate tokens (~25 per match)
+    let tokens = 60 + (result.matches.len() * 25);
+
+    (
+        StatusCode::OK,
+        Json(GraphQueryDslResponsePayload {
+            success: true,
+            endpoint: "/graph-query-dsl-evaluate".to_string(),
+            data: result,
+            tokens,
+        }),
+    )
+        .into_response()
+}
@@ -0,0 +1,153 @@
+//! Keyword search near seed endpoint handler
+//!
+//! # 4-Word Naming: keyword_search_near_seed_handler
+//!
+//! Endpoint: GET /keyword-search-near-seed?q={words}&near={key}&limit=N
+//!
+//! v1.7.3: BM25 over identifiers, doc comments and string literals
+//! (no embeddings needed), blended with graph distance from `near` when
+//! given — "rate limiting" near a handler. Same scoring as
+//! `parseltongue search --mode keyword`.
+
+use axum::{
+    extract::{Query, State},
+    http::StatusCode,
+    Json,
+    response::IntoResponse,
+};
+use serde::{Deserialize, Serialize};
+
+use parseltongue_core::bm25_hybrid_keyword_retriever::{
+    search_keywords_near_seed, HybridRetrievalHitEntry, HybridRetrievalOptionsSpec, KeywordSearchIndexStore,
+    DEFAULT_PROXIMITY_BLEND_WEIGHT,
+};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Query parameters for keyword search
+///
+/// # 4-Word Name: KeywordSearchQueryParams
+#[derive(Debug, Deserialize)]
+pub struct KeywordSearchQueryParams {
+    /// Search words
+    pub q: String,
+    /// Seed entity key whose neighbourhood is preferred
+    pub near: Option<String>,
+    /// Share of the score from graph distance (default 0.4)
+    pub proximity_weight: Option<f64>,
+    /// Maximum results (default 10)
+    pub limit: Option<usize>,
+}
+
+/// Keyword search result data
+///
+/// # 4-Word Name: KeywordSearchResultData
+#[derive(Debug, Serialize)]
+pub struct KeywordSearchResultData {
+    pub query: String,
+    pub near: Option<String>,
+    pub indexed_symbols: usize,
+    pub results: Vec<HybridRetrievalHitEntry>,
+}
+
+/// Keyword search response payload
+///
+/// # 4-Word Name: KeywordSearchResponsePayload
+#[derive(Debug, Serialize)]
+pub struct KeywordSearchResponsePayload {
+    pub success: bool,
+    pub endpoint: String,
+    pub data: KeywordSearchResultData,
+    pub tokens: usize,
+}
+
+/// Keyword search error response
+///
+/// # 4-Word Name: KeywordSearchErrorResponse
+#[derive(Debug, Serialize)]
+pub struct KeywordSearchErrorResponse {
+    pub success: bool,
+    pub endpoint: String,
+    pub error: String,
+}
+
+/// Handle keyword search near seed request
+///
+/// # 4-Word Name: handle_keyword_search_near_seed
+///
+/// # Contract
+/// - Precondition: `q` has at least one word; `near`, when given, is an entity key
+/// - Postcondition: Matching symbols by blended score, at most `limit`
+/// - Error Handling: 404 for an unknown `near`, 500 if database unavailable
+pub async fn handle_keyword_search_near_seed(
+    State(state): State<SharedApplicationStateContainer>,
+    Query(params): Query<KeywordSearchQueryParams>,
+) -> impl IntoResponse {
+    // Update last request timestamp
+    state.update_last_request_timestamp().await;
+
+    let error_response = |status: StatusCode, error: String| {
+        (
+            status,
+            Json(KeywordSearchErrorResponse {
+                success: false,
+                endpoint: "/keyword-search-near-seed".to_string(),
+                error,
+            }),
+        )
+            .into_response()
+    };
+
+    // Clone Arc inside RwLock scope, release lock
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        match db_guard.as_ref() {
+            Some(s) => s.clone(),
+            None => {
+                return error_response(StatusCode::INTERNAL_SERVER_ERROR, "Database not connected".to_string())
+            }
+        }
+    }; // Lock released here
+
+    let entities = match storage.get_all_entities().await {
+        Ok(entities) => entities,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query entities: {}", e)),
+    };
+    if let Some(near) = &params.near {
+        if !entities.iter().any(|e| &e.isgl1_key == near) {
+            return error_response(StatusCode::NOT_FOUND, format!("Entity not found: {}", near));
+        }
+    }
+    let edges = match storage.get_all_dependencies().await {
+        Ok(edges) => edges,
+        Err(e) => return error_response(StatusCode::INTERNAL_SERVER_ERROR, format!("Failed to query edges: {}", e)),
+    };
+
+    let index = KeywordSearchIndexStore::build_from_code_entities(&entities);
+    let options = HybridRetrievalOptionsSpec {
+        seed_key: params.near.clone(),
+        proximity_weight: params.proximity_weight.unwrap_or(DEFAULT_PROXIMITY_BLEND_WEIGHT),
+        limit: params.limit.unwrap_or(10),
+        ..HybridRetrievalOptionsSpec::default()
+    };
+    let results = search_keywords_near_seed(&index, &params.q, &edges, &options);
+
+    // Estimate tokens (~30 per hit)
+    let tokens = 60 + (results.len() * 30);
+
+    (
+        StatusCode::OK,
+        Json(KeywordSearchResponsePayload {
+            success: true,
+            endpoint: "/keyword-search-near-seed".to_string(),
+            data: KeywordSearchResultData {
+                query: params.q,
+                near: params.near,
+                indexed_symbols: index.indexed_document_count_value(),
+                results,
+            },
+            tokens,
+        }),
+    )
+        .into_response()
+}
@@ -34,3 +34,15 @@ pub mod ingestion_coverage_folder_handler;
 // v1.6.5: Diagnostics and folder discovery
 pub mod ingestion_diagnostics_coverage_handler;
 pub mod folder_structure_discovery_handler;
+// v1.7.3: Canonical cross-language symbol IDs
+pub mod canonical_symbol_id_resolve_handler;
+// v1.7.3: Monorepo workspace modules
+pub mod workspace_modules_list_handler;
+// v1.7.3: Graph query language
+pub mod graph_query_dsl_evaluate_handler;
+// v1.7.3: Read-only Cypher subset
+pub mod cypher_subset_query_handler;
+// v1.7.3: BM25 keyword search near a seed
+pub mod keyword_search_near_seed_handler;
+// v1.7.3: Prometheus metrics
+pub mod prometheus_metrics_exposition_handler;
@@ -0,0 +1,57 @@
+//! Prometheus metrics endpoint handler
+//!
+//! # 4-Word Naming: prometheus_metrics_exposition_handler
+//!
+//! Endpoint: GET /metrics
+//!
+//! v1.7.3: Graph size is counted at scrape time, so the gauges follow
+//! incremental reindexes; request metrics come from
+//! `daemon_query_telemetry_recorder`.
+
+use axum::{
+    extract::State,
+    http::header,
+    response::IntoResponse,
+};
+
+use crate::daemon_query_telemetry_recorder::GraphSizeGaugeSnapshot;
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Content type of the Prometheus text format
+const PROMETHEUS_TEXT_CONTENT_TYPE: &str = "text/plain; version=0.0.4; charset=utf-8";
+
+/// Handle a Prometheus scrape
+///
+/// # 4-Word Name: handle_prometheus_metrics_exposition_text
+///
+/// # Contract
+/// - Postcondition: 200 with text format 0.0.4; graph gauges are 0 when no
+///   database is connected or a count query fails
+/// - Side effect: does not touch the idle-timeout timestamp, so a scraper
+///   never keeps an idle server alive
+pub async fn handle_prometheus_metrics_exposition_text(
+    State(state): State<SharedApplicationStateContainer>,
+) -> impl IntoResponse {
+    // Clone Arc, release lock, then await
+    let storage = state.database_storage_connection_arc.read().await.clone();
+    let (entities, edges) = match storage {
+        Some(storage) => (
+            storage.count_all_entities_total().await.unwrap_or(0),
+            storage.count_all_edges_total().await.unwrap_or(0),
+        ),
+        None => (0, 0),
+    };
+    let snapshot = GraphSizeGaugeSnapshot {
+        entities,
+        edges,
+        revision: state.current_graph_revision_value(),
+        uptime_seconds: chrono::Utc::now()
+            .signed_duration_since(state.server_start_timestamp_utc)
+            .num_seconds(),
+    };
+
+    (
+        [(header::CONTENT_TYPE, PROMETHEUS_TEXT_CONTENT_TYPE)],
+        state.query_telemetry_registry_arc.render_prometheus_exposition_text(snapshot),
+    )
+}
@@ -7,6 +7,10 @@
 //! v1.0.4 FIX: Added fuzzy key matching for stdlib function calls.
 //! Edge targets may use simplified keys like `rust:fn:new:unknown:0-0`
 //! but we query with full entity keys like `rust:method:new:__path:38-54`.
+//!
+//! v1.7.3: `max_fanout` (distinct callers) and `max_nodes` (caller edges)
+//! guard the list like the CLI's `--max-fanout` / `--max-nodes`;
+//! `truncation` in the response says what was cut.
 
 use axum::{
     extract::{Query, State},
@@ -15,6 +19,11 @@ use axum::{
     response::IntoResponse,
 };
 use serde::{Deserialize, Serialize};
+use std::collections::BTreeSet;
+
+use parseltongue_core::traversal_expansion_limit_guards::{
+    TraversalExpansionLimitSpec, TraversalTruncationMarkerReport,
+};
 
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
 use crate::scope_filter_utilities_module::parse_scope_build_filter_clause;
@@ -47,6 +56,10 @@ pub struct ReverseCallersQueryParams {
     pub entity: String,
     /// Filter by folder scope (e.g., "crates||parseltongue-core")
     pub scope: Option<String>,
+    /// Distinct callers listed (default: all)
+    pub max_fanout: Option<usize>,
+    /// Caller edges listed (default: all)
+    pub max_nodes: Option<usize>,
 }
 
 /// Caller edge data
@@ -65,8 +78,11 @@ pub struct CallerEdgeDataPayload {
 /// # 4-Word Name: ReverseCallersDataPayload
 #[derive(Debug, Serialize)]
 pub struct ReverseCallersDataPayload {
+    /// Caller edges found, before `max_fanout` / `max_nodes`
     pub total_count: usize,
     pub callers: Vec<CallerEdgeDataPayload>,
+    /// v1.7.3: What the fan-out and node guards left out
+    pub truncation: TraversalTruncationMarkerReport,
 }
 
 /// Reverse callers response for success case
@@ -129,11 +145,18 @@ pub async fn handle_reverse_callers_query_graph(
     };
 
     // Use direct query method compatible with test database setup
-    let callers = query_reverse_callers_direct_method(&state, &entity_key, &params.scope).await;
+    let mut callers = query_reverse_callers_direct_method(&state, &entity_key, &params.scope).await;
     let total_count = callers.len();
+    let truncation = limit_neighbor_edge_entries(
+        &entity_key,
+        &mut callers,
+        |caller| caller.from_key.as_str(),
+        params.max_fanout,
+        params.max_nodes,
+    );
 
     // Estimate tokens (~50 per caller + entity key)
-    let tokens = 80 + (total_count * 50) + entity_key.len();
+    let tokens = 80 + (callers.len() * 50) + entity_key.len();
 
     if callers.is_empty() {
         return (
@@ -155,12 +178,43 @@ pub async fn handle_reverse_callers_query_graph(
             data: ReverseCallersDataPayload {
                 total_count,
                 callers,
+                truncation,
             },
             tokens,
         }),
     ).into_response()
 }
 
+/// Apply the one-hop fan-out and node guards to a neighbour edge list
+///
+/// # 4-Word Name: limit_neighbor_edge_entries
+///
+/// # Contract
+/// - Postcondition: edges of the first `max_fanout` distinct neighbours (key
+///   order) are kept, then the first `max_nodes` of those; edge order is
+///   preserved and the returned report records both cuts
+/// - Shared by the callers and callees endpoints
+pub fn limit_neighbor_edge_entries<T>(
+    entity_key: &str,
+    edges: &mut Vec<T>,
+    neighbor_key: impl Fn(&T) -> &str,
+    max_fanout: Option<usize>,
+    max_nodes: Option<usize>,
+) -> TraversalTruncationMarkerReport {
+    let limits = TraversalExpansionLimitSpec { max_depth: 1, max_fanout, max_nodes };
+    let mut truncation = TraversalTruncationMarkerReport::create_empty_marker_report(limits);
+    let mut neighbors: Vec<String> =
+        edges.iter().map(|e| neighbor_key(e).to_string()).collect::<BTreeSet<String>>().into_iter().collect();
+    let before = neighbors.len();
+    truncation.cap_node_neighbour_list(entity_key, &mut neighbors);
+    if neighbors.len() < before {
+        let kept: BTreeSet<&str> = neighbors.iter().map(String::as_str).collect();
+        edges.retain(|e| kept.contains(neighbor_key(e)));
+    }
+    truncation.truncate_entries_to_node_limit(edges);
+    truncation
+}
+
 /// Query reverse callers using direct CozoDB queries with fuzzy matching
 ///
 /// # 4-Word Name: query_reverse_callers_direct_method
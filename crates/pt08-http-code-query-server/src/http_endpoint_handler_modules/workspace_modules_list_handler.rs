@@ -0,0 +1,195 @@
+//! Workspace modules list endpoint handler
+//!
+//! # 4-Word Naming: workspace_modules_list_handler
+//!
+//! Endpoint: GET /workspace-modules-list-all
+//!
+//! v1.7.3: Lists the go.mod / Cargo.toml / package.json modules of a
+//! monorepo with their entity counts. Modules rooted at depth 1 or 2 carry a
+//! `scope` value usable as `?scope=` on the graph endpoints to query one
+//! service; omitting `scope` spans the whole monorepo.
+
+use axum::{
+    extract::State,
+    http::StatusCode,
+    Json,
+    response::IntoResponse,
+};
+use serde::Serialize;
+use std::collections::BTreeMap;
+
+use parseltongue_core::workspace_module_root_detector::{
+    WORKSPACE_MODULE_KIND_METADATA_KEY, WORKSPACE_MODULE_METADATA_KEY, WORKSPACE_MODULE_ROOT_METADATA_KEY,
+};
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+
+/// Single workspace module
+///
+/// # 4-Word Name: WorkspaceModuleSummaryItem
+#[derive(Debug, Serialize)]
+pub struct WorkspaceModuleSummaryItem {
+    pub name: String,
+    pub kind: String,
+    pub root_dir: String,
+    pub entity_count: usize,
+    /// `L1||L2` scope covering exactly this module, when one exists
+    pub scope: Option<String>,
+}
+
+/// Workspace modules data payload
+///
+/// # 4-Word Name: WorkspaceModulesDataPayload
+#[derive(Debug, Serialize)]
+pub struct WorkspaceModulesDataPayload {
+    pub total_count: usize,
+    /// Entities outside every detected module
+    pub unowned_entity_count: usize,
+    pub modules: Vec<WorkspaceModuleSummaryItem>,
+}
+
+/// Workspace modules response payload
+///
+/// # 4-Word Name: WorkspaceModulesResponsePayload
+#[derive(Debug, Serialize)]
+pub struct WorkspaceModulesResponsePayload {
+    pub success: bool,
+    pub endpoint: String,
+    pub data: WorkspaceModulesDataPayload,
+    pub tokens: usize,
+}
+
+/// Workspace modules error response
+///
+/// # 4-Word Name: WorkspaceModulesErrorResponse
+#[derive(Debug, Serialize)]
+pub struct WorkspaceModulesErrorResponse {
+    pub success: bool,
+    pub endpoint: String,
+    pub error: String,
+}
+
+/// Handle workspace modules list request
+///
+/// # 4-Word Name: handle_workspace_modules_list_all
+///
+/// # Contract
+/// - Precondition: Database ingested with workspace module tagging (v1.7.3)
+/// - Postcondition: Modules sorted by root directory, then kind
+/// - Error Handling: Returns error JSON if database unavailable
+pub async fn handle_workspace_modules_list_all(
+    State(state): State<SharedApplicationStateContainer>,
+) -> impl IntoResponse {
+    // Update last request timestamp
+    state.update_last_request_timestamp().await;
+
+    // Clone Arc inside RwLock scope, release lock
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        match db_guard.as_ref() {
+            Some(s) => s.clone(),
+            None => {
+                return (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(WorkspaceModulesErrorResponse {
+                        success: false,
+                        endpoint: "/workspace-modules-list-all".to_string(),
+                        error: "Database not connected".to_string(),
+                    }),
+                )
+                    .into_response()
+            }
+        }
+    }; // Lock released here
+
+    let metadata = match storage.get_entity_extractor_metadata_map().await {
+        Ok(m) => m,
+        Err(e) => {
+            return (
+                StatusCode::INTERNAL_SERVER_ERROR,
+                Json(WorkspaceModulesErrorResponse {
+                    success: false,
+                    endpoint: "/workspace-modules-list-all".to_string(),
+                    error: format!("Failed to query entity metadata: {}", e),
+                }),
+            )
+                .into_response()
+        }
+    };
+
+    let entity_count = storage
+        .raw_query("?[count(key)] := *CodeGraph{ISGL1_key: key}")
+        .await
+        .ok()
+        .and_then(|result| result.rows.first().and_then(|row| row.first()).and_then(extract_count_value))
+        .unwrap_or(0);
+
+    let modules = summarize_workspace_modules_from_metadata(metadata.values());
+    let owned: usize = modules.iter().map(|module| module.entity_count).sum();
+
+    // Estimate tokens (~30 per module)
+    let tokens = 60 + (modules.len() * 30);
+
+    (
+        StatusCode::OK,
+        Json(WorkspaceModulesResponsePayload {
+            success: true,
+            endpoint: "/workspace-modules-list-all".to_string(),
+            data: WorkspaceModulesDataPayload {
+                total_count: modules.len(),
+                unowned_entity_count: entity_count.saturating_sub(owned),
+                modules,
+            },
+            tokens,
+        }),
+    )
+        .into_response()
+}
+
+/// Group tagged entity metadata into per-module counts
+///
+/// # 4-Word Name: summarize_workspace_modules_from_metadata
+fn summarize_workspace_modules_from_metadata<'a>(
+    metadata: impl Iterator<Item = &'a std::collections::HashMap<String, String>>,
+) -> Vec<WorkspaceModuleSummaryItem> {
+    let mut counts: BTreeMap<(String, String, String), usize> = BTreeMap::new();
+    for entry in metadata {
+        let (Some(name), Some(kind), Some(root)) = (
+            entry.get(WORKSPACE_MODULE_METADATA_KEY),
+            entry.get(WORKSPACE_MODULE_KIND_METADATA_KEY),
+            entry.get(WORKSPACE_MODULE_ROOT_METADATA_KEY),
+        ) else {
+            continue;
+        };
+        *counts.entry((root.clone(), kind.clone(), name.clone())).or_default() += 1;
+    }
+
+    counts
+        .into_iter()
+        .map(|((root_dir, kind, name), entity_count)| WorkspaceModuleSummaryItem {
+            scope: scope_for_module_root(&root_dir),
+            name,
+            kind,
+            root_dir,
+            entity_count,
+        })
+        .collect()
+}
+
+/// `?scope=` value selecting exactly the files under `root_dir`
+fn scope_for_module_root(root_dir: &str) -> Option<String> {
+    let parts: Vec<&str> = root_dir.split('/').filter(|part| !part.is_empty() && *part != ".").collect();
+    match parts.as_slice() {
+        [l1] => Some(l1.to_string()),
+        [l1, l2] => Some(format!("{}||{}", l1, l2)),
+        _ => None,
+    }
+}
+
+fn extract_count_value(value: &cozo::DataValue) -> Option<usize> {
+    match value {
+        cozo::DataValue::Num(cozo::Num::Int(i)) => Some(*i as usize),
+        cozo::DataValue::Num(cozo::Num::Float(f)) => Some(*f as usize),
+        _ => None,
+    }
+}
@@ -3,20 +3,25 @@
 //! # 4-Word Naming: http_server_startup_runner
 
 use std::path::PathBuf;
-use std::sync::atomic::AtomicUsize;
+use std::sync::atomic::{AtomicU64, AtomicUsize, Ordering};
 use std::sync::Arc;
 use anyhow::Result;
 use chrono::{DateTime, Utc};
+use tokio::net::TcpListener;
 use tokio::sync::RwLock;
 
 use crate::command_line_argument_parser::HttpServerStartupConfig;
+use crate::daemon_query_telemetry_recorder::{spawn_query_span_export_loop, DaemonQueryTelemetryRegistry};
 use crate::file_watcher_integration_service::{
     create_production_watcher_service, FileWatcherIntegrationConfig,
 };
+use crate::graph_snapshot_isolation_registry::GraphSnapshotSwapRegistry;
+use crate::jsonrpc_stdio_daemon_bridge::{claim_process_stdout_protocol, run_jsonrpc_stdio_daemon_loop};
 use crate::port_selection::{find_and_bind_port_available, PortSelectionError};
 use crate::route_definition_builder_module::build_complete_router_instance;
 // TODO v1.4.3: Re-enable after implementing file_parser and entity_conversion
 // use parseltongue_core::file_parser::FileParser;
+use parseltongue_core::otlp_trace_span_exporter::{otlp_endpoint_from_environment, telemetry_service_name_or};
 use parseltongue_core::storage::CozoDbStorage;
 
 /// Shared application state container
@@ -59,6 +64,42 @@ pub struct SharedApplicationStateContainer {
     ///
     /// Bug Report: docs/File-Watcher-Debug-20260202.md
     pub watcher_service_instance_arc: Arc<RwLock<Option<crate::file_watcher_integration_service::ProductionFileWatcherService>>>,
+
+    /// Graph revision, bumped by every reindex that changes the graph (v1.7.3)
+    ///
+    /// # 4-Word Name: graph_revision_counter_arc
+    ///
+    /// Drives the ETags of `graph_revision_etag_middleware`.
+    pub graph_revision_counter_arc: Arc<AtomicU64>,
+
+    /// Webhook receiving graph change events, if configured (v1.7.3)
+    ///
+    /// # 4-Word Name: graph_change_webhook_url_arc
+    ///
+    /// Set from `--webhook`; read by every incremental reindex.
+    pub graph_change_webhook_url_arc: Arc<RwLock<Option<String>>>,
+
+    /// Query latency, status and cache counters plus pending spans (v1.7.3)
+    ///
+    /// # 4-Word Name: query_telemetry_registry_arc
+    ///
+    /// Fed by `record_daemon_query_telemetry`, read by `GET /metrics`.
+    pub query_telemetry_registry_arc: Arc<DaemonQueryTelemetryRegistry>,
+
+    /// Published graph snapshot plus the graph writer lock (v1.7.3)
+    ///
+    /// # 4-Word Name: graph_snapshot_registry_arc
+    ///
+    /// Pinned by multi-step queries; advanced by every incremental reindex.
+    pub graph_snapshot_registry_arc: Arc<GraphSnapshotSwapRegistry>,
+
+    /// Workspace the daemon ingested with pt01, once the hot ingest succeeded (v1.7.3)
+    ///
+    /// # 4-Word Name: hot_ingest_workspace_root_arc
+    ///
+    /// Set, reindexes of files under it run pt01's incremental ingest, so the
+    /// cross-file passes see the change.
+    pub hot_ingest_workspace_root_arc: Arc<RwLock<Option<PathBuf>>>,
 }
 
 /// Codebase statistics metadata
@@ -145,6 +186,11 @@ impl SharedApplicationStateContainer {
             // file_parser_instance_arc: Arc::new(parser),
             file_watcher_status_metadata_arc: Arc::new(RwLock::new(FileWatcherStatusMetadata::default())),
             watcher_service_instance_arc: Arc::new(RwLock::new(None)),
+            graph_revision_counter_arc: Arc::new(AtomicU64::new(0)),
+            graph_change_webhook_url_arc: Arc::new(RwLock::new(None)),
+            query_telemetry_registry_arc: Arc::new(DaemonQueryTelemetryRegistry::default()),
+            graph_snapshot_registry_arc: Arc::new(GraphSnapshotSwapRegistry::default()),
+            hot_ingest_workspace_root_arc: Arc::new(RwLock::new(None)),
         }
     }
 
@@ -166,6 +212,11 @@ impl SharedApplicationStateContainer {
             // file_parser_instance_arc: Arc::new(parser),
             file_watcher_status_metadata_arc: Arc::new(RwLock::new(FileWatcherStatusMetadata::default())),
             watcher_service_instance_arc: Arc::new(RwLock::new(None)),
+            graph_revision_counter_arc: Arc::new(AtomicU64::new(0)),
+            graph_change_webhook_url_arc: Arc::new(RwLock::new(None)),
+            query_telemetry_registry_arc: Arc::new(DaemonQueryTelemetryRegistry::default()),
+            graph_snapshot_registry_arc: Arc::new(GraphSnapshotSwapRegistry::default()),
+            hot_ingest_workspace_root_arc: Arc::new(RwLock::new(None)),
         }
     }
 
@@ -177,6 +228,20 @@ impl SharedApplicationStateContainer {
         *timestamp = Utc::now();
     }
 
+    /// Current graph revision
+    ///
+    /// # 4-Word Name: current_graph_revision_value
+    pub fn current_graph_revision_value(&self) -> u64 {
+        self.graph_revision_counter_arc.load(Ordering::Acquire)
+    }
+
+    /// Record a graph change; returns the new revision
+    ///
+    /// # 4-Word Name: bump_graph_revision_counter
+    pub fn bump_graph_revision_counter(&self) -> u64 {
+        self.graph_revision_counter_arc.fetch_add(1, Ordering::AcqRel) + 1
+    }
+
     /// Query entity counts from database
     ///
     /// # 4-Word Name: query_entity_counts_from_database
@@ -310,42 +375,35 @@ impl SharedApplicationStateContainer {
 /// - Returns error if all ports in range are occupied
 /// - Returns error if binding fails due to permissions
 pub async fn start_http_server_blocking_loop(config: HttpServerStartupConfig) -> Result<()> {
+    // v1.7.3: --stdio takes stdout before anything prints and binds no port
+    let protocol_output = if config.stdio_jsonrpc_mode_flag {
+        Some(claim_process_stdout_protocol()?)
+    } else {
+        None
+    };
+
     // REQ-PORT-001.0 & REQ-PORT-002.0: Smart port selection with retry
     // This handles both --port specified and not specified cases uniformly
-    let listener = match find_and_bind_port_available(
-        config.http_port_override_option,
-        100, // max_attempts: try up to 100 ports
-    ).await {
-        Ok(l) => l,
-        Err(PortSelectionError::RangeExhausted { start, end }) => {
-            anyhow::bail!(
-                "No available port in range {}-{}. \
-                 Try closing some Parseltongue instances or specify a different starting port.",
-                start, end
-            );
-        }
-        Err(PortSelectionError::PermissionDenied { port, cause }) => {
-            anyhow::bail!(
-                "Permission denied for port {}: {}. \
-                 Try using a port >= 1024.",
-                port, cause
-            );
-        }
-        Err(PortSelectionError::SystemError { port, cause }) => {
-            anyhow::bail!(
-                "System error binding to port {}: {}. \
-                 Check if the port is available.",
-                port, cause
-            );
-        }
+    let listener = if protocol_output.is_some() {
+        None
+    } else {
+        Some(bind_http_listener_port(&config).await?)
     };
 
     // Get the actual bound port (may differ from preference)
-    let port = listener.local_addr()?.port();
+    let port = match &listener {
+        Some(listener) => listener.local_addr()?.port(),
+        None => 0,
+    };
 
     // Connect to database if path provided
     let db_path = &config.database_connection_string_value;
-    let state = if !db_path.is_empty() && db_path != "mem" {
+    let state = if config.daemon_background_mode_flag {
+        // v1.7.3: Watch daemon keeps the whole graph hot in memory
+        println!("Daemon mode: building in-memory graph");
+        let storage = CozoDbStorage::new("mem").await?;
+        SharedApplicationStateContainer::create_with_database_storage(storage)
+    } else if !db_path.is_empty() && db_path != "mem" {
         println!("Connecting to database: {}", db_path);
         match CozoDbStorage::new(db_path).await {
             Ok(storage) => {
@@ -362,10 +420,31 @@ pub async fn start_http_server_blocking_loop(config: HttpServerStartupConfig) ->
         SharedApplicationStateContainer::create_new_application_state()
     };
 
+    // v1.7.3: Graph change events for --webhook
+    if let Some(url) = &config.graph_change_webhook_url_option {
+        println!("✓ Graph change webhook: {}", url);
+        *state.graph_change_webhook_url_arc.write().await = Some(url.clone());
+    }
+
+    // v1.7.3: Per-query spans when OTEL_EXPORTER_OTLP_ENDPOINT is set
+    match otlp_endpoint_from_environment() {
+        Some(Ok(endpoint)) => {
+            println!("✓ OTLP traces: {}", endpoint.url);
+            let service = telemetry_service_name_or("parseltongue-daemon");
+            spawn_query_span_export_loop(state.query_telemetry_registry_arc.clone(), endpoint, service);
+        }
+        Some(Err(e)) => println!("⚠ Warning: query traces disabled: {}", e),
+        None => {}
+    }
+
     // Update database path in stats
     {
         let mut stats = state.codebase_statistics_metadata_arc.write().await;
-        stats.database_file_path_string = config.database_connection_string_value.clone();
+        stats.database_file_path_string = if config.daemon_background_mode_flag {
+            "mem".to_string()
+        } else {
+            config.database_connection_string_value.clone()
+        };
     }
 
     // v1.0.4: Populate languages from database
@@ -377,7 +456,27 @@ pub async fn start_http_server_blocking_loop(config: HttpServerStartupConfig) ->
     //   1. Initial scan (manual directory walk)
     //   2. Event watching (incremental updates)
     // Reference: /tmp/file_watching_research.md
-    if !db_path.is_empty() && db_path != "mem" {
+    if config.daemon_background_mode_flag {
+        let watch_dir = &config.target_directory_path_value;
+
+        match crate::initial_scan::execute_hot_memory_ingest_scan(watch_dir, &state).await {
+            Ok(stats) => {
+                println!("[HotIngest] ✓ Graph loaded:");
+                println!("  {} files, {} entities in {}ms",
+                    stats.files_processed_success_count,
+                    stats.entities_created_total_count,
+                    stats.scan_duration_milliseconds
+                );
+                state.populate_languages_from_database().await;
+                let mut metadata = state.codebase_statistics_metadata_arc.write().await;
+                metadata.ingestion_timestamp_utc_option = Some(Utc::now());
+            }
+            Err(e) => {
+                println!("[HotIngest] ⚠ Warning: Hot ingest failed: {}", e);
+                println!("  Continuing with file watcher (incremental only)");
+            }
+        }
+    } else if !db_path.is_empty() && db_path != "mem" {
         println!("[InitialScan] Performing initial codebase scan...");
         let watch_dir = &config.target_directory_path_value;
 
@@ -475,6 +574,11 @@ pub async fn start_http_server_blocking_loop(config: HttpServerStartupConfig) ->
     // Build router
     let router = build_complete_router_instance(state);
 
+    let Some(listener) = listener else {
+        let output = protocol_output.expect("stdio mode claims stdout");
+        return run_jsonrpc_stdio_daemon_loop(router, output).await;
+    };
+
     // Print startup message with actual bound port
     println!("Parseltongue HTTP Server");
     println!("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━");
@@ -496,6 +600,37 @@ pub async fn start_http_server_blocking_loop(config: HttpServerStartupConfig) ->
     Ok(())
 }
 
+/// Bind the HTTP listener with smart port selection (REQ-PORT-001 through REQ-PORT-005)
+async fn bind_http_listener_port(config: &HttpServerStartupConfig) -> Result<TcpListener> {
+    Ok(match find_and_bind_port_available(
+        config.http_port_override_option,
+        100, // max_attempts: try up to 100 ports
+    ).await {
+        Ok(l) => l,
+        Err(PortSelectionError::RangeExhausted { start, end }) => {
+            anyhow::bail!(
+                "No available port in range {}-{}. \
+                 Try closing some Parseltongue instances or specify a different starting port.",
+                start, end
+            );
+        }
+        Err(PortSelectionError::PermissionDenied { port, cause }) => {
+            anyhow::bail!(
+                "Permission denied for port {}: {}. \
+                 Try using a port >= 1024.",
+                port, cause
+            );
+        }
+        Err(PortSelectionError::SystemError { port, cause }) => {
+            anyhow::bail!(
+                "System error binding to port {}: {}. \
+                 Check if the port is available.",
+                port, cause
+            );
+        }
+    })
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
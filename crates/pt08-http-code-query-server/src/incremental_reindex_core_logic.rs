@@ -21,24 +21,39 @@
 //!
 //! 3. WHEN file parsing fails
 //!    THEN the system SHALL delete old entities and return deletion stats
+//!
+//! 4. WHEN the file lies in the workspace the daemon hot-ingested
+//!    THEN the system SHALL run pt01's incremental ingest over that workspace,
+//!    so cross-file edges into and out of the file are resolved as on ingest
 
 use sha2::{Digest, Sha256};
 use std::path::Path;
 use std::sync::Arc;
 use std::time::Instant;
 use thiserror::Error;
-use std::collections::HashSet;
+use std::collections::{HashMap, HashSet};
 
+use crate::graph_change_webhook_notifier::{
+    build_graph_change_event, resolve_edge_target_packages, spawn_graph_change_webhook_post, ReindexGraphChangeInputs,
+};
+use crate::graph_snapshot_isolation_registry::{advance_published_graph_snapshot, reload_published_graph_snapshot};
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
 use parseltongue_core::storage::CozoDbStorage;
+use parseltongue_core::derived_symbol_artifact_invalidator::{
+    collect_derived_artifact_carryover, plan_derived_artifact_invalidation, strip_derived_symbol_metadata,
+};
 use parseltongue_core::isgl1_v2::{
     EntityCandidate, OldEntity, EntityMatchResult, match_entity_with_old_index,
     compute_birth_timestamp, compute_content_hash, extract_semantic_path, format_key_v2,
 };
-use parseltongue_core::entities::{CodeEntity, InterfaceSignature, LineRange, Visibility, EntityClass as CoreEntityClass, Language, LanguageSpecificSignature, EntityType as CoreEntityType, RustSignature};
+use parseltongue_core::entities::{CodeEntity, DependencyEdge, InterfaceSignature, LineRange, Visibility, EntityClass as CoreEntityClass, Language, LanguageSpecificSignature, EntityType as CoreEntityType, RustSignature};
+use pt01_folder_to_cozodb_streamer::ingest_progress_event_reporter::IngestProgressOutputMode;
 use pt01_folder_to_cozodb_streamer::isgl1_generator::Isgl1KeyGeneratorFactory;
+use pt01_folder_to_cozodb_streamer::{FileStreamer, ToolFactory};
 use std::path::PathBuf;
 
+use crate::initial_scan::create_hot_ingest_config;
+
 /// Error types for incremental reindex operations
 ///
 /// # 4-Word Name: IncrementalReindexOperationError
@@ -112,6 +127,8 @@ pub fn compute_content_hash_sha256(content: &[u8]) -> String {
 /// - Performance: <500ms for typical file with <100 entities
 ///
 /// # Algorithm
+/// 0. Daemon workspace file (also deleted): pt01 incremental ingest, see
+///    `execute_workspace_incremental_ingest`
 /// 1. Read file content, compute SHA-256 hash
 /// 2. Compare hash against cached value (if exists)
 /// 3. If unchanged, return early with hash_changed: false
@@ -127,6 +144,13 @@ pub async fn execute_incremental_reindex_core(
     let start_time = Instant::now();
     let file_path = Path::new(file_path_string);
 
+    // v1.7.3: The daemon's graph came from pt01; re-run its incremental ingest
+    let workspace_root = state.hot_ingest_workspace_root_arc.read().await.clone();
+    if let Some(resolved) = workspace_root.as_deref().and_then(|root| resolve_workspace_file_path(root, file_path)) {
+        let root = workspace_root.unwrap_or_default();
+        return execute_workspace_incremental_ingest(&resolved, &root, state, start_time).await;
+    }
+
     // Validate file exists
     if !file_path.exists() {
         return Err(IncrementalReindexOperationError::FileNotFound(
@@ -149,6 +173,9 @@ pub async fn execute_incremental_reindex_core(
     // Compute hash
     let current_hash = compute_content_hash_sha256(&file_content);
 
+    // v1.7.3: One graph writer at a time; pinned query snapshots never see a half-done reindex
+    let _graph_writer = state.graph_snapshot_registry_arc.lock_graph_writer_exclusive().await;
+
     // Get database connection
     let db_guard = state.database_storage_connection_arc.read().await;
     let storage = db_guard.as_ref().ok_or(
@@ -202,7 +229,7 @@ async fn execute_reindex_with_storage_arc(
     file_content: &[u8],
     current_hash: &str,
     storage: &Arc<CozoDbStorage>,
-    _state: &SharedApplicationStateContainer,
+    state: &SharedApplicationStateContainer,
     start_time: Instant,
 ) -> ReindexResult<IncrementalReindexResultData> {
     let file_path = Path::new(file_path_string);
@@ -222,6 +249,14 @@ async fn execute_reindex_with_storage_arc(
 
     let entities_before = existing_entities.len();
 
+    // v1.7.3: Outgoing edges before the reindex, to spot new ones for --webhook
+    let webhook_url = state.graph_change_webhook_url_arc.read().await.clone();
+    let previous_edges = if webhook_url.is_some() {
+        collect_outgoing_file_edges(storage, &existing_entities).await
+    } else {
+        Vec::new()
+    };
+
     // Parse file using pt01's Isgl1KeyGenerator
     let key_generator = Isgl1KeyGeneratorFactory::new();
     let (parsed_entities, dependencies, _warnings) = match key_generator.parse_source(&file_content_str, file_path) {
@@ -249,7 +284,22 @@ async fn execute_reindex_with_storage_arc(
             } else {
                 0
             };
-
+            invalidate_stale_derived_artifacts(storage, &existing_entities, &[]).await;
+
+            let revision = state.bump_graph_revision_counter();
+            advance_published_graph_snapshot(state, storage, file_path_string, revision).await;
+            if let Some(url) = webhook_url {
+                let inputs = ReindexGraphChangeInputs {
+                    file_path: file_path_string,
+                    added_entities: Vec::new(),
+                    removed_entities: existing_entities.iter().collect(),
+                    previous_edges: &previous_edges,
+                    current_edges: &[],
+                };
+                if let Some(event) = build_graph_change_event(&inputs, &HashMap::new(), revision) {
+                    spawn_graph_change_webhook_post(url, event);
+                }
+            }
             let processing_time_ms = start_time.elapsed().as_millis() as u64;
             return Ok(IncrementalReindexResultData {
                 file_path: file_path_string.to_string(),
@@ -394,6 +444,9 @@ async fn execute_reindex_with_storage_arc(
         0
     };
 
+    // v1.7.3: Drop summaries/embeddings of edited and removed symbols only
+    invalidate_stale_derived_artifacts(storage, &existing_entities, &entities_to_upsert).await;
+
     // Insert/update all entities (CozoDB :put handles upsert)
     for code_entity in &entities_to_upsert {
         if let Err(e) = storage.insert_entity(code_entity).await {
@@ -429,6 +482,21 @@ async fn execute_reindex_with_storage_arc(
         eprintln!("[ReindexCore] Warning: Failed to update hash cache: {}", e);
     }
 
+    let revision = state.bump_graph_revision_counter();
+    advance_published_graph_snapshot(state, storage, file_path_string, revision).await;
+    if let Some(url) = webhook_url {
+        let target_packages = resolve_edge_target_packages(storage, &dependencies).await;
+        let inputs = ReindexGraphChangeInputs {
+            file_path: file_path_string,
+            added_entities: entities_to_upsert.iter().filter(|e| new_entity_keys.contains(&e.isgl1_key)).collect(),
+            removed_entities: existing_entities.iter().filter(|e| !matched_keys.contains(&e.isgl1_key)).collect(),
+            previous_edges: &previous_edges,
+            current_edges: &dependencies,
+        };
+        if let Some(event) = build_graph_change_event(&inputs, &target_packages, revision) {
+            spawn_graph_change_webhook_post(url, event);
+        }
+    }
     let processing_time_ms = start_time.elapsed().as_millis() as u64;
 
     Ok(IncrementalReindexResultData {
@@ -444,6 +512,168 @@ async fn execute_reindex_with_storage_arc(
     })
 }
 
+/// Execute pt01 incremental ingest for a daemon workspace file
+///
+/// # 4-Word Name: execute_workspace_incremental_ingest
+///
+/// # Contract
+/// - Precondition: the workspace was hot-ingested with `create_hot_ingest_config`
+/// - Postcondition: the stored graph matches what a full ingest would build,
+///   cross-file edges of unchanged files included; statistics count the
+///   entities of `file_path` and their outgoing edges
+/// - Performance: hashes every workspace file, re-parses only changed ones
+async fn execute_workspace_incremental_ingest(
+    file_path: &Path,
+    workspace_root: &Path,
+    state: &SharedApplicationStateContainer,
+    start_time: Instant,
+) -> ReindexResult<IncrementalReindexResultData> {
+    let file_path_string = file_path.to_string_lossy().to_string();
+    let _graph_writer = state.graph_snapshot_registry_arc.lock_graph_writer_exclusive().await;
+    let storage = state
+        .database_storage_connection_arc
+        .read()
+        .await
+        .clone()
+        .ok_or(IncrementalReindexOperationError::DatabaseNotConnected)?;
+
+    let existing_entities = storage
+        .get_entities_by_file_path(&file_path_string)
+        .await
+        .map_err(|e| IncrementalReindexOperationError::DatabaseOperationFailed(e.to_string()))?;
+    let previous_edges = collect_outgoing_file_edges(&storage, &existing_entities).await;
+    let previous_hash = storage.get_cached_file_hash_value(&file_path_string).await.ok().flatten();
+
+    let mut config = create_hot_ingest_config(workspace_root);
+    config.progress_output = IngestProgressOutputMode::Off;
+    let streamer = ToolFactory::create_streamer_with_storage(config, storage.clone())
+        .await
+        .map_err(|e| IncrementalReindexOperationError::DatabaseOperationFailed(e.to_string()))?;
+    let result = streamer
+        .stream_directory_incremental_by_hash()
+        .await
+        .map_err(|e| IncrementalReindexOperationError::DatabaseOperationFailed(e.to_string()))?;
+
+    let current_entities = storage
+        .get_entities_by_file_path(&file_path_string)
+        .await
+        .map_err(|e| IncrementalReindexOperationError::DatabaseOperationFailed(e.to_string()))?;
+    let current_edges = collect_outgoing_file_edges(&storage, &current_entities).await;
+    let previous_keys: HashSet<&str> = existing_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+    let current_keys: HashSet<&str> = current_entities.iter().map(|e| e.isgl1_key.as_str()).collect();
+    let edge_identity = |e: &DependencyEdge| (e.from_key.as_str().to_string(), e.to_key.as_str().to_string());
+    let previous_edge_ids: HashSet<(String, String)> = previous_edges.iter().map(edge_identity).collect();
+    let current_edge_ids: HashSet<(String, String)> = current_edges.iter().map(edge_identity).collect();
+    let hash_changed = storage.get_cached_file_hash_value(&file_path_string).await.ok().flatten() != previous_hash;
+
+    // Other files changed since their last event are ingested in the same run,
+    // and the cross-file passes may rebind edges of files that did not change
+    if hash_changed || result.processed_files > 0 {
+        let revision = state.bump_graph_revision_counter();
+        reload_published_graph_snapshot(state, &storage, revision).await;
+        if let Some(url) = state.graph_change_webhook_url_arc.read().await.clone() {
+            let target_packages = resolve_edge_target_packages(&storage, &current_edges).await;
+            let inputs = ReindexGraphChangeInputs {
+                file_path: &file_path_string,
+                added_entities: current_entities
+                    .iter()
+                    .filter(|e| !previous_keys.contains(e.isgl1_key.as_str()))
+                    .collect(),
+                removed_entities: existing_entities
+                    .iter()
+                    .filter(|e| !current_keys.contains(e.isgl1_key.as_str()))
+                    .collect(),
+                previous_edges: &previous_edges,
+                current_edges: &current_edges,
+            };
+            if let Some(event) = build_graph_change_event(&inputs, &target_packages, revision) {
+                spawn_graph_change_webhook_post(url, event);
+            }
+        }
+    }
+
+    Ok(IncrementalReindexResultData {
+        file_path: file_path_string,
+        entities_before: existing_entities.len(),
+        entities_after: current_entities.len(),
+        entities_added: current_keys.difference(&previous_keys).count(),
+        entities_removed: previous_keys.difference(&current_keys).count(),
+        edges_added: current_edge_ids.difference(&previous_edge_ids).count(),
+        edges_removed: previous_edge_ids.difference(&current_edge_ids).count(),
+        hash_changed,
+        processing_time_ms: start_time.elapsed().as_millis() as u64,
+    })
+}
+
+/// `file_path` under `workspace_root`, with its directory canonicalized
+///
+/// The file itself may be gone (deleted); its directory must exist.
+fn resolve_workspace_file_path(workspace_root: &Path, file_path: &Path) -> Option<PathBuf> {
+    let directory = file_path.parent().filter(|d| !d.as_os_str().is_empty()).unwrap_or(Path::new("."));
+    let resolved = directory.canonicalize().ok()?.join(file_path.file_name()?);
+    resolved.starts_with(workspace_root).then_some(resolved)
+}
+
+/// Stored edges whose source is one of `entities`
+async fn collect_outgoing_file_edges(storage: &CozoDbStorage, entities: &[CodeEntity]) -> Vec<DependencyEdge> {
+    let keys: Vec<String> = entities.iter().map(|e| e.isgl1_key.clone()).collect();
+    let sources: HashSet<&str> = keys.iter().map(|k| k.as_str()).collect();
+    storage
+        .get_edges_touching_entity_keys(&keys)
+        .await
+        .unwrap_or_default()
+        .into_iter()
+        .filter(|edge| sources.contains(edge.from_key.as_str()))
+        .collect()
+}
+
+/// Remove summaries and embeddings whose symbol changed or disappeared
+///
+/// Position-matched entities keep their key while their code changes, so the
+/// stored metadata row would otherwise keep a summary of the old code.
+async fn invalidate_stale_derived_artifacts(
+    storage: &CozoDbStorage,
+    previous: &[CodeEntity],
+    current: &[CodeEntity],
+) {
+    let stored_metadata = storage.get_entity_extractor_metadata_map().await.unwrap_or_default();
+    let carryover = collect_derived_artifact_carryover(previous, &stored_metadata);
+    let invalidated = plan_derived_artifact_invalidation(&carryover, current);
+    if invalidated.is_empty() {
+        return;
+    }
+
+    let current_by_key: HashMap<&str, &CodeEntity> = current.iter().map(|e| (e.isgl1_key.as_str(), e)).collect();
+    let mut rewritten = Vec::new();
+    let mut emptied = Vec::new();
+    for key in &invalidated {
+        let Some(mut metadata) = stored_metadata.get(key).cloned() else {
+            continue;
+        };
+        if !strip_derived_symbol_metadata(&mut metadata) && current_by_key.contains_key(key.as_str()) {
+            continue;
+        }
+        match current_by_key.get(key.as_str()) {
+            Some(entity) if !metadata.is_empty() => {
+                let mut entity = (*entity).clone();
+                entity.metadata.additional = metadata;
+                rewritten.push(entity);
+            }
+            _ => emptied.push(key.clone()),
+        }
+    }
+
+    if let Err(e) = storage.upsert_entity_extractor_metadata_batch(&rewritten).await {
+        eprintln!("[ReindexCore] Warning: Failed to rewrite stale metadata: {}", e);
+    }
+    if let Err(e) = storage.delete_entity_extractor_metadata_keys(&emptied).await {
+        eprintln!("[ReindexCore] Warning: Failed to delete stale metadata: {}", e);
+    }
+    if let Err(e) = storage.delete_symbol_embeddings_by_keys(&invalidated).await {
+        eprintln!("[ReindexCore] Warning: Failed to delete stale embeddings: {}", e);
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -27,6 +27,8 @@
 //!
 //! All functions follow 4-word naming:
 //! - execute_initial_codebase_scan
+//! - execute_hot_memory_ingest_scan
+//! - create_hot_ingest_config
 //! - extract_database_path_from_state
 
 use anyhow::Result;
@@ -100,6 +102,7 @@ pub async fn execute_initial_codebase_scan(
         ],
         parsing_library: "tree-sitter".to_string(),
         chunking: "ISGL1".to_string(),
+        ..Default::default()
     };
 
     // Create pt01 streamer (reuse ALL pt01 logic!)
@@ -128,6 +131,86 @@ pub async fn execute_initial_codebase_scan(
     Ok(stats)
 }
 
+/// Execute hot in-memory ingest scan (v1.7.3 watch daemon)
+///
+/// # 4-Word Name: execute_hot_memory_ingest_scan
+///
+/// Ingests the workspace directly into the storage instance held by `state`
+/// (typically `mem`), so HTTP queries and file watcher reindexing operate on
+/// the same hot graph. Uses pt01's parallel streamer (including the Go
+/// cross-file passes) with the same file filters as `pt01-folder-to-cozodb-streamer`.
+/// On success the workspace root is recorded, so later reindexes run pt01's
+/// incremental ingest rather than a single-file re-parse.
+///
+/// ## Error Handling
+/// Fails if no database is attached to the state; per-file errors are counted.
+pub async fn execute_hot_memory_ingest_scan(
+    workspace_path: &Path,
+    state: &SharedApplicationStateContainer,
+) -> Result<InitialScanStatistics> {
+    let start_time = Instant::now();
+
+    // Clone Arc, release lock before the long-running ingest
+    let storage = {
+        let db_guard = state.database_storage_connection_arc.read().await;
+        db_guard
+            .as_ref()
+            .cloned()
+            .ok_or_else(|| anyhow::anyhow!("No database attached - cannot perform hot ingest"))?
+    };
+
+    // v1.7.3: Canonical root, so watcher paths and stored file paths agree
+    let workspace_root = workspace_path.canonicalize().unwrap_or_else(|_| workspace_path.to_path_buf());
+    println!("[HotIngest] Scanning directory: {}", workspace_root.display());
+
+    let config = create_hot_ingest_config(&workspace_root);
+
+    let streamer = ToolFactory::create_streamer_with_storage(config, storage).await
+        .map_err(|e| anyhow::anyhow!("Failed to create streamer: {}", e))?;
+
+    let result = streamer.stream_directory_with_parallel_rayon().await
+        .map_err(|e| anyhow::anyhow!("Failed to stream directory: {}", e))?;
+    *state.hot_ingest_workspace_root_arc.write().await = Some(workspace_root);
+
+    let detailed_stats = streamer.get_stats();
+
+    Ok(InitialScanStatistics {
+        files_scanned_total_count: result.total_files,
+        files_processed_success_count: result.processed_files,
+        files_skipped_error_count: result.errors.len(),
+        entities_created_total_count: result.entities_created,
+        code_entities_created_count: detailed_stats.code_entities_created,
+        test_entities_created_count: detailed_stats.test_entities_created,
+        scan_duration_milliseconds: start_time.elapsed().as_millis(),
+    })
+}
+
+/// Create the streamer config of the hot ingest (v1.7.3 watch daemon)
+///
+/// # 4-Word Name: create_hot_ingest_config
+///
+/// Every file pt01 parses, build and dependency directories excluded. The
+/// daemon's incremental reindexes reuse it, so both see the same files.
+pub fn create_hot_ingest_config(workspace_root: &Path) -> StreamerConfig {
+    StreamerConfig {
+        root_dir: workspace_root.to_path_buf(),
+        db_path: "mem".to_string(),
+        max_file_size: 100 * 1024 * 1024,
+        include_patterns: vec!["*".to_string()],
+        exclude_patterns: vec![
+            "target".to_string(),
+            "node_modules".to_string(),
+            ".git".to_string(),
+            "build".to_string(),
+            "dist".to_string(),
+            "__pycache__".to_string(),
+            ".venv".to_string(),
+            "venv".to_string(),
+        ],
+        ..Default::default()
+    }
+}
+
 /// Extract database path from state
 ///
 /// # 4-Word Name: extract_database_path_from_state
@@ -170,4 +253,12 @@ mod tests {
         let result = extract_database_path_from_state(&state).await;
         assert!(result.is_err());
     }
+
+    #[tokio::test]
+    async fn test_hot_ingest_requires_attached_database() {
+        let state = SharedApplicationStateContainer::create_new_application_state();
+
+        let result = execute_hot_memory_ingest_scan(Path::new("."), &state).await;
+        assert!(result.is_err());
+    }
 }
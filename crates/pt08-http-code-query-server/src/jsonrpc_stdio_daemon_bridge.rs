@@ -0,0 +1,292 @@
+//! JSON-RPC stdio daemon bridge
+//!
+//! # 4-Word Naming: jsonrpc_stdio_daemon_bridge
+//!
+//! v1.7.3: `parseltongue daemon --stdio` serves the daemon API as
+//! newline-delimited JSON-RPC 2.0 on stdin/stdout instead of a TCP port, so an
+//! editor extension can spawn the companion as a child process and never
+//! manage ports.
+//!
+//! ## Methods
+//!
+//! Every daemon endpoint is a method named after its path without the
+//! leading slash; object `params` become the query string:
+//!
+//! ```text
+//! {"jsonrpc":"2.0","id":1,"method":"blast-radius-impact-analysis","params":{"entity":"rust:fn:main:...","hops":2}}
+//! ```
+//!
+//! The result is the endpoint's JSON body. Non-2xx answers become error
+//! `-32000` carrying the body as `data`; unknown paths are `-32601`.
+//! `initialize` and `shutdown` cover the editor lifecycle; the loop ends on
+//! `shutdown` or when stdin closes.
+//!
+//! ## stdout
+//!
+//! Ingest and watcher diagnostics print to stdout. On Unix the process's
+//! stdout is moved onto stderr before anything else runs and the protocol
+//! gets a private duplicate of the original descriptor; elsewhere the two
+//! share stdout.
+
+use axum::{
+    body::Body,
+    http::{Method, Request, StatusCode},
+    Router,
+};
+use serde_json::{json, Value};
+use tokio::io::{AsyncBufReadExt, AsyncWrite, AsyncWriteExt, BufReader};
+use tower::ServiceExt;
+
+use crate::mcp_stdio_protocol_server::{JSONRPC_INVALID_PARAMS, JSONRPC_METHOD_NOT_FOUND, JSONRPC_PARSE_ERROR};
+
+/// JSON-RPC error: the endpoint answered with a non-2xx status
+const JSONRPC_ENDPOINT_ERROR: i64 = -32000;
+
+/// Endpoints routed as POST; every other method is a GET
+pub const POST_DAEMON_API_METHODS: &[&str] = &["incremental-reindex-file-update"];
+
+/// Protocol channel handed to the stdio loop
+pub type JsonRpcProtocolOutputStream = Box<dyn AsyncWrite + Unpin + Send>;
+
+/// Endpoint URI (path + query) for a daemon method call
+///
+/// # 4-Word Name: build_daemon_method_uri
+///
+/// # Contract
+/// - Precondition: `params` is an object or null
+/// - Postcondition: method names other than lowercase words joined by `-`
+///   are rejected (no path traversal into gRPC or test routes); arrays are
+///   joined with `,`, values are percent-encoded
+pub fn build_daemon_method_uri(method: &str, params: &Value) -> Result<String, (i64, String)> {
+    let valid_name = !method.is_empty()
+        && method.split('-').all(|word| !word.is_empty() && word.chars().all(|c| c.is_ascii_lowercase() || c.is_ascii_digit()));
+    if !valid_name {
+        return Err((JSONRPC_METHOD_NOT_FOUND, format!("Method not found: {}", method)));
+    }
+
+    let mut query_pairs = Vec::new();
+    match params {
+        Value::Null => {}
+        Value::Object(map) => {
+            for (name, value) in map {
+                let text = match value {
+                    Value::String(s) => s.clone(),
+                    Value::Number(n) => n.to_string(),
+                    Value::Bool(b) => b.to_string(),
+                    Value::Array(items) => items
+                        .iter()
+                        .map(|item| item.as_str().map(str::to_string).unwrap_or_else(|| item.to_string()))
+                        .collect::<Vec<_>>()
+                        .join(","),
+                    Value::Null => continue,
+                    Value::Object(_) => {
+                        return Err((JSONRPC_INVALID_PARAMS, format!("Parameter '{}' must not be an object", name)))
+                    }
+                };
+                query_pairs.push(format!("{}={}", urlencoding::encode(name), urlencoding::encode(&text)));
+            }
+        }
+        _ => return Err((JSONRPC_INVALID_PARAMS, "params must be an object".to_string())),
+    }
+
+    if query_pairs.is_empty() {
+        Ok(format!("/{}", method))
+    } else {
+        Ok(format!("/{}?{}", method, query_pairs.join("&")))
+    }
+}
+
+/// Handle one JSON-RPC message
+///
+/// # 4-Word Name: handle_daemon_jsonrpc_message
+///
+/// # Contract
+/// - Postcondition: None for notifications (no `id`), otherwise a JSON-RPC
+///   response with either `result` or `error`
+pub async fn handle_daemon_jsonrpc_message(router: &Router, message: &Value) -> Option<Value> {
+    let id = message.get("id").cloned()?;
+    let method = message.get("method").and_then(Value::as_str).unwrap_or_default();
+    let params = message.get("params").cloned().unwrap_or(Value::Null);
+
+    let outcome = match method {
+        "initialize" => Ok(json!({
+            "serverInfo": { "name": "parseltongue", "version": env!("CARGO_PKG_VERSION") },
+            "methods": "daemon endpoint paths, see api-reference-documentation-help",
+        })),
+        "shutdown" => Ok(Value::Null),
+        _ => call_daemon_endpoint_via_router(router, method, &params).await,
+    };
+
+    Some(match outcome {
+        Ok(result) => json!({ "jsonrpc": "2.0", "id": id, "result": result }),
+        Err((code, message, data)) => {
+            let mut error = json!({ "code": code, "message": message });
+            if let Some(data) = data {
+                error["data"] = data;
+            }
+            json!({ "jsonrpc": "2.0", "id": id, "error": error })
+        }
+    })
+}
+
+/// Dispatch a method through the HTTP router
+async fn call_daemon_endpoint_via_router(
+    router: &Router,
+    method: &str,
+    params: &Value,
+) -> Result<Value, (i64, String, Option<Value>)> {
+    let uri = build_daemon_method_uri(method, params).map_err(|(code, message)| (code, message, None))?;
+    let http_method = if POST_DAEMON_API_METHODS.contains(&method) { Method::POST } else { Method::GET };
+    let request = Request::builder()
+        .method(http_method)
+        .uri(uri)
+        .body(Body::empty())
+        .map_err(|e| (JSONRPC_INVALID_PARAMS, e.to_string(), None))?;
+
+    let response = router
+        .clone()
+        .oneshot(request)
+        .await
+        .map_err(|e| (JSONRPC_ENDPOINT_ERROR, format!("Router error: {}", e), None))?;
+    let status = response.status();
+    let bytes = axum::body::to_bytes(response.into_body(), usize::MAX)
+        .await
+        .map_err(|e| (JSONRPC_ENDPOINT_ERROR, format!("Failed to read response body: {}", e), None))?;
+    let body: Option<Value> = serde_json::from_slice(&bytes).ok();
+
+    match body {
+        Some(body) if status.is_success() => Ok(body),
+        None if status == StatusCode::NOT_FOUND || status == StatusCode::METHOD_NOT_ALLOWED => {
+            Err((JSONRPC_METHOD_NOT_FOUND, format!("Method not found: {}", method), None))
+        }
+        Some(body) => {
+            let message = body
+                .get("error")
+                .and_then(Value::as_str)
+                .map(str::to_string)
+                .unwrap_or_else(|| format!("HTTP {}", status.as_u16()));
+            Err((JSONRPC_ENDPOINT_ERROR, message, Some(body)))
+        }
+        None => Err((
+            JSONRPC_ENDPOINT_ERROR,
+            format!("HTTP {}: {}", status.as_u16(), String::from_utf8_lossy(&bytes)),
+            None,
+        )),
+    }
+}
+
+/// Take stdout for the protocol, sending everything else printed to stderr
+///
+/// # 4-Word Name: claim_process_stdout_protocol
+///
+/// # Contract
+/// - Precondition: called once, before anything is printed
+/// - Postcondition: on Unix, `println!` output lands on stderr and the
+///   returned stream writes to the original stdout
+#[cfg(unix)]
+pub fn claim_process_stdout_protocol() -> std::io::Result<JsonRpcProtocolOutputStream> {
+    use std::io::Write;
+    use std::os::unix::io::FromRawFd;
+
+    extern "C" {
+        fn dup(fd: i32) -> i32;
+        fn dup2(src: i32, dst: i32) -> i32;
+    }
+
+    std::io::stdout().flush()?;
+    // SAFETY: plain descriptor calls on 0-2, which the process always owns;
+    // `saved` is a fresh descriptor whose ownership moves into the File.
+    let saved = unsafe { dup(1) };
+    if saved < 0 {
+        return Err(std::io::Error::last_os_error());
+    }
+    if unsafe { dup2(2, 1) } < 0 {
+        return Err(std::io::Error::last_os_error());
+    }
+    let protocol = unsafe { std::fs::File::from_raw_fd(saved) };
+    Ok(Box::new(tokio::fs::File::from_std(protocol)))
+}
+
+/// Take stdout for the protocol (shared with diagnostics off Unix)
+///
+/// # 4-Word Name: claim_process_stdout_protocol
+#[cfg(not(unix))]
+pub fn claim_process_stdout_protocol() -> std::io::Result<JsonRpcProtocolOutputStream> {
+    eprintln!("[JSON-RPC] Warning: diagnostics share stdout on this platform");
+    Ok(Box::new(tokio::io::stdout()))
+}
+
+/// Run the JSON-RPC loop until `shutdown` or stdin closes
+///
+/// # 4-Word Name: run_jsonrpc_stdio_daemon_loop
+///
+/// # Contract
+/// - Postcondition: malformed lines get a parse error response with
+///   `id: null`; every response is flushed before the next line is read
+pub async fn run_jsonrpc_stdio_daemon_loop(router: Router, mut output: JsonRpcProtocolOutputStream) -> anyhow::Result<()> {
+    let mut lines = BufReader::new(tokio::io::stdin()).lines();
+    eprintln!("[JSON-RPC] parseltongue daemon ready on stdio");
+
+    while let Some(line) = lines.next_line().await? {
+        if line.trim().is_empty() {
+            continue;
+        }
+        let (response, shutdown) = match serde_json::from_str::<Value>(&line) {
+            Ok(message) => (
+                handle_daemon_jsonrpc_message(&router, &message).await,
+                message.get("method").and_then(Value::as_str) == Some("shutdown"),
+            ),
+            Err(e) => (
+                Some(json!({
+                    "jsonrpc": "2.0",
+                    "id": Value::Null,
+                    "error": { "code": JSONRPC_PARSE_ERROR, "message": format!("Parse error: {}", e) },
+                })),
+                false,
+            ),
+        };
+        if let Some(response) = response {
+            output.write_all(response.to_string().as_bytes()).await?;
+            output.write_all(b"\n").await?;
+            output.flush().await?;
+        }
+        if shutdown {
+            break;
+        }
+    }
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::http_server_startup_runner::SharedApplicationStateContainer;
+    use crate::route_definition_builder_module::build_complete_router_instance;
+
+    #[tokio::test]
+    async fn test_methods_map_to_daemon_endpoints() {
+        assert_eq!(
+            build_daemon_method_uri("blast-radius-impact-analysis", &json!({ "entity": "rust:fn:a b" })).unwrap(),
+            "/blast-radius-impact-analysis?entity=rust%3Afn%3Aa%20b"
+        );
+        assert_eq!(
+            build_daemon_method_uri("blast-radius-impact-analysis", &json!({ "edges": ["calls", "uses"] })).unwrap(),
+            "/blast-radius-impact-analysis?edges=calls%2Cuses"
+        );
+        assert!(build_daemon_method_uri("parseltongue.v1.GraphQuery/Search", &Value::Null).is_err());
+        assert!(build_daemon_method_uri("code-entity-detail-view", &json!([1])).is_err());
+
+        let router = build_complete_router_instance(SharedApplicationStateContainer::create_new_application_state());
+        let stats = json!({ "jsonrpc": "2.0", "id": 1, "method": "codebase-statistics-overview-summary" });
+        let response = handle_daemon_jsonrpc_message(&router, &stats).await.unwrap();
+        assert_eq!(response["result"]["endpoint"], "/codebase-statistics-overview-summary");
+
+        let unknown = json!({ "jsonrpc": "2.0", "id": 2, "method": "no-such-endpoint" });
+        let response = handle_daemon_jsonrpc_message(&router, &unknown).await.unwrap();
+        assert_eq!(response["error"]["code"], JSONRPC_METHOD_NOT_FOUND);
+
+        let notification = json!({ "jsonrpc": "2.0", "method": "initialized" });
+        assert!(handle_daemon_jsonrpc_message(&router, &notification).await.is_none());
+    }
+}
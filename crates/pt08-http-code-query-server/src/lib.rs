@@ -22,6 +22,22 @@ pub mod route_definition_builder_module;
 pub mod structured_error_handling_types;
 pub mod http_endpoint_handler_modules;
 pub mod scope_filter_utilities_module;
+// v1.7.3: MCP stdio server (tools dispatched through the HTTP router)
+pub mod mcp_stdio_protocol_server;
+// v1.7.3: gRPC GraphQuery service (RPCs dispatched through the HTTP router)
+pub mod grpc_graph_query_service;
+// v1.7.3: ETags tied to the graph revision
+pub mod graph_revision_etag_middleware;
+// v1.7.3: Graph change events POSTed to --webhook
+pub mod graph_change_webhook_notifier;
+// v1.7.3: Daemon API as JSON-RPC over stdio (--stdio)
+pub mod jsonrpc_stdio_daemon_bridge;
+// v1.7.3: Read-only LSP server over the graph
+pub mod lsp_graph_navigation_server;
+// v1.7.3: Prometheus metrics and OTLP spans for daemon queries
+pub mod daemon_query_telemetry_recorder;
+// v1.7.3: Immutable graph snapshots pinned per query, swapped after reindex
+pub mod graph_snapshot_isolation_registry;
 
 // Re-export main types for convenience
 pub use command_line_argument_parser::HttpServerStartupConfig;
@@ -32,6 +48,7 @@ pub use file_watcher_integration_service::{
     create_mock_watcher_service,
 };
 pub use initial_scan::{execute_initial_codebase_scan, InitialScanStatistics};
+pub use mcp_stdio_protocol_server::run_mcp_stdio_server_loop;
 pub use port_selection::{find_and_bind_port_available, PortSelectionError, ValidatedPortNumber};
 pub use route_definition_builder_module::build_complete_router_instance;
 pub use structured_error_handling_types::HttpServerErrorTypes;
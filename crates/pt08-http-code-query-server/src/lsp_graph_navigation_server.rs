@@ -0,0 +1,835 @@
+//! LSP graph navigation server
+//!
+//! # 4-Word Naming: lsp_graph_navigation_server
+//!
+//! v1.7.3: `parseltongue lsp --db ...` is a read-only Language Server over
+//! stdio (Content-Length framed JSON-RPC) answering from the same graph the
+//! LLM endpoints read, so editor navigation and model context agree:
+//!
+//! - `textDocument/definition`: entities named like the word under the
+//!   cursor, callees of the enclosing entity first
+//! - `textDocument/references`: call sites of that entity (edge source line
+//!   when recorded, otherwise the caller's span)
+//! - `textDocument/prepareCallHierarchy`, `callHierarchy/incomingCalls`,
+//!   `callHierarchy/outgoingCalls`: callers and callees
+//! - `workspace/symbol`: case-insensitive name search
+//!
+//! Cross-language edges (cgo, FFI, gRPC stubs) are followed like any other.
+//! The graph is loaded once at startup; open documents are tracked with
+//! full-text sync only to find the word under the cursor. A frame with a bad
+//! `Content-Length` or body gets a parse error with `id: null` and the
+//! reader resumes at the next `Content-Length` header, as the MCP and
+//! JSON-RPC stdio loops do for bad lines; only end of input or `exit` stops
+//! the server.
+
+use std::collections::{BTreeSet, HashMap};
+use std::path::{Path, PathBuf};
+
+use parseltongue_core::entities::{CodeEntity, DependencyEdge};
+use serde_json::{json, Value};
+use tokio::io::{AsyncBufRead, AsyncBufReadExt, AsyncReadExt, AsyncWrite, AsyncWriteExt};
+
+use crate::mcp_stdio_protocol_server::{JSONRPC_INVALID_PARAMS, JSONRPC_METHOD_NOT_FOUND, JSONRPC_PARSE_ERROR};
+
+/// Upper bound on `workspace/symbol` results
+pub const WORKSPACE_SYMBOL_RESULT_LIMIT: usize = 200;
+
+/// One graph entity as the editor sees it
+///
+/// # 4-Word Name: LspEntityLocationEntry
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct LspEntityLocationEntry {
+    pub key: String,
+    pub name: String,
+    /// Key segment 2: fn, method, struct, ...
+    pub kind: String,
+    /// Relative to the workspace root, `/`-separated
+    pub file_path: String,
+    /// 1-based, inclusive
+    pub line_start: u32,
+    pub line_end: u32,
+}
+
+/// Graph edge reduced to navigation: target and call-site line
+#[derive(Debug, Clone)]
+struct NavigationEdgeEntry {
+    other_key: String,
+    /// 1-based line of the reference, from `source_location`
+    line: Option<u32>,
+}
+
+/// In-memory navigation view of the graph
+///
+/// # 4-Word Name: LspGraphNavigationIndex
+#[derive(Debug, Default)]
+pub struct LspGraphNavigationIndex {
+    entities: HashMap<String, LspEntityLocationEntry>,
+    keys_by_name: HashMap<String, Vec<String>>,
+    keys_by_file: HashMap<String, Vec<String>>,
+    callers: HashMap<String, Vec<NavigationEdgeEntry>>,
+    callees: HashMap<String, Vec<NavigationEdgeEntry>>,
+}
+
+impl LspGraphNavigationIndex {
+    /// Index entities and edges for navigation
+    ///
+    /// # 4-Word Name: build_lsp_navigation_index
+    ///
+    /// # Contract
+    /// - Postcondition: file paths are made relative to `root`; edges into
+    ///   unresolved placeholders follow the single entity of that name, or
+    ///   are dropped when the name is ambiguous or unknown
+    pub fn build_lsp_navigation_index(root: &Path, entities: &[CodeEntity], edges: &[DependencyEdge]) -> Self {
+        let mut index = Self::default();
+        for entity in entities {
+            let file_path = relative_graph_file_path(root, &entity.interface_signature.file_path);
+            if file_path.is_empty() {
+                continue;
+            }
+            let entry = LspEntityLocationEntry {
+                key: entity.isgl1_key.clone(),
+                name: entity.interface_signature.name.clone(),
+                kind: key_segment(&entity.isgl1_key, 1).to_string(),
+                file_path: file_path.clone(),
+                line_start: entity.interface_signature.line_range.start.max(1),
+                line_end: entity.interface_signature.line_range.end.max(entity.interface_signature.line_range.start.max(1)),
+            };
+            index.keys_by_name.entry(entry.name.clone()).or_default().push(entry.key.clone());
+            index.keys_by_file.entry(file_path).or_default().push(entry.key.clone());
+            index.entities.insert(entry.key.clone(), entry);
+        }
+        for keys in index.keys_by_name.values_mut().chain(index.keys_by_file.values_mut()) {
+            keys.sort();
+        }
+
+        for edge in edges {
+            let from = edge.from_key.as_str();
+            if !index.entities.contains_key(from) {
+                continue;
+            }
+            let Some(to) = index.resolve_edge_target_key(edge.to_key.as_str()) else {
+                continue;
+            };
+            let line = edge
+                .source_location
+                .as_deref()
+                .and_then(|location| location.rsplit(':').next())
+                .and_then(|line| line.parse().ok());
+            index.callees.entry(from.to_string()).or_default().push(NavigationEdgeEntry { other_key: to.clone(), line });
+            index.callers.entry(to).or_default().push(NavigationEdgeEntry { other_key: from.to_string(), line });
+        }
+        index
+    }
+
+    fn resolve_edge_target_key(&self, to_key: &str) -> Option<String> {
+        if self.entities.contains_key(to_key) {
+            return Some(to_key.to_string());
+        }
+        match self.keys_by_name.get(key_segment(to_key, 2)) {
+            Some(keys) if keys.len() == 1 && key_segment(to_key, 3) == "unresolved-reference" => Some(keys[0].clone()),
+            _ => None,
+        }
+    }
+
+    /// Number of indexed entities
+    ///
+    /// # 4-Word Name: indexed_entity_count_total
+    pub fn indexed_entity_count_total(&self) -> usize {
+        self.entities.len()
+    }
+
+    /// Innermost entity spanning a 1-based line of a file
+    ///
+    /// # 4-Word Name: find_enclosing_entity_at
+    pub fn find_enclosing_entity_at(&self, file_path: &str, line: u32) -> Option<&LspEntityLocationEntry> {
+        self.keys_by_file
+            .get(file_path)?
+            .iter()
+            .filter_map(|key| self.entities.get(key))
+            .filter(|entity| entity.line_start <= line && line <= entity.line_end)
+            .min_by_key(|entity| (entity.line_end - entity.line_start, entity.key.clone()))
+    }
+
+    /// Entities the word at a position refers to
+    ///
+    /// # 4-Word Name: resolve_symbol_at_position
+    ///
+    /// # Contract
+    /// - Postcondition: the enclosing entity itself when the cursor is on its
+    ///   name; else its callees with that name; else every entity with it
+    pub fn resolve_symbol_at_position(&self, file_path: &str, line: u32, word: &str) -> Vec<&LspEntityLocationEntry> {
+        let enclosing = self.find_enclosing_entity_at(file_path, line);
+        if let Some(enclosing) = enclosing {
+            if enclosing.name == word && enclosing.line_start == line {
+                return vec![enclosing];
+            }
+            let called: Vec<&LspEntityLocationEntry> = self
+                .callees
+                .get(&enclosing.key)
+                .into_iter()
+                .flatten()
+                .filter_map(|edge| self.entities.get(&edge.other_key))
+                .filter(|entity| entity.name == word)
+                .collect();
+            if !called.is_empty() {
+                return dedupe_entities(called);
+            }
+        }
+        self.keys_by_name
+            .get(word)
+            .into_iter()
+            .flatten()
+            .filter_map(|key| self.entities.get(key))
+            .collect()
+    }
+
+    /// Callers or callees of an entity, with the line of each reference
+    ///
+    /// # 4-Word Name: neighbour_entities_with_lines
+    pub fn neighbour_entities_with_lines(&self, key: &str, incoming: bool) -> Vec<(&LspEntityLocationEntry, Vec<u32>)> {
+        let edges = if incoming { self.callers.get(key) } else { self.callees.get(key) };
+        let mut grouped: HashMap<&str, BTreeSet<u32>> = HashMap::new();
+        for edge in edges.into_iter().flatten() {
+            let Some(other) = self.entities.get(&edge.other_key) else { continue };
+            let lines = grouped.entry(other.key.as_str()).or_default();
+            // Incoming: the call site sits in the caller; outgoing: in `key`
+            let fallback = if incoming { other.line_start } else { self.entities[key].line_start };
+            lines.insert(edge.line.unwrap_or(fallback));
+        }
+        let mut neighbours: Vec<(&LspEntityLocationEntry, Vec<u32>)> = grouped
+            .into_iter()
+            .map(|(other, lines)| (&self.entities[other], lines.into_iter().collect()))
+            .collect();
+        neighbours.sort_by(|a, b| a.0.key.cmp(&b.0.key));
+        neighbours
+    }
+
+    /// Entities whose name contains `query`, best matches first
+    ///
+    /// # 4-Word Name: search_workspace_symbol_names
+    pub fn search_workspace_symbol_names(&self, query: &str) -> Vec<&LspEntityLocationEntry> {
+        let needle = query.to_lowercase();
+        let mut matches: Vec<(u8, &LspEntityLocationEntry)> = self
+            .entities
+            .values()
+            .filter_map(|entity| {
+                let name = entity.name.to_lowercase();
+                let rank = if name == needle {
+                    0
+                } else if name.starts_with(&needle) {
+                    1
+                } else if name.contains(&needle) {
+                    2
+                } else {
+                    return None;
+                };
+                Some((rank, entity))
+            })
+            .collect();
+        matches.sort_by(|a, b| (a.0, a.1.name.len(), &a.1.key).cmp(&(b.0, b.1.name.len(), &b.1.key)));
+        matches.into_iter().take(WORKSPACE_SYMBOL_RESULT_LIMIT).map(|(_, entity)| entity).collect()
+    }
+}
+
+fn dedupe_entities(mut entities: Vec<&LspEntityLocationEntry>) -> Vec<&LspEntityLocationEntry> {
+    entities.sort_by(|a, b| a.key.cmp(&b.key));
+    entities.dedup_by(|a, b| a.key == b.key);
+    entities
+}
+
+fn key_segment(key: &str, index: usize) -> &str {
+    key.split(':').nth(index).unwrap_or("")
+}
+
+/// Graph file path relative to the workspace root, `/`-separated
+fn relative_graph_file_path(root: &Path, path: &Path) -> String {
+    let relative = path.strip_prefix(root).unwrap_or(path);
+    relative
+        .components()
+        .filter(|c| !matches!(c, std::path::Component::CurDir))
+        .map(|c| c.as_os_str().to_string_lossy())
+        .collect::<Vec<_>>()
+        .join("/")
+}
+
+/// Server state beyond the index: root and open documents
+///
+/// # 4-Word Name: LspNavigationSessionState
+#[derive(Debug)]
+pub struct LspNavigationSessionState {
+    pub index: LspGraphNavigationIndex,
+    pub root: PathBuf,
+    open_documents: HashMap<String, String>,
+    shutdown_requested: bool,
+}
+
+impl LspNavigationSessionState {
+    /// Session over an index for a workspace root
+    ///
+    /// # 4-Word Name: create_lsp_navigation_session
+    pub fn create_lsp_navigation_session(index: LspGraphNavigationIndex, root: PathBuf) -> Self {
+        Self { index, root, open_documents: HashMap::new(), shutdown_requested: false }
+    }
+
+    fn uri_to_relative_path(&self, uri: &str) -> String {
+        let path = uri.strip_prefix("file://").unwrap_or(uri);
+        let decoded = urlencoding::decode(path).map(|p| p.into_owned()).unwrap_or_else(|_| path.to_string());
+        relative_graph_file_path(&self.root, Path::new(&decoded))
+    }
+
+    fn relative_path_to_uri(&self, file_path: &str) -> String {
+        let absolute = self.root.join(file_path);
+        let text = absolute.to_string_lossy().replace('\\', "/");
+        let encoded: Vec<String> = text.split('/').map(|part| urlencoding::encode(part).into_owned()).collect();
+        let joined = encoded.join("/");
+        if joined.starts_with('/') {
+            format!("file://{}", joined)
+        } else {
+            format!("file:///{}", joined)
+        }
+    }
+
+    fn word_at_position(&self, uri: &str, line: usize, character: usize) -> Option<String> {
+        let text = match self.open_documents.get(uri) {
+            Some(text) => text.clone(),
+            None => std::fs::read_to_string(self.root.join(self.uri_to_relative_path(uri))).ok()?,
+        };
+        let line_text: Vec<char> = text.lines().nth(line)?.chars().collect();
+        let is_word = |c: &char| c.is_alphanumeric() || *c == '_';
+        let mut start = character.min(line_text.len());
+        while start > 0 && is_word(&line_text[start - 1]) {
+            start -= 1;
+        }
+        let mut end = character.min(line_text.len());
+        while end < line_text.len() && is_word(&line_text[end]) {
+            end += 1;
+        }
+        (start < end).then(|| line_text[start..end].iter().collect())
+    }
+
+    fn location_json(&self, entity: &LspEntityLocationEntry, line: Option<u32>) -> Value {
+        let range = match line {
+            Some(line) => line_range_json(line, line),
+            None => line_range_json(entity.line_start, entity.line_end),
+        };
+        json!({ "uri": self.relative_path_to_uri(&entity.file_path), "range": range })
+    }
+
+    fn call_hierarchy_item_json(&self, entity: &LspEntityLocationEntry) -> Value {
+        json!({
+            "name": entity.name,
+            "kind": lsp_symbol_kind_number(&entity.kind),
+            "detail": entity.key,
+            "uri": self.relative_path_to_uri(&entity.file_path),
+            "range": line_range_json(entity.line_start, entity.line_end),
+            "selectionRange": line_range_json(entity.line_start, entity.line_start),
+            "data": { "key": entity.key },
+        })
+    }
+
+    /// Cursor position → (relative file, 1-based line, word)
+    fn cursor_symbol_context(&self, params: &Value) -> Option<(String, u32, String)> {
+        let uri = params.pointer("/textDocument/uri")?.as_str()?;
+        let line = params.pointer("/position/line")?.as_u64()? as usize;
+        let character = params.pointer("/position/character")?.as_u64()? as usize;
+        let word = self.word_at_position(uri, line, character)?;
+        Some((self.uri_to_relative_path(uri), line as u32 + 1, word))
+    }
+}
+
+/// 1-based inclusive lines → LSP range (0-based, end exclusive)
+fn line_range_json(line_start: u32, line_end: u32) -> Value {
+    json!({
+        "start": { "line": line_start.saturating_sub(1), "character": 0 },
+        "end": { "line": line_end, "character": 0 },
+    })
+}
+
+/// LSP `SymbolKind` for a key kind segment
+fn lsp_symbol_kind_number(kind: &str) -> u32 {
+    match kind {
+        "module" | "package" | "mod" => 2,
+        "class" | "impl" => 5,
+        "method" => 6,
+        "field" => 8,
+        "enum" => 10,
+        "interface" | "trait" => 11,
+        "fn" | "function" | "macro" | "proc_macro" | "test" => 12,
+        "var" | "variable" | "static" => 13,
+        "const" | "constant" => 14,
+        "struct" | "type" => 23,
+        _ => 13,
+    }
+}
+
+/// Handle one LSP message
+///
+/// # 4-Word Name: handle_lsp_navigation_message
+///
+/// # Contract
+/// - Postcondition: None for notifications; requests get `result` or
+///   `error`; after `shutdown` every request except `exit` is rejected
+pub fn handle_lsp_navigation_message(session: &mut LspNavigationSessionState, message: &Value) -> Option<Value> {
+    let method = message.get("method").and_then(Value::as_str).unwrap_or_default();
+    let params = message.get("params").cloned().unwrap_or(Value::Null);
+
+    match method {
+        "textDocument/didOpen" | "textDocument/didChange" => {
+            let uri = params.pointer("/textDocument/uri").and_then(Value::as_str).unwrap_or_default().to_string();
+            let text = params
+                .pointer("/textDocument/text")
+                .or_else(|| params.pointer("/contentChanges/0/text"))
+                .and_then(Value::as_str);
+            if let Some(text) = text {
+                session.open_documents.insert(uri, text.to_string());
+            }
+        }
+        "textDocument/didClose" => {
+            if let Some(uri) = params.pointer("/textDocument/uri").and_then(Value::as_str) {
+                session.open_documents.remove(uri);
+            }
+        }
+        _ => {}
+    }
+
+    let id = message.get("id").cloned()?;
+    let outcome: Result<Value, (i64, String)> = if session.shutdown_requested {
+        Err((JSONRPC_INVALID_PARAMS, "Server is shutting down".to_string()))
+    } else {
+        match method {
+            "initialize" => {
+                if let Some(root) = params
+                    .get("rootUri")
+                    .and_then(Value::as_str)
+                    .and_then(|uri| uri.strip_prefix("file://"))
+                    .and_then(|path| urlencoding::decode(path).ok())
+                {
+                    session.root = PathBuf::from(root.into_owned());
+                }
+                Ok(json!({
+                    "capabilities": {
+                        "textDocumentSync": 1,
+                        "definitionProvider": true,
+                        "referencesProvider": true,
+                        "callHierarchyProvider": true,
+                        "workspaceSymbolProvider": true,
+                    },
+                    "serverInfo": { "name": "parseltongue", "version": env!("CARGO_PKG_VERSION") },
+                }))
+            }
+            "shutdown" => {
+                session.shutdown_requested = true;
+                Ok(Value::Null)
+            }
+            "textDocument/definition" => Ok(match session.cursor_symbol_context(&params) {
+                Some((file, line, word)) => Value::Array(
+                    session
+                        .index
+                        .resolve_symbol_at_position(&file, line, &word)
+                        .into_iter()
+                        .map(|entity| session.location_json(entity, None))
+                        .collect(),
+                ),
+                None => Value::Null,
+            }),
+            "textDocument/references" => Ok(match session.cursor_symbol_context(&params) {
+                Some((file, line, word)) => {
+                    let include_declaration =
+                        params.pointer("/context/includeDeclaration").and_then(Value::as_bool).unwrap_or(false);
+                    let mut locations = Vec::new();
+                    for target in session.index.resolve_symbol_at_position(&file, line, &word) {
+                        if include_declaration {
+                            locations.push(session.location_json(target, Some(target.line_start)));
+                        }
+                        for (caller, lines) in session.index.neighbour_entities_with_lines(&target.key, true) {
+                            locations.extend(lines.into_iter().map(|line| session.location_json(caller, Some(line))));
+                        }
+                    }
+                    Value::Array(locations)
+                }
+                None => Value::Null,
+            }),
+            "textDocument/prepareCallHierarchy" => Ok(match session.cursor_symbol_context(&params) {
+                Some((file, line, word)) => Value::Array(
+                    session
+                        .index
+                        .resolve_symbol_at_position(&file, line, &word)
+                        .into_iter()
+                        .map(|entity| session.call_hierarchy_item_json(entity))
+                        .collect(),
+                ),
+                None => Value::Null,
+            }),
+            "callHierarchy/incomingCalls" | "callHierarchy/outgoingCalls" => {
+                let incoming = method == "callHierarchy/incomingCalls";
+                match params.pointer("/item/data/key").and_then(Value::as_str) {
+                    Some(key) if session.index.entities.contains_key(key) => Ok(Value::Array(
+                        session
+                            .index
+                            .neighbour_entities_with_lines(key, incoming)
+                            .into_iter()
+                            .map(|(other, lines)| {
+                                let ranges: Vec<Value> = lines.iter().map(|line| line_range_json(*line, *line)).collect();
+                                let item = session.call_hierarchy_item_json(other);
+                                if incoming {
+                                    json!({ "from": item, "fromRanges": ranges })
+                                } else {
+                                    json!({ "to": item, "fromRanges": ranges })
+                                }
+                            })
+                            .collect(),
+                    )),
+                    _ => Err((JSONRPC_INVALID_PARAMS, "item.data.key is not a graph entity".to_string())),
+                }
+            }
+            "workspace/symbol" => {
+                let query = params.get("query").and_then(Value::as_str).unwrap_or_default();
+                Ok(Value::Array(
+                    session
+                        .index
+                        .search_workspace_symbol_names(query)
+                        .into_iter()
+                        .map(|entity| {
+                            json!({
+                                "name": entity.name,
+                                "kind": lsp_symbol_kind_number(&entity.kind),
+                                "containerName": entity.file_path,
+                                "location": session.location_json(entity, None),
+                            })
+                        })
+                        .collect(),
+                ))
+            }
+            _ => Err((JSONRPC_METHOD_NOT_FOUND, format!("Method not found: {}", method))),
+        }
+    };
+
+    Some(match outcome {
+        Ok(result) => json!({ "jsonrpc": "2.0", "id": id, "result": result }),
+        Err((code, message)) => json!({ "jsonrpc": "2.0", "id": id, "error": { "code": code, "message": message } }),
+    })
+}
+
+/// One read from a `Content-Length` framed stream
+///
+/// # 4-Word Name: LspFramedReadOutcome
+#[derive(Debug, Clone, PartialEq)]
+pub enum LspFramedReadOutcome {
+    Message(Value),
+    /// Unusable frame (reason); the reader is already past it
+    Malformed(String),
+    EndOfInput,
+}
+
+/// Read one `Content-Length` framed message
+///
+/// # 4-Word Name: read_lsp_framed_message
+///
+/// # Contract
+/// - Postcondition: `Malformed` for a non-numeric length, a body that is
+///   not JSON or one cut short by end of input; in the first case the
+///   unsized body is skipped as non-header lines up to the next
+///   `Content-Length` (which may follow the body on the same line)
+/// - Error: only I/O errors of the underlying reader
+pub async fn read_lsp_framed_message<R: AsyncBufRead + Unpin>(reader: &mut R) -> anyhow::Result<LspFramedReadOutcome> {
+    const LENGTH_HEADER: &str = "content-length:";
+    let mut content_length: Option<Result<usize, String>> = None;
+    let length = loop {
+        let mut raw = Vec::new();
+        if reader.read_until(b'\n', &mut raw).await? == 0 {
+            return Ok(LspFramedReadOutcome::EndOfInput);
+        }
+        let line = String::from_utf8_lossy(&raw);
+        let line = line.trim_end();
+        if line.is_empty() {
+            match content_length.take() {
+                Some(Ok(length)) => break length,
+                Some(Err(reason)) => return Ok(LspFramedReadOutcome::Malformed(reason)),
+                None => continue,
+            }
+        }
+        // ASCII lowercasing keeps byte offsets, so `at` indexes `line` too
+        if let Some(at) = line.to_ascii_lowercase().find(LENGTH_HEADER) {
+            let value = line[at + LENGTH_HEADER.len()..].trim();
+            content_length = Some(value.parse().map_err(|_| format!("Parse error: bad Content-Length {:?}", value)));
+        }
+    };
+    let mut body = vec![0u8; length];
+    if let Err(e) = reader.read_exact(&mut body).await {
+        if e.kind() == std::io::ErrorKind::UnexpectedEof {
+            return Ok(LspFramedReadOutcome::Malformed(format!("Parse error: body shorter than {} bytes", length)));
+        }
+        return Err(e.into());
+    }
+    Ok(match serde_json::from_slice(&body) {
+        Ok(message) => LspFramedReadOutcome::Message(message),
+        Err(e) => LspFramedReadOutcome::Malformed(format!("Parse error: {}", e)),
+    })
+}
+
+/// Write one `Content-Length` framed message
+///
+/// # 4-Word Name: write_lsp_framed_message
+pub async fn write_lsp_framed_message<W: AsyncWrite + Unpin>(writer: &mut W, message: &Value) -> anyhow::Result<()> {
+    let body = message.to_string();
+    writer.write_all(format!("Content-Length: {}\r\n\r\n", body.len()).as_bytes()).await?;
+    writer.write_all(body.as_bytes()).await?;
+    writer.flush().await?;
+    Ok(())
+}
+
+/// Serve LSP on stdin/stdout until `exit` or end of input
+///
+/// # 4-Word Name: run_lsp_stdio_server_loop
+pub async fn run_lsp_stdio_server_loop(session: LspNavigationSessionState) -> anyhow::Result<()> {
+    let mut reader = tokio::io::BufReader::new(tokio::io::stdin());
+    let mut stdout = tokio::io::stdout();
+    eprintln!("[LSP] parseltongue language server ready ({} entities)", session.index.indexed_entity_count_total());
+    serve_lsp_framed_message_stream(session, &mut reader, &mut stdout).await
+}
+
+/// Answer framed messages from `reader` on `writer` until `exit` or end of input
+///
+/// # 4-Word Name: serve_lsp_framed_message_stream
+///
+/// # Contract
+/// - Postcondition: malformed frames get a parse error response with
+///   `id: null` and serving continues; Err only for I/O errors
+pub async fn serve_lsp_framed_message_stream<R: AsyncBufRead + Unpin, W: AsyncWrite + Unpin>(
+    mut session: LspNavigationSessionState,
+    reader: &mut R,
+    writer: &mut W,
+) -> anyhow::Result<()> {
+    loop {
+        let response = match read_lsp_framed_message(reader).await? {
+            LspFramedReadOutcome::EndOfInput => break,
+            LspFramedReadOutcome::Malformed(reason) => Some(json!({
+                "jsonrpc": "2.0",
+                "id": Value::Null,
+                "error": { "code": JSONRPC_PARSE_ERROR, "message": reason },
+            })),
+            LspFramedReadOutcome::Message(message) => {
+                if message.get("method").and_then(Value::as_str) == Some("exit") {
+                    break;
+                }
+                handle_lsp_navigation_message(&mut session, &message)
+            }
+        };
+        if let Some(response) = response {
+            write_lsp_framed_message(writer, &response).await?;
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use parseltongue_core::entities::{
+        EdgeType, EntityClass, EntityType, InterfaceSignature, LanguageSpecificSignature, LineRange, RustSignature,
+        Visibility,
+    };
+
+    fn entity(key: &str, name: &str, file: &str, start: u32, end: u32) -> CodeEntity {
+        let signature = InterfaceSignature {
+            entity_type: EntityType::Function,
+            name: name.to_string(),
+            visibility: Visibility::Public,
+            file_path: PathBuf::from(file),
+            line_range: LineRange::new(start, end).unwrap(),
+            module_path: vec![],
+            documentation: None,
+            language_specific: LanguageSpecificSignature::Rust(RustSignature {
+                generics: vec![],
+                lifetimes: vec![],
+                where_clauses: vec![],
+                attributes: vec![],
+                trait_impl: None,
+            }),
+        };
+        CodeEntity::new(key.to_string(), signature, EntityClass::CodeImplementation).unwrap()
+    }
+
+    #[tokio::test]
+    async fn test_definition_references_and_framing() {
+        let root = PathBuf::from("/ws");
+        let entities = vec![
+            entity("go:fn:Serve:__api_server:T1", "Serve", "/ws/api/server.go", 3, 6),
+            entity("go:fn:Open:__store_db:T2", "Open", "./store/db.go", 1, 4),
+        ];
+        let edges = vec![DependencyEdge::builder()
+            .from_key("go:fn:Serve:__api_server:T1")
+            .to_key("go:fn:Open:unresolved-reference:0-0")
+            .edge_type(EdgeType::Calls)
+            .source_location("api/server.go:4")
+            .build()
+            .unwrap()];
+        let index = LspGraphNavigationIndex::build_lsp_navigation_index(&root, &entities, &edges);
+        let mut session = LspNavigationSessionState::create_lsp_navigation_session(index, root);
+
+        let uri = "file:///ws/api/server.go";
+        let open = json!({ "jsonrpc": "2.0", "method": "textDocument/didOpen", "params": { "textDocument": {
+            "uri": uri, "text": "package api\n\nfunc Serve() {\n\tstore.Open()\n}\n"
+        } } });
+        assert!(handle_lsp_navigation_message(&mut session, &open).is_none());
+
+        let at = |method: &str, line: u64, character: u64| {
+            json!({ "jsonrpc": "2.0", "id": 1, "method": method, "params": {
+                "textDocument": { "uri": uri }, "position": { "line": line, "character": character },
+                "context": { "includeDeclaration": false },
+            } })
+        };
+        let definition = handle_lsp_navigation_message(&mut session, &at("textDocument/definition", 3, 8)).unwrap();
+        assert_eq!(definition["result"][0]["uri"], "file:///ws/store/db.go");
+        assert_eq!(definition["result"][0]["range"]["start"]["line"], 0);
+
+        let references = handle_lsp_navigation_message(&mut session, &at("textDocument/references", 3, 8)).unwrap();
+        assert_eq!(references["result"][0]["uri"], uri);
+        assert_eq!(references["result"][0]["range"]["start"]["line"], 3);
+
+        let symbols = json!({ "jsonrpc": "2.0", "id": 2, "method": "workspace/symbol", "params": { "query": "ser" } });
+        let symbols = handle_lsp_navigation_message(&mut session, &symbols).unwrap();
+        assert_eq!(symbols["result"][0]["name"], "Serve");
+
+        let mut framed = Vec::new();
+        write_lsp_framed_message(&mut framed, &symbols).await.unwrap();
+        let mut reader = tokio::io::BufReader::new(framed.as_slice());
+        assert_eq!(read_lsp_framed_message(&mut reader).await.unwrap(), LspFramedReadOutcome::Message(symbols));
+        assert_eq!(read_lsp_framed_message(&mut reader).await.unwrap(), LspFramedReadOutcome::EndOfInput);
+    }
+
+    #[test]
+    fn test_definition_fallbacks_and_declaration_references() {
+        let root = PathBuf::from("/ws");
+        let entities = vec![
+            entity("go:fn:Serve:__api_server:T1", "Serve", "/ws/api/server.go", 3, 7),
+            entity("go:fn:Open:__store_db:T2", "Open", "/ws/store/db.go", 1, 4),
+            entity("go:fn:Open:__cache_db:T3", "Open", "/ws/cache/db.go", 1, 2),
+            entity("go:fn:Close:__store_db:T4", "Close", "/ws/store/db.go", 6, 8),
+        ];
+        let call = |to: &str| {
+            DependencyEdge::builder()
+                .from_key("go:fn:Serve:__api_server:T1")
+                .to_key(to)
+                .edge_type(EdgeType::Calls)
+        };
+        let edges = vec![
+            // Two entities are named Open, so this placeholder is dropped
+            call("go:fn:Open:unresolved-reference:0-0").source_location("api/server.go:4").build().unwrap(),
+            call("go:fn:Close:__store_db:T4").build().unwrap(),
+        ];
+        let index = LspGraphNavigationIndex::build_lsp_navigation_index(&root, &entities, &edges);
+        let mut session = LspNavigationSessionState::create_lsp_navigation_session(index, root);
+
+        let uri = "file:///ws/api/server.go";
+        let open = json!({ "jsonrpc": "2.0", "method": "textDocument/didOpen", "params": { "textDocument": {
+            "uri": uri, "text": "package api\n\nfunc Serve() {\n\tstore.Open()\n\tstore.Close()\n}\n"
+        } } });
+        handle_lsp_navigation_message(&mut session, &open);
+        let at = |method: &str, line: u64, character: u64, include_declaration: bool| {
+            json!({ "jsonrpc": "2.0", "id": 1, "method": method, "params": {
+                "textDocument": { "uri": uri }, "position": { "line": line, "character": character },
+                "context": { "includeDeclaration": include_declaration },
+            } })
+        };
+        let uris = |response: &Value| -> Vec<String> {
+            response["result"].as_array().unwrap().iter().map(|l| l["uri"].as_str().unwrap().to_string()).collect()
+        };
+
+        // No callee edge survived: every entity named Open, in key order
+        let definition = handle_lsp_navigation_message(&mut session, &at("textDocument/definition", 3, 8, false));
+        assert_eq!(uris(&definition.unwrap()), vec!["file:///ws/cache/db.go", "file:///ws/store/db.go"]);
+
+        // On its own name the enclosing entity is the definition
+        let itself = handle_lsp_navigation_message(&mut session, &at("textDocument/definition", 2, 6, false)).unwrap();
+        assert_eq!(uris(&itself), vec![uri]);
+        assert_eq!(itself["result"][0]["range"]["start"]["line"], 2);
+
+        // Declaration first; an edge without a location points at the caller's first line
+        let references =
+            handle_lsp_navigation_message(&mut session, &at("textDocument/references", 4, 8, true)).unwrap();
+        assert_eq!(uris(&references), vec!["file:///ws/store/db.go", uri]);
+        assert_eq!(references["result"][0]["range"]["start"]["line"], 5);
+        assert_eq!(references["result"][1]["range"]["start"]["line"], 2);
+
+        // No word under the cursor
+        let blank = handle_lsp_navigation_message(&mut session, &at("textDocument/definition", 1, 0, false)).unwrap();
+        assert_eq!(blank["result"], Value::Null);
+    }
+
+    #[tokio::test]
+    async fn test_malformed_requests_and_frames_rejected() {
+        let index = LspGraphNavigationIndex::build_lsp_navigation_index(Path::new("/ws"), &[], &[]);
+        let mut session = LspNavigationSessionState::create_lsp_navigation_session(index, PathBuf::from("/ws"));
+        let mut send = |message: Value| handle_lsp_navigation_message(&mut session, &message);
+
+        let unknown = send(json!({ "jsonrpc": "2.0", "id": 7, "method": "textDocument/hover" })).unwrap();
+        assert_eq!(unknown["id"], 7);
+        assert_eq!(unknown["error"]["code"], JSONRPC_METHOD_NOT_FOUND);
+        let no_method = send(json!({ "jsonrpc": "2.0", "id": 8 })).unwrap();
+        assert_eq!(no_method["error"]["code"], JSONRPC_METHOD_NOT_FOUND);
+        // Notifications never get a response, known or not
+        assert!(send(json!({ "jsonrpc": "2.0", "method": "$/cancelRequest", "params": {} })).is_none());
+
+        // Missing position is a null result, not an error
+        let no_params = send(json!({ "jsonrpc": "2.0", "id": 9, "method": "textDocument/definition" })).unwrap();
+        assert_eq!(no_params["result"], Value::Null);
+        assert!(no_params.get("error").is_none());
+        let bad_item = send(json!({ "jsonrpc": "2.0", "id": 10, "method": "callHierarchy/incomingCalls",
+            "params": { "item": { "data": { "key": "go:fn:Nope:__x:T1" } } } }))
+        .unwrap();
+        assert_eq!(bad_item["error"]["code"], JSONRPC_INVALID_PARAMS);
+
+        let shutdown = send(json!({ "jsonrpc": "2.0", "id": 11, "method": "shutdown" })).unwrap();
+        assert_eq!(shutdown["result"], Value::Null);
+        let late = send(json!({ "jsonrpc": "2.0", "id": 12, "method": "workspace/symbol", "params": {} })).unwrap();
+        assert_eq!(late["error"]["code"], JSONRPC_INVALID_PARAMS);
+
+        // Framing: other headers and header case are ignored; bad frames are reported, not fatal
+        let mut reader = tokio::io::BufReader::new(
+            &b"content-length: 2\r\nContent-Type: application/vscode-jsonrpc\r\n\r\n{}"[..],
+        );
+        assert_eq!(read_lsp_framed_message(&mut reader).await.unwrap(), LspFramedReadOutcome::Message(json!({})));
+        for frame in [
+            &b"Content-Length: 5\r\n\r\n{oops"[..],
+            &b"Content-Length: ten\r\n\r\n{}"[..],
+            &b"Content-Length: 40\r\n\r\n{}"[..],
+        ] {
+            let mut reader = tokio::io::BufReader::new(frame);
+            let outcome = read_lsp_framed_message(&mut reader).await.unwrap();
+            assert!(matches!(outcome, LspFramedReadOutcome::Malformed(_)), "{:?}", outcome);
+        }
+        // Blank lines before any Content-Length are skipped up to end of input
+        let mut reader = tokio::io::BufReader::new(&b"\r\n\r\n"[..]);
+        assert_eq!(read_lsp_framed_message(&mut reader).await.unwrap(), LspFramedReadOutcome::EndOfInput);
+    }
+
+    #[tokio::test]
+    async fn test_server_survives_malformed_frames() {
+        let index = LspGraphNavigationIndex::build_lsp_navigation_index(Path::new("/ws"), &[], &[]);
+        let session = LspNavigationSessionState::create_lsp_navigation_session(index, PathBuf::from("/ws"));
+        let symbols = r#"{"jsonrpc":"2.0","id":3,"method":"workspace/symbol","params":{"query":"x"}}"#;
+        // Bad JSON, then an unparsable length whose body runs into the next header
+        let input = format!(
+            "Content-Length: 5\r\n\r\n{{oopsContent-Length: ten\r\n\r\n{{}}Content-Length: {}\r\n\r\n{}",
+            symbols.len(),
+            symbols
+        );
+        let mut reader = tokio::io::BufReader::new(input.as_bytes());
+        let mut output = Vec::new();
+        serve_lsp_framed_message_stream(session, &mut reader, &mut output).await.unwrap();
+
+        let mut replies = tokio::io::BufReader::new(output.as_slice());
+        let mut responses = Vec::new();
+        while let LspFramedReadOutcome::Message(reply) = read_lsp_framed_message(&mut replies).await.unwrap() {
+            responses.push(reply);
+        }
+        assert_eq!(responses.len(), 3, "{:?}", responses);
+        for parse_error in &responses[..2] {
+            assert_eq!(parse_error["id"], Value::Null);
+            assert_eq!(parse_error["error"]["code"], JSONRPC_PARSE_ERROR);
+        }
+        assert_eq!(responses[2]["id"], 3);
+        assert_eq!(responses[2]["result"], json!([]));
+    }
+}
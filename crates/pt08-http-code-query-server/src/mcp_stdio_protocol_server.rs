@@ -0,0 +1,384 @@
+//! MCP (Model Context Protocol) stdio server
+//!
+//! # 4-Word Naming: mcp_stdio_protocol_server
+//!
+//! v1.7.3: Exposes the code graph as MCP tools so agentic LLM clients can
+//! call `get_callers`, `get_blast_radius`, `get_context_pack`, ... directly
+//! instead of shelling out to curl.
+//!
+//! ## Transport
+//!
+//! Newline-delimited JSON-RPC 2.0 over stdin/stdout (MCP stdio transport).
+//! stdout carries protocol messages only; diagnostics go to stderr.
+//!
+//! ## Implementation Strategy
+//!
+//! Every tool maps to an existing HTTP endpoint. Calls are dispatched through
+//! the same axum router in-process (`tower::ServiceExt::oneshot`), so MCP and
+//! REST answers are identical and no handler logic is duplicated.
+
+use axum::{body::Body, http::Request, Router};
+use serde_json::{json, Value};
+use tokio::io::{AsyncBufReadExt, AsyncWriteExt, BufReader};
+use tower::ServiceExt;
+
+use crate::http_server_startup_runner::SharedApplicationStateContainer;
+use crate::route_definition_builder_module::build_complete_router_instance;
+
+/// MCP protocol revision implemented by this server
+pub const MCP_PROTOCOL_VERSION_STRING: &str = "2024-11-05";
+
+/// JSON-RPC error: method not found
+pub(crate) const JSONRPC_METHOD_NOT_FOUND: i64 = -32601;
+
+/// JSON-RPC error: invalid params
+pub(crate) const JSONRPC_INVALID_PARAMS: i64 = -32602;
+
+/// JSON-RPC error: parse error
+pub(crate) const JSONRPC_PARSE_ERROR: i64 = -32700;
+
+/// Single tool argument mapped to a query parameter
+///
+/// # 4-Word Name: McpToolArgumentSpec
+#[derive(Debug, Clone, Copy)]
+pub struct McpToolArgumentSpec {
+    /// Argument name in the MCP call (also the query parameter name)
+    pub name: &'static str,
+    /// JSON schema type ("string" or "integer")
+    pub json_type: &'static str,
+    /// Whether the argument must be supplied
+    pub required: bool,
+    /// Human-readable description shown to the LLM
+    pub description: &'static str,
+}
+
+/// MCP tool backed by an HTTP endpoint
+///
+/// # 4-Word Name: McpToolDefinitionEntry
+#[derive(Debug, Clone, Copy)]
+pub struct McpToolDefinitionEntry {
+    pub name: &'static str,
+    pub description: &'static str,
+    pub endpoint: &'static str,
+    pub arguments: &'static [McpToolArgumentSpec],
+}
+
+const ENTITY_ARGUMENT: McpToolArgumentSpec = McpToolArgumentSpec {
+    name: "entity",
+    json_type: "string",
+    required: true,
+    description: "ISGL1 entity key (e.g. rust:fn:main:src_main_rs:T1706284800)",
+};
+
+const SCOPE_ARGUMENT: McpToolArgumentSpec = McpToolArgumentSpec {
+    name: "scope",
+    json_type: "string",
+    required: false,
+    description: "Folder scope filter (e.g. crates||core)",
+};
+
+const MAX_FANOUT_ARGUMENT: McpToolArgumentSpec = McpToolArgumentSpec {
+    name: "max_fanout",
+    json_type: "integer",
+    required: false,
+    description: "Neighbours expanded per entity; the rest are counted in `truncation` (default all)",
+};
+
+const MAX_NODES_ARGUMENT: McpToolArgumentSpec = McpToolArgumentSpec {
+    name: "max_nodes",
+    json_type: "integer",
+    required: false,
+    description: "Entities returned in total; the rest are counted in `truncation` (default all)",
+};
+
+/// Tool table: MCP tool name → endpoint + arguments
+pub const MCP_TOOL_DEFINITION_TABLE: &[McpToolDefinitionEntry] = &[
+    McpToolDefinitionEntry {
+        name: "search_entities",
+        description: "Fuzzy search code entities by name; use first to find entity keys",
+        endpoint: "/code-entities-search-fuzzy",
+        arguments: &[
+            McpToolArgumentSpec { name: "q", json_type: "string", required: true, description: "Name fragment to search for" },
+            SCOPE_ARGUMENT,
+        ],
+    },
+    McpToolDefinitionEntry {
+        name: "get_entity_detail",
+        description: "Source code and metadata of one entity",
+        endpoint: "/code-entity-detail-view",
+        arguments: &[
+            McpToolArgumentSpec { name: "key", json_type: "string", required: true, description: "ISGL1 entity key" },
+            SCOPE_ARGUMENT,
+        ],
+    },
+    McpToolDefinitionEntry {
+        name: "get_callers",
+        description: "Entities that call the given entity (reverse edges)",
+        endpoint: "/reverse-callers-query-graph",
+        arguments: &[ENTITY_ARGUMENT, SCOPE_ARGUMENT, MAX_FANOUT_ARGUMENT, MAX_NODES_ARGUMENT],
+    },
+    McpToolDefinitionEntry {
+        name: "get_callees",
+        description: "Entities the given entity calls (forward edges)",
+        endpoint: "/forward-callees-query-graph",
+        arguments: &[ENTITY_ARGUMENT, SCOPE_ARGUMENT, MAX_FANOUT_ARGUMENT, MAX_NODES_ARGUMENT],
+    },
+    McpToolDefinitionEntry {
+        name: "get_blast_radius",
+        description: "Every entity transitively affected by changing the given entity",
+        endpoint: "/blast-radius-impact-analysis",
+        arguments: &[
+            ENTITY_ARGUMENT,
+            McpToolArgumentSpec { name: "hops", json_type: "integer", required: false, description: "Maximum hops to traverse (default 3)" },
+            McpToolArgumentSpec { name: "edges", json_type: "string", required: false, description: "Edge types to follow, e.g. calls,implements,uses (default all)" },
+            SCOPE_ARGUMENT,
+            MAX_FANOUT_ARGUMENT,
+            MAX_NODES_ARGUMENT,
+        ],
+    },
+    McpToolDefinitionEntry {
+        name: "get_context_pack",
+        description: "Most relevant context around a focus entity, fitted to a token budget",
+        endpoint: "/smart-context-token-budget",
+        arguments: &[
+            McpToolArgumentSpec { name: "focus", json_type: "string", required: true, description: "ISGL1 key of the focus entity" },
+            McpToolArgumentSpec { name: "tokens", json_type: "integer", required: false, description: "Token budget (default 4000)" },
+            SCOPE_ARGUMENT,
+        ],
+    },
+    McpToolDefinitionEntry {
+        name: "get_codebase_statistics",
+        description: "Entity/edge counts and languages of the indexed codebase",
+        endpoint: "/codebase-statistics-overview-summary",
+        arguments: &[],
+    },
+];
+
+/// Build the `tools/list` result payload
+///
+/// # 4-Word Name: list_mcp_tool_definitions
+pub fn list_mcp_tool_definitions() -> Value {
+    let tools: Vec<Value> = MCP_TOOL_DEFINITION_TABLE
+        .iter()
+        .map(|tool| {
+            let properties: serde_json::Map<String, Value> = tool
+                .arguments
+                .iter()
+                .map(|arg| {
+                    (arg.name.to_string(), json!({ "type": arg.json_type, "description": arg.description }))
+                })
+                .collect();
+            let required: Vec<&str> = tool.arguments.iter().filter(|a| a.required).map(|a| a.name).collect();
+            json!({
+                "name": tool.name,
+                "description": tool.description,
+                "inputSchema": { "type": "object", "properties": properties, "required": required },
+            })
+        })
+        .collect();
+    json!({ "tools": tools })
+}
+
+/// Build endpoint URI (path + query) for a tool call
+///
+/// # 4-Word Name: build_tool_endpoint_uri
+///
+/// # Contract
+/// - Precondition: `arguments` is a JSON object (or null when no args)
+/// - Postcondition: Err names the first missing required argument;
+///   unknown arguments are ignored; values are percent-encoded
+pub fn build_tool_endpoint_uri(tool: &McpToolDefinitionEntry, arguments: &Value) -> Result<String, String> {
+    let mut query_pairs = Vec::new();
+    for arg in tool.arguments {
+        let value = match arguments.get(arg.name) {
+            Some(Value::String(s)) => s.clone(),
+            Some(Value::Number(n)) => n.to_string(),
+            Some(Value::Bool(b)) => b.to_string(),
+            _ if arg.required => return Err(format!("Missing required argument: {}", arg.name)),
+            _ => continue,
+        };
+        query_pairs.push(format!("{}={}", arg.name, urlencoding::encode(&value)));
+    }
+    if query_pairs.is_empty() {
+        Ok(tool.endpoint.to_string())
+    } else {
+        Ok(format!("{}?{}", tool.endpoint, query_pairs.join("&")))
+    }
+}
+
+/// Handle one JSON-RPC message
+///
+/// # 4-Word Name: handle_mcp_jsonrpc_message
+///
+/// # Contract
+/// - Postcondition: None for notifications (no `id`), otherwise a JSON-RPC
+///   response with either `result` or `error`
+/// - Tool failures (unknown entity, no database) are reported as
+///   `isError: true` tool results, not protocol errors (per MCP spec)
+pub async fn handle_mcp_jsonrpc_message(router: &Router, message: &Value) -> Option<Value> {
+    let id = message.get("id").cloned()?;
+    let method = message.get("method").and_then(Value::as_str).unwrap_or_default();
+    let params = message.get("params").cloned().unwrap_or(Value::Null);
+
+    let outcome = match method {
+        "initialize" => Ok(json!({
+            "protocolVersion": MCP_PROTOCOL_VERSION_STRING,
+            "capabilities": { "tools": {} },
+            "serverInfo": { "name": "parseltongue", "version": env!("CARGO_PKG_VERSION") },
+        })),
+        "ping" => Ok(json!({})),
+        "tools/list" => Ok(list_mcp_tool_definitions()),
+        "tools/call" => call_mcp_tool_via_router(router, &params).await,
+        _ => Err((JSONRPC_METHOD_NOT_FOUND, format!("Method not found: {}", method))),
+    };
+
+    Some(match outcome {
+        Ok(result) => json!({ "jsonrpc": "2.0", "id": id, "result": result }),
+        Err((code, message)) => json!({ "jsonrpc": "2.0", "id": id, "error": { "code": code, "message": message } }),
+    })
+}
+
+/// Dispatch a `tools/call` through the HTTP router
+///
+/// # 4-Word Name: call_mcp_tool_via_router
+async fn call_mcp_tool_via_router(router: &Router, params: &Value) -> Result<Value, (i64, String)> {
+    let name = params.get("name").and_then(Value::as_str).unwrap_or_default();
+    let tool = MCP_TOOL_DEFINITION_TABLE
+        .iter()
+        .find(|t| t.name == name)
+        .ok_or_else(|| (JSONRPC_INVALID_PARAMS, format!("Unknown tool: {}", name)))?;
+
+    let arguments = params.get("arguments").cloned().unwrap_or(Value::Null);
+    let uri = build_tool_endpoint_uri(tool, &arguments).map_err(|e| (JSONRPC_INVALID_PARAMS, e))?;
+
+    let request = Request::builder()
+        .uri(uri)
+        .body(Body::empty())
+        .map_err(|e| (JSONRPC_INVALID_PARAMS, e.to_string()))?;
+
+    let (is_error, text) = match router.clone().oneshot(request).await {
+        Ok(response) => {
+            let is_error = !response.status().is_success();
+            match axum::body::to_bytes(response.into_body(), usize::MAX).await {
+                Ok(bytes) => (is_error, String::from_utf8_lossy(&bytes).to_string()),
+                Err(e) => (true, format!("Failed to read response body: {}", e)),
+            }
+        }
+        Err(e) => (true, format!("Router error: {}", e)),
+    };
+
+    Ok(json!({
+        "content": [{ "type": "text", "text": text }],
+        "isError": is_error,
+    }))
+}
+
+/// Run the MCP stdio loop until stdin closes
+///
+/// # 4-Word Name: run_mcp_stdio_server_loop
+///
+/// # Contract
+/// - Precondition: `state` already holds the database connection
+/// - Postcondition: returns Ok(()) on EOF; malformed lines get a parse error
+///   response with `id: null`
+pub async fn run_mcp_stdio_server_loop(state: SharedApplicationStateContainer) -> anyhow::Result<()> {
+    let router = build_complete_router_instance(state);
+    let mut lines = BufReader::new(tokio::io::stdin()).lines();
+    let mut stdout = tokio::io::stdout();
+
+    eprintln!("[MCP] parseltongue MCP server ready on stdio");
+
+    while let Some(line) = lines.next_line().await? {
+        if line.trim().is_empty() {
+            continue;
+        }
+        let response = match serde_json::from_str::<Value>(&line) {
+            Ok(message) => handle_mcp_jsonrpc_message(&router, &message).await,
+            Err(e) => Some(json!({
+                "jsonrpc": "2.0",
+                "id": Value::Null,
+                "error": { "code": JSONRPC_PARSE_ERROR, "message": format!("Parse error: {}", e) },
+            })),
+        };
+        if let Some(response) = response {
+            stdout.write_all(response.to_string().as_bytes()).await?;
+            stdout.write_all(b"\n").await?;
+            stdout.flush().await?;
+        }
+    }
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn find_tool(name: &str) -> &'static McpToolDefinitionEntry {
+        MCP_TOOL_DEFINITION_TABLE.iter().find(|t| t.name == name).unwrap()
+    }
+
+    #[test]
+    fn test_tools_list_exposes_core_tools() {
+        let listed = list_mcp_tool_definitions();
+        let names: Vec<&str> = listed["tools"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .filter_map(|t| t["name"].as_str())
+            .collect();
+        for expected in ["get_callers", "get_blast_radius", "get_context_pack"] {
+            assert!(names.contains(&expected), "missing tool {}", expected);
+        }
+        let blast = listed["tools"].as_array().unwrap().iter().find(|t| t["name"] == "get_blast_radius").unwrap();
+        assert_eq!(blast["inputSchema"]["required"], json!(["entity"]));
+    }
+
+    #[test]
+    fn test_endpoint_uri_encodes_and_requires() {
+        let tool = find_tool("get_blast_radius");
+        let uri = build_tool_endpoint_uri(tool, &json!({ "entity": "rust:fn:a b:x:T1", "hops": 2 })).unwrap();
+        assert_eq!(uri, "/blast-radius-impact-analysis?entity=rust%3Afn%3Aa%20b%3Ax%3AT1&hops=2");
+
+        assert!(build_tool_endpoint_uri(tool, &json!({ "hops": 2 })).is_err());
+        let capped_args = json!({ "entity": "k", "max_fanout": 5, "max_nodes": 50 });
+        let capped = build_tool_endpoint_uri(tool, &capped_args).unwrap();
+        assert_eq!(capped, "/blast-radius-impact-analysis?entity=k&max_fanout=5&max_nodes=50");
+        assert_eq!(
+            build_tool_endpoint_uri(find_tool("get_codebase_statistics"), &Value::Null).unwrap(),
+            "/codebase-statistics-overview-summary"
+        );
+    }
+
+    #[tokio::test]
+    async fn test_initialize_and_notifications() {
+        let router = build_complete_router_instance(SharedApplicationStateContainer::create_new_application_state());
+
+        let init = handle_mcp_jsonrpc_message(&router, &json!({ "jsonrpc": "2.0", "id": 1, "method": "initialize" }))
+            .await
+            .unwrap();
+        assert_eq!(init["result"]["protocolVersion"], MCP_PROTOCOL_VERSION_STRING);
+
+        let notification = json!({ "jsonrpc": "2.0", "method": "notifications/initialized" });
+        assert!(handle_mcp_jsonrpc_message(&router, &notification).await.is_none());
+
+        let unknown = handle_mcp_jsonrpc_message(&router, &json!({ "jsonrpc": "2.0", "id": 2, "method": "nope" }))
+            .await
+            .unwrap();
+        assert_eq!(unknown["error"]["code"], JSONRPC_METHOD_NOT_FOUND);
+    }
+
+    #[tokio::test]
+    async fn test_tool_call_returns_endpoint_body() {
+        let router = build_complete_router_instance(SharedApplicationStateContainer::create_new_application_state());
+        let call = json!({
+            "jsonrpc": "2.0", "id": 3, "method": "tools/call",
+            "params": { "name": "get_codebase_statistics", "arguments": {} },
+        });
+
+        let response = handle_mcp_jsonrpc_message(&router, &call).await.unwrap();
+        let text = response["result"]["content"][0]["text"].as_str().unwrap();
+        let body: Value = serde_json::from_str(text).unwrap();
+        assert_eq!(body["endpoint"], "/codebase-statistics-overview-summary");
+    }
+}
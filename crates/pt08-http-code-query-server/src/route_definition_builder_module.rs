@@ -4,9 +4,13 @@
 
 use axum::{
     Router,
+    middleware,
     routing::{get, post},
 };
 
+use crate::daemon_query_telemetry_recorder::{record_daemon_query_telemetry, PROMETHEUS_METRICS_ENDPOINT_PATH};
+use crate::graph_revision_etag_middleware::apply_graph_revision_etag;
+use crate::grpc_graph_query_service::{self, GRPC_SERVICE_NAME_STRING};
 use crate::http_server_startup_runner::SharedApplicationStateContainer;
 use crate::http_endpoint_handler_modules::{
     server_health_check_handler,
@@ -39,6 +43,18 @@ use crate::http_endpoint_handler_modules::{
     // v1.6.5: Diagnostics and folder discovery
     ingestion_diagnostics_coverage_handler,
     folder_structure_discovery_handler,
+    // v1.7.3: Canonical cross-language symbol IDs
+    canonical_symbol_id_resolve_handler,
+    // v1.7.3: Monorepo workspace modules
+    workspace_modules_list_handler,
+    // v1.7.3: Graph query language
+    graph_query_dsl_evaluate_handler,
+    // v1.7.3: Read-only Cypher subset
+    cypher_subset_query_handler,
+    // v1.7.3: BM25 keyword search near a seed
+    keyword_search_near_seed_handler,
+    // v1.7.3: Prometheus scrape endpoint
+    prometheus_metrics_exposition_handler,
 };
 
 /// Build the complete router with all endpoints
@@ -49,11 +65,15 @@ use crate::http_endpoint_handler_modules::{
 /// - GET /server-health-check-status
 /// - GET /codebase-statistics-overview-summary
 /// - GET /api-reference-documentation-help
+/// - GET /metrics (Prometheus text format)
 ///
 /// ## Entity Endpoints
 /// - GET /code-entities-list-all
 /// - GET /code-entity-detail-view/{*key}
 /// - GET /fuzzy-entity-search-query?q=pattern
+/// - GET /canonical-symbol-id-resolve?id=lang://module#Type::member
+/// - GET /keyword-search-near-seed?q=rate limiting&near=KEY
+/// - GET /workspace-modules-list-all
 ///
 /// ## Edge Endpoints
 /// - GET /dependency-edges-list-all
@@ -65,9 +85,14 @@ use crate::http_endpoint_handler_modules::{
 /// - GET /circular-dependency-detection-scan
 /// - GET /complexity-hotspots-ranking-view?top=N
 /// - GET /semantic-cluster-grouping-list
+/// - GET /graph-query-dsl-evaluate?q=callers(X) & in_package("api") depth 2
+/// - GET /cypher-subset-query-execute?query=MATCH (a)-[:CALLS]->(b) RETURN a.name
 ///
 /// ## Context Optimization
 /// - GET /smart-context-token-budget?focus=X&tokens=N
+///
+/// ## gRPC
+/// - POST /parseltongue.v1.GraphQuery/{GetNode,GetNeighbors,BlastRadius,PackContext,Search}
 pub fn build_complete_router_instance(state: SharedApplicationStateContainer) -> Router {
     Router::new()
         // Core endpoints
@@ -183,11 +208,50 @@ pub fn build_complete_router_instance(state: SharedApplicationStateContainer) ->
             "/folder-structure-discovery-tree",
             get(folder_structure_discovery_handler::handle_folder_structure_discovery_tree)
         )
+        // v1.7.3: Canonical cross-language symbol IDs
+        .route(
+            "/canonical-symbol-id-resolve",
+            get(canonical_symbol_id_resolve_handler::handle_canonical_symbol_id_resolve)
+        )
+        // v1.7.3: Monorepo workspace modules
+        .route(
+            "/workspace-modules-list-all",
+            get(workspace_modules_list_handler::handle_workspace_modules_list_all)
+        )
+        // v1.7.3: Graph query language
+        .route(
+            "/graph-query-dsl-evaluate",
+            get(graph_query_dsl_evaluate_handler::handle_graph_query_dsl_evaluate)
+        )
+        // v1.7.3: Read-only Cypher subset
+        .route(
+            "/cypher-subset-query-execute",
+            get(cypher_subset_query_handler::handle_cypher_subset_query_execute)
+        )
+        // v1.7.3: BM25 keyword search near a seed
+        .route(
+            "/keyword-search-near-seed",
+            get(keyword_search_near_seed_handler::handle_keyword_search_near_seed)
+        )
+        // v1.7.3: Prometheus scrape endpoint
+        .route(
+            PROMETHEUS_METRICS_ENDPOINT_PATH,
+            get(prometheus_metrics_exposition_handler::handle_prometheus_metrics_exposition_text)
+        )
+        // v1.7.3: gRPC GraphQuery service (HTTP/2)
+        .route(
+            &format!("/{}/:method", GRPC_SERVICE_NAME_STRING),
+            post(grpc_graph_query_service::handle_grpc_graph_query_call)
+        )
         // Test route for debugging
         .route(
             "/test-simple/{param}",
             get(reverse_callers_query_graph_handler::handle_reverse_callers_query_graph)
         )
         // More endpoints will be added in subsequent phases
+        // v1.7.3: ETag / If-None-Match on GET responses
+        .layer(middleware::from_fn_with_state(state.clone(), apply_graph_revision_etag))
+        // v1.7.3: Latency/status/cache metrics and query spans (outside ETag, to count 304s)
+        .layer(middleware::from_fn_with_state(state.clone(), record_daemon_query_telemetry))
         .with_state(state)
 }
@@ -0,0 +1,155 @@
+//! E2E tests for watcher reindexing of a hot-ingested daemon workspace
+//!
+//! # 4-Word Naming: e2e_daemon_workspace_reindex_tests
+//!
+//! Tests verify:
+//! - Editing a file keeps its resolved cross-file edges
+//! - Renaming a callee sends the unchanged caller's edge back to its placeholder
+//! - Two files edited before one watcher event leave the pinned-query
+//!   snapshot equal to storage
+
+use std::path::Path;
+
+use parseltongue_core::entities::{DependencyEdge, EdgeType};
+use parseltongue_core::storage::CozoDbStorage;
+use pt08_http_code_query_server::incremental_reindex_core_logic::execute_incremental_reindex_core;
+use pt08_http_code_query_server::initial_scan::execute_hot_memory_ingest_scan;
+use pt08_http_code_query_server::graph_snapshot_isolation_registry::pin_graph_snapshot_for_query;
+use pt08_http_code_query_server::SharedApplicationStateContainer;
+
+const STORE_FIXTURE_CONTENT: &str = "package store\n\nfunc Save() {}\n";
+
+const HANDLER_FIXTURE_CONTENT: &str =
+    "package api\n\nimport \"example.com/app/store\"\n\nfunc Handle() {\n\tstore.Save()\n}\n";
+
+/// Write one fixture file under the workspace
+///
+/// # 4-Word Name: write_workspace_fixture_file
+fn write_workspace_fixture_file(root: &Path, relative: &str, content: &str) {
+    let path = root.join(relative);
+    std::fs::create_dir_all(path.parent().unwrap()).unwrap();
+    std::fs::write(path, content).unwrap();
+}
+
+/// Stored `Calls` edges out of `Handle`
+///
+/// # 4-Word Name: collect_handle_call_edges
+async fn collect_handle_call_edges(state: &SharedApplicationStateContainer) -> Vec<DependencyEdge> {
+    let storage = state.database_storage_connection_arc.read().await.clone().unwrap();
+    storage
+        .get_all_dependencies()
+        .await
+        .unwrap()
+        .into_iter()
+        .filter(|e| e.edge_type == EdgeType::Calls && e.from_key.as_str().starts_with("go:fn:Handle:"))
+        .collect()
+}
+
+/// Daemon state with the Go fixture workspace hot-ingested
+///
+/// # 4-Word Name: setup_hot_ingested_workspace
+async fn setup_hot_ingested_workspace() -> (SharedApplicationStateContainer, tempfile::TempDir) {
+    let temp_dir = tempfile::TempDir::new().unwrap();
+    write_workspace_fixture_file(temp_dir.path(), "store/store.go", STORE_FIXTURE_CONTENT);
+    write_workspace_fixture_file(temp_dir.path(), "api/handler.go", HANDLER_FIXTURE_CONTENT);
+
+    let state = SharedApplicationStateContainer::create_with_database_storage(CozoDbStorage::new("mem").await.unwrap());
+    execute_hot_memory_ingest_scan(temp_dir.path(), &state).await.unwrap();
+    (state, temp_dir)
+}
+
+/// Test E2E-DAEMON-001: Editing a caller keeps its resolved cross-file call
+///
+/// # 4-Word Name: test_edited_caller_keeps_edge
+///
+/// # Acceptance Criteria
+/// WHEN the calling file is edited and reindexed by the watcher
+/// THEN its call into the other package SHALL still target `Save`, not a placeholder
+#[tokio::test]
+async fn test_edited_caller_keeps_edge() {
+    // GIVEN: Hot-ingested workspace, Handle bound to store.Save
+    let (state, temp_dir) = setup_hot_ingested_workspace().await;
+    let calls = collect_handle_call_edges(&state).await;
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    let save_key = calls[0].to_key.as_str().to_string();
+    assert!(save_key.starts_with("go:fn:Save:"), "{}", save_key);
+
+    // WHEN: The caller gains a comment and is reindexed
+    let handler = temp_dir.path().join("api/handler.go");
+    let edited = HANDLER_FIXTURE_CONTENT.replace("func Handle", "// Handle serves requests\nfunc Handle");
+    std::fs::write(&handler, edited).unwrap();
+    let result = execute_incremental_reindex_core(handler.to_str().unwrap(), &state).await.unwrap();
+
+    // THEN: The edge still reaches the stored Save
+    assert!(result.hash_changed);
+    let calls = collect_handle_call_edges(&state).await;
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    assert_eq!(calls[0].to_key.as_str(), save_key);
+}
+
+/// Test E2E-DAEMON-002: Renaming a callee unbinds the unchanged caller
+///
+/// # 4-Word Name: test_renamed_callee_unbinds_caller
+///
+/// # Acceptance Criteria
+/// WHEN only the callee's file is edited (Save renamed) and reindexed
+/// THEN the caller's edge SHALL return to the `Save` placeholder
+#[tokio::test]
+async fn test_renamed_callee_unbinds_caller() {
+    // GIVEN: Hot-ingested workspace
+    let (state, temp_dir) = setup_hot_ingested_workspace().await;
+
+    // WHEN: Save becomes Persist
+    let store = temp_dir.path().join("store/store.go");
+    std::fs::write(&store, STORE_FIXTURE_CONTENT.replace("Save", "Persist")).unwrap();
+    let result = execute_incremental_reindex_core(store.to_str().unwrap(), &state).await.unwrap();
+
+    // THEN: One entity swapped, Handle's call is unresolved again
+    assert_eq!((result.entities_added, result.entities_removed), (1, 1));
+    let calls = collect_handle_call_edges(&state).await;
+    assert_eq!(calls.len(), 1, "{:?}", calls);
+    assert!(calls[0].to_key.as_str().contains("unresolved-reference"), "{:?}", calls[0]);
+}
+
+/// Test E2E-DAEMON-003: One event for two edited files refreshes the snapshot
+///
+/// # 4-Word Name: test_multi_file_event_refreshes_snapshot
+///
+/// # Acceptance Criteria
+/// WHEN both files are edited and only the callee's file raises an event
+/// THEN the snapshot pinned by later queries SHALL hold the stored edges,
+/// the other file's rewritten edges included
+#[tokio::test]
+async fn test_multi_file_event_refreshes_snapshot() {
+    // GIVEN: Hot-ingested workspace with a published snapshot
+    let (state, temp_dir) = setup_hot_ingested_workspace().await;
+    let pinned = pin_graph_snapshot_for_query(&state).await.unwrap();
+
+    // WHEN: Save becomes Persist, Handle becomes Serve calling it; one event
+    let store = temp_dir.path().join("store/store.go");
+    std::fs::write(&store, STORE_FIXTURE_CONTENT.replace("Save", "Persist")).unwrap();
+    let handler = HANDLER_FIXTURE_CONTENT.replace("Save", "Persist").replace("Handle", "Serve");
+    write_workspace_fixture_file(temp_dir.path(), "api/handler.go", &handler);
+    execute_incremental_reindex_core(store.to_str().unwrap(), &state).await.unwrap();
+
+    // THEN: The new snapshot is the stored graph; the pinned one is untouched
+    let latest = pin_graph_snapshot_for_query(&state).await.unwrap();
+    assert_eq!(latest.revision, state.current_graph_revision_value());
+    let edge_pairs = |edges: Vec<&DependencyEdge>| {
+        let mut pairs: Vec<(String, String)> =
+            edges.into_iter().map(|e| (e.from_key.to_string(), e.to_key.to_string())).collect();
+        pairs.sort();
+        pairs
+    };
+    let storage = state.database_storage_connection_arc.read().await.clone().unwrap();
+    let stored = storage.get_all_dependencies().await.unwrap();
+    let snapshot_pairs = edge_pairs(latest.incoming_edges_with_prefix("").collect());
+    assert_eq!(snapshot_pairs, edge_pairs(stored.iter().collect()));
+    assert!(
+        snapshot_pairs.iter().any(|(from, to)| from.starts_with("go:fn:Serve:") && to.starts_with("go:fn:Persist:")),
+        "{:?}",
+        snapshot_pairs
+    );
+    assert!(snapshot_pairs.iter().all(|(from, _)| !from.starts_with("go:fn:Handle:")));
+    assert!(pinned.incoming_edges_with_prefix("go:fn:Save:").any(|e| e.from_key.as_str().starts_with("go:fn:Handle:")));
+}
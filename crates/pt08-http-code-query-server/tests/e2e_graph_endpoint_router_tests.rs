@@ -0,0 +1,492 @@
+//! Router-level tests for graph lookup endpoints
+//!
+//! # 4-Word Naming: e2e_graph_endpoint_router_tests
+//!
+//! ## Test Coverage
+//! - Canonical symbol ID resolve: canonical IDs, raw qualified names, bad requests
+//! - Entity detail view carries the entity's canonical ID
+//! - Workspace modules list: per-module counts, scopes, unowned entities
+//! - Graph query DSL: traversal plus filters, syntax and unknown-symbol 400s
+//! - Cypher subset: MATCH/WHERE/RETURN round trip, write and syntax 400s
+//! - Keyword search: BM25 ranking, seed proximity reordering, unknown seed 404
+//! - Prometheus metrics: graph gauges, per-route counters, ETag cache hits
+//! - Traversal limits: blast radius and callers honour max_fanout / max_nodes
+//!
+//! Every test drives `build_complete_router_instance` over an in-memory graph,
+//! so routing, query parsing and the middleware layers are exercised too.
+
+use axum::{
+    body::Body,
+    http::{Request, StatusCode},
+};
+use std::path::PathBuf;
+use tower::ServiceExt;
+
+use parseltongue_core::entities::{
+    CodeEntity, DependencyEdge, EdgeType, EntityClass, EntityType, InterfaceSignature, LanguageSpecificSignature,
+    LineRange, RustSignature, Visibility,
+};
+use parseltongue_core::storage::CozoDbStorage;
+use pt08_http_code_query_server::{build_complete_router_instance, SharedApplicationStateContainer};
+
+/// Stored entity with source code (test fixture)
+///
+/// # 4-Word Name: create_stored_test_entity
+fn create_stored_test_entity(key: &str, name: &str, entity_type: EntityType, file: &str, code: &str) -> CodeEntity {
+    let signature = InterfaceSignature {
+        entity_type,
+        name: name.to_string(),
+        visibility: Visibility::Public,
+        file_path: PathBuf::from(file),
+        line_range: LineRange::new(1, 3).unwrap(),
+        module_path: vec![],
+        documentation: None,
+        language_specific: LanguageSpecificSignature::Rust(RustSignature {
+            generics: vec![],
+            lifetimes: vec![],
+            where_clauses: vec![],
+            attributes: vec![],
+            trait_impl: None,
+        }),
+    };
+    let mut entity = CodeEntity::new(key.to_string(), signature, EntityClass::CodeImplementation).unwrap();
+    entity.current_code = Some(code.to_string());
+    entity.future_code = Some(code.to_string());
+    entity
+}
+
+/// Go method, Go function of the same name elsewhere, Rust function
+///
+/// # 4-Word Name: create_login_symbol_fixture
+fn create_login_symbol_fixture() -> Vec<CodeEntity> {
+    vec![
+        create_stored_test_entity(
+            "go:method:Login:__internal_auth_service:T1",
+            "Login",
+            EntityType::Method,
+            "internal/auth/service.go",
+            "func (s *Service) Login(user string) error {\n\treturn nil\n}",
+        ),
+        create_stored_test_entity(
+            "go:fn:Login:__internal_admin_login:T2",
+            "Login",
+            EntityType::Function,
+            "internal/admin/login.go",
+            "func Login() {}",
+        ),
+        create_stored_test_entity(
+            "rust:fn:login:__src_auth:T3",
+            "login",
+            EntityType::Function,
+            "src/auth.rs",
+            "pub fn login() {}",
+        ),
+    ]
+}
+
+/// Resolved Calls edge between two stored keys (test fixture)
+///
+/// # 4-Word Name: create_calls_test_edge
+fn create_calls_test_edge(from: &str, to: &str) -> DependencyEdge {
+    DependencyEdge::builder().from_key(from).to_key(to).edge_type(EdgeType::Calls).build().unwrap()
+}
+
+/// Router over an in-memory graph holding `entities` and `edges`
+///
+/// # 4-Word Name: build_router_with_graph
+async fn build_router_with_graph(entities: &[CodeEntity], edges: &[DependencyEdge]) -> axum::Router {
+    let storage = CozoDbStorage::new("mem").await.unwrap();
+    storage.create_schema().await.unwrap();
+    storage.create_dependency_edges_schema().await.unwrap();
+    storage.create_entity_extractor_metadata_schema().await.unwrap();
+    storage.insert_entities_batch(entities).await.unwrap();
+    storage.upsert_entity_extractor_metadata_batch(entities).await.unwrap();
+    storage.insert_edges_batch(edges).await.unwrap();
+    build_complete_router_instance(SharedApplicationStateContainer::create_with_database_storage(storage))
+}
+
+/// GET `uri`, returning status and body text
+///
+/// # 4-Word Name: send_get_request_text
+async fn send_get_request_text(app: &axum::Router, uri: &str) -> (StatusCode, String) {
+    let response = app
+        .clone()
+        .oneshot(Request::builder().uri(uri).body(Body::empty()).unwrap())
+        .await
+        .unwrap();
+    let status = response.status();
+    let body = axum::body::to_bytes(response.into_body(), usize::MAX).await.unwrap();
+    (status, String::from_utf8_lossy(&body).to_string())
+}
+
+/// GET `uri`, returning status and JSON body
+///
+/// # 4-Word Name: send_get_request_json
+async fn send_get_request_json(app: &axum::Router, uri: &str) -> (StatusCode, serde_json::Value) {
+    let (status, body) = send_get_request_text(app, uri).await;
+    let json = serde_json::from_str(&body).unwrap_or_else(|e| panic!("{} is not JSON ({}): {}", uri, e, body));
+    (status, json)
+}
+
+/// Keys of the entities a resolve response matched
+fn resolved_entity_keys(body: &serde_json::Value) -> Vec<String> {
+    body["data"]["entities"]
+        .as_array()
+        .unwrap()
+        .iter()
+        .map(|entity| entity["key"].as_str().unwrap().to_string())
+        .collect()
+}
+
+#[tokio::test]
+async fn test_canonical_id_resolves_go_method_by_receiver() {
+    let app = build_router_with_graph(&create_login_symbol_fixture(), &[]).await;
+
+    let uri = format!(
+        "/canonical-symbol-id-resolve?id={}",
+        urlencoding::encode("go://internal/auth#Service::Login")
+    );
+    let (status, body) = send_get_request_json(&app, &uri).await;
+
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["canonical_id"], "go://internal/auth#Service::Login");
+    assert_eq!(resolved_entity_keys(&body), vec!["go:method:Login:__internal_auth_service:T1"]);
+    assert_eq!(body["data"]["entities"][0]["canonical_id"], "go://internal/auth#Service::Login");
+}
+
+#[tokio::test]
+async fn test_canonical_id_matches_import_path_and_bare_name() {
+    let app = build_router_with_graph(&create_login_symbol_fixture(), &[]).await;
+
+    // Import path ending in the repo-relative package directory
+    let import_path = format!(
+        "/canonical-symbol-id-resolve?id={}",
+        urlencoding::encode("go://github.com/acme/app/internal/auth#Service::Login")
+    );
+    let (_, body) = send_get_request_json(&app, &import_path).await;
+    assert_eq!(resolved_entity_keys(&body), vec!["go:method:Login:__internal_auth_service:T1"]);
+
+    // No module and no receiver: every Go `Login`, never the Rust `login`
+    let bare = format!("/canonical-symbol-id-resolve?id={}", urlencoding::encode("go://#Login"));
+    let (_, body) = send_get_request_json(&app, &bare).await;
+    assert_eq!(
+        resolved_entity_keys(&body),
+        vec!["go:fn:Login:__internal_admin_login:T2", "go:method:Login:__internal_auth_service:T1"]
+    );
+    assert_eq!(body["data"]["total_count"], 2);
+}
+
+#[tokio::test]
+async fn test_raw_qualified_symbol_needs_language() {
+    let app = build_router_with_graph(&create_login_symbol_fixture(), &[]).await;
+
+    let uri = format!(
+        "/canonical-symbol-id-resolve?symbol={}&language=rust",
+        urlencoding::encode("crate::auth::login")
+    );
+    let (status, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["canonical_id"], "rust://auth#login");
+    assert_eq!(resolved_entity_keys(&body), vec!["rust:fn:login:__src_auth:T3"]);
+
+    for bad in [
+        "/canonical-symbol-id-resolve?symbol=crate::auth::login",
+        "/canonical-symbol-id-resolve?id=internal/auth",
+        "/canonical-symbol-id-resolve",
+    ] {
+        let (status, body) = send_get_request_json(&app, bad).await;
+        assert_eq!(status, StatusCode::BAD_REQUEST, "{} → {}", bad, body);
+        assert_eq!(body["success"], false);
+    }
+}
+
+#[tokio::test]
+async fn test_entity_detail_view_exposes_canonical_id() {
+    let app = build_router_with_graph(&create_login_symbol_fixture(), &[]).await;
+
+    let uri = format!(
+        "/code-entity-detail-view?key={}",
+        urlencoding::encode("go:method:Login:__internal_auth_service:T1")
+    );
+    let (status, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["canonical_id"], "go://internal/auth#Service::Login");
+
+    let uri = format!("/code-entity-detail-view?key={}", urlencoding::encode("rust:fn:login:__src_auth:T3"));
+    let (_, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(body["data"]["canonical_id"], "rust://src/auth#login");
+}
+
+#[tokio::test]
+async fn test_workspace_modules_list_counts_and_scopes() {
+    let mut entities = vec![
+        create_stored_test_entity("go:fn:Charge:__services_billing_charge:T1", "Charge",
+            EntityType::Function, "services/billing/charge.go", "func Charge() {}"),
+        create_stored_test_entity("go:fn:Refund:__services_billing_refund:T2", "Refund",
+            EntityType::Function, "services/billing/refund.go", "func Refund() {}"),
+        create_stored_test_entity("rust:fn:main:__tools_gen_main:T3", "main",
+            EntityType::Function, "tools/gen/src/main.rs", "fn main() {}"),
+        create_stored_test_entity("rust:fn:helper:__scripts_helper:T4", "helper",
+            EntityType::Function, "scripts/helper.rs", "fn helper() {}"),
+    ];
+    for (entity, (module, kind, root)) in entities.iter_mut().zip([
+        ("example.com/billing", "go", "services/billing"),
+        ("example.com/billing", "go", "services/billing"),
+        ("gen", "cargo", "tools/gen"),
+    ]) {
+        entity.metadata.additional.insert("workspace_module".to_string(), module.to_string());
+        entity.metadata.additional.insert("workspace_module_kind".to_string(), kind.to_string());
+        entity.metadata.additional.insert("workspace_module_root".to_string(), root.to_string());
+    }
+    let app = build_router_with_graph(&entities, &[]).await;
+
+    let (status, body) = send_get_request_json(&app, "/workspace-modules-list-all").await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["total_count"], 2);
+    assert_eq!(body["data"]["unowned_entity_count"], 1, "scripts/helper.rs belongs to no module");
+    let modules = body["data"]["modules"].as_array().unwrap();
+    assert_eq!(modules[0]["name"], "example.com/billing");
+    assert_eq!(modules[0]["root_dir"], "services/billing");
+    assert_eq!(modules[0]["entity_count"], 2);
+    assert_eq!(modules[0]["scope"], "services||billing");
+    assert_eq!(modules[1]["kind"], "cargo");
+    assert_eq!(modules[1]["scope"], "tools||gen");
+}
+
+#[tokio::test]
+async fn test_graph_query_dsl_traverses_and_filters() {
+    let entities = vec![
+        create_stored_test_entity("go:fn:Save:__store_store:T1", "Save",
+            EntityType::Function, "store/store.go", "func Save() {}"),
+        create_stored_test_entity("go:fn:Handle:__api_handler:T2", "Handle",
+            EntityType::Function, "api/handler.go", "func Handle() { Save() }"),
+        create_stored_test_entity("go:fn:routes:__api_routes:T3", "routes",
+            EntityType::Function, "api/routes.go", "func routes() { Handle() }"),
+        create_stored_test_entity("go:fn:main:__cmd_main:T4", "main",
+            EntityType::Function, "cmd/main.go", "func main() { Save() }"),
+    ];
+    let edges = vec![
+        create_calls_test_edge("go:fn:Handle:__api_handler:T2", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:routes:__api_routes:T3", "go:fn:Handle:__api_handler:T2"),
+        create_calls_test_edge("go:fn:main:__cmd_main:T4", "go:fn:Save:__store_store:T1"),
+    ];
+    let app = build_router_with_graph(&entities, &edges).await;
+    let query_uri = |q: &str| format!("/graph-query-dsl-evaluate?q={}", urlencoding::encode(q));
+    let match_keys = |body: &serde_json::Value| -> Vec<String> {
+        body["data"]["matches"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .map(|entry| entry["key"].as_str().unwrap().to_string())
+            .collect()
+    };
+
+    let (status, body) = send_get_request_json(&app, &query_uri("callers(Save) & in_package(\"api\")")).await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(match_keys(&body), vec!["go:fn:Handle:__api_handler:T2"]);
+
+    let (_, body) = send_get_request_json(&app, &query_uri("callers(Save) - in_package(cmd) depth 2")).await;
+    assert_eq!(match_keys(&body), vec!["go:fn:Handle:__api_handler:T2", "go:fn:routes:__api_routes:T3"]);
+    assert_eq!(body["data"]["depth"], 2);
+
+    let (_, body) = send_get_request_json(&app, &query_uri("callers(Save) limit 1")).await;
+    assert_eq!(body["data"]["total_count"], 2);
+    assert_eq!(body["data"]["truncated"], true);
+    assert_eq!(match_keys(&body).len(), 1);
+
+    for bad in ["callers(Save) depth", "callers(Missing)", "frobnicate(x)"] {
+        let (status, body) = send_get_request_json(&app, &query_uri(bad)).await;
+        assert_eq!(status, StatusCode::BAD_REQUEST, "{} → {}", bad, body);
+        assert_eq!(body["success"], false);
+        assert!(!body["error"].as_str().unwrap().is_empty());
+    }
+}
+
+#[tokio::test]
+async fn test_cypher_subset_query_round_trip() {
+    let entities = vec![
+        create_stored_test_entity("go:fn:Save:__store_store:T1", "Save",
+            EntityType::Function, "store/store.go", "func Save() {}"),
+        create_stored_test_entity("go:fn:Handle:__api_handler:T2", "Handle",
+            EntityType::Function, "api/handler.go", "func Handle() { Save() }"),
+        create_stored_test_entity("go:fn:main:__cmd_main:T3", "main",
+            EntityType::Function, "cmd/main.go", "func main() { Save(); Handle() }"),
+    ];
+    let edges = vec![
+        create_calls_test_edge("go:fn:Handle:__api_handler:T2", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:main:__cmd_main:T3", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:main:__cmd_main:T3", "go:fn:Handle:__api_handler:T2"),
+    ];
+    let app = build_router_with_graph(&entities, &edges).await;
+    let query_uri = |query: &str| format!("/cypher-subset-query-execute?query={}", urlencoding::encode(query));
+
+    let (status, body) = send_get_request_json(
+        &app,
+        &query_uri(
+            "MATCH (c:Function)-[:CALLS]->(t {name: 'Save'}) WHERE c.file STARTS WITH 'api/' OR c.name = 'main' \
+             RETURN c.name AS caller, c.file ORDER BY caller",
+        ),
+    )
+    .await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["columns"], serde_json::json!(["caller", "c.file"]));
+    assert_eq!(
+        body["data"]["rows"],
+        serde_json::json!([["Handle", "api/handler.go"], ["main", "cmd/main.go"]])
+    );
+
+    let grouped = query_uri("MATCH (c)-[:CALLS]->(t) RETURN t.name, count(c) AS n ORDER BY n DESC LIMIT 1");
+    let (_, body) = send_get_request_json(&app, &grouped).await;
+    assert_eq!(body["data"]["rows"], serde_json::json!([["Save", 2]]));
+    assert_eq!(body["data"]["total_count"], 2);
+    assert_eq!(body["data"]["truncated"], true);
+
+    for bad in [
+        "MATCH (n) DETACH DELETE n",
+        "MATCH (n)-[:FLOWS_TO]->(m) RETURN n",
+        "MATCH (n RETURN n",
+    ] {
+        let (status, body) = send_get_request_json(&app, &query_uri(bad)).await;
+        assert_eq!(status, StatusCode::BAD_REQUEST, "{} → {}", bad, body);
+        assert_eq!(body["success"], false);
+    }
+}
+
+#[tokio::test]
+async fn test_keyword_search_prefers_seed_neighbourhood() {
+    let mut entities = vec![
+        create_stored_test_entity("go:fn:NewRateLimiter:__mw:T1", "NewRateLimiter",
+            EntityType::Function, "mw/limiter.go", "func NewRateLimiter() {}"),
+        create_stored_test_entity("go:fn:HandleLogin:__api:T2", "HandleLogin",
+            EntityType::Function, "api/login.go", "func HandleLogin() { allow(\"login rate limited\") }"),
+        create_stored_test_entity("go:fn:ParseConfig:__cfg:T3", "ParseConfig",
+            EntityType::Function, "cfg/config.go", "func ParseConfig() { load() }"),
+        create_stored_test_entity("go:fn:throttle:__jobs:T4", "throttle",
+            EntityType::Function, "jobs/throttle.go", "func throttle() {}"),
+    ];
+    entities[0].interface_signature.documentation = Some("NewRateLimiter caps requests per client.".to_string());
+    entities[3].interface_signature.documentation = Some("throttle limits job rate".to_string());
+    let edges = vec![create_calls_test_edge("go:fn:HandleLogin:__api:T2", "go:fn:throttle:__jobs:T4")];
+    let app = build_router_with_graph(&entities, &edges).await;
+    let hit_keys = |body: &serde_json::Value| -> Vec<String> {
+        body["data"]["results"]
+            .as_array()
+            .unwrap()
+            .iter()
+            .map(|hit| hit["entity_key"].as_str().unwrap().to_string())
+            .collect()
+    };
+
+    let (status, body) = send_get_request_json(&app, "/keyword-search-near-seed?q=rate%20limit").await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["indexed_symbols"], 4);
+    assert_eq!(hit_keys(&body).first().map(String::as_str), Some("go:fn:NewRateLimiter:__mw:T1"));
+    assert!(!hit_keys(&body).contains(&"go:fn:ParseConfig:__cfg:T3".to_string()));
+
+    let near = format!(
+        "/keyword-search-near-seed?q=rate%20limit&near={}&proximity_weight=0.6",
+        urlencoding::encode("go:fn:HandleLogin:__api:T2")
+    );
+    let (_, body) = send_get_request_json(&app, &near).await;
+    assert_eq!(hit_keys(&body)[..2], ["go:fn:HandleLogin:__api:T2", "go:fn:throttle:__jobs:T4"]);
+    assert_eq!(body["data"]["results"][1]["graph_distance"], 1);
+    assert_eq!(body["data"]["near"], "go:fn:HandleLogin:__api:T2");
+
+    let (_, body) = send_get_request_json(&app, &format!("{}&limit=1", near)).await;
+    assert_eq!(hit_keys(&body), vec!["go:fn:HandleLogin:__api:T2"]);
+
+    let unknown = format!("/keyword-search-near-seed?q=rate&near={}", urlencoding::encode("go:fn:Gone:__x:T9"));
+    let (status, body) = send_get_request_json(&app, &unknown).await;
+    assert_eq!(status, StatusCode::NOT_FOUND, "{}", body);
+    assert_eq!(body["success"], false);
+}
+
+#[tokio::test]
+async fn test_metrics_count_routes_statuses_and_cache_hits() {
+    let entities = create_login_symbol_fixture();
+    let edges =
+        vec![create_calls_test_edge("go:fn:Login:__internal_admin_login:T2", "go:method:Login:__internal_auth_service:T1")];
+    let app = build_router_with_graph(&entities, &edges).await;
+    let detail = format!("/code-entity-detail-view?key={}", urlencoding::encode("rust:fn:login:__src_auth:T3"));
+
+    let first = app.clone().oneshot(Request::builder().uri(&detail).body(Body::empty()).unwrap()).await.unwrap();
+    assert_eq!(first.status(), StatusCode::OK);
+    let etag = first.headers()["etag"].to_str().unwrap().to_string();
+    let repeat = Request::builder().uri(&detail).header("if-none-match", &etag).body(Body::empty()).unwrap();
+    assert_eq!(app.clone().oneshot(repeat).await.unwrap().status(), StatusCode::NOT_MODIFIED);
+    let (status, _) = send_get_request_text(&app, "/no-such-endpoint-here").await;
+    assert_eq!(status, StatusCode::NOT_FOUND);
+
+    let scrape = app.clone().oneshot(Request::builder().uri("/metrics").body(Body::empty()).unwrap()).await.unwrap();
+    assert_eq!(scrape.status(), StatusCode::OK);
+    assert!(scrape.headers()["content-type"].to_str().unwrap().starts_with("text/plain; version=0.0.4"));
+    let text = String::from_utf8(axum::body::to_bytes(scrape.into_body(), usize::MAX).await.unwrap().to_vec()).unwrap();
+    let detail_series = "method=\"GET\",route=\"/code-entity-detail-view\"";
+    for expected in [
+        "parseltongue_graph_entities 3\n".to_string(),
+        "parseltongue_graph_edges 1\n".to_string(),
+        format!("parseltongue_http_requests_total{{{},status=\"200\"}} 1\n", detail_series),
+        format!("parseltongue_http_requests_total{{{},status=\"304\"}} 1\n", detail_series),
+        "parseltongue_http_requests_total{method=\"GET\",route=\"unmatched\",status=\"404\"} 1\n".to_string(),
+        format!("parseltongue_http_request_duration_seconds_count{{{}}} 2\n", detail_series),
+        "parseltongue_http_cache_lookups_total 1\n".to_string(),
+        "parseltongue_http_cache_hits_total 1\n".to_string(),
+    ] {
+        assert!(text.contains(&expected), "missing {:?} in\n{}", expected, text);
+    }
+    assert!(!text.contains("route=\"/metrics\""), "the scrape must not measure itself:\n{}", text);
+}
+
+#[tokio::test]
+async fn test_traversal_limits_cap_blast_radius_and_callers() {
+    let entities = vec![
+        create_stored_test_entity("go:fn:Save:__store_store:T1", "Save",
+            EntityType::Function, "store/store.go", "func Save() {}"),
+        create_stored_test_entity("go:fn:Handle:__api_handler:T2", "Handle",
+            EntityType::Function, "api/handler.go", "func Handle() { Save() }"),
+        create_stored_test_entity("go:fn:Worker:__jobs_worker:T3", "Worker",
+            EntityType::Function, "jobs/worker.go", "func Worker() { Save() }"),
+        create_stored_test_entity("go:fn:main:__cmd_main:T4", "main",
+            EntityType::Function, "cmd/main.go", "func main() { Save() }"),
+        create_stored_test_entity("go:fn:routes:__api_routes:T5", "routes",
+            EntityType::Function, "api/routes.go", "func routes() { Handle() }"),
+    ];
+    let edges = vec![
+        create_calls_test_edge("go:fn:Handle:__api_handler:T2", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:Worker:__jobs_worker:T3", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:main:__cmd_main:T4", "go:fn:Save:__store_store:T1"),
+        create_calls_test_edge("go:fn:routes:__api_routes:T5", "go:fn:Handle:__api_handler:T2"),
+    ];
+    let app = build_router_with_graph(&entities, &edges).await;
+    let save = urlencoding::encode("go:fn:Save:__store_store:T1");
+
+    let uri = format!("/blast-radius-impact-analysis?entity={}&hops=1&max_fanout=2", save);
+    let (status, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    let data = &body["data"];
+    assert_eq!(
+        data["by_hop"][0]["entities"],
+        serde_json::json!(["go:fn:Handle:__api_handler:T2", "go:fn:Worker:__jobs_worker:T3"])
+    );
+    assert_eq!(data["truncation"]["truncated"], true);
+    assert_eq!(data["truncation"]["fanout_capped"][0]["entity_key"], "go:fn:Save:__store_store:T1");
+    assert_eq!(data["truncation"]["fanout_capped"][0]["shown_count"], 2);
+    assert_eq!(data["truncation"]["fanout_capped"][0]["total_count"], 3);
+    assert_eq!(data["truncation"]["beyond_depth_count"], 1);
+
+    let uri = format!("/blast-radius-impact-analysis?entity={}&hops=2&max_nodes=1", save);
+    let (_, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(body["data"]["total_affected"], 1);
+    assert_eq!(body["data"]["truncation"]["nodes_omitted"], 3);
+
+    let (_, body) = send_get_request_json(&app, &format!("/blast-radius-impact-analysis?entity={}", save)).await;
+    assert_eq!(body["data"]["total_affected"], 4);
+    assert_eq!(body["data"]["truncation"]["truncated"], false);
+
+    let uri = format!("/reverse-callers-query-graph?entity={}&max_fanout=2", save);
+    let (status, body) = send_get_request_json(&app, &uri).await;
+    assert_eq!(status, StatusCode::OK, "{}", body);
+    assert_eq!(body["data"]["total_count"], 3);
+    assert_eq!(body["data"]["callers"].as_array().unwrap().len(), 2);
+    assert_eq!(body["data"]["truncation"]["fanout_capped"][0]["total_count"], 3);
+}
@@ -5,27 +5,55 @@
 ; LEGACY PATTERNS (v0.9.0 - KEEP)
 ; ============================================================================
 
-; Function calls
+; Function calls (explicit generic instantiations handled in PATTERN D)
 (call_expression
-  function: (identifier) @reference.call) @dependency.call
+  function: (identifier) @reference.call
+  !type_arguments) @dependency.call
 
 ; Method calls (receiver.Method())
+; Operand recorded as `receiver_expr` edge metadata (promoted method resolution)
 (call_expression
   function: (selector_expression
-    field: (field_identifier) @reference.method_call)) @dependency.method_call
+    operand: (_) @call_receiver.method_call
+    field: (field_identifier) @reference.method_call)
+  !type_arguments) @dependency.method_call
 
 ; Import statements
 (import_spec
   path: (interpreted_string_literal) @reference.import) @dependency.import
 
-; Type embedding (struct composition)
-(type_declaration
-  (type_spec
-    name: (type_identifier) @definition.type
-    type: (struct_type
-      (field_declaration_list
-        (field_declaration
-          type: (type_identifier) @reference.embeds))))) @dependency.embeds
+; Type embedding (struct composition) - embedded fields have no name.
+; The grammar keeps `*` as an anonymous token, so `*Logger` also matches here.
+(struct_type
+  (field_declaration_list
+    (field_declaration
+      !name
+      type: (type_identifier) @reference.embeds) @dependency.embeds))
+
+; Embedded qualified type: struct { io.Reader } / struct { *sync.Mutex }
+(struct_type
+  (field_declaration_list
+    (field_declaration
+      !name
+      type: (qualified_type name: (type_identifier) @reference.embeds_qualified)) @dependency.embeds))
+
+; Embedded generic type: struct { Base[T] }
+(struct_type
+  (field_declaration_list
+    (field_declaration
+      !name
+      type: (generic_type type: (type_identifier) @reference.embeds_generic)) @dependency.embeds))
+
+; Interface embedding: interface { io.Reader; Closer }
+; Constraint unions (int | string) are not embeddings - skip predeclared types
+(interface_type
+  (type_elem
+    (type_identifier) @reference.embeds_interface
+    (#not-match? @reference.embeds_interface "^(any|bool|byte|comparable|complex64|complex128|float32|float64|int|int8|int16|int32|int64|rune|string|uint|uint8|uint16|uint32|uint64|uintptr)$")) @dependency.embeds)
+
+(interface_type
+  (type_elem
+    (qualified_type name: (type_identifier) @reference.embeds_interface_qualified)) @dependency.embeds)
 
 ; ============================================================================
 ; PATTERN A: Composite Literals (v1.4.9) - Go's Constructor Equivalent
@@ -85,6 +113,7 @@
 ; ============================================================================
 
 ; Goroutine launch: go processData()
+; Emitted as Spawns; the plain call patterns above add the Calls edge too
 (go_statement
   (call_expression
     function: (identifier) @reference.goroutine_call)) @dependency.goroutine_call
@@ -93,8 +122,46 @@
 (go_statement
   (call_expression
     function: (selector_expression
+      operand: (_) @call_receiver.goroutine_method
       field: (field_identifier) @reference.goroutine_method))) @dependency.goroutine_call
 
+; ============================================================================
+; PATTERN D: Generic Instantiation (Go 1.18+)
+; ============================================================================
+
+; Explicit instantiation with several type args: Map[int, string](xs, f)
+(call_expression
+  function: (identifier) @reference.generic_call
+  type_arguments: (type_arguments) @type_arguments.generic_call) @dependency.generic_call
+
+; Qualified instantiation: slices.Map[int, string](xs, f)
+(call_expression
+  function: (selector_expression
+    field: (field_identifier) @reference.generic_method_call)
+  type_arguments: (type_arguments) @type_arguments.generic_method_call) @dependency.generic_method_call
+
+; Single type arg is ambiguous with indexing, so tree-sitter emits an
+; index_expression: Map[int](xs). Only accept type-looking indices
+; (predeclared types or exported identifiers) to avoid handlers[i](x).
+(call_expression
+  function: (index_expression
+    operand: (identifier) @reference.generic_call
+    index: (identifier) @type_arguments.generic_call)
+  (#match? @type_arguments.generic_call "^(any|bool|byte|comparable|complex64|complex128|error|float32|float64|int|int8|int16|int32|int64|rune|string|uint|uint8|uint16|uint32|uint64|uintptr|[A-Z][A-Za-z0-9_]*)$")) @dependency.generic_call
+
+; Generic struct literal: Box[int]{Value: 1}
+(composite_literal
+  type: (generic_type
+    type: (type_identifier) @reference.composite_generic
+    type_arguments: (type_arguments) @type_arguments.composite_generic)) @dependency.constructor
+
+; Qualified generic struct literal: container.Box[int]{}
+(composite_literal
+  type: (generic_type
+    type: (qualified_type
+      name: (type_identifier) @reference.composite_generic_qualified)
+    type_arguments: (type_arguments) @type_arguments.composite_generic_qualified)) @dependency.constructor
+
 ; ============================================================================
 ; NOTES
 ; ============================================================================
@@ -109,7 +176,12 @@
 ; - @dependency.call → EdgeType::Calls
 ; - @dependency.method_call → EdgeType::Calls
 ; - @dependency.import → EdgeType::Uses
-; - @dependency.embeds → EdgeType::Implements
+; - @dependency.embeds → EdgeType::Embeds (promoted methods resolved cross-file)
 ; - @dependency.constructor → EdgeType::Calls (composite literals)
 ; - @dependency.field_access → EdgeType::Uses
 ; - @dependency.goroutine_call → EdgeType::Calls
+; - @dependency.generic_call → EdgeType::Calls (type args in edge metadata)
+;
+; @type_arguments.* captures are not references: their text is recorded as
+; the `type_arguments` edge metadata entry (brackets stripped).
+; @call_receiver.* captures record a plain identifier operand as `receiver_expr`.
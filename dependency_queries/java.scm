@@ -26,6 +26,46 @@
     (type_list
       (type_identifier) @reference.implements))) @dependency.implements
 
+; ============================================================================
+; TYPE HIERARCHY (v1.7.3)
+; ============================================================================
+
+; Generic superclass: class Repo extends Base<User>
+(class_declaration
+  name: (identifier) @definition.class
+  (superclass
+    (generic_type
+      (type_identifier) @reference.extends_generic))) @dependency.extends_generic
+
+; Generic interface: class User implements Comparable<User>
+(class_declaration
+  name: (identifier) @definition.class
+  (super_interfaces
+    (type_list
+      (generic_type
+        (type_identifier) @reference.implements_generic)))) @dependency.implements_generic
+
+; Interface extension: interface Repo extends Closeable, Iterable<T>
+(interface_declaration
+  name: (identifier) @definition.interface
+  (extends_interfaces
+    (type_list
+      (type_identifier) @reference.extends_interface))) @dependency.extends_interface
+
+(interface_declaration
+  name: (identifier) @definition.interface
+  (extends_interfaces
+    (type_list
+      (generic_type
+        (type_identifier) @reference.extends_interface_generic)))) @dependency.extends_interface_generic
+
+; Enum implementing interfaces: enum Color implements Labeled
+(enum_declaration
+  name: (identifier) @definition.enum
+  (super_interfaces
+    (type_list
+      (type_identifier) @reference.implements_enum))) @dependency.implements_enum
+
 ; ============================================================================
 ; PATTERN A: Constructor Calls (v1.4.9)
 ; ============================================================================
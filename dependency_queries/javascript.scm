@@ -9,18 +9,18 @@
 (call_expression
   function: (identifier) @reference.call) @dependency.call
 
-; Method calls
+; Method calls (receiver kept for namespace-import / this resolution)
 (call_expression
   function: (member_expression
+    object: (_) @call_receiver.method_call
     property: (property_identifier) @reference.method_call)) @dependency.method_call
 
 ; Import statements
 (import_statement
   source: (string) @reference.import) @dependency.import
 
-(import_statement
-  (import_clause
-    (identifier) @reference.import_name)) @dependency.import_name
+; Import bindings (default/named/namespace) and CommonJS destructuring are
+; emitted by the extractor's module-binding pass (v1.7.3)
 
 ; Require (CommonJS)
 (call_expression
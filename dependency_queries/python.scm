@@ -29,6 +29,12 @@
     name: (dotted_name
       (identifier) @reference.import_alias_name))) @dependency.import_from_alias
 
+; Relative import: from .models import User / from . import utils
+(import_from_statement
+  module_name: (relative_import) @reference.import_relative_module
+  name: (dotted_name
+    (identifier) @reference.import_relative_name)) @dependency.import_from_relative
+
 ; Import with alias: import numpy as np
 (import_statement
   name: (aliased_import
@@ -43,8 +49,10 @@
   function: (identifier) @reference.call) @dependency.call
 
 ; Method call: obj.method()
+; call_receiver records the operand (`self` in self.save()) for resolution
 (call
   function: (attribute
+    object: (_) @call_receiver.method_call
     attribute: (identifier) @reference.method_call)) @dependency.method_call
 
 ; Chained method call: obj.method1().method2()
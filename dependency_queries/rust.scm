@@ -72,13 +72,8 @@
 ; ============================================================================
 
 ; impl Trait for Struct
-(impl_item
-  trait: [
-    (type_identifier) @reference.impl_trait
-    (scoped_type_identifier
-      name: (type_identifier) @reference.impl_trait_scoped)
-  ]
-  type: (type_identifier) @definition.impl_type) @dependency.implements
+; v1.7.3: emitted by rust_trait_impl_bound_extractor (generic and scoped
+; forms, impl_type metadata), together with Bound edges for trait bounds
 
 ; impl Struct (inherent impl, no trait)
 ; These create structural relationships but not trait dependencies
@@ -172,7 +167,8 @@
 ; Parseltongue EdgeType mapping:
 ; - @dependency.call → EdgeType::Calls
 ; - @dependency.use* → EdgeType::Uses
-; - @dependency.implements → EdgeType::Implements
+; - impl Trait for Type → EdgeType::Implements (rust_trait_impl_bound_extractor)
+; - T: Trait bounds → EdgeType::Bound (rust_trait_impl_bound_extractor)
 ; - @dependency.*_type_ref → EdgeType::Uses
 ; - @dependency.await → EdgeType::Calls
 ; - @dependency.field_access → EdgeType::Uses
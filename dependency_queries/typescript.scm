@@ -9,9 +9,10 @@
 (call_expression
   function: (identifier) @reference.call) @dependency.call
 
-; Method calls
+; Method calls (receiver kept for namespace-import / this resolution)
 (call_expression
   function: (member_expression
+    object: (_) @call_receiver.method_call
     property: (property_identifier) @reference.method_call)) @dependency.method_call
 
 ; Import statements
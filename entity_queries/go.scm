@@ -1,6 +1,11 @@
 ; Go entity extraction queries
 ; Based on tree-sitter-go grammar
 
+; Generic functions: func Map[T any, U any](...) - type params kept as metadata
+(function_declaration
+  name: (identifier) @name
+  type_parameters: (type_parameter_list) @type_parameters) @definition.function
+
 ; Functions
 (function_declaration
   name: (identifier) @name) @definition.function
@@ -9,12 +14,26 @@
 (method_declaration
   name: (field_identifier) @name) @definition.method
 
+; Generic structs: type Box[T any] struct {...}
+(type_declaration
+  (type_spec
+    name: (type_identifier) @name
+    type_parameters: (type_parameter_list) @type_parameters
+    type: (struct_type))) @definition.struct
+
 ; Structs
 (type_declaration
   (type_spec
     name: (type_identifier) @name
     type: (struct_type))) @definition.struct
 
+; Generic interfaces: type Store[K comparable, V any] interface {...}
+(type_declaration
+  (type_spec
+    name: (type_identifier) @name
+    type_parameters: (type_parameter_list) @type_parameters
+    type: (interface_type))) @definition.interface
+
 ; Interfaces
 (type_declaration
   (type_spec
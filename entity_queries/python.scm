@@ -11,6 +11,13 @@
     (function_definition
       name: (identifier) @name) @definition.method))
 
+; Decorated methods (@property, @staticmethod, @classmethod, ...)
+(class_definition
+  body: (block
+    (decorated_definition
+      definition: (function_definition
+        name: (identifier) @name) @definition.method)))
+
 ; Functions (top-level only - but tree-sitter will match all, so we rely on dedup in code)
 (function_definition
   name: (identifier) @name) @definition.function
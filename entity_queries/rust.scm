@@ -21,6 +21,11 @@
 (impl_item
   type: (type_identifier) @name) @definition.impl
 
+; Generic impl blocks: impl<T> Trait for Wrapper<T> (v1.7.3)
+(impl_item
+  type: (generic_type
+    type: (type_identifier) @name)) @definition.impl
+
 ; Methods within impl blocks
 (impl_item
   body: (declaration_list
@@ -0,0 +1,9 @@
+#![no_main]
+
+use libfuzzer_sys::fuzz_target;
+use parseltongue_core::entities::Language;
+use parseltongue_core::source_extraction_fuzz_driver::run_extractors_on_source_bytes;
+
+fuzz_target!(|data: &[u8]| {
+    run_extractors_on_source_bytes(Language::CSharp, data);
+});